@@ -35,6 +35,8 @@ import (
 var (
 	cmdBucketKey = []byte("Commands")
 	pkgBucketKey = []byte("Snaps")
+	libBucketKey = []byte("Libraries")
+	manBucketKey = []byte("ManPages")
 )
 
 type writer struct {
@@ -43,6 +45,8 @@ type writer struct {
 	tx        *bbolt.Tx
 	cmdBucket *bbolt.Bucket
 	pkgBucket *bbolt.Bucket
+	libBucket *bbolt.Bucket
+	manBucket *bbolt.Bucket
 }
 
 // Create opens the commands database for writing, and starts a
@@ -67,6 +71,12 @@ func Create() (CommandDB, error) {
 		if err == nil {
 			t.pkgBucket, err = t.tx.CreateBucket(pkgBucketKey)
 		}
+		if err == nil {
+			t.libBucket, err = t.tx.CreateBucket(libBucketKey)
+		}
+		if err == nil {
+			t.manBucket, err = t.tx.CreateBucket(manBucketKey)
+		}
 
 		if err != nil {
 			t.tx.Rollback()
@@ -81,27 +91,36 @@ func Create() (CommandDB, error) {
 	return t, nil
 }
 
-func (t *writer) AddSnap(snapName, version, summary string, commands []string) error {
-	for _, cmd := range commands {
+// addToBucket appends the given snap to the list of snaps stored under
+// each of the given keys in the bucket.
+func addToBucket(b *bbolt.Bucket, keys []string, snapName, version string) error {
+	for _, key := range keys {
 		var sil []Package
 
-		bcmd := []byte(cmd)
-		row := t.cmdBucket.Get(bcmd)
+		bkey := []byte(key)
+		row := b.Get(bkey)
 		if row != nil {
 			if err := json.Unmarshal(row, &sil); err != nil {
 				return err
 			}
 		}
-		// For the mapping of command->snap we do not need the summary, nothing is using that.
+		// For the mapping of key->snap we do not need the summary, nothing is using that.
 		sil = append(sil, Package{Snap: snapName, Version: version})
 		row, err := json.Marshal(sil)
 		if err != nil {
 			return err
 		}
-		if err := t.cmdBucket.Put(bcmd, row); err != nil {
+		if err := b.Put(bkey, row); err != nil {
 			return err
 		}
 	}
+	return nil
+}
+
+func (t *writer) AddSnap(snapName, version, summary string, commands []string) error {
+	if err := addToBucket(t.cmdBucket, commands, snapName, version); err != nil {
+		return err
+	}
 
 	// TODO: use json here as well and put the version information here
 	bj, err := json.Marshal(Package{
@@ -119,6 +138,13 @@ func (t *writer) AddSnap(snapName, version, summary string, commands []string) e
 	return nil
 }
 
+func (t *writer) AddSnapProvides(snapName, version string, libraries, manPages []string) error {
+	if err := addToBucket(t.libBucket, libraries, snapName, version); err != nil {
+		return err
+	}
+	return addToBucket(t.manBucket, manPages, snapName, version)
+}
+
 func (t *writer) Commit() error {
 	// either everything worked, and therefore this will fail, or something
 	// will fail, and that error is more important than this one if this one
@@ -156,6 +182,8 @@ func (t *writer) done(commit bool) error {
 
 	t.cmdBucket = nil
 	t.pkgBucket = nil
+	t.libBucket = nil
+	t.manBucket = nil
 	if t.tx != nil {
 		if commit {
 			e1 = t.tx.Commit()
@@ -230,19 +258,23 @@ func Open() (Finder, error) {
 	return &boltFinder{db}, nil
 }
 
-func (f *boltFinder) FindCommand(command string) ([]Command, error) {
+// findInBucket returns the list of snaps stored under the given key in the
+// bucket, or nil if there are none.
+func (f *boltFinder) findInBucket(bucketKey []byte, key string) ([]Package, error) {
 	tx, err := f.Begin(false)
 	if err != nil {
 		return nil, err
 	}
 	defer tx.Rollback()
 
-	b := tx.Bucket(cmdBucketKey)
+	// databases created by older versions of snapd may not have all
+	// the buckets
+	b := tx.Bucket(bucketKey)
 	if b == nil {
 		return nil, nil
 	}
 
-	buf := b.Get([]byte(command))
+	buf := b.Get([]byte(key))
 	if buf == nil {
 		return nil, nil
 	}
@@ -250,6 +282,14 @@ func (f *boltFinder) FindCommand(command string) ([]Command, error) {
 	if err := json.Unmarshal(buf, &sil); err != nil {
 		return nil, err
 	}
+	return sil, nil
+}
+
+func (f *boltFinder) FindCommand(command string) ([]Command, error) {
+	sil, err := f.findInBucket(cmdBucketKey, command)
+	if err != nil || sil == nil {
+		return nil, err
+	}
 	cmds := make([]Command, len(sil))
 	for i, si := range sil {
 		cmds[i] = Command{
@@ -262,6 +302,14 @@ func (f *boltFinder) FindCommand(command string) ([]Command, error) {
 	return cmds, nil
 }
 
+func (f *boltFinder) FindLibrary(soname string) ([]Package, error) {
+	return f.findInBucket(libBucketKey, soname)
+}
+
+func (f *boltFinder) FindManPage(name string) ([]Package, error) {
+	return f.findInBucket(manBucketKey, name)
+}
+
 func (f *boltFinder) FindPackage(pkgName string) (*Package, error) {
 	tx, err := f.Begin(false)
 	if err != nil {
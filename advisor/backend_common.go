@@ -25,6 +25,9 @@ type CommandDB interface {
 	// AddSnap adds the entries for commands pointing to the given
 	// snap name to the commands database.
 	AddSnap(snapName, version, summary string, commands []string) error
+	// AddSnapProvides adds the entries for shared library sonames and
+	// man page names pointing to the given snap name to the database.
+	AddSnapProvides(snapName, version string, libraries, manPages []string) error
 	// Commit persist the changes, and closes the database. If the
 	// database has already been committed/rollbacked, does nothing.
 	Commit() error
@@ -24,6 +24,8 @@ var newFinder = Open
 type Finder interface {
 	FindCommand(command string) ([]Command, error)
 	FindPackage(pkgName string) (*Package, error)
+	FindLibrary(soname string) ([]Package, error)
+	FindManPage(name string) ([]Package, error)
 	Close() error
 }
 
@@ -0,0 +1,54 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package advisor
+
+import (
+	"os"
+)
+
+// FindLibrary returns the snaps that provide a shared library with the
+// given soname, e.g. "libfoo.so.3".
+func FindLibrary(soname string) ([]Package, error) {
+	finder, err := newFinder()
+	if err != nil && os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer finder.Close()
+
+	return finder.FindLibrary(soname)
+}
+
+// FindManPage returns the snaps that provide a man page with the given
+// name, e.g. "foo" or "foo.1".
+func FindManPage(name string) ([]Package, error) {
+	finder, err := newFinder()
+	if err != nil && os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer finder.Close()
+
+	return finder.FindManPage(name)
+}
@@ -0,0 +1,86 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package advisor_test
+
+import (
+	"os"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/advisor"
+	"github.com/snapcore/snapd/dirs"
+)
+
+type libfinderSuite struct{}
+
+var _ = Suite(&libfinderSuite{})
+
+func (s *libfinderSuite) SetUpTest(c *C) {
+	dirs.SetRootDir(c.MkDir())
+	c.Assert(os.MkdirAll(dirs.SnapCacheDir, 0755), IsNil)
+
+	db, err := advisor.Create()
+	c.Assert(err, IsNil)
+	c.Assert(db.AddSnap("foo", "1.0", "foo summary", []string{"foo"}), IsNil)
+	c.Assert(db.AddSnapProvides("foo", "1.0", []string{"libfoo.so.3", "libmeh.so.1"}, []string{"foo.1"}), IsNil)
+	c.Assert(db.AddSnapProvides("bar", "2.0", []string{"libmeh.so.1"}, nil), IsNil)
+	c.Assert(db.Commit(), IsNil)
+}
+
+func (s *libfinderSuite) TearDownTest(c *C) {
+	dirs.SetRootDir("")
+}
+
+func (s *libfinderSuite) TestFindLibraryHit(c *C) {
+	pkgs, err := advisor.FindLibrary("libmeh.so.1")
+	c.Assert(err, IsNil)
+	c.Check(pkgs, DeepEquals, []advisor.Package{
+		{Snap: "foo", Version: "1.0"},
+		{Snap: "bar", Version: "2.0"},
+	})
+}
+
+func (s *libfinderSuite) TestFindLibraryMiss(c *C) {
+	pkgs, err := advisor.FindLibrary("libmoh.so.1")
+	c.Assert(err, IsNil)
+	c.Check(pkgs, HasLen, 0)
+}
+
+func (s *libfinderSuite) TestFindManPageHit(c *C) {
+	pkgs, err := advisor.FindManPage("foo.1")
+	c.Assert(err, IsNil)
+	c.Check(pkgs, DeepEquals, []advisor.Package{
+		{Snap: "foo", Version: "1.0"},
+	})
+}
+
+func (s *libfinderSuite) TestFindManPageMiss(c *C) {
+	pkgs, err := advisor.FindManPage("bar.1")
+	c.Assert(err, IsNil)
+	c.Check(pkgs, HasLen, 0)
+}
+
+func (s *libfinderSuite) TestFindLibraryNoDatabase(c *C) {
+	dirs.SetRootDir(c.MkDir())
+
+	pkgs, err := advisor.FindLibrary("libfoo.so.3")
+	c.Assert(err, IsNil)
+	c.Check(pkgs, IsNil)
+}
@@ -131,27 +131,28 @@ func (at *AssertionType) JSONBody() bool {
 
 // Understood assertion types.
 var (
-	AccountType              = &AssertionType{"account", []string{"account-id"}, nil, assembleAccount, 0}
-	AccountKeyType           = &AssertionType{"account-key", []string{"public-key-sha3-384"}, nil, assembleAccountKey, 0}
-	RepairType               = &AssertionType{"repair", []string{"brand-id", "repair-id"}, nil, assembleRepair, sequenceForming}
-	ModelType                = &AssertionType{"model", []string{"series", "brand-id", "model"}, nil, assembleModel, 0}
-	SerialType               = &AssertionType{"serial", []string{"brand-id", "model", "serial"}, nil, assembleSerial, 0}
-	BaseDeclarationType      = &AssertionType{"base-declaration", []string{"series"}, nil, assembleBaseDeclaration, 0}
-	SnapDeclarationType      = &AssertionType{"snap-declaration", []string{"series", "snap-id"}, nil, assembleSnapDeclaration, 0}
-	SnapBuildType            = &AssertionType{"snap-build", []string{"snap-sha3-384"}, nil, assembleSnapBuild, 0}
-	SnapRevisionType         = &AssertionType{"snap-revision", []string{"snap-sha3-384", "provenance"}, map[string]string{"provenance": naming.DefaultProvenance}, assembleSnapRevision, 0}
-	SnapDeveloperType        = &AssertionType{"snap-developer", []string{"snap-id", "publisher-id"}, nil, assembleSnapDeveloper, 0}
-	SystemUserType           = &AssertionType{"system-user", []string{"brand-id", "email"}, nil, assembleSystemUser, 0}
-	ValidationType           = &AssertionType{"validation", []string{"series", "snap-id", "approved-snap-id", "approved-snap-revision"}, nil, assembleValidation, 0}
-	ValidationSetType        = &AssertionType{"validation-set", []string{"series", "account-id", "name", "sequence"}, nil, assembleValidationSet, sequenceForming}
-	StoreType                = &AssertionType{"store", []string{"store"}, nil, assembleStore, 0}
-	PreseedType              = &AssertionType{"preseed", []string{"series", "brand-id", "model", "system-label"}, nil, assemblePreseed, 0}
-	SnapResourceRevisionType = &AssertionType{"snap-resource-revision", []string{"snap-id", "resource-name", "resource-sha3-384", "provenance"}, map[string]string{"provenance": naming.DefaultProvenance}, assembleSnapResourceRevision, 0}
-	SnapResourcePairType     = &AssertionType{"snap-resource-pair", []string{"snap-id", "resource-name", "resource-revision", "snap-revision", "provenance"}, map[string]string{"provenance": naming.DefaultProvenance}, assembleSnapResourcePair, 0}
-	ConfdbSchemaType         = &AssertionType{"confdb-schema", []string{"account-id", "name"}, nil, assembleConfdbSchema, jsonBody}
-	ClusterType              = &AssertionType{"cluster", []string{"cluster-id", "sequence"}, nil, assembleCluster, sequenceForming}
-	RequestMessageType       = &AssertionType{"request-message", []string{"account-id", "message-id"}, nil, assembleRequestMessage, 0}
-	HardwareIdentityType     = &AssertionType{"hardware-identity", []string{"issuer-id", "hardware-id-key-sha3-384"}, nil, assembleHardwareIdentity, 0}
+	AccountType               = &AssertionType{"account", []string{"account-id"}, nil, assembleAccount, 0}
+	AccountKeyType            = &AssertionType{"account-key", []string{"public-key-sha3-384"}, nil, assembleAccountKey, 0}
+	RepairType                = &AssertionType{"repair", []string{"brand-id", "repair-id"}, nil, assembleRepair, sequenceForming}
+	ModelType                 = &AssertionType{"model", []string{"series", "brand-id", "model"}, nil, assembleModel, 0}
+	SerialType                = &AssertionType{"serial", []string{"brand-id", "model", "serial"}, nil, assembleSerial, 0}
+	BaseDeclarationType       = &AssertionType{"base-declaration", []string{"series"}, nil, assembleBaseDeclaration, 0}
+	SnapDeclarationType       = &AssertionType{"snap-declaration", []string{"series", "snap-id"}, nil, assembleSnapDeclaration, 0}
+	SnapBuildType             = &AssertionType{"snap-build", []string{"snap-sha3-384"}, nil, assembleSnapBuild, 0}
+	SnapRevisionType          = &AssertionType{"snap-revision", []string{"snap-sha3-384", "provenance"}, map[string]string{"provenance": naming.DefaultProvenance}, assembleSnapRevision, 0}
+	SnapDeveloperType         = &AssertionType{"snap-developer", []string{"snap-id", "publisher-id"}, nil, assembleSnapDeveloper, 0}
+	SystemUserType            = &AssertionType{"system-user", []string{"brand-id", "email"}, nil, assembleSystemUser, 0}
+	ValidationType            = &AssertionType{"validation", []string{"series", "snap-id", "approved-snap-id", "approved-snap-revision"}, nil, assembleValidation, 0}
+	ValidationSetType         = &AssertionType{"validation-set", []string{"series", "account-id", "name", "sequence"}, nil, assembleValidationSet, sequenceForming}
+	StoreType                 = &AssertionType{"store", []string{"store"}, nil, assembleStore, 0}
+	PreseedType               = &AssertionType{"preseed", []string{"series", "brand-id", "model", "system-label"}, nil, assemblePreseed, 0}
+	SnapResourceRevisionType  = &AssertionType{"snap-resource-revision", []string{"snap-id", "resource-name", "resource-sha3-384", "provenance"}, map[string]string{"provenance": naming.DefaultProvenance}, assembleSnapResourceRevision, 0}
+	SnapResourcePairType      = &AssertionType{"snap-resource-pair", []string{"snap-id", "resource-name", "resource-revision", "snap-revision", "provenance"}, map[string]string{"provenance": naming.DefaultProvenance}, assembleSnapResourcePair, 0}
+	ConfdbSchemaType          = &AssertionType{"confdb-schema", []string{"account-id", "name"}, nil, assembleConfdbSchema, jsonBody}
+	ClusterType               = &AssertionType{"cluster", []string{"cluster-id", "sequence"}, nil, assembleCluster, sequenceForming}
+	RequestMessageType        = &AssertionType{"request-message", []string{"account-id", "message-id"}, nil, assembleRequestMessage, 0}
+	HardwareIdentityType      = &AssertionType{"hardware-identity", []string{"issuer-id", "hardware-id-key-sha3-384"}, nil, assembleHardwareIdentity, 0}
+	AutoConnectionControlType = &AssertionType{"auto-connection-control", []string{"brand-id", "model", "snap-id"}, nil, assembleAutoConnectionControl, 0}
 	// ...
 )
 
@@ -165,27 +166,28 @@ var (
 )
 
 var typeRegistry = map[string]*AssertionType{
-	AccountType.Name:              AccountType,
-	AccountKeyType.Name:           AccountKeyType,
-	ModelType.Name:                ModelType,
-	SerialType.Name:               SerialType,
-	BaseDeclarationType.Name:      BaseDeclarationType,
-	SnapDeclarationType.Name:      SnapDeclarationType,
-	SnapBuildType.Name:            SnapBuildType,
-	SnapRevisionType.Name:         SnapRevisionType,
-	SnapDeveloperType.Name:        SnapDeveloperType,
-	SystemUserType.Name:           SystemUserType,
-	ValidationType.Name:           ValidationType,
-	ValidationSetType.Name:        ValidationSetType,
-	RepairType.Name:               RepairType,
-	StoreType.Name:                StoreType,
-	PreseedType.Name:              PreseedType,
-	SnapResourceRevisionType.Name: SnapResourceRevisionType,
-	SnapResourcePairType.Name:     SnapResourcePairType,
-	ConfdbSchemaType.Name:         ConfdbSchemaType,
-	ClusterType.Name:              ClusterType,
-	RequestMessageType.Name:       RequestMessageType,
-	HardwareIdentityType.Name:     HardwareIdentityType,
+	AccountType.Name:               AccountType,
+	AccountKeyType.Name:            AccountKeyType,
+	ModelType.Name:                 ModelType,
+	SerialType.Name:                SerialType,
+	BaseDeclarationType.Name:       BaseDeclarationType,
+	SnapDeclarationType.Name:       SnapDeclarationType,
+	SnapBuildType.Name:             SnapBuildType,
+	SnapRevisionType.Name:          SnapRevisionType,
+	SnapDeveloperType.Name:         SnapDeveloperType,
+	SystemUserType.Name:            SystemUserType,
+	ValidationType.Name:            ValidationType,
+	ValidationSetType.Name:         ValidationSetType,
+	RepairType.Name:                RepairType,
+	StoreType.Name:                 StoreType,
+	PreseedType.Name:               PreseedType,
+	SnapResourceRevisionType.Name:  SnapResourceRevisionType,
+	SnapResourcePairType.Name:      SnapResourcePairType,
+	ConfdbSchemaType.Name:          ConfdbSchemaType,
+	ClusterType.Name:               ClusterType,
+	RequestMessageType.Name:        RequestMessageType,
+	HardwareIdentityType.Name:      HardwareIdentityType,
+	AutoConnectionControlType.Name: AutoConnectionControlType,
 	// no authority
 	DeviceSessionRequestType.Name: DeviceSessionRequestType,
 	SerialRequestType.Name:        SerialRequestType,
@@ -51,6 +51,7 @@ func (as *assertsSuite) TestTypeNames(c *C) {
 		"account",
 		"account-key",
 		"account-key-request",
+		"auto-connection-control",
 		"base-declaration",
 		"cluster",
 		"confdb-control",
@@ -1208,6 +1209,7 @@ func (as *assertsSuite) TestWithAuthority(c *C) {
 		"preseed",
 		"confdb-schema",
 		"hardware-identity",
+		"auto-connection-control",
 		"serial",
 		"system-user",
 		"validation",
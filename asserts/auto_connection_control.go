@@ -0,0 +1,200 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package asserts
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/snapcore/snapd/snap/naming"
+)
+
+// AutoConnectionControl holds an auto-connection-control assertion, which
+// allows a brand to grant auto-connections of the interfaces of a snap on
+// the devices of one of its models, optionally limited to some serials,
+// without changing the snap-declaration of the snap.
+type AutoConnectionControl struct {
+	assertionBase
+
+	serials   []string
+	plugRules map[string]*PlugRule
+	slotRules map[string]*SlotRule
+	timestamp time.Time
+}
+
+// BrandID returns the brand identifier that signed this assertion.
+func (acc *AutoConnectionControl) BrandID() string {
+	return acc.HeaderString("brand-id")
+}
+
+// Model returns the name of the model this assertion applies to.
+func (acc *AutoConnectionControl) Model() string {
+	return acc.HeaderString("model")
+}
+
+// SnapID returns the identifier of the snap whose auto-connections are
+// controlled.
+func (acc *AutoConnectionControl) SnapID() string {
+	return acc.HeaderString("snap-id")
+}
+
+// Serials returns the serials, or serial prefixes ending in "*", of the
+// devices this assertion applies to. If empty the assertion applies to all
+// the devices of the model.
+func (acc *AutoConnectionControl) Serials() []string {
+	return acc.serials
+}
+
+// Timestamp returns the time when the assertion was issued.
+func (acc *AutoConnectionControl) Timestamp() time.Time {
+	return acc.timestamp
+}
+
+// PlugRule returns the plug-side rule about the given interface if one was
+// included in the plugs stanza of the assertion, otherwise it returns nil.
+func (acc *AutoConnectionControl) PlugRule(interfaceName string) *PlugRule {
+	return acc.plugRules[interfaceName]
+}
+
+// SlotRule returns the slot-side rule about the given interface if one was
+// included in the slots stanza of the assertion, otherwise it returns nil.
+func (acc *AutoConnectionControl) SlotRule(interfaceName string) *SlotRule {
+	return acc.slotRules[interfaceName]
+}
+
+// AppliesTo returns whether the assertion applies to the device with the
+// given serial assertion.
+func (acc *AutoConnectionControl) AppliesTo(serial *Serial) bool {
+	if serial == nil {
+		return false
+	}
+	if serial.BrandID() != acc.BrandID() || serial.Model() != acc.Model() {
+		return false
+	}
+	if len(acc.serials) == 0 {
+		return true
+	}
+	for _, s := range acc.serials {
+		if strings.HasSuffix(s, "*") {
+			if strings.HasPrefix(serial.Serial(), strings.TrimSuffix(s, "*")) {
+				return true
+			}
+		} else if s == serial.Serial() {
+			return true
+		}
+	}
+	return false
+}
+
+// the rules of an auto-connection-control can only grant auto-connections
+var autoConnectionControlSubrules = []string{"allow-auto-connection", "deny-auto-connection"}
+
+func checkAutoConnectionControlRules(side string, rules map[string]any) error {
+	for iface, rule := range rules {
+		m, ok := rule.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s rule for interface %q must be a map", side, iface)
+		}
+		for subrule := range m {
+			if subrule != autoConnectionControlSubrules[0] && subrule != autoConnectionControlSubrules[1] {
+				return fmt.Errorf("%s rule for interface %q can only have %s, not %q", side, iface, strings.Join(autoConnectionControlSubrules, " or "), subrule)
+			}
+		}
+	}
+	return nil
+}
+
+func assembleAutoConnectionControl(assert assertionBase) (Assertion, error) {
+	if err := checkAuthorityMatchesBrand(&assert); err != nil {
+		return nil, err
+	}
+
+	if _, err := checkModel(assert.headers); err != nil {
+		return nil, err
+	}
+
+	if _, err := checkStringMatches(assert.headers, "snap-id", naming.ValidSnapID); err != nil {
+		return nil, err
+	}
+
+	serials, err := checkStringList(assert.headers, "serials")
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range serials {
+		if s == "" || s == "*" || strings.Contains(strings.TrimSuffix(s, "*"), "*") {
+			return nil, fmt.Errorf(`"serials" header must contain serials or serial prefixes ending in "*", not %q`, s)
+		}
+	}
+
+	plugs, err := checkMap(assert.headers, "plugs")
+	if err != nil {
+		return nil, err
+	}
+	slots, err := checkMap(assert.headers, "slots")
+	if err != nil {
+		return nil, err
+	}
+	if len(plugs) == 0 && len(slots) == 0 {
+		return nil, fmt.Errorf(`"plugs" or "slots" header is mandatory`)
+	}
+
+	var plugRules map[string]*PlugRule
+	if plugs != nil {
+		if err := checkAutoConnectionControlRules("plug", plugs); err != nil {
+			return nil, err
+		}
+		plugRules = make(map[string]*PlugRule, len(plugs))
+		err := compilePlugRules(plugs, func(iface string, rule *PlugRule) {
+			plugRules[iface] = rule
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var slotRules map[string]*SlotRule
+	if slots != nil {
+		if err := checkAutoConnectionControlRules("slot", slots); err != nil {
+			return nil, err
+		}
+		slotRules = make(map[string]*SlotRule, len(slots))
+		err := compileSlotRules(slots, func(iface string, rule *SlotRule) {
+			slotRules[iface] = rule
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	timestamp, err := checkRFC3339Date(assert.headers, "timestamp")
+	if err != nil {
+		return nil, err
+	}
+
+	return &AutoConnectionControl{
+		assertionBase: assert,
+		serials:       serials,
+		plugRules:     plugRules,
+		slotRules:     slotRules,
+		timestamp:     timestamp,
+	}, nil
+}
@@ -0,0 +1,163 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package asserts_test
+
+import (
+	"strings"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/asserts"
+)
+
+var (
+	_ = Suite(&autoConnectionControlSuite{})
+)
+
+type autoConnectionControlSuite struct {
+	ts     time.Time
+	tsLine string
+}
+
+func (s *autoConnectionControlSuite) SetUpSuite(c *C) {
+	s.ts = time.Now().Truncate(time.Second).UTC()
+	s.tsLine = "timestamp: " + s.ts.Format(time.RFC3339) + "\n"
+}
+
+const autoConnectionControlExample = "type: auto-connection-control\n" +
+	"authority-id: brand-id1\n" +
+	"brand-id: brand-id1\n" +
+	"model: baz-3000\n" +
+	"snap-id: snapidsnapidsnapidsnapidsnapidsn\n" +
+	"serials:\n" +
+	"  - 2700\n" +
+	"  - lab-*\n" +
+	"plugs:\n" +
+	"  hardware-observe:\n" +
+	"    allow-auto-connection: true\n" +
+	"slots:\n" +
+	"  serial-port:\n" +
+	"    allow-auto-connection:\n" +
+	"      plug-snap-id:\n" +
+	"        - plugsnapidplugsnapidplugsnapidpl\n" +
+	"TSLINE" +
+	"sign-key-sha3-384: Jv8_JiHiIzJVcO9M55pPdqSDWUvuhfDIBJUS-3VW7F_idjix7Ffn5qMxB21ZQuij\n\n" +
+	"AXNpZw=="
+
+func (s *autoConnectionControlSuite) TestDecodeOK(c *C) {
+	encoded := strings.Replace(autoConnectionControlExample, "TSLINE", s.tsLine, 1)
+	a, err := asserts.Decode([]byte(encoded))
+	c.Assert(err, IsNil)
+	c.Check(a.Type(), Equals, asserts.AutoConnectionControlType)
+	acc := a.(*asserts.AutoConnectionControl)
+	c.Check(acc.AuthorityID(), Equals, "brand-id1")
+	c.Check(acc.BrandID(), Equals, "brand-id1")
+	c.Check(acc.Model(), Equals, "baz-3000")
+	c.Check(acc.SnapID(), Equals, "snapidsnapidsnapidsnapidsnapidsn")
+	c.Check(acc.Serials(), DeepEquals, []string{"2700", "lab-*"})
+	c.Check(acc.Timestamp(), Equals, s.ts)
+
+	plugRule := acc.PlugRule("hardware-observe")
+	c.Assert(plugRule, NotNil)
+	c.Check(plugRule.AllowAutoConnection, HasLen, 1)
+	c.Check(acc.PlugRule("serial-port"), IsNil)
+
+	slotRule := acc.SlotRule("serial-port")
+	c.Assert(slotRule, NotNil)
+	c.Assert(slotRule.AllowAutoConnection, HasLen, 1)
+	c.Check(slotRule.AllowAutoConnection[0].PlugSnapIDs, DeepEquals, []string{"plugsnapidplugsnapidplugsnapidpl"})
+	c.Check(acc.SlotRule("hardware-observe"), IsNil)
+}
+
+func (s *autoConnectionControlSuite) serial(c *C, brandID, model, serial string) *asserts.Serial {
+	encodedPubKey, err := asserts.EncodePublicKey(testPrivKey2.PublicKey())
+	c.Assert(err, IsNil)
+	a, err := asserts.AssembleAndSignInTest(asserts.SerialType, map[string]any{
+		"authority-id":        brandID,
+		"brand-id":            brandID,
+		"model":               model,
+		"serial":              serial,
+		"device-key":          string(encodedPubKey),
+		"device-key-sha3-384": testPrivKey2.PublicKey().ID(),
+		"timestamp":           s.ts.Format(time.RFC3339),
+	}, nil, testPrivKey0)
+	c.Assert(err, IsNil)
+	return a.(*asserts.Serial)
+}
+
+func (s *autoConnectionControlSuite) TestAppliesTo(c *C) {
+	encoded := strings.Replace(autoConnectionControlExample, "TSLINE", s.tsLine, 1)
+	a, err := asserts.Decode([]byte(encoded))
+	c.Assert(err, IsNil)
+	acc := a.(*asserts.AutoConnectionControl)
+
+	c.Check(acc.AppliesTo(s.serial(c, "brand-id1", "baz-3000", "2700")), Equals, true)
+	c.Check(acc.AppliesTo(s.serial(c, "brand-id1", "baz-3000", "lab-42")), Equals, true)
+	c.Check(acc.AppliesTo(s.serial(c, "brand-id1", "baz-3000", "27000")), Equals, false)
+	c.Check(acc.AppliesTo(s.serial(c, "brand-id1", "baz-3000", "prod-42")), Equals, false)
+	c.Check(acc.AppliesTo(s.serial(c, "brand-id1", "other-model", "2700")), Equals, false)
+	c.Check(acc.AppliesTo(s.serial(c, "brand-id2", "baz-3000", "2700")), Equals, false)
+	c.Check(acc.AppliesTo(nil), Equals, false)
+
+	// without serials it applies to all the devices of the model
+	encoded = strings.Replace(encoded, "serials:\n  - 2700\n  - lab-*\n", "", 1)
+	a, err = asserts.Decode([]byte(encoded))
+	c.Assert(err, IsNil)
+	acc = a.(*asserts.AutoConnectionControl)
+	c.Check(acc.Serials(), HasLen, 0)
+	c.Check(acc.AppliesTo(s.serial(c, "brand-id1", "baz-3000", "prod-42")), Equals, true)
+	c.Check(acc.AppliesTo(s.serial(c, "brand-id1", "other-model", "prod-42")), Equals, false)
+}
+
+const (
+	autoConnectionControlErrPrefix = "assertion auto-connection-control: "
+)
+
+func (s *autoConnectionControlSuite) TestDecodeInvalid(c *C) {
+	encoded := strings.Replace(autoConnectionControlExample, "TSLINE", s.tsLine, 1)
+
+	invalidTests := []struct{ original, invalid, expectedErr string }{
+		{"brand-id: brand-id1\n", "brand-id: brand-id2\n", `authority-id and brand-id must match, auto-connection-control assertions are expected to be signed by the brand: "brand-id1" != "brand-id2"`},
+		{"model: baz-3000\n", "", `"model" header is mandatory`},
+		{"model: baz-3000\n", "model: Baz-3000\n", `"model" header cannot contain uppercase letters`},
+		{"snap-id: snapidsnapidsnapidsnapidsnapidsn\n", "", `"snap-id" header is mandatory`},
+		{"snap-id: snapidsnapidsnapidsnapidsnapidsn\n", "snap-id: foo\n", `"snap-id" header contains invalid characters: "foo"`},
+		{"  - lab-*\n", "  - *\n", `"serials" header must contain serials or serial prefixes ending in "\*", not "\*"`},
+		{"  - lab-*\n", "  - l*b\n", `"serials" header must contain serials or serial prefixes ending in "\*", not "l\*b"`},
+		{"serials:\n  - 2700\n  - lab-*\n", "serials: 2700\n", `"serials" header must be a list of strings`},
+		{"    allow-auto-connection: true\n", "    allow-connection: true\n", `plug rule for interface "hardware-observe" can only have allow-auto-connection or deny-auto-connection, not "allow-connection"`},
+		{"    allow-auto-connection:\n      plug-snap-id", "    allow-installation:\n      plug-snap-id", `slot rule for interface "serial-port" can only have allow-auto-connection or deny-auto-connection, not "allow-installation"`},
+		{"  hardware-observe:\n    allow-auto-connection: true\n", "  hardware-observe: true\n", `plug rule for interface "hardware-observe" must be a map`},
+		{"    allow-auto-connection: true\n", "    allow-auto-connection: maybe\n", `allow-auto-connection in plug rule for interface "hardware-observe" must be a map or one of the shortcuts 'true' or 'false'`},
+		{s.tsLine, "", `"timestamp" header is mandatory`},
+	}
+
+	for _, test := range invalidTests {
+		invalid := strings.Replace(encoded, test.original, test.invalid, 1)
+		_, err := asserts.Decode([]byte(invalid))
+		c.Check(err, ErrorMatches, autoConnectionControlErrPrefix+test.expectedErr, Commentf("%s", test.invalid))
+	}
+
+	// either plugs or slots are needed
+	invalid := encoded[:strings.Index(encoded, "plugs:\n")] + encoded[strings.Index(encoded, s.tsLine):]
+	_, err := asserts.Decode([]byte(invalid))
+	c.Check(err, ErrorMatches, autoConnectionControlErrPrefix+`"plugs" or "slots" header is mandatory`)
+}
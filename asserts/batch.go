@@ -194,7 +194,7 @@ func (b *Batch) commitTo(db *Database, observe func(Assertion)) error {
 	return nil
 }
 
-func (b *Batch) prereqSort(db *Database) error {
+func (b *Batch) prereqSort(db RODatabase) error {
 	if b.inPrereqOrder {
 		// nothing to do
 		return nil
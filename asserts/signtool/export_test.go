@@ -0,0 +1,32 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package signtool
+
+import (
+	"github.com/snapcore/snapd/asserts"
+)
+
+func NewPKCS11KeypairMgrBackend(uri string) (asserts.ExtKeypairMgrBackend, error) {
+	p, err := parsePKCS11URI(uri)
+	if err != nil {
+		return nil, err
+	}
+	return &pkcs11KeypairMgrBackend{uri: p}, nil
+}
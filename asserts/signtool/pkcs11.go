@@ -0,0 +1,328 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package signtool
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/i18n"
+)
+
+// pkcs11URI holds the parts of a PKCS#11 URI (RFC 7512) used to select a
+// token and its keys.
+type pkcs11URI struct {
+	token  string
+	slotID string
+	object string
+	// id is hex encoded
+	id string
+
+	modulePath string
+	pin        string
+}
+
+func parsePKCS11URIValue(attr, value string) (string, error) {
+	v, err := url.PathUnescape(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid %q attribute: %v", attr, err)
+	}
+	return v, nil
+}
+
+func readPKCS11PinSource(pinSource string) (string, error) {
+	// pin-source is a URI, only the file scheme is supported, a plain
+	// path is accepted as well
+	path := strings.TrimPrefix(pinSource, "file:")
+	if !strings.HasPrefix(path, "/") {
+		return "", fmt.Errorf("unsupported \"pin-source\" %q, it must be a file", pinSource)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("cannot read PIN: %v", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+func parsePKCS11URI(uri string) (*pkcs11URI, error) {
+	if !strings.HasPrefix(uri, "pkcs11:") {
+		return nil, fmt.Errorf("cannot parse PKCS#11 URI: missing \"pkcs11:\" scheme")
+	}
+	path, query, _ := strings.Cut(strings.TrimPrefix(uri, "pkcs11:"), "?")
+
+	var p pkcs11URI
+	if path != "" {
+		for _, attrValue := range strings.Split(path, ";") {
+			attr, value, _ := strings.Cut(attrValue, "=")
+			v, err := parsePKCS11URIValue(attr, value)
+			if err != nil {
+				return nil, fmt.Errorf("cannot parse PKCS#11 URI: %v", err)
+			}
+			switch attr {
+			case "token":
+				p.token = v
+			case "slot-id":
+				p.slotID = v
+			case "object":
+				p.object = v
+			case "id":
+				p.id = hex.EncodeToString([]byte(v))
+			case "type":
+				// the keys are found by looking at the public ones
+			default:
+				return nil, fmt.Errorf("cannot parse PKCS#11 URI: unsupported attribute %q", attr)
+			}
+		}
+	}
+	var pinSource string
+	if query != "" {
+		for _, attrValue := range strings.Split(query, "&") {
+			attr, value, _ := strings.Cut(attrValue, "=")
+			v, err := parsePKCS11URIValue(attr, value)
+			if err != nil {
+				return nil, fmt.Errorf("cannot parse PKCS#11 URI: %v", err)
+			}
+			switch attr {
+			case "module-path":
+				p.modulePath = v
+			case "pin-value":
+				p.pin = v
+			case "pin-source":
+				pinSource = v
+			default:
+				return nil, fmt.Errorf("cannot parse PKCS#11 URI: unsupported query attribute %q", attr)
+			}
+		}
+	}
+	if pinSource != "" {
+		if p.pin != "" {
+			return nil, fmt.Errorf("cannot parse PKCS#11 URI: cannot have both \"pin-value\" and \"pin-source\"")
+		}
+		pin, err := readPKCS11PinSource(pinSource)
+		if err != nil {
+			return nil, fmt.Errorf("cannot use PKCS#11 URI: %v", err)
+		}
+		p.pin = pin
+	}
+	return &p, nil
+}
+
+// pkcs11ToolPinEnv is the environment variable through which the PIN is
+// given to pkcs11-tool, so that it does not appear on its command line.
+const pkcs11ToolPinEnv = "SNAPD_PKCS11_PIN"
+
+// pkcs11KeypairMgrBackend implements asserts.ExtKeypairMgrBackend and
+// asserts.ExtKeypairMgrKeyVisitBackend by using pkcs11-tool from OpenSC to
+// talk to the PKCS#11 token selected by the URI. Keys are named by their
+// label in the token.
+type pkcs11KeypairMgrBackend struct {
+	uri *pkcs11URI
+}
+
+// expected interfaces are implemented
+var (
+	_ asserts.ExtKeypairMgrBackend         = (*pkcs11KeypairMgrBackend)(nil)
+	_ asserts.ExtKeypairMgrKeyVisitBackend = (*pkcs11KeypairMgrBackend)(nil)
+)
+
+func (b *pkcs11KeypairMgrBackend) pkcs11Tool(in []byte, args ...string) ([]byte, error) {
+	var toolArgs []string
+	if b.uri.modulePath != "" {
+		toolArgs = append(toolArgs, "--module", b.uri.modulePath)
+	}
+	if b.uri.token != "" {
+		toolArgs = append(toolArgs, "--token-label", b.uri.token)
+	}
+	if b.uri.slotID != "" {
+		toolArgs = append(toolArgs, "--slot", b.uri.slotID)
+	}
+	if b.uri.pin != "" {
+		toolArgs = append(toolArgs, "--login", "--pin", "env:"+pkcs11ToolPinEnv)
+	}
+	toolArgs = append(toolArgs, args...)
+
+	cmd := exec.Command("pkcs11-tool", toolArgs...)
+	cmd.Env = os.Environ()
+	if b.uri.pin != "" {
+		cmd.Env = append(cmd.Env, pkcs11ToolPinEnv+"="+b.uri.pin)
+	}
+	var outBuf, errBuf bytes.Buffer
+	if len(in) != 0 {
+		cmd.Stdin = bytes.NewBuffer(in)
+	}
+	cmd.Stdout = &outBuf
+	cmd.Stderr = &errBuf
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("pkcs11-tool %v failed: %v (%q)", args, err, errBuf.Bytes())
+	}
+	return outBuf.Bytes(), nil
+}
+
+func (b *pkcs11KeypairMgrBackend) CheckFeatures() (asserts.ExtKeypairMgrSigning, error) {
+	if _, err := exec.LookPath("pkcs11-tool"); err != nil {
+		return "", fmt.Errorf("cannot use PKCS#11 token: pkcs11-tool (from OpenSC) is not available: %v", err)
+	}
+	return asserts.ExtKeypairMgrSigningRSAPKCS, nil
+}
+
+type pkcs11Object struct {
+	label string
+	id    string
+}
+
+// keyHandle identifies the key for pkcs11-tool, preferably by its ID.
+func (o pkcs11Object) keyHandle() string {
+	if o.id != "" {
+		return "id:" + o.id
+	}
+	return "label:" + o.label
+}
+
+func keySelectionArgs(keyHandle string) []string {
+	if strings.HasPrefix(keyHandle, "id:") {
+		return []string{"--id", strings.TrimPrefix(keyHandle, "id:")}
+	}
+	return []string{"--label", strings.TrimPrefix(keyHandle, "label:")}
+}
+
+// parsePKCS11ToolObjects parses the output of pkcs11-tool --list-objects.
+func parsePKCS11ToolObjects(out []byte) []pkcs11Object {
+	var objs []pkcs11Object
+	var cur *pkcs11Object
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, " ") {
+			objs = append(objs, pkcs11Object{})
+			cur = &objs[len(objs)-1]
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+		field, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		switch field {
+		case "label":
+			cur.label = strings.TrimSpace(value)
+		case "ID":
+			cur.id = strings.TrimSpace(value)
+		}
+	}
+	return objs
+}
+
+func (b *pkcs11KeypairMgrBackend) publicKey(obj pkcs11Object) (asserts.PublicKey, error) {
+	args := append([]string{"--read-object", "--type", "pubkey"}, keySelectionArgs(obj.keyHandle())...)
+	der, err := b.pkcs11Tool(nil, args...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read public key %q: %v", obj.label, err)
+	}
+	var rsaPub *rsa.PublicKey
+	if pubk, err := x509.ParsePKIXPublicKey(der); err == nil {
+		var ok bool
+		rsaPub, ok = pubk.(*rsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("expected RSA public key, got instead: %T", pubk)
+		}
+	} else {
+		rsaPub, err = x509.ParsePKCS1PublicKey(der)
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode public key %q: %v", obj.label, err)
+		}
+	}
+	return asserts.RSAPublicKey(rsaPub), nil
+}
+
+func (b *pkcs11KeypairMgrBackend) Visit(consider func(loaded *asserts.ExtKeypairMgrLoadedKey) error) error {
+	out, err := b.pkcs11Tool(nil, "--list-objects", "--type", "pubkey")
+	if err != nil {
+		return fmt.Errorf("cannot list PKCS#11 token keys: %v", err)
+	}
+	for _, obj := range parsePKCS11ToolObjects(out) {
+		if b.uri.object != "" && obj.label != b.uri.object {
+			continue
+		}
+		if b.uri.id != "" && obj.id != b.uri.id {
+			continue
+		}
+		name := obj.label
+		if name == "" {
+			name = obj.id
+		}
+		if name == "" {
+			continue
+		}
+		pubKey, err := b.publicKey(obj)
+		if err != nil {
+			return err
+		}
+		err = consider(&asserts.ExtKeypairMgrLoadedKey{
+			Name:      name,
+			KeyHandle: obj.keyHandle(),
+			PublicKey: pubKey,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *pkcs11KeypairMgrBackend) RSAPKCSSign(keyHandle string, prepared []byte) ([]byte, error) {
+	args := append([]string{"--sign", "--mechanism", "RSA-PKCS"}, keySelectionArgs(keyHandle)...)
+	return b.pkcs11Tool(prepared, args...)
+}
+
+func (b *pkcs11KeypairMgrBackend) Sign(keyHandle string, content []byte) ([]byte, error) {
+	return nil, fmt.Errorf("internal error: PKCS#11 tokens only support RSA-PKCS signing")
+}
+
+// GetPKCS11KeypairManager returns a KeypairManager for the RSA keys held in
+// the PKCS#11 token selected by the given PKCS#11 URI (RFC 7512), e.g. an
+// HSM or, via tpm2-pkcs11, the TPM. Keys are named by their label. The URI
+// can restrict the keys with the "object" or "id" attributes, and give the
+// module to use with "module-path" and the PIN with "pin-value" or
+// "pin-source".
+func GetPKCS11KeypairManager(uri string) (KeypairManager, error) {
+	p, err := parsePKCS11URI(uri)
+	if err != nil {
+		return nil, err
+	}
+	keypairMgr, err := asserts.NewExternalKeypairManagerWithBackend(&pkcs11KeypairMgrBackend{uri: p}, asserts.ExtKeypairMgrConfig{
+		SigningWith: "PKCS#11 token",
+		KeyStore:    "PKCS#11 token",
+	})
+	if err != nil {
+		return nil, fmt.Errorf(i18n.G("cannot setup PKCS#11 keypair manager: %v"), err)
+	}
+	return keypairMgr, nil
+}
@@ -0,0 +1,179 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package signtool_test
+
+import (
+	"crypto/x509"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/asserts/assertstest"
+	"github.com/snapcore/snapd/asserts/signtool"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type pkcs11Suite struct {
+	testutil.BaseTest
+
+	dir       string
+	pubKey    asserts.PublicKey
+	pkcs11Cmd *testutil.MockCmd
+}
+
+var _ = check.Suite(&pkcs11Suite{})
+
+func (s *pkcs11Suite) SetUpTest(c *check.C) {
+	s.BaseTest.SetUpTest(c)
+	s.dir = c.MkDir()
+
+	for _, id := range []string{"01", "02"} {
+		privKey, rsaPrivKey := assertstest.GenerateKey(752)
+		if id == "01" {
+			s.pubKey = privKey.PublicKey()
+		}
+		der, err := x509.MarshalPKIXPublicKey(&rsaPrivKey.PublicKey)
+		c.Assert(err, check.IsNil)
+		c.Assert(os.WriteFile(filepath.Join(s.dir, id+".der"), der, 0644), check.IsNil)
+	}
+
+	s.pkcs11Cmd = testutil.MockCommand(c, "pkcs11-tool", `
+dir=`+s.dir+`
+echo "$SNAPD_PKCS11_PIN" > "$dir/pin"
+while [ $# -gt 0 ]; do
+    case "$1" in
+        --list-objects)
+            cat <<'EOF2'
+Public Key Object; RSA 752 bits
+  label:      models
+  ID:         01
+  Usage:      encrypt, verify, wrap
+  Access:     local
+Public Key Object; RSA 752 bits
+  label:      serials
+  ID:         02
+  Usage:      encrypt, verify, wrap
+  Access:     local
+EOF2
+            exit 0
+            ;;
+        --read-object)
+            op=read
+            ;;
+        --sign)
+            op=sign
+            ;;
+        --id)
+            id="$2"
+            shift
+            ;;
+    esac
+    shift
+done
+case "$op" in
+    read)
+        cat "$dir/$id.der"
+        ;;
+    sign)
+        cat > "$dir/signed"
+        printf "signature"
+        ;;
+esac
+`)
+	s.AddCleanup(s.pkcs11Cmd.Restore)
+}
+
+func (s *pkcs11Suite) TestParseURIErrors(c *check.C) {
+	for _, t := range []struct {
+		uri string
+		err string
+	}{
+		{"token=foo", `cannot parse PKCS#11 URI: missing "pkcs11:" scheme`},
+		{"pkcs11:token=foo;serial=1234", `cannot parse PKCS#11 URI: unsupported attribute "serial"`},
+		{"pkcs11:token=foo?module-name=opensc", `cannot parse PKCS#11 URI: unsupported query attribute "module-name"`},
+		{"pkcs11:object=%zz", `cannot parse PKCS#11 URI: invalid "object" attribute: .*`},
+		{"pkcs11:token=foo?pin-value=1234&pin-source=/pin", `cannot parse PKCS#11 URI: cannot have both "pin-value" and "pin-source"`},
+		{"pkcs11:token=foo?pin-source=http://pin", `cannot use PKCS#11 URI: unsupported "pin-source" "http://pin", it must be a file`},
+		{"pkcs11:token=foo?pin-source=/does/not/exist", `cannot use PKCS#11 URI: cannot read PIN: .*`},
+	} {
+		_, err := signtool.GetPKCS11KeypairManager(t.uri)
+		c.Check(err, check.ErrorMatches, t.err, check.Commentf("uri: %s", t.uri))
+	}
+	c.Check(s.pkcs11Cmd.Calls(), check.HasLen, 0)
+}
+
+func (s *pkcs11Suite) TestList(c *check.C) {
+	keypairMgr, err := signtool.GetPKCS11KeypairManager("pkcs11:token=release?module-path=/usr/lib/libtpm2_pkcs11.so.1")
+	c.Assert(err, check.IsNil)
+
+	keys, err := keypairMgr.List()
+	c.Assert(err, check.IsNil)
+	c.Assert(keys, check.HasLen, 2)
+	c.Check(keys[0].Name, check.Equals, "models")
+	c.Check(keys[0].ID, check.Equals, s.pubKey.ID())
+	c.Check(keys[1].Name, check.Equals, "serials")
+
+	calls := s.pkcs11Cmd.Calls()
+	c.Assert(calls, check.HasLen, 3)
+	c.Check(calls[0], check.DeepEquals, []string{"pkcs11-tool", "--module", "/usr/lib/libtpm2_pkcs11.so.1", "--token-label", "release", "--list-objects", "--type", "pubkey"})
+	c.Check(calls[1], check.DeepEquals, []string{"pkcs11-tool", "--module", "/usr/lib/libtpm2_pkcs11.so.1", "--token-label", "release", "--read-object", "--type", "pubkey", "--id", "01"})
+	c.Check(calls[2], check.DeepEquals, []string{"pkcs11-tool", "--module", "/usr/lib/libtpm2_pkcs11.so.1", "--token-label", "release", "--read-object", "--type", "pubkey", "--id", "02"})
+}
+
+func (s *pkcs11Suite) TestGetByNameRestrictedByURI(c *check.C) {
+	keypairMgr, err := signtool.GetPKCS11KeypairManager("pkcs11:token=release;object=models")
+	c.Assert(err, check.IsNil)
+
+	privKey, err := keypairMgr.GetByName("models")
+	c.Assert(err, check.IsNil)
+	c.Check(privKey.PublicKey().ID(), check.Equals, s.pubKey.ID())
+
+	_, err = keypairMgr.GetByName("serials")
+	c.Check(err, check.ErrorMatches, `cannot find key pair in PKCS#11 token`)
+}
+
+func (s *pkcs11Suite) TestRSAPKCSSignWithPIN(c *check.C) {
+	pinFile := filepath.Join(c.MkDir(), "pin")
+	c.Assert(os.WriteFile(pinFile, []byte("1234\n"), 0600), check.IsNil)
+
+	backend, err := signtool.NewPKCS11KeypairMgrBackend("pkcs11:token=release?pin-source=file:" + pinFile)
+	c.Assert(err, check.IsNil)
+
+	sig, err := backend.RSAPKCSSign("id:01", []byte("prepared"))
+	c.Assert(err, check.IsNil)
+	c.Check(string(sig), check.Equals, "signature")
+	c.Check(filepath.Join(s.dir, "signed"), testutil.FileEquals, "prepared")
+	// the PIN is not on the command line
+	c.Check(filepath.Join(s.dir, "pin"), testutil.FileEquals, "1234\n")
+	c.Check(s.pkcs11Cmd.Calls(), check.DeepEquals, [][]string{
+		{"pkcs11-tool", "--token-label", "release", "--login", "--pin", "env:SNAPD_PKCS11_PIN", "--sign", "--mechanism", "RSA-PKCS", "--id", "01"},
+	})
+}
+
+func (s *pkcs11Suite) TestMissingPKCS11Tool(c *check.C) {
+	oldPath := os.Getenv("PATH")
+	s.AddCleanup(func() { os.Setenv("PATH", oldPath) })
+	os.Setenv("PATH", c.MkDir())
+
+	_, err := signtool.GetPKCS11KeypairManager("pkcs11:token=release")
+	c.Check(err, check.ErrorMatches, `cannot setup PKCS#11 keypair manager: cannot use PKCS#11 token: pkcs11-tool \(from OpenSC\) is not available: .*`)
+}
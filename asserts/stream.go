@@ -0,0 +1,221 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package asserts
+
+import (
+	"errors"
+	"io"
+)
+
+// DefaultStreamBatchSize is the default number of assertions that
+// are accumulated from a stream before they are committed.
+const DefaultStreamBatchSize = 1000
+
+// StreamOptions control how a stream of assertions is committed.
+type StreamOptions struct {
+	// BatchSize is the number of assertions accumulated before they are
+	// committed, by default DefaultStreamBatchSize. Assertions held back
+	// for their prerequisites count toward it.
+	BatchSize int
+	// Unsupported can be used to ignore/log assertions with unsupported
+	// formats, default behavior is to error on them.
+	Unsupported func(u *Ref, err error) error
+	// Observe, if set, is invoked for each assertion after it is added,
+	// it is only used by Database.AddStream.
+	Observe func(Assertion)
+}
+
+// StreamCommitter decodes streams of assertions and commits them in
+// batches, so that very large streams can be committed without holding all
+// of their assertions in memory.
+//
+// Assertions can come in any order in the streams. The ones with
+// prerequisites that are neither in the database nor in the current batch
+// are held back to the next batches, so memory use is bounded as long as
+// prerequisites appear close to the assertions needing them. Unlike with
+// Batch, the assertions of earlier batches stay committed if a later one
+// fails or if prerequisites are still missing at the end.
+type StreamCommitter struct {
+	db          RODatabase
+	commitTo    func(*Batch) error
+	unsupported func(u *Ref, err error) error
+	batchSize   int
+
+	b *Batch
+}
+
+// NewStreamCommitter returns a StreamCommitter that resolves prerequisites
+// against db and hands the batches, in prerequisite order, to commitTo
+// which is expected to add them to db.
+func NewStreamCommitter(db RODatabase, commitTo func(*Batch) error, opts *StreamOptions) *StreamCommitter {
+	if opts == nil {
+		opts = &StreamOptions{}
+	}
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultStreamBatchSize
+	}
+	return &StreamCommitter{
+		db:          db,
+		commitTo:    commitTo,
+		unsupported: opts.Unsupported,
+		batchSize:   batchSize,
+		b:           NewBatch(opts.Unsupported),
+	}
+}
+
+// AddStream decodes the assertions read from r, committing the current
+// batch whenever it is full.
+// Returns references to the assertions effectively added to the batches.
+func (sc *StreamCommitter) AddStream(r io.Reader) ([]*Ref, error) {
+	var refs []*Ref
+	dec := NewDecoder(r)
+	for {
+		a, err := dec.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		n := len(sc.b.added)
+		if err := sc.b.Add(a); err != nil {
+			return nil, err
+		}
+		if len(sc.b.added) > n {
+			refs = append(refs, a.Ref())
+		}
+		if len(sc.b.added) < sc.batchSize {
+			continue
+		}
+		heldBack, err := sc.commitAvailable()
+		if err != nil {
+			return nil, err
+		}
+		// the held back assertions stay in memory and so
+		// count toward the size of the next batch
+		sc.b = NewBatch(sc.unsupported)
+		for _, a := range heldBack {
+			if err := sc.b.Add(a); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return refs, nil
+}
+
+// Commit commits the assertions left in the current batch. It errors if
+// the prerequisites of some of them are still missing.
+func (sc *StreamCommitter) Commit() error {
+	heldBack, err := sc.commitAvailable()
+	if err != nil {
+		return err
+	}
+	sc.b = NewBatch(sc.unsupported)
+	if len(heldBack) == 0 {
+		return nil
+	}
+	// report the missing prerequisites
+	b := NewBatch(sc.unsupported)
+	for _, a := range heldBack {
+		if err := b.Add(a); err != nil {
+			return err
+		}
+	}
+	return b.prereqSort(sc.db)
+}
+
+func (sc *StreamCommitter) commitAvailable() (heldBack []Assertion, err error) {
+	heldBack, err = sc.b.holdBackUnavailable(sc.db)
+	if err != nil {
+		return nil, err
+	}
+	if len(sc.b.added) == 0 {
+		return heldBack, nil
+	}
+	if err := sc.commitTo(sc.b); err != nil {
+		return nil, err
+	}
+	return heldBack, nil
+}
+
+// AddStream decodes the assertions read from r, verifies them and adds
+// them to the database in batches, see StreamCommitter.
+func (db *Database) AddStream(r io.Reader, opts *StreamOptions) error {
+	var observe func(Assertion)
+	if opts != nil {
+		observe = opts.Observe
+	}
+	commitTo := func(b *Batch) error {
+		return b.commitTo(db, observe)
+	}
+	sc := NewStreamCommitter(db, commitTo, opts)
+	if _, err := sc.AddStream(r); err != nil {
+		return err
+	}
+	return sc.Commit()
+}
+
+// holdBackUnavailable leaves in the batch, in prerequisite order, only the
+// assertions whose prerequisites are available and returns the others.
+func (b *Batch) holdBackUnavailable(db RODatabase) (heldBack []Assertion, err error) {
+	var missing bool
+	ordered := make([]Assertion, 0, len(b.added))
+	saved := make(map[string]bool, len(b.added))
+	retrieve := func(ref *Ref) (Assertion, error) {
+		a, err := b.bs.Get(ref.Type, ref.PrimaryKey, ref.Type.MaxSupportedFormat())
+		if errors.Is(err, &NotFoundError{}) {
+			// fallback to pre-existing assertions
+			a, err = ref.Resolve(db.Find)
+		}
+		if errors.Is(err, &NotFoundError{}) {
+			missing = true
+		}
+		if err != nil {
+			return nil, resolveError("cannot resolve prerequisite assertion: %s", ref, err)
+		}
+		return a, nil
+	}
+	save := func(a Assertion) error {
+		u := a.Ref().Unique()
+		if !saved[u] {
+			saved[u] = true
+			ordered = append(ordered, a)
+		}
+		return nil
+	}
+
+	for _, a := range b.added {
+		missing = false
+		// a fresh fetcher for each assertion as a failed fetch leaves
+		// the fetcher in an inconsistent state
+		f := NewFetcher(db, retrieve, save)
+		if err := f.Fetch(a.Ref()); err != nil {
+			if !missing {
+				return nil, err
+			}
+			heldBack = append(heldBack, a)
+		}
+	}
+
+	b.added = ordered
+	b.inPrereqOrder = true
+	return heldBack, nil
+}
@@ -0,0 +1,164 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package asserts_test
+
+import (
+	"bytes"
+	"fmt"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/asserts"
+)
+
+func (s *batchSuite) TestDatabaseAddStream(c *C) {
+	decl1 := s.snapDecl(c, "foo", nil)
+	decl2 := s.snapDecl(c, "bar", nil)
+	storeKey := s.storeSigning.StoreAccountKey("")
+
+	for _, batchSize := range []int{0, 1, 2, 3} {
+		db, err := asserts.OpenDatabase(&asserts.DatabaseConfig{
+			Backstore: asserts.NewMemoryBackstore(),
+			Trusted:   s.storeSigning.Trusted,
+		})
+		c.Assert(err, IsNil)
+
+		b := &bytes.Buffer{}
+		enc := asserts.NewEncoder(b)
+		// prerequisites come last
+		for _, a := range []asserts.Assertion{decl1, decl2, s.dev1Acct, storeKey} {
+			c.Assert(enc.Encode(a), IsNil)
+		}
+
+		var seen []*asserts.Ref
+		err = db.AddStream(b, &asserts.StreamOptions{
+			BatchSize: batchSize,
+			Observe: func(a asserts.Assertion) {
+				seen = append(seen, a.Ref())
+			},
+		})
+		c.Assert(err, IsNil, Commentf("batch size %d", batchSize))
+		c.Check(seen, HasLen, 4)
+		// prerequisites were added first
+		c.Check(seen[0], DeepEquals, storeKey.Ref())
+
+		for _, a := range []asserts.Assertion{decl1, decl2, s.dev1Acct, storeKey} {
+			_, err := a.Ref().Resolve(db.Find)
+			c.Check(err, IsNil)
+		}
+	}
+}
+
+func (s *batchSuite) TestDatabaseAddStreamMissingPrerequisites(c *C) {
+	decl := s.snapDecl(c, "foo", nil)
+	storeKey := s.storeSigning.StoreAccountKey("")
+
+	b := &bytes.Buffer{}
+	enc := asserts.NewEncoder(b)
+	for _, a := range []asserts.Assertion{decl, storeKey} {
+		c.Assert(enc.Encode(a), IsNil)
+	}
+
+	err := s.db.AddStream(b, &asserts.StreamOptions{BatchSize: 1})
+	c.Assert(err, ErrorMatches, fmt.Sprintf(`cannot resolve prerequisite assertion: account \(%s\)`, s.dev1Acct.AccountID()))
+
+	// what could be added was
+	_, err = storeKey.Ref().Resolve(s.db.Find)
+	c.Check(err, IsNil)
+	_, err = decl.Ref().Resolve(s.db.Find)
+	c.Check(err, FitsTypeOf, &asserts.NotFoundError{})
+}
+
+func (s *batchSuite) TestDatabaseAddStreamDecodeError(c *C) {
+	b := bytes.NewBufferString("type: account\n\nfoo")
+	err := s.db.AddStream(b, nil)
+	c.Check(err, ErrorMatches, `assertion: "sign-key-sha3-384" header is mandatory`)
+}
+
+func (s *batchSuite) TestStreamCommitterHeldBackCountTowardBatchSize(c *C) {
+	decl1 := s.snapDecl(c, "foo", nil)
+	decl2 := s.snapDecl(c, "bar", nil)
+	decl3 := s.snapDecl(c, "baz", nil)
+	storeKey := s.storeSigning.StoreAccountKey("")
+
+	db, err := asserts.OpenDatabase(&asserts.DatabaseConfig{
+		Backstore: asserts.NewMemoryBackstore(),
+		Trusted:   s.storeSigning.Trusted,
+	})
+	c.Assert(err, IsNil)
+
+	var commits [][]*asserts.Ref
+	commitTo := func(b *asserts.Batch) error {
+		commits = append(commits, nil)
+		return b.CommitToAndObserve(db, func(a asserts.Assertion) {
+			commits[len(commits)-1] = append(commits[len(commits)-1], a.Ref())
+		}, nil)
+	}
+	sc := asserts.NewStreamCommitter(db, commitTo, &asserts.StreamOptions{BatchSize: 3})
+
+	encode := func(as ...asserts.Assertion) *bytes.Buffer {
+		b := &bytes.Buffer{}
+		enc := asserts.NewEncoder(b)
+		for _, a := range as {
+			c.Assert(enc.Encode(a), IsNil)
+		}
+		return b
+	}
+
+	refs, err := sc.AddStream(encode(decl1, decl2, storeKey))
+	c.Assert(err, IsNil)
+	c.Check(refs, DeepEquals, []*asserts.Ref{decl1.Ref(), decl2.Ref(), storeKey.Ref()})
+	// the declarations are held back for the account
+	c.Check(commits, DeepEquals, [][]*asserts.Ref{{storeKey.Ref()}})
+
+	// the held back declarations fill the next batch
+	refs, err = sc.AddStream(encode(s.dev1Acct, decl3))
+	c.Assert(err, IsNil)
+	c.Check(refs, DeepEquals, []*asserts.Ref{s.dev1Acct.Ref(), decl3.Ref()})
+	c.Check(commits, DeepEquals, [][]*asserts.Ref{
+		{storeKey.Ref()},
+		{s.dev1Acct.Ref(), decl1.Ref(), decl2.Ref()},
+	})
+
+	c.Assert(sc.Commit(), IsNil)
+	c.Check(commits, DeepEquals, [][]*asserts.Ref{
+		{storeKey.Ref()},
+		{s.dev1Acct.Ref(), decl1.Ref(), decl2.Ref()},
+		{decl3.Ref()},
+	})
+
+	// nothing left
+	c.Assert(sc.Commit(), IsNil)
+	c.Check(commits, HasLen, 3)
+}
+
+func (s *batchSuite) TestStreamCommitterCommitError(c *C) {
+	storeKey := s.storeSigning.StoreAccountKey("")
+
+	commitTo := func(b *asserts.Batch) error {
+		return fmt.Errorf("boom")
+	}
+	sc := asserts.NewStreamCommitter(s.db, commitTo, &asserts.StreamOptions{BatchSize: 1})
+
+	b := &bytes.Buffer{}
+	c.Assert(asserts.NewEncoder(b).Encode(storeKey), IsNil)
+	_, err := sc.AddStream(b)
+	c.Check(err, ErrorMatches, "boom")
+}
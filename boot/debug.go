@@ -22,11 +22,13 @@ package boot
 import (
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 
 	"github.com/snapcore/snapd/bootloader"
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/strutil"
 )
 
 // DebugDumpBootVars writes a dump of the snapd bootvars to the given writer
@@ -91,13 +93,14 @@ func DebugDumpBootVars(w io.Writer, dir string, uc20 bool) error {
 	return nil
 }
 
-// DebugSetBootVars is a debug helper that takes a list of <var>=<value> entries
-// and sets them for the configured bootloader.
-func DebugSetBootVars(dir string, recoveryBootloader bool, varEqVal []string) error {
+// debugBootloader finds the bootloader for setting boot variables, dir is
+// the bootloader root directory and recoveryBootloader selects the UC20
+// recovery bootloader.
+func debugBootloader(dir string, uc20, recoveryBootloader bool) (bootloader.Bootloader, bootloader.Role, error) {
 	opts := &bootloader.Options{
 		NoSlashBoot: dir != "" && dir != "/",
 	}
-	if opts.NoSlashBoot || osutil.FileExists(dirs.SnapModeenvFile) {
+	if uc20 || opts.NoSlashBoot || osutil.FileExists(dirs.SnapModeenvFile) {
 		// implied UC20 bootloader
 		opts.Role = bootloader.RoleRunMode
 	}
@@ -105,7 +108,7 @@ func DebugSetBootVars(dir string, recoveryBootloader bool, varEqVal []string) er
 	switch dir {
 	case InitramfsUbuntuBootDir:
 		if recoveryBootloader {
-			return fmt.Errorf("cannot use run bootloader root-dir with a recovery flag")
+			return nil, "", fmt.Errorf("cannot use run bootloader root-dir with a recovery flag")
 		}
 		opts.Role = bootloader.RoleRunMode
 	case InitramfsUbuntuSeedDir:
@@ -122,17 +125,149 @@ func DebugSetBootVars(dir string, recoveryBootloader bool, varEqVal []string) er
 	}
 	bloader, err := bootloader.Find(dir, opts)
 	if err != nil {
-		return err
+		return nil, "", err
 	}
+	return bloader, opts.Role, nil
+}
 
+func parseBootVarsSettings(varEqVal []string) (map[string]string, error) {
 	toSet := map[string]string{}
 
 	for _, req := range varEqVal {
 		split := strings.SplitN(req, "=", 2)
 		if len(split) != 2 {
-			return fmt.Errorf("incorrect setting %q", varEqVal)
+			return nil, fmt.Errorf("incorrect setting %q", varEqVal)
 		}
 		toSet[split[0]] = split[1]
 	}
+	return toSet, nil
+}
+
+// DebugSetBootVars is a debug helper that takes a list of <var>=<value> entries
+// and sets them for the configured bootloader.
+func DebugSetBootVars(dir string, recoveryBootloader bool, varEqVal []string) error {
+	bloader, _, err := debugBootloader(dir, false, recoveryBootloader)
+	if err != nil {
+		return err
+	}
+	toSet, err := parseBootVarsSettings(varEqVal)
+	if err != nil {
+		return err
+	}
+	return bloader.SetBootVars(toSet)
+}
+
+// knownBootVars lists, per bootloader name and role, the boot variables that
+// snapd uses and which can thus be set with DebugSetKnownBootVars. Note that
+// lk silently ignores variables which are not part of its environment
+// structure.
+var knownBootVars = map[string]map[bootloader.Role][]string{
+	"grub": {
+		bootloader.RoleSole: {
+			"snap_mode", "snap_core", "snap_try_core", "snap_kernel", "snap_try_kernel",
+		},
+		bootloader.RoleRunMode: {
+			"kernel_status", "snap_kernel", "snap_try_kernel",
+			"snapd_extra_cmdline_args", "snapd_full_cmdline_args",
+		},
+		bootloader.RoleRecovery: {
+			"snapd_recovery_mode", "snapd_recovery_system", "snapd_recovery_kernel",
+			"try_recovery_system", "recovery_system_status", "snapd_good_recovery_systems",
+		},
+	},
+	"lk": {
+		bootloader.RoleSole: {
+			"snap_mode", "snap_core", "snap_try_core", "snap_kernel", "snap_try_kernel",
+			"snap_gadget", "snap_try_gadget", "reboot_reason",
+		},
+		bootloader.RoleRunMode: {
+			"kernel_status", "snap_kernel", "snap_try_kernel",
+			"snap_gadget", "snap_try_gadget",
+		},
+		bootloader.RoleRecovery: {
+			"snapd_recovery_mode", "snapd_recovery_system",
+			"try_recovery_system", "recovery_system_status",
+		},
+	},
+	"piboot": {
+		bootloader.RoleRunMode: {
+			"kernel_status", "snap_kernel", "snap_try_kernel",
+			"snapd_extra_cmdline_args", "snapd_full_cmdline_args",
+		},
+		bootloader.RoleRecovery: {
+			"snapd_recovery_mode", "snapd_recovery_system",
+			"try_recovery_system", "recovery_system_status", "snapd_good_recovery_systems",
+		},
+	},
+	"systemd-boot": {
+		bootloader.RoleRunMode: {
+			"kernel_status", "snap_kernel", "snap_try_kernel",
+			"snapd_extra_cmdline_args", "snapd_full_cmdline_args",
+		},
+		bootloader.RoleRecovery: {
+			"snapd_recovery_mode", "snapd_recovery_system",
+			"try_recovery_system", "recovery_system_status", "snapd_good_recovery_systems",
+		},
+	},
+	"uboot": {
+		bootloader.RoleSole: {
+			"snap_mode", "snap_core", "snap_try_core", "snap_kernel", "snap_try_kernel",
+		},
+		bootloader.RoleRunMode: {
+			"kernel_status", "snap_kernel", "snap_try_kernel",
+		},
+		bootloader.RoleRecovery: {
+			"snapd_recovery_mode", "snapd_recovery_system",
+			"try_recovery_system", "recovery_system_status", "snapd_good_recovery_systems",
+		},
+	},
+	"androidboot": {
+		bootloader.RoleSole: {
+			"snap_mode", "snap_core", "snap_try_core", "snap_kernel", "snap_try_kernel",
+		},
+	},
+}
+
+// DebugKnownBootVars returns the boot variables that snapd uses with the
+// given bootloader in the given role.
+func DebugKnownBootVars(bootloaderName string, role bootloader.Role) ([]string, error) {
+	keys := knownBootVars[bootloaderName][role]
+	if len(keys) == 0 {
+		if role == bootloader.RoleSole {
+			return nil, fmt.Errorf("cannot set boot variables of bootloader %q", bootloaderName)
+		}
+		return nil, fmt.Errorf("cannot set boot variables of bootloader %q with role %q", bootloaderName, role)
+	}
+	return keys, nil
+}
+
+// DebugSetKnownBootVars is like DebugSetBootVars but only allows setting the
+// boot variables that snapd uses with the bootloader that is found, see
+// DebugKnownBootVars. All settings are validated before any of them is
+// written.
+func DebugSetKnownBootVars(dir string, uc20, recoveryBootloader bool, varEqVal []string) error {
+	bloader, role, err := debugBootloader(dir, uc20, recoveryBootloader)
+	if err != nil {
+		return err
+	}
+	toSet, err := parseBootVarsSettings(varEqVal)
+	if err != nil {
+		return err
+	}
+	known, err := DebugKnownBootVars(bloader.Name(), role)
+	if err != nil {
+		return err
+	}
+	var unknown []string
+	for k := range toSet {
+		if !strutil.ListContains(known, k) {
+			unknown = append(unknown, k)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("cannot set boot variables %s for bootloader %q: allowed variables are %s",
+			strutil.Quoted(unknown), bloader.Name(), strutil.Quoted(known))
+	}
 	return bloader.SetBootVars(toSet)
 }
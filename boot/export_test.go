@@ -27,6 +27,7 @@ import (
 	"github.com/snapcore/snapd/bootloader"
 	"github.com/snapcore/snapd/gadget/device"
 	"github.com/snapcore/snapd/secboot"
+	"github.com/snapcore/snapd/secboot/netkey"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/testutil"
 )
@@ -253,6 +254,14 @@ func MockSealKeyForBootChains(f func(method device.SealingMethod, key, saveKey s
 	}
 }
 
+func MockSecbootEnrollNetworkKeys(f func(container secboot.BootstrappedContainer, servers []*netkey.Server, opts *netkey.RecoverOptions, path string) error) (restore func()) {
+	old := secbootEnrollNetworkKeys
+	secbootEnrollNetworkKeys = f
+	return func() {
+		secbootEnrollNetworkKeys = old
+	}
+}
+
 func MockCryptsetupSupportsTokenReplace(support bool) (restore func()) {
 	return testutil.Mock(&cryptsetupSupportsTokenReplace, func() bool {
 		return support
@@ -32,10 +32,12 @@ import (
 	"github.com/snapcore/snapd/bootloader"
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/gadget"
+	"github.com/snapcore/snapd/gadget/device"
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/osutil/kcmdline"
 	"github.com/snapcore/snapd/secboot"
+	"github.com/snapcore/snapd/secboot/netkey"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/snap/snapfile"
 	"github.com/snapcore/snapd/strutil"
@@ -474,6 +476,38 @@ func isSealModeenvLocked() bool {
 	return atomic.LoadInt32(&sealModeenvLocked) == 1
 }
 
+var secbootEnrollNetworkKeys = secboot.EnrollNetworkKeys
+
+// enrollNetworkKeys binds keys of the data partition to the key servers
+// listed by the gadget, if any. It must happen before the keys are sealed,
+// as the bootstrap key of the partition is removed then.
+func enrollNetworkKeys(model *asserts.Model, gadgetDir string, encryption *EncryptionSetup) error {
+	info, err := gadget.ReadInfo(gadgetDir, model)
+	if err != nil {
+		return err
+	}
+	if info.NetworkUnlock == nil {
+		return nil
+	}
+
+	servers := make([]*netkey.Server, 0, len(info.NetworkUnlock.Servers))
+	for _, server := range info.NetworkUnlock.Servers {
+		servers = append(servers, &netkey.Server{
+			URL:        server.URL,
+			Thumbprint: server.Thumbprint,
+		})
+	}
+	opts := &netkey.RecoverOptions{
+		Timeout:  info.NetworkUnlock.TimeoutDuration(),
+		Attempts: info.NetworkUnlock.Attempts,
+	}
+	path := device.DataNetworkKeysUnder(InitramfsBootEncryptionKeyDir)
+	if err := secbootEnrollNetworkKeys(encryption.dataBootstrappedContainer, servers, opts, path); err != nil {
+		return fmt.Errorf("cannot enroll network keys: %v", err)
+	}
+	return nil
+}
+
 func makeRunnableSystemSeal(modeenv *Modeenv, model *asserts.Model, protector secboot.KeyProtectorFactory, encryption *EncryptionSetup, makeOpts makeRunnableOptions) error {
 	tokens := UseTokens(model)
 	if tokens {
@@ -703,6 +737,10 @@ func makeRunnableSystem(model *asserts.Model, bootWith *BootableSet, bootAssets
 	}
 
 	if encryption != nil {
+		if err := enrollNetworkKeys(model, bootWith.UnpackedGadgetDir, encryption); err != nil {
+			return err
+		}
+
 		protector, err := HookKeyProtectorFactory(bootWith.Kernel)
 		if err != nil && !errors.Is(err, secboot.ErrNoKeyProtector) {
 			return fmt.Errorf("cannot check for fde-setup hook key protector: %v", err)
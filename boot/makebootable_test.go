@@ -26,6 +26,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	. "gopkg.in/check.v1"
 
@@ -45,6 +46,7 @@ import (
 	"github.com/snapcore/snapd/osutil/kcmdline"
 	"github.com/snapcore/snapd/release"
 	"github.com/snapcore/snapd/secboot"
+	"github.com/snapcore/snapd/secboot/netkey"
 	"github.com/snapcore/snapd/seed"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/snap/snapfile"
@@ -1357,6 +1359,181 @@ version: 5.0
 	c.Check(sealKeyForBootChainsCalled, Equals, 1)
 }
 
+// mockNetworkUnlockInstall sets up installing a system with a gadget
+// binding keys of the data partition to a key server.
+func (s *makeBootable20Suite) mockNetworkUnlockInstall(c *C) (*asserts.Model, *boot.BootableSet, boot.TrustedAssetsInstallObserver, secboot.BootstrappedContainer) {
+	bootloader.Force(nil)
+
+	model := boottest.MakeMockUC20Model()
+	seedSnapsDirs := filepath.Join(s.rootdir, "/snaps")
+	err := os.MkdirAll(seedSnapsDirs, 0755)
+	c.Assert(err, IsNil)
+
+	// grub on ubuntu-seed
+	mockSeedGrubDir := filepath.Join(boot.InitramfsUbuntuSeedDir, "EFI", "ubuntu")
+	mockSeedGrubCfg := filepath.Join(mockSeedGrubDir, "grub.cfg")
+	err = os.MkdirAll(filepath.Dir(mockSeedGrubCfg), 0755)
+	c.Assert(err, IsNil)
+	err = os.WriteFile(mockSeedGrubCfg, []byte("# Snapd-Boot-Config-Edition: 1\n"), 0644)
+	c.Assert(err, IsNil)
+
+	// setup recovery boot assets
+	err = os.MkdirAll(filepath.Join(boot.InitramfsUbuntuSeedDir, "EFI/boot"), 0755)
+	c.Assert(err, IsNil)
+	// SHA3-384: 39efae6545f16e39633fbfbef0d5e9fdd45a25d7df8764978ce4d81f255b038046a38d9855e42e5c7c4024e153fd2e37
+	err = os.WriteFile(filepath.Join(boot.InitramfsUbuntuSeedDir, "EFI/boot/bootx64.efi"),
+		[]byte("recovery shim content"), 0644)
+	c.Assert(err, IsNil)
+	// SHA3-384: aa3c1a83e74bf6dd40dd64e5c5bd1971d75cdf55515b23b9eb379f66bf43d4661d22c4b8cf7d7a982d2013ab65c1c4c5
+	err = os.WriteFile(filepath.Join(boot.InitramfsUbuntuSeedDir, "EFI/boot/grubx64.efi"),
+		[]byte("recovery grub content"), 0644)
+	c.Assert(err, IsNil)
+
+	// grub on ubuntu-boot
+	mockBootGrubDir := filepath.Join(boot.InitramfsUbuntuBootDir, "EFI", "ubuntu")
+	mockBootGrubCfg := filepath.Join(mockBootGrubDir, "grub.cfg")
+	err = os.MkdirAll(filepath.Dir(mockBootGrubCfg), 0755)
+	c.Assert(err, IsNil)
+	err = os.WriteFile(mockBootGrubCfg, nil, 0644)
+	c.Assert(err, IsNil)
+
+	unpackedGadgetDir := c.MkDir()
+	grubRecoveryCfg := []byte("#grub-recovery cfg")
+	grubRecoveryCfgAsset := []byte("#grub-recovery cfg from assets")
+	grubCfg := []byte("#grub cfg")
+	grubCfgAsset := []byte("# Snapd-Boot-Config-Edition: 1\n#grub cfg from assets")
+	snaptest.PopulateDir(unpackedGadgetDir, [][]string{
+		{"grub-recovery.conf", string(grubRecoveryCfg)},
+		{"grub.conf", string(grubCfg)},
+		{"bootx64.efi", "shim content"},
+		{"grubx64.efi", "grub content"},
+		{"meta/snap.yaml", gadgetSnapYaml},
+		{"meta/gadget.yaml", gadgetYaml + `
+network-unlock:
+  servers:
+    - url: http://tang.example.com
+      thumbprint: oKIywvGUpTVTyxMQ3bwIIeQUudfr_CkLMjCE19ECD-U
+  timeout: 30s
+  attempts: 5
+`},
+	})
+	s.AddCleanup(assets.MockInternal("grub-recovery.cfg", grubRecoveryCfgAsset))
+	s.AddCleanup(assets.MockInternal("grub.cfg", grubCfgAsset))
+
+	// make the snaps symlinks so that we can ensure that makebootable follows
+	// the symlinks and copies the files and not the symlinks
+	baseFn, baseInfo := makeSnap(c, "core20", `name: core20
+type: base
+version: 5.0
+`, snap.R(3))
+	baseInSeed := filepath.Join(seedSnapsDirs, baseInfo.Filename())
+	err = os.Symlink(baseFn, baseInSeed)
+	c.Assert(err, IsNil)
+	kernelFn, kernelInfo := makeSnapWithFiles(c, "pc-kernel", `name: pc-kernel
+type: kernel
+version: 5.0
+`, snap.R(5),
+		[][]string{
+			{"kernel.efi", "I'm a kernel.efi"},
+		},
+	)
+	kernelInSeed := filepath.Join(seedSnapsDirs, kernelInfo.Filename())
+	err = os.Symlink(kernelFn, kernelInSeed)
+	c.Assert(err, IsNil)
+	gadgetFn, gadgetInfo := makeSnap(c, "pc", `name: pc
+type: gadget
+version: 5.0
+`, snap.R(4))
+	gadgetInSeed := filepath.Join(seedSnapsDirs, gadgetInfo.Filename())
+	err = os.Symlink(gadgetFn, gadgetInSeed)
+	c.Assert(err, IsNil)
+
+	bootWith := &boot.BootableSet{
+		RecoverySystemLabel: "20191216",
+		BasePath:            baseInSeed,
+		Base:                baseInfo,
+		KernelPath:          kernelInSeed,
+		Kernel:              kernelInfo,
+		Gadget:              gadgetInfo,
+		GadgetPath:          gadgetInSeed,
+		Recovery:            false,
+		UnpackedGadgetDir:   unpackedGadgetDir,
+	}
+
+	// set up observer state
+	useEncryption := true
+	obs, err := boot.TrustedAssetsInstallObserverForModel(model, unpackedGadgetDir, useEncryption)
+	c.Assert(obs, NotNil)
+	c.Assert(err, IsNil)
+
+	// only grubx64.efi gets installed to system-boot
+	_, err = obs.Observe(gadget.ContentWrite, gadget.SystemBoot, boot.InitramfsUbuntuBootDir, "EFI/boot/grubx64.efi",
+		&gadget.ContentChange{After: filepath.Join(unpackedGadgetDir, "grubx64.efi")})
+	c.Assert(err, IsNil)
+
+	// observe recovery assets
+	err = obs.ObserveExistingTrustedRecoveryAssets(boot.InitramfsUbuntuSeedDir)
+	c.Assert(err, IsNil)
+
+	// set encryption key
+	myKey := secboot.CreateMockBootstrappedContainer()
+	myKey2 := secboot.CreateMockBootstrappedContainer()
+	chosenPrimaryKey := []byte("primarykey!")
+	obs.SetEncryptionParams(myKey, myKey2, chosenPrimaryKey, nil, nil)
+
+	// set a mock recovery kernel
+	s.AddCleanup(boot.MockSeedReadSystemEssential(func(seedDir, label string, essentialTypes []snap.Type, tm timings.Measurer) (*asserts.Model, []*seed.Snap, error) {
+		return model, []*seed.Snap{mockKernelSeedSnap(snap.R(1)), mockGadgetSeedSnap(c, nil)}, nil
+	}))
+
+	return model, bootWith, obs, myKey
+}
+
+func (s *makeBootable20Suite) TestMakeRunnableSystem20EnrollNetworkKeys(c *C) {
+	model, bootWith, obs, dataKey := s.mockNetworkUnlockInstall(c)
+
+	enrolled := 0
+	restore := boot.MockSecbootEnrollNetworkKeys(func(container secboot.BootstrappedContainer, servers []*netkey.Server, opts *netkey.RecoverOptions, path string) error {
+		enrolled++
+		c.Check(container, Equals, dataKey)
+		c.Check(servers, DeepEquals, []*netkey.Server{{
+			URL:        "http://tang.example.com",
+			Thumbprint: "oKIywvGUpTVTyxMQ3bwIIeQUudfr_CkLMjCE19ECD-U",
+		}})
+		c.Check(opts, DeepEquals, &netkey.RecoverOptions{Timeout: 30 * time.Second, Attempts: 5})
+		c.Check(path, Equals, filepath.Join(boot.InitramfsBootEncryptionKeyDir, "ubuntu-data.network-keys"))
+		return nil
+	})
+	defer restore()
+
+	restore = boot.MockSealKeyForBootChains(func(method device.SealingMethod, key, saveKey secboot.BootstrappedContainer, primaryKey []byte, volumesAuth *device.VolumesAuthOptions, checkResult *secboot.PreinstallCheckResult, params *boot.SealKeyForBootChainsParams) error {
+		// network keys are enrolled before the bootstrap key is removed
+		c.Check(enrolled, Equals, 1)
+		return fmt.Errorf("seal error")
+	})
+	defer restore()
+
+	err := boot.MakeRunnableSystem(model, bootWith, obs.BootAssets(), obs.EncryptionSetup())
+	c.Assert(err, ErrorMatches, "seal error")
+	c.Check(enrolled, Equals, 1)
+}
+
+func (s *makeBootable20Suite) TestMakeRunnableSystem20EnrollNetworkKeysError(c *C) {
+	restore := boot.MockSecbootEnrollNetworkKeys(func(container secboot.BootstrappedContainer, servers []*netkey.Server, opts *netkey.RecoverOptions, path string) error {
+		return fmt.Errorf("cannot bind key to http://tang.example.com: boom")
+	})
+	defer restore()
+	restore = boot.MockSealKeyForBootChains(func(method device.SealingMethod, key, saveKey secboot.BootstrappedContainer, primaryKey []byte, volumesAuth *device.VolumesAuthOptions, checkResult *secboot.PreinstallCheckResult, params *boot.SealKeyForBootChainsParams) error {
+		c.Fatalf("unexpected call")
+		return nil
+	})
+	defer restore()
+
+	model, bootWith, obs, _ := s.mockNetworkUnlockInstall(c)
+	err := boot.MakeRunnableSystem(model, bootWith, obs.BootAssets(), obs.EncryptionSetup())
+	c.Assert(err, ErrorMatches, "cannot enroll network keys: cannot bind key to http://tang.example.com: boom")
+}
+
 func (s *makeBootable20Suite) testMakeSystemRunnable20WithCustomKernelArgs(c *C, whichFile, content, errMsg string, cmdlines map[string]string) {
 	s.testMakeSystemRunnable20WithCustomKernelAndSnapdArgs(c, whichFile, content, "", errMsg, cmdlines)
 }
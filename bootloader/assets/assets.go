@@ -20,7 +20,10 @@
 package assets
 
 import (
+	"bytes"
+	"compress/gzip"
 	"fmt"
+	"io"
 	"sort"
 
 	"github.com/snapcore/snapd/osutil"
@@ -28,6 +31,10 @@ import (
 
 var registeredAssets = map[string][]byte{}
 
+// registeredSets maps the name of an asset set to the names of the assets
+// it carries.
+var registeredSets = map[string][]string{}
+
 // ForEditions wraps a snippet that is used in editions starting with
 // FirstEdition.
 type ForEditions struct {
@@ -53,6 +60,68 @@ func Internal(name string) []byte {
 	return registeredAssets[name]
 }
 
+func decompress(data []byte, compression string) ([]byte, error) {
+	switch compression {
+	case "":
+		return data, nil
+	case "gzip":
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return io.ReadAll(r)
+	default:
+		return nil, fmt.Errorf("unsupported compression %q", compression)
+	}
+}
+
+// registerInternalCompressed registers an internal asset under the given
+// name, its data being compressed with the given compression.
+func registerInternalCompressed(name, compression string, data []byte) {
+	decompressed, err := decompress(data, compression)
+	if err != nil {
+		panic(fmt.Sprintf("cannot decompress asset %q: %v", name, err))
+	}
+	registerInternal(name, decompressed)
+}
+
+// internalSetFile is a file carried by a set of internal assets.
+type internalSetFile struct {
+	Name string
+	Data []byte
+}
+
+// registerInternalSet registers the files of a set of internal assets, each
+// under <set name>/<file name>, their data being compressed with the given
+// compression.
+func registerInternalSet(name, compression string, files []internalSetFile) {
+	if _, ok := registeredSets[name]; ok {
+		panic(fmt.Sprintf("asset set %q is already registered", name))
+	}
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		registerInternalCompressed(name+"/"+f.Name, compression, f.Data)
+		names = append(names, f.Name)
+	}
+	registeredSets[name] = names
+}
+
+// InternalSet returns the contents of the internal assets of the set
+// registered under the given name, keyed by their file names, or nil when
+// none was found.
+func InternalSet(name string) map[string][]byte {
+	names, ok := registeredSets[name]
+	if !ok {
+		return nil
+	}
+	set := make(map[string][]byte, len(names))
+	for _, n := range names {
+		set[n] = registeredAssets[name+"/"+n]
+	}
+	return set
+}
+
 type byFirstEdition []ForEditions
 
 func (b byFirstEdition) Len() int           { return len(b) }
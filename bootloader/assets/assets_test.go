@@ -20,6 +20,9 @@
 package assets_test
 
 import (
+	"bytes"
+	"compress/gzip"
+
 	. "gopkg.in/check.v1"
 
 	"github.com/snapcore/snapd/bootloader/assets"
@@ -62,6 +65,49 @@ func (s *assetsTestSuite) TestRegisterDoublePanics(c *C) {
 		PanicMatches, `asset "foo" is already registered`)
 }
 
+func gzipped(c *C, data string) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write([]byte(data))
+	c.Assert(err, IsNil)
+	c.Assert(w.Close(), IsNil)
+	return buf.Bytes()
+}
+
+func (s *assetsTestSuite) TestRegisterInternalCompressed(c *C) {
+	assets.RegisterInternalCompressed("foo", "gzip", gzipped(c, "bar"))
+	c.Check(assets.Internal("foo"), DeepEquals, []byte("bar"))
+
+	assets.RegisterInternalCompressed("plain", "", []byte("plain data"))
+	c.Check(assets.Internal("plain"), DeepEquals, []byte("plain data"))
+
+	c.Assert(func() { assets.RegisterInternalCompressed("foo", "gzip", gzipped(c, "bar")) },
+		PanicMatches, `asset "foo" is already registered`)
+	c.Assert(func() { assets.RegisterInternalCompressed("broken", "gzip", []byte("this is not gzip data")) },
+		PanicMatches, `cannot decompress asset "broken": gzip: invalid header`)
+	c.Assert(func() { assets.RegisterInternalCompressed("lzma", "lzma", []byte("data")) },
+		PanicMatches, `cannot decompress asset "lzma": unsupported compression "lzma"`)
+}
+
+func (s *assetsTestSuite) TestRegisterInternalSet(c *C) {
+	assets.RegisterInternalSet("set", "gzip", []assets.InternalSetFile{
+		{Name: "one", Data: gzipped(c, "one data")},
+		{Name: "two", Data: gzipped(c, "two data")},
+	})
+	c.Check(assets.InternalSet("set"), DeepEquals, map[string][]byte{
+		"one": []byte("one data"),
+		"two": []byte("two data"),
+	})
+	// each file is also available on its own
+	c.Check(assets.Internal("set/one"), DeepEquals, []byte("one data"))
+	c.Check(assets.Internal("set/two"), DeepEquals, []byte("two data"))
+
+	c.Check(assets.InternalSet("no set"), IsNil)
+
+	c.Assert(func() { assets.RegisterInternalSet("set", "", nil) },
+		PanicMatches, `asset set "set" is already registered`)
+}
+
 func (s *assetsTestSuite) TestRegisterSnippetPanics(c *C) {
 	assets.RegisterSnippetForEditions("foo", []assets.ForEditions{
 		{FirstEdition: 1, Snippet: []byte("foo")},
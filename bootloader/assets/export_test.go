@@ -21,17 +21,24 @@ package assets
 
 var (
 	RegisterInternal           = registerInternal
+	RegisterInternalCompressed = registerInternalCompressed
+	RegisterInternalSet        = registerInternalSet
 	RegisterSnippetForEditions = registerSnippetForEditions
 	RegisterGrubSnippets       = registerGrubSnippets
 )
 
+type InternalSetFile = internalSetFile
+
 func MockCleanState() (restore func()) {
 	oldRegisteredAssets := registeredAssets
 	oldRegisteredEditionAssets := registeredEditionSnippets
+	oldRegisteredSets := registeredSets
 	registeredAssets = map[string][]byte{}
 	registeredEditionSnippets = map[string][]ForEditions{}
+	registeredSets = map[string][]string{}
 	return func() {
 		registeredAssets = oldRegisteredAssets
 		registeredEditionSnippets = oldRegisteredEditionAssets
+		registeredSets = oldRegisteredSets
 	}
 }
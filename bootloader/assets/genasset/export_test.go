@@ -23,10 +23,16 @@ var (
 	ParseArgs   = parseArgs
 	Run         = run
 	FormatLines = formatLines
+	Compress    = compress
 )
 
+func InputFiles() []string {
+	return inFiles
+}
+
 func ResetArgs() {
-	*inFile = ""
+	inFiles = nil
 	*outFile = ""
 	*assetName = ""
+	*compression = ""
 }
@@ -21,11 +21,14 @@ package main
 
 import (
 	"bytes"
+	"compress/gzip"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"text/template"
 	"time"
 
@@ -56,16 +59,75 @@ package assets
 // Code generated from {{ .InputFileName }} DO NOT EDIT
 
 func init() {
+{{- if .Compression }}
+	registerInternalCompressed("{{ .AssetName }}", "{{ .Compression }}", []byte{
+{{- else }}
 	registerInternal("{{ .AssetName }}", []byte{
+{{- end }}
 {{ range .AssetDataLines }}		{{ . }}
 {{ end }}	})
 }
 `
 
-var inFile = flag.String("in", "", "asset input file")
+var assetSetTemplateText = `// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2022 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package assets
+
+// Code generated from {{ .InputFileName }} DO NOT EDIT
+
+func init() {
+	registerInternalSet("{{ .AssetName }}", "{{ .Compression }}", []internalSetFile{
+{{- range .Files }}
+		{
+			Name: "{{ .Name }}",
+			Data: []byte{
+{{ range .DataLines }}				{{ . }}
+{{ end }}			},
+		},
+{{- end }}
+	})
+}
+`
+
+// inputFiles collects the files given with repeated -in flags.
+type inputFiles []string
+
+func (i *inputFiles) String() string {
+	return strings.Join(*i, ",")
+}
+
+func (i *inputFiles) Set(value string) error {
+	*i = append(*i, value)
+	return nil
+}
+
+var inFiles inputFiles
 var outFile = flag.String("out", "", "asset output file")
 var assetName = flag.String("name", "", "asset name")
+var compression = flag.String("compress", "", "compress the asset data, with gzip")
 var assetTemplate = template.Must(template.New("asset").Parse(assetTemplateText))
+var assetSetTemplate = template.Must(template.New("asset-set").Parse(assetSetTemplateText))
+
+func init() {
+	flag.Var(&inFiles, "in", "asset input file, repeat to generate a set of assets")
+}
 
 // formatLines generates a list of strings, each carrying a line containing hex
 // encoded data
@@ -93,16 +155,80 @@ func formatLines(data []byte) []string {
 	return lines
 }
 
-func run(assetName, inputFile, outputFile string) error {
+// compress returns the data compressed with the given compression, which
+// must be one the assets package can decompress at registration time.
+func compress(data []byte, compression string) ([]byte, error) {
+	switch compression {
+	case "":
+		return data, nil
+	case "gzip":
+		var buf bytes.Buffer
+		// the header carries no name nor modification time, so
+		// that the output is reproducible
+		w, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unsupported compression %q", compression)
+	}
+}
+
+func readInput(inputFile, compression string) ([]byte, error) {
 	inf, err := os.Open(inputFile)
 	if err != nil {
-		return fmt.Errorf("cannot open input file: %v", err)
+		return nil, fmt.Errorf("cannot open input file: %v", err)
 	}
 	defer inf.Close()
 
 	var inData bytes.Buffer
 	if _, err := io.Copy(&inData, inf); err != nil {
-		return fmt.Errorf("cannot copy input data: %v", err)
+		return nil, fmt.Errorf("cannot copy input data: %v", err)
+	}
+	data, err := compress(inData.Bytes(), compression)
+	if err != nil {
+		return nil, fmt.Errorf("cannot compress input data: %v", err)
+	}
+	return data, nil
+}
+
+type setFile struct {
+	Name      string
+	DataLines []string
+}
+
+func run(assetName string, inputFiles []string, outputFile, compression string) error {
+	// dealing with precise formatting in template is annoying thus
+	// we use a preformatted lines carrying asset data
+	var assetDataLines []string
+	var files []setFile
+	if len(inputFiles) == 1 {
+		data, err := readInput(inputFiles[0], compression)
+		if err != nil {
+			return err
+		}
+		assetDataLines = formatLines(data)
+	} else {
+		seen := make(map[string]bool, len(inputFiles))
+		for _, inputFile := range inputFiles {
+			name := filepath.Base(inputFile)
+			if seen[name] {
+				return fmt.Errorf("cannot use input file %q: duplicate name %q in the asset set", inputFile, name)
+			}
+			seen[name] = true
+			data, err := readInput(inputFile, compression)
+			if err != nil {
+				return err
+			}
+			files = append(files, setFile{Name: name, DataLines: formatLines(data)})
+		}
 	}
 
 	outf, err := osutil.NewAtomicFile(outputFile, 0644, 0, osutil.NoChown, osutil.NoChown)
@@ -115,20 +241,26 @@ func run(assetName, inputFile, outputFile string) error {
 		Comment        string
 		InputFileName  string
 		AssetName      string
+		Compression    string
 		AssetDataLines []string
+		Files          []setFile
 		Year           string
 	}{
-		InputFileName: inputFile,
-		// dealing with precise formatting in template is annoying thus
-		// we use a preformatted lines carrying asset data
-		AssetDataLines: formatLines(inData.Bytes()),
+		InputFileName:  strings.Join(inputFiles, ", "),
+		AssetDataLines: assetDataLines,
+		Files:          files,
 		AssetName:      assetName,
+		Compression:    compression,
 		// XXX: The year is currently not used because it leads
 		//      to spurious changes every year. Once we use something
 		//      like real build-system we can re-enable this
 		Year: strconv.Itoa(time.Now().Year()),
 	}
-	if err := assetTemplate.Execute(outf, &templateData); err != nil {
+	tmpl := assetTemplate
+	if len(inputFiles) > 1 {
+		tmpl = assetSetTemplate
+	}
+	if err := tmpl.Execute(outf, &templateData); err != nil {
 		return fmt.Errorf("cannot generate content: %v", err)
 	}
 	return outf.Commit()
@@ -136,7 +268,7 @@ func run(assetName, inputFile, outputFile string) error {
 
 func parseArgs() error {
 	flag.Parse()
-	if *inFile == "" {
+	if len(inFiles) == 0 {
 		return fmt.Errorf("input file not provided")
 	}
 	if *outFile == "" {
@@ -145,6 +277,11 @@ func parseArgs() error {
 	if *assetName == "" {
 		return fmt.Errorf("asset name not provided")
 	}
+	switch *compression {
+	case "", "gzip":
+	default:
+		return fmt.Errorf("unsupported compression %q", *compression)
+	}
 	return nil
 }
 
@@ -153,7 +290,7 @@ func main() {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
-	if err := run(*assetName, *inFile, *outFile); err != nil {
+	if err := run(*assetName, inFiles, *outFile, *compression); err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
 	}
@@ -21,7 +21,9 @@ package main_test
 
 import (
 	"bytes"
+	"compress/gzip"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -74,6 +76,19 @@ func (s *generateAssetsTestSuite) TestArgs(c *C) {
 	restore = mockArgs([]string{"self", "-in", "in", "-out", "out"})
 	defer restore()
 	c.Assert(generate.ParseArgs(), ErrorMatches, "asset name not provided")
+	// unsupported compression
+	generate.ResetArgs()
+	restore = mockArgs([]string{"self", "-in", "in", "-out", "out", "-name", "assetname", "-compress", "lzma"})
+	defer restore()
+	c.Assert(generate.ParseArgs(), ErrorMatches, `unsupported compression "lzma"`)
+}
+
+func (s *generateAssetsTestSuite) TestArgsMultipleInputs(c *C) {
+	generate.ResetArgs()
+	restore := mockArgs([]string{"self", "-in", "one", "-in", "two", "-out", "ok", "-name", "assetname", "-compress", "gzip"})
+	defer restore()
+	c.Assert(generate.ParseArgs(), IsNil)
+	c.Check(generate.InputFiles(), DeepEquals, []string{"one", "two"})
 }
 
 func (s *generateAssetsTestSuite) TestSimpleAsset(c *C) {
@@ -81,7 +96,7 @@ func (s *generateAssetsTestSuite) TestSimpleAsset(c *C) {
 	err := os.WriteFile(filepath.Join(d, "in"), []byte("this is a\n"+
 		"multiline asset \"'``\nwith chars\n"), 0644)
 	c.Assert(err, IsNil)
-	err = generate.Run("asset-name", filepath.Join(d, "in"), filepath.Join(d, "out"))
+	err = generate.Run("asset-name", []string{filepath.Join(d, "in")}, filepath.Join(d, "out"), "")
 	c.Assert(err, IsNil)
 	data, err := os.ReadFile(filepath.Join(d, "out"))
 	c.Assert(err, IsNil)
@@ -130,7 +145,7 @@ func (s *generateAssetsTestSuite) TestGoFmtClean(c *C) {
 	err = os.WriteFile(filepath.Join(d, "in"), []byte("this is a\n"+
 		"multiline asset \"'``\nuneven chars\n"), 0644)
 	c.Assert(err, IsNil)
-	err = generate.Run("asset-name", filepath.Join(d, "in"), filepath.Join(d, "out"))
+	err = generate.Run("asset-name", []string{filepath.Join(d, "in")}, filepath.Join(d, "out"), "")
 	c.Assert(err, IsNil)
 
 	cmd := exec.Command("gofmt", "-l", "-d", filepath.Join(d, "out"))
@@ -141,16 +156,113 @@ func (s *generateAssetsTestSuite) TestGoFmtClean(c *C) {
 
 func (s *generateAssetsTestSuite) TestRunErrors(c *C) {
 	d := c.MkDir()
-	err := generate.Run("asset-name", filepath.Join(d, "missing"), filepath.Join(d, "out"))
+	err := generate.Run("asset-name", []string{filepath.Join(d, "missing")}, filepath.Join(d, "out"), "")
 	c.Assert(err, ErrorMatches, "cannot open input file: open .*/missing: no such file or directory")
 
 	err = os.WriteFile(filepath.Join(d, "in"), []byte("this is a\n"+
 		"multiline asset \"'``\nuneven chars\n"), 0644)
 	c.Assert(err, IsNil)
 
-	err = generate.Run("asset-name", filepath.Join(d, "in"), filepath.Join(d, "does-not-exist", "out"))
+	err = generate.Run("asset-name", []string{filepath.Join(d, "in")}, filepath.Join(d, "does-not-exist", "out"), "")
 	c.Assert(err, ErrorMatches, `cannot open output file: open .*/does-not-exist/out\..*: no such file or directory`)
 
+	err = generate.Run("asset-name", []string{filepath.Join(d, "in")}, filepath.Join(d, "out"), "lzma")
+	c.Assert(err, ErrorMatches, `cannot compress input data: unsupported compression "lzma"`)
+
+	c.Assert(os.MkdirAll(filepath.Join(d, "other"), 0755), IsNil)
+	err = os.WriteFile(filepath.Join(d, "other", "in"), []byte("other"), 0644)
+	c.Assert(err, IsNil)
+	err = generate.Run("asset-name", []string{filepath.Join(d, "in"), filepath.Join(d, "other", "in")}, filepath.Join(d, "out"), "")
+	c.Assert(err, ErrorMatches, `cannot use input file ".*/other/in": duplicate name "in" in the asset set`)
+}
+
+func decompressed(c *C, data []byte) []byte {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	c.Assert(err, IsNil)
+	defer r.Close()
+	out, err := io.ReadAll(r)
+	c.Assert(err, IsNil)
+	return out
+}
+
+func (s *generateAssetsTestSuite) TestCompressedAsset(c *C) {
+	d := c.MkDir()
+	in := bytes.Repeat([]byte("a compressible asset\n"), 100)
+	err := os.WriteFile(filepath.Join(d, "in"), in, 0644)
+	c.Assert(err, IsNil)
+	err = generate.Run("asset-name", []string{filepath.Join(d, "in")}, filepath.Join(d, "out"), "gzip")
+	c.Assert(err, IsNil)
+	data, err := os.ReadFile(filepath.Join(d, "out"))
+	c.Assert(err, IsNil)
+
+	c.Check(string(data), testutil.Contains, fmt.Sprintf(`// Code generated from %s DO NOT EDIT
+
+func init() {
+	registerInternalCompressed("asset-name", "gzip", []byte{
+		0x1f, 0x8b,`, filepath.Join(d, "in")))
+
+	compressed, err := generate.Compress(in, "gzip")
+	c.Assert(err, IsNil)
+	c.Check(len(compressed) < len(in), Equals, true)
+	c.Check(decompressed(c, compressed), DeepEquals, in)
+	// the output is reproducible
+	again, err := generate.Compress(in, "gzip")
+	c.Assert(err, IsNil)
+	c.Check(again, DeepEquals, compressed)
+}
+
+func (s *generateAssetsTestSuite) TestAssetSet(c *C) {
+	d := c.MkDir()
+	err := os.WriteFile(filepath.Join(d, "one"), []byte("one"), 0644)
+	c.Assert(err, IsNil)
+	err = os.WriteFile(filepath.Join(d, "two"), []byte("two"), 0644)
+	c.Assert(err, IsNil)
+	err = generate.Run("asset-set", []string{filepath.Join(d, "one"), filepath.Join(d, "two")}, filepath.Join(d, "out"), "")
+	c.Assert(err, IsNil)
+	data, err := os.ReadFile(filepath.Join(d, "out"))
+	c.Assert(err, IsNil)
+
+	const exp = `// Code generated from %s, %s DO NOT EDIT
+
+func init() {
+	registerInternalSet("asset-set", "", []internalSetFile{
+		{
+			Name: "one",
+			Data: []byte{
+				0x6f, 0x6e, 0x65,
+			},
+		},
+		{
+			Name: "two",
+			Data: []byte{
+				0x74, 0x77, 0x6f,
+			},
+		},
+	})
+}
+`
+	c.Check(string(data), testutil.Contains, fmt.Sprintf(exp, filepath.Join(d, "one"), filepath.Join(d, "two")))
+}
+
+func (s *generateAssetsTestSuite) TestGoFmtCleanCompressedSet(c *C) {
+	_, err := exec.LookPath("gofmt")
+	if err != nil {
+		c.Skip("gofmt is missing")
+	}
+
+	d := c.MkDir()
+	err = os.WriteFile(filepath.Join(d, "one"), []byte("this is a\n"+
+		"multiline asset \"'``\nuneven chars\n"), 0644)
+	c.Assert(err, IsNil)
+	err = os.WriteFile(filepath.Join(d, "two"), []byte("another asset\n"), 0644)
+	c.Assert(err, IsNil)
+	err = generate.Run("asset-set", []string{filepath.Join(d, "one"), filepath.Join(d, "two")}, filepath.Join(d, "out"), "gzip")
+	c.Assert(err, IsNil)
+
+	cmd := exec.Command("gofmt", "-l", "-d", filepath.Join(d, "out"))
+	out, err := cmd.CombinedOutput()
+	c.Assert(err, IsNil)
+	c.Assert(out, HasLen, 0, Commentf("output file is not gofmt clean: %s", string(out)))
 }
 
 func (s *generateAssetsTestSuite) TestFormatLines(c *C) {
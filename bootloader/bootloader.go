@@ -363,6 +363,7 @@ var (
 		newAndroidBoot,
 		newLk,
 		newPiboot,
+		newSystemdBoot,
 	}
 )
 
@@ -320,6 +320,31 @@ func LayoutKernelAssetsToDir(b Bootloader, snapf snap.Container, dstDir string)
 	return p.layoutKernelAssetsToDir(snapf, dstDir)
 }
 
+func NewSystemdBoot(rootdir string, opts *Options) ExtractedRecoveryKernelImageBootloader {
+	return newSystemdBoot(rootdir, opts).(ExtractedRecoveryKernelImageBootloader)
+}
+
+func MockSystemdBootFiles(c *C, rootdir string, blOpts *Options) func() {
+	oldSeedPartDir := ubuntuSeedDir
+	ubuntuSeedDir = rootdir
+
+	sb := &systemdBoot{rootdir: rootdir}
+	sb.setDefaults()
+	sb.processBlOpts(blOpts)
+	err := os.MkdirAll(sb.dir(), 0755)
+	c.Assert(err, IsNil)
+
+	// ensure that we have a valid loader.env
+	err = os.WriteFile(sb.envFile(), nil, 0644)
+	c.Assert(err, IsNil)
+
+	return func() { ubuntuSeedDir = oldSeedPartDir }
+}
+
+func SystemdBootEnvFile(b Bootloader) string {
+	return b.(*systemdBoot).envFile()
+}
+
 func NewUbootPart(rootdir string, blOpts *Options) ExtractedRecoveryKernelImageBootloader {
 	return newUbootPart(rootdir, blOpts).(ExtractedRecoveryKernelImageBootloader)
 }
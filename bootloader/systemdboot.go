@@ -0,0 +1,355 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package bootloader
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/snapcore/snapd/bootloader/androidbootenv"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/strutil"
+)
+
+// ensure systemdBoot implements the required interfaces
+var (
+	_ Bootloader                             = (*systemdBoot)(nil)
+	_ ExtractedRecoveryKernelImageBootloader = (*systemdBoot)(nil)
+	_ NotScriptableBootloader                = (*systemdBoot)(nil)
+	_ RecoveryBootConfigBootloader           = (*systemdBoot)(nil)
+)
+
+const (
+	systemdBootEnvFilename    = "loader.env"
+	systemdBootConfigFilename = "loader.conf"
+	systemdBootPartFolder     = "/loader/"
+	systemdBootKernelsFolder  = "/EFI/ubuntu/"
+
+	// entries written by snapd are selected with "default snapd-*",
+	// systemd-boot sorts them by file name in descending order, so
+	// that the entry of a kernel being tried comes first, as long as
+	// it has boot tries left
+	systemdBootEntryPrefix   = "snapd-"
+	systemdBootRunEntry      = "snapd-run.conf"
+	systemdBootTryEntry      = "snapd-try+1.conf"
+	systemdBootRecoveryEntry = "snapd-recovery.conf"
+
+	// same static arguments as the grub boot config
+	systemdBootStaticCmdline = "console=ttyS0 console=tty1 panic=-1"
+)
+
+// systemdBoot implements support for systemd-boot on EFI systems, as
+// used by hybrid classic and core images. As systemd-boot cannot
+// modify its configuration, snapd keeps its variables in an
+// environment file and generates Boot Loader Specification entries
+// from them whenever they affect what is booted. A new kernel is
+// tried using the boot counting of systemd-boot: its entry has a
+// single boot try, and once it is used up systemd-boot falls back to
+// the entry of the current kernel.
+type systemdBoot struct {
+	rootdir          string
+	basedir          string
+	prepareImageTime bool
+}
+
+func (sb *systemdBoot) setDefaults() {
+	sb.basedir = "/boot/efi/loader/"
+}
+
+func (sb *systemdBoot) processBlOpts(blOpts *Options) {
+	if blOpts == nil {
+		return
+	}
+
+	sb.prepareImageTime = blOpts.PrepareImageTime
+	switch {
+	case blOpts.Role == RoleRecovery || blOpts.NoSlashBoot:
+		if !blOpts.PrepareImageTime {
+			sb.rootdir = ubuntuSeedDir
+		}
+		// systemd-boot and its configuration live on the ESP,
+		// which is ubuntu-seed, for all roles
+		sb.basedir = systemdBootPartFolder
+	}
+}
+
+// newSystemdBoot creates a new systemd-boot bootloader object
+func newSystemdBoot(rootdir string, blOpts *Options) Bootloader {
+	sb := &systemdBoot{
+		rootdir: rootdir,
+	}
+	sb.setDefaults()
+	sb.processBlOpts(blOpts)
+	return sb
+}
+
+func (sb *systemdBoot) Name() string {
+	return "systemd-boot"
+}
+
+func (sb *systemdBoot) dir() string {
+	if sb.rootdir == "" {
+		panic("internal error: unset rootdir")
+	}
+	return filepath.Join(sb.rootdir, sb.basedir)
+}
+
+// espDir returns the root of the partition systemd-boot is loaded from,
+// paths in the boot entries are relative to it.
+func (sb *systemdBoot) espDir() string {
+	return filepath.Dir(filepath.Clean(sb.dir()))
+}
+
+func (sb *systemdBoot) envFile() string {
+	return filepath.Join(sb.dir(), systemdBootEnvFilename)
+}
+
+func (sb *systemdBoot) entriesDir() string {
+	return filepath.Join(sb.dir(), "entries")
+}
+
+func (sb *systemdBoot) loadEnv() (*androidbootenv.Env, error) {
+	env := androidbootenv.NewEnv(sb.envFile())
+	if err := env.Load(); err != nil {
+		return nil, fmt.Errorf("cannot open systemd-boot environment: %v", err)
+	}
+	return env, nil
+}
+
+// systemd-boot enabled if env file exists
+func (sb *systemdBoot) Present() (bool, error) {
+	return osutil.FileExists(sb.envFile()), nil
+}
+
+func (sb *systemdBoot) GetBootVars(names ...string) (map[string]string, error) {
+	env, err := sb.loadEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]string, len(names))
+	for _, name := range names {
+		out[name] = env.Get(name)
+	}
+
+	return out, nil
+}
+
+// setBootVars sets the given variables in the environment and returns
+// whether boot entries need to be generated again.
+func (sb *systemdBoot) setBootVars(env *androidbootenv.Env, values map[string]string) (reconfigure bool, err error) {
+	dirtyEnv := false
+	for k, v := range values {
+		// already set to the right value, nothing to do
+		if env.Get(k) == v {
+			continue
+		}
+		env.Set(k, v)
+		dirtyEnv = true
+		switch k {
+		case "snapd_recovery_mode", "snapd_recovery_system", "kernel_status",
+			"snap_kernel", "snap_try_kernel",
+			"snapd_extra_cmdline_args", "snapd_full_cmdline_args":
+			reconfigure = true
+		}
+	}
+
+	if dirtyEnv {
+		if err := env.Save(); err != nil {
+			return false, err
+		}
+	}
+	return reconfigure, nil
+}
+
+func (sb *systemdBoot) SetBootVars(values map[string]string) error {
+	env, err := sb.loadEnv()
+	if err != nil {
+		return err
+	}
+
+	reconfigure, err := sb.setBootVars(env, values)
+	if err != nil {
+		return err
+	}
+	if reconfigure {
+		return sb.writeEntries(env)
+	}
+	return nil
+}
+
+func (sb *systemdBoot) SetBootVarsFromInitramfs(values map[string]string) error {
+	env, err := sb.loadEnv()
+	if err != nil {
+		return err
+	}
+
+	// boot entries are left alone, a kernel being tried keeps its entry
+	// until snapd decides about the outcome of the try
+	_, err = sb.setBootVars(env, values)
+	return err
+}
+
+func (sb *systemdBoot) Reconfigure() error {
+	env, err := sb.loadEnv()
+	if err != nil {
+		return err
+	}
+	return sb.writeEntries(env)
+}
+
+// cmdline returns the kernel command line for the given mode arguments,
+// using the command line arguments from the environment, if any.
+func (sb *systemdBoot) cmdline(env *androidbootenv.Env, modeArgs ...string) string {
+	args := strings.TrimSpace(env.Get("snapd_full_cmdline_args"))
+	if args == "" {
+		args = strutil.JoinNonEmpty([]string{systemdBootStaticCmdline, env.Get("snapd_extra_cmdline_args")}, " ")
+	}
+	return strutil.JoinNonEmpty(append(modeArgs, args), " ")
+}
+
+func writeSystemdBootEntry(path, title, kernelEfi, options string) error {
+	entry := fmt.Sprintf("title %s\nefi %s\noptions %s\n", title, kernelEfi, options)
+	return osutil.AtomicWriteFile(path, []byte(entry), 0644, 0)
+}
+
+// writeEntries generates the boot entries matching the environment,
+// removing any other entry previously generated by snapd.
+func (sb *systemdBoot) writeEntries(env *androidbootenv.Env) error {
+	entriesDir := sb.entriesDir()
+	if err := os.MkdirAll(entriesDir, 0755); err != nil {
+		return err
+	}
+	// boot counting renames the entry of a tried kernel, so match
+	// all entries written by snapd
+	oldEntries, err := filepath.Glob(filepath.Join(entriesDir, systemdBootEntryPrefix+"*.conf"))
+	if err != nil {
+		return err
+	}
+	for _, old := range oldEntries {
+		if err := os.Remove(old); err != nil {
+			return err
+		}
+	}
+
+	mode := env.Get("snapd_recovery_mode")
+	if mode != "run" {
+		// install/recovery modes, use recovery kernel
+		system := env.Get("snapd_recovery_system")
+		kernelEfi := filepath.Join("/systems", system, "kernel", "kernel.efi")
+		logger.Debugf("configure systemd-boot for %s mode with %s", mode, kernelEfi)
+		return writeSystemdBootEntry(filepath.Join(entriesDir, systemdBootRecoveryEntry),
+			fmt.Sprintf("Ubuntu Core recovery system %s", system), kernelEfi,
+			sb.cmdline(env, "snapd_recovery_mode="+mode, "snapd_recovery_system="+system))
+	}
+
+	kernelEfi := filepath.Join(systemdBootKernelsFolder, env.Get("snap_kernel"), "kernel.efi")
+	logger.Debugf("configure systemd-boot for run mode with %s", kernelEfi)
+	if err := writeSystemdBootEntry(filepath.Join(entriesDir, systemdBootRunEntry),
+		"Ubuntu Core", kernelEfi, sb.cmdline(env, "snapd_recovery_mode=run")); err != nil {
+		return err
+	}
+	if env.Get("kernel_status") != "try" {
+		return nil
+	}
+	// Signal when we are trying a new kernel
+	tryKernelEfi := filepath.Join(systemdBootKernelsFolder, env.Get("snap_try_kernel"), "kernel.efi")
+	logger.Debugf("configure systemd-boot to try %s", tryKernelEfi)
+	return writeSystemdBootEntry(filepath.Join(entriesDir, systemdBootTryEntry),
+		"Ubuntu Core (trying new kernel)", tryKernelEfi,
+		sb.cmdline(env, "snapd_recovery_mode=run", "kernel_status=trying"))
+}
+
+// writeLoaderConfig writes loader.conf from the given configuration,
+// making the entries generated by snapd the default ones.
+func (sb *systemdBoot) writeLoaderConfig(config []byte) error {
+	var lines []string
+	for _, line := range strings.Split(string(config), "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 && fields[0] == "default" {
+			logger.Noticef("ignoring %q in systemd-boot configuration", line)
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	lines = append(lines, "default "+systemdBootEntryPrefix+"*", "")
+	return osutil.AtomicWriteFile(filepath.Join(sb.dir(), systemdBootConfigFilename),
+		[]byte(strings.Join(lines, "\n")), 0644, 0)
+}
+
+func (sb *systemdBoot) InstallBootConfig(gadgetDir string, blOpts *Options) error {
+	if err := os.MkdirAll(sb.entriesDir(), 0755); err != nil {
+		return err
+	}
+
+	// the marker file in the gadget is the base of loader.conf
+	config, err := os.ReadFile(filepath.Join(gadgetDir, sb.Name()+".conf"))
+	if err != nil {
+		return err
+	}
+	if err := sb.writeLoaderConfig(config); err != nil {
+		return err
+	}
+
+	// We create an empty env file
+	return androidbootenv.NewEnv(sb.envFile()).Save()
+}
+
+func (sb *systemdBoot) ExtractKernelAssets(s snap.PlaceInfo, snapf snap.Container) error {
+	// Rootdir will point to ubuntu-boot, but we need to put things in
+	// the ESP, which is ubuntu-seed
+	dstDir := filepath.Join(sb.kernelsDir(), s.Filename())
+
+	logger.Debugf("ExtractKernelAssets to %s", dstDir)
+
+	return extractKernelAssetsToBootDir(dstDir, snapf, []string{"kernel.efi"})
+}
+
+func (sb *systemdBoot) ExtractRecoveryKernelAssets(recoverySystemDir string, s snap.PlaceInfo,
+	snapf snap.Container) error {
+	if recoverySystemDir == "" {
+		return fmt.Errorf("internal error: recoverySystemDir unset")
+	}
+
+	recoveryKernelAssetsDir := filepath.Join(sb.espDir(), recoverySystemDir, "kernel")
+	logger.Debugf("ExtractRecoveryKernelAssets to %s", recoveryKernelAssetsDir)
+
+	return extractKernelAssetsToBootDir(recoveryKernelAssetsDir, snapf, []string{"kernel.efi"})
+}
+
+func (sb *systemdBoot) kernelsDir() string {
+	if sb.prepareImageTime {
+		return filepath.Join(sb.espDir(), systemdBootKernelsFolder)
+	}
+	return filepath.Join(ubuntuSeedDir, systemdBootKernelsFolder)
+}
+
+func (sb *systemdBoot) RemoveKernelAssets(s snap.PlaceInfo) error {
+	return removeKernelAssetsFromBootDir(sb.kernelsDir(), s)
+}
+
+func (sb *systemdBoot) RequiredByGadget(gadgetDir string) bool {
+	return checkForBlMarker(sb, gadgetDir)
+}
@@ -0,0 +1,286 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package bootloader_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/bootloader"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snapfile"
+	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type systemdBootTestSuite struct {
+	baseBootenvTestSuite
+}
+
+var _ = Suite(&systemdBootTestSuite{})
+
+func (s *systemdBootTestSuite) runModeSystemdBoot(c *C) bootloader.ExtractedRecoveryKernelImageBootloader {
+	// We need PrepareImageTime due to fixed reference to /run/mnt otherwise
+	opts := bootloader.Options{PrepareImageTime: true,
+		Role: bootloader.RoleRunMode, NoSlashBoot: true}
+	s.AddCleanup(bootloader.MockSystemdBootFiles(c, s.rootdir, &opts))
+	return bootloader.NewSystemdBoot(s.rootdir, &opts)
+}
+
+func (s *systemdBootTestSuite) entry(name string) string {
+	return filepath.Join(s.rootdir, "loader", "entries", name)
+}
+
+func (s *systemdBootTestSuite) checkEntries(c *C, names ...string) {
+	entries, err := filepath.Glob(s.entry("*"))
+	c.Assert(err, IsNil)
+	var found []string
+	for _, e := range entries {
+		found = append(found, filepath.Base(e))
+	}
+	c.Check(found, DeepEquals, names)
+}
+
+func (s *systemdBootTestSuite) TestNewSystemdBoot(c *C) {
+	// no files means bl is not present, but we can still create the bl object
+	sb := bootloader.NewSystemdBoot(s.rootdir, nil)
+	c.Assert(sb, NotNil)
+	c.Assert(sb.Name(), Equals, "systemd-boot")
+
+	present, err := sb.Present()
+	c.Assert(err, IsNil)
+	c.Assert(present, Equals, false)
+
+	// now with files present, the bl is present
+	r := bootloader.MockSystemdBootFiles(c, s.rootdir, nil)
+	defer r()
+	present, err = sb.Present()
+	c.Assert(err, IsNil)
+	c.Assert(present, Equals, true)
+}
+
+func (s *systemdBootTestSuite) TestGetBootloaderWithSystemdBoot(c *C) {
+	r := bootloader.MockSystemdBootFiles(c, s.rootdir, nil)
+	defer r()
+
+	bootloader, err := bootloader.Find(s.rootdir, nil)
+	c.Assert(err, IsNil)
+	c.Assert(bootloader.Name(), Equals, "systemd-boot")
+}
+
+func (s *systemdBootTestSuite) TestGetSetBootVars(c *C) {
+	sb := s.runModeSystemdBoot(c)
+
+	err := sb.SetBootVars(map[string]string{
+		"snap_mode": "",
+		"snap_core": "4",
+	})
+	c.Assert(err, IsNil)
+
+	m, err := sb.GetBootVars("snap_mode", "snap_core")
+	c.Assert(err, IsNil)
+	c.Assert(m, DeepEquals, map[string]string{
+		"snap_mode": "",
+		"snap_core": "4",
+	})
+	// nothing affecting the boot entries was set
+	c.Check(osutil.IsDirectory(filepath.Join(s.rootdir, "loader", "entries")), Equals, false)
+}
+
+func (s *systemdBootTestSuite) TestRunModeEntry(c *C) {
+	sb := s.runModeSystemdBoot(c)
+
+	err := sb.SetBootVars(map[string]string{
+		"snapd_recovery_mode": "run",
+		"snap_kernel":         "pc-kernel_1.snap",
+	})
+	c.Assert(err, IsNil)
+
+	s.checkEntries(c, "snapd-run.conf")
+	c.Check(s.entry("snapd-run.conf"), testutil.FileEquals, `title Ubuntu Core
+efi /EFI/ubuntu/pc-kernel_1.snap/kernel.efi
+options snapd_recovery_mode=run console=ttyS0 console=tty1 panic=-1
+`)
+}
+
+func (s *systemdBootTestSuite) TestRunModeEntryCommandLine(c *C) {
+	sb := s.runModeSystemdBoot(c)
+
+	err := sb.SetBootVars(map[string]string{
+		"snapd_recovery_mode":      "run",
+		"snap_kernel":              "pc-kernel_1.snap",
+		"snapd_extra_cmdline_args": "quiet splash",
+	})
+	c.Assert(err, IsNil)
+	c.Check(s.entry("snapd-run.conf"), testutil.FileContains,
+		"options snapd_recovery_mode=run console=ttyS0 console=tty1 panic=-1 quiet splash\n")
+
+	err = sb.SetBootVars(map[string]string{
+		"snapd_extra_cmdline_args": "",
+		"snapd_full_cmdline_args":  "console=ttyS1 quiet",
+	})
+	c.Assert(err, IsNil)
+	c.Check(s.entry("snapd-run.conf"), testutil.FileContains,
+		"options snapd_recovery_mode=run console=ttyS1 quiet\n")
+}
+
+func (s *systemdBootTestSuite) TestTryKernelEntries(c *C) {
+	sb := s.runModeSystemdBoot(c)
+
+	err := sb.SetBootVars(map[string]string{
+		"snapd_recovery_mode": "run",
+		"snap_kernel":         "pc-kernel_1.snap",
+	})
+	c.Assert(err, IsNil)
+
+	// a new kernel is installed
+	err = sb.SetBootVars(map[string]string{
+		"snap_try_kernel": "pc-kernel_2.snap",
+		"kernel_status":   "try",
+	})
+	c.Assert(err, IsNil)
+
+	s.checkEntries(c, "snapd-run.conf", "snapd-try+1.conf")
+	c.Check(s.entry("snapd-run.conf"), testutil.FileContains,
+		"efi /EFI/ubuntu/pc-kernel_1.snap/kernel.efi\n")
+	c.Check(s.entry("snapd-try+1.conf"), testutil.FileEquals, `title Ubuntu Core (trying new kernel)
+efi /EFI/ubuntu/pc-kernel_2.snap/kernel.efi
+options snapd_recovery_mode=run kernel_status=trying console=ttyS0 console=tty1 panic=-1
+`)
+
+	// the initramfs does not touch the entries, which systemd-boot
+	// renamed when counting the boot try
+	err = os.Rename(s.entry("snapd-try+1.conf"), s.entry("snapd-try+0-1.conf"))
+	c.Assert(err, IsNil)
+	nsbl, ok := sb.(bootloader.NotScriptableBootloader)
+	c.Assert(ok, Equals, true)
+	err = nsbl.SetBootVarsFromInitramfs(map[string]string{"kernel_status": "trying"})
+	c.Assert(err, IsNil)
+	s.checkEntries(c, "snapd-run.conf", "snapd-try+0-1.conf")
+
+	// the new kernel is good
+	err = sb.SetBootVars(map[string]string{
+		"snap_kernel":     "pc-kernel_2.snap",
+		"snap_try_kernel": "",
+		"kernel_status":   "",
+	})
+	c.Assert(err, IsNil)
+	s.checkEntries(c, "snapd-run.conf")
+	c.Check(s.entry("snapd-run.conf"), testutil.FileContains,
+		"efi /EFI/ubuntu/pc-kernel_2.snap/kernel.efi\n")
+}
+
+func (s *systemdBootTestSuite) TestRecoveryModeEntry(c *C) {
+	sb := s.runModeSystemdBoot(c)
+
+	err := sb.SetBootVars(map[string]string{
+		"snapd_recovery_mode": "run",
+		"snap_kernel":         "pc-kernel_1.snap",
+	})
+	c.Assert(err, IsNil)
+
+	err = sb.SetBootVars(map[string]string{
+		"snapd_recovery_mode":   "recover",
+		"snapd_recovery_system": "20260101",
+	})
+	c.Assert(err, IsNil)
+
+	s.checkEntries(c, "snapd-recovery.conf")
+	c.Check(s.entry("snapd-recovery.conf"), testutil.FileEquals, `title Ubuntu Core recovery system 20260101
+efi /systems/20260101/kernel/kernel.efi
+options snapd_recovery_mode=recover snapd_recovery_system=20260101 console=ttyS0 console=tty1 panic=-1
+`)
+}
+
+func (s *systemdBootTestSuite) TestReconfigure(c *C) {
+	sb := s.runModeSystemdBoot(c)
+
+	err := sb.SetBootVars(map[string]string{
+		"snapd_recovery_mode":   "install",
+		"snapd_recovery_system": "20260101",
+	})
+	c.Assert(err, IsNil)
+	err = os.Remove(s.entry("snapd-recovery.conf"))
+	c.Assert(err, IsNil)
+
+	rbl, ok := sb.(bootloader.RecoveryBootConfigBootloader)
+	c.Assert(ok, Equals, true)
+	err = rbl.Reconfigure()
+	c.Assert(err, IsNil)
+
+	c.Check(s.entry("snapd-recovery.conf"), testutil.FileContains,
+		"options snapd_recovery_mode=install snapd_recovery_system=20260101 ")
+}
+
+func (s *systemdBootTestSuite) TestInstallBootConfig(c *C) {
+	gadgetDir := c.MkDir()
+	err := os.WriteFile(filepath.Join(gadgetDir, "systemd-boot.conf"), []byte("timeout 3\ndefault foo.conf\n"), 0644)
+	c.Assert(err, IsNil)
+
+	opts := &bootloader.Options{PrepareImageTime: true, Role: bootloader.RoleRecovery}
+	sb := bootloader.NewSystemdBoot(s.rootdir, opts)
+	c.Check(sb.RequiredByGadget(gadgetDir), Equals, true)
+
+	err = bootloader.InstallBootConfig(gadgetDir, s.rootdir, opts)
+	c.Assert(err, IsNil)
+
+	c.Check(filepath.Join(s.rootdir, "loader", "loader.conf"), testutil.FileEquals, "timeout 3\ndefault snapd-*\n")
+	c.Check(bootloader.SystemdBootEnvFile(sb), testutil.FileEquals, "")
+	present, err := sb.Present()
+	c.Assert(err, IsNil)
+	c.Check(present, Equals, true)
+}
+
+func (s *systemdBootTestSuite) TestExtractKernelAssetsAndRemove(c *C) {
+	sb := s.runModeSystemdBoot(c)
+
+	files := [][]string{
+		{"kernel.efi", "I'm a kernel"},
+		{"meta/kernel.yaml", "version: 4.2"},
+	}
+	si := &snap.SideInfo{
+		RealName: "ubuntu-kernel",
+		Revision: snap.R(42),
+	}
+	fn := snaptest.MakeTestSnapWithFiles(c, packageKernel, files)
+	snapf, err := snapfile.Open(fn)
+	c.Assert(err, IsNil)
+
+	info, err := snap.ReadInfoFromSnapFile(snapf, si)
+	c.Assert(err, IsNil)
+
+	err = sb.ExtractKernelAssets(info, snapf)
+	c.Assert(err, IsNil)
+
+	kernelAssetsDir := filepath.Join(s.rootdir, "EFI", "ubuntu", "ubuntu-kernel_42.snap")
+	c.Check(filepath.Join(kernelAssetsDir, "kernel.efi"), testutil.FileEquals, "I'm a kernel")
+
+	err = sb.ExtractRecoveryKernelAssets("systems/20260101", info, snapf)
+	c.Assert(err, IsNil)
+	c.Check(filepath.Join(s.rootdir, "systems", "20260101", "kernel", "kernel.efi"), testutil.FileEquals, "I'm a kernel")
+
+	// remove
+	err = sb.RemoveKernelAssets(info)
+	c.Assert(err, IsNil)
+	c.Check(osutil.FileExists(kernelAssetsDir), Equals, false)
+}
@@ -22,7 +22,6 @@ package client
 import (
 	"bufio"
 	"bytes"
-	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -56,6 +55,15 @@ type AppInfo struct {
 	Active      bool             `json:"active,omitempty"`
 	CommonID    string           `json:"common-id,omitempty"`
 	Activators  []AppActivator   `json:"activators,omitempty"`
+	// Sessions and ActiveSessions are only set for user services when
+	// reporting their global status, and count the user sessions the
+	// status was queried in and the ones running the service.
+	Sessions       int `json:"sessions,omitempty"`
+	ActiveSessions int `json:"active-sessions,omitempty"`
+	// RestartPending is set for services which need a restart to see the
+	// changes of their snap, e.g. to run its new revision after they were
+	// kept running during a refresh.
+	RestartPending bool `json:"restart-pending,omitempty"`
 }
 
 // MarshalJSON marshals the AppActivator in such a way to retain
@@ -169,7 +177,7 @@ func (client *Client) Logs(names []string, opts LogOptions) (<-chan Log, error)
 		query.Set("follow", strconv.FormatBool(opts.Follow))
 	}
 
-	rsp, err := client.raw(context.Background(), "GET", "/v2/logs", query, nil, nil)
+	rsp, err := client.raw(client.context(), "GET", "/v2/logs", query, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -398,18 +406,24 @@ type RestartOptions struct {
 	// Reload the services, if possible (i.e. if the App has a
 	// ReloadCommand, invoque it), instead of restarting.
 	Reload bool `json:"reload,omitempty"`
+	// AllAffected selects the services which need a restart to see the
+	// changes of their snap, instead of named ones.
+	AllAffected bool `json:"all-affected,omitempty"`
 }
 
 // Restart services.
 //
 // It takes a list of names that can be snaps, of which all their
 // services are restarted, or snap.service which are individual
-// services to restart; it shouldn't be empty. If the service is not
-// running, starts it.
+// services to restart; it shouldn't be empty unless opts.AllAffected is
+// set. If the service is not running, starts it.
 func (client *Client) Restart(names []string, scope ScopeSelector, users UserSelector, opts RestartOptions) (changeID string, err error) {
-	if len(names) == 0 {
+	if len(names) == 0 && !opts.AllAffected {
 		return "", ErrNoNames
 	}
+	if len(names) > 0 && opts.AllAffected {
+		return "", fmt.Errorf("cannot restart named services and all affected services at once")
+	}
 
 	buf, err := json.Marshal(appInstruction{
 		Action:         "restart",
@@ -551,6 +551,26 @@ func (cs *clientSuite) TestClientServiceRestart(c *check.C) {
 	}
 }
 
+func (cs *clientSuite) TestClientRestartAllAffected(c *check.C) {
+	cs.status = 202
+	cs.rsp = `{"type": "async", "status-code": 202, "result": {}, "change": "24"}`
+
+	id, err := cs.cli.Restart(nil, nil, client.UserSelector{}, client.RestartOptions{AllAffected: true})
+	c.Assert(err, check.IsNil)
+	c.Check(id, check.Equals, "24")
+	var reqOp map[string]any
+	c.Assert(json.NewDecoder(cs.req.Body).Decode(&reqOp), check.IsNil)
+	c.Check(reqOp["action"], check.Equals, "restart")
+	c.Check(reqOp["all-affected"], check.Equals, true)
+	c.Check(reqOp["names"], check.IsNil)
+}
+
+func (cs *clientSuite) TestClientRestartAllAffectedWithNames(c *check.C) {
+	_, err := cs.cli.Restart([]string{"foo"}, nil, client.UserSelector{}, client.RestartOptions{AllAffected: true})
+	c.Assert(err, check.ErrorMatches, "cannot restart named services and all affected services at once")
+	c.Check(cs.req, check.IsNil)
+}
+
 type userSelectorSuite struct{}
 
 var _ = check.Suite(&userSelectorSuite{})
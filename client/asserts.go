@@ -21,7 +21,6 @@ package client
 
 import (
 	"bytes"
-	"context"
 	"fmt"
 	"io"
 	"net/url"
@@ -82,7 +81,7 @@ func (client *Client) Known(assertTypeName string, headers map[string]string, op
 		q.Set("remote", "true")
 	}
 
-	response, cancel, err := client.rawWithTimeout(context.Background(), "GET", path, q, nil, nil, nil)
+	response, cancel, err := client.rawWithTimeout(client.context(), "GET", path, q, nil, nil, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query assertions: %w", err)
 	}
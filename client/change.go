@@ -21,6 +21,7 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/url"
@@ -122,7 +123,8 @@ type TaskProgress struct {
 
 type changeAndData struct {
 	Change
-	Data map[string]*json.RawMessage `json:"data"`
+	Data     map[string]*json.RawMessage `json:"data"`
+	UpdateID string                      `json:"update-id"`
 }
 
 // Change fetches information about a Change given its ID.
@@ -137,6 +139,68 @@ func (client *Client) Change(id string) (*Change, error) {
 	return &chgd.Change, nil
 }
 
+// changeWatchTimeout is how long the server is asked to wait for an update
+// of a watched change.
+var changeWatchTimeout = 30 * time.Second
+
+// A ChangeWatcher delivers the updates of a change as they happen.
+type ChangeWatcher struct {
+	// PollTime is how long to wait between requests when the server
+	// cannot wait for updates of the change.
+	PollTime time.Duration
+
+	client   *Client
+	ctx      context.Context
+	id       string
+	updateID string
+	fetched  bool
+}
+
+// WatchChange returns a ChangeWatcher for the change with the given ID,
+// performing its requests with the given context.
+func (client *Client) WatchChange(ctx context.Context, id string) *ChangeWatcher {
+	return &ChangeWatcher{
+		PollTime: 100 * time.Millisecond,
+		client:   client.WithContext(ctx),
+		ctx:      ctx,
+		id:       id,
+	}
+}
+
+// Next returns the change as soon as it, or any of its tasks, has been
+// updated since the change was last returned, or after a timeout if it has
+// not. The first call returns the change immediately, as do all calls once
+// the change is ready.
+func (w *ChangeWatcher) Next() (*Change, error) {
+	var query url.Values
+	if w.fetched {
+		if w.updateID == "" {
+			// the server does not support waiting for updates
+			select {
+			case <-time.After(w.PollTime):
+			case <-w.ctx.Done():
+				return nil, ConnectionError{w.ctx.Err()}
+			}
+		} else {
+			query = url.Values{
+				"update-id": []string{w.updateID},
+				"timeout":   []string{changeWatchTimeout.String()},
+			}
+		}
+	}
+
+	var chgd changeAndData
+	_, err := w.client.doSync("GET", "/v2/changes/"+w.id, query, nil, nil, &chgd)
+	if err != nil {
+		return nil, err
+	}
+	w.fetched = true
+	w.updateID = chgd.UpdateID
+
+	chgd.Change.data = chgd.Data
+	return &chgd.Change, nil
+}
+
 // Abort attempts to abort a change that is in not yet ready.
 func (client *Client) Abort(id string) (*Change, error) {
 	var postData struct {
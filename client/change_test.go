@@ -20,7 +20,9 @@
 package client_test
 
 import (
+	"context"
 	"io"
+	"net/url"
 	"time"
 
 	"gopkg.in/check.v1"
@@ -229,6 +231,84 @@ func (cs *clientSuite) TestClientTaskData(c *check.C) {
 	c.Check(n, check.Equals, "")
 }
 
+func (cs *clientSuite) TestClientWatchChange(c *check.C) {
+	restore := client.MockChangeWatchTimeout(time.Minute)
+	defer restore()
+
+	cs.rsps = []string{
+		`{"type": "sync", "result": {"id": "uno", "status": "Do", "update-id": "1"}}`,
+		`{"type": "sync", "result": {"id": "uno", "status": "Doing", "update-id": "4"}}`,
+		`{"type": "sync", "result": {"id": "uno", "status": "Done", "ready": true, "update-id": "7"}}`,
+	}
+
+	w := cs.cli.WatchChange(context.Background(), "uno")
+	var statuses []string
+	for {
+		chg, err := w.Next()
+		c.Assert(err, check.IsNil)
+		c.Check(chg.ID, check.Equals, "uno")
+		statuses = append(statuses, chg.Status)
+		if chg.Ready {
+			break
+		}
+	}
+	c.Check(statuses, check.DeepEquals, []string{"Do", "Doing", "Done"})
+
+	c.Assert(cs.reqs, check.HasLen, 3)
+	for _, req := range cs.reqs {
+		c.Check(req.Method, check.Equals, "GET")
+		c.Check(req.URL.Path, check.Equals, "/v2/changes/uno")
+	}
+	c.Check(cs.reqs[0].URL.RawQuery, check.Equals, "")
+	c.Check(cs.reqs[1].URL.Query(), check.DeepEquals, url.Values{
+		"update-id": []string{"1"},
+		"timeout":   []string{"1m0s"},
+	})
+	c.Check(cs.reqs[2].URL.Query(), check.DeepEquals, url.Values{
+		"update-id": []string{"4"},
+		"timeout":   []string{"1m0s"},
+	})
+}
+
+func (cs *clientSuite) TestClientWatchChangeNoUpdateIDPolls(c *check.C) {
+	// server does not support waiting for updates
+	cs.rsp = `{"type": "sync", "result": {"id": "uno", "status": "Do"}}`
+
+	w := cs.cli.WatchChange(context.Background(), "uno")
+	c.Check(w.PollTime, check.Equals, 100*time.Millisecond)
+	w.PollTime = time.Millisecond
+	for i := 0; i < 2; i++ {
+		_, err := w.Next()
+		c.Assert(err, check.IsNil)
+	}
+	c.Assert(cs.reqs, check.HasLen, 2)
+	c.Check(cs.reqs[1].URL.RawQuery, check.Equals, "")
+}
+
+func (cs *clientSuite) TestClientWatchChangeCanceled(c *check.C) {
+	cs.rsp = `{"type": "sync", "result": {"id": "uno", "status": "Do"}}`
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w := cs.cli.WatchChange(ctx, "uno")
+	w.PollTime = time.Hour
+	_, err := w.Next()
+	c.Assert(err, check.IsNil)
+
+	cancel()
+	_, err = w.Next()
+	c.Check(err, check.ErrorMatches, "cannot communicate with server: request canceled")
+	c.Check(cs.reqs, check.HasLen, 1)
+}
+
+func (cs *clientSuite) TestClientWatchChangeError(c *check.C) {
+	cs.status = 404
+	cs.rsp = `{"type": "error", "status-code": 404, "result": {"message": "cannot find change with id \"uno\""}}`
+
+	w := cs.cli.WatchChange(context.Background(), "uno")
+	_, err := w.Next()
+	c.Check(err, check.ErrorMatches, `cannot find change with id "uno"`)
+}
+
 func (cs *clientSuite) TestClientAbort(c *check.C) {
 	cs.rsp = `{"type": "sync", "result": {
   "id":   "uno",
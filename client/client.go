@@ -37,7 +37,9 @@ import (
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/features"
 	"github.com/snapcore/snapd/httputil"
+	"github.com/snapcore/snapd/i18n"
 	"github.com/snapcore/snapd/jsonutil"
+	"github.com/snapcore/snapd/snap"
 )
 
 func unixDialer(socketPath string) func(string, string) (net.Conn, error) {
@@ -87,10 +89,11 @@ type Client struct {
 	disableAuth bool
 	interactive bool
 
-	maintenance error
+	// ctx is the context requests are performed with, see WithContext
+	ctx context.Context
 
-	warningCount     int
-	warningTimestamp time.Time
+	// status is shared by all clients derived with WithContext
+	*status
 
 	userAgent string
 
@@ -99,6 +102,14 @@ type Client struct {
 	SetMayLogBody func(bool)
 }
 
+// status holds the daemon status reported along with responses.
+type status struct {
+	maintenance error
+
+	warningCount     int
+	warningTimestamp time.Time
+}
+
 // New returns a new instance of Client
 func New(config *Config) *Client {
 	if config == nil {
@@ -137,12 +148,34 @@ func New(config *Config) *Client {
 		disableAuth: config.DisableAuth,
 		interactive: config.Interactive,
 		userAgent:   config.UserAgent,
+		status:      &status{},
 		SetMayLogBody: func(logBody bool) {
 			transport.MayLogBody = logBody
 		},
 	}
 }
 
+// WithContext returns a shallow copy of the client whose requests are
+// performed with the given context, so that they can be canceled or
+// given a deadline. The copy shares the maintenance and warnings status
+// with the original client. The provided ctx must be non-nil.
+func (client *Client) WithContext(ctx context.Context) *Client {
+	if ctx == nil {
+		panic("nil context")
+	}
+	c := *client
+	c.ctx = ctx
+	return &c
+}
+
+// context returns the context requests are performed with.
+func (client *Client) context() context.Context {
+	if client.ctx == nil {
+		return context.Background()
+	}
+	return client.ctx
+}
+
 // Maintenance returns an error reflecting the daemon maintenance status or nil.
 func (client *Client) Maintenance() error {
 	return client.maintenance
@@ -188,13 +221,13 @@ func (client *Client) setAuthorization(req *http.Request) error {
 type RequestError struct{ error }
 
 func (e RequestError) Error() string {
-	return fmt.Sprintf("cannot build request: %v", e.error)
+	return fmt.Sprintf(i18n.G("cannot build request: %v"), e.error)
 }
 
 type AuthorizationError struct{ Err error }
 
 func (e AuthorizationError) Error() string {
-	return fmt.Sprintf("cannot add authorization: %v", e.Err)
+	return fmt.Sprintf(i18n.G("cannot add authorization: %v"), e.Err)
 }
 
 func (e AuthorizationError) Is(target error) bool {
@@ -208,13 +241,13 @@ func (e ConnectionError) Error() string {
 	var errStr string
 	switch e.Err {
 	case context.DeadlineExceeded:
-		errStr = "timeout exceeded while waiting for response"
+		errStr = i18n.G("timeout exceeded while waiting for response")
 	case context.Canceled:
-		errStr = "request canceled"
+		errStr = i18n.G("request canceled")
 	default:
 		errStr = e.Err.Error()
 	}
-	return fmt.Sprintf("cannot communicate with server: %s", errStr)
+	return fmt.Sprintf(i18n.G("cannot communicate with server: %s"), errStr)
 }
 
 func (e ConnectionError) Unwrap() error {
@@ -224,7 +257,7 @@ func (e ConnectionError) Unwrap() error {
 type InternalClientError struct{ Err error }
 
 func (e InternalClientError) Error() string {
-	return fmt.Sprintf("internal error: %s", e.Err.Error())
+	return fmt.Sprintf(i18n.G("internal error: %s"), e.Err.Error())
 }
 
 func (e InternalClientError) Is(target error) bool {
@@ -381,7 +414,7 @@ func (client *Client) do(method, path string, query url.Values, headers map[stri
 	client.checkMaintenanceJSON()
 
 	var rsp *http.Response
-	ctx := context.Background()
+	ctx := client.context()
 	if opts.Timeout <= 0 {
 		// no timeout and retries
 		rsp, err = client.raw(ctx, method, path, query, headers, body)
@@ -410,6 +443,8 @@ func (client *Client) do(method, path string, query url.Values, headers map[stri
 			case <-retry.C:
 				continue
 			case <-timeout.C:
+			case <-ctx.Done():
+				err = ConnectionError{ctx.Err()}
 			}
 			break
 		}
@@ -672,6 +707,42 @@ type RefreshInfo struct {
 	Last     string `json:"last,omitempty"`
 	Hold     string `json:"hold,omitempty"`
 	Next     string `json:"next,omitempty"`
+	// DownloadWindow contains the store.download-window setting.
+	DownloadWindow string `json:"download-window,omitempty"`
+	// DownloadsDeferred is when the downloads of auto-refreshes that
+	// are deferred to the download window start.
+	DownloadsDeferred string `json:"downloads-deferred,omitempty"`
+	// Stagger contains the refresh.stagger setting.
+	Stagger string `json:"stagger,omitempty"`
+	// StaggerOffset is the offset of the next auto-refresh from the
+	// start of its refresh window, when it is not random.
+	StaggerOffset string `json:"stagger-offset,omitempty"`
+	// Backoff lists the snaps whose auto-refreshes are paused because
+	// of previous failed attempts.
+	Backoff []RefreshBackoff `json:"backoff,omitempty"`
+	// Holds lists the refresh holds set on individual snaps.
+	Holds []RefreshHold `json:"holds,omitempty"`
+}
+
+// RefreshHold holds information about a refresh hold set on a snap with
+// "snap refresh --hold".
+type RefreshHold struct {
+	Snap string `json:"snap"`
+	// Level is either "auto-refresh" or "general".
+	Level string `json:"level"`
+	Until string `json:"until"`
+	// Windows restricts the hold to recurring calendar windows, if set.
+	Windows string `json:"windows,omitempty"`
+}
+
+// RefreshBackoff holds information about a snap whose auto-refreshes are
+// paused because of previous failed attempts.
+type RefreshBackoff struct {
+	Snap      string        `json:"snap"`
+	Revision  snap.Revision `json:"revision"`
+	Failures  int           `json:"failures"`
+	LastError string        `json:"last-error,omitempty"`
+	Until     string        `json:"until"`
 }
 
 // SysInfo holds system information
@@ -712,7 +783,7 @@ func (rsp *response) err(cli *Client, statusCode int) error {
 	var resultErr Error
 	err := json.Unmarshal(rsp.Result, &resultErr)
 	if err != nil || resultErr.Message == "" {
-		return fmt.Errorf("server error: %q", http.StatusText(statusCode))
+		return fmt.Errorf(i18n.G("server error: %q"), http.StatusText(statusCode))
 	}
 	resultErr.StatusCode = statusCode
 
@@ -722,7 +793,7 @@ func (rsp *response) err(cli *Client, statusCode int) error {
 func parseError(r *http.Response) error {
 	var rsp response
 	if r.Header.Get("Content-Type") != "application/json" {
-		return fmt.Errorf("server error: %q", r.Status)
+		return fmt.Errorf(i18n.G("server error: %q"), r.Status)
 	}
 
 	dec := json.NewDecoder(r.Body)
@@ -732,7 +803,7 @@ func parseError(r *http.Response) error {
 
 	err := rsp.err(nil, r.StatusCode)
 	if err == nil {
-		return fmt.Errorf("server error: %q", r.Status)
+		return fmt.Errorf(i18n.G("server error: %q"), r.Status)
 	}
 	return err
 }
@@ -806,6 +877,50 @@ func (c *Client) MigrateSnapHome(snaps []string) (changeID string, err error) {
 	return c.doAsync("POST", "/v2/debug", nil, nil, bytes.NewReader(body))
 }
 
+// DiskSpaceUsage is the space a simulated operation needs on a filesystem.
+type DiskSpaceUsage struct {
+	Path       string `json:"path"`
+	Snaps      uint64 `json:"snaps"`
+	DataCopies uint64 `json:"data-copies"`
+	Snapshots  uint64 `json:"snapshots"`
+	Required   uint64 `json:"required"`
+	Available  uint64 `json:"available"`
+}
+
+// DiskSpaceEstimate is the result of simulating an operation on snaps.
+type DiskSpaceEstimate struct {
+	Operation    string           `json:"operation"`
+	Snaps        []string         `json:"snaps"`
+	Filesystems  []DiskSpaceUsage `json:"filesystems"`
+	CheckEnabled bool             `json:"check-enabled"`
+	Refused      bool             `json:"refused"`
+	Error        string           `json:"error,omitempty"`
+}
+
+// SimulateDiskSpace asks snapd for the disk space that installing,
+// refreshing or removing the given snaps would need, without performing
+// the operation.
+func (c *Client) SimulateDiskSpace(op string, snaps []string) (*DiskSpaceEstimate, error) {
+	body, err := json.Marshal(struct {
+		Action string   `json:"action"`
+		Params any      `json:"params"`
+		Snaps  []string `json:"snaps,omitempty"`
+	}{
+		Action: "simulate-disk-space",
+		Params: map[string]string{"operation": op},
+		Snaps:  snaps,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var est DiskSpaceEstimate
+	if _, err := c.doSync("POST", "/v2/debug", nil, nil, bytes.NewReader(body), &est); err != nil {
+		return nil, err
+	}
+	return &est, nil
+}
+
 // DebugRaw allows to make raw queries to the API with the intention of using it
 // from the debug code.
 func (client *Client) DebugRaw(ctx context.Context, method, urlpath string, query url.Values, headers map[string]string, body io.Reader) (*http.Response, error) {
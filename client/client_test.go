@@ -20,6 +20,7 @@
 package client_test
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -39,6 +40,7 @@ import (
 	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/features"
+	"github.com/snapcore/snapd/i18n"
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/testutil"
 )
@@ -57,6 +59,7 @@ type clientSuite struct {
 	err           error
 	doCalls       int
 	header        http.Header
+	trailer       http.Header
 	status        int
 	contentLength int64
 
@@ -78,6 +81,7 @@ func (cs *clientSuite) SetUpTest(c *C) {
 	cs.rsps = nil
 	cs.req = nil
 	cs.header = nil
+	cs.trailer = nil
 	cs.status = 200
 	cs.doCalls = 0
 	cs.contentLength = 0
@@ -110,6 +114,7 @@ func (cs *clientSuite) Do(req *http.Request) (*http.Response, error) {
 	rsp := &http.Response{
 		Body:          cs.countingCloser,
 		Header:        cs.header,
+		Trailer:       cs.trailer,
 		StatusCode:    cs.status,
 		ContentLength: cs.contentLength,
 	}
@@ -132,6 +137,38 @@ func (cs *clientSuite) TestClientDoReportsErrors(c *C) {
 	}
 }
 
+type mockLocale map[string]string
+
+func (l mockLocale) Gettext(msgid string) string {
+	if translated, ok := l[msgid]; ok {
+		return translated
+	}
+	return msgid
+}
+
+func (l mockLocale) NGettext(msgid string, msgidPlural string, n int) string {
+	if n == 1 {
+		return l.Gettext(msgid)
+	}
+	return l.Gettext(msgidPlural)
+}
+
+func (cs *clientSuite) TestClientErrorsAreTranslated(c *C) {
+	cs.err = errors.New("ouchie")
+	_, err := cs.cli.Do("GET", "/", nil, nil, nil, nil)
+	c.Assert(err, NotNil)
+
+	// the message is translated when the error is reported, using the
+	// locale in effect at that time
+	restore := i18n.MockLocale(mockLocale{
+		"cannot communicate with server: %s": "kann nicht mit dem Server kommunizieren: %s",
+		"request canceled":                   "Anfrage abgebrochen",
+	})
+	defer restore()
+	c.Check(err, ErrorMatches, "kann nicht mit dem Server kommunizieren: ouchie")
+	c.Check(client.ConnectionError{Err: context.Canceled}, ErrorMatches, "kann nicht mit dem Server kommunizieren: Anfrage abgebrochen")
+}
+
 func (cs *clientSuite) TestClientWorks(c *C) {
 	var v []int
 	cs.rsp = `[1,2]`
@@ -546,6 +583,64 @@ func (cs *clientSuite) TestClientAsyncOpMaintenance(c *C) {
 	c.Check(cs.cli.Maintenance(), Equals, error(nil))
 }
 
+type ctxKey struct{}
+
+func (cs *clientSuite) TestClientWithContext(c *C) {
+	cs.rsp = `{"type":"sync", "result":{"series":"42"}}`
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+
+	_, err := cs.cli.WithContext(ctx).SysInfo()
+	c.Assert(err, IsNil)
+	c.Assert(cs.req, NotNil)
+	c.Check(cs.req.Context().Value(ctxKey{}), Equals, "value")
+
+	// the original client is unaffected
+	_, err = cs.cli.SysInfo()
+	c.Assert(err, IsNil)
+	c.Check(cs.req.Context().Value(ctxKey{}), IsNil)
+}
+
+func (cs *clientSuite) TestClientWithContextNilPanics(c *C) {
+	//lint:ignore SA1012 nil context is what is being tested
+	c.Check(func() { cs.cli.WithContext(nil) }, PanicMatches, "nil context")
+}
+
+func (cs *clientSuite) TestClientWithContextCanceled(c *C) {
+	cs.err = errors.New("borken")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := cs.cli.WithContext(ctx).Do("GET", "/this", nil, nil, nil, nil)
+	c.Check(err, ErrorMatches, "cannot communicate with server: request canceled")
+	c.Check(errors.Is(err, context.Canceled), Equals, true)
+}
+
+func (cs *clientSuite) TestClientWithContextDeadlineStopsRetries(c *C) {
+	// the retry loop would otherwise go on for a minute
+	defer client.MockDoTimings(time.Millisecond, time.Minute)()
+	cs.err = errors.New("borken")
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := cs.cli.WithContext(ctx).Do("GET", "/this", nil, nil, nil, nil)
+	c.Check(err, ErrorMatches, "cannot communicate with server: timeout exceeded while waiting for response")
+	c.Check(errors.Is(err, context.DeadlineExceeded), Equals, true)
+}
+
+func (cs *clientSuite) TestClientWithContextSharesStatus(c *C) {
+	cs.rsp = `{"type":"sync", "result":{"series":"42"}, "warning-count": 2, "warning-timestamp": "2018-09-19T12:44:19.680362867Z", "maintenance": {"kind": "system-restart", "message": "system is restarting"}}`
+	_, err := cs.cli.WithContext(context.Background()).SysInfo()
+	c.Assert(err, IsNil)
+
+	count, stamp := cs.cli.WarningsSummary()
+	c.Check(count, Equals, 2)
+	c.Check(stamp, Equals, time.Date(2018, 9, 19, 12, 44, 19, 680362867, time.UTC))
+	c.Check(cs.cli.Maintenance(), DeepEquals, &client.Error{
+		Kind:    client.ErrorKindSystemRestart,
+		Message: "system is restarting",
+	})
+}
+
 func (cs *clientSuite) TestParseError(c *C) {
 	resp := &http.Response{
 		Status: "404 Not Found",
@@ -663,6 +758,40 @@ func (cs *clientSuite) TestDebugMigrateHome(c *C) {
 	c.Check(string(data), Equals, `{"action":"migrate-home","snaps":["foo","bar"]}`)
 }
 
+func (cs *clientSuite) TestDebugSimulateDiskSpace(c *C) {
+	cs.rsp = `{"type": "sync", "result": {
+		"operation": "install",
+		"snaps": ["foo"],
+		"filesystems": [{"path": "/var/lib/snapd/snaps", "snaps": 100, "data-copies": 0, "snapshots": 0, "required": 200, "available": 50}],
+		"check-enabled": true,
+		"refused": true,
+		"error": "insufficient space"
+	}}`
+
+	est, err := cs.cli.SimulateDiskSpace("install", []string{"foo"})
+	c.Assert(err, IsNil)
+	c.Check(est, DeepEquals, &client.DiskSpaceEstimate{
+		Operation: "install",
+		Snaps:     []string{"foo"},
+		Filesystems: []client.DiskSpaceUsage{{
+			Path:      "/var/lib/snapd/snaps",
+			Snaps:     100,
+			Required:  200,
+			Available: 50,
+		}},
+		CheckEnabled: true,
+		Refused:      true,
+		Error:        "insufficient space",
+	})
+
+	c.Check(cs.reqs, HasLen, 1)
+	c.Check(cs.reqs[0].Method, Equals, "POST")
+	c.Check(cs.reqs[0].URL.Path, Equals, "/v2/debug")
+	data, err := io.ReadAll(cs.reqs[0].Body)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, `{"action":"simulate-disk-space","params":{"operation":"install"},"snaps":["foo"]}`)
+}
+
 type integrationSuite struct{}
 
 var _ = Suite(&integrationSuite{})
@@ -101,11 +101,15 @@ func FmtServiceStatus(svc *client.AppInfo, opts FmtServiceStatusOptions) string
 		startup = maybeI18nG("enabled")
 	}
 
-	// When requesting global service status, we don't have any active
-	// information available for user daemons.
+	// When requesting global service status, the active information of user
+	// daemons is only available aggregated over the user sessions, if any.
 	current := maybeI18nG("inactive")
 	if svc.DaemonScope == snap.UserDaemon && opts.IsUserGlobal {
 		current = "-"
+		if svc.Sessions > 0 {
+			// TRANSLATORS: the first %d is the number of user sessions running the service, the second the number of user sessions
+			current = fmt.Sprintf(maybeI18nG("%d/%d active"), svc.ActiveSessions, svc.Sessions)
+		}
 	} else if svc.Active {
 		current = maybeI18nG("active")
 	}
@@ -131,6 +131,18 @@ func (s *serviceScopeSuite) TestFmtServiceStatus(c *C) {
 	})
 	c.Check(out, Equals, "test-snap.bar\tenabled\t-\t-")
 
+	out = clientutil.FmtServiceStatus(&client.AppInfo{
+		Snap:           "test-snap",
+		Name:           "bar",
+		Enabled:        true,
+		DaemonScope:    snap.UserDaemon,
+		Sessions:       3,
+		ActiveSessions: 2,
+	}, clientutil.FmtServiceStatusOptions{
+		IsUserGlobal: true,
+	})
+	c.Check(out, Equals, "test-snap.bar\tenabled\t2/3 active\t-")
+
 	out = clientutil.FmtServiceStatus(&client.AppInfo{
 		Snap:    "test-snap_foo",
 		Name:    "bar",
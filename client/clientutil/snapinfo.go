@@ -81,6 +81,8 @@ func ClientSnapFromSnapInfo(snapInfo *snap.Info, decorator StatusDecorator) (*cl
 		Prices:      snapInfo.Prices,
 		Channels:    snapInfo.Channels,
 		Tracks:      snapInfo.Tracks,
+		TrackInfos:  snapInfo.TrackInfos,
+		ChannelMap:  snapInfo.ChannelMap,
 		CommonIDs:   snapInfo.CommonIDs,
 		Links:       snapInfo.Links(),
 		Contact:     snapInfo.Contact(),
@@ -121,6 +123,9 @@ func ClientAppInfoNotes(app *client.AppInfo) string {
 	if seenDbus {
 		notes = append(notes, "dbus-activated")
 	}
+	if app.RestartPending {
+		notes = append(notes, "restart-pending")
+	}
 	if len(notes) == 0 {
 		return "-"
 	}
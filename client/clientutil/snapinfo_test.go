@@ -80,7 +80,13 @@ func (*cmdSuite) TestClientSnapFromSnapInfo(c *C) {
 		},
 		Channels: map[string]*snap.ChannelSnapInfo{},
 		Tracks:   []string{},
-		Prices:   map[string]float64{},
+		TrackInfos: map[string]*snap.TrackInfo{
+			"1.0": {Successor: "2.0"},
+		},
+		ChannelMap: []*snap.ChannelSnapInfo{
+			{Architecture: "arm64", Channel: "1.0/stable", Revision: snap.R(1)},
+		},
+		Prices: map[string]float64{},
 		Media: []snap.MediaInfo{
 			{Type: "icon", URL: "https://dashboard.snapcraft.io/site_media/appmedia/2017/12/Thingy.png"},
 			{Type: "screenshot", URL: "https://dashboard.snapcraft.io/site_media/appmedia/2018/01/Thingy_01.png"},
@@ -166,6 +172,8 @@ func (*cmdSuite) TestClientSnapFromSnapInfo(c *C) {
 	c.Check(ci.Developer, Equals, "thingyinc")
 	c.Check(ci.Publisher, DeepEquals, &si.Publisher)
 	c.Check(ci.Categories, DeepEquals, si.Categories)
+	c.Check(ci.TrackInfos, DeepEquals, si.TrackInfos)
+	c.Check(ci.ChannelMap, DeepEquals, si.ChannelMap)
 }
 
 type testStatusDecorator struct {
@@ -328,4 +336,12 @@ func (*cmdSuite) TestAppStatusNotes(c *C) {
 		},
 	}
 	c.Check(clientutil.ClientAppInfoNotes(&ai), Equals, "user,timer-activated,socket-activated,dbus-activated")
+
+	ai = client.AppInfo{
+		Daemon:         "simple",
+		RestartPending: true,
+	}
+	c.Check(clientutil.ClientAppInfoNotes(&ai), Equals, "restart-pending")
+	ai.Activators = []client.AppActivator{{Type: "socket"}}
+	c.Check(clientutil.ClientAppInfoNotes(&ai), Equals, "socket-activated,restart-pending")
 }
@@ -0,0 +1,163 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/snapcore/snapd/snap"
+)
+
+// ClusterMember describes a device identified while assembling a cluster.
+type ClusterMember struct {
+	// RDT is the random device token identifying the device during the
+	// assembly.
+	RDT         string `json:"rdt"`
+	Serial      string `json:"serial,omitempty"`
+	Address     string `json:"address,omitempty"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// ClusterAssembly describes the progress of the assembly of a cluster.
+type ClusterAssembly struct {
+	// Status is either "in-progress" or "done".
+	Status       string    `json:"status"`
+	RDT          string    `json:"rdt"`
+	Address      string    `json:"address"`
+	Fingerprint  string    `json:"fingerprint"`
+	Initiated    time.Time `json:"initiated"`
+	ExpectedSize int       `json:"expected-size,omitempty"`
+	// Members are the devices identified so far, including this one.
+	Members []ClusterMember `json:"members,omitempty"`
+	// Trusted is the number of peers which proved knowledge of the secret.
+	Trusted int `json:"trusted"`
+	// Routes is the number of verified routes between the devices.
+	Routes int `json:"routes"`
+}
+
+// ClusterRefresh describes the refresh of a clustered snap on this device.
+type ClusterRefresh struct {
+	Snap     string        `json:"snap"`
+	Revision snap.Revision `json:"revision"`
+	// Status is either "waiting" or "refreshing".
+	Status string `json:"status"`
+	// Reason is why the refresh waits, if it does.
+	Reason string `json:"reason,omitempty"`
+}
+
+// ClusterRefreshes describes the coordination of the refreshes of clustered
+// snaps with the other members of the cluster.
+type ClusterRefreshes struct {
+	// Leader is the random device token of the member coordinating the
+	// refreshes.
+	Leader string `json:"leader,omitempty"`
+	// HeldAll is set if the refreshes of all clustered snaps are held.
+	HeldAll bool `json:"held-all,omitempty"`
+	// Held are the clustered snaps whose refreshes are held.
+	Held []string `json:"held,omitempty"`
+	// Refreshes are the refreshes of clustered snaps that this device waits
+	// to perform or performs.
+	Refreshes []ClusterRefresh `json:"refreshes,omitempty"`
+}
+
+// ClusterStatus describes the clustering state of the device.
+type ClusterStatus struct {
+	ClusterID string            `json:"cluster-id,omitempty"`
+	Assembly  *ClusterAssembly  `json:"assembly,omitempty"`
+	Refreshes *ClusterRefreshes `json:"refreshes,omitempty"`
+}
+
+// ClusterAssembleOptions contains the options for assembling a cluster.
+type ClusterAssembleOptions struct {
+	// Secret is the secret shared by the devices assembling the cluster.
+	Secret string `json:"secret"`
+	// Address is the host:port address the device listens on for its peers.
+	Address string `json:"address"`
+	// Peers are the addresses of other devices to reach out to.
+	Peers []string `json:"peers,omitempty"`
+	// ExpectedSize is the expected number of devices in the cluster, if
+	// known.
+	ExpectedSize int `json:"expected-size,omitempty"`
+}
+
+type clusterAction struct {
+	Action string   `json:"action"`
+	Snaps  []string `json:"snaps,omitempty"`
+	*ClusterAssembleOptions
+}
+
+// ClusterStatus returns the clustering state of the device.
+func (client *Client) ClusterStatus() (*ClusterStatus, error) {
+	var status ClusterStatus
+	if _, err := client.doSync("GET", "/v2/cluster", nil, nil, nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// AssembleCluster starts assembling a cluster with the devices sharing the
+// given secret.
+func (client *Client) AssembleCluster(opts *ClusterAssembleOptions) (changeID string, err error) {
+	if opts == nil || opts.Secret == "" {
+		return "", fmt.Errorf("cannot assemble cluster without a secret")
+	}
+
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(clusterAction{Action: "assemble", ClusterAssembleOptions: opts}); err != nil {
+		return "", err
+	}
+	return client.doAsync("POST", "/v2/cluster", nil, nil, &body)
+}
+
+// LeaveCluster makes the device leave the cluster it is part of, aborting
+// any assembly in progress.
+func (client *Client) LeaveCluster() error {
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(clusterAction{Action: "leave"}); err != nil {
+		return err
+	}
+	_, err := client.doSync("POST", "/v2/cluster", nil, nil, &body, nil)
+	return err
+}
+
+// HoldClusterRefreshes holds the refreshes of the given clustered snaps, or of
+// all of them if none are given, on all the members of the cluster.
+func (client *Client) HoldClusterRefreshes(snaps []string) error {
+	return client.clusterRefreshesAction("hold-refreshes", snaps)
+}
+
+// ResumeClusterRefreshes removes the holds on the refreshes of the given
+// clustered snaps, or of all of them if none are given, on all the members of
+// the cluster.
+func (client *Client) ResumeClusterRefreshes(snaps []string) error {
+	return client.clusterRefreshesAction("resume-refreshes", snaps)
+}
+
+func (client *Client) clusterRefreshesAction(action string, snaps []string) error {
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(clusterAction{Action: action, Snaps: snaps}); err != nil {
+		return err
+	}
+	_, err := client.doSync("POST", "/v2/cluster", nil, nil, &body, nil)
+	return err
+}
@@ -0,0 +1,234 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package client_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/snap"
+)
+
+func (cs *clientSuite) TestClusterStatus(c *check.C) {
+	cs.rsp = `{
+		"type": "sync",
+		"status-code": 200,
+		"result": {
+			"assembly": {
+				"status": "in-progress",
+				"rdt": "rdt-1",
+				"address": "10.0.0.1:7070",
+				"fingerprint": "fp-1",
+				"initiated": "2026-10-16T10:00:00Z",
+				"expected-size": 2,
+				"members": [
+					{"rdt": "rdt-1", "serial": "serial-1", "address": "10.0.0.1:7070", "fingerprint": "fp-1"}
+				],
+				"trusted": 1,
+				"routes": 0
+			}
+		}
+	}`
+
+	status, err := cs.cli.ClusterStatus()
+	c.Assert(err, check.IsNil)
+	c.Check(cs.req.Method, check.Equals, "GET")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/cluster")
+	c.Check(status, check.DeepEquals, &client.ClusterStatus{
+		Assembly: &client.ClusterAssembly{
+			Status:       "in-progress",
+			RDT:          "rdt-1",
+			Address:      "10.0.0.1:7070",
+			Fingerprint:  "fp-1",
+			Initiated:    time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC),
+			ExpectedSize: 2,
+			Members: []client.ClusterMember{
+				{RDT: "rdt-1", Serial: "serial-1", Address: "10.0.0.1:7070", Fingerprint: "fp-1"},
+			},
+			Trusted: 1,
+		},
+	})
+}
+
+func (cs *clientSuite) TestClusterStatusError(c *check.C) {
+	cs.status = 500
+	cs.rsp = `{"type": "error"}`
+	_, err := cs.cli.ClusterStatus()
+	c.Check(err, check.ErrorMatches, `server error: "Internal Server Error"`)
+}
+
+func (cs *clientSuite) TestAssembleCluster(c *check.C) {
+	cs.status = 202
+	cs.rsp = `{
+		"type": "async",
+		"status-code": 202,
+		"change": "42"
+	}`
+
+	chgID, err := cs.cli.AssembleCluster(&client.ClusterAssembleOptions{
+		Secret:       "secret",
+		Address:      "10.0.0.1:7070",
+		Peers:        []string{"10.0.0.2:7070"},
+		ExpectedSize: 2,
+	})
+	c.Assert(err, check.IsNil)
+	c.Check(chgID, check.Equals, "42")
+	c.Check(cs.req.Method, check.Equals, "POST")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/cluster")
+	body, err := io.ReadAll(cs.req.Body)
+	c.Assert(err, check.IsNil)
+	var req map[string]any
+	err = json.Unmarshal(body, &req)
+	c.Assert(err, check.IsNil)
+	c.Check(req, check.DeepEquals, map[string]any{
+		"action":        "assemble",
+		"secret":        "secret",
+		"address":       "10.0.0.1:7070",
+		"peers":         []any{"10.0.0.2:7070"},
+		"expected-size": float64(2),
+	})
+}
+
+func (cs *clientSuite) TestAssembleClusterNoSecret(c *check.C) {
+	_, err := cs.cli.AssembleCluster(&client.ClusterAssembleOptions{Address: "10.0.0.1:7070"})
+	c.Check(err, check.ErrorMatches, "cannot assemble cluster without a secret")
+	_, err = cs.cli.AssembleCluster(nil)
+	c.Check(err, check.ErrorMatches, "cannot assemble cluster without a secret")
+}
+
+func (cs *clientSuite) TestLeaveCluster(c *check.C) {
+	cs.rsp = `{
+		"type": "sync",
+		"status-code": 200,
+		"result": null
+	}`
+
+	err := cs.cli.LeaveCluster()
+	c.Assert(err, check.IsNil)
+	c.Check(cs.req.Method, check.Equals, "POST")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/cluster")
+	body, err := io.ReadAll(cs.req.Body)
+	c.Assert(err, check.IsNil)
+	var req map[string]any
+	err = json.Unmarshal(body, &req)
+	c.Assert(err, check.IsNil)
+	c.Check(req, check.DeepEquals, map[string]any{
+		"action": "leave",
+	})
+}
+
+func (cs *clientSuite) TestLeaveClusterError(c *check.C) {
+	cs.status = 400
+	cs.rsp = `{
+		"type": "error",
+		"status-code": 400,
+		"result": {"message": "device is not part of a cluster"}
+	}`
+
+	err := cs.cli.LeaveCluster()
+	c.Check(err, check.ErrorMatches, "device is not part of a cluster")
+}
+
+func (cs *clientSuite) TestClusterStatusRefreshes(c *check.C) {
+	cs.rsp = `{
+		"type": "sync",
+		"status-code": 200,
+		"result": {
+			"cluster-id": "bf3675f5-cffa-40f4-a119-7492ccc08e04",
+			"refreshes": {
+				"leader": "rdt-1",
+				"held": ["other"],
+				"refreshes": [
+					{"snap": "microk8s", "revision": "2", "status": "waiting", "reason": "waiting for member \"rdt-2\" to finish refreshing \"microk8s\""}
+				]
+			}
+		}
+	}`
+
+	status, err := cs.cli.ClusterStatus()
+	c.Assert(err, check.IsNil)
+	c.Check(status, check.DeepEquals, &client.ClusterStatus{
+		ClusterID: "bf3675f5-cffa-40f4-a119-7492ccc08e04",
+		Refreshes: &client.ClusterRefreshes{
+			Leader: "rdt-1",
+			Held:   []string{"other"},
+			Refreshes: []client.ClusterRefresh{{
+				Snap:     "microk8s",
+				Revision: snap.R(2),
+				Status:   "waiting",
+				Reason:   `waiting for member "rdt-2" to finish refreshing "microk8s"`,
+			}},
+		},
+	})
+}
+
+func (cs *clientSuite) TestHoldResumeClusterRefreshes(c *check.C) {
+	for _, tc := range []struct {
+		action string
+		call   func(snaps []string) error
+	}{
+		{"hold-refreshes", cs.cli.HoldClusterRefreshes},
+		{"resume-refreshes", cs.cli.ResumeClusterRefreshes},
+	} {
+		cs.rsp = `{
+			"type": "sync",
+			"status-code": 200,
+			"result": null
+		}`
+
+		err := tc.call([]string{"microk8s"})
+		c.Assert(err, check.IsNil)
+		c.Check(cs.req.Method, check.Equals, "POST")
+		c.Check(cs.req.URL.Path, check.Equals, "/v2/cluster")
+		body, err := io.ReadAll(cs.req.Body)
+		c.Assert(err, check.IsNil)
+		var req map[string]any
+		err = json.Unmarshal(body, &req)
+		c.Assert(err, check.IsNil)
+		c.Check(req, check.DeepEquals, map[string]any{
+			"action": tc.action,
+			"snaps":  []any{"microk8s"},
+		})
+
+		// all clustered snaps when none are given
+		err = tc.call(nil)
+		c.Assert(err, check.IsNil)
+		body, err = io.ReadAll(cs.req.Body)
+		c.Assert(err, check.IsNil)
+		c.Check(string(body), check.Equals, fmt.Sprintf(`{"action":%q}`+"\n", tc.action))
+	}
+}
+
+func (cs *clientSuite) TestHoldClusterRefreshesError(c *check.C) {
+	cs.status = 400
+	cs.rsp = `{
+		"type": "error",
+		"status-code": 400,
+		"result": {"message": "cannot hold cluster refreshes: device is not part of an assembled cluster"}
+	}`
+
+	err := cs.cli.HoldClusterRefreshes(nil)
+	c.Check(err, check.ErrorMatches, "cannot hold cluster refreshes: device is not part of an assembled cluster")
+}
@@ -23,6 +23,7 @@ import (
 	"encoding/json"
 	"io"
 	"net/url"
+	"time"
 )
 
 // SetDoer sets the client's doer to the given one
@@ -64,3 +65,11 @@ func MockStdinReadLimit(new int64) (restore func()) {
 		stdinReadLimit = oldStdinReadLimit
 	}
 }
+
+func MockChangeWatchTimeout(timeout time.Duration) (restore func()) {
+	oldTimeout := changeWatchTimeout
+	changeWatchTimeout = timeout
+	return func() {
+		changeWatchTimeout = oldTimeout
+	}
+}
@@ -20,7 +20,6 @@
 package client
 
 import (
-	"context"
 	"fmt"
 	"io"
 	"regexp"
@@ -38,7 +37,7 @@ var contentDispositionMatcher = regexp.MustCompile(`attachment; filename=(.+)`).
 func (c *Client) Icon(pkgID string) (*Icon, error) {
 	const errPrefix = "cannot retrieve icon"
 
-	response, cancel, err := c.rawWithTimeout(context.Background(), "GET", fmt.Sprintf("/v2/icons/%s/icon", pkgID), nil, nil, nil, nil)
+	response, cancel, err := c.rawWithTimeout(c.context(), "GET", fmt.Sprintf("/v2/icons/%s/icon", pkgID), nil, nil, nil, nil)
 	if err != nil {
 		return nil, fmt.Errorf("%s: failed to communicate with server: %w", errPrefix, err)
 	}
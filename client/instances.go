@@ -0,0 +1,61 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// instanceAction represents an action performed on parallel instances of a
+// snap.
+type instanceAction struct {
+	Action string `json:"action"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+}
+
+func (client *Client) performInstanceAction(a *instanceAction) (changeID string, err error) {
+	b, err := json.Marshal(a)
+	if err != nil {
+		return "", err
+	}
+	return client.doAsync("POST", "/v2/instances", nil, nil, bytes.NewReader(b))
+}
+
+// RenameInstance renames the snap instance from to to, which must be another,
+// not yet installed, instance of the same snap.
+func (client *Client) RenameInstance(from, to string) (changeID string, err error) {
+	return client.performInstanceAction(&instanceAction{
+		Action: "rename",
+		From:   from,
+		To:     to,
+	})
+}
+
+// CopyInstanceData replaces the data of the snap instance to with a copy of
+// the data of the snap instance from.
+func (client *Client) CopyInstanceData(from, to string) (changeID string, err error) {
+	return client.performInstanceAction(&instanceAction{
+		Action: "copy-data",
+		From:   from,
+		To:     to,
+	})
+}
@@ -67,14 +67,21 @@ type Interface struct {
 	DocURL  string `json:"doc-url,omitempty"`
 	Plugs   []Plug `json:"plugs,omitempty"`
 	Slots   []Slot `json:"slots,omitempty"`
+
+	// The following are only set when documentation is requested.
+	RequiredPlugAttrs []string `json:"required-plug-attrs,omitempty"`
+	RequiredSlotAttrs []string `json:"required-slot-attrs,omitempty"`
+	Consumers         string   `json:"consumers,omitempty"`
+	SecurityNotes     []string `json:"security-notes,omitempty"`
 }
 
 // InterfaceAction represents an action performed on the interface system.
 type InterfaceAction struct {
-	Action string `json:"action"`
-	Forget bool   `json:"forget,omitempty"`
-	Plugs  []Plug `json:"plugs,omitempty"`
-	Slots  []Slot `json:"slots,omitempty"`
+	Action          string `json:"action"`
+	Forget          bool   `json:"forget,omitempty"`
+	RestartAffected bool   `json:"restart-affected,omitempty"`
+	Plugs           []Plug `json:"plugs,omitempty"`
+	Slots           []Slot `json:"slots,omitempty"`
 }
 
 // InterfaceOptions represents opt-in elements include in responses.
@@ -89,6 +96,15 @@ type InterfaceOptions struct {
 // DisconnectOptions represents extra options for disconnect op
 type DisconnectOptions struct {
 	Forget bool
+	// RestartAffected restarts the services which need it to see the
+	// removal of the connection.
+	RestartAffected bool
+}
+
+type ConnectOptions struct {
+	// RestartAffected restarts the services which need it to see the
+	// new connection.
+	RestartAffected bool
 }
 
 func (client *Client) Interfaces(opts *InterfaceOptions) ([]*Interface, error) {
@@ -130,20 +146,45 @@ func (client *Client) performInterfaceAction(sa *InterfaceAction) (changeID stri
 
 // Connect establishes a connection between a plug and a slot.
 // The plug and the slot must have the same interface.
-func (client *Client) Connect(plugSnapName, plugName, slotSnapName, slotName string) (changeID string, err error) {
+func (client *Client) Connect(plugSnapName, plugName, slotSnapName, slotName string, opts *ConnectOptions) (changeID string, err error) {
 	return client.performInterfaceAction(&InterfaceAction{
-		Action: "connect",
-		Plugs:  []Plug{{Snap: plugSnapName, Name: plugName}},
-		Slots:  []Slot{{Snap: slotSnapName, Name: slotName}},
+		Action:          "connect",
+		RestartAffected: opts != nil && opts.RestartAffected,
+		Plugs:           []Plug{{Snap: plugSnapName, Name: plugName}},
+		Slots:           []Slot{{Snap: slotSnapName, Name: slotName}},
 	})
 }
 
+// ConnectionRequest describes a single plug to slot connection that is part
+// of a batch.
+type ConnectionRequest struct {
+	Plug Plug
+	Slot Slot
+}
+
+// ConnectBatch establishes all the given connections in a single change.
+// Either all of the connections are established or, if any of them fails,
+// none of them are.
+func (client *Client) ConnectBatch(conns []ConnectionRequest) (changeID string, err error) {
+	action := &InterfaceAction{
+		Action: "connect-batch",
+		Plugs:  make([]Plug, 0, len(conns)),
+		Slots:  make([]Slot, 0, len(conns)),
+	}
+	for _, conn := range conns {
+		action.Plugs = append(action.Plugs, Plug{Snap: conn.Plug.Snap, Name: conn.Plug.Name})
+		action.Slots = append(action.Slots, Slot{Snap: conn.Slot.Snap, Name: conn.Slot.Name})
+	}
+	return client.performInterfaceAction(action)
+}
+
 // Disconnect breaks the connection between a plug and a slot.
 func (client *Client) Disconnect(plugSnapName, plugName, slotSnapName, slotName string, opts *DisconnectOptions) (changeID string, err error) {
 	return client.performInterfaceAction(&InterfaceAction{
-		Action: "disconnect",
-		Forget: opts != nil && opts.Forget,
-		Plugs:  []Plug{{Snap: plugSnapName, Name: plugName}},
-		Slots:  []Slot{{Snap: slotSnapName, Name: slotName}},
+		Action:          "disconnect",
+		Forget:          opts != nil && opts.Forget,
+		RestartAffected: opts != nil && opts.RestartAffected,
+		Plugs:           []Plug{{Snap: plugSnapName, Name: plugName}},
+		Slots:           []Slot{{Snap: slotSnapName, Name: slotName}},
 	})
 }
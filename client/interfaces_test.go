@@ -135,6 +135,36 @@ func (cs *clientSuite) TestClientInterfacesSelectedDetails(c *check.C) {
 	})
 }
 
+func (cs *clientSuite) TestClientInterfacesDocMetadata(c *check.C) {
+	cs.rsp = `{
+		"type": "sync",
+		"result": [
+			{
+				"name": "iface-a",
+				"summary": "the A iface",
+				"doc-url": "http://example.org/ifaces/a",
+				"required-plug-attrs": ["path"],
+				"required-slot-attrs": ["bus", "name"],
+				"consumers": "tools",
+				"security-notes": ["plug connections are not established automatically"]
+			}
+		]
+	}`
+	ifaces, err := cs.cli.Interfaces(&client.InterfaceOptions{Names: []string{"iface-a"}, Doc: true})
+	c.Assert(err, check.IsNil)
+	c.Check(ifaces, check.DeepEquals, []*client.Interface{
+		{
+			Name:              "iface-a",
+			Summary:           "the A iface",
+			DocURL:            "http://example.org/ifaces/a",
+			RequiredPlugAttrs: []string{"path"},
+			RequiredSlotAttrs: []string{"bus", "name"},
+			Consumers:         "tools",
+			SecurityNotes:     []string{"plug connections are not established automatically"},
+		},
+	})
+}
+
 func (cs *clientSuite) TestClientInterfacesMultiple(c *check.C) {
 	// Ask for multiple interfaces.
 	cs.rsp = `{
@@ -157,7 +187,7 @@ func (cs *clientSuite) TestClientInterfacesMultiple(c *check.C) {
 }
 
 func (cs *clientSuite) TestClientConnectCallsEndpoint(c *check.C) {
-	cs.cli.Connect("producer", "plug", "consumer", "slot")
+	cs.cli.Connect("producer", "plug", "consumer", "slot", nil)
 	c.Check(cs.req.Method, check.Equals, "POST")
 	c.Check(cs.req.URL.Path, check.Equals, "/v2/interfaces")
 }
@@ -170,7 +200,7 @@ func (cs *clientSuite) TestClientConnect(c *check.C) {
 		"result": { },
                 "change": "foo"
 	}`
-	id, err := cs.cli.Connect("producer", "plug", "consumer", "slot")
+	id, err := cs.cli.Connect("producer", "plug", "consumer", "slot", nil)
 	c.Assert(err, check.IsNil)
 	c.Check(id, check.Equals, "foo")
 	var body map[string]any
@@ -194,6 +224,55 @@ func (cs *clientSuite) TestClientConnect(c *check.C) {
 	})
 }
 
+func (cs *clientSuite) TestClientConnectRestartAffected(c *check.C) {
+	cs.status = 202
+	cs.rsp = `{
+		"type": "async",
+		"status-code": 202,
+		"result": { },
+		"change": "foo"
+	}`
+	_, err := cs.cli.Connect("producer", "plug", "consumer", "slot", &client.ConnectOptions{RestartAffected: true})
+	c.Assert(err, check.IsNil)
+	var body map[string]any
+	c.Assert(json.NewDecoder(cs.req.Body).Decode(&body), check.IsNil)
+	c.Check(body["action"], check.Equals, "connect")
+	c.Check(body["restart-affected"], check.Equals, true)
+}
+
+func (cs *clientSuite) TestClientConnectBatch(c *check.C) {
+	cs.status = 202
+	cs.rsp = `{
+		"type": "async",
+		"status-code": 202,
+		"result": { },
+		"change": "foo"
+	}`
+	id, err := cs.cli.ConnectBatch([]client.ConnectionRequest{
+		{Plug: client.Plug{Snap: "consumer", Name: "plug"}, Slot: client.Slot{Snap: "producer", Name: "slot"}},
+		{Plug: client.Plug{Snap: "consumer", Name: "other-plug"}, Slot: client.Slot{Name: "network"}},
+	})
+	c.Assert(err, check.IsNil)
+	c.Check(id, check.Equals, "foo")
+	c.Check(cs.req.Method, check.Equals, "POST")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/interfaces")
+	var body map[string]any
+	decoder := json.NewDecoder(cs.req.Body)
+	err = decoder.Decode(&body)
+	c.Check(err, check.IsNil)
+	c.Check(body, check.DeepEquals, map[string]any{
+		"action": "connect-batch",
+		"plugs": []any{
+			map[string]any{"snap": "consumer", "plug": "plug"},
+			map[string]any{"snap": "consumer", "plug": "other-plug"},
+		},
+		"slots": []any{
+			map[string]any{"snap": "producer", "slot": "slot"},
+			map[string]any{"snap": "", "slot": "network"},
+		},
+	})
+}
+
 func (cs *clientSuite) TestClientDisconnectCallsEndpoint(c *check.C) {
 	cs.cli.Disconnect("producer", "plug", "consumer", "slot", nil)
 	c.Check(cs.req.Method, check.Equals, "POST")
@@ -266,3 +345,21 @@ func (cs *clientSuite) TestClientDisconnectForget(c *check.C) {
 		},
 	})
 }
+
+func (cs *clientSuite) TestClientDisconnectRestartAffected(c *check.C) {
+	cs.status = 202
+	cs.rsp = `{
+		"type": "async",
+		"status-code": 202,
+		"result": { },
+		"change": "42"
+	}`
+	opts := &client.DisconnectOptions{RestartAffected: true}
+	_, err := cs.cli.Disconnect("producer", "plug", "consumer", "slot", opts)
+	c.Assert(err, check.IsNil)
+	var body map[string]any
+	c.Assert(json.NewDecoder(cs.req.Body).Decode(&body), check.IsNil)
+	c.Check(body["action"], check.Equals, "disconnect")
+	c.Check(body["restart-affected"], check.Equals, true)
+	c.Check(body["forget"], check.IsNil)
+}
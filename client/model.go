@@ -21,7 +21,6 @@ package client
 
 import (
 	"bytes"
-	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -32,11 +31,13 @@ import (
 	"path/filepath"
 
 	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/snap"
 )
 
 type remodelData struct {
 	NewModel string `json:"new-model"`
 	Offline  bool   `json:"offline,omitempty"`
+	DryRun   bool   `json:"dry-run,omitempty"`
 }
 
 // RemodelOpts defines options to be used when remodeling the system.
@@ -62,6 +63,54 @@ func (client *Client) Remodel(b []byte, opts RemodelOpts) (changeID string, err
 	return client.doAsync("POST", "/v2/model", nil, headers, bytes.NewReader(data))
 }
 
+// RemodelPlanModel identifies a model in a RemodelPlan.
+type RemodelPlanModel struct {
+	BrandID  string `json:"brand-id"`
+	Model    string `json:"model"`
+	Revision int    `json:"revision"`
+}
+
+// RemodelPlanSnap describes what a remodel would do to a snap.
+type RemodelPlanSnap struct {
+	Name string `json:"name"`
+	// Action is one of "install", "refresh" or "switch-channel".
+	Action   string        `json:"action"`
+	Channel  string        `json:"channel,omitempty"`
+	Revision snap.Revision `json:"revision,omitempty"`
+}
+
+// RemodelPlan describes what a remodel would do to the system.
+type RemodelPlan struct {
+	// Kind is one of "update", "store-switch" or "re-registration".
+	Kind         string           `json:"kind"`
+	CurrentModel RemodelPlanModel `json:"current-model"`
+	NewModel     RemodelPlanModel `json:"new-model"`
+	// Snaps are not known in advance for re-registration remodels.
+	Snaps []RemodelPlanSnap `json:"snaps,omitempty"`
+}
+
+// RemodelPreview returns what remodeling the system with the given
+// assertion data would do, without doing it.
+func (client *Client) RemodelPreview(b []byte, opts RemodelOpts) (*RemodelPlan, error) {
+	data, err := json.Marshal(&remodelData{
+		NewModel: string(b),
+		Offline:  opts.Offline,
+		DryRun:   true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot marshal remodel data: %v", err)
+	}
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	var plan RemodelPlan
+	if _, err := client.doSync("POST", "/v2/model", nil, headers, bytes.NewReader(data), &plan); err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
 // RemodelWithLocalSnaps tries to remodel the system with the given model
 // assertion and local snaps and assertion files. Remodeling using this method
 // will ensure that snapd does not contact the store.
@@ -201,7 +250,7 @@ func (client *Client) CurrentSerialAssertion() (*asserts.Serial, error) {
 func currentAssertion(client *Client, path string) (asserts.Assertion, error) {
 	q := url.Values{}
 
-	response, cancel, err := client.rawWithTimeout(context.Background(), "GET", path, q, nil, nil, nil)
+	response, cancel, err := client.rawWithTimeout(client.context(), "GET", path, q, nil, nil, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query current assertion: %w", err)
 	}
@@ -34,6 +34,7 @@ import (
 	"github.com/snapcore/snapd/asserts"
 	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/snap"
 )
 
 const happyModelAssertionResponse = `type: model
@@ -135,6 +136,48 @@ func (cs *clientSuite) TestClientRemodel(c *C) {
 	c.Check(jsonBody["offline"], IsNil)
 }
 
+func (cs *clientSuite) TestClientRemodelPreview(c *C) {
+	cs.rsp = `{
+		"type": "sync",
+		"status-code": 200,
+		"result": {
+			"kind": "update",
+			"current-model": {"brand-id": "my-brand", "model": "my-model", "revision": 1},
+			"new-model": {"brand-id": "my-brand", "model": "my-model", "revision": 2},
+			"snaps": [
+				{"name": "foo", "action": "install", "channel": "latest/stable", "revision": "12"},
+				{"name": "pc-kernel", "action": "switch-channel", "channel": "22/stable", "revision": "3"}
+			]
+		}
+	}`
+	remodelJsonData := []byte(`{"new-model": "some-model"}`)
+	plan, err := cs.cli.RemodelPreview(remodelJsonData, client.RemodelOpts{Offline: true})
+	c.Assert(err, IsNil)
+	c.Check(plan, DeepEquals, &client.RemodelPlan{
+		Kind:         "update",
+		CurrentModel: client.RemodelPlanModel{BrandID: "my-brand", Model: "my-model", Revision: 1},
+		NewModel:     client.RemodelPlanModel{BrandID: "my-brand", Model: "my-model", Revision: 2},
+		Snaps: []client.RemodelPlanSnap{
+			{Name: "foo", Action: "install", Channel: "latest/stable", Revision: snap.R(12)},
+			{Name: "pc-kernel", Action: "switch-channel", Channel: "22/stable", Revision: snap.R(3)},
+		},
+	})
+	c.Check(cs.req.Method, Equals, "POST")
+	c.Check(cs.req.URL.Path, Equals, "/v2/model")
+	c.Assert(cs.req.Header.Get("Content-Type"), Equals, "application/json")
+
+	body, err := io.ReadAll(cs.req.Body)
+	c.Assert(err, IsNil)
+	jsonBody := make(map[string]any)
+	err = json.Unmarshal(body, &jsonBody)
+	c.Assert(err, IsNil)
+	c.Check(jsonBody, DeepEquals, map[string]any{
+		"new-model": string(remodelJsonData),
+		"offline":   true,
+		"dry-run":   true,
+	})
+}
+
 func (cs *clientSuite) TestClientRemodelOffline(c *C) {
 	cs.status = 202
 	cs.rsp = `{
@@ -82,6 +82,12 @@ type Snap struct {
 	// The ordered list of tracks that contains channels
 	Tracks []string `json:"tracks,omitempty"`
 
+	// The metadata of the tracks, by track name
+	TrackInfos map[string]*snap.TrackInfo `json:"track-infos,omitempty"`
+
+	// The complete channel map, for all architectures, if requested
+	ChannelMap []*snap.ChannelSnapInfo `json:"channel-map,omitempty"`
+
 	Health *SnapHealth `json:"health,omitempty"`
 
 	// Hold is the time until which the snap's refreshes are held by the user.
@@ -255,9 +261,29 @@ func (client *Client) Find(opts *FindOptions) ([]*Snap, *ResultInfo, error) {
 	return client.snapsFromPath("/v2/find", q)
 }
 
+// FindOneOptions supports exactly what's in the FindOne API call.
+type FindOneOptions struct {
+	// ChannelMap requests the complete channel map of the snap, for all
+	// architectures and branches.
+	ChannelMap bool
+}
+
 func (client *Client) FindOne(name string) (*Snap, *ResultInfo, error) {
+	return client.FindOneWithOptions(name, nil)
+}
+
+// FindOneWithOptions returns the store information about the snap with the
+// given name, as FindOne, with the additional data requested by opts.
+func (client *Client) FindOneWithOptions(name string, opts *FindOneOptions) (*Snap, *ResultInfo, error) {
+	if opts == nil {
+		opts = &FindOneOptions{}
+	}
+
 	q := url.Values{}
 	q.Set("name", name)
+	if opts.ChannelMap {
+		q.Set("channel-map", "true")
+	}
 
 	snaps, ri, err := client.snapsFromPath("/v2/find", q)
 	if err != nil {
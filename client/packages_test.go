@@ -236,6 +236,30 @@ func (cs *clientSuite) TestClientFindOne(c *check.C) {
 	c.Check(cs.req.URL.RawQuery, check.Equals, "name=foo")
 }
 
+func (cs *clientSuite) TestClientFindOneWithChannelMap(c *check.C) {
+	cs.rsp = `{
+		"type": "sync",
+		"result": [{
+			"name": "foo",
+			"channel-map": [
+				{"architecture": "amd64", "channel": "latest/stable", "revision": "1", "version": "1.0", "epoch": {"read": [0], "write": [0]}, "confinement": "strict", "size": 1024, "released-at": "2019-01-02T03:04:05Z"},
+				{"architecture": "arm64", "channel": "latest/stable/fix", "revision": "2", "version": "1.1", "epoch": {"read": [0], "write": [0]}, "confinement": "strict", "size": 2048, "released-at": "2019-01-02T03:04:05Z"}
+			]
+		}]
+	}`
+	snp, _, err := cs.cli.FindOneWithOptions("foo", &client.FindOneOptions{ChannelMap: true})
+	c.Assert(err, check.IsNil)
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/find")
+	c.Check(cs.req.URL.Query(), check.DeepEquals, url.Values{
+		"name":        []string{"foo"},
+		"channel-map": []string{"true"},
+	})
+	c.Check(snp.ChannelMap, check.DeepEquals, []*snap.ChannelSnapInfo{
+		{Architecture: "amd64", Channel: "latest/stable", Revision: snap.R(1), Version: "1.0", Epoch: snap.E("0"), Confinement: snap.StrictConfinement, Size: 1024, ReleasedAt: time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{Architecture: "arm64", Channel: "latest/stable/fix", Revision: snap.R(2), Version: "1.1", Epoch: snap.E("0"), Confinement: snap.StrictConfinement, Size: 2048, ReleasedAt: time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)},
+	})
+}
+
 const (
 	pkgName = "chatroom"
 )
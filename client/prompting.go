@@ -0,0 +1,99 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Prompt is a request for access from a snap awaiting a reply from the
+// user, as raised by AppArmor prompting.
+type Prompt struct {
+	ID          string            `json:"id"`
+	Timestamp   time.Time         `json:"timestamp"`
+	Snap        string            `json:"snap"`
+	PID         int32             `json:"pid"`
+	Cgroup      string            `json:"cgroup"`
+	Interface   string            `json:"interface"`
+	Constraints PromptConstraints `json:"constraints"`
+}
+
+// PromptConstraints describe what a prompt asks access to. Path is only set
+// for interfaces mediating access to files.
+type PromptConstraints struct {
+	Path                 string   `json:"path,omitempty"`
+	RequestedPermissions []string `json:"requested-permissions"`
+	AvailablePermissions []string `json:"available-permissions"`
+}
+
+// PromptReply is a reply to a prompt.
+type PromptReply struct {
+	// Action is either "allow" or "deny".
+	Action string `json:"action"`
+	// Lifespan is one of "single", "session", "timespan" or "forever".
+	Lifespan string `json:"lifespan"`
+	// Duration is only used with the "timespan" lifespan.
+	Duration    string                 `json:"duration,omitempty"`
+	Constraints PromptReplyConstraints `json:"constraints"`
+}
+
+// PromptReplyConstraints describe what a reply to a prompt applies to.
+type PromptReplyConstraints struct {
+	PathPattern string   `json:"path-pattern,omitempty"`
+	Permissions []string `json:"permissions"`
+}
+
+// Prompts returns the prompts awaiting a reply from the user.
+func (client *Client) Prompts() ([]*Prompt, error) {
+	var prompts []*Prompt
+	if _, err := client.doSync("GET", "/v2/interfaces/requests/prompts", nil, nil, nil, &prompts); err != nil {
+		return nil, err
+	}
+	return prompts, nil
+}
+
+// Prompt returns the prompt with the given ID.
+func (client *Client) Prompt(id string) (*Prompt, error) {
+	var prompt Prompt
+	path := fmt.Sprintf("/v2/interfaces/requests/prompts/%s", url.PathEscape(id))
+	if _, err := client.doSync("GET", path, nil, nil, nil, &prompt); err != nil {
+		return nil, err
+	}
+	return &prompt, nil
+}
+
+// ReplyToPrompt replies to the prompt with the given ID, returning the IDs
+// of all the prompts satisfied by the reply.
+func (client *Client) ReplyToPrompt(id string, reply *PromptReply) ([]string, error) {
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(reply); err != nil {
+		return nil, err
+	}
+	var satisfied []string
+	path := fmt.Sprintf("/v2/interfaces/requests/prompts/%s", url.PathEscape(id))
+	if _, err := client.doSync("POST", path, nil, nil, &body, &satisfied); err != nil {
+		return nil, err
+	}
+	return satisfied, nil
+}
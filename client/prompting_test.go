@@ -0,0 +1,115 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package client_test
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/client"
+)
+
+const mockPromptJSON = `{
+  "id": "0000000000000002",
+  "timestamp": "2024-08-14T09:47:03.350324989-05:00",
+  "snap": "firefox",
+  "pid": 1234,
+  "cgroup": "0::/user.slice/user-1000.slice/user@1000.service/app.slice/snap.firefox.firefox.scope",
+  "interface": "home",
+  "constraints": {
+    "path": "/home/test/Downloads/foo.pdf",
+    "requested-permissions": ["read"],
+    "available-permissions": ["read", "write", "execute"]
+  }
+}`
+
+func (cs *clientSuite) TestPrompts(c *C) {
+	cs.rsp = `{"type": "sync", "result": [` + mockPromptJSON + `]}`
+	prompts, err := cs.cli.Prompts()
+	c.Assert(err, IsNil)
+	c.Check(cs.req.Method, Equals, "GET")
+	c.Check(cs.req.URL.Path, Equals, "/v2/interfaces/requests/prompts")
+	c.Assert(prompts, HasLen, 1)
+	c.Check(prompts[0], DeepEquals, &client.Prompt{
+		ID:        "0000000000000002",
+		Timestamp: time.Date(2024, 8, 14, 9, 47, 3, 350324989, time.FixedZone("", -5*60*60)),
+		Snap:      "firefox",
+		PID:       1234,
+		Cgroup:    "0::/user.slice/user-1000.slice/user@1000.service/app.slice/snap.firefox.firefox.scope",
+		Interface: "home",
+		Constraints: client.PromptConstraints{
+			Path:                 "/home/test/Downloads/foo.pdf",
+			RequestedPermissions: []string{"read"},
+			AvailablePermissions: []string{"read", "write", "execute"},
+		},
+	})
+}
+
+func (cs *clientSuite) TestPrompt(c *C) {
+	cs.rsp = `{"type": "sync", "result": ` + mockPromptJSON + `}`
+	prompt, err := cs.cli.Prompt("0000000000000002")
+	c.Assert(err, IsNil)
+	c.Check(cs.req.Method, Equals, "GET")
+	c.Check(cs.req.URL.Path, Equals, "/v2/interfaces/requests/prompts/0000000000000002")
+	c.Check(prompt.ID, Equals, "0000000000000002")
+	c.Check(prompt.Constraints.Path, Equals, "/home/test/Downloads/foo.pdf")
+}
+
+func (cs *clientSuite) TestPromptNotFound(c *C) {
+	cs.status = 404
+	cs.rsp = `{"type": "error", "result": {"message": "cannot find prompt for the given ID", "kind": "interfaces-requests-prompt-not-found"}}`
+	_, err := cs.cli.Prompt("0000000000000002")
+	c.Assert(err, ErrorMatches, "cannot find prompt for the given ID")
+	c.Check(err.(*client.Error).Kind, Equals, client.ErrorKindInterfacesRequestsPromptNotFound)
+}
+
+func (cs *clientSuite) TestReplyToPrompt(c *C) {
+	cs.rsp = `{"type": "sync", "result": ["0000000000000002", "0000000000000005"]}`
+	satisfied, err := cs.cli.ReplyToPrompt("0000000000000002", &client.PromptReply{
+		Action:   "allow",
+		Lifespan: "timespan",
+		Duration: "10m",
+		Constraints: client.PromptReplyConstraints{
+			PathPattern: "/home/test/Downloads/*.pdf",
+			Permissions: []string{"read"},
+		},
+	})
+	c.Assert(err, IsNil)
+	c.Check(satisfied, DeepEquals, []string{"0000000000000002", "0000000000000005"})
+	c.Check(cs.req.Method, Equals, "POST")
+	c.Check(cs.req.URL.Path, Equals, "/v2/interfaces/requests/prompts/0000000000000002")
+
+	body, err := io.ReadAll(cs.req.Body)
+	c.Assert(err, IsNil)
+	var m map[string]any
+	c.Assert(json.Unmarshal(body, &m), IsNil)
+	c.Check(m, DeepEquals, map[string]any{
+		"action":   "allow",
+		"lifespan": "timespan",
+		"duration": "10m",
+		"constraints": map[string]any{
+			"path-pattern": "/home/test/Downloads/*.pdf",
+			"permissions":  []any{"read"},
+		},
+	})
+}
@@ -64,6 +64,9 @@ type QuotaJournalRate struct {
 type QuotaJournalValues struct {
 	Size quantity.Size `json:"size,omitempty"`
 	*QuotaJournalRate
+	// Forward is the target the journal namespace of the group is
+	// forwarded to, "none" disables forwarding.
+	Forward string `json:"forward,omitempty"`
 }
 
 type QuotaValues struct {
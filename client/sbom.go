@@ -0,0 +1,41 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package client
+
+import (
+	"encoding/json"
+	"net/url"
+)
+
+// SBOM returns the software bill of materials of the installed snaps in
+// the given format, either "spdx" or "cyclonedx". The document is returned
+// as is for the caller to process or store.
+func (client *Client) SBOM(format string) (json.RawMessage, error) {
+	q := url.Values{}
+	if format != "" {
+		q.Set("format", format)
+	}
+
+	var doc json.RawMessage
+	if _, err := client.doSync("GET", "/v2/sbom", q, nil, nil, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
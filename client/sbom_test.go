@@ -0,0 +1,64 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package client_test
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+func (cs *clientSuite) TestClientSBOM(c *C) {
+	cs.rsp = `{
+		"type": "sync",
+		"status-code": 200,
+		"result": {"bomFormat": "CycloneDX", "specVersion": "1.5"}
+	}`
+
+	doc, err := cs.cli.SBOM("cyclonedx")
+	c.Assert(err, IsNil)
+	c.Check(string(doc), Equals, `{"bomFormat": "CycloneDX", "specVersion": "1.5"}`)
+	c.Check(cs.req.Method, Equals, "GET")
+	c.Check(cs.req.URL.Path, Equals, "/v2/sbom")
+	c.Check(cs.req.URL.Query().Get("format"), Equals, "cyclonedx")
+}
+
+func (cs *clientSuite) TestClientSBOMDefaultFormat(c *C) {
+	cs.rsp = `{
+		"type": "sync",
+		"status-code": 200,
+		"result": {"spdxVersion": "SPDX-2.3"}
+	}`
+
+	_, err := cs.cli.SBOM("")
+	c.Assert(err, IsNil)
+	c.Check(cs.req.URL.Path, Equals, "/v2/sbom")
+	c.Check(cs.req.URL.RawQuery, Equals, "")
+}
+
+func (cs *clientSuite) TestClientSBOMError(c *C) {
+	cs.status = 400
+	cs.rsp = `{
+		"type": "error",
+		"status-code": 400,
+		"result": {"message": "invalid format \"swid\", expected \"spdx\" or \"cyclonedx\""}
+	}`
+
+	_, err := cs.cli.SBOM("swid")
+	c.Check(err, ErrorMatches, `invalid format "swid", expected "spdx" or "cyclonedx"`)
+}
@@ -21,7 +21,6 @@ package client
 
 import (
 	"bytes"
-	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -43,27 +42,31 @@ const (
 )
 
 type SnapOptions struct {
-	Channel          string          `json:"channel,omitempty"`
-	Revision         string          `json:"revision,omitempty"`
-	CohortKey        string          `json:"cohort-key,omitempty"`
-	LeaveCohort      bool            `json:"leave-cohort,omitempty"`
-	DevMode          bool            `json:"devmode,omitempty"`
-	JailMode         bool            `json:"jailmode,omitempty"`
-	Classic          bool            `json:"classic,omitempty"`
-	Dangerous        bool            `json:"dangerous,omitempty"`
-	IgnoreValidation bool            `json:"ignore-validation,omitempty"`
-	IgnoreRunning    bool            `json:"ignore-running,omitempty"`
-	Unaliased        bool            `json:"unaliased,omitempty"`
-	Prefer           bool            `json:"prefer,omitempty"`
-	Purge            bool            `json:"purge,omitempty"`
-	Terminate        bool            `json:"terminate,omitempty"`
-	Amend            bool            `json:"amend,omitempty"`
-	Transaction      TransactionType `json:"transaction,omitempty"`
-	QuotaGroupName   string          `json:"quota-group,omitempty"`
-	ValidationSets   []string        `json:"validation-sets,omitempty"`
-	Time             string          `json:"time,omitempty"`
-	HoldLevel        string          `json:"hold-level,omitempty"`
-	Users            []string        `json:"users,omitempty"`
+	Channel              string          `json:"channel,omitempty"`
+	Revision             string          `json:"revision,omitempty"`
+	CohortKey            string          `json:"cohort-key,omitempty"`
+	LeaveCohort          bool            `json:"leave-cohort,omitempty"`
+	DevMode              bool            `json:"devmode,omitempty"`
+	JailMode             bool            `json:"jailmode,omitempty"`
+	Classic              bool            `json:"classic,omitempty"`
+	Dangerous            bool            `json:"dangerous,omitempty"`
+	IgnoreValidation     bool            `json:"ignore-validation,omitempty"`
+	IgnoreRunning        bool            `json:"ignore-running,omitempty"`
+	Unaliased            bool            `json:"unaliased,omitempty"`
+	Prefer               bool            `json:"prefer,omitempty"`
+	NoRestoreConnections bool            `json:"no-restore-connections,omitempty"`
+	Purge                bool            `json:"purge,omitempty"`
+	Terminate            bool            `json:"terminate,omitempty"`
+	KeepConfig           bool            `json:"keep-config,omitempty"`
+	KeepConnections      bool            `json:"keep-connections,omitempty"`
+	Amend                bool            `json:"amend,omitempty"`
+	Transaction          TransactionType `json:"transaction,omitempty"`
+	QuotaGroupName       string          `json:"quota-group,omitempty"`
+	ValidationSets       []string        `json:"validation-sets,omitempty"`
+	Time                 string          `json:"time,omitempty"`
+	HoldLevel            string          `json:"hold-level,omitempty"`
+	HoldWindows          string          `json:"hold-windows,omitempty"`
+	Users                []string        `json:"users,omitempty"`
 }
 
 func writeFieldBool(mw *multipart.Writer, key string, val bool) error {
@@ -103,6 +106,7 @@ func (opts *SnapOptions) writeOptionFields(mw *multipart.Writer) error {
 		{"ignore-running", opts.IgnoreRunning},
 		{"unaliased", opts.Unaliased},
 		{"prefer", opts.Prefer},
+		{"no-restore-connections", opts.NoRestoreConnections},
 	}
 	if opts.Transaction != "" {
 		if err := mw.WriteField("transaction", string(opts.Transaction)); err != nil {
@@ -126,16 +130,19 @@ type actionData struct {
 }
 
 type multiActionData struct {
-	Action         string              `json:"action"`
-	Snaps          []string            `json:"snaps,omitempty"`
-	Users          []string            `json:"users,omitempty"`
-	Transaction    TransactionType     `json:"transaction,omitempty"`
-	IgnoreRunning  bool                `json:"ignore-running,omitempty"`
-	Purge          bool                `json:"purge,omitempty"`
-	ValidationSets []string            `json:"validation-sets,omitempty"`
-	Time           string              `json:"time,omitempty"`
-	HoldLevel      string              `json:"hold-level,omitempty"`
-	Components     map[string][]string `json:"components,omitempty"`
+	Action          string              `json:"action"`
+	Snaps           []string            `json:"snaps,omitempty"`
+	Users           []string            `json:"users,omitempty"`
+	Transaction     TransactionType     `json:"transaction,omitempty"`
+	IgnoreRunning   bool                `json:"ignore-running,omitempty"`
+	Purge           bool                `json:"purge,omitempty"`
+	KeepConfig      bool                `json:"keep-config,omitempty"`
+	KeepConnections bool                `json:"keep-connections,omitempty"`
+	ValidationSets  []string            `json:"validation-sets,omitempty"`
+	Time            string              `json:"time,omitempty"`
+	HoldLevel       string              `json:"hold-level,omitempty"`
+	HoldWindows     string              `json:"hold-windows,omitempty"`
+	Components      map[string][]string `json:"components,omitempty"`
 }
 
 // Install adds the snap with the given name from the given channel (or
@@ -266,9 +273,12 @@ func (client *Client) doMultiSnapActionFull(actionName string, snaps []string, c
 		action.Transaction = options.Transaction
 		action.IgnoreRunning = options.IgnoreRunning
 		action.Purge = options.Purge
+		action.KeepConfig = options.KeepConfig
+		action.KeepConnections = options.KeepConnections
 		action.ValidationSets = options.ValidationSets
 		action.Time = options.Time
 		action.HoldLevel = options.HoldLevel
+		action.HoldWindows = options.HoldWindows
 	}
 
 	data, err := json.Marshal(&action)
@@ -480,9 +490,8 @@ func (client *Client) Download(name string, options *DownloadOptions) (dlInfo *D
 		headers["range"] = fmt.Sprintf("bytes: %d-", options.Resume)
 	}
 
-	// no deadline for downloads
-	ctx := context.Background()
-	rsp, err := client.raw(ctx, "POST", "/v2/download", nil, headers, bytes.NewBuffer(data))
+	// no deadline for downloads other than the one of the client context
+	rsp, err := client.raw(client.context(), "POST", "/v2/download", nil, headers, bytes.NewBuffer(data))
 	if err != nil {
 		return nil, nil, err
 	}
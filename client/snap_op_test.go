@@ -757,20 +757,23 @@ func (cs *clientSuite) TestClientOpTryModeDangerous(c *check.C) {
 
 func (cs *clientSuite) TestSnapOptionsSerialises(c *check.C) {
 	tests := map[string]client.SnapOptions{
-		"{}":                         {},
-		`{"channel":"edge"}`:         {Channel: "edge"},
-		`{"revision":"42"}`:          {Revision: "42"},
-		`{"cohort-key":"what"}`:      {CohortKey: "what"},
-		`{"leave-cohort":true}`:      {LeaveCohort: true},
-		`{"devmode":true}`:           {DevMode: true},
-		`{"jailmode":true}`:          {JailMode: true},
-		`{"classic":true}`:           {Classic: true},
-		`{"dangerous":true}`:         {Dangerous: true},
-		`{"ignore-validation":true}`: {IgnoreValidation: true},
-		`{"unaliased":true}`:         {Unaliased: true},
-		`{"purge":true}`:             {Purge: true},
-		`{"amend":true}`:             {Amend: true},
-		`{"prefer":true}`:            {Prefer: true},
+		"{}":                              {},
+		`{"channel":"edge"}`:              {Channel: "edge"},
+		`{"revision":"42"}`:               {Revision: "42"},
+		`{"cohort-key":"what"}`:           {CohortKey: "what"},
+		`{"leave-cohort":true}`:           {LeaveCohort: true},
+		`{"devmode":true}`:                {DevMode: true},
+		`{"jailmode":true}`:               {JailMode: true},
+		`{"classic":true}`:                {Classic: true},
+		`{"dangerous":true}`:              {Dangerous: true},
+		`{"ignore-validation":true}`:      {IgnoreValidation: true},
+		`{"unaliased":true}`:              {Unaliased: true},
+		`{"purge":true}`:                  {Purge: true},
+		`{"amend":true}`:                  {Amend: true},
+		`{"prefer":true}`:                 {Prefer: true},
+		`{"no-restore-connections":true}`: {NoRestoreConnections: true},
+		`{"keep-config":true}`:            {KeepConfig: true},
+		`{"keep-connections":true}`:       {KeepConnections: true},
 	}
 	for expected, opts := range tests {
 		buf, err := json.Marshal(&opts)
@@ -917,17 +920,19 @@ func (cs *clientSuite) TestClientHoldMany(c *check.C) {
 	}`
 
 	chgID, err := cs.cli.HoldRefreshesMany([]string{"foo", "bar"}, &client.SnapOptions{
-		Time:      "forever",
-		HoldLevel: "general",
+		Time:        "forever",
+		HoldLevel:   "general",
+		HoldWindows: "sat-sun",
 	})
 	c.Assert(err, check.IsNil)
 	c.Check(chgID, check.Equals, "12")
 
 	type req struct {
-		Action    string   `json:"action"`
-		Snaps     []string `json:"snaps"`
-		Time      string   `json:"time"`
-		HoldLevel string   `json:"hold-level"`
+		Action      string   `json:"action"`
+		Snaps       []string `json:"snaps"`
+		Time        string   `json:"time"`
+		HoldLevel   string   `json:"hold-level"`
+		HoldWindows string   `json:"hold-windows"`
 	}
 	body, err := io.ReadAll(cs.req.Body)
 	c.Assert(err, check.IsNil)
@@ -937,10 +942,11 @@ func (cs *clientSuite) TestClientHoldMany(c *check.C) {
 	c.Assert(err, check.IsNil)
 
 	c.Check(decodedBody, check.DeepEquals, req{
-		Action:    "hold",
-		Snaps:     []string{"foo", "bar"},
-		Time:      "forever",
-		HoldLevel: "general",
+		Action:      "hold",
+		Snaps:       []string{"foo", "bar"},
+		Time:        "forever",
+		HoldLevel:   "general",
+		HoldWindows: "sat-sun",
 	})
 	c.Check(cs.req.Header["Content-Type"], check.DeepEquals, []string{"application/json"})
 }
@@ -166,7 +166,11 @@ func (client *Client) snapctlPollLoop(changeID string, contextID string, header
 			return errors.New(stderr)
 		case 3:
 			// Not ready yet, wait and poll again.
-			time.Sleep(100 * time.Millisecond)
+			select {
+			case <-time.After(100 * time.Millisecond):
+			case <-client.context().Done():
+				return ConnectionError{client.context().Err()}
+			}
 			continue
 		default:
 			return fmt.Errorf("internal error: unexpected exit code %d", int64(num))
@@ -21,8 +21,10 @@ package client_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"time"
 
 	"gopkg.in/check.v1"
 
@@ -211,6 +213,47 @@ func (cs *clientSuite) TestClientRunSnapctlAsync(c *check.C) {
 	c.Check(payload2["args"], check.DeepEquals, []any{"is-ready", "123"})
 }
 
+func (cs *clientSuite) TestClientRunSnapctlAsyncCanceled(c *check.C) {
+	cs.rsps = []string{
+		`{
+			"type": "sync",
+			"status-code": 200,
+			"status": "OK",
+			"result": {
+				"stdout": "",
+				"stderr": "",
+				"change-id": "123"
+			}
+		}`,
+	}
+	// never ready
+	cs.rsp = `{
+		"type": "error",
+		"status-code": 200,
+		"status": "OK",
+		"result": {
+			"message": "unsuccessful with exit code: 3",
+			"kind": "unsuccessful",
+			"value": {
+				"stdout": "",
+				"stderr": "",
+				"exit-code": 3
+			}
+		}
+	}`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	options := &client.SnapCtlOptions{
+		ContextID: "1234ABCD",
+		Args:      []string{"install", "some-snap"},
+	}
+	_, _, err := cs.cli.WithContext(ctx).RunSnapctl(options, nil)
+	c.Assert(err, check.ErrorMatches, "cannot communicate with server: timeout exceeded while waiting for response")
+	c.Check(len(cs.reqs) > 1, check.Equals, true)
+}
+
 func (cs *clientSuite) TestClientRunSnapctlPollLoopErrors(c *check.C) {
 	// Initial response that triggers the poll loop (returns a change-id).
 	const initialRsp = `{
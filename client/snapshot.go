@@ -21,13 +21,15 @@ package client
 
 import (
 	"bytes"
-	"context"
 	"crypto/sha256"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"hash"
 	"io"
+	"net/http"
 	"net/url"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -39,6 +41,16 @@ import (
 // SnapshotExportMediaType is the media type used to identify snapshot exports in the API.
 const SnapshotExportMediaType = "application/x.snapd.snapshot"
 
+const (
+	// SnapshotChunkHashHeader carries the hex encoded sha256 hash of a
+	// chunk of a snapshot export. It is sent as a trailer with ranges of
+	// exports and as a header with chunks of uploads.
+	SnapshotChunkHashHeader = "Snap-Chunk-Sha256"
+	// SnapshotUploadIDHeader identifies the upload that a chunk of a
+	// snapshot export belongs to.
+	SnapshotUploadIDHeader = "Snap-Upload-Id"
+)
+
 var (
 	ErrSnapshotSetNotFound   = errors.New("no snapshot set with the given ID")
 	ErrSnapshotSnapsNotFound = errors.New("no snapshot for the requested snaps found in the set with the given ID")
@@ -50,6 +62,18 @@ type snapshotAction struct {
 	Action string   `json:"action"`
 	Snaps  []string `json:"snaps,omitempty"`
 	Users  []string `json:"users,omitempty"`
+	Deep   bool     `json:"deep,omitempty"`
+}
+
+// SnapshotCorruption describes an archive of a snapshot whose data does
+// not match what was saved.
+type SnapshotCorruption struct {
+	SetID uint64 `json:"set"`
+	Snap  string `json:"snap"`
+	// Entry is the archive in the snapshot, e.g. "archive.tgz" or
+	// "user/<username>.tgz".
+	Entry  string `json:"entry"`
+	Reason string `json:"reason"`
 }
 
 // A Snapshot is a collection of archives with a simple metadata json file
@@ -77,6 +101,10 @@ type Snapshot struct {
 	SHA3_384 map[string]string `json:"sha3-384"`
 	// the sum of the archive sizes
 	Size int64 `json:"size,omitempty"`
+	// set if the archives' data is stored as content-defined chunks in a
+	// chunk store shared with other snapshots, the snapshot itself then
+	// only lists the chunks of each archive
+	Incremental bool `json:"incremental,omitempty"`
 
 	// dynamic snapshot options
 	Options *snap.SnapshotOptions `json:"options,omitempty"`
@@ -182,6 +210,32 @@ func (client *Client) SnapshotSets(setID uint64, snapNames []string) ([]Snapshot
 	return snapshotSets, err
 }
 
+// KeptConfig describes the configuration of a snap removed with
+// --keep-config, which is restored when the snap is installed again.
+type KeptConfig struct {
+	Snap     string        `json:"snap"`
+	Revision snap.Revision `json:"revision"`
+	// Time is when the snap was removed.
+	Time time.Time `json:"time"`
+	// Keys are the top-level configuration options that are kept.
+	Keys []string `json:"keys,omitempty"`
+}
+
+// KeptConfigs lists the configurations of removed snaps that are kept to
+// be restored when the snaps are installed again, limited to the given
+// snaps (if non-empty).
+func (client *Client) KeptConfigs(snapNames []string) ([]KeptConfig, error) {
+	q := make(url.Values)
+	q.Add("kept-configs", "true")
+	if len(snapNames) > 0 {
+		q.Add("snaps", strings.Join(snapNames, ","))
+	}
+
+	var keptConfigs []KeptConfig
+	_, err := client.doSync("GET", "/v2/snapshots", q, nil, nil, &keptConfigs)
+	return keptConfigs, err
+}
+
 // ForgetSnapshots permanently removes the snapshot set, limited to the
 // given snaps (if non-empty).
 func (client *Client) ForgetSnapshots(setID uint64, snaps []string) (changeID string, err error) {
@@ -205,6 +259,21 @@ func (client *Client) CheckSnapshots(setID uint64, snaps []string, users []strin
 	})
 }
 
+// DeepCheckSnapshots verifies the archives in the given snapshot set like
+// CheckSnapshots, but it checks all the archives instead of stopping at
+// the first corrupted one, and it reads the archives in full. The
+// corrupted archives are listed as SnapshotCorruption entries in the
+// "snapshot-corruptions" data of the change.
+func (client *Client) DeepCheckSnapshots(setID uint64, snaps []string, users []string) (changeID string, err error) {
+	return client.snapshotAction(&snapshotAction{
+		SetID:  setID,
+		Action: "check",
+		Snaps:  snaps,
+		Users:  users,
+		Deep:   true,
+	})
+}
+
 // RestoreSnapshots extracts the given snapshot set.
 //
 // If snaps or users are non-empty, limit to checking only those
@@ -235,7 +304,7 @@ func (client *Client) snapshotAction(action *snapshotAction) (changeID string, e
 //
 // The return value includes the length of the returned stream.
 func (client *Client) SnapshotExport(setID uint64) (stream io.ReadCloser, contentLength int64, err error) {
-	rsp, err := client.raw(context.Background(), "GET", fmt.Sprintf("/v2/snapshots/%v/export", setID), nil, nil, nil)
+	rsp, err := client.raw(client.context(), "GET", fmt.Sprintf("/v2/snapshots/%v/export", setID), nil, nil, nil)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -260,6 +329,118 @@ func (client *Client) SnapshotExport(setID uint64) (stream io.ReadCloser, conten
 	return rsp.Body, rsp.ContentLength, nil
 }
 
+// SnapshotExportRangeInfo describes a range of a snapshot export.
+type SnapshotExportRangeInfo struct {
+	Offset int64
+	Length int64
+	// Size is the size of the whole export.
+	Size int64
+	// ETag identifies the export, it can be passed when requesting
+	// further ranges to ensure they belong to the same export.
+	ETag string
+}
+
+var snapshotContentRangeRegexp = regexp.MustCompile(`^bytes (\d+)-(\d+)/(\d+)$`)
+
+// SnapshotExportRange streams length bytes of the export of the given
+// snapshot set starting at offset, or the rest of the export if length is
+// 0. If etag is set the range must belong to the export with that ETag.
+//
+// Reading the stream fails at its end if the data does not match the hash
+// that snapd computed for it.
+func (client *Client) SnapshotExportRange(setID uint64, offset, length int64, etag string) (stream io.ReadCloser, info *SnapshotExportRangeInfo, err error) {
+	if offset < 0 || length < 0 {
+		return nil, nil, fmt.Errorf("cannot request range of %d bytes at offset %d of snapshot export", length, offset)
+	}
+	headers := map[string]string{
+		"Range": fmt.Sprintf("bytes=%d-", offset),
+	}
+	if length > 0 {
+		headers["Range"] = fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)
+	}
+	if etag != "" {
+		headers["If-Range"] = strconv.Quote(etag)
+	}
+	rsp, err := client.raw(client.context(), "GET", fmt.Sprintf("/v2/snapshots/%v/export", setID), nil, headers, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if rsp.StatusCode != 206 {
+		defer rsp.Body.Close()
+
+		if rsp.StatusCode == 200 {
+			// the export does not match the ETag anymore
+			return nil, nil, fmt.Errorf("cannot get range of snapshot export: export of set %v changed", setID)
+		}
+		var r response
+		if err := decodeInto(rsp.Body, &r); err == nil {
+			if specificErr := r.err(client, rsp.StatusCode); specificErr != nil {
+				return nil, nil, specificErr
+			}
+		}
+		return nil, nil, fmt.Errorf("unexpected status code: %v", rsp.Status)
+	}
+
+	info, err = parseSnapshotExportRangeInfo(rsp.Header)
+	if err != nil {
+		rsp.Body.Close()
+		return nil, nil, err
+	}
+	return &snapshotChunkVerifier{rsp: rsp, h: sha256.New()}, info, nil
+}
+
+func parseSnapshotExportRangeInfo(header http.Header) (*SnapshotExportRangeInfo, error) {
+	contentRange := header.Get("Content-Range")
+	subs := snapshotContentRangeRegexp.FindStringSubmatch(contentRange)
+	if subs == nil {
+		return nil, fmt.Errorf("cannot parse snapshot export Content-Range %q", contentRange)
+	}
+	var first, last, size int64
+	for i, v := range []*int64{&first, &last, &size} {
+		n, err := strconv.ParseInt(subs[i+1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse snapshot export Content-Range %q: %v", contentRange, err)
+		}
+		*v = n
+	}
+	etag, err := strconv.Unquote(header.Get("ETag"))
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse snapshot export ETag %q: %v", header.Get("ETag"), err)
+	}
+	return &SnapshotExportRangeInfo{
+		Offset: first,
+		Length: last - first + 1,
+		Size:   size,
+		ETag:   etag,
+	}, nil
+}
+
+// snapshotChunkVerifier checks that the data of a range of a snapshot
+// export matches the hash in the trailer of the response.
+type snapshotChunkVerifier struct {
+	rsp *http.Response
+	h   hash.Hash
+}
+
+func (v *snapshotChunkVerifier) Read(p []byte) (int, error) {
+	n, err := v.rsp.Body.Read(p)
+	v.h.Write(p[:n])
+	if err == io.EOF {
+		expected := v.rsp.Trailer.Get(SnapshotChunkHashHeader)
+		if expected == "" {
+			return n, fmt.Errorf("cannot verify range of snapshot export: no hash received")
+		}
+		if fmt.Sprintf("%x", v.h.Sum(nil)) != expected {
+			return n, fmt.Errorf("range of snapshot export does not match its sha256 hash")
+		}
+	}
+	return n, err
+}
+
+func (v *snapshotChunkVerifier) Close() error {
+	return v.rsp.Body.Close()
+}
+
 // SnapshotImportSet is a snapshot import created by a "snap import-snapshot".
 type SnapshotImportSet struct {
 	ID    uint64   `json:"set-id"`
@@ -280,3 +461,51 @@ func (client *Client) SnapshotImport(exportStream io.Reader, size int64) (Snapsh
 
 	return importSet, nil
 }
+
+// SnapshotImportUpload is a snapshot export being uploaded in chunks to be
+// imported.
+type SnapshotImportUpload struct {
+	ID       string `json:"upload-id"`
+	Size     int64  `json:"size"`
+	Received int64  `json:"received"`
+
+	// SetID and Snaps are set once the whole export was uploaded and
+	// imported.
+	SetID uint64   `json:"set-id,omitempty"`
+	Snaps []string `json:"snaps,omitempty"`
+}
+
+// SnapshotImportChunk uploads a chunk of a snapshot export of the given
+// size that starts at offset. The first chunk starts a new upload and is
+// sent without an upload ID, the following ones must use the ID of the
+// returned upload. The export is imported once its last chunk is uploaded.
+func (client *Client) SnapshotImportChunk(uploadID string, chunk []byte, offset, size int64) (*SnapshotImportUpload, error) {
+	if len(chunk) == 0 {
+		return nil, fmt.Errorf("cannot upload empty chunk of snapshot export")
+	}
+	headers := map[string]string{
+		"Content-Type":          SnapshotExportMediaType,
+		"Content-Length":        strconv.Itoa(len(chunk)),
+		"Content-Range":         fmt.Sprintf("bytes %d-%d/%d", offset, offset+int64(len(chunk))-1, size),
+		SnapshotChunkHashHeader: fmt.Sprintf("%x", sha256.Sum256(chunk)),
+	}
+	if uploadID != "" {
+		headers[SnapshotUploadIDHeader] = uploadID
+	}
+
+	var upload SnapshotImportUpload
+	if _, err := client.doSync("POST", "/v2/snapshots", nil, headers, bytes.NewReader(chunk), &upload); err != nil {
+		return nil, err
+	}
+	return &upload, nil
+}
+
+// SnapshotImportUploadStatus returns how much of the given snapshot export
+// upload was received, to resume it from there.
+func (client *Client) SnapshotImportUploadStatus(uploadID string) (*SnapshotImportUpload, error) {
+	var upload SnapshotImportUpload
+	if _, err := client.doSync("GET", "/v2/snapshots/uploads/"+url.PathEscape(uploadID), nil, nil, nil, &upload); err != nil {
+		return nil, err
+	}
+	return &upload, nil
+}
@@ -21,6 +21,7 @@ package client_test
 
 import (
 	"crypto/sha256"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
@@ -98,7 +99,28 @@ func (cs *clientSuite) TestClientSnapshotSets(c *check.C) {
 	})
 }
 
-func (cs *clientSuite) testClientSnapshotActionFull(c *check.C, action string, users []string, f func() (string, error)) {
+func (cs *clientSuite) TestClientKeptConfigs(c *check.C) {
+	cs.rsp = `{
+		"type": "sync",
+		"result": [{"snap": "foo", "revision": "7", "time": "2026-10-01T10:00:00Z", "keys": ["a", "b"]}]
+}`
+	kept, err := cs.cli.KeptConfigs([]string{"foo", "bar"})
+	c.Assert(err, check.IsNil)
+	c.Check(kept, check.DeepEquals, []client.KeptConfig{{
+		Snap:     "foo",
+		Revision: snap.R(7),
+		Time:     time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC),
+		Keys:     []string{"a", "b"},
+	}})
+	c.Check(cs.req.Method, check.Equals, "GET")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/snapshots")
+	c.Check(cs.req.URL.Query(), check.DeepEquals, url.Values{
+		"kept-configs": []string{"true"},
+		"snaps":        []string{"foo,bar"},
+	})
+}
+
+func (cs *clientSuite) testClientSnapshotActionFull(c *check.C, action string, users []string, f func() (string, error)) (deep bool) {
 	cs.status = 202
 	cs.rsp = `{
 		"status-code": 202,
@@ -121,6 +143,8 @@ func (cs *clientSuite) testClientSnapshotActionFull(c *check.C, action string, u
 	c.Check(cs.req.Method, check.Equals, "POST")
 	c.Check(cs.req.URL.Path, check.Equals, "/v2/snapshots")
 	c.Check(cs.req.URL.Query(), check.HasLen, 0)
+
+	return act.Deep
 }
 
 func (cs *clientSuite) TestClientForgetSnapshot(c *check.C) {
@@ -129,14 +153,20 @@ func (cs *clientSuite) TestClientForgetSnapshot(c *check.C) {
 	})
 }
 
-func (cs *clientSuite) testClientSnapshotAction(c *check.C, action string, f func(uint64, []string, []string) (string, error)) {
-	cs.testClientSnapshotActionFull(c, action, []string{"auser", "buser"}, func() (string, error) {
+func (cs *clientSuite) testClientSnapshotAction(c *check.C, action string, f func(uint64, []string, []string) (string, error)) (deep bool) {
+	return cs.testClientSnapshotActionFull(c, action, []string{"auser", "buser"}, func() (string, error) {
 		return f(42, []string{"asnap", "bsnap"}, []string{"auser", "buser"})
 	})
 }
 
 func (cs *clientSuite) TestClientCheckSnapshots(c *check.C) {
-	cs.testClientSnapshotAction(c, "check", cs.cli.CheckSnapshots)
+	deep := cs.testClientSnapshotAction(c, "check", cs.cli.CheckSnapshots)
+	c.Check(deep, check.Equals, false)
+}
+
+func (cs *clientSuite) TestClientDeepCheckSnapshots(c *check.C) {
+	deep := cs.testClientSnapshotAction(c, "check", cs.cli.DeepCheckSnapshots)
+	c.Check(deep, check.Equals, true)
 }
 
 func (cs *clientSuite) TestClientRestoreSnapshots(c *check.C) {
@@ -229,6 +259,119 @@ func (cs *clientSuite) TestClientSnapshotImport(c *check.C) {
 	}
 }
 
+func (cs *clientSuite) TestClientSnapshotExportRange(c *check.C) {
+	content := "some-range"
+	cs.rsp = content
+	cs.status = 206
+	cs.header = http.Header{
+		"Content-Type":  []string{client.SnapshotExportMediaType},
+		"Content-Range": []string{"bytes 10-19/100"},
+		"Etag":          []string{`"abc"`},
+	}
+	cs.trailer = http.Header{
+		client.SnapshotChunkHashHeader: []string{fmt.Sprintf("%x", sha256.Sum256([]byte(content)))},
+	}
+
+	r, info, err := cs.cli.SnapshotExportRange(42, 10, 10, "abc")
+	c.Assert(err, check.IsNil)
+	c.Check(cs.req.Method, check.Equals, "GET")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/snapshots/42/export")
+	c.Check(cs.req.Header.Get("Range"), check.Equals, "bytes=10-19")
+	c.Check(cs.req.Header.Get("If-Range"), check.Equals, `"abc"`)
+	c.Check(info, check.DeepEquals, &client.SnapshotExportRangeInfo{
+		Offset: 10,
+		Length: 10,
+		Size:   100,
+		ETag:   "abc",
+	})
+	data, err := io.ReadAll(r)
+	c.Assert(err, check.IsNil)
+	c.Check(string(data), check.Equals, content)
+	c.Assert(r.Close(), check.IsNil)
+	c.Check(cs.countingCloser.closeCalled, check.Equals, 1)
+
+	// open ended range
+	_, _, err = cs.cli.SnapshotExportRange(42, 10, 0, "")
+	c.Assert(err, check.IsNil)
+	c.Check(cs.req.Header.Get("Range"), check.Equals, "bytes=10-")
+	c.Check(cs.req.Header.Get("If-Range"), check.Equals, "")
+
+	// data that does not match the hash
+	cs.trailer = http.Header{
+		client.SnapshotChunkHashHeader: []string{"1234"},
+	}
+	r, _, err = cs.cli.SnapshotExportRange(42, 10, 10, "abc")
+	c.Assert(err, check.IsNil)
+	_, err = io.ReadAll(r)
+	c.Check(err, check.ErrorMatches, "range of snapshot export does not match its sha256 hash")
+
+	cs.trailer = nil
+	r, _, err = cs.cli.SnapshotExportRange(42, 10, 10, "abc")
+	c.Assert(err, check.IsNil)
+	_, err = io.ReadAll(r)
+	c.Check(err, check.ErrorMatches, "cannot verify range of snapshot export: no hash received")
+}
+
+func (cs *clientSuite) TestClientSnapshotExportRangeErrors(c *check.C) {
+	// the export changed
+	cs.rsp = "the-whole-export"
+	cs.status = 200
+	cs.header = http.Header{"Content-Type": []string{client.SnapshotExportMediaType}}
+	_, _, err := cs.cli.SnapshotExportRange(42, 10, 10, "abc")
+	c.Check(err, check.ErrorMatches, "cannot get range of snapshot export: export of set 42 changed")
+	c.Check(cs.countingCloser.closeCalled, check.Equals, 1)
+
+	cs.rsp = `{"type":"error","status-code":400,"result":{"message":"boom"}}`
+	cs.status = 400
+	_, _, err = cs.cli.SnapshotExportRange(42, 10, 10, "abc")
+	c.Check(err, check.ErrorMatches, "boom")
+
+	cs.rsp = "data"
+	cs.status = 206
+	cs.header = http.Header{"Content-Range": []string{"bytes 10-19"}}
+	_, _, err = cs.cli.SnapshotExportRange(42, 10, 10, "abc")
+	c.Check(err, check.ErrorMatches, `cannot parse snapshot export Content-Range "bytes 10-19"`)
+
+	_, _, err = cs.cli.SnapshotExportRange(42, -1, 10, "abc")
+	c.Check(err, check.ErrorMatches, "cannot request range of 10 bytes at offset -1 of snapshot export")
+}
+
+func (cs *clientSuite) TestClientSnapshotImportChunk(c *check.C) {
+	cs.rsp = `{"type": "sync", "result": {"upload-id": "some-id", "size": 11, "received": 6}}`
+	upload, err := cs.cli.SnapshotImportChunk("", []byte("hello "), 0, 11)
+	c.Assert(err, check.IsNil)
+	c.Check(upload, check.DeepEquals, &client.SnapshotImportUpload{ID: "some-id", Size: 11, Received: 6})
+	c.Check(cs.req.Method, check.Equals, "POST")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/snapshots")
+	c.Check(cs.req.Header.Get("Content-Type"), check.Equals, client.SnapshotExportMediaType)
+	c.Check(cs.req.Header.Get("Content-Length"), check.Equals, "6")
+	c.Check(cs.req.Header.Get("Content-Range"), check.Equals, "bytes 0-5/11")
+	c.Check(cs.req.Header.Get(client.SnapshotChunkHashHeader), check.Equals, fmt.Sprintf("%x", sha256.Sum256([]byte("hello "))))
+	c.Check(cs.req.Header.Get(client.SnapshotUploadIDHeader), check.Equals, "")
+	data, err := io.ReadAll(cs.req.Body)
+	c.Assert(err, check.IsNil)
+	c.Check(string(data), check.Equals, "hello ")
+
+	cs.rsp = `{"type": "sync", "result": {"upload-id": "some-id", "size": 11, "received": 11, "set-id": 3, "snaps": ["foo"]}}`
+	upload, err = cs.cli.SnapshotImportChunk("some-id", []byte("world"), 6, 11)
+	c.Assert(err, check.IsNil)
+	c.Check(upload, check.DeepEquals, &client.SnapshotImportUpload{ID: "some-id", Size: 11, Received: 11, SetID: 3, Snaps: []string{"foo"}})
+	c.Check(cs.req.Header.Get("Content-Range"), check.Equals, "bytes 6-10/11")
+	c.Check(cs.req.Header.Get(client.SnapshotUploadIDHeader), check.Equals, "some-id")
+
+	_, err = cs.cli.SnapshotImportChunk("some-id", nil, 6, 11)
+	c.Check(err, check.ErrorMatches, "cannot upload empty chunk of snapshot export")
+}
+
+func (cs *clientSuite) TestClientSnapshotImportUploadStatus(c *check.C) {
+	cs.rsp = `{"type": "sync", "result": {"upload-id": "some-id", "size": 11, "received": 6}}`
+	upload, err := cs.cli.SnapshotImportUploadStatus("some-id")
+	c.Assert(err, check.IsNil)
+	c.Check(upload, check.DeepEquals, &client.SnapshotImportUpload{ID: "some-id", Size: 11, Received: 6})
+	c.Check(cs.req.Method, check.Equals, "GET")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/snapshots/uploads/some-id")
+}
+
 func (cs *clientSuite) TestClientSnapshotContentHash(c *check.C) {
 	now := time.Now()
 	revno := snap.R(1)
@@ -243,6 +243,12 @@ const (
 	// this step.
 	InstallStepGenerateRecoveryKey InstallStep = "generate-recovery-key"
 
+	// Reports the sealing method and the PCR profile that the keys
+	// of the encrypted volumes will be bound to in the "finish"
+	// step. InstallStepSetupStorageEncryption must be called before
+	// calling this step.
+	InstallStepPreviewSealing InstallStep = "preview-sealing"
+
 	// Creates a change to finish the installation. The change
 	// ensures all volume structure content is written to disk, it
 	// sets up boot, kernel etc and when finished the installed
@@ -291,7 +297,9 @@ type InstallSystemOptions struct {
 	Step InstallStep `json:"step,omitempty"`
 
 	// OnVolumes is the volume description of the volumes that the
-	// given step should operate on.
+	// given step should operate on. Gadgets can define multiple
+	// volumes, each of them installed to a different disk which is
+	// selected by setting the AssignedDevice of the volume.
 	OnVolumes map[string]*gadget.Volume `json:"on-volumes,omitempty"`
 	// OptionalInstall contains the optional snaps and components that should be
 	// installed on the system. Omitting this field will result in all optional
@@ -378,6 +386,56 @@ func (client *Client) GeneratePreInstallRecoveryKey(systemLabel string) (recover
 	return rsp.RecoveryKey, nil
 }
 
+// InstallSealingPreview describes how the keys of the encrypted volumes
+// will be sealed in the finish step `InstallStepFinish`.
+type InstallSealingPreview struct {
+	// SealingMethod is the method used to seal the keys.
+	SealingMethod device.SealingMethod `json:"sealing-method"`
+	// VolumesAuthMode is the authentication mode of the encrypted
+	// volumes, if any.
+	VolumesAuthMode device.AuthMode `json:"volumes-auth-mode,omitempty"`
+	// RecoveryKey is true if a pre-install recovery key will be
+	// enrolled.
+	RecoveryKey bool `json:"recovery-key"`
+	// PCRProfile is the PCR profile the keys are bound to when
+	// sealing with the TPM.
+	PCRProfile *secboot.PCRProfilePreview `json:"pcr-profile,omitempty"`
+}
+
+// PreviewInstallSealing reports the sealing method and the PCR profile
+// that will be used to seal the keys of the encrypted volumes in the
+// finish step `InstallStepFinish`. It fails if the TPM is not in a state
+// suitable for sealing.
+//
+// Note: `InstallStepSetupStorageEncryption` must be called before
+// previewing the sealing.
+func (client *Client) PreviewInstallSealing(systemLabel string) (*InstallSealingPreview, error) {
+	if systemLabel == "" {
+		return nil, fmt.Errorf("cannot preview sealing with an empty system label")
+	}
+
+	req := struct {
+		Action string `json:"action"`
+		*InstallSystemOptions
+	}{
+		Action: "install",
+		InstallSystemOptions: &InstallSystemOptions{
+			Step: InstallStepPreviewSealing,
+		},
+	}
+
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(&req); err != nil {
+		return nil, err
+	}
+
+	var rsp InstallSealingPreview
+	if _, err := client.doSync("POST", "/v2/systems/"+systemLabel, nil, nil, &body, &rsp); err != nil {
+		return nil, fmt.Errorf("cannot preview sealing for system %q: %v", systemLabel, err)
+	}
+	return &rsp, nil
+}
+
 // CreateSystemOptions contains the options for creating a new recovery system.
 type CreateSystemOptions struct {
 	// Label is the label of the new system.
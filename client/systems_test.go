@@ -30,6 +30,7 @@ import (
 	"github.com/snapcore/snapd/gadget"
 	"github.com/snapcore/snapd/gadget/device"
 	"github.com/snapcore/snapd/osutil/keyboard"
+	"github.com/snapcore/snapd/secboot"
 	"github.com/snapcore/snapd/snap"
 )
 
@@ -431,6 +432,53 @@ func (cs *clientSuite) TestRequestSystemInstallHappy(c *check.C) {
 	})
 }
 
+func (cs *clientSuite) TestRequestSystemInstallMultipleVolumes(c *check.C) {
+	cs.status = 202
+	cs.rsp = `{
+		"type": "async",
+		"status-code": 202,
+		"change": "42"
+	}`
+	opts := &client.InstallSystemOptions{
+		Step: client.InstallStepSetupStorageEncryption,
+		OnVolumes: map[string]*gadget.Volume{
+			"pc": {
+				Schema:         "gpt",
+				AssignedDevice: "/dev/vda",
+			},
+			"extra": {
+				Schema:         "gpt",
+				AssignedDevice: "/dev/vdb",
+			},
+		},
+	}
+	chgID, err := cs.cli.InstallSystem("1234", opts)
+	c.Assert(err, check.IsNil)
+	c.Assert(chgID, check.Equals, "42")
+
+	body, err := io.ReadAll(cs.req.Body)
+	c.Assert(err, check.IsNil)
+	var req map[string]any
+	err = json.Unmarshal(body, &req)
+	c.Assert(err, check.IsNil)
+	c.Assert(req["on-volumes"], check.DeepEquals, map[string]any{
+		"pc": map[string]any{
+			"schema":          "gpt",
+			"bootloader":      "",
+			"id":              "",
+			"structure":       nil,
+			"assigned-device": "/dev/vda",
+		},
+		"extra": map[string]any{
+			"schema":          "gpt",
+			"bootloader":      "",
+			"id":              "",
+			"structure":       nil,
+			"assigned-device": "/dev/vdb",
+		},
+	})
+}
+
 func (cs *clientSuite) TestRequestGeneratePreInstallRecoveryKey(c *check.C) {
 	cs.rsp = `{
 	    "type": "sync",
@@ -478,6 +526,71 @@ func (cs *clientSuite) TestRequestGeneratePreInstallRecoveryKeyError(c *check.C)
 	c.Check(cs.req.URL.Path, check.Equals, "/v2/systems/1234")
 }
 
+func (cs *clientSuite) TestRequestPreviewInstallSealing(c *check.C) {
+	cs.rsp = `{
+	    "type": "sync",
+	    "status-code": 200,
+	    "result": {
+			"sealing-method": "tpm",
+			"volumes-auth-mode": "passphrase",
+			"recovery-key": true,
+			"pcr-profile": {
+				"pcr-alg": "sha256",
+				"pcrs": [0, 2, 4, 7, 12],
+				"options": ["trust-authorities-for-boot-code"],
+				"accepted-errors": ["tpm-hierarchies-owned"]
+			}
+		}
+	}`
+
+	preview, err := cs.cli.PreviewInstallSealing("1234")
+	c.Assert(err, check.IsNil)
+	c.Check(preview, check.DeepEquals, &client.InstallSealingPreview{
+		SealingMethod:   device.SealingMethodTPM,
+		VolumesAuthMode: device.AuthModePassphrase,
+		RecoveryKey:     true,
+		PCRProfile: &secboot.PCRProfilePreview{
+			PCRAlg:         "sha256",
+			PCRs:           []int{0, 2, 4, 7, 12},
+			Options:        []string{"trust-authorities-for-boot-code"},
+			AcceptedErrors: []string{"tpm-hierarchies-owned"},
+		},
+	})
+
+	c.Check(cs.req.Method, check.Equals, "POST")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/systems/1234")
+
+	body, err := io.ReadAll(cs.req.Body)
+	c.Assert(err, check.IsNil)
+	var req map[string]any
+	err = json.Unmarshal(body, &req)
+	c.Assert(err, check.IsNil)
+	c.Assert(req, check.DeepEquals, map[string]any{
+		"action": "install",
+		"step":   "preview-sealing",
+	})
+}
+
+func (cs *clientSuite) TestRequestPreviewInstallSealingNoLabel(c *check.C) {
+	_, err := cs.cli.PreviewInstallSealing("")
+	c.Assert(err, check.ErrorMatches, "cannot preview sealing with an empty system label")
+	// no request was performed
+	c.Check(cs.req, check.IsNil)
+}
+
+func (cs *clientSuite) TestRequestPreviewInstallSealingError(c *check.C) {
+	cs.rsp = `{
+	    "type": "error",
+	    "status-code": 400,
+	    "result": {"message": "cannot seal keys with the TPM: TPM device is not enabled"}
+	}`
+
+	_, err := cs.cli.PreviewInstallSealing("1234")
+	c.Assert(err, check.ErrorMatches, `cannot preview sealing for system "1234": cannot seal keys with the TPM: TPM device is not enabled`)
+	c.Check(cs.req.Method, check.Equals, "POST")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/systems/1234")
+}
+
 func (s *clientSuite) TestKeyboardConfigXKBConfig(c *check.C) {
 	kb := client.KeyboardConfig{
 		Model:   "pc105",
@@ -24,6 +24,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+
+	"github.com/snapcore/snapd/snap"
 )
 
 // ValidateApplyOptions carries options for ApplyValidationSet.
@@ -127,3 +129,37 @@ func (client *Client) ValidationSet(accountID, name string, sequence int) (*Vali
 	}
 	return res, nil
 }
+
+// ValidationSetSnapStatus describes how an installed snap breaks the
+// tracked validation sets.
+type ValidationSetSnapStatus struct {
+	Name string `json:"name"`
+	// Problem is one of "missing", "invalid" or "wrong-revision".
+	Problem          string        `json:"problem"`
+	Revision         snap.Revision `json:"revision,omitempty"`
+	RequiredRevision snap.Revision `json:"required-revision,omitempty"`
+	ValidationSets   []string      `json:"validation-sets"`
+	// Fix is one of "install", "refresh" or "remove", it is unset when
+	// the validation sets are in conflict.
+	Fix string `json:"fix,omitempty"`
+}
+
+// ValidationSetsStatus holds the status of all the tracked validation sets,
+// checked together.
+type ValidationSetsStatus struct {
+	ValidationSets []*ValidationSetResult `json:"validation-sets"`
+	// Conflict is set when the validation sets cannot be satisfied
+	// together.
+	Conflict string                     `json:"conflict,omitempty"`
+	Snaps    []*ValidationSetSnapStatus `json:"snaps,omitempty"`
+}
+
+// ValidationSetsStatus queries which snaps break the tracked validation sets
+// and how to fix them.
+func (client *Client) ValidationSetsStatus() (*ValidationSetsStatus, error) {
+	var res *ValidationSetsStatus
+	if _, err := client.doSync("GET", "/v2/validation-sets/status", nil, nil, nil, &res); err != nil {
+		return nil, fmt.Errorf("cannot query validation sets status: %w", err)
+	}
+	return res, nil
+}
@@ -27,6 +27,7 @@ import (
 	"gopkg.in/check.v1"
 
 	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/snap"
 )
 
 var errorResponseJSON = `{
@@ -238,3 +239,42 @@ func (cs *clientSuite) TestValidationSetWithSequence(c *check.C) {
 		AccountID: "abc", Name: "def", Mode: "monitor", Sequence: 9, Valid: false,
 	})
 }
+
+func (cs *clientSuite) TestValidationSetsStatus(c *check.C) {
+	cs.rsp = `{
+		"type": "sync",
+		"status-code": 200,
+		"result": {
+			"validation-sets": [{"account-id": "abc", "name": "def", "mode": "enforce", "sequence": 2}],
+			"snaps": [
+				{"name": "foo", "problem": "wrong-revision", "revision": "3", "required-revision": "5", "validation-sets": ["abc/def"], "fix": "refresh"}
+			]
+		}
+	}`
+
+	status, err := cs.cli.ValidationSetsStatus()
+	c.Assert(err, check.IsNil)
+	c.Check(cs.req.Method, check.Equals, "GET")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/validation-sets/status")
+	c.Check(status, check.DeepEquals, &client.ValidationSetsStatus{
+		ValidationSets: []*client.ValidationSetResult{
+			{AccountID: "abc", Name: "def", Mode: "enforce", Sequence: 2},
+		},
+		Snaps: []*client.ValidationSetSnapStatus{{
+			Name:             "foo",
+			Problem:          "wrong-revision",
+			Revision:         snap.R(3),
+			RequiredRevision: snap.R(5),
+			ValidationSets:   []string{"abc/def"},
+			Fix:              "refresh",
+		}},
+	})
+}
+
+func (cs *clientSuite) TestValidationSetsStatusError(c *check.C) {
+	cs.status = 500
+	cs.rsp = errorResponseJSON
+
+	_, err := cs.cli.ValidationSetsStatus()
+	c.Assert(err, check.ErrorMatches, "cannot query validation sets status: failed")
+}
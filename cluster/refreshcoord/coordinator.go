@@ -0,0 +1,437 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package refreshcoord implements the coordination of snap refreshes across
+// the members of an assembled cluster. The members elect a leader which
+// staggers the refreshes of each clustered snap, allowing only one member at a
+// time to refresh and waiting for that member to report that the refreshed
+// snap is healthy before letting the next one proceed. Refreshes can also be
+// held and resumed cluster-wide.
+package refreshcoord
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/snapcore/snapd/cluster/assemblestate"
+	"github.com/snapcore/snapd/strutil"
+)
+
+// ErrNotLeader is returned when an operation that must be performed by the
+// leader is attempted on another member.
+var ErrNotLeader = errors.New("cannot coordinate refreshes: not the cluster leader")
+
+// Config contains the configuration of a [Coordinator].
+type Config struct {
+	// RDT is the device token of this member.
+	RDT assemblestate.DeviceToken
+
+	// Members contains the device tokens of all the members of the cluster,
+	// including this one.
+	Members []assemblestate.DeviceToken
+
+	// HeartbeatTimeout is how long a member can go without sending a
+	// heartbeat before it is considered gone.
+	HeartbeatTimeout time.Duration
+
+	// HealthTimeout is how long a member that was granted a refresh has to
+	// report on it before the rollout of the snap is halted.
+	HealthTimeout time.Duration
+
+	// Clock is used to get the current time, time.Now is used if nil.
+	Clock func() time.Time
+}
+
+// Rollout tracks the staggered refresh of a snap across the cluster.
+type Rollout struct {
+	Refresh
+
+	// Done contains the members that refreshed the snap and reported it as
+	// healthy.
+	Done []assemblestate.DeviceToken `json:"done,omitempty"`
+
+	// Current is the member currently granted the refresh, if any.
+	Current assemblestate.DeviceToken `json:"current,omitempty"`
+
+	// Granted is when the refresh was granted to Current.
+	Granted time.Time `json:"granted,omitempty"`
+
+	// Halted explains why the rollout was halted. No further refreshes of the
+	// snap are granted until the snap is resumed.
+	Halted string `json:"halted,omitempty"`
+}
+
+// CoordinatorState is the persistable state of a [Coordinator].
+type CoordinatorState struct {
+	Holds    Holds              `json:"holds"`
+	Rollouts map[string]Rollout `json:"rollouts,omitempty"`
+}
+
+// Coordinator keeps track of this member's view of the cluster: which members
+// are alive, who the leader is, the cluster-wide refresh holds and, when this
+// member is the leader, the rollouts of clustered snaps.
+type Coordinator struct {
+	config Config
+
+	lock     sync.Mutex
+	seen     map[assemblestate.DeviceToken]time.Time
+	holds    Holds
+	rollouts map[string]*Rollout
+}
+
+// New creates a new [Coordinator], restoring the given state that was
+// previously obtained via [Coordinator.State].
+func New(config Config, cs CoordinatorState) (*Coordinator, error) {
+	if config.RDT == "" {
+		return nil, errors.New("cannot create refresh coordinator without a device token")
+	}
+	if !containsRDT(config.Members, config.RDT) {
+		return nil, fmt.Errorf("cannot create refresh coordinator: %q is not a cluster member", config.RDT)
+	}
+	if config.HeartbeatTimeout <= 0 || config.HealthTimeout <= 0 {
+		return nil, errors.New("cannot create refresh coordinator: timeouts must be positive")
+	}
+	if config.Clock == nil {
+		config.Clock = time.Now
+	}
+
+	c := &Coordinator{
+		config:   config,
+		seen:     make(map[assemblestate.DeviceToken]time.Time),
+		holds:    cs.Holds,
+		rollouts: make(map[string]*Rollout, len(cs.Rollouts)),
+	}
+	for name, r := range cs.Rollouts {
+		r := r
+		c.rollouts[name] = &r
+	}
+
+	// give every member a full heartbeat period before considering it gone
+	now := config.Clock()
+	for _, rdt := range config.Members {
+		c.seen[rdt] = now
+	}
+
+	return c, nil
+}
+
+// State returns the state of the coordinator, suitable for persisting and
+// passing to [New].
+func (c *Coordinator) State() CoordinatorState {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	cs := CoordinatorState{
+		Holds: c.holdsCopy(),
+	}
+	if len(c.rollouts) > 0 {
+		cs.Rollouts = make(map[string]Rollout, len(c.rollouts))
+		for name, r := range c.rollouts {
+			rc := *r
+			rc.Done = append([]assemblestate.DeviceToken(nil), r.Done...)
+			cs.Rollouts[name] = rc
+		}
+	}
+	return cs
+}
+
+// Heartbeat returns the heartbeat that this member should send to the other
+// members, given the refreshes that it is currently performing.
+func (c *Coordinator) Heartbeat(refreshing []Refresh) Heartbeat {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return Heartbeat{
+		RDT:        c.config.RDT,
+		Holds:      c.holdsCopy(),
+		Refreshing: refreshing,
+	}
+}
+
+// CommitHeartbeat records a heartbeat received from another member. Holds
+// with a newer version replace our own and, if we are the leader, refreshes
+// reported as in progress are adopted into their rollouts. This lets a newly
+// elected leader carry on where the previous one left off.
+func (c *Coordinator) CommitHeartbeat(hb Heartbeat) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if !containsRDT(c.config.Members, hb.RDT) {
+		return fmt.Errorf("cannot commit heartbeat from unknown member %q", hb.RDT)
+	}
+
+	c.seen[hb.RDT] = c.config.Clock()
+
+	if hb.Holds.Version > c.holds.Version {
+		c.holds = Holds{
+			Version: hb.Holds.Version,
+			All:     hb.Holds.All,
+			Snaps:   append([]string(nil), hb.Holds.Snaps...),
+		}
+	}
+
+	if c.leader() != c.config.RDT {
+		return nil
+	}
+
+	for _, ref := range hb.Refreshing {
+		r, ok := c.rollouts[ref.Snap]
+		if !ok {
+			r = &Rollout{Refresh: ref}
+			c.rollouts[ref.Snap] = r
+		}
+		if r.Revision != ref.Revision || r.Current != "" {
+			continue
+		}
+		r.Current = hb.RDT
+		r.Granted = c.config.Clock()
+	}
+
+	return nil
+}
+
+// Leader returns the device token of the current leader. The leader is the
+// alive member with the lowest device token, this member always considers
+// itself alive.
+func (c *Coordinator) Leader() assemblestate.DeviceToken {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.leader()
+}
+
+func (c *Coordinator) leader() assemblestate.DeviceToken {
+	leader := c.config.RDT
+	for _, rdt := range c.config.Members {
+		if rdt < leader && c.alive(rdt) {
+			leader = rdt
+		}
+	}
+	return leader
+}
+
+func (c *Coordinator) alive(rdt assemblestate.DeviceToken) bool {
+	if rdt == c.config.RDT {
+		return true
+	}
+	seen, ok := c.seen[rdt]
+	if !ok {
+		return false
+	}
+	return c.config.Clock().Sub(seen) < c.config.HeartbeatTimeout
+}
+
+// Held returns true if refreshes of the given snap are held cluster-wide.
+func (c *Coordinator) Held(snapName string) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.held(snapName)
+}
+
+func (c *Coordinator) held(snapName string) bool {
+	return c.holds.All || strutil.ListContains(c.holds.Snaps, snapName)
+}
+
+// Hold holds the refreshes of the given snaps cluster-wide, or of all
+// clustered snaps if none are given. Refreshes already in progress are allowed
+// to finish. The new holds are propagated to the other members with the next
+// heartbeat.
+func (c *Coordinator) Hold(snaps ...string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if len(snaps) == 0 {
+		c.holds.All = true
+	}
+	for _, name := range snaps {
+		if !strutil.ListContains(c.holds.Snaps, name) {
+			c.holds.Snaps = append(c.holds.Snaps, name)
+		}
+	}
+	sort.Strings(c.holds.Snaps)
+	c.holds.Version++
+}
+
+// Resume removes the cluster-wide holds on the given snaps, or all holds if
+// none are given. Rollouts of the resumed snaps that were halted are allowed
+// to continue.
+func (c *Coordinator) Resume(snaps ...string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if len(snaps) == 0 {
+		c.holds.All = false
+		c.holds.Snaps = nil
+		for _, r := range c.rollouts {
+			r.Halted = ""
+		}
+	} else {
+		var kept []string
+		for _, name := range c.holds.Snaps {
+			if !strutil.ListContains(snaps, name) {
+				kept = append(kept, name)
+			}
+		}
+		c.holds.Snaps = kept
+		for _, name := range snaps {
+			if r, ok := c.rollouts[name]; ok {
+				r.Halted = ""
+			}
+		}
+	}
+	c.holds.Version++
+}
+
+// RequestRefresh decides whether the requesting member may refresh the snap
+// now. Only one member at a time is granted the refresh of a given snap. The
+// next member is granted the refresh once the previous one reports it as
+// healthy, or is considered gone. If the previous member does not report in
+// time, or reports the refresh as unhealthy, the rollout is halted until the
+// snap is resumed.
+func (c *Coordinator) RequestRefresh(req RefreshRequest) (Decision, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.leader() != c.config.RDT {
+		return Decision{}, ErrNotLeader
+	}
+	if !containsRDT(c.config.Members, req.RDT) {
+		return Decision{}, fmt.Errorf("cannot handle refresh request from unknown member %q", req.RDT)
+	}
+	if req.Snap == "" || req.Revision.Unset() {
+		return Decision{}, errors.New("cannot handle refresh request without snap and revision")
+	}
+
+	// asking for a refresh is as good as a heartbeat
+	c.seen[req.RDT] = c.config.Clock()
+
+	r, ok := c.rollouts[req.Snap]
+	if ok {
+		c.checkCurrent(r)
+	}
+	if !ok || (r.Revision != req.Revision && r.Current == "" && r.Halted == "") {
+		// either nothing is rolling out or the previous rollout is idle, start
+		// over with the requested revision
+		r = &Rollout{Refresh: req.Refresh}
+		c.rollouts[req.Snap] = r
+	}
+
+	if r.Halted != "" {
+		return wait("refreshes of %q are halted: %s", r.Snap, r.Halted), nil
+	}
+	if r.Revision != req.Revision {
+		return wait("refresh of %q to revision %s is in progress", r.Snap, r.Revision), nil
+	}
+	if r.Current == req.RDT || containsRDT(r.Done, req.RDT) {
+		return Decision{Granted: true}, nil
+	}
+	if c.held(req.Snap) {
+		return wait("refreshes of %q are held cluster-wide", req.Snap), nil
+	}
+	if r.Current != "" {
+		return wait("waiting for member %q to finish refreshing %q", r.Current, r.Snap), nil
+	}
+
+	r.Current = req.RDT
+	r.Granted = c.config.Clock()
+	return Decision{Granted: true}, nil
+}
+
+// checkCurrent checks on the member currently refreshing the rollout's snap.
+// A member that is gone is dropped so that another one can proceed, it will
+// need to ask again once back. A member that is alive but did not report in
+// time halts the rollout.
+func (c *Coordinator) checkCurrent(r *Rollout) {
+	if r.Current == "" {
+		return
+	}
+	if !c.alive(r.Current) {
+		r.Current = ""
+		r.Granted = time.Time{}
+		return
+	}
+	if c.config.Clock().Sub(r.Granted) >= c.config.HealthTimeout {
+		r.Halted = fmt.Sprintf("member %q did not report on its refresh in time", r.Current)
+		r.Current = ""
+		r.Granted = time.Time{}
+	}
+}
+
+// ReportRefresh records the outcome of a refresh granted to a member. A
+// healthy refresh lets the next member proceed, an unhealthy one halts the
+// rollout.
+func (c *Coordinator) ReportRefresh(report RefreshReport) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.leader() != c.config.RDT {
+		return ErrNotLeader
+	}
+	if !containsRDT(c.config.Members, report.RDT) {
+		return fmt.Errorf("cannot accept report from unknown member %q", report.RDT)
+	}
+
+	c.seen[report.RDT] = c.config.Clock()
+
+	r, ok := c.rollouts[report.Snap]
+	if !ok || r.Revision != report.Revision || r.Current != report.RDT {
+		return fmt.Errorf("cannot accept report from member %q: refresh of %q to revision %s was not granted to it", report.RDT, report.Snap, report.Revision)
+	}
+
+	r.Current = ""
+	r.Granted = time.Time{}
+
+	if !report.Healthy {
+		msg := report.Message
+		if msg == "" {
+			msg = "no details given"
+		}
+		r.Halted = fmt.Sprintf("member %q reported refresh as unhealthy: %s", report.RDT, msg)
+		return nil
+	}
+
+	r.Done = append(r.Done, report.RDT)
+	if len(r.Done) == len(c.config.Members) {
+		delete(c.rollouts, report.Snap)
+	}
+	return nil
+}
+
+func (c *Coordinator) holdsCopy() Holds {
+	h := c.holds
+	h.Snaps = append([]string(nil), c.holds.Snaps...)
+	return h
+}
+
+func wait(format string, args ...any) Decision {
+	return Decision{Reason: fmt.Sprintf(format, args...)}
+}
+
+func containsRDT(rdts []assemblestate.DeviceToken, rdt assemblestate.DeviceToken) bool {
+	for _, r := range rdts {
+		if r == rdt {
+			return true
+		}
+	}
+	return false
+}
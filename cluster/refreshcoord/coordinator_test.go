@@ -0,0 +1,402 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package refreshcoord_test
+
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/cluster/assemblestate"
+	"github.com/snapcore/snapd/cluster/refreshcoord"
+	"github.com/snapcore/snapd/snap"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type coordinatorSuite struct {
+	now time.Time
+}
+
+var _ = check.Suite(&coordinatorSuite{})
+
+func (s *coordinatorSuite) SetUpTest(c *check.C) {
+	s.now = time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+}
+
+func (s *coordinatorSuite) clock() time.Time {
+	return s.now
+}
+
+func (s *coordinatorSuite) coordinator(c *check.C, rdt assemblestate.DeviceToken, cs refreshcoord.CoordinatorState) *refreshcoord.Coordinator {
+	coord, err := refreshcoord.New(refreshcoord.Config{
+		RDT:              rdt,
+		Members:          []assemblestate.DeviceToken{"a", "b", "c"},
+		HeartbeatTimeout: time.Minute,
+		HealthTimeout:    10 * time.Minute,
+		Clock:            s.clock,
+	}, cs)
+	c.Assert(err, check.IsNil)
+	return coord
+}
+
+func request(rdt assemblestate.DeviceToken, name string, rev int) refreshcoord.RefreshRequest {
+	return refreshcoord.RefreshRequest{
+		RDT:     rdt,
+		Refresh: refreshcoord.Refresh{Snap: name, Revision: snap.R(rev)},
+	}
+}
+
+func report(rdt assemblestate.DeviceToken, name string, rev int, healthy bool) refreshcoord.RefreshReport {
+	return refreshcoord.RefreshReport{
+		RDT:     rdt,
+		Refresh: refreshcoord.Refresh{Snap: name, Revision: snap.R(rev)},
+		Healthy: healthy,
+	}
+}
+
+func (s *coordinatorSuite) TestNewErrors(c *check.C) {
+	members := []assemblestate.DeviceToken{"a", "b"}
+	for _, tc := range []struct {
+		config refreshcoord.Config
+		err    string
+	}{{
+		config: refreshcoord.Config{Members: members, HeartbeatTimeout: time.Second, HealthTimeout: time.Second},
+		err:    "cannot create refresh coordinator without a device token",
+	}, {
+		config: refreshcoord.Config{RDT: "z", Members: members, HeartbeatTimeout: time.Second, HealthTimeout: time.Second},
+		err:    `cannot create refresh coordinator: "z" is not a cluster member`,
+	}, {
+		config: refreshcoord.Config{RDT: "a", Members: members, HealthTimeout: time.Second},
+		err:    "cannot create refresh coordinator: timeouts must be positive",
+	}} {
+		_, err := refreshcoord.New(tc.config, refreshcoord.CoordinatorState{})
+		c.Check(err, check.ErrorMatches, tc.err)
+	}
+}
+
+func (s *coordinatorSuite) TestLeaderElection(c *check.C) {
+	coord := s.coordinator(c, "b", refreshcoord.CoordinatorState{})
+
+	// everyone is given the benefit of the doubt at first
+	c.Check(coord.Leader(), check.Equals, assemblestate.DeviceToken("a"))
+
+	s.now = s.now.Add(30 * time.Second)
+	c.Assert(coord.CommitHeartbeat(refreshcoord.Heartbeat{RDT: "c"}), check.IsNil)
+
+	// "a" goes silent, we take over since we have the lowest token among the
+	// members that are alive
+	s.now = s.now.Add(45 * time.Second)
+	c.Check(coord.Leader(), check.Equals, assemblestate.DeviceToken("b"))
+
+	// and "a" takes over again once it is back
+	c.Assert(coord.CommitHeartbeat(refreshcoord.Heartbeat{RDT: "a"}), check.IsNil)
+	c.Check(coord.Leader(), check.Equals, assemblestate.DeviceToken("a"))
+
+	err := coord.CommitHeartbeat(refreshcoord.Heartbeat{RDT: "z"})
+	c.Check(err, check.ErrorMatches, `cannot commit heartbeat from unknown member "z"`)
+}
+
+func (s *coordinatorSuite) TestNotLeader(c *check.C) {
+	coord := s.coordinator(c, "b", refreshcoord.CoordinatorState{})
+
+	_, err := coord.RequestRefresh(request("c", "microk8s", 2))
+	c.Check(err, check.Equals, refreshcoord.ErrNotLeader)
+
+	err = coord.ReportRefresh(report("c", "microk8s", 2, true))
+	c.Check(err, check.Equals, refreshcoord.ErrNotLeader)
+}
+
+func (s *coordinatorSuite) TestStaggeredRollout(c *check.C) {
+	coord := s.coordinator(c, "a", refreshcoord.CoordinatorState{})
+
+	d, err := coord.RequestRefresh(request("b", "microk8s", 2))
+	c.Assert(err, check.IsNil)
+	c.Check(d, check.DeepEquals, refreshcoord.Decision{Granted: true})
+
+	// asking again is fine
+	d, err = coord.RequestRefresh(request("b", "microk8s", 2))
+	c.Assert(err, check.IsNil)
+	c.Check(d.Granted, check.Equals, true)
+
+	// only one member at a time
+	d, err = coord.RequestRefresh(request("a", "microk8s", 2))
+	c.Assert(err, check.IsNil)
+	c.Check(d, check.DeepEquals, refreshcoord.Decision{
+		Reason: `waiting for member "b" to finish refreshing "microk8s"`,
+	})
+
+	// other snaps are rolled out independently
+	d, err = coord.RequestRefresh(request("c", "other", 7))
+	c.Assert(err, check.IsNil)
+	c.Check(d.Granted, check.Equals, true)
+
+	c.Assert(coord.ReportRefresh(report("b", "microk8s", 2, true)), check.IsNil)
+
+	d, err = coord.RequestRefresh(request("a", "microk8s", 2))
+	c.Assert(err, check.IsNil)
+	c.Check(d.Granted, check.Equals, true)
+
+	// members that are done are not held back
+	d, err = coord.RequestRefresh(request("b", "microk8s", 2))
+	c.Assert(err, check.IsNil)
+	c.Check(d.Granted, check.Equals, true)
+
+	c.Assert(coord.ReportRefresh(report("a", "microk8s", 2, true)), check.IsNil)
+
+	d, err = coord.RequestRefresh(request("c", "microk8s", 2))
+	c.Assert(err, check.IsNil)
+	c.Check(d.Granted, check.Equals, true)
+	c.Assert(coord.ReportRefresh(report("c", "microk8s", 2, true)), check.IsNil)
+
+	// the rollout is forgotten once every member is done
+	_, ok := coord.State().Rollouts["microk8s"]
+	c.Check(ok, check.Equals, false)
+	_, ok = coord.State().Rollouts["other"]
+	c.Check(ok, check.Equals, true)
+}
+
+func (s *coordinatorSuite) TestRequestRefreshErrors(c *check.C) {
+	coord := s.coordinator(c, "a", refreshcoord.CoordinatorState{})
+
+	_, err := coord.RequestRefresh(request("z", "microk8s", 2))
+	c.Check(err, check.ErrorMatches, `cannot handle refresh request from unknown member "z"`)
+
+	_, err = coord.RequestRefresh(refreshcoord.RefreshRequest{RDT: "b", Refresh: refreshcoord.Refresh{Snap: "microk8s"}})
+	c.Check(err, check.ErrorMatches, "cannot handle refresh request without snap and revision")
+}
+
+func (s *coordinatorSuite) TestReportRefreshNotGranted(c *check.C) {
+	coord := s.coordinator(c, "a", refreshcoord.CoordinatorState{})
+
+	_, err := coord.RequestRefresh(request("b", "microk8s", 2))
+	c.Assert(err, check.IsNil)
+
+	err = coord.ReportRefresh(report("c", "microk8s", 2, true))
+	c.Check(err, check.ErrorMatches, `cannot accept report from member "c": refresh of "microk8s" to revision 2 was not granted to it`)
+
+	err = coord.ReportRefresh(report("b", "microk8s", 3, true))
+	c.Check(err, check.ErrorMatches, `cannot accept report from member "b": refresh of "microk8s" to revision 3 was not granted to it`)
+
+	err = coord.ReportRefresh(report("z", "microk8s", 2, true))
+	c.Check(err, check.ErrorMatches, `cannot accept report from unknown member "z"`)
+}
+
+func (s *coordinatorSuite) TestNewRevisionWaitsForRollout(c *check.C) {
+	coord := s.coordinator(c, "a", refreshcoord.CoordinatorState{})
+
+	_, err := coord.RequestRefresh(request("b", "microk8s", 2))
+	c.Assert(err, check.IsNil)
+
+	d, err := coord.RequestRefresh(request("c", "microk8s", 3))
+	c.Assert(err, check.IsNil)
+	c.Check(d.Reason, check.Equals, `refresh of "microk8s" to revision 2 is in progress`)
+
+	// once the rollout is idle a new revision starts a new rollout
+	c.Assert(coord.ReportRefresh(report("b", "microk8s", 2, true)), check.IsNil)
+
+	d, err = coord.RequestRefresh(request("c", "microk8s", 3))
+	c.Assert(err, check.IsNil)
+	c.Check(d.Granted, check.Equals, true)
+	c.Check(coord.State().Rollouts["microk8s"].Revision, check.Equals, snap.R(3))
+}
+
+func (s *coordinatorSuite) TestUnhealthyHaltsRollout(c *check.C) {
+	coord := s.coordinator(c, "a", refreshcoord.CoordinatorState{})
+
+	_, err := coord.RequestRefresh(request("b", "microk8s", 2))
+	c.Assert(err, check.IsNil)
+
+	rep := report("b", "microk8s", 2, false)
+	rep.Message = "kubelet not ready"
+	c.Assert(coord.ReportRefresh(rep), check.IsNil)
+
+	halted := `refreshes of "microk8s" are halted: member "b" reported refresh as unhealthy: kubelet not ready`
+	d, err := coord.RequestRefresh(request("c", "microk8s", 2))
+	c.Assert(err, check.IsNil)
+	c.Check(d.Reason, check.Equals, halted)
+
+	// a new revision is held back too until the snap is resumed
+	d, err = coord.RequestRefresh(request("c", "microk8s", 3))
+	c.Assert(err, check.IsNil)
+	c.Check(d.Reason, check.Equals, halted)
+
+	coord.Resume("microk8s")
+
+	d, err = coord.RequestRefresh(request("c", "microk8s", 2))
+	c.Assert(err, check.IsNil)
+	c.Check(d.Granted, check.Equals, true)
+}
+
+func (s *coordinatorSuite) TestHealthTimeoutHaltsRollout(c *check.C) {
+	coord := s.coordinator(c, "a", refreshcoord.CoordinatorState{})
+
+	_, err := coord.RequestRefresh(request("b", "microk8s", 2))
+	c.Assert(err, check.IsNil)
+
+	// "b" keeps sending heartbeats but never reports on the refresh
+	for i := 0; i < 11; i++ {
+		s.now = s.now.Add(time.Minute)
+		c.Assert(coord.CommitHeartbeat(refreshcoord.Heartbeat{RDT: "b"}), check.IsNil)
+	}
+
+	d, err := coord.RequestRefresh(request("c", "microk8s", 2))
+	c.Assert(err, check.IsNil)
+	c.Check(d.Reason, check.Equals, `refreshes of "microk8s" are halted: member "b" did not report on its refresh in time`)
+
+	err = coord.ReportRefresh(report("b", "microk8s", 2, true))
+	c.Check(err, check.ErrorMatches, `cannot accept report from member "b": .* was not granted to it`)
+}
+
+func (s *coordinatorSuite) TestGoneMemberIsSkipped(c *check.C) {
+	coord := s.coordinator(c, "a", refreshcoord.CoordinatorState{})
+
+	_, err := coord.RequestRefresh(request("b", "microk8s", 2))
+	c.Assert(err, check.IsNil)
+
+	// "b" disappears while refreshing
+	s.now = s.now.Add(2 * time.Minute)
+
+	d, err := coord.RequestRefresh(request("c", "microk8s", 2))
+	c.Assert(err, check.IsNil)
+	c.Check(d.Granted, check.Equals, true)
+
+	// and has to wait its turn once back
+	d, err = coord.RequestRefresh(request("b", "microk8s", 2))
+	c.Assert(err, check.IsNil)
+	c.Check(d.Reason, check.Equals, `waiting for member "c" to finish refreshing "microk8s"`)
+}
+
+func (s *coordinatorSuite) TestHoldAndResume(c *check.C) {
+	coord := s.coordinator(c, "a", refreshcoord.CoordinatorState{})
+
+	coord.Hold("microk8s", "other")
+	c.Check(coord.Held("microk8s"), check.Equals, true)
+	c.Check(coord.Held("unrelated"), check.Equals, false)
+
+	d, err := coord.RequestRefresh(request("b", "microk8s", 2))
+	c.Assert(err, check.IsNil)
+	c.Check(d.Reason, check.Equals, `refreshes of "microk8s" are held cluster-wide`)
+
+	coord.Resume("microk8s")
+	c.Check(coord.Held("microk8s"), check.Equals, false)
+	c.Check(coord.Held("other"), check.Equals, true)
+
+	d, err = coord.RequestRefresh(request("b", "microk8s", 2))
+	c.Assert(err, check.IsNil)
+	c.Check(d.Granted, check.Equals, true)
+
+	// holding everything lets the refresh in progress finish
+	coord.Hold()
+	c.Check(coord.Held("unrelated"), check.Equals, true)
+
+	d, err = coord.RequestRefresh(request("b", "microk8s", 2))
+	c.Assert(err, check.IsNil)
+	c.Check(d.Granted, check.Equals, true)
+	c.Assert(coord.ReportRefresh(report("b", "microk8s", 2, true)), check.IsNil)
+
+	d, err = coord.RequestRefresh(request("c", "microk8s", 2))
+	c.Assert(err, check.IsNil)
+	c.Check(d.Granted, check.Equals, false)
+
+	coord.Resume()
+	c.Check(coord.State().Holds, check.DeepEquals, refreshcoord.Holds{Version: 4})
+
+	d, err = coord.RequestRefresh(request("c", "microk8s", 2))
+	c.Assert(err, check.IsNil)
+	c.Check(d.Granted, check.Equals, true)
+}
+
+func (s *coordinatorSuite) TestHoldsAreGossiped(c *check.C) {
+	leader := s.coordinator(c, "a", refreshcoord.CoordinatorState{})
+	member := s.coordinator(c, "b", refreshcoord.CoordinatorState{})
+
+	leader.Hold("microk8s")
+
+	hb := leader.Heartbeat(nil)
+	c.Check(hb, check.DeepEquals, refreshcoord.Heartbeat{
+		RDT:   "a",
+		Holds: refreshcoord.Holds{Version: 1, Snaps: []string{"microk8s"}},
+	})
+
+	c.Assert(member.CommitHeartbeat(hb), check.IsNil)
+	c.Check(member.Held("microk8s"), check.Equals, true)
+
+	// older views do not override newer ones
+	member.Resume()
+	c.Assert(member.CommitHeartbeat(hb), check.IsNil)
+	c.Check(member.Held("microk8s"), check.Equals, false)
+
+	c.Assert(leader.CommitHeartbeat(member.Heartbeat(nil)), check.IsNil)
+	c.Check(leader.Held("microk8s"), check.Equals, false)
+}
+
+func (s *coordinatorSuite) TestNewLeaderAdoptsRefreshInProgress(c *check.C) {
+	coord := s.coordinator(c, "b", refreshcoord.CoordinatorState{})
+
+	// "a" was the leader and granted a refresh to "c" before going away
+	s.now = s.now.Add(30 * time.Second)
+	c.Assert(coord.CommitHeartbeat(refreshcoord.Heartbeat{RDT: "c"}), check.IsNil)
+	s.now = s.now.Add(45 * time.Second)
+	c.Assert(coord.Leader(), check.Equals, assemblestate.DeviceToken("b"))
+
+	c.Assert(coord.CommitHeartbeat(refreshcoord.Heartbeat{
+		RDT:        "c",
+		Refreshing: []refreshcoord.Refresh{{Snap: "microk8s", Revision: snap.R(2)}},
+	}), check.IsNil)
+
+	d, err := coord.RequestRefresh(request("b", "microk8s", 2))
+	c.Assert(err, check.IsNil)
+	c.Check(d.Reason, check.Equals, `waiting for member "c" to finish refreshing "microk8s"`)
+
+	c.Check(coord.ReportRefresh(report("c", "microk8s", 2, true)), check.IsNil)
+
+	d, err = coord.RequestRefresh(request("b", "microk8s", 2))
+	c.Assert(err, check.IsNil)
+	c.Check(d.Granted, check.Equals, true)
+}
+
+func (s *coordinatorSuite) TestStateRoundTrip(c *check.C) {
+	coord := s.coordinator(c, "a", refreshcoord.CoordinatorState{})
+
+	coord.Hold("other")
+	_, err := coord.RequestRefresh(request("b", "microk8s", 2))
+	c.Assert(err, check.IsNil)
+
+	cs := coord.State()
+	c.Check(cs, check.DeepEquals, refreshcoord.CoordinatorState{
+		Holds: refreshcoord.Holds{Version: 1, Snaps: []string{"other"}},
+		Rollouts: map[string]refreshcoord.Rollout{
+			"microk8s": {
+				Refresh: refreshcoord.Refresh{Snap: "microk8s", Revision: snap.R(2)},
+				Current: "b",
+				Granted: s.now,
+			},
+		},
+	})
+
+	restored := s.coordinator(c, "a", cs)
+	c.Check(restored.Held("other"), check.Equals, true)
+
+	d, err := restored.RequestRefresh(request("c", "microk8s", 2))
+	c.Assert(err, check.IsNil)
+	c.Check(d.Reason, check.Equals, `waiting for member "b" to finish refreshing "microk8s"`)
+}
@@ -0,0 +1,88 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package refreshcoord
+
+import (
+	"github.com/snapcore/snapd/cluster/assemblestate"
+	"github.com/snapcore/snapd/snap"
+)
+
+// Heartbeat is periodically sent by every cluster member to every other
+// member. It is used to track member liveness, to gossip the cluster-wide
+// refresh holds, and to let a newly elected leader learn which refreshes are
+// already in progress.
+type Heartbeat struct {
+	// RDT is the device token of the sending member.
+	RDT assemblestate.DeviceToken `json:"rdt"`
+
+	// Holds is the sender's view of the cluster-wide refresh holds.
+	Holds Holds `json:"holds"`
+
+	// Refreshing contains the refreshes that the sender was granted and has
+	// not yet reported on.
+	Refreshing []Refresh `json:"refreshing,omitempty"`
+}
+
+// Refresh identifies the refresh of a snap to a specific revision.
+type Refresh struct {
+	Snap     string        `json:"snap"`
+	Revision snap.Revision `json:"revision"`
+}
+
+// RefreshRequest is sent by a member to the leader when it would like to
+// refresh a clustered snap.
+type RefreshRequest struct {
+	RDT assemblestate.DeviceToken `json:"rdt"`
+	Refresh
+}
+
+// Decision is the leader's response to a [RefreshRequest].
+type Decision struct {
+	// Granted is true if the member may proceed with the refresh.
+	Granted bool `json:"granted"`
+
+	// Reason explains why the refresh was not granted.
+	Reason string `json:"reason,omitempty"`
+}
+
+// RefreshReport is sent by a member to the leader once a granted refresh has
+// finished and the health of the refreshed snap has been checked.
+type RefreshReport struct {
+	RDT assemblestate.DeviceToken `json:"rdt"`
+	Refresh
+
+	// Healthy is true if the snap was refreshed and passed its health checks.
+	Healthy bool `json:"healthy"`
+
+	// Message describes the failure when Healthy is false.
+	Message string `json:"message,omitempty"`
+}
+
+// Holds is the set of cluster-wide refresh holds. Each modification bumps the
+// version, the set with the highest version wins when views are merged.
+type Holds struct {
+	Version uint64 `json:"version"`
+
+	// All is true if refreshes of all clustered snaps are held.
+	All bool `json:"all,omitempty"`
+
+	// Snaps contains the names of the individually held snaps.
+	Snaps []string `json:"snaps,omitempty"`
+}
@@ -0,0 +1,313 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package refreshcoord
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/snapcore/snapd/cluster/assemblestate"
+	"github.com/snapcore/snapd/httputil"
+)
+
+// maxMessageSize is the maximum size of a message accepted from a peer.
+const maxMessageSize = 1 << 20
+
+// Peer is another member of the cluster. Peers are identified by the
+// fingerprint of the certificate they used while assembling the cluster.
+type Peer struct {
+	RDT     assemblestate.DeviceToken
+	Address string
+	FP      assemblestate.Fingerprint
+}
+
+// Serve starts an HTTPS server with the given [net.Listener], which handles
+// the refresh coordination messages sent by the given peers to the
+// [Coordinator]. Peers must use the certificate they used while assembling
+// the cluster, and may only send messages on their own behalf.
+//
+// The server handles the following endpoints:
+//   - /refresh/heartbeat: heartbeats from all the members
+//   - /refresh/request: refresh requests sent to the leader
+//   - /refresh/report: refresh reports sent to the leader
+//
+// The server runs until the context is cancelled.
+func Serve(ctx context.Context, ln net.Listener, cert tls.Certificate, peers []Peer, coord *Coordinator) error {
+	byFP := make(map[assemblestate.Fingerprint]assemblestate.DeviceToken, len(peers))
+	for _, p := range peers {
+		byFP[p.FP] = p.RDT
+	}
+
+	h := &handler{
+		coord: coord,
+		peers: byFP,
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/refresh/heartbeat", h.trusted(h.handleHeartbeat))
+	mux.Handle("/refresh/request", h.trusted(h.handleRequest))
+	mux.Handle("/refresh/report", h.trusted(h.handleReport))
+
+	server := &http.Server{
+		Handler: mux,
+		BaseContext: func(l net.Listener) context.Context {
+			return ctx
+		},
+		ErrorLog: log.New(io.Discard, "", 0),
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		listener := tls.NewListener(ln, &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			ClientAuth:   tls.RequireAnyClientCert,
+			MinVersion:   tls.VersionTLS12,
+		})
+
+		// serve always returns a non-nil error, nothing to handle here
+		_ = server.Serve(listener)
+	}()
+
+	<-ctx.Done()
+
+	_ = server.Shutdown(context.Background())
+	wg.Wait()
+
+	return nil
+}
+
+type handler struct {
+	coord *Coordinator
+	peers map[assemblestate.Fingerprint]assemblestate.DeviceToken
+}
+
+// trusted wraps the given handler, only letting through POST requests from
+// known peers. The device token of the peer is passed on to the handler.
+func (h *handler) trusted(next func(w http.ResponseWriter, r *http.Request, rdt assemblestate.DeviceToken)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		if r.TLS == nil || len(r.TLS.PeerCertificates) != 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		rdt, ok := h.peers[assemblestate.CalculateFP(r.TLS.PeerCertificates[0].Raw)]
+		if !ok {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxMessageSize)
+		next(w, r, rdt)
+	}
+}
+
+func (h *handler) handleHeartbeat(w http.ResponseWriter, r *http.Request, rdt assemblestate.DeviceToken) {
+	var hb Heartbeat
+	if !decode(w, r, &hb) {
+		return
+	}
+	if hb.RDT != rdt {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	if err := h.coord.CommitHeartbeat(hb); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *handler) handleRequest(w http.ResponseWriter, r *http.Request, rdt assemblestate.DeviceToken) {
+	var req RefreshRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if req.RDT != rdt {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	d, err := h.coord.RequestRefresh(req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(d)
+}
+
+func (h *handler) handleReport(w http.ResponseWriter, r *http.Request, rdt assemblestate.DeviceToken) {
+	var report RefreshReport
+	if !decode(w, r, &report) {
+		return
+	}
+	if report.RDT != rdt {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	if err := h.coord.ReportRefresh(report); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// decode decodes the body of the request into v, replying with an error if
+// it cannot be decoded.
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// writeError replies with the given error. A member that is not the leader
+// replies with a conflict, which the [Client] turns back into [ErrNotLeader].
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusBadRequest
+	if errors.Is(err, ErrNotLeader) {
+		status = http.StatusConflict
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(status)
+	fmt.Fprint(w, err.Error())
+}
+
+// Client sends refresh coordination messages to the other members of the
+// cluster, over HTTPS with mutual TLS authentication.
+type Client struct {
+	cert tls.Certificate
+}
+
+// NewClient creates a new [Client] which authenticates to the other members
+// with the given certificate.
+func NewClient(cert tls.Certificate) *Client {
+	return &Client{cert: cert}
+}
+
+// Heartbeat sends the given heartbeat to the peer.
+func (c *Client) Heartbeat(ctx context.Context, peer Peer, hb Heartbeat) error {
+	return c.send(ctx, peer, "heartbeat", hb, nil)
+}
+
+// RequestRefresh asks the peer, which must be the leader, whether the
+// refresh may proceed. [ErrNotLeader] is returned if the peer does not
+// consider itself the leader.
+func (c *Client) RequestRefresh(ctx context.Context, peer Peer, req RefreshRequest) (Decision, error) {
+	var d Decision
+	if err := c.send(ctx, peer, "request", req, &d); err != nil {
+		return Decision{}, err
+	}
+	return d, nil
+}
+
+// ReportRefresh reports on a refresh to the peer, which must be the leader.
+// [ErrNotLeader] is returned if the peer does not consider itself the leader.
+func (c *Client) ReportRefresh(ctx context.Context, peer Peer, report RefreshReport) error {
+	return c.send(ctx, peer, "report", report, nil)
+}
+
+func (c *Client) send(ctx context.Context, peer Peer, kind string, data, result any) error {
+	verify := func(certs [][]byte, chains [][]*x509.Certificate) error {
+		if len(certs) != 1 {
+			return fmt.Errorf("exactly one peer certificate expected, got %d", len(certs))
+		}
+		if assemblestate.CalculateFP(certs[0]) != peer.FP {
+			return errors.New("refusing to communicate with unexpected peer certificate")
+		}
+		return nil
+	}
+
+	client := httputil.NewHTTPClient(&httputil.ClientOptions{
+		Timeout: 30 * time.Second,
+		TLSConfig: &tls.Config{
+			InsecureSkipVerify:    true,
+			VerifyPeerCertificate: verify,
+			Certificates:          []tls.Certificate{c.cert},
+		},
+	})
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return errors.New("redirects are not expected")
+	}
+	defer client.CloseIdleConnections()
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("https://%s/refresh/%s", peer.Address, kind)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	body := io.LimitReader(res.Body, maxMessageSize)
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusConflict:
+		return ErrNotLeader
+	default:
+		msg, _ := io.ReadAll(body)
+		if len(msg) == 0 {
+			return fmt.Errorf("response to %q message contains status code %d", kind, res.StatusCode)
+		}
+		return fmt.Errorf("response to %q message contains status code %d: %s", kind, res.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(body).Decode(result); err != nil {
+		return fmt.Errorf("cannot decode response to %q message: %v", kind, err)
+	}
+	return nil
+}
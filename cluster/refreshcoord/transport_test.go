@@ -0,0 +1,202 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package refreshcoord_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"time"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/cluster/assemblestate"
+	"github.com/snapcore/snapd/cluster/refreshcoord"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type transportSuite struct {
+	testutil.BaseTest
+	coordinatorSuite
+
+	certs map[assemblestate.DeviceToken]tls.Certificate
+	peers map[assemblestate.DeviceToken]refreshcoord.Peer
+}
+
+var _ = check.Suite(&transportSuite{})
+
+func generateTestCert(c *check.C) tls.Certificate {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	c.Assert(err, check.IsNil)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	c.Assert(err, check.IsNil)
+
+	template := x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{Organization: []string{"Test"}},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, pub, priv)
+	c.Assert(err, check.IsNil)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: priv}
+}
+
+func (s *transportSuite) SetUpTest(c *check.C) {
+	s.BaseTest.SetUpTest(c)
+	s.coordinatorSuite.SetUpTest(c)
+
+	s.certs = make(map[assemblestate.DeviceToken]tls.Certificate)
+	s.peers = make(map[assemblestate.DeviceToken]refreshcoord.Peer)
+	for _, rdt := range []assemblestate.DeviceToken{"a", "b", "c"} {
+		cert := generateTestCert(c)
+		s.certs[rdt] = cert
+		s.peers[rdt] = refreshcoord.Peer{
+			RDT: rdt,
+			FP:  assemblestate.CalculateFP(cert.Certificate[0]),
+		}
+	}
+}
+
+// serve serves the messages sent to the member with the given device token
+// by the other members, until the test is over.
+func (s *transportSuite) serve(c *check.C, rdt assemblestate.DeviceToken, coord *refreshcoord.Coordinator) refreshcoord.Peer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, check.IsNil)
+
+	var peers []refreshcoord.Peer
+	for other, p := range s.peers {
+		if other != rdt {
+			peers = append(peers, p)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- refreshcoord.Serve(ctx, ln, s.certs[rdt], peers, coord)
+	}()
+
+	s.AddCleanup(func() {
+		cancel()
+		c.Check(<-done, check.IsNil)
+	})
+
+	self := s.peers[rdt]
+	self.Address = ln.Addr().String()
+	return self
+}
+
+func (s *transportSuite) TestHeartbeatRequestReport(c *check.C) {
+	leader := s.coordinator(c, "a", refreshcoord.CoordinatorState{})
+	peer := s.serve(c, "a", leader)
+
+	client := refreshcoord.NewClient(s.certs["b"])
+
+	err := client.Heartbeat(context.Background(), peer, refreshcoord.Heartbeat{
+		RDT:   "b",
+		Holds: refreshcoord.Holds{Version: 1, Snaps: []string{"other"}},
+	})
+	c.Assert(err, check.IsNil)
+	c.Check(leader.Held("other"), check.Equals, true)
+
+	d, err := client.RequestRefresh(context.Background(), peer, request("b", "microk8s", 2))
+	c.Assert(err, check.IsNil)
+	c.Check(d, check.DeepEquals, refreshcoord.Decision{Granted: true})
+
+	d, err = refreshcoord.NewClient(s.certs["c"]).RequestRefresh(context.Background(), peer, request("c", "microk8s", 2))
+	c.Assert(err, check.IsNil)
+	c.Check(d, check.DeepEquals, refreshcoord.Decision{
+		Reason: `waiting for member "b" to finish refreshing "microk8s"`,
+	})
+
+	err = client.ReportRefresh(context.Background(), peer, report("b", "microk8s", 2, true))
+	c.Assert(err, check.IsNil)
+	c.Check(leader.State().Rollouts["microk8s"].Done, check.DeepEquals, []assemblestate.DeviceToken{"b"})
+
+	err = client.ReportRefresh(context.Background(), peer, report("b", "microk8s", 3, true))
+	c.Check(err, check.ErrorMatches, `response to "report" message contains status code 400: cannot accept report from member "b": refresh of "microk8s" to revision 3 was not granted to it`)
+}
+
+func (s *transportSuite) TestNotLeader(c *check.C) {
+	member := s.coordinator(c, "b", refreshcoord.CoordinatorState{})
+	peer := s.serve(c, "b", member)
+
+	client := refreshcoord.NewClient(s.certs["c"])
+
+	_, err := client.RequestRefresh(context.Background(), peer, request("c", "microk8s", 2))
+	c.Check(err, check.Equals, refreshcoord.ErrNotLeader)
+
+	err = client.ReportRefresh(context.Background(), peer, report("c", "microk8s", 2, true))
+	c.Check(err, check.Equals, refreshcoord.ErrNotLeader)
+}
+
+func (s *transportSuite) TestOnlyOnOwnBehalf(c *check.C) {
+	leader := s.coordinator(c, "a", refreshcoord.CoordinatorState{})
+	peer := s.serve(c, "a", leader)
+
+	// "c" cannot ask for a refresh in the name of "b"
+	client := refreshcoord.NewClient(s.certs["c"])
+
+	_, err := client.RequestRefresh(context.Background(), peer, request("b", "microk8s", 2))
+	c.Check(err, check.ErrorMatches, `response to "request" message contains status code 403`)
+
+	err = client.Heartbeat(context.Background(), peer, refreshcoord.Heartbeat{RDT: "b"})
+	c.Check(err, check.ErrorMatches, `response to "heartbeat" message contains status code 403`)
+
+	err = client.ReportRefresh(context.Background(), peer, report("b", "microk8s", 2, true))
+	c.Check(err, check.ErrorMatches, `response to "report" message contains status code 403`)
+
+	c.Check(leader.State().Rollouts, check.HasLen, 0)
+}
+
+func (s *transportSuite) TestUnknownPeer(c *check.C) {
+	leader := s.coordinator(c, "a", refreshcoord.CoordinatorState{})
+	peer := s.serve(c, "a", leader)
+
+	client := refreshcoord.NewClient(generateTestCert(c))
+
+	_, err := client.RequestRefresh(context.Background(), peer, request("b", "microk8s", 2))
+	c.Check(err, check.ErrorMatches, `response to "request" message contains status code 403`)
+}
+
+func (s *transportSuite) TestUnexpectedPeerCertificate(c *check.C) {
+	leader := s.coordinator(c, "a", refreshcoord.CoordinatorState{})
+	peer := s.serve(c, "a", leader)
+
+	// the leader is expected to use another certificate
+	peer.FP = s.peers["c"].FP
+
+	client := refreshcoord.NewClient(s.certs["b"])
+
+	_, err := client.RequestRefresh(context.Background(), peer, request("b", "microk8s", 2))
+	c.Check(err, check.ErrorMatches, ".*refusing to communicate with unexpected peer certificate")
+	c.Check(leader.State().Rollouts, check.HasLen, 0)
+}
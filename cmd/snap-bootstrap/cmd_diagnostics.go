@@ -0,0 +1,230 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/boot"
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/osutil"
+)
+
+func init() {
+	const (
+		short = "Collect diagnostics of a failed boot"
+		long  = `
+The diagnostics command gathers the kernel logs, the mount table, the
+partitions of the boot disk, the measured boot state and the state of
+unlocking encrypted partitions into a tarball, by default in the
+diagnostics directory of ubuntu-seed, to attach it to bug reports.
+`
+	)
+
+	addCommandBuilder(func(parser *flags.Parser) {
+		if _, err := parser.AddCommand("diagnostics", short, long, &cmdDiagnostics{}); err != nil {
+			panic(err)
+		}
+	})
+}
+
+type cmdDiagnostics struct {
+	Output string `long:"output" value-name:"filename" description:"location of the tarball"`
+}
+
+// diagnosticsCommands are the commands whose output is collected, by the
+// name it is stored under.
+var diagnosticsCommands = map[string][]string{
+	"dmesg":   {"dmesg"},
+	"journal": {"journalctl", "-b", "--no-pager", "-o", "short-monotonic"},
+	"lsblk":   {"lsblk", "--output-all"},
+}
+
+// diagnosticsFiles returns the files that are collected, by the name they
+// are stored under.
+func diagnosticsFiles() map[string]string {
+	return map[string]string{
+		"cmdline":       filepath.Join(dirs.GlobalRootDir, "/proc/cmdline"),
+		"mountinfo":     filepath.Join(dirs.GlobalRootDir, "/proc/self/mountinfo"),
+		"partitions":    filepath.Join(dirs.GlobalRootDir, "/proc/partitions"),
+		"tpm-event-log": filepath.Join(dirs.GlobalRootDir, "/sys/kernel/security/tpm0/binary_bios_measurements"),
+	}
+}
+
+type diagnosticsBundle struct {
+	tw       *tar.Writer
+	failures []string
+}
+
+func (b *diagnosticsBundle) add(name string, content []byte) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(content)),
+		ModTime: timeNow(),
+	}
+	if err := b.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := b.tw.Write(content)
+	return err
+}
+
+// addFailure records diagnostics that could not be collected, which is
+// expected when the boot failed early.
+func (b *diagnosticsBundle) addFailure(name string, err error) {
+	b.failures = append(b.failures, fmt.Sprintf("%s: %v", name, err))
+}
+
+func (b *diagnosticsBundle) addCommands() error {
+	names := make([]string, 0, len(diagnosticsCommands))
+	for name := range diagnosticsCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		cmd := diagnosticsCommands[name]
+		output, err := exec.Command(cmd[0], cmd[1:]...).CombinedOutput()
+		if err != nil {
+			b.addFailure(name, osutil.OutputErr(output, err))
+			continue
+		}
+		if err := b.add(name, output); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *diagnosticsBundle) addFiles() error {
+	files := diagnosticsFiles()
+	// the stamps of what was measured and the states of unlocking the
+	// encrypted partitions
+	stateFiles, err := filepath.Glob(filepath.Join(dirs.SnapBootstrapRunDir, "*"))
+	if err != nil {
+		return err
+	}
+	for _, path := range stateFiles {
+		files["snap-bootstrap/"+filepath.Base(path)] = path
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		content, err := os.ReadFile(files[name])
+		if err != nil {
+			b.addFailure(name, err)
+			continue
+		}
+		if err := b.add(name, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *diagnosticsBundle) addBootDisk() error {
+	diskNode, err := diskNodeFromDiskSnapdSymlink()
+	if err != nil {
+		b.addFailure("disk.json", err)
+		return nil
+	}
+	disk, err := probeDisk(diskNode, probeDiskOpts{probeFsAlways: true})
+	if err != nil {
+		b.addFailure("disk.json", err)
+		return nil
+	}
+	content, err := json.MarshalIndent(disk, "", "  ")
+	if err != nil {
+		return err
+	}
+	return b.add("disk.json", content)
+}
+
+func (b *diagnosticsBundle) finish() error {
+	if len(b.failures) > 0 {
+		if err := b.add("failures", []byte(strings.Join(b.failures, "\n")+"\n")); err != nil {
+			return err
+		}
+	}
+	return b.tw.Close()
+}
+
+func writeDiagnostics(output string) error {
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return err
+	}
+	f, err := osutil.NewAtomicFile(output, 0600, 0, osutil.NoChown, osutil.NoChown)
+	if err != nil {
+		return err
+	}
+	// Cancel is a no-op after Commit
+	defer f.Cancel()
+
+	gz := gzip.NewWriter(f)
+	b := &diagnosticsBundle{tw: tar.NewWriter(gz)}
+	for _, collect := range []func() error{b.addCommands, b.addFiles, b.addBootDisk} {
+		if err := collect(); err != nil {
+			return err
+		}
+	}
+	if err := b.finish(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return f.Commit()
+}
+
+func (c *cmdDiagnostics) Execute(args []string) error {
+	output := c.Output
+	if output == "" {
+		mounted, err := osutilIsMounted(boot.InitramfsUbuntuSeedDir)
+		if err != nil {
+			return err
+		}
+		if !mounted {
+			return fmt.Errorf("cannot store diagnostics: ubuntu-seed is not mounted, use --output")
+		}
+		name := fmt.Sprintf("snap-bootstrap-%s.tar.gz", timeNow().UTC().Format("20060102T150405Z"))
+		output = filepath.Join(boot.InitramfsUbuntuSeedDir, "diagnostics", name)
+	}
+
+	if err := writeDiagnostics(output); err != nil {
+		return fmt.Errorf("cannot store diagnostics: %v", err)
+	}
+	logger.Noticef("diagnostics stored in %s", output)
+	return nil
+}
@@ -0,0 +1,145 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main_test
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/boot"
+	main "github.com/snapcore/snapd/cmd/snap-bootstrap"
+	"github.com/snapcore/snapd/cmd/snap-bootstrap/blkid"
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/testutil"
+)
+
+func (s *cmdSuite) mockDiagnostics(c *C) {
+	dirs.SetRootDir(c.MkDir())
+	s.AddCleanup(func() { dirs.SetRootDir("") })
+
+	s.AddCleanup(main.MockTimeNow(func() time.Time {
+		return time.Date(2026, 10, 16, 8, 30, 0, 0, time.UTC)
+	}))
+
+	dmesg := testutil.MockCommand(c, "dmesg", `echo "[    0.000000] Linux version 6.8.0"`)
+	s.AddCleanup(dmesg.Restore)
+	journalctl := testutil.MockCommand(c, "journalctl", `echo "snap-bootstrap[123]: cannot unlock ubuntu-data"`)
+	s.AddCleanup(journalctl.Restore)
+	lsblk := testutil.MockCommand(c, "lsblk", `echo "cannot open /dev/sda" >&2; exit 1`)
+	s.AddCleanup(lsblk.Restore)
+
+	for path, content := range map[string]string{
+		"/proc/cmdline":                                    "snapd_recovery_mode=run\n",
+		"/proc/self/mountinfo":                             "27 1 8:3 / /run/mnt/ubuntu-seed rw - vfat /dev/sda1 rw\n",
+		"/run/snapd/snap-bootstrap/unlocked.json":          `{"ubuntu-data":{"unlock-key":"run"}}`,
+		"/run/snapd/snap-bootstrap/secboot-epoch-measured": "",
+	} {
+		c.Assert(os.MkdirAll(filepath.Dir(filepath.Join(dirs.GlobalRootDir, path)), 0755), IsNil)
+		c.Assert(os.WriteFile(filepath.Join(dirs.GlobalRootDir, path), []byte(content), 0644), IsNil)
+	}
+
+	devLink := filepath.Join(dirs.GlobalRootDir, "/dev/disk/snapd/disk")
+	c.Assert(os.MkdirAll(filepath.Dir(devLink), 0755), IsNil)
+	c.Assert(os.Symlink(filepath.Join(dirs.GlobalRootDir, "/dev/sda"), devLink), IsNil)
+	diskProbe := blkid.BuildFakeProbe(map[string]string{"PTTYPE": "gpt"})
+	partProbe := diskProbe.AddPartitionProbe(1, "ubuntu-seed", "ubuntu-seed-partuuid", 0)
+	s.AddCleanup(blkid.MockBlkidMap(map[string]*blkid.FakeBlkidProbe{"/dev/sda": diskProbe}))
+	s.AddCleanup(blkid.MockBlkidPartitionMap(map[int64]*blkid.FakeBlkidProbe{0: partProbe}))
+}
+
+func readDiagnostics(c *C, path string) map[string]string {
+	f, err := os.Open(path)
+	c.Assert(err, IsNil)
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	c.Assert(err, IsNil)
+
+	files := make(map[string]string)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		c.Assert(err, IsNil)
+		content, err := io.ReadAll(tr)
+		c.Assert(err, IsNil)
+		files[hdr.Name] = string(content)
+	}
+	return files
+}
+
+func (s *cmdSuite) TestDiagnostics(c *C) {
+	s.mockDiagnostics(c)
+	s.AddCleanup(main.MockOsutilIsMounted(func(path string) (bool, error) {
+		c.Check(path, Equals, boot.InitramfsUbuntuSeedDir)
+		return true, nil
+	}))
+
+	rest, err := main.Parser().ParseArgs([]string{"diagnostics"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, HasLen, 0)
+
+	output := filepath.Join(boot.InitramfsUbuntuSeedDir, "diagnostics/snap-bootstrap-20261016T083000Z.tar.gz")
+	files := readDiagnostics(c, output)
+	c.Check(files["dmesg"], Equals, "[    0.000000] Linux version 6.8.0\n")
+	c.Check(files["journal"], Equals, "snap-bootstrap[123]: cannot unlock ubuntu-data\n")
+	c.Check(files["cmdline"], Equals, "snapd_recovery_mode=run\n")
+	c.Check(files["mountinfo"], Equals, "27 1 8:3 / /run/mnt/ubuntu-seed rw - vfat /dev/sda1 rw\n")
+	c.Check(files["snap-bootstrap/unlocked.json"], Equals, `{"ubuntu-data":{"unlock-key":"run"}}`)
+	c.Check(files["snap-bootstrap/secboot-epoch-measured"], Equals, "")
+	c.Check(files["disk.json"], testutil.Contains, `"Node": "/dev/sda"`)
+	c.Check(files["disk.json"], testutil.Contains, `"Name": "ubuntu-seed"`)
+	// what cannot be collected is listed
+	c.Check(files["failures"], Matches, `(?s)lsblk: cannot open /dev/sda\npartitions: open .*: no such file or directory\ntpm-event-log: open .*: no such file or directory\n`)
+	c.Check(files, HasLen, 8)
+}
+
+func (s *cmdSuite) TestDiagnosticsOutput(c *C) {
+	s.mockDiagnostics(c)
+	s.AddCleanup(main.MockOsutilIsMounted(func(path string) (bool, error) {
+		c.Fatalf("unexpected call")
+		return false, nil
+	}))
+
+	output := filepath.Join(c.MkDir(), "some/dir/diag.tar.gz")
+	_, err := main.Parser().ParseArgs([]string{"diagnostics", "--output", output})
+	c.Assert(err, IsNil)
+
+	files := readDiagnostics(c, output)
+	c.Check(files["dmesg"], Equals, "[    0.000000] Linux version 6.8.0\n")
+	c.Check(filepath.Join(boot.InitramfsUbuntuSeedDir, "diagnostics"), testutil.FileAbsent)
+}
+
+func (s *cmdSuite) TestDiagnosticsSeedNotMounted(c *C) {
+	s.mockDiagnostics(c)
+	s.AddCleanup(main.MockOsutilIsMounted(func(path string) (bool, error) {
+		return false, nil
+	}))
+
+	_, err := main.Parser().ParseArgs([]string{"diagnostics"})
+	c.Assert(err, ErrorMatches, "cannot store diagnostics: ubuntu-seed is not mounted, use --output")
+}
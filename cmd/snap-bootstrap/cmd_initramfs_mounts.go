@@ -57,6 +57,7 @@ import (
 	"github.com/snapcore/snapd/overlord/install"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/secboot"
+	"github.com/snapcore/snapd/secboot/keys"
 	"github.com/snapcore/snapd/seed"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/snap/integrity"
@@ -123,6 +124,7 @@ var (
 	installBuildInstallObserver      = install.BuildInstallObserver
 	lookupDmVerityDataAndCrossCheck  = integrity.LookupDmVerityDataAndCrossCheck
 	secbootNewActivateContext        = secboot.NewActivateContext
+	secbootGetPrimaryKey             = secboot.GetPrimaryKey
 )
 
 func stampedAction(stamp string, action func() error) error {
@@ -1287,6 +1289,14 @@ func (m *recoverModeStateMachine) mountBoot() (stateFunc, error) {
 	return m.unlockData, nil
 }
 
+// dataKeyProviders returns the providers of keys, besides the sealed ones,
+// that can unlock ubuntu-data.
+func dataKeyProviders() []secboot.KeyProvider {
+	return []secboot.KeyProvider{
+		secboot.NewNetworkKeyProvider(device.DataNetworkKeysUnder(boot.InitramfsBootEncryptionKeyDir)),
+	}
+}
+
 // stateUnlockDataRunKey will try to unlock ubuntu-data with the normal run-mode
 // key, and if it fails, progresses to the next state, which is either:
 // - failed to unlock data, but we know it's an encrypted device -> try to unlock with fallback key
@@ -1310,6 +1320,7 @@ func (m *recoverModeStateMachine) unlockData() (stateFunc, error) {
 		AllowRecoveryKey: true,
 		WhichModel:       m.whichModel,
 		BootMode:         m.mode,
+		KeyProviders:     dataKeyProviders(),
 	}
 	unlockRes, unlockErr := secbootUnlockVolumeUsingSealedKeyIfEncrypted(m.activateContext, &SecbootDisk{Disk: m.disk}, "ubuntu-data", keys, unlockOpts)
 	if unlockRes.Keyslot != "external:legacy-fallback" && unlockRes.Keyslot != "default-fallback" {
@@ -1373,7 +1384,7 @@ func (m *recoverModeStateMachine) unlockEncryptedSaveRunKey() (stateFunc, error)
 	// to get to this state, we needed to have mounted ubuntu-data on host, so
 	// if encrypted, we can try to read the run key from host ubuntu-data
 	saveKey := device.SaveKeyUnder(dirs.SnapFDEDirUnder(boot.InitramfsHostWritableDir(m.model)))
-	key, err := os.ReadFile(saveKey)
+	key, err := readSaveProtectorKey(saveKey, m.degradedState.partition("ubuntu-data").partDevice)
 	if err != nil {
 		// log the error and skip to trying the fallback key
 		m.degradedState.LogDegraded("cannot access run ubuntu-save key: %v", err)
@@ -1999,19 +2010,44 @@ func generateMountsRecoverOrFactoryReset(mst *initramfsMountsState) (model *asse
 	return model, snaps, seedDisk, nil
 }
 
-func maybeMountSave(activateContext secboot.ActivateContext, disk *Disk, rootdir string, encrypted bool, mountOpts *systemdMountOptions) (haveSave bool, unlockRes secboot.UnlockResult, err error) {
+// readSaveProtectorKey returns the key protecting ubuntu-save. It is
+// normally read from saveKeyFile, but if that cannot be read it is derived
+// from the primary key put in the keyring when unlocking ubuntu-data.
+func readSaveProtectorKey(saveKeyFile, dataPartDevice string) ([]byte, error) {
+	key, err := os.ReadFile(saveKeyFile)
+	if err == nil {
+		return key, nil
+	}
+	if dataPartDevice == "" {
+		return nil, err
+	}
+	primaryKey, pkErr := secbootGetPrimaryKey([]string{dataPartDevice}, nil)
+	if pkErr != nil {
+		logger.Debugf("cannot find primary key for %s: %v", dataPartDevice, pkErr)
+		return nil, err
+	}
+	derived, deriveErr := keys.PrimaryKey(primaryKey).DeriveProtectorKey(gadget.SystemSave)
+	if deriveErr != nil {
+		logger.Noticef("cannot derive ubuntu-save key: %v", deriveErr)
+		return nil, err
+	}
+	logger.Noticef("using ubuntu-save key derived from the primary key: %v", err)
+	return derived, nil
+}
+
+func maybeMountSave(activateContext secboot.ActivateContext, disk *Disk, rootdir string, encrypted bool, dataPartDevice string, mountOpts *systemdMountOptions) (haveSave bool, unlockRes secboot.UnlockResult, err error) {
 	var saveDevice string
 	if encrypted {
+		// if ubuntu-save exists and is encrypted, the key has been
+		// created during install, or can be derived from the primary key
 		saveKey := device.SaveKeyUnder(dirs.SnapFDEDirUnder(rootdir))
-		// if ubuntu-save exists and is encrypted, the key has been created during install
-		if !osutil.FileExists(saveKey) {
-			// ubuntu-data is encrypted, but we appear to be missing
-			// a key to open ubuntu-save
-			return false, unlockRes, fmt.Errorf("cannot find ubuntu-save encryption key at %v", saveKey)
-		}
-		// we have save.key, volume exists and is encrypted
-		key, err := os.ReadFile(saveKey)
+		key, err := readSaveProtectorKey(saveKey, dataPartDevice)
 		if err != nil {
+			if os.IsNotExist(err) {
+				// ubuntu-data is encrypted, but we appear to be missing
+				// a key to open ubuntu-save
+				return false, unlockRes, fmt.Errorf("cannot find ubuntu-save encryption key at %v", saveKey)
+			}
 			return true, unlockRes, err
 		}
 		unlockRes, err = secbootUnlockEncryptedVolumeUsingProtectorKey(activateContext, &SecbootDisk{Disk: disk}, "ubuntu-save", key)
@@ -2280,6 +2316,7 @@ func generateMountsModeRun(mst *initramfsMountsState) error {
 		AllowRecoveryKey: true,
 		WhichModel:       mst.UnverifiedBootModel,
 		BootMode:         mst.mode,
+		KeyProviders:     dataKeyProviders(),
 	}
 	unlockRes, err := secbootUnlockVolumeUsingSealedKeyIfEncrypted(mst.activateContext, &SecbootDisk{Disk: disk}, "ubuntu-data", keys, opts)
 	if err != nil {
@@ -2313,7 +2350,7 @@ func generateMountsModeRun(mst *initramfsMountsState) error {
 		NoSuid:    true,
 		NoExec:    true,
 	}
-	haveSave, saveUnlockRes, err := maybeMountSave(mst.activateContext, disk, rootfsDir, isEncryptedDev, saveMountOpts)
+	haveSave, saveUnlockRes, err := maybeMountSave(mst.activateContext, disk, rootfsDir, isEncryptedDev, unlockRes.PartDevice, saveMountOpts)
 	if err != nil {
 		return err
 	}
@@ -2434,9 +2471,14 @@ func generateMountsModeRun(mst *initramfsMountsState) error {
 		}
 	}
 
-	// 4.4 check if we expected a ubuntu-seed partition from the gadget data
+	// 4.4 set up the device mappings declared by the gadget
+	gadgetDir := filepath.Join(boot.InitramfsRunMntDir, snapTypeToMountDir[snap.TypeGadget])
+	if err := setupGadgetDeviceMappings(disk, gadgetDir, model); err != nil {
+		return err
+	}
+
+	// 4.5 check if we expected a ubuntu-seed partition from the gadget data
 	if isClassic {
-		gadgetDir := filepath.Join(boot.InitramfsRunMntDir, snapTypeToMountDir[snap.TypeGadget])
 		foundRole, err := gadget.HasRole(gadgetDir, []string{gadget.SystemSeed, gadget.SystemSeedNull})
 		if err != nil {
 			return err
@@ -2450,7 +2492,7 @@ func generateMountsModeRun(mst *initramfsMountsState) error {
 		}
 	}
 
-	// 4.5 mount snapd snap only on first boot
+	// 4.6 mount snapd snap only on first boot
 	if modeEnv.RecoverySystem != "" && !isClassic {
 		// load the recovery system and generate mount for snapd
 		theSeed, err := mst.LoadSeed(modeEnv.RecoverySystem)
@@ -20,6 +20,7 @@
 package main_test
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -38,6 +39,7 @@ import (
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/osutil/disks"
 	"github.com/snapcore/snapd/secboot"
+	"github.com/snapcore/snapd/secboot/keys"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/snap/integrity"
 	"github.com/snapcore/snapd/systemd"
@@ -904,6 +906,86 @@ func (s *initramfsMountsSuite) TestInitramfsMountsRecoverModeHappyEncrypted(c *C
 	checkSnapdMountUnit(c)
 }
 
+func (s *initramfsMountsSuite) TestInitramfsMountsRecoverModeEncryptedDerivedSaveKey(c *C) {
+	s.mockProcCmdlineContent(c, "snapd_recovery_mode=recover snapd_recovery_system="+s.sysLabel)
+	s.mockBlkidDiskOpts(mockBlkidOpts{diskType: "gpt", encrypted: true, seedIdx: 1})
+
+	restore := main.MockPartitionUUIDForBootedKernelDisk("")
+	defer restore()
+
+	// setup a bootloader for setting the bootenv after we are done
+	bloader := bootloadertest.Mock("mock", c.MkDir())
+	bootloader.Force(bloader)
+	defer bootloader.Force(nil)
+
+	restore = main.MockSecbootUnlockVolumeUsingSealedKeyIfEncrypted(func(activateContext secboot.ActivateContext, disk secboot.Disk, name string, sealedEncryptionKeyFiles []*secboot.LegacyKeyFile, opts *secboot.UnlockVolumeUsingSealedKeyOptions) (secboot.UnlockResult, error) {
+		c.Assert(name, Equals, "ubuntu-data")
+		return happyUnlocked("ubuntu-data", secboot.UnlockedWithSealedKey, "external:legacy"), nil
+	})
+	defer restore()
+
+	// ubuntu-save.key is lost, only the marker is left
+	hostDataRootDir := filepath.Join(dirs.GlobalRootDir, "/run/mnt/host/ubuntu-data/system-data")
+	s.mockUbuntuSaveKeyAndMarker(c, hostDataRootDir, "foo", "marker")
+	c.Assert(os.Remove(filepath.Join(dirs.SnapFDEDirUnder(hostDataRootDir), "ubuntu-save.key")), IsNil)
+	s.mockUbuntuSaveMarker(c, boot.InitramfsUbuntuSaveDir, "marker")
+
+	// the primary key was put in the keyring when unlocking ubuntu-data
+	primaryKey := bytes.Repeat([]byte{0x42}, keys.PrimaryKeySize)
+	restore = main.MockSecbootGetPrimaryKey(func(devices []string, fallbackKeyFiles []string) ([]byte, error) {
+		c.Check(devices, DeepEquals, []string{"/dev/disk/by-partuuid/ubuntu-data-enc-partuuid"})
+		return primaryKey, nil
+	})
+	defer restore()
+	derivedKey, err := keys.PrimaryKey(primaryKey).DeriveProtectorKey("system-save")
+	c.Assert(err, IsNil)
+
+	saveActivated := false
+	restore = main.MockSecbootUnlockEncryptedVolumeUsingProtectorKey(func(activateContext secboot.ActivateContext, disk secboot.Disk, name string, key []byte) (secboot.UnlockResult, error) {
+		c.Assert(name, Equals, "ubuntu-save")
+		c.Assert(key, DeepEquals, []byte(derivedKey))
+		saveActivated = true
+		return happyUnlocked("ubuntu-save", secboot.UnlockedWithKey, "external:legacy"), nil
+	})
+	defer restore()
+
+	restore = s.mockSystemdMountSequence(c, []systemdMount{
+		s.nodeMount("ubuntu-seed", "recover"),
+		s.makeSeedSnapSystemdMount(snap.TypeKernel),
+		s.makeSeedSnapSystemdMount(snap.TypeBase),
+		s.makeSeedSnapSystemdMount(snap.TypeGadget),
+		{
+			"tmpfs",
+			boot.InitramfsDataDir,
+			tmpfsMountOpts,
+			nil,
+			nil,
+		},
+		s.nodeMount("ubuntu-boot", "recover"),
+		{
+			"/dev/mapper/ubuntu-data-random",
+			boot.InitramfsHostUbuntuDataDir,
+			needsNoSuidDiskMountOpts,
+			nil,
+			nil,
+		},
+		{
+			"/dev/mapper/ubuntu-save-random",
+			boot.InitramfsUbuntuSaveDir,
+			needsNoSuidNoDevNoExecMountOpts,
+			nil,
+			nil,
+		},
+	}, nil)
+	defer restore()
+
+	s.testRecoverModeHappy(c, "core20")
+
+	// the derived key unlocked ubuntu-save, so the system is not degraded
+	c.Assert(filepath.Join(dirs.SnapBootstrapRunDir, "degraded.json"), testutil.FileAbsent)
+	c.Check(saveActivated, Equals, true)
+}
+
 func (s *initramfsMountsSuite) TestInitramfsMountsRecoverModeEncryptedDegradedDataUnlockFallbackHappy(c *C) {
 	s.mockProcCmdlineContent(c, "snapd_recovery_mode=recover snapd_recovery_system="+s.sysLabel)
 	s.mockBlkidDiskOpts(mockBlkidOpts{diskType: "gpt", encrypted: true, seedIdx: 1})
@@ -20,6 +20,7 @@
 package main_test
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os"
@@ -37,6 +38,7 @@ import (
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/osutil/disks"
 	"github.com/snapcore/snapd/secboot"
+	"github.com/snapcore/snapd/secboot/keys"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/systemd"
 	"github.com/snapcore/snapd/testutil"
@@ -150,6 +152,74 @@ func (s *initramfsMountsSuite) TestInitramfsMountsRunModeUnencryptedWithSaveHapp
 	c.Assert(err, IsNil)
 }
 
+func (s *initramfsMountsSuite) TestInitramfsMountsRunModeGadgetDeviceMappingsHappy(c *C) {
+	s.mockProcCmdlineContent(c, "snapd_recovery_mode=run")
+	s.mockBlkidDisk("gpt", 1)
+
+	restore := disks.MockDeviceNameToDiskMapping(map[string]*disks.MockDiskMapping{
+		"/dev/sda": mappingsDisk,
+	})
+	defer restore()
+
+	var opened [][]string
+	restore = main.MockDmverityOpen(func(dataDevice, name, hashDevice, rootHash string) error {
+		opened = append(opened, []string{dataDevice, name, hashDevice, rootHash})
+		return nil
+	})
+	defer restore()
+	var created []string
+	restore = main.MockDmsetupCreate(func(name, table string) error {
+		created = append(created, name)
+		return nil
+	})
+	defer restore()
+
+	restore = s.mockSystemdMountSequence(c, []systemdMount{
+		s.nodeMount("ubuntu-boot", "run"),
+		s.nodeMount("ubuntu-seed", "run"),
+		s.nodeMount("ubuntu-data", "run"),
+		s.nodeMount("ubuntu-save", "run"),
+		s.makeRunSnapSystemdMount(snap.TypeBase, s.core20),
+		s.makeRunSnapSystemdMount(snap.TypeGadget, s.gadget),
+		s.makeRunSnapSystemdMount(snap.TypeKernel, s.kernel),
+	}, nil)
+	defer restore()
+
+	// mock a bootloader
+	bloader := boottest.MockUC20RunBootenv(bootloadertest.Mock("mock", c.MkDir()))
+	bootloader.Force(bloader)
+	defer bootloader.Force(nil)
+
+	// set the current kernel
+	restore = bloader.SetEnabledKernel(s.kernel)
+	defer restore()
+
+	// the gadget declares device mappings
+	gadgetDir := filepath.Join(boot.InitramfsRunMntDir, "gadget")
+	c.Assert(os.MkdirAll(filepath.Join(gadgetDir, "meta"), 0755), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(gadgetDir, "meta", "gadget.yaml"), []byte(mappingsGadgetYaml), 0644), IsNil)
+
+	s.makeSnapFilesOnEarlyBootUbuntuData(c, s.kernel, s.core20, s.gadget)
+
+	// write modeenv
+	modeEnv := boot.Modeenv{
+		Mode:           "run",
+		Base:           s.core20.Filename(),
+		Gadget:         s.gadget.Filename(),
+		CurrentKernels: []string{s.kernel.Filename()},
+	}
+	err := modeEnv.WriteTo(filepath.Join(dirs.GlobalRootDir, "/run/mnt/data/system-data"))
+	c.Assert(err, IsNil)
+
+	_, err = main.Parser().ParseArgs([]string{"initramfs-mounts"})
+	c.Assert(err, IsNil)
+
+	c.Check(opened, DeepEquals, [][]string{
+		{"/dev/sda3", "payload", "/dev/sda4", mappingsRootHash},
+	})
+	c.Check(created, DeepEquals, []string{"extra"})
+}
+
 func (s *initramfsMountsSuite) TestInitramfsMountsRunModeHappyNoGadgetMount(c *C) {
 	s.mockProcCmdlineContent(c, "snapd_recovery_mode=run")
 	s.mockBlkidDisk("gpt", 1)
@@ -882,6 +952,9 @@ func (s *initramfsMountsSuite) TestInitramfsMountsRunModeEncryptedDataHappy(c *C
 		c.Assert(err, IsNil)
 		c.Check(mod.Model(), Equals, "my-model")
 		c.Check(opts.BootMode, Equals, "run")
+		// keys bound to key servers are tried as well
+		c.Assert(opts.KeyProviders, HasLen, 1)
+		c.Check(opts.KeyProviders[0].Name(), Equals, "network")
 
 		dataActivated = true
 		// return true because we are using an encrypted device
@@ -968,6 +1041,93 @@ func (s *initramfsMountsSuite) TestInitramfsMountsRunModeEncryptedDataHappy(c *C
 	})
 }
 
+func (s *initramfsMountsSuite) TestInitramfsMountsRunModeEncryptedDataHappyDerivedSaveKey(c *C) {
+	s.mockProcCmdlineContent(c, "snapd_recovery_mode=run")
+
+	s.mockBlkidDisk("gpt", 1)
+
+	restore := s.mockSystemdMountSequence(c, []systemdMount{
+		s.nodeMount("ubuntu-boot", "run"),
+		s.nodeMount("ubuntu-seed", "run"),
+		{
+			"/dev/mapper/ubuntu-data-random",
+			boot.InitramfsDataDir,
+			needsFsckAndNoSuidDiskMountOpts,
+			nil,
+			nil,
+		},
+		{
+			"/dev/mapper/ubuntu-save-random",
+			boot.InitramfsUbuntuSaveDir,
+			needsFsckAndNoSuidNoDevNoExecMountOpts,
+			nil,
+			nil,
+		},
+		s.makeRunSnapSystemdMount(snap.TypeBase, s.core20),
+		s.makeRunSnapSystemdMount(snap.TypeGadget, s.gadget),
+		s.makeRunSnapSystemdMount(snap.TypeKernel, s.kernel),
+	}, nil)
+	defer restore()
+
+	restore = main.MockSecbootUnlockVolumeUsingSealedKeyIfEncrypted(func(activateContext secboot.ActivateContext, disk secboot.Disk, name string, sealedEncryptionKeyFiles []*secboot.LegacyKeyFile, opts *secboot.UnlockVolumeUsingSealedKeyOptions) (secboot.UnlockResult, error) {
+		c.Assert(name, Equals, "ubuntu-data")
+		return happyUnlocked("ubuntu-data", secboot.UnlockedWithSealedKey, "external:legacy"), nil
+	})
+	defer restore()
+
+	// ubuntu-save.key is lost, only the marker is left
+	dataRootDir := filepath.Join(dirs.GlobalRootDir, "/run/mnt/data/system-data")
+	s.mockUbuntuSaveKeyAndMarker(c, dataRootDir, "foo", "marker")
+	c.Assert(os.Remove(filepath.Join(dirs.SnapFDEDirUnder(dataRootDir), "ubuntu-save.key")), IsNil)
+	s.mockUbuntuSaveMarker(c, boot.InitramfsUbuntuSaveDir, "marker")
+
+	// the primary key was put in the keyring when unlocking ubuntu-data
+	primaryKey := bytes.Repeat([]byte{0x42}, keys.PrimaryKeySize)
+	restore = main.MockSecbootGetPrimaryKey(func(devices []string, fallbackKeyFiles []string) ([]byte, error) {
+		c.Check(devices, DeepEquals, []string{"/dev/disk/by-partuuid/ubuntu-data-enc-partuuid"})
+		c.Check(fallbackKeyFiles, IsNil)
+		return primaryKey, nil
+	})
+	defer restore()
+	derivedKey, err := keys.PrimaryKey(primaryKey).DeriveProtectorKey("system-save")
+	c.Assert(err, IsNil)
+
+	saveActivated := false
+	restore = main.MockSecbootUnlockEncryptedVolumeUsingProtectorKey(func(activateContext secboot.ActivateContext, disk secboot.Disk, name string, key []byte) (secboot.UnlockResult, error) {
+		saveActivated = true
+		c.Assert(name, Equals, "ubuntu-save")
+		c.Assert(key, DeepEquals, []byte(derivedKey))
+		return happyUnlocked("ubuntu-save", secboot.UnlockedWithKey, ""), nil
+	})
+	defer restore()
+
+	// mock a bootloader
+	bloader := boottest.MockUC20RunBootenv(bootloadertest.Mock("mock", c.MkDir()))
+	bootloader.Force(bloader)
+	defer bootloader.Force(nil)
+
+	// set the current kernel
+	restore = bloader.SetEnabledKernel(s.kernel)
+	defer restore()
+
+	s.makeSnapFilesOnEarlyBootUbuntuData(c, s.kernel, s.core20, s.gadget)
+
+	// write modeenv
+	modeEnv := boot.Modeenv{
+		Mode:           "run",
+		Base:           s.core20.Filename(),
+		Gadget:         s.gadget.Filename(),
+		CurrentKernels: []string{s.kernel.Filename()},
+	}
+	err = modeEnv.WriteTo(dataRootDir)
+	c.Assert(err, IsNil)
+
+	_, err = main.Parser().ParseArgs([]string{"initramfs-mounts"})
+	c.Assert(err, IsNil)
+	c.Check(saveActivated, Equals, true)
+	c.Check(s.logs.String(), testutil.Contains, "using ubuntu-save key derived from the primary key")
+}
+
 func (s *initramfsMountsSuite) TestInitramfsMountsRunModeEncryptedDataHappyRecoveryKey(c *C) {
 	s.mockProcCmdlineContent(c, "snapd_recovery_mode=run")
 
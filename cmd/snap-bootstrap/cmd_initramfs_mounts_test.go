@@ -500,6 +500,9 @@ func (s *baseInitramfsMountsSuite) SetUpTest(c *C) {
 	s.AddCleanup(main.MockSecbootLockSealedKeys(func() error {
 		return nil
 	}))
+	s.AddCleanup(main.MockSecbootGetPrimaryKey(func(devices []string, fallbackKeyFiles []string) ([]byte, error) {
+		return nil, secboot.ErrKernelKeyNotFound
+	}))
 
 	s.AddCleanup(main.MockOsutilSetTime(func(time.Time) error {
 		return nil
@@ -49,6 +49,8 @@ var (
 	ParseImageManifest = parseImageManifest
 
 	CreateOverlayDirs = createOverlayDirs
+
+	SetupGadgetDeviceMappings = setupGadgetDeviceMappings
 )
 
 type OverlayFsOptions = overlayFsOptions
@@ -271,6 +273,18 @@ func MockSecbootNewActivateContext(f func(ctx context.Context) (secboot.Activate
 	return testutil.Mock(&secbootNewActivateContext, f)
 }
 
+func MockSecbootGetPrimaryKey(f func(devices []string, fallbackKeyFiles []string) ([]byte, error)) (restore func()) {
+	return testutil.Mock(&secbootGetPrimaryKey, f)
+}
+
 func MockOsutilDeviceMajorAndMinor(f func(devPath string) (uint32, uint32, error)) (restore func()) {
 	return testutil.Mock(&osutilDeviceMajorAndMinor, f)
 }
+
+func MockDmverityOpen(f func(dataDevice, name, hashDevice, rootHash string) error) (restore func()) {
+	return testutil.Mock(&dmverityOpen, f)
+}
+
+func MockDmsetupCreate(f func(name, table string) error) (restore func()) {
+	return testutil.Mock(&dmsetupCreate, f)
+}
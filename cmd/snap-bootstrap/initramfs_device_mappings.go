@@ -0,0 +1,135 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/snapcore/snapd/gadget"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/osutil/disks"
+	"github.com/snapcore/snapd/snap/integrity/dmverity"
+)
+
+var (
+	dmverityOpen  = dmverity.Open
+	dmsetupCreate = dmsetupCreateImpl
+)
+
+func dmsetupCreateImpl(name, table string) error {
+	output, err := exec.Command("dmsetup", "create", name, "--table", table).CombinedOutput()
+	if err != nil {
+		return osutil.OutputErr(output, err)
+	}
+	return nil
+}
+
+// setupGadgetDeviceMappings creates the device-mapper devices declared with
+// device-mapping in the gadget mounted at gadgetDir, out of the partitions of
+// the disk the system was booted from.
+func setupGadgetDeviceMappings(bootDisk *Disk, gadgetDir string, model gadget.Model) error {
+	if !osutil.FileExists(filepath.Join(gadgetDir, "meta", "gadget.yaml")) {
+		// nothing declared, nothing to do
+		return nil
+	}
+	info, err := gadget.ReadInfo(gadgetDir, model)
+	if err != nil {
+		return fmt.Errorf("cannot read gadget metadata: %v", err)
+	}
+	if !hasDeviceMappings(info) {
+		return nil
+	}
+
+	// the partitions are looked up by their label and their size is needed
+	// for linear mappings, neither of which is known to the probed boot disk
+	disk, err := disks.DiskFromDeviceName(bootDisk.Node)
+	if err != nil {
+		return fmt.Errorf("cannot find boot disk %s: %v", bootDisk.Node, err)
+	}
+
+	partitionNode := func(name string) (string, uint64, error) {
+		part, err := disk.FindMatchingPartitionWithPartLabel(name)
+		if err != nil {
+			return "", 0, err
+		}
+		return part.KernelDeviceNode, part.SizeInBytes, nil
+	}
+
+	// device mappings are only allowed in the volume of the boot disk, see
+	// gadget.validateDeviceMappings
+	for _, vol := range info.Volumes {
+		for _, vs := range vol.Structure {
+			dm := vs.DeviceMapping
+			if dm == nil {
+				continue
+			}
+			name := vs.MappedDeviceName()
+			switch dm.Type {
+			case gadget.DeviceMappingVerity:
+				dataNode, _, err := partitionNode(vs.Name)
+				if err != nil {
+					return fmt.Errorf("cannot set up device mapping %q: %v", name, err)
+				}
+				hashNode, _, err := partitionNode(dm.HashStructure)
+				if err != nil {
+					return fmt.Errorf("cannot set up device mapping %q: %v", name, err)
+				}
+				if err := dmverityOpen(dataNode, name, hashNode, dm.RootHash); err != nil {
+					return fmt.Errorf("cannot set up device mapping %q: %v", name, err)
+				}
+			case gadget.DeviceMappingLinear:
+				var table strings.Builder
+				var start uint64
+				for _, structName := range append([]string{vs.Name}, dm.Segments...) {
+					node, size, err := partitionNode(structName)
+					if err != nil {
+						return fmt.Errorf("cannot set up device mapping %q: %v", name, err)
+					}
+					sectors := size / 512
+					fmt.Fprintf(&table, "%d %d linear %s 0\n", start, sectors, node)
+					start += sectors
+				}
+				if err := dmsetupCreate(name, table.String()); err != nil {
+					return fmt.Errorf("cannot set up device mapping %q: %v", name, err)
+				}
+			default:
+				// already rejected when reading gadget.yaml
+				return fmt.Errorf("internal error: unsupported device mapping type %q", dm.Type)
+			}
+			logger.Noticef("set up %s device mapping %q", dm.Type, name)
+		}
+	}
+	return nil
+}
+
+func hasDeviceMappings(info *gadget.Info) bool {
+	for _, vol := range info.Volumes {
+		for _, vs := range vol.Structure {
+			if vs.DeviceMapping != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
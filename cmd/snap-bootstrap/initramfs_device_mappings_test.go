@@ -0,0 +1,256 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	main "github.com/snapcore/snapd/cmd/snap-bootstrap"
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/osutil/disks"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type deviceMappingsSuite struct {
+	testutil.BaseTest
+
+	gadgetDir string
+}
+
+var _ = Suite(&deviceMappingsSuite{})
+
+const mappingsRootHash = "cf9a379613c0dc10301fe3eba4665c38b849b7aad311471faa4d2392ee4ede49"
+
+const mappingsGadgetYaml = `
+volumes:
+  pc:
+    bootloader: grub
+    structure:
+      - name: ubuntu-seed
+        role: system-seed
+        filesystem: vfat
+        type: EF,C12A7328-F81F-11D2-BA4B-00A0C93EC93B
+        size: 1200M
+      - name: ubuntu-boot
+        role: system-boot
+        filesystem: ext4
+        type: 83,0FC63DAF-8483-4772-8E79-3D69D8477DE4
+        size: 750M
+      - name: payload
+        type: 0FC63DAF-8483-4772-8E79-3D69D8477DE4
+        size: 64M
+        device-mapping:
+          type: verity
+          hash-structure: payload-hash
+          root-hash: ` + mappingsRootHash + `
+      - name: payload-hash
+        type: 0FC63DAF-8483-4772-8E79-3D69D8477DE4
+        size: 1M
+      - name: extra-a
+        type: 0FC63DAF-8483-4772-8E79-3D69D8477DE4
+        size: 8M
+        device-mapping:
+          type: linear
+          name: extra
+          segments: [extra-b]
+      - name: extra-b
+        type: 0FC63DAF-8483-4772-8E79-3D69D8477DE4
+        size: 4M
+      - name: ubuntu-save
+        role: system-save
+        filesystem: ext4
+        type: 83,0FC63DAF-8483-4772-8E79-3D69D8477DE4
+        size: 16M
+      - name: ubuntu-data
+        role: system-data
+        filesystem: ext4
+        type: 83,0FC63DAF-8483-4772-8E79-3D69D8477DE4
+        size: 1G
+`
+
+const gadgetYamlNoMappings = `
+volumes:
+  pc:
+    bootloader: grub
+    structure:
+      - name: ubuntu-seed
+        role: system-seed
+        filesystem: vfat
+        type: EF,C12A7328-F81F-11D2-BA4B-00A0C93EC93B
+        size: 1200M
+      - name: ubuntu-boot
+        role: system-boot
+        filesystem: ext4
+        type: 83,0FC63DAF-8483-4772-8E79-3D69D8477DE4
+        size: 750M
+      - name: ubuntu-save
+        role: system-save
+        filesystem: ext4
+        type: 83,0FC63DAF-8483-4772-8E79-3D69D8477DE4
+        size: 16M
+      - name: ubuntu-data
+        role: system-data
+        filesystem: ext4
+        type: 83,0FC63DAF-8483-4772-8E79-3D69D8477DE4
+        size: 1G
+`
+
+var mappingsDisk = &disks.MockDiskMapping{
+	Structure: []disks.Partition{
+		{PartitionLabel: "ubuntu-seed", KernelDeviceNode: "/dev/sda1"},
+		{PartitionLabel: "ubuntu-boot", KernelDeviceNode: "/dev/sda2"},
+		{PartitionLabel: "payload", KernelDeviceNode: "/dev/sda3", SizeInBytes: 64 * 1024 * 1024},
+		{PartitionLabel: "payload-hash", KernelDeviceNode: "/dev/sda4", SizeInBytes: 1024 * 1024},
+		{PartitionLabel: "extra-a", KernelDeviceNode: "/dev/sda5", SizeInBytes: 8 * 1024 * 1024},
+		{PartitionLabel: "extra-b", KernelDeviceNode: "/dev/sda6", SizeInBytes: 4 * 1024 * 1024},
+		{PartitionLabel: "ubuntu-save", KernelDeviceNode: "/dev/sda7"},
+		{PartitionLabel: "ubuntu-data", KernelDeviceNode: "/dev/sda8"},
+	},
+	DiskHasPartitions: true,
+	DevNum:            "mappings",
+}
+
+var mappingsBootDisk = &main.Disk{Node: "/dev/sda"}
+
+func (s *deviceMappingsSuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
+
+	dirs.SetRootDir(c.MkDir())
+	s.AddCleanup(func() { dirs.SetRootDir("") })
+
+	s.AddCleanup(disks.MockDeviceNameToDiskMapping(map[string]*disks.MockDiskMapping{
+		"/dev/sda": mappingsDisk,
+	}))
+
+	s.gadgetDir = c.MkDir()
+}
+
+func (s *deviceMappingsSuite) writeGadgetYaml(c *C, gadgetYaml string) {
+	c.Assert(os.MkdirAll(filepath.Join(s.gadgetDir, "meta"), 0755), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(s.gadgetDir, "meta", "gadget.yaml"), []byte(gadgetYaml), 0644), IsNil)
+}
+
+func (s *deviceMappingsSuite) TestSetupGadgetDeviceMappingsHappy(c *C) {
+	s.writeGadgetYaml(c, mappingsGadgetYaml)
+
+	var opened [][]string
+	s.AddCleanup(main.MockDmverityOpen(func(dataDevice, name, hashDevice, rootHash string) error {
+		opened = append(opened, []string{dataDevice, name, hashDevice, rootHash})
+		return nil
+	}))
+	var created [][]string
+	s.AddCleanup(main.MockDmsetupCreate(func(name, table string) error {
+		created = append(created, []string{name, table})
+		return nil
+	}))
+
+	err := main.SetupGadgetDeviceMappings(mappingsBootDisk, s.gadgetDir, nil)
+	c.Assert(err, IsNil)
+
+	c.Check(opened, DeepEquals, [][]string{
+		{"/dev/sda3", "payload", "/dev/sda4", mappingsRootHash},
+	})
+	c.Check(created, DeepEquals, [][]string{
+		{"extra", "0 16384 linear /dev/sda5 0\n16384 8192 linear /dev/sda6 0\n"},
+	})
+}
+
+func (s *deviceMappingsSuite) TestSetupGadgetDeviceMappingsNoGadgetYaml(c *C) {
+	s.AddCleanup(main.MockDmverityOpen(func(dataDevice, name, hashDevice, rootHash string) error {
+		c.Fatalf("unexpected call")
+		return nil
+	}))
+	s.AddCleanup(main.MockDmsetupCreate(func(name, table string) error {
+		c.Fatalf("unexpected call")
+		return nil
+	}))
+
+	err := main.SetupGadgetDeviceMappings(mappingsBootDisk, s.gadgetDir, nil)
+	c.Assert(err, IsNil)
+}
+
+func (s *deviceMappingsSuite) TestSetupGadgetDeviceMappingsMissingPartition(c *C) {
+	s.writeGadgetYaml(c, mappingsGadgetYaml)
+
+	s.AddCleanup(main.MockDmverityOpen(func(dataDevice, name, hashDevice, rootHash string) error {
+		c.Fatalf("unexpected call")
+		return nil
+	}))
+
+	s.AddCleanup(disks.MockDeviceNameToDiskMapping(map[string]*disks.MockDiskMapping{
+		"/dev/sda": {
+			Structure: []disks.Partition{
+				{PartitionLabel: "payload", KernelDeviceNode: "/dev/sda3"},
+			},
+			DiskHasPartitions: true,
+			DevNum:            "missing-hash",
+		},
+	}))
+	err := main.SetupGadgetDeviceMappings(mappingsBootDisk, s.gadgetDir, nil)
+	c.Assert(err, ErrorMatches, `cannot set up device mapping "payload": partition label "payload-hash" not found`)
+}
+
+func (s *deviceMappingsSuite) TestSetupGadgetDeviceMappingsNoMappings(c *C) {
+	s.writeGadgetYaml(c, gadgetYamlNoMappings)
+
+	// the boot disk is not looked up when nothing is mapped
+	s.AddCleanup(disks.MockDeviceNameToDiskMapping(map[string]*disks.MockDiskMapping{}))
+
+	err := main.SetupGadgetDeviceMappings(mappingsBootDisk, s.gadgetDir, nil)
+	c.Assert(err, IsNil)
+}
+
+func (s *deviceMappingsSuite) TestSetupGadgetDeviceMappingsNoBootDisk(c *C) {
+	s.writeGadgetYaml(c, mappingsGadgetYaml)
+
+	s.AddCleanup(disks.MockDeviceNameToDiskMapping(map[string]*disks.MockDiskMapping{}))
+
+	err := main.SetupGadgetDeviceMappings(mappingsBootDisk, s.gadgetDir, nil)
+	c.Assert(err, ErrorMatches, `cannot find boot disk /dev/sda: .*`)
+}
+
+func (s *deviceMappingsSuite) TestSetupGadgetDeviceMappingsVerityError(c *C) {
+	s.writeGadgetYaml(c, mappingsGadgetYaml)
+
+	s.AddCleanup(main.MockDmverityOpen(func(dataDevice, name, hashDevice, rootHash string) error {
+		return errors.New("verity error")
+	}))
+
+	err := main.SetupGadgetDeviceMappings(mappingsBootDisk, s.gadgetDir, nil)
+	c.Assert(err, ErrorMatches, `cannot set up device mapping "payload": verity error`)
+}
+
+func (s *deviceMappingsSuite) TestSetupGadgetDeviceMappingsLinearError(c *C) {
+	s.writeGadgetYaml(c, mappingsGadgetYaml)
+
+	s.AddCleanup(main.MockDmverityOpen(func(dataDevice, name, hashDevice, rootHash string) error {
+		return nil
+	}))
+	s.AddCleanup(main.MockDmsetupCreate(func(name, table string) error {
+		return errors.New("dmsetup error")
+	}))
+
+	err := main.SetupGadgetDeviceMappings(mappingsBootDisk, s.gadgetDir, nil)
+	c.Assert(err, ErrorMatches, `cannot set up device mapping "extra": dmsetup error`)
+}
@@ -1028,6 +1028,37 @@ void sc_setup_user_mounts(struct sc_apparmor *apparmor, int snap_update_ns_fd, c
     sc_call_snap_update_ns_as_user(snap_update_ns_fd, snap_name, apparmor);
 }
 
+void sc_setup_app_hardening(const sc_invocation *inv) {
+    debug("%s: %s", __FUNCTION__, inv->security_tag);
+
+    // In our new mount namespace, recursively change all mounts
+    // to slave mode, so we see changes from the parent namespace
+    // but the hardening does not leak into the per-snap namespace
+    // shared with the other applications of the snap.
+    sc_do_mount("none", "/", NULL, MS_REC | MS_SLAVE, NULL);
+
+    if (inv->private_tmp) {
+        sc_do_mount("none", "/tmp", "tmpfs", MS_NODEV | MS_NOSUID, "mode=1777");
+    }
+
+    if (inv->protect_system != NULL) {
+        const char *dirs[] = {"/usr", "/boot", "/etc", NULL};
+        for (int i = 0; dirs[i] != NULL; i++) {
+            const char *dir = dirs[i];
+            if (sc_streq(dir, "/etc") && !sc_streq(inv->protect_system, "full")) {
+                continue;
+            }
+            if (access(dir, F_OK) != 0) {
+                // Not all bases have /boot.
+                debug("not protecting missing %s", dir);
+                continue;
+            }
+            sc_do_mount(dir, dir, NULL, MS_BIND | MS_REC, NULL);
+            sc_do_mount("none", dir, NULL, MS_BIND | MS_REMOUNT | MS_RDONLY, NULL);
+        }
+    }
+}
+
 void sc_ensure_snap_dir_shared_mounts(void) {
     const char *dirs[] = {sc_snap_mount_dir(NULL), "/var/snap", NULL};
     for (int i = 0; dirs[i] != NULL; i++) {
@@ -64,6 +64,18 @@ void sc_ensure_shared_snap_mount(void);
  */
 void sc_setup_user_mounts(struct sc_apparmor *apparmor, int snap_update_ns_fd, const char *snap_name);
 
+/**
+ * Apply the hardening requested for the application, private to this process.
+ *
+ * This is expected to run in a new, never preserved, mount namespace and
+ * does the following:
+ * - reconfigure all existing mounts to slave mode
+ * - mount a fresh tmpfs on /tmp if a private /tmp was requested
+ * - make /usr and /boot, as well as /etc for "full", read-only if
+ *   protect-system was requested
+ */
+void sc_setup_app_hardening(const sc_invocation *inv);
+
 /**
  * Ensure that SNAP_MOUNT_DIR and /var/snap are mount points.
  *
@@ -328,6 +328,106 @@ static void test_sc_nonfatal_parse_args__base_snap__twice(void) {
     g_assert_true(sc_error_match(err, SC_ARGS_DOMAIN, SC_ARGS_ERR_USAGE));
 }
 
+static void test_sc_nonfatal_parse_args__hardening(void) {
+    // Check that --private-tmp and --protect-system are recorded.
+    sc_error *err SC_CLEANUP(sc_cleanup_error) = NULL;
+    struct sc_args *args SC_CLEANUP(sc_cleanup_args) = NULL;
+
+    int argc;
+    char **argv;
+    test_argc_argv(&argc, &argv, "/usr/lib/snapd/snap-confine", "--private-tmp", "--protect-system", "full",
+                   "snap.SNAP_NAME.APP_NAME", "/usr/lib/snapd/snap-exec", NULL);
+
+    args = sc_nonfatal_parse_args(&argc, &argv, &err);
+    g_assert_null(err);
+    g_assert_nonnull(args);
+
+    // Check the hardening switches
+    g_assert_true(sc_args_private_tmp(args));
+    g_assert_cmpstr(sc_args_protect_system(args), ==, "full");
+    // Check other arguments
+    g_assert_cmpstr(sc_args_security_tag(args), ==, "snap.SNAP_NAME.APP_NAME");
+    g_assert_cmpstr(sc_args_executable(args), ==, "/usr/lib/snapd/snap-exec");
+    g_assert_null(sc_args_base_snap(args));
+}
+
+static void test_sc_nonfatal_parse_args__no_hardening(void) {
+    // Check that hardening is off unless requested.
+    sc_error *err SC_CLEANUP(sc_cleanup_error) = NULL;
+    struct sc_args *args SC_CLEANUP(sc_cleanup_args) = NULL;
+
+    int argc;
+    char **argv;
+    test_argc_argv(&argc, &argv, "/usr/lib/snapd/snap-confine", "snap.SNAP_NAME.APP_NAME",
+                   "/usr/lib/snapd/snap-exec", NULL);
+
+    args = sc_nonfatal_parse_args(&argc, &argv, &err);
+    g_assert_null(err);
+    g_assert_nonnull(args);
+
+    g_assert_false(sc_args_private_tmp(args));
+    g_assert_null(sc_args_protect_system(args));
+}
+
+static void test_sc_nonfatal_parse_args__protect_system__missing_arg(void) {
+    sc_error *err SC_CLEANUP(sc_cleanup_error) = NULL;
+    struct sc_args *args SC_CLEANUP(sc_cleanup_args) = NULL;
+
+    int argc;
+    char **argv;
+    test_argc_argv(&argc, &argv, "/usr/lib/snapd/snap-confine", "--protect-system", NULL);
+
+    args = sc_nonfatal_parse_args(&argc, &argv, &err);
+    g_assert_nonnull(err);
+    g_assert_null(args);
+
+    // Check the error that we've got
+    g_assert_cmpstr(sc_error_msg(err), ==,
+                    "Usage: snap-confine <security-tag> <executable>\n"
+                    "\nthe --protect-system option requires an argument");
+    g_assert_true(sc_error_match(err, SC_ARGS_DOMAIN, SC_ARGS_ERR_USAGE));
+}
+
+static void test_sc_nonfatal_parse_args__protect_system__twice(void) {
+    sc_error *err SC_CLEANUP(sc_cleanup_error) = NULL;
+    struct sc_args *args SC_CLEANUP(sc_cleanup_args) = NULL;
+
+    int argc;
+    char **argv;
+    test_argc_argv(&argc, &argv, "/usr/lib/snapd/snap-confine", "--protect-system", "true", "--protect-system",
+                   "full", NULL);
+
+    args = sc_nonfatal_parse_args(&argc, &argv, &err);
+    g_assert_nonnull(err);
+    g_assert_null(args);
+
+    // Check the error that we've got
+    g_assert_cmpstr(sc_error_msg(err), ==,
+                    "Usage: snap-confine <security-tag> <executable>\n"
+                    "\nthe --protect-system option can be used only once");
+    g_assert_true(sc_error_match(err, SC_ARGS_DOMAIN, SC_ARGS_ERR_USAGE));
+}
+
+static void test_sc_nonfatal_parse_args__protect_system__invalid(void) {
+    sc_error *err SC_CLEANUP(sc_cleanup_error) = NULL;
+    struct sc_args *args SC_CLEANUP(sc_cleanup_args) = NULL;
+
+    int argc;
+    char **argv;
+    test_argc_argv(&argc, &argv, "/usr/lib/snapd/snap-confine", "--protect-system", "strict",
+                   "snap.SNAP_NAME.APP_NAME", "/usr/lib/snapd/snap-exec", NULL);
+
+    args = sc_nonfatal_parse_args(&argc, &argv, &err);
+    g_assert_nonnull(err);
+    g_assert_null(args);
+
+    // Check the error that we've got
+    g_assert_cmpstr(sc_error_msg(err), ==,
+                    "Usage: snap-confine <security-tag> <executable>\n"
+                    "\ninvalid value for the --protect-system option: strict");
+    g_assert_true(sc_error_match(err, SC_ARGS_DOMAIN, SC_ARGS_ERR_USAGE));
+}
+
 static void __attribute__((constructor)) init(void) {
     g_test_add_func("/args/sc_cleanup_args", test_sc_cleanup_args);
     g_test_add_func("/args/sc_nonfatal_parse_args/typical", test_sc_nonfatal_parse_args__typical);
@@ -343,4 +443,12 @@ static void __attribute__((constructor)) init(void) {
     g_test_add_func("/args/sc_nonfatal_parse_args/base_snap/missing-arg",
                     test_sc_nonfatal_parse_args__base_snap__missing_arg);
     g_test_add_func("/args/sc_nonfatal_parse_args/base_snap/twice", test_sc_nonfatal_parse_args__base_snap__twice);
+    g_test_add_func("/args/sc_nonfatal_parse_args/hardening", test_sc_nonfatal_parse_args__hardening);
+    g_test_add_func("/args/sc_nonfatal_parse_args/no_hardening", test_sc_nonfatal_parse_args__no_hardening);
+    g_test_add_func("/args/sc_nonfatal_parse_args/protect_system/missing-arg",
+                    test_sc_nonfatal_parse_args__protect_system__missing_arg);
+    g_test_add_func("/args/sc_nonfatal_parse_args/protect_system/twice",
+                    test_sc_nonfatal_parse_args__protect_system__twice);
+    g_test_add_func("/args/sc_nonfatal_parse_args/protect_system/invalid",
+                    test_sc_nonfatal_parse_args__protect_system__invalid);
 }
@@ -29,11 +29,15 @@ struct sc_args {
     char *executable;
     // Name of the base snap to use.
     char *base_snap;
+    // Value of the --protect-system option, either "true" or "full".
+    char *protect_system;
 
     // Flag indicating that --version was passed on command line.
     bool is_version_query;
     // Flag indicating that --classic was passed on command line.
     bool is_classic_confinement;
+    // Flag indicating that --private-tmp was passed on command line.
+    bool private_tmp;
 };
 
 struct sc_args *sc_nonfatal_parse_args(int *argcp, char ***argvp, sc_error **errorp) {
@@ -98,6 +102,33 @@ struct sc_args *sc_nonfatal_parse_args(int *argcp, char ***argvp, sc_error **err
             }
             args->base_snap = sc_strdup(argv[optind + 1]);
             optind += 1;
+        } else if (strcmp(argv[optind], "--private-tmp") == 0) {
+            args->private_tmp = true;
+        } else if (strcmp(argv[optind], "--protect-system") == 0) {
+            if (optind + 1 >= argc) {
+                err = sc_error_init(SC_ARGS_DOMAIN, SC_ARGS_ERR_USAGE,
+                                    "Usage: snap-confine <security-tag> <executable>\n"
+                                    "\n"
+                                    "the --protect-system option requires an argument");
+                goto out;
+            }
+            if (args->protect_system != NULL) {
+                err = sc_error_init(SC_ARGS_DOMAIN, SC_ARGS_ERR_USAGE,
+                                    "Usage: snap-confine <security-tag> <executable>\n"
+                                    "\n"
+                                    "the --protect-system option can be used only once");
+                goto out;
+            }
+            if (!sc_streq(argv[optind + 1], "true") && !sc_streq(argv[optind + 1], "full")) {
+                err = sc_error_init(SC_ARGS_DOMAIN, SC_ARGS_ERR_USAGE,
+                                    "Usage: snap-confine <security-tag> <executable>\n"
+                                    "\n"
+                                    "invalid value for the --protect-system option: %s",
+                                    argv[optind + 1]);
+                goto out;
+            }
+            args->protect_system = sc_strdup(argv[optind + 1]);
+            optind += 1;
         } else {
             // Report unhandled option switches
             err = sc_error_init(SC_ARGS_DOMAIN, SC_ARGS_ERR_USAGE,
@@ -174,6 +205,8 @@ void sc_args_free(struct sc_args *args) {
         args->executable = NULL;
         free(args->base_snap);
         args->base_snap = NULL;
+        free(args->protect_system);
+        args->protect_system = NULL;
         free(args);
     }
 }
@@ -217,3 +250,17 @@ const char *sc_args_base_snap(const struct sc_args *args) {
     }
     return args->base_snap;
 }
+
+bool sc_args_private_tmp(const struct sc_args *args) {
+    if (args == NULL) {
+        die("cannot obtain private tmp flag from NULL argument parser");
+    }
+    return args->private_tmp;
+}
+
+const char *sc_args_protect_system(const struct sc_args *args) {
+    if (args == NULL) {
+        die("cannot obtain protect system value from NULL argument parser");
+    }
+    return args->protect_system;
+}
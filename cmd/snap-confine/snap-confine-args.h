@@ -116,4 +116,23 @@ const char *sc_args_executable(const struct sc_args *args);
  **/
 const char *sc_args_base_snap(const struct sc_args *args);
 
+/**
+ * Check if snap-confine was invoked with the --private-tmp switch.
+ *
+ * The switch requests a private /tmp for the application, in addition to
+ * the one shared by all the applications of the snap.
+ **/
+bool sc_args_private_tmp(const struct sc_args *args);
+
+/**
+ * Get the value of the --protect-system option.
+ *
+ * The value is either NULL, "true" or "full". It follows the semantics of
+ * the ProtectSystem= directive of systemd.
+ *
+ * The return value must not be freed(). It is bound to the lifetime of
+ * the argument parser.
+ **/
+const char *sc_args_protect_system(const struct sc_args *args);
+
 #endif
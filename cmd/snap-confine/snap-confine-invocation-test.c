@@ -59,10 +59,32 @@ static void test_sc_invocation_basic(snap_mount_dir_fixture *fix, gconstpointer
     g_assert_cmpstr(inv.snap_name, ==, "foo");
     g_assert_cmpstr(inv.snap_component, ==, NULL);
     g_assert_false(inv.classic_confinement);
+    g_assert_false(inv.private_tmp);
+    g_assert_null(inv.protect_system);
     /* derived later */
     g_assert_false(inv.is_normal_mode);
 }
 
+static void test_sc_invocation_hardening(snap_mount_dir_fixture *fix, gconstpointer user_data) {
+    struct sc_args *args SC_CLEANUP(sc_cleanup_args) = NULL;
+    sc_error *err SC_CLEANUP(sc_cleanup_error) = NULL;
+    int argc;
+    char **argv;
+
+    test_argc_argv(&argc, &argv, "/usr/lib/snapd/snap-confine", "--private-tmp", "--protect-system", "true",
+                   "snap.foo.app", "/usr/lib/snapd/snap-exec", NULL);
+    args = sc_nonfatal_parse_args(&argc, &argv, &err);
+    g_assert_null(err);
+    g_assert_nonnull(args);
+
+    sc_invocation inv SC_CLEANUP(sc_cleanup_invocation);
+    sc_init_invocation(&inv, args, "foo", NULL);
+
+    g_assert_cmpstr(inv.security_tag, ==, "snap.foo.app");
+    g_assert_true(inv.private_tmp);
+    g_assert_cmpstr(inv.protect_system, ==, "true");
+}
+
 static void test_sc_invocation_instance_key(snap_mount_dir_fixture *fix, gconstpointer user_data) {
     struct sc_args *args SC_CLEANUP(sc_cleanup_args) = test_prepare_args("core", "snap.foo_bar.app");
 
@@ -220,6 +242,8 @@ static void __attribute__((constructor)) init(void) {
                test_sc_invocation_base_name, snap_mount_dir_fixture_teardown);
     g_test_add("/invocation/basic", snap_mount_dir_fixture, "/snap", snap_mount_dir_fixture_setup,
                test_sc_invocation_basic, snap_mount_dir_fixture_teardown);
+    g_test_add("/invocation/hardening", snap_mount_dir_fixture, "/snap", snap_mount_dir_fixture_setup,
+               test_sc_invocation_hardening, snap_mount_dir_fixture_teardown);
     g_test_add("/invocation/classic", snap_mount_dir_fixture, "/snap", snap_mount_dir_fixture_setup,
                test_sc_invocation_classic, snap_mount_dir_fixture_teardown);
     g_test_add("/invocation/instance_key", snap_mount_dir_fixture, "/snap", snap_mount_dir_fixture_setup,
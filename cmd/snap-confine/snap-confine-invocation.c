@@ -88,6 +88,11 @@ void sc_init_invocation(sc_invocation *inv, const struct sc_args *args, const ch
     inv->snap_component = snap_component != NULL ? sc_strdup(snap_component) : NULL;
     inv->snap_name = sc_strdup(snap_name);
     inv->classic_confinement = sc_args_is_classic_confinement(args);
+    /* The hardening requested on the command line only restricts the
+     * application further, there is nothing to validate. */
+    inv->private_tmp = sc_args_private_tmp(args);
+    const char *protect_system = sc_args_protect_system(args);
+    inv->protect_system = protect_system != NULL ? sc_strdup(protect_system) : NULL;
 
     // construct rootfs_dir based on base_snap_name
     char mount_point[PATH_MAX] = {0};
@@ -110,6 +115,7 @@ void sc_cleanup_invocation(sc_invocation *inv) {
         sc_cleanup_string(&inv->executable);
         sc_cleanup_string(&inv->rootfs_dir);
         sc_cleanup_string(&inv->snap_component);
+        sc_cleanup_string(&inv->protect_system);
         sc_cleanup_deep_strv(&inv->homedirs);
     }
 }
@@ -36,6 +36,10 @@ typedef struct sc_invocation {
     char *security_tag;
     char *executable;
     bool classic_confinement;
+    /* Hardening requested for the application, applied in an ephemeral
+     * per-app mount namespace. */
+    bool private_tmp;
+    char *protect_system; /* NULL, "true" or "full" */
     /* Things derived at runtime. */
     char *base_snap_name;
     char *rootfs_dir;
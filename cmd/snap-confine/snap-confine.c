@@ -931,6 +931,17 @@ static void enter_non_classic_execution_environment(sc_invocation *inv, struct s
             debug("NOT preserving per-user mount namespace");
         }
     }
+    /* Hardening requested by the application is applied in yet another mount
+     * namespace. Just like the per-user one, it is never preserved, so that
+     * the hardening does not leak into the per-snap mount namespace that the
+     * other applications of the snap join. */
+    if (inv->private_tmp || inv->protect_system != NULL) {
+        debug("unsharing the mount namespace (per-app hardening)");
+        if (unshare(CLONE_NEWNS) < 0) {
+            die("cannot unshare the mount namespace");
+        }
+        sc_setup_app_hardening(inv);
+    }
     // With cgroups v1, associate each snap process with a dedicated
     // snap freezer cgroup and snap pids cgroup. All snap processes
     // belonging to one snap share the freezer cgroup. All snap
@@ -20,8 +20,11 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"text/tabwriter"
+
+	"github.com/snapcore/snapd/cmd/snap-repair/repairtrace"
 )
 
 func init() {
@@ -36,9 +39,24 @@ func init() {
 
 }
 
-type cmdList struct{}
+type cmdList struct {
+	JSON bool `long:"json" description:"Print the repairs as JSON"`
+}
+
+// writeJSON outputs the given repair traces as a JSON list to Stdout.
+func writeJSON(traces []*repairtrace.Trace, withLogs bool) error {
+	infos := make([]*repairtrace.Info, 0, len(traces))
+	for _, t := range traces {
+		infos = append(infos, t.Info(withLogs))
+	}
+	return json.NewEncoder(Stdout).Encode(infos)
+}
 
 func (c *cmdList) Execute([]string) error {
+	// the command is registered once, do not carry its options over to
+	// the next invocation
+	defer func() { *c = cmdList{} }()
+
 	w := tabwriter.NewWriter(Stdout, 5, 3, 2, ' ', 0)
 	defer w.Flush()
 
@@ -56,10 +74,13 @@ func (c *cmdList) Execute([]string) error {
 	//    2/
 	//      r3.done
 	//      r3.script
-	repairTraces, err := newRepairTraces("*", "*")
+	repairTraces, err := repairtrace.Find("*", "*")
 	if err != nil {
 		return err
 	}
+	if c.JSON {
+		return writeJSON(repairTraces, false)
+	}
 	if len(repairTraces) == 0 {
 		fmt.Fprintf(Stderr, "no repairs yet\n")
 		return nil
@@ -45,3 +45,23 @@ my-brand-3   0    running  my-brand repair three
 `)
 	c.Check(r.Stderr(), Equals, "")
 }
+
+func (r *repairSuite) TestListRepairsJSON(c *C) {
+	makeMockRepairState(c)
+
+	err := repair.ParseArgs([]string{"list", "--json"})
+	c.Check(err, IsNil)
+	c.Check(r.Stdout(), Equals, `[{"repair":"canonical-1","revision":3,"status":"retry","summary":"repair one"},`+
+		`{"repair":"my-brand-1","revision":1,"status":"done","summary":"my-brand repair one"},`+
+		`{"repair":"my-brand-2","revision":2,"status":"skip","summary":"my-brand repair two"},`+
+		`{"repair":"my-brand-3","revision":0,"status":"running","summary":"my-brand repair three"}]
+`)
+	c.Check(r.Stderr(), Equals, "")
+}
+
+func (r *repairSuite) TestListNoRepairsYetJSON(c *C) {
+	err := repair.ParseArgs([]string{"list", "--json"})
+	c.Check(err, IsNil)
+	c.Check(r.Stdout(), Equals, "[]\n")
+	c.Check(r.Stderr(), Equals, "")
+}
@@ -44,7 +44,9 @@ func init() {
 
 }
 
-type cmdRun struct{}
+type cmdRun struct {
+	FromFile string `long:"from-file" value-name:"<file>" description:"Run the repairs found in the given file instead of fetching them, for devices without network access"`
+}
 
 var baseURL *url.URL
 
@@ -93,6 +95,11 @@ func (c *cmdRun) Execute(args []string) error {
 	if err != nil {
 		return err
 	}
+	if c.FromFile != "" {
+		if err := run.LoadRepairsFromFile(c.FromFile); err != nil {
+			return err
+		}
+	}
 
 	for _, rootRepairBrandID := range rootBrandIDs {
 		for {
@@ -107,6 +107,58 @@ exit 0
 	c.Check(osutil.FileExists(filepath.Join(dirs.SnapRepairRunDir, "canonical", "1", "r0.done")), Equals, true)
 }
 
+func (r *repairSuite) TestRunFromFile(c *C) {
+	restore := repair.MockOsGetuid(func() int { return 0 })
+	defer restore()
+	restore = release.MockOnClassic(false)
+	defer restore()
+
+	r1 := sysdb.InjectTrusted(r.storeSigning.Trusted)
+	defer r1()
+	r2 := repair.MockTrustedRepairRootKeys([]*asserts.AccountKey{r.repairRootAcctKey})
+	defer r2()
+
+	r.freshState(c)
+
+	// the store being offline does not matter
+	data, err := json.Marshal(repair.RepairConfig{
+		StoreOffline: true,
+	})
+	c.Assert(err, IsNil)
+	c.Assert(os.MkdirAll(filepath.Dir(dirs.SnapRepairConfigFile), 0755), IsNil)
+	c.Assert(osutil.AtomicWriteFile(dirs.SnapRepairConfigFile, data, 0644, 0), IsNil)
+
+	const script = `#!/bin/sh
+echo "happy output"
+echo "done" >&$SNAP_REPAIR_STATUS_FD
+exit 0
+`
+	seqRepairs := r.signSeqRepairs(c, []string{makeMockRepair(script)})
+	repairsFile := filepath.Join(c.MkDir(), "repairs")
+	c.Assert(os.WriteFile(repairsFile, []byte(seqRepairs[0]), 0644), IsNil)
+
+	origArgs := os.Args
+	defer func() { os.Args = origArgs }()
+	os.Args = []string{"snap-repair", "run", "--from-file", repairsFile}
+	err = repair.Run()
+	c.Check(err, IsNil)
+	c.Check(r.Stdout(), HasLen, 0)
+
+	c.Check(osutil.FileExists(filepath.Join(dirs.SnapRepairRunDir, "canonical", "1", "r0.done")), Equals, true)
+}
+
+func (r *repairSuite) TestRunFromFileError(c *C) {
+	restore := repair.MockOsGetuid(func() int { return 0 })
+	defer restore()
+	restore = release.MockOnClassic(false)
+	defer restore()
+
+	r.freshState(c)
+
+	err := repair.ParseArgs([]string{"run", "--from-file", filepath.Join(c.MkDir(), "missing")})
+	c.Check(err, ErrorMatches, `cannot read repairs: open .*/missing: no such file or directory`)
+}
+
 func (r *repairSuite) TestRunAlreadyLocked(c *C) {
 	err := os.MkdirAll(dirs.SnapRunRepairDir, 0700)
 	c.Assert(err, IsNil)
@@ -23,6 +23,8 @@ import (
 	"fmt"
 	"io"
 	"strings"
+
+	"github.com/snapcore/snapd/cmd/snap-repair/repairtrace"
 )
 
 func init() {
@@ -38,25 +40,31 @@ func init() {
 }
 
 type cmdShow struct {
+	JSON       bool `long:"json" description:"Print the repairs, including their script and output, as JSON"`
 	Positional struct {
 		Repair []string `positional-arg-name:"<repair>"`
 	} `positional-args:"yes"`
 }
 
-func showRepairDetails(w io.Writer, repair string) error {
-	i := strings.LastIndex(repair, "-")
-	if i < 0 {
-		return fmt.Errorf("cannot parse repair %q", repair)
-	}
-	brand := repair[:i]
-	seq := repair[i+1:]
+func indentPrefix(level int) string {
+	return strings.Repeat(" ", level)
+}
 
-	repairTraces, err := newRepairTraces(brand, seq)
+func findRepairTraces(repair string) ([]*repairtrace.Trace, error) {
+	repairTraces, err := repairtrace.FindRepair(repair)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if len(repairTraces) == 0 {
-		return fmt.Errorf("cannot find repair \"%s-%s\"", brand, seq)
+		return nil, fmt.Errorf("cannot find repair %q", repair)
+	}
+	return repairTraces, nil
+}
+
+func showRepairDetails(w io.Writer, repair string) error {
+	repairTraces, err := findRepairTraces(repair)
+	if err != nil {
+		return err
 	}
 
 	for _, trace := range repairTraces {
@@ -80,6 +88,22 @@ func showRepairDetails(w io.Writer, repair string) error {
 }
 
 func (c *cmdShow) Execute([]string) error {
+	// the command is registered once, do not carry its options over to
+	// the next invocation
+	defer func() { *c = cmdShow{} }()
+
+	if c.JSON {
+		var repairTraces []*repairtrace.Trace
+		for _, repair := range c.Positional.Repair {
+			traces, err := findRepairTraces(repair)
+			if err != nil {
+				return err
+			}
+			repairTraces = append(repairTraces, traces...)
+		}
+		return writeJSON(repairTraces, true)
+	}
+
 	for _, repair := range c.Positional.Repair {
 		if err := showRepairDetails(Stdout, repair); err != nil {
 			return err
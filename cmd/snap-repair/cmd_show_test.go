@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 
 	. "gopkg.in/check.v1"
 
@@ -143,3 +144,24 @@ output:
 `, scriptPath))
 
 }
+
+func (r *repairSuite) TestShowRepairJSON(c *C) {
+	makeMockRepairState(c)
+	scriptPath := filepath.Join(dirs.SnapRepairRunDir, "my-brand/1", "r1.script")
+	c.Assert(os.Remove(scriptPath), IsNil)
+
+	cmd := repair.NewCmdShow("canonical-1", "my-brand-1")
+	cmd.JSON = true
+	err := cmd.Execute(nil)
+	c.Check(err, IsNil)
+	c.Check(r.Stdout(), Equals, `[{"repair":"canonical-1","revision":3,"status":"retry","summary":"repair one","script":"#!/bin/sh\necho retry output\n","output":"retry output\n"},`+
+		`{"repair":"my-brand-1","revision":1,"status":"done","summary":"my-brand repair one","output":"done output\n"}]
+`)
+}
+
+func (r *repairSuite) TestShowRepairErrorInvalidRepair(c *C) {
+	for _, name := range []string{"canonical", "canonical-", "-1", "canonical-*", "*-1", "canonical-1a"} {
+		err := repair.NewCmdShow(name).Execute(nil)
+		c.Check(err, ErrorMatches, regexp.QuoteMeta(fmt.Sprintf("cannot parse repair %q", name)))
+	}
+}
@@ -1,7 +1,7 @@
 // -*- Mode: Go; indent-tabs-mode: t -*-
 
 /*
- * Copyright (C) 2017 Canonical Ltd
+ * Copyright (C) 2017-2026 Canonical Ltd
  *
  * This program is free software: you can redistribute it and/or modify
  * it under the terms of the GNU General Public License version 3 as
@@ -17,10 +17,13 @@
  *
  */
 
-package main
+// Package repairtrace gives access to the traces left behind by the repairs
+// that snap-repair ran on the device.
+package repairtrace
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"io"
 	"os"
@@ -32,46 +35,67 @@ import (
 	"github.com/snapcore/snapd/dirs"
 )
 
-// newRepairTraces returns all repairTrace about the given "brand" and "seq"
-// that can be found. brand, seq can be filepath.Glob expressions.
-func newRepairTraces(brand, seq string) ([]*repairTrace, error) {
+// Find returns all traces about the given "brand" and "seq" that can be
+// found. brand, seq can be filepath.Glob expressions.
+func Find(brand, seq string) ([]*Trace, error) {
 	matches, err := filepath.Glob(filepath.Join(dirs.SnapRepairRunDir, brand, seq, "*"))
 	if err != nil {
 		return nil, err
 	}
 
-	var repairTraces []*repairTrace
+	var traces []*Trace
 	for _, match := range matches {
-		if trace := newRepairTraceFromPath(match); trace != nil {
-			repairTraces = append(repairTraces, trace)
+		if trace := newTraceFromPath(match); trace != nil {
+			traces = append(traces, trace)
 		}
 	}
 
-	return repairTraces, nil
+	return traces, nil
 }
 
-// repairTrace holds information about a repair that was run.
-type repairTrace struct {
+var validRepairSeq = regexp.MustCompile(`^[0-9]+$`)
+
+// ParseRepair splits a repair in the form $brand-$seq, as returned by
+// Trace.Repair, into its brand and sequence number.
+func ParseRepair(repair string) (brand, seq string, err error) {
+	i := strings.LastIndex(repair, "-")
+	if i <= 0 || !validRepairSeq.MatchString(repair[i+1:]) || strings.ContainsAny(repair[:i], `/*?[]\`) {
+		return "", "", fmt.Errorf("cannot parse repair %q", repair)
+	}
+	return repair[:i], repair[i+1:], nil
+}
+
+// FindRepair returns all traces of the given repair in the form $brand-$seq.
+func FindRepair(repair string) ([]*Trace, error) {
+	brand, seq, err := ParseRepair(repair)
+	if err != nil {
+		return nil, err
+	}
+	return Find(brand, seq)
+}
+
+// Trace holds information about a repair that was run.
+type Trace struct {
 	path string
 }
 
-// validRepairTraceName checks that the given name looks like a valid repair
+// validTraceName checks that the given name looks like a valid repair
 // trace
-var validRepairTraceName = regexp.MustCompile(`^r[0-9]+\.(done|skip|retry|running)$`)
+var validTraceName = regexp.MustCompile(`^r[0-9]+\.(done|skip|retry|running)$`)
 
-// newRepairTraceFromPath takes a repair log path like
+// newTraceFromPath takes a repair log path like
 // the path /var/lib/snapd/repair/run/my-brand/1/r2.done
 // and contructs a repair log from that.
-func newRepairTraceFromPath(path string) *repairTrace {
-	rt := &repairTrace{path: path}
-	if !validRepairTraceName.MatchString(filepath.Base(path)) {
+func newTraceFromPath(path string) *Trace {
+	rt := &Trace{path: path}
+	if !validTraceName.MatchString(filepath.Base(path)) {
 		return nil
 	}
 	return rt
 }
 
 // Repair returns the repair human readable string in the form $brand-$id
-func (rt *repairTrace) Repair() string {
+func (rt *Trace) Repair() string {
 	seq := filepath.Base(filepath.Dir(rt.path))
 	brand := filepath.Base(filepath.Dir(filepath.Dir(rt.path)))
 
@@ -79,7 +103,7 @@ func (rt *repairTrace) Repair() string {
 }
 
 // Revision returns the revision of the repair
-func (rt *repairTrace) Revision() string {
+func (rt *Trace) Revision() string {
 	rev, err := revFromFilepath(rt.path)
 	if err != nil {
 		// this can never happen because we check that path starts
@@ -87,14 +111,21 @@ func (rt *repairTrace) Revision() string {
 		// case.
 		return "-"
 	}
-	return rev
+	return strconv.Itoa(rev)
 }
 
 // Summary returns the summary of the repair that was run
-func (rt *repairTrace) Summary() string {
+func (rt *Trace) Summary() string {
+	if summary := rt.summary(); summary != "" {
+		return summary
+	}
+	return "-"
+}
+
+func (rt *Trace) summary() string {
 	f, err := os.Open(rt.path)
 	if err != nil {
-		return "-"
+		return ""
 	}
 	defer f.Close()
 
@@ -107,11 +138,11 @@ func (rt *repairTrace) Summary() string {
 		}
 	}
 
-	return "-"
+	return ""
 }
 
 // Status returns the status of the given repair {done,skip,retry,running}
-func (rt *repairTrace) Status() string {
+func (rt *Trace) Status() string {
 	return filepath.Ext(rt.path)[1:]
 }
 
@@ -121,7 +152,7 @@ func indentPrefix(level int) string {
 
 // WriteScriptIndented outputs the script that produced this repair output
 // to the given writer w with the indent level given by indent.
-func (rt *repairTrace) WriteScriptIndented(w io.Writer, indent int) error {
+func (rt *Trace) WriteScriptIndented(w io.Writer, indent int) error {
 	scriptPath := rt.path[:strings.LastIndex(rt.path, ".")] + ".script"
 	f, err := os.Open(scriptPath)
 	if err != nil {
@@ -141,7 +172,7 @@ func (rt *repairTrace) WriteScriptIndented(w io.Writer, indent int) error {
 
 // WriteOutputIndented outputs the repair output to the given writer w
 // with the indent level given by indent.
-func (rt *repairTrace) WriteOutputIndented(w io.Writer, indent int) error {
+func (rt *Trace) WriteOutputIndented(w io.Writer, indent int) error {
 	f, err := os.Open(rt.path)
 	if err != nil {
 		return err
@@ -165,12 +196,48 @@ func (rt *repairTrace) WriteOutputIndented(w io.Writer, indent int) error {
 	return nil
 }
 
+// Info is the structured representation of a repair trace.
+type Info struct {
+	Repair   string `json:"repair"`
+	Revision int    `json:"revision"`
+	Status   string `json:"status"`
+	Summary  string `json:"summary,omitempty"`
+	// Script and Output are only set when the logs are requested and
+	// they could be read.
+	Script string `json:"script,omitempty"`
+	Output string `json:"output,omitempty"`
+}
+
+// Info returns the structured representation of the trace, including the
+// script that was run and its output if withLogs is set.
+func (rt *Trace) Info(withLogs bool) *Info {
+	// the path was validated when the trace was created
+	rev, _ := revFromFilepath(rt.path)
+	info := &Info{
+		Repair:   rt.Repair(),
+		Revision: rev,
+		Status:   rt.Status(),
+		Summary:  rt.summary(),
+	}
+	if withLogs {
+		var buf bytes.Buffer
+		if err := rt.WriteScriptIndented(&buf, 0); err == nil {
+			info.Script = buf.String()
+		}
+		buf.Reset()
+		if err := rt.WriteOutputIndented(&buf, 0); err == nil {
+			info.Output = buf.String()
+		}
+	}
+	return info
+}
+
 // revFromFilepath is a helper that extracts the revision number from the
-// filename of the repairTrace
-func revFromFilepath(name string) (string, error) {
+// filename of the trace
+func revFromFilepath(name string) (int, error) {
 	var rev int
 	if _, err := fmt.Sscanf(filepath.Base(name), "r%d.", &rev); err == nil {
-		return strconv.Itoa(rev), nil
+		return rev, nil
 	}
-	return "", fmt.Errorf("cannot find revision in %q", name)
+	return 0, fmt.Errorf("cannot find revision in %q", name)
 }
@@ -0,0 +1,146 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package repairtrace_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/cmd/snap-repair/repairtrace"
+	"github.com/snapcore/snapd/dirs"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type repairTraceSuite struct{}
+
+var _ = Suite(&repairTraceSuite{})
+
+func (s *repairTraceSuite) SetUpTest(c *C) {
+	dirs.SetRootDir(c.MkDir())
+}
+
+func (s *repairTraceSuite) TearDownTest(c *C) {
+	dirs.SetRootDir("")
+}
+
+func (s *repairTraceSuite) writeTrace(c *C, brand, seq, name, content string) {
+	basedir := filepath.Join(dirs.SnapRepairRunDir, brand, seq)
+	c.Assert(os.MkdirAll(basedir, 0700), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(basedir, name), []byte(content), 0600), IsNil)
+}
+
+func (s *repairTraceSuite) TestFind(c *C) {
+	s.writeTrace(c, "canonical", "1", "r0.retry", "repair: canonical-1\nsummary: one\noutput:\n")
+	s.writeTrace(c, "canonical", "1", "r1.done", "repair: canonical-1\nsummary: one\noutput:\n")
+	s.writeTrace(c, "canonical", "1", "r1.script", "#!/bin/sh\n")
+	s.writeTrace(c, "canonical", "2", "r0.skip", "repair: canonical-2\noutput:\n")
+	s.writeTrace(c, "my-brand", "1", "r4.running", "repair: my-brand-1\noutput:\n")
+
+	traces, err := repairtrace.Find("*", "*")
+	c.Assert(err, IsNil)
+	var found []string
+	for _, t := range traces {
+		found = append(found, t.Repair()+"/"+t.Revision()+"/"+t.Status())
+	}
+	c.Check(found, DeepEquals, []string{
+		"canonical-1/0/retry", "canonical-1/1/done", "canonical-2/0/skip", "my-brand-1/4/running",
+	})
+
+	traces, err = repairtrace.FindRepair("my-brand-1")
+	c.Assert(err, IsNil)
+	c.Assert(traces, HasLen, 1)
+	c.Check(traces[0].Repair(), Equals, "my-brand-1")
+
+	traces, err = repairtrace.FindRepair("my-brand-2")
+	c.Assert(err, IsNil)
+	c.Check(traces, HasLen, 0)
+}
+
+func (s *repairTraceSuite) TestParseRepair(c *C) {
+	brand, seq, err := repairtrace.ParseRepair("my-brand-12")
+	c.Assert(err, IsNil)
+	c.Check(brand, Equals, "my-brand")
+	c.Check(seq, Equals, "12")
+
+	for _, name := range []string{"", "canonical", "canonical-", "-1", "canonical-x", "canonical-*", "*-1", "a/b-1", "c[a]-1"} {
+		_, _, err := repairtrace.ParseRepair(name)
+		c.Check(err, ErrorMatches, `cannot parse repair ".*"`, Commentf(name))
+	}
+
+	_, err = repairtrace.FindRepair("canonical-*")
+	c.Check(err, ErrorMatches, `cannot parse repair "canonical-\*"`)
+}
+
+func (s *repairTraceSuite) TestSummaryAndLogs(c *C) {
+	s.writeTrace(c, "canonical", "1", "r2.done", "repair: canonical-1\nsummary: fix things\noutput:\nline 1\nline 2\n")
+	s.writeTrace(c, "canonical", "1", "r2.script", "#!/bin/sh\necho fixed\n")
+	s.writeTrace(c, "canonical", "2", "r0.skip", "repair: canonical-2\noutput:\n")
+
+	traces, err := repairtrace.Find("canonical", "*")
+	c.Assert(err, IsNil)
+	c.Assert(traces, HasLen, 2)
+
+	c.Check(traces[0].Summary(), Equals, "fix things")
+	var buf bytes.Buffer
+	c.Assert(traces[0].WriteScriptIndented(&buf, 2), IsNil)
+	c.Check(buf.String(), Equals, "  #!/bin/sh\n  echo fixed\n")
+	buf.Reset()
+	c.Assert(traces[0].WriteOutputIndented(&buf, 1), IsNil)
+	c.Check(buf.String(), Equals, " line 1\n line 2\n")
+
+	c.Check(traces[1].Summary(), Equals, "-")
+	c.Check(traces[1].WriteScriptIndented(&buf, 0), ErrorMatches, "open .*/r0.script: no such file or directory")
+}
+
+func (s *repairTraceSuite) TestInfo(c *C) {
+	s.writeTrace(c, "canonical", "1", "r2.done", "repair: canonical-1\nsummary: fix things\noutput:\nline 1\n")
+	s.writeTrace(c, "canonical", "1", "r2.script", "#!/bin/sh\necho fixed\n")
+	s.writeTrace(c, "canonical", "2", "r0.skip", "repair: canonical-2\noutput:\n")
+
+	traces, err := repairtrace.Find("canonical", "*")
+	c.Assert(err, IsNil)
+	c.Assert(traces, HasLen, 2)
+
+	c.Check(traces[0].Info(false), DeepEquals, &repairtrace.Info{
+		Repair:   "canonical-1",
+		Revision: 2,
+		Status:   "done",
+		Summary:  "fix things",
+	})
+	c.Check(traces[0].Info(true), DeepEquals, &repairtrace.Info{
+		Repair:   "canonical-1",
+		Revision: 2,
+		Status:   "done",
+		Summary:  "fix things",
+		Script:   "#!/bin/sh\necho fixed\n",
+		Output:   "line 1\n",
+	})
+	// no summary, script nor output
+	c.Check(traces[1].Info(true), DeepEquals, &repairtrace.Info{
+		Repair:   "canonical-2",
+		Revision: 0,
+		Status:   "skip",
+	})
+}
@@ -256,6 +256,10 @@ type Runner struct {
 
 	// sequenceNext keeps track of the next integer id in a brand sequence to considered in this run, see Next.
 	sequenceNext map[string]int
+
+	// local holds the repairs by brand and repair id when they are read
+	// from a file instead of fetched, see LoadRepairsFromFile.
+	local map[string]map[int]localRepair
 }
 
 // NewRunner returns a Runner.
@@ -964,6 +968,10 @@ func (run *Runner) Applicable(headers map[string]any) bool {
 var errSkip = errors.New("repair unnecessary on this system")
 
 func (run *Runner) fetch(brandID string, repairID int) (repair *asserts.Repair, aux []asserts.Assertion, err error) {
+	if run.local != nil {
+		// applicability is checked once the repair is verified
+		return run.localRepair(brandID, repairID, -1)
+	}
 	headers, err := run.Peek(brandID, repairID)
 	if err != nil {
 		return nil, nil, err
@@ -975,9 +983,99 @@ func (run *Runner) fetch(brandID string, repairID int) (repair *asserts.Repair,
 }
 
 func (run *Runner) refetch(brandID string, repairID, revision int) (repair *asserts.Repair, aux []asserts.Assertion, err error) {
+	if run.local != nil {
+		return run.localRepair(brandID, repairID, revision)
+	}
 	return run.Fetch(brandID, repairID, revision)
 }
 
+// localRepair is a repair read from a file, with the account keys found
+// along with it.
+type localRepair struct {
+	repair *asserts.Repair
+	aux    []asserts.Assertion
+}
+
+// LoadRepairsFromFile makes the runner use the repairs found in the given
+// file instead of fetching them from the network. This is meant for devices
+// without network access, with the file provided by an operator, e.g. on
+// removable media. The file is a stream of repair assertions and of the
+// account keys needed to verify them, the repairs are verified and checked
+// for applicability like fetched ones.
+func (run *Runner) LoadRepairsFromFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("cannot read repairs: %v", err)
+	}
+	defer f.Close()
+
+	dec := asserts.NewDecoderWithTypeMaxBodySize(f, map[*asserts.AssertionType]int{
+		asserts.RepairType: maxRepairScriptSize,
+	})
+	var repairs []*asserts.Repair
+	var aux []asserts.Assertion
+	seenKeys := make(map[string]bool)
+	for {
+		a, err := dec.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("cannot decode repairs from %s: %v", path, err)
+		}
+		switch a := a.(type) {
+		case *asserts.Repair:
+			repairs = append(repairs, a)
+		case *asserts.AccountKey:
+			// streams of several repairs usually repeat the keys
+			if !seenKeys[a.Ref().Unique()] {
+				seenKeys[a.Ref().Unique()] = true
+				aux = append(aux, a)
+			}
+		default:
+			return fmt.Errorf("cannot use repairs from %s: unexpected %q assertion", path, a.Type().Name)
+		}
+	}
+	if len(repairs) == 0 {
+		return fmt.Errorf("cannot use repairs from %s: no repair found", path)
+	}
+
+	local := make(map[string]map[int]localRepair)
+	for _, repair := range repairs {
+		brandID := repair.BrandID()
+		if !strutil.ListContains(rootBrandIDs, brandID) {
+			return fmt.Errorf("cannot use repair %s-%d from %s: unsupported brand", brandID, repair.RepairID(), path)
+		}
+		if local[brandID] == nil {
+			local[brandID] = make(map[int]localRepair)
+		}
+		// only the latest revision of a repair is relevant
+		if cur, ok := local[brandID][repair.RepairID()]; ok && cur.repair.Revision() >= repair.Revision() {
+			continue
+		}
+		local[brandID][repair.RepairID()] = localRepair{repair: repair, aux: aux}
+	}
+	run.local = local
+	return nil
+}
+
+// localRepair returns the repair with the given ids loaded from a file. It
+// behaves like Fetch, with a revision>=0 ErrRepairNotModified is returned
+// unless a newer revision of the repair was loaded.
+func (run *Runner) localRepair(brandID string, repairID, revision int) (*asserts.Repair, []asserts.Assertion, error) {
+	lr, ok := run.local[brandID][repairID]
+	if !ok {
+		if revision >= 0 {
+			return nil, nil, ErrRepairNotModified
+		}
+		return nil, nil, ErrRepairNotFound
+	}
+	if lr.repair.Revision() <= revision {
+		return nil, nil, ErrRepairNotModified
+	}
+	return lr.repair, lr.aux, nil
+}
+
 func (run *Runner) saveStream(brandID string, repairID int, repair *asserts.Repair, aux []asserts.Assertion) error {
 	d := filepath.Join(dirs.SnapRepairAssertsDir, brandID, strconv.Itoa(repairID))
 	err := os.MkdirAll(d, 0775)
@@ -1347,6 +1347,156 @@ func (s *runnerSuite) TestNextVerifyAllKeysOK(c *C) {
 	c.Check(rpr.RepairID(), Equals, 1)
 }
 
+func makeMockRepairWithID(repairID, revision int, series string) string {
+	return fmt.Sprintf(`type: repair
+authority-id: canonical
+brand-id: canonical
+repair-id: %d
+revision: %d
+summary: repair %d
+series:
+  - %s
+timestamp: 2017-07-02T12:00:00Z
+body-length: 7
+sign-key-sha3-384: KPIl7M4vQ9d4AUjkoU41TGAwtOMLc_bWUCeW8AvdRWD4_xcP60Oo4ABsFNo6BtXj
+
+script
+
+
+AXNpZw==`, repairID, revision, repairID, series)
+}
+
+func (s *runnerSuite) writeRepairsFile(c *C, repairs ...string) string {
+	p := filepath.Join(c.MkDir(), "repairs")
+	c.Assert(os.WriteFile(p, []byte(strings.Join(s.signSeqRepairs(c, repairs), "\n")), 0644), IsNil)
+	return p
+}
+
+func (s *runnerSuite) TestNextFromFile(c *C) {
+	r1 := sysdb.InjectTrusted(s.storeSigning.Trusted)
+	defer r1()
+	r2 := repair.MockTrustedRepairRootKeys([]*asserts.AccountKey{s.repairRootAcctKey})
+	defer r2()
+
+	p := s.writeRepairsFile(c,
+		makeMockRepairWithID(2, 0, "16"),
+		makeMockRepairWithID(1, 0, "16"),
+		// not applicable
+		makeMockRepairWithID(3, 0, "33"),
+		// only the latest revision is considered
+		makeMockRepairWithID(4, 1, "16"),
+		makeMockRepairWithID(4, 2, "16"),
+	)
+
+	runner := repair.NewRunner()
+	// the network is not used
+	runner.BaseURL = mustParseURL("https://127.0.0.1:0/")
+	runner.LoadState()
+	c.Assert(runner.LoadRepairsFromFile(p), IsNil)
+
+	rpr, err := runner.Next("canonical")
+	c.Assert(err, IsNil)
+	c.Check(rpr.RepairID(), Equals, 1)
+
+	rpr, err = runner.Next("canonical")
+	c.Assert(err, IsNil)
+	c.Check(rpr.RepairID(), Equals, 2)
+
+	rpr, err = runner.Next("canonical")
+	c.Assert(err, IsNil)
+	c.Check(rpr.RepairID(), Equals, 4)
+	c.Check(rpr.Revision(), Equals, 2)
+
+	_, err = runner.Next("canonical")
+	c.Check(err, Equals, repair.ErrRepairNotFound)
+
+	expectedSeq := []*repair.RepairState{
+		{Sequence: 1},
+		{Sequence: 2},
+		{Sequence: 3, Status: repair.SkipStatus},
+		{Sequence: 4, Revision: 2},
+	}
+	c.Check(runner.Sequence("canonical"), DeepEquals, expectedSeq)
+	c.Check(s.loadSequences(c)["canonical"], DeepEquals, expectedSeq)
+
+	// the repairs are kept on disk like fetched ones
+	c.Check(filepath.Join(dirs.SnapRepairAssertsDir, "canonical", "4", "r2.repair"), testutil.FilePresent)
+}
+
+func (s *runnerSuite) TestNextFromFileRetry(c *C) {
+	r1 := sysdb.InjectTrusted(s.storeSigning.Trusted)
+	defer r1()
+	r2 := repair.MockTrustedRepairRootKeys([]*asserts.AccountKey{s.repairRootAcctKey})
+	defer r2()
+
+	runner := repair.NewRunner()
+	runner.LoadState()
+	c.Assert(runner.LoadRepairsFromFile(s.writeRepairsFile(c, makeMockRepairWithID(1, 0, "16"))), IsNil)
+	rpr, err := runner.Next("canonical")
+	c.Assert(err, IsNil)
+	c.Check(rpr.Revision(), Equals, 0)
+
+	// the repair is retried with what is on disk when not in the file
+	runner = repair.NewRunner()
+	runner.LoadState()
+	c.Assert(runner.LoadRepairsFromFile(s.writeRepairsFile(c, makeMockRepairWithID(2, 0, "16"))), IsNil)
+	rpr, err = runner.Next("canonical")
+	c.Assert(err, IsNil)
+	c.Check(rpr.RepairID(), Equals, 1)
+	c.Check(rpr.Revision(), Equals, 0)
+
+	// or with a new revision from the file
+	runner = repair.NewRunner()
+	runner.LoadState()
+	c.Assert(runner.LoadRepairsFromFile(s.writeRepairsFile(c, makeMockRepairWithID(1, 1, "16"))), IsNil)
+	rpr, err = runner.Next("canonical")
+	c.Assert(err, IsNil)
+	c.Check(rpr.RepairID(), Equals, 1)
+	c.Check(rpr.Revision(), Equals, 1)
+}
+
+func (s *runnerSuite) TestNextFromFileVerifyFails(c *C) {
+	// the repair-root key is not trusted
+	runner := repair.NewRunner()
+	runner.LoadState()
+	c.Assert(runner.LoadRepairsFromFile(s.writeRepairsFile(c, makeMockRepairWithID(1, 0, "16"))), IsNil)
+
+	_, err := runner.Next("canonical")
+	c.Check(err, ErrorMatches, `cannot verify repair canonical-1: .*`)
+	c.Check(runner.Sequence("canonical"), HasLen, 0)
+}
+
+func (s *runnerSuite) TestLoadRepairsFromFileErrors(c *C) {
+	runner := repair.NewRunner()
+
+	err := runner.LoadRepairsFromFile(filepath.Join(c.MkDir(), "missing"))
+	c.Check(err, ErrorMatches, `cannot read repairs: open .*/missing: no such file or directory`)
+
+	p := filepath.Join(c.MkDir(), "repairs")
+	c.Assert(os.WriteFile(p, []byte("garbage"), 0644), IsNil)
+	err = runner.LoadRepairsFromFile(p)
+	c.Check(err, ErrorMatches, `cannot decode repairs from .*/repairs: .*`)
+
+	c.Assert(os.WriteFile(p, nil, 0644), IsNil)
+	err = runner.LoadRepairsFromFile(p)
+	c.Check(err, ErrorMatches, `cannot use repairs from .*/repairs: no repair found`)
+
+	c.Assert(os.WriteFile(p, asserts.Encode(s.modelAs), 0644), IsNil)
+	err = runner.LoadRepairsFromFile(p)
+	c.Check(err, ErrorMatches, `cannot use repairs from .*/repairs: unexpected "model" assertion`)
+
+	brandRepair, err := s.brandSigning.Sign(asserts.RepairType, map[string]any{
+		"brand-id":  "my-brand",
+		"repair-id": "1",
+		"summary":   "brand repair",
+		"timestamp": "2017-07-02T12:00:00Z",
+	}, []byte("script"), "")
+	c.Assert(err, IsNil)
+	c.Assert(os.WriteFile(p, asserts.Encode(brandRepair), 0644), IsNil)
+	err = runner.LoadRepairsFromFile(p)
+	c.Check(err, ErrorMatches, `cannot use repair my-brand-1 from .*/repairs: unsupported brand`)
+}
+
 func (s *runnerSuite) TestRepairSetStatus(c *C) {
 	seqRepairs := []string{`type: repair
 authority-id: canonical
@@ -26,12 +26,14 @@ import (
 )
 
 var (
-	Compile           = compile
-	SeccompResolver   = seccompResolver
-	VersionInfo       = versionInfo
-	GoSeccompFeatures = goSeccompFeatures
-	ExportBPF         = exportBPF
-	Dump              = dump
+	Compile            = compile
+	SeccompResolver    = seccompResolver
+	VersionInfo        = versionInfo
+	GoSeccompFeatures  = goSeccompFeatures
+	ExportBPF          = exportBPF
+	Dump               = dump
+	ResolveSyscall     = resolveSyscall
+	KernelArchitecture = kernelArchitecture
 )
 
 func MockArchDpkgArchitecture(f func() string) (restore func()) {
@@ -220,6 +220,7 @@ import (
 	"syscall"
 
 	seccomp "github.com/seccomp/libseccomp-golang"
+	"golang.org/x/sys/unix"
 
 	"github.com/snapcore/snapd/arch"
 	"github.com/snapcore/snapd/osutil"
@@ -616,6 +617,37 @@ var (
 	errnoOnImplicitDenial int16 = C.EPERM
 )
 
+// seccompErrnoResolver maps the errno names that can be attached to explicit
+// denials, eg. "~socket AF_INET (ENOSYS)", to their values.
+var seccompErrnoResolver = map[string]int16{
+	"EPERM":           C.EPERM,
+	"ENOENT":          C.ENOENT,
+	"EIO":             C.EIO,
+	"ENXIO":           C.ENXIO,
+	"EBADF":           C.EBADF,
+	"EAGAIN":          C.EAGAIN,
+	"ENOMEM":          C.ENOMEM,
+	"EACCES":          C.EACCES,
+	"EFAULT":          C.EFAULT,
+	"ENODEV":          C.ENODEV,
+	"EINVAL":          C.EINVAL,
+	"ENOTTY":          C.ENOTTY,
+	"ENOSYS":          C.ENOSYS,
+	"EOPNOTSUPP":      C.EOPNOTSUPP,
+	"EPROTONOSUPPORT": C.EPROTONOSUPPORT,
+	"EAFNOSUPPORT":    C.EAFNOSUPPORT,
+}
+
+// readErrno parses a "(ERRNO)" token.
+func readErrno(token string) (int16, error) {
+	name := strings.TrimSuffix(strings.TrimPrefix(token, "("), ")")
+	errno, ok := seccompErrnoResolver[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown errno %q", name)
+	}
+	return errno, nil
+}
+
 func parseLine(line string, secFilterAllow, secFilterDeny *seccomp.ScmpFilter) error {
 	// ignore comments and empty lines
 	if strings.HasPrefix(line, "#") || line == "" {
@@ -625,6 +657,21 @@ func parseLine(line string, secFilterAllow, secFilterDeny *seccomp.ScmpFilter) e
 
 	// regular line
 	tokens := strings.Fields(line)
+
+	// explicit denials may end with the errno to return, eg.
+	// "~socket AF_INET (ENOSYS)"
+	errno := errnoOnExplicitDenial
+	hasErrno := false
+	if last := tokens[len(tokens)-1]; len(tokens) > 1 && strings.HasPrefix(last, "(") && strings.HasSuffix(last, ")") {
+		var err error
+		errno, err = readErrno(last)
+		if err != nil {
+			return fmt.Errorf("cannot parse token %q (line %q): %v", last, line, err)
+		}
+		hasErrno = true
+		tokens = tokens[:len(tokens)-1]
+	}
+
 	if len(tokens[1:]) > ScArgsMaxlength {
 		return fmt.Errorf("too many arguments specified for syscall '%s' in line %q", tokens[0], line)
 	}
@@ -636,9 +683,11 @@ func parseLine(line string, secFilterAllow, secFilterDeny *seccomp.ScmpFilter) e
 	// fish out syscall
 	syscallName := tokens[0]
 	if strings.HasPrefix(syscallName, "~") {
-		action = seccomp.ActErrno.SetReturnCode(errnoOnExplicitDenial)
+		action = seccomp.ActErrno.SetReturnCode(errno)
 		syscallName = syscallName[1:]
 		secFilter = secFilterDeny
+	} else if hasErrno {
+		return fmt.Errorf("cannot use errno with allowed syscall '%s' in line %q", syscallName, line)
 	}
 
 	secSyscall, err := seccomp.GetSyscallFromName(syscallName)
@@ -745,9 +794,20 @@ var (
 
 var (
 	dpkgArchitecture       = archDpkgArchitecture()
-	dpkgKernelArchitecture = archDpkgKernelArchitecture()
+	dpkgKernelArchitecture = kernelArchitecture()
 )
 
+// kernelArchitecture returns the dpkg architecture of the kernel the
+// filters are compiled for. When preseeding an image of another
+// architecture under emulation that is the architecture of the target
+// system, as given by snap-preseed, and not the one of the host kernel.
+func kernelArchitecture() string {
+	if targetArch := os.Getenv("SNAPD_PRESEED_TARGET_ARCH"); targetArch != "" {
+		return targetArch
+	}
+	return archDpkgKernelArchitecture()
+}
+
 // For architectures that support a compat architecture, when the
 // kernel and userspace match, add the compat arch, otherwise add
 // the kernel arch to support the kernel's arch (eg, 64bit kernels with
@@ -780,7 +840,7 @@ func addSecondaryArches(secFilter *seccomp.ScmpFilter) error {
 		// snaps. While unusual from a traditional Linux distribution
 		// perspective, certain classes of embedded devices are known
 		// to use this configuration.
-		compatArch = DpkgArchToScmpArch(archDpkgKernelArchitecture())
+		compatArch = DpkgArchToScmpArch(kernelArchitecture())
 	}
 
 	if compatArch != seccomp.ArchInvalid {
@@ -1056,6 +1116,52 @@ func showSeccompLibraryVersion() error {
 	return nil
 }
 
+// auditArchToScmpArch takes an AUDIT_ARCH_* value, as found in the audit
+// records of seccomp denials, and converts it to the seccomp.ScmpArch as used
+// in the libseccomp-golang library
+func auditArchToScmpArch(auditArch uint32) seccomp.ScmpArch {
+	switch auditArch {
+	case unix.AUDIT_ARCH_X86_64:
+		return seccomp.ArchAMD64
+	case unix.AUDIT_ARCH_AARCH64:
+		return seccomp.ArchARM64
+	case unix.AUDIT_ARCH_ARM:
+		return seccomp.ArchARM
+	case unix.AUDIT_ARCH_I386:
+		return seccomp.ArchX86
+	case unix.AUDIT_ARCH_PPC:
+		return seccomp.ArchPPC
+	case unix.AUDIT_ARCH_PPC64:
+		return seccomp.ArchPPC64
+	case unix.AUDIT_ARCH_PPC64LE:
+		return seccomp.ArchPPC64LE
+	case unix.AUDIT_ARCH_S390X:
+		return seccomp.ArchS390X
+	case unix.AUDIT_ARCH_RISCV64:
+		return seccomp.ArchRISCV64
+	}
+	return seccomp.ArchInvalid
+}
+
+// resolveSyscall returns the name of the system call with the given number
+// on the given architecture, both as found in the audit records of seccomp
+// denials, e.g. "c000003e" and "165".
+func resolveSyscall(auditArch, nr string) (string, error) {
+	a, err := strconv.ParseUint(auditArch, 16, 32)
+	if err != nil {
+		return "", fmt.Errorf("cannot parse architecture %q: %v", auditArch, err)
+	}
+	scmpArch := auditArchToScmpArch(uint32(a))
+	if scmpArch == seccomp.ArchInvalid {
+		return "", fmt.Errorf("unsupported architecture %q", auditArch)
+	}
+	n, err := strconv.ParseInt(nr, 10, 32)
+	if err != nil {
+		return "", fmt.Errorf("cannot parse system call number %q: %v", nr, err)
+	}
+	return seccomp.ScmpSyscall(n).GetNameByArch(scmpArch)
+}
+
 func dump(what, prefix string) error {
 	f, err := os.Open(what)
 	if err != nil {
@@ -1129,6 +1235,16 @@ func main() {
 		what := os.Args[2]
 		prefix := os.Args[3]
 		err = dump(what, prefix)
+	case "resolve-syscall":
+		if len(os.Args) < 4 {
+			fmt.Println("resolve-syscall needs <audit-arch> <number>")
+			os.Exit(1)
+		}
+		var name string
+		name, err = resolveSyscall(os.Args[2], os.Args[3])
+		if err == nil {
+			fmt.Fprintln(os.Stdout, name)
+		}
 	default:
 		err = fmt.Errorf("unsupported argument %q", cmd)
 	}
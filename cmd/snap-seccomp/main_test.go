@@ -52,6 +52,7 @@ var _ = Suite(&snapSeccompSuite{})
 const (
 	Deny = iota
 	DenyExplicit
+	DenyExplicitENOSYS
 	Allow
 )
 
@@ -189,6 +190,10 @@ int main(int argc, char** argv)
     if (syscall_ret < 0 && errno == 999) {
         ret = 20;
     }
+    // ENOSYS is used as the custom errno of explicit denials
+    if (syscall_ret < 0 && errno == ENOSYS) {
+        ret = 30;
+    }
     syscall(SYS_exit, ret, 0, 0, 0, 0, 0);
     return 0;
 }
@@ -379,7 +384,7 @@ clock_gettime64
 	// else is unexpected (segv, strtoll failure, ...)
 	exitCode, e := osutil.ExitCode(err)
 	c.Assert(e, IsNil)
-	c.Assert(exitCode == 0 || exitCode == 10 || exitCode == 20, Equals, true, Commentf("unexpected exit code: %v for %v - test setup broken", exitCode, seccompAllowlist))
+	c.Assert(exitCode == 0 || exitCode == 10 || exitCode == 20 || exitCode == 30, Equals, true, Commentf("unexpected exit code: %v for %v - test setup broken", exitCode, seccompAllowlist))
 	switch expected {
 	case Allow:
 		if err != nil {
@@ -399,6 +404,13 @@ clock_gettime64
 		if err == nil {
 			c.Fatalf("unexpected success for %q %q (ran but should have failed)", seccompAllowlist, bpfInput)
 		}
+	case DenyExplicitENOSYS:
+		if exitCode != 30 {
+			c.Fatalf("unexpected exit code for %q %q (%v != %v)", seccompAllowlist, bpfInput, exitCode, 30)
+		}
+		if err == nil {
+			c.Fatalf("unexpected success for %q %q (ran but should have failed)", seccompAllowlist, bpfInput)
+		}
 	default:
 		c.Fatalf("unknown expected result %v", expected)
 	}
@@ -535,6 +547,12 @@ func (s *snapSeccompSuite) TestCompile(c *C) {
 		{"setpriority PRIO_PROCESS 0 >=5\n~setpriority PRIO_PROCESS 0 >=10", "setpriority;native;PRIO_PROCESS,0,2", Deny},
 		{"setpriority PRIO_PROCESS 0 >=5\n~setpriority PRIO_PROCESS 0 >=10", "setpriority;native;PRIO_PROCESS,0,5", Allow},
 		{"setpriority PRIO_PROCESS 0 >=5\n~setpriority PRIO_PROCESS 0 >=10", "setpriority;native;PRIO_PROCESS,0,10", DenyExplicit},
+		// explicit denial with a custom errno
+		{"setpriority\n~setpriority PRIO_PROCESS 0 >=10 (ENOSYS)", "setpriority;native;PRIO_PROCESS,0,10", DenyExplicitENOSYS},
+		{"setpriority\n~setpriority PRIO_PROCESS 0 >=10 (ENOSYS)", "setpriority;native;PRIO_PROCESS,0,5", Allow},
+		{"setpriority\n~setpriority PRIO_PROCESS 0 >=10 (ENOSYS)\n~setpriority PRIO_PROCESS 0 <=2", "setpriority;native;PRIO_PROCESS,0,2", DenyExplicit},
+		{"setpriority\n~setpriority PRIO_PROCESS 0 >=10 (ENOSYS)\n~setpriority PRIO_PROCESS 0 <=2", "setpriority;native;PRIO_PROCESS,0,10", DenyExplicitENOSYS},
+		{"ioctl\n~ioctl - TIOCSTI (ENOSYS)", "ioctl;native;-,TIOCSTI", DenyExplicitENOSYS},
 
 		// test_bad_seccomp_filter_args_quotactl
 		{"quotactl Q_GETQUOTA", "quotactl;native;Q_GETQUOTA", Allow},
@@ -631,6 +649,12 @@ func (s *snapSeccompSuite) TestCompileBadInput(c *C) {
 		{"setpriority 999999999999999999999999999999999999999999999999999999999999999999999999999999999999999999", `cannot parse line: cannot parse token "999999999999999999999999999999999999999999999999999999999999999999999999999999999999999999" .*`},
 		{"mbind - - - - - - 7", `cannot parse line: too many arguments specified for syscall 'mbind' in line.*`},
 		{"mbind 1 2 3 4 5 6 7", `cannot parse line: too many arguments specified for syscall 'mbind' in line.*`},
+		{"~mbind 1 2 3 4 5 6 7 (ENOSYS)", `cannot parse line: too many arguments specified for syscall '~mbind' in line.*`},
+		// errno on explicit denials
+		{"~socket AF_INET (ENOSYSS)", `cannot parse line: cannot parse token "\(ENOSYSS\)" \(line "~socket AF_INET \(ENOSYSS\)"\): unknown errno "ENOSYSS"`},
+		{"~socket AF_INET (38)", `cannot parse line: cannot parse token "\(38\)" .*: unknown errno "38"`},
+		{"~socket AF_INET ()", `cannot parse line: cannot parse token "\(\)" .*: unknown errno ""`},
+		{"socket AF_INET (ENOSYS)", `cannot parse line: cannot use errno with allowed syscall 'socket' in line "socket AF_INET \(ENOSYS\)"`},
 		// test_bad_seccomp_filter_args_prctl
 		{"prctl PR_GET_SECCOM", `cannot parse line: cannot parse token "PR_GET_SECCOM" .*`},
 		{"prctl PR_GET_SECCOMPP", `cannot parse line: cannot parse token "PR_GET_SECCOMPP" .*`},
@@ -934,6 +958,18 @@ func (s *snapSeccompSuite) TestCompatArchWorks(c *C) {
 	}
 }
 
+func (s *snapSeccompSuite) TestKernelArchitecturePreseedTarget(c *C) {
+	restore := main.MockArchDpkgKernelArchitecture(func() string { return "amd64" })
+	defer restore()
+
+	os.Unsetenv("SNAPD_PRESEED_TARGET_ARCH")
+	c.Check(main.KernelArchitecture(), Equals, "amd64")
+
+	os.Setenv("SNAPD_PRESEED_TARGET_ARCH", "arm64")
+	defer os.Unsetenv("SNAPD_PRESEED_TARGET_ARCH")
+	c.Check(main.KernelArchitecture(), Equals, "arm64")
+}
+
 func (s *snapSeccompSuite) TestExportBpfErrors(c *C) {
 	fout, err := os.Create(filepath.Join(c.MkDir(), "filter"))
 	c.Assert(err, IsNil)
@@ -966,3 +1002,24 @@ uname
 	c.Assert(err, IsNil)
 	c.Check(fi.Size() > 10, Equals, true)
 }
+
+func (s *snapSeccompSuite) TestResolveSyscall(c *C) {
+	for _, t := range []struct {
+		arch, nr, name string
+	}{
+		{"c000003e", "165", "mount"},
+		{"c00000b7", "40", "mount"},
+		{"40000003", "21", "mount"},
+	} {
+		name, err := main.ResolveSyscall(t.arch, t.nr)
+		c.Check(err, IsNil)
+		c.Check(name, Equals, t.name, Commentf("%s/%s", t.arch, t.nr))
+	}
+
+	_, err := main.ResolveSyscall("zzz", "165")
+	c.Check(err, ErrorMatches, `cannot parse architecture "zzz": .*`)
+	_, err = main.ResolveSyscall("1234", "165")
+	c.Check(err, ErrorMatches, `unsupported architecture "1234"`)
+	_, err = main.ResolveSyscall("c000003e", "mount")
+	c.Check(err, ErrorMatches, `cannot parse system call number "mount": .*`)
+}
@@ -134,7 +134,7 @@ func (c *Change) createPath(path string, pokeHoles bool, as *Assumptions) ([]*Ch
 		// If the error can be recovered by using a writable mimic
 		// then construct one and try again.
 		logger.Debugf("need to create writable mimic in order to create path %q (mount entry id: %q) (original error: %v)", path, c.Entry.XSnapdEntryID(), err)
-		changes, err = createWritableMimic(mimicPath, c.Entry.XSnapdEntryID(), as)
+		changes, err = createMimic(mimicPath, &c.Entry, as)
 		if err != nil {
 			err = fmt.Errorf("cannot create writable mimic over %q: %s", mimicPath, err)
 		} else {
@@ -545,7 +545,8 @@ func (c *Change) DoPerform(as *Assumptions) error {
 // layout in /dir/sd2/sd3. So /dir/sd2 is used twice with different
 // filesystem types (none and tmpfs). As we make sure that mimics are
 // created only once per directory, we should only have one entry per
-// dir+fstype, being fstype either none or tmpfs.
+// dir+fstype, being fstype either none, tmpfs or, for mimics using
+// overlayfs, overlay.
 //
 // TODO Ideally we should have only one mount per mountpoint, but we
 // perform mounts as we create the changes in Change.DoPerform which
@@ -707,7 +708,7 @@ func neededChanges(currentProfile, desiredProfile *osutil.MountProfile) []*Chang
 			entry.Options = append([]string(nil), entry.Options...)
 			// If the mount entry can potentially host nested mount points then detach
 			// rather than unmount, since detach will always succeed.
-			shouldDetach := entry.Type == "tmpfs" || entry.OptBool("bind") || entry.OptBool("rbind")
+			shouldDetach := entry.Type == "tmpfs" || entry.Type == "overlay" || entry.OptBool("bind") || entry.OptBool("rbind")
 			if shouldDetach && !entry.XSnapdDetach() {
 				entry.Options = append(entry.Options, osutil.XSnapdDetach())
 			}
@@ -23,6 +23,7 @@ import (
 	"io/fs"
 	"os"
 	"syscall"
+	"time"
 
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/osutil/sys"
@@ -37,6 +38,9 @@ var (
 	// utils
 	PlanWritableMimic = planWritableMimic
 	ExecWritableMimic = execWritableMimic
+	PlanOverlayMimic  = planOverlayMimic
+	ExecOverlayMimic  = execOverlayMimic
+	CreateMimic       = createMimic
 
 	// bootstrap
 	ClearBootstrapError = clearBootstrapError
@@ -62,6 +66,10 @@ var (
 
 	// update
 	ExecuteMountProfileUpdate = executeMountProfileUpdate
+
+	// mountlog
+	MountLogPath       = mountLogPath
+	MaxMountLogRecords = maxMountLogRecords
 )
 
 // SystemCalls encapsulates various system interactions performed by this module.
@@ -196,6 +204,26 @@ func MockReadDir(fn func(string) ([]fs.DirEntry, error)) (restore func()) {
 	}
 }
 
+func MockReadFile(fn func(string) ([]byte, error)) (restore func()) {
+	old := osReadFile
+	osReadFile = fn
+	return func() {
+		osReadFile = old
+	}
+}
+
+func MockOverlayMimicSupported(supported bool) (restore func()) {
+	old := overlayMimicSupported
+	overlayMimicSupported = func() bool { return supported }
+	return func() {
+		overlayMimicSupported = old
+	}
+}
+
+func OverlayMimicSupported() bool {
+	return overlayMimicSupported()
+}
+
 // MockSnapConfineUserEnv provide the environment variables provided by snap-confine
 // when it calls snap-update-ns for a specific user
 func MockSnapConfineUserEnv(xdgNew, realHomeNew string) (restore func()) {
@@ -306,3 +334,9 @@ func MockSaveMountProfile(f func(p *osutil.MountProfile, fname string, uid sys.U
 	osutilSaveMountProfile = f
 	return r
 }
+
+func MockTimeNow(f func() time.Time) (restore func()) {
+	r := testutil.Backup(&timeNow)
+	timeNow = f
+	return r
+}
@@ -0,0 +1,136 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/osutil"
+)
+
+// maxMountLogRecords is the number of records kept in the mount log of a
+// snap, older records are dropped.
+const maxMountLogRecords = 100
+
+var timeNow = time.Now
+
+// MountLogChange describes a change performed, or attempted, by
+// snap-update-ns.
+type MountLogChange struct {
+	Action string `json:"action"`
+	Entry  string `json:"entry"`
+	Error  string `json:"error,omitempty"`
+}
+
+// MountLogRecord describes what a single invocation of snap-update-ns did
+// to the mount namespace of a snap. Records are stored as JSON lines.
+type MountLogRecord struct {
+	Time            time.Time        `json:"time"`
+	Duration        string           `json:"duration"`
+	FromSnapConfine bool             `json:"from-snap-confine,omitempty"`
+	Changes         []MountLogChange `json:"changes,omitempty"`
+	Error           string           `json:"error,omitempty"`
+}
+
+func newMountLogRecord() *MountLogRecord {
+	return &MountLogRecord{Time: timeNow()}
+}
+
+// addPerformed records the given changes as performed. Keep changes leave
+// the mount namespace alone and are not recorded.
+func (r *MountLogRecord) addPerformed(changes []*Change) {
+	for _, change := range changes {
+		if change.Action == Keep {
+			continue
+		}
+		r.Changes = append(r.Changes, MountLogChange{
+			Action: string(change.Action),
+			Entry:  change.Entry.String(),
+		})
+	}
+}
+
+func (r *MountLogRecord) addFailed(change *Change, err error) {
+	r.Changes = append(r.Changes, MountLogChange{
+		Action: string(change.Action),
+		Entry:  change.Entry.String(),
+		Error:  err.Error(),
+	})
+}
+
+func (r *MountLogRecord) finish(err error) {
+	r.Duration = timeNow().Sub(r.Time).String()
+	if err != nil {
+		r.Error = err.Error()
+	}
+}
+
+// mountLogPath returns the path of the log of the changes made to the
+// mount namespace of a snap.
+func mountLogPath(snapName string) string {
+	return filepath.Join(dirs.SnapRunNsDir, snapName+".log")
+}
+
+// appendMountLog appends the record to the given mount log, dropping the
+// oldest records when there are too many. The log is replaced atomically
+// so that readers never see a partially written record.
+func appendMountLog(path string, record *MountLogRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	var lines [][]byte
+	if len(data) > 0 {
+		lines = bytes.Split(bytes.TrimSuffix(data, []byte("\n")), []byte("\n"))
+	}
+	lines = append(lines, line)
+	if len(lines) > maxMountLogRecords {
+		lines = lines[len(lines)-maxMountLogRecords:]
+	}
+	var buf bytes.Buffer
+	for _, l := range lines {
+		buf.Write(l)
+		buf.WriteByte('\n')
+	}
+	return osutil.AtomicWriteFile(path, buf.Bytes(), 0644, 0)
+}
+
+// AppendMountLog appends the record to the mount log of the snap, readable
+// with "snap debug mount-log".
+func (upCtx *SystemProfileUpdateContext) AppendMountLog(record *MountLogRecord) error {
+	record.FromSnapConfine = upCtx.fromSnapConfine
+	if err := appendMountLog(mountLogPath(upCtx.instanceName), record); err != nil {
+		return fmt.Errorf("cannot save mount log of snap %q: %v", upCtx.instanceName, err)
+	}
+	return nil
+}
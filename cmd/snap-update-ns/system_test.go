@@ -24,6 +24,8 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	. "gopkg.in/check.v1"
 
@@ -32,6 +34,7 @@ import (
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/osutil/sys"
 	"github.com/snapcore/snapd/sandbox/cgroup"
+	"github.com/snapcore/snapd/testutil"
 )
 
 type systemSuite struct{}
@@ -170,3 +173,57 @@ func (s *systemSuite) TestDesiredSystemProfilePath(c *C) {
 func (s *systemSuite) TestCurrentSystemProfilePath(c *C) {
 	c.Check(update.CurrentSystemProfilePath("foo"), Equals, "/run/snapd/ns/snap.foo.fstab")
 }
+
+func (s *systemSuite) TestMountLogPath(c *C) {
+	c.Check(update.MountLogPath("foo"), Equals, "/run/snapd/ns/foo.log")
+}
+
+func (s *systemSuite) TestAppendMountLog(c *C) {
+	dirs.SetRootDir(c.MkDir())
+	defer dirs.SetRootDir("/")
+	c.Assert(os.MkdirAll(dirs.SnapRunNsDir, 0755), IsNil)
+
+	upCtx := update.NewSystemProfileUpdateContext("foo", true)
+	record := &update.MountLogRecord{
+		Time:     time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC),
+		Duration: "2ms",
+		Changes: []update.MountLogChange{
+			{Action: "mount", Entry: "tmpfs /usr/share/foo tmpfs x-snapd.synthetic 0 0"},
+		},
+	}
+	c.Assert(upCtx.AppendMountLog(record), IsNil)
+	c.Assert(upCtx.AppendMountLog(record), IsNil)
+
+	line := `{"time":"2026-10-16T12:00:00Z","duration":"2ms","from-snap-confine":true,"changes":[{"action":"mount","entry":"tmpfs /usr/share/foo tmpfs x-snapd.synthetic 0 0"}]}` + "\n"
+	c.Check(filepath.Join(dirs.SnapRunNsDir, "foo.log"), testutil.FileEquals, line+line)
+}
+
+func (s *systemSuite) TestAppendMountLogDropsOldRecords(c *C) {
+	dirs.SetRootDir(c.MkDir())
+	defer dirs.SetRootDir("/")
+	c.Assert(os.MkdirAll(dirs.SnapRunNsDir, 0755), IsNil)
+
+	upCtx := update.NewSystemProfileUpdateContext("foo", false)
+	start := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < update.MaxMountLogRecords+5; i++ {
+		record := &update.MountLogRecord{Time: start.Add(time.Duration(i) * time.Second), Duration: "1ms"}
+		c.Assert(upCtx.AppendMountLog(record), IsNil)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dirs.SnapRunNsDir, "foo.log"))
+	c.Assert(err, IsNil)
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	c.Assert(lines, HasLen, update.MaxMountLogRecords)
+	c.Check(lines[0], Equals, `{"time":"2026-10-16T12:00:05Z","duration":"1ms"}`)
+	c.Check(lines[len(lines)-1], Equals, `{"time":"2026-10-16T12:01:44Z","duration":"1ms"}`)
+}
+
+func (s *systemSuite) TestAppendMountLogError(c *C) {
+	dirs.SetRootDir(c.MkDir())
+	defer dirs.SetRootDir("/")
+
+	// the directory of the mount logs does not exist
+	upCtx := update.NewSystemProfileUpdateContext("foo", false)
+	err := upCtx.AppendMountLog(&update.MountLogRecord{})
+	c.Check(err, ErrorMatches, `cannot save mount log of snap "foo": open .*/run/snapd/ns/foo.log.*: no such file or directory`)
+}
@@ -41,15 +41,34 @@ type MountProfileUpdateContext interface {
 	LoadCurrentProfile() (*osutil.MountProfile, error)
 	// SaveCurrentProfile saves the mount profile that is currently applied.
 	SaveCurrentProfile(*osutil.MountProfile) error
+	// AppendMountLog records what the update did in the mount log.
+	AppendMountLog(*MountLogRecord) error
 }
 
-func executeMountProfileUpdate(upCtx MountProfileUpdateContext) error {
+func executeMountProfileUpdate(upCtx MountProfileUpdateContext) (err error) {
 	unlock, err := upCtx.Lock()
 	if err != nil {
 		return err
 	}
 	defer unlock()
 
+	// Log what was done, while still holding the lock, so that the
+	// records of concurrent invocations are not lost.
+	log := newMountLogRecord()
+	var changesNeeded []*Change
+	var changeErr []error
+	defer func() {
+		for i, change := range changesNeeded {
+			if changeErr[i] != nil {
+				log.addFailed(change, changeErr[i])
+			}
+		}
+		log.finish(err)
+		if logErr := upCtx.AppendMountLog(log); logErr != nil {
+			logger.Noticef("%v", logErr)
+		}
+	}()
+
 	desired, err := upCtx.LoadDesiredProfile()
 	if err != nil {
 		return err
@@ -69,13 +88,13 @@ func executeMountProfileUpdate(upCtx MountProfileUpdateContext) error {
 	// Compute the needed changes and perform each change if
 	// needed, collecting those that we managed to perform or that
 	// were performed already.
-	changesNeeded := NeededChanges(currentBefore, desired)
+	changesNeeded = NeededChanges(currentBefore, desired)
 	// TODO: NeededChanges could return changes grouped by origin (overname,
 	// non-layout, layout) instead of a flat list, removing the need to
 	// filter by origin in each pass.
 
 	var changesMade []*Change
-	changeErr := make([]error, len(changesNeeded))
+	changeErr = make([]error, len(changesNeeded))
 
 	var errContinue = errors.New("continue")
 
@@ -90,6 +109,7 @@ func executeMountProfileUpdate(upCtx MountProfileUpdateContext) error {
 
 			logger.Debugf("apply: %v", change)
 			actualChangesMade, err := f(i, change)
+			log.addPerformed(actualChangesMade)
 			if err != nil {
 				if err == errContinue {
 					continue
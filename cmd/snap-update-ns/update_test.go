@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"syscall"
+	"time"
 
 	. "gopkg.in/check.v1"
 
@@ -280,6 +281,63 @@ func (s *updateSuite) TestCannotPerformLayoutChange(c *C) {
 	c.Check(saved, IsNil)
 }
 
+func (s *updateSuite) TestMountLog(c *C) {
+	// The performed and failed changes are recorded in the mount log,
+	// along with the error of the update.
+	var logged *update.MountLogRecord
+	upCtx := &testProfileUpdateContext{
+		neededChanges: func(old, new *osutil.MountProfile) []*update.Change {
+			return []*update.Change{
+				{Action: update.Keep, Entry: osutil.MountEntry{Dir: "/keep"}},
+				{Action: update.Unmount, Entry: osutil.MountEntry{Dir: "/unmount"}},
+				{Action: update.Mount, Entry: osutil.MountEntry{Dir: "/dir-1"}},
+				{Action: update.Mount, Entry: osutil.MountEntry{Dir: "/dir-2", Options: []string{"x-snapd.origin=layout"}}},
+			}
+		},
+		prepareToPerformChange: func(change *update.Change, as *update.Assumptions) ([]*update.Change, error) {
+			// The change to /dir-2 cannot be made.
+			if change.Action == update.Mount && change.Entry.Dir == "/dir-2" {
+				return nil, errTesting
+			}
+			return nil, nil
+		},
+		appendMountLog: func(record *update.MountLogRecord) error {
+			logged = record
+			return nil
+		},
+	}
+	restore := upCtx.MockRelatedFunctions()
+	defer restore()
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	restore = update.MockTimeNow(func() time.Time {
+		now = now.Add(time.Millisecond)
+		return now
+	})
+	defer restore()
+
+	err := update.ExecuteMountProfileUpdate(upCtx)
+	c.Check(err, Equals, errTesting)
+	c.Check(logged, DeepEquals, &update.MountLogRecord{
+		Time:     time.Date(2026, 10, 16, 12, 0, 0, int(time.Millisecond), time.UTC),
+		Duration: "1ms",
+		Changes: []update.MountLogChange{
+			{Action: "unmount", Entry: "none /unmount none defaults 0 0"},
+			{Action: "mount", Entry: "none /dir-1 none defaults 0 0"},
+			{Action: "mount", Entry: "none /dir-2 none x-snapd.origin=layout 0 0", Error: "testing"},
+		},
+		Error: "testing",
+	})
+}
+
+func (s *updateSuite) TestMountLogFailureIsNotFatal(c *C) {
+	upCtx := &testProfileUpdateContext{
+		appendMountLog: func(record *update.MountLogRecord) error {
+			return errTesting
+		},
+	}
+	c.Assert(update.ExecuteMountProfileUpdate(upCtx), IsNil)
+}
+
 func (s *updateSuite) TestCannotPerformOvermountChange(c *C) {
 	// When performing a mount change for an "overname", errors are immediately fatal.
 	var saved *osutil.MountProfile
@@ -456,6 +514,7 @@ type testProfileUpdateContext struct {
 	loadCurrentProfile func() (*osutil.MountProfile, error)
 	loadDesiredProfile func() (*osutil.MountProfile, error)
 	saveCurrentProfile func(*osutil.MountProfile) error
+	appendMountLog     func(*update.MountLogRecord) error
 	assumptions        func() *update.Assumptions
 
 	// The remaining functions are defined for consistency but are installed by
@@ -520,3 +579,10 @@ func (upCtx *testProfileUpdateContext) SaveCurrentProfile(profile *osutil.MountP
 	}
 	return nil
 }
+
+func (upCtx *testProfileUpdateContext) AppendMountLog(record *update.MountLogRecord) error {
+	if upCtx.appendMountLog != nil {
+		return upCtx.appendMountLog(record)
+	}
+	return nil
+}
@@ -149,6 +149,14 @@ func (upCtx *UserProfileUpdateContext) LoadCurrentProfile() (*osutil.MountProfil
 	return &osutil.MountProfile{}, nil
 }
 
+// AppendMountLog does nothing at all.
+//
+// Per-user updates run with the privileges of the user, who cannot write to
+// the directory of mount logs.
+func (upCtx *UserProfileUpdateContext) AppendMountLog(record *MountLogRecord) error {
+	return nil
+}
+
 // desiredUserProfilePath returns the path of the fstab-like file with the desired, user-specific mount profile for a snap.
 func desiredUserProfilePath(snapName string) string {
 	return fmt.Sprintf("%s/snap.%s.user-fstab", dirs.SnapMountPolicyDir, snapName)
@@ -68,7 +68,8 @@ var (
 	sysGetuid = sys.Getuid
 	sysGetgid = sys.Getgid
 
-	osReadDir = os.ReadDir
+	osReadDir  = os.ReadDir
+	osReadFile = os.ReadFile
 )
 
 // ReadOnlyFsError is an error encapsulating encountered EROFS.
@@ -785,3 +786,179 @@ func createWritableMimic(dir, neededBy string, as *Assumptions) ([]*Change, erro
 	}
 	return changes, nil
 }
+
+// overlayMimicSupported returns whether writable mimics can be constructed
+// with overlayfs. This needs a kernel with overlayfs that, unless we are
+// privileged, allows mounting it in a user namespace, which is the case
+// since Linux 5.11.
+var overlayMimicSupported = func() bool {
+	filesystems, err := osReadFile("/proc/filesystems")
+	if err != nil {
+		logger.Debugf("cannot read supported filesystems: %v", err)
+		return false
+	}
+	found := false
+	for _, line := range strings.Split(string(filesystems), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 0 && fields[len(fields)-1] == "overlay" {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return false
+	}
+	if sysGetuid() == 0 {
+		return true
+	}
+	cmp, err := strutil.VersionCompare(osutil.KernelVersion(), "5.11")
+	return err == nil && cmp >= 0
+}
+
+// planOverlayMimic plans how to make a given directory writable with overlayfs.
+//
+// Unlike the tmpfs based writable mimic, nothing from the original directory
+// is copied: the original directory is the lower layer of an overlay mounted
+// over itself, so that changes made to it later on remain visible. The upper
+// and work directories of the overlay are kept in a tmpfs mounted in the
+// auxiliary directory, which is detached once the overlay is mounted, the
+// overlay keeps it alive.
+func planOverlayMimic(dir, neededBy string) ([]*Change, error) {
+	logger.Debugf("create-overlay-mimic %q", dir)
+	// Those are separators in the options of overlayfs.
+	if strings.ContainsAny(dir, ",:\\") {
+		return nil, fmt.Errorf("cannot use %q as overlay layer", dir)
+	}
+	auxDir := filepath.Join("/tmp/.snap/", dir)
+
+	// Stat the original directory to know which mode and ownership to
+	// replicate on the upper layer, which is the root of the overlay.
+	var sb syscall.Stat_t
+	if err := sysLstat(dir, &sb); err != nil {
+		return nil, err
+	}
+
+	changes := []*Change{
+		// Mount tmpfs for the upper and work directories.
+		{Action: Mount, Entry: osutil.MountEntry{
+			Name: "tmpfs", Dir: auxDir, Type: "tmpfs",
+			Options: []string{
+				fmt.Sprintf("mode=%#o", sb.Mode&07777),
+				fmt.Sprintf("uid=%d", sb.Uid),
+				fmt.Sprintf("gid=%d", sb.Gid),
+			},
+		}},
+		// Mount the overlay over the original directory.
+		{Action: Mount, Entry: osutil.MountEntry{
+			Name: "overlay", Dir: dir, Type: "overlay",
+			Options: []string{
+				fmt.Sprintf("lowerdir=%s", dir),
+				fmt.Sprintf("upperdir=%s", filepath.Join(auxDir, "upper")),
+				fmt.Sprintf("workdir=%s", filepath.Join(auxDir, "work")),
+				osutil.XSnapdSynthetic(),
+				osutil.XSnapdNeededBy(neededBy),
+			},
+		}},
+		// Detach the tmpfs, it is only referenced by the overlay now.
+		{Action: Unmount, Entry: osutil.MountEntry{Name: "none", Dir: auxDir, Options: []string{osutil.XSnapdDetach()}}},
+	}
+	return changes, nil
+}
+
+// execOverlayMimic executes the plan for an overlay based writable mimic.
+//
+// The plan must be the one created by planOverlayMimic. The upper and work
+// directories are created once the tmpfs holding them is mounted. The
+// returned undo plan only contains the overlay, as the tmpfs is detached.
+//
+// In the event of a failure the changes performed so far are undone and an
+// error is returned. If that fails the function returns a FatalError.
+func execOverlayMimic(plan []*Change, as *Assumptions) ([]*Change, error) {
+	if len(plan) != 3 {
+		return nil, fmt.Errorf("internal error: unexpected overlay mimic plan: %v", plan)
+	}
+	auxMount, overlayMount, auxUnmount := plan[0], plan[1], plan[2]
+	auxDir := auxMount.Entry.Dir
+
+	if _, err := auxMount.Perform(as); err != nil {
+		return nil, err
+	}
+	undo := func(err error, changes ...*Change) error {
+		for _, change := range changes {
+			if _, err2 := change.Perform(as); err2 != nil {
+				return &FatalError{error: fmt.Errorf("cannot undo change %q while recovering from earlier error %v: %v", change, err, err2)}
+			}
+		}
+		return err
+	}
+
+	// The tmpfs has the mode and ownership of the original directory.
+	var sb syscall.Stat_t
+	if err := sysLstat(auxDir, &sb); err != nil {
+		return nil, undo(err, auxUnmount)
+	}
+	upperDir := filepath.Join(auxDir, "upper")
+	if err := MkdirAll(upperDir, os.FileMode(sb.Mode&07777), sys.UserID(sb.Uid), sys.GroupID(sb.Gid), as.RestrictionsFor(upperDir)); err != nil {
+		return nil, undo(err, auxUnmount)
+	}
+	workDir := filepath.Join(auxDir, "work")
+	if err := MkdirAll(workDir, 0700, 0, 0, as.RestrictionsFor(workDir)); err != nil {
+		return nil, undo(err, auxUnmount)
+	}
+
+	if _, err := overlayMount.Perform(as); err != nil {
+		return nil, undo(err, auxUnmount)
+	}
+	if _, err := auxUnmount.Perform(as); err != nil {
+		overlayUnmount := &Change{Action: Unmount, Entry: overlayMount.Entry}
+		return nil, undo(err, overlayUnmount, auxUnmount)
+	}
+	return []*Change{{Action: Mount, Entry: overlayMount.Entry}}, nil
+}
+
+func createOverlayMimic(dir, neededBy string, as *Assumptions) ([]*Change, error) {
+	plan, err := planOverlayMimic(dir, neededBy)
+	if err != nil {
+		return nil, err
+	}
+	return execOverlayMimic(plan, as)
+}
+
+// hasNestedMounts returns whether anything is mounted below the given
+// directory. If mountinfo cannot be read then nested mounts are assumed.
+func hasNestedMounts(dir string) bool {
+	entries, err := osutil.LoadMountInfo()
+	if err != nil {
+		logger.Debugf("cannot read mountinfo: %v", err)
+		return true
+	}
+	prefix := strings.TrimSuffix(dir, "/") + "/"
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.MountDir, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// createMimic creates a writable mimic over the given directory, needed by
+// the given mount entry.
+//
+// The tmpfs based writable mimic is used unless the entry asks for an
+// overlayfs based one and that is supported. Overlayfs does not cross the
+// mount points of its lower layer, so an overlay would hide whatever is
+// mounted below the directory: the tmpfs based mimic, which replicates
+// nested mounts with recursive bind mounts, is used then as well.
+func createMimic(dir string, entry *osutil.MountEntry, as *Assumptions) ([]*Change, error) {
+	if entry.XSnapdMimic() == "overlay" {
+		switch {
+		case !overlayMimicSupported():
+			logger.Debugf("overlay mimic not supported, using tmpfs mimic over %q", dir)
+		case hasNestedMounts(dir):
+			logger.Debugf("%q has nested mounts, using tmpfs mimic", dir)
+		default:
+			return createOverlayMimic(dir, entry.XSnapdEntryID(), as)
+		}
+	}
+	return createWritableMimic(dir, entry.XSnapdEntryID(), as)
+}
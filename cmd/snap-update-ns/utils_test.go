@@ -856,6 +856,193 @@ func (s *utilsSuite) TestExecWirableMimicErrorNothingDone(c *C) {
 	c.Assert(undoPlan, HasLen, 0)
 }
 
+func (s *utilsSuite) TestPlanOverlayMimic(c *C) {
+	s.sys.InsertSysLstatResult(`lstat "/foo" <ptr>`, syscall.Stat_t{Uid: 0, Gid: 0, Mode: 0755})
+
+	changes, err := update.PlanOverlayMimic("/foo", "/foo/bar")
+	c.Assert(err, IsNil)
+
+	c.Assert(changes, DeepEquals, []*update.Change{
+		// Put a tmpfs for the upper and work directories in /tmp/.snap/foo
+		{Entry: osutil.MountEntry{Name: "tmpfs", Dir: "/tmp/.snap/foo", Type: "tmpfs", Options: []string{"mode=0755", "uid=0", "gid=0"}}, Action: update.Mount},
+		// Put an overlay with /foo as lower layer over /foo
+		{Entry: osutil.MountEntry{Name: "overlay", Dir: "/foo", Type: "overlay", Options: []string{
+			"lowerdir=/foo", "upperdir=/tmp/.snap/foo/upper", "workdir=/tmp/.snap/foo/work",
+			"x-snapd.synthetic", "x-snapd.needed-by=/foo/bar"}}, Action: update.Mount},
+		// Detach the tmpfs
+		{Entry: osutil.MountEntry{Name: "none", Dir: "/tmp/.snap/foo", Options: []string{"x-snapd.detach"}}, Action: update.Unmount},
+	})
+}
+
+func (s *utilsSuite) TestPlanOverlayMimicErrors(c *C) {
+	changes, err := update.PlanOverlayMimic("/foo,bar", "/foo,bar/baz")
+	c.Assert(err, ErrorMatches, `cannot use "/foo,bar" as overlay layer`)
+	c.Assert(changes, HasLen, 0)
+
+	s.sys.InsertFault(`lstat "/foo" <ptr>`, errTesting)
+	changes, err = update.PlanOverlayMimic("/foo", "/foo/bar")
+	c.Assert(err, Equals, errTesting)
+	c.Assert(changes, HasLen, 0)
+}
+
+var overlayMimicPlan = []*update.Change{
+	{Entry: osutil.MountEntry{Name: "tmpfs", Dir: "/tmp/.snap/foo", Type: "tmpfs", Options: []string{"mode=0755", "uid=0", "gid=0"}}, Action: update.Mount},
+	{Entry: osutil.MountEntry{Name: "overlay", Dir: "/foo", Type: "overlay", Options: []string{
+		"lowerdir=/foo", "upperdir=/tmp/.snap/foo/upper", "workdir=/tmp/.snap/foo/work",
+		"x-snapd.synthetic", "x-snapd.needed-by=/foo/bar"}}, Action: update.Mount},
+	{Entry: osutil.MountEntry{Name: "none", Dir: "/tmp/.snap/foo", Options: []string{"x-snapd.detach"}}, Action: update.Unmount},
+}
+
+func (s *utilsSuite) TestExecOverlayMimicSuccess(c *C) {
+	defer s.as.MockUnrestrictedPaths("/tmp/")()
+	s.sys.InsertSysLstatResult(`lstat "/tmp/.snap/foo" <ptr>`, syscall.Stat_t{Uid: 0, Gid: 0, Mode: 0755})
+
+	var performed []*update.Change
+	restore := update.MockChangePerform(func(chg *update.Change, as *update.Assumptions) ([]*update.Change, error) {
+		performed = append(performed, chg)
+		return nil, nil
+	}, func(chg *update.Change, as *update.Assumptions) error {
+		return nil
+	})
+	defer restore()
+
+	undoPlan, err := update.ExecOverlayMimic(overlayMimicPlan, s.as)
+	c.Assert(err, IsNil)
+	c.Check(performed, DeepEquals, overlayMimicPlan)
+	// Only the overlay needs to be undone, the tmpfs is gone.
+	c.Check(undoPlan, DeepEquals, []*update.Change{overlayMimicPlan[1]})
+
+	// The upper directory replicates the original one.
+	c.Check(s.sys.Calls(), testutil.Contains, `mkdirat 6 "upper" 0755`)
+	c.Check(s.sys.Calls(), testutil.Contains, `mkdirat 6 "work" 0700`)
+}
+
+func (s *utilsSuite) TestExecOverlayMimicErrorWithRecovery(c *C) {
+	defer s.as.MockUnrestrictedPaths("/tmp/")()
+	s.sys.InsertSysLstatResult(`lstat "/tmp/.snap/foo" <ptr>`, syscall.Stat_t{Uid: 0, Gid: 0, Mode: 0755})
+
+	var performed []*update.Change
+	restore := update.MockChangePerform(func(chg *update.Change, as *update.Assumptions) ([]*update.Change, error) {
+		performed = append(performed, chg)
+		if chg.Entry.Type == "overlay" {
+			return nil, errTesting
+		}
+		return nil, nil
+	}, func(chg *update.Change, as *update.Assumptions) error {
+		return nil
+	})
+	defer restore()
+
+	undoPlan, err := update.ExecOverlayMimic(overlayMimicPlan, s.as)
+	c.Assert(err, Equals, errTesting)
+	c.Check(undoPlan, HasLen, 0)
+	// The tmpfs was detached after the overlay failed.
+	c.Check(performed, DeepEquals, overlayMimicPlan)
+}
+
+func (s *utilsSuite) TestExecOverlayMimicErrorNothingDone(c *C) {
+	restore := update.MockChangePerform(func(chg *update.Change, as *update.Assumptions) ([]*update.Change, error) {
+		return nil, errTesting
+	}, func(chg *update.Change, as *update.Assumptions) error {
+		return nil
+	})
+	defer restore()
+
+	undoPlan, err := update.ExecOverlayMimic(overlayMimicPlan, s.as)
+	c.Assert(err, Equals, errTesting)
+	c.Check(undoPlan, HasLen, 0)
+}
+
+func (s *utilsSuite) TestCreateMimicOverlay(c *C) {
+	defer s.as.MockUnrestrictedPaths("/")()
+	defer update.MockOverlayMimicSupported(true)()
+	defer osutil.MockMountInfo("132 28 0:82 / /foobar rw,relatime shared:74 - tmpfs tmpfs rw")()
+	s.sys.InsertSysLstatResult(`lstat "/foo" <ptr>`, syscall.Stat_t{Uid: 0, Gid: 0, Mode: 0755})
+	s.sys.InsertSysLstatResult(`lstat "/tmp/.snap/foo" <ptr>`, syscall.Stat_t{Uid: 0, Gid: 0, Mode: 0755})
+	restore := update.MockChangePerform(func(chg *update.Change, as *update.Assumptions) ([]*update.Change, error) {
+		return nil, nil
+	}, func(chg *update.Change, as *update.Assumptions) error {
+		return nil
+	})
+	defer restore()
+
+	entry := &osutil.MountEntry{Name: "/snap/some-snap/1/bar", Dir: "/foo/bar", Options: []string{"rbind", osutil.XSnapdMimicOverlay()}}
+	undoPlan, err := update.CreateMimic("/foo", entry, s.as)
+	c.Assert(err, IsNil)
+	c.Assert(undoPlan, HasLen, 1)
+	c.Check(undoPlan[0].Entry.Type, Equals, "overlay")
+	c.Check(undoPlan[0].Entry.Dir, Equals, "/foo")
+}
+
+func (s *utilsSuite) TestCreateMimicOverlayNestedMounts(c *C) {
+	defer s.as.MockUnrestrictedPaths("/")()
+	defer update.MockOverlayMimicSupported(true)()
+	// something is mounted below /foo, an overlay would hide it
+	defer osutil.MockMountInfo("132 28 0:82 / /foo/baz/nested rw,relatime shared:74 - tmpfs tmpfs rw")()
+	s.sys.InsertSysLstatResult(`lstat "/foo" <ptr>`, syscall.Stat_t{Uid: 0, Gid: 0, Mode: 0755})
+	s.sys.InsertReadDirResult(`readdir "/foo"`, nil)
+	restore := update.MockChangePerform(func(chg *update.Change, as *update.Assumptions) ([]*update.Change, error) {
+		return nil, nil
+	}, func(chg *update.Change, as *update.Assumptions) error {
+		return nil
+	})
+	defer restore()
+
+	entry := &osutil.MountEntry{Name: "/snap/some-snap/1/bar", Dir: "/foo/bar", Options: []string{"rbind", osutil.XSnapdMimicOverlay()}}
+	undoPlan, err := update.CreateMimic("/foo", entry, s.as)
+	c.Assert(err, IsNil)
+	// the tmpfs based mimic, replicating nested mounts, was used instead
+	c.Assert(undoPlan, HasLen, 1)
+	c.Check(undoPlan[0].Entry.Type, Equals, "tmpfs")
+	c.Check(undoPlan[0].Entry.Dir, Equals, "/foo")
+}
+
+func (s *utilsSuite) TestCreateMimicNoOverlayRequested(c *C) {
+	defer s.as.MockUnrestrictedPaths("/")()
+	defer update.MockOverlayMimicSupported(true)()
+	defer osutil.MockMountInfo("")()
+	s.sys.InsertSysLstatResult(`lstat "/foo" <ptr>`, syscall.Stat_t{Uid: 0, Gid: 0, Mode: 0755})
+	s.sys.InsertReadDirResult(`readdir "/foo"`, nil)
+	restore := update.MockChangePerform(func(chg *update.Change, as *update.Assumptions) ([]*update.Change, error) {
+		return nil, nil
+	}, func(chg *update.Change, as *update.Assumptions) error {
+		return nil
+	})
+	defer restore()
+
+	entry := &osutil.MountEntry{Name: "/snap/some-snap/1/bar", Dir: "/foo/bar", Options: []string{"rbind"}}
+	undoPlan, err := update.CreateMimic("/foo", entry, s.as)
+	c.Assert(err, IsNil)
+	c.Assert(undoPlan, HasLen, 1)
+	c.Check(undoPlan[0].Entry.Type, Equals, "tmpfs")
+}
+
+func (s *utilsSuite) TestOverlayMimicSupported(c *C) {
+	restore := update.MockGetuid(func() sys.UserID { return 0 })
+	defer restore()
+	filesystems := "nodev\ttmpfs\n\text4\nnodev\toverlay\n"
+	restore = update.MockReadFile(func(path string) ([]byte, error) {
+		c.Assert(path, Equals, "/proc/filesystems")
+		return []byte(filesystems), nil
+	})
+	defer restore()
+	c.Check(update.OverlayMimicSupported(), Equals, true)
+
+	// without privileges the kernel must allow overlays in user namespaces
+	restore = update.MockGetuid(func() sys.UserID { return 1000 })
+	defer restore()
+	restore = osutil.MockKernelVersion("5.4.0-42-generic")
+	defer restore()
+	c.Check(update.OverlayMimicSupported(), Equals, false)
+	restore = osutil.MockKernelVersion("6.8.0-31-generic")
+	defer restore()
+	c.Check(update.OverlayMimicSupported(), Equals, true)
+
+	// and overlayfs must be available
+	filesystems = "nodev\ttmpfs\n\text4\n"
+	c.Check(update.OverlayMimicSupported(), Equals, false)
+}
+
 func (s *utilsSuite) TestExecWirableMimicErrorCannotUndo(c *C) {
 	// This plan is the same as in the test above. This is what comes out of planWritableMimic.
 	plan := []*update.Change{
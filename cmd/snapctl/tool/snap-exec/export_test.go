@@ -21,6 +21,7 @@ package snap_exec
 
 import (
 	"syscall"
+	"time"
 
 	"github.com/snapcore/snapd/testutil"
 )
@@ -70,3 +71,11 @@ func MockSyscallStat(f func(string, *syscall.Stat_t) (err error)) func() {
 	syscallStat = f
 	return r
 }
+
+func MockJournalSendFields(f func(fields map[string]string) error) (restore func()) {
+	return testutil.Mock(&journalSendFields, f)
+}
+
+func MockTimeNow(f func() time.Time) (restore func()) {
+	return testutil.Mock(&timeNow, f)
+}
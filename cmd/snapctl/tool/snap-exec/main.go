@@ -174,6 +174,9 @@ func execApp(snapTarget, revision, command string, args []string) error {
 		return fmt.Errorf("snap-exec cannot run a snap component without a hook specified (use --hook)")
 	}
 
+	tracer := newStartupTracer(snapTarget)
+	tracer.begin("read-info")
+
 	rev, err := snap.ParseRevision(revision)
 	if err != nil {
 		return fmt.Errorf("cannot parse revision %q: %s", revision, err)
@@ -197,6 +200,8 @@ func execApp(snapTarget, revision, command string, args []string) error {
 		return err
 	}
 
+	tracer.begin("environment")
+
 	// build the environment from the yaml, translating TMPDIR and
 	// similar variables back from where they were hidden when
 	// invoking the setuid snap-confine.
@@ -223,6 +228,8 @@ func execApp(snapTarget, revision, command string, args []string) error {
 		env["CUPS_SERVER"] = "/var/cups/cups.sock"
 	}
 
+	tracer.begin("command-chain")
+
 	// strings.Split() is ok here because we validate all app fields and the
 	// whitelist is pretty strict (see snap/validate.go:appContentWhitelist)
 	// (see also overlord/snapstate/check_snap.go's normPath)
@@ -256,6 +263,7 @@ func execApp(snapTarget, revision, command string, args []string) error {
 	fullCmd = append(absoluteCommandChain(app.Snap.MountDir(), app.CommandChain), fullCmd...)
 
 	logger.StartupStageTimestamp("snap-exec to app")
+	tracer.exec(fullCmd[0])
 	if err := syscallExec(fullCmd[0], fullCmd, env.ForExec()); err != nil {
 		return fmt.Errorf("cannot exec %q: %s", fullCmd[0], err)
 	}
@@ -271,6 +279,9 @@ func getComponentInfo(name string, snapInfo *snap.Info) (*snap.ComponentInfo, er
 func execHook(snapTarget string, revision, hookName string) error {
 	snapName, componentName := snap.SplitSnapComponentInstanceName(snapTarget)
 
+	tracer := newStartupTracer(snapTarget)
+	tracer.begin("read-info")
+
 	rev, err := snap.ParseRevision(revision)
 	if err != nil {
 		return err
@@ -304,6 +315,8 @@ func execHook(snapTarget string, revision, hookName string) error {
 		return fmt.Errorf("cannot find hook %q in %q", hookName, snapName)
 	}
 
+	tracer.begin("environment")
+
 	// build the environment
 	// NOTE: we do not use OSEnvironmentUnescapeUnsafe, we do not
 	// particurly want to transmit snapd exec environment details
@@ -316,9 +329,12 @@ func execHook(snapTarget string, revision, hookName string) error {
 		env.ExtendWithExpanded(eenv)
 	}
 
+	tracer.begin("command-chain")
+
 	hookPath := filepath.Join(mountDir, "meta", "hooks", hookName)
 
 	// run the hook
 	cmd := append(absoluteCommandChain(mountDir, hook.CommandChain), hookPath)
+	tracer.exec(cmd[0])
 	return syscallExec(cmd[0], cmd, env.ForExec())
 }
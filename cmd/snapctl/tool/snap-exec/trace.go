@@ -0,0 +1,110 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snap_exec
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/systemd"
+)
+
+var (
+	journalSendFields = systemd.SendJournalFields
+	timeNow           = time.Now
+)
+
+// startupTracer emits a structured journal entry for each stage of the snap
+// startup performed by snap-exec, if SNAPD_TRACE is set. The entries carry
+// the trace ID passed by "snap run --trace-exec" in SNAPD_TRACE_ID, so that
+// they can be collected and rendered together with the snap-confine and
+// snap-update-ns timings.
+type startupTracer struct {
+	enabled bool
+	traceID string
+	target  string
+
+	stage      string
+	stageStart time.Time
+}
+
+func newStartupTracer(target string) *startupTracer {
+	return &startupTracer{
+		enabled: osutil.GetenvBool("SNAPD_TRACE"),
+		traceID: os.Getenv("SNAPD_TRACE_ID"),
+		target:  target,
+	}
+}
+
+// begin starts the given stage, ending the current one if any.
+func (t *startupTracer) begin(stage string) {
+	if !t.enabled {
+		return
+	}
+	now := timeNow()
+	t.end(now)
+	t.stage = stage
+	t.stageStart = now
+}
+
+// exec ends the current stage and records the exec of the given command.
+func (t *startupTracer) exec(cmd string) {
+	if !t.enabled {
+		return
+	}
+	now := timeNow()
+	t.end(now)
+	t.emit("exec", now, 0, cmd)
+}
+
+func (t *startupTracer) end(now time.Time) {
+	if t.stage == "" {
+		return
+	}
+	t.emit(t.stage, t.stageStart, now.Sub(t.stageStart), "")
+	t.stage = ""
+}
+
+func (t *startupTracer) emit(stage string, start time.Time, duration time.Duration, cmd string) {
+	fields := map[string]string{
+		"MESSAGE":                           fmt.Sprintf("snap startup stage %q of %q took %v", stage, t.target, duration),
+		"PRIORITY":                          "7",
+		"SYSLOG_IDENTIFIER":                 "snap-exec",
+		"SNAPD_STARTUP_TARGET":              t.target,
+		"SNAPD_STARTUP_STAGE":               stage,
+		"SNAPD_STARTUP_STAGE_START_USEC":    strconv.FormatInt(start.UnixNano()/1e3, 10),
+		"SNAPD_STARTUP_STAGE_DURATION_USEC": strconv.FormatInt(duration.Microseconds(), 10),
+	}
+	if t.traceID != "" {
+		fields["SNAPD_TRACE_ID"] = t.traceID
+	}
+	if cmd != "" {
+		fields["SNAPD_STARTUP_COMMAND"] = cmd
+	}
+	if err := journalSendFields(fields); err != nil {
+		// tracing is best effort, do not try again
+		logger.Debugf("cannot send startup trace to the journal: %v", err)
+		t.enabled = false
+	}
+}
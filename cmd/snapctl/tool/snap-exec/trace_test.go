@@ -0,0 +1,146 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snap_exec_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/cmd/snapctl/tool/snap-exec"
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+)
+
+func mockTrace(c *C) (entries *[]map[string]string, restore func()) {
+	os.Setenv("SNAPD_TRACE", "1")
+	os.Setenv("SNAPD_TRACE_ID", "trace-id")
+
+	// every call advances the clock by a millisecond
+	now := time.Unix(1700000000, 0)
+	restoreTimeNow := snap_exec.MockTimeNow(func() time.Time {
+		now = now.Add(time.Millisecond)
+		return now
+	})
+
+	entries = &[]map[string]string{}
+	restoreJournal := snap_exec.MockJournalSendFields(func(fields map[string]string) error {
+		*entries = append(*entries, fields)
+		return nil
+	})
+	restoreExec := snap_exec.MockSyscallExec(func(argv0 string, argv []string, env []string) error {
+		return nil
+	})
+	return entries, func() {
+		restoreExec()
+		restoreJournal()
+		restoreTimeNow()
+		os.Unsetenv("SNAPD_TRACE")
+		os.Unsetenv("SNAPD_TRACE_ID")
+	}
+}
+
+func checkStages(c *C, entries []map[string]string, target string, stages []string) {
+	c.Assert(entries, HasLen, len(stages))
+	for i, stage := range stages {
+		fields := entries[i]
+		c.Check(fields["SYSLOG_IDENTIFIER"], Equals, "snap-exec")
+		c.Check(fields["PRIORITY"], Equals, "7")
+		c.Check(fields["SNAPD_TRACE_ID"], Equals, "trace-id")
+		c.Check(fields["SNAPD_STARTUP_TARGET"], Equals, target)
+		c.Check(fields["SNAPD_STARTUP_STAGE"], Equals, stage)
+		c.Check(fields["SNAPD_STARTUP_STAGE_START_USEC"], Equals, fmt.Sprintf("%d", 1700000000001000+i*1000))
+		if stage == "exec" {
+			c.Check(fields["SNAPD_STARTUP_STAGE_DURATION_USEC"], Equals, "0")
+		} else {
+			c.Check(fields["SNAPD_STARTUP_STAGE_DURATION_USEC"], Equals, "1000")
+			c.Check(fields["MESSAGE"], Equals, fmt.Sprintf("snap startup stage %q of %q took 1ms", stage, target))
+		}
+	}
+}
+
+func (s *snapExecSuite) TestSnapExecAppStartupTrace(c *C) {
+	dirs.SetRootDir(c.MkDir())
+	snaptest.MockSnap(c, string(mockYaml), &snap.SideInfo{
+		Revision: snap.R("42"),
+	})
+	entries, restore := mockTrace(c)
+	defer restore()
+
+	err := snap_exec.ExecApp("snapname.app2", "42", "", nil)
+	c.Assert(err, IsNil)
+
+	checkStages(c, *entries, "snapname.app2", []string{"read-info", "environment", "command-chain", "exec"})
+	c.Check((*entries)[3]["SNAPD_STARTUP_COMMAND"], Equals, fmt.Sprintf("%s/snapname/42/chain1", dirs.SnapMountDir))
+}
+
+func (s *snapExecSuite) TestSnapExecHookStartupTrace(c *C) {
+	dirs.SetRootDir(c.MkDir())
+	snaptest.MockSnap(c, string(mockHookYaml), &snap.SideInfo{
+		Revision: snap.R("42"),
+	})
+	entries, restore := mockTrace(c)
+	defer restore()
+
+	err := snap_exec.ExecHook("snapname", "42", "configure")
+	c.Assert(err, IsNil)
+
+	checkStages(c, *entries, "snapname", []string{"read-info", "environment", "command-chain", "exec"})
+	c.Check((*entries)[3]["SNAPD_STARTUP_COMMAND"], Equals, fmt.Sprintf("%s/snapname/42/meta/hooks/configure", dirs.SnapMountDir))
+}
+
+func (s *snapExecSuite) TestSnapExecStartupTraceDisabled(c *C) {
+	dirs.SetRootDir(c.MkDir())
+	snaptest.MockSnap(c, string(mockYaml), &snap.SideInfo{
+		Revision: snap.R("42"),
+	})
+	entries, restore := mockTrace(c)
+	defer restore()
+	os.Unsetenv("SNAPD_TRACE")
+
+	err := snap_exec.ExecApp("snapname.app", "42", "", nil)
+	c.Assert(err, IsNil)
+	c.Check(*entries, HasLen, 0)
+}
+
+func (s *snapExecSuite) TestSnapExecStartupTraceJournalError(c *C) {
+	dirs.SetRootDir(c.MkDir())
+	snaptest.MockSnap(c, string(mockYaml), &snap.SideInfo{
+		Revision: snap.R("42"),
+	})
+	_, restore := mockTrace(c)
+	defer restore()
+	calls := 0
+	restore = snap_exec.MockJournalSendFields(func(fields map[string]string) error {
+		calls++
+		return errors.New("no journal")
+	})
+	defer restore()
+
+	// tracing errors do not prevent running the app
+	err := snap_exec.ExecApp("snapname.app", "42", "", nil)
+	c.Assert(err, IsNil)
+	// and tracing is not attempted again
+	c.Check(calls, Equals, 1)
+}
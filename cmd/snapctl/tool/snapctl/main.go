@@ -26,6 +26,8 @@ import (
 
 	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/image/preseed/preseedctl"
+	"github.com/snapcore/snapd/snapdenv"
 	"github.com/snapcore/snapd/usersession/xdgopenproxy"
 )
 
@@ -39,6 +41,8 @@ var clientConfig = client.Config{
 	Socket: dirs.SnapSocket,
 }
 
+var preseedctlRun = preseedctl.Run
+
 func Main() {
 	// check for internal commands
 	if len(os.Args) > 2 && os.Args[1] == "internal" {
@@ -101,8 +105,15 @@ func run(stdin io.Reader) (stdout, stderr []byte, err error) {
 	if cookie == "" {
 		cookie = os.Getenv("SNAP_CONTEXT")
 	}
-	return cli.RunSnapctl(&client.SnapCtlOptions{
+	stdout, stderr, err = cli.RunSnapctl(&client.SnapCtlOptions{
 		ContextID: cookie,
 		Args:      os.Args[1:],
 	}, stdin)
+	if _, ok := err.(client.ConnectionError); ok && snapdenv.Preseeding() {
+		// some phases of preseeding run hooks without a live snapd,
+		// access the configuration in the state directly instead
+		stdout, err = preseedctlRun(cookie, os.Args[1:])
+		return stdout, nil, err
+	}
+	return stdout, stderr, err
 }
@@ -127,3 +127,42 @@ func (s *snapctlSuite) TestSnapctlWithStdin(c *C) {
 	_, _, err := run(mockStdin)
 	c.Check(err, IsNil)
 }
+
+func (s *snapctlSuite) TestSnapctlPreseedingWithoutSnapd(c *C) {
+	os.Setenv("SNAPD_PRESEED", "1")
+	defer os.Unsetenv("SNAPD_PRESEED")
+	s.server.Close()
+
+	os.Args = []string{"snapctl", "get", "foo"}
+	restore := mockPreseedctlRun(func(contextID string, args []string) ([]byte, error) {
+		c.Check(contextID, Equals, "snap-context-test")
+		c.Check(args, DeepEquals, []string{"get", "foo"})
+		return []byte("bar\n"), nil
+	})
+	defer restore()
+
+	stdout, stderr, err := run(nil)
+	c.Check(err, IsNil)
+	c.Check(string(stdout), Equals, "bar\n")
+	c.Check(stderr, IsNil)
+}
+
+func (s *snapctlSuite) TestSnapctlWithoutSnapdNotPreseeding(c *C) {
+	os.Unsetenv("SNAPD_PRESEED")
+	s.server.Close()
+
+	restore := mockPreseedctlRun(func(contextID string, args []string) ([]byte, error) {
+		c.Fatalf("unexpected call")
+		return nil, nil
+	})
+	defer restore()
+
+	_, _, err := run(nil)
+	c.Check(err, FitsTypeOf, client.ConnectionError{})
+}
+
+func mockPreseedctlRun(f func(contextID string, args []string) ([]byte, error)) (restore func()) {
+	old := preseedctlRun
+	preseedctlRun = f
+	return func() { preseedctlRun = old }
+}
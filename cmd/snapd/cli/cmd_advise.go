@@ -22,6 +22,7 @@ package cli
 import (
 	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -45,6 +46,14 @@ type cmdAdviseSnap struct {
 	// Command makes advise try to find snaps that provide this command
 	Command bool `long:"command"`
 
+	// FromMissingLibrary makes advise try to find snaps that provide
+	// this shared library
+	FromMissingLibrary bool `long:"from-missing-library"`
+
+	// FromMissingManPage makes advise try to find snaps that provide
+	// this man page
+	FromMissingManPage bool `long:"from-missing-manpage"`
+
 	// FromApt tells advise that it got started from an apt hook
 	// and needs to communicate over a socket
 	FromApt bool `long:"from-apt"`
@@ -58,6 +67,9 @@ var longAdviseSnapHelp = i18n.G(`
 The advise-snap command searches for and suggests the installation of snaps.
 
 If --command is given, it suggests snaps that provide the given command.
+If --from-missing-library is given, it suggests snaps that provide the shared
+library with the given soname. If --from-missing-manpage is given, it suggests
+snaps that provide the given man page.
 Otherwise it suggests snaps with the given name.
 `)
 
@@ -68,6 +80,10 @@ func init() {
 		// TRANSLATORS: This should not start with a lowercase letter.
 		"command": i18n.G("Advise on snaps that provide the given command"),
 		// TRANSLATORS: This should not start with a lowercase letter.
+		"from-missing-library": i18n.G("Advise on snaps that provide the given shared library"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"from-missing-manpage": i18n.G("Advise on snaps that provide the given man page"),
+		// TRANSLATORS: This should not start with a lowercase letter.
 		"dump-db": i18n.G("Dump advise database for use by command-not-found."),
 		// TRANSLATORS: This should not start with a lowercase letter.
 		"from-apt": i18n.G("Run as an apt hook"),
@@ -113,6 +129,25 @@ func outputAdviseJSON(command string, results []advisor.Command) error {
 	return nil
 }
 
+func outputAdviseProvidesText(notFoundMsg string, result []advisor.Package) error {
+	fmt.Fprintf(Stdout, "\n")
+	fmt.Fprint(Stdout, notFoundMsg)
+	fmt.Fprintf(Stdout, "\n")
+	for _, snap := range result {
+		fmt.Fprintf(Stdout, "sudo snap install %s\n", snap.Snap)
+	}
+	fmt.Fprintf(Stdout, "\n")
+	fmt.Fprintln(Stdout, i18n.G("See 'snap info <snap name>' for additional versions."))
+	fmt.Fprintf(Stdout, "\n")
+	return nil
+}
+
+func outputAdviseProvidesJSON(results []advisor.Package) error {
+	enc := json.NewEncoder(Stdout)
+	enc.Encode(results)
+	return nil
+}
+
 type jsonRPC struct {
 	JsonRPC string `json:"jsonrpc"`
 	Method  string `json:"method"`
@@ -276,10 +311,28 @@ func (x *cmdAdviseSnap) Execute(args []string) error {
 		return fmt.Errorf("the required argument `<command or pkg>` was not provided")
 	}
 
+	modes := 0
+	for _, mode := range []bool{x.Command, x.FromMissingLibrary, x.FromMissingManPage} {
+		if mode {
+			modes++
+		}
+	}
+	if modes > 1 {
+		return errors.New(i18n.G("cannot use --command, --from-missing-library and --from-missing-manpage together"))
+	}
+
 	if x.Command {
 		return adviseCommand(x.Positionals.CommandOrPkg, x.Format)
 	}
 
+	if x.FromMissingLibrary {
+		return adviseLibrary(x.Positionals.CommandOrPkg, x.Format)
+	}
+
+	if x.FromMissingManPage {
+		return adviseManPage(x.Positionals.CommandOrPkg, x.Format)
+	}
+
 	return advisePkg(x.Positionals.CommandOrPkg)
 }
 
@@ -334,3 +387,43 @@ func adviseCommand(cmd string, format string) error {
 
 	return fmt.Errorf("%s: command not found", cmd)
 }
+
+func adviseLibrary(soname string, format string) error {
+	matches, err := advisor.FindLibrary(soname)
+	if err != nil {
+		return fmt.Errorf("advise for library failed: %s", err)
+	}
+	if len(matches) == 0 {
+		return fmt.Errorf("%s: library not found", soname)
+	}
+
+	switch format {
+	case "json":
+		return outputAdviseProvidesJSON(matches)
+	case "pretty":
+		// TRANSLATORS: %q is a shared library soname (like "libfoo.so.3")
+		return outputAdviseProvidesText(fmt.Sprintf(i18n.G("Library %q not found, but can be installed with:\n"), soname), matches)
+	default:
+		return fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+func adviseManPage(name string, format string) error {
+	matches, err := advisor.FindManPage(name)
+	if err != nil {
+		return fmt.Errorf("advise for man page failed: %s", err)
+	}
+	if len(matches) == 0 {
+		return fmt.Errorf("%s: man page not found", name)
+	}
+
+	switch format {
+	case "json":
+		return outputAdviseProvidesJSON(matches)
+	case "pretty":
+		// TRANSLATORS: %q is a man page name (like "foo.1")
+		return outputAdviseProvidesText(fmt.Sprintf(i18n.G("Man page %q not found, but can be installed with:\n"), name), matches)
+	default:
+		return fmt.Errorf("unsupported format %q", format)
+	}
+}
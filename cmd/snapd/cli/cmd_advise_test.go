@@ -68,6 +68,31 @@ func (sf *sillyFinder) FindPackage(pkgName string) (*advisor.Package, error) {
 	}
 }
 
+func (sf *sillyFinder) FindLibrary(soname string) ([]advisor.Package, error) {
+	switch soname {
+	case "libhello.so.1":
+		return []advisor.Package{
+			{Snap: "hello", Version: "1.0"},
+			{Snap: "hello-wcm", Version: "2.0"},
+		}, nil
+	case "error-please":
+		return nil, fmt.Errorf("find-lib failed")
+	default:
+		return nil, nil
+	}
+}
+
+func (sf *sillyFinder) FindManPage(name string) ([]advisor.Package, error) {
+	switch name {
+	case "hello.1":
+		return []advisor.Package{{Snap: "hello", Version: "1.0"}}, nil
+	case "error-please":
+		return nil, fmt.Errorf("find-man failed")
+	default:
+		return nil, nil
+	}
+}
+
 func (*sillyFinder) Close() error { return nil }
 
 func (s *SnapSuite) TestAdviseCommandHappyText(c *C) {
@@ -100,6 +125,78 @@ func (s *SnapSuite) TestAdviseCommandHappyJSON(c *C) {
 	c.Assert(s.Stderr(), Equals, "")
 }
 
+func (s *SnapSuite) TestAdviseLibraryHappyText(c *C) {
+	restore := advisor.ReplaceCommandsFinder(mkSillyFinder)
+	defer restore()
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"advise-snap", "--from-missing-library", "libhello.so.1"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Assert(s.Stdout(), Equals, `
+Library "libhello.so.1" not found, but can be installed with:
+
+sudo snap install hello
+sudo snap install hello-wcm
+
+See 'snap info <snap name>' for additional versions.
+
+`)
+	c.Assert(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestAdviseLibraryHappyJSON(c *C) {
+	restore := advisor.ReplaceCommandsFinder(mkSillyFinder)
+	defer restore()
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"advise-snap", "--from-missing-library", "--format=json", "libhello.so.1"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Assert(s.Stdout(), Equals, `[{"snap":"hello","version":"1.0"},{"snap":"hello-wcm","version":"2.0"}]`+"\n")
+	c.Assert(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestAdviseLibraryNotFound(c *C) {
+	restore := advisor.ReplaceCommandsFinder(mkSillyFinder)
+	defer restore()
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"advise-snap", "--from-missing-library", "libmeh.so.1"})
+	c.Assert(err, ErrorMatches, "libmeh.so.1: library not found")
+
+	_, err = snap.Parser(snap.Client()).ParseArgs([]string{"advise-snap", "--from-missing-library", "error-please"})
+	c.Assert(err, ErrorMatches, "advise for library failed: find-lib failed")
+}
+
+func (s *SnapSuite) TestAdviseManPageHappyText(c *C) {
+	restore := advisor.ReplaceCommandsFinder(mkSillyFinder)
+	defer restore()
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"advise-snap", "--from-missing-manpage", "hello.1"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Assert(s.Stdout(), Equals, `
+Man page "hello.1" not found, but can be installed with:
+
+sudo snap install hello
+
+See 'snap info <snap name>' for additional versions.
+
+`)
+	c.Assert(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestAdviseManPageNotFound(c *C) {
+	restore := advisor.ReplaceCommandsFinder(mkSillyFinder)
+	defer restore()
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"advise-snap", "--from-missing-manpage", "meh.1"})
+	c.Assert(err, ErrorMatches, "meh.1: man page not found")
+}
+
+func (s *SnapSuite) TestAdviseConflictingModes(c *C) {
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"advise-snap", "--command", "--from-missing-library", "hello"})
+	c.Assert(err, ErrorMatches, "cannot use --command, --from-missing-library and --from-missing-manpage together")
+}
+
 func (s *SnapSuite) TestAdviseCommandDumpDb(c *C) {
 	dirs.SetRootDir(c.MkDir())
 	c.Assert(os.MkdirAll(dirs.SnapCacheDir, 0755), IsNil)
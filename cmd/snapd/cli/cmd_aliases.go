@@ -32,6 +32,7 @@ import (
 
 type cmdAliases struct {
 	clientMixin
+	structuredOutputMixin
 	Positionals struct {
 		Snap installedSnapName `positional-arg-name:"<snap>"`
 	} `positional-args:"true"`
@@ -52,12 +53,14 @@ func init() {
 	}, nil, nil)
 }
 
+// aliasInfo is also the structured output of the aliases command for each
+// alias.
 type aliasInfo struct {
-	Snap    string
-	Command string
-	Alias   string
-	Status  string
-	Auto    string
+	Snap    string `json:"snap" yaml:"snap"`
+	Command string `json:"command" yaml:"command"`
+	Alias   string `json:"alias" yaml:"alias"`
+	Status  string `json:"status" yaml:"status"`
+	Auto    string `json:"auto,omitempty" yaml:"auto,omitempty"`
 }
 
 type aliasInfos []*aliasInfo
@@ -110,6 +113,14 @@ func (x *cmdAliases) Execute(args []string) error {
 		}
 	}
 
+	if x.structuredOutput() {
+		sort.Sort(infos)
+		if infos == nil {
+			infos = aliasInfos{}
+		}
+		return x.writeStructuredOutput(infos)
+	}
+
 	if len(infos) > 0 {
 		w := tabWriter()
 		fmt.Fprintln(w, i18n.G("Command\tAlias\tNotes"))
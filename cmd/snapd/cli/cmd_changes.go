@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"regexp"
 	"sort"
+	"time"
 
 	"github.com/jessevdk/go-flags"
 	"github.com/snapcore/snapd/client"
@@ -42,6 +43,7 @@ change.
 type cmdChanges struct {
 	clientMixin
 	timeMixin
+	structuredOutputMixin
 	Positional struct {
 		Snap string `positional-arg-name:"<snap>"`
 	} `positional-args:"yes"`
@@ -51,6 +53,7 @@ type cmdTasks struct {
 	timeMixin
 	changeIDMixin
 	formatMixin
+	structuredOutputMixin
 }
 
 func init() {
@@ -70,6 +73,78 @@ func (s changesByTime) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
 
 var allDigits = regexp.MustCompile(`^[0-9]+$`).MatchString
 
+// changeOutput is the structured output of the changes command for each
+// change.
+type changeOutput struct {
+	ID        string     `json:"id" yaml:"id"`
+	Kind      string     `json:"kind" yaml:"kind"`
+	Summary   string     `json:"summary" yaml:"summary"`
+	Status    string     `json:"status" yaml:"status"`
+	SpawnTime time.Time  `json:"spawn-time" yaml:"spawn-time"`
+	ReadyTime *time.Time `json:"ready-time,omitempty" yaml:"ready-time,omitempty"`
+	Err       string     `json:"err,omitempty" yaml:"err,omitempty"`
+	// Tasks is only set by the tasks command
+	Tasks []taskOutput `json:"tasks,omitempty" yaml:"tasks,omitempty"`
+}
+
+// taskOutput is the structured output of the tasks command for each task.
+type taskOutput struct {
+	ID        string             `json:"id" yaml:"id"`
+	Kind      string             `json:"kind" yaml:"kind"`
+	Summary   string             `json:"summary" yaml:"summary"`
+	Status    string             `json:"status" yaml:"status"`
+	Progress  taskProgressOutput `json:"progress" yaml:"progress"`
+	SpawnTime time.Time          `json:"spawn-time" yaml:"spawn-time"`
+	ReadyTime *time.Time         `json:"ready-time,omitempty" yaml:"ready-time,omitempty"`
+	Log       []string           `json:"log,omitempty" yaml:"log,omitempty"`
+}
+
+type taskProgressOutput struct {
+	Label string `json:"label,omitempty" yaml:"label,omitempty"`
+	Done  int    `json:"done" yaml:"done"`
+	Total int    `json:"total" yaml:"total"`
+}
+
+func maybeTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+func changeToOutput(chg *client.Change, withTasks bool) changeOutput {
+	co := changeOutput{
+		ID:        chg.ID,
+		Kind:      chg.Kind,
+		Summary:   chg.Summary,
+		Status:    chg.Status,
+		SpawnTime: chg.SpawnTime,
+		ReadyTime: maybeTime(chg.ReadyTime),
+		Err:       chg.Err,
+	}
+	if !withTasks {
+		return co
+	}
+	co.Tasks = make([]taskOutput, 0, len(chg.Tasks))
+	for _, t := range chg.Tasks {
+		co.Tasks = append(co.Tasks, taskOutput{
+			ID:      t.ID,
+			Kind:    t.Kind,
+			Summary: t.Summary,
+			Status:  t.Status,
+			Progress: taskProgressOutput{
+				Label: t.Progress.Label,
+				Done:  t.Progress.Done,
+				Total: t.Progress.Total,
+			},
+			SpawnTime: t.SpawnTime,
+			ReadyTime: maybeTime(t.ReadyTime),
+			Log:       t.Log,
+		})
+	}
+	return co
+}
+
 func queryChanges(cli *client.Client, opts *client.ChangesOptions) ([]*client.Change, error) {
 	chgs, err := cli.Changes(opts)
 	if err != nil {
@@ -106,13 +181,21 @@ func (c *cmdChanges) Execute(args []string) error {
 		return err
 	}
 
-	if len(changes) == 0 {
+	if len(changes) == 0 && !c.structuredOutput() {
 		fmt.Fprintln(Stderr, i18n.G("no changes found"))
 		return nil
 	}
 
 	sort.Sort(changesByTime(changes))
 
+	if c.structuredOutput() {
+		out := make([]changeOutput, 0, len(changes))
+		for _, chg := range changes {
+			out = append(out, changeToOutput(chg, false))
+		}
+		return c.writeStructuredOutput(out)
+	}
+
 	w := tabWriter()
 
 	fmt.Fprint(w, i18n.G("ID\tStatus\tSpawn\tReady\tSummary\n"))
@@ -160,6 +243,10 @@ func (c *cmdTasks) showChange(chid string) error {
 		return err
 	}
 
+	if c.structuredOutput() {
+		return c.writeStructuredOutput(changeToOutput(chg, true))
+	}
+
 	if c.Format != "text" && c.Format != "" {
 		err = c.formatNonText(chg)
 		return err
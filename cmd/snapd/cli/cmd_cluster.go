@@ -0,0 +1,319 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/strutil"
+)
+
+type cmdCluster struct{}
+
+var shortClusterHelp = i18n.G("Assemble and inspect device clusters")
+var longClusterHelp = i18n.G(`
+The cluster command contains sub-commands to assemble a cluster of devices,
+to inspect the cluster the device is part of and to hold or resume the
+refreshes of clustered snaps across it.
+
+Clustering is experimental and requires experimental.clustering to be set.
+`)
+
+type cmdClusterAssemble struct {
+	waitMixin
+
+	Secret       string   `long:"secret" required:"yes"`
+	Address      string   `long:"address" required:"yes"`
+	Peers        []string `long:"peer"`
+	ExpectedSize int      `long:"expected-size"`
+}
+
+var shortClusterAssembleHelp = i18n.G("Assemble a cluster with other devices")
+var longClusterAssembleHelp = i18n.G(`
+The assemble command makes the device take part in the assembly of a cluster
+with the other devices which are given the same secret.
+
+The device listens for its peers on the given address, and reaches out to
+the peers given with --peer. Peers reached this way share the addresses of
+the devices they know of in turn.
+
+Unless the expected number of devices is given, the assembly goes on until
+its session expires, one hour after it started.
+`)
+
+type cmdClusterStatus struct {
+	clientMixin
+	timeMixin
+}
+
+var shortClusterStatusHelp = i18n.G("Show the cluster the device is part of")
+var longClusterStatusHelp = i18n.G(`
+The status command shows the cluster the device is part of and the progress
+of its assembly: the devices identified so far, with their address and the
+fingerprint of their certificate, the number of peers which proved knowledge
+of the secret and the number of verified routes between the devices.
+
+Once the cluster is assembled, it also shows the member coordinating the
+refreshes of clustered snaps, the snaps whose refreshes are held across the
+cluster and the refreshes of clustered snaps this device waits to perform or
+performs.
+`)
+
+type cmdClusterLeave struct {
+	clientMixin
+}
+
+var shortClusterLeaveHelp = i18n.G("Leave the cluster the device is part of")
+var longClusterLeaveHelp = i18n.G(`
+The leave command makes the device leave the cluster it is part of, aborting
+the assembly of the cluster if it is in progress. The snaps installed on
+behalf of the cluster are left installed.
+`)
+
+type cmdClusterHoldRefreshes struct {
+	clientMixin
+	Positional struct {
+		Snaps []installedSnapName `positional-arg-name:"<snap>"`
+	} `positional-args:"yes"`
+}
+
+var shortClusterHoldRefreshesHelp = i18n.G("Hold the refreshes of clustered snaps")
+var longClusterHoldRefreshesHelp = i18n.G(`
+The hold-refreshes command holds the refreshes of the given clustered snaps,
+or of all of them if none are given, on all the members of the cluster.
+Refreshes already under way on a member are allowed to finish.
+`)
+
+type cmdClusterResumeRefreshes struct {
+	clientMixin
+	Positional struct {
+		Snaps []installedSnapName `positional-arg-name:"<snap>"`
+	} `positional-args:"yes"`
+}
+
+var shortClusterResumeRefreshesHelp = i18n.G("Resume the refreshes of clustered snaps")
+var longClusterResumeRefreshesHelp = i18n.G(`
+The resume-refreshes command removes the holds on the refreshes of the given
+clustered snaps, or of all of them if none are given, on all the members of
+the cluster.
+
+Refreshes of clustered snaps are rolled out one member at a time, and the
+rollout stops if a refreshed snap is reported as unhealthy or does not report
+on its health in time. Resuming the refreshes of a snap also lets its rollout
+carry on.
+`)
+
+func init() {
+	addClusterCommand("assemble", shortClusterAssembleHelp, longClusterAssembleHelp, func() flags.Commander {
+		return &cmdClusterAssemble{}
+	}, waitDescs.also(map[string]string{
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"secret": i18n.G("Secret shared by the devices assembling the cluster"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"address": i18n.G("Address, as host:port, to listen on for the other devices"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"peer": i18n.G("Address, as host:port, of another device to reach out to (can be repeated)"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"expected-size": i18n.G("Number of devices expected in the cluster"),
+	}), nil)
+	addClusterCommand("status", shortClusterStatusHelp, longClusterStatusHelp, func() flags.Commander {
+		return &cmdClusterStatus{}
+	}, timeDescs, nil)
+	addClusterCommand("leave", shortClusterLeaveHelp, longClusterLeaveHelp, func() flags.Commander {
+		return &cmdClusterLeave{}
+	}, nil, nil)
+	addClusterCommand("hold-refreshes", shortClusterHoldRefreshesHelp, longClusterHoldRefreshesHelp, func() flags.Commander {
+		return &cmdClusterHoldRefreshes{}
+	}, nil, []argDesc{{
+		// TRANSLATORS: This needs to begin with < and end with >
+		name: i18n.G("<snap>"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		desc: i18n.G("Clustered snap whose refreshes to hold"),
+	}})
+	addClusterCommand("resume-refreshes", shortClusterResumeRefreshesHelp, longClusterResumeRefreshesHelp, func() flags.Commander {
+		return &cmdClusterResumeRefreshes{}
+	}, nil, []argDesc{{
+		// TRANSLATORS: This needs to begin with < and end with >
+		name: i18n.G("<snap>"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		desc: i18n.G("Clustered snap whose refreshes to resume"),
+	}})
+}
+
+func (x *cmdClusterAssemble) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	chgID, err := x.client.AssembleCluster(&client.ClusterAssembleOptions{
+		Secret:       x.Secret,
+		Address:      x.Address,
+		Peers:        x.Peers,
+		ExpectedSize: x.ExpectedSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := x.wait(chgID); err != nil {
+		if err == noWait {
+			return nil
+		}
+		return err
+	}
+
+	fmt.Fprintln(Stdout, i18n.G("Cluster assembled"))
+	return nil
+}
+
+func (x *cmdClusterStatus) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	status, err := x.client.ClusterStatus()
+	if err != nil {
+		return err
+	}
+	if status.ClusterID == "" && status.Assembly == nil {
+		fmt.Fprintln(Stderr, i18n.G("Device is not part of a cluster."))
+		return nil
+	}
+
+	w := tabWriter()
+	defer w.Flush()
+
+	if status.ClusterID != "" {
+		fmt.Fprintf(w, "cluster-id:\t%s\n", status.ClusterID)
+	}
+
+	as := status.Assembly
+	if as == nil {
+		return nil
+	}
+
+	devices := strconv.Itoa(len(as.Members))
+	if as.ExpectedSize > 0 {
+		devices = fmt.Sprintf("%d/%d", len(as.Members), as.ExpectedSize)
+	}
+	fmt.Fprintf(w, "assembly:\t%s\n", as.Status)
+	fmt.Fprintf(w, "initiated:\t%s\n", x.fmtTime(as.Initiated))
+	fmt.Fprintf(w, "address:\t%s\n", as.Address)
+	fmt.Fprintf(w, "fingerprint:\t%s\n", as.Fingerprint)
+	fmt.Fprintf(w, "devices:\t%s\n", devices)
+	fmt.Fprintf(w, "trusted-peers:\t%d\n", as.Trusted)
+	fmt.Fprintf(w, "routes:\t%d\n", as.Routes)
+
+	rs := status.Refreshes
+	if rs != nil {
+		fmt.Fprintf(w, "refresh-leader:\t%s\n", fmtClusterField(rs.Leader))
+		fmt.Fprintf(w, "refreshes-held:\t%s\n", fmtClusterHolds(rs))
+	}
+
+	if len(as.Members) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, i18n.G("Device\tSerial\tAddress\tFingerprint"))
+		for _, m := range as.Members {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", m.RDT, fmtClusterField(m.Serial), fmtClusterField(m.Address), m.Fingerprint)
+		}
+	}
+
+	if rs != nil && len(rs.Refreshes) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, i18n.G("Snap\tRev\tStatus\tNotes"))
+		for _, r := range rs.Refreshes {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Snap, r.Revision, r.Status, fmtClusterField(r.Reason))
+		}
+	}
+
+	return nil
+}
+
+func fmtClusterHolds(rs *client.ClusterRefreshes) string {
+	if rs.HeldAll {
+		return i18n.G("all")
+	}
+	if len(rs.Held) == 0 {
+		return "-"
+	}
+	return strings.Join(rs.Held, ",")
+}
+
+func fmtClusterField(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func (x *cmdClusterLeave) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	if err := x.client.LeaveCluster(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(Stdout, i18n.G("Left the cluster"))
+	return nil
+}
+
+func (x *cmdClusterHoldRefreshes) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	snaps := installedSnapNames(x.Positional.Snaps)
+	if err := x.client.HoldClusterRefreshes(snaps); err != nil {
+		return err
+	}
+
+	if len(snaps) == 0 {
+		fmt.Fprintln(Stdout, i18n.G("Refreshes of all clustered snaps held across the cluster"))
+		return nil
+	}
+	fmt.Fprintf(Stdout, i18n.G("Refreshes of %s held across the cluster\n"), strutil.Quoted(snaps))
+	return nil
+}
+
+func (x *cmdClusterResumeRefreshes) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	snaps := installedSnapNames(x.Positional.Snaps)
+	if err := x.client.ResumeClusterRefreshes(snaps); err != nil {
+		return err
+	}
+
+	if len(snaps) == 0 {
+		fmt.Fprintln(Stdout, i18n.G("Refreshes of all clustered snaps resumed across the cluster"))
+		return nil
+	}
+	fmt.Fprintf(Stdout, i18n.G("Refreshes of %s resumed across the cluster\n"), strutil.Quoted(snaps))
+	return nil
+}
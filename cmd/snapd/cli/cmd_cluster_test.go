@@ -0,0 +1,315 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	. "gopkg.in/check.v1"
+
+	snap "github.com/snapcore/snapd/cmd/snapd/cli"
+)
+
+func (s *SnapSuite) TestClusterAssemble(c *C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.Method, Equals, "POST")
+			c.Check(r.URL.Path, Equals, "/v2/cluster")
+			var body map[string]any
+			c.Assert(json.NewDecoder(r.Body).Decode(&body), IsNil)
+			c.Check(body, DeepEquals, map[string]any{
+				"action":        "assemble",
+				"secret":        "secret",
+				"address":       "10.0.0.1:7070",
+				"peers":         []any{"10.0.0.2:7070", "10.0.0.3:7070"},
+				"expected-size": float64(3),
+			})
+			w.WriteHeader(202)
+			fmt.Fprintln(w, `{"type": "async", "change": "42", "status-code": 202}`)
+		case 1:
+			c.Check(r.Method, Equals, "GET")
+			c.Check(r.URL.Path, Equals, "/v2/changes/42")
+			fmt.Fprintln(w, `{"type": "sync", "result": {"ready": true, "status": "Done"}}`)
+		default:
+			c.Fatalf("expected to get 2 requests, now on %d", n+1)
+		}
+		n++
+	})
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"cluster", "assemble",
+		"--secret=secret", "--address=10.0.0.1:7070",
+		"--peer=10.0.0.2:7070", "--peer=10.0.0.3:7070", "--expected-size=3"})
+	c.Assert(err, IsNil)
+	c.Check(rest, HasLen, 0)
+	c.Check(s.Stdout(), Equals, "Cluster assembled\n")
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestClusterAssembleNoWait(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "POST")
+		c.Check(r.URL.Path, Equals, "/v2/cluster")
+		w.WriteHeader(202)
+		fmt.Fprintln(w, `{"type": "async", "change": "42", "status-code": 202}`)
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"cluster", "assemble", "--no-wait", "--secret=secret", "--address=10.0.0.1:7070"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, "42\n")
+}
+
+func (s *SnapSuite) TestClusterAssembleRequiresSecret(c *C) {
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"cluster", "assemble", "--address=10.0.0.1:7070"})
+	c.Assert(err, ErrorMatches, "the required flag `--secret' was not specified")
+}
+
+func (s *SnapSuite) TestClusterAssembleError(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(400)
+		fmt.Fprintln(w, `{"type": "error", "status-code": 400, "result": {"message": "cannot assemble cluster: an assembly is already in progress"}}`)
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"cluster", "assemble", "--secret=secret", "--address=10.0.0.1:7070"})
+	c.Assert(err, ErrorMatches, "cannot assemble cluster: an assembly is already in progress")
+}
+
+func (s *SnapSuite) TestClusterStatus(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/cluster")
+		fmt.Fprintln(w, `{"type": "sync", "result": {
+			"assembly": {
+				"status": "in-progress",
+				"rdt": "rdt-1",
+				"address": "10.0.0.1:7070",
+				"fingerprint": "fp-1",
+				"initiated": "2026-10-16T10:00:00Z",
+				"expected-size": 3,
+				"members": [
+					{"rdt": "rdt-1", "serial": "serial-1", "address": "10.0.0.1:7070", "fingerprint": "fp-1"},
+					{"rdt": "rdt-2", "fingerprint": "fp-2"}
+				],
+				"trusted": 1,
+				"routes": 2
+			}
+		}}`)
+	})
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"cluster", "status", "--abs-time"})
+	c.Assert(err, IsNil)
+	c.Check(rest, HasLen, 0)
+	c.Check(s.Stdout(), Equals, `assembly:       in-progress
+initiated:      2026-10-16T10:00:00Z
+address:        10.0.0.1:7070
+fingerprint:    fp-1
+devices:        2/3
+trusted-peers:  1
+routes:         2
+
+Device  Serial    Address        Fingerprint
+rdt-1   serial-1  10.0.0.1:7070  fp-1
+rdt-2   -         -              fp-2
+`)
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestClusterStatusClusterOnly(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"type": "sync", "result": {"cluster-id": "cluster-id"}}`)
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"cluster", "status"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, "cluster-id:  cluster-id\n")
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestClusterStatusNotInCluster(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"type": "sync", "result": {}}`)
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"cluster", "status"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, "")
+	c.Check(s.Stderr(), Equals, "Device is not part of a cluster.\n")
+}
+
+func (s *SnapSuite) TestClusterLeave(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "POST")
+		c.Check(r.URL.Path, Equals, "/v2/cluster")
+		var body map[string]any
+		c.Assert(json.NewDecoder(r.Body).Decode(&body), IsNil)
+		c.Check(body, DeepEquals, map[string]any{"action": "leave"})
+		fmt.Fprintln(w, `{"type": "sync", "result": null}`)
+	})
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"cluster", "leave"})
+	c.Assert(err, IsNil)
+	c.Check(rest, HasLen, 0)
+	c.Check(s.Stdout(), Equals, "Left the cluster\n")
+}
+
+func (s *SnapSuite) TestClusterLeaveError(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(400)
+		fmt.Fprintln(w, `{"type": "error", "status-code": 400, "result": {"message": "cannot leave cluster: device is not part of a cluster"}}`)
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"cluster", "leave"})
+	c.Assert(err, ErrorMatches, "cannot leave cluster: device is not part of a cluster")
+}
+
+func (s *SnapSuite) TestClusterStatusRefreshes(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"type": "sync", "result": {
+			"cluster-id": "cluster-id",
+			"assembly": {
+				"status": "done",
+				"rdt": "rdt-2",
+				"address": "10.0.0.2:7070",
+				"fingerprint": "fp-2",
+				"initiated": "2026-10-16T10:00:00Z",
+				"members": [
+					{"rdt": "rdt-1", "serial": "serial-1", "address": "10.0.0.1:7070", "fingerprint": "fp-1"},
+					{"rdt": "rdt-2", "serial": "serial-2", "address": "10.0.0.2:7070", "fingerprint": "fp-2"}
+				],
+				"trusted": 1,
+				"routes": 2
+			},
+			"refreshes": {
+				"leader": "rdt-1",
+				"held": ["other", "third"],
+				"refreshes": [
+					{"snap": "microk8s", "revision": "2", "status": "refreshing"},
+					{"snap": "other", "revision": "5", "status": "waiting", "reason": "refreshes of \"other\" are held"}
+				]
+			}
+		}}`)
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"cluster", "status", "--abs-time"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, `cluster-id:      cluster-id
+assembly:        done
+initiated:       2026-10-16T10:00:00Z
+address:         10.0.0.2:7070
+fingerprint:     fp-2
+devices:         2
+trusted-peers:   1
+routes:          2
+refresh-leader:  rdt-1
+refreshes-held:  other,third
+
+Device  Serial    Address        Fingerprint
+rdt-1   serial-1  10.0.0.1:7070  fp-1
+rdt-2   serial-2  10.0.0.2:7070  fp-2
+
+Snap      Rev  Status      Notes
+microk8s  2    refreshing  -
+other     5    waiting     refreshes of "other" are held
+`)
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestClusterStatusAllRefreshesHeld(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"type": "sync", "result": {
+			"assembly": {
+				"status": "done",
+				"rdt": "rdt-1",
+				"address": "10.0.0.1:7070",
+				"fingerprint": "fp-1",
+				"initiated": "2026-10-16T10:00:00Z",
+				"trusted": 0,
+				"routes": 0
+			},
+			"refreshes": {"held-all": true}
+		}}`)
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"cluster", "status", "--abs-time"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, `assembly:        done
+initiated:       2026-10-16T10:00:00Z
+address:         10.0.0.1:7070
+fingerprint:     fp-1
+devices:         0
+trusted-peers:   0
+routes:          0
+refresh-leader:  -
+refreshes-held:  all
+`)
+}
+
+func (s *SnapSuite) TestClusterHoldResumeRefreshes(c *C) {
+	for _, tc := range []struct {
+		args   []string
+		body   map[string]any
+		stdout string
+	}{{
+		args:   []string{"cluster", "hold-refreshes", "microk8s", "other"},
+		body:   map[string]any{"action": "hold-refreshes", "snaps": []any{"microk8s", "other"}},
+		stdout: "Refreshes of \"microk8s\", \"other\" held across the cluster\n",
+	}, {
+		args:   []string{"cluster", "hold-refreshes"},
+		body:   map[string]any{"action": "hold-refreshes"},
+		stdout: "Refreshes of all clustered snaps held across the cluster\n",
+	}, {
+		args:   []string{"cluster", "resume-refreshes", "microk8s"},
+		body:   map[string]any{"action": "resume-refreshes", "snaps": []any{"microk8s"}},
+		stdout: "Refreshes of \"microk8s\" resumed across the cluster\n",
+	}, {
+		args:   []string{"cluster", "resume-refreshes"},
+		body:   map[string]any{"action": "resume-refreshes"},
+		stdout: "Refreshes of all clustered snaps resumed across the cluster\n",
+	}} {
+		s.ResetStdStreams()
+
+		s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+			c.Check(r.Method, Equals, "POST")
+			c.Check(r.URL.Path, Equals, "/v2/cluster")
+			var body map[string]any
+			c.Assert(json.NewDecoder(r.Body).Decode(&body), IsNil)
+			c.Check(body, DeepEquals, tc.body)
+			fmt.Fprintln(w, `{"type": "sync", "result": null}`)
+		})
+
+		rest, err := snap.Parser(snap.Client()).ParseArgs(tc.args)
+		c.Assert(err, IsNil)
+		c.Check(rest, HasLen, 0)
+		c.Check(s.Stdout(), Equals, tc.stdout)
+	}
+}
+
+func (s *SnapSuite) TestClusterHoldRefreshesError(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(400)
+		fmt.Fprintln(w, `{"type": "error", "status-code": 400, "result": {"message": "cannot hold cluster refreshes: device is not part of an assembled cluster"}}`)
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"cluster", "hold-refreshes"})
+	c.Assert(err, ErrorMatches, "cannot hold cluster refreshes: device is not part of an assembled cluster")
+}
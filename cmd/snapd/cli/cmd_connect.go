@@ -20,15 +20,24 @@
 package cli
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
 	"github.com/jessevdk/go-flags"
+	"gopkg.in/yaml.v2"
 
+	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/i18n"
 )
 
 type cmdConnect struct {
 	waitMixin
-	Positionals struct {
-		PlugSpec connectPlugSpec `required:"yes"`
+	File            flags.Filename `long:"file"`
+	RestartAffected bool           `long:"restart-affected"`
+	Positionals     struct {
+		PlugSpec connectPlugSpec
 		SlotSpec connectSlotSpec
 	} `positional-args:"true"`
 }
@@ -52,12 +61,32 @@ $ snap connect <snap>:<plug>
 
 Connects the provided plug to the slot in the core snap with a name matching
 the plug name.
+
+$ snap connect --file <connections.yaml>
+
+Connects all the plugs listed in the given file in a single change. Either all
+of the connections are made or, if any of them fails, none of them are. The
+file lists the connections as follows:
+
+connections:
+- {plug: <snap>:<plug>, slot: <snap>:<slot>}
+
+Some connections change the mount namespace of a snap, in which case its
+running services need a restart to reliably see the change. The
+--restart-affected option restarts them as part of the same change,
+otherwise they are listed and can be restarted later with
+'snap restart --all-affected'.
 `)
 
 func init() {
 	addCommand("connect", shortConnectHelp, longConnectHelp, func() flags.Commander {
 		return &cmdConnect{}
-	}, waitDescs, []argDesc{
+	}, waitDescs.also(map[string]string{
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"file": i18n.G("Connect all plugs listed in the given YAML file"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"restart-affected": i18n.G("Restart the services which need it to see the new connection"),
+	}), []argDesc{
 		// TRANSLATORS: This needs to begin with < and end with >
 		{name: i18n.G("<snap>:<plug>")},
 		// TRANSLATORS: This needs to begin with < and end with >
@@ -70,6 +99,27 @@ func (x *cmdConnect) Execute(args []string) error {
 		return ErrExtraArgs
 	}
 
+	if x.File != "" {
+		if x.Positionals.PlugSpec.Snap != "" || x.Positionals.PlugSpec.Name != "" {
+			return errors.New(i18n.G("cannot use --file together with a plug or slot"))
+		}
+		if x.RestartAffected {
+			return errors.New(i18n.G("cannot use --file together with --restart-affected"))
+		}
+		conns, err := readConnectionsFile(string(x.File))
+		if err != nil {
+			return err
+		}
+		id, err := x.client.ConnectBatch(conns)
+		if err != nil {
+			return err
+		}
+		return x.waitIgnoringNoWait(id)
+	}
+	if x.Positionals.PlugSpec.Snap == "" && x.Positionals.PlugSpec.Name == "" {
+		return errors.New(i18n.G("the required argument `<snap>:<plug>` was not provided"))
+	}
+
 	// snap connect <plug> <snap>[:<slot>]
 	if x.Positionals.PlugSpec.Snap != "" && x.Positionals.PlugSpec.Name == "" {
 		// Move the value of .Snap to .Name and keep .Snap empty
@@ -77,11 +127,26 @@ func (x *cmdConnect) Execute(args []string) error {
 		x.Positionals.PlugSpec.Snap = ""
 	}
 
-	id, err := x.client.Connect(x.Positionals.PlugSpec.Snap, x.Positionals.PlugSpec.Name, x.Positionals.SlotSpec.Snap, x.Positionals.SlotSpec.Name)
+	opts := &client.ConnectOptions{RestartAffected: x.RestartAffected}
+	id, err := x.client.Connect(x.Positionals.PlugSpec.Snap, x.Positionals.PlugSpec.Name, x.Positionals.SlotSpec.Snap, x.Positionals.SlotSpec.Name, opts)
 	if err != nil {
 		return err
 	}
 
+	chg, err := x.wait(id)
+	if err != nil {
+		if err == noWait {
+			return nil
+		}
+		return err
+	}
+	if !x.RestartAffected {
+		showServicesNeedingRestart(chg)
+	}
+	return nil
+}
+
+func (x *cmdConnect) waitIgnoringNoWait(id string) error {
 	if _, err := x.wait(id); err != nil {
 		if err == noWait {
 			return nil
@@ -91,3 +156,56 @@ func (x *cmdConnect) Execute(args []string) error {
 
 	return nil
 }
+
+// showServicesNeedingRestart lists the services which need a restart to see
+// the connection change made by the given change.
+func showServicesNeedingRestart(chg *client.Change) {
+	var services []string
+	if err := chg.Get("affected-services", &services); err != nil || len(services) == 0 {
+		return
+	}
+	// TRANSLATORS: %s is a comma-separated list of services
+	fmt.Fprintf(Stderr, i18n.G("Services %s need a restart to see the change, run 'snap restart --all-affected' to restart them.\n"), strings.Join(services, ", "))
+}
+
+type connectionsFile struct {
+	Connections []struct {
+		Plug string `yaml:"plug"`
+		Slot string `yaml:"slot"`
+	} `yaml:"connections"`
+}
+
+// readConnectionsFile reads the list of connections to make from the YAML
+// file at the given path.
+func readConnectionsFile(path string) ([]client.ConnectionRequest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cf connectionsFile
+	if err := yaml.UnmarshalStrict(data, &cf); err != nil {
+		return nil, fmt.Errorf(i18n.G("cannot parse connections file %q: %v"), path, err)
+	}
+	if len(cf.Connections) == 0 {
+		return nil, fmt.Errorf(i18n.G("cannot connect: no connections listed in %q"), path)
+	}
+
+	conns := make([]client.ConnectionRequest, 0, len(cf.Connections))
+	for i, entry := range cf.Connections {
+		var plug SnapAndName
+		if err := plug.UnmarshalFlag(entry.Plug); err != nil || plug.Snap == "" || plug.Name == "" {
+			return nil, fmt.Errorf(i18n.G("cannot parse connection #%d in %q: invalid plug %q (want snap:plug)"), i+1, path, entry.Plug)
+		}
+		var slot SnapAndName
+		if entry.Slot != "" {
+			if err := slot.UnmarshalFlag(entry.Slot); err != nil {
+				return nil, fmt.Errorf(i18n.G("cannot parse connection #%d in %q: %v"), i+1, path, err)
+			}
+		}
+		conns = append(conns, client.ConnectionRequest{
+			Plug: client.Plug{Snap: plug.Snap, Name: plug.Name},
+			Slot: client.Slot{Snap: slot.Snap, Name: slot.Name},
+		})
+	}
+	return conns, nil
+}
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"path/filepath"
 
 	"github.com/jessevdk/go-flags"
 	. "gopkg.in/check.v1"
@@ -33,7 +34,7 @@ import (
 
 func (s *SnapSuite) TestConnectHelp(c *C) {
 	msg := `Usage:
-  cli.test connect [connect-OPTIONS] <snap>:<plug> [<snap>:<slot>]
+  cli.test connect [connect-OPTIONS] [<snap>:<plug>] [<snap>:<slot>]
 
 The connect command connects a plug to a slot.
 It may be called in the following ways:
@@ -53,9 +54,27 @@ $ snap connect <snap>:<plug>
 Connects the provided plug to the slot in the core snap with a name matching
 the plug name.
 
+$ snap connect --file <connections.yaml>
+
+Connects all the plugs listed in the given file in a single change. Either all
+of the connections are made or, if any of them fails, none of them are. The
+file lists the connections as follows:
+
+connections:
+- {plug: <snap>:<plug>, slot: <snap>:<slot>}
+
+Some connections change the mount namespace of a snap, in which case its
+running services need a restart to reliably see the change. The
+--restart-affected option restarts them as part of the same change,
+otherwise they are listed and can be restarted later with
+'snap restart --all-affected'.
+
 [connect command options]
-      --no-wait          Do not wait for the operation to finish but just print
-                         the change id.
+      --no-wait             Do not wait for the operation to finish but just
+                            print the change id.
+      --file=               Connect all plugs listed in the given YAML file
+      --restart-affected    Restart the services which need it to see the new
+                            connection
 `
 	s.testSubCommandHelp(c, "connect", msg)
 }
@@ -94,6 +113,71 @@ func (s *SnapSuite) TestConnectExplicitEverything(c *C) {
 	c.Assert(rest, DeepEquals, []string{})
 }
 
+func (s *SnapSuite) TestConnectRestartAffected(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/interfaces":
+			c.Check(r.Method, Equals, "POST")
+			c.Check(DecodedRequestBody(c, r), DeepEquals, map[string]any{
+				"action": "connect",
+				"plugs": []any{
+					map[string]any{
+						"snap": "producer",
+						"plug": "plug",
+					},
+				},
+				"slots": []any{
+					map[string]any{
+						"snap": "consumer",
+						"slot": "slot",
+					},
+				},
+				"restart-affected": true,
+			})
+			w.WriteHeader(202)
+			fmt.Fprintln(w, `{"type":"async", "status-code": 202, "change": "zzz"}`)
+		case "/v2/changes/zzz":
+			c.Check(r.Method, Equals, "GET")
+			fmt.Fprintln(w, `{"type":"sync", "result":{"ready": true, "status": "Done", "data": {"affected-services": ["producer.svc"]}}}`)
+		default:
+			c.Fatalf("unexpected path %q", r.URL.Path)
+		}
+	})
+	rest, err := Parser(Client()).ParseArgs([]string{"connect", "--restart-affected", "producer:plug", "consumer:slot"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	// the services were restarted, nothing to hint about
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestConnectShowsServicesNeedingRestart(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/interfaces":
+			c.Check(r.Method, Equals, "POST")
+			w.WriteHeader(202)
+			fmt.Fprintln(w, `{"type":"async", "status-code": 202, "change": "zzz"}`)
+		case "/v2/changes/zzz":
+			c.Check(r.Method, Equals, "GET")
+			fmt.Fprintln(w, `{"type":"sync", "result":{"ready": true, "status": "Done", "data": {"affected-services": ["producer.svc1", "producer.svc2"]}}}`)
+		default:
+			c.Fatalf("unexpected path %q", r.URL.Path)
+		}
+	})
+	rest, err := Parser(Client()).ParseArgs([]string{"connect", "producer:plug", "consumer:slot"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Check(s.Stderr(), Equals, "Services producer.svc1, producer.svc2 need a restart to see the change, run 'snap restart --all-affected' to restart them.\n")
+}
+
+func (s *SnapSuite) TestConnectFileRestartAffected(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Fatalf("unexpected request %q", r.URL.Path)
+	})
+	_, err := Parser(Client()).ParseArgs([]string{"connect", "--restart-affected", "--file", "connections.yaml"})
+	c.Assert(err, ErrorMatches, `cannot use --file together with --restart-affected`)
+}
+
 func (s *SnapSuite) TestConnectExplicitPlugImplicitSlot(c *C) {
 	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
@@ -196,6 +280,82 @@ func (s *SnapSuite) TestConnectImplicitPlugImplicitSlot(c *C) {
 	c.Assert(rest, DeepEquals, []string{})
 }
 
+func (s *SnapSuite) TestConnectNoPlug(c *C) {
+	_, err := Parser(Client()).ParseArgs([]string{"connect"})
+	c.Assert(err, ErrorMatches, "the required argument `<snap>:<plug>` was not provided")
+}
+
+func (s *SnapSuite) TestConnectFile(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/interfaces":
+			c.Check(r.Method, Equals, "POST")
+			c.Check(DecodedRequestBody(c, r), DeepEquals, map[string]any{
+				"action": "connect-batch",
+				"plugs": []any{
+					map[string]any{"snap": "consumer", "plug": "plug"},
+					map[string]any{"snap": "consumer", "plug": "network"},
+					map[string]any{"snap": "consumer", "plug": "other"},
+				},
+				"slots": []any{
+					map[string]any{"snap": "producer", "slot": "slot"},
+					map[string]any{"snap": "", "slot": ""},
+					map[string]any{"snap": "producer", "slot": ""},
+				},
+			})
+			w.WriteHeader(202)
+			fmt.Fprintln(w, `{"type":"async", "status-code": 202, "change": "zzz"}`)
+		case "/v2/changes/zzz":
+			c.Check(r.Method, Equals, "GET")
+			fmt.Fprintln(w, `{"type":"sync", "result":{"ready": true, "status": "Done"}}`)
+		default:
+			c.Fatalf("unexpected path %q", r.URL.Path)
+		}
+	})
+	path := filepath.Join(c.MkDir(), "connections.yaml")
+	err := os.WriteFile(path, []byte(`connections:
+  - plug: consumer:plug
+    slot: producer:slot
+  - plug: consumer:network
+  - plug: consumer:other
+    slot: producer
+`), 0644)
+	c.Assert(err, IsNil)
+
+	rest, err := Parser(Client()).ParseArgs([]string{"connect", "--file", path})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+}
+
+func (s *SnapSuite) TestConnectFileErrors(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Fatalf("unexpected request to %q", r.URL.Path)
+	})
+	dir := c.MkDir()
+	for _, tc := range []struct {
+		content string
+		err     string
+	}{
+		{"", `cannot connect: no connections listed in ".*"`},
+		{"connections: []\n", `cannot connect: no connections listed in ".*"`},
+		{"connections:\n  - slot: producer:slot\n", `cannot parse connection #1 in ".*": invalid plug "" \(want snap:plug\)`},
+		{"connections:\n  - plug: consumer\n", `cannot parse connection #1 in ".*": invalid plug "consumer" \(want snap:plug\)`},
+		{"connections:\n  - plug: a:b\n  - plug: c:d\n    slot: \"e:f:g\"\n", `cannot parse connection #2 in ".*": invalid value: "e:f:g" \(want snap:name or snap\)`},
+		{"connections:\n  - plug: a:b\n    foo: bar\n", `(?s)cannot parse connections file ".*": .*field foo not found.*`},
+	} {
+		path := filepath.Join(dir, "connections.yaml")
+		c.Assert(os.WriteFile(path, []byte(tc.content), 0644), IsNil)
+		_, err := Parser(Client()).ParseArgs([]string{"connect", "--file", path})
+		c.Check(err, ErrorMatches, tc.err, Commentf("%q", tc.content))
+	}
+
+	_, err := Parser(Client()).ParseArgs([]string{"connect", "--file", filepath.Join(dir, "missing.yaml")})
+	c.Check(err, ErrorMatches, ".*no such file or directory")
+
+	_, err = Parser(Client()).ParseArgs([]string{"connect", "--file", filepath.Join(dir, "connections.yaml"), "a:b"})
+	c.Check(err, ErrorMatches, "cannot use --file together with a plug or slot")
+}
+
 var fortestingConnectionList = client.Connections{
 	Slots: []client.Slot{
 		{
@@ -29,12 +29,15 @@ import (
 
 	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/interfaces/compatibility"
 )
 
 type cmdConnections struct {
 	clientMixin
-	All         bool `long:"all"`
-	Positionals struct {
+	structuredOutputMixin
+	All                  bool `long:"all"`
+	CheckContentVersions bool `long:"check-content-versions"`
+	Positionals          struct {
 		Snap installedSnapName
 	} `positional-args:"true"`
 }
@@ -52,6 +55,11 @@ $ snap connections <snap>
 
 Lists connected and unconnected plugs and slots for the specified
 snap.
+
+With --check-content-versions, content interface connections whose plug
+and slot versions do not overlap anymore, for instance because the
+providing snap was refreshed to a new content layout, are noted as
+"version-mismatch" and the command fails.
 `)
 
 func init() {
@@ -59,6 +67,8 @@ func init() {
 		return &cmdConnections{}
 	}, map[string]string{
 		"all": i18n.G("Show connected and unconnected plugs and slots"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"check-content-versions": i18n.G("Check that the versions of connected content plugs and slots overlap"),
 	}, []argDesc{{
 		// TRANSLATORS: This needs to be wrapped in <>s.
 		name: "<snap>",
@@ -85,6 +95,7 @@ type connection struct {
 	interfaceDeterminant string
 	manual               bool
 	gadget               bool
+	versionMismatch      bool
 }
 
 func (cn connection) String() string {
@@ -95,12 +106,44 @@ func (cn connection) String() string {
 	if cn.gadget {
 		opts = append(opts, "gadget")
 	}
+	if cn.versionMismatch {
+		opts = append(opts, "version-mismatch")
+	}
 	if len(opts) == 0 {
 		return "-"
 	}
 	return strings.Join(opts, ",")
 }
 
+// connectionOutput is the structured output of the connections command
+// for each connection, or unconnected plug or slot.
+type connectionOutput struct {
+	Interface       string `json:"interface" yaml:"interface"`
+	Plug            string `json:"plug,omitempty" yaml:"plug,omitempty"`
+	Slot            string `json:"slot,omitempty" yaml:"slot,omitempty"`
+	Manual          bool   `json:"manual,omitempty" yaml:"manual,omitempty"`
+	Gadget          bool   `json:"gadget,omitempty" yaml:"gadget,omitempty"`
+	VersionMismatch bool   `json:"version-mismatch,omitempty" yaml:"version-mismatch,omitempty"`
+}
+
+func (cn connection) output() connectionOutput {
+	co := connectionOutput{
+		Interface:       cn.interfaceName,
+		Plug:            cn.plug,
+		Slot:            cn.slot,
+		Manual:          cn.manual,
+		Gadget:          cn.gadget,
+		VersionMismatch: cn.versionMismatch,
+	}
+	if co.Plug == "-" {
+		co.Plug = ""
+	}
+	if co.Slot == "-" {
+		co.Slot = ""
+	}
+	return co
+}
+
 type byConnectionData []connection
 
 func (b byConnectionData) Len() int      { return len(b) }
@@ -138,6 +181,18 @@ func interfaceDeterminant(conn *client.Connection) string {
 	return fmt.Sprintf("[%v]", value)
 }
 
+// contentVersionMismatch returns whether the content versions of the plug and
+// the slot of a content connection do not overlap.
+func contentVersionMismatch(conn *client.Connection) bool {
+	if conn.Interface != "content" {
+		return false
+	}
+	plugVersion, _ := conn.PlugAttrs["version"].(string)
+	slotVersion, _ := conn.SlotAttrs["version"].(string)
+	overlap, err := compatibility.VersionRangesOverlap(plugVersion, slotVersion)
+	return err != nil || !overlap
+}
+
 func (x *cmdConnections) Execute(args []string) error {
 	if len(args) > 0 {
 		return ErrExtraArgs
@@ -166,16 +221,25 @@ func (x *cmdConnections) Execute(args []string) error {
 		return err
 	}
 	if len(connections.Plugs) == 0 && len(connections.Slots) == 0 {
+		if x.structuredOutput() {
+			return x.writeStructuredOutput([]connectionOutput{})
+		}
 		return nil
 	}
 
+	mismatches := 0
 	annotatedConns := make([]connection, 0, len(connections.Established)+len(connections.Undesired))
 	for _, conn := range connections.Established {
+		versionMismatch := x.CheckContentVersions && contentVersionMismatch(&conn)
+		if versionMismatch {
+			mismatches++
+		}
 		annotatedConns = append(annotatedConns, connection{
 			plug:                 endpoint(conn.Plug.Snap, conn.Plug.Name),
 			slot:                 endpoint(conn.Slot.Snap, conn.Slot.Name),
 			manual:               conn.Manual,
 			gadget:               conn.Gadget,
+			versionMismatch:      versionMismatch,
 			interfaceName:        conn.Interface,
 			interfaceDeterminant: interfaceDeterminant(&conn),
 		})
@@ -210,6 +274,17 @@ func (x *cmdConnections) Execute(args []string) error {
 
 	sort.Sort(byConnectionData(annotatedConns))
 
+	if x.structuredOutput() {
+		out := make([]connectionOutput, 0, len(annotatedConns))
+		for _, cn := range annotatedConns {
+			out = append(out, cn.output())
+		}
+		if err := x.writeStructuredOutput(out); err != nil {
+			return err
+		}
+		return versionMismatchError(mismatches)
+	}
+
 	for _, note := range annotatedConns {
 		fmt.Fprintf(w, "%s%s\t%s\t%s\t%s\n", note.interfaceName, note.interfaceDeterminant, note.plug, note.slot, note)
 	}
@@ -217,5 +292,13 @@ func (x *cmdConnections) Execute(args []string) error {
 	if len(annotatedConns) > 0 {
 		w.Flush()
 	}
+	return versionMismatchError(mismatches)
+}
+
+func versionMismatchError(mismatches int) error {
+	if mismatches > 0 {
+		return fmt.Errorf(i18n.NG("%d content connection has plug and slot versions that do not overlap",
+			"%d content connections have plug and slot versions that do not overlap", mismatches), mismatches)
+	}
 	return nil
 }
@@ -30,6 +30,7 @@ import (
 
 	"github.com/snapcore/snapd/client"
 	. "github.com/snapcore/snapd/cmd/snapd/cli"
+	"github.com/snapcore/snapd/testutil"
 )
 
 func (s *SnapSuite) TestConnectionsNoneConnected(c *C) {
@@ -861,3 +862,66 @@ func (s *SnapSuite) testConnectionsDefiningAttribute(c *C, attr string) {
 	c.Assert(s.Stdout(), Equals, expectedStdout)
 	c.Assert(s.Stderr(), Equals, "")
 }
+
+func (s *SnapSuite) TestConnectionsCheckContentVersions(c *C) {
+	result := client.Connections{
+		Established: []client.Connection{
+			{
+				Plug:      client.PlugRef{Snap: "foo", Name: "a-plug"},
+				Slot:      client.SlotRef{Snap: "a-content-provider", Name: "data"},
+				Interface: "content",
+				PlugAttrs: map[string]any{"content": "a", "version": "2.x"},
+				SlotAttrs: map[string]any{"content": "a", "version": "2.3"},
+			}, {
+				Plug:      client.PlugRef{Snap: "foo", Name: "b-plug"},
+				Slot:      client.SlotRef{Snap: "b-content-provider", Name: "data"},
+				Interface: "content",
+				Manual:    true,
+				PlugAttrs: map[string]any{"content": "b", "version": "2.x"},
+				SlotAttrs: map[string]any{"content": "b", "version": "3.0"},
+			}, {
+				Plug:      client.PlugRef{Snap: "foo", Name: "c-plug"},
+				Slot:      client.SlotRef{Snap: "c-content-provider", Name: "data"},
+				Interface: "content",
+				// no version on the plug, any version is accepted
+				PlugAttrs: map[string]any{"content": "c"},
+				SlotAttrs: map[string]any{"content": "c", "version": "3.0"},
+			}, {
+				Plug:      client.PlugRef{Snap: "foo", Name: "network"},
+				Slot:      client.SlotRef{Snap: "core", Name: "network"},
+				Interface: "network",
+			},
+		},
+		Plugs: []client.Plug{
+			{Snap: "foo", Name: "a-plug", Interface: "content", Connections: []client.SlotRef{{Snap: "a-content-provider", Name: "data"}}},
+			{Snap: "foo", Name: "b-plug", Interface: "content", Connections: []client.SlotRef{{Snap: "b-content-provider", Name: "data"}}},
+			{Snap: "foo", Name: "c-plug", Interface: "content", Connections: []client.SlotRef{{Snap: "c-content-provider", Name: "data"}}},
+			{Snap: "foo", Name: "network", Interface: "network", Connections: []client.SlotRef{{Snap: "core", Name: "network"}}},
+		},
+	}
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/connections")
+		EncodeResponseBody(c, w, map[string]any{
+			"type":   "sync",
+			"result": result,
+		})
+	})
+
+	_, err := Parser(Client()).ParseArgs([]string{"connections", "--check-content-versions"})
+	c.Assert(err, ErrorMatches, "1 content connection has plug and slot versions that do not overlap")
+	expectedStdout := "" +
+		"Interface   Plug         Slot                     Notes\n" +
+		"content[a]  foo:a-plug   a-content-provider:data  -\n" +
+		"content[b]  foo:b-plug   b-content-provider:data  manual,version-mismatch\n" +
+		"content[c]  foo:c-plug   c-content-provider:data  -\n" +
+		"network     foo:network  :network                 -\n"
+	c.Check(s.Stdout(), Equals, expectedStdout)
+	c.Check(s.Stderr(), Equals, "")
+
+	// versions are not checked by default
+	s.ResetStdStreams()
+	_, err = Parser(Client()).ParseArgs([]string{"connections"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), testutil.Contains, "content[b]  foo:b-plug   b-content-provider:data  manual\n")
+}
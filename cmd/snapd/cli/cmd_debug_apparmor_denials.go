@@ -0,0 +1,220 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/interfaces/denials"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snapdtool"
+)
+
+var shortDebugAppArmorDenialsHelp = i18n.G("Show the AppArmor and seccomp denials of a snap")
+
+var longDebugAppArmorDenialsHelp = i18n.G(`
+The debug apparmor-denials command shows the accesses recently denied by
+AppArmor and seccomp to the applications and hooks of the given snap, as
+logged by the kernel in the journal, or in the given log file.
+
+With --suggest, the interfaces whose connection would grant each denied
+access are looked up among the interfaces known to snap, and the commands
+connecting them are suggested. Interfaces that need attributes to grant
+anything are not considered.
+
+The command may require root privileges to read the log.
+`)
+
+type cmdDebugAppArmorDenials struct {
+	clientMixin
+	Since   string `long:"since" default:"-1h"`
+	File    string `long:"file"`
+	Suggest bool   `long:"suggest"`
+
+	Positional struct {
+		Snap string `positional-arg-name:"<snap>" required:"yes"`
+	} `positional-args:"yes"`
+}
+
+func init() {
+	addDebugCommand("apparmor-denials",
+		shortDebugAppArmorDenialsHelp,
+		longDebugAppArmorDenialsHelp,
+		func() flags.Commander { return &cmdDebugAppArmorDenials{} },
+		map[string]string{
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"since": i18n.G("Show denials logged since the given time, as understood by journalctl"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"file": i18n.G("Read the denials from the given log file instead of the journal"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"suggest": i18n.G("Suggest interfaces granting the denied accesses"),
+		},
+		[]argDesc{
+			{"<snap>", "Snap name"},
+		},
+	)
+}
+
+// readKernelLog returns the messages logged by the kernel, and the audit
+// messages, since the given time.
+var readKernelLog = func(since string) (io.Reader, error) {
+	stdout, stderr, err := osutil.RunSplitOutput("journalctl", "--no-pager", "--output=cat", "--since="+since,
+		"_TRANSPORT=kernel", "+", "_TRANSPORT=audit")
+	if err != nil {
+		return nil, fmt.Errorf(i18n.G("cannot read the journal: %v"), osutil.OutputErrCombine(stdout, stderr, err))
+	}
+	return bytes.NewReader(stdout), nil
+}
+
+// resolveSyscall returns the name of a system call, as identified in the
+// audit record of a seccomp denial.
+var resolveSyscall = func(arch, nr string) (string, error) {
+	tool, err := snapdtool.InternalToolPath("snap-seccomp")
+	if err != nil {
+		return "", err
+	}
+	stdout, stderr, err := osutil.RunSplitOutput(tool, "resolve-syscall", arch, nr)
+	if err != nil {
+		return "", osutil.OutputErrCombine(stdout, stderr, err)
+	}
+	return strings.TrimSpace(string(stdout)), nil
+}
+
+func (x *cmdDebugAppArmorDenials) readDenials(snapName string) ([]*denials.Denial, error) {
+	var r io.Reader
+	if x.File != "" {
+		f, err := os.Open(x.File)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	} else {
+		var err error
+		r, err = readKernelLog(x.Since)
+		if err != nil {
+			return nil, err
+		}
+	}
+	ds, err := denials.Parse(r, snapName)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.G("cannot read denials: %v"), err)
+	}
+	resolveSyscallNames(ds)
+	return ds, nil
+}
+
+// resolveSyscallNames sets the name of the system calls denied by seccomp,
+// when they can be resolved.
+func resolveSyscallNames(ds []*denials.Denial) {
+	for _, d := range ds {
+		if d.Kind != denials.Syscall {
+			continue
+		}
+		// without the name the denial is still shown
+		if name, err := resolveSyscall(d.Arch, d.SyscallNumber); err == nil {
+			d.SyscallName = name
+		}
+	}
+}
+
+func (x *cmdDebugAppArmorDenials) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	snapName := x.Positional.Snap
+	if err := snap.ValidateInstanceName(snapName); err != nil {
+		return err
+	}
+
+	ds, err := x.readDenials(snapName)
+	if err != nil {
+		return err
+	}
+	if len(ds) == 0 {
+		fmt.Fprintf(Stdout, i18n.G("No denials of snap %q found.\n"), snapName)
+		return nil
+	}
+
+	if !x.Suggest {
+		for _, d := range ds {
+			printDenial(Stdout, snapName, d)
+		}
+		return nil
+	}
+	return printDenialSuggestions(Stdout, x.client, snapName, ds)
+}
+
+// printDenialSuggestions prints the denials of the given snap, each followed
+// by the interfaces granting the denied access and how to get them
+// connected.
+func printDenialSuggestions(w io.Writer, cli *client.Client, snapName string, ds []*denials.Denial) error {
+	// plugs of the snap by interface
+	conns, err := cli.Connections(&client.ConnectionOptions{Snap: snapName, All: true})
+	if err != nil {
+		return err
+	}
+	plugs := make(map[string][]client.Plug)
+	for _, plug := range conns.Plugs {
+		if plug.Snap == snapName {
+			plugs[plug.Interface] = append(plugs[plug.Interface], plug)
+		}
+	}
+
+	for _, s := range denials.Suggest(snapName, ds) {
+		printDenial(w, snapName, s.Denial)
+		if len(s.Interfaces) == 0 {
+			fmt.Fprintf(w, "  %s\n", i18n.G("no interface grants this access"))
+		}
+		for _, iface := range s.Interfaces {
+			if len(plugs[iface]) == 0 {
+				fmt.Fprintf(w, "  "+i18n.G("add a plug of the %s interface to the snap")+"\n", iface)
+				continue
+			}
+			for _, plug := range plugs[iface] {
+				if len(plug.Connections) > 0 {
+					fmt.Fprintf(w, "  "+i18n.G("plug %s:%s is already connected")+"\n", snapName, plug.Name)
+					continue
+				}
+				fmt.Fprintf(w, "  snap connect %s:%s\n", snapName, plug.Name)
+			}
+		}
+	}
+	return nil
+}
+
+func printDenial(w io.Writer, snapName string, d *denials.Denial) {
+	who := d.Label
+	if who == "" {
+		who = fmt.Sprintf(i18n.G("snap %s"), snapName)
+	}
+	count := fmt.Sprintf(i18n.NG("%d time", "%d times", d.Count), d.Count)
+	fmt.Fprintf(w, "%s: %s, %s\n", who, d, count)
+}
@@ -0,0 +1,145 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli_test
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	snap "github.com/snapcore/snapd/cmd/snapd/cli"
+	"github.com/snapcore/snapd/testutil"
+)
+
+const denialsLog = `audit: type=1400 audit(1760616000.123:100): apparmor="DENIED" operation="open" class="file" profile="snap.foo.app" name="/etc/shadow" pid=1234 comm="app" requested_mask="r" denied_mask="r" fsuid=1000 ouid=0
+audit: type=1400 audit(1760616001.123:101): apparmor="DENIED" operation="open" class="file" profile="snap.foo.app" name="/etc/shadow" pid=1234 comm="app" requested_mask="r" denied_mask="r" fsuid=1000 ouid=0
+audit: type=1400 audit(1760616002.123:102): apparmor="DENIED" operation="capable" class="cap" profile="snap.foo.app" pid=1234 comm="app" capability=25 capname="sys_time"
+audit: type=1400 audit(1760616003.123:103): apparmor="DENIED" operation="capable" class="cap" profile="snap.bar.app" pid=1235 comm="app" capability=25 capname="sys_time"
+audit: type=1326 audit(1760616004.123:104): auid=1000 uid=1000 gid=1000 ses=2 subj=? pid=1234 comm="app" exe="/snap/foo/x1/bin/app" sig=0 arch=c000003e syscall=164 compat=0 ip=0x7f0000000000 code=0x50000
+audit: type=1326 audit(1760616005.123:105): auid=1000 uid=1000 gid=1000 ses=2 subj=? pid=1234 comm="app" exe="/snap/foo/x1/bin/app" sig=0 arch=c000003e syscall=9999 compat=0 ip=0x7f0000000000 code=0x50000
+`
+
+func (s *SnapSuite) mockDenialsJournal(c *C) *testutil.MockCmd {
+	logPath := filepath.Join(c.MkDir(), "log")
+	c.Assert(os.WriteFile(logPath, []byte(denialsLog), 0644), IsNil)
+	journalctl := testutil.MockCommand(c, "journalctl", fmt.Sprintf("cat %s", logPath))
+	s.AddCleanup(journalctl.Restore)
+	s.AddCleanup(snap.MockResolveSyscall(func(arch, nr string) (string, error) {
+		c.Check(arch, Equals, "c000003e")
+		if nr == "164" {
+			return "settimeofday", nil
+		}
+		return "", fmt.Errorf("unknown system call")
+	}))
+	return journalctl
+}
+
+func (s *SnapSuite) TestDebugAppArmorDenials(c *C) {
+	journalctl := s.mockDenialsJournal(c)
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "apparmor-denials", "foo"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Check(s.Stdout(), Equals, `snap.foo.app: file /etc/shadow (r), 2 times
+snap.foo.app: capability sys_time, 1 time
+snap foo: syscall settimeofday, 1 time
+snap foo: syscall 9999 (arch c000003e), 1 time
+`)
+	c.Check(s.Stderr(), Equals, "")
+	c.Check(journalctl.Calls(), DeepEquals, [][]string{
+		{"journalctl", "--no-pager", "--output=cat", "--since=-1h", "_TRANSPORT=kernel", "+", "_TRANSPORT=audit"},
+	})
+}
+
+func (s *SnapSuite) TestDebugAppArmorDenialsSince(c *C) {
+	journalctl := s.mockDenialsJournal(c)
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "apparmor-denials", "--since=today", "foo"})
+	c.Assert(err, IsNil)
+	c.Check(journalctl.Calls(), DeepEquals, [][]string{
+		{"journalctl", "--no-pager", "--output=cat", "--since=today", "_TRANSPORT=kernel", "+", "_TRANSPORT=audit"},
+	})
+}
+
+func (s *SnapSuite) TestDebugAppArmorDenialsFile(c *C) {
+	journalctl := s.mockDenialsJournal(c)
+	logPath := filepath.Join(c.MkDir(), "audit.log")
+	c.Assert(os.WriteFile(logPath, []byte(`type=AVC msg=audit(1760616000.123:100): apparmor="DENIED" operation="open" class="file" profile="snap.foo.app" name="/etc/gshadow" pid=1234 comm="app" requested_mask="r" denied_mask="r" fsuid=1000 ouid=0
+`), 0644), IsNil)
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "apparmor-denials", "--file", logPath, "foo"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, "snap.foo.app: file /etc/gshadow (r), 1 time\n")
+	c.Check(journalctl.Calls(), HasLen, 0)
+}
+
+func (s *SnapSuite) TestDebugAppArmorDenialsNone(c *C) {
+	s.mockDenialsJournal(c)
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "apparmor-denials", "baz"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, "No denials of snap \"baz\" found.\n")
+}
+
+func (s *SnapSuite) TestDebugAppArmorDenialsJournalError(c *C) {
+	journalctl := testutil.MockCommand(c, "journalctl", "echo 'No journal files were found.' >&2; exit 1")
+	defer journalctl.Restore()
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "apparmor-denials", "foo"})
+	c.Assert(err, ErrorMatches, `(?s)cannot read the journal: \n-----\nstderr:\nNo journal files were found\.\n-----`)
+}
+
+func (s *SnapSuite) TestDebugAppArmorDenialsInvalidSnapName(c *C) {
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "apparmor-denials", "Foo!"})
+	c.Assert(err, ErrorMatches, `invalid snap name: "Foo!"`)
+}
+
+func (s *SnapSuite) TestDebugAppArmorDenialsSuggest(c *C) {
+	s.mockDenialsJournal(c)
+	logPath := filepath.Join(c.MkDir(), "audit.log")
+	c.Assert(os.WriteFile(logPath, []byte(`type=AVC msg=audit(1760616000.123:100): apparmor="DENIED" operation="open" class="file" profile="snap.foo.app" name="/var/lib/extrausers/shadow" pid=1234 comm="app" requested_mask="r" denied_mask="r" fsuid=0 ouid=0
+type=AVC msg=audit(1760616002.123:102): apparmor="DENIED" operation="capable" class="cap" profile="snap.foo.app" pid=1234 comm="app" capability=25 capname="sys_time"
+type=SECCOMP msg=audit(1760616005.123:105): auid=1000 uid=1000 gid=1000 ses=2 subj=? pid=1234 comm="app" exe="/snap/foo/x1/bin/app" sig=0 arch=c000003e syscall=9999 compat=0 ip=0x7f0000000000 code=0x50000
+`), 0644), IsNil)
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/connections")
+		c.Check(r.URL.Query().Get("snap"), Equals, "foo")
+		c.Check(r.URL.Query().Get("select"), Equals, "all")
+		fmt.Fprintln(w, `{"type": "sync", "result": {
+"plugs": [
+  {"snap": "foo", "plug": "time-control", "interface": "time-control"},
+  {"snap": "foo", "plug": "account-control", "interface": "account-control", "connections": [{"snap": "core", "slot": "account-control"}]}
+]}}`)
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "apparmor-denials", "--suggest", "--file", logPath, "foo"})
+	c.Assert(err, IsNil)
+	out := s.Stdout()
+	c.Check(out, testutil.Contains, "snap.foo.app: file /var/lib/extrausers/shadow (r), 1 time\n")
+	c.Check(out, testutil.Contains, "  plug foo:account-control is already connected\n")
+	c.Check(out, testutil.Contains, "snap.foo.app: capability sys_time, 1 time\n")
+	c.Check(out, testutil.Contains, "  snap connect foo:time-control\n")
+	c.Check(out, testutil.Contains, "  add a plug of the rtc-control interface to the snap\n")
+	c.Check(out, testutil.Contains, "snap foo: syscall 9999 (arch c000003e), 1 time\n  no interface grants this access\n")
+}
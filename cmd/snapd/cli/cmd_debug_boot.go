@@ -0,0 +1,147 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jessevdk/go-flags"
+)
+
+type cmdDebugBoot struct {
+	clientMixin
+	timeMixin
+	formatMixin
+}
+
+func init() {
+	addDebugCommand("boot",
+		"(internal) show the boot chains the disk encryption keys are sealed to",
+		"(internal) show the boot chains the disk encryption keys are sealed to",
+		func() flags.Commander {
+			return &cmdDebugBoot{}
+		}, timeDescs.also(formatArgsHelp), nil)
+}
+
+type debugBootAsset struct {
+	Role   string   `json:"role"`
+	Name   string   `json:"name"`
+	Hashes []string `json:"hashes"`
+}
+
+type debugBootChain struct {
+	BrandID        string           `json:"brand-id"`
+	Model          string           `json:"model"`
+	Grade          string           `json:"grade"`
+	ModelSignKeyID string           `json:"model-sign-key-id"`
+	AssetChain     []debugBootAsset `json:"asset-chain"`
+	Kernel         string           `json:"kernel"`
+	KernelRevision string           `json:"kernel-revision"`
+	KernelCmdlines []string         `json:"kernel-cmdlines"`
+}
+
+type debugSealedBootChains struct {
+	BootChains  []debugBootChain `json:"boot-chains"`
+	ResealCount int              `json:"reseal-count"`
+}
+
+type debugBootStatus struct {
+	SealingMethod      string                `json:"sealing-method"`
+	RunBootChains      debugSealedBootChains `json:"run-boot-chains"`
+	RecoveryBootChains debugSealedBootChains `json:"recovery-boot-chains"`
+	LastReseal         *struct {
+		Time  time.Time `json:"time"`
+		Error string    `json:"error,omitempty"`
+	} `json:"last-reseal,omitempty"`
+	PendingResealReasons []string `json:"pending-reseal-reasons,omitempty"`
+}
+
+func (x *cmdDebugBoot) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	var resp debugBootStatus
+	if err := x.client.DebugGet("boot", &resp, nil); err != nil {
+		return err
+	}
+
+	if x.Format != "text" && x.Format != "" {
+		return x.formatNonText(resp)
+	}
+
+	w := Stdout
+	method := resp.SealingMethod
+	if method == "" {
+		// keys sealed before the method was recorded
+		method = "tpm (legacy)"
+	}
+	fmt.Fprintf(w, "sealing-method:  %s\n", method)
+	if resp.LastReseal != nil {
+		fmt.Fprintf(w, "last-reseal:     %s\n", x.fmtTime(resp.LastReseal.Time))
+		if resp.LastReseal.Error != "" {
+			fmt.Fprintf(w, "last-reseal-error: |\n")
+			for _, line := range strings.Split(resp.LastReseal.Error, "\n") {
+				fmt.Fprintf(w, "  %s\n", line)
+			}
+		}
+	}
+	fmt.Fprintf(w, "reseal-pending:  %t\n", len(resp.PendingResealReasons) > 0)
+	if len(resp.PendingResealReasons) > 0 {
+		fmt.Fprintf(w, "reseal-pending-reasons:\n")
+		for _, reason := range resp.PendingResealReasons {
+			fmt.Fprintf(w, "  - %s\n", reason)
+		}
+	}
+	fmtDebugBootChains(w, "run-boot-chains", resp.RunBootChains)
+	fmtDebugBootChains(w, "recovery-boot-chains", resp.RecoveryBootChains)
+
+	return nil
+}
+
+func fmtDebugBootChains(w io.Writer, key string, chains debugSealedBootChains) {
+	fmt.Fprintf(w, "%s:\n", key)
+	fmt.Fprintf(w, "  reseal-count:  %d\n", chains.ResealCount)
+	if len(chains.BootChains) == 0 {
+		fmt.Fprintf(w, "  chains:        []\n")
+		return
+	}
+	fmt.Fprintf(w, "  chains:\n")
+	for _, bc := range chains.BootChains {
+		fmt.Fprintf(w, "    - model:   %s/%s\n", bc.BrandID, bc.Model)
+		fmt.Fprintf(w, "      grade:   %s\n", bc.Grade)
+		fmt.Fprintf(w, "      kernel:  %s (%s)\n", bc.Kernel, bc.KernelRevision)
+		if len(bc.AssetChain) > 0 {
+			fmt.Fprintf(w, "      assets:\n")
+			for _, asset := range bc.AssetChain {
+				fmt.Fprintf(w, "        - %s/%s:  %s\n", asset.Role, asset.Name, strings.Join(asset.Hashes, ", "))
+			}
+		}
+		if len(bc.KernelCmdlines) > 0 {
+			fmt.Fprintf(w, "      cmdlines:\n")
+			for _, cmdline := range bc.KernelCmdlines {
+				fmt.Fprintf(w, "        - %s\n", cmdline)
+			}
+		}
+	}
+}
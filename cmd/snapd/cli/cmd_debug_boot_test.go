@@ -0,0 +1,149 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli_test
+
+import (
+	"fmt"
+	"net/http"
+
+	. "gopkg.in/check.v1"
+
+	snap "github.com/snapcore/snapd/cmd/snapd/cli"
+)
+
+var debugBootStatusJSON = `{
+  "type": "sync",
+  "status-code": 200,
+  "result": {
+    "sealing-method": "tpm",
+    "run-boot-chains": {
+      "reseal-count": 3,
+      "boot-chains": [
+        {
+          "brand-id": "my-brand",
+          "model": "my-model",
+          "grade": "signed",
+          "model-sign-key-id": "my-key-id",
+          "asset-chain": [
+            {"role": "recovery", "name": "shim", "hashes": ["x"]},
+            {"role": "run-mode", "name": "grubx64.efi", "hashes": ["y", "z"]}
+          ],
+          "kernel": "pc-kernel",
+          "kernel-revision": "1",
+          "kernel-cmdlines": ["snapd_recovery_mode=run"]
+        }
+      ]
+    },
+    "recovery-boot-chains": {
+      "reseal-count": 1,
+      "boot-chains": null
+    },
+    "last-reseal": {
+      "time": "2026-10-16T10:00:00Z",
+      "error": "cannot reseal:\nboom"
+    },
+    "pending-reseal-reasons": ["last reseal failed"]
+  }
+}`
+
+func (s *SnapSuite) mockDebugBootServer(c *C, resp string) *int {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		n++
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/debug")
+		c.Check(r.URL.RawQuery, Equals, "aspect=boot")
+		fmt.Fprintln(w, resp)
+	})
+	return &n
+}
+
+func (s *SnapSuite) TestDebugBoot(c *C) {
+	n := s.mockDebugBootServer(c, debugBootStatusJSON)
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "boot", "--abs-time"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Check(*n, Equals, 1)
+	c.Check(s.Stdout(), Equals, `
+sealing-method:  tpm
+last-reseal:     2026-10-16T10:00:00Z
+last-reseal-error: |
+  cannot reseal:
+  boom
+reseal-pending:  true
+reseal-pending-reasons:
+  - last reseal failed
+run-boot-chains:
+  reseal-count:  3
+  chains:
+    - model:   my-brand/my-model
+      grade:   signed
+      kernel:  pc-kernel (1)
+      assets:
+        - recovery/shim:  x
+        - run-mode/grubx64.efi:  y, z
+      cmdlines:
+        - snapd_recovery_mode=run
+recovery-boot-chains:
+  reseal-count:  1
+  chains:        []
+`[1:])
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestDebugBootFDESetupHook(c *C) {
+	s.mockDebugBootServer(c, `{"type": "sync", "status-code": 200, "result": {
+  "sealing-method": "fde-setup-hook",
+  "run-boot-chains": {"reseal-count": 0, "boot-chains": null},
+  "recovery-boot-chains": {"reseal-count": 0, "boot-chains": null}
+}}`)
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "boot"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, `
+sealing-method:  fde-setup-hook
+reseal-pending:  false
+run-boot-chains:
+  reseal-count:  0
+  chains:        []
+recovery-boot-chains:
+  reseal-count:  0
+  chains:        []
+`[1:])
+}
+
+func (s *SnapSuite) TestDebugBootJSON(c *C) {
+	s.mockDebugBootServer(c, debugBootStatusJSON)
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "boot", "--format=json"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Matches, `\{"sealing-method":"tpm","run-boot-chains":\{"boot-chains":\[\{"brand-id":"my-brand",.*\],"reseal-count":3\},.*"pending-reseal-reasons":\["last reseal failed"\]\}\n`)
+}
+
+func (s *SnapSuite) TestDebugBootError(c *C) {
+	s.mockDebugBootServer(c, `{"type": "error", "status-code": 400, "result": {
+  "message": "cannot report boot status: system has no sealed disk encryption keys"
+}}`)
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "boot"})
+	c.Assert(err, ErrorMatches, "cannot report boot status: system has no sealed disk encryption keys")
+	c.Check(s.Stdout(), Equals, "")
+}
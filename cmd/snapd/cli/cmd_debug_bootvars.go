@@ -30,8 +30,11 @@ import (
 )
 
 type cmdBootvarsGet struct {
-	UC20    bool   `long:"uc20"`
-	RootDir string `long:"root-dir"`
+	UC20      bool     `long:"uc20"`
+	RootDir   string   `long:"root-dir"`
+	Recovery  bool     `long:"recovery"`
+	Set       []string `long:"set" value-name:"<var>=<value>"`
+	Dangerous bool     `long:"dangerous"`
 }
 
 type cmdBootvarsSet struct {
@@ -49,8 +52,11 @@ func init() {
 		func() flags.Commander {
 			return &cmdBootvarsGet{}
 		}, map[string]string{
-			"uc20":     i18n.G("Whether to use UC20+ boot vars or not"),
-			"root-dir": i18n.G("Root directory to look for boot variables in"),
+			"uc20":      i18n.G("Whether to use UC20+ boot vars or not"),
+			"root-dir":  i18n.G("Root directory to look for boot variables in"),
+			"recovery":  i18n.G("Set boot variables of the recovery bootloader (implies UC20+)"),
+			"set":       i18n.G("Set a boot variable known to snapd for the bootloader (can be repeated)"),
+			"dangerous": i18n.G("Acknowledge that setting boot variables may leave the system unbootable"),
 		}, nil)
 
 	cmdSet := addDebugCommand("set-boot-vars",
@@ -73,7 +79,16 @@ func (x *cmdBootvarsGet) Execute(args []string) error {
 	if release.OnClassic {
 		return errors.New(`the "boot-vars" command is not available on classic systems`)
 	}
-	return boot.DebugDumpBootVars(Stdout, x.RootDir, x.UC20)
+	if len(x.Set) == 0 {
+		if x.Recovery || x.Dangerous {
+			return errors.New("--recovery and --dangerous can only be used with --set")
+		}
+		return boot.DebugDumpBootVars(Stdout, x.RootDir, x.UC20)
+	}
+	if !x.Dangerous {
+		return errors.New("cannot set boot variables without --dangerous, incorrect boot variables may leave the system unbootable")
+	}
+	return boot.DebugSetKnownBootVars(x.RootDir, x.UC20, x.Recovery, x.Set)
 }
 
 func (x *cmdBootvarsSet) Execute(args []string) error {
@@ -155,3 +155,101 @@ snapd_full_cmdline_args=
 	_, err = snap.Parser(snap.Client()).ParseArgs([]string{"debug", "set-boot-vars", "--recovery", "--root-dir", boot.InitramfsUbuntuBootDir, "foo=recovery"})
 	c.Assert(err, check.ErrorMatches, "cannot use run bootloader root-dir with a recovery flag")
 }
+
+func (s *SnapSuite) TestDebugBootvarsSetKnown(c *check.C) {
+	restore := release.MockOnClassic(false)
+	defer restore()
+	bloader := bootloadertest.Mock("grub", c.MkDir())
+	bootloader.Force(bloader)
+	err := bloader.SetBootVars(map[string]string{
+		"snap_mode":   "try",
+		"snap_kernel": "pc-kernel_3.snap",
+	})
+	c.Assert(err, check.IsNil)
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "boot-vars", "--dangerous",
+		"--set", "snap_mode=", "--set", "snap_try_kernel=pc-kernel_4.snap"})
+	c.Assert(err, check.IsNil)
+	c.Check(rest, check.HasLen, 0)
+	c.Check(bloader.BootVars, check.DeepEquals, map[string]string{
+		"snap_mode":       "",
+		"snap_kernel":     "pc-kernel_3.snap",
+		"snap_try_kernel": "pc-kernel_4.snap",
+	})
+	c.Check(s.Stdout(), check.Equals, "")
+	c.Check(s.Stderr(), check.Equals, "")
+}
+
+func (s *SnapSuite) TestDebugBootvarsSetKnownRoles(c *check.C) {
+	restore := release.MockOnClassic(false)
+	defer restore()
+
+	for _, tc := range []struct {
+		args []string
+		set  string
+	}{
+		{[]string{"--uc20"}, "kernel_status=try"},
+		{[]string{"--uc20"}, "snapd_full_cmdline_args=console=ttyS0"},
+		{[]string{"--root-dir", boot.InitramfsUbuntuBootDir}, "kernel_status=try"},
+		{[]string{"--recovery"}, "try_recovery_system=1234"},
+		{[]string{"--root-dir", boot.InitramfsUbuntuSeedDir}, "snapd_recovery_kernel=/snaps/pc-kernel_1.snap"},
+	} {
+		bloader := bootloadertest.Mock("grub", c.MkDir())
+		bootloader.Force(bloader)
+
+		args := append([]string{"debug", "boot-vars", "--dangerous", "--set", tc.set}, tc.args...)
+		_, err := snap.Parser(snap.Client()).ParseArgs(args)
+		c.Assert(err, check.IsNil, check.Commentf("%v", args))
+		c.Check(bloader.BootVars, check.HasLen, 1, check.Commentf("%v", args))
+	}
+}
+
+func (s *SnapSuite) TestDebugBootvarsSetUnknownVariable(c *check.C) {
+	restore := release.MockOnClassic(false)
+	defer restore()
+	bloader := bootloadertest.Mock("lk", c.MkDir())
+	bootloader.Force(bloader)
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "boot-vars", "--dangerous", "--uc20",
+		"--set", "snap_kernel=pc-kernel_3.snap", "--set", "snapd_full_cmdline_args=quiet", "--set", "foo=bar"})
+	c.Assert(err, check.ErrorMatches, `cannot set boot variables "foo", "snapd_full_cmdline_args" for bootloader "lk": allowed variables are "kernel_status", "snap_kernel", "snap_try_kernel", "snap_gadget", "snap_try_gadget"`)
+	// nothing was set
+	c.Check(bloader.BootVars, check.HasLen, 0)
+}
+
+func (s *SnapSuite) TestDebugBootvarsSetUnsupportedBootloader(c *check.C) {
+	restore := release.MockOnClassic(false)
+	defer restore()
+	bloader := bootloadertest.Mock("mock", c.MkDir())
+	bootloader.Force(bloader)
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "boot-vars", "--dangerous", "--set", "snap_mode=try"})
+	c.Assert(err, check.ErrorMatches, `cannot set boot variables of bootloader "mock"`)
+
+	bloader = bootloadertest.Mock("androidboot", c.MkDir())
+	bootloader.Force(bloader)
+	_, err = snap.Parser(snap.Client()).ParseArgs([]string{"debug", "boot-vars", "--dangerous", "--uc20", "--set", "snap_kernel=pc-kernel_3.snap"})
+	c.Assert(err, check.ErrorMatches, `cannot set boot variables of bootloader "androidboot" with role "run-mode"`)
+}
+
+func (s *SnapSuite) TestDebugBootvarsSetErrors(c *check.C) {
+	restore := release.MockOnClassic(false)
+	defer restore()
+	bloader := bootloadertest.Mock("grub", c.MkDir())
+	bootloader.Force(bloader)
+
+	for _, tc := range []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"--set", "snap_mode=try"}, "cannot set boot variables without --dangerous, incorrect boot variables may leave the system unbootable"},
+		{[]string{"--dangerous"}, "--recovery and --dangerous can only be used with --set"},
+		{[]string{"--recovery"}, "--recovery and --dangerous can only be used with --set"},
+		{[]string{"--dangerous", "--set", "snap_mode"}, `incorrect setting \["snap_mode"\]`},
+		{[]string{"--dangerous", "--recovery", "--root-dir", boot.InitramfsUbuntuBootDir, "--set", "snap_mode=try"}, "cannot use run bootloader root-dir with a recovery flag"},
+	} {
+		_, err := snap.Parser(snap.Client()).ParseArgs(append([]string{"debug", "boot-vars"}, tc.args...))
+		c.Check(err, check.ErrorMatches, tc.err, check.Commentf("%v", tc.args))
+	}
+	c.Check(bloader.BootVars, check.HasLen, 0)
+}
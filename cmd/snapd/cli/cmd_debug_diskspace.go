@@ -0,0 +1,122 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/strutil"
+)
+
+type cmdDebugDiskSpace struct {
+	clientMixin
+
+	Simulate string `long:"simulate" required:"yes" choice:"install" choice:"refresh" choice:"remove"`
+
+	Positional struct {
+		Snaps []string `positional-arg-name:"<snap>"`
+	} `positional-args:"yes"`
+}
+
+var shortDebugDiskSpaceHelp = i18n.G("Estimate the disk space needed by an operation")
+var longDebugDiskSpaceHelp = i18n.G(`
+The diskspace command simulates installing, refreshing or removing the given
+snaps and reports, for each affected filesystem, the space that would be needed
+for downloading snaps, copying snap data and taking automatic snapshots, along
+with the space currently available. It also reports whether snapd would refuse
+to perform the operation because of insufficient disk space.
+
+Refreshing without naming any snaps simulates refreshing all snaps with
+available updates. Nothing is changed on the system.
+`)
+
+func init() {
+	addDebugCommand("diskspace",
+		shortDebugDiskSpaceHelp,
+		longDebugDiskSpaceHelp,
+		func() flags.Commander {
+			return &cmdDebugDiskSpace{}
+		}, map[string]string{
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"simulate": i18n.G("Operation to simulate (install, refresh or remove)"),
+		}, []argDesc{{
+			// TRANSLATORS: This needs to begin with < and end with >
+			name: i18n.G("<snap>"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			desc: i18n.G("Snaps to simulate the operation for"),
+		}})
+}
+
+func (x *cmdDebugDiskSpace) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+	snaps := x.Positional.Snaps
+	if len(snaps) == 0 && x.Simulate != "refresh" {
+		return fmt.Errorf(i18n.G("cannot simulate %s without snap names"), x.Simulate)
+	}
+
+	est, err := x.client.SimulateDiskSpace(x.Simulate, snaps)
+	if err != nil {
+		return err
+	}
+
+	if len(est.Snaps) == 0 {
+		fmt.Fprintf(Stdout, i18n.G("No snaps to %s.\n"), est.Operation)
+		return nil
+	}
+	fmt.Fprintf(Stdout, i18n.G("Simulating %s of: %s\n\n"), est.Operation, strings.Join(est.Snaps, ", "))
+
+	w := tabWriter()
+	fmt.Fprintln(w, i18n.G("Filesystem\tSnaps\tData copies\tSnapshots\tRequired\tAvailable\tNotes"))
+	for _, fs := range est.Filesystems {
+		notes := "-"
+		if fs.Available < fs.Required {
+			// TRANSLATORS: note shown for a filesystem without enough free space
+			notes = i18n.G("insufficient-space")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", fs.Path,
+			diskSpaceSize(fs.Snaps), diskSpaceSize(fs.DataCopies), diskSpaceSize(fs.Snapshots),
+			diskSpaceSize(fs.Required), diskSpaceSize(fs.Available), notes)
+	}
+	w.Flush()
+	fmt.Fprintln(Stdout)
+
+	switch {
+	case !est.CheckEnabled:
+		fmt.Fprintf(Stdout, i18n.G("snapd does not check disk space before %s (see experimental.check-disk-space-%s).\n"), est.Operation, est.Operation)
+	case est.Refused:
+		fmt.Fprintf(Stdout, i18n.G("snapd would refuse the operation: %s\n"), est.Error)
+	default:
+		fmt.Fprintln(Stdout, i18n.G("snapd would allow the operation."))
+	}
+	return nil
+}
+
+func diskSpaceSize(size uint64) string {
+	if size == 0 {
+		return "-"
+	}
+	return strutil.SizeToStr(int64(size))
+}
@@ -0,0 +1,124 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"gopkg.in/check.v1"
+
+	snap "github.com/snapcore/snapd/cmd/snapd/cli"
+)
+
+func (s *SnapSuite) mockSimulateDiskSpace(c *check.C, expectedBody map[string]any, result string) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.Method, check.Equals, "POST")
+			c.Check(r.URL.Path, check.Equals, "/v2/debug")
+			c.Check(DecodedRequestBody(c, r), check.DeepEquals, expectedBody)
+			fmt.Fprintf(w, `{"type": "sync", "result": %s}`, result)
+		default:
+			c.Fatalf("expected to get 1 requests, now on %d", n+1)
+		}
+		n++
+	})
+}
+
+func (s *SnapSuite) TestDebugDiskSpaceRefresh(c *check.C) {
+	s.mockSimulateDiskSpace(c, map[string]any{
+		"action": "simulate-disk-space",
+		"params": map[string]any{"operation": "refresh"},
+		"snaps":  []any{"foo", "bar"},
+	}, `{
+		"operation": "refresh",
+		"snaps": ["bar", "foo"],
+		"filesystems": [
+			{"path": "/var/lib/snapd/snaps", "snaps": 120000000, "required": 125242880, "available": 2000000000},
+			{"path": "/var/snap", "data-copies": 30000000, "required": 35242880, "available": 1000000}
+		],
+		"check-enabled": true,
+		"refused": true,
+		"error": "insufficient space in \"/var/lib/snapd\" to perform \"refresh\" change for the following snaps: bar, foo"
+	}`)
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "diskspace", "--simulate=refresh", "foo", "bar"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.DeepEquals, []string{})
+	c.Check(s.Stdout(), check.Equals, `Simulating refresh of: bar, foo
+
+Filesystem            Snaps  Data copies  Snapshots  Required  Available  Notes
+/var/lib/snapd/snaps  120MB  -            -          125MB     2GB        -
+/var/snap             -      30MB         -          35MB      1MB        insufficient-space
+
+snapd would refuse the operation: insufficient space in "/var/lib/snapd" to perform "refresh" change for the following snaps: bar, foo
+`)
+	c.Check(s.Stderr(), check.Equals, "")
+}
+
+func (s *SnapSuite) TestDebugDiskSpaceRemoveCheckDisabled(c *check.C) {
+	s.mockSimulateDiskSpace(c, map[string]any{
+		"action": "simulate-disk-space",
+		"params": map[string]any{"operation": "remove"},
+		"snaps":  []any{"foo"},
+	}, `{
+		"operation": "remove",
+		"snaps": ["foo"],
+		"filesystems": [
+			{"path": "/var/lib/snapd/snapshots", "snapshots": 4000, "required": 5246880, "available": 2000000000}
+		],
+		"check-enabled": false,
+		"refused": false
+	}`)
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "diskspace", "--simulate=remove", "foo"})
+	c.Assert(err, check.IsNil)
+	c.Check(s.Stdout(), check.Equals, `Simulating remove of: foo
+
+Filesystem                Snaps  Data copies  Snapshots  Required  Available  Notes
+/var/lib/snapd/snapshots  -      -            4kB        5MB       2GB        -
+
+snapd does not check disk space before remove (see experimental.check-disk-space-remove).
+`)
+}
+
+func (s *SnapSuite) TestDebugDiskSpaceNothingToRefresh(c *check.C) {
+	s.mockSimulateDiskSpace(c, map[string]any{
+		"action": "simulate-disk-space",
+		"params": map[string]any{"operation": "refresh"},
+	}, `{"operation": "refresh", "snaps": [], "filesystems": [], "check-enabled": true}`)
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "diskspace", "--simulate=refresh"})
+	c.Assert(err, check.IsNil)
+	c.Check(s.Stdout(), check.Equals, "No snaps to refresh.\n")
+}
+
+func (s *SnapSuite) TestDebugDiskSpaceErrors(c *check.C) {
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "diskspace", "--simulate=install"})
+	c.Check(err, check.ErrorMatches, "cannot simulate install without snap names")
+
+	_, err = snap.Parser(snap.Client()).ParseArgs([]string{"debug", "diskspace", "--simulate=frobnicate", "foo"})
+	c.Check(err, check.ErrorMatches, `Invalid value .frobnicate. for option .--simulate.*`)
+
+	_, err = snap.Parser(snap.Client()).ParseArgs([]string{"debug", "diskspace", "foo"})
+	c.Check(err, check.ErrorMatches, `the required flag .--simulate. was not specified`)
+}
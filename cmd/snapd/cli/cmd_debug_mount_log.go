@@ -0,0 +1,135 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/snap"
+)
+
+var shortDebugMountLogHelp = i18n.G("Show the changes made to the mount namespace of a snap")
+
+var longDebugMountLogHelp = i18n.G(`
+The debug mount-log command shows what snap-update-ns did, each time it
+updated the mount namespace of the given snap: the mount changes it performed
+or failed to perform, how long the update took and whether it failed.
+
+Only the most recent updates of the system-wide mount namespace are kept.
+`)
+
+type cmdDebugMountLog struct {
+	timeMixin
+	Positional struct {
+		Snap string `positional-arg-name:"<snap>" required:"yes"`
+	} `positional-args:"yes"`
+}
+
+func init() {
+	addDebugCommand("mount-log",
+		shortDebugMountLogHelp,
+		longDebugMountLogHelp,
+		func() flags.Commander { return &cmdDebugMountLog{} },
+		timeDescs,
+		[]argDesc{
+			{"<snap>", "Snap name"},
+		},
+	)
+}
+
+// mountLogChange and mountLogRecord mirror the records written by
+// snap-update-ns.
+type mountLogChange struct {
+	Action string `json:"action"`
+	Entry  string `json:"entry"`
+	Error  string `json:"error,omitempty"`
+}
+
+type mountLogRecord struct {
+	Time            time.Time        `json:"time"`
+	Duration        string           `json:"duration"`
+	FromSnapConfine bool             `json:"from-snap-confine,omitempty"`
+	Changes         []mountLogChange `json:"changes,omitempty"`
+	Error           string           `json:"error,omitempty"`
+}
+
+func (x *cmdDebugMountLog) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	snapName := x.Positional.Snap
+	if err := snap.ValidateInstanceName(snapName); err != nil {
+		return err
+	}
+
+	f, err := os.Open(filepath.Join(dirs.SnapRunNsDir, snapName+".log"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			fmt.Fprintf(Stdout, i18n.G("no mount log of snap %q found\n"), snapName)
+			return nil
+		}
+		return fmt.Errorf(i18n.G("cannot open mount log: %v"), err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		var record mountLogRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return fmt.Errorf(i18n.G("cannot decode mount log: %v"), err)
+		}
+		x.printRecord(&record)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf(i18n.G("cannot read mount log: %v"), err)
+	}
+	return nil
+}
+
+func (x *cmdDebugMountLog) printRecord(record *mountLogRecord) {
+	fmt.Fprintf(Stdout, "%s (%s)", x.fmtTime(record.Time), record.Duration)
+	if record.FromSnapConfine {
+		fmt.Fprintf(Stdout, " %s", i18n.G("from snap-confine"))
+	}
+	fmt.Fprintln(Stdout)
+	if len(record.Changes) == 0 {
+		fmt.Fprintf(Stdout, "  %s\n", i18n.G("no changes"))
+	}
+	for _, change := range record.Changes {
+		fmt.Fprintf(Stdout, "  %-7s %s\n", change.Action, change.Entry)
+		if change.Error != "" {
+			fmt.Fprintf(Stdout, "          %s %s\n", i18n.G("failed:"), change.Error)
+		}
+	}
+	if record.Error != "" {
+		fmt.Fprintf(Stdout, "  %s %s\n", i18n.G("error:"), record.Error)
+	}
+}
@@ -0,0 +1,82 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	snap "github.com/snapcore/snapd/cmd/snapd/cli"
+	"github.com/snapcore/snapd/dirs"
+)
+
+func (s *SnapSuite) TestDebugMountLog(c *C) {
+	dirs.SetRootDir(c.MkDir())
+	defer dirs.SetRootDir("/")
+
+	c.Assert(os.MkdirAll(dirs.SnapRunNsDir, 0755), IsNil)
+	log := `{"time":"2026-10-16T12:00:00Z","duration":"2ms","from-snap-confine":true,"changes":[{"action":"mount","entry":"tmpfs /usr/share/foo tmpfs x-snapd.synthetic 0 0"}]}
+{"time":"2026-10-16T12:05:00Z","duration":"1ms"}
+{"time":"2026-10-16T12:10:00Z","duration":"3ms","changes":[{"action":"unmount","entry":"/a /b none bind 0 0"},{"action":"mount","entry":"/c /d none bind,x-snapd.origin=layout 0 0","error":"cannot create path"}],"error":"cannot create path"}
+`
+	c.Assert(os.WriteFile(filepath.Join(dirs.SnapRunNsDir, "foo.log"), []byte(log), 0644), IsNil)
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "mount-log", "--abs-time", "foo"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Check(s.Stdout(), Equals, `2026-10-16T12:00:00Z (2ms) from snap-confine
+  mount   tmpfs /usr/share/foo tmpfs x-snapd.synthetic 0 0
+2026-10-16T12:05:00Z (1ms)
+  no changes
+2026-10-16T12:10:00Z (3ms)
+  unmount /a /b none bind 0 0
+  mount   /c /d none bind,x-snapd.origin=layout 0 0
+          failed: cannot create path
+  error: cannot create path
+`)
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestDebugMountLogNotFound(c *C) {
+	dirs.SetRootDir(c.MkDir())
+	defer dirs.SetRootDir("/")
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "mount-log", "foo"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, "no mount log of snap \"foo\" found\n")
+}
+
+func (s *SnapSuite) TestDebugMountLogInvalidSnapName(c *C) {
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "mount-log", "../foo"})
+	c.Assert(err, ErrorMatches, `invalid snap name: "../foo"`)
+}
+
+func (s *SnapSuite) TestDebugMountLogBadRecord(c *C) {
+	dirs.SetRootDir(c.MkDir())
+	defer dirs.SetRootDir("/")
+
+	c.Assert(os.MkdirAll(dirs.SnapRunNsDir, 0755), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(dirs.SnapRunNsDir, "foo.log"), []byte("garbage\n"), 0644), IsNil)
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "mount-log", "foo"})
+	c.Assert(err, ErrorMatches, `cannot decode mount log: invalid character .*`)
+}
@@ -0,0 +1,125 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/i18n"
+)
+
+type cmdDebugRecovery struct {
+	clientMixin
+	formatMixin
+}
+
+var shortDebugRecoveryHelp = i18n.G("Show how the encrypted partitions were unlocked during boot")
+var longDebugRecoveryHelp = i18n.G(`
+The recovery command reports how the initramfs unlocked and mounted the
+ubuntu-boot, ubuntu-data and ubuntu-save partitions during the last boot. When
+the system entered degraded mode it shows which unlock paths failed, which
+fallback keys were used instead, and the actions recommended to bring the
+system back to a healthy state.
+`)
+
+func init() {
+	addDebugCommand("recovery",
+		shortDebugRecoveryHelp,
+		longDebugRecoveryHelp,
+		func() flags.Commander {
+			return &cmdDebugRecovery{}
+		}, formatArgsHelp, nil)
+}
+
+type debugRecoveryPartition struct {
+	Name          string `json:"name"`
+	UnlockState   string `json:"unlock-state,omitempty"`
+	UnlockKey     string `json:"unlock-key,omitempty"`
+	MountState    string `json:"mount-state,omitempty"`
+	MountLocation string `json:"mount-location,omitempty"`
+	Fallback      bool   `json:"fallback,omitempty"`
+	Failed        bool   `json:"failed,omitempty"`
+}
+
+type debugRecovery struct {
+	Source     string                   `json:"source"`
+	Degraded   bool                     `json:"degraded"`
+	Partitions []debugRecoveryPartition `json:"partitions"`
+	Actions    []string                 `json:"actions,omitempty"`
+}
+
+func (x *cmdDebugRecovery) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	var resp debugRecovery
+	if err := x.client.DebugGet("recovery", &resp, nil); err != nil {
+		return err
+	}
+
+	if x.Format != "text" && x.Format != "" {
+		return x.formatNonText(resp)
+	}
+
+	if resp.Degraded {
+		fmt.Fprintf(Stdout, i18n.G("The system booted in degraded mode (see %s).\n\n"), resp.Source)
+	} else {
+		fmt.Fprintf(Stdout, i18n.G("The system booted normally (see %s).\n\n"), resp.Source)
+	}
+
+	w := tabWriter()
+	fmt.Fprintln(w, i18n.G("Partition\tUnlock\tKey\tMount\tLocation\tNotes"))
+	for _, part := range resp.Partitions {
+		notes := "-"
+		switch {
+		case part.Failed:
+			// TRANSLATORS: note shown for a partition that could not be unlocked or mounted
+			notes = i18n.G("failed")
+		case part.Fallback:
+			// TRANSLATORS: note shown for a partition unlocked with a key other than the run key
+			notes = i18n.G("fallback")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", part.Name,
+			fmtDebugRecoveryField(part.UnlockState), fmtDebugRecoveryField(part.UnlockKey),
+			fmtDebugRecoveryField(part.MountState), fmtDebugRecoveryField(part.MountLocation), notes)
+	}
+	w.Flush()
+	fmt.Fprintln(Stdout)
+
+	if len(resp.Actions) == 0 {
+		fmt.Fprintln(Stdout, i18n.G("No action is needed."))
+		return nil
+	}
+	fmt.Fprintln(Stdout, i18n.G("Recommended actions:"))
+	for _, action := range resp.Actions {
+		fmt.Fprintf(Stdout, "  - %s\n", action)
+	}
+	return nil
+}
+
+func fmtDebugRecoveryField(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,118 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli_test
+
+import (
+	"fmt"
+	"net/http"
+
+	. "gopkg.in/check.v1"
+
+	snap "github.com/snapcore/snapd/cmd/snapd/cli"
+)
+
+func (s *SnapSuite) mockDebugRecoveryServer(c *C, resp string) *int {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		n++
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/debug")
+		c.Check(r.URL.RawQuery, Equals, "aspect=recovery")
+		fmt.Fprintln(w, resp)
+	})
+	return &n
+}
+
+func (s *SnapSuite) TestDebugRecoveryDegraded(c *C) {
+	n := s.mockDebugRecoveryServer(c, `{"type": "sync", "status-code": 200, "result": {
+  "source": "degraded.json",
+  "degraded": true,
+  "partitions": [
+    {"name": "ubuntu-boot", "mount-state": "mounted", "mount-location": "/run/mnt/ubuntu-boot"},
+    {"name": "ubuntu-data", "unlock-state": "error-unlocking", "mount-state": "error-mounting", "failed": true},
+    {"name": "ubuntu-save", "unlock-state": "unlocked", "unlock-key": "fallback", "mount-state": "mounted", "mount-location": "/run/mnt/ubuntu-save", "fallback": true}
+  ],
+  "actions": ["unlock ubuntu-data", "check ubuntu-save"]
+}}`)
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "recovery"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Check(*n, Equals, 1)
+	c.Check(s.Stdout(), Equals, `
+The system booted in degraded mode (see degraded.json).
+
+Partition    Unlock           Key       Mount           Location              Notes
+ubuntu-boot  -                -         mounted         /run/mnt/ubuntu-boot  -
+ubuntu-data  error-unlocking  -         error-mounting  -                     failed
+ubuntu-save  unlocked         fallback  mounted         /run/mnt/ubuntu-save  fallback
+
+Recommended actions:
+  - unlock ubuntu-data
+  - check ubuntu-save
+`[1:])
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestDebugRecoveryHealthy(c *C) {
+	s.mockDebugRecoveryServer(c, `{"type": "sync", "status-code": 200, "result": {
+  "source": "unlocked.json",
+  "degraded": false,
+  "partitions": [
+    {"name": "ubuntu-boot"},
+    {"name": "ubuntu-data", "unlock-state": "unlocked", "unlock-key": "run"},
+    {"name": "ubuntu-save", "unlock-state": "unlocked", "unlock-key": "run"}
+  ]
+}}`)
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "recovery"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, `
+The system booted normally (see unlocked.json).
+
+Partition    Unlock    Key  Mount  Location  Notes
+ubuntu-boot  -         -    -      -         -
+ubuntu-data  unlocked  run  -      -         -
+ubuntu-save  unlocked  run  -      -         -
+
+No action is needed.
+`[1:])
+}
+
+func (s *SnapSuite) TestDebugRecoveryJSON(c *C) {
+	s.mockDebugRecoveryServer(c, `{"type": "sync", "status-code": 200, "result": {
+  "source": "unlocked.json",
+  "degraded": false,
+  "partitions": [{"name": "ubuntu-boot"}]
+}}`)
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "recovery", "--format=json"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, `{"source":"unlocked.json","degraded":false,"partitions":[{"name":"ubuntu-boot"}]}`+"\n")
+}
+
+func (s *SnapSuite) TestDebugRecoveryNoState(c *C) {
+	s.mockDebugRecoveryServer(c, `{"type": "error", "status-code": 404, "result": {
+  "message": "cannot report recovery status: no disk unlock state was recorded during boot"
+}}`)
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "recovery"})
+	c.Assert(err, ErrorMatches, "cannot report recovery status: no disk unlock state was recorded during boot")
+}
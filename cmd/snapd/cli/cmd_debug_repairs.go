@@ -0,0 +1,137 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/i18n"
+)
+
+type cmdDebugRepairs struct {
+	clientMixin
+	formatMixin
+
+	Positional struct {
+		Repair string `positional-arg-name:"<repair>"`
+	} `positional-args:"yes"`
+}
+
+var shortDebugRepairsHelp = i18n.G("Show the repairs run on this device")
+var longDebugRepairsHelp = i18n.G(`
+The repairs command lists the repairs that snap-repair ran on this device,
+with their revision and outcome. When a repair is given, in the form
+<brand>-<sequence>, every run of it is shown along with the script that was
+run and its output.
+`)
+
+func init() {
+	addDebugCommand("repairs",
+		shortDebugRepairsHelp,
+		longDebugRepairsHelp,
+		func() flags.Commander {
+			return &cmdDebugRepairs{}
+		}, formatArgsHelp, []argDesc{{
+			// TRANSLATORS: This needs to begin with < and end with >
+			name: i18n.G("<repair>"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			desc: i18n.G("Repair to show, including its script and output"),
+		}})
+}
+
+type debugRepair struct {
+	Repair   string `json:"repair"`
+	Revision int    `json:"revision"`
+	Status   string `json:"status"`
+	Summary  string `json:"summary,omitempty"`
+	Script   string `json:"script,omitempty"`
+	Output   string `json:"output,omitempty"`
+}
+
+func (x *cmdDebugRepairs) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	var params map[string]string
+	if x.Positional.Repair != "" {
+		params = map[string]string{"repair": x.Positional.Repair}
+	}
+	var repairs []debugRepair
+	if err := x.client.DebugGet("repairs", &repairs, params); err != nil {
+		return err
+	}
+
+	if x.Format != "text" && x.Format != "" {
+		return x.formatNonText(repairs)
+	}
+
+	if len(repairs) == 0 {
+		fmt.Fprintln(Stderr, i18n.G("No repairs were run on this device."))
+		return nil
+	}
+
+	if x.Positional.Repair != "" {
+		for i, r := range repairs {
+			if i > 0 {
+				fmt.Fprintln(Stdout)
+			}
+			fmt.Fprintf(Stdout, "repair: %s\n", r.Repair)
+			fmt.Fprintf(Stdout, "revision: %d\n", r.Revision)
+			fmt.Fprintf(Stdout, "status: %s\n", r.Status)
+			fmt.Fprintf(Stdout, "summary: %s\n", fmtDebugRepairField(r.Summary))
+			fmt.Fprintf(Stdout, "script:\n%s", indentDebugRepairLog(r.Script))
+			fmt.Fprintf(Stdout, "output:\n%s", indentDebugRepairLog(r.Output))
+		}
+		return nil
+	}
+
+	w := tabWriter()
+	fmt.Fprintln(w, i18n.G("Repair\tRev\tStatus\tSummary"))
+	for _, r := range repairs {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", r.Repair, r.Revision, r.Status, fmtDebugRepairField(r.Summary))
+	}
+	w.Flush()
+	return nil
+}
+
+func fmtDebugRepairField(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// indentDebugRepairLog indents every line of the given script or output.
+func indentDebugRepairLog(log string) string {
+	if log == "" {
+		return ""
+	}
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(strings.TrimSuffix(log, "\n"), "\n") {
+		b.WriteString("  ")
+		b.WriteString(line)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
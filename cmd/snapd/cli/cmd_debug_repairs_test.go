@@ -0,0 +1,118 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli_test
+
+import (
+	"fmt"
+	"net/http"
+
+	. "gopkg.in/check.v1"
+
+	snap "github.com/snapcore/snapd/cmd/snapd/cli"
+)
+
+func (s *SnapSuite) mockDebugRepairsServer(c *C, query, resp string) *int {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		n++
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/debug")
+		c.Check(r.URL.RawQuery, Equals, query)
+		fmt.Fprintln(w, resp)
+	})
+	return &n
+}
+
+func (s *SnapSuite) TestDebugRepairs(c *C) {
+	n := s.mockDebugRepairsServer(c, "aspect=repairs", `{"type": "sync", "status-code": 200, "result": [
+  {"repair": "canonical-1", "revision": 0, "status": "retry", "summary": "repair one"},
+  {"repair": "canonical-1", "revision": 1, "status": "done", "summary": "repair one"},
+  {"repair": "my-brand-12", "revision": 3, "status": "skip"}
+]}`)
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "repairs"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Check(*n, Equals, 1)
+	c.Check(s.Stdout(), Equals, `
+Repair       Rev  Status  Summary
+canonical-1  0    retry   repair one
+canonical-1  1    done    repair one
+my-brand-12  3    skip    -
+`[1:])
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestDebugRepairsNone(c *C) {
+	s.mockDebugRepairsServer(c, "aspect=repairs", `{"type": "sync", "status-code": 200, "result": []}`)
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "repairs"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, "")
+	c.Check(s.Stderr(), Equals, "No repairs were run on this device.\n")
+}
+
+func (s *SnapSuite) TestDebugRepairsSingle(c *C) {
+	s.mockDebugRepairsServer(c, "aspect=repairs&repair=canonical-1", `{"type": "sync", "status-code": 200, "result": [
+  {"repair": "canonical-1", "revision": 0, "status": "retry", "summary": "repair one", "script": "#!/bin/sh\nexit 1\n", "output": "failed\n"},
+  {"repair": "canonical-1", "revision": 1, "status": "done", "script": "#!/bin/sh\necho fixed\n"}
+]}`)
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "repairs", "canonical-1"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, `
+repair: canonical-1
+revision: 0
+status: retry
+summary: repair one
+script:
+  #!/bin/sh
+  exit 1
+output:
+  failed
+
+repair: canonical-1
+revision: 1
+status: done
+summary: -
+script:
+  #!/bin/sh
+  echo fixed
+output:
+`[1:])
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestDebugRepairsJSON(c *C) {
+	s.mockDebugRepairsServer(c, "aspect=repairs", `{"type": "sync", "status-code": 200, "result": [
+  {"repair": "canonical-1", "revision": 1, "status": "done", "summary": "repair one"}
+]}`)
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "repairs", "--format=json"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, `[{"repair":"canonical-1","revision":1,"status":"done","summary":"repair one"}]`+"\n")
+}
+
+func (s *SnapSuite) TestDebugRepairsError(c *C) {
+	s.mockDebugRepairsServer(c, "aspect=repairs&repair=canonical-2", `{"type": "error", "status-code": 404, "result": {"message": "cannot find repair \"canonical-2\""}}`)
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "repairs", "canonical-2"})
+	c.Assert(err, ErrorMatches, `cannot find repair "canonical-2"`)
+}
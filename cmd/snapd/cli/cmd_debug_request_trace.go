@@ -0,0 +1,126 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/i18n"
+)
+
+type cmdDebugRequestTrace struct {
+	changeIDMixin
+	formatMixin
+}
+
+var shortDebugRequestTraceHelp = i18n.G("Show a timeline of everything involved in a change")
+var longDebugRequestTraceHelp = i18n.G(`
+The request-trace command assembles a single timeline for a change out of
+the lifecycle of the change and its tasks, the task logs, including the
+output of failing hooks, the system and snapd restarts that happened while
+the change was in progress, and the snapd log entries that refer to the
+change or to the API request that created it.
+`)
+
+func init() {
+	addDebugCommand("request-trace",
+		shortDebugRequestTraceHelp,
+		longDebugRequestTraceHelp,
+		func() flags.Commander {
+			return &cmdDebugRequestTrace{}
+		}, changeIDMixinOptDesc.also(formatArgsHelp), changeIDMixinArgDesc)
+}
+
+type requestTraceEntry struct {
+	Time    time.Time `json:"time"`
+	Source  string    `json:"source"`
+	TaskID  string    `json:"task-id,omitempty"`
+	Level   string    `json:"level,omitempty"`
+	Message string    `json:"message"`
+}
+
+type requestTrace struct {
+	ChangeID  string               `json:"change-id"`
+	Kind      string               `json:"kind"`
+	Summary   string               `json:"summary"`
+	Status    string               `json:"status"`
+	RequestID string               `json:"request-id,omitempty"`
+	Entries   []*requestTraceEntry `json:"entries"`
+}
+
+// requestTraceTimeFormat keeps the milliseconds as many entries of a
+// timeline usually happen within the same second.
+const requestTraceTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+func (x *cmdDebugRequestTrace) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	chgID, err := x.GetChangeID()
+	if err != nil {
+		if err == noChangeFoundOK {
+			return nil
+		}
+		return err
+	}
+
+	var trace requestTrace
+	params := map[string]string{"change-id": chgID}
+	if err := x.client.DebugGet("request-trace", &trace, params); err != nil {
+		return err
+	}
+
+	if x.Format != "text" && x.Format != "" {
+		return x.formatNonText(trace)
+	}
+
+	fmt.Fprintf(Stdout, "change: %s\n", trace.ChangeID)
+	fmt.Fprintf(Stdout, "kind: %s\n", trace.Kind)
+	fmt.Fprintf(Stdout, "summary: %s\n", trace.Summary)
+	fmt.Fprintf(Stdout, "status: %s\n", trace.Status)
+	if trace.RequestID != "" {
+		fmt.Fprintf(Stdout, "request-id: %s\n", trace.RequestID)
+	}
+	fmt.Fprintln(Stdout)
+
+	w := tabWriter()
+	fmt.Fprintln(w, i18n.G("Time\tSource\tTask\tMessage"))
+	for _, e := range trace.Entries {
+		taskID := e.TaskID
+		if taskID == "" {
+			taskID = "-"
+		}
+		msg := e.Message
+		if e.Level == "ERROR" {
+			msg = "ERROR " + msg
+		}
+		// keep multi-line messages, like hook output, in the message
+		// column
+		msg = strings.Replace(strings.TrimRight(msg, "\n"), "\n", "\n\t\t\t", -1)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.Time.Format(requestTraceTimeFormat), e.Source, taskID, msg)
+	}
+	w.Flush()
+	return nil
+}
@@ -0,0 +1,105 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli_test
+
+import (
+	"fmt"
+	"net/http"
+
+	. "gopkg.in/check.v1"
+
+	snap "github.com/snapcore/snapd/cmd/snapd/cli"
+)
+
+const requestTraceResponse = `{"type": "sync", "status-code": 200, "result": {
+  "change-id": "1", "kind": "refresh-snap", "summary": "Refresh snap \"foo\"", "status": "Error", "request-id": "req-1",
+  "entries": [
+    {"time": "2026-10-01T10:00:00Z", "source": "change", "message": "Change \"Refresh snap \\\"foo\\\"\" spawned"},
+    {"time": "2026-10-01T10:00:00.250Z", "source": "daemon", "message": "127.0.0.1 POST /v2/snaps/foo 1ms 202 request-id=req-1"},
+    {"time": "2026-10-01T10:00:02Z", "source": "restart", "task-id": "1", "level": "INFO", "message": "Task has requested a system restart"},
+    {"time": "2026-10-01T10:00:10Z", "source": "restart", "message": "System booted (boot id boot-2)"},
+    {"time": "2026-10-01T10:00:20Z", "source": "hook", "task-id": "2", "level": "ERROR", "message": "run hook \"post-refresh\": \n-----\nline one\nline two\n-----"},
+    {"time": "2026-10-01T10:00:21Z", "source": "change", "message": "Change ready with status Error"}
+  ]
+}}`
+
+func (s *SnapSuite) mockDebugRequestTraceServer(c *C, resp string) *int {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		n++
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/debug")
+		c.Check(r.URL.RawQuery, Equals, "aspect=request-trace&change-id=1")
+		fmt.Fprintln(w, resp)
+	})
+	return &n
+}
+
+func (s *SnapSuite) TestDebugRequestTrace(c *C) {
+	n := s.mockDebugRequestTraceServer(c, requestTraceResponse)
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "request-trace", "1"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Check(*n, Equals, 1)
+	c.Check(s.Stdout(), Equals, `
+change: 1
+kind: refresh-snap
+summary: Refresh snap "foo"
+status: Error
+request-id: req-1
+
+Time                      Source   Task  Message
+2026-10-01T10:00:00.000Z  change   -     Change "Refresh snap \"foo\"" spawned
+2026-10-01T10:00:00.250Z  daemon   -     127.0.0.1 POST /v2/snaps/foo 1ms 202 request-id=req-1
+2026-10-01T10:00:02.000Z  restart  1     Task has requested a system restart
+2026-10-01T10:00:10.000Z  restart  -     System booted (boot id boot-2)
+2026-10-01T10:00:20.000Z  hook     2     ERROR run hook "post-refresh": 
+                                         -----
+                                         line one
+                                         line two
+                                         -----
+2026-10-01T10:00:21.000Z  change   -     Change ready with status Error
+`[1:])
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestDebugRequestTraceJSON(c *C) {
+	s.mockDebugRequestTraceServer(c, `{"type": "sync", "status-code": 200, "result": {
+  "change-id": "1", "kind": "install-snap", "summary": "Install", "status": "Done",
+  "entries": [{"time": "2026-10-01T10:00:00Z", "source": "change", "message": "Change \"Install\" spawned"}]
+}}`)
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "request-trace", "1", "--format=json"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, `{"change-id":"1","kind":"install-snap","summary":"Install","status":"Done","entries":[{"time":"2026-10-01T10:00:00Z","source":"change","message":"Change \"Install\" spawned"}]}`+"\n")
+}
+
+func (s *SnapSuite) TestDebugRequestTraceNoChangeID(c *C) {
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "request-trace"})
+	c.Assert(err, ErrorMatches, `please provide change ID or type with --last=<type>`)
+}
+
+func (s *SnapSuite) TestDebugRequestTraceError(c *C) {
+	s.mockDebugRequestTraceServer(c, `{"type": "error", "status-code": 404, "result": {"message": "cannot find change with id \"1\""}}`)
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "request-trace", "1"})
+	c.Assert(err, ErrorMatches, `cannot find change with id "1"`)
+}
@@ -20,9 +20,12 @@
 package cli
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
@@ -34,6 +37,7 @@ import (
 
 	"github.com/snapcore/snapd/i18n"
 	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/overlord/dot"
 	"github.com/snapcore/snapd/overlord/ifacestate/schema"
 	"github.com/snapcore/snapd/overlord/state"
@@ -42,6 +46,7 @@ import (
 
 type cmdDebugState struct {
 	timeMixin
+	formatMixin
 
 	Changes  bool   `long:"changes"`
 	TaskID   string `long:"task"`
@@ -50,6 +55,15 @@ type cmdDebugState struct {
 
 	Connections bool   `long:"connections"`
 	Connection  string `long:"connection"`
+	Interface   string `long:"interface"`
+
+	Consistency bool `long:"consistency"`
+
+	Dump bool `long:"dump"`
+
+	// flags editing the state file, for use when snapd is not running
+	Abort     bool   `long:"abort"`
+	SetStatus string `long:"set-status"`
 
 	IsSeeded bool `long:"is-seeded"`
 
@@ -72,10 +86,15 @@ func (c byChangeSpawnTime) Len() int           { return len(c) }
 func (c byChangeSpawnTime) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
 func (c byChangeSpawnTime) Less(i, j int) bool { return c[i].SpawnTime().Before(c[j].SpawnTime()) }
 
-func loadState(path string) (*state.State, error) {
+func statePath(path string) string {
 	if path == "" {
-		path = "state.json"
+		return "state.json"
 	}
+	return path
+}
+
+func loadState(path string) (*state.State, error) {
+	path = statePath(path)
 	r, err := os.Open(path)
 	if err != nil {
 		return nil, fmt.Errorf("cannot read the state file: %s", err)
@@ -88,7 +107,7 @@ func loadState(path string) (*state.State, error) {
 func init() {
 	addDebugCommand("state", cmdDebugStateShortHelp, cmdDebugStateLongHelp, func() flags.Commander {
 		return &cmdDebugState{}
-	}, timeDescs.also(map[string]string{
+	}, timeDescs.also(formatArgsHelp).also(map[string]string{
 		// TRANSLATORS: This should not start with a lowercase letter.
 		"change":      i18n.G("ID of the change to inspect"),
 		"task":        i18n.G("ID of the task to inspect"),
@@ -99,6 +118,11 @@ func init() {
 		"connection":  i18n.G("Show details of the matching connections (snap or snap:plug,snap:slot or snap:plug-or-slot"),
 		"is-seeded":   i18n.G("Output seeding status (true or false)"),
 		"check":       i18n.G("Check change consistency"),
+		"interface":   i18n.G("Only show connections of the given interface"),
+		"consistency": i18n.G("Check the consistency of connections, changes, tasks and lanes"),
+		"dump":        i18n.G("Dump the whole state as JSON, with sensitive fields like credentials redacted"),
+		"abort":       i18n.G("Abort the change given with --change= in the state file (snapd must not be running)"),
+		"set-status":  i18n.G("Set the status of the task given with --task= in the state file (snapd must not be running)"),
 	}), nil)
 }
 
@@ -279,6 +303,23 @@ func (c *cmdDebugState) showChanges(st *state.State) error {
 	return nil
 }
 
+func (c *cmdDebugState) dumpState(st *state.State) error {
+	st.Lock()
+	data, err := st.RedactedJSON()
+	st.Unlock()
+	if err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, data, "", "  "); err != nil {
+		return err
+	}
+	out.WriteString("\n")
+	_, err = out.WriteTo(Stdout)
+	return err
+}
+
 func (c *cmdDebugState) showIsSeeded(st *state.State) error {
 	st.Lock()
 	defer st.Unlock()
@@ -294,10 +335,10 @@ func (c *cmdDebugState) showIsSeeded(st *state.State) error {
 }
 
 type connectionInfo struct {
-	PlugSnap string
-	PlugName string
-	SlotSnap string
-	SlotName string
+	PlugSnap string `json:"plug-snap"`
+	PlugName string `json:"plug-name"`
+	SlotSnap string `json:"slot-snap"`
+	SlotName string `json:"slot-name"`
 
 	schema.ConnState
 }
@@ -381,6 +422,9 @@ func (c *cmdDebugState) showConnectionDetails(st *state.State, connArg string) e
 		}
 
 		conn := conns[connID]
+		if c.Interface != "" && conn.Interface != c.Interface {
+			continue
+		}
 
 		// the output of 'debug connection' is yaml
 		fmt.Fprintf(Stdout, "id: %s\n", connID)
@@ -410,6 +454,9 @@ func (c *cmdDebugState) showConnections(st *state.State) error {
 		}
 		plug := strings.Split(p[0], ":")
 		slot := strings.Split(p[1], ":")
+		if c.Interface != "" && conn.Interface != c.Interface {
+			continue
+		}
 
 		c := &connectionInfo{
 			PlugSnap:  plug[0],
@@ -423,6 +470,10 @@ func (c *cmdDebugState) showConnections(st *state.State) error {
 
 	sort.Sort(byPlug(all))
 
+	if c.Format != "text" && c.Format != "" {
+		return c.formatNonText(all)
+	}
+
 	w := tabwriter.NewWriter(Stdout, 5, 3, 2, ' ', 0)
 	fmt.Fprintf(w, "Interface\tPlug\tSlot\tNotes\n")
 	for _, conn := range all {
@@ -443,6 +494,169 @@ func (c *cmdDebugState) showConnections(st *state.State) error {
 	return nil
 }
 
+type stateProblem struct {
+	Kind    string `json:"kind"`
+	ID      string `json:"id"`
+	Problem string `json:"problem"`
+}
+
+// connectionProblems reports connections where either side refers to a snap
+// that is not installed.
+func connectionProblems(st *state.State) ([]stateProblem, error) {
+	var conns map[string]*schema.ConnState
+	if err := st.Get("conns", &conns); err != nil && !errors.Is(err, state.ErrNoState) {
+		return nil, err
+	}
+	// the snap state is not decoded as the CLI cannot use snapstate
+	var snaps map[string]json.RawMessage
+	if err := st.Get("snaps", &snaps); err != nil && !errors.Is(err, state.ErrNoState) {
+		return nil, err
+	}
+
+	connIDs := make([]string, 0, len(conns))
+	for connID := range conns {
+		connIDs = append(connIDs, connID)
+	}
+	sort.Strings(connIDs)
+
+	var problems []stateProblem
+	for _, connID := range connIDs {
+		connRef, err := interfaces.ParseConnRef(connID)
+		if err != nil {
+			problems = append(problems, stateProblem{Kind: "connection", ID: connID, Problem: err.Error()})
+			continue
+		}
+		if _, ok := snaps[connRef.PlugRef.Snap]; !ok {
+			problems = append(problems, stateProblem{Kind: "connection", ID: connID,
+				Problem: fmt.Sprintf("plug snap %q is not installed", connRef.PlugRef.Snap)})
+		}
+		if _, ok := snaps[connRef.SlotRef.Snap]; !ok {
+			problems = append(problems, stateProblem{Kind: "connection", ID: connID,
+				Problem: fmt.Sprintf("slot snap %q is not installed", connRef.SlotRef.Snap)})
+		}
+	}
+	return problems, nil
+}
+
+// sortedByID sorts the given changes or tasks by their numeric ID.
+func sortedByID[T interface{ ID() string }](items []T) []T {
+	sort.Slice(items, func(i, j int) bool {
+		a, _ := strconv.Atoi(items[i].ID())
+		b, _ := strconv.Atoi(items[j].ID())
+		return a < b
+	})
+	return items
+}
+
+// changeProblems reports changes without tasks, task dependency cycles,
+// tasks waiting on tasks missing from the state and tasks not linked to any
+// change.
+func changeProblems(st *state.State) []stateProblem {
+	var problems []stateProblem
+	for _, chg := range sortedByID(st.Changes()) {
+		tasks := chg.Tasks()
+		if len(tasks) == 0 {
+			problems = append(problems, stateProblem{Kind: "change", ID: chg.ID(), Problem: "change has no tasks"})
+			continue
+		}
+		missing := false
+		for _, t := range sortedByID(tasks) {
+			for _, wt := range t.WaitTasks() {
+				if wt == nil {
+					problems = append(problems, stateProblem{Kind: "task", ID: t.ID(), Problem: "task waits for a task missing from the state"})
+					missing = true
+					break
+				}
+			}
+			for _, ht := range t.HaltTasks() {
+				if ht == nil {
+					problems = append(problems, stateProblem{Kind: "task", ID: t.ID(), Problem: "task halts a task missing from the state"})
+					missing = true
+					break
+				}
+			}
+		}
+		// missing tasks would be reported as a dependency cycle too
+		if missing {
+			continue
+		}
+		if err := chg.CheckTaskDependencies(); err != nil {
+			problems = append(problems, stateProblem{Kind: "change", ID: chg.ID(), Problem: err.Error()})
+		}
+	}
+
+	for _, t := range sortedByID(st.UnlinkedTasks()) {
+		problems = append(problems, stateProblem{Kind: "task", ID: t.ID(), Problem: "task is not linked to any change"})
+	}
+	return problems
+}
+
+// laneProblems reports lanes that are shared by tasks of different changes,
+// lanes are allocated for a single change only.
+func laneProblems(st *state.State) []stateProblem {
+	changesByLane := make(map[int][]string)
+	for _, chg := range sortedByID(st.Changes()) {
+		for _, t := range chg.Tasks() {
+			for _, lane := range t.Lanes() {
+				if lane == 0 {
+					continue
+				}
+				if !strutil.ListContains(changesByLane[lane], chg.ID()) {
+					changesByLane[lane] = append(changesByLane[lane], chg.ID())
+				}
+			}
+		}
+	}
+
+	lanes := make([]int, 0, len(changesByLane))
+	for lane := range changesByLane {
+		lanes = append(lanes, lane)
+	}
+	sort.Ints(lanes)
+
+	var problems []stateProblem
+	for _, lane := range lanes {
+		if chgIDs := changesByLane[lane]; len(chgIDs) > 1 {
+			problems = append(problems, stateProblem{Kind: "lane", ID: strconv.Itoa(lane),
+				Problem: fmt.Sprintf("lane is used by tasks of multiple changes: %s", strings.Join(chgIDs, ","))})
+		}
+	}
+	return problems
+}
+
+func (c *cmdDebugState) checkConsistency(st *state.State) error {
+	st.Lock()
+	defer st.Unlock()
+
+	problems, err := connectionProblems(st)
+	if err != nil {
+		return err
+	}
+	problems = append(problems, changeProblems(st)...)
+	problems = append(problems, laneProblems(st)...)
+
+	if c.Format != "text" && c.Format != "" {
+		if problems == nil {
+			problems = []stateProblem{}
+		}
+		return c.formatNonText(problems)
+	}
+
+	if len(problems) == 0 {
+		fmt.Fprintln(Stdout, i18n.G("No problems found."))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(Stdout, 5, 3, 2, ' ', 0)
+	fmt.Fprintf(w, "Kind\tID\tProblem\n")
+	for _, p := range problems {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", p.Kind, p.ID, p.Problem)
+	}
+	w.Flush()
+
+	return nil
+}
+
 func (c *cmdDebugState) showTask(st *state.State, taskID string) error {
 	st.Lock()
 	defer st.Unlock()
@@ -488,7 +702,117 @@ func (c *cmdDebugState) showTask(st *state.State, taskID string) error {
 	return nil
 }
 
+// lockStateFile takes the lock that snapd holds on the state while it is
+// running, the lock file is expected next to the state file.
+func lockStateFile(path string) (unlock func(), err error) {
+	lockPath := filepath.Join(filepath.Dir(statePath(path)), "state.lock")
+	flock, err := osutil.NewFileLockWithMode(lockPath, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open the state lock file: %v", err)
+	}
+	if err := flock.TryLock(); err != nil {
+		flock.Close()
+		if err == osutil.ErrAlreadyLocked {
+			return nil, fmt.Errorf("cannot edit the state file while snapd is running")
+		}
+		return nil, fmt.Errorf("cannot lock the state file: %v", err)
+	}
+	return func() { flock.Close() }, nil
+}
+
+// editState applies the given edit to the state and, if it succeeds, writes
+// the state back to the state file after taking a backup of the original.
+func (c *cmdDebugState) editState(st *state.State, edit func() error) error {
+	st.Lock()
+	defer st.Unlock()
+
+	if err := edit(); err != nil {
+		return err
+	}
+
+	path := statePath(c.Positional.StateFilePath)
+	backup := fmt.Sprintf("%s.%s", path, timeNow().Format("20060102T150405"))
+	if err := osutil.CopyFile(path, backup, osutil.CopyFlagPreserveAll); err != nil {
+		return fmt.Errorf("cannot back up the state file: %v", err)
+	}
+	data, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	if err := osutil.AtomicWriteFile(path, data, 0600, 0); err != nil {
+		return fmt.Errorf("cannot write the state file: %v", err)
+	}
+	fmt.Fprintf(Stdout, i18n.G("Original state saved to %s\n"), backup)
+	return nil
+}
+
+func (c *cmdDebugState) abortChange(st *state.State, changeID string) error {
+	return c.editState(st, func() error {
+		chg := st.Change(changeID)
+		if chg == nil {
+			return fmt.Errorf("no such change: %s", changeID)
+		}
+		if chg.IsReady() {
+			return fmt.Errorf("cannot abort change %s with nothing pending", changeID)
+		}
+		chg.Abort()
+		fmt.Fprintf(Stdout, i18n.G("Change %s aborted\n"), changeID)
+		return nil
+	})
+}
+
+// settableTaskStatuses lists the statuses that can be set with
+// --set-status, WaitStatus is left out as it requires a status to restore
+// after the wait.
+var settableTaskStatuses = []state.Status{
+	state.DoStatus,
+	state.DoingStatus,
+	state.DoneStatus,
+	state.AbortStatus,
+	state.UndoStatus,
+	state.UndoingStatus,
+	state.UndoneStatus,
+	state.HoldStatus,
+	state.ErrorStatus,
+}
+
+func parseTaskStatus(s string) (state.Status, error) {
+	names := make([]string, 0, len(settableTaskStatuses))
+	for _, status := range settableTaskStatuses {
+		if strings.EqualFold(s, status.String()) {
+			return status, nil
+		}
+		names = append(names, status.String())
+	}
+	return state.DefaultStatus, fmt.Errorf("invalid task status %q, must be one of: %s", s, strings.Join(names, ", "))
+}
+
+func (c *cmdDebugState) setTaskStatus(st *state.State, taskID, statusStr string) error {
+	status, err := parseTaskStatus(statusStr)
+	if err != nil {
+		return err
+	}
+	return c.editState(st, func() error {
+		task := st.Task(taskID)
+		if task == nil {
+			return fmt.Errorf("no such task: %s", taskID)
+		}
+		old := task.Status()
+		task.SetStatus(status)
+		fmt.Fprintf(Stdout, i18n.G("Status of task %s changed from %s to %s\n"), taskID, old, task.Status())
+		return nil
+	})
+}
+
 func (c *cmdDebugState) Execute(args []string) error {
+	if c.Abort || c.SetStatus != "" {
+		unlock, err := lockStateFile(c.Positional.StateFilePath)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
 	st, err := loadState(c.Positional.StateFilePath)
 	if err != nil {
 		return err
@@ -511,6 +835,12 @@ func (c *cmdDebugState) Execute(args []string) error {
 	if c.Connections {
 		cmds = append(cmds, "--connections")
 	}
+	if c.Consistency {
+		cmds = append(cmds, "--consistency")
+	}
+	if c.Dump {
+		cmds = append(cmds, "--dump")
+	}
 	if len(cmds) > 1 {
 		return fmt.Errorf("cannot use %s and %s together", cmds[0], cmds[1])
 	}
@@ -519,6 +849,10 @@ func (c *cmdDebugState) Execute(args []string) error {
 		return c.showIsSeeded(st)
 	}
 
+	if c.Dump {
+		return c.dumpState(st)
+	}
+
 	if c.DotOutput && c.ChangeID == "" {
 		return fmt.Errorf("--dot can only be used with --change=")
 	}
@@ -528,6 +862,21 @@ func (c *cmdDebugState) Execute(args []string) error {
 	if c.Check && c.ChangeID == "" {
 		return fmt.Errorf("--check can only be used with --change")
 	}
+	if c.Abort && c.ChangeID == "" {
+		return fmt.Errorf("--abort can only be used with --change=")
+	}
+	if c.Abort && (c.DotOutput || c.NoHoldState || c.Check) {
+		return fmt.Errorf("--abort cannot be used with --dot, --no-hold or --check")
+	}
+	if c.SetStatus != "" && c.TaskID == "" {
+		return fmt.Errorf("--set-status can only be used with --task=")
+	}
+	if c.Interface != "" && !c.Connections && c.Connection == "" {
+		return fmt.Errorf("--interface can only be used with --connections or --connection=")
+	}
+	if c.Format != "text" && c.Format != "" && !c.Connections && !c.Consistency {
+		return fmt.Errorf("--format can only be used with --connections or --consistency")
+	}
 
 	if c.Changes {
 		return c.showChanges(st)
@@ -538,6 +887,9 @@ func (c *cmdDebugState) Execute(args []string) error {
 		if err != nil {
 			return fmt.Errorf("invalid change: %s", c.ChangeID)
 		}
+		if c.Abort {
+			return c.abortChange(st, c.ChangeID)
+		}
 		if c.DotOutput {
 			return c.writeDotOutput(st, c.ChangeID)
 		}
@@ -552,6 +904,9 @@ func (c *cmdDebugState) Execute(args []string) error {
 		if err != nil {
 			return fmt.Errorf("invalid task: %s", c.TaskID)
 		}
+		if c.SetStatus != "" {
+			return c.setTaskStatus(st, c.TaskID, c.SetStatus)
+		}
 		return c.showTask(st, c.TaskID)
 	}
 
@@ -559,6 +914,10 @@ func (c *cmdDebugState) Execute(args []string) error {
 		return c.showConnections(st)
 	}
 
+	if c.Consistency {
+		return c.checkConsistency(st)
+	}
+
 	if c.Connection != "" {
 		return c.showConnectionDetails(st, c.Connection)
 	}
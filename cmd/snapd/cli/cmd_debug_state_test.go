@@ -20,6 +20,7 @@
 package cli_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -28,6 +29,9 @@ import (
 	. "gopkg.in/check.v1"
 
 	main "github.com/snapcore/snapd/cmd/snapd/cli"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/testutil"
 )
 
 var stateJSON = []byte(`
@@ -222,6 +226,109 @@ var stateCyclesJSON = []byte(`
 	}
 }`)
 
+var stateConsistencyJSON = []byte(`
+{
+	"last-task-id": 16,
+	"last-change-id": 4,
+	"last-lane-id": 1,
+
+	"data": {
+		"snaps": {
+			"core": {},
+			"foo": {}
+		},
+		"conns": {
+			"foo:network core:network": {
+				"auto": true,
+				"interface": "network"
+			},
+			"bar:x11 core:x11": {
+				"auto": true,
+				"interface": "x11"
+			},
+			"foo:content baz:content": {
+				"interface": "content"
+			}
+		}
+	},
+	"changes": {
+		"1": {
+			"id": "1",
+			"kind": "install-snap",
+			"summary": "install a snap",
+			"status": 0,
+			"task-ids": ["11","12"]
+		},
+		"2": {
+			"id": "2",
+			"kind": "refresh-snap",
+			"summary": "refresh a snap",
+			"status": 0,
+			"task-ids": ["13"]
+		},
+		"3": {
+			"id": "3",
+			"kind": "remove-snap",
+			"summary": "remove a snap",
+			"status": 0
+		},
+		"4": {
+			"id": "4",
+			"kind": "revert-snap",
+			"summary": "revert a snap",
+			"status": 0,
+			"task-ids": ["15","16"]
+		}
+	},
+	"tasks": {
+		"11": {
+			"id": "11",
+			"change": "1",
+			"kind": "foo",
+			"summary": "Foo task",
+			"halt-tasks": ["12"],
+			"lanes": [1]
+		},
+		"12": {
+			"id": "12",
+			"change": "1",
+			"kind": "bar",
+			"summary": "Bar task",
+			"wait-tasks": ["11","99"],
+			"lanes": [1]
+		},
+		"13": {
+			"id": "13",
+			"change": "2",
+			"kind": "foo",
+			"summary": "Foo task",
+			"lanes": [1]
+		},
+		"14": {
+			"id": "14",
+			"change": "9",
+			"kind": "foo",
+			"summary": "Orphaned task"
+		},
+		"15": {
+			"id": "15",
+			"change": "4",
+			"kind": "foo",
+			"summary": "Foo task",
+			"wait-tasks": ["16"],
+			"halt-tasks": ["16"]
+		},
+		"16": {
+			"id": "16",
+			"change": "4",
+			"kind": "bar",
+			"summary": "Bar task",
+			"wait-tasks": ["15"],
+			"halt-tasks": ["15"]
+		}
+	}
+}`)
+
 var stateRunHookJSON = []byte(`
 {
         "changes": {
@@ -391,6 +498,9 @@ func (s *SnapSuite) TestDebugTaskMutuallyExclusiveCommands(c *C) {
 
 	_, err = main.Parser(main.Client()).ParseArgs([]string{"debug", "state", "--change=1", "--is-seeded", stateFile})
 	c.Check(err, ErrorMatches, "cannot use --change= and --is-seeded together")
+
+	_, err = main.Parser(main.Client()).ParseArgs([]string{"debug", "state", "--changes", "--dump", stateFile})
+	c.Check(err, ErrorMatches, "cannot use --changes and --dump together")
 }
 
 func (s *SnapSuite) TestDebugTasks(c *C) {
@@ -480,6 +590,65 @@ func (s *SnapSuite) TestDebugIsSeededNo(c *C) {
 	c.Check(s.Stderr(), Equals, "")
 }
 
+func (s *SnapSuite) TestDebugStateDump(c *C) {
+	dir := c.MkDir()
+	stateFile := filepath.Join(dir, "test-state.json")
+	stateAuthJSON := []byte(`{
+	"data": {
+		"auth": {
+			"last-id": 1,
+			"users": [{"id": 1, "username": "foo", "macaroon": "snapd-macaroon", "store-macaroon": "sealed:v1:AAAA", "store-discharges": ["store-discharge"]}],
+			"device": {"brand": "my-brand", "session-macaroon": "session"},
+			"macaroon-key": "a2V5"
+		},
+		"seeded": true
+	},
+	"changes": {},
+	"tasks": {},
+	"last-change-id": 0,
+	"last-task-id": 0,
+	"last-lane-id": 0,
+	"last-notice-id": 0
+}`)
+	c.Assert(os.WriteFile(stateFile, stateAuthJSON, 0644), IsNil)
+
+	rest, err := main.Parser(main.Client()).ParseArgs([]string{"debug", "state", "--dump", stateFile})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Check(s.Stdout(), Equals, `{
+  "data": {
+    "auth": {
+      "device": {
+        "brand": "my-brand",
+        "session-macaroon": "[redacted]"
+      },
+      "last-id": 1,
+      "macaroon-key": "[redacted]",
+      "users": [
+        {
+          "id": 1,
+          "macaroon": "[redacted]",
+          "store-discharges": [
+            "[redacted]"
+          ],
+          "store-macaroon": "[redacted]",
+          "username": "foo"
+        }
+      ]
+    },
+    "seeded": true
+  },
+  "changes": {},
+  "tasks": {},
+  "last-change-id": 0,
+  "last-task-id": 0,
+  "last-lane-id": 0,
+  "last-notice-id": 0
+}
+`)
+	c.Check(s.Stderr(), Equals, "")
+}
+
 func (s *SnapSuite) TestDebugConnections(c *C) {
 	dir := c.MkDir()
 	stateFile := filepath.Join(dir, "test-state.json")
@@ -633,3 +802,259 @@ func (s *SnapSuite) TestDebugConnectionDetailsManySlotSide(c *C) {
 			"undesired: false\n"+
 			"\n")
 }
+
+func (s *SnapSuite) TestDebugConnectionsInterface(c *C) {
+	dir := c.MkDir()
+	stateFile := filepath.Join(dir, "test-state.json")
+	c.Assert(os.WriteFile(stateFile, stateConnsJSON, 0644), IsNil)
+
+	rest, err := main.Parser(main.Client()).ParseArgs([]string{"debug", "state", "--connections", "--interface=x11", stateFile})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Check(s.Stdout(), Equals,
+		"Interface  Plug                  Slot      Notes\n"+
+			"x11        gnome-calculator:x11  core:x11  auto\n"+
+			"x11        vlc:x11               core:x11  auto\n")
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestDebugConnectionsJSON(c *C) {
+	dir := c.MkDir()
+	stateFile := filepath.Join(dir, "test-state.json")
+	c.Assert(os.WriteFile(stateFile, stateConnsJSON, 0644), IsNil)
+
+	rest, err := main.Parser(main.Client()).ParseArgs([]string{"debug", "state", "--connections", "--interface=x11", "--format=json", stateFile})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Check(s.Stdout(), Equals, `[{"plug-snap":"gnome-calculator","plug-name":"x11","slot-snap":"core","slot-name":"x11","auto":true,"interface":"x11"},`+
+		`{"plug-snap":"vlc","plug-name":"x11","slot-snap":"core","slot-name":"x11","auto":true,"interface":"x11"}]`+"\n")
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestDebugConnectionDetailsInterface(c *C) {
+	dir := c.MkDir()
+	stateFile := filepath.Join(dir, "test-state.json")
+	c.Assert(os.WriteFile(stateFile, stateConnsJSON, 0644), IsNil)
+
+	rest, err := main.Parser(main.Client()).ParseArgs([]string{"debug", "state", "--connection=vlc", "--interface=network", stateFile})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Check(s.Stdout(), Equals,
+		"id: vlc:network core:network\n"+
+			"auto: true\n"+
+			"by-gadget: false\n"+
+			"interface: network\n"+
+			"undesired: true\n"+
+			"\n")
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestDebugConsistency(c *C) {
+	dir := c.MkDir()
+	stateFile := filepath.Join(dir, "test-state.json")
+	c.Assert(os.WriteFile(stateFile, stateConsistencyJSON, 0644), IsNil)
+
+	rest, err := main.Parser(main.Client()).ParseArgs([]string{"debug", "state", "--consistency", stateFile})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Check(s.Stdout(), Equals,
+		"Kind        ID                       Problem\n"+
+			"connection  bar:x11 core:x11         plug snap \"bar\" is not installed\n"+
+			"connection  foo:content baz:content  slot snap \"baz\" is not installed\n"+
+			"task        12                       task waits for a task missing from the state\n"+
+			"change      3                        change has no tasks\n"+
+			"change      4                        dependency cycle involving tasks [15:foo 16:bar]\n"+
+			"task        14                       task is not linked to any change\n"+
+			"lane        1                        lane is used by tasks of multiple changes: 1,2\n")
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestDebugConsistencyJSON(c *C) {
+	dir := c.MkDir()
+	stateFile := filepath.Join(dir, "test-state.json")
+	c.Assert(os.WriteFile(stateFile, stateConsistencyJSON, 0644), IsNil)
+
+	rest, err := main.Parser(main.Client()).ParseArgs([]string{"debug", "state", "--consistency", "--format=json", stateFile})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+
+	var problems []map[string]string
+	c.Assert(json.Unmarshal([]byte(s.Stdout()), &problems), IsNil)
+	c.Assert(problems, HasLen, 7)
+	c.Check(problems[0], DeepEquals, map[string]string{
+		"kind":    "connection",
+		"id":      "bar:x11 core:x11",
+		"problem": `plug snap "bar" is not installed`,
+	})
+	c.Check(problems[6], DeepEquals, map[string]string{
+		"kind":    "lane",
+		"id":      "1",
+		"problem": "lane is used by tasks of multiple changes: 1,2",
+	})
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestDebugConsistencyNoProblems(c *C) {
+	dir := c.MkDir()
+	stateFile := filepath.Join(dir, "test-state.json")
+	c.Assert(os.WriteFile(stateFile, stateRunHookJSON, 0644), IsNil)
+
+	rest, err := main.Parser(main.Client()).ParseArgs([]string{"debug", "state", "--consistency", stateFile})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Check(s.Stdout(), Equals, "No problems found.\n")
+
+	s.ResetStdStreams()
+	rest, err = main.Parser(main.Client()).ParseArgs([]string{"debug", "state", "--consistency", "--format=json", stateFile})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Check(s.Stdout(), Equals, "[]\n")
+}
+
+func (s *SnapSuite) TestDebugStateInvalidFlagCombinations(c *C) {
+	dir := c.MkDir()
+	stateFile := filepath.Join(dir, "test-state.json")
+	c.Assert(os.WriteFile(stateFile, stateConnsJSON, 0644), IsNil)
+
+	for _, tc := range []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"--changes", "--consistency"}, "cannot use --changes and --consistency together"},
+		{[]string{"--changes", "--interface=x11"}, "--interface can only be used with --connections or --connection="},
+		{[]string{"--changes", "--format=json"}, "--format can only be used with --connections or --consistency"},
+	} {
+		args := append([]string{"debug", "state"}, tc.args...)
+		_, err := main.Parser(main.Client()).ParseArgs(append(args, stateFile))
+		c.Check(err, ErrorMatches, tc.err, Commentf("%v", tc.args))
+	}
+}
+
+func readStateFile(c *C, path string) *state.State {
+	f, err := os.Open(path)
+	c.Assert(err, IsNil)
+	defer f.Close()
+	st, err := state.ReadState(nil, f)
+	c.Assert(err, IsNil)
+	return st
+}
+
+func (s *SnapSuite) TestDebugStateAbortChange(c *C) {
+	restore := main.MockTimeNow(func() time.Time {
+		return time.Date(2026, 10, 16, 10, 20, 30, 0, time.UTC)
+	})
+	defer restore()
+
+	dir := c.MkDir()
+	stateFile := filepath.Join(dir, "test-state.json")
+	c.Assert(os.WriteFile(stateFile, stateJSON, 0600), IsNil)
+
+	rest, err := main.Parser(main.Client()).ParseArgs([]string{"debug", "state", "--change=9", "--abort", stateFile})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	backup := stateFile + ".20261016T102030"
+	c.Check(s.Stdout(), Equals, "Change 9 aborted\nOriginal state saved to "+backup+"\n")
+	c.Check(s.Stderr(), Equals, "")
+
+	c.Check(backup, testutil.FileEquals, string(stateJSON))
+
+	st := readStateFile(c, stateFile)
+	st.Lock()
+	defer st.Unlock()
+	c.Check(st.Task("11").Status(), Equals, state.UndoStatus)
+	c.Check(st.Task("12").Status(), Equals, state.HoldStatus)
+	// other changes are untouched
+	c.Check(st.Task("21").Status(), Equals, state.DoneStatus)
+}
+
+func (s *SnapSuite) TestDebugStateAbortChangeErrors(c *C) {
+	dir := c.MkDir()
+	stateFile := filepath.Join(dir, "test-state.json")
+	c.Assert(os.WriteFile(stateFile, stateJSON, 0600), IsNil)
+
+	for _, tc := range []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"--abort"}, "--abort can only be used with --change="},
+		{[]string{"--change=9", "--abort", "--check"}, "--abort cannot be used with --dot, --no-hold or --check"},
+		{[]string{"--change=99", "--abort"}, "no such change: 99"},
+		{[]string{"--change=10", "--abort"}, "cannot abort change 10 with nothing pending"},
+	} {
+		args := append([]string{"debug", "state"}, tc.args...)
+		_, err := main.Parser(main.Client()).ParseArgs(append(args, stateFile))
+		c.Check(err, ErrorMatches, tc.err, Commentf("%v", tc.args))
+	}
+
+	// the state was not modified and no backup was taken
+	c.Check(stateFile, testutil.FileEquals, string(stateJSON))
+	matches, err := filepath.Glob(stateFile + ".*")
+	c.Assert(err, IsNil)
+	c.Check(matches, HasLen, 0)
+}
+
+func (s *SnapSuite) TestDebugStateSetTaskStatus(c *C) {
+	restore := main.MockTimeNow(func() time.Time {
+		return time.Date(2026, 10, 16, 10, 20, 30, 0, time.UTC)
+	})
+	defer restore()
+
+	dir := c.MkDir()
+	stateFile := filepath.Join(dir, "test-state.json")
+	c.Assert(os.WriteFile(stateFile, stateJSON, 0600), IsNil)
+
+	rest, err := main.Parser(main.Client()).ParseArgs([]string{"debug", "state", "--task=12", "--set-status=hold", stateFile})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Check(s.Stdout(), Equals, "Status of task 12 changed from Do to Hold\n"+
+		"Original state saved to "+stateFile+".20261016T102030\n")
+	c.Check(s.Stderr(), Equals, "")
+
+	st := readStateFile(c, stateFile)
+	st.Lock()
+	defer st.Unlock()
+	c.Check(st.Task("12").Status(), Equals, state.HoldStatus)
+	c.Check(st.Change("9").Status(), Equals, state.DoneStatus)
+}
+
+func (s *SnapSuite) TestDebugStateSetTaskStatusErrors(c *C) {
+	dir := c.MkDir()
+	stateFile := filepath.Join(dir, "test-state.json")
+	c.Assert(os.WriteFile(stateFile, stateJSON, 0600), IsNil)
+
+	for _, tc := range []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"--set-status=Hold"}, "--set-status can only be used with --task="},
+		{[]string{"--task=12", "--set-status=Wait"}, `invalid task status "Wait", must be one of: Do, Doing, Done, Abort, Undo, Undoing, Undone, Hold, Error`},
+		{[]string{"--task=99", "--set-status=Hold"}, "no such task: 99"},
+	} {
+		args := append([]string{"debug", "state"}, tc.args...)
+		_, err := main.Parser(main.Client()).ParseArgs(append(args, stateFile))
+		c.Check(err, ErrorMatches, tc.err, Commentf("%v", tc.args))
+	}
+	c.Check(stateFile, testutil.FileEquals, string(stateJSON))
+}
+
+func (s *SnapSuite) TestDebugStateEditRefusedWhileSnapdRuns(c *C) {
+	dir := c.MkDir()
+	stateFile := filepath.Join(dir, "test-state.json")
+	c.Assert(os.WriteFile(stateFile, stateJSON, 0600), IsNil)
+
+	// snapd holds the state lock while running
+	flock, err := osutil.NewFileLock(filepath.Join(dir, "state.lock"))
+	c.Assert(err, IsNil)
+	defer flock.Close()
+	c.Assert(flock.Lock(), IsNil)
+
+	_, err = main.Parser(main.Client()).ParseArgs([]string{"debug", "state", "--change=9", "--abort", stateFile})
+	c.Assert(err, ErrorMatches, "cannot edit the state file while snapd is running")
+	_, err = main.Parser(main.Client()).ParseArgs([]string{"debug", "state", "--task=12", "--set-status=Hold", stateFile})
+	c.Assert(err, ErrorMatches, "cannot edit the state file while snapd is running")
+	c.Check(stateFile, testutil.FileEquals, string(stateJSON))
+
+	// inspecting the state is still possible
+	_, err = main.Parser(main.Client()).ParseArgs([]string{"debug", "state", "--task=12", stateFile})
+	c.Assert(err, IsNil)
+}
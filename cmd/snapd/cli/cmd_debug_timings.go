@@ -33,6 +33,7 @@ import (
 
 type cmdChangeTimings struct {
 	changeIDMixin
+	formatMixin
 	EnsureTag  string `long:"ensure" choice:"auto-refresh" choice:"become-operational" choice:"refresh-catalogs" choice:"refresh-hints" choice:"seed" choice:"install-system"`
 	All        bool   `long:"all"`
 	StartupTag string `long:"startup" choice:"load-state" choice:"ifacemgr"`
@@ -51,7 +52,7 @@ func init() {
 			"startup": i18n.G("Show timings for the startup of given subsystem (one of: load-state, ifacemgr)"),
 			// TRANSLATORS: This should not start with a lowercase letter.
 			"verbose": i18n.G("Show more information"),
-		}), changeIDMixinArgDesc)
+		}).also(formatArgsHelp), changeIDMixinArgDesc)
 }
 
 type Timing struct {
@@ -164,6 +165,32 @@ func (x *cmdChangeTimings) printChangeTimings(w io.Writer, timing *timingsData)
 	return nil
 }
 
+// printSecurityBackendTimings prints the time spent in each security backend
+// summed up across all the tasks of the change.
+func (x *cmdChangeTimings) printSecurityBackendTimings(w io.Writer, timing *timingsData) {
+	totals := make(map[string]time.Duration)
+	for _, chgTiming := range timing.ChangeTimings {
+		for backend, dur := range chgTiming.SecurityBackendTimings {
+			totals[backend] += dur
+		}
+	}
+	if len(totals) == 0 {
+		return
+	}
+
+	backends := make([]string, 0, len(totals))
+	for backend := range totals {
+		backends = append(backends, backend)
+	}
+	sort.Strings(backends)
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%s\t%11s\n", i18n.G("Security backend"), i18n.G("Duration"))
+	for _, backend := range backends {
+		fmt.Fprintf(w, "%s\t%11s\n", backend, formatDuration(totals[backend]))
+	}
+}
+
 func (x *cmdChangeTimings) printEnsureTimings(w io.Writer, timings []*timingsData) error {
 	for _, td := range timings {
 		printTiming(w, x.Verbose, 0, x.EnsureTag, "", formatDuration(td.TotalDuration), "-", "", "")
@@ -199,6 +226,8 @@ type changeTimings struct {
 	UndoingTime    time.Duration `json:"undoing-time,omitempty"`
 	DoingTimings   []Timing      `json:"doing-timings,omitempty"`
 	UndoingTimings []Timing      `json:"undoing-timings,omitempty"`
+
+	SecurityBackendTimings map[string]time.Duration `json:"security-backend-timings,omitempty"`
 }
 
 type timingsData struct {
@@ -264,6 +293,10 @@ func (x *cmdChangeTimings) Execute(args []string) error {
 		return err
 	}
 
+	if x.Format != "text" && x.Format != "" {
+		return x.formatNonText(timings)
+	}
+
 	w := tabWriter()
 	if x.Verbose {
 		fmt.Fprintf(w, "ID\tStatus\t%11s\t%11s\tLabel\tSummary\n", "Doing", "Undoing")
@@ -275,6 +308,7 @@ func (x *cmdChangeTimings) Execute(args []string) error {
 	// If "ensure" activity was requested, we may get multiple elements (for multiple executions of the ensure)
 	if chgid != "" && len(timings) > 0 {
 		x.printChangeTimings(w, timings[0])
+		x.printSecurityBackendTimings(w, timings[0])
 	}
 
 	if x.EnsureTag != "" {
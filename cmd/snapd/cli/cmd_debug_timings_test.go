@@ -136,6 +136,21 @@ var timingsTests = []timingsCmdArgs{{
 	args: "debug timings 2",
 	stdout: "ID   Status        Doing      Undoing  Summary\n" +
 		"41   Undone            -        210ms  lane 0 task bar summary\n\n",
+}, {
+	args: "debug timings 3",
+	stdout: "ID   Status        Doing      Undoing  Summary\n" +
+		"50   Done          210ms            -  setup profiles\n" +
+		" ^                  12ms            -    setup security backend \"apparmor\" for snap \"foo\"\n" +
+		" ^                   3ms            -    setup security backend \"seccomp\" for snap \"foo\"\n" +
+		"51   Undone            -         20ms  setup profiles\n" +
+		" ^                     -          8ms    setup security backend \"apparmor\" for snap \"foo\"\n" +
+		"\n" +
+		"Security backend     Duration\n" +
+		"apparmor                 20ms\n" +
+		"seccomp                   3ms\n\n",
+}, {
+	args:   "debug timings 2 --format=json",
+	stdout: `[{"change-id":"1","change-timings":{"41":{"status":"Undone","kind":"baz","summary":"lane 0 task bar summary","ready-time":"2016-04-22T01:02:04Z","undoing-time":210000000}}}]` + "\n",
 },
 }
 
@@ -196,6 +211,22 @@ func (s *SnapSuite) mockCmdTimingsAPI(c *C) {
 				{"change-id":"1", "change-timings":{
 					"41":{"undoing-time":210000000, "status": "Undone", "lane": 0, "ready-time": "2016-04-22T01:02:04Z", "kind": "baz", "summary": "lane 0 task bar summary"}
 				}}]}`)
+			case changeID == "3":
+				// tasks with security backend timings
+				fmt.Fprintln(w, `{"type":"sync","status-code":200,"status":"OK","result":[
+				{"change-id":"3", "change-timings":{
+					"50":{"doing-time":210000000, "status": "Done", "ready-time": "2016-04-22T01:02:04Z", "kind": "setup-profiles", "summary": "setup profiles",
+						"doing-timings":[
+							{"label":"setup-security-backend", "summary": "setup security backend \"apparmor\" for snap \"foo\"", "duration": 12000000},
+							{"label":"setup-security-backend", "summary": "setup security backend \"seccomp\" for snap \"foo\"", "duration": 3000000}
+						],
+						"security-backend-timings": {"apparmor": 12000000, "seccomp": 3000000}},
+					"51":{"undoing-time":20000000, "status": "Undone", "ready-time": "2016-04-23T01:02:04Z", "kind": "setup-profiles", "summary": "setup profiles",
+						"undoing-timings":[
+							{"label":"setup-security-backend", "summary": "setup security backend \"apparmor\" for snap \"foo\"", "duration": 8000000}
+						],
+						"security-backend-timings": {"apparmor": 8000000}}
+				}}]}`)
 			case ensure == "seed" && all == "false":
 				fmt.Fprintln(w, `{"type":"sync","status-code":200,"status":"OK","result":[
 					{"change-id":"1",
@@ -106,7 +106,7 @@ func (x *cmdDelta) Execute(args []string) error {
 	case !x.Generate && !x.Apply:
 		return fmt.Errorf(i18n.G("one of --generate or --apply must be specified"))
 	case x.Generate:
-		supportedFormats := squashfs.SupportedDeltaFormats(squashfs.DeltaFormatOpts{WithSnapDeltaFormat: true})
+		supportedFormats := squashfs.SupportedDeltaFormats(squashfs.DeltaFormatOpts{WithSnapDeltaFormat: true, WithZstdFormat: true})
 		if x.Format == "" {
 			return fmt.Errorf(i18n.G("the --format flag is required for --generate, supported formats: %s"),
 				strings.Join(supportedFormats, ", "))
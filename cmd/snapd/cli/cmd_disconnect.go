@@ -30,8 +30,9 @@ import (
 
 type cmdDisconnect struct {
 	waitMixin
-	Forget      bool `long:"forget"`
-	Positionals struct {
+	Forget          bool `long:"forget"`
+	RestartAffected bool `long:"restart-affected"`
+	Positionals     struct {
 		Offer disconnectSlotOrPlugSpec `required:"true"`
 		Use   disconnectSlotSpec
 	} `positional-args:"true"`
@@ -55,12 +56,19 @@ When an automatic connection is manually disconnected, its disconnected state
 is retained after a snap refresh. The --forget flag can be added to the
 disconnect command to reset this behaviour, and consequently re-enable
 an automatic reconnection after a snap refresh.
+
+Services which need a restart to see the removal of the connection can be
+restarted as part of the same change with the --restart-affected flag.
 `)
 
 func init() {
 	addCommand("disconnect", shortDisconnectHelp, longDisconnectHelp, func() flags.Commander {
 		return &cmdDisconnect{}
-	}, waitDescs.also(map[string]string{"forget": "Forget remembered state about the given connection."}), []argDesc{
+	}, waitDescs.also(map[string]string{
+		"forget": "Forget remembered state about the given connection.",
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"restart-affected": i18n.G("Restart the services which need it to see the removal of the connection"),
+	}), []argDesc{
 		// TRANSLATORS: This needs to begin with < and end with >
 		{name: i18n.G("<snap>:<plug>")},
 		// TRANSLATORS: This needs to begin with < and end with >
@@ -83,7 +91,7 @@ func (x *cmdDisconnect) Execute(args []string) error {
 		offer, use = use, offer
 	}
 
-	opts := &client.DisconnectOptions{Forget: x.Forget}
+	opts := &client.DisconnectOptions{Forget: x.Forget, RestartAffected: x.RestartAffected}
 	id, err := x.client.Disconnect(offer.Snap, offer.Name, use.Snap, use.Name, opts)
 	if err != nil {
 		if client.IsInterfacesUnchangedError(err) {
@@ -93,12 +101,16 @@ func (x *cmdDisconnect) Execute(args []string) error {
 		return err
 	}
 
-	if _, err := x.wait(id); err != nil {
+	chg, err := x.wait(id)
+	if err != nil {
 		if err == noWait {
 			return nil
 		}
 		return err
 	}
+	if !x.RestartAffected {
+		showServicesNeedingRestart(chg)
+	}
 
 	return nil
 }
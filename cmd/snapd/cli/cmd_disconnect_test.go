@@ -51,10 +51,15 @@ is retained after a snap refresh. The --forget flag can be added to the
 disconnect command to reset this behaviour, and consequently re-enable
 an automatic reconnection after a snap refresh.
 
+Services which need a restart to see the removal of the connection can be
+restarted as part of the same change with the --restart-affected flag.
+
 [disconnect command options]
-      --no-wait          Do not wait for the operation to finish but just print
-                         the change id.
-      --forget           Forget remembered state about the given connection.
+      --no-wait             Do not wait for the operation to finish but just
+                            print the change id.
+      --forget              Forget remembered state about the given connection.
+      --restart-affected    Restart the services which need it to see the
+                            removal of the connection
 `
 	s.testSubCommandHelp(c, "disconnect", msg)
 }
@@ -132,6 +137,43 @@ func (s *SnapSuite) TestDisconnectWithForgetFlag(c *C) {
 	c.Assert(s.Stderr(), Equals, "")
 }
 
+func (s *SnapSuite) TestDisconnectRestartAffected(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/interfaces":
+			c.Check(r.Method, Equals, "POST")
+			c.Check(DecodedRequestBody(c, r), DeepEquals, map[string]any{
+				"action":           "disconnect",
+				"restart-affected": true,
+				"plugs": []any{
+					map[string]any{
+						"snap": "consumer",
+						"plug": "plug",
+					},
+				},
+				"slots": []any{
+					map[string]any{
+						"snap": "producer",
+						"slot": "slot",
+					},
+				},
+			})
+			w.WriteHeader(202)
+			fmt.Fprintln(w, `{"type":"async", "status-code": 202, "change": "zzz"}`)
+		case "/v2/changes/zzz":
+			c.Check(r.Method, Equals, "GET")
+			fmt.Fprintln(w, `{"type":"sync", "result":{"ready": true, "status": "Done", "data": {"affected-services": ["consumer.svc"]}}}`)
+		default:
+			c.Fatalf("unexpected path %q", r.URL.Path)
+		}
+	})
+	rest, err := Parser(Client()).ParseArgs([]string{"disconnect", "--restart-affected", "consumer:plug", "producer:slot"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Assert(s.Stdout(), Equals, "")
+	c.Assert(s.Stderr(), Equals, "")
+}
+
 func (s *SnapSuite) TestDisconnectEverythingFromSpecificSlot(c *C) {
 	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
@@ -255,7 +255,7 @@ func (x *cmdDownload) downloadFromStore(snap string, comps []string, revision sn
 	return downloadDirect(snap, comps, tooling.DownloadSnapOptions{
 		TargetDir: x.TargetDir,
 		Basename:  x.Basename,
-		Channel:   x.Channel,
+		Channel:   string(x.Channel),
 		CohortKey: x.CohortKey,
 		Revision:  revision,
 		// if something goes wrong, don't force it to start over again
@@ -26,12 +26,12 @@ import (
 	"github.com/jessevdk/go-flags"
 
 	"github.com/snapcore/snapd/asserts"
-	"github.com/snapcore/snapd/asserts/signtool"
 	"github.com/snapcore/snapd/i18n"
 )
 
 type cmdExportKey struct {
 	Account    string `long:"account"`
+	PKCS11URI  string `long:"pkcs11-uri"`
 	Positional struct {
 		KeyName keyName
 	} `positional-args:"true"`
@@ -48,6 +48,8 @@ imported by other systems.
 			return &cmdExportKey{}
 		}, map[string]string{
 			"account": i18n.G("Format public key material as a request for an account-key for this account-id"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"pkcs11-uri": i18n.G("Use the keys of the PKCS#11 token (e.g. an HSM or the TPM) selected by this PKCS#11 URI, named by their label"),
 		}, []argDesc{{
 			// TRANSLATORS: This needs to begin with < and end with >
 			name: i18n.G("<key-name>"),
@@ -67,7 +69,7 @@ func (x *cmdExportKey) Execute(args []string) error {
 		keyName = "default"
 	}
 
-	keypairMgr, err := signtool.GetKeypairManager()
+	keypairMgr, err := getKeypairManager(x.PKCS11URI)
 	if err != nil {
 		return err
 	}
@@ -206,9 +206,10 @@ var helpCategories = []helpCategory{
 		Description: i18n.G("basic snap management"),
 		Commands:    []string{"find", "info", "install", "remove", "list", "components", "component"},
 	}, {
-		Label:       i18n.G("...more"),
-		Description: i18n.G("slightly more advanced snap management"),
-		Commands:    []string{"refresh", "revert", "switch", "disable", "enable", "create-cohort"},
+		Label:           i18n.G("...more"),
+		Description:     i18n.G("slightly more advanced snap management"),
+		Commands:        []string{"refresh", "revert", "switch", "disable", "enable", "create-cohort"},
+		AllOnlyCommands: []string{"instance"},
 	}, {
 		Label:       i18n.G("History"),
 		Description: i18n.G("manage system change transactions"),
@@ -243,9 +244,10 @@ var helpCategories = []helpCategory{
 		Description: i18n.G("report issues with a specific snap"),
 		Commands:    []string{"report-issue"},
 	}, {
-		Label:       i18n.G("Device"),
-		Description: i18n.G("manage device"),
-		Commands:    []string{"model", "remodel", "reboot", "recovery"},
+		Label:           i18n.G("Device"),
+		Description:     i18n.G("manage device"),
+		Commands:        []string{"model", "remodel", "reboot", "recovery"},
+		AllOnlyCommands: []string{"cluster"},
 	}, {
 		Label:       i18n.G("Warnings"),
 		Other:       true,
@@ -20,6 +20,7 @@
 package cli
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -40,6 +41,7 @@ import (
 	"github.com/snapcore/snapd/i18n"
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/channel"
 	"github.com/snapcore/snapd/snap/snapfile"
 	"github.com/snapcore/snapd/snap/squashfs"
 	"github.com/snapcore/snapd/strutil"
@@ -51,6 +53,8 @@ type infoCmd struct {
 	timeMixin
 
 	Verbose    bool `long:"verbose"`
+	ChannelMap bool `long:"channel-map"`
+	JSON       bool `long:"json"`
 	Positional struct {
 		Snaps []anySnapName `positional-arg-name:"<snap>" required:"1"`
 	} `positional-args:"yes" required:"yes"`
@@ -64,6 +68,9 @@ The snaps can be specified by name or by path; names are looked for both in the
 store and in the installed snaps; paths can refer to a .snap file, or to a
 directory that contains an unpacked snap suitable for 'snap try' (an example
 of this would be the 'prime' directory snapcraft produces).
+
+With --channel-map --json, the complete channel map of the snaps in the store,
+for all architectures, tracks, risks and branches, is printed as JSON instead.
 `)
 
 func init() {
@@ -75,6 +82,10 @@ func init() {
 		}, colorDescs.also(timeDescs).also(map[string]string{
 			// TRANSLATORS: This should not start with a lowercase letter.
 			"verbose": i18n.G("Include more details on the snap (expanded notes, base, etc.)"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"channel-map": i18n.G("Show the complete channel map of the snap in the store, for all architectures"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"json": i18n.G("Print the channel map as JSON"),
 		}), nil)
 }
 
@@ -322,6 +333,48 @@ func (iw *infoWriter) maybePrintTrackingChannel() {
 		return
 	}
 	fmt.Fprintf(iw, "tracking:\t%s\n", iw.localSnap.TrackingChannel)
+	iw.maybePrintTrackEndOfLife()
+}
+
+// maybePrintTrackEndOfLife prints the end-of-life metadata the store has
+// for the track being followed, if any.
+func (iw *infoWriter) maybePrintTrackEndOfLife() {
+	if iw.remoteSnap == nil || len(iw.remoteSnap.TrackInfos) == 0 {
+		return
+	}
+	tracking, err := channel.ParseVerbatim(iw.localSnap.TrackingChannel, "-")
+	if err != nil {
+		return
+	}
+	track, risk := tracking.Track, tracking.Risk
+	if track == "" {
+		track = "latest"
+	}
+	if risk == "" {
+		risk = "stable"
+	}
+	trackInfo := iw.remoteSnap.TrackInfos[track]
+	if trackInfo == nil {
+		return
+	}
+	if !trackInfo.EndOfLife.IsZero() {
+		fmt.Fprintf(iw, "track-end-of-life:\t%s\n", iw.fmtTime(trackInfo.EndOfLife))
+	}
+	if trackInfo.Successor != "" {
+		fmt.Fprintf(iw, "track-successor:\t%s\n", trackInfo.Successor)
+	}
+	if !trackInfo.EndOfLifeReached(timeNow()) {
+		return
+	}
+	if trackInfo.Successor != "" {
+		// TRANSLATORS: the first %q is the track name, the second %q is the
+		// successor track name, followed by the command to switch to it
+		fmt.Fprintf(iw, i18n.G("warning:\ttrack %q reached its end of life, switch to %q with: snap refresh --channel=%s/%s %s\n"),
+			track, trackInfo.Successor, trackInfo.Successor, risk, iw.localSnap.Name)
+	} else {
+		// TRANSLATORS: %q is the track name
+		fmt.Fprintf(iw, i18n.G("warning:\ttrack %q reached its end of life\n"), track)
+	}
 }
 
 func (iw *infoWriter) maybePrintRefreshInfo() {
@@ -685,7 +738,50 @@ func (chInfos *channelInfos) dump(w io.Writer) {
 	}
 }
 
+// snapChannelMap is how "snap info --channel-map --json" prints the channel
+// map of a snap. It is part of the interface of the command and must be kept
+// stable.
+type snapChannelMap struct {
+	Name       string                  `json:"name"`
+	SnapID     string                  `json:"snap-id,omitempty"`
+	ChannelMap []*snap.ChannelSnapInfo `json:"channel-map"`
+}
+
+func (x *infoCmd) showChannelMaps() error {
+	channelMaps := make([]snapChannelMap, 0, len(x.Positional.Snaps))
+	for _, snapName := range x.Positional.Snaps {
+		snapName := string(snapName)
+		remoteSnap, _, err := x.client.FindOneWithOptions(snap.InstanceSnap(snapName), &client.FindOneOptions{ChannelMap: true})
+		if err != nil {
+			return err
+		}
+		channelMap := remoteSnap.ChannelMap
+		if channelMap == nil {
+			channelMap = []*snap.ChannelSnapInfo{}
+		}
+		channelMaps = append(channelMaps, snapChannelMap{
+			Name:       remoteSnap.Name,
+			SnapID:     remoteSnap.ID,
+			ChannelMap: channelMap,
+		})
+	}
+
+	enc := json.NewEncoder(Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(channelMaps)
+}
+
 func (x *infoCmd) Execute([]string) error {
+	if x.ChannelMap != x.JSON {
+		return errors.New(i18n.G("--channel-map and --json can only be used together"))
+	}
+	if x.ChannelMap {
+		if x.Verbose {
+			return errors.New(i18n.G("cannot use --verbose with --channel-map"))
+		}
+		return x.showChannelMaps()
+	}
+
 	termWidth, _ := termSize()
 	termWidth -= 3
 	if termWidth > 100 {
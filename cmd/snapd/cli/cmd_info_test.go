@@ -550,6 +550,60 @@ func (s *infoSuite) TestMaybePrintHoldingNonUTCLocalTime(c *check.C) {
 	c.Assert(buf.String(), check.Equals, "hold:\tin 4 days, at 14:00 UTC+4\n")
 }
 
+func (s *infoSuite) TestMaybePrintTrackingChannelEndOfLife(c *check.C) {
+	var buf flushBuffer
+	iw := snap.NewInfoWriterWithFmtTime(&buf, func(t time.Time) string { return t.Format("2006-01-02") })
+	restore := snap.MockTimeNow(func() time.Time {
+		return time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	})
+	defer restore()
+
+	remote := &client.Snap{
+		TrackInfos: map[string]*snaplib.TrackInfo{
+			"9": {
+				EndOfLife: time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC),
+				Successor: "10",
+			},
+			"10": {
+				EndOfLife: time.Date(2026, 4, 30, 0, 0, 0, 0, time.UTC),
+			},
+			"latest": {
+				EndOfLife: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	for tracking, expected := range map[string]string{
+		"9/beta": "tracking:\t9/beta\n" +
+			"track-end-of-life:\t2024-04-30\n" +
+			"track-successor:\t10\n" +
+			"warning:\ttrack \"9\" reached its end of life, switch to \"10\" with: snap refresh --channel=10/beta foo\n",
+		"9": "tracking:\t9\n" +
+			"track-end-of-life:\t2024-04-30\n" +
+			"track-successor:\t10\n" +
+			"warning:\ttrack \"9\" reached its end of life, switch to \"10\" with: snap refresh --channel=10/stable foo\n",
+		"10/stable": "tracking:\t10/stable\n" +
+			"track-end-of-life:\t2026-04-30\n",
+		"stable": "tracking:\tstable\n" +
+			"track-end-of-life:\t2024-01-01\n" +
+			"warning:\ttrack \"latest\" reached its end of life\n",
+		"11/stable": "tracking:\t11/stable\n",
+	} {
+		buf.Reset()
+		snap.SetupSnap(iw, &client.Snap{Name: "foo", TrackingChannel: tracking}, remote, nil)
+		snap.MaybePrintTrackingChannel(iw)
+		iw.Flush()
+		c.Check(buf.String(), check.Equals, expected, check.Commentf(tracking))
+	}
+
+	// no metadata without the remote snap
+	buf.Reset()
+	snap.SetupSnap(iw, &client.Snap{Name: "foo", TrackingChannel: "9/stable"}, nil, nil)
+	snap.MaybePrintTrackingChannel(iw)
+	iw.Flush()
+	c.Check(buf.String(), check.Equals, "tracking:\t9/stable\n")
+}
+
 func (s *infoSuite) TestMaybePrintLinksVerbose(c *check.C) {
 	var buf flushBuffer
 	iw := snap.NewInfoWriter(&buf)
@@ -957,6 +1011,104 @@ func (s *infoSuite) TestInfoNotFound(c *check.C) {
 	c.Check(s.Stderr(), check.Equals, "")
 }
 
+func (s *infoSuite) TestInfoChannelMapJSON(c *check.C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, check.Equals, "GET")
+		c.Check(r.URL.Path, check.Equals, "/v2/find")
+		c.Check(r.URL.Query().Get("channel-map"), check.Equals, "true")
+		switch n {
+		case 0:
+			c.Check(r.URL.Query().Get("name"), check.Equals, "hello")
+			fmt.Fprint(w, `{"type": "sync", "result": [{
+  "name": "hello",
+  "id": "hello-id",
+  "channel-map": [
+    {"architecture": "amd64", "channel": "latest/stable", "revision": "1", "version": "1.0", "epoch": {"read": [0], "write": [0]}, "confinement": "strict", "size": 1024, "released-at": "2019-01-02T03:04:05Z"},
+    {"architecture": "arm64", "channel": "2.0/beta/fix", "revision": "2", "version": "2.0", "epoch": {"read": [0], "write": [0]}, "confinement": "classic", "size": 2048, "released-at": "2019-02-03T04:05:06Z"}
+  ]
+}]}`)
+		case 1:
+			// the instance key is dropped
+			c.Check(r.URL.Query().Get("name"), check.Equals, "other")
+			fmt.Fprint(w, `{"type": "sync", "result": [{"name": "other", "id": "other-id"}]}`)
+		default:
+			c.Fatalf("expected to get 2 requests, now on %d", n+1)
+		}
+		n++
+	})
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"info", "--channel-map", "--json", "hello", "other_foo"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.DeepEquals, []string{})
+	c.Check(n, check.Equals, 2)
+	c.Check(s.Stdout(), check.Equals, `[
+  {
+    "name": "hello",
+    "snap-id": "hello-id",
+    "channel-map": [
+      {
+        "revision": "1",
+        "confinement": "strict",
+        "version": "1.0",
+        "channel": "latest/stable",
+        "epoch": {
+          "read": [
+            0
+          ],
+          "write": [
+            0
+          ]
+        },
+        "size": 1024,
+        "released-at": "2019-01-02T03:04:05Z",
+        "architecture": "amd64"
+      },
+      {
+        "revision": "2",
+        "confinement": "classic",
+        "version": "2.0",
+        "channel": "2.0/beta/fix",
+        "epoch": {
+          "read": [
+            0
+          ],
+          "write": [
+            0
+          ]
+        },
+        "size": 2048,
+        "released-at": "2019-02-03T04:05:06Z",
+        "architecture": "arm64"
+      }
+    ]
+  },
+  {
+    "name": "other",
+    "snap-id": "other-id",
+    "channel-map": []
+  }
+]
+`)
+	c.Check(s.Stderr(), check.Equals, "")
+}
+
+func (s *infoSuite) TestInfoChannelMapInvalidArgs(c *check.C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Fatalf("unexpected request to %q", r.URL.Path)
+	})
+	for _, tc := range []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"info", "--channel-map", "foo"}, "--channel-map and --json can only be used together"},
+		{[]string{"info", "--json", "foo"}, "--channel-map and --json can only be used together"},
+		{[]string{"info", "--channel-map", "--json", "--verbose", "foo"}, "cannot use --verbose with --channel-map"},
+	} {
+		_, err := snap.Parser(snap.Client()).ParseArgs(tc.args)
+		c.Check(err, check.ErrorMatches, tc.err, check.Commentf("%v", tc.args))
+	}
+}
+
 func (s *infoSuite) TestInfoWithLocalNoLicense(c *check.C) {
 	n := 0
 	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,151 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/i18n"
+)
+
+var shortInstanceHelp = i18n.G("Manage parallel instances of snaps")
+var longInstanceHelp = i18n.G(`
+The instance command manages parallel instances of a snap, that is snaps
+installed under a name with an instance key, like "foo_bar".
+`)
+
+var shortInstanceRenameHelp = i18n.G("Rename a snap instance")
+var longInstanceRenameHelp = i18n.G(`
+The instance rename command installs the current revision of the given snap
+instance under the new instance name, carrying over its data, the state of
+its services, its connections and its manual aliases, and then removes the
+old instance. Previous revisions of the snap instance are not kept.
+`)
+
+var shortInstanceCopyDataHelp = i18n.G("Copy the data of a snap instance to another one")
+var longInstanceCopyDataHelp = i18n.G(`
+The instance copy-data command replaces the data of the target snap instance
+with a copy of the data of the source instance of the same snap. The services
+of both instances are stopped while the data is copied.
+`)
+
+type instanceNames struct {
+	From installedSnapName `required:"yes"`
+	To   string            `required:"yes"`
+}
+
+type cmdInstanceRename struct {
+	waitMixin
+	Positionals instanceNames `positional-args:"true"`
+}
+
+type cmdInstanceCopyData struct {
+	waitMixin
+	Positionals instanceNames `positional-args:"true"`
+}
+
+type cmdInstance struct {
+	Rename   cmdInstanceRename   `command:"rename"`
+	CopyData cmdInstanceCopyData `command:"copy-data"`
+}
+
+func init() {
+	cmd := addCommand("instance", shortInstanceHelp, longInstanceHelp, func() flags.Commander {
+		return &cmdInstance{}
+	}, nil, nil)
+	cmd.extra = func(cmd *flags.Command) {
+		for _, sub := range []struct {
+			name, short, long string
+		}{
+			{"rename", shortInstanceRenameHelp, longInstanceRenameHelp},
+			{"copy-data", shortInstanceCopyDataHelp, longInstanceCopyDataHelp},
+		} {
+			subcmd := cmd.Find(sub.name)
+			subcmd.ShortDescription = sub.short
+			subcmd.LongDescription = strings.TrimSpace(sub.long)
+			for _, opt := range subcmd.Options() {
+				opt.Description = waitDescs[opt.LongName]
+			}
+			args := subcmd.Args()
+			// TRANSLATORS: This needs to begin with < and end with >
+			args[0].Name = i18n.G("<from-instance>")
+			// TRANSLATORS: This needs to begin with < and end with >
+			args[1].Name = i18n.G("<to-instance>")
+		}
+	}
+}
+
+func (x *cmdInstance) setClient(cli *client.Client) {
+	x.Rename.setClient(cli)
+	x.CopyData.setClient(cli)
+}
+
+func (x *cmdInstance) Execute(args []string) error {
+	return flag.ErrHelp
+}
+
+func (x *cmdInstanceRename) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	from, to := string(x.Positionals.From), x.Positionals.To
+	id, err := x.client.RenameInstance(from, to)
+	if err != nil {
+		return err
+	}
+	if _, err := x.wait(id); err != nil {
+		if err == noWait {
+			return nil
+		}
+		return err
+	}
+
+	// TRANSLATORS: the first %q is the old snap instance name, the second %q the new one
+	fmt.Fprintf(Stdout, i18n.G("Snap %q renamed to %q\n"), from, to)
+	return nil
+}
+
+func (x *cmdInstanceCopyData) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	from, to := string(x.Positionals.From), x.Positionals.To
+	id, err := x.client.CopyInstanceData(from, to)
+	if err != nil {
+		return err
+	}
+	if _, err := x.wait(id); err != nil {
+		if err == noWait {
+			return nil
+		}
+		return err
+	}
+
+	// TRANSLATORS: the first %q is the source snap instance name, the second %q the target one
+	fmt.Fprintf(Stdout, i18n.G("Data of snap %q copied to snap %q\n"), from, to)
+	return nil
+}
@@ -0,0 +1,87 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli_test
+
+import (
+	"fmt"
+	"net/http"
+
+	. "gopkg.in/check.v1"
+
+	. "github.com/snapcore/snapd/cmd/snapd/cli"
+)
+
+func (s *SnapSuite) mockInstanceServer(c *C, action string) *int {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		n++
+		switch r.URL.Path {
+		case "/v2/instances":
+			c.Check(r.Method, Equals, "POST")
+			c.Check(DecodedRequestBody(c, r), DeepEquals, map[string]any{
+				"action": action,
+				"from":   "foo_one",
+				"to":     "foo_two",
+			})
+			w.WriteHeader(202)
+			fmt.Fprintln(w, `{"type":"async", "status-code": 202, "change": "zzz"}`)
+		case "/v2/changes/zzz":
+			c.Check(r.Method, Equals, "GET")
+			fmt.Fprintln(w, `{"type":"sync", "result":{"ready": true, "status": "Done"}}`)
+		default:
+			c.Fatalf("unexpected path %q", r.URL.Path)
+		}
+	})
+	return &n
+}
+
+func (s *SnapSuite) TestInstanceRename(c *C) {
+	n := s.mockInstanceServer(c, "rename")
+	rest, err := Parser(Client()).ParseArgs([]string{"instance", "rename", "foo_one", "foo_two"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Check(*n, Equals, 2)
+	c.Check(s.Stdout(), Equals, "Snap \"foo_one\" renamed to \"foo_two\"\n")
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestInstanceCopyData(c *C) {
+	n := s.mockInstanceServer(c, "copy-data")
+	rest, err := Parser(Client()).ParseArgs([]string{"instance", "copy-data", "foo_one", "foo_two"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Check(*n, Equals, 2)
+	c.Check(s.Stdout(), Equals, "Data of snap \"foo_one\" copied to snap \"foo_two\"\n")
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestInstanceCopyDataNoWait(c *C) {
+	n := s.mockInstanceServer(c, "copy-data")
+	rest, err := Parser(Client()).ParseArgs([]string{"instance", "copy-data", "--no-wait", "foo_one", "foo_two"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Check(*n, Equals, 1)
+	c.Check(s.Stdout(), Equals, "zzz\n")
+}
+
+func (s *SnapSuite) TestInstanceMissingArgs(c *C) {
+	_, err := Parser(Client()).ParseArgs([]string{"instance", "rename", "foo_one"})
+	c.Check(err, ErrorMatches, "the required argument `<to-instance>` was not provided")
+}
@@ -37,6 +37,7 @@ type cmdInterface struct {
 	clientMixin
 	ShowAttrs   bool `long:"attrs"`
 	ShowAll     bool `long:"all"`
+	Verbose     bool `long:"verbose"`
 	Positionals struct {
 		Interface interfaceName `skip-help:"true"`
 	} `positional-args:"true"`
@@ -48,6 +49,10 @@ The interface command shows details of snap interfaces.
 
 If no interface name is provided, a list of interface names with at least
 one connection is shown, or a list of all interfaces if --all is provided.
+
+With --verbose, the details of an interface include the attributes its plugs
+and slots must have, the snaps that typically use it and notes about its
+security implications.
 `)
 
 func init() {
@@ -58,6 +63,8 @@ func init() {
 		"attrs": i18n.G("Show interface attributes"),
 		// TRANSLATORS: This should not start with a lowercase letter.
 		"all": i18n.G("Include unused interfaces"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"verbose": i18n.G("Show more details of a specific interface"),
 	}, []argDesc{{
 		// TRANSLATORS: This needs to begin with < and end with >
 		name: i18n.G("<interface>"),
@@ -117,6 +124,14 @@ func (x *cmdInterface) showOneInterface(iface *client.Interface) {
 	if iface.DocURL != "" {
 		fmt.Fprintf(w, "documentation:\t%s\n", iface.DocURL)
 	}
+	if x.Verbose {
+		if iface.Consumers != "" {
+			fmt.Fprintf(w, "consumers:\t%s\n", iface.Consumers)
+		}
+		x.showList(w, "required-plug-attributes", iface.RequiredPlugAttrs)
+		x.showList(w, "required-slot-attributes", iface.RequiredSlotAttrs)
+		x.showList(w, "security-notes", iface.SecurityNotes)
+	}
 	if len(iface.Plugs) > 0 {
 		fmt.Fprintf(w, "plugs:\n")
 		for _, plug := range iface.Plugs {
@@ -163,6 +178,16 @@ func (x *cmdInterface) showOneInterface(iface *client.Interface) {
 	}
 }
 
+func (x *cmdInterface) showList(w io.Writer, name string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%s:\n", name)
+	for _, item := range items {
+		fmt.Fprintf(w, "  - %s\n", item)
+	}
+}
+
 func (x *cmdInterface) showManyInterfaces(infos []*client.Interface) {
 	w := tabWriter()
 	defer w.Flush()
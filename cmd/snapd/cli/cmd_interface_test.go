@@ -40,9 +40,14 @@ The interface command shows details of snap interfaces.
 If no interface name is provided, a list of interface names with at least
 one connection is shown, or a list of all interfaces if --all is provided.
 
+With --verbose, the details of an interface include the attributes its plugs
+and slots must have, the snaps that typically use it and notes about its
+security implications.
+
 [interface command options]
       --attrs          Show interface attributes
       --all            Include unused interfaces
+      --verbose        Show more details of a specific interface
 
 [interface command arguments]
   <interface>:         Show details of a specific interface
@@ -192,6 +197,46 @@ func (s *SnapSuite) TestInterfaceDetails(c *C) {
 	c.Assert(s.Stderr(), Equals, "")
 }
 
+func (s *SnapSuite) TestInterfaceDetailsVerbose(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/interfaces")
+		c.Check(r.URL.RawQuery, Equals, "doc=true&names=gpio&plugs=true&select=all&slots=true")
+		EncodeResponseBody(c, w, map[string]any{
+			"type": "sync",
+			"result": []*client.Interface{{
+				Name:              "gpio",
+				Summary:           "allows access to specific GPIO pin",
+				DocURL:            "https://snapcraft.io/docs/gpio-interface",
+				RequiredSlotAttrs: []string{"number"},
+				Consumers:         "applications driving hardware through GPIO lines",
+				SecurityNotes: []string{
+					"slots can only be provided by snaps of type core, gadget",
+					"slot connections are not established automatically",
+				},
+				Slots: []client.Slot{{Snap: "pi", Name: "bcm-gpio-4"}},
+			}},
+		})
+	})
+	rest, err := Parser(Client()).ParseArgs([]string{"interface", "--verbose", "gpio"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	expectedStdout := "" +
+		"name:          gpio\n" +
+		"summary:       allows access to specific GPIO pin\n" +
+		"documentation: https://snapcraft.io/docs/gpio-interface\n" +
+		"consumers:     applications driving hardware through GPIO lines\n" +
+		"required-slot-attributes:\n" +
+		"  - number\n" +
+		"security-notes:\n" +
+		"  - slots can only be provided by snaps of type core, gadget\n" +
+		"  - slot connections are not established automatically\n" +
+		"slots:\n" +
+		"  - pi:bcm-gpio-4\n"
+	c.Assert(s.Stdout(), Equals, expectedStdout)
+	c.Assert(s.Stderr(), Equals, "")
+}
+
 func (s *SnapSuite) TestInterfaceDetailsAndAttrs(c *C) {
 	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
 		c.Check(r.Method, Equals, "GET")
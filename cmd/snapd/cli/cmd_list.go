@@ -48,6 +48,7 @@ type cmdList struct {
 
 	All bool `long:"all"`
 	colorMixin
+	structuredOutputMixin
 }
 
 func init() {
@@ -92,6 +93,42 @@ func fmtVersion(v string) string {
 	return v
 }
 
+// listOutput is the structured output of the list command for each snap.
+type listOutput struct {
+	Name            string `json:"name" yaml:"name"`
+	Version         string `json:"version" yaml:"version"`
+	Revision        string `json:"revision" yaml:"revision"`
+	TrackingChannel string `json:"tracking-channel,omitempty" yaml:"tracking-channel,omitempty"`
+	Publisher       string `json:"publisher,omitempty" yaml:"publisher,omitempty"`
+	Type            string `json:"type" yaml:"type"`
+	Confinement     string `json:"confinement" yaml:"confinement"`
+	Disabled        bool   `json:"disabled,omitempty" yaml:"disabled,omitempty"`
+	DevMode         bool   `json:"devmode,omitempty" yaml:"devmode,omitempty"`
+	JailMode        bool   `json:"jailmode,omitempty" yaml:"jailmode,omitempty"`
+}
+
+func (x *cmdList) writeStructured(snaps []*client.Snap) error {
+	out := make([]listOutput, 0, len(snaps))
+	for _, snap := range snaps {
+		lo := listOutput{
+			Name:            snap.Name,
+			Version:         snap.Version,
+			Revision:        snap.Revision.String(),
+			TrackingChannel: snap.TrackingChannel,
+			Type:            snap.Type,
+			Confinement:     snap.Confinement,
+			Disabled:        snap.Status != client.StatusActive,
+			DevMode:         snap.DevMode,
+			JailMode:        snap.JailMode,
+		}
+		if snap.Publisher != nil {
+			lo.Publisher = snap.Publisher.Username
+		}
+		out = append(out, lo)
+	}
+	return x.writeStructuredOutput(out)
+}
+
 func (x *cmdList) Execute(args []string) error {
 	if len(args) > 0 {
 		return ErrExtraArgs
@@ -101,6 +138,9 @@ func (x *cmdList) Execute(args []string) error {
 	snaps, err := x.client.List(names, &client.ListOptions{All: x.All})
 	if err != nil {
 		if err == client.ErrNoSnapsInstalled {
+			if len(names) == 0 && x.structuredOutput() {
+				return x.writeStructured(nil)
+			}
 			if len(names) == 0 {
 				fmt.Fprintln(Stderr, i18n.G("No snaps are installed yet. Try 'snap install hello-world'."))
 				return nil
@@ -114,6 +154,10 @@ func (x *cmdList) Execute(args []string) error {
 	}
 	sort.Sort(snapsByName(snaps))
 
+	if x.structuredOutput() {
+		return x.writeStructured(snaps)
+	}
+
 	esc := x.getEscapes()
 	w := tabWriter()
 
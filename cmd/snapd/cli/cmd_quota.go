@@ -103,6 +103,11 @@ The journal limits can be increased and decreased after being set on a group.
 Setting a journal limit will cause the snaps in the group to be put into the same
 journal namespace. This will affect the behaviour of the log command.
 
+The journal namespace of a group can be forwarded to a remote server with
+--journal-forward, either a remote journal server (https://host[:port]) or a
+syslog server over UDP (syslog://host[:port]) or TCP (syslog+tcp://host[:port]).
+Forwarding is disabled again with --journal-forward=none.
+
 New quotas can be set on existing quota groups, but existing quotas cannot be removed
 from a quota group, without removing and recreating the entire group.
 
@@ -122,6 +127,7 @@ func init() {
 			"threads":            i18n.G("Threads quota as a positive integer (e.g. 512)"),
 			"journal-size":       i18n.G("Journal size quota as <number><unit> (e.g. 16MB)"),
 			"journal-rate-limit": i18n.G("Journal rate limit as <message count>/<message period> (e.g. 100/1s, 1000/1m)"),
+			"journal-forward":    i18n.G("Forward the journal to a remote server as https://<host>[:<port>], syslog://<host>[:<port>] or syslog+tcp://<host>[:<port>], or none"),
 			"parent":             i18n.G("Parent quota group"),
 		}), nil)
 	addCommand("quota", shortQuotaHelp, longQuotaHelp, func() flags.Commander { return &cmdQuota{} }, nil, nil)
@@ -138,6 +144,7 @@ type cmdSetQuota struct {
 	ThreadsMax       string `long:"threads" optional:"true"`
 	JournalSizeMax   string `long:"journal-size" optional:"true"`
 	JournalRateLimit string `long:"journal-rate-limit" optional:"true"`
+	JournalForward   string `long:"journal-forward" optional:"true"`
 	Parent           string `long:"parent" optional:"true"`
 	Positional       struct {
 		GroupName string        `positional-arg-name:"<group-name>" required:"true"`
@@ -244,7 +251,7 @@ func (x *cmdSetQuota) parseQuotas() (*client.QuotaValues, error) {
 		quotaValues.Threads = int(value)
 	}
 
-	if x.JournalSizeMax != "" || x.JournalRateLimit != "" {
+	if x.JournalSizeMax != "" || x.JournalRateLimit != "" || x.JournalForward != "" {
 		quotaValues.Journal = &client.QuotaJournalValues{}
 		if x.JournalSizeMax != "" {
 			value, err := strutil.ParseByteSize(x.JournalSizeMax)
@@ -264,6 +271,9 @@ func (x *cmdSetQuota) parseQuotas() (*client.QuotaValues, error) {
 				RatePeriod: period,
 			}
 		}
+
+		// the target is validated by snapd
+		quotaValues.Journal.Forward = x.JournalForward
 	}
 
 	return &quotaValues, nil
@@ -271,7 +281,8 @@ func (x *cmdSetQuota) parseQuotas() (*client.QuotaValues, error) {
 
 func (x *cmdSetQuota) hasQuotaSet() bool {
 	return x.MemoryMax != "" || x.CPUMax != "" || x.CPUSet != "" ||
-		x.ThreadsMax != "" || x.JournalSizeMax != "" || x.JournalRateLimit != ""
+		x.ThreadsMax != "" || x.JournalSizeMax != "" || x.JournalRateLimit != "" ||
+		x.JournalForward != ""
 }
 
 func (x *cmdSetQuota) splitSnapsAndServices() (snaps []string, services []string) {
@@ -437,6 +448,9 @@ func (x *cmdQuota) Execute(args []string) (err error) {
 				group.Constraints.Journal.RateCount,
 				group.Constraints.Journal.RatePeriod)
 		}
+		if group.Constraints.Journal.Forward != "" {
+			fmt.Fprintf(w, "  journal-forward:\t%s\n", group.Constraints.Journal.Forward)
+		}
 	}
 
 	memoryUsage := "0B"
@@ -212,6 +212,7 @@ func (s *quotaSuite) TestParseQuotas(c *check.C) {
 		threadsMax       string
 		journalSizeMax   string
 		journalRateLimit string
+		journalForward   string
 
 		// Use the JSON representation of the quota, as it's easier to handle in the test data
 		quotas string
@@ -227,6 +228,9 @@ func (s *quotaSuite) TestParseQuotas(c *check.C) {
 		{journalRateLimit: "1500/15ms", quotas: `{"journal":{"rate-count":1500,"rate-period":15000000}}`},
 		{journalRateLimit: "1/15us", quotas: `{"journal":{"rate-count":1,"rate-period":15000}}`},
 		{journalRateLimit: "0/0s", quotas: `{"journal":{"rate-count":0,"rate-period":0}}`},
+		{journalForward: "syslog://logs.example.com", quotas: `{"journal":{"forward":"syslog://logs.example.com"}}`},
+		{journalForward: "none", quotas: `{"journal":{"forward":"none"}}`},
+		{journalSizeMax: "16MB", journalForward: "https://logs.example.com", quotas: `{"journal":{"size":16000000,"forward":"https://logs.example.com"}}`},
 
 		// Error cases
 		{cpuMax: "ASD", err: `cannot parse cpu quota string "ASD"`},
@@ -245,7 +249,7 @@ func (s *quotaSuite) TestParseQuotas(c *check.C) {
 		{journalRateLimit: "1/wow", err: `cannot parse journal rate limit "1/wow": cannot parse period: time: invalid duration ["]?wow["]?`},
 	} {
 		quotas, err := main.ParseQuotaValues(testData.maxMemory, testData.cpuMax,
-			testData.cpuSet, testData.threadsMax, testData.journalSizeMax, testData.journalRateLimit)
+			testData.cpuSet, testData.threadsMax, testData.journalSizeMax, testData.journalRateLimit, testData.journalForward)
 		testLabel := check.Commentf("%v", testData)
 		if testData.err == "" {
 			c.Check(err, check.IsNil, testLabel)
@@ -517,6 +521,34 @@ current:
 	c.Check(s.quotaGetGroupHandlerCalls, check.Equals, 1)
 }
 
+func (s *quotaSuite) TestJournalQuotaGroupForward(c *check.C) {
+	const jsonTemplate = `{
+		"type": "sync",
+		"status-code": 200,
+		"result": {
+			"group-name": "foo",
+			"constraints": {"journal":{"size":1048576,"forward":"syslog+tcp://logs.example.com:6514"}}
+		}
+	}`
+
+	s.RedirectClientToTestServer(s.makeFakeGetQuotaGroupHandler(c, jsonTemplate))
+
+	outputTemplate := `
+name:  foo
+constraints:
+  journal-size:     1.05MB
+  journal-forward:  syslog+tcp://logs.example.com:6514
+current:
+`[1:]
+
+	rest, err := main.Parser(main.Client()).ParseArgs([]string{"quota", "foo"})
+	c.Assert(err, check.IsNil)
+	c.Check(rest, check.HasLen, 0)
+	c.Check(s.Stderr(), check.Equals, "")
+	c.Check(s.Stdout(), check.Equals, outputTemplate)
+	c.Check(s.quotaGetGroupHandlerCalls, check.Equals, 1)
+}
+
 func (s *quotaSuite) TestSetQuotaGroupCreateNew(c *check.C) {
 	const postJSON = `{"type": "async", "status-code": 202,"change":"42", "result": []}`
 	fakeHandlerOpts := fakeQuotaGroupPostHandlerOpts{
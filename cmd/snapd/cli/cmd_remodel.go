@@ -42,6 +42,9 @@ Snaps and assertions are downloaded from the store unless they are provided as
 local files specified by --snap and --assertion options. If using these
 options, it is expected that all the needed snaps and assertions are provided
 locally, otherwise the remodel will fail.
+
+With --dry-run the changes the remodel would make are shown without
+remodeling the device.
 `)
 )
 
@@ -50,6 +53,7 @@ type cmdRemodel struct {
 	SnapFiles      []string `long:"snap"`
 	AssertionFiles []string `long:"assertion"`
 	Offline        bool     `long:"offline"`
+	DryRun         bool     `long:"dry-run"`
 	RemodelOptions struct {
 		NewModelFile flags.Filename
 	} `positional-args:"true" required:"true"`
@@ -66,6 +70,7 @@ func init() {
 			"snap":      i18n.G("Use one or more locally available snaps."),
 			"assertion": i18n.G("Use one or more locally available assertion files."),
 			"offline":   i18n.G("Use only pre-installed and locally provided snaps and assertions. Providing any snaps or assertions locally implies --offline."),
+			"dry-run":   i18n.G("Show what the remodel would do without remodeling."),
 		}),
 		[]argDesc{{
 			// TRANSLATORS: This needs to begin with < and end with >
@@ -85,6 +90,20 @@ func (x *cmdRemodel) Execute(args []string) error {
 		return err
 	}
 
+	if x.DryRun {
+		if len(x.SnapFiles) > 0 || len(x.AssertionFiles) > 0 {
+			return fmt.Errorf(i18n.G("cannot use --dry-run with --snap or --assertion"))
+		}
+		plan, err := x.client.RemodelPreview(modelData, client.RemodelOpts{
+			Offline: x.Offline,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot preview remodel: %v", err)
+		}
+		showRemodelPlan(plan)
+		return nil
+	}
+
 	var changeID string
 	if len(x.SnapFiles) > 0 || len(x.AssertionFiles) > 0 {
 		// don't log the request's body as it will be large
@@ -111,3 +130,34 @@ func (x *cmdRemodel) Execute(args []string) error {
 	fmt.Fprintf(Stdout, i18n.G("New model %s set\n"), newModelFile)
 	return nil
 }
+
+func fmtRemodelPlanModel(m client.RemodelPlanModel) string {
+	// TRANSLATORS: %s/%s are the brand and model, %d is the revision
+	return fmt.Sprintf(i18n.G("%s/%s (revision %d)"), m.BrandID, m.Model, m.Revision)
+}
+
+func showRemodelPlan(plan *client.RemodelPlan) {
+	// TRANSLATORS: the first two %s are models, the last one the kind of remodel
+	fmt.Fprintf(Stdout, i18n.G("Remodel from %s to %s (%s remodel)\n"),
+		fmtRemodelPlanModel(plan.CurrentModel), fmtRemodelPlanModel(plan.NewModel), plan.Kind)
+
+	if plan.Kind == "re-registration" {
+		fmt.Fprintln(Stdout, i18n.G("Snap changes are only known after the device is registered with the new model."))
+		return
+	}
+	if len(plan.Snaps) == 0 {
+		fmt.Fprintln(Stdout, i18n.G("No snap changes."))
+		return
+	}
+
+	w := tabWriter()
+	fmt.Fprintln(w, i18n.G("Name\tAction\tChannel\tRevision"))
+	for _, sn := range plan.Snaps {
+		rev := "-"
+		if !sn.Revision.Unset() {
+			rev = sn.Revision.String()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", sn.Name, sn.Action, fmtChannel(sn.Channel), rev)
+	}
+	w.Flush()
+}
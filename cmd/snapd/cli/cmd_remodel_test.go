@@ -142,3 +142,74 @@ func (s *SnapSuite) TestRemodelLocalSnapsError(c *C) {
 
 	s.ResetStdStreams()
 }
+
+func (s *SnapSuite) TestRemodelDryRun(c *C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "POST")
+		c.Check(r.URL.Path, Equals, "/v2/model")
+
+		var req map[string]any
+		err := json.NewDecoder(r.Body).Decode(&req)
+		c.Assert(err, IsNil)
+		c.Check(req["dry-run"], Equals, true)
+
+		fmt.Fprint(w, `{"type": "sync", "status-code": 200, "result": {
+  "kind": "update",
+  "current-model": {"brand-id": "my-brand", "model": "my-model", "revision": 1},
+  "new-model": {"brand-id": "my-brand", "model": "my-model", "revision": 2},
+  "snaps": [
+    {"name": "foo", "action": "install", "channel": "latest/stable", "revision": "12"},
+    {"name": "pc-kernel", "action": "switch-channel", "channel": "22/stable", "revision": "3"}
+  ]
+}}`)
+		n++
+	})
+
+	modelPath := filepath.Join(dirs.GlobalRootDir, "new-model")
+	err := os.WriteFile(modelPath, []byte("snap1"), 0644)
+	c.Assert(err, IsNil)
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"remodel", "--dry-run", modelPath})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Assert(n, Equals, 1)
+
+	c.Check(s.Stdout(), Equals, `
+Remodel from my-brand/my-model (revision 1) to my-brand/my-model (revision 2) (update remodel)
+Name       Action          Channel        Revision
+foo        install         latest/stable  12
+pc-kernel  switch-channel  22/stable      3
+`[1:])
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestRemodelDryRunRereg(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"type": "sync", "status-code": 200, "result": {
+  "kind": "re-registration",
+  "current-model": {"brand-id": "my-brand", "model": "my-model", "revision": 1},
+  "new-model": {"brand-id": "my-brand", "model": "other-model", "revision": 0}
+}}`)
+	})
+
+	modelPath := filepath.Join(dirs.GlobalRootDir, "new-model")
+	err := os.WriteFile(modelPath, []byte("snap1"), 0644)
+	c.Assert(err, IsNil)
+
+	_, err = snap.Parser(snap.Client()).ParseArgs([]string{"remodel", "--dry-run", modelPath})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, `
+Remodel from my-brand/my-model (revision 1) to my-brand/other-model (revision 0) (re-registration remodel)
+Snap changes are only known after the device is registered with the new model.
+`[1:])
+}
+
+func (s *SnapSuite) TestRemodelDryRunLocalSnaps(c *C) {
+	modelPath := filepath.Join(dirs.GlobalRootDir, "new-model")
+	err := os.WriteFile(modelPath, []byte("snap1"), 0644)
+	c.Assert(err, IsNil)
+
+	_, err = snap.Parser(snap.Client()).ParseArgs([]string{"remodel", "--dry-run", "--snap", "foo.snap", modelPath})
+	c.Assert(err, ErrorMatches, "cannot use --dry-run with --snap or --assertion")
+}
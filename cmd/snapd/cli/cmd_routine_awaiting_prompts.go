@@ -0,0 +1,167 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/i18n"
+)
+
+type cmdRoutineAwaitingPrompts struct {
+	clientMixin
+	Reply       string `long:"reply" choice:"allow" choice:"deny"`
+	Lifespan    string `long:"lifespan" choice:"single" choice:"session" choice:"timespan" choice:"forever" default:"single"`
+	Duration    string `long:"duration"`
+	PathPattern string `long:"path-pattern"`
+	Permissions string `long:"permissions"`
+	Positional  struct {
+		ID string `positional-arg-name:"<prompt-id>"`
+	} `positional-args:"true"`
+}
+
+var shortRoutineAwaitingPromptsHelp = i18n.G("List and reply to prompts awaiting a reply")
+var longRoutineAwaitingPromptsHelp = i18n.G(`
+The awaiting-prompts command lists the AppArmor prompting requests of the
+current user that are awaiting a reply. Given a prompt ID, it shows the
+details of that prompt, and with --reply it replies to it.
+
+By default a reply applies once, to the requested path and permissions.
+--lifespan, --duration, --path-pattern and --permissions (a comma separated
+list) make it apply more widely.
+
+This command lets headless systems with prompting enabled handle prompts
+without a graphical prompting client.
+`)
+
+func init() {
+	addRoutineCommand("awaiting-prompts", shortRoutineAwaitingPromptsHelp, longRoutineAwaitingPromptsHelp, func() flags.Commander {
+		return &cmdRoutineAwaitingPrompts{}
+	}, map[string]string{
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"reply": i18n.G("Reply to the prompt, either allow or deny"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"lifespan": i18n.G("How long the reply applies (default: single)"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"duration": i18n.G("Duration of a reply with the timespan lifespan, e.g. 10m"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"path-pattern": i18n.G("Path pattern the reply applies to (default: the requested path)"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"permissions": i18n.G("Permissions the reply applies to (default: the requested permissions)"),
+	}, []argDesc{{
+		// TRANSLATORS: This needs to begin with < and end with >
+		name: i18n.G("<prompt-id>"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		desc: i18n.G("ID of the prompt to show or reply to"),
+	}})
+}
+
+func (x *cmdRoutineAwaitingPrompts) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+	id := x.Positional.ID
+	if id == "" {
+		if x.Reply != "" {
+			return fmt.Errorf(i18n.G("cannot reply without a prompt ID"))
+		}
+		return x.listPrompts()
+	}
+	prompt, err := x.client.Prompt(id)
+	if err != nil {
+		return err
+	}
+	if x.Reply == "" {
+		x.showPrompt(prompt)
+		return nil
+	}
+	return x.replyToPrompt(prompt)
+}
+
+func (x *cmdRoutineAwaitingPrompts) listPrompts() error {
+	prompts, err := x.client.Prompts()
+	if err != nil {
+		return err
+	}
+	if len(prompts) == 0 {
+		fmt.Fprintln(Stderr, i18n.G("No prompts are awaiting a reply."))
+		return nil
+	}
+
+	w := tabWriter()
+	fmt.Fprintln(w, i18n.G("ID\tTimestamp\tSnap\tInterface\tPath\tPermissions"))
+	for _, p := range prompts {
+		path := p.Constraints.Path
+		if path == "" {
+			path = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", p.ID, p.Timestamp.Format(time.RFC3339), p.Snap, p.Interface, path, strings.Join(p.Constraints.RequestedPermissions, ","))
+	}
+	w.Flush()
+	return nil
+}
+
+func (x *cmdRoutineAwaitingPrompts) showPrompt(p *client.Prompt) {
+	fmt.Fprintf(Stdout, "id:\t%s\n", p.ID)
+	fmt.Fprintf(Stdout, "timestamp:\t%s\n", p.Timestamp.Format(time.RFC3339))
+	fmt.Fprintf(Stdout, "snap:\t%s\n", p.Snap)
+	fmt.Fprintf(Stdout, "pid:\t%d\n", p.PID)
+	fmt.Fprintf(Stdout, "interface:\t%s\n", p.Interface)
+	if p.Constraints.Path != "" {
+		fmt.Fprintf(Stdout, "path:\t%s\n", p.Constraints.Path)
+	}
+	fmt.Fprintf(Stdout, "requested-permissions:\t%s\n", strings.Join(p.Constraints.RequestedPermissions, ","))
+	fmt.Fprintf(Stdout, "available-permissions:\t%s\n", strings.Join(p.Constraints.AvailablePermissions, ","))
+}
+
+func (x *cmdRoutineAwaitingPrompts) replyToPrompt(p *client.Prompt) error {
+	if x.Duration != "" && x.Lifespan != "timespan" {
+		return fmt.Errorf(i18n.G("cannot use --duration without --lifespan=timespan"))
+	}
+	reply := &client.PromptReply{
+		Action:   x.Reply,
+		Lifespan: x.Lifespan,
+		Duration: x.Duration,
+		Constraints: client.PromptReplyConstraints{
+			PathPattern: x.PathPattern,
+			Permissions: p.Constraints.RequestedPermissions,
+		},
+	}
+	if reply.Constraints.PathPattern == "" {
+		reply.Constraints.PathPattern = p.Constraints.Path
+	}
+	if x.Permissions != "" {
+		reply.Constraints.Permissions = strings.Split(x.Permissions, ",")
+	}
+
+	satisfied, err := x.client.ReplyToPrompt(p.ID, reply)
+	if err != nil {
+		return err
+	}
+	for _, id := range satisfied {
+		fmt.Fprintln(Stdout, id)
+	}
+	return nil
+}
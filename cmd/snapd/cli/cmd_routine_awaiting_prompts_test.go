@@ -0,0 +1,154 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	. "gopkg.in/check.v1"
+
+	snap "github.com/snapcore/snapd/cmd/snapd/cli"
+)
+
+const mockAwaitingPromptJSON = `{
+  "id": "0000000000000002",
+  "timestamp": "2024-08-14T09:47:03Z",
+  "snap": "firefox",
+  "pid": 1234,
+  "cgroup": "0::/user.slice/user-1000.slice/user@1000.service/app.slice/snap.firefox.firefox.scope",
+  "interface": "home",
+  "constraints": {
+    "path": "/home/test/Downloads/foo.pdf",
+    "requested-permissions": ["read"],
+    "available-permissions": ["read", "write", "execute"]
+  }
+}`
+
+func (s *SnapSuite) TestAwaitingPromptsList(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/interfaces/requests/prompts")
+		fmt.Fprintf(w, `{"type": "sync", "result": [%s]}`, mockAwaitingPromptJSON)
+	})
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"routine", "awaiting-prompts"})
+	c.Assert(err, IsNil)
+	c.Check(rest, HasLen, 0)
+	c.Check(s.Stdout(), Equals, `
+ID                Timestamp             Snap     Interface  Path                          Permissions
+0000000000000002  2024-08-14T09:47:03Z  firefox  home       /home/test/Downloads/foo.pdf  read
+`[1:])
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestAwaitingPromptsListEmpty(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"type": "sync", "result": []}`)
+	})
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"routine", "awaiting-prompts"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, "")
+	c.Check(s.Stderr(), Equals, "No prompts are awaiting a reply.\n")
+}
+
+func (s *SnapSuite) TestAwaitingPromptsShow(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/interfaces/requests/prompts/0000000000000002")
+		fmt.Fprintf(w, `{"type": "sync", "result": %s}`, mockAwaitingPromptJSON)
+	})
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"routine", "awaiting-prompts", "0000000000000002"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, `
+id:	0000000000000002
+timestamp:	2024-08-14T09:47:03Z
+snap:	firefox
+pid:	1234
+interface:	home
+path:	/home/test/Downloads/foo.pdf
+requested-permissions:	read
+available-permissions:	read,write,execute
+`[1:])
+}
+
+func (s *SnapSuite) TestAwaitingPromptsReply(c *C) {
+	for _, tc := range []struct {
+		args     []string
+		expected map[string]any
+	}{{
+		args: []string{"--reply=deny"},
+		expected: map[string]any{
+			"action":   "deny",
+			"lifespan": "single",
+			"constraints": map[string]any{
+				"path-pattern": "/home/test/Downloads/foo.pdf",
+				"permissions":  []any{"read"},
+			},
+		},
+	}, {
+		args: []string{"--reply=allow", "--lifespan=timespan", "--duration=10m", "--path-pattern=/home/test/Downloads/*.pdf", "--permissions=read,write"},
+		expected: map[string]any{
+			"action":   "allow",
+			"lifespan": "timespan",
+			"duration": "10m",
+			"constraints": map[string]any{
+				"path-pattern": "/home/test/Downloads/*.pdf",
+				"permissions":  []any{"read", "write"},
+			},
+		},
+	}} {
+		s.ResetStdStreams()
+		n := 0
+		s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+			c.Check(r.URL.Path, Equals, "/v2/interfaces/requests/prompts/0000000000000002")
+			switch n {
+			case 0:
+				c.Check(r.Method, Equals, "GET")
+				fmt.Fprintf(w, `{"type": "sync", "result": %s}`, mockAwaitingPromptJSON)
+			case 1:
+				c.Check(r.Method, Equals, "POST")
+				var body map[string]any
+				c.Check(json.NewDecoder(r.Body).Decode(&body), IsNil)
+				c.Check(body, DeepEquals, tc.expected)
+				fmt.Fprint(w, `{"type": "sync", "result": ["0000000000000002", "0000000000000005"]}`)
+			default:
+				c.Fatalf("expected 2 queries, got %d", n+1)
+			}
+			n++
+		})
+		args := append([]string{"routine", "awaiting-prompts", "0000000000000002"}, tc.args...)
+		_, err := snap.Parser(snap.Client()).ParseArgs(args)
+		c.Assert(err, IsNil)
+		c.Check(n, Equals, 2)
+		c.Check(s.Stdout(), Equals, "0000000000000002\n0000000000000005\n")
+	}
+}
+
+func (s *SnapSuite) TestAwaitingPromptsReplyErrors(c *C) {
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"routine", "awaiting-prompts", "--reply=allow"})
+	c.Check(err, ErrorMatches, "cannot reply without a prompt ID")
+
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"type": "sync", "result": %s}`, mockAwaitingPromptJSON)
+	})
+	_, err = snap.Parser(snap.Client()).ParseArgs([]string{"routine", "awaiting-prompts", "0000000000000002", "--reply=allow", "--duration=10m"})
+	c.Check(err, ErrorMatches, "cannot use --duration without --lifespan=timespan")
+}
@@ -0,0 +1,282 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+	"gopkg.in/yaml.v2"
+
+	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/mount"
+	"github.com/snapcore/snapd/interfaces/seccomp"
+	"github.com/snapcore/snapd/interfaces/systemd"
+	"github.com/snapcore/snapd/interfaces/udev"
+	"github.com/snapcore/snapd/snap"
+)
+
+type cmdRoutineGenerateProfile struct {
+	Interface    interfaceName `long:"interface" required:"yes"`
+	PlugAttrs    []string      `long:"plug-attr"`
+	SlotAttrs    []string      `long:"slot-attr"`
+	SlotSnapType string        `long:"slot-snap-type" choice:"core" choice:"app" choice:"gadget" default:"core"`
+}
+
+var shortRoutineGenerateProfileHelp = i18n.G("Preview the security profiles generated for an interface")
+var longRoutineGenerateProfileHelp = i18n.G(`
+The generate-profile command prints the AppArmor, seccomp, udev, mount and
+systemd snippets that would be generated for a connection of the given
+interface, without installing anything.
+
+The connection is between the "app" application of a hypothetical snap
+named "plug-snap" and a slot provided by the core snap or, as selected
+with --slot-snap-type, by a hypothetical application or gadget snap.
+
+Attributes of the plug and of the slot are given as key=value, where the
+value is YAML, e.g. --plug-attr 'write=[$HOME/.config/foo]'.
+
+This command is meant to help developing interfaces and reviewing snap
+declarations.
+`)
+
+func init() {
+	addRoutineCommand("generate-profile", shortRoutineGenerateProfileHelp, longRoutineGenerateProfileHelp, func() flags.Commander {
+		return &cmdRoutineGenerateProfile{}
+	}, map[string]string{
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"interface": i18n.G("Interface of the connection"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"plug-attr": i18n.G("Attribute of the plug, as key=value"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"slot-attr": i18n.G("Attribute of the slot, as key=value"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"slot-snap-type": i18n.G("Type of the snap providing the slot"),
+	}, nil)
+}
+
+const (
+	generateProfilePlugSnap = "plug-snap"
+	generateProfileApp      = "app"
+)
+
+func parseGenerateProfileAttrs(attrs []string) (map[string]any, error) {
+	m := make(map[string]any, len(attrs))
+	for _, attr := range attrs {
+		key, value, ok := strings.Cut(attr, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf(i18n.G("invalid attribute %q (want key=value)"), attr)
+		}
+		var v any
+		if err := yaml.Unmarshal([]byte(value), &v); err != nil {
+			return nil, fmt.Errorf(i18n.G("cannot parse value of attribute %q: %v"), key, err)
+		}
+		m[key] = v
+	}
+	return m, nil
+}
+
+// generateProfileSnap returns the info of a hypothetical snap with the
+// given plug or slot, after they are sanitized like when installing it.
+func generateProfileSnap(snapYaml map[string]any, kind, ifaceName string, attrs map[string]any) (*snap.Info, error) {
+	plugOrSlot := map[string]any{"interface": ifaceName}
+	for k, v := range attrs {
+		plugOrSlot[k] = v
+	}
+	snapYaml[kind] = map[string]any{ifaceName: plugOrSlot}
+	if apps, ok := snapYaml["apps"].(map[string]any); ok {
+		apps[generateProfileApp] = map[string]any{
+			"command": "bin/" + generateProfileApp,
+			kind:      []string{ifaceName},
+		}
+	}
+
+	data, err := yaml.Marshal(snapYaml)
+	if err != nil {
+		return nil, err
+	}
+	info, err := snap.InfoFromSnapYaml(data)
+	if err != nil {
+		return nil, err
+	}
+	builtin.SanitizePlugsSlots(info)
+	if reason, ok := info.BadInterfaces[ifaceName]; ok {
+		// TRANSLATORS: the first %s is either "plugs" or "slots"
+		return nil, fmt.Errorf(i18n.G("invalid %s of snap %q: %s"), kind, info.InstanceName(), reason)
+	}
+	return info, nil
+}
+
+func (x *cmdRoutineGenerateProfile) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	ifaceName := string(x.Interface)
+	var iface interfaces.Interface
+	for _, i := range builtin.Interfaces() {
+		if i.Name() == ifaceName {
+			iface = i
+			break
+		}
+	}
+	if iface == nil {
+		return fmt.Errorf(i18n.G("unknown interface %q"), ifaceName)
+	}
+
+	plugAttrs, err := parseGenerateProfileAttrs(x.PlugAttrs)
+	if err != nil {
+		return err
+	}
+	slotAttrs, err := parseGenerateProfileAttrs(x.SlotAttrs)
+	if err != nil {
+		return err
+	}
+
+	plugInfo, err := generateProfileSnap(map[string]any{
+		"name":    generateProfilePlugSnap,
+		"version": "1",
+		"apps":    map[string]any{},
+	}, "plugs", ifaceName, plugAttrs)
+	if err != nil {
+		return err
+	}
+	slotSnapYaml := map[string]any{"version": "1"}
+	switch x.SlotSnapType {
+	case "core":
+		slotSnapYaml["name"] = "core"
+		slotSnapYaml["type"] = "os"
+	case "gadget":
+		slotSnapYaml["name"] = "slot-snap"
+		slotSnapYaml["type"] = "gadget"
+	default:
+		slotSnapYaml["name"] = "slot-snap"
+		slotSnapYaml["apps"] = map[string]any{}
+	}
+	slotInfo, err := generateProfileSnap(slotSnapYaml, "slots", ifaceName, slotAttrs)
+	if err != nil {
+		return err
+	}
+
+	plugAppSet, err := interfaces.NewSnapAppSet(plugInfo, nil)
+	if err != nil {
+		return err
+	}
+	slotAppSet, err := interfaces.NewSnapAppSet(slotInfo, nil)
+	if err != nil {
+		return err
+	}
+	plug := interfaces.NewConnectedPlug(plugInfo.Plugs[ifaceName], plugAppSet, nil, nil)
+	slot := interfaces.NewConnectedSlot(slotInfo.Slots[ifaceName], slotAppSet, nil, nil)
+
+	for _, side := range []struct {
+		appSet *interfaces.SnapAppSet
+		add    func(spec interfaces.Specification) error
+	}{{
+		appSet: plugAppSet,
+		add: func(spec interfaces.Specification) error {
+			if err := spec.AddPermanentPlug(iface, plugInfo.Plugs[ifaceName]); err != nil {
+				return err
+			}
+			return spec.AddConnectedPlug(iface, plug, slot)
+		},
+	}, {
+		appSet: slotAppSet,
+		add: func(spec interfaces.Specification) error {
+			if err := spec.AddPermanentSlot(iface, slotInfo.Slots[ifaceName]); err != nil {
+				return err
+			}
+			return spec.AddConnectedSlot(iface, plug, slot)
+		},
+	}} {
+		if err := x.printSnippets(side.appSet, side.add); err != nil {
+			return fmt.Errorf(i18n.G("cannot generate snippets: %v"), err)
+		}
+	}
+	return nil
+}
+
+func printGenerateProfileSection(header, content string) {
+	if content == "" {
+		return
+	}
+	fmt.Fprintf(Stdout, "### %s\n%s\n", header, strings.TrimRight(content, "\n"))
+}
+
+func (x *cmdRoutineGenerateProfile) printSnippets(appSet *interfaces.SnapAppSet, add func(spec interfaces.Specification) error) error {
+	snapName := appSet.InstanceName()
+
+	aaSpec := apparmor.NewSpecification(appSet)
+	if err := add(aaSpec); err != nil {
+		return err
+	}
+	for _, tag := range aaSpec.SecurityTags() {
+		printGenerateProfileSection(fmt.Sprintf("apparmor %s", tag), aaSpec.SnippetForTag(tag))
+	}
+
+	seccompSpec := seccomp.NewSpecification(appSet)
+	if err := add(seccompSpec); err != nil {
+		return err
+	}
+	for _, tag := range seccompSpec.SecurityTags() {
+		printGenerateProfileSection(fmt.Sprintf("seccomp %s", tag), seccompSpec.SnippetForTag(tag))
+	}
+
+	udevSpec := udev.NewSpecification(appSet)
+	if err := add(udevSpec); err != nil {
+		return err
+	}
+	printGenerateProfileSection(fmt.Sprintf("udev %s", snapName), strings.Join(udevSpec.Snippets(), "\n"))
+
+	mountSpec := &mount.Specification{}
+	if err := add(mountSpec); err != nil {
+		return err
+	}
+	var entries []string
+	for _, entry := range mountSpec.MountEntries() {
+		entries = append(entries, entry.String())
+	}
+	printGenerateProfileSection(fmt.Sprintf("mount %s", snapName), strings.Join(entries, "\n"))
+	entries = nil
+	for _, entry := range mountSpec.UserMountEntries() {
+		entries = append(entries, entry.String())
+	}
+	printGenerateProfileSection(fmt.Sprintf("user mount %s", snapName), strings.Join(entries, "\n"))
+
+	systemdSpec := &systemd.Specification{}
+	if err := add(systemdSpec); err != nil {
+		return err
+	}
+	services := systemdSpec.Services()
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		printGenerateProfileSection(fmt.Sprintf("systemd %s", name), services[name].String())
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli_test
+
+import (
+	"net/http"
+
+	. "gopkg.in/check.v1"
+
+	snap "github.com/snapcore/snapd/cmd/snapd/cli"
+	"github.com/snapcore/snapd/testutil"
+)
+
+func (s *SnapSuite) mockNoSnapdForGenerateProfile(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Fatalf("unexpected request to %s", r.URL.Path)
+	})
+}
+
+func (s *SnapSuite) TestRoutineGenerateProfile(c *C) {
+	s.mockNoSnapdForGenerateProfile(c)
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"routine", "generate-profile", "--interface=home", "--plug-attr=read=all"})
+	c.Assert(err, IsNil)
+	c.Check(rest, DeepEquals, []string{})
+	c.Check(s.Stdout(), testutil.Contains, "### apparmor snap.plug-snap.app\n")
+	c.Check(s.Stdout(), testutil.Contains, `owner @{HOME}/[^s.]**`)
+	// the read attribute allows reading files of other users
+	c.Check(s.Stdout(), testutil.Contains, "capability dac_read_search,")
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestRoutineGenerateProfileSlotSnapType(c *C) {
+	s.mockNoSnapdForGenerateProfile(c)
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"routine", "generate-profile", "--interface=content", "--slot-snap-type=app", "--plug-attr=target=$SNAP/data", "--slot-attr=read=[$SNAP/data]"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), testutil.Contains, "### apparmor snap.plug-snap.app\n")
+	c.Check(s.Stdout(), testutil.Contains, "### mount plug-snap\n")
+}
+
+func (s *SnapSuite) TestRoutineGenerateProfileErrors(c *C) {
+	s.mockNoSnapdForGenerateProfile(c)
+
+	for _, t := range []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"--interface=no-such-interface"}, `unknown interface "no-such-interface"`},
+		{[]string{"--interface=home", "--plug-attr=read"}, `invalid attribute "read" \(want key=value\)`},
+		{[]string{"--interface=home", "--plug-attr=read=[all"}, `cannot parse value of attribute "read": .*`},
+		{[]string{"--interface=home", "--plug-attr=read=some"}, `invalid plugs of snap "plug-snap": .*`},
+		{[]string{"--interface=home", "extra"}, `too many arguments for command`},
+	} {
+		args := append([]string{"routine", "generate-profile"}, t.args...)
+		_, err := snap.Parser(snap.Client()).ParseArgs(args)
+		c.Check(err, ErrorMatches, t.err, Commentf("%v", t.args))
+	}
+	c.Check(s.Stdout(), Equals, "")
+}
@@ -0,0 +1,84 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/osutil"
+)
+
+type cmdRoutineGenerateSBOM struct {
+	clientMixin
+	Format string         `long:"format" choice:"spdx" choice:"cyclonedx" default:"spdx"`
+	Output flags.Filename `long:"output"`
+}
+
+var shortRoutineGenerateSBOMHelp = i18n.G("Generate a software bill of materials of the installed snaps")
+var longRoutineGenerateSBOMHelp = i18n.G(`
+The generate-sbom command prints a software bill of materials of the
+device, as SPDX or CycloneDX JSON.
+
+The bill of materials lists the installed snaps with their version,
+revision, publisher, digest, tracked channel and base, as well as the
+validation sets in force on the device.
+`)
+
+func init() {
+	addRoutineCommand("generate-sbom", shortRoutineGenerateSBOMHelp, longRoutineGenerateSBOMHelp, func() flags.Commander {
+		return &cmdRoutineGenerateSBOM{}
+	}, map[string]string{
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"format": i18n.G("Format of the bill of materials"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"output": i18n.G("Write the bill of materials to the given file"),
+	}, nil)
+}
+
+func (x *cmdRoutineGenerateSBOM) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	doc, err := x.client.SBOM(x.Format)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, doc, "", "  "); err != nil {
+		return fmt.Errorf(i18n.G("cannot format bill of materials: %v"), err)
+	}
+	buf.WriteByte('\n')
+
+	if x.Output != "" {
+		if err := osutil.AtomicWriteFile(string(x.Output), buf.Bytes(), 0644, 0); err != nil {
+			return fmt.Errorf(i18n.G("cannot write bill of materials: %v"), err)
+		}
+		return nil
+	}
+	_, err = Stdout.Write(buf.Bytes())
+	return err
+}
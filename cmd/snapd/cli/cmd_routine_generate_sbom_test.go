@@ -0,0 +1,83 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli_test
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	snap "github.com/snapcore/snapd/cmd/snapd/cli"
+	"github.com/snapcore/snapd/testutil"
+)
+
+func (s *SnapSuite) mockSBOMServer(c *C, expectedFormat string) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/sbom")
+		c.Check(r.URL.Query().Get("format"), Equals, expectedFormat)
+		fmt.Fprintln(w, `{"type": "sync", "result": {"bomFormat": "CycloneDX", "components": [{"name": "hello"}]}}`)
+	})
+}
+
+const expectedSBOMOutput = `{
+  "bomFormat": "CycloneDX",
+  "components": [
+    {
+      "name": "hello"
+    }
+  ]
+}
+`
+
+func (s *SnapSuite) TestRoutineGenerateSBOM(c *C) {
+	s.mockSBOMServer(c, "cyclonedx")
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"routine", "generate-sbom", "--format", "cyclonedx"})
+	c.Assert(err, IsNil)
+	c.Check(rest, HasLen, 0)
+	c.Check(s.Stdout(), Equals, expectedSBOMOutput)
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestRoutineGenerateSBOMDefaultFormat(c *C) {
+	s.mockSBOMServer(c, "spdx")
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"routine", "generate-sbom"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, expectedSBOMOutput)
+}
+
+func (s *SnapSuite) TestRoutineGenerateSBOMOutput(c *C) {
+	s.mockSBOMServer(c, "spdx")
+	output := filepath.Join(c.MkDir(), "sbom.json")
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"routine", "generate-sbom", "--output", output})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, "")
+	c.Check(output, testutil.FileEquals, expectedSBOMOutput)
+}
+
+func (s *SnapSuite) TestRoutineGenerateSBOMBadFormat(c *C) {
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"routine", "generate-sbom", "--format", "swid"})
+	c.Assert(err, ErrorMatches, `Invalid value .swid. for option .--format.*`)
+}
@@ -0,0 +1,271 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snapdtool"
+	"github.com/snapcore/snapd/systemd"
+)
+
+type cmdRoutineUmountAll struct {
+	clientMixin
+	Positional struct {
+		Snap installedSnapName `required:"yes"`
+	} `positional-args:"yes"`
+}
+
+var shortRoutineUmountAllHelp = i18n.G("Tear down the mounts of a snap")
+var longRoutineUmountAllHelp = i18n.G(`
+The umount-all command unmounts all revisions and components of the given
+snap, discards its mount namespaces and removes its residual mount units.
+
+It is meant to recover from a removal of the snap that keeps failing because
+its mounts are busy. The snap must not be active. If any process still uses
+files of the snap, the processes are listed and nothing is unmounted. Mounts
+are never detached lazily.
+
+The command requires root privileges.
+`)
+
+func init() {
+	addRoutineCommand("umount-all", shortRoutineUmountAllHelp, longRoutineUmountAllHelp, func() flags.Commander {
+		return &cmdRoutineUmountAll{}
+	}, nil, []argDesc{{
+		// TRANSLATORS: This needs to begin with < and end with >
+		name: i18n.G("<snap>"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		desc: i18n.G("Snap to tear down the mounts of"),
+	}})
+}
+
+var procDir = "/proc"
+
+// busyProcess is a process that uses a file under the mount point of a
+// snap.
+type busyProcess struct {
+	pid     int
+	command string
+	path    string
+}
+
+// processUsing returns the first path used by the given process that is
+// under dir, checking its working and root directories, executable, open
+// files and memory mappings.
+func processUsing(pidDir, dir string) string {
+	under := func(path string) bool {
+		return path == dir || strings.HasPrefix(path, dir+"/")
+	}
+
+	for _, name := range []string{"cwd", "root", "exe"} {
+		if target, err := os.Readlink(filepath.Join(pidDir, name)); err == nil && under(target) {
+			return target
+		}
+	}
+	fds, _ := os.ReadDir(filepath.Join(pidDir, "fd"))
+	for _, fd := range fds {
+		if target, err := os.Readlink(filepath.Join(pidDir, "fd", fd.Name())); err == nil && under(target) {
+			return target
+		}
+	}
+	f, err := os.Open(filepath.Join(pidDir, "maps"))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// address perms offset dev inode pathname
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 6 && under(fields[5]) {
+			return fields[5]
+		}
+	}
+	return ""
+}
+
+// processesUsing returns the processes that use files under dir. Processes
+// that cannot be inspected, e.g. because they exited meanwhile, are
+// skipped.
+func processesUsing(dir string) ([]busyProcess, error) {
+	entries, err := os.ReadDir(procDir)
+	if err != nil {
+		return nil, err
+	}
+	var procs []busyProcess
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		pidDir := filepath.Join(procDir, entry.Name())
+		path := processUsing(pidDir, dir)
+		if path == "" {
+			continue
+		}
+		comm, _ := os.ReadFile(filepath.Join(pidDir, "comm"))
+		procs = append(procs, busyProcess{
+			pid:     pid,
+			command: strings.TrimSpace(string(comm)),
+			path:    path,
+		})
+	}
+	sort.Slice(procs, func(i, j int) bool { return procs[i].pid < procs[j].pid })
+	return procs, nil
+}
+
+func reportBusyProcesses(snapName string, procs []busyProcess) error {
+	fmt.Fprintf(Stdout, i18n.G("The mounts of snap %q are in use by the following processes:\n"), snapName)
+	w := tabWriter()
+	fmt.Fprintln(w, i18n.G("PID\tCommand\tPath"))
+	for _, p := range procs {
+		fmt.Fprintf(w, "%d\t%s\t%s\n", p.pid, p.command, p.path)
+	}
+	w.Flush()
+	return fmt.Errorf(i18n.G("cannot unmount snap %q: mounts are in use by %d processes"), snapName, len(procs))
+}
+
+// snapMountPoints returns the mount points under dir, most nested first.
+func snapMountPoints(dir string) ([]string, error) {
+	entries, err := osutil.LoadMountInfo()
+	if err != nil {
+		return nil, err
+	}
+	var mountPoints []string
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.MountDir, dir+"/") || seen[entry.MountDir] {
+			continue
+		}
+		seen[entry.MountDir] = true
+		mountPoints = append(mountPoints, entry.MountDir)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(mountPoints)))
+	return mountPoints, nil
+}
+
+// snapMountUnits returns the paths of the mount units, persistent or
+// transient, of mount points under dir.
+func snapMountUnits(dir string) ([]string, error) {
+	prefix := systemd.EscapeUnitNamePath(dirs.StripRootDir(dir)) + "-"
+	var units []string
+	for _, unitDir := range []string{dirs.SnapServicesDir, dirs.SnapRuntimeServicesDir} {
+		entries, err := os.ReadDir(unitDir)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if strings.HasPrefix(name, prefix) && strings.HasSuffix(name, ".mount") {
+				units = append(units, filepath.Join(unitDir, name))
+			}
+		}
+	}
+	return units, nil
+}
+
+func (x *cmdRoutineUmountAll) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	snapName := string(x.Positional.Snap)
+	if err := snap.ValidateInstanceName(snapName); err != nil {
+		return err
+	}
+	// snapd may well be unavailable or wedged, only refuse to proceed if
+	// it says that the snap is in use
+	if info, _, err := x.client.Snap(snapName); err == nil && info.Status == client.StatusActive {
+		return fmt.Errorf(i18n.G("cannot unmount snap %q: snap is active, disable or remove it first"), snapName)
+	}
+
+	snapDir := filepath.Join(dirs.SnapMountDir, snapName)
+	procs, err := processesUsing(snapDir)
+	if err != nil {
+		return fmt.Errorf(i18n.G("cannot list processes using snap %q: %v"), snapName, err)
+	}
+	if len(procs) > 0 {
+		return reportBusyProcesses(snapName, procs)
+	}
+
+	// the preserved mount namespaces hold on to the mounts of the snap,
+	// so they go first
+	toolPath, err := snapdtool.InternalToolPath("snap-discard-ns")
+	if err != nil {
+		return fmt.Errorf(i18n.G("cannot find snap-discard-ns: %v"), err)
+	}
+	if output, err := exec.Command(toolPath, snapName).CombinedOutput(); err != nil {
+		return fmt.Errorf(i18n.G("cannot discard mount namespaces of snap %q: %v"), snapName, osutil.OutputErr(output, err))
+	}
+	fmt.Fprintf(Stdout, i18n.G("Discarded mount namespaces of snap %q\n"), snapName)
+
+	mountPoints, err := snapMountPoints(snapDir)
+	if err != nil {
+		return fmt.Errorf(i18n.G("cannot list mounts of snap %q: %v"), snapName, err)
+	}
+	for _, mountPoint := range mountPoints {
+		if output, err := exec.Command("umount", "-d", mountPoint).CombinedOutput(); err != nil {
+			// something may have started using the snap meanwhile
+			if procs, _ := processesUsing(snapDir); len(procs) > 0 {
+				return reportBusyProcesses(snapName, procs)
+			}
+			return fmt.Errorf(i18n.G("cannot unmount %s: %v"), mountPoint, osutil.OutputErr(output, err))
+		}
+		fmt.Fprintf(Stdout, i18n.G("Unmounted %s\n"), mountPoint)
+	}
+
+	units, err := snapMountUnits(snapDir)
+	if err != nil {
+		return fmt.Errorf(i18n.G("cannot list mount units of snap %q: %v"), snapName, err)
+	}
+	if len(units) == 0 {
+		return nil
+	}
+	sysd := systemd.New(systemd.SystemMode, nil)
+	for _, unit := range units {
+		if err := sysd.DisableNoReload([]string{filepath.Base(unit)}); err != nil {
+			return fmt.Errorf(i18n.G("cannot disable mount unit %s: %v"), filepath.Base(unit), err)
+		}
+		if err := os.Remove(unit); err != nil {
+			return fmt.Errorf(i18n.G("cannot remove mount unit: %v"), err)
+		}
+		fmt.Fprintf(Stdout, i18n.G("Removed mount unit %s\n"), filepath.Base(unit))
+	}
+	// make systemd forget about the removed units
+	if err := sysd.DaemonReload(); err != nil {
+		return fmt.Errorf(i18n.G("cannot reload systemd: %v"), err)
+	}
+	return nil
+}
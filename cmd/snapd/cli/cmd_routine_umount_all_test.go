@@ -0,0 +1,196 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli_test
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	snap "github.com/snapcore/snapd/cmd/snapd/cli"
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/systemd"
+	"github.com/snapcore/snapd/testutil"
+)
+
+// mountUnitName returns the name of the mount unit of the given directory
+// under the snap mount directory, which depends on the distribution.
+func mountUnitName(dir string) string {
+	return systemd.EscapeUnitNamePath(filepath.Join(dirs.StripRootDir(dirs.SnapMountDir), dir)) + ".mount"
+}
+
+type umountAllFixture struct {
+	procDir     string
+	discardNs   *testutil.MockCmd
+	umount      *testutil.MockCmd
+	systemctlFn [][]string
+}
+
+func (s *SnapSuite) mockUmountAll(c *C, snapStatus string) *umountAllFixture {
+	f := &umountAllFixture{}
+
+	dirs.SetRootDir(c.MkDir())
+	s.AddCleanup(func() { dirs.SetRootDir("/") })
+
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, Equals, "/v2/snaps/foo")
+		if snapStatus == "" {
+			w.WriteHeader(404)
+			fmt.Fprintln(w, `{"type": "error", "status-code": 404, "result": {"message": "snap not installed", "kind": "snap-not-found", "value": "foo"}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"type": "sync", "result": {"name": "foo", "status": %q}}`, snapStatus)
+	})
+
+	f.discardNs = testutil.MockCommand(c, "snap-discard-ns", "")
+	s.AddCleanup(f.discardNs.Restore)
+	oldLibExecDir := dirs.DistroLibExecDir
+	dirs.DistroLibExecDir = f.discardNs.BinDir()
+	s.AddCleanup(func() { dirs.DistroLibExecDir = oldLibExecDir })
+
+	f.umount = testutil.MockCommand(c, "umount", "")
+	s.AddCleanup(f.umount.Restore)
+
+	s.AddCleanup(systemd.MockSystemctl(func(args ...string) ([]byte, error) {
+		f.systemctlFn = append(f.systemctlFn, args)
+		return nil, nil
+	}))
+
+	f.procDir = c.MkDir()
+	s.AddCleanup(snap.MockProcDir(f.procDir))
+
+	mountInfo := ""
+	for i, dir := range []string{"foo/1", "foo/2", "foo/components/mnt/comp/1", "foobar/1"} {
+		mountInfo += fmt.Sprintf("%d 25 7:%d / %s ro,nodev,relatime shared:%d - squashfs /dev/loop%d ro\n",
+			100+i, i, filepath.Join(dirs.SnapMountDir, dir), i, i)
+	}
+	s.AddCleanup(osutil.MockMountInfo(mountInfo))
+
+	for _, unit := range []string{
+		filepath.Join(dirs.SnapServicesDir, mountUnitName("foo/1")),
+		filepath.Join(dirs.SnapServicesDir, mountUnitName("foobar/1")),
+		filepath.Join(dirs.SnapRuntimeServicesDir, mountUnitName("foo/components/mnt/comp/1")),
+	} {
+		c.Assert(os.MkdirAll(filepath.Dir(unit), 0755), IsNil)
+		c.Assert(os.WriteFile(unit, nil, 0644), IsNil)
+	}
+	return f
+}
+
+func (f *umountAllFixture) mockProcess(c *C, pid int, comm string, links map[string]string, maps string) {
+	pidDir := filepath.Join(f.procDir, fmt.Sprint(pid))
+	c.Assert(os.MkdirAll(filepath.Join(pidDir, "fd"), 0755), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(pidDir, "comm"), []byte(comm+"\n"), 0644), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(pidDir, "maps"), []byte(maps), 0644), IsNil)
+	for name, target := range links {
+		c.Assert(os.Symlink(target, filepath.Join(pidDir, name)), IsNil)
+	}
+}
+
+func (s *SnapSuite) TestRoutineUmountAll(c *C) {
+	f := s.mockUmountAll(c, "")
+	// a process unrelated to the snap
+	f.mockProcess(c, 1, "init", map[string]string{"cwd": "/", "exe": "/sbin/init", "fd/0": "/dev/null"},
+		"7f0000000000-7f0000001000 r-xp 00000000 08:01 1234 /usr/lib/libc.so.6\n")
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"routine", "umount-all", "foo"})
+	c.Assert(err, IsNil)
+	c.Check(rest, DeepEquals, []string{})
+
+	c.Check(f.discardNs.Calls(), DeepEquals, [][]string{{"snap-discard-ns", "foo"}})
+	// the most nested mounts go first and mounts of other snaps are kept
+	c.Check(f.umount.Calls(), DeepEquals, [][]string{
+		{"umount", "-d", filepath.Join(dirs.SnapMountDir, "foo/components/mnt/comp/1")},
+		{"umount", "-d", filepath.Join(dirs.SnapMountDir, "foo/2")},
+		{"umount", "-d", filepath.Join(dirs.SnapMountDir, "foo/1")},
+	})
+	c.Check(f.systemctlFn, DeepEquals, [][]string{
+		{"--no-reload", "disable", mountUnitName("foo/1")},
+		{"--no-reload", "disable", mountUnitName("foo/components/mnt/comp/1")},
+		{"daemon-reload"},
+	})
+	c.Check(filepath.Join(dirs.SnapServicesDir, mountUnitName("foo/1")), testutil.FileAbsent)
+	c.Check(filepath.Join(dirs.SnapRuntimeServicesDir, mountUnitName("foo/components/mnt/comp/1")), testutil.FileAbsent)
+	c.Check(filepath.Join(dirs.SnapServicesDir, mountUnitName("foobar/1")), testutil.FilePresent)
+
+	c.Check(s.Stdout(), Equals, fmt.Sprintf(`Discarded mount namespaces of snap "foo"
+Unmounted %[1]s/foo/components/mnt/comp/1
+Unmounted %[1]s/foo/2
+Unmounted %[1]s/foo/1
+Removed mount unit %[2]s
+Removed mount unit %[3]s
+`, dirs.SnapMountDir, mountUnitName("foo/1"), mountUnitName("foo/components/mnt/comp/1")))
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestRoutineUmountAllBusy(c *C) {
+	f := s.mockUmountAll(c, "disabled")
+	snapDir := filepath.Join(dirs.SnapMountDir, "foo")
+	f.mockProcess(c, 1, "init", map[string]string{"cwd": "/"}, "")
+	f.mockProcess(c, 42, "foo-daemon", map[string]string{"cwd": snapDir + "/1/bin"}, "")
+	f.mockProcess(c, 1234, "bash", map[string]string{"cwd": "/root", "fd/3": snapDir + "/2/data.db"}, "")
+	f.mockProcess(c, 4321, "python3", map[string]string{"cwd": "/"},
+		"7f0000000000-7f0000001000 r-xp 00000000 07:02 42 "+snapDir+"/components/mnt/comp/1/lib/libfoo.so\n")
+	// other snaps do not count
+	f.mockProcess(c, 5000, "foobar", map[string]string{"cwd": dirs.SnapMountDir + "/foobar/1"}, "")
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"routine", "umount-all", "foo"})
+	c.Assert(err, ErrorMatches, `cannot unmount snap "foo": mounts are in use by 3 processes`)
+	c.Check(s.Stdout(), Equals, fmt.Sprintf(`The mounts of snap "foo" are in use by the following processes:
+PID   Command     Path
+42    foo-daemon  %[1]s/1/bin
+1234  bash        %[1]s/2/data.db
+4321  python3     %[1]s/components/mnt/comp/1/lib/libfoo.so
+`, snapDir))
+
+	// nothing was torn down
+	c.Check(f.discardNs.Calls(), HasLen, 0)
+	c.Check(f.umount.Calls(), HasLen, 0)
+	c.Check(f.systemctlFn, HasLen, 0)
+}
+
+func (s *SnapSuite) TestRoutineUmountAllUmountFails(c *C) {
+	f := s.mockUmountAll(c, "")
+	f.umount = testutil.MockCommand(c, "umount", "echo 'target is busy'; exit 32")
+	s.AddCleanup(f.umount.Restore)
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"routine", "umount-all", "foo"})
+	c.Assert(err, ErrorMatches, fmt.Sprintf(`cannot unmount %s/foo/components/mnt/comp/1: target is busy`, dirs.SnapMountDir))
+	c.Check(f.umount.Calls(), HasLen, 1)
+	c.Check(f.systemctlFn, HasLen, 0)
+}
+
+func (s *SnapSuite) TestRoutineUmountAllActiveSnap(c *C) {
+	f := s.mockUmountAll(c, "active")
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"routine", "umount-all", "foo"})
+	c.Assert(err, ErrorMatches, `cannot unmount snap "foo": snap is active, disable or remove it first`)
+	c.Check(f.discardNs.Calls(), HasLen, 0)
+	c.Check(f.umount.Calls(), HasLen, 0)
+}
+
+func (s *SnapSuite) TestRoutineUmountAllInvalidName(c *C) {
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"routine", "umount-all", "Foo!"})
+	c.Assert(err, ErrorMatches, `invalid snap name: "Foo!"`)
+}
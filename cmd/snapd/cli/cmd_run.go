@@ -20,6 +20,7 @@ package cli
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -29,8 +30,10 @@ import (
 	"net"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"syscall"
@@ -46,10 +49,12 @@ import (
 	"github.com/snapcore/snapd/features"
 	"github.com/snapcore/snapd/i18n"
 	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/denials"
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/osutil/strace"
 	"github.com/snapcore/snapd/osutil/user"
+	"github.com/snapcore/snapd/randutil"
 	"github.com/snapcore/snapd/sandbox/cgroup"
 	"github.com/snapcore/snapd/sandbox/selinux"
 	"github.com/snapcore/snapd/snap"
@@ -95,6 +100,8 @@ type cmdRun struct {
 	Gdbserver             string `long:"gdbserver" default:"no-gdbserver" optional-value:":0" optional:"true"`
 	ExperimentalGdbserver string `long:"experimental-gdbserver" default:"no-gdbserver" optional-value:":0" optional:"true" hidden:"yes"`
 	TraceExec             bool   `long:"trace-exec"`
+	StraceJSON            bool   `long:"strace-json"`
+	SummarizeDenials      bool   `long:"summarize-denials"`
 
 	// not a real option, used to check if cmdRun is initialized by
 	// the parser
@@ -137,6 +144,10 @@ and environment.
 			// TRANSLATORS: This should not start with a lowercase letter.
 			"trace-exec": i18n.G("Display exec calls timing data"),
 			// TRANSLATORS: This should not start with a lowercase letter.
+			"strace-json": i18n.G("Run the command under strace and display a JSON summary of its system calls and of their denials"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"summarize-denials": i18n.G("Display the AppArmor and seccomp denials of the command when it exits, with the interfaces granting the denied access"),
+			// TRANSLATORS: This should not start with a lowercase letter.
 			"debug-log":  i18n.G("Enable debug logging during early snap startup phases"),
 			"parser-ran": "",
 		}, nil)
@@ -326,6 +337,12 @@ func (x *cmdRun) Execute(args []string) error {
 	if x.Gdb {
 		return errors.New("--gdb is no longer supported: use --gdbserver option instead")
 	}
+	if x.SummarizeDenials && (x.useStrace() || x.StraceJSON || x.TraceExec || x.useGdbserver()) {
+		return errors.New(i18n.G("cannot use --summarize-denials with --strace, --strace-json, --trace-exec or --gdbserver"))
+	}
+	if x.StraceJSON && (x.useStrace() || x.TraceExec || x.useGdbserver()) {
+		return errors.New(i18n.G("cannot use --strace-json with --strace, --trace-exec or --gdbserver"))
+	}
 
 	logger.StartupStageTimestamp("start")
 
@@ -1123,11 +1140,21 @@ func (x *cmdRun) runCmdWithTraceExec(origCmd []string, envForExec envForExecFunc
 	}
 	defer fw.Close()
 
+	// with SNAPD_TRACE set, snap-exec sends structured startup trace
+	// entries to the journal, tagged with this ID so they can be collected
+	// once the application is done
+	var traceID string
+	var extraEnv map[string]string
+	if osutil.GetenvBool("SNAPD_TRACE") {
+		traceID = randutil.RandomString(16)
+		extraEnv = map[string]string{"SNAPD_TRACE_ID": traceID}
+	}
+
 	appCmd := exec.Command(straceShim, origCmd...)
 	appCmd.Stdin = os.Stdin
 	appCmd.Stdout = os.Stdout
 	appCmd.Stderr = os.Stderr
-	appCmd.Env = envForExec(nil)
+	appCmd.Env = envForExec(extraEnv)
 	if err := appCmd.Start(); err != nil {
 		return err
 	}
@@ -1185,6 +1212,9 @@ func (x *cmdRun) runCmdWithTraceExec(origCmd []string, envForExec envForExecFunc
 	<-doneCh
 	if traceErr == nil {
 		slg.Display(Stderr)
+		if traceID != "" {
+			displayStartupStages(Stderr, slg, traceID)
+		}
 	} else {
 		logger.Noticef("cannot extract runtime data: %v", traceErr)
 	}
@@ -1199,6 +1229,88 @@ func (x *cmdRun) runCmdWithTraceExec(origCmd []string, envForExec envForExecFunc
 	return strutil.JoinErrors(straceCmdErr, maybeIgnoreTracedAppKillError(appCmdErr, appKillSent))
 }
 
+// startupTraceEntries returns the journal entries of the startup trace with
+// the given ID, see cmd/snapctl/tool/snap-exec/trace.go.
+var startupTraceEntries = func(traceID string) ([]map[string]string, error) {
+	cmd := exec.Command("journalctl", "--output=json", "--no-pager", "SNAPD_TRACE_ID="+traceID)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, osutil.OutputErr(output, err)
+	}
+
+	var entries []map[string]string
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		var raw map[string]json.RawMessage
+		if err := json.Unmarshal(scanner.Bytes(), &raw); err != nil {
+			return nil, fmt.Errorf("cannot decode journal entry: %v", err)
+		}
+		entry := make(map[string]string, len(raw))
+		for k, v := range raw {
+			var str string
+			// binary fields are encoded as arrays, they are of no
+			// interest here
+			if json.Unmarshal(v, &str) == nil {
+				entry[k] = str
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+type startupStage struct {
+	name     string
+	startSec float64
+	totalSec float64
+}
+
+// displayStartupStages shows a breakdown of the snap startup, combining the
+// snapd helpers runtimes observed by strace with the stages traced by
+// snap-exec.
+func displayStartupStages(w io.Writer, slg *strace.ExecveTiming, traceID string) {
+	var stages []startupStage
+	for _, stage := range slg.Stages {
+		stages = append(stages, startupStage{
+			name:     filepath.Base(stage.Exe),
+			startSec: stage.StartSec,
+			totalSec: stage.TotalSec,
+		})
+	}
+
+	entries, err := startupTraceEntries(traceID)
+	if err != nil {
+		logger.Noticef("cannot obtain startup trace from the journal: %v", err)
+	}
+	for _, entry := range entries {
+		startUsec, err1 := strconv.ParseInt(entry["SNAPD_STARTUP_STAGE_START_USEC"], 10, 64)
+		durationUsec, err2 := strconv.ParseInt(entry["SNAPD_STARTUP_STAGE_DURATION_USEC"], 10, 64)
+		if err1 != nil || err2 != nil || entry["SNAPD_STARTUP_STAGE"] == "" {
+			continue
+		}
+		name := "snap-exec: " + entry["SNAPD_STARTUP_STAGE"]
+		if cmd := entry["SNAPD_STARTUP_COMMAND"]; cmd != "" {
+			name += " " + cmd
+		}
+		stages = append(stages, startupStage{
+			name:     name,
+			startSec: float64(startUsec)/1e6 - slg.StartTime,
+			totalSec: float64(durationUsec) / 1e6,
+		})
+	}
+	if len(stages) == 0 {
+		return
+	}
+
+	sort.SliceStable(stages, func(i, j int) bool {
+		return stages[i].startSec < stages[j].startSec
+	})
+	fmt.Fprintf(w, "Startup stages (start, duration):\n")
+	for _, stage := range stages {
+		fmt.Fprintf(w, "  %2.3fs %2.3fs %s\n", stage.startSec, stage.totalSec, stage.name)
+	}
+}
+
 // maybeIgnoreTracedAppKillError processes the error from a snap application that may
 // have been forcefully killed during trace as a result of strace process
 // finishing prematurely.
@@ -1253,6 +1365,205 @@ func awaitTraceHelperCheckpoint(pid int) error {
 	return nil
 }
 
+// isStraceExecIntoSnap returns whether the given line of strace output is
+// the execve() of something inside the snap dir.
+func isStraceExecIntoSnap(s string) bool {
+	// We need check both /snap (which is where snaps are located inside
+	// the mount namespace) and the distro snap mount dir (which is
+	// different on e.g. fedora/arch) to fully work with classic snaps.
+	needle1 := fmt.Sprintf(`execve("%s`, dirs.SnapMountDir)
+	needle2 := `execve("/snap`
+	// Ensure we catch the execve but *not* the exec into
+	// /snap/core/current/usr/lib/snapd/snap-confine which is just `snap
+	// run` using the core version snap-confine.
+	return (strings.Contains(s, needle1) || strings.Contains(s, needle2)) && !strings.Contains(s, "usr/lib/snapd/snap-confine")
+}
+
+func (x *cmdRun) runCmdWithStraceSummary(origCmd []string, envForExec envForExecFunc, securityTag string) error {
+	straceShim, err := snapdtool.InternalToolPath("snap-strace-shim")
+	if err != nil {
+		return fmt.Errorf("cannot locate snap-strace-shim: %w", err)
+	}
+
+	// the seccomp profile is used to tell which failed system calls it
+	// denied
+	profile, err := os.ReadFile(filepath.Join(dirs.SnapSeccompDir, securityTag+".src"))
+	if err != nil {
+		logger.Noticef("cannot read seccomp profile of %s: %v", securityTag, err)
+		profile = nil
+	}
+
+	// setup private tmp dir with strace fifo
+	straceTmp, err := os.MkdirTemp("", "strace-summary")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(straceTmp)
+	straceLog := filepath.Join(straceTmp, "strace.fifo")
+	if err := syscall.Mkfifo(straceLog, 0640); err != nil {
+		return err
+	}
+	// ensure we have one writer on the fifo so that if strace fails
+	// nothing blocks
+	fw, err := os.OpenFile(straceLog, os.O_RDWR, 0640)
+	if err != nil {
+		return err
+	}
+	defer fw.Close()
+
+	appCmd := exec.Command(straceShim, origCmd...)
+	appCmd.Stdin = os.Stdin
+	appCmd.Stdout = os.Stdout
+	appCmd.Stderr = os.Stderr
+	appCmd.Env = envForExec(nil)
+	if err := appCmd.Start(); err != nil {
+		return err
+	}
+
+	if err := awaitTraceHelperCheckpoint(appCmd.Process.Pid); err != nil {
+		return err
+	}
+
+	logger.Debugf("child stopped, ready to be traced")
+	childStopped := true
+
+	// read strace data from fifo async
+	var summary *strace.SyscallSummary
+	var traceErr error
+	doneCh := make(chan bool, 1)
+	go func() {
+		summary, traceErr = strace.SummarizeSyscalls(straceLog, &strace.SyscallSummaryOptions{
+			NotifyTraceAttached: func() {
+				logger.Debug("strace attached to child process")
+				if childStopped {
+					childStopped = false
+					if err := appCmd.Process.Signal(syscall.SIGCONT); err != nil {
+						fmt.Fprintf(Stderr, "cannot signal child to continue: %v\n", err)
+					}
+				}
+			},
+			// what snap-confine and snap-exec do is not interesting
+			StartAt:        isStraceExecIntoSnap,
+			SeccompProfile: profile,
+		})
+		close(doneCh)
+	}()
+
+	straceCmd, err := strace.CommandWithTraceePid(appCmd.Process.Pid, []string{"-o", straceLog})
+	if err != nil {
+		return err
+	}
+	logger.Debugf("strace command: %v", straceCmd.Args)
+	straceCmd.Stdin = Stdin
+	straceCmd.Stdout = Stdout
+	straceCmd.Stderr = Stderr
+
+	// see comment at a similar place in runCmdWithTraceExec()
+	appCmdErrC := make(chan error, 1)
+	go func() {
+		defer close(appCmdErrC)
+		appCmdErrC <- appCmd.Wait()
+	}()
+
+	straceCmdErr := straceCmd.Run()
+	// ensure we close the fifo here so that strace.SummarizeSyscalls()
+	// gets a EOF from the fifo
+	fw.Close()
+
+	// wait for strace reader
+	<-doneCh
+	var summaryErr error
+	if traceErr == nil {
+		enc := json.NewEncoder(Stderr)
+		enc.SetIndent("", "  ")
+		summaryErr = enc.Encode(summary)
+	} else {
+		summaryErr = fmt.Errorf("cannot summarize system calls: %v", traceErr)
+	}
+
+	// see comment at a similar place in runCmdUnderStrace()
+	appKillSent, killErr := maybeKillTracedApp(appCmd)
+	if killErr != nil {
+		fmt.Fprintf(Stderr, "error: cannot kill child application: %v\n", killErr)
+	}
+	appCmdErr := <-appCmdErrC
+
+	return strutil.JoinErrors(straceCmdErr, maybeIgnoreTracedAppKillError(appCmdErr, appKillSent), summaryErr)
+}
+
+func (x *cmdRun) runCmdWithDenialsSummary(origCmd []string, envForExec envForExecFunc, snapName, securityTag string) error {
+	// the kernel log has a resolution of one second
+	start := timeNow().Truncate(time.Second)
+
+	appCmd := exec.Command(origCmd[0], origCmd[1:]...)
+	appCmd.Stdin = os.Stdin
+	appCmd.Stdout = os.Stdout
+	appCmd.Stderr = os.Stderr
+	appCmd.Env = envForExec(nil)
+
+	// the application gets the signals from the terminal directly, and
+	// the summary is printed once it exits because of them
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+
+	if err := appCmd.Start(); err != nil {
+		return err
+	}
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	go func() {
+		for {
+			select {
+			case sig := <-sigCh:
+				if sig == syscall.SIGTERM {
+					appCmd.Process.Signal(sig)
+				}
+			case <-doneCh:
+				return
+			}
+		}
+	}()
+	appCmdErr := appCmd.Wait()
+
+	summaryErr := printDenialsSummary(x.client, snapName, securityTag, start)
+	if summaryErr != nil {
+		summaryErr = fmt.Errorf(i18n.G("cannot summarize denials: %v"), summaryErr)
+	}
+	return strutil.JoinErrors(appCmdErr, summaryErr)
+}
+
+// printDenialsSummary prints the denials of the given application logged
+// since the given time, with the interfaces granting the denied access.
+func printDenialsSummary(cli *client.Client, snapName, securityTag string, since time.Time) error {
+	r, err := readKernelLog(fmt.Sprintf("@%d", since.Unix()))
+	if err != nil {
+		return err
+	}
+	ds, err := denials.Parse(r, snapName)
+	if err != nil {
+		return err
+	}
+	// seccomp denials are not always labelled, those of other applications
+	// of the snap are told apart by the AppArmor label only
+	appDenials := ds[:0]
+	for _, d := range ds {
+		label := strings.TrimSuffix(d.Label, " (enforce)")
+		label = strings.TrimSuffix(label, " (complain)")
+		if label == "" || label == securityTag {
+			appDenials = append(appDenials, d)
+		}
+	}
+	if len(appDenials) == 0 {
+		fmt.Fprintf(Stderr, i18n.G("No denials of %s found.\n"), securityTag)
+		return nil
+	}
+	resolveSyscallNames(appDenials)
+
+	fmt.Fprintf(Stderr, i18n.G("Denials of %s:\n"), securityTag)
+	return printDenialSuggestions(Stderr, cli, snapName, appDenials)
+}
+
 func (x *cmdRun) runCmdUnderStrace(origCmd []string, envForExec envForExecFunc) error {
 	extraStraceOpts, raw, err := x.straceOpts()
 	if err != nil {
@@ -1360,25 +1671,13 @@ func (x *cmdRun) runCmdUnderStrace(origCmd []string, envForExec envForExecFunc)
 			// execve() something inside the snap dir so
 			// we know that from that point on the output
 			// will be interesting to the user.
-			//
-			// We need check both /snap (which is where snaps
-			// are located inside the mount namespace) and the
-			// distro snap mount dir (which is different on e.g.
-			// fedora/arch) to fully work with classic snaps.
-			needle1 := fmt.Sprintf(`execve("%s`, dirs.SnapMountDir)
-			needle2 := `execve("/snap`
 			for {
 				s, err := r.ReadString('\n')
 				if err != nil {
 					filterDone <- errWrapIf("cannot read while waiting for exec() to snap-confine: %w", err)
 					return
 				}
-				// Ensure we catch the execve but *not* the
-				// exec into
-				// /snap/core/current/usr/lib/snapd/snap-confine
-				// which is just `snap run` using the core version
-				// snap-confine.
-				if (strings.Contains(s, needle1) || strings.Contains(s, needle2)) && !strings.Contains(s, "usr/lib/snapd/snap-confine") {
+				if isStraceExecIntoSnap(s) {
 					fmt.Fprint(Stderr, s)
 					break
 				}
@@ -1556,6 +1855,29 @@ func makeStdStreamsForJournal(app *snap.AppInfo, namespace string) (stdout, stde
 	return stdout, stderr
 }
 
+// snapConfineHardeningArgs returns the arguments of snap-confine which
+// apply the hardening of the mount namespace requested by the service.
+// snap-confine applies it in a mount namespace private to the service.
+func snapConfineHardeningArgs(app *snap.AppInfo) []string {
+	hardening := app.Hardening
+	if hardening == nil {
+		return nil
+	}
+
+	var args []string
+	if hardening.PrivateTmp {
+		args = append(args, "--private-tmp")
+	}
+	if hardening.ProtectSystem != "" {
+		if plug := app.ProtectSystemConflict(); plug != "" {
+			logger.Noticef("WARNING: ignoring protect-system hardening as it is incompatible with plug %q", plug)
+		} else {
+			args = append(args, "--protect-system", hardening.ProtectSystem)
+		}
+	}
+	return args
+}
+
 func (x *cmdRun) runSnapConfine(info *snap.Info, runner runnable, beforeExec func() error, args []string) error {
 	needsClassic := info.NeedsClassic()
 
@@ -1636,6 +1958,10 @@ func (x *cmdRun) runSnapConfine(info *snap.Info, runner runnable, beforeExec fun
 		}
 	}
 
+	if app := runner.App(); app != nil && app.IsService() && !needsClassic {
+		cmd = append(cmd, snapConfineHardeningArgs(app)...)
+	}
+
 	appSecurityTag := runner.SecurityTag()
 	cmd = append(cmd, appSecurityTag)
 
@@ -1876,6 +2202,10 @@ func (x *cmdRun) runSnapConfine(info *snap.Info, runner runnable, beforeExec fun
 	logger.StartupStageTimestamp("snap to snap-confine")
 	if x.TraceExec {
 		return x.runCmdWithTraceExec(cmd, envForExec)
+	} else if x.StraceJSON {
+		return x.runCmdWithStraceSummary(cmd, envForExec, appSecurityTag)
+	} else if x.SummarizeDenials {
+		return x.runCmdWithDenialsSummary(cmd, envForExec, runner.info.InstanceName(), appSecurityTag)
 	} else if x.useGdbserver() {
 		if _, err := exec.LookPath("gdbserver"); err != nil {
 			// TODO: use errors.Is(err, exec.ErrNotFound) once
@@ -20,6 +20,7 @@
 package cli_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -285,6 +286,71 @@ func (s *RunSuite) TestSnapRunAppIntegration(c *check.C) {
 	c.Check(execEnv, testutil.Contains, fmt.Sprintf("TMPDIR=%s", tmpdir))
 }
 
+func (s *RunSuite) TestSnapRunServiceHardening(c *check.C) {
+	defer mockSnapConfine(dirs.DistroLibExecDir)()
+
+	// mock installed snap
+	snaptest.MockSnapCurrent(c, `name: snapname
+version: 1.0
+plugs:
+ etc-files:
+  interface: system-files
+  write: [/etc/foo]
+apps:
+ app:
+  command: run-app
+ svc:
+  command: run-svc
+  daemon: simple
+  hardening:
+   private-tmp: true
+   protect-system: full
+ conflicting:
+  command: run-svc
+  daemon: simple
+  plugs: [etc-files]
+  hardening:
+   private-tmp: true
+   protect-system: full
+`, &snap.SideInfo{
+		Revision: snap.R("x2"),
+	})
+
+	// redirect exec
+	execArgs := []string{}
+	restorer := snaprun.MockSyscallExec(func(arg0 string, args []string, envv []string) error {
+		execArgs = args
+		return nil
+	})
+	defer restorer()
+
+	// services are already tracked by systemd
+	restorer = snaprun.MockConfirmSystemdServiceTracking(func(securityTag string) error {
+		return nil
+	})
+	defer restorer()
+
+	for _, t := range []struct {
+		app           string
+		hardeningArgs []string
+	}{
+		{"svc", []string{"--private-tmp", "--protect-system", "full"}},
+		{"conflicting", []string{"--private-tmp"}},
+		{"app", nil},
+	} {
+		_, err := snaprun.Parser(snaprun.Client()).ParseArgs([]string{"run", "--", "snapname." + t.app})
+		c.Assert(err, check.IsNil)
+		expected := []string{filepath.Join(dirs.DistroLibExecDir, "snap-confine")}
+		expected = append(expected, t.hardeningArgs...)
+		expected = append(expected,
+			"snap.snapname."+t.app,
+			filepath.Join(dirs.CoreLibExecDir, "snap-exec"),
+			"snapname."+t.app)
+		c.Check(execArgs, check.DeepEquals, expected, check.Commentf(t.app))
+	}
+	c.Check(s.Stderr(), check.Equals, "")
+}
+
 func checkHintFileNotLocked(c *check.C, snapName string) {
 	flock, err := openHintFileLock(snapName)
 	c.Assert(err, check.IsNil)
@@ -2209,6 +2275,167 @@ func (s *RunSuite) TestSnapRunAppWithStraceOptions(c *check.C) {
 	})
 }
 
+func (s *RunSuite) TestSnapRunAppWithStraceJSON(c *check.C) {
+	defer mockSnapConfine(dirs.DistroLibExecDir)()
+
+	// mock installed snap
+	snaptest.MockSnapCurrent(c, string(mockYamlForNameBase("snapname", "")), &snap.SideInfo{
+		Revision: snap.R("x2"),
+	})
+	c.Assert(os.MkdirAll(dirs.SnapSeccompDir, 0755), check.IsNil)
+	c.Assert(os.WriteFile(filepath.Join(dirs.SnapSeccompDir, "snap.snapname.app.src"), []byte("openat\nkill\n"), 0644), check.IsNil)
+
+	// pretend we have sudo, writing the trace where strace would
+	sudoCmd := testutil.MockCommand(c, "sudo", `
+while [ $# -gt 0 ]; do
+    if [ "$1" = "-o" ]; then
+        out="$2"
+    fi
+    shift
+done
+cat > "$out" <<'EOF'
+strace: Process 1234 attached
+1234  --- stopped by SIGSTOP ---
+1234  execve("/usr/lib/snapd/snap-confine", ["snap-confine"], 0x7ffd /* 30 vars */) = 0
+1234  mount("tmpfs", "/tmp", "tmpfs", 0, NULL) = 0
+1234  execve("/snap/snapname/x2/bin/app", ["app"], 0x7ffd /* 30 vars */) = 0
+1234  openat(AT_FDCWD, "/etc/shadow", O_RDONLY) = -1 EACCES (Permission denied)
+1234  mount("tmpfs", "/mnt", "tmpfs", 0, NULL) = -1 EPERM (Operation not permitted)
+1234  +++ exited with 0 +++
+EOF
+`)
+	defer sudoCmd.Restore()
+
+	// pretend we have strace
+	straceCmd := testutil.MockCommand(c, "strace", "")
+	defer straceCmd.Restore()
+
+	traceShimCmd, shimPid := mockTraceShim(c, dirs.DistroLibExecDir)
+	defer traceShimCmd.Restore()
+
+	rest, err := snaprun.Parser(snaprun.Client()).ParseArgs([]string{"run", "--strace-json", "--", "snapname.app", "--arg1", "arg2"})
+	c.Assert(err, check.IsNil)
+	c.Check(rest, check.DeepEquals, []string{"--arg1", "arg2"})
+	c.Assert(sudoCmd.Calls(), check.HasLen, 1)
+	call := sudoCmd.Calls()[0]
+	c.Assert(call, check.HasLen, 10)
+	c.Check(call[:7], check.DeepEquals, []string{
+		"sudo",
+		"--",
+		filepath.Join(straceCmd.BinDir(), "strace"),
+		"-f",
+		"-e", strace.ExcludedSyscalls,
+		"-o",
+	})
+	c.Check(call[8:], check.DeepEquals, []string{"-p", shimPid()})
+
+	c.Check(s.Stderr(), check.Equals, `{
+  "syscalls": {
+    "execve": {
+      "calls": 1
+    },
+    "mount": {
+      "calls": 1,
+      "errors": 1
+    },
+    "openat": {
+      "calls": 1,
+      "errors": 1
+    }
+  },
+  "seccomp-denied": [
+    "mount"
+  ],
+  "apparmor-denied": [
+    "/etc/shadow"
+  ]
+}
+`)
+}
+
+func (s *RunSuite) TestSnapRunAppWithStraceJSONConflicts(c *check.C) {
+	for _, opt := range []string{"--strace", "--trace-exec", "--gdbserver"} {
+		_, err := snaprun.Parser(snaprun.Client()).ParseArgs([]string{"run", "--strace-json", opt, "--", "snapname.app"})
+		c.Check(err, check.ErrorMatches, "cannot use --strace-json with --strace, --trace-exec or --gdbserver")
+	}
+}
+
+func (s *RunSuite) mockSummarizeDenials(c *check.C, confine, log string) *testutil.MockCmd {
+	// mock installed snap
+	snaptest.MockSnapCurrent(c, string(mockYamlForNameBase("snapname", "")), &snap.SideInfo{
+		Revision: snap.R("x2"),
+	})
+
+	// the application actually runs under the --summarize-denials runner
+	snapConfine := filepath.Join(dirs.DistroLibExecDir, "snap-confine")
+	c.Assert(os.MkdirAll(dirs.DistroLibExecDir, 0755), check.IsNil)
+	c.Assert(os.WriteFile(snapConfine, []byte("#!/bin/sh\n"+confine), 0755), check.IsNil)
+
+	logPath := filepath.Join(c.MkDir(), "log")
+	c.Assert(os.WriteFile(logPath, []byte(log), 0644), check.IsNil)
+	journalctl := testutil.MockCommand(c, "journalctl", fmt.Sprintf("cat %s", logPath))
+	s.AddCleanup(journalctl.Restore)
+	s.AddCleanup(snaprun.MockResolveSyscall(func(arch, nr string) (string, error) {
+		return "settimeofday", nil
+	}))
+	s.AddCleanup(snaprun.MockTimeNow(func() time.Time {
+		return time.Unix(1760616000, 500000000)
+	}))
+	return journalctl
+}
+
+func (s *RunSuite) TestSnapRunAppSummarizeDenials(c *check.C) {
+	journalctl := s.mockSummarizeDenials(c, "echo \"$@\" > \"$(dirname \"$0\")/args\"", `audit: type=1400 audit(1760616001.123:100): apparmor="DENIED" operation="capable" class="cap" profile="snap.snapname.app" pid=1234 comm="app" capability=25 capname="sys_time"
+audit: type=1400 audit(1760616001.223:101): apparmor="DENIED" operation="capable" class="cap" profile="snap.snapname.app" pid=1234 comm="app" capability=25 capname="sys_time"
+audit: type=1400 audit(1760616001.323:102): apparmor="DENIED" operation="open" class="file" profile="snap.snapname.other" name="/etc/shadow" pid=1235 comm="other" requested_mask="r" denied_mask="r" fsuid=1000 ouid=0
+audit: type=1326 audit(1760616002.123:103): auid=1000 uid=1000 gid=1000 ses=2 subj=? pid=1234 comm="app" exe="/snap/snapname/x2/bin/app" sig=0 arch=c000003e syscall=164 compat=0 ip=0x7f0000000000 code=0x50000
+`)
+
+	rest, err := snaprun.Parser(snaprun.Client()).ParseArgs([]string{"run", "--summarize-denials", "--", "snapname.app", "--arg1", "arg2"})
+	c.Assert(err, check.IsNil)
+	c.Check(rest, check.DeepEquals, []string{"--arg1", "arg2"})
+
+	args, err := os.ReadFile(filepath.Join(dirs.DistroLibExecDir, "args"))
+	c.Assert(err, check.IsNil)
+	c.Check(string(args), check.Equals, fmt.Sprintf("snap.snapname.app %s snapname.app --arg1 arg2\n", filepath.Join(dirs.CoreLibExecDir, "snap-exec")))
+
+	c.Check(journalctl.Calls(), check.DeepEquals, [][]string{
+		{"journalctl", "--no-pager", "--output=cat", "--since=@1760616000", "_TRANSPORT=kernel", "+", "_TRANSPORT=audit"},
+	})
+	stderr := s.Stderr()
+	c.Check(stderr, testutil.Contains, "Denials of snap.snapname.app:\n")
+	c.Check(stderr, testutil.Contains, "snap.snapname.app: capability sys_time, 2 times\n")
+	c.Check(stderr, testutil.Contains, "  add a plug of the time-control interface to the snap\n")
+	c.Check(stderr, testutil.Contains, "snap snapname: syscall settimeofday, 1 time\n")
+	c.Check(stderr, check.Not(testutil.Contains), "snap.snapname.other")
+}
+
+func (s *RunSuite) TestSnapRunAppSummarizeDenialsNone(c *check.C) {
+	s.mockSummarizeDenials(c, "exit 3", `audit: type=1400 audit(1760616001.323:102): apparmor="DENIED" operation="open" class="file" profile="snap.snapname.other" name="/etc/shadow" pid=1235 comm="other" requested_mask="r" denied_mask="r" fsuid=1000 ouid=0
+`)
+
+	_, err := snaprun.Parser(snaprun.Client()).ParseArgs([]string{"run", "--summarize-denials", "--", "snapname.app"})
+	c.Assert(err, check.ErrorMatches, "exit status 3")
+	c.Check(s.Stderr(), check.Equals, "No denials of snap.snapname.app found.\n")
+}
+
+func (s *RunSuite) TestSnapRunAppSummarizeDenialsJournalError(c *check.C) {
+	s.mockSummarizeDenials(c, "exit 0", "")
+	// shadows the mocked journal
+	journalctl := testutil.MockCommand(c, "journalctl", "echo 'No journal files were found.' >&2; exit 1")
+	defer journalctl.Restore()
+
+	_, err := snaprun.Parser(snaprun.Client()).ParseArgs([]string{"run", "--summarize-denials", "--", "snapname.app"})
+	c.Assert(err, check.ErrorMatches, `(?s)cannot summarize denials: cannot read the journal: \n-----\nstderr:\nNo journal files were found\.\n-----`)
+}
+
+func (s *RunSuite) TestSnapRunAppSummarizeDenialsConflicts(c *check.C) {
+	for _, opt := range []string{"--strace", "--strace-json", "--trace-exec", "--gdbserver"} {
+		_, err := snaprun.Parser(snaprun.Client()).ParseArgs([]string{"run", "--summarize-denials", opt, "--", "snapname.app"})
+		c.Check(err, check.ErrorMatches, "cannot use --summarize-denials with --strace, --strace-json, --trace-exec or --gdbserver")
+	}
+}
+
 func (s *RunSuite) TestSnapRunAppWithStraceBadShim(c *check.C) {
 	defer mockSnapConfine(dirs.DistroLibExecDir)()
 
@@ -2366,6 +2593,96 @@ func (s *RunSuite) TestRunCmdWithTraceExecUnhappy(c *check.C) {
 	c.Check(s.Stderr(), check.Equals, "")
 }
 
+func (s *RunSuite) TestStartupTraceEntries(c *check.C) {
+	journalctlCmd := testutil.MockCommand(c, "journalctl", `
+echo '{"SNAPD_STARTUP_STAGE":"read-info","SNAPD_STARTUP_STAGE_START_USEC":"1000000100000","BINARY":[1,2]}'
+echo '{"SNAPD_STARTUP_STAGE":"exec","SNAPD_STARTUP_COMMAND":"/snap/foo/1/bin/foo"}'
+`)
+	defer journalctlCmd.Restore()
+
+	entries, err := snaprun.StartupTraceEntries("trace-id")
+	c.Assert(err, check.IsNil)
+	c.Check(entries, check.DeepEquals, []map[string]string{
+		{"SNAPD_STARTUP_STAGE": "read-info", "SNAPD_STARTUP_STAGE_START_USEC": "1000000100000"},
+		{"SNAPD_STARTUP_STAGE": "exec", "SNAPD_STARTUP_COMMAND": "/snap/foo/1/bin/foo"},
+	})
+	c.Check(journalctlCmd.Calls(), check.DeepEquals, [][]string{
+		{"journalctl", "--output=json", "--no-pager", "SNAPD_TRACE_ID=trace-id"},
+	})
+}
+
+func (s *RunSuite) TestStartupTraceEntriesError(c *check.C) {
+	journalctlCmd := testutil.MockCommand(c, "journalctl", "echo 'no journal'; exit 1")
+	defer journalctlCmd.Restore()
+
+	_, err := snaprun.StartupTraceEntries("trace-id")
+	c.Assert(err, check.ErrorMatches, "no journal")
+}
+
+func (s *RunSuite) TestDisplayStartupStages(c *check.C) {
+	restore := snaprun.MockStartupTraceEntries(func(traceID string) ([]map[string]string, error) {
+		c.Check(traceID, check.Equals, "trace-id")
+		return []map[string]string{{
+			"SNAPD_STARTUP_STAGE":               "read-info",
+			"SNAPD_STARTUP_STAGE_START_USEC":    "1000000000180000",
+			"SNAPD_STARTUP_STAGE_DURATION_USEC": "2000",
+		}, {
+			"SNAPD_STARTUP_STAGE":               "environment",
+			"SNAPD_STARTUP_STAGE_START_USEC":    "1000000000182000",
+			"SNAPD_STARTUP_STAGE_DURATION_USEC": "1000",
+		}, {
+			"SNAPD_STARTUP_STAGE":               "exec",
+			"SNAPD_STARTUP_STAGE_START_USEC":    "1000000000184000",
+			"SNAPD_STARTUP_STAGE_DURATION_USEC": "0",
+			"SNAPD_STARTUP_COMMAND":             "/snap/foo/1/bin/foo",
+		}, {
+			// not a startup stage
+			"MESSAGE": "something else",
+		}}, nil
+	})
+	defer restore()
+
+	slg := strace.NewExecveTiming(10)
+	slg.StartTime = 1000000000.0
+	slg.Stages = []strace.ExeStage{
+		{Exe: "/usr/lib/snapd/snap-confine", StartSec: 0.020, TotalSec: 0.158},
+		{Exe: "snap-update-ns", StartSec: 0.150, TotalSec: 0.005},
+		{Exe: "/usr/lib/snapd/snap-exec", StartSec: 0.178, TotalSec: 0.007},
+	}
+
+	var buf bytes.Buffer
+	snaprun.DisplayStartupStages(&buf, slg, "trace-id")
+	c.Check(buf.String(), check.Equals, `Startup stages (start, duration):
+  0.020s 0.158s snap-confine
+  0.150s 0.005s snap-update-ns
+  0.178s 0.007s snap-exec
+  0.180s 0.002s snap-exec: read-info
+  0.182s 0.001s snap-exec: environment
+  0.184s 0.000s snap-exec: exec /snap/foo/1/bin/foo
+`)
+}
+
+func (s *RunSuite) TestDisplayStartupStagesJournalError(c *check.C) {
+	logbuf, restore := logger.MockLogger()
+	defer restore()
+	restore = snaprun.MockStartupTraceEntries(func(traceID string) ([]map[string]string, error) {
+		return nil, errors.New("no journal")
+	})
+	defer restore()
+
+	slg := strace.NewExecveTiming(10)
+	slg.Stages = []strace.ExeStage{
+		{Exe: "/usr/lib/snapd/snap-confine", StartSec: 0.020, TotalSec: 0.158},
+	}
+
+	var buf bytes.Buffer
+	snaprun.DisplayStartupStages(&buf, slg, "trace-id")
+	c.Check(buf.String(), check.Equals, `Startup stages (start, duration):
+  0.020s 0.158s snap-confine
+`)
+	c.Check(logbuf.String(), testutil.Contains, "cannot obtain startup trace from the journal: no journal")
+}
+
 func (s *RunSuite) TestSnapRunRestoreSecurityContextHappy(c *check.C) {
 	logbuf, restorer := logger.MockLogger()
 	defer restorer()
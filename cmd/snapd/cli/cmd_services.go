@@ -30,10 +30,12 @@ import (
 	"github.com/snapcore/snapd/client/clientutil"
 	"github.com/snapcore/snapd/i18n"
 	"github.com/snapcore/snapd/osutil/user"
+	"github.com/snapcore/snapd/snap"
 )
 
 type svcStatus struct {
 	clientMixin
+	structuredOutputMixin
 	Positional struct {
 		ServiceNames []serviceName
 	} `positional-args:"yes"`
@@ -84,6 +86,10 @@ The restart command restarts the given services.
 
 If the --reload option is given, for each service whose app has a reload
 command, a reload is performed instead of a restart.
+
+If the --all-affected option is given, the services which need a restart to
+see the changes of the connections of their snap, or to run its new revision
+after they were kept running during a refresh, are restarted.
 `)
 )
 
@@ -122,6 +128,8 @@ func init() {
 		waitDescs.also(userAndScopeDescs).also(map[string]string{
 			// TRANSLATORS: This should not start with a lowercase letter.
 			"reload": i18n.G("If the service has a reload command, use it instead of restarting."),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"all-affected": i18n.G("Restart the services pending a restart instead of the given ones."),
 		}), argdescs)
 }
 
@@ -150,6 +158,42 @@ func (s *svcStatus) validateArguments() error {
 	return nil
 }
 
+// serviceOutput is the structured output of the services command for each
+// service.
+type serviceOutput struct {
+	Service     string `json:"service" yaml:"service"`
+	Daemon      string `json:"daemon" yaml:"daemon"`
+	DaemonScope string `json:"daemon-scope" yaml:"daemon-scope"`
+	Enabled     bool   `json:"enabled" yaml:"enabled"`
+	// Active is unset for user services when listing their global status
+	Active *bool `json:"active,omitempty" yaml:"active,omitempty"`
+	// Sessions and ActiveSessions are only set for user services when
+	// listing their global status while users are logged in
+	Sessions       int `json:"sessions,omitempty" yaml:"sessions,omitempty"`
+	ActiveSessions int `json:"active-sessions,omitempty" yaml:"active-sessions,omitempty"`
+}
+
+func (s *svcStatus) writeStructured(services []*client.AppInfo, isGlobal bool) error {
+	out := make([]serviceOutput, 0, len(services))
+	for _, svc := range services {
+		so := serviceOutput{
+			Service:     svc.Snap + "." + svc.Name,
+			Daemon:      svc.Daemon,
+			DaemonScope: string(svc.DaemonScope),
+			Enabled:     svc.Enabled,
+		}
+		if !(svc.DaemonScope == snap.UserDaemon && isGlobal) {
+			active := svc.Active
+			so.Active = &active
+		} else {
+			so.Sessions = svc.Sessions
+			so.ActiveSessions = svc.ActiveSessions
+		}
+		out = append(out, so)
+	}
+	return s.writeStructuredOutput(out)
+}
+
 func (s *svcStatus) Execute(args []string) error {
 	if len(args) > 0 {
 		return ErrExtraArgs
@@ -173,6 +217,10 @@ func (s *svcStatus) Execute(args []string) error {
 		return err
 	}
 
+	if s.structuredOutput() {
+		return s.writeStructured(services, isGlobal)
+	}
+
 	if len(services) == 0 {
 		fmt.Fprintln(Stderr, i18n.G("There are no services provided by installed snaps."))
 		return nil
@@ -299,9 +347,10 @@ type svcRestart struct {
 	waitMixin
 	clientutil.ServiceScopeOptions
 	Positional struct {
-		ServiceNames []serviceName `required:"1"`
-	} `positional-args:"yes" required:"yes"`
-	Reload bool `long:"reload"`
+		ServiceNames []serviceName
+	} `positional-args:"yes"`
+	Reload      bool `long:"reload"`
+	AllAffected bool `long:"all-affected"`
 }
 
 func (s *svcRestart) Execute(args []string) error {
@@ -312,7 +361,13 @@ func (s *svcRestart) Execute(args []string) error {
 		return err
 	}
 	names := svcNames(s.Positional.ServiceNames)
-	changeID, err := s.client.Restart(names, s.Scope(), s.Users(), client.RestartOptions{Reload: s.Reload})
+	switch {
+	case s.AllAffected && len(names) > 0:
+		return errors.New(i18n.G("cannot use --all-affected with a list of services"))
+	case !s.AllAffected && len(names) == 0:
+		return errors.New(i18n.G("the required argument `<service> (at least 1 argument)` was not provided"))
+	}
+	changeID, err := s.client.Restart(names, s.Scope(), s.Users(), client.RestartOptions{Reload: s.Reload, AllAffected: s.AllAffected})
 	if err != nil {
 		return err
 	}
@@ -171,6 +171,44 @@ func (s *appOpSuite) TestAppOps(c *check.C) {
 	}
 }
 
+func (s *appOpSuite) TestAppOpsRestartAllAffected(c *check.C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.Method, check.Equals, "POST")
+			c.Check(r.URL.Path, check.Equals, "/v2/apps")
+			c.Check(DecodedRequestBody(c, r), check.DeepEquals, map[string]any{
+				"action":       "restart",
+				"names":        []any{},
+				"users":        []any{},
+				"all-affected": true,
+			})
+			w.WriteHeader(202)
+			fmt.Fprintln(w, `{"type":"async", "change": "42", "status-code": 202}`)
+		case 1:
+			c.Check(r.Method, check.Equals, "GET")
+			c.Check(r.URL.Path, check.Equals, "/v2/changes/42")
+			fmt.Fprintln(w, `{"type": "sync", "result": {"ready": true, "status": "Done"}}`)
+		default:
+			c.Fatalf("expected to get 2 requests, now on %d", n+1)
+		}
+		n++
+	})
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"restart", "--all-affected"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.HasLen, 0)
+	c.Check(s.Stdout(), check.Equals, "Restarted.\n")
+	c.Check(s.Stderr(), check.Equals, "")
+	c.Check(n, check.Equals, 2)
+}
+
+func (s *appOpSuite) TestAppOpsRestartAllAffectedWithNames(c *check.C) {
+	s.RedirectClientToTestServer(nil)
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"restart", "--all-affected", "foo"})
+	c.Assert(err, check.ErrorMatches, `cannot use --all-affected with a list of services`)
+}
+
 func (s *appOpSuite) TestAppOpsScopeSwitches(c *check.C) {
 	var n int
 	var body map[string]any
@@ -310,6 +348,14 @@ func (s *appOpSuite) TestAppStatus(c *check.C) {
 						"daemon-scope": "user",
 						"active":       false,
 						"enabled":      true,
+					}, {
+						"snap":            "foo",
+						"name":            "quux",
+						"daemon":          "simple",
+						"daemon-scope":    "user",
+						"enabled":         true,
+						"sessions":        3,
+						"active-sessions": 2,
 					}, {
 						"snap":    "foo",
 						"name":    "zed",
@@ -429,11 +475,12 @@ func (s *appOpSuite) TestAppStatusGlobal(c *check.C) {
 	c.Assert(err, check.IsNil)
 	c.Assert(rest, check.HasLen, 0)
 	c.Check(s.Stderr(), check.Equals, "")
-	c.Check(s.Stdout(), check.Equals, `Service  Startup  Current   Notes
-foo.bar  enabled  inactive  timer-activated
-foo.baz  enabled  inactive  socket-activated
-foo.qux  enabled  -         user
-foo.zed  enabled  active    -
+	c.Check(s.Stdout(), check.Equals, `Service   Startup  Current     Notes
+foo.bar   enabled  inactive    timer-activated
+foo.baz   enabled  inactive    socket-activated
+foo.qux   enabled  -           user
+foo.quux  enabled  2/3 active  user
+foo.zed   enabled  active      -
 `)
 	// ensure that the fake server api was actually hit
 	c.Check(n, check.Equals, 1)
@@ -535,6 +582,52 @@ func (s *appOpSuite) TestAppStatusNoServices(c *check.C) {
 	c.Check(n, check.Equals, 1)
 }
 
+func (s *appOpSuite) TestAppStatusRestartPending(c *check.C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.URL.Path, check.Equals, "/v2/apps")
+			c.Check(r.Method, check.Equals, "GET")
+			w.WriteHeader(200)
+			enc := json.NewEncoder(w)
+			enc.Encode(map[string]any{
+				"type": "sync",
+				"result": []map[string]any{
+					{
+						"snap":            "foo",
+						"name":            "bar",
+						"daemon":          "simple",
+						"active":          true,
+						"enabled":         true,
+						"restart-pending": true,
+					}, {
+						"snap":    "foo",
+						"name":    "baz",
+						"daemon":  "simple",
+						"active":  true,
+						"enabled": true,
+					},
+				},
+				"status":      "OK",
+				"status-code": 200,
+			})
+		default:
+			c.Fatalf("expected to get 1 requests, now on %d", n+1)
+		}
+		n++
+	})
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"services"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.HasLen, 0)
+	c.Check(s.Stdout(), check.Equals, `Service  Startup  Current  Notes
+foo.bar  enabled  active   restart-pending
+foo.baz  enabled  active   -
+`)
+	c.Check(s.Stderr(), check.Equals, "")
+	c.Check(n, check.Equals, 1)
+}
+
 func (s *appOpSuite) TestLogsCommand(c *check.C) {
 	n := 0
 	timestamp := "2021-08-16T17:33:55Z"
@@ -45,6 +45,7 @@ type cmdSign struct {
 	} `positional-args:"yes"`
 
 	KeyName         keyName `short:"k" default:"default"`
+	PKCS11URI       string  `long:"pkcs11-uri"`
 	Chain           bool    `long:"chain"`
 	UpdateTimestamp bool    `long:"update-timestamp"`
 }
@@ -56,6 +57,8 @@ func init() {
 		// TRANSLATORS: This should not start with a lowercase letter.
 		"k": i18n.G("Name of the key to use, otherwise use the default key"),
 		// TRANSLATORS: This should not start with a lowercase letter.
+		"pkcs11-uri": i18n.G("Use the keys of the PKCS#11 token (e.g. an HSM or the TPM) selected by this PKCS#11 URI, named by their label"),
+		// TRANSLATORS: This should not start with a lowercase letter.
 		"chain": i18n.G("Append the account and account-key assertions necessary to allow any device to validate the signed assertion."),
 		// TRANSLATORS: This should not start with a lowercase letter.
 		"update-timestamp": i18n.G("Update the output \"timestamp\" header to the current time"),
@@ -87,7 +90,7 @@ func (x *cmdSign) Execute(args []string) error {
 		return fmt.Errorf(i18n.G("cannot read assertion input: %v"), err)
 	}
 
-	keypairMgr, err := signtool.GetKeypairManager()
+	keypairMgr, err := getKeypairManager(x.PKCS11URI)
 	if err != nil {
 		return err
 	}
@@ -152,6 +155,15 @@ func (x *cmdSign) Execute(args []string) error {
 	return nil
 }
 
+// getKeypairManager returns the keypair manager for the PKCS#11 token
+// selected by pkcs11URI if set, otherwise the default one.
+func getKeypairManager(pkcs11URI string) (signtool.KeypairManager, error) {
+	if pkcs11URI != "" {
+		return signtool.GetPKCS11KeypairManager(pkcs11URI)
+	}
+	return signtool.GetKeypairManager()
+}
+
 // call this function in a way that is guaranteed to specify a unique assertion
 // (i.e. with a header specifying a value for the assertion's primary key)
 func mustGetOneAssert(assertType string, headers map[string]string) (asserts.Assertion, error) {
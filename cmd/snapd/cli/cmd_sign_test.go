@@ -25,6 +25,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
 	"time"
 
 	. "gopkg.in/check.v1"
@@ -70,6 +71,21 @@ func (s *SnapKeysSuite) TestHappyNonDefaultKey(c *C) {
 	c.Check(a.Type(), Equals, asserts.SnapBuildType)
 }
 
+func (s *SnapKeysSuite) TestSignPKCS11URIInvalid(c *C) {
+	s.stdin.Write(statement)
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"sign", "--pkcs11-uri", "token=release"})
+	c.Assert(err, ErrorMatches, `cannot parse PKCS#11 URI: missing "pkcs11:" scheme`)
+}
+
+func (s *SnapKeysSuite) TestSignPKCS11URIMissingTool(c *C) {
+	s.stdin.Write(statement)
+	os.Setenv("PATH", c.MkDir())
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"sign", "--pkcs11-uri", "pkcs11:token=release"})
+	c.Assert(err, ErrorMatches, `cannot setup PKCS#11 keypair manager: cannot use PKCS#11 token: pkcs11-tool \(from OpenSC\) is not available: .*`)
+}
+
 const mockAccountKeyAssertion = `type: account-key
 authority-id: canonical
 public-key-sha3-384: g4Pks54W_US4pZuxhgG_RHNAf_UeZBBuZyGRLLmMj1Do3GkE_r_5A5BFjx24ZwVJ
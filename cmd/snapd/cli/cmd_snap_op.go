@@ -88,6 +88,11 @@ revision is removed.
 Unless automatic snapshots are disabled, a snapshot of all data for the snap is
 saved upon removal, which is then available for future restoration with snap
 restore. The --purge option disables automatically creating snapshots.
+
+With --keep-config, the configuration of the snap is kept and restored when
+the snap is installed again. With --keep-connections, its manual interface
+connections are kept until then as well, instead of being forgotten after a
+while. Kept configurations are listed by 'snap saved --configs'.
 `)
 
 var longRefreshHelp = i18n.G(`
@@ -111,6 +116,17 @@ requests from 'snap refresh target-snap'.
 When snaps are specified --hold is effective on both their auto-refreshes
 and general refresh requests from 'snap refresh'. However, specific snap
 requests from 'snap refresh target-snap' remain unblocked and will proceed.
+
+Hold windows (--hold-windows) restrict the hold of the specified snaps to
+recurring calendar windows, using the same format as the refresh.timer option
+(e.g. "mon-fri,9:00-17:00"). Outside of the windows the snaps are refreshed as
+usual. The holds are listed by 'snap refresh --time'.
+
+Stagger (--stagger) controls when auto-refreshes happen within the refresh
+window: at a "random" time, which is the default, at a time derived from the
+serial of the device with "device", or at a percentage of the window, e.g.
+"25%". Staggering by device spreads a fleet of devices over the window while
+each device keeps refreshing at the same point of it.
 `)
 
 var longTryHelp = i18n.G(`
@@ -137,10 +153,12 @@ and the snap can easily be enabled again.
 type cmdRemove struct {
 	waitMixin
 
-	Revision   string `long:"revision"`
-	Purge      bool   `long:"purge"`
-	Terminate  bool   `long:"terminate"`
-	Positional struct {
+	Revision        string `long:"revision"`
+	Purge           bool   `long:"purge"`
+	Terminate       bool   `long:"terminate"`
+	KeepConfig      bool   `long:"keep-config"`
+	KeepConnections bool   `long:"keep-connections"`
+	Positional      struct {
 		Snaps []installedSnapName `positional-arg-name:"<snap>" required:"1"`
 	} `positional-args:"yes" required:"yes"`
 }
@@ -322,7 +340,13 @@ func (x *cmdRemove) removeMany(opts *client.SnapOptions) error {
 }
 
 func (x *cmdRemove) Execute([]string) error {
-	opts := &client.SnapOptions{Revision: x.Revision, Purge: x.Purge, Terminate: x.Terminate}
+	opts := &client.SnapOptions{
+		Revision:        x.Revision,
+		Purge:           x.Purge,
+		Terminate:       x.Terminate,
+		KeepConfig:      x.KeepConfig,
+		KeepConnections: x.KeepConnections,
+	}
 	if len(x.Positional.Snaps) == 1 {
 		return x.removeOne(opts)
 	}
@@ -334,7 +358,7 @@ func (x *cmdRemove) Execute([]string) error {
 }
 
 type channelMixin struct {
-	Channel string `long:"channel"`
+	Channel channelName `long:"channel"`
 
 	// shortcuts
 	EdgeChannel      bool `long:"edge"`
@@ -385,11 +409,11 @@ func (mx *channelMixin) setChannelFromCommandline() error {
 		if mx.Channel != "" {
 			return fmt.Errorf("please specify a single channel")
 		}
-		mx.Channel = ch.chName
+		mx.Channel = channelName(ch.chName)
 	}
 
 	if mx.Channel != "" {
-		if _, err := channel.Parse(mx.Channel, ""); err != nil {
+		if _, err := channel.Parse(string(mx.Channel), ""); err != nil {
 			return fmt.Errorf("cannot parse channel: %v", err)
 		}
 	}
@@ -721,8 +745,9 @@ type cmdInstall struct {
 	// because we released 2.14.2 with --force-dangerous
 	ForceDangerous bool `long:"force-dangerous" hidden:"yes"`
 
-	Unaliased bool `long:"unaliased"`
-	Prefer    bool `long:"prefer"`
+	Unaliased            bool `long:"unaliased"`
+	Prefer               bool `long:"prefer"`
+	NoRestoreConnections bool `long:"no-restore-connections"`
 
 	Name string `long:"name"`
 
@@ -928,16 +953,17 @@ func (x *cmdInstall) Execute([]string) error {
 
 	dangerous := x.Dangerous || x.ForceDangerous
 	opts := &client.SnapOptions{
-		Channel:          x.Channel,
-		Revision:         x.Revision,
-		Dangerous:        dangerous,
-		Unaliased:        x.Unaliased,
-		CohortKey:        x.Cohort,
-		IgnoreValidation: x.IgnoreValidation,
-		IgnoreRunning:    x.IgnoreRunning,
-		Transaction:      x.Transaction,
-		QuotaGroupName:   x.QuotaGroupName,
-		Prefer:           x.Prefer,
+		Channel:              string(x.Channel),
+		Revision:             x.Revision,
+		Dangerous:            dangerous,
+		Unaliased:            x.Unaliased,
+		CohortKey:            x.Cohort,
+		IgnoreValidation:     x.IgnoreValidation,
+		IgnoreRunning:        x.IgnoreRunning,
+		Transaction:          x.Transaction,
+		QuotaGroupName:       x.QuotaGroupName,
+		Prefer:               x.Prefer,
+		NoRestoreConnections: x.NoRestoreConnections,
 	}
 	x.setModes(opts)
 
@@ -986,7 +1012,9 @@ type cmdRefresh struct {
 	Tracking         bool                   `long:"tracking"`
 	Transaction      client.TransactionType `long:"transaction" default:"per-snap" choice:"all-snaps" choice:"per-snap"`
 	Hold             string                 `long:"hold" optional:"yes" optional-value:"forever"`
+	HoldWindows      string                 `long:"hold-windows"`
 	Unhold           bool                   `long:"unhold"`
+	Stagger          string                 `long:"stagger"`
 	Positional       struct {
 		Snaps []installedSnapName `positional-arg-name:"<snap>"`
 	} `positional-args:"yes"`
@@ -1107,6 +1135,45 @@ func (x *cmdRefresh) showRefreshTimes() error {
 	} else {
 		fmt.Fprintf(Stdout, "next: n/a\n")
 	}
+	if sysinfo.Refresh.Stagger != "" {
+		if sysinfo.Refresh.StaggerOffset != "" {
+			fmt.Fprintf(Stdout, "stagger: %s (%s into the refresh window)\n", sysinfo.Refresh.Stagger, sysinfo.Refresh.StaggerOffset)
+		} else {
+			fmt.Fprintf(Stdout, "stagger: %s\n", sysinfo.Refresh.Stagger)
+		}
+	}
+	if sysinfo.Refresh.DownloadWindow != "" {
+		fmt.Fprintf(Stdout, "download-window: %s\n", sysinfo.Refresh.DownloadWindow)
+	}
+	if deferred := parseSysinfoTime(sysinfo.Refresh.DownloadsDeferred); !deferred.IsZero() {
+		fmt.Fprintf(Stdout, "downloads: deferred until %s (outside of the download window)\n", x.fmtTime(deferred))
+	}
+	if len(sysinfo.Refresh.Backoff) > 0 {
+		fmt.Fprintf(Stdout, "backoff:\n")
+		for _, backoff := range sysinfo.Refresh.Backoff {
+			until := parseSysinfoTime(backoff.Until)
+			fmt.Fprintf(Stdout, "  %s: revision %s (failures: %d), retrying after %s\n", backoff.Snap, backoff.Revision, backoff.Failures, x.fmtTime(until))
+			if backoff.LastError != "" {
+				fmt.Fprintf(Stdout, "    last error: %s\n", backoff.LastError)
+			}
+		}
+	}
+	if len(sysinfo.Refresh.Holds) > 0 {
+		fmt.Fprintf(Stdout, "holds:\n")
+		for _, hold := range sysinfo.Refresh.Holds {
+			until := parseSysinfoTime(hold.Until)
+			// as for the general hold, show very long holds as "forever"
+			untilStr := "until " + x.fmtTime(until)
+			if until.After(timeNow().Add(100 * 365 * 24 * time.Hour)) {
+				untilStr = "forever"
+			}
+			fmt.Fprintf(Stdout, "  %s: %s refreshes held %s", hold.Snap, hold.Level, untilStr)
+			if hold.Windows != "" {
+				fmt.Fprintf(Stdout, " during %s", hold.Windows)
+			}
+			fmt.Fprintf(Stdout, "\n")
+		}
+	}
 	return nil
 }
 
@@ -1169,7 +1236,21 @@ func (x *cmdRefresh) Execute([]string) error {
 		x.LeaveCohort || x.List || x.Time || x.IgnoreValidation || x.IgnoreRunning ||
 		x.Transaction != client.TransactionPerSnap
 
+	if x.HoldWindows != "" {
+		if x.Hold == "" {
+			return errors.New(i18n.G("cannot use --hold-windows without --hold"))
+		}
+		if len(x.Positional.Snaps) == 0 {
+			return errors.New(i18n.G("cannot use --hold-windows without snaps"))
+		}
+	}
+
 	switch {
+	case x.Stagger != "":
+		if x.Hold != "" || x.Unhold || otherFlags || x.Tracking || len(x.Positional.Snaps) > 0 {
+			return errors.New(i18n.G("cannot use --stagger with other flags or snaps"))
+		}
+		return x.staggerRefreshes()
 	case x.Tracking:
 		if x.Hold != "" || x.Unhold || otherFlags {
 			return errors.New(i18n.G("cannot use --tracking with other flags"))
@@ -1191,7 +1272,7 @@ func (x *cmdRefresh) Execute([]string) error {
 	if len(names) == 1 {
 		opts := &client.SnapOptions{
 			Amend:            x.Amend,
-			Channel:          x.Channel,
+			Channel:          string(x.Channel),
 			IgnoreValidation: x.IgnoreValidation,
 			IgnoreRunning:    x.IgnoreRunning,
 			Revision:         x.Revision,
@@ -1280,6 +1361,8 @@ func (x *cmdRefresh) holdRefreshes() (err error) {
 		opts.Time = timeNow().Add(dur).Format(time.RFC3339)
 	}
 
+	opts.HoldWindows = x.HoldWindows
+
 	names := installedSnapNames(x.Positional.Snaps)
 	var changeID string
 	opts.HoldLevel = "general"
@@ -1310,6 +1393,11 @@ func (x *cmdRefresh) holdRefreshes() (err error) {
 		timeStr = fmt.Sprintf(i18n.G("until %s"), opts.Time)
 	}
 
+	if x.HoldWindows != "" {
+		// TRANSLATORS: %s is a schedule like "mon-fri,9:00-17:00"
+		timeStr += fmt.Sprintf(i18n.G(" during %s"), x.HoldWindows)
+	}
+
 	if len(names) == 0 {
 		fmt.Fprintf(Stdout, i18n.G("Auto-refresh of all snaps held %s\n"), timeStr)
 	} else {
@@ -1349,6 +1437,23 @@ func (x *cmdRefresh) unholdRefreshes() (err error) {
 	return nil
 }
 
+func (x *cmdRefresh) staggerRefreshes() error {
+	changeID, err := x.client.SetConf("core", map[string]any{"refresh.stagger": x.Stagger})
+	if err != nil {
+		return err
+	}
+
+	if _, err := x.wait(changeID); err != nil {
+		if err == noWait {
+			return nil
+		}
+		return err
+	}
+
+	fmt.Fprintf(Stdout, i18n.G("Auto-refreshes staggered by %s within the refresh window\n"), x.Stagger)
+	return nil
+}
+
 type cmdTry struct {
 	waitMixin
 
@@ -1623,6 +1728,10 @@ func init() {
 			"purge": i18n.G("Remove the snap without saving a snapshot of its data"),
 			// TRANSLATORS: This should not start with a lowercase letter.
 			"terminate": i18n.G("Terminate running processes associated with a snap before removal"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"keep-config": i18n.G("Keep the configuration of the snap to restore it when the snap is installed again"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"keep-connections": i18n.G("Keep the manual connections of the snap to restore them when the snap is installed again"),
 		}), nil)
 	addCommand("install", shortInstallHelp, longInstallHelp, func() flags.Commander { return &cmdInstall{} },
 		colorDescs.also(waitDescs).also(channelDescs).also(modeDescs).also(map[string]string{
@@ -1635,6 +1744,8 @@ func init() {
 			// TRANSLATORS: This should not start with a lowercase letter.
 			"unaliased": i18n.G("Install the given snap without enabling its automatic aliases"),
 			// TRANSLATORS: This should not start with a lowercase letter.
+			"no-restore-connections": i18n.G("Do not restore the manual connections of a recently removed installation of the snap"),
+			// TRANSLATORS: This should not start with a lowercase letter.
 			"name": i18n.G("Install the snap file under the given instance name"),
 			// TRANSLATORS: This should not start with a lowercase letter.
 			"cohort": i18n.G("Install the snap in the given cohort"),
@@ -1674,7 +1785,11 @@ func init() {
 			// TRANSLATORS: This should not start with a lowercase letter.
 			"hold": i18n.G("Hold refreshes for a specified duration (or forever, if no value is specified)"),
 			// TRANSLATORS: This should not start with a lowercase letter.
+			"hold-windows": i18n.G("Only hold refreshes during the given recurring windows, in refresh.timer format"),
+			// TRANSLATORS: This should not start with a lowercase letter.
 			"unhold": i18n.G("Remove refresh hold"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"stagger": i18n.G("Set when auto-refreshes happen within the refresh window: random, device or a percentage"),
 		}), nil)
 	addCommand("try", shortTryHelp, longTryHelp, func() flags.Commander { return &cmdTry{} }, waitDescs.also(modeDescs), nil)
 	addCommand("enable", shortEnableHelp, longEnableHelp, func() flags.Commander { return &cmdEnable{} }, waitDescs, nil)
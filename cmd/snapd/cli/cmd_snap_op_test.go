@@ -293,6 +293,40 @@ func (s *SnapOpSuite) TestWaitRecovers(c *check.C) {
 	c.Check(meter.Labels, testutil.Contains, "Waiting for server to restart")
 }
 
+func (s *SnapOpSuite) TestWaitWatchesChange(c *check.C) {
+	meter := &progresstest.Meter{}
+	defer progress.MockMeter(meter)()
+
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, check.Equals, "GET")
+		c.Check(r.URL.Path, check.Equals, "/v2/changes/x")
+		switch n {
+		case 0:
+			c.Check(r.URL.RawQuery, check.Equals, "")
+			fmt.Fprintln(w, `{"type": "sync", "result": {"status": "Doing", "update-id": "3", "tasks": [{"id": "1", "summary": "foo", "status": "Doing", "progress": {"done": 1, "total": 5}}]}}`)
+		case 1:
+			c.Check(r.URL.Query().Get("update-id"), check.Equals, "3")
+			c.Check(r.URL.Query().Get("timeout"), check.Not(check.Equals), "")
+			fmt.Fprintln(w, `{"type": "sync", "result": {"status": "Doing", "update-id": "4", "tasks": [{"id": "1", "summary": "foo", "status": "Doing", "progress": {"done": 3, "total": 5}}]}}`)
+		case 2:
+			c.Check(r.URL.Query().Get("update-id"), check.Equals, "4")
+			fmt.Fprintln(w, `{"type": "sync", "result": {"ready": true, "status": "Done", "update-id": "6"}}`)
+		default:
+			c.Fatalf("expected to get 3 requests, now on %d", n+1)
+		}
+		n++
+	})
+
+	cli := snap.Client()
+	chg, err := snap.Wait(cli, "x")
+	c.Assert(err, check.IsNil)
+	c.Check(chg.Status, check.Equals, "Done")
+	c.Check(n, check.Equals, 3)
+	c.Check(meter.Labels, testutil.Contains, "foo")
+	c.Check(meter.Values, testutil.Contains, 3.0)
+}
+
 func (s *SnapOpSuite) TestWaitRebooting(c *check.C) {
 	meter := &progresstest.Meter{}
 	defer progress.MockMeter(meter)()
@@ -988,6 +1022,26 @@ func (s *SnapOpSuite) TestInstallPrefer(c *check.C) {
 	c.Check(s.srv.n, check.Equals, s.srv.total)
 }
 
+func (s *SnapOpSuite) TestInstallNoRestoreConnections(c *check.C) {
+	s.srv.checker = func(r *http.Request) {
+		c.Check(r.URL.Path, check.Equals, "/v2/snaps/foo")
+		c.Check(DecodedRequestBody(c, r), check.DeepEquals, map[string]any{
+			"action":                 "install",
+			"no-restore-connections": true,
+			"transaction":            string(client.TransactionPerSnap),
+		})
+	}
+
+	s.RedirectClientToTestServer(s.srv.handle)
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"install", "--no-restore-connections", "foo"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.DeepEquals, []string{})
+	c.Check(s.Stdout(), check.Matches, `(?sm).*foo 1.0 from Bar installed`)
+	c.Check(s.Stderr(), check.Equals, "")
+	// ensure that the fake server api was actually hit
+	c.Check(s.srv.n, check.Equals, s.srv.total)
+}
+
 func (s *SnapOpSuite) TestInstallSnapNotFound(c *check.C) {
 	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintln(w, `{"type": "error", "result": {"message": "snap not found", "value": "foo", "kind": "snap-not-found"}, "status-code": 404}`)
@@ -2081,6 +2135,166 @@ next: 2017-04-26T00:58:00+02:00
 	c.Check(n, check.Equals, 1)
 }
 
+func (s *SnapSuite) TestRefreshTimeShowsDownloadWindow(c *check.C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.Method, check.Equals, "GET")
+			c.Check(r.URL.Path, check.Equals, "/v2/system-info")
+			fmt.Fprintln(w, `{"type": "sync", "status-code": 200, "result": {"refresh": {"timer": "0:00-24:00/4", "last": "2017-04-25T17:35:00+02:00", "next": "2017-04-26T00:58:00+02:00", "download-window": "22:00-06:00", "downloads-deferred": "2017-04-26T22:00:00+02:00"}}}`)
+		default:
+			c.Fatalf("expected to get 1 requests, now on %d", n+1)
+		}
+
+		n++
+	})
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"refresh", "--time", "--abs-time"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.DeepEquals, []string{})
+	c.Check(s.Stdout(), check.Equals, `timer: 0:00-24:00/4
+last: 2017-04-25T17:35:00+02:00
+next: 2017-04-26T00:58:00+02:00
+download-window: 22:00-06:00
+downloads: deferred until 2017-04-26T22:00:00+02:00 (outside of the download window)
+`)
+	c.Check(s.Stderr(), check.Equals, "")
+	c.Check(n, check.Equals, 1)
+}
+
+func (s *SnapSuite) TestRefreshTimeShowsBackoff(c *check.C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.Method, check.Equals, "GET")
+			c.Check(r.URL.Path, check.Equals, "/v2/system-info")
+			fmt.Fprintln(w, `{"type": "sync", "status-code": 200, "result": {"refresh": {"timer": "0:00-24:00/4", "last": "2017-04-25T17:35:00+02:00", "next": "2017-04-26T00:58:00+02:00", "backoff": [{"snap": "bar", "revision": "7", "failures": 1, "until": "2017-04-26T08:00:00+02:00"}, {"snap": "foo", "revision": "42", "failures": 3, "last-error": "run hook \"pre-refresh\": boom", "until": "2017-04-27T17:35:00+02:00"}]}}}`)
+		default:
+			c.Fatalf("expected to get 1 requests, now on %d", n+1)
+		}
+
+		n++
+	})
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"refresh", "--time", "--abs-time"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.DeepEquals, []string{})
+	c.Check(s.Stdout(), check.Equals, `timer: 0:00-24:00/4
+last: 2017-04-25T17:35:00+02:00
+next: 2017-04-26T00:58:00+02:00
+backoff:
+  bar: revision 7 (failures: 1), retrying after 2017-04-26T08:00:00+02:00
+  foo: revision 42 (failures: 3), retrying after 2017-04-27T17:35:00+02:00
+    last error: run hook "pre-refresh": boom
+`)
+	c.Check(s.Stderr(), check.Equals, "")
+	c.Check(n, check.Equals, 1)
+}
+
+func (s *SnapSuite) TestRefreshTimeShowsSnapHolds(c *check.C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.Method, check.Equals, "GET")
+			c.Check(r.URL.Path, check.Equals, "/v2/system-info")
+			fmt.Fprintln(w, `{"type": "sync", "status-code": 200, "result": {"refresh": {"timer": "0:00-24:00/4", "last": "2017-04-25T17:35:00+02:00", "next": "2017-04-26T00:58:00+02:00", "holds": [{"snap": "bar", "level": "auto-refresh", "until": "2017-04-26T08:00:00+02:00"}, {"snap": "foo", "level": "general", "until": "2317-04-26T08:00:00+02:00", "windows": "mon-fri,9:00-17:00"}]}}}`)
+		default:
+			c.Fatalf("expected to get 1 requests, now on %d", n+1)
+		}
+
+		n++
+	})
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"refresh", "--time", "--abs-time"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.DeepEquals, []string{})
+	c.Check(s.Stdout(), check.Equals, `timer: 0:00-24:00/4
+last: 2017-04-25T17:35:00+02:00
+next: 2017-04-26T00:58:00+02:00
+holds:
+  bar: auto-refresh refreshes held until 2017-04-26T08:00:00+02:00
+  foo: general refreshes held forever during mon-fri,9:00-17:00
+`)
+	c.Check(s.Stderr(), check.Equals, "")
+	c.Check(n, check.Equals, 1)
+}
+
+func (s *SnapSuite) TestRefreshTimeShowsStagger(c *check.C) {
+	for _, tc := range []struct {
+		refresh string
+		out     string
+	}{
+		{`"stagger": "device", "stagger-offset": "1h23m4s"`, "stagger: device (1h23m4s into the refresh window)\n"},
+		{`"stagger": "random"`, "stagger: random\n"},
+	} {
+		s.ResetStdStreams()
+		s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+			c.Check(r.Method, check.Equals, "GET")
+			c.Check(r.URL.Path, check.Equals, "/v2/system-info")
+			fmt.Fprintf(w, `{"type": "sync", "status-code": 200, "result": {"refresh": {"timer": "0:00-24:00/4", "last": "2017-04-25T17:35:00+02:00", "next": "2017-04-26T00:58:00+02:00", %s}}}`+"\n", tc.refresh)
+		})
+		rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"refresh", "--time", "--abs-time"})
+		c.Assert(err, check.IsNil)
+		c.Assert(rest, check.DeepEquals, []string{})
+		c.Check(s.Stdout(), check.Equals, `timer: 0:00-24:00/4
+last: 2017-04-25T17:35:00+02:00
+next: 2017-04-26T00:58:00+02:00
+`+tc.out)
+		c.Check(s.Stderr(), check.Equals, "")
+	}
+}
+
+func (s *SnapSuite) TestRefreshStagger(c *check.C) {
+	var n int
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.Method, check.Equals, "PUT")
+			c.Check(r.URL.Path, check.Equals, "/v2/snaps/core/conf")
+			c.Check(DecodedRequestBody(c, r), check.DeepEquals, map[string]any{
+				"refresh.stagger": "device",
+			})
+			w.WriteHeader(202)
+			fmt.Fprintln(w, `{"type": "async", "change": "42", "status-code": 202}`)
+
+		case 1:
+			c.Check(r.Method, check.Equals, "GET")
+			c.Check(r.URL.Path, check.Equals, "/v2/changes/42")
+			w.WriteHeader(200)
+			fmt.Fprintln(w, `{"type": "sync", "result": {"ready": true, "status": "Done"}}`)
+
+		default:
+			c.Errorf("expected to get 2 requests, now on %d", n+1)
+			fmt.Fprintln(w, `{"type": "error", "result": {"message": "received too many requests"}, "status-code": 500}`)
+		}
+
+		n++
+	})
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"refresh", "--stagger=device"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.DeepEquals, []string{})
+	c.Check(s.Stdout(), check.Equals, "Auto-refreshes staggered by device within the refresh window\n")
+	c.Check(s.Stderr(), check.Equals, "")
+	c.Check(n, check.Equals, 2)
+}
+
+func (s *SnapSuite) TestRefreshStaggerFailsWithOtherFlags(c *check.C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Errorf("unexpected request")
+		fmt.Fprintln(w, `{"type": "error", "result": {"message": "received too many requests"}, "status-code": 500}`)
+	})
+
+	for _, args := range [][]string{
+		{"refresh", "--stagger=device", "--amend"},
+		{"refresh", "--stagger=device", "--hold"},
+		{"refresh", "--stagger=device", "foo"},
+	} {
+		_, err := snap.Parser(snap.Client()).ParseArgs(args)
+		c.Assert(err, check.ErrorMatches, "cannot use --stagger with other flags or snaps")
+	}
+}
+
 func (s *SnapSuite) TestRefreshTimeShowsHolds(c *check.C) {
 	type testcase struct {
 		in  string
@@ -2290,6 +2504,56 @@ func (s *SnapSuite) TestRefreshHoldSnapForever(c *check.C) {
 	c.Check(s.Stderr(), check.Equals, "")
 }
 
+func (s *SnapSuite) TestRefreshHoldManyInWindows(c *check.C) {
+	var n int
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.Method, check.Equals, "POST")
+			c.Check(r.URL.Path, check.Equals, "/v2/snaps")
+			c.Check(DecodedRequestBody(c, r), check.DeepEquals, map[string]any{
+				"action":       "hold",
+				"snaps":        []any{"foo", "bar"},
+				"time":         "forever",
+				"hold-level":   "general",
+				"hold-windows": "mon-fri,9:00-17:00",
+			})
+			w.WriteHeader(202)
+			fmt.Fprintln(w, `{"type": "async", "change": "42", "status-code": 202}`)
+
+		case 1:
+			c.Check(r.Method, check.Equals, "GET")
+			c.Check(r.URL.Path, check.Equals, "/v2/changes/42")
+			w.WriteHeader(200)
+			fmt.Fprintln(w, `{"type": "sync", "result": {"ready": true, "status": "Done"}}`)
+
+		default:
+			c.Errorf("expected to get 2 requests, now on %d", n+1)
+			fmt.Fprintln(w, `{"type": "error", "result": {"message": "received too many requests"}, "status-code": 500}`)
+		}
+
+		n++
+	})
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"refresh", "--hold", "--hold-windows=mon-fri,9:00-17:00", "foo", "bar"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.DeepEquals, []string{})
+	c.Check(s.Stdout(), check.Equals, "General refreshes of \"foo\", \"bar\" held indefinitely during mon-fri,9:00-17:00\n")
+	c.Check(s.Stderr(), check.Equals, "")
+}
+
+func (s *SnapSuite) TestRefreshHoldWindowsErrors(c *check.C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Fatalf("unexpected request")
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"refresh", "--hold-windows=sat-sun", "foo"})
+	c.Check(err, check.ErrorMatches, "cannot use --hold-windows without --hold")
+
+	_, err = snap.Parser(snap.Client()).ParseArgs([]string{"refresh", "--hold", "--hold-windows=sat-sun"})
+	c.Check(err, check.ErrorMatches, "cannot use --hold-windows without snaps")
+}
+
 func (s *SnapSuite) TestRefreshUnholdAllSnaps(c *check.C) {
 	var n int
 	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
@@ -3326,6 +3590,27 @@ func (s *SnapOpSuite) TestRemoveWithTerminate(c *check.C) {
 	c.Check(s.srv.n, check.Equals, s.srv.total)
 }
 
+func (s *SnapOpSuite) TestRemoveWithKeepConfig(c *check.C) {
+	s.srv.total = 3
+	s.srv.checker = func(r *http.Request) {
+		c.Check(r.URL.Path, check.Equals, "/v2/snaps/foo")
+		c.Check(DecodedRequestBody(c, r), check.DeepEquals, map[string]any{
+			"action":           "remove",
+			"keep-config":      true,
+			"keep-connections": true,
+		})
+	}
+
+	s.RedirectClientToTestServer(s.srv.handle)
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"remove", "--keep-config", "--keep-connections", "foo"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.DeepEquals, []string{})
+	c.Check(s.Stdout(), check.Matches, `(?sm).*foo removed`)
+	c.Check(s.Stderr(), check.Equals, "")
+	// ensure that the fake server api was actually hit
+	c.Check(s.srv.n, check.Equals, s.srv.total)
+}
+
 func (s *SnapOpSuite) TestRemoveInsufficientDiskSpace(c *check.C) {
 	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintln(w, `{
@@ -20,6 +20,7 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -28,6 +29,7 @@ import (
 
 	"github.com/jessevdk/go-flags"
 
+	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/i18n"
 	"github.com/snapcore/snapd/strutil"
 	"github.com/snapcore/snapd/strutil/quantity"
@@ -52,6 +54,10 @@ var (
 var longSavedHelp = i18n.G(`
 The saved command displays a list of snapshots that have been created
 previously with the 'save' command.
+
+With --configs, the configurations of snaps removed with 'snap remove
+--keep-config' are listed instead. These are restored when the snaps are
+installed again.
 `)
 var longSaveHelp = i18n.G(`
 The save command creates a snapshot of the current user, system and
@@ -89,6 +95,10 @@ for which users, or a combination of these.
 If a snap is included in a check-snapshot operation, excluding its
 system and configuration data from the check is not currently
 possible. This restriction may be lifted in the future.
+
+With --deep, all the archives in the snapshot are checked instead of
+stopping at the first one that fails verification, and their contents
+are read in full. The archives found to be corrupted are then listed.
 `)
 var longRestoreHelp = i18n.G(`
 The restore command replaces the current user, system and
@@ -117,12 +127,20 @@ type savedCmd struct {
 	clientMixin
 	durationMixin
 	ID         snapshotID `long:"id"`
+	Configs    bool       `long:"configs"`
 	Positional struct {
 		Snaps []installedSnapName `positional-arg-name:"<snap>"`
 	} `positional-args:"yes"`
 }
 
 func (x *savedCmd) Execute([]string) error {
+	if x.Configs {
+		if x.ID != "" {
+			return errors.New(i18n.G("cannot use --id with --configs"))
+		}
+		return x.showKeptConfigs()
+	}
+
 	var setID uint64
 	var err error
 	if x.ID != "" {
@@ -177,6 +195,37 @@ func (x *savedCmd) Execute([]string) error {
 	return nil
 }
 
+func (x *savedCmd) showKeptConfigs() error {
+	list, err := x.client.KeptConfigs(installedSnapNames(x.Positional.Snaps))
+	if err != nil {
+		return err
+	}
+	if len(list) == 0 {
+		fmt.Fprintln(Stdout, i18n.G("No kept configurations found."))
+		return nil
+	}
+
+	w := tabWriter()
+	defer w.Flush()
+
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+		"Snap",
+		// TRANSLATORS: 'Age' as in how old something is
+		i18n.G("Age"),
+		// TRANSLATORS: 'Rev' is an abbreviation of 'Revision'
+		i18n.G("Rev"),
+		// TRANSLATORS: 'Keys' as in the configuration options of a snap
+		i18n.G("Keys"))
+	for _, kc := range list {
+		keys := "-"
+		if len(kc.Keys) > 0 {
+			keys = strings.Join(kc.Keys, ",")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", kc.Snap, x.fmtDuration(kc.Time), kc.Revision, keys)
+	}
+	return nil
+}
+
 type saveCmd struct {
 	waitMixin
 	durationMixin
@@ -246,6 +295,7 @@ func (x *forgetCmd) Execute([]string) error {
 type checkSnapshotCmd struct {
 	waitMixin
 	Users      string `long:"users"`
+	Deep       bool   `long:"deep"`
 	Positional struct {
 		ID    snapshotID          `positional-arg-name:"<id>"`
 		Snaps []installedSnapName `positional-arg-name:"<snap>"`
@@ -259,15 +309,22 @@ func (x *checkSnapshotCmd) Execute([]string) error {
 	}
 	snaps := installedSnapNames(x.Positional.Snaps)
 	users := strutil.CommaSeparatedList(x.Users)
-	changeID, err := x.client.CheckSnapshots(setID, snaps, users)
+	checkSnapshots := x.client.CheckSnapshots
+	if x.Deep {
+		checkSnapshots = x.client.DeepCheckSnapshots
+	}
+	changeID, err := checkSnapshots(setID, snaps, users)
 	if err != nil {
 		return err
 	}
-	_, err = x.wait(changeID)
+	chg, err := x.wait(changeID)
 	if err == noWait {
 		return nil
 	}
 	if err != nil {
+		if x.Deep && chg != nil {
+			showSnapshotCorruptions(chg)
+		}
 		return err
 	}
 
@@ -282,6 +339,29 @@ func (x *checkSnapshotCmd) Execute([]string) error {
 	return nil
 }
 
+// showSnapshotCorruptions lists the corrupted archives found by a deep
+// check, if any.
+func showSnapshotCorruptions(chg *client.Change) {
+	var corruptions []client.SnapshotCorruption
+	if err := chg.Get("snapshot-corruptions", &corruptions); err != nil || len(corruptions) == 0 {
+		return
+	}
+
+	w := tabWriter()
+	defer w.Flush()
+
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+		// TRANSLATORS: 'Set' as in group or bag of things
+		i18n.G("Set"),
+		"Snap",
+		// TRANSLATORS: 'Archive' as in a file with data in a snapshot
+		i18n.G("Archive"),
+		i18n.G("Problem"))
+	for _, corruption := range corruptions {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", corruption.SetID, corruption.Snap, corruption.Entry, corruption.Reason)
+	}
+}
+
 type restoreCmd struct {
 	waitMixin
 	Users      string `long:"users"`
@@ -331,6 +411,8 @@ func init() {
 		durationDescs.also(map[string]string{
 			// TRANSLATORS: This should not start with a lowercase letter.
 			"id": i18n.G("Show only a specific snapshot."),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"configs": i18n.G("Show the configurations kept for removed snaps instead."),
 		}),
 		nil)
 
@@ -389,6 +471,8 @@ func init() {
 		}, waitDescs.also(map[string]string{
 			// TRANSLATORS: This should not start with a lowercase letter.
 			"users": i18n.G("Check data of only specific users (comma-separated) (default: all users)"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"deep": i18n.G("Check all archives in full and list the corrupted ones"),
 		}), []argDesc{
 			{
 				name: "<id>",
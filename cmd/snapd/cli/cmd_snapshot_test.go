@@ -20,6 +20,7 @@
 package cli_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
@@ -47,6 +48,12 @@ var snapshotsTests = []getCmdArgs{{
 }, {
 	args:   "saved",
 	stdout: "Set  Snap  Age    Version  Rev   Size    Notes\n1    htop  .*  2        1168      1B  -\n",
+}, {
+	args:   "saved --configs",
+	stdout: "Snap  Age    Rev   Keys\nhtop  .*  1168  color,theme\n",
+}, {
+	args:  "saved --configs --id=3",
+	error: `cannot use --id with --configs`,
 }, {
 	args:  "forget x",
 	error: `invalid argument for snapshot set id: expected a non-negative integer argument \(see 'snap help saved'\)`,
@@ -68,6 +75,9 @@ var snapshotsTests = []getCmdArgs{{
 }, {
 	args:   "check-snapshot 4 snap1 snap2",
 	stdout: "Snapshot #4 of snaps \"snap1\", \"snap2\" verified successfully.\n",
+}, {
+	args:   "check-snapshot --deep 4",
+	stdout: "Snapshot #4 verified successfully.\n",
 }, {
 	args:  "export-snapshot x snapshot-export.snapshot",
 	error: `invalid argument for snapshot set id: expected a non-negative integer argument \(see 'snap help saved'\)`,
@@ -113,6 +123,46 @@ func (s *SnapSuite) TestSnapshotExportHappy(c *C) {
 	c.Check(exportedSnapshotPath+".part", testutil.FileAbsent)
 }
 
+func (s *SnapSuite) TestCheckSnapshotDeepCorrupted(c *C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		n++
+		switch n {
+		case 1:
+			c.Check(r.Method, Equals, "POST")
+			c.Check(r.URL.Path, Equals, "/v2/snapshots")
+			var action map[string]any
+			c.Check(json.NewDecoder(r.Body).Decode(&action), IsNil)
+			c.Check(action, DeepEquals, map[string]any{
+				"set":    4.,
+				"action": "check",
+				"snaps":  []any{"foo", "bar"},
+				"deep":   true,
+			})
+			w.WriteHeader(202)
+			fmt.Fprintln(w, `{"type":"async", "status-code": 202, "change": "9"}`)
+		case 2:
+			c.Check(r.Method, Equals, "GET")
+			c.Check(r.URL.Path, Equals, "/v2/changes/9")
+			fmt.Fprintln(w, `{"type": "sync", "result": {"ready": true, "status": "Error", "err": "cannot perform the following tasks:\n- Check data of snap \"foo\" in snapshot set #4 (snapshot of snap \"foo\" in set #4 has 2 corrupted archives)", "data": {"snap-names": ["foo", "bar"], "snapshot-corruptions": [
+{"set": 4, "snap": "foo", "entry": "archive.tgz", "reason": "cannot read snapshot entry \"archive.tgz\": unexpected EOF"},
+{"set": 4, "snap": "foo", "entry": "user/bob.tgz", "reason": "snapshot entry \"user/bob.tgz\" expected hash (0000000…) does not match actual (1234567…)"}
+]}}}`)
+		default:
+			c.Fatalf("expected to get 2 requests, now on %d", n)
+		}
+	})
+
+	_, err := main.Parser(main.Client()).ParseArgs([]string{"check-snapshot", "--deep", "4", "foo", "bar"})
+	c.Assert(err, ErrorMatches, `(?s)cannot perform the following tasks:.*has 2 corrupted archives.*`)
+	c.Check(s.Stdout(), Equals, `Set  Snap  Archive       Problem
+4    foo   archive.tgz   cannot read snapshot entry "archive.tgz": unexpected EOF
+4    foo   user/bob.tgz  snapshot entry "user/bob.tgz" expected hash (0000000…) does not match actual (1234567…)
+`)
+	c.Check(s.Stderr(), Equals, "")
+	c.Check(n, Equals, 2)
+}
+
 func (s *SnapSuite) mockSnapshotsServer(c *C) {
 	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
@@ -120,6 +170,10 @@ func (s *SnapSuite) mockSnapshotsServer(c *C) {
 			if r.Method == "GET" {
 				// simulate a 1-month old snapshot
 				snapshotTime := time.Now().AddDate(0, -1, 0).Format(time.RFC3339)
+				if r.URL.Query().Get("kept-configs") == "true" {
+					fmt.Fprintf(w, `{"type":"sync","status-code":200,"status":"OK","result":[{"snap":"htop","revision":"1168","time":%q,"keys":["color","theme"]}]}`, snapshotTime)
+					return
+				}
 				if r.URL.Query().Get("set") == "3" {
 					fmt.Fprintf(w, `{"type":"sync","status-code":200,"status":"OK","result":[{"id":3,"snapshots":[{"set":3,"time":%q,"snap":"htop","revision":"1168","snap-id":"Z","auto":true,"epoch":{"read":[0],"write":[0]},"summary":"","version":"2","sha3-384":{"archive.tgz":""},"size":1}]}]}`, snapshotTime)
 					return
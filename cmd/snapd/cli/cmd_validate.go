@@ -37,6 +37,7 @@ type cmdValidate struct {
 	Enforce    bool `long:"enforce"`
 	Forget     bool `long:"forget"`
 	Refresh    bool `long:"refresh"`
+	MonitorAll bool `long:"monitor-all"`
 	Positional struct {
 		ValidationSet string `positional-arg-name:"<validation-set>"`
 	} `positional-args:"yes"`
@@ -53,6 +54,10 @@ fixed revisions.
 A validation set can either be in monitoring mode, in which case its constraints
 aren't enforced, or in enforcing mode, in which case snapd will not allow
 operations which would result in snaps breaking the validation set's constraints.
+
+With --monitor-all, the installed snaps are checked against all the tracked
+validation sets together, and the snaps breaking their constraints are listed
+along with what would fix them.
 `)
 
 func init() {
@@ -65,6 +70,8 @@ func init() {
 		"forget": i18n.G("Forget the given validation set"),
 		// TRANSLATORS: This should not start with a lowercase letter.
 		"refresh": i18n.G("Refresh or install snaps to satisfy enforced validation sets"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"monitor-all": i18n.G("Show the snaps breaking any tracked validation set and how to fix them"),
 	})), []argDesc{{
 		// TRANSLATORS: This needs to begin with < and end with >
 		name: i18n.G("<validation-set>"),
@@ -106,6 +113,16 @@ func (cmd *cmdValidate) Execute(args []string) error {
 		}
 	}
 
+	if cmd.MonitorAll {
+		if action != "" || cmd.Refresh {
+			return fmt.Errorf("cannot use --monitor-all with other options")
+		}
+		if cmd.Positional.ValidationSet != "" {
+			return fmt.Errorf("cannot use --monitor-all with a validation set")
+		}
+		return cmd.showStatus()
+	}
+
 	if cmd.Refresh && !cmd.Enforce {
 		return fmt.Errorf("--refresh can only be used together with --enforce")
 	}
@@ -216,3 +233,56 @@ func (cmd *cmdValidate) Execute(args []string) error {
 
 	return nil
 }
+
+func fmtValidationSetSnapFix(sn *client.ValidationSetSnapStatus) string {
+	switch {
+	case sn.Fix == "":
+		return "-"
+	case sn.RequiredRevision.Unset():
+		return sn.Fix
+	default:
+		// TRANSLATORS: the first %s is the fix, e.g. refresh, the second one a revision
+		return fmt.Sprintf(i18n.G("%s to revision %s"), sn.Fix, sn.RequiredRevision)
+	}
+}
+
+func (cmd *cmdValidate) showStatus() error {
+	status, err := cmd.client.ValidationSetsStatus()
+	if err != nil {
+		return err
+	}
+	if len(status.ValidationSets) == 0 {
+		fmt.Fprintln(Stderr, i18n.G("No validations are available"))
+		return nil
+	}
+
+	w := tabWriter()
+	fmt.Fprintln(w, i18n.G("Validation\tMode\tSeq\tCurrent"))
+	for _, res := range status.ValidationSets {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", fmtValidationSet(res), res.Mode, res.Sequence, fmtValid(res))
+	}
+	w.Flush()
+
+	if status.Conflict != "" {
+		fmt.Fprintf(Stdout, "\n%s\n", status.Conflict)
+	}
+	if len(status.Snaps) == 0 {
+		if status.Conflict == "" {
+			fmt.Fprintln(Stdout, i18n.G("\nAll snaps satisfy the validation sets."))
+		}
+		return nil
+	}
+
+	fmt.Fprintln(Stdout)
+	w = tabWriter()
+	fmt.Fprintln(w, i18n.G("Snap\tProblem\tRev\tFix\tValidation sets"))
+	for _, sn := range status.Snaps {
+		rev := "-"
+		if !sn.Revision.Unset() {
+			rev = sn.Revision.String()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", sn.Name, sn.Problem, rev, fmtValidationSetSnapFix(sn), strings.Join(sn.ValidationSets, ","))
+	}
+	w.Flush()
+	return nil
+}
@@ -303,3 +303,100 @@ func (s *validateSuite) TestValidationSetsRefreshEnforceNoUnmetConstraints(c *ch
 	c.Check(s.Stderr(), check.Equals, "")
 	c.Check(s.Stdout(), check.Equals, "Enforced validation set \"foo/bar\"\n")
 }
+
+func makeFakeValidationSetsStatusHandler(c *check.C, body string) func(w http.ResponseWriter, r *http.Request) {
+	var called bool
+	return func(w http.ResponseWriter, r *http.Request) {
+		if called {
+			c.Fatalf("expected a single request")
+		}
+		called = true
+		c.Check(r.URL.Path, check.Equals, "/v2/validation-sets/status")
+		c.Check(r.Method, check.Equals, "GET")
+		w.WriteHeader(200)
+		fmt.Fprintln(w, body)
+	}
+}
+
+func (s *validateSuite) TestValidateMonitorAll(c *check.C) {
+	s.RedirectClientToTestServer(makeFakeValidationSetsStatusHandler(c, `{"type": "sync", "status-code": 200, "result": {
+		"validation-sets": [
+			{"account-id":"foo","name":"bar","mode":"monitor","sequence":3,"valid":false},
+			{"account-id":"foo","name":"baz","mode":"enforce","sequence":1,"valid":true}
+		],
+		"snaps": [
+			{"name":"some-snap","problem":"wrong-revision","revision":"2","required-revision":"3","validation-sets":["foo/bar=3"],"fix":"refresh"},
+			{"name":"other-snap","problem":"missing","validation-sets":["foo/bar=3","foo/baz=1"],"fix":"install"},
+			{"name":"bad-snap","problem":"invalid","revision":"7","validation-sets":["foo/bar=3"],"fix":"remove"}
+		]}}`))
+
+	rest, err := main.Parser(main.Client()).ParseArgs([]string{"validate", "--monitor-all"})
+	c.Assert(err, check.IsNil)
+	c.Check(rest, check.HasLen, 0)
+	c.Check(s.Stderr(), check.Equals, "")
+	c.Check(s.Stdout(), check.Equals, `Validation  Mode     Seq  Current
+foo/bar     monitor  3    invalid
+foo/baz     enforce  1    valid
+
+Snap        Problem         Rev  Fix                    Validation sets
+some-snap   wrong-revision  2    refresh to revision 3  foo/bar=3
+other-snap  missing         -    install                foo/bar=3,foo/baz=1
+bad-snap    invalid         7    remove                 foo/bar=3
+`)
+}
+
+func (s *validateSuite) TestValidateMonitorAllValid(c *check.C) {
+	s.RedirectClientToTestServer(makeFakeValidationSetsStatusHandler(c, `{"type": "sync", "status-code": 200, "result": {
+		"validation-sets": [
+			{"account-id":"foo","name":"bar","mode":"enforce","sequence":3,"valid":true}
+		]}}`))
+
+	_, err := main.Parser(main.Client()).ParseArgs([]string{"validate", "--monitor-all"})
+	c.Assert(err, check.IsNil)
+	c.Check(s.Stderr(), check.Equals, "")
+	c.Check(s.Stdout(), check.Equals, `Validation  Mode     Seq  Current
+foo/bar     enforce  3    valid
+
+All snaps satisfy the validation sets.
+`)
+}
+
+func (s *validateSuite) TestValidateMonitorAllConflict(c *check.C) {
+	s.RedirectClientToTestServer(makeFakeValidationSetsStatusHandler(c, `{"type": "sync", "status-code": 200, "result": {
+		"validation-sets": [
+			{"account-id":"foo","name":"bar","mode":"monitor","sequence":3,"valid":false},
+			{"account-id":"foo","name":"baz","mode":"monitor","sequence":1,"valid":false}
+		],
+		"conflict": "validation sets are in conflict:\n- cannot constrain snap \"some-snap\" as both invalid (foo/bar) and required at revision 3 (foo/baz)",
+		"snaps": [
+			{"name":"some-snap","problem":"wrong-revision","revision":"2","validation-sets":["foo/bar=3","foo/baz=1"]}
+		]}}`))
+
+	_, err := main.Parser(main.Client()).ParseArgs([]string{"validate", "--monitor-all"})
+	c.Assert(err, check.IsNil)
+	c.Check(s.Stderr(), check.Equals, "")
+	c.Check(s.Stdout(), check.Equals, `Validation  Mode     Seq  Current
+foo/bar     monitor  3    invalid
+foo/baz     monitor  1    invalid
+
+validation sets are in conflict:
+- cannot constrain snap "some-snap" as both invalid (foo/bar) and required at revision 3 (foo/baz)
+
+Snap       Problem         Rev  Fix  Validation sets
+some-snap  wrong-revision  2    -    foo/bar=3,foo/baz=1
+`)
+}
+
+func (s *validateSuite) TestValidateMonitorAllInvalidArgs(c *check.C) {
+	for _, args := range []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"--monitor-all", "foo/bar"}, `cannot use --monitor-all with a validation set`},
+		{[]string{"--monitor-all", "--monitor", "foo/bar"}, `cannot use --monitor-all with other options`},
+		{[]string{"--monitor-all", "--enforce", "--refresh", "foo/bar"}, `cannot use --monitor-all with other options`},
+	} {
+		_, err := main.Parser(main.Client()).ParseArgs(append([]string{"validate"}, args.args...))
+		c.Check(err, check.ErrorMatches, args.err)
+	}
+}
@@ -39,6 +39,7 @@ type cmdWarnings struct {
 	clientMixin
 	timeMixin
 	unicodeMixin
+	structuredOutputMixin
 	All     bool `long:"all"`
 	Verbose bool `long:"verbose"`
 }
@@ -74,6 +75,39 @@ func init() {
 	addCommand("okay", shortOkayHelp, longOkayHelp, func() flags.Commander { return &cmdOkay{} }, nil, nil)
 }
 
+// warningOutput is the structured output of the warnings command for each
+// warning.
+type warningOutput struct {
+	Message         string     `json:"message" yaml:"message"`
+	FirstOccurrence time.Time  `json:"first-occurrence" yaml:"first-occurrence"`
+	LastOccurrence  time.Time  `json:"last-occurrence" yaml:"last-occurrence"`
+	Acknowledged    *time.Time `json:"acknowledged,omitempty" yaml:"acknowledged,omitempty"`
+	RepeatsAfter    string     `json:"repeats-after,omitempty" yaml:"repeats-after,omitempty"`
+	ExpiresAfter    string     `json:"expires-after,omitempty" yaml:"expires-after,omitempty"`
+}
+
+func durationOutput(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return d.String()
+}
+
+func (cmd *cmdWarnings) writeStructured(warnings []*client.Warning) error {
+	out := make([]warningOutput, 0, len(warnings))
+	for _, warning := range warnings {
+		out = append(out, warningOutput{
+			Message:         warning.Message,
+			FirstOccurrence: warning.FirstAdded,
+			LastOccurrence:  warning.LastAdded,
+			Acknowledged:    maybeTime(warning.LastShown),
+			RepeatsAfter:    durationOutput(warning.RepeatAfter),
+			ExpiresAfter:    durationOutput(warning.ExpireAfter),
+		})
+	}
+	return cmd.writeStructuredOutput(out)
+}
+
 func (cmd *cmdWarnings) Execute(args []string) error {
 	if len(args) > 0 {
 		return ErrExtraArgs
@@ -84,6 +118,9 @@ func (cmd *cmdWarnings) Execute(args []string) error {
 	if err != nil {
 		return err
 	}
+	if len(warnings) == 0 && cmd.structuredOutput() {
+		return cmd.writeStructured(nil)
+	}
 	if len(warnings) == 0 {
 		if t, _ := lastWarningTimestamp(); t.IsZero() {
 			fmt.Fprintln(Stdout, i18n.G("No warnings."))
@@ -97,6 +134,10 @@ func (cmd *cmdWarnings) Execute(args []string) error {
 		return err
 	}
 
+	if cmd.structuredOutput() {
+		return cmd.writeStructured(warnings)
+	}
+
 	termWidth, _ := termSize()
 	if termWidth > 100 {
 		// any wider than this and it gets hard to read
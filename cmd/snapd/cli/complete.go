@@ -21,11 +21,15 @@ package cli
 
 import (
 	"bufio"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jessevdk/go-flags"
 
@@ -33,7 +37,10 @@ import (
 	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/channel"
+	"github.com/snapcore/snapd/strutil"
 )
 
 // SnapAndApp holds a snap name and an application name
@@ -80,7 +87,7 @@ func (s SnapAndApp) Complete(match string) []flags.Completion {
 	matchSnap.UnmarshalFlag(match)
 	if !matchSnap.hasDot {
 		// No dot in match, so complete with snap names
-		installedSnaps, err := mkClient().List(nil, nil)
+		installedSnaps, err := installedSnapsForCompletion()
 		if err != nil {
 			return nil
 		}
@@ -128,7 +135,7 @@ func (s SnapAndApp) Complete(match string) []flags.Completion {
 type installedSnapName string
 
 func (s installedSnapName) Complete(match string) []flags.Completion {
-	snaps, err := mkClient().List(nil, nil)
+	snaps, err := installedSnapsForCompletion()
 	if err != nil {
 		return nil
 	}
@@ -185,6 +192,54 @@ func completeFromSortedFile(filename, match string) ([]flags.Completion, error)
 	return ret, nil
 }
 
+// completionCacheTTL is for how long the answers of snapd to the queries
+// done for completing arguments are reused. Completion runs a new snap
+// process for every request of the shell, so this keeps completion fast
+// when the user presses tab repeatedly.
+var completionCacheTTL = 10 * time.Second
+
+// completionCacheDir returns the directory where the answers of snapd
+// used for completion are cached, or "" if they cannot be cached.
+var completionCacheDir = func() string {
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir == "" {
+		return ""
+	}
+	return filepath.Join(runtimeDir, "snap-completion")
+}
+
+// cachedForCompletion returns the result of fetch, reusing the one cached
+// under the given key if it is recent enough. Caching is best effort, any
+// problem with the cache results in fetch being called.
+func cachedForCompletion[T any](key string, fetch func() (T, error)) (T, error) {
+	dir := completionCacheDir()
+	if dir == "" {
+		return fetch()
+	}
+	fn := filepath.Join(dir, url.PathEscape(key)+".json")
+	if st, err := os.Stat(fn); err == nil && timeNow().Sub(st.ModTime()) < completionCacheTTL {
+		var cached T
+		if data, err := os.ReadFile(fn); err == nil && json.Unmarshal(data, &cached) == nil {
+			return cached, nil
+		}
+	}
+
+	v, err := fetch()
+	if err != nil {
+		return v, err
+	}
+	if data, err := json.Marshal(v); err == nil && os.MkdirAll(dir, 0700) == nil {
+		osutil.AtomicWriteFile(fn, data, 0600, 0)
+	}
+	return v, nil
+}
+
+func installedSnapsForCompletion() ([]*client.Snap, error) {
+	return cachedForCompletion("snaps", func() ([]*client.Snap, error) {
+		return mkClient().List(nil, nil)
+	})
+}
+
 type remoteSnapName string
 
 func (s remoteSnapName) Complete(match string) []flags.Completion {
@@ -195,9 +250,12 @@ func (s remoteSnapName) Complete(match string) []flags.Completion {
 	if len(match) < 3 {
 		return nil
 	}
-	snaps, _, err := mkClient().Find(&client.FindOptions{
-		Query:  match,
-		Prefix: true,
+	snaps, err := cachedForCompletion("find-"+match, func() ([]*client.Snap, error) {
+		snaps, _, err := mkClient().Find(&client.FindOptions{
+			Query:  match,
+			Prefix: true,
+		})
+		return snaps, err
 	})
 	if err != nil {
 		return nil
@@ -236,6 +294,40 @@ func (s anySnapName) Complete(match string) []flags.Completion {
 	return res
 }
 
+// channelName is a channel given with --channel.
+type channelName string
+
+func (s channelName) Complete(match string) []flags.Completion {
+	// the snap the channel is for is not known here, offer the tracks
+	// that installed snaps are tracking
+	tracks := []string{"latest"}
+	if snaps, err := installedSnapsForCompletion(); err == nil {
+		for _, sn := range snaps {
+			ch, err := channel.Parse(sn.TrackingChannel, "")
+			if err != nil || ch.Track == "" || strutil.ListContains(tracks, ch.Track) {
+				continue
+			}
+			tracks = append(tracks, ch.Track)
+		}
+	}
+
+	var ret []flags.Completion
+	for _, risk := range channelRisks {
+		if strings.HasPrefix(risk, match) {
+			ret = append(ret, flags.Completion{Item: risk})
+		}
+	}
+	for _, track := range tracks {
+		for _, risk := range channelRisks {
+			name := track + "/" + risk
+			if strings.HasPrefix(name, match) {
+				ret = append(ret, flags.Completion{Item: name})
+			}
+		}
+	}
+	return ret
+}
+
 type changeID string
 
 func (s changeID) Complete(match string) []flags.Completion {
@@ -397,7 +489,9 @@ func (spec *interfaceSpec) Complete(match string) []flags.Completion {
 	opts := client.ConnectionOptions{
 		All: true,
 	}
-	ifaces, err := mkClient().Connections(&opts)
+	ifaces, err := cachedForCompletion("connections", func() (client.Connections, error) {
+		return mkClient().Connections(&opts)
+	})
 	if err != nil {
 		return nil
 	}
@@ -488,7 +582,9 @@ func (spec *interfaceSpec) Complete(match string) []flags.Completion {
 type interfaceName string
 
 func (s interfaceName) Complete(match string) []flags.Completion {
-	ifaces, err := mkClient().Interfaces(nil)
+	ifaces, err := cachedForCompletion("interfaces", func() ([]*client.Interface, error) {
+		return mkClient().Interfaces(nil)
+	})
 	if err != nil {
 		return nil
 	}
@@ -0,0 +1,110 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli_test
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jessevdk/go-flags"
+	"gopkg.in/check.v1"
+
+	snap "github.com/snapcore/snapd/cmd/snapd/cli"
+	"github.com/snapcore/snapd/testutil"
+)
+
+func (s *SnapSuite) mockSnapsForCompletion(c *check.C) *int {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, check.Equals, "GET")
+		c.Check(r.URL.Path, check.Equals, "/v2/snaps")
+		fmt.Fprintln(w, `{"type": "sync", "result": [
+{"name": "foo", "status": "active", "version": "1", "revision": 1, "tracking-channel": "latest/edge"},
+{"name": "bar", "status": "active", "version": "1", "revision": 1, "tracking-channel": "2.0/stable"},
+{"name": "baz", "status": "active", "version": "1", "revision": 1, "tracking-channel": "1.0/beta/fix"}
+]}`)
+		n++
+	})
+	return &n
+}
+
+func (s *SnapSuite) completeArgs(c *check.C, args ...string) []flags.Completion {
+	os.Setenv("GO_FLAGS_COMPLETION", "verbose")
+	defer os.Unsetenv("GO_FLAGS_COMPLETION")
+
+	var obtained []flags.Completion
+	parser := snap.Parser(snap.Client())
+	parser.CompletionHandler = func(comps []flags.Completion) {
+		obtained = comps
+	}
+	_, err := parser.ParseArgs(args)
+	c.Assert(err, check.IsNil)
+	return obtained
+}
+
+func (s *SnapSuite) TestCompleteChannel(c *check.C) {
+	s.mockSnapsForCompletion(c)
+
+	c.Check(s.completeArgs(c, "refresh", "--channel", "e"), check.DeepEquals, []flags.Completion{
+		{Item: "edge"},
+	})
+	c.Check(s.completeArgs(c, "install", "--channel", "1"), check.DeepEquals, []flags.Completion{
+		{Item: "1.0/beta"}, {Item: "1.0/candidate"}, {Item: "1.0/edge"}, {Item: "1.0/stable"},
+	})
+	c.Check(s.completeArgs(c, "download", "--channel", "latest/"), check.DeepEquals, []flags.Completion{
+		{Item: "latest/beta"}, {Item: "latest/candidate"}, {Item: "latest/edge"}, {Item: "latest/stable"},
+	})
+	c.Check(s.completeArgs(c, "switch", "--channel", "2.0/c"), check.DeepEquals, []flags.Completion{
+		{Item: "2.0/candidate"},
+	})
+}
+
+func (s *SnapSuite) TestCompleteCachesSnapdAnswers(c *check.C) {
+	cacheDir := filepath.Join(c.MkDir(), "snap-completion")
+	s.AddCleanup(snap.MockCompletionCacheDir(cacheDir))
+	n := s.mockSnapsForCompletion(c)
+
+	expected := []flags.Completion{{Item: "bar"}, {Item: "baz"}}
+	c.Check(s.completeArgs(c, "remove", "ba"), check.DeepEquals, expected)
+	c.Check(*n, check.Equals, 1)
+	c.Check(filepath.Join(cacheDir, "snaps.json"), testutil.FilePresent)
+
+	// the cached answer is reused while it is fresh
+	c.Check(s.completeArgs(c, "remove", "ba"), check.DeepEquals, expected)
+	c.Check(*n, check.Equals, 1)
+
+	// and snapd is asked again after that
+	s.AddCleanup(snap.MockTimeNow(func() time.Time {
+		return time.Now().Add(time.Minute)
+	}))
+	c.Check(s.completeArgs(c, "remove", "ba"), check.DeepEquals, expected)
+	c.Check(*n, check.Equals, 2)
+}
+
+func (s *SnapSuite) TestCompleteWithoutCacheDir(c *check.C) {
+	n := s.mockSnapsForCompletion(c)
+
+	c.Check(s.completeArgs(c, "remove", "f"), check.DeepEquals, []flags.Completion{{Item: "foo"}})
+	c.Check(s.completeArgs(c, "remove", "f"), check.DeepEquals, []flags.Completion{{Item: "foo"}})
+	c.Check(*n, check.Equals, 2)
+}
@@ -156,6 +156,7 @@ var (
 	MaybePrintCohortKey         = (*infoWriter).maybePrintCohortKey
 	MaybePrintHealth            = (*infoWriter).maybePrintHealth
 	MaybePrintRefreshInfo       = (*infoWriter).maybePrintRefreshInfo
+	MaybePrintTrackingChannel   = (*infoWriter).maybePrintTrackingChannel
 	WaitWhileInhibited          = waitWhileInhibited
 	NewInhibitionFlow           = newInhibitionFlow
 	ErrSnapRefreshConflict      = errSnapRefreshConflict
@@ -185,6 +186,20 @@ func MockSyscallExec(f func(string, []string, []string) error) (restore func())
 	}
 }
 
+var DisplayStartupStages = displayStartupStages
+
+func StartupTraceEntries(traceID string) ([]map[string]string, error) {
+	return startupTraceEntries(traceID)
+}
+
+func MockStartupTraceEntries(f func(traceID string) ([]map[string]string, error)) (restore func()) {
+	old := startupTraceEntries
+	startupTraceEntries = f
+	return func() {
+		startupTraceEntries = old
+	}
+}
+
 func MockUserCurrent(f func() (*user.User, error)) (restore func()) {
 	userCurrentOrig := userCurrent
 	userCurrent = f
@@ -466,7 +481,7 @@ func MockAutostartSessionApps(f func(string) error) func() {
 	}
 }
 
-func ParseQuotaValues(maxMemory, cpuMax, cpuSet, threadsMax, journalSizeMax, journalRateLimit string) (*client.QuotaValues, error) {
+func ParseQuotaValues(maxMemory, cpuMax, cpuSet, threadsMax, journalSizeMax, journalRateLimit, journalForward string) (*client.QuotaValues, error) {
 	var quotas cmdSetQuota
 
 	quotas.MemoryMax = maxMemory
@@ -475,6 +490,7 @@ func ParseQuotaValues(maxMemory, cpuMax, cpuSet, threadsMax, journalSizeMax, jou
 	quotas.ThreadsMax = threadsMax
 	quotas.JournalSizeMax = journalSizeMax
 	quotas.JournalRateLimit = journalRateLimit
+	quotas.JournalForward = journalForward
 
 	return quotas.parseQuotas()
 }
@@ -504,3 +520,25 @@ func MockSquashfsGenerateDelta(f func(context.Context, string, string, string, s
 func MockSquashfsApplyDelta(f func(context.Context, string, string, string) error) (restore func()) {
 	return testutil.Mock(&squashfsApplyDelta, f)
 }
+
+func MockCompletionCacheDir(dir string) (restore func()) {
+	return testutil.Mock(&completionCacheDir, func() string { return dir })
+}
+
+func MockResolveSyscall(f func(arch, nr string) (string, error)) (restore func()) {
+	old := resolveSyscall
+	resolveSyscall = f
+	return func() {
+		resolveSyscall = old
+	}
+}
+
+func MockProcDir(dir string) (restore func()) {
+	return testutil.Mock(&procDir, dir)
+}
+
+var LoadSnapdSnapCatalogs = loadSnapdSnapCatalogs
+
+func MockI18nLoadCatalogOverrides(f func(dir string) error) (restore func()) {
+	return testutil.Mock(&i18nLoadCatalogOverrides, f)
+}
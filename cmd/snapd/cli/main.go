@@ -67,6 +67,7 @@ var (
 
 type options struct {
 	Version func() `long:"version"`
+	Output  string `long:"output" choice:"json" choice:"yaml"`
 }
 
 type argDesc struct {
@@ -102,6 +103,9 @@ var debugCommands []*cmdInfo
 // routineCommands holds information about all internal commands.
 var routineCommands []*cmdInfo
 
+// clusterCommands holds information about all "snap cluster" commands.
+var clusterCommands []*cmdInfo
+
 // addCommand replaces parser.addCommand() in a way that is compatible with
 // re-constructing a pristine parser.
 func addCommand(name, shortHelp, longHelp string, builder func() flags.Commander, optDescs map[string]string, argDescs []argDesc) *cmdInfo {
@@ -149,6 +153,22 @@ func addRoutineCommand(name, shortHelp, longHelp string, builder func() flags.Co
 	return info
 }
 
+// addClusterCommand replaces parser.addCommand() in a way that is
+// compatible with re-constructing a pristine parser. It is meant for
+// adding "snap cluster" commands.
+func addClusterCommand(name, shortHelp, longHelp string, builder func() flags.Commander, optDescs map[string]string, argDescs []argDesc) *cmdInfo {
+	info := &cmdInfo{
+		name:      name,
+		shortHelp: shortHelp,
+		longHelp:  longHelp,
+		builder:   builder,
+		optDescs:  optDescs,
+		argDescs:  argDescs,
+	}
+	clusterCommands = append(clusterCommands, info)
+	return info
+}
+
 type parserSetter interface {
 	setParser(*flags.Parser)
 }
@@ -357,10 +377,18 @@ func Parser(cli *client.Client) *flags.Parser {
 		version.Description = i18n.G("Print the version and exit")
 		version.Hidden = true
 	}
+	optionsData.Output = ""
+	if output := parser.FindOptionByLongName("output"); output != nil {
+		output.Description = i18n.G("Print machine-readable output in the given format, for commands that do not modify the system")
+		output.ValueName = "json|yaml"
+		// like --version, it is not listed by the help of every command
+		output.Hidden = true
+	}
+	parser.CommandHandler = executeCommand
 	// add --help like what go-flags would do for us, but hidden
 	addHelp(parser)
 
-	seen := make(map[string]bool, len(commands)+len(debugCommands)+len(routineCommands))
+	seen := make(map[string]bool, len(commands)+len(debugCommands)+len(routineCommands)+len(clusterCommands))
 	checkUnique := func(ci *cmdInfo, kind string) {
 		if seen[ci.shortHelp] && ci.shortHelp != "Internal" && ci.shortHelp != "Deprecated (hidden)" {
 			logger.Panicf(`%scommand %q has an already employed description != "Internal"|"Deprecated (hidden)": %s`, kind, ci.name, ci.shortHelp)
@@ -391,6 +419,15 @@ func Parser(cli *client.Client) *flags.Parser {
 	registerCommands(cli, parser, routineCommand, routineCommands, func(ci *cmdInfo) {
 		checkUnique(ci, "routine ")
 	})
+	// Add the cluster command
+	clusterCommand, err := parser.AddCommand("cluster", shortClusterHelp, longClusterHelp, &cmdCluster{})
+	if err != nil {
+		logger.Panicf("cannot add command %q: %v", "cluster", err)
+	}
+	// Add all the sub-commands of the cluster command
+	registerCommands(cli, parser, clusterCommand, clusterCommands, func(ci *cmdInfo) {
+		checkUnique(ci, "cluster ")
+	})
 	return parser
 }
 
@@ -477,6 +514,7 @@ func Main() {
 
 	// late initialization, cross package settings etc.
 	lateInit()
+	loadSnapdSnapCatalogs()
 
 	// check for magic symlink to /usr/bin/snap:
 	// 1. symlink from command-not-found to /usr/bin/snap: run c-n-f
@@ -536,6 +574,25 @@ func Main() {
 	}
 }
 
+var i18nLoadCatalogOverrides = i18n.LoadCatalogOverrides
+
+// loadSnapdSnapCatalogs makes the message catalogs shipped with the snapd
+// snap take precedence over the system ones when running from the snap.
+func loadSnapdSnapCatalogs() {
+	exe, err := osReadlink("/proc/self/exe")
+	if err != nil || !strings.HasPrefix(exe, dirs.SnapMountDir) {
+		return
+	}
+	// the snap command is in usr/bin of the snap
+	localeDir := filepath.Join(filepath.Dir(exe), "..", "share", "locale")
+	if !osutil.IsDirectory(localeDir) {
+		return
+	}
+	if err := i18nLoadCatalogOverrides(localeDir); err != nil {
+		logger.Debugf("cannot load message catalogs of the snapd snap: %v", err)
+	}
+}
+
 type exitStatus struct {
 	code int
 }
@@ -611,7 +668,7 @@ func makeCommandHandler(allCommands []*flags.Command) func(flags.Commander, []st
 				break
 			}
 		}
-		return command.Execute(args)
+		return executeCommand(command, args)
 	}
 }
 
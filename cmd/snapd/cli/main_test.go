@@ -103,6 +103,8 @@ func (s *BaseSnapSuite) SetUpTest(c *C) {
 	s.AddCleanup(snap.MockIsStdinTTY(false))
 
 	s.AddCleanup(snap.MockSELinuxIsEnabled(func() (bool, error) { return false, nil }))
+	// do not reuse answers of the mocked snapd across completions
+	s.AddCleanup(snap.MockCompletionCacheDir(""))
 
 	// mock an empty cmdline since we check the cmdline to check whether we are
 	// in install mode or not and we don't want to use the host's proc/cmdline
@@ -529,3 +531,40 @@ func (s *SnapSuite) TestCommandHandlerThreeLevels(c *C) {
 	name := snap.ComposeSubCmd(cmd0, 2, []string{cmd0.Name})
 	c.Assert(name, Equals, "level0 level1 level2")
 }
+
+func (s *SnapSuite) TestLoadSnapdSnapCatalogs(c *C) {
+	localeDir := filepath.Join(dirs.SnapMountDir, "snapd/123/usr/share/locale")
+	c.Assert(os.MkdirAll(localeDir, 0755), IsNil)
+
+	var loaded []string
+	restore := snap.MockI18nLoadCatalogOverrides(func(dir string) error {
+		loaded = append(loaded, dir)
+		return nil
+	})
+	defer restore()
+
+	// not running from a snap
+	restore = snap.MockOsReadlink(func(string) (string, error) {
+		return "/usr/bin/snap", nil
+	})
+	defer restore()
+	snap.LoadSnapdSnapCatalogs()
+	c.Check(loaded, HasLen, 0)
+
+	// running from the snapd snap
+	restore = snap.MockOsReadlink(func(string) (string, error) {
+		return filepath.Join(dirs.SnapMountDir, "snapd/123/usr/bin/snap"), nil
+	})
+	defer restore()
+	snap.LoadSnapdSnapCatalogs()
+	c.Check(loaded, DeepEquals, []string{localeDir})
+
+	// a snap without catalogs
+	loaded = nil
+	restore = snap.MockOsReadlink(func(string) (string, error) {
+		return filepath.Join(dirs.SnapMountDir, "core/456/usr/bin/snap"), nil
+	})
+	defer restore()
+	snap.LoadSnapdSnapCatalogs()
+	c.Check(loaded, HasLen, 0)
+}
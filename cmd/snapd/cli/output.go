@@ -0,0 +1,77 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jessevdk/go-flags"
+	"gopkg.in/yaml.v2"
+
+	"github.com/snapcore/snapd/i18n"
+)
+
+// structuredOutputMixin is embedded by the read-only commands that honour
+// the global --output option, printing machine-readable output instead of
+// the human-readable one. The structures they output are part of the
+// interface of the commands and must be kept stable.
+type structuredOutputMixin struct{}
+
+func (structuredOutputMixin) supportsStructuredOutput() {}
+
+type structuredOutputCommand interface {
+	supportsStructuredOutput()
+}
+
+// structuredOutput returns whether machine-readable output was requested.
+func (structuredOutputMixin) structuredOutput() bool {
+	return optionsData.Output != ""
+}
+
+// writeStructuredOutput writes v to standard output in the format
+// requested with --output.
+func (structuredOutputMixin) writeStructuredOutput(v any) error {
+	switch optionsData.Output {
+	case "json":
+		enc := json.NewEncoder(Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case "yaml":
+		enc := yaml.NewEncoder(Stdout)
+		defer enc.Close()
+		return enc.Encode(v)
+	}
+	return fmt.Errorf("internal error: unsupported output format %q", optionsData.Output)
+}
+
+var errStructuredOutputUnsupported = errors.New(i18n.G("--output is only supported by commands that do not modify the system"))
+
+// executeCommand executes the given command, unless it does not support
+// the output format requested with --output.
+func executeCommand(command flags.Commander, args []string) error {
+	if optionsData.Output != "" {
+		if _, ok := command.(structuredOutputCommand); !ok {
+			return errStructuredOutputUnsupported
+		}
+	}
+	return command.Execute(args)
+}
@@ -0,0 +1,273 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/client"
+	snap "github.com/snapcore/snapd/cmd/snapd/cli"
+)
+
+// The structured outputs checked here are an interface that scripts rely
+// on, changing the expectations must be done with care.
+
+func (s *SnapSuite) mockListForOutput(c *check.C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, check.Equals, "GET")
+		c.Check(r.URL.Path, check.Equals, "/v2/snaps")
+		fmt.Fprintln(w, `{"type": "sync", "result": [
+{
+  "name": "foo",
+  "status": "active",
+  "version": "4.2",
+  "type": "app",
+  "confinement": "strict",
+  "publisher": {"id": "bar-id", "username": "bar", "display-name": "Bar", "validation": "unproven"},
+  "revision": 17,
+  "tracking-channel": "latest/stable"
+},
+{
+  "name": "baz",
+  "status": "installed",
+  "version": "1.0",
+  "type": "app",
+  "confinement": "classic",
+  "revision": "x1",
+  "devmode": true
+}]}`)
+	})
+}
+
+func (s *SnapSuite) TestOutputJSONList(c *check.C) {
+	s.mockListForOutput(c)
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"--output=json", "list"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.DeepEquals, []string{})
+	c.Check(s.Stdout(), check.Equals, `[
+  {
+    "name": "baz",
+    "version": "1.0",
+    "revision": "x1",
+    "type": "app",
+    "confinement": "classic",
+    "disabled": true,
+    "devmode": true
+  },
+  {
+    "name": "foo",
+    "version": "4.2",
+    "revision": "17",
+    "tracking-channel": "latest/stable",
+    "publisher": "bar",
+    "type": "app",
+    "confinement": "strict"
+  }
+]
+`)
+	c.Check(s.Stderr(), check.Equals, "")
+}
+
+func (s *SnapSuite) TestOutputYAMLList(c *check.C) {
+	s.mockListForOutput(c)
+	// the option is global, it can also be given after the command
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"list", "--output=yaml"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.DeepEquals, []string{})
+	c.Check(s.Stdout(), check.Equals, `
+- name: baz
+  version: "1.0"
+  revision: x1
+  type: app
+  confinement: classic
+  disabled: true
+  devmode: true
+- name: foo
+  version: "4.2"
+  revision: "17"
+  tracking-channel: latest/stable
+  publisher: bar
+  type: app
+  confinement: strict
+`[1:])
+	c.Check(s.Stderr(), check.Equals, "")
+}
+
+func (s *SnapSuite) TestOutputJSONListEmpty(c *check.C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"type": "sync", "result": []}`)
+	})
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"--output=json", "list"})
+	c.Assert(err, check.IsNil)
+	c.Check(s.Stdout(), check.Equals, "[]\n")
+	c.Check(s.Stderr(), check.Equals, "")
+}
+
+func (s *SnapSuite) TestOutputJSONConnections(c *check.C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, check.Equals, "/v2/connections")
+		EncodeResponseBody(c, w, map[string]any{
+			"type": "sync",
+			"result": client.Connections{
+				Established: []client.Connection{{
+					Plug:      client.PlugRef{Snap: "keyboard-lights", Name: "capslock-led"},
+					Slot:      client.SlotRef{Snap: "leds-provider", Name: "capslock-led"},
+					Interface: "leds",
+					Manual:    true,
+				}},
+				Plugs: []client.Plug{{
+					Snap:      "keyboard-lights",
+					Name:      "capslock-led",
+					Interface: "leds",
+					Connections: []client.SlotRef{{
+						Snap: "leds-provider",
+						Name: "capslock-led",
+					}},
+				}},
+				Slots: []client.Slot{{
+					Snap:      "leds-provider",
+					Name:      "capslock-led",
+					Interface: "leds",
+					Connections: []client.PlugRef{{
+						Snap: "keyboard-lights",
+						Name: "capslock-led",
+					}},
+				}},
+			},
+		})
+	})
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"--output=json", "connections"})
+	c.Assert(err, check.IsNil)
+	c.Check(s.Stdout(), check.Equals, `[
+  {
+    "interface": "leds",
+    "plug": "keyboard-lights:capslock-led",
+    "slot": "leds-provider:capslock-led",
+    "manual": true
+  }
+]
+`)
+	c.Check(s.Stderr(), check.Equals, "")
+}
+
+func (s *SnapSuite) TestOutputJSONConnectionsNone(c *check.C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		EncodeResponseBody(c, w, map[string]any{
+			"type":   "sync",
+			"result": client.Connections{},
+		})
+	})
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"--output=json", "connections"})
+	c.Assert(err, check.IsNil)
+	c.Check(s.Stdout(), check.Equals, "[]\n")
+	c.Check(s.Stderr(), check.Equals, "")
+}
+
+func (s *SnapSuite) TestOutputJSONAliases(c *check.C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, check.Equals, "/v2/aliases")
+		EncodeResponseBody(c, w, map[string]any{
+			"type": "sync",
+			"result": map[string]map[string]client.AliasStatus{
+				"foo": {
+					"foo0":      {Command: "foo", Status: "auto", Auto: "foo"},
+					"foo_reset": {Command: "foo.reset", Manual: "reset", Status: "manual"},
+				},
+			},
+		})
+	})
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"--output=json", "aliases"})
+	c.Assert(err, check.IsNil)
+	c.Check(s.Stdout(), check.Equals, `[
+  {
+    "snap": "foo",
+    "command": "foo",
+    "alias": "foo0",
+    "status": "auto",
+    "auto": "foo"
+  },
+  {
+    "snap": "foo",
+    "command": "foo.reset",
+    "alias": "foo_reset",
+    "status": "manual"
+  }
+]
+`)
+	c.Check(s.Stderr(), check.Equals, "")
+}
+
+func (s *SnapSuite) TestOutputJSONServices(c *check.C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, check.Equals, "/v2/apps")
+		EncodeResponseBody(c, w, map[string]any{
+			"type": "sync",
+			"result": []map[string]any{
+				{"snap": "foo", "name": "bar", "daemon": "simple", "daemon-scope": "system", "active": true, "enabled": true},
+				{"snap": "foo", "name": "baz", "daemon": "oneshot", "daemon-scope": "user", "enabled": true},
+				{"snap": "foo", "name": "qux", "daemon": "simple", "daemon-scope": "user", "enabled": true, "sessions": 2, "active-sessions": 1},
+			},
+		})
+	})
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"--output=json", "services", "--global"})
+	c.Assert(err, check.IsNil)
+	c.Check(s.Stdout(), check.Equals, `[
+  {
+    "service": "foo.bar",
+    "daemon": "simple",
+    "daemon-scope": "system",
+    "enabled": true,
+    "active": true
+  },
+  {
+    "service": "foo.baz",
+    "daemon": "oneshot",
+    "daemon-scope": "user",
+    "enabled": true
+  },
+  {
+    "service": "foo.qux",
+    "daemon": "simple",
+    "daemon-scope": "user",
+    "enabled": true,
+    "sessions": 2,
+    "active-sessions": 1
+  }
+]
+`)
+	c.Check(s.Stderr(), check.Equals, "")
+}
+
+func (s *SnapSuite) TestOutputUnsupportedCommand(c *check.C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Fatalf("unexpected request to %s", r.URL.Path)
+	})
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"--output=json", "install", "foo"})
+	c.Assert(err, check.ErrorMatches, `--output is only supported by commands that do not modify the system`)
+	c.Check(s.Stdout(), check.Equals, "")
+}
+
+func (s *SnapSuite) TestOutputInvalidFormat(c *check.C) {
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"--output=xml", "list"})
+	c.Assert(err, check.ErrorMatches, `Invalid value .xml. for option .--output.*`)
+}
@@ -20,6 +20,7 @@
 package cli
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -84,11 +85,16 @@ func (wmx mustWaitMixin) wait(id string) (*client.Change, error) {
 
 	tMax := time.Time{}
 
+	// the watcher returns the change as soon as it is updated, or polls
+	// for it every pollTime if the server cannot wait for updates
+	watcher := cli.WatchChange(context.Background(), id)
+	watcher.PollTime = pollTime
+
 	var lastID string
 	lastLog := map[string]string{}
 	for {
 		var rebootingErr error
-		chg, err := cli.Change(id)
+		chg, err := watcher.Next()
 		if err != nil {
 			// a client.Error means we were able to communicate with
 			// the server (got an answer)
@@ -202,11 +208,6 @@ func (wmx mustWaitMixin) wait(id string) (*client.Change, error) {
 		if rebootingErr != nil {
 			return nil, rebootingErr
 		}
-
-		// note this very purposely is not a ticker; we want
-		// to sleep 100ms between calls, not call once every
-		// 100ms.
-		time.Sleep(pollTime)
 	}
 }
 
@@ -42,7 +42,12 @@ snaps (at /var/lib/snapd/seed), and runs through the snapd first-boot process
 up to hook execution. No boot actions unrelated to snapd are performed.
 It creates systemd units for seeded snaps, makes any connections, and generates
 security profiles. The image is updated and consequently optimised to reduce
-first-boot startup time`
+first-boot startup time.
+
+Images of another architecture than the host can be preseeded when the
+"qemu-user-static" package is installed and its binfmt_misc handlers are
+registered. The AppArmor policy cache of such images is not preseeded but
+compiled on first boot.`
 )
 
 // options holds the command line options for snap-preseed.
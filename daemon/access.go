@@ -212,12 +212,22 @@ func (ac authenticatedAccess) CheckAccess(d *Daemon, r *http.Request, ucred *ucr
 
 // rootAccess allows requests from the root uid, provided they
 // were not received on snapd-snap.socket
-type rootAccess struct{}
+//
+// Non-root users can be granted access by Polkit if an action is set.
+type rootAccess struct {
+	// Polkit is an optional polkit action to check as fallback
+	// if the user is not root.
+	//
+	// Note: The specified polkit action must require auth_admin
+	// to avoid compromising security.
+	Polkit string
+}
 
 func (ac rootAccess) CheckAccess(d *Daemon, r *http.Request, ucred *ucrednet, user *auth.UserState) *apiError {
 	opts := accessOptions{
-		AccessLevel: accessLevelRoot,
-		Sockets:     []string{dirs.SnapdSocket},
+		AccessLevel:  accessLevelRoot,
+		Sockets:      []string{dirs.SnapdSocket},
+		PolkitAction: ac.Polkit,
 	}
 	return checkAccess(d, r, ucred, user, opts)
 }
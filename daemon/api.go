@@ -20,6 +20,7 @@
 package daemon
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -59,10 +60,12 @@ var api = []*Command{
 	buyCmd,
 	readyToBuyCmd,
 	snapctlCmd,
+	sbomCmd,
 	usersCmd,
 	sectionsCmd,
 	categoriesCmd,
 	aliasesCmd,
+	instancesCmd,
 	appsCmd,
 	logsCmd,
 	warningsCmd,
@@ -70,6 +73,7 @@ var api = []*Command{
 	debugCmd,
 	snapshotCmd,
 	snapshotExportCmd,
+	snapshotImportUploadCmd,
 	connectionsCmd,
 	modelCmd,
 	cohortsCmd,
@@ -79,6 +83,7 @@ var api = []*Command{
 	themesCmd,
 	accessoriesChangeCmd,
 	validationSetsListCmd,
+	validationSetsStatusCmd,
 	validationSetsCmd,
 	routineConsoleConfStartCmd,
 	systemRecoveryKeysCmd,
@@ -95,6 +100,8 @@ var api = []*Command{
 	requestsRuleCmd,
 	systemSecurebootCmd,
 	systemVolumesCmd,
+	metricsCmd,
+	clusterCmd,
 }
 
 type featureEndpoint struct {
@@ -136,6 +143,7 @@ const (
 	polkitActionManageInterfaces    = "io.snapcraft.snapd.manage-interfaces"
 	polkitActionManageConfiguration = "io.snapcraft.snapd.manage-configuration"
 	polkitActionManageFDE           = "io.snapcraft.snapd.manage-fde"
+	polkitActionManageSystem        = "io.snapcraft.snapd.manage-system"
 )
 
 // userFromRequest extracts user information from request and return the
@@ -207,6 +215,7 @@ var (
 	snapstateSwitch                         = snapstate.Switch
 	snapstateProceedWithRefresh             = snapstate.ProceedWithRefresh
 	snapstateHoldRefreshesBySystem          = snapstate.HoldRefreshesBySystem
+	snapstateHoldRefreshesBySystemInWindows = snapstate.HoldRefreshesBySystemInWindows
 	snapstateLongestGatingHold              = snapstate.LongestGatingHold
 	snapstateSystemHold                     = snapstate.SystemHold
 	snapstateRemoveComponents               = snapstate.RemoveComponents
@@ -232,8 +241,9 @@ var ensureStateSoon = ensureStateSoonImpl
 
 var newChange = newChangeImpl
 
-func newChangeImpl(st *state.State, kind, summary string, tsets []*state.TaskSet, snapNames []string) *state.Change {
+func newChangeImpl(ctx context.Context, st *state.State, kind, summary string, tsets []*state.TaskSet, snapNames []string) *state.Change {
 	chg := st.NewChange(kind, summary)
+	setRequestID(ctx, chg)
 	for _, ts := range tsets {
 		chg.AddAll(ts)
 	}
@@ -127,7 +127,7 @@ func changeAliases(c *Command, r *http.Request, user *auth.UserState) Response {
 		changeKind = preferChangeKind
 	}
 
-	change := newChange(st, changeKind, summary, []*state.TaskSet{taskset}, []string{a.Snap})
+	change := newChange(r.Context(), st, changeKind, summary, []*state.TaskSet{taskset}, []string{a.Snap})
 	st.EnsureBefore(0)
 
 	return AsyncResponse(nil, change.ID())
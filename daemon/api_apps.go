@@ -30,6 +30,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/client/clientutil"
 	"github.com/snapcore/snapd/osutil/user"
 	"github.com/snapcore/snapd/overlord/auth"
@@ -62,7 +63,7 @@ var serviceControlChangeKind = swfeats.RegisterChangeKind("service-control")
 
 var newStatusDecorator = func(ctx context.Context, isGlobal bool, uid string) clientutil.StatusDecorator {
 	if isGlobal {
-		return servicestate.NewStatusDecorator(progress.Null)
+		return servicestate.NewAggregatingStatusDecorator(progress.Null, ctx)
 	} else {
 		return servicestate.NewStatusDecoratorForUid(progress.Null, ctx, uid)
 	}
@@ -117,10 +118,35 @@ func getAppsInfo(c *Command, r *http.Request, user *auth.UserState) Response {
 	if err != nil {
 		return InternalError("%v", err)
 	}
+	if err := markRestartPending(c.d.overlord.State(), clientAppInfos); err != nil {
+		return InternalError("cannot get services needing a restart: %v", err)
+	}
 
 	return SyncResponse(clientAppInfos)
 }
 
+// markRestartPending flags the services which need a restart to see the
+// changes of their snap.
+func markRestartPending(st *state.State, appInfos []client.AppInfo) error {
+	st.Lock()
+	apps, err := servicestate.ServicesNeedingRestart(st)
+	st.Unlock()
+	if err != nil {
+		return err
+	}
+	if len(apps) == 0 {
+		return nil
+	}
+	pending := make(map[string]bool, len(apps))
+	for _, app := range apps {
+		pending[app.String()] = true
+	}
+	for i := range appInfos {
+		appInfos[i].RestartPending = pending[appInfos[i].Snap+"."+appInfos[i].Name]
+	}
+	return nil
+}
+
 type appInfoOptions struct {
 	service bool
 }
@@ -256,7 +282,18 @@ func getLogs(c *Command, r *http.Request, user *auth.UserState) Response {
 		return AppNotFound("no matching services")
 	}
 
-	reader, err := servicestate.LogReader(appInfos, n, follow)
+	u, err := systemUserFromRequest(r)
+	if err != nil {
+		return BadRequest("cannot get logs: %v", err)
+	}
+	// root gets the logs of user services of all users, everyone else only
+	// those of their own user services
+	uid := u.Uid
+	if uid == "0" {
+		uid = ""
+	}
+
+	reader, err := servicestate.LogReader(appInfos, n, follow, uid)
 	if err != nil {
 		return InternalError("cannot get logs: %v", err)
 	}
@@ -306,12 +343,33 @@ func postApps(c *Command, r *http.Request, user *auth.UserState) Response {
 		return BadRequest("cannot decode request body into service operation: %v", err)
 	}
 	// XXX: decoder.More()
+	st := c.d.overlord.State()
+	if inst.AllAffected {
+		if inst.Action != "restart" || len(inst.Names) > 0 {
+			return BadRequest("cannot use all-affected with a list of services or an action other than restart")
+		}
+		st.Lock()
+		apps, err := servicestate.ServicesNeedingRestart(st)
+		if err != nil {
+			st.Unlock()
+			return InternalError("cannot get services needing a restart: %v", err)
+		}
+		if len(apps) == 0 {
+			chg := newChange(r.Context(), st, serviceControlChangeKind, "Restart services pending a restart", nil, nil)
+			chg.SetStatus(state.DoneStatus)
+			st.Unlock()
+			return AsyncResponse(nil, chg.ID())
+		}
+		st.Unlock()
+		for _, app := range apps {
+			inst.Names = append(inst.Names, app.String())
+		}
+	}
 	if len(inst.Names) == 0 {
 		// on POST, don't allow empty to mean all
 		return BadRequest("cannot perform operation on services without a list of services to operate on")
 	}
 
-	st := c.d.overlord.State()
 	appInfos, rspe := appInfosFor(st, inst.Names, appInfoOptions{service: true})
 	if rspe != nil {
 		return rspe
@@ -343,7 +401,7 @@ func postApps(c *Command, r *http.Request, user *auth.UserState) Response {
 	}
 	// names received in the request can be snap or snap.app, we need to
 	// extract the actual snap names before associating them with a change
-	chg := newChange(st, serviceControlChangeKind, "Running service command", tss, namesToSnapNames(inst))
+	chg := newChange(r.Context(), st, serviceControlChangeKind, "Running service command", tss, namesToSnapNames(inst))
 	st.EnsureBefore(0)
 	return AsyncResponse(nil, chg.ID())
 }
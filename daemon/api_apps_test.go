@@ -61,6 +61,8 @@ type appsSuite struct {
 
 	journalctlRestorer func()
 	jctlSvcses         [][]string
+	jctlUserSvcses     [][]string
+	jctlUids           []string
 	jctlNs             []int
 	jctlFollows        []bool
 	jctlNamespaces     []bool
@@ -74,8 +76,10 @@ type appsSuite struct {
 	infoA, infoB, infoC, infoD, infoE *snap.Info
 }
 
-func (s *appsSuite) journalctl(svcs []string, n int, follow, namespaces bool) (rc io.ReadCloser, err error) {
+func (s *appsSuite) journalctl(svcs, userSvcs []string, uid string, n int, follow, namespaces bool) (rc io.ReadCloser, err error) {
 	s.jctlSvcses = append(s.jctlSvcses, svcs)
+	s.jctlUserSvcses = append(s.jctlUserSvcses, userSvcs)
+	s.jctlUids = append(s.jctlUids, uid)
 	s.jctlNs = append(s.jctlNs, n)
 	s.jctlFollows = append(s.jctlFollows, follow)
 	s.jctlNamespaces = append(s.jctlNamespaces, namespaces)
@@ -150,6 +154,8 @@ func (s *appsSuite) SetUpTest(c *check.C) {
 	s.apiBaseSuite.SetUpTest(c)
 
 	s.jctlSvcses = nil
+	s.jctlUserSvcses = nil
+	s.jctlUids = nil
 	s.jctlNs = nil
 	s.jctlFollows = nil
 	s.jctlNamespaces = nil
@@ -294,6 +300,35 @@ func (s *appsSuite) TestGetAppsInfoNames(c *check.C) {
 	c.Check(sort.StringsAreSorted(appNames), check.Equals, true)
 }
 
+func (s *appsSuite) TestGetAppsInfoRestartPending(c *check.C) {
+	r := daemon.MockNewStatusDecorator(func(ctx context.Context, isGlobal bool, uid string) clientutil.StatusDecorator {
+		return s
+	})
+	defer r()
+	s.decoratorResults = map[string]appsSuiteDecoratorResult{
+		"snap-a.svc1": {daemonType: "simple", active: true, enabled: true},
+		"snap-a.svc2": {daemonType: "simple", active: true, enabled: true},
+	}
+
+	st := s.d.Overlord().State()
+	st.Lock()
+	err := servicestate.MarkNeedingRestart(st, []*snap.AppInfo{s.infoA.Apps["svc2"]})
+	st.Unlock()
+	c.Assert(err, check.IsNil)
+
+	req, err := http.NewRequest("GET", "/v2/apps?names=snap-a&select=service", nil)
+	c.Assert(err, check.IsNil)
+
+	rsp := s.syncReq(c, req, nil, actionIsExpected)
+	c.Assert(rsp.Status, check.Equals, 200)
+	apps := rsp.Result.([]client.AppInfo)
+	c.Assert(apps, check.HasLen, 2)
+	c.Check(apps[0].Name, check.Equals, "svc1")
+	c.Check(apps[0].RestartPending, check.Equals, false)
+	c.Check(apps[1].Name, check.Equals, "svc2")
+	c.Check(apps[1].RestartPending, check.Equals, true)
+}
+
 func (s *appsSuite) TestGetAppsInfoServices(c *check.C) {
 	r := daemon.MockNewStatusDecorator(func(ctx context.Context, isGlobal bool, uid string) clientutil.StatusDecorator {
 		c.Check(isGlobal, check.Equals, true)
@@ -762,6 +797,49 @@ func (s *appsSuite) TestPostAppsRestart(c *check.C) {
 	s.testPostApps(c, inst, expected)
 }
 
+func (s *appsSuite) TestPostAppsRestartAllAffected(c *check.C) {
+	st := s.d.Overlord().State()
+	st.Lock()
+	err := servicestate.MarkNeedingRestart(st, []*snap.AppInfo{s.infoA.Apps["svc1"], s.infoB.Apps["svc3"]})
+	st.Unlock()
+	c.Assert(err, check.IsNil)
+
+	inst := servicestate.Instruction{Action: "restart"}
+	inst.AllAffected = true
+	expected := []serviceControlArgs{
+		{action: "restart", names: []string{"snap-a.svc1", "snap-b.svc3"}, scope: client.ScopeSelector{"system", "user"}},
+	}
+	s.testPostApps(c, inst, expected)
+}
+
+func (s *appsSuite) TestPostAppsRestartAllAffectedNone(c *check.C) {
+	inst := servicestate.Instruction{Action: "restart"}
+	inst.AllAffected = true
+	chg := s.testPostApps(c, inst, nil)
+
+	st := chg.State()
+	st.Lock()
+	defer st.Unlock()
+	c.Check(chg.Status(), check.Equals, state.DoneStatus)
+}
+
+func (s *appsSuite) TestPostAppsAllAffectedErrors(c *check.C) {
+	for _, inst := range []servicestate.Instruction{
+		{Action: "restart", Names: []string{"snap-a"}},
+		{Action: "start"},
+	} {
+		inst.AllAffected = true
+		postBody, err := json.Marshal(inst)
+		c.Assert(err, check.IsNil)
+		req, err := http.NewRequest("POST", "/v2/apps", bytes.NewBuffer(postBody))
+		c.Assert(err, check.IsNil)
+
+		rspe := s.errorReq(c, req, s.authUser, actionIsExpected)
+		c.Check(rspe.Status, check.Equals, 400)
+		c.Check(rspe.Message, check.Equals, "cannot use all-affected with a list of services or an action other than restart")
+	}
+}
+
 func (s *appsSuite) TestPostAppsReload(c *check.C) {
 	inst := servicestate.Instruction{Action: "restart", Names: []string{"snap-a.svc2"}}
 	inst.Reload = true
@@ -1049,6 +1127,46 @@ func (s *appsSuite) TestLogsWithNamespaceOption(c *check.C) {
 	c.Check(rec.Body.String(), check.Equals, "")
 }
 
+func (s *appsSuite) TestLogsUserServices(c *check.C) {
+	s.expectLogsAccess()
+
+	s.jctlRCs = []io.ReadCloser{io.NopCloser(strings.NewReader(`
+{"MESSAGE": "hello1", "SYSLOG_IDENTIFIER": "xyzzy", "_PID": "42", "__REALTIME_TIMESTAMP": "42"}
+	`))}
+
+	req, err := http.NewRequest("GET", "/v2/logs?names=snap-a.svc2,snap-e", nil)
+	c.Assert(err, check.IsNil)
+
+	rec := httptest.NewRecorder()
+	s.req(c, req, nil, actionIsExpected).ServeHTTP(rec, req)
+	c.Check(rec.Code, check.Equals, 200)
+
+	c.Check(s.jctlSvcses, check.DeepEquals, [][]string{{"snap.snap-a.svc2.service"}})
+	c.Check(s.jctlUserSvcses, check.DeepEquals, [][]string{{"snap.snap-e.svc4.service"}})
+	// root gets the logs of all users
+	c.Check(s.jctlUids, check.DeepEquals, []string{""})
+}
+
+func (s *appsSuite) TestLogsUserServicesNonRoot(c *check.C) {
+	s.expectLogsAccess()
+	s.AddCleanup(daemon.MockSystemUserFromRequest(func(r *http.Request) (*user.User, error) {
+		return &user.User{Uid: "1000", Username: "user"}, nil
+	}))
+
+	s.jctlRCs = []io.ReadCloser{io.NopCloser(strings.NewReader(""))}
+
+	req, err := http.NewRequest("GET", "/v2/logs?names=snap-e", nil)
+	c.Assert(err, check.IsNil)
+
+	rec := httptest.NewRecorder()
+	s.req(c, req, nil, actionIsExpected).ServeHTTP(rec, req)
+	c.Check(rec.Code, check.Equals, 200)
+
+	c.Check(s.jctlSvcses, check.DeepEquals, [][]string{nil})
+	c.Check(s.jctlUserSvcses, check.DeepEquals, [][]string{{"snap.snap-e.svc4.service"}})
+	c.Check(s.jctlUids, check.DeepEquals, []string{"1000"})
+}
+
 func (s *appsSuite) TestLogsN(c *check.C) {
 	s.expectLogsAccess()
 
@@ -22,8 +22,10 @@ package daemon
 import (
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"os"
 	"strconv"
 
 	"github.com/snapcore/snapd/asserts"
@@ -100,25 +102,57 @@ func getAssertTypeNames(c *Command, r *http.Request, user *auth.UserState) Respo
 }
 
 func doAssert(c *Command, r *http.Request, user *auth.UserState) Response {
-	batch := asserts.NewBatch(nil)
-	_, err := batch.AddStream(r.Body)
+	// spool the assertions to disk so that neither they all need to be
+	// held in memory nor the state is locked while reading the request
+	tmpPath, err := writeToTempFile(r.Body)
+	if tmpPath != "" {
+		defer os.Remove(tmpPath)
+	}
 	if err != nil {
+		return InternalError("cannot spool assertions: %v", err)
+	}
+	if err := checkAssertionsFile(tmpPath); err != nil {
 		return BadRequest("cannot decode request body into assertions: %v", err)
 	}
 
+	f, err := os.Open(tmpPath)
+	if err != nil {
+		return InternalError("cannot open spooled assertions: %v", err)
+	}
+	defer f.Close()
+
 	state := c.d.overlord.State()
 	state.Lock()
 	defer state.Unlock()
 
-	if err := assertstate.AddBatch(state, batch, &asserts.CommitOptions{
-		Precheck: true,
-	}); err != nil {
+	if err := assertstate.AddStream(state, f, nil); err != nil {
 		return BadRequest("assert failed: %v", err)
 	}
 
 	return SyncResponse(nil)
 }
 
+// checkAssertionsFile checks that the file at path holds a well-formed
+// stream of assertions.
+func checkAssertionsFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dec := asserts.NewDecoder(f)
+	for {
+		_, err := dec.Decode()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
 func assertsFindOneRemote(c *Command, at *asserts.AssertionType, headers map[string]string, user *auth.UserState) ([]asserts.Assertion, error) {
 	primaryKeys, err := asserts.PrimaryKeyFromHeaders(at, headers)
 	if err != nil {
@@ -83,6 +83,7 @@ type apiBaseSuite struct {
 	err               error
 	vars              map[string]string
 	storeSearch       store.Search
+	storeSnapSpec     store.SnapSpec
 	suggestedCurrency string
 	d                 *daemon.Daemon
 	user              *auth.UserState
@@ -213,6 +214,7 @@ func (s *apiBaseSuite) SnapInfo(ctx context.Context, spec store.SnapSpec, user *
 	s.pokeStateLock()
 	s.user = user
 	s.ctx = ctx
+	s.storeSnapSpec = spec
 	if len(s.rsnaps) > 0 {
 		return s.rsnaps[0], s.err
 	}
@@ -350,6 +352,7 @@ func (s *apiBaseSuite) SetUpTest(c *check.C) {
 	s.rsnaps = nil
 	s.suggestedCurrency = ""
 	s.storeSearch = store.Search{}
+	s.storeSnapSpec = store.SnapSpec{}
 	s.err = nil
 	s.vars = nil
 	s.user = nil
@@ -780,6 +783,11 @@ func (s *apiBaseSuite) expectRootAccess() {
 	s.expectedWriteAccess = daemon.RootAccess{}
 }
 
+func (s *apiBaseSuite) expectManageSystemAccess() {
+	s.expectedReadAccess = daemon.RootAccess{}
+	s.expectedWriteAccess = daemon.RootAccess{Polkit: "io.snapcraft.snapd.manage-system"}
+}
+
 func (s *apiBaseSuite) expectAuthenticatedAccess() {
 	s.expectedReadAccess = daemon.AuthenticatedAccess{}
 	s.expectedWriteAccess = daemon.AuthenticatedAccess{}
@@ -0,0 +1,202 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/features"
+	"github.com/snapcore/snapd/overlord/auth"
+	"github.com/snapcore/snapd/overlord/clusterstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/overlord/swfeats"
+)
+
+var clusterCmd = &Command{
+	Path:        "/v2/cluster",
+	GET:         getCluster,
+	POST:        postCluster,
+	Actions:     []string{"assemble", "leave", "hold-refreshes", "resume-refreshes"},
+	ReadAccess:  authenticatedAccess{},
+	WriteAccess: rootAccess{Polkit: polkitActionManageSystem},
+}
+
+var (
+	clusterstateAssemble      = clusterstate.Assemble
+	clusterstateLeave         = clusterstate.Leave
+	clusterstateClusterStatus = clusterstate.ClusterStatus
+
+	clusterstateHoldRefreshes   = (*clusterstate.ClusterManager).HoldRefreshes
+	clusterstateResumeRefreshes = (*clusterstate.ClusterManager).ResumeRefreshes
+)
+
+var assembleClusterChangeKind = swfeats.RegisterChangeKind("assemble-cluster")
+
+func getCluster(c *Command, r *http.Request, user *auth.UserState) Response {
+	st := c.d.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	if err := validateFeatureFlag(st, features.Clustering); err != nil {
+		return err
+	}
+
+	status, err := clusterstateClusterStatus(st)
+	if err != nil {
+		return InternalError("cannot get cluster status: %v", err)
+	}
+
+	result := client.ClusterStatus{
+		ClusterID: status.ClusterID,
+	}
+	if as := status.Assembly; as != nil {
+		assembly := &client.ClusterAssembly{
+			Status:       "in-progress",
+			RDT:          as.RDT,
+			Address:      as.Address,
+			Fingerprint:  as.Fingerprint,
+			Initiated:    as.Initiated,
+			ExpectedSize: as.ExpectedSize,
+			Trusted:      as.Trusted,
+			Routes:       as.Routes,
+		}
+		if as.Done {
+			assembly.Status = "done"
+		}
+		for _, m := range as.Members {
+			assembly.Members = append(assembly.Members, client.ClusterMember{
+				RDT:         m.RDT,
+				Serial:      m.Serial,
+				Address:     m.Address,
+				Fingerprint: m.Fingerprint,
+			})
+		}
+		result.Assembly = assembly
+	}
+	if rs := status.Refreshes; rs != nil {
+		refreshes := &client.ClusterRefreshes{
+			Leader:  rs.Leader,
+			HeldAll: rs.HeldAll,
+			Held:    rs.Held,
+		}
+		for _, r := range rs.Refreshes {
+			refreshes.Refreshes = append(refreshes.Refreshes, client.ClusterRefresh{
+				Snap:     r.Snap,
+				Revision: r.Revision,
+				Status:   r.Status,
+				Reason:   r.Reason,
+			})
+		}
+		result.Refreshes = refreshes
+	}
+
+	return SyncResponse(result)
+}
+
+type postClusterData struct {
+	Action       string   `json:"action"`
+	Secret       string   `json:"secret"`
+	Address      string   `json:"address"`
+	Peers        []string `json:"peers"`
+	ExpectedSize int      `json:"expected-size"`
+	Snaps        []string `json:"snaps"`
+}
+
+func postCluster(c *Command, r *http.Request, user *auth.UserState) Response {
+	var data postClusterData
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		return BadRequest("cannot decode cluster action from request body: %v", err)
+	}
+
+	st := c.d.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	if err := validateFeatureFlag(st, features.Clustering); err != nil {
+		return err
+	}
+
+	switch data.Action {
+	case "assemble":
+		return assembleCluster(r.Context(), st, &data)
+	case "leave":
+		return leaveCluster(st)
+	case "hold-refreshes":
+		return holdClusterRefreshes(c.d.overlord.ClusterManager(), &data)
+	case "resume-refreshes":
+		return resumeClusterRefreshes(c.d.overlord.ClusterManager(), &data)
+	default:
+		return BadRequest("unknown cluster action %q", data.Action)
+	}
+}
+
+func assembleCluster(ctx context.Context, st *state.State, data *postClusterData) Response {
+	ts, err := clusterstateAssemble(st, clusterstate.AssembleOptions{
+		Secret:       data.Secret,
+		Address:      data.Address,
+		Peers:        data.Peers,
+		ExpectedSize: data.ExpectedSize,
+	})
+	if err != nil {
+		return BadRequest(err.Error())
+	}
+
+	chg := newChange(ctx, st, assembleClusterChangeKind, "Assemble cluster", []*state.TaskSet{ts}, nil)
+	ensureStateSoon(st)
+
+	return AsyncResponse(nil, chg.ID())
+}
+
+func leaveCluster(st *state.State) Response {
+	if err := clusterstateLeave(st); err != nil {
+		if errors.Is(err, clusterstate.ErrNotInCluster) {
+			return BadRequest("cannot leave cluster: %v", err)
+		}
+		return InternalError("cannot leave cluster: %v", err)
+	}
+
+	return SyncResponse(nil)
+}
+
+func holdClusterRefreshes(mgr *clusterstate.ClusterManager, data *postClusterData) Response {
+	if err := clusterstateHoldRefreshes(mgr, data.Snaps); err != nil {
+		if errors.Is(err, clusterstate.ErrNotAssembled) {
+			return BadRequest("cannot hold cluster refreshes: %v", err)
+		}
+		return InternalError("cannot hold cluster refreshes: %v", err)
+	}
+
+	return SyncResponse(nil)
+}
+
+func resumeClusterRefreshes(mgr *clusterstate.ClusterManager, data *postClusterData) Response {
+	if err := clusterstateResumeRefreshes(mgr, data.Snaps); err != nil {
+		if errors.Is(err, clusterstate.ErrNotAssembled) {
+			return BadRequest("cannot resume cluster refreshes: %v", err)
+		}
+		return InternalError("cannot resume cluster refreshes: %v", err)
+	}
+
+	return SyncResponse(nil)
+}
@@ -0,0 +1,322 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/daemon"
+	"github.com/snapcore/snapd/overlord/clusterstate"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+)
+
+var _ = Suite(&clusterSuite{})
+
+type clusterSuite struct {
+	apiBaseSuite
+
+	st *state.State
+}
+
+func (s *clusterSuite) SetUpTest(c *C) {
+	s.apiBaseSuite.SetUpTest(c)
+
+	d := s.daemonWithOverlordMock()
+	s.st = d.Overlord().State()
+
+	s.expectReadAccess(daemon.AuthenticatedAccess{})
+	s.expectWriteAccess(daemon.RootAccess{Polkit: "io.snapcraft.snapd.manage-system"})
+
+	_, restore := daemon.MockEnsureStateSoon(func(st *state.State) {})
+	s.AddCleanup(restore)
+}
+
+func (s *clusterSuite) enableClustering(c *C) {
+	s.st.Lock()
+	defer s.st.Unlock()
+	tr := config.NewTransaction(s.st)
+	c.Assert(tr.Set("core", "experimental.clustering", true), IsNil)
+	tr.Commit()
+}
+
+func (s *clusterSuite) TestClusterDisabled(c *C) {
+	req, err := http.NewRequest("GET", "/v2/cluster", nil)
+	c.Assert(err, IsNil)
+	rspe := s.errorReq(c, req, nil, actionIsExpected)
+	c.Check(rspe.Status, Equals, 400)
+	c.Check(rspe.Message, Equals, `feature flag "clustering" is disabled: set 'experimental.clustering' to true`)
+
+	req, err = http.NewRequest("POST", "/v2/cluster", bytes.NewBufferString(`{"action": "leave"}`))
+	c.Assert(err, IsNil)
+	rspe = s.errorReq(c, req, nil, actionIsExpected)
+	c.Check(rspe.Status, Equals, 400)
+	c.Check(rspe.Message, Equals, `feature flag "clustering" is disabled: set 'experimental.clustering' to true`)
+}
+
+func (s *clusterSuite) TestGetClusterNotInCluster(c *C) {
+	s.enableClustering(c)
+
+	req, err := http.NewRequest("GET", "/v2/cluster", nil)
+	c.Assert(err, IsNil)
+	rsp := s.syncReq(c, req, nil, actionIsExpected)
+	c.Check(rsp.Status, Equals, 200)
+	c.Check(rsp.Result, DeepEquals, client.ClusterStatus{})
+}
+
+func (s *clusterSuite) TestGetClusterAssembling(c *C) {
+	s.enableClustering(c)
+
+	s.st.Lock()
+	_, err := clusterstate.Assemble(s.st, clusterstate.AssembleOptions{
+		Secret:       "secret",
+		Address:      "10.0.0.1:7070",
+		ExpectedSize: 3,
+	})
+	s.st.Unlock()
+	c.Assert(err, IsNil)
+
+	req, err := http.NewRequest("GET", "/v2/cluster", nil)
+	c.Assert(err, IsNil)
+	rsp := s.syncReq(c, req, nil, actionIsExpected)
+	c.Check(rsp.Status, Equals, 200)
+
+	status, ok := rsp.Result.(client.ClusterStatus)
+	c.Assert(ok, Equals, true)
+	c.Assert(status.Assembly, NotNil)
+	as := status.Assembly
+	c.Check(as.Status, Equals, "in-progress")
+	c.Check(as.RDT, Not(Equals), "")
+	c.Check(as.Fingerprint, Not(Equals), "")
+	c.Check(as.Address, Equals, "10.0.0.1:7070")
+	c.Check(as.ExpectedSize, Equals, 3)
+	c.Check(time.Since(as.Initiated) < time.Minute, Equals, true)
+}
+
+func (s *clusterSuite) TestGetClusterRefreshes(c *C) {
+	s.enableClustering(c)
+
+	s.AddCleanup(daemon.MockClusterstateClusterStatus(func(st *state.State) (*clusterstate.Status, error) {
+		return &clusterstate.Status{
+			Assembly: &clusterstate.AssemblyStatus{
+				RDT:  "rdt-1",
+				Done: true,
+			},
+			Refreshes: &clusterstate.RefreshStatus{
+				Leader:  "rdt-1",
+				HeldAll: true,
+				Refreshes: []clusterstate.ClusterRefresh{
+					{Snap: "microk8s", Revision: snap.R(2), Status: "refreshing"},
+					{Snap: "other", Revision: snap.R(5), Status: "waiting", Reason: "refreshes are held"},
+				},
+			},
+		}, nil
+	}))
+
+	req, err := http.NewRequest("GET", "/v2/cluster", nil)
+	c.Assert(err, IsNil)
+	rsp := s.syncReq(c, req, nil, actionIsExpected)
+	c.Check(rsp.Status, Equals, 200)
+	c.Check(rsp.Result, DeepEquals, client.ClusterStatus{
+		Assembly: &client.ClusterAssembly{
+			Status: "done",
+			RDT:    "rdt-1",
+		},
+		Refreshes: &client.ClusterRefreshes{
+			Leader:  "rdt-1",
+			HeldAll: true,
+			Refreshes: []client.ClusterRefresh{
+				{Snap: "microk8s", Revision: snap.R(2), Status: "refreshing"},
+				{Snap: "other", Revision: snap.R(5), Status: "waiting", Reason: "refreshes are held"},
+			},
+		},
+	})
+}
+
+func (s *clusterSuite) TestGetClusterStatusError(c *C) {
+	s.enableClustering(c)
+
+	s.AddCleanup(daemon.MockClusterstateClusterStatus(func(st *state.State) (*clusterstate.Status, error) {
+		return nil, errors.New("boom")
+	}))
+
+	req, err := http.NewRequest("GET", "/v2/cluster", nil)
+	c.Assert(err, IsNil)
+	rspe := s.errorReq(c, req, nil, actionIsExpected)
+	c.Check(rspe.Status, Equals, 500)
+	c.Check(rspe.Message, Equals, "cannot get cluster status: boom")
+}
+
+func (s *clusterSuite) TestPostClusterAssemble(c *C) {
+	s.enableClustering(c)
+
+	var opts clusterstate.AssembleOptions
+	s.AddCleanup(daemon.MockClusterstateAssemble(func(st *state.State, o clusterstate.AssembleOptions) (*state.TaskSet, error) {
+		opts = o
+		return state.NewTaskSet(st.NewTask("assemble-cluster", "...")), nil
+	}))
+
+	body := `{"action": "assemble", "secret": "secret", "address": "10.0.0.1:7070", "peers": ["10.0.0.2:7070"], "expected-size": 2}`
+	req, err := http.NewRequest("POST", "/v2/cluster", bytes.NewBufferString(body))
+	c.Assert(err, IsNil)
+	rsp := s.asyncReq(c, req, nil, actionIsExpected)
+
+	c.Check(opts, DeepEquals, clusterstate.AssembleOptions{
+		Secret:       "secret",
+		Address:      "10.0.0.1:7070",
+		Peers:        []string{"10.0.0.2:7070"},
+		ExpectedSize: 2,
+	})
+
+	s.st.Lock()
+	defer s.st.Unlock()
+	chg := s.st.Change(rsp.Change)
+	c.Assert(chg, NotNil)
+	c.Check(chg.Kind(), Equals, "assemble-cluster")
+	c.Check(chg.Summary(), Equals, "Assemble cluster")
+	c.Check(chg.Tasks(), HasLen, 1)
+}
+
+func (s *clusterSuite) TestPostClusterAssembleError(c *C) {
+	s.enableClustering(c)
+
+	req, err := http.NewRequest("POST", "/v2/cluster", bytes.NewBufferString(`{"action": "assemble", "address": "10.0.0.1:7070"}`))
+	c.Assert(err, IsNil)
+	rspe := s.errorReq(c, req, nil, actionIsExpected)
+	c.Check(rspe.Status, Equals, 400)
+	c.Check(rspe.Message, Equals, "cannot assemble cluster: secret is required")
+}
+
+func (s *clusterSuite) TestPostClusterLeave(c *C) {
+	s.enableClustering(c)
+
+	called := 0
+	s.AddCleanup(daemon.MockClusterstateLeave(func(st *state.State) error {
+		called++
+		return nil
+	}))
+
+	req, err := http.NewRequest("POST", "/v2/cluster", bytes.NewBufferString(`{"action": "leave"}`))
+	c.Assert(err, IsNil)
+	rsp := s.syncReq(c, req, nil, actionIsExpected)
+	c.Check(rsp.Status, Equals, 200)
+	c.Check(called, Equals, 1)
+}
+
+func (s *clusterSuite) TestPostClusterLeaveErrors(c *C) {
+	s.enableClustering(c)
+
+	for _, tc := range []struct {
+		err     error
+		status  int
+		message string
+	}{
+		{clusterstate.ErrNotInCluster, 400, "cannot leave cluster: device is not part of a cluster"},
+		{errors.New("boom"), 500, "cannot leave cluster: boom"},
+	} {
+		restore := daemon.MockClusterstateLeave(func(st *state.State) error {
+			return tc.err
+		})
+
+		req, err := http.NewRequest("POST", "/v2/cluster", bytes.NewBufferString(`{"action": "leave"}`))
+		c.Assert(err, IsNil)
+		rspe := s.errorReq(c, req, nil, actionIsExpected)
+		c.Check(rspe.Status, Equals, tc.status)
+		c.Check(rspe.Message, Equals, tc.message)
+
+		restore()
+	}
+}
+
+func (s *clusterSuite) TestPostClusterHoldResumeRefreshes(c *C) {
+	s.enableClustering(c)
+
+	var held, resumed []string
+	s.AddCleanup(daemon.MockClusterstateHoldRefreshes(func(m *clusterstate.ClusterManager, snaps []string) error {
+		held = snaps
+		return nil
+	}))
+	s.AddCleanup(daemon.MockClusterstateResumeRefreshes(func(m *clusterstate.ClusterManager, snaps []string) error {
+		resumed = snaps
+		return nil
+	}))
+
+	req, err := http.NewRequest("POST", "/v2/cluster", bytes.NewBufferString(`{"action": "hold-refreshes", "snaps": ["microk8s"]}`))
+	c.Assert(err, IsNil)
+	rsp := s.syncReq(c, req, nil, actionIsExpected)
+	c.Check(rsp.Status, Equals, 200)
+	c.Check(held, DeepEquals, []string{"microk8s"})
+	c.Check(resumed, IsNil)
+
+	req, err = http.NewRequest("POST", "/v2/cluster", bytes.NewBufferString(`{"action": "resume-refreshes", "snaps": ["microk8s", "other"]}`))
+	c.Assert(err, IsNil)
+	rsp = s.syncReq(c, req, nil, actionIsExpected)
+	c.Check(rsp.Status, Equals, 200)
+	c.Check(resumed, DeepEquals, []string{"microk8s", "other"})
+}
+
+func (s *clusterSuite) TestPostClusterHoldResumeRefreshesErrors(c *C) {
+	s.enableClustering(c)
+
+	for _, tc := range []struct {
+		action  string
+		err     error
+		status  int
+		message string
+	}{
+		{"hold-refreshes", clusterstate.ErrNotAssembled, 400, "cannot hold cluster refreshes: device is not part of an assembled cluster"},
+		{"hold-refreshes", errors.New("boom"), 500, "cannot hold cluster refreshes: boom"},
+		{"resume-refreshes", clusterstate.ErrNotAssembled, 400, "cannot resume cluster refreshes: device is not part of an assembled cluster"},
+		{"resume-refreshes", errors.New("boom"), 500, "cannot resume cluster refreshes: boom"},
+	} {
+		f := func(m *clusterstate.ClusterManager, snaps []string) error {
+			return tc.err
+		}
+		restoreHold := daemon.MockClusterstateHoldRefreshes(f)
+		restoreResume := daemon.MockClusterstateResumeRefreshes(f)
+
+		req, err := http.NewRequest("POST", "/v2/cluster", bytes.NewBufferString(fmt.Sprintf(`{"action": %q}`, tc.action)))
+		c.Assert(err, IsNil)
+		rspe := s.errorReq(c, req, nil, actionIsExpected)
+		c.Check(rspe.Status, Equals, tc.status)
+		c.Check(rspe.Message, Equals, tc.message)
+
+		restoreHold()
+		restoreResume()
+	}
+}
+
+func (s *clusterSuite) TestPostClusterUnknownAction(c *C) {
+	s.enableClustering(c)
+
+	req, err := http.NewRequest("POST", "/v2/cluster", bytes.NewBufferString(`{"action": "explode"}`))
+	c.Assert(err, IsNil)
+	rspe := s.errorReq(c, req, nil, actionIsUnexpected)
+	c.Check(rspe.Status, Equals, 400)
+	c.Check(rspe.Message, Equals, `unknown cluster action "explode"`)
+}
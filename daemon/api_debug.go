@@ -23,6 +23,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"regexp"
 	"sort"
 	"time"
 
@@ -46,6 +47,7 @@ var debugCmd = &Command{
 		"add-warning", "unshow-warnings", "ensure-state-soon",
 		"can-manage-refreshes", "prune", "stacktraces",
 		"create-recovery-system", "migrate-home",
+		"simulate-disk-space",
 	},
 	ReadAccess:  openAccess{},
 	WriteAccess: rootAccess{},
@@ -58,6 +60,8 @@ type debugAction struct {
 		ChgID string `json:"chg-id"`
 
 		RecoverySystemLabel string `json:"recovery-system-label"`
+
+		Operation string `json:"operation"`
 	} `json:"params"`
 	Snaps []string `json:"snaps"`
 }
@@ -108,6 +112,10 @@ type changeTimings struct {
 	UndoingTime    time.Duration         `json:"undoing-time,omitempty"`
 	DoingTimings   []*timings.TimingJSON `json:"doing-timings,omitempty"`
 	UndoingTimings []*timings.TimingJSON `json:"undoing-timings,omitempty"`
+	// SecurityBackendTimings are the total durations spent in each
+	// security backend while doing or undoing the task, indexed by
+	// backend name
+	SecurityBackendTimings map[string]time.Duration `json:"security-backend-timings,omitempty"`
 }
 
 type debugTimings struct {
@@ -132,6 +140,46 @@ func minLane(t *state.Task) int {
 	return minLane
 }
 
+// securityBackendTimingLabels are the labels of the timings recorded by
+// interfaces and ifacestate when running a security backend.
+var securityBackendTimingLabels = map[string]bool{
+	"setup-security-backend":         true,
+	"setup-security-backend[many]":   true,
+	"delayed-setup-security-backend": true,
+	"reinitialize-security-backend":  true,
+}
+
+// securityBackendSummaryRe extracts the name of the backend from the summary
+// of a security backend timing, e.g. `setup security backend "apparmor" for
+// snap "foo"` or `reinitialize "apparmor" security backend`.
+var securityBackendSummaryRe = regexp.MustCompile(`security backend "([^"]+)"|"([^"]+)" security backend`)
+
+// securityBackendTimings sums up the durations of security backend timings by
+// backend name.
+func securityBackendTimings(tms ...[]*timings.TimingJSON) map[string]time.Duration {
+	var durations map[string]time.Duration
+	for _, nested := range tms {
+		for _, tm := range nested {
+			if !securityBackendTimingLabels[tm.Label] {
+				continue
+			}
+			match := securityBackendSummaryRe.FindStringSubmatch(tm.Summary)
+			if match == nil {
+				continue
+			}
+			name := match[1]
+			if name == "" {
+				name = match[2]
+			}
+			if durations == nil {
+				durations = make(map[string]time.Duration)
+			}
+			durations[name] += tm.Duration
+		}
+	}
+	return durations
+}
+
 func collectChangeTimings(st *state.State, changeID string) (map[string]*changeTimings, error) {
 	chg := st.Change(changeID)
 	if chg == nil {
@@ -172,6 +220,8 @@ func collectChangeTimings(st *state.State, changeID string) (map[string]*changeT
 			UndoingTime:    t.UndoingTime(),
 			DoingTimings:   doingTimingsByTask[t.ID()],
 			UndoingTimings: undoingTimingsByTask[t.ID()],
+
+			SecurityBackendTimings: securityBackendTimings(doingTimingsByTask[t.ID()], undoingTimingsByTask[t.ID()]),
 		}
 	}
 	return m, nil
@@ -382,6 +432,12 @@ func getFeatures(c *Command) Response {
 	return SyncResponse(resp)
 }
 
+func getUDevMonitorHealth(c *Command) Response {
+	// when the monitor is not running its zero health is reported
+	health, _ := c.d.overlord.InterfaceManager().UDevMonitorHealth()
+	return SyncResponse(health)
+}
+
 func getDebug(c *Command, r *http.Request, user *auth.UserState) Response {
 	query := r.URL.Query()
 	aspect := query.Get("aspect")
@@ -408,6 +464,8 @@ func getDebug(c *Command, r *http.Request, user *auth.UserState) Response {
 		startupTag := query.Get("startup")
 		all := query.Get("all")
 		return getChangeTimings(st, chgID, ensureTag, startupTag, all == "true")
+	case "request-trace":
+		return getRequestTrace(st, query.Get("change-id"))
 	case "seeding":
 		return getSeedingInfo(st)
 	case "gadget-disk-mapping":
@@ -418,6 +476,14 @@ func getDebug(c *Command, r *http.Request, user *auth.UserState) Response {
 		return getRAAInfo(st)
 	case "features":
 		return getFeatures(c)
+	case "boot":
+		return getBootStatus(st)
+	case "udev-monitor":
+		return getUDevMonitorHealth(c)
+	case "recovery":
+		return getRecoveryInfo()
+	case "repairs":
+		return getRepairs(query.Get("repair"))
 	default:
 		return BadRequest("unknown debug aspect %q", aspect)
 	}
@@ -458,7 +524,9 @@ func postDebug(c *Command, r *http.Request, user *auth.UserState) Response {
 	case "create-recovery-system":
 		return createRecovery(st, a.Params.RecoverySystemLabel)
 	case "migrate-home":
-		return migrateHome(st, a.Snaps)
+		return migrateHome(r.Context(), st, a.Snaps)
+	case "simulate-disk-space":
+		return simulateDiskSpace(r.Context(), st, a.Params.Operation, a.Snaps, user)
 	default:
 		return BadRequest("unknown debug action: %v", a.Action)
 	}
@@ -0,0 +1,41 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon
+
+import (
+	"errors"
+
+	"github.com/snapcore/snapd/gadget/device"
+	"github.com/snapcore/snapd/overlord/fdestate"
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+var fdestateGetBootStatus = fdestate.GetBootStatus
+
+func getBootStatus(st *state.State) Response {
+	status, err := fdestateGetBootStatus(st)
+	if errors.Is(err, device.ErrNoSealedKeys) {
+		return BadRequest("cannot report boot status: system has no sealed disk encryption keys")
+	}
+	if err != nil {
+		return InternalError("cannot get boot status: %v", err)
+	}
+	return SyncResponse(status)
+}
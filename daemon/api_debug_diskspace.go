@@ -0,0 +1,46 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon
+
+import (
+	"context"
+
+	"github.com/snapcore/snapd/overlord/auth"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+var snapstateSimulateDiskSpace = snapstate.SimulateDiskSpace
+
+func simulateDiskSpace(ctx context.Context, st *state.State, op string, snaps []string, user *auth.UserState) Response {
+	if op == "" {
+		return BadRequest("no operation was provided")
+	}
+
+	var userID int
+	if user != nil {
+		userID = user.ID
+	}
+	est, err := snapstateSimulateDiskSpace(ctx, st, op, snaps, userID)
+	if err != nil {
+		return errToResponse(err, snaps, BadRequest, "cannot simulate disk space usage: %v")
+	}
+	return SyncResponse(est)
+}
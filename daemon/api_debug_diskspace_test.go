@@ -0,0 +1,98 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/daemon"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+)
+
+func (s *postDebugSuite) TestSimulateDiskSpace(c *check.C) {
+	s.daemonWithOverlordMock()
+	s.expectRootAccess()
+
+	est := &snapstate.DiskSpaceEstimate{
+		Operation: "refresh",
+		Snaps:     []string{"foo", "bar"},
+		Filesystems: []snapstate.DiskSpaceUsage{{
+			Path:      "/var/lib/snapd/snaps",
+			Snaps:     100,
+			Required:  200,
+			Available: 300,
+		}},
+	}
+	called := 0
+	restore := daemon.MockSnapstateSimulateDiskSpace(func(ctx context.Context, st *state.State, op string, names []string, userID int) (*snapstate.DiskSpaceEstimate, error) {
+		called++
+		c.Check(op, check.Equals, "refresh")
+		c.Check(names, check.DeepEquals, []string{"foo", "bar"})
+		c.Check(userID, check.Equals, 0)
+		return est, nil
+	})
+	defer restore()
+
+	body := strings.NewReader(`{"action": "simulate-disk-space", "params": {"operation": "refresh"}, "snaps": ["foo", "bar"]}`)
+	req, err := http.NewRequest("POST", "/v2/debug", body)
+	c.Assert(err, check.IsNil)
+
+	rsp := s.syncReq(c, req, nil, actionIsExpected)
+	c.Check(rsp.Result, check.Equals, est)
+	c.Check(called, check.Equals, 1)
+}
+
+func (s *postDebugSuite) TestSimulateDiskSpaceNoOperation(c *check.C) {
+	s.daemonWithOverlordMock()
+	s.expectRootAccess()
+
+	body := strings.NewReader(`{"action": "simulate-disk-space", "snaps": ["foo"]}`)
+	req, err := http.NewRequest("POST", "/v2/debug", body)
+	c.Assert(err, check.IsNil)
+
+	rspe := s.errorReq(c, req, nil, actionIsExpected)
+	c.Check(rspe.Status, check.Equals, 400)
+	c.Check(rspe.Message, check.Equals, "no operation was provided")
+}
+
+func (s *postDebugSuite) TestSimulateDiskSpaceError(c *check.C) {
+	s.daemonWithOverlordMock()
+	s.expectRootAccess()
+
+	restore := daemon.MockSnapstateSimulateDiskSpace(func(ctx context.Context, st *state.State, op string, names []string, userID int) (*snapstate.DiskSpaceEstimate, error) {
+		return nil, &snap.NotInstalledError{Snap: "foo"}
+	})
+	defer restore()
+
+	body := strings.NewReader(`{"action": "simulate-disk-space", "params": {"operation": "remove"}, "snaps": ["foo"]}`)
+	req, err := http.NewRequest("POST", "/v2/debug", body)
+	c.Assert(err, check.IsNil)
+
+	rspe := s.errorReq(c, req, nil, actionIsExpected)
+	c.Check(rspe.Status, check.Equals, 400)
+	c.Check(rspe.Kind, check.Equals, client.ErrorKindSnapNotInstalled)
+}
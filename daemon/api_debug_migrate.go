@@ -20,6 +20,7 @@
 package daemon
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/snapcore/snapd/overlord/snapstate"
@@ -33,7 +34,7 @@ var snapstateMigrateHome = snapstate.MigrateHome
 
 var migrateHomeChangeKind = swfeats.RegisterChangeKind("migrate-home")
 
-func migrateHome(st *state.State, snaps []string) Response {
+func migrateHome(ctx context.Context, st *state.State, snaps []string) Response {
 	if len(snaps) == 0 {
 		return BadRequest("no snaps were provided")
 	}
@@ -48,6 +49,7 @@ func migrateHome(st *state.State, snaps []string) Response {
 	}
 
 	chg := st.NewChange(migrateHomeChangeKind, fmt.Sprintf("Migrate snap homes to ~/Snap for snaps %s", strutil.Quoted(snaps)))
+	setRequestID(ctx, chg)
 	for _, ts := range tss {
 		chg.AddAll(ts)
 	}
@@ -0,0 +1,118 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/snapcore/snapd/boot"
+)
+
+type recoveryPartitionInfo struct {
+	Name          string `json:"name"`
+	UnlockState   string `json:"unlock-state,omitempty"`
+	UnlockKey     string `json:"unlock-key,omitempty"`
+	MountState    string `json:"mount-state,omitempty"`
+	MountLocation string `json:"mount-location,omitempty"`
+	// Fallback is set when the partition was unlocked with a key other
+	// than the run key.
+	Fallback bool `json:"fallback,omitempty"`
+	// Failed is set when the partition could not be unlocked or mounted.
+	Failed bool `json:"failed,omitempty"`
+}
+
+type recoveryInfo struct {
+	// Source is the snap-bootstrap state file the information was read
+	// from, either degraded.json or unlocked.json.
+	Source string `json:"source"`
+	// Degraded is true when the initramfs entered degraded mode.
+	Degraded   bool                    `json:"degraded"`
+	Partitions []recoveryPartitionInfo `json:"partitions"`
+	// Actions are the recommended actions for the operator.
+	Actions []string `json:"actions,omitempty"`
+}
+
+func getRecoveryInfo() Response {
+	source := boot.DegradedStateFileName
+	unlockState, err := boot.LoadDiskUnlockState(source)
+	if errors.Is(err, fs.ErrNotExist) {
+		source = boot.UnlockedStateFileName
+		unlockState, err = boot.LoadDiskUnlockState(source)
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return NotFound("cannot report recovery status: no disk unlock state was recorded during boot")
+	}
+	if err != nil {
+		return InternalError("cannot read disk unlock state: %v", err)
+	}
+
+	info := &recoveryInfo{
+		Source:   source,
+		Degraded: source == boot.DegradedStateFileName,
+	}
+	for _, part := range []struct {
+		name  string
+		state boot.PartitionState
+	}{
+		{"ubuntu-boot", unlockState.UbuntuBoot},
+		{"ubuntu-data", unlockState.UbuntuData},
+		{"ubuntu-save", unlockState.UbuntuSave},
+	} {
+		pi := recoveryPartitionInfo{
+			Name:          part.name,
+			UnlockState:   part.state.UnlockState,
+			UnlockKey:     part.state.UnlockKey,
+			MountState:    part.state.MountState,
+			MountLocation: part.state.MountLocation,
+			Fallback:      part.state.UnlockKey == boot.KeyFallback || part.state.UnlockKey == boot.KeyRecovery,
+			Failed:        part.state.UnlockState == boot.PartitionErrUnlocking || part.state.MountState == boot.PartitionErrMounting,
+		}
+		info.Partitions = append(info.Partitions, pi)
+		info.Actions = append(info.Actions, recoveryActions(pi)...)
+	}
+
+	return SyncResponse(info)
+}
+
+// recoveryActions returns the actions recommended to the operator given
+// the state of a partition.
+func recoveryActions(pi recoveryPartitionInfo) []string {
+	var actions []string
+	switch pi.UnlockState {
+	case boot.PartitionErrUnlocking:
+		actions = append(actions, fmt.Sprintf("%s could not be unlocked with any of the sealed keys: boot into recover mode and unlock it with the recovery key to access its data", pi.Name))
+	case boot.PartitionUnlocked:
+		switch pi.UnlockKey {
+		case boot.KeyFallback:
+			actions = append(actions, fmt.Sprintf("%s was unlocked with the fallback key: the run key no longer matches the boot chain, check \"snap debug boot\" for pending reseals", pi.Name))
+		case boot.KeyRecovery:
+			actions = append(actions, fmt.Sprintf("%s was unlocked with the recovery key: the sealed keys no longer match the boot chain, check \"snap debug boot\" and consider replacing the recovery key", pi.Name))
+		}
+	}
+	switch pi.MountState {
+	case boot.PartitionErrMounting:
+		actions = append(actions, fmt.Sprintf("%s could not be mounted: check the partition and its filesystem for errors", pi.Name))
+	case boot.PartitionMountedUntrusted:
+		actions = append(actions, fmt.Sprintf("%s was mounted but could not be verified to belong to this installation: do not trust its content", pi.Name))
+	}
+	return actions
+}
@@ -0,0 +1,133 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/daemon"
+	"github.com/snapcore/snapd/dirs"
+)
+
+var _ = Suite(&recoveryDebugSuite{})
+
+type recoveryDebugSuite struct {
+	apiBaseSuite
+}
+
+func (s *recoveryDebugSuite) SetUpTest(c *C) {
+	s.apiBaseSuite.SetUpTest(c)
+	s.daemonWithOverlordMock()
+	c.Assert(os.MkdirAll(dirs.SnapBootstrapRunDir, 0755), IsNil)
+}
+
+func (s *recoveryDebugSuite) writeState(c *C, name, content string) {
+	err := os.WriteFile(filepath.Join(dirs.SnapBootstrapRunDir, name), []byte(content), 0644)
+	c.Assert(err, IsNil)
+}
+
+func (s *recoveryDebugSuite) getRecoveryReq(c *C) *http.Request {
+	req, err := http.NewRequest("GET", "/v2/debug?aspect=recovery", nil)
+	c.Assert(err, IsNil)
+	return req
+}
+
+func (s *recoveryDebugSuite) TestDegraded(c *C) {
+	s.writeState(c, "degraded.json", `{
+"ubuntu-boot": {"mount-state": "mounted", "mount-location": "/run/mnt/ubuntu-boot"},
+"ubuntu-data": {"unlock-state": "error-unlocking", "mount-state": "error-mounting"},
+"ubuntu-save": {"unlock-state": "unlocked", "unlock-key": "fallback", "mount-state": "mounted", "mount-location": "/run/mnt/ubuntu-save"}
+}`)
+	// degraded.json takes precedence
+	s.writeState(c, "unlocked.json", `{}`)
+
+	rsp := s.syncReq(c, s.getRecoveryReq(c), nil, actionIsExpected)
+	c.Check(rsp.Result, DeepEquals, &daemon.RecoveryInfo{
+		Source:   "degraded.json",
+		Degraded: true,
+		Partitions: []daemon.RecoveryPartitionInfo{{
+			Name:          "ubuntu-boot",
+			MountState:    "mounted",
+			MountLocation: "/run/mnt/ubuntu-boot",
+		}, {
+			Name:        "ubuntu-data",
+			UnlockState: "error-unlocking",
+			MountState:  "error-mounting",
+			Failed:      true,
+		}, {
+			Name:          "ubuntu-save",
+			UnlockState:   "unlocked",
+			UnlockKey:     "fallback",
+			MountState:    "mounted",
+			MountLocation: "/run/mnt/ubuntu-save",
+			Fallback:      true,
+		}},
+		Actions: []string{
+			"ubuntu-data could not be unlocked with any of the sealed keys: boot into recover mode and unlock it with the recovery key to access its data",
+			"ubuntu-data could not be mounted: check the partition and its filesystem for errors",
+			`ubuntu-save was unlocked with the fallback key: the run key no longer matches the boot chain, check "snap debug boot" for pending reseals`,
+		},
+	})
+}
+
+func (s *recoveryDebugSuite) TestUnlockedWithRecoveryKey(c *C) {
+	s.writeState(c, "unlocked.json", `{
+"ubuntu-data": {"unlock-state": "unlocked", "unlock-key": "recovery"},
+"ubuntu-save": {"unlock-state": "unlocked", "unlock-key": "run"}
+}`)
+
+	rsp := s.syncReq(c, s.getRecoveryReq(c), nil, actionIsExpected)
+	c.Check(rsp.Result, DeepEquals, &daemon.RecoveryInfo{
+		Source: "unlocked.json",
+		Partitions: []daemon.RecoveryPartitionInfo{{
+			Name: "ubuntu-boot",
+		}, {
+			Name:        "ubuntu-data",
+			UnlockState: "unlocked",
+			UnlockKey:   "recovery",
+			Fallback:    true,
+		}, {
+			Name:        "ubuntu-save",
+			UnlockState: "unlocked",
+			UnlockKey:   "run",
+		}},
+		Actions: []string{
+			`ubuntu-data was unlocked with the recovery key: the sealed keys no longer match the boot chain, check "snap debug boot" and consider replacing the recovery key`,
+		},
+	})
+}
+
+func (s *recoveryDebugSuite) TestNoState(c *C) {
+	rsp := s.errorReq(c, s.getRecoveryReq(c), nil, actionIsExpected)
+	c.Check(rsp.Status, Equals, 404)
+	c.Check(rsp.Message, Equals, "cannot report recovery status: no disk unlock state was recorded during boot")
+}
+
+func (s *recoveryDebugSuite) TestInvalidState(c *C) {
+	s.writeState(c, "degraded.json", `{`)
+
+	rsp := s.errorReq(c, s.getRecoveryReq(c), nil, actionIsExpected)
+	c.Check(rsp.Status, Equals, 500)
+	c.Check(rsp.Message, Matches, "cannot read disk unlock state: .*")
+}
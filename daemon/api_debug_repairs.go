@@ -0,0 +1,52 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon
+
+import (
+	"github.com/snapcore/snapd/cmd/snap-repair/repairtrace"
+)
+
+// getRepairs reports the repairs that snap-repair ran on the device. When
+// a repair in the form $brand-$seq is given only its runs are reported,
+// including the script that was run and its output.
+func getRepairs(repair string) Response {
+	var traces []*repairtrace.Trace
+	var err error
+	if repair == "" {
+		traces, err = repairtrace.Find("*", "*")
+	} else {
+		traces, err = repairtrace.FindRepair(repair)
+		if err != nil {
+			return BadRequest("%v", err)
+		}
+	}
+	if err != nil {
+		return InternalError("cannot list repairs: %v", err)
+	}
+	if repair != "" && len(traces) == 0 {
+		return NotFound("cannot find repair %q", repair)
+	}
+
+	infos := make([]*repairtrace.Info, 0, len(traces))
+	for _, t := range traces {
+		infos = append(infos, t.Info(repair != ""))
+	}
+	return SyncResponse(infos)
+}
@@ -0,0 +1,113 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/cmd/snap-repair/repairtrace"
+	"github.com/snapcore/snapd/dirs"
+)
+
+var _ = Suite(&repairsDebugSuite{})
+
+type repairsDebugSuite struct {
+	apiBaseSuite
+}
+
+func (s *repairsDebugSuite) SetUpTest(c *C) {
+	s.apiBaseSuite.SetUpTest(c)
+	s.daemonWithOverlordMock()
+}
+
+func (s *repairsDebugSuite) writeTrace(c *C, brand, seq, name, content string) {
+	basedir := filepath.Join(dirs.SnapRepairRunDir, brand, seq)
+	c.Assert(os.MkdirAll(basedir, 0700), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(basedir, name), []byte(content), 0600), IsNil)
+}
+
+func (s *repairsDebugSuite) mockRepairs(c *C) {
+	s.writeTrace(c, "canonical", "1", "r0.retry", "repair: canonical-1\nsummary: repair one\noutput:\nfailed\n")
+	s.writeTrace(c, "canonical", "1", "r0.script", "#!/bin/sh\nexit 1\n")
+	s.writeTrace(c, "canonical", "1", "r1.done", "repair: canonical-1\nsummary: repair one\noutput:\nfixed\n")
+	s.writeTrace(c, "canonical", "1", "r1.script", "#!/bin/sh\necho fixed\n")
+	s.writeTrace(c, "my-brand", "2", "r3.skip", "repair: my-brand-2\nsummary: repair two\noutput:\n")
+}
+
+func (s *repairsDebugSuite) getRepairsReq(c *C, query string) *http.Request {
+	req, err := http.NewRequest("GET", "/v2/debug?aspect=repairs"+query, nil)
+	c.Assert(err, IsNil)
+	return req
+}
+
+func (s *repairsDebugSuite) TestRepairs(c *C) {
+	s.mockRepairs(c)
+
+	rsp := s.syncReq(c, s.getRepairsReq(c, ""), nil, actionIsExpected)
+	c.Check(rsp.Result, DeepEquals, []*repairtrace.Info{
+		{Repair: "canonical-1", Revision: 0, Status: "retry", Summary: "repair one"},
+		{Repair: "canonical-1", Revision: 1, Status: "done", Summary: "repair one"},
+		{Repair: "my-brand-2", Revision: 3, Status: "skip", Summary: "repair two"},
+	})
+}
+
+func (s *repairsDebugSuite) TestRepairsNone(c *C) {
+	rsp := s.syncReq(c, s.getRepairsReq(c, ""), nil, actionIsExpected)
+	c.Check(rsp.Result, DeepEquals, []*repairtrace.Info{})
+}
+
+func (s *repairsDebugSuite) TestRepairWithLogs(c *C) {
+	s.mockRepairs(c)
+
+	rsp := s.syncReq(c, s.getRepairsReq(c, "&repair=canonical-1"), nil, actionIsExpected)
+	c.Check(rsp.Result, DeepEquals, []*repairtrace.Info{{
+		Repair:   "canonical-1",
+		Revision: 0,
+		Status:   "retry",
+		Summary:  "repair one",
+		Script:   "#!/bin/sh\nexit 1\n",
+		Output:   "failed\n",
+	}, {
+		Repair:   "canonical-1",
+		Revision: 1,
+		Status:   "done",
+		Summary:  "repair one",
+		Script:   "#!/bin/sh\necho fixed\n",
+		Output:   "fixed\n",
+	}})
+}
+
+func (s *repairsDebugSuite) TestRepairNotFound(c *C) {
+	s.mockRepairs(c)
+
+	rsp := s.errorReq(c, s.getRepairsReq(c, "&repair=canonical-2"), nil, actionIsExpected)
+	c.Check(rsp.Status, Equals, 404)
+	c.Check(rsp.Message, Equals, `cannot find repair "canonical-2"`)
+}
+
+func (s *repairsDebugSuite) TestRepairInvalid(c *C) {
+	rsp := s.errorReq(c, s.getRepairsReq(c, "&repair=canonical-*"), nil, actionIsExpected)
+	c.Check(rsp.Status, Equals, 400)
+	c.Check(rsp.Message, Equals, `cannot parse repair "canonical-*"`)
+}
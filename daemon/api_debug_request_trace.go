@@ -0,0 +1,287 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/systemd"
+)
+
+// Sources of the entries in a request trace.
+const (
+	traceSourceChange  = "change"
+	traceSourceTask    = "task"
+	traceSourceHook    = "hook"
+	traceSourceRestart = "restart"
+	traceSourceDaemon  = "daemon"
+)
+
+type requestTraceEntry struct {
+	Time    time.Time `json:"time"`
+	Source  string    `json:"source"`
+	TaskID  string    `json:"task-id,omitempty"`
+	Level   string    `json:"level,omitempty"`
+	Message string    `json:"message"`
+}
+
+type requestTrace struct {
+	ChangeID  string               `json:"change-id"`
+	Kind      string               `json:"kind"`
+	Summary   string               `json:"summary"`
+	Status    string               `json:"status"`
+	RequestID string               `json:"request-id,omitempty"`
+	Entries   []*requestTraceEntry `json:"entries"`
+}
+
+// journalSlack is how far past the ready time of a change the journal of
+// snapd is searched, to catch what was logged while the change was wrapping
+// up.
+const journalSlack = time.Minute
+
+// snapdJournal returns the JSON formatted journal entries of snapd logged
+// between the given times.
+var snapdJournal = func(since, until time.Time) (io.ReadCloser, error) {
+	return osutil.StreamCommand("journalctl", "-o", "json", "--no-pager",
+		"-u", "snapd.service",
+		"--since", fmt.Sprintf("@%d", since.Unix()),
+		"--until", fmt.Sprintf("@%d", until.Unix()))
+}
+
+var restartRx = regexp.MustCompile(`(?i)\b(restart|reboot)`)
+
+// getRequestTrace assembles a single timeline for the given change out of
+// the lifecycle of the change and its tasks, the task logs, which carry the
+// output of failing hooks, the restart markers and the entries in the
+// journal of snapd that refer to the change or to the request that created
+// it.
+//
+// The state must be locked by the caller, it is unlocked while the journal
+// is read.
+func getRequestTrace(st *state.State, changeID string) Response {
+	if changeID == "" {
+		return BadRequest("change-id is required")
+	}
+	chg := st.Change(changeID)
+	if chg == nil {
+		return NotFound("cannot find change with id %q", changeID)
+	}
+
+	trace := &requestTrace{
+		ChangeID: chg.ID(),
+		Kind:     chg.Kind(),
+		Summary:  chg.Summary(),
+		Status:   chg.Status().String(),
+	}
+	// a missing request ID only means the change was not created
+	// through the API
+	chg.Get("request-id", &trace.RequestID)
+
+	trace.Entries = append(trace.Entries, &requestTraceEntry{
+		Time:    chg.SpawnTime(),
+		Source:  traceSourceChange,
+		Message: fmt.Sprintf("Change %q spawned", chg.Summary()),
+	})
+	for _, t := range chg.Tasks() {
+		trace.Entries = append(trace.Entries, taskTraceEntries(t)...)
+	}
+	until := time.Now()
+	if !chg.ReadyTime().IsZero() {
+		trace.Entries = append(trace.Entries, &requestTraceEntry{
+			Time:    chg.ReadyTime(),
+			Source:  traceSourceChange,
+			Message: fmt.Sprintf("Change ready with status %s", chg.Status()),
+		})
+		until = chg.ReadyTime().Add(journalSlack)
+	}
+	since := chg.SpawnTime()
+
+	// reading the journal can take a while, do not hold the state
+	st.Unlock()
+	journalEntries, err := snapdJournalTraceEntries(since, until, trace.ChangeID, trace.RequestID)
+	st.Lock()
+	if err != nil {
+		// the rest of the trace is still useful without the journal
+		logger.Noticef("cannot read the journal of snapd for change %s: %v", changeID, err)
+	}
+	trace.Entries = append(trace.Entries, journalEntries...)
+
+	for _, e := range trace.Entries {
+		e.Time = e.Time.UTC()
+	}
+	sort.SliceStable(trace.Entries, func(i, j int) bool {
+		return trace.Entries[i].Time.Before(trace.Entries[j].Time)
+	})
+
+	return SyncResponse(trace)
+}
+
+func taskTraceEntries(t *state.Task) []*requestTraceEntry {
+	entries := []*requestTraceEntry{{
+		Time:    t.SpawnTime(),
+		Source:  traceSourceTask,
+		TaskID:  t.ID(),
+		Message: fmt.Sprintf("Task %q (%s) spawned", t.Summary(), t.Kind()),
+	}}
+
+	logSource := traceSourceTask
+	if t.Kind() == "run-hook" {
+		logSource = traceSourceHook
+	}
+	for _, line := range t.Log() {
+		// log entries are "<RFC3339 time> <INFO|ERROR> <message>"
+		fields := strings.SplitN(line, " ", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		tm, err := time.Parse(time.RFC3339, fields[0])
+		if err != nil {
+			continue
+		}
+		source := logSource
+		if restartRx.MatchString(fields[2]) {
+			source = traceSourceRestart
+		}
+		entries = append(entries, &requestTraceEntry{
+			Time:    tm,
+			Source:  source,
+			TaskID:  t.ID(),
+			Level:   fields[1],
+			Message: fields[2],
+		})
+	}
+
+	if !t.ReadyTime().IsZero() {
+		entries = append(entries, &requestTraceEntry{
+			Time:    t.ReadyTime(),
+			Source:  traceSourceTask,
+			TaskID:  t.ID(),
+			Message: fmt.Sprintf("Task ready with status %s", t.Status()),
+		})
+	}
+	return entries
+}
+
+// snapdJournalTraceEntries returns the entries of the journal of snapd
+// between the given times that refer to the given change or request, along
+// with markers for every start of snapd and every boot.
+func snapdJournalTraceEntries(since, until time.Time, changeID, requestID string) ([]*requestTraceEntry, error) {
+	r, err := snapdJournal(since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*requestTraceEntry
+	var lastBootID string
+	dec := json.NewDecoder(r)
+	for {
+		var l systemd.Log
+		if err := dec.Decode(&l); err != nil {
+			if err == io.EOF {
+				break
+			}
+			r.Close()
+			return entries, fmt.Errorf("cannot decode journal entry: %v", err)
+		}
+		tm, err := l.Time()
+		if err != nil {
+			continue
+		}
+
+		var bootID string
+		if raw := l["_BOOT_ID"]; raw != nil {
+			json.Unmarshal(*raw, &bootID)
+		}
+		if bootID != lastBootID {
+			if lastBootID != "" {
+				entries = append(entries, &requestTraceEntry{
+					Time:    tm,
+					Source:  traceSourceRestart,
+					Message: fmt.Sprintf("System booted (boot id %s)", bootID),
+				})
+			}
+			lastBootID = bootID
+		}
+
+		msg, attrs := parseJournalMessage(l.Message())
+		switch {
+		case strings.HasPrefix(msg, "started snapd/"):
+			entries = append(entries, &requestTraceEntry{
+				Time:    tm,
+				Source:  traceSourceRestart,
+				Message: msg,
+			})
+		case attrs["change"] == changeID || (requestID != "" && attrs["request-id"] == requestID):
+			entries = append(entries, &requestTraceEntry{
+				Time:    tm,
+				Source:  traceSourceDaemon,
+				Message: msg,
+			})
+		}
+	}
+	return entries, r.Close()
+}
+
+var logAttrRx = regexp.MustCompile(`(?:^|\s)([a-z-]+)=("(?:[^"\\]|\\.)*"|\S*)`)
+
+// parseJournalMessage splits a message logged by snapd into the message
+// proper and its key/value attributes, for both the plain and the
+// structured logger.
+func parseJournalMessage(msg string) (string, map[string]string) {
+	attrs := make(map[string]string)
+
+	if strings.HasPrefix(msg, "{") {
+		var structured map[string]any
+		if err := json.Unmarshal([]byte(msg), &structured); err == nil {
+			for k, v := range structured {
+				if s, ok := v.(string); ok {
+					attrs[k] = s
+				}
+			}
+			return attrs["msg"], attrs
+		}
+	}
+
+	for _, m := range logAttrRx.FindAllStringSubmatch(msg, -1) {
+		v := m[2]
+		if strings.HasPrefix(v, `"`) {
+			if unquoted, err := strconv.Unquote(v); err == nil {
+				v = unquoted
+			}
+		}
+		attrs[m[1]] = v
+	}
+	// the plain logger prefixes messages with the location they were
+	// logged at, e.g. "daemon.go:329: started snapd/..."
+	if idx := strings.Index(msg, ": "); idx > 0 && !strings.ContainsAny(msg[:idx], " \t") {
+		msg = msg[idx+2:]
+	}
+	return msg, attrs
+}
@@ -0,0 +1,165 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/daemon"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+var _ = Suite(&requestTraceDebugSuite{})
+
+type requestTraceDebugSuite struct {
+	apiBaseSuite
+}
+
+func (s *requestTraceDebugSuite) SetUpTest(c *C) {
+	s.apiBaseSuite.SetUpTest(c)
+	s.daemonWithOverlordMock()
+}
+
+var traceBase = time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC)
+
+func traceTime(secs int) time.Time {
+	return traceBase.Add(time.Duration(secs) * time.Second)
+}
+
+func journalLine(secs int, bootID, msg string) string {
+	return fmt.Sprintf(`{"__REALTIME_TIMESTAMP":"%d","_BOOT_ID":%q,"MESSAGE":%q}`+"\n",
+		traceTime(secs).UnixNano()/1000, bootID, msg)
+}
+
+func (s *requestTraceDebugSuite) mockChange(c *C) {
+	st := s.d.Overlord().State()
+	st.Lock()
+	defer st.Unlock()
+
+	restore := state.MockTime(traceTime(0))
+	defer restore()
+	chg := st.NewChange("refresh-snap", "Refresh snap \"foo\"")
+	chg.Set("request-id", "req-1")
+	t1 := st.NewTask("link-snap", "Make snap \"foo\" available")
+	chg.AddTask(t1)
+	t2 := st.NewTask("run-hook", "Run post-refresh hook of \"foo\" snap")
+	t2.WaitFor(t1)
+	chg.AddTask(t2)
+
+	state.MockTime(traceTime(2))
+	t1.Logf("Task has requested a system restart")
+	state.MockTime(traceTime(3))
+	t1.SetStatus(state.DoneStatus)
+
+	state.MockTime(traceTime(20))
+	t2.Errorf("run hook \"post-refresh\": boom")
+	state.MockTime(traceTime(21))
+	t2.SetStatus(state.ErrorStatus)
+}
+
+func (s *requestTraceDebugSuite) getRequestTraceReq(c *C, query string) *http.Request {
+	req, err := http.NewRequest("GET", "/v2/debug?aspect=request-trace"+query, nil)
+	c.Assert(err, IsNil)
+	return req
+}
+
+func (s *requestTraceDebugSuite) TestRequestTrace(c *C) {
+	s.mockChange(c)
+
+	var since, until time.Time
+	restore := daemon.MockSnapdJournal(func(s, u time.Time) (io.ReadCloser, error) {
+		since, until = s, u
+		return io.NopCloser(strings.NewReader(
+			journalLine(1, "boot-1", `daemon.go:299: 127.0.0.1 POST /v2/snaps/foo 1ms 202 request-id=req-1`) +
+				journalLine(1, "boot-1", `api.go:12: unrelated request-id=req-2`) +
+				journalLine(2, "boot-1", `taskrunner.go:1: Running task 1 on Do: link-snap change=1 change-kind=refresh-snap task=1`) +
+				journalLine(2, "boot-1", `taskrunner.go:1: Running task 7 on Do: other change=11 change-kind=install task=7`) +
+				journalLine(10, "boot-2", `daemon.go:329: started snapd/2.70 (series 16; classic).`) +
+				journalLine(19, "boot-2", `{"time":"x","level":"DEBUG","msg":"Running task 2 on Do: run-hook","change":"1","task":"2"}`),
+		)), nil
+	})
+	defer restore()
+
+	rsp := s.syncReq(c, s.getRequestTraceReq(c, "&change-id=1"), nil, actionIsExpected)
+	c.Check(since.Equal(traceTime(0)), Equals, true)
+	c.Check(until.Equal(traceTime(21).Add(time.Minute)), Equals, true)
+
+	c.Check(rsp.Result, DeepEquals, &daemon.RequestTrace{
+		ChangeID:  "1",
+		Kind:      "refresh-snap",
+		Summary:   `Refresh snap "foo"`,
+		Status:    "Error",
+		RequestID: "req-1",
+		Entries: []*daemon.RequestTraceEntry{
+			{Time: traceTime(0), Source: "change", Message: `Change "Refresh snap \"foo\"" spawned`},
+			{Time: traceTime(0), Source: "task", TaskID: "1", Message: `Task "Make snap \"foo\" available" (link-snap) spawned`},
+			{Time: traceTime(0), Source: "task", TaskID: "2", Message: `Task "Run post-refresh hook of \"foo\" snap" (run-hook) spawned`},
+			{Time: traceTime(1), Source: "daemon", Message: "127.0.0.1 POST /v2/snaps/foo 1ms 202 request-id=req-1"},
+			{Time: traceTime(2), Source: "restart", TaskID: "1", Level: "INFO", Message: "Task has requested a system restart"},
+			{Time: traceTime(2), Source: "daemon", Message: "Running task 1 on Do: link-snap change=1 change-kind=refresh-snap task=1"},
+			{Time: traceTime(3), Source: "task", TaskID: "1", Message: "Task ready with status Done"},
+			{Time: traceTime(10), Source: "restart", Message: "System booted (boot id boot-2)"},
+			{Time: traceTime(10), Source: "restart", Message: "started snapd/2.70 (series 16; classic)."},
+			{Time: traceTime(19), Source: "daemon", Message: "Running task 2 on Do: run-hook"},
+			{Time: traceTime(20), Source: "hook", TaskID: "2", Level: "ERROR", Message: `run hook "post-refresh": boom`},
+			{Time: traceTime(21), Source: "task", TaskID: "2", Message: "Task ready with status Error"},
+			{Time: traceTime(21), Source: "change", Message: "Change ready with status Error"},
+		},
+	})
+}
+
+func (s *requestTraceDebugSuite) TestRequestTraceJournalError(c *C) {
+	logbuf, restore := logger.MockLogger()
+	defer restore()
+	s.mockChange(c)
+
+	restore = daemon.MockSnapdJournal(func(since, until time.Time) (io.ReadCloser, error) {
+		return nil, errors.New("no journal")
+	})
+	defer restore()
+
+	rsp := s.syncReq(c, s.getRequestTraceReq(c, "&change-id=1"), nil, actionIsExpected)
+	trace := rsp.Result.(*daemon.RequestTrace)
+	c.Check(trace.Entries, HasLen, 8)
+	for _, e := range trace.Entries {
+		c.Check(e.Source, Not(Equals), "daemon")
+	}
+	c.Check(logbuf.String(), Matches, `(?s).*cannot read the journal of snapd for change 1: no journal.*`)
+}
+
+func (s *requestTraceDebugSuite) TestRequestTraceNotFound(c *C) {
+	rsp := s.errorReq(c, s.getRequestTraceReq(c, "&change-id=42"), nil, actionIsExpected)
+	c.Check(rsp.Status, Equals, 404)
+	c.Check(rsp.Message, Equals, `cannot find change with id "42"`)
+}
+
+func (s *requestTraceDebugSuite) TestRequestTraceMissingChangeID(c *C) {
+	rsp := s.errorReq(c, s.getRequestTraceReq(c, ""), nil, actionIsExpected)
+	c.Check(rsp.Status, Equals, 400)
+	c.Check(rsp.Message, Equals, "change-id is required")
+}
@@ -26,11 +26,19 @@ import (
 	"errors"
 	"net/http"
 	"strings"
+	"time"
 
 	"gopkg.in/check.v1"
 
+	"github.com/snapcore/snapd/boot"
+	"github.com/snapcore/snapd/bootloader"
 	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/daemon"
+	"github.com/snapcore/snapd/gadget/device"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+	"github.com/snapcore/snapd/overlord/fdestate"
+	"github.com/snapcore/snapd/overlord/ifacestate/udevmonitor"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/testutil"
@@ -244,6 +252,65 @@ func (s *postDebugSuite) TestMinLane(c *check.C) {
 	c.Check(t.Lanes(), check.DeepEquals, []int{lane1, lane2})
 }
 
+func (s *postDebugSuite) TestSecurityBackendTimings(c *check.C) {
+	doing := []*timings.TimingJSON{
+		{Label: "setup-security-backend", Summary: `setup security backend "apparmor" for snap "foo"`, Duration: 10 * time.Millisecond},
+		{Level: 1, Label: "load-profiles", Summary: "...", Duration: 8 * time.Millisecond},
+		{Label: "setup-security-backend", Summary: `setup security backend "seccomp" for snap "foo"`, Duration: 3 * time.Millisecond},
+		{Label: "setup-security-backend[many]", Summary: `setup security backend "apparmor" for 2 snaps`, Duration: 5 * time.Millisecond},
+		{Label: "other", Summary: `security backend "bogus"`, Duration: time.Second},
+	}
+	undoing := []*timings.TimingJSON{
+		{Label: "delayed-setup-security-backend", Summary: `delayed setup security backend "seccomp" effects for snap "foo"`, Duration: 2 * time.Millisecond},
+		{Label: "reinitialize-security-backend", Summary: `reinitialize "apparmor" security backend`, Duration: 4 * time.Millisecond},
+	}
+
+	c.Check(daemon.SecurityBackendTimings(doing, undoing), check.DeepEquals, map[string]time.Duration{
+		"apparmor": 19 * time.Millisecond,
+		"seccomp":  5 * time.Millisecond,
+	})
+	c.Check(daemon.SecurityBackendTimings(nil, nil), check.IsNil)
+}
+
+func (s *postDebugSuite) TestGetDebugTimingsSecurityBackends(c *check.C) {
+	defer mockDurationThreshold()()
+
+	s.daemonWithOverlordMock()
+
+	st := s.d.Overlord().State()
+	st.Lock()
+	chg := st.NewChange("foo", "...")
+	task := st.NewTask("bar", "...")
+	chg.AddTask(task)
+	task.SetStatus(state.DoingStatus)
+
+	// record the timings the way the interface manager does
+	appSet := ifacetest.MockSnapAndAppSet(c, "name: foo\nversion: 1\n", nil, &snap.SideInfo{Revision: snap.R(1)})
+	backend := &ifacetest.TestSecurityBackend{BackendName: "apparmor"}
+	tm := state.TimingsForTask(task)
+	errs := interfaces.SetupMany(interfaces.NewRepository(), backend, []*interfaces.SnapAppSet{appSet},
+		func(string) interfaces.ConfinementOptions { return interfaces.ConfinementOptions{} },
+		func(string) interfaces.SetupContext { return interfaces.SetupContext{} },
+		tm)
+	c.Assert(errs, check.HasLen, 0)
+	tm.Save(st)
+	st.Unlock()
+
+	req, err := http.NewRequest("GET", "/v2/debug?aspect=change-timings&change-id="+chg.ID(), nil)
+	c.Assert(err, check.IsNil)
+	rsp := s.syncReq(c, req, nil, actionIsExpected)
+	data, err := json.Marshal(rsp.Result)
+	c.Assert(err, check.IsNil)
+	var dataJSON []map[string]any
+	c.Assert(json.Unmarshal(data, &dataJSON), check.IsNil)
+
+	c.Assert(dataJSON, check.HasLen, 1)
+	chgTimings := dataJSON[0]["change-timings"].(map[string]any)
+	taskTimings := chgTimings[task.ID()].(map[string]any)
+	c.Check(taskTimings["security-backend-timings"], check.NotNil)
+	c.Check(taskTimings["security-backend-timings"].(map[string]any)["apparmor"], check.NotNil)
+}
+
 func (s *postDebugSuite) TestMigrateHome(c *check.C) {
 	d := s.daemonWithOverlordMock()
 	s.expectRootAccess()
@@ -409,3 +476,73 @@ func (s *postDebugSuite) TestRefreshAppAwarenessUnhappy(c *check.C) {
 	c.Check(rsp.Status, check.Equals, 500)
 	c.Check(rsp.Message, check.Equals, "boom!")
 }
+
+func (s *postDebugSuite) TestGetDebugBootStatus(c *check.C) {
+	s.daemonWithOverlordMock()
+
+	status := &fdestate.BootStatus{
+		SealingMethod: device.SealingMethodTPM,
+		RunBootChains: fdestate.SealedBootChains{
+			BootChains: boot.PredictableBootChains{
+				{
+					BrandID: "my-brand",
+					Model:   "my-model",
+					AssetChain: []boot.BootAsset{
+						{Role: bootloader.RoleRecovery, Name: "shim", Hashes: []string{"x"}},
+					},
+					Kernel:         "pc-kernel",
+					KernelRevision: "1",
+					KernelCmdlines: []string{"snapd_recovery_mode=run"},
+				},
+			},
+			ResealCount: 2,
+		},
+		LastReseal: &fdestate.ResealStatus{
+			Time: time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC),
+		},
+		PendingResealReasons: []string{"run boot chains differ from the expected ones"},
+	}
+	restore := daemon.MockFdestateGetBootStatus(func(st *state.State) (*fdestate.BootStatus, error) {
+		return status, nil
+	})
+	defer restore()
+
+	req, err := http.NewRequest("GET", "/v2/debug?aspect=boot", nil)
+	c.Assert(err, check.IsNil)
+
+	rsp := s.syncReq(c, req, nil, actionIsExpected)
+	c.Check(rsp.Result, check.Equals, status)
+}
+
+func (s *postDebugSuite) TestGetDebugBootStatusErrors(c *check.C) {
+	s.daemonWithOverlordMock()
+
+	var getErr error
+	restore := daemon.MockFdestateGetBootStatus(func(st *state.State) (*fdestate.BootStatus, error) {
+		return nil, getErr
+	})
+	defer restore()
+
+	req, err := http.NewRequest("GET", "/v2/debug?aspect=boot", nil)
+	c.Assert(err, check.IsNil)
+
+	getErr = device.ErrNoSealedKeys
+	rsp := s.errorReq(c, req, nil, actionIsExpected)
+	c.Check(rsp.Status, check.Equals, 400)
+	c.Check(rsp.Message, check.Equals, "cannot report boot status: system has no sealed disk encryption keys")
+
+	getErr = errors.New("boom")
+	rsp = s.errorReq(c, req, nil, actionIsExpected)
+	c.Check(rsp.Status, check.Equals, 500)
+	c.Check(rsp.Message, check.Equals, "cannot get boot status: boom")
+}
+
+func (s *postDebugSuite) TestGetDebugUDevMonitorNotRunning(c *check.C) {
+	s.daemon(c)
+
+	req, err := http.NewRequest("GET", "/v2/debug?aspect=udev-monitor", nil)
+	c.Assert(err, check.IsNil)
+
+	rsp := s.syncReq(c, req, nil, actionIsExpected)
+	c.Check(rsp.Result, check.DeepEquals, udevmonitor.Health{})
+}
@@ -50,6 +50,7 @@ func searchStore(c *Command, r *http.Request, user *auth.UserState) Response {
 	category := query.Get("category")
 	name := query.Get("name")
 	scope := query.Get("scope")
+	channelMap := query.Get("channel-map")
 	private := false
 	prefix := false
 
@@ -80,7 +81,7 @@ func searchStore(c *Command, r *http.Request, user *auth.UserState) Response {
 		}
 
 		if name[len(name)-1] != '*' {
-			return findOne(c, r, user, name)
+			return findOne(c, r, user, name, channelMap == "true")
 		}
 
 		prefix = true
@@ -91,6 +92,10 @@ func searchStore(c *Command, r *http.Request, user *auth.UserState) Response {
 		return BadRequest("cannot use 'common-id' and 'q' together")
 	}
 
+	if channelMap != "" {
+		return BadRequest("cannot use 'channel-map' without the exact 'name' of a snap")
+	}
+
 	if section != "" && category != "" {
 		return BadRequest("cannot use 'section' and 'category' together")
 	}
@@ -154,14 +159,15 @@ func searchStore(c *Command, r *http.Request, user *auth.UserState) Response {
 	return sendStorePackages(found, fresp)
 }
 
-func findOne(c *Command, r *http.Request, user *auth.UserState, name string) Response {
+func findOne(c *Command, r *http.Request, user *auth.UserState, name string, channelMap bool) Response {
 	if err := snap.ValidateName(name); err != nil {
 		return BadRequest(err.Error())
 	}
 
 	theStore := storeFrom(c.d)
 	spec := store.SnapSpec{
-		Name: name,
+		Name:       name,
+		ChannelMap: channelMap,
 	}
 	ctx := store.WithClientUserAgent(r.Context(), r)
 	snapInfo, err := theStore.SnapInfo(ctx, spec, user)
@@ -371,6 +371,49 @@ func (s *findSuite) TestFindOne(c *check.C) {
 	c.Check(m["revision"], check.Equals, "42")
 }
 
+func (s *findSuite) TestFindOneChannelMap(c *check.C) {
+	s.daemon(c)
+
+	s.rsnaps = []*snap.Info{{
+		SideInfo: snap.SideInfo{
+			RealName: "store",
+		},
+		ChannelMap: []*snap.ChannelSnapInfo{
+			{Architecture: "amd64", Channel: "latest/stable", Revision: snap.R(42)},
+			{Architecture: "arm64", Channel: "latest/stable/fix", Revision: snap.R(43)},
+		},
+	}}
+	s.mockSnap(c, "name: store\nversion: 1.0")
+
+	req, err := http.NewRequest("GET", "/v2/find?name=foo&channel-map=true", nil)
+	c.Assert(err, check.IsNil)
+
+	rsp := s.syncReq(c, req, nil, actionIsExpected)
+
+	c.Check(s.storeSnapSpec, check.DeepEquals, store.SnapSpec{Name: "foo", ChannelMap: true})
+
+	snaps := snapList(rsp.Result)
+	c.Assert(snaps, check.HasLen, 1)
+	channelMap := snaps[0]["channel-map"].([]any)
+	c.Assert(channelMap, check.HasLen, 2)
+	c.Check(channelMap[1].(map[string]any)["architecture"], check.Equals, "arm64")
+	c.Check(channelMap[1].(map[string]any)["channel"], check.Equals, "latest/stable/fix")
+	c.Check(channelMap[1].(map[string]any)["revision"], check.Equals, "43")
+}
+
+func (s *findSuite) TestFindChannelMapNeedsExactName(c *check.C) {
+	s.daemon(c)
+
+	for _, query := range []string{"q=foo", "name=foo*", "common-id=foo"} {
+		req, err := http.NewRequest("GET", "/v2/find?channel-map=true&"+query, nil)
+		c.Assert(err, check.IsNil)
+
+		rspe := s.errorReq(c, req, nil, actionIsExpected)
+		c.Check(rspe.Status, check.Equals, 400)
+		c.Check(rspe.Message, check.Equals, "cannot use 'channel-map' without the exact 'name' of a snap")
+	}
+}
+
 func (s *findSuite) TestFindOneNotFound(c *check.C) {
 	s.daemon(c)
 
@@ -21,10 +21,12 @@ package daemon
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"sort"
+	"strconv"
 	"sync"
 	"time"
 
@@ -41,6 +43,7 @@ import (
 	"github.com/snapcore/snapd/overlord/fdestate"
 	"github.com/snapcore/snapd/overlord/hookstate/ctlcmd"
 	"github.com/snapcore/snapd/overlord/ifacestate"
+	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/release"
 	"github.com/snapcore/snapd/sandbox"
@@ -149,6 +152,26 @@ func sysInfo(c *Command, r *http.Request, user *auth.UserState) Response {
 	if err != nil {
 		return InternalError("cannot get refresh schedule: %s", err)
 	}
+	downloadsDeferred, err := snapMgr.DownloadsDeferredUntil()
+	if err != nil {
+		return InternalError("cannot get download window: %s", err)
+	}
+	refreshStagger, staggerOffset, err := snapMgr.RefreshStagger()
+	if err != nil {
+		return InternalError("cannot get refresh stagger: %s", err)
+	}
+	refreshBackoffs, err := snapMgr.RefreshBackoffs()
+	if err != nil {
+		return InternalError("cannot get refresh backoffs: %s", err)
+	}
+	refreshHolds, err := snapMgr.SystemRefreshHolds()
+	if err != nil {
+		return InternalError("cannot get refresh holds: %s", err)
+	}
+	var downloadWindow string
+	if err := tr.Get("core", "store.download-window", &downloadWindow); err != nil && !config.IsNoOption(err) {
+		return InternalError("cannot get download window: %s", err)
+	}
 	users, err := auth.Users(st)
 	if err != nil && !errors.Is(err, state.ErrNoState) {
 		return InternalError("cannot get user auth data: %s", err)
@@ -165,9 +188,36 @@ func sysInfo(c *Command, r *http.Request, user *auth.UserState) Response {
 	}
 
 	refreshInfo := client.RefreshInfo{
-		Last: formatRefreshTime(lastRefresh),
-		Hold: formatRefreshTime(refreshHold),
-		Next: formatRefreshTime(nextRefresh),
+		Last:              formatRefreshTime(lastRefresh),
+		Hold:              formatRefreshTime(refreshHold),
+		Next:              formatRefreshTime(nextRefresh),
+		DownloadWindow:    downloadWindow,
+		DownloadsDeferred: formatRefreshTime(downloadsDeferred),
+		Stagger:           refreshStagger,
+	}
+	if staggerOffset > 0 {
+		refreshInfo.StaggerOffset = staggerOffset.Round(time.Second).String()
+	}
+	for _, backoff := range refreshBackoffs {
+		refreshInfo.Backoff = append(refreshInfo.Backoff, client.RefreshBackoff{
+			Snap:      backoff.InstanceName,
+			Revision:  backoff.Revision,
+			Failures:  backoff.FailureCount,
+			LastError: backoff.LastError,
+			Until:     formatRefreshTime(backoff.Until),
+		})
+	}
+	for _, hold := range refreshHolds {
+		level := "auto-refresh"
+		if hold.Level == snapstate.HoldGeneral {
+			level = "general"
+		}
+		refreshInfo.Holds = append(refreshInfo.Holds, client.RefreshHold{
+			Snap:    hold.InstanceName,
+			Level:   level,
+			Until:   formatRefreshTime(hold.Until),
+			Windows: hold.Windows,
+		})
 	}
 	if !legacySchedule {
 		refreshInfo.Timer = refreshScheduleStr
@@ -299,8 +349,23 @@ func sandboxFeatures(backends []interfaces.SecurityBackend) map[string][]string
 	return result
 }
 
+// changeInfoWithUpdate is the information about a single change, along
+// with the identifier of its last update which can be used to wait for the
+// next one.
+type changeInfoWithUpdate struct {
+	*ctlcmd.ChangeInfo
+	UpdateID string `json:"update-id"`
+}
+
 func getChange(c *Command, r *http.Request, user *auth.UserState) Response {
 	chID := muxVars(r)["id"]
+	query := r.URL.Query()
+	lastUpdateID := query.Get("update-id")
+	timeout, err := parseOptionalDuration(query.Get("timeout"))
+	if err != nil {
+		return BadRequest("invalid timeout: %v", err)
+	}
+
 	state := c.d.overlord.State()
 	state.Lock()
 	defer state.Unlock()
@@ -309,7 +374,36 @@ func getChange(c *Command, r *http.Request, user *auth.UserState) Response {
 		return NotFound("cannot find change with id %q", chID)
 	}
 
-	return SyncResponse(ctlcmd.StateChangeToChangeInfo(chg))
+	if timeout != 0 && lastUpdateID == strconv.Itoa(chg.UpdateID()) && !chg.IsReady() {
+		// Wait up to timeout for the change to be updated. Use daemon's
+		// tomb context so that the request will get canceled as well
+		// when the tomb gets killed when shutting down the daemon
+		ctx, cancel := context.WithTimeout(c.d.tomb.Context(r.Context()), timeout)
+		defer cancel()
+
+		updated := chg.Updated()
+		state.Unlock()
+		select {
+		case <-updated:
+		case <-ctx.Done():
+		}
+		state.Lock()
+		// DeadlineExceeded will occur if timeout elapses; in that case
+		// return the change as is, not an error.
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return InternalError("request canceled")
+		}
+		// the change might have been pruned in the meantime
+		chg = state.Change(chID)
+		if chg == nil {
+			return NotFound("cannot find change with id %q", chID)
+		}
+	}
+
+	return SyncResponse(&changeInfoWithUpdate{
+		ChangeInfo: ctlcmd.StateChangeToChangeInfo(chg),
+		UpdateID:   strconv.Itoa(chg.UpdateID()),
+	})
 }
 
 func getChanges(c *Command, r *http.Request, user *auth.UserState) Response {
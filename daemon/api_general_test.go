@@ -21,6 +21,7 @@ package daemon_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -28,6 +29,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strconv"
 	"time"
 
 	"gopkg.in/check.v1"
@@ -35,6 +37,7 @@ import (
 	"github.com/snapcore/snapd/arch"
 	"github.com/snapcore/snapd/asserts"
 	"github.com/snapcore/snapd/boot"
+	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/daemon"
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/dirs/dirstest"
@@ -50,11 +53,13 @@ import (
 	"github.com/snapcore/snapd/overlord/hookstate/ctlcmd"
 	"github.com/snapcore/snapd/overlord/install"
 	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/snapstate/snapstatetest"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/release"
 	"github.com/snapcore/snapd/sandbox"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/systemd"
+	"github.com/snapcore/snapd/testutil"
 )
 
 var _ = check.Suite(&generalSuite{})
@@ -504,6 +509,102 @@ func (s *generalSuite) TestSysInfoIsManaged(c *check.C) {
 	c.Check(rsp.Result.(map[string]any)["managed"], check.Equals, true)
 }
 
+func (s *generalSuite) TestSysInfoRefreshStagger(c *check.C) {
+	s.expectSystemInfoReadAccess()
+	d := s.daemon(c)
+
+	st := d.Overlord().State()
+	st.Lock()
+	tr := config.NewTransaction(st)
+	tr.Set("core", "refresh.stagger", "device")
+	tr.Commit()
+	st.Unlock()
+
+	req, err := http.NewRequest("GET", "/v2/system-info", nil)
+	c.Assert(err, check.IsNil)
+
+	rsp := s.syncReq(c, req, nil, actionIsExpected)
+	refresh := rsp.Result.(map[string]any)["refresh"].(client.RefreshInfo)
+	c.Check(refresh.Stagger, check.Equals, "device")
+	// the next auto-refresh was not computed yet
+	c.Check(refresh.StaggerOffset, check.Equals, "")
+}
+
+func (s *generalSuite) TestSysInfoRefreshBackoff(c *check.C) {
+	s.expectSystemInfoReadAccess()
+	d := s.daemon(c)
+
+	st := d.Overlord().State()
+	st.Lock()
+	snapstate.Set(st, "foo", &snapstate.SnapState{
+		Active: true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+			{RealName: "foo", Revision: snap.R(1)},
+		}),
+		Current: snap.R(1),
+		RefreshFailures: &snap.RefreshFailuresInfo{
+			Revision:        snap.R(7),
+			FailureCount:    2,
+			LastFailureTime: time.Now(),
+			LastError:       "boom",
+		},
+	})
+	st.Unlock()
+
+	req, err := http.NewRequest("GET", "/v2/system-info", nil)
+	c.Assert(err, check.IsNil)
+
+	rsp := s.syncReq(c, req, nil, actionIsExpected)
+	refresh := rsp.Result.(map[string]any)["refresh"].(client.RefreshInfo)
+	c.Assert(refresh.Backoff, check.HasLen, 1)
+	backoff := refresh.Backoff[0]
+	c.Check(backoff.Snap, check.Equals, "foo")
+	c.Check(backoff.Revision, check.Equals, snap.R(7))
+	c.Check(backoff.Failures, check.Equals, 2)
+	c.Check(backoff.LastError, check.Equals, "boom")
+	until, err := time.Parse(time.RFC3339, backoff.Until)
+	c.Assert(err, check.IsNil)
+	c.Check(until.After(time.Now().Add(11*time.Hour)), check.Equals, true)
+}
+
+func (s *generalSuite) TestSysInfoRefreshHolds(c *check.C) {
+	s.expectSystemInfoReadAccess()
+	d := s.daemon(c)
+
+	st := d.Overlord().State()
+	st.Lock()
+	for _, name := range []string{"foo", "bar"} {
+		snapstate.Set(st, name, &snapstate.SnapState{
+			Active: true,
+			Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+				{RealName: name, Revision: snap.R(1)},
+			}),
+			Current: snap.R(1),
+		})
+	}
+	err := snapstate.HoldRefreshesBySystemInWindows(st, snapstate.HoldGeneral, "forever", "mon-fri,9:00-17:00", []string{"foo"})
+	c.Assert(err, check.IsNil)
+	until := time.Now().Add(time.Hour).Truncate(time.Minute).UTC()
+	err = snapstate.HoldRefreshesBySystem(st, snapstate.HoldAutoRefresh, until.Format(time.RFC3339), []string{"bar"})
+	c.Assert(err, check.IsNil)
+	st.Unlock()
+
+	req, err := http.NewRequest("GET", "/v2/system-info", nil)
+	c.Assert(err, check.IsNil)
+
+	rsp := s.syncReq(c, req, nil, actionIsExpected)
+	refresh := rsp.Result.(map[string]any)["refresh"].(client.RefreshInfo)
+	c.Assert(refresh.Holds, check.HasLen, 2)
+	c.Check(refresh.Holds[0], check.DeepEquals, client.RefreshHold{
+		Snap:  "bar",
+		Level: "auto-refresh",
+		Until: until.Local().Format(time.RFC3339),
+	})
+	c.Check(refresh.Holds[1].Snap, check.Equals, "foo")
+	c.Check(refresh.Holds[1].Level, check.Equals, "general")
+	c.Check(refresh.Holds[1].Windows, check.Equals, "mon-fri,9:00-17:00")
+}
+
 func (s *generalSuite) TestSysInfoWorksDegraded(c *check.C) {
 	s.expectSystemInfoReadAccess()
 	d := s.daemon(c)
@@ -1022,6 +1123,7 @@ func (s *generalSuite) TestStateChange(c *check.C) {
 	t2.Set("snap-setup", "some-snap")
 
 	chg1.Set("api-data", map[string]int{"n": 42})
+	updateID := strconv.Itoa(chg1.UpdateID())
 	st.Unlock()
 
 	// Execute
@@ -1069,9 +1171,135 @@ func (s *generalSuite) TestStateChange(c *check.C) {
 		"data": map[string]any{
 			"n": float64(42),
 		},
+		"update-id": updateID,
 	})
 }
 
+func (s *generalSuite) setupChangeToWatch(c *check.C) (st *state.State, chg *state.Change, t *state.Task, updateID string) {
+	s.expectChangeReadAccess()
+	d := s.daemon(c)
+	st = d.Overlord().State()
+	st.Lock()
+	defer st.Unlock()
+	chg = st.NewChange("install", "install...")
+	t = st.NewTask("download", "1...")
+	chg.AddTask(t)
+	return st, chg, t, strconv.Itoa(chg.UpdateID())
+}
+
+func (s *generalSuite) TestStateChangeWaitUpdate(c *check.C) {
+	st, chg, t, updateID := s.setupChangeToWatch(c)
+
+	go func() {
+		time.Sleep(testutil.HostScaledTimeout(50 * time.Millisecond))
+		st.Lock()
+		t.SetProgress("downloading", 5, 10)
+		st.Unlock()
+	}()
+
+	timeout := testutil.HostScaledTimeout(5 * time.Second)
+	start := time.Now()
+	req, err := http.NewRequest("GET", fmt.Sprintf("/v2/changes/%s?update-id=%s&timeout=%s", chg.ID(), updateID, timeout), nil)
+	c.Assert(err, check.IsNil)
+	rsp := s.syncReq(c, req, nil, actionIsExpected)
+	c.Check(time.Since(start) < timeout, check.Equals, true)
+
+	st.Lock()
+	newUpdateID := strconv.Itoa(chg.UpdateID())
+	st.Unlock()
+	c.Check(newUpdateID, check.Not(check.Equals), updateID)
+
+	info, err := json.Marshal(rsp.Result)
+	c.Assert(err, check.IsNil)
+	var result map[string]any
+	c.Assert(json.Unmarshal(info, &result), check.IsNil)
+	c.Check(result["update-id"], check.Equals, newUpdateID)
+	tasks := result["tasks"].([]any)
+	c.Assert(tasks, check.HasLen, 1)
+	c.Check(tasks[0].(map[string]any)["progress"], check.DeepEquals, map[string]any{
+		"label": "downloading", "done": 5., "total": 10.,
+	})
+}
+
+func (s *generalSuite) TestStateChangeWaitTimeout(c *check.C) {
+	_, chg, _, updateID := s.setupChangeToWatch(c)
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("/v2/changes/%s?update-id=%s&timeout=1ms", chg.ID(), updateID), nil)
+	c.Assert(err, check.IsNil)
+	rsp := s.syncReq(c, req, nil, actionIsExpected)
+	c.Check(rsp.Status, check.Equals, 200)
+
+	info, err := json.Marshal(rsp.Result)
+	c.Assert(err, check.IsNil)
+	var result map[string]any
+	c.Assert(json.Unmarshal(info, &result), check.IsNil)
+	c.Check(result["id"], check.Equals, chg.ID())
+	c.Check(result["update-id"], check.Equals, updateID)
+}
+
+func (s *generalSuite) TestStateChangeWaitNotNeeded(c *check.C) {
+	st, chg, _, updateID := s.setupChangeToWatch(c)
+
+	timeout := testutil.HostScaledTimeout(5 * time.Second)
+	for _, lastUpdateID := range []string{"", "1000"} {
+		start := time.Now()
+		// an outdated or missing update ID returns the change immediately
+		req, err := http.NewRequest("GET", fmt.Sprintf("/v2/changes/%s?update-id=%s&timeout=%s", chg.ID(), lastUpdateID, timeout), nil)
+		c.Assert(err, check.IsNil)
+		s.syncReq(c, req, nil, actionIsExpected)
+		c.Check(time.Since(start) < timeout, check.Equals, true)
+	}
+
+	st.Lock()
+	chg.SetStatus(state.DoneStatus)
+	updateID = strconv.Itoa(chg.UpdateID())
+	st.Unlock()
+
+	// so does a ready change
+	start := time.Now()
+	req, err := http.NewRequest("GET", fmt.Sprintf("/v2/changes/%s?update-id=%s&timeout=%s", chg.ID(), updateID, timeout), nil)
+	c.Assert(err, check.IsNil)
+	s.syncReq(c, req, nil, actionIsExpected)
+	c.Check(time.Since(start) < timeout, check.Equals, true)
+}
+
+func (s *generalSuite) TestStateChangeWaitRequestCancelled(c *check.C) {
+	_, chg, _, updateID := s.setupChangeToWatch(c)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cancelTimeout := testutil.HostScaledTimeout(50 * time.Millisecond)
+	reqTimeout := testutil.HostScaledTimeout(5 * time.Second)
+
+	start := time.Now()
+
+	go func() {
+		time.Sleep(cancelTimeout)
+		cancel()
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("/v2/changes/%s?update-id=%s&timeout=%s", chg.ID(), updateID, reqTimeout), nil)
+	c.Assert(err, check.IsNil)
+	rsp := s.errorReq(c, req, nil, actionIsExpected)
+	c.Check(rsp.Status, check.Equals, 500)
+	c.Check(rsp.Message, check.Matches, "request canceled")
+
+	elapsed := time.Since(start)
+	c.Check(elapsed > cancelTimeout, check.Equals, true)
+	c.Check(elapsed < reqTimeout, check.Equals, true)
+}
+
+func (s *generalSuite) TestStateChangeInvalidTimeout(c *check.C) {
+	_, chg, _, _ := s.setupChangeToWatch(c)
+
+	req, err := http.NewRequest("GET", "/v2/changes/"+chg.ID()+"?timeout=foo", nil)
+	c.Assert(err, check.IsNil)
+	rsp := s.errorReq(c, req, nil, actionIsExpected)
+	c.Check(rsp.Status, check.Equals, 400)
+	c.Check(rsp.Message, check.Matches, "invalid timeout: .*")
+}
+
 func (s *generalSuite) expectManageAccess() {
 	s.expectWriteAccess(daemon.AuthenticatedAccess{Polkit: "io.snapcraft.snapd.manage"})
 }
@@ -0,0 +1,101 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/overlord/auth"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/overlord/swfeats"
+)
+
+var (
+	instancesCmd = &Command{
+		Path:        "/v2/instances",
+		POST:        changeInstances,
+		Actions:     []string{"rename", "copy-data"},
+		WriteAccess: authenticatedAccess{},
+	}
+)
+
+var (
+	renameInstanceChangeKind   = swfeats.RegisterChangeKind("rename-instance")
+	copyInstanceDataChangeKind = swfeats.RegisterChangeKind("copy-instance-data")
+)
+
+var (
+	snapstateRenameInstance   = snapstate.RenameInstance
+	snapstateCopyInstanceData = snapstate.CopyInstanceData
+)
+
+// instanceAction is an action performed on parallel instances of a snap
+type instanceAction struct {
+	Action string `json:"action"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+}
+
+func changeInstances(c *Command, r *http.Request, user *auth.UserState) Response {
+	var a instanceAction
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&a); err != nil {
+		return BadRequest("cannot decode request body into an instance action: %v", err)
+	}
+	if a.From == "" || a.To == "" {
+		return BadRequest("cannot %s instance: both source and target instance names are required", a.Action)
+	}
+
+	st := c.d.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	var tss []*state.TaskSet
+	var changeKind, summary string
+	switch a.Action {
+	case "rename":
+		var err error
+		tss, err = snapstateRenameInstance(st, a.From, a.To)
+		if err != nil {
+			return errToResponse(err, []string{a.From, a.To}, BadRequest, "%v")
+		}
+		changeKind = renameInstanceChangeKind
+		summary = fmt.Sprintf(i18n.G("Rename snap %q to %q"), a.From, a.To)
+	case "copy-data":
+		ts, err := snapstateCopyInstanceData(st, a.From, a.To)
+		if err != nil {
+			return errToResponse(err, []string{a.From, a.To}, BadRequest, "%v")
+		}
+		tss = []*state.TaskSet{ts}
+		changeKind = copyInstanceDataChangeKind
+		summary = fmt.Sprintf(i18n.G("Copy data of snap %q to snap %q"), a.From, a.To)
+	default:
+		return BadRequest("unsupported instance action: %q", a.Action)
+	}
+
+	change := newChange(r.Context(), st, changeKind, summary, tss, []string{a.From, a.To})
+	ensureStateSoon(st)
+
+	return AsyncResponse(nil, change.ID())
+}
@@ -0,0 +1,140 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/daemon"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+var _ = check.Suite(&instancesSuite{})
+
+type instancesSuite struct {
+	apiBaseSuite
+}
+
+func (s *instancesSuite) SetUpTest(c *check.C) {
+	s.apiBaseSuite.SetUpTest(c)
+
+	_, restore := daemon.MockEnsureStateSoon(func(st *state.State) {})
+	s.AddCleanup(restore)
+}
+
+func (s *instancesSuite) instancesReq(c *check.C, action *daemon.InstanceAction) *http.Request {
+	text, err := json.Marshal(action)
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest("POST", "/v2/instances", bytes.NewBuffer(text))
+	c.Assert(err, check.IsNil)
+	return req
+}
+
+func (s *instancesSuite) TestRenameInstance(c *check.C) {
+	d := s.daemon(c)
+
+	var called int
+	restore := daemon.MockSnapstateRenameInstance(func(st *state.State, from, to string) ([]*state.TaskSet, error) {
+		called++
+		c.Check(from, check.Equals, "foo_one")
+		c.Check(to, check.Equals, "foo_two")
+		t1 := st.NewTask("fake-rename-1", "...")
+		t2 := st.NewTask("fake-rename-2", "...")
+		return []*state.TaskSet{state.NewTaskSet(t1), state.NewTaskSet(t2)}, nil
+	})
+	defer restore()
+
+	req := s.instancesReq(c, &daemon.InstanceAction{Action: "rename", From: "foo_one", To: "foo_two"})
+	rsp := s.asyncReq(c, req, nil, actionIsExpected)
+	c.Check(called, check.Equals, 1)
+
+	st := d.Overlord().State()
+	st.Lock()
+	defer st.Unlock()
+	chg := st.Change(rsp.Change)
+	c.Assert(chg, check.NotNil)
+	c.Check(chg.Kind(), check.Equals, "rename-instance")
+	c.Check(chg.Summary(), check.Equals, `Rename snap "foo_one" to "foo_two"`)
+	c.Check(chg.Tasks(), check.HasLen, 2)
+	var snapNames []string
+	c.Assert(chg.Get("snap-names", &snapNames), check.IsNil)
+	c.Check(snapNames, check.DeepEquals, []string{"foo_one", "foo_two"})
+}
+
+func (s *instancesSuite) TestCopyInstanceData(c *check.C) {
+	d := s.daemon(c)
+
+	var called int
+	restore := daemon.MockSnapstateCopyInstanceData(func(st *state.State, from, to string) (*state.TaskSet, error) {
+		called++
+		c.Check(from, check.Equals, "foo_one")
+		c.Check(to, check.Equals, "foo_two")
+		return state.NewTaskSet(st.NewTask("fake-copy", "...")), nil
+	})
+	defer restore()
+
+	req := s.instancesReq(c, &daemon.InstanceAction{Action: "copy-data", From: "foo_one", To: "foo_two"})
+	rsp := s.asyncReq(c, req, nil, actionIsExpected)
+	c.Check(called, check.Equals, 1)
+
+	st := d.Overlord().State()
+	st.Lock()
+	defer st.Unlock()
+	chg := st.Change(rsp.Change)
+	c.Assert(chg, check.NotNil)
+	c.Check(chg.Kind(), check.Equals, "copy-instance-data")
+	c.Check(chg.Summary(), check.Equals, `Copy data of snap "foo_one" to snap "foo_two"`)
+	c.Check(chg.Tasks(), check.HasLen, 1)
+}
+
+func (s *instancesSuite) TestInstancesErrors(c *check.C) {
+	s.daemon(c)
+
+	restore := daemon.MockSnapstateCopyInstanceData(func(st *state.State, from, to string) (*state.TaskSet, error) {
+		return nil, &snapstate.ChangeConflictError{Snap: "foo_two", ChangeKind: "refresh"}
+	})
+	defer restore()
+	restore = daemon.MockSnapstateRenameInstance(func(st *state.State, from, to string) ([]*state.TaskSet, error) {
+		return nil, &snapstate.ChangeConflictError{Snap: "foo_one", ChangeKind: "refresh"}
+	})
+	defer restore()
+
+	for _, tc := range []struct {
+		action daemon.InstanceAction
+		status int
+		msg    string
+	}{
+		{daemon.InstanceAction{Action: "what", From: "foo_one", To: "foo_two"}, 400, `unsupported instance action: "what"`},
+		{daemon.InstanceAction{Action: "rename", From: "foo_one"}, 400, `cannot rename instance: both source and target instance names are required`},
+		{daemon.InstanceAction{Action: "copy-data", To: "foo_two"}, 400, `cannot copy-data instance: both source and target instance names are required`},
+		{daemon.InstanceAction{Action: "rename", From: "foo_one", To: "foo_two"}, 409, `snap "foo_one" has "refresh" change in progress`},
+		{daemon.InstanceAction{Action: "copy-data", From: "foo_one", To: "foo_two"}, 409, `snap "foo_two" has "refresh" change in progress`},
+	} {
+		req := s.instancesReq(c, &tc.action)
+		rspe := s.errorReq(c, req, nil, actionIsUnexpected)
+		c.Check(rspe.Status, check.Equals, tc.status, check.Commentf("%+v", tc.action))
+		c.Check(rspe.Message, check.Equals, tc.msg)
+	}
+}
@@ -20,6 +20,7 @@
 package daemon
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -30,9 +31,11 @@ import (
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/overlord/auth"
 	"github.com/snapcore/snapd/overlord/ifacestate"
+	"github.com/snapcore/snapd/overlord/servicestate"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/overlord/swfeats"
+	"github.com/snapcore/snapd/snap"
 )
 
 var (
@@ -40,15 +43,16 @@ var (
 		Path:        "/v2/interfaces",
 		GET:         interfacesConnectionsMultiplexer,
 		POST:        changeInterfaces,
-		Actions:     []string{"connect", "disconnect"},
+		Actions:     []string{"connect", "connect-batch", "disconnect"},
 		ReadAccess:  openAccess{},
 		WriteAccess: authenticatedAccess{Polkit: polkitActionManageInterfaces},
 	}
 )
 
 var (
-	connectSnapChangeKind    = swfeats.RegisterChangeKind("connect-snap")
-	disconnectSnapChangeKind = swfeats.RegisterChangeKind("disconnect-snap")
+	connectSnapChangeKind      = swfeats.RegisterChangeKind("connect-snap")
+	connectSnapBatchChangeKind = swfeats.RegisterChangeKind("connect-snap-batch")
+	disconnectSnapChangeKind   = swfeats.RegisterChangeKind("disconnect-snap")
 )
 
 // interfacesConnectionsMultiplexer multiplexes to either legacy (connection) or modern behavior (interfaces).
@@ -111,6 +115,11 @@ func getInterfaces(c *Command, r *http.Request, user *auth.UserState) Response {
 			DocURL:  info.DocURL,
 			Plugs:   plugs,
 			Slots:   slots,
+
+			RequiredPlugAttrs: info.RequiredPlugAttrs,
+			RequiredSlotAttrs: info.RequiredSlotAttrs,
+			Consumers:         info.Consumers,
+			SecurityNotes:     info.SecurityNotes,
 		})
 	}
 	return SyncResponse(infoJSONs)
@@ -139,6 +148,9 @@ func changeInterfaces(c *Command, r *http.Request, user *auth.UserState) Respons
 	if a.Action == "" {
 		return BadRequest("interface action not specified")
 	}
+	if a.Action == "connect-batch" {
+		return connectInterfacesBatch(r.Context(), c, &a)
+	}
 	if len(a.Plugs) > 1 || len(a.Slots) > 1 {
 		return NotImplemented("many-to-many operations are not implemented")
 	}
@@ -154,38 +166,15 @@ func changeInterfaces(c *Command, r *http.Request, user *auth.UserState) Respons
 
 	var tasksets []*state.TaskSet
 	var affected []string
+	// connections whose change can affect running services
+	var changedConns []*interfaces.ConnRef
 
 	st := c.d.overlord.State()
 	st.Lock()
 	defer st.Unlock()
 
-	checkInstalled := func(snapName string) error {
-		// empty snap name is fine, ResolveConnect/ResolveDisconnect handles it.
-		if snapName == "" {
-			return nil
-		}
-		var snapst snapstate.SnapState
-		err := snapstate.Get(st, snapName, &snapst)
-		if (err == nil && !snapst.IsInstalled()) || errors.Is(err, state.ErrNoState) {
-			return fmt.Errorf("snap %q is not installed", snapName)
-		}
-		if err == nil {
-			return nil
-		}
-		return fmt.Errorf("internal error: cannot get state of snap %q: %v", snapName, err)
-	}
-
-	for i := range a.Plugs {
-		a.Plugs[i].Snap = ifacestate.RemapSnapFromRequest(a.Plugs[i].Snap)
-		if err := checkInstalled(a.Plugs[i].Snap); err != nil {
-			return errToResponse(err, nil, BadRequest, "%v")
-		}
-	}
-	for i := range a.Slots {
-		a.Slots[i].Snap = ifacestate.RemapSnapFromRequest(a.Slots[i].Snap)
-		if err := checkInstalled(a.Slots[i].Snap); err != nil {
-			return errToResponse(err, nil, BadRequest, "%v")
-		}
+	if err := remapAndCheckInstalled(st, &a); err != nil {
+		return errToResponse(err, nil, BadRequest, "%v")
 	}
 
 	var changeKind string
@@ -200,11 +189,12 @@ func changeInterfaces(c *Command, r *http.Request, user *auth.UserState) Respons
 			summary = fmt.Sprintf("Connect %s:%s to %s:%s", connRef.PlugRef.Snap, connRef.PlugRef.Name, connRef.SlotRef.Snap, connRef.SlotRef.Name)
 			ts, err = ifacestate.Connect(st, connRef.PlugRef.Snap, connRef.PlugRef.Name, connRef.SlotRef.Snap, connRef.SlotRef.Name)
 			if _, ok := err.(*ifacestate.ErrAlreadyConnected); ok {
-				change := newChange(st, connectSnapChangeKind, summary, nil, affected)
+				change := newChange(r.Context(), st, connectSnapChangeKind, summary, nil, affected)
 				change.SetStatus(state.DoneStatus)
 				return AsyncResponse(nil, change.ID())
 			}
 			tasksets = append(tasksets, ts)
+			changedConns = append(changedConns, connRef)
 		}
 		changeKind = connectSnapChangeKind
 	case "disconnect":
@@ -236,6 +226,10 @@ func changeInterfaces(c *Command, r *http.Request, user *auth.UserState) Respons
 				}
 				ts.JoinLane(st.NewLane())
 				tasksets = append(tasksets, ts)
+				// forgotten connections can be inactive already
+				if _, err := repo.Connection(connRef); err == nil {
+					changedConns = append(changedConns, connRef)
+				}
 			}
 			affected = snapNamesFromConns(conns)
 		}
@@ -245,8 +239,161 @@ func changeInterfaces(c *Command, r *http.Request, user *auth.UserState) Respons
 		return errToResponse(err, nil, BadRequest, "%v")
 	}
 
-	change := newChange(st, changeKind, summary, tasksets, affected)
-	st.EnsureBefore(0)
+	services, err := affectedServices(c.d.overlord.InterfaceManager(), changedConns)
+	if err != nil {
+		return InternalError("cannot find the services affected by the connection change: %v", err)
+	}
+	serviceNames := make([]string, 0, len(services))
+	for _, app := range services {
+		serviceNames = append(serviceNames, app.String())
+	}
+	if a.RestartAffected && len(services) > 0 {
+		u, err := systemUserFromRequest(r)
+		if err != nil {
+			return BadRequest("cannot restart affected services: %v", err)
+		}
+		inst := &servicestate.Instruction{Action: "restart"}
+		inst.EnsureDefaultScopeForUser(u)
+		restartTss, err := servicestateControl(st, services, inst, u, nil, nil)
+		if err != nil {
+			if _, ok := err.(servicestate.ServiceActionConflictError); ok {
+				return Conflict(err.Error())
+			}
+			return BadRequest("cannot restart affected services: %v", err)
+		}
+		for _, restartTs := range restartTss {
+			for _, ts := range tasksets {
+				restartTs.WaitAll(ts)
+			}
+		}
+		tasksets = append(tasksets, restartTss...)
+		summary = fmt.Sprintf("%s and restart %s", summary, strings.Join(serviceNames, ", "))
+	}
+
+	change := newChange(r.Context(), st, changeKind, summary, tasksets, affected)
+	if len(serviceNames) > 0 {
+		change.Set("api-data", map[string]any{"affected-services": serviceNames})
+	}
+	ensureStateSoon(st)
+
+	return AsyncResponse(nil, change.ID())
+}
+
+// affectedServices returns the services which need a restart to see the
+// given connections being made or removed.
+func affectedServices(ifaceMgr *ifacestate.InterfaceManager, connRefs []*interfaces.ConnRef) ([]*snap.AppInfo, error) {
+	var services []*snap.AppInfo
+	seen := make(map[string]bool)
+	for _, connRef := range connRefs {
+		apps, err := ifaceMgr.AffectedServices(connRef)
+		if err != nil {
+			return nil, err
+		}
+		for _, app := range apps {
+			if !seen[app.String()] {
+				seen[app.String()] = true
+				services = append(services, app)
+			}
+		}
+	}
+	return services, nil
+}
+
+// remapAndCheckInstalled remaps the snap names of the plugs and slots of the
+// given action and checks that all the named snaps are installed.
+func remapAndCheckInstalled(st *state.State, a *interfaceAction) error {
+	checkInstalled := func(snapName string) error {
+		// empty snap name is fine, ResolveConnect/ResolveDisconnect handles it.
+		if snapName == "" {
+			return nil
+		}
+		var snapst snapstate.SnapState
+		err := snapstate.Get(st, snapName, &snapst)
+		if (err == nil && !snapst.IsInstalled()) || errors.Is(err, state.ErrNoState) {
+			return fmt.Errorf("snap %q is not installed", snapName)
+		}
+		if err == nil {
+			return nil
+		}
+		return fmt.Errorf("internal error: cannot get state of snap %q: %v", snapName, err)
+	}
+
+	for i := range a.Plugs {
+		a.Plugs[i].Snap = ifacestate.RemapSnapFromRequest(a.Plugs[i].Snap)
+		if err := checkInstalled(a.Plugs[i].Snap); err != nil {
+			return err
+		}
+	}
+	for i := range a.Slots {
+		a.Slots[i].Snap = ifacestate.RemapSnapFromRequest(a.Slots[i].Snap)
+		if err := checkInstalled(a.Slots[i].Snap); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// connectInterfacesBatch connects the i-th plug of the action to its i-th
+// slot. All connections are resolved and validated up front and then
+// applied in a single change sharing one lane, so that a failure of any of
+// them undoes all the others.
+func connectInterfacesBatch(ctx context.Context, c *Command, a *interfaceAction) Response {
+	if len(a.Plugs) == 0 {
+		return BadRequest("at least one plug and slot is required")
+	}
+	if len(a.Plugs) != len(a.Slots) {
+		return BadRequest("cannot connect in batch: got %d plugs and %d slots", len(a.Plugs), len(a.Slots))
+	}
+
+	st := c.d.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	if err := remapAndCheckInstalled(st, a); err != nil {
+		return errToResponse(err, nil, BadRequest, "%v")
+	}
+
+	repo := c.d.overlord.InterfaceManager().Repository()
+	connRefs := make([]*interfaces.ConnRef, 0, len(a.Plugs))
+	seen := make(map[string]bool, len(a.Plugs))
+	for i := range a.Plugs {
+		connRef, err := repo.ResolveConnect(a.Plugs[i].Snap, a.Plugs[i].Name, a.Slots[i].Snap, a.Slots[i].Name)
+		if err != nil {
+			return BadRequest("cannot connect in batch: %v", err)
+		}
+		if seen[connRef.ID()] {
+			return BadRequest("cannot connect in batch: connection %q requested more than once", connRef.ID())
+		}
+		seen[connRef.ID()] = true
+		connRefs = append(connRefs, connRef)
+	}
+
+	lane := st.NewLane()
+	var tasksets []*state.TaskSet
+	for _, connRef := range connRefs {
+		ts, err := ifacestate.Connect(st, connRef.PlugRef.Snap, connRef.PlugRef.Name, connRef.SlotRef.Snap, connRef.SlotRef.Name)
+		if _, ok := err.(*ifacestate.ErrAlreadyConnected); ok {
+			continue
+		}
+		if err != nil {
+			return errToResponse(err, nil, BadRequest, "cannot connect in batch: %v")
+		}
+		ts.JoinLane(lane)
+		tasksets = append(tasksets, ts)
+	}
+
+	summary := fmt.Sprintf("Connect %d plugs to slots", len(connRefs))
+	if len(connRefs) == 1 {
+		connRef := connRefs[0]
+		summary = fmt.Sprintf("Connect %s:%s to %s:%s", connRef.PlugRef.Snap, connRef.PlugRef.Name, connRef.SlotRef.Snap, connRef.SlotRef.Name)
+	}
+	change := newChange(ctx, st, connectSnapBatchChangeKind, summary, tasksets, snapNamesFromConns(connRefs))
+	if len(tasksets) == 0 {
+		// everything was already connected
+		change.SetStatus(state.DoneStatus)
+	} else {
+		st.EnsureBefore(0)
+	}
 
 	return AsyncResponse(nil, change.ID())
 }
@@ -34,8 +34,15 @@ import (
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/builtin"
 	"github.com/snapcore/snapd/interfaces/ifacetest"
+	"github.com/snapcore/snapd/interfaces/mount"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/osutil/user"
+	"github.com/snapcore/snapd/overlord/hookstate"
 	"github.com/snapcore/snapd/overlord/ifacestate"
+	"github.com/snapcore/snapd/overlord/servicestate"
 	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/testutil"
 )
 
 var _ = check.Suite(&interfacesSuite{})
@@ -177,6 +184,278 @@ func (s *interfacesSuite) TestConnectPlugSuccess(c *check.C) {
 	}})
 }
 
+const consumerServiceYaml = `
+name: consumer
+version: 1
+apps:
+ app:
+ svc:
+  daemon: simple
+plugs:
+ plug:
+  interface: test
+`
+
+func (s *interfacesSuite) testConnectAffectedServices(c *check.C, restartAffected bool) (*state.Change, []*servicestate.Instruction) {
+	restore := builtin.MockInterface(&ifacetest.TestInterface{
+		InterfaceName: "test",
+		MountConnectedPlugCallback: func(spec *mount.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+			return spec.AddMountEntry(osutil.MountEntry{Name: "/snap/producer/1/dir", Dir: "/snap/consumer/1/dir", Type: "none", Options: []string{"bind"}})
+		},
+	})
+	defer restore()
+	var insts []*servicestate.Instruction
+	restore = daemon.MockServicestateControl(func(st *state.State, appInfos []*snap.AppInfo, inst *servicestate.Instruction, cu *user.User, flags *servicestate.Flags, context *hookstate.Context) ([]*state.TaskSet, error) {
+		c.Assert(appInfos, check.HasLen, 1)
+		c.Check(appInfos[0].String(), check.Equals, "consumer.svc")
+		insts = append(insts, inst)
+		return []*state.TaskSet{state.NewTaskSet(st.NewTask("sample", ""))}, nil
+	})
+	defer restore()
+	// the overlord loop is not running
+	_, restore = daemon.MockEnsureStateSoon(func(st *state.State) {})
+	defer restore()
+
+	d := s.daemon(c)
+	s.mockSnap(c, consumerServiceYaml)
+	s.mockSnap(c, producerYaml)
+
+	text, err := json.Marshal(&client.InterfaceAction{
+		Action:          "connect",
+		RestartAffected: restartAffected,
+		Plugs:           []client.Plug{{Snap: "consumer", Name: "plug"}},
+		Slots:           []client.Slot{{Snap: "producer", Name: "slot"}},
+	})
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest("POST", "/v2/interfaces", bytes.NewBuffer(text))
+	c.Assert(err, check.IsNil)
+	s.asRootAuth(req)
+	rsp := s.asyncReq(c, req, nil, actionIsExpected)
+
+	st := d.Overlord().State()
+	st.Lock()
+	defer st.Unlock()
+	chg := st.Change(rsp.Change)
+	c.Assert(chg, check.NotNil)
+
+	var apiData map[string]any
+	c.Assert(chg.Get("api-data", &apiData), check.IsNil)
+	c.Check(apiData, check.DeepEquals, map[string]any{
+		"affected-services": []any{"consumer.svc"},
+	})
+	return chg, insts
+}
+
+func (s *interfacesSuite) TestConnectRestartAffected(c *check.C) {
+	chg, insts := s.testConnectAffectedServices(c, true)
+
+	c.Check(chg.Summary(), check.Equals, "Connect consumer:plug to producer:slot and restart consumer.svc")
+	c.Assert(insts, check.HasLen, 1)
+	c.Check(insts[0].Action, check.Equals, "restart")
+	c.Check(insts[0].Scope, check.DeepEquals, client.ScopeSelector{"system", "user"})
+
+	st := chg.State()
+	st.Lock()
+	defer st.Unlock()
+	var connectTask, restartTask *state.Task
+	for _, t := range chg.Tasks() {
+		switch t.Kind() {
+		case "connect":
+			connectTask = t
+		case "sample":
+			restartTask = t
+		}
+	}
+	c.Assert(connectTask, check.NotNil)
+	c.Assert(restartTask, check.NotNil)
+	c.Check(restartTask.WaitTasks(), testutil.Contains, connectTask)
+}
+
+func (s *interfacesSuite) TestConnectAffectedServicesNoRestart(c *check.C) {
+	chg, insts := s.testConnectAffectedServices(c, false)
+
+	c.Check(chg.Summary(), check.Equals, "Connect consumer:plug to producer:slot")
+	c.Check(insts, check.HasLen, 0)
+}
+
+var (
+	batchConsumerYaml = `
+name: consumer
+version: 1
+apps:
+ app:
+plugs:
+ plug:
+  interface: test
+ other-plug:
+  interface: test
+`
+	batchProducerYaml = `
+name: producer
+version: 1
+apps:
+ app:
+slots:
+ slot:
+  interface: test
+ other-slot:
+  interface: test
+ different-slot:
+  interface: different
+`
+)
+
+func (s *interfacesSuite) postInterfaceAction(c *check.C, action *client.InterfaceAction) (*httptest.ResponseRecorder, map[string]any) {
+	text, err := json.Marshal(action)
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest("POST", "/v2/interfaces", bytes.NewBuffer(text))
+	c.Assert(err, check.IsNil)
+	rec := httptest.NewRecorder()
+	s.req(c, req, nil, actionIsExpected).ServeHTTP(rec, req)
+	var body map[string]any
+	c.Assert(json.Unmarshal(rec.Body.Bytes(), &body), check.IsNil)
+	return rec, body
+}
+
+func (s *interfacesSuite) TestConnectBatchSuccess(c *check.C) {
+	d := s.daemon(c)
+
+	mockIface(c, d, &ifacetest.TestInterface{InterfaceName: "test"})
+	mockIface(c, d, &ifacetest.TestInterface{InterfaceName: "different"})
+	s.mockSnap(c, batchConsumerYaml)
+	s.mockSnap(c, batchProducerYaml)
+
+	d.Overlord().Loop()
+	defer d.Overlord().Stop()
+
+	rec, body := s.postInterfaceAction(c, &client.InterfaceAction{
+		Action: "connect-batch",
+		Plugs:  []client.Plug{{Snap: "consumer", Name: "plug"}, {Snap: "consumer", Name: "other-plug"}},
+		Slots:  []client.Slot{{Snap: "producer", Name: "slot"}, {Snap: "producer", Name: "other-slot"}},
+	})
+	c.Assert(rec.Code, check.Equals, 202)
+	id := body["change"].(string)
+
+	st := d.Overlord().State()
+	st.Lock()
+	chg := st.Change(id)
+	c.Assert(chg, check.NotNil)
+	c.Check(chg.Kind(), check.Equals, "connect-snap-batch")
+	c.Check(chg.Summary(), check.Equals, "Connect 2 plugs to slots")
+	var snapNames []string
+	c.Assert(chg.Get("snap-names", &snapNames), check.IsNil)
+	c.Check(snapNames, check.DeepEquals, []string{"consumer", "producer"})
+	// all tasks share a single lane so that they are undone together
+	lanes := map[int]bool{}
+	for _, t := range chg.Tasks() {
+		c.Assert(t.Lanes(), check.HasLen, 1)
+		lanes[t.Lanes()[0]] = true
+	}
+	c.Check(lanes, check.HasLen, 1)
+	st.Unlock()
+
+	<-chg.Ready()
+
+	st.Lock()
+	err := chg.Err()
+	st.Unlock()
+	c.Assert(err, check.IsNil)
+
+	repo := d.Overlord().InterfaceManager().Repository()
+	c.Check(repo.Interfaces().Connections, check.DeepEquals, []*interfaces.ConnRef{{
+		PlugRef: interfaces.PlugRef{Snap: "consumer", Name: "other-plug"},
+		SlotRef: interfaces.SlotRef{Snap: "producer", Name: "other-slot"},
+	}, {
+		PlugRef: interfaces.PlugRef{Snap: "consumer", Name: "plug"},
+		SlotRef: interfaces.SlotRef{Snap: "producer", Name: "slot"},
+	}})
+}
+
+func (s *interfacesSuite) TestConnectBatchAlreadyConnected(c *check.C) {
+	d := s.daemon(c)
+
+	mockIface(c, d, &ifacetest.TestInterface{InterfaceName: "test"})
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+
+	repo := d.Overlord().InterfaceManager().Repository()
+	connRef := &interfaces.ConnRef{
+		PlugRef: interfaces.PlugRef{Snap: "consumer", Name: "plug"},
+		SlotRef: interfaces.SlotRef{Snap: "producer", Name: "slot"},
+	}
+	_, err := repo.Connect(connRef, nil, nil, nil, nil, nil)
+	c.Assert(err, check.IsNil)
+	st := d.Overlord().State()
+	st.Lock()
+	st.Set("conns", map[string]any{
+		"consumer:plug producer:slot": map[string]any{"auto": false},
+	})
+	st.Unlock()
+
+	rec, body := s.postInterfaceAction(c, &client.InterfaceAction{
+		Action: "connect-batch",
+		Plugs:  []client.Plug{{Snap: "consumer", Name: "plug"}},
+		Slots:  []client.Slot{{Snap: "producer", Name: "slot"}},
+	})
+	c.Assert(rec.Code, check.Equals, 202)
+	id := body["change"].(string)
+
+	st.Lock()
+	defer st.Unlock()
+	chg := st.Change(id)
+	c.Check(chg.Summary(), check.Equals, "Connect consumer:plug to producer:slot")
+	c.Check(chg.Tasks(), check.HasLen, 0)
+	c.Check(chg.Status(), check.Equals, state.DoneStatus)
+}
+
+func (s *interfacesSuite) TestConnectBatchValidationFailure(c *check.C) {
+	d := s.daemon(c)
+
+	mockIface(c, d, &ifacetest.TestInterface{InterfaceName: "test"})
+	mockIface(c, d, &ifacetest.TestInterface{InterfaceName: "different"})
+	s.mockSnap(c, batchConsumerYaml)
+	s.mockSnap(c, batchProducerYaml)
+
+	for _, tc := range []struct {
+		plugs []client.Plug
+		slots []client.Slot
+		err   string
+	}{{
+		plugs: []client.Plug{{Snap: "consumer", Name: "plug"}, {Snap: "consumer", Name: "other-plug"}},
+		slots: []client.Slot{{Snap: "producer", Name: "slot"}, {Snap: "producer", Name: "different-slot"}},
+		err:   `cannot connect in batch: cannot connect consumer:other-plug \("test" interface\) to producer:different-slot \("different" interface\)`,
+	}, {
+		plugs: []client.Plug{{Snap: "consumer", Name: "plug"}, {Snap: "consumer", Name: "plug"}},
+		slots: []client.Slot{{Snap: "producer", Name: "slot"}, {Snap: "producer", Name: "slot"}},
+		err:   `cannot connect in batch: connection "consumer:plug producer:slot" requested more than once`,
+	}, {
+		plugs: []client.Plug{{Snap: "consumer", Name: "plug"}, {Snap: "consumer", Name: "other-plug"}},
+		slots: []client.Slot{{Snap: "producer", Name: "slot"}},
+		err:   `cannot connect in batch: got 2 plugs and 1 slots`,
+	}, {
+		plugs: []client.Plug{{Snap: "consumer", Name: "plug"}},
+		slots: []client.Slot{{Snap: "missing", Name: "slot"}},
+		err:   `snap "missing" is not installed`,
+	}, {
+		err: `at least one plug and slot is required`,
+	}} {
+		rec, body := s.postInterfaceAction(c, &client.InterfaceAction{
+			Action: "connect-batch",
+			Plugs:  tc.plugs,
+			Slots:  tc.slots,
+		})
+		c.Check(rec.Code, check.Equals, 400)
+		c.Check(body["result"].(map[string]any)["message"], check.Matches, tc.err)
+	}
+
+	st := d.Overlord().State()
+	st.Lock()
+	c.Check(st.Changes(), check.HasLen, 0)
+	st.Unlock()
+	repo := d.Overlord().InterfaceManager().Repository()
+	c.Check(repo.Interfaces().Connections, check.HasLen, 0)
+}
+
 func (s *interfacesSuite) TestConnectPlugFailureInterfaceMismatch(c *check.C) {
 	d := s.daemon(c)
 
@@ -1211,3 +1490,29 @@ func (s *interfacesSuite) TestInterfacesAllDefaultDocURL(c *check.C) {
 		}
 	}
 }
+
+func (s *interfacesSuite) TestInterfacesDocMetadata(c *check.C) {
+	_ = s.daemon(c)
+
+	req, err := http.NewRequest("GET", "/v2/interfaces?select=all&doc=true&names=gpio", nil)
+	c.Assert(err, check.IsNil)
+	rec := httptest.NewRecorder()
+	s.req(c, req, nil, actionIsExpected).ServeHTTP(rec, req)
+	c.Check(rec.Code, check.Equals, 200)
+	var body map[string]any
+	err = json.Unmarshal(rec.Body.Bytes(), &body)
+	c.Check(err, check.IsNil)
+	c.Check(body["result"], check.DeepEquals, []any{
+		map[string]any{
+			"name":                "gpio",
+			"summary":             "allows access to specific GPIO pin",
+			"doc-url":             "https://snapcraft.io/docs/gpio-interface",
+			"required-slot-attrs": []any{"number"},
+			"consumers":           "applications driving hardware through GPIO lines",
+			"security-notes": []any{
+				"slots can only be provided by snaps of type core, gadget",
+				"slot connections are not established automatically",
+			},
+		},
+	})
+}
@@ -54,14 +54,20 @@ type interfaceJSON struct {
 	DocURL  string      `json:"doc-url,omitempty"`
 	Plugs   []*plugJSON `json:"plugs,omitempty"`
 	Slots   []*slotJSON `json:"slots,omitempty"`
+
+	RequiredPlugAttrs []string `json:"required-plug-attrs,omitempty"`
+	RequiredSlotAttrs []string `json:"required-slot-attrs,omitempty"`
+	Consumers         string   `json:"consumers,omitempty"`
+	SecurityNotes     []string `json:"security-notes,omitempty"`
 }
 
 // interfaceAction is an action performed on the interface system.
 type interfaceAction struct {
-	Action string     `json:"action"`
-	Forget bool       `json:"forget,omitempty"`
-	Plugs  []plugJSON `json:"plugs,omitempty"`
-	Slots  []slotJSON `json:"slots,omitempty"`
+	Action          string     `json:"action"`
+	Forget          bool       `json:"forget,omitempty"`
+	RestartAffected bool       `json:"restart-affected,omitempty"`
+	Plugs           []plugJSON `json:"plugs,omitempty"`
+	Slots           []slotJSON `json:"slots,omitempty"`
 }
 
 // connectionsJSON aids in marshalling information about a single connection
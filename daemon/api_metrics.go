@@ -0,0 +1,171 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon
+
+import (
+	"bytes"
+	"net/http"
+	"sort"
+
+	"github.com/snapcore/snapd/features"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/metrics"
+	"github.com/snapcore/snapd/overlord/auth"
+	"github.com/snapcore/snapd/store"
+)
+
+// The metrics endpoint only exports aggregated figures which are not tied
+// to specific snaps, it is therefore open to all local users once the
+// experimental.metrics flag is set.
+var metricsCmd = &Command{
+	Path:       "/v2/metrics",
+	GET:        getMetrics,
+	ReadAccess: openAccess{},
+}
+
+var storeRequestDurations = store.RequestDurations
+
+var (
+	changeDurationBuckets = []float64{1, 5, 10, 30, 60, 120, 300, 600, 1800, 3600}
+	snapshotSizeBuckets   = []float64{1 << 20, 16 << 20, 64 << 20, 256 << 20, 1 << 30, 4 << 30, 16 << 30}
+)
+
+// refreshChangeKinds are the kinds of changes accounted as refreshes.
+var refreshChangeKinds = map[string]bool{
+	"auto-refresh":        true,
+	refreshSnapChangeKind: true,
+}
+
+// kindStatus is the key of metrics labeled by change or task kind and status.
+type kindStatus struct {
+	kind   string
+	status string
+}
+
+type kindStatusCounts map[kindStatus]int
+
+func (counts kindStatusCounts) write(w *metrics.Writer, name string) {
+	keys := make([]kindStatus, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].kind != keys[j].kind {
+			return keys[i].kind < keys[j].kind
+		}
+		return keys[i].status < keys[j].status
+	})
+	for _, k := range keys {
+		w.Sample(name, float64(counts[k]),
+			metrics.Label{Name: "kind", Value: k.kind},
+			metrics.Label{Name: "status", Value: k.status})
+	}
+}
+
+func getMetrics(c *Command, r *http.Request, user *auth.UserState) Response {
+	st := c.d.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	if err := validateFeatureFlag(st, features.Metrics); err != nil {
+		return err
+	}
+
+	changes := make(kindStatusCounts)
+	tasks := make(kindStatusCounts)
+	refreshes := make(kindStatusCounts)
+	durations := make(map[string]*metrics.Histogram)
+	for _, chg := range st.Changes() {
+		status := chg.Status()
+		changes[kindStatus{chg.Kind(), status.String()}]++
+		for _, t := range chg.Tasks() {
+			tasks[kindStatus{t.Kind(), t.Status().String()}]++
+		}
+		if !status.Ready() {
+			continue
+		}
+		if refreshChangeKinds[chg.Kind()] {
+			refreshes[kindStatus{chg.Kind(), status.String()}]++
+		}
+		readyTime := chg.ReadyTime()
+		if readyTime.IsZero() {
+			continue
+		}
+		h := durations[chg.Kind()]
+		if h == nil {
+			h = metrics.NewHistogram(changeDurationBuckets...)
+			durations[chg.Kind()] = h
+		}
+		h.Observe(readyTime.Sub(chg.SpawnTime()).Seconds())
+	}
+
+	snapshotSizes := metrics.NewHistogram(snapshotSizeBuckets...)
+	sets, err := snapshotList(r.Context(), st, 0, nil)
+	if err != nil {
+		return InternalError("cannot list snapshots: %v", err)
+	}
+	for _, set := range sets {
+		snapshotSizes.Observe(float64(set.Size()))
+	}
+
+	var buf bytes.Buffer
+	w := metrics.NewWriter(&buf)
+
+	w.Family("snapd_changes", metrics.TypeGauge, "Number of changes known to snapd by kind and status.")
+	changes.write(w, "snapd_changes")
+
+	w.Family("snapd_change_duration_seconds", metrics.TypeHistogram, "Duration of the changes that are ready by kind.")
+	kinds := make([]string, 0, len(durations))
+	for kind := range durations {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		w.Histogram("snapd_change_duration_seconds", durations[kind], metrics.Label{Name: "kind", Value: kind})
+	}
+
+	w.Family("snapd_tasks", metrics.TypeGauge, "Number of tasks known to snapd by kind and status.")
+	tasks.write(w, "snapd_tasks")
+
+	w.Family("snapd_refresh_changes", metrics.TypeGauge, "Number of refresh changes that are ready by kind and outcome.")
+	refreshes.write(w, "snapd_refresh_changes")
+
+	w.Family("snapd_store_request_duration_seconds", metrics.TypeHistogram, "Duration of the requests made to the store since snapd started.")
+	w.Histogram("snapd_store_request_duration_seconds", storeRequestDurations())
+
+	w.Family("snapd_snapshot_set_size_bytes", metrics.TypeHistogram, "Size of the snapshot sets on disk.")
+	w.Histogram("snapd_snapshot_set_size_bytes", snapshotSizes)
+
+	if err := w.Flush(); err != nil {
+		return InternalError("cannot encode metrics: %v", err)
+	}
+	return metricsResponse(buf.Bytes())
+}
+
+// metricsResponse serves metrics in the Prometheus text exposition format.
+type metricsResponse []byte
+
+func (m metricsResponse) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.WriteHeader(200)
+	if _, err := w.Write(m); err != nil {
+		logger.Debugf("cannot write metrics response: %v", err)
+	}
+}
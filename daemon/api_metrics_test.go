@@ -0,0 +1,154 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/daemon"
+	"github.com/snapcore/snapd/metrics"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/testutil"
+)
+
+var _ = Suite(&metricsSuite{})
+
+type metricsSuite struct {
+	apiBaseSuite
+
+	st *state.State
+}
+
+func (s *metricsSuite) SetUpTest(c *C) {
+	s.apiBaseSuite.SetUpTest(c)
+
+	d := s.daemonWithOverlordMock()
+	s.st = d.Overlord().State()
+	s.expectOpenAccess()
+
+	storeDurations := metrics.NewHistogram(0.5, 1)
+	storeDurations.Observe(0.25)
+	storeDurations.Observe(2)
+	s.AddCleanup(daemon.MockStoreRequestDurations(func() *metrics.Histogram {
+		return storeDurations
+	}))
+	s.AddCleanup(daemon.MockSnapshotList(func(context.Context, *state.State, uint64, []string) ([]client.SnapshotSet, error) {
+		return []client.SnapshotSet{
+			{ID: 1, Snapshots: []*client.Snapshot{{Size: 1000}, {Size: 2000}}},
+			{ID: 2, Snapshots: []*client.Snapshot{{Size: 100 << 20}}},
+		}, nil
+	}))
+}
+
+func (s *metricsSuite) enableMetrics(c *C) {
+	s.st.Lock()
+	defer s.st.Unlock()
+	tr := config.NewTransaction(s.st)
+	c.Assert(tr.Set("core", "experimental.metrics", true), IsNil)
+	tr.Commit()
+}
+
+func (s *metricsSuite) TestMetricsDisabled(c *C) {
+	req, err := http.NewRequest("GET", "/v2/metrics", nil)
+	c.Assert(err, IsNil)
+	rspe := s.errorReq(c, req, nil, actionIsExpected)
+	c.Check(rspe.Status, Equals, 400)
+	c.Check(rspe.Message, Equals, `feature flag "metrics" is disabled: set 'experimental.metrics' to true`)
+}
+
+func (s *metricsSuite) TestMetrics(c *C) {
+	s.enableMetrics(c)
+
+	s.st.Lock()
+	chg := s.st.NewChange("auto-refresh", "...")
+	t1 := s.st.NewTask("download-snap", "...")
+	t2 := s.st.NewTask("link-snap", "...")
+	chg.AddTask(t1)
+	chg.AddTask(t2)
+	t1.SetStatus(state.DoneStatus)
+	t2.SetStatus(state.ErrorStatus)
+	chg = s.st.NewChange("install-snap", "...")
+	chg.AddTask(s.st.NewTask("download-snap", "..."))
+	s.st.Unlock()
+
+	req, err := http.NewRequest("GET", "/v2/metrics", nil)
+	c.Assert(err, IsNil)
+	rec := httptest.NewRecorder()
+	s.req(c, req, nil, actionIsExpected).ServeHTTP(rec, req)
+	c.Check(rec.Code, Equals, 200)
+	c.Check(rec.Header().Get("Content-Type"), Equals, "text/plain; version=0.0.4; charset=utf-8")
+
+	out := rec.Body.String()
+	c.Check(out, testutil.Contains, `# TYPE snapd_changes gauge
+snapd_changes{kind="auto-refresh",status="Error"} 1
+snapd_changes{kind="install-snap",status="Do"} 1
+`)
+	// only ready changes have a duration
+	c.Check(out, testutil.Contains, `# TYPE snapd_change_duration_seconds histogram
+snapd_change_duration_seconds_bucket{kind="auto-refresh",le="1"} 1
+`)
+	c.Check(out, testutil.Contains, `snapd_change_duration_seconds_count{kind="auto-refresh"} 1
+`)
+	c.Check(out, Not(testutil.Contains), `snapd_change_duration_seconds_count{kind="install-snap"}`)
+	c.Check(out, testutil.Contains, `# TYPE snapd_tasks gauge
+snapd_tasks{kind="download-snap",status="Do"} 1
+snapd_tasks{kind="download-snap",status="Done"} 1
+snapd_tasks{kind="link-snap",status="Error"} 1
+`)
+	c.Check(out, testutil.Contains, `# TYPE snapd_refresh_changes gauge
+snapd_refresh_changes{kind="auto-refresh",status="Error"} 1
+`)
+	c.Check(out, testutil.Contains, `# TYPE snapd_store_request_duration_seconds histogram
+snapd_store_request_duration_seconds_bucket{le="0.5"} 1
+snapd_store_request_duration_seconds_bucket{le="1"} 1
+snapd_store_request_duration_seconds_bucket{le="+Inf"} 2
+snapd_store_request_duration_seconds_sum 2.25
+snapd_store_request_duration_seconds_count 2
+`)
+	c.Check(out, testutil.Contains, `# TYPE snapd_snapshot_set_size_bytes histogram
+snapd_snapshot_set_size_bytes_bucket{le="1.048576e+06"} 1
+snapd_snapshot_set_size_bytes_bucket{le="1.6777216e+07"} 1
+snapd_snapshot_set_size_bytes_bucket{le="6.7108864e+07"} 1
+snapd_snapshot_set_size_bytes_bucket{le="2.68435456e+08"} 2
+`)
+	c.Check(out, testutil.Contains, `snapd_snapshot_set_size_bytes_count 2
+`)
+}
+
+func (s *metricsSuite) TestMetricsSnapshotListError(c *C) {
+	s.enableMetrics(c)
+
+	s.AddCleanup(daemon.MockSnapshotList(func(context.Context, *state.State, uint64, []string) ([]client.SnapshotSet, error) {
+		return nil, errors.New("boom")
+	}))
+
+	req, err := http.NewRequest("GET", "/v2/metrics", nil)
+	c.Assert(err, IsNil)
+	rspe := s.errorReq(c, req, nil, actionIsExpected)
+	c.Check(rspe.Status, Equals, 500)
+	c.Check(rspe.Message, Equals, "cannot list snapshots: boom")
+}
@@ -48,25 +48,27 @@ var (
 		POST:        postSerial,
 		Actions:     []string{"forget"},
 		ReadAccess:  openAccess{},
-		WriteAccess: rootAccess{},
+		WriteAccess: rootAccess{Polkit: polkitActionManageSystem},
 	}
 	modelCmd = &Command{
 		Path:        "/v2/model",
 		POST:        postModel,
 		GET:         getModel,
 		ReadAccess:  openAccess{},
-		WriteAccess: rootAccess{},
+		WriteAccess: rootAccess{Polkit: polkitActionManageSystem},
 	}
 )
 
 var (
-	devicestateRemodel = devicestate.Remodel
-	sideloadSnapsInfo  = sideloadInfo
+	devicestateRemodel        = devicestate.Remodel
+	devicestateRemodelPreview = devicestate.RemodelPreview
+	sideloadSnapsInfo         = sideloadInfo
 )
 
 type postModelData struct {
 	NewModel string `json:"new-model"`
 	Offline  bool   `json:"offline"`
+	DryRun   bool   `json:"dry-run"`
 }
 
 func postModel(c *Command, r *http.Request, _ *auth.UserState) Response {
@@ -119,6 +121,16 @@ func remodelJSON(c *Command, r *http.Request) Response {
 	st.Lock()
 	defer st.Unlock()
 
+	if data.DryRun {
+		plan, err := devicestateRemodelPreview(st, newModel, devicestate.RemodelOptions{
+			Offline: data.Offline,
+		})
+		if err != nil {
+			return BadRequest("cannot remodel device: %v", err)
+		}
+		return SyncResponse(remodelPlanResult(plan))
+	}
+
 	chg, err := devicestateRemodel(st, newModel, devicestate.RemodelOptions{
 		Offline: data.Offline,
 	})
@@ -130,6 +142,38 @@ func remodelJSON(c *Command, r *http.Request) Response {
 	return AsyncResponse(nil, chg.ID())
 }
 
+func remodelPlanModel(model *asserts.Model) client.RemodelPlanModel {
+	return client.RemodelPlanModel{
+		BrandID:  model.BrandID(),
+		Model:    model.Model(),
+		Revision: model.Revision(),
+	}
+}
+
+func remodelPlanResult(plan *devicestate.RemodelPlan) *client.RemodelPlan {
+	res := &client.RemodelPlan{
+		CurrentModel: remodelPlanModel(plan.CurrentModel),
+		NewModel:     remodelPlanModel(plan.NewModel),
+	}
+	switch plan.Kind {
+	case devicestate.UpdateRemodel:
+		res.Kind = "update"
+	case devicestate.StoreSwitchRemodel:
+		res.Kind = "store-switch"
+	case devicestate.ReregRemodel:
+		res.Kind = "re-registration"
+	}
+	for _, sn := range plan.Snaps {
+		res.Snaps = append(res.Snaps, client.RemodelPlanSnap{
+			Name:     sn.Name,
+			Action:   string(sn.Action),
+			Channel:  sn.Channel,
+			Revision: sn.Revision,
+		})
+	}
+	return res
+}
+
 func readOfflineRemodelForm(form *Form) (*asserts.Model, []*uploadedContainer, *asserts.Batch, *apiError) {
 	// New model
 	model := form.Values["new-model"]
@@ -69,7 +69,7 @@ type modelSuite struct {
 func (s *modelSuite) TestPostRemodelUnhappy(c *check.C) {
 	s.daemon(c)
 
-	s.expectRootAccess()
+	s.expectManageSystemAccess()
 
 	data, err := json.Marshal(daemon.PostModelData{NewModel: "invalid model"})
 	c.Check(err, check.IsNil)
@@ -84,7 +84,7 @@ func (s *modelSuite) TestPostRemodelUnhappy(c *check.C) {
 func (s *modelSuite) TestPostRemodelUnhappyWrongAssertion(c *check.C) {
 	s.daemon(c)
 
-	s.expectRootAccess()
+	s.expectManageSystemAccess()
 
 	acct := assertstest.NewAccount(s.StoreSigning, "developer1", nil, "")
 	buf := bytes.NewBuffer(asserts.Encode(acct))
@@ -110,7 +110,7 @@ func (s *modelSuite) TestPostRemodelOffline(c *check.C) {
 }
 
 func (s *modelSuite) testPostRemodel(c *check.C, offline bool) {
-	s.expectRootAccess()
+	s.expectManageSystemAccess()
 
 	oldModel := s.Brands.Model("my-brand", "my-old-model", modelDefaults)
 	newModel := s.Brands.Model("my-brand", "my-old-model", modelDefaults, map[string]any{
@@ -177,8 +177,65 @@ func (s *modelSuite) testPostRemodel(c *check.C, offline bool) {
 	c.Assert(soon, check.Equals, 1)
 }
 
+func (s *modelSuite) TestPostRemodelDryRun(c *check.C) {
+	s.expectManageSystemAccess()
+
+	oldModel := s.Brands.Model("my-brand", "my-old-model", modelDefaults)
+	newModel := s.Brands.Model("my-brand", "my-old-model", modelDefaults, map[string]any{
+		"revision": "2",
+	})
+
+	d := s.daemonWithOverlordMockAndStore()
+	st := d.Overlord().State()
+
+	defer daemon.MockDevicestateRemodel(func(st *state.State, nm *asserts.Model, opts devicestate.RemodelOptions) (*state.Change, error) {
+		c.Fatalf("unexpected remodel")
+		return nil, nil
+	})()
+	defer daemon.MockDevicestateRemodelPreview(func(st *state.State, nm *asserts.Model, opts devicestate.RemodelOptions) (*devicestate.RemodelPlan, error) {
+		c.Check(nm, check.DeepEquals, newModel)
+		c.Check(opts.Offline, check.Equals, true)
+		return &devicestate.RemodelPlan{
+			Kind:         devicestate.UpdateRemodel,
+			CurrentModel: oldModel,
+			NewModel:     nm,
+			Snaps: []devicestate.RemodelPlannedSnap{{
+				Name:     "foo",
+				Action:   devicestate.RemodelSnapInstall,
+				Channel:  "latest/stable",
+				Revision: snap.R(12),
+			}},
+		}, nil
+	})()
+
+	data, err := json.Marshal(daemon.PostModelData{
+		NewModel: string(asserts.Encode(newModel)),
+		Offline:  true,
+		DryRun:   true,
+	})
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest("POST", "/v2/model", bytes.NewBuffer(data))
+	c.Assert(err, check.IsNil)
+	rsp := s.syncReq(c, req, nil, actionIsExpected)
+	c.Check(rsp.Result, check.DeepEquals, &client.RemodelPlan{
+		Kind:         "update",
+		CurrentModel: client.RemodelPlanModel{BrandID: "my-brand", Model: "my-old-model", Revision: 0},
+		NewModel:     client.RemodelPlanModel{BrandID: "my-brand", Model: "my-old-model", Revision: 2},
+		Snaps: []client.RemodelPlanSnap{{
+			Name:     "foo",
+			Action:   "install",
+			Channel:  "latest/stable",
+			Revision: snap.R(12),
+		}},
+	})
+
+	st.Lock()
+	defer st.Unlock()
+	c.Check(st.Changes(), check.HasLen, 0)
+}
+
 func (s *modelSuite) TestPostRemodelWrongBody(c *check.C) {
-	s.expectRootAccess()
+	s.expectManageSystemAccess()
 
 	d := s.daemonWithOverlordMockAndStore()
 	hookMgr, err := hookstate.Manager(d.Overlord().State(), d.Overlord().TaskRunner())
@@ -208,7 +265,7 @@ func (s *modelSuite) TestPostRemodelWrongBody(c *check.C) {
 }
 
 func (s *modelSuite) TestPostRemodelWrongContentType(c *check.C) {
-	s.expectRootAccess()
+	s.expectManageSystemAccess()
 
 	d := s.daemonWithOverlordMockAndStore()
 	hookMgr, err := hookstate.Manager(d.Overlord().State(), d.Overlord().TaskRunner())
@@ -574,7 +631,7 @@ type testPostOfflineRemodelParams struct {
 }
 
 func (s *modelSuite) testPostOfflineRemodel(c *check.C, params *testPostOfflineRemodelParams) {
-	s.expectRootAccess()
+	s.expectManageSystemAccess()
 
 	oldModel := s.Brands.Model("my-brand", "my-old-model", modelDefaults)
 	newModel := s.Brands.Model("my-brand", "my-old-model", modelDefaults, map[string]any{
@@ -682,7 +739,7 @@ func (s *modelSuite) testPostOfflineRemodel(c *check.C, params *testPostOfflineR
 }
 
 func (s *modelSuite) TestPostOfflineRemodelWithComponents(c *check.C) {
-	s.expectRootAccess()
+	s.expectManageSystemAccess()
 
 	oldModel := s.Brands.Model("my-brand", "my-old-model", modelDefaults)
 	newModel := s.Brands.Model("my-brand", "my-old-model", modelDefaults, map[string]any{
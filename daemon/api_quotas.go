@@ -113,6 +113,7 @@ func createQuotaValues(grp *quota.Group) *client.QuotaValues {
 				RatePeriod: grp.JournalLimit.RatePeriod,
 			}
 		}
+		constraints.Journal.Forward = grp.JournalLimit.Forward
 	}
 	return &constraints
 }
@@ -222,6 +223,13 @@ func quotaValuesToResources(values client.QuotaValues) quota.Resources {
 		if values.Journal.QuotaJournalRate != nil {
 			resourcesBuilder.WithJournalRate(values.Journal.RateCount, values.Journal.RatePeriod)
 		}
+		switch values.Journal.Forward {
+		case "":
+		case "none":
+			resourcesBuilder.WithJournalForward("")
+		default:
+			resourcesBuilder.WithJournalForward(values.Journal.Forward)
+		}
 	}
 	return resourcesBuilder.Build()
 }
@@ -294,7 +302,7 @@ func postQuotaGroup(c *Command, r *http.Request, _ *auth.UserState) Response {
 		return BadRequest("unknown quota action %q", data.Action)
 	}
 
-	chg := newChange(st, quoteControlChangeKind, chgSummary, []*state.TaskSet{ts}, data.Snaps)
+	chg := newChange(r.Context(), st, quoteControlChangeKind, chgSummary, []*state.TaskSet{ts}, data.Snaps)
 	ensureStateSoon(st)
 	return AsyncResponse(nil, chg.ID())
 }
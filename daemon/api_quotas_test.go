@@ -90,6 +90,7 @@ func (s *apiQuotaSuite) TestCreateQuotaValues(c *check.C) {
 			WithCPUSet([]int{0, 1}).
 			WithJournalRate(150, time.Second).
 			WithJournalSize(quantity.SizeMiB).
+			WithJournalForward("syslog://logs.example.com").
 			Build())
 	allGroups, err2 := servicestate.AllQuotas(st)
 	st.Unlock()
@@ -117,6 +118,7 @@ func (s *apiQuotaSuite) TestCreateQuotaValues(c *check.C) {
 			RateCount:  150,
 			RatePeriod: time.Second,
 		},
+		Forward: "syslog://logs.example.com",
 	})
 }
 
@@ -382,6 +384,56 @@ func (s *apiQuotaSuite) TestPostEnsureQuotaUpdateCpuHappy(c *check.C) {
 	c.Assert(s.ensureSoonCalled, check.Equals, 1)
 }
 
+func (s *apiQuotaSuite) TestPostEnsureQuotaUpdateJournalForward(c *check.C) {
+	st := s.d.Overlord().State()
+	st.Lock()
+	err := servicestatetest.MockQuotaInState(st, "ginger-ale", "", nil, nil,
+		quota.NewResourcesBuilder().
+			WithJournalSize(quantity.SizeMiB).
+			WithJournalForward("syslog://logs.example.com").
+			Build())
+	st.Unlock()
+	c.Assert(err, check.IsNil)
+
+	for _, t := range []struct {
+		forward string
+		limits  quota.Resources
+	}{
+		{"https://logs.example.com", quota.NewResourcesBuilder().WithJournalForward("https://logs.example.com").Build()},
+		// none disables forwarding
+		{"none", quota.NewResourcesBuilder().WithJournalForward("").Build()},
+	} {
+		updateCalled := 0
+		r := daemon.MockServicestateUpdateQuota(func(st *state.State, name string, opts servicestate.UpdateQuotaOptions) (*state.TaskSet, error) {
+			updateCalled++
+			c.Assert(name, check.Equals, "ginger-ale")
+			c.Assert(opts, check.DeepEquals, servicestate.UpdateQuotaOptions{
+				NewResourceLimits: t.limits,
+			})
+			ts := state.NewTaskSet(st.NewTask("foo-quota", "..."))
+			return ts, nil
+		})
+		defer r()
+
+		data, err := json.Marshal(daemon.PostQuotaGroupData{
+			Action:    "ensure",
+			GroupName: "ginger-ale",
+			Constraints: client.QuotaValues{
+				Journal: &client.QuotaJournalValues{
+					Forward: t.forward,
+				},
+			},
+		})
+		c.Assert(err, check.IsNil)
+
+		req, err := http.NewRequest("POST", "/v2/quotas", bytes.NewBuffer(data))
+		c.Assert(err, check.IsNil)
+		rsp := s.asyncReq(c, req, nil, actionIsExpected)
+		c.Assert(rsp.Status, check.Equals, 202)
+		c.Check(updateCalled, check.Equals, 1)
+	}
+}
+
 func (s *apiQuotaSuite) TestPostEnsureQuotaUpdateCpu2Happy(c *check.C) {
 	st := s.d.Overlord().State()
 	st.Lock()
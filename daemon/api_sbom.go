@@ -0,0 +1,160 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/assertstate"
+	"github.com/snapcore/snapd/overlord/auth"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/sbom"
+)
+
+var sbomCmd = &Command{
+	Path:       "/v2/sbom",
+	GET:        getSBOM,
+	ReadAccess: authenticatedAccess{},
+}
+
+var (
+	snapFileSHA3_384 = asserts.SnapFileSHA3_384
+	sbomTimeNow      = time.Now
+)
+
+func getSBOM(c *Command, r *http.Request, user *auth.UserState) Response {
+	format := sbom.Format(r.URL.Query().Get("format"))
+	switch format {
+	case "":
+		format = sbom.FormatSPDX
+	case sbom.FormatSPDX, sbom.FormatCycloneDX:
+		// valid
+	default:
+		return BadRequest("invalid format %q, expected %q or %q", format, sbom.FormatSPDX, sbom.FormatCycloneDX)
+	}
+
+	st := c.d.overlord.State()
+	st.Lock()
+	inv, snapFiles, err := sbomInventory(st)
+	st.Unlock()
+	if err != nil {
+		return InternalError("cannot collect installed software: %v", err)
+	}
+
+	// snaps without a snap-revision assertion need their digest computed
+	// from the snap file, which is done without holding the state lock
+	for i := range inv.Snaps {
+		sn := &inv.Snaps[i]
+		snapFile, ok := snapFiles[sn.Name]
+		if !ok {
+			continue
+		}
+		digest, _, err := snapFileSHA3_384(snapFile)
+		if err != nil {
+			logger.Noticef("cannot compute digest of snap %q for the bill of materials: %v", sn.Name, err)
+			continue
+		}
+		sn.SHA3_384 = digest
+	}
+
+	doc, err := sbom.Generate(inv, format)
+	if err != nil {
+		return InternalError("%v", err)
+	}
+	return SyncResponse(doc)
+}
+
+// sbomInventory collects the installed snaps and the validation sets in
+// force from the state. It also returns the snap files of the snaps whose
+// digest is not known from a snap-revision assertion.
+func sbomInventory(st *state.State) (inv *sbom.Inventory, snapFiles map[string]string, err error) {
+	snapStates, err := snapstate.All(st)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db := assertstate.DB(st)
+	inv = &sbom.Inventory{}
+	snapFiles = make(map[string]string)
+	for name, snapst := range snapStates {
+		info, err := snapst.CurrentInfo()
+		if err != nil {
+			return nil, nil, err
+		}
+		publisher, err := assertstate.PublisherStoreAccount(st, info.SnapID)
+		if err != nil {
+			return nil, nil, err
+		}
+		sn := sbom.Snap{
+			Name:      name,
+			SnapID:    info.SnapID,
+			Version:   info.Version,
+			Revision:  info.Revision,
+			Type:      info.Type(),
+			Base:      info.Base,
+			Channel:   snapst.TrackingChannel,
+			Publisher: publisher,
+		}
+		if info.SnapID != "" {
+			revs, err := db.FindMany(asserts.SnapRevisionType, map[string]string{
+				"snap-id":       info.SnapID,
+				"snap-revision": info.Revision.String(),
+			})
+			if err != nil && !errors.Is(err, &asserts.NotFoundError{}) {
+				return nil, nil, err
+			}
+			if len(revs) > 0 {
+				sn.SHA3_384 = revs[0].(*asserts.SnapRevision).SnapSHA3_384()
+			}
+		}
+		if sn.SHA3_384 == "" {
+			snapFiles[name] = info.MountFile()
+		}
+		inv.Snaps = append(inv.Snaps, sn)
+	}
+	sort.Slice(inv.Snaps, func(i, j int) bool { return inv.Snaps[i].Name < inv.Snaps[j].Name })
+
+	tracking, err := assertstate.ValidationSets(st)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, vs := range tracking {
+		if vs.Mode != assertstate.Enforce {
+			continue
+		}
+		inv.ValidationSets = append(inv.ValidationSets, sbom.ValidationSet{
+			AccountID: vs.AccountID,
+			Name:      vs.Name,
+			Sequence:  vs.Sequence(),
+		})
+	}
+	sort.Slice(inv.ValidationSets, func(i, j int) bool {
+		return inv.ValidationSets[i].String() < inv.ValidationSets[j].String()
+	})
+	inv.Timestamp = sbomTimeNow()
+
+	return inv, snapFiles, nil
+}
@@ -0,0 +1,200 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"time"
+
+	"golang.org/x/crypto/sha3"
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/daemon"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/assertstate"
+	"github.com/snapcore/snapd/snap"
+)
+
+var _ = check.Suite(&sbomSuite{})
+
+type sbomSuite struct {
+	apiBaseSuite
+
+	digestCalls []string
+}
+
+// sha3-384 of the empty string, encoded as in assertions
+const emptySHA3_384 = "DGOnW4ReT30BEH2FLkwkhcUaUKqqlPxhmV5xu-6YOirDcTgxJkrbR_tr0eBY1fAE"
+
+func (s *sbomSuite) SetUpTest(c *check.C) {
+	s.apiBaseSuite.SetUpTest(c)
+
+	s.expectReadAccess(daemon.AuthenticatedAccess{})
+
+	s.digestCalls = nil
+	s.AddCleanup(daemon.MockSnapFileSHA3_384(func(snapPath string) (string, uint64, error) {
+		s.digestCalls = append(s.digestCalls, snapPath)
+		return emptySHA3_384, 0, nil
+	}))
+	s.AddCleanup(daemon.MockSBOMTimeNow(func() time.Time {
+		return time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	}))
+}
+
+func (s *sbomSuite) mockInstalled(c *check.C) (fooDigest string) {
+	d := s.daemon(c)
+
+	foo := s.mkInstalledInState(c, d, "foo", "bar", "v1", snap.R(10), true, "base: core22")
+	s.mkInstalledInState(c, d, "core22", "canonical", "20240111", snap.R(20), true, "type: base")
+	s.mkInstalledInState(c, d, "local", "", "1.0", snap.R(-1), true, "")
+
+	st := d.Overlord().State()
+	st.Lock()
+	assertstate.UpdateValidationSet(st, &assertstate.ValidationSetTracking{
+		AccountID: "foo",
+		Name:      "enforced",
+		Mode:      assertstate.Enforce,
+		PinnedAt:  3,
+		Current:   3,
+	})
+	assertstate.UpdateValidationSet(st, &assertstate.ValidationSetTracking{
+		AccountID: "foo",
+		Name:      "monitored",
+		Mode:      assertstate.Monitor,
+		Current:   1,
+	})
+	st.Unlock()
+
+	content, err := os.ReadFile(foo.MountFile())
+	c.Assert(err, check.IsNil)
+	h := sha3.Sum384(content)
+	return hex.EncodeToString(h[:])
+}
+
+func sbomResult(c *check.C, rsp *daemon.RespJSON) map[string]any {
+	data, err := json.Marshal(rsp.Result)
+	c.Assert(err, check.IsNil)
+	var doc map[string]any
+	c.Assert(json.Unmarshal(data, &doc), check.IsNil)
+	return doc
+}
+
+func (s *sbomSuite) TestGetSBOMSPDX(c *check.C) {
+	fooDigest := s.mockInstalled(c)
+
+	req, err := http.NewRequest("GET", "/v2/sbom", nil)
+	c.Assert(err, check.IsNil)
+	rsp := s.syncReq(c, req, nil, actionIsUnexpected)
+	doc := sbomResult(c, rsp)
+
+	c.Check(doc["spdxVersion"], check.Equals, "SPDX-2.3")
+	c.Check(doc["creationInfo"].(map[string]any)["created"], check.Equals, "2026-10-16T12:00:00Z")
+
+	pkgs := doc["packages"].([]any)
+	c.Assert(pkgs, check.HasLen, 3)
+	core22 := pkgs[0].(map[string]any)
+	c.Check(core22["name"], check.Equals, "core22")
+	c.Check(core22["supplier"], check.Equals, "Organization: canonical")
+	foo := pkgs[1].(map[string]any)
+	c.Check(foo["name"], check.Equals, "foo")
+	c.Check(foo["versionInfo"], check.Equals, "v1")
+	c.Check(foo["supplier"], check.Equals, "Organization: bar")
+	c.Check(foo["checksums"], check.DeepEquals, []any{
+		map[string]any{"algorithm": "SHA3-384", "checksumValue": fooDigest},
+	})
+	c.Check(foo["externalRefs"].([]any)[0].(map[string]any)["referenceLocator"], check.Equals, "pkg:snap/foo@v1?channel=stable&revision=10")
+	local := pkgs[2].(map[string]any)
+	c.Check(local["name"], check.Equals, "local")
+	c.Check(local["supplier"], check.Equals, "NOASSERTION")
+	c.Check(local["checksums"], check.DeepEquals, []any{
+		map[string]any{"algorithm": "SHA3-384", "checksumValue": "0c63a75b845e4f7d01107d852e4c2485c51a50aaaa94fc61995e71bbee983a2ac3713831264adb47fb6bd1e058d5f004"},
+	})
+
+	c.Check(doc["relationships"], check.DeepEquals, []any{
+		map[string]any{"spdxElementId": "SPDXRef-DOCUMENT", "relationshipType": "DESCRIBES", "relatedSpdxElement": "SPDXRef-Snap-core22"},
+		map[string]any{"spdxElementId": "SPDXRef-DOCUMENT", "relationshipType": "DESCRIBES", "relatedSpdxElement": "SPDXRef-Snap-foo"},
+		map[string]any{"spdxElementId": "SPDXRef-Snap-foo", "relationshipType": "DEPENDS_ON", "relatedSpdxElement": "SPDXRef-Snap-core22"},
+		map[string]any{"spdxElementId": "SPDXRef-DOCUMENT", "relationshipType": "DESCRIBES", "relatedSpdxElement": "SPDXRef-Snap-local"},
+	})
+	annotations := doc["annotations"].([]any)
+	c.Assert(annotations, check.HasLen, 1)
+	c.Check(annotations[0].(map[string]any)["comment"], check.Equals, "validation-set: foo/enforced=3")
+
+	// only the digest of the snap without a snap-revision assertion is
+	// computed from its file
+	c.Check(s.digestCalls, check.HasLen, 1)
+	c.Check(s.digestCalls[0], check.Matches, `.*/local_x1\.snap`)
+}
+
+func (s *sbomSuite) TestGetSBOMCycloneDX(c *check.C) {
+	s.mockInstalled(c)
+
+	req, err := http.NewRequest("GET", "/v2/sbom?format=cyclonedx", nil)
+	c.Assert(err, check.IsNil)
+	rsp := s.syncReq(c, req, nil, actionIsUnexpected)
+	doc := sbomResult(c, rsp)
+
+	c.Check(doc["bomFormat"], check.Equals, "CycloneDX")
+	c.Check(doc["metadata"].(map[string]any)["properties"], check.DeepEquals, []any{
+		map[string]any{"name": "snap:validation-set", "value": "foo/enforced=3"},
+	})
+	components := doc["components"].([]any)
+	c.Assert(components, check.HasLen, 3)
+	c.Check(components[0].(map[string]any)["type"], check.Equals, "operating-system")
+	c.Check(components[1].(map[string]any)["publisher"], check.Equals, "bar")
+	c.Check(doc["dependencies"], check.DeepEquals, []any{
+		map[string]any{"ref": "snap:foo", "dependsOn": []any{"snap:core22"}},
+	})
+}
+
+func (s *sbomSuite) TestGetSBOMDigestError(c *check.C) {
+	logbuf, restore := logger.MockLogger()
+	defer restore()
+	restore = daemon.MockSnapFileSHA3_384(func(snapPath string) (string, uint64, error) {
+		return "", 0, errors.New("boom")
+	})
+	defer restore()
+
+	s.mockInstalled(c)
+
+	req, err := http.NewRequest("GET", "/v2/sbom?format=spdx", nil)
+	c.Assert(err, check.IsNil)
+	rsp := s.syncReq(c, req, nil, actionIsUnexpected)
+	doc := sbomResult(c, rsp)
+
+	local := doc["packages"].([]any)[2].(map[string]any)
+	c.Check(local["name"], check.Equals, "local")
+	c.Check(local["checksums"], check.IsNil)
+	c.Check(logbuf.String(), check.Matches, `(?s).*cannot compute digest of snap "local" for the bill of materials: boom.*`)
+}
+
+func (s *sbomSuite) TestGetSBOMBadFormat(c *check.C) {
+	s.daemon(c)
+
+	req, err := http.NewRequest("GET", "/v2/sbom?format=swid", nil)
+	c.Assert(err, check.IsNil)
+	rspe := s.errorReq(c, req, nil, actionIsUnexpected)
+	c.Check(rspe.Status, check.Equals, 400)
+	c.Check(rspe.Message, check.Equals, `invalid format "swid", expected "spdx" or "cyclonedx"`)
+}
@@ -174,7 +174,7 @@ func sideloadOrTrySnap(ctx context.Context, c *Command, body io.ReadCloser, boun
 		if len(form.Values["snap-path"]) == 0 {
 			return BadRequest("need 'snap-path' value in form")
 		}
-		return trySnap(c.d.overlord.State(), form.Values["snap-path"][0], flags)
+		return trySnap(ctx, c.d.overlord.State(), form.Values["snap-path"][0], flags)
 	}
 
 	if len(form.Values["quota-group"]) > 0 {
@@ -187,6 +187,7 @@ func sideloadOrTrySnap(ctx context.Context, c *Command, body io.ReadCloser, boun
 	flags.RemoveSnapPath = true
 	flags.Unaliased = isTrue(form, "unaliased")
 	flags.IgnoreRunning = isTrue(form, "ignore-running")
+	flags.NoRestoreConnections = isTrue(form, "no-restore-connections")
 	trasactionVals := form.Values["transaction"]
 	flags.Transaction = client.TransactionPerSnap
 	if len(trasactionVals) > 0 {
@@ -445,7 +446,7 @@ func sideloadManySnaps(ctx context.Context, st *state.State, uploads []*uploaded
 
 	msg := multiPathInstallMessage(slInfo)
 
-	chg := newChange(st, installSnapChangeKind, msg, tss, snapNames)
+	chg := newChange(ctx, st, installSnapChangeKind, msg, tss, snapNames)
 	apiData := make(map[string]any, 0)
 
 	if len(snapNames) > 0 {
@@ -520,7 +521,7 @@ func multiPathInstallMessage(sli *sideloadedInfo) string {
 	return b.String()
 }
 
-func sideloadSnap(_ context.Context, st *state.State, upload *uploadedContainer, flags sideloadFlags) (*state.Change, *apiError) {
+func sideloadSnap(ctx context.Context, st *state.State, upload *uploadedContainer, flags sideloadFlags) (*state.Change, *apiError) {
 	var instanceName string
 	if upload.instanceName != "" {
 		// caller has specified desired instance name
@@ -596,7 +597,7 @@ func sideloadSnap(_ context.Context, st *state.State, upload *uploadedContainer,
 	}
 
 	msg := fmt.Sprintf(i18n.G("Install %s from file %q"), message, upload.filename)
-	chg := newChange(st, changeType, msg, []*state.TaskSet{tset}, []string{instanceName})
+	chg := newChange(ctx, st, changeType, msg, []*state.TaskSet{tset}, []string{instanceName})
 	apiData := map[string]any{}
 	if compInfo == nil {
 		apiData = map[string]any{
@@ -963,7 +964,7 @@ func writeToTempFile(reader io.Reader) (path string, err error) {
 	return tmpf.Name(), nil
 }
 
-func trySnap(st *state.State, trydir string, flags snapstate.Flags) Response {
+func trySnap(ctx context.Context, st *state.State, trydir string, flags snapstate.Flags) Response {
 	st.Lock()
 	defer st.Unlock()
 
@@ -994,7 +995,7 @@ func trySnap(st *state.State, trydir string, flags snapstate.Flags) Response {
 	}
 
 	msg := fmt.Sprintf(i18n.G("Try %q snap from %s"), info.InstanceName(), trydir)
-	chg := newChange(st, trySnapChangeKind, msg, []*state.TaskSet{tset}, []string{info.InstanceName()})
+	chg := newChange(ctx, st, trySnapChangeKind, msg, []*state.TaskSet{tset}, []string{info.InstanceName()})
 	chg.Set("api-data", map[string]any{
 		"snap-name":  info.InstanceName(),
 		"snap-names": []string{info.InstanceName()},
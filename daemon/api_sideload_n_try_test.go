@@ -2415,7 +2415,7 @@ func (s *trySuite) TestTrySnapRelative(c *check.C) {
 	d := s.daemon(c)
 	st := d.Overlord().State()
 
-	rspe := daemon.TrySnap(st, "relative-path", snapstate.Flags{}).(*daemon.APIError)
+	rspe := daemon.TrySnap(context.Background(), st, "relative-path", snapstate.Flags{}).(*daemon.APIError)
 	c.Check(rspe.Message, testutil.Contains, "need an absolute path")
 }
 
@@ -2423,7 +2423,7 @@ func (s *trySuite) TestTrySnapNotDir(c *check.C) {
 	d := s.daemon(c)
 	st := d.Overlord().State()
 
-	rspe := daemon.TrySnap(st, "/does/not/exist", snapstate.Flags{}).(*daemon.APIError)
+	rspe := daemon.TrySnap(context.Background(), st, "/does/not/exist", snapstate.Flags{}).(*daemon.APIError)
 	c.Check(rspe.Message, testutil.Contains, "not a snap directory")
 }
 
@@ -2442,7 +2442,7 @@ func (s *trySuite) TestTryChangeConflict(c *check.C) {
 		return nil, &snapstate.ChangeConflictError{Snap: "foo"}
 	})()
 
-	rspe := daemon.TrySnap(st, tryDir, snapstate.Flags{}).(*daemon.APIError)
+	rspe := daemon.TrySnap(context.Background(), st, tryDir, snapstate.Flags{}).(*daemon.APIError)
 	c.Check(rspe.Kind, check.Equals, client.ErrorKindSnapChangeConflict)
 }
 
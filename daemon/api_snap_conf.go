@@ -170,7 +170,7 @@ func setSnapConf(c *Command, r *http.Request, user *auth.UserState) Response {
 	}
 
 	summary := fmt.Sprintf("Change configuration of %q snap", snapName)
-	change := newChange(st, configureSnapChangeKind, summary, []*state.TaskSet{taskset}, []string{snapName})
+	change := newChange(r.Context(), st, configureSnapChangeKind, summary, []*state.TaskSet{taskset}, []string{snapName})
 
 	st.EnsureBefore(0)
 
@@ -45,6 +45,7 @@ import (
 	"github.com/snapcore/snapd/snap/channel"
 	"github.com/snapcore/snapd/snap/naming"
 	"github.com/snapcore/snapd/strutil"
+	"github.com/snapcore/snapd/timeutil"
 )
 
 const (
@@ -211,7 +212,7 @@ func postSnap(c *Command, r *http.Request, user *auth.UserState) Response {
 		return BadRequest("unknown action %s", inst.Action)
 	}
 
-	chg := newChange(st, changeKind, res.Summary, res.Tasksets, res.Affected)
+	chg := newChange(r.Context(), st, changeKind, res.Summary, res.Tasksets, res.Affected)
 	if len(res.Tasksets) == 0 {
 		chg.SetStatus(state.DoneStatus)
 	}
@@ -277,8 +278,11 @@ type snapInstruction struct {
 	IgnoreRunning          bool                             `json:"ignore-running"`
 	Unaliased              bool                             `json:"unaliased"`
 	Prefer                 bool                             `json:"prefer"`
+	NoRestoreConnections   bool                             `json:"no-restore-connections"`
 	Purge                  bool                             `json:"purge,omitempty"`
 	Terminate              bool                             `json:"terminate"`
+	KeepConfig             bool                             `json:"keep-config"`
+	KeepConnections        bool                             `json:"keep-connections"`
 	SystemRestartImmediate bool                             `json:"system-restart-immediate"`
 	Transaction            client.TransactionType           `json:"transaction"`
 	Snaps                  []string                         `json:"snaps"`
@@ -288,6 +292,7 @@ type snapInstruction struct {
 	QuotaGroupName         string                           `json:"quota-group"`
 	Time                   string                           `json:"time"`
 	HoldLevel              string                           `json:"hold-level"`
+	HoldWindows            string                           `json:"hold-windows"`
 
 	// The fields below should not be unmarshalled into. Do not export them.
 	userID int
@@ -337,6 +342,9 @@ func (inst *snapInstruction) installFlags() (snapstate.Flags, error) {
 	if inst.Prefer {
 		flags.Prefer = true
 	}
+	if inst.NoRestoreConnections {
+		flags.NoRestoreConnections = true
+	}
 	flags.QuotaGroupName = inst.QuotaGroupName
 
 	return flags, nil
@@ -436,6 +444,14 @@ func (inst *snapInstruction) validate() error {
 		} else if !(inst.HoldLevel == "auto-refresh" || inst.HoldLevel == "general") {
 			return errors.New(`hold action requires hold-level to be either "auto-refresh" or "general"`)
 		}
+		if inst.HoldWindows != "" {
+			if len(inst.Snaps) == 0 {
+				return errors.New("hold windows can only be specified when holding specific snaps")
+			}
+			if _, err := timeutil.ParseSchedule(inst.HoldWindows); err != nil {
+				return fmt.Errorf("cannot parse hold windows: %v", err)
+			}
+		}
 	}
 
 	if inst.Action != holdCmdAction {
@@ -445,6 +461,9 @@ func (inst *snapInstruction) validate() error {
 		if inst.HoldLevel != "" {
 			return errors.New(`hold-level can only be specified for the "hold" action`)
 		}
+		if inst.HoldWindows != "" {
+			return errors.New(`hold-windows can only be specified for the "hold" action`)
+		}
 	}
 
 	if inst.Unaliased && inst.Prefer {
@@ -453,6 +472,9 @@ func (inst *snapInstruction) validate() error {
 	if inst.Prefer && inst.Action != installCmdAction {
 		return fmt.Errorf("the prefer flag can only be specified on install")
 	}
+	if inst.NoRestoreConnections && inst.Action != installCmdAction {
+		return fmt.Errorf("the no-restore-connections flag can only be specified on install")
+	}
 
 	if inst.Terminate && inst.Action != removeCmdAction {
 		return fmt.Errorf(`terminate can only be specified for the "remove" action`)
@@ -461,6 +483,13 @@ func (inst *snapInstruction) validate() error {
 		return fmt.Errorf(`terminate can only be specified when revision is unset`)
 	}
 
+	if inst.KeepConfig && inst.Action != removeCmdAction {
+		return fmt.Errorf(`keep-config can only be specified for the "remove" action`)
+	}
+	if inst.KeepConnections && inst.Action != removeCmdAction {
+		return fmt.Errorf(`keep-connections can only be specified for the "remove" action`)
+	}
+
 	if err := inst.validateSnapshotOptions(); err != nil {
 		return err
 	}
@@ -661,7 +690,12 @@ func snapRemove(_ context.Context, inst *snapInstruction, st *state.State) (*sna
 }
 
 func removeSnap(inst *snapInstruction, st *state.State) (*snapInstructionResult, error) {
-	flags := &snapstate.RemoveFlags{Purge: inst.Purge, Terminate: inst.Terminate}
+	flags := &snapstate.RemoveFlags{
+		Purge:           inst.Purge,
+		Terminate:       inst.Terminate,
+		KeepConfig:      inst.KeepConfig,
+		KeepConnections: inst.KeepConnections,
+	}
 	ts, err := snapstateRemove(st, inst.Snaps[0], inst.Revision, flags)
 	if err != nil {
 		return nil, err
@@ -840,7 +874,7 @@ func snapOpMany(c *Command, r *http.Request, user *auth.UserState) Response {
 	}
 
 	// TODO: inst.Amend, etc?
-	if inst.Channel != "" || !inst.Revision.Unset() || inst.DevMode || inst.JailMode || inst.CohortKey != "" || inst.LeaveCohort || inst.Prefer {
+	if inst.Channel != "" || !inst.Revision.Unset() || inst.DevMode || inst.JailMode || inst.CohortKey != "" || inst.LeaveCohort || inst.Prefer || inst.NoRestoreConnections {
 		return BadRequest("unsupported option provided for multi-snap operation")
 	}
 	if len(inst.CompsRaw) > 0 {
@@ -877,7 +911,7 @@ func snapOpMany(c *Command, r *http.Request, user *auth.UserState) Response {
 		return BadRequest("unknown action %s", inst.Action)
 	}
 
-	chg := newChange(st, changeKind, res.Summary, res.Tasksets, res.Affected)
+	chg := newChange(r.Context(), st, changeKind, res.Summary, res.Tasksets, res.Affected)
 	if len(res.Tasksets) == 0 {
 		chg.SetStatus(state.DoneStatus)
 	}
@@ -1236,7 +1270,12 @@ func snapRemoveMany(_ context.Context, inst *snapInstruction, st *state.State) (
 		}
 	}
 	if len(inst.Snaps) > 0 {
-		flags := &snapstate.RemoveFlags{Purge: inst.Purge, Terminate: inst.Terminate}
+		flags := &snapstate.RemoveFlags{
+			Purge:           inst.Purge,
+			Terminate:       inst.Terminate,
+			KeepConfig:      inst.KeepConfig,
+			KeepConnections: inst.KeepConnections,
+		}
 		removedSnaps, snapsTaskSets, err = snapstateRemoveMany(st, inst.Snaps, flags)
 		if err != nil {
 			return nil, err
@@ -1366,14 +1405,24 @@ func snapHoldMany(_ context.Context, inst *snapInstruction, st *state.State) (re
 		msg = i18n.G("Hold auto-refreshes for all snaps")
 	} else {
 		holdLevel := inst.holdLevel()
-		if err := snapstateHoldRefreshesBySystem(st, holdLevel, inst.Time, inst.Snaps); err != nil {
-			return nil, err
+		if inst.HoldWindows != "" {
+			if err := snapstateHoldRefreshesBySystemInWindows(st, holdLevel, inst.Time, inst.HoldWindows, inst.Snaps); err != nil {
+				return nil, err
+			}
+		} else {
+			if err := snapstateHoldRefreshesBySystem(st, holdLevel, inst.Time, inst.Snaps); err != nil {
+				return nil, err
+			}
 		}
 		msgFmt := i18n.G("Hold general refreshes for %s")
 		if holdLevel == snapstate.HoldAutoRefresh {
 			msgFmt = i18n.G("Hold auto-refreshes for %s")
 		}
 		msg = fmt.Sprintf(msgFmt, strutil.Quoted(inst.Snaps))
+		if inst.HoldWindows != "" {
+			// TRANSLATORS: %s is a schedule like "mon-fri,9:00-17:00"
+			msg += fmt.Sprintf(i18n.G(" during %s"), inst.HoldWindows)
+		}
 	}
 
 	return &snapInstructionResult{
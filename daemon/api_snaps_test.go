@@ -478,13 +478,14 @@ func (s *snapsSuite) TestPostSnapsNoWeirdses(c *check.C) {
 	// one could add more actions here ... 🤷
 	for _, action := range []string{"install", "refresh", "remove"} {
 		for weird, v := range map[string]string{
-			"channel":      `"beta"`,
-			"revision":     `"1"`,
-			"devmode":      "true",
-			"jailmode":     "true",
-			"cohort-key":   `"what"`,
-			"leave-cohort": "true",
-			"prefer":       "true",
+			"channel":                `"beta"`,
+			"revision":               `"1"`,
+			"devmode":                "true",
+			"jailmode":               "true",
+			"cohort-key":             `"what"`,
+			"leave-cohort":           "true",
+			"prefer":                 "true",
+			"no-restore-connections": "true",
 		} {
 			buf := strings.NewReader(fmt.Sprintf(`{"action": "%s","snaps":["foo","bar"], "%s": %s}`, action, weird, v))
 			req, err := http.NewRequest("POST", "/v2/snaps", buf)
@@ -575,6 +576,36 @@ func (s *snapsSuite) TestPostSnapsRemoveWithTerminate(c *check.C) {
 	c.Assert(snapstateRemoveCalled, check.Equals, 1)
 }
 
+func (s *snapsSuite) TestPostSnapsRemoveWithKeepConfig(c *check.C) {
+	d := s.daemonWithOverlordMockAndStore()
+
+	var snapstateRemoveCalled int
+	defer daemon.MockSnapstateRemove(func(st *state.State, name string, revision snap.Revision, flags *snapstate.RemoveFlags) (*state.TaskSet, error) {
+		snapstateRemoveCalled++
+		c.Check(name, check.Equals, "foo")
+		c.Check(flags.KeepConfig, check.Equals, true)
+		c.Check(flags.KeepConnections, check.Equals, true)
+		t := st.NewTask("fake-remove", "Remove one")
+		return state.NewTaskSet(t), nil
+	})()
+
+	buf := strings.NewReader(`{"action": "remove", "keep-config": true, "keep-connections": true}`)
+	req, err := http.NewRequest("POST", "/v2/snaps/foo", buf)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Content-Type", "application/json")
+
+	rsp := s.jsonReq(c, req, nil, actionIsExpected)
+	c.Check(rsp.Status, check.Equals, 202)
+
+	st := d.Overlord().State()
+	st.Lock()
+	defer st.Unlock()
+	chg := st.Change(rsp.Change)
+	c.Check(chg.Summary(), check.Equals, `Remove "foo" snap`)
+
+	c.Assert(snapstateRemoveCalled, check.Equals, 1)
+}
+
 func (s *snapsSuite) TestPostSnapsRemoveManyWithTerminate(c *check.C) {
 	d := s.daemonWithOverlordMockAndStore()
 
@@ -1064,6 +1095,26 @@ func (s *snapsSuite) TestRemoveManyWithPurge(c *check.C) {
 	c.Check(res.Summary, check.Equals, `Remove snaps "foo", "bar"`)
 	c.Check(res.Affected, check.DeepEquals, inst.Snaps)
 }
+func (s *snapsSuite) TestRemoveManyWithKeepConfig(c *check.C) {
+	defer daemon.MockSnapstateRemoveMany(func(s *state.State, names []string, opts *snapstate.RemoveFlags) ([]string, []*state.TaskSet, error) {
+		c.Check(names, check.HasLen, 2)
+		c.Check(opts.KeepConfig, check.Equals, true)
+		c.Check(opts.KeepConnections, check.Equals, false)
+		t := s.NewTask("fake-remove-2", "Remove two")
+		return names, []*state.TaskSet{state.NewTaskSet(t)}, nil
+	})()
+
+	d := s.daemon(c)
+	inst := &daemon.SnapInstruction{Action: "remove", KeepConfig: true, Snaps: []string{"foo", "bar"}}
+	st := d.Overlord().State()
+	st.Lock()
+	res, err := inst.DispatchForMany()(context.Background(), inst, st)
+	st.Unlock()
+	c.Assert(err, check.IsNil)
+	c.Check(res.Summary, check.Equals, `Remove snaps "foo", "bar"`)
+	c.Check(res.Affected, check.DeepEquals, inst.Snaps)
+}
+
 func (s *snapsSuite) TestSnapInfoOneIntegration(c *check.C) {
 	s.expectSnapsNameReadAccess()
 	d := s.daemon(c)
@@ -2174,6 +2225,47 @@ func (s *snapsSuite) TestPostSnapPreferWrongAction(c *check.C) {
 	}
 }
 
+func (s *snapsSuite) TestPostSnapNoRestoreConnectionsWrongAction(c *check.C) {
+	s.daemonWithOverlordMock()
+	const expectedErr = "the no-restore-connections flag can only be specified on install"
+
+	for _, action := range []string{"remove", "refresh", "revert", "enable", "disable", "xyzzy"} {
+		buf := strings.NewReader(fmt.Sprintf(`{"action": "%s", "no-restore-connections": true}`, action))
+		req, err := http.NewRequest("POST", "/v2/snaps/some-snap", buf)
+		c.Assert(err, check.IsNil)
+
+		rspe := s.errorReq(c, req, nil, action != "xyzzy")
+		c.Check(rspe.Status, check.Equals, 400, check.Commentf("%q", action))
+		c.Check(rspe.Message, check.Equals, expectedErr, check.Commentf("%q", action))
+	}
+}
+
+func (s *snapsSuite) TestInstallNoRestoreConnections(c *check.C) {
+	var calledFlags snapstate.Flags
+
+	defer daemon.MockSnapstateInstallWithGoal(func(ctx context.Context, st *state.State, g snapstate.InstallGoal, opts snapstate.Options) ([]*snap.Info, []*state.TaskSet, error) {
+		calledFlags = opts.Flags
+
+		t := st.NewTask("fake-install-snap", "Doing a fake install")
+		return []*snap.Info{{}}, []*state.TaskSet{state.NewTaskSet(t)}, nil
+	})()
+
+	d := s.daemon(c)
+	inst := &daemon.SnapInstruction{
+		Action:               "install",
+		NoRestoreConnections: true,
+		Snaps:                []string{"fake"},
+	}
+
+	st := d.Overlord().State()
+	st.Lock()
+	defer st.Unlock()
+	_, err := inst.Dispatch()(context.Background(), inst, st)
+	c.Check(err, check.IsNil)
+
+	c.Check(calledFlags.NoRestoreConnections, check.Equals, true)
+}
+
 func (s *snapsSuite) TestPostSnapTerminateWrongAction(c *check.C) {
 	s.daemonWithOverlordMock()
 	const expectedErr = `terminate can only be specified for the "remove" action`
@@ -2189,6 +2281,23 @@ func (s *snapsSuite) TestPostSnapTerminateWrongAction(c *check.C) {
 	}
 }
 
+func (s *snapsSuite) TestPostSnapKeepConfigWrongAction(c *check.C) {
+	s.daemonWithOverlordMock()
+
+	for _, opt := range []string{"keep-config", "keep-connections"} {
+		expectedErr := fmt.Sprintf(`%s can only be specified for the "remove" action`, opt)
+		for _, action := range []string{"install", "refresh", "revert", "enable", "disable", "xyzzy"} {
+			buf := strings.NewReader(fmt.Sprintf(`{"action": "%s", "%s": true}`, action, opt))
+			req, err := http.NewRequest("POST", "/v2/snaps/some-snap", buf)
+			c.Assert(err, check.IsNil)
+
+			rspe := s.errorReq(c, req, nil, action != "xyzzy")
+			c.Check(rspe.Status, check.Equals, 400, check.Commentf("%q", action))
+			c.Check(rspe.Message, check.Equals, expectedErr, check.Commentf("%q", action))
+		}
+	}
+}
+
 func (s *snapsSuite) TestPostSnapTerminateWithRevisionSet(c *check.C) {
 	s.daemonWithOverlordMock()
 
@@ -3968,6 +4077,62 @@ func (s *snapsSuite) TestOnlyAllowHoldLevelParamForHold(c *check.C) {
 	c.Assert(rspe.Error(), check.Matches, `hold-level can only be specified for the "hold" action.*`)
 }
 
+func (s *snapsSuite) TestHoldRefreshInWindows(c *check.C) {
+	d := s.daemon(c)
+	st := d.Overlord().State()
+	st.Lock()
+	defer st.Unlock()
+
+	restore := daemon.MockSnapstateHoldRefreshesBySystem(func(s *state.State, level snapstate.HoldLevel, mockTime string, mockSnaps []string) error {
+		c.Fatal("unexpected call")
+		return nil
+	})
+	defer restore()
+	called := false
+	restore = daemon.MockSnapstateHoldRefreshesBySystemInWindows(func(s *state.State, level snapstate.HoldLevel, mockTime, windows string, mockSnaps []string) error {
+		called = true
+		c.Check(level, check.Equals, snapstate.HoldGeneral)
+		c.Check(mockTime, check.Equals, "forever")
+		c.Check(windows, check.Equals, "mon-fri,9:00-17:00")
+		c.Check(mockSnaps, check.DeepEquals, []string{"some-snap", "other-snap"})
+		return nil
+	})
+	defer restore()
+
+	inst := &daemon.SnapInstruction{
+		Action:      "hold",
+		Snaps:       []string{"some-snap", "other-snap"},
+		Time:        "forever",
+		HoldLevel:   "general",
+		HoldWindows: "mon-fri,9:00-17:00",
+	}
+
+	res, err := inst.DispatchForMany()(context.Background(), inst, st)
+	c.Assert(err, check.IsNil)
+	c.Check(res.Summary, check.Equals, `Hold general refreshes for "some-snap", "other-snap" during mon-fri,9:00-17:00`)
+	c.Check(called, check.Equals, true)
+}
+
+func (s *snapsSuite) TestHoldWindowsErrors(c *check.C) {
+	s.daemon(c)
+	for _, t := range []struct {
+		body string
+		err  string
+	}{
+		{`{"action": "hold", "time": "forever", "hold-level": "auto-refresh", "hold-windows": "mon"}`, `hold windows can only be specified when holding specific snaps.*`},
+		{`{"action": "hold", "snaps": ["foo"], "time": "forever", "hold-level": "auto-refresh", "hold-windows": "boom"}`, `cannot parse hold windows: .*`},
+		{`{"action": "refresh", "hold-windows": "mon"}`, `hold-windows can only be specified for the "hold" action.*`},
+	} {
+		req, err := http.NewRequest("POST", "/v2/snaps", bytes.NewBufferString(t.body))
+		c.Assert(err, check.IsNil)
+		req.Header.Set("Content-Type", "application/json")
+
+		rspe := s.errorReq(c, req, nil, actionIsExpected)
+		c.Check(rspe.Status, check.Equals, 400, check.Commentf(t.body))
+		c.Check(rspe.Error(), check.Matches, t.err, check.Commentf(t.body))
+	}
+}
+
 func (s *snapsSuite) TestHoldAllSnapsGeneralRefreshesNotSupported(c *check.C) {
 	s.daemon(c)
 	buf := bytes.NewBufferString(`{"action": "hold", "time": "forever", "hold-level": "general"}`)
@@ -22,9 +22,11 @@ package daemon
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -32,6 +34,7 @@ import (
 	"github.com/snapcore/snapd/i18n"
 	"github.com/snapcore/snapd/overlord/auth"
 	"github.com/snapcore/snapd/overlord/snapshotstate"
+	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/overlord/swfeats"
 	"github.com/snapcore/snapd/strutil"
@@ -53,14 +56,33 @@ var snapshotExportCmd = &Command{
 	ReadAccess: authenticatedAccess{},
 }
 
+var snapshotImportUploadCmd = &Command{
+	Path:       "/v2/snapshots/uploads/{id}",
+	GET:        getSnapshotImportUpload,
+	ReadAccess: authenticatedAccess{},
+}
+
+var (
+	snapshotList      = snapshotstate.List
+	snapshotCheck     = snapshotstate.Check
+	snapshotDeepCheck = snapshotstate.DeepCheck
+	snapshotForget    = snapshotstate.Forget
+	snapshotRestore   = snapshotstate.Restore
+	snapshotSave      = snapshotstate.Save
+	snapshotExport    = snapshotstate.Export
+	snapshotImport    = snapshotstate.Import
+
+	snapshotStartImportUpload       = snapshotstate.StartImportUpload
+	snapshotImportUploadStatus      = snapshotstate.ImportUploadStatusFor
+	snapshotAppendImportUploadChunk = snapshotstate.AppendImportUploadChunk
+	snapshotImportUploaded          = snapshotstate.ImportUploaded
+
+	snapstateKeptConfigs = snapstate.KeptConfigs
+)
+
 var (
-	snapshotList    = snapshotstate.List
-	snapshotCheck   = snapshotstate.Check
-	snapshotForget  = snapshotstate.Forget
-	snapshotRestore = snapshotstate.Restore
-	snapshotSave    = snapshotstate.Save
-	snapshotExport  = snapshotstate.Export
-	snapshotImport  = snapshotstate.Import
+	snapshotExportRangeRegexp = regexp.MustCompile(`^\s*bytes=(\d+)-(\d*)\s*$`)
+	snapshotImportRangeRegexp = regexp.MustCompile(`^\s*bytes (\d+)-(\d+)/(\d+)\s*$`)
 )
 
 var (
@@ -71,6 +93,9 @@ var (
 
 func listSnapshots(c *Command, r *http.Request, user *auth.UserState) Response {
 	query := r.URL.Query()
+	if query.Get("kept-configs") == "true" {
+		return listKeptConfigs(c, r)
+	}
 	var setID uint64
 	if sid := query.Get("set"); sid != "" {
 		var err error
@@ -90,6 +115,24 @@ func listSnapshots(c *Command, r *http.Request, user *auth.UserState) Response {
 	return SyncResponse(sets)
 }
 
+// listKeptConfigs lists the configurations of removed snaps that are kept
+// to be restored when the snaps are installed again.
+func listKeptConfigs(c *Command, r *http.Request) Response {
+	query := r.URL.Query()
+	if query.Get("set") != "" {
+		return BadRequest("'set' cannot be used with 'kept-configs'")
+	}
+
+	st := c.d.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+	kept, err := snapstateKeptConfigs(st, strutil.CommaSeparatedList(query.Get("snaps")))
+	if err != nil {
+		return InternalError("%v", err)
+	}
+	return SyncResponse(kept)
+}
+
 // A snapshotAction is used to request an operation on a snapshot
 // keep this in sync with client/snapshotAction...
 type snapshotAction struct {
@@ -97,10 +140,15 @@ type snapshotAction struct {
 	Action string   `json:"action"`
 	Snaps  []string `json:"snaps,omitempty"`
 	Users  []string `json:"users,omitempty"`
+	Deep   bool     `json:"deep,omitempty"`
 }
 
 func (action snapshotAction) String() string {
-	// verb of snapshot #N [for snaps %q] [for users %q]
+	// [deep ]verb of snapshot #N [for snaps %q] [for users %q]
+	verb := strings.Title(action.Action)
+	if action.Deep {
+		verb = "Deep " + action.Action
+	}
 	var snaps string
 	var users string
 	if len(action.Snaps) > 0 {
@@ -109,7 +157,7 @@ func (action snapshotAction) String() string {
 	if len(action.Users) > 0 {
 		users = " for users " + strutil.Quoted(action.Users)
 	}
-	return fmt.Sprintf("%s of snapshot set #%d%s%s", strings.Title(action.Action), action.SetID, snaps, users)
+	return fmt.Sprintf("%s of snapshot set #%d%s%s", verb, action.SetID, snaps, users)
 }
 
 func changeSnapshots(c *Command, r *http.Request, user *auth.UserState) Response {
@@ -135,6 +183,10 @@ func changeSnapshots(c *Command, r *http.Request, user *auth.UserState) Response
 		return BadRequest("snapshot operation requires action")
 	}
 
+	if action.Deep && action.Action != "check" {
+		return BadRequest(`snapshot %q operation cannot be deep`, action.Action)
+	}
+
 	var affected []string
 	var ts *state.TaskSet
 	var err error
@@ -146,7 +198,11 @@ func changeSnapshots(c *Command, r *http.Request, user *auth.UserState) Response
 	var changeKind string
 	switch action.Action {
 	case "check":
-		affected, ts, err = snapshotCheck(st, action.SetID, action.Snaps, action.Users)
+		if action.Deep {
+			affected, ts, err = snapshotDeepCheck(st, action.SetID, action.Snaps, action.Users)
+		} else {
+			affected, ts, err = snapshotCheck(st, action.SetID, action.Snaps, action.Users)
+		}
 		changeKind = checkSnapshotChangeKind
 	case "restore":
 		affected, ts, err = snapshotRestore(st, action.SetID, action.Snaps, action.Users)
@@ -170,7 +226,7 @@ func changeSnapshots(c *Command, r *http.Request, user *auth.UserState) Response
 		return InternalError("%v", err)
 	}
 
-	chg := newChange(st, changeKind, action.String(), []*state.TaskSet{ts}, affected)
+	chg := newChange(r.Context(), st, changeKind, action.String(), []*state.TaskSet{ts}, affected)
 	chg.Set("api-data", map[string]any{"snap-names": affected})
 	ensureStateSoon(st)
 
@@ -205,7 +261,47 @@ func getSnapshotExport(c *Command, r *http.Request, user *auth.UserState) Respon
 		return BadRequest("cannot calculate size of exported snapshot %v: %v", setID, err)
 	}
 
-	return &snapshotExportResponse{SnapshotExport: export, setID: setID, st: st}
+	rsp := &snapshotExportResponse{SnapshotExport: export, setID: setID, st: st}
+	// ranges of the export can be requested to resume a transfer, the
+	// whole export is sent if it changed since the transfer started
+	rangestr := r.Header.Get("Range")
+	ifRange := r.Header.Get("If-Range")
+	if rangestr != "" && (ifRange == "" || ifRange == strconv.Quote(export.ETag())) {
+		offset, length, err := parseSnapshotExportRange(rangestr, export.Size())
+		if err != nil {
+			export.Close()
+			snapshotstate.UnsetSnapshotOpInProgress(st, setID)
+			return BadRequest("cannot export %v: %v", setID, err)
+		}
+		rsp.offset = offset
+		rsp.length = length
+	}
+
+	return rsp
+}
+
+// parseSnapshotExportRange parses the value of a Range header asking for a
+// single range of an export of the given size.
+func parseSnapshotExportRange(rangestr string, size int64) (offset, length int64, err error) {
+	subs := snapshotExportRangeRegexp.FindStringSubmatch(rangestr)
+	if subs == nil {
+		return 0, 0, fmt.Errorf("cannot parse range %q", rangestr)
+	}
+	first, err := strconv.ParseInt(subs[1], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("cannot parse range %q: %v", rangestr, err)
+	}
+	last := size - 1
+	if subs[2] != "" {
+		last, err = strconv.ParseInt(subs[2], 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("cannot parse range %q: %v", rangestr, err)
+		}
+	}
+	if first > last || last >= size {
+		return 0, 0, fmt.Errorf("range %q is not within the export size %d", rangestr, size)
+	}
+	return first, last - first + 1, nil
 }
 
 func doSnapshotImport(c *Command, r *http.Request, user *auth.UserState) Response {
@@ -218,6 +314,10 @@ func doSnapshotImport(c *Command, r *http.Request, user *auth.UserState) Respons
 	// ensure we don't read more than we expect
 	limitedBodyReader := io.LimitReader(r.Body, expectedSize)
 
+	if contentRange := r.Header.Get("Content-Range"); contentRange != "" {
+		return doSnapshotImportChunk(c, r, contentRange, expectedSize, limitedBodyReader)
+	}
+
 	// XXX: check that we have enough space to import the compressed snapshots
 	st := c.d.overlord.State()
 	setID, snapNames, err := snapshotImport(r.Context(), st, limitedBodyReader)
@@ -229,6 +329,98 @@ func doSnapshotImport(c *Command, r *http.Request, user *auth.UserState) Respons
 	return SyncResponse(result)
 }
 
+// doSnapshotImportChunk appends a chunk of a snapshot export to an upload,
+// starting a new one for the first chunk, and imports the export once all
+// of it was uploaded.
+func doSnapshotImportChunk(c *Command, r *http.Request, contentRange string, length int64, body io.Reader) Response {
+	subs := snapshotImportRangeRegexp.FindStringSubmatch(contentRange)
+	if subs == nil {
+		return BadRequest("cannot parse Content-Range %q", contentRange)
+	}
+	var first, last, size int64
+	for i, v := range []*int64{&first, &last, &size} {
+		n, err := strconv.ParseInt(subs[i+1], 10, 64)
+		if err != nil {
+			return BadRequest("cannot parse Content-Range %q: %v", contentRange, err)
+		}
+		*v = n
+	}
+	if first > last || last >= size || last-first+1 != length {
+		return BadRequest("invalid Content-Range %q for %d bytes of data", contentRange, length)
+	}
+	chunkHash := r.Header.Get(client.SnapshotChunkHashHeader)
+	if chunkHash == "" {
+		return BadRequest("cannot import snapshot chunk without %s header", client.SnapshotChunkHashHeader)
+	}
+
+	st := c.d.overlord.State()
+	uploadID := r.Header.Get(client.SnapshotUploadIDHeader)
+	st.Lock()
+	var err error
+	if uploadID == "" {
+		if first != 0 {
+			err = fmt.Errorf("cannot start snapshot import upload at offset %d", first)
+		} else {
+			uploadID, err = snapshotStartImportUpload(st, size)
+		}
+	} else {
+		var status *snapshotstate.ImportUploadStatus
+		status, err = snapshotImportUploadStatus(st, uploadID)
+		if err == nil && status.Size != size {
+			err = fmt.Errorf("cannot append chunk of %d bytes export to snapshot import upload %s of %d bytes", size, uploadID, status.Size)
+		}
+	}
+	st.Unlock()
+	if err != nil {
+		return snapshotImportUploadError(err)
+	}
+
+	status, err := snapshotAppendImportUploadChunk(st, uploadID, first, body, chunkHash)
+	if err != nil {
+		return snapshotImportUploadError(err)
+	}
+
+	result := map[string]any{
+		"upload-id": status.ID,
+		"size":      status.Size,
+		"received":  status.Received,
+	}
+	if status.Complete() {
+		setID, snapNames, err := snapshotImportUploaded(r.Context(), st, uploadID)
+		if err != nil {
+			return BadRequest(err.Error())
+		}
+		result["set-id"] = setID
+		result["snaps"] = snapNames
+	}
+	return SyncResponse(result)
+}
+
+func snapshotImportUploadError(err error) Response {
+	var offsetErr *snapshotstate.UploadOffsetError
+	switch {
+	case errors.Is(err, snapshotstate.ErrNoImportUpload):
+		return NotFound(err.Error())
+	case errors.As(err, &offsetErr):
+		return Conflict(err.Error())
+	}
+	return BadRequest(err.Error())
+}
+
+// getSnapshotImportUpload returns how much of a snapshot export was
+// uploaded so far, so that the upload can be resumed from there.
+func getSnapshotImportUpload(c *Command, r *http.Request, user *auth.UserState) Response {
+	st := c.d.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	status, err := snapshotImportUploadStatus(st, muxVars(r)["id"])
+	if err != nil {
+		return snapshotImportUploadError(err)
+	}
+	return SyncResponse(status)
+}
+
 func snapshotMany(_ context.Context, inst *snapInstruction, st *state.State) (*snapInstructionResult, error) {
 	setID, snapshotted, ts, err := snapshotSave(st, inst.Snaps, inst.Users, inst.SnapshotOptions)
 	if err != nil {
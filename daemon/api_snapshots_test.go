@@ -22,10 +22,12 @@ package daemon_test
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"strconv"
 	"strings"
 
@@ -182,6 +184,59 @@ func (s *snapshotSuite) TestListSnapshotsListError(c *check.C) {
 	c.Check(rspe.Message, check.Equals, "no")
 }
 
+func (s *snapshotSuite) TestListKeptConfigs(c *check.C) {
+	s.expectOpenAccess()
+
+	kept := []client.KeptConfig{{Snap: "foo", Revision: snap.R(7), Keys: []string{"a"}}}
+
+	defer daemon.MockSnapshotList(func(context.Context, *state.State, uint64, []string) ([]client.SnapshotSet, error) {
+		c.Fatal("snapshotList should not be reached")
+		return nil, nil
+	})()
+	defer daemon.MockSnapstateKeptConfigs(func(_ *state.State, snaps []string) ([]client.KeptConfig, error) {
+		c.Check(snaps, check.DeepEquals, []string{"foo", "bar"})
+		return kept, nil
+	})()
+
+	req, err := http.NewRequest("GET", "/v2/snapshots?kept-configs=true&snaps=foo,bar", nil)
+	c.Assert(err, check.IsNil)
+
+	rsp := s.syncReq(c, req, nil, actionIsExpected)
+	c.Check(rsp.Status, check.Equals, 200)
+	c.Check(rsp.Result, check.DeepEquals, kept)
+}
+
+func (s *snapshotSuite) TestListKeptConfigsWithSet(c *check.C) {
+	s.expectOpenAccess()
+
+	defer daemon.MockSnapstateKeptConfigs(func(*state.State, []string) ([]client.KeptConfig, error) {
+		c.Fatal("snapstateKeptConfigs should not be reached")
+		return nil, nil
+	})()
+
+	req, err := http.NewRequest("GET", "/v2/snapshots?kept-configs=true&set=42", nil)
+	c.Assert(err, check.IsNil)
+
+	rspe := s.errorReq(c, req, nil, actionIsExpected)
+	c.Check(rspe.Status, check.Equals, 400)
+	c.Check(rspe.Message, check.Equals, `'set' cannot be used with 'kept-configs'`)
+}
+
+func (s *snapshotSuite) TestListKeptConfigsError(c *check.C) {
+	s.expectOpenAccess()
+
+	defer daemon.MockSnapstateKeptConfigs(func(*state.State, []string) ([]client.KeptConfig, error) {
+		return nil, errors.New("no")
+	})()
+
+	req, err := http.NewRequest("GET", "/v2/snapshots?kept-configs=true", nil)
+	c.Assert(err, check.IsNil)
+
+	rspe := s.errorReq(c, req, nil, actionIsExpected)
+	c.Check(rspe.Status, check.Equals, 500)
+	c.Check(rspe.Message, check.Equals, "no")
+}
+
 func (s *snapshotSuite) TestFormatSnapshotAction(c *check.C) {
 	type table struct {
 		action   string
@@ -206,6 +261,9 @@ func (s *snapshotSuite) TestFormatSnapshotAction(c *check.C) {
 		}, {
 			`{"set": 2, "action": "verb", "users": ["meep", "quux"], "snaps": ["foo", "bar"]}`,
 			`Verb of snapshot set #2 for snaps "foo", "bar" for users "meep", "quux"`,
+		}, {
+			`{"set": 2, "action": "verb", "deep": true}`,
+			`Deep verb of snapshot set #2`,
 		},
 	}
 
@@ -236,6 +294,9 @@ func (s *snapshotSuite) TestChangeSnapshots400(c *check.C) {
 		}, {
 			body:  `{"set": 42, "action": "forget", "users": ["foo"]}`,
 			error: `snapshot "forget" operation cannot specify users`,
+		}, {
+			body:  `{"set": 42, "action": "restore", "deep": true}`,
+			error: `snapshot "restore" operation cannot be deep`,
 		},
 	}
 
@@ -355,6 +416,37 @@ func (s *snapshotSuite) TestChangeSnapshot(c *check.C) {
 	}
 }
 
+func (s *snapshotSuite) TestChangeSnapshotDeepCheck(c *check.C) {
+	defer daemon.MockSnapshotCheck(func(*state.State, uint64, []string, []string) ([]string, *state.TaskSet, error) {
+		c.Fatalf("unexpected shallow check")
+		return nil, nil, nil
+	})()
+	var deepChecked int
+	defer daemon.MockSnapshotDeepCheck(func(_ *state.State, setID uint64, snaps, users []string) ([]string, *state.TaskSet, error) {
+		deepChecked++
+		c.Check(setID, check.Equals, uint64(42))
+		c.Check(snaps, check.DeepEquals, []string{"foo"})
+		c.Check(users, check.DeepEquals, []string{"meep"})
+		return []string{"foo"}, state.NewTaskSet(), nil
+	})()
+
+	body := `{"set": 42, "action": "check", "snaps": ["foo"], "users": ["meep"], "deep": true}`
+	req, err := http.NewRequest("POST", "/v2/snapshots", strings.NewReader(body))
+	c.Assert(err, check.IsNil)
+
+	rsp := s.asyncReq(c, req, nil, actionIsExpected)
+	c.Check(rsp.Status, check.Equals, 202)
+	c.Check(deepChecked, check.Equals, 1)
+
+	st := s.d.Overlord().State()
+	st.Lock()
+	defer st.Unlock()
+	chg := st.Change(rsp.Change)
+	c.Assert(chg, check.NotNil)
+	c.Check(chg.Kind(), check.Equals, "check-snapshot")
+	c.Check(chg.Summary(), check.Equals, `Deep check of snapshot set #42 for snaps "foo" for users "meep"`)
+}
+
 func (s *snapshotSuite) TestExportSnapshots(c *check.C) {
 	var snapshotExportCalled int
 
@@ -372,6 +464,71 @@ func (s *snapshotSuite) TestExportSnapshots(c *check.C) {
 	c.Check(snapshotExportCalled, check.Equals, 1)
 }
 
+func (s *snapshotSuite) serveSnapshotExport(c *check.C, headers map[string]string) *http.Response {
+	defer daemon.MockSnapshotExport(func(ctx context.Context, st *state.State, setID uint64) (*snapshotstate.SnapshotExport, error) {
+		return &snapshotstate.SnapshotExport{}, nil
+	})()
+
+	req, err := http.NewRequest("GET", "/v2/snapshots/1/export", nil)
+	c.Assert(err, check.IsNil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rsp := s.req(c, req, nil, actionIsExpected)
+	rec := httptest.NewRecorder()
+	rsp.ServeHTTP(rec, req)
+	return rec.Result()
+}
+
+func (s *snapshotSuite) TestExportSnapshotsRange(c *check.C) {
+	full := s.serveSnapshotExport(c, nil)
+	c.Assert(full.StatusCode, check.Equals, 200)
+	c.Check(full.Header.Get("Accept-Ranges"), check.Equals, "bytes")
+	c.Check(full.Header.Get("ETag"), check.Equals, `""`)
+	fullData, err := io.ReadAll(full.Body)
+	c.Assert(err, check.IsNil)
+	c.Check(full.Header.Get("Content-Length"), check.Equals, strconv.Itoa(len(fullData)))
+
+	rsp := s.serveSnapshotExport(c, map[string]string{"Range": "bytes=10-19"})
+	c.Assert(rsp.StatusCode, check.Equals, 206)
+	c.Check(rsp.Header.Get("Content-Range"), check.Equals, fmt.Sprintf("bytes 10-19/%d", len(fullData)))
+	data, err := io.ReadAll(rsp.Body)
+	c.Assert(err, check.IsNil)
+	c.Check(data, check.DeepEquals, fullData[10:20])
+	c.Check(rsp.Trailer.Get(client.SnapshotChunkHashHeader), check.Equals, fmt.Sprintf("%x", sha256.Sum256(fullData[10:20])))
+
+	// open ended range with a matching If-Range
+	rsp = s.serveSnapshotExport(c, map[string]string{"Range": "bytes=1000-", "If-Range": `""`})
+	c.Assert(rsp.StatusCode, check.Equals, 206)
+	c.Check(rsp.Header.Get("Content-Range"), check.Equals, fmt.Sprintf("bytes 1000-%d/%d", len(fullData)-1, len(fullData)))
+	data, err = io.ReadAll(rsp.Body)
+	c.Assert(err, check.IsNil)
+	c.Check(data, check.DeepEquals, fullData[1000:])
+
+	// the whole export is sent if it changed
+	rsp = s.serveSnapshotExport(c, map[string]string{"Range": "bytes=10-19", "If-Range": `"other"`})
+	c.Assert(rsp.StatusCode, check.Equals, 200)
+	data, err = io.ReadAll(rsp.Body)
+	c.Assert(err, check.IsNil)
+	c.Check(data, check.DeepEquals, fullData)
+}
+
+func (s *snapshotSuite) TestExportSnapshotsBadRange(c *check.C) {
+	defer daemon.MockSnapshotExport(func(ctx context.Context, st *state.State, setID uint64) (*snapshotstate.SnapshotExport, error) {
+		return &snapshotstate.SnapshotExport{}, nil
+	})()
+
+	for _, rangestr := range []string{"bytes=10-5", "bytes=0-100000", "items=1-2"} {
+		req, err := http.NewRequest("GET", "/v2/snapshots/1/export", nil)
+		c.Assert(err, check.IsNil)
+		req.Header.Set("Range", rangestr)
+
+		rspe := s.errorReq(c, req, nil, actionIsExpected)
+		c.Check(rspe.Status, check.Equals, 400, check.Commentf(rangestr))
+		c.Check(rspe.Message, check.Matches, `cannot export 1: (cannot parse range|range) ".*".*`, check.Commentf(rangestr))
+	}
+}
+
 func (s *snapshotSuite) TestExportSnapshotsBadRequestOnNonNumericID(c *check.C) {
 	req, err := http.NewRequest("GET", "/v2/snapshots/xxx/export", nil)
 	c.Assert(err, check.IsNil)
@@ -465,3 +622,103 @@ func (s *snapshotSuite) TestImportSnapshotLimits(c *check.C) {
 	c.Check(rsp.Status, check.Equals, 200)
 	c.Check(dataRead, check.Equals, 10)
 }
+
+func snapshotChunkRequest(c *check.C, uploadID, contentRange string, data string) *http.Request {
+	req, err := http.NewRequest("POST", "/v2/snapshots", strings.NewReader(data))
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Content-Type", client.SnapshotExportMediaType)
+	req.Header.Set("Content-Length", strconv.Itoa(len(data)))
+	req.Header.Set("Content-Range", contentRange)
+	req.Header.Set(client.SnapshotChunkHashHeader, fmt.Sprintf("%x", sha256.Sum256([]byte(data))))
+	if uploadID != "" {
+		req.Header.Set(client.SnapshotUploadIDHeader, uploadID)
+	}
+	return req
+}
+
+func (s *snapshotSuite) TestImportSnapshotChunks(c *check.C) {
+	received := int64(0)
+	defer daemon.MockSnapshotStartImportUpload(func(st *state.State, size int64) (string, error) {
+		c.Check(size, check.Equals, int64(11))
+		return "some-id", nil
+	})()
+	defer daemon.MockSnapshotImportUploadStatus(func(st *state.State, id string) (*snapshotstate.ImportUploadStatus, error) {
+		c.Check(id, check.Equals, "some-id")
+		return &snapshotstate.ImportUploadStatus{ID: id, Size: 11, Received: received}, nil
+	})()
+	defer daemon.MockSnapshotAppendImportUploadChunk(func(st *state.State, id string, offset int64, r io.Reader, chunkHash string) (*snapshotstate.ImportUploadStatus, error) {
+		c.Check(id, check.Equals, "some-id")
+		c.Check(offset, check.Equals, received)
+		data, err := io.ReadAll(r)
+		c.Assert(err, check.IsNil)
+		c.Check(chunkHash, check.Equals, fmt.Sprintf("%x", sha256.Sum256(data)))
+		received += int64(len(data))
+		return &snapshotstate.ImportUploadStatus{ID: id, Size: 11, Received: received}, nil
+	})()
+	importUploadedCalls := 0
+	defer daemon.MockSnapshotImportUploaded(func(ctx context.Context, st *state.State, id string) (uint64, []string, error) {
+		importUploadedCalls++
+		c.Check(id, check.Equals, "some-id")
+		return 3, []string{"foo"}, nil
+	})()
+
+	rsp := s.syncReq(c, snapshotChunkRequest(c, "", "bytes 0-5/11", "hello "), nil, actionIsExpected)
+	c.Check(rsp.Result, check.DeepEquals, map[string]any{"upload-id": "some-id", "size": int64(11), "received": int64(6)})
+	c.Check(importUploadedCalls, check.Equals, 0)
+
+	req, err := http.NewRequest("GET", "/v2/snapshots/uploads/some-id", nil)
+	c.Assert(err, check.IsNil)
+	rsp = s.syncReq(c, req, nil, actionIsExpected)
+	c.Check(rsp.Result, check.DeepEquals, &snapshotstate.ImportUploadStatus{ID: "some-id", Size: 11, Received: 6})
+
+	rsp = s.syncReq(c, snapshotChunkRequest(c, "some-id", "bytes 6-10/11", "world"), nil, actionIsExpected)
+	c.Check(rsp.Result, check.DeepEquals, map[string]any{
+		"upload-id": "some-id",
+		"size":      int64(11),
+		"received":  int64(11),
+		"set-id":    uint64(3),
+		"snaps":     []string{"foo"},
+	})
+	c.Check(importUploadedCalls, check.Equals, 1)
+}
+
+func (s *snapshotSuite) TestImportSnapshotChunkErrors(c *check.C) {
+	defer daemon.MockSnapshotImportUploadStatus(func(st *state.State, id string) (*snapshotstate.ImportUploadStatus, error) {
+		if id == "unknown" {
+			return nil, snapshotstate.ErrNoImportUpload
+		}
+		return &snapshotstate.ImportUploadStatus{ID: id, Size: 11, Received: 6}, nil
+	})()
+	defer daemon.MockSnapshotAppendImportUploadChunk(func(st *state.State, id string, offset int64, r io.Reader, chunkHash string) (*snapshotstate.ImportUploadStatus, error) {
+		return nil, &snapshotstate.UploadOffsetError{Offset: offset, Received: 6}
+	})()
+
+	for _, t := range []struct {
+		req     *http.Request
+		status  int
+		message string
+	}{
+		{snapshotChunkRequest(c, "", "bytes 0-5", "hello "), 400, `cannot parse Content-Range "bytes 0-5"`},
+		{snapshotChunkRequest(c, "", "bytes 0-6/11", "hello "), 400, `invalid Content-Range "bytes 0-6/11" for 6 bytes of data`},
+		{snapshotChunkRequest(c, "", "bytes 6-10/5", "hello"), 400, `invalid Content-Range "bytes 6-10/5" for 5 bytes of data`},
+		{snapshotChunkRequest(c, "", "bytes 6-10/11", "world"), 400, `cannot start snapshot import upload at offset 6`},
+		{snapshotChunkRequest(c, "unknown", "bytes 6-10/11", "world"), 404, `no such snapshot import upload`},
+		{snapshotChunkRequest(c, "some-id", "bytes 6-10/12", "world"), 400, `cannot append chunk of 12 bytes export to snapshot import upload some-id of 11 bytes`},
+		{snapshotChunkRequest(c, "some-id", "bytes 3-7/11", "world"), 409, `cannot append chunk at offset 3: 6 bytes received so far`},
+	} {
+		rspe := s.errorReq(c, t.req, nil, actionIsExpected)
+		c.Check(rspe.Status, check.Equals, t.status, check.Commentf(t.message))
+		c.Check(rspe.Message, check.Equals, t.message)
+	}
+
+	req := snapshotChunkRequest(c, "some-id", "bytes 6-10/11", "world")
+	req.Header.Del(client.SnapshotChunkHashHeader)
+	rspe := s.errorReq(c, req, nil, actionIsExpected)
+	c.Check(rspe.Status, check.Equals, 400)
+	c.Check(rspe.Message, check.Equals, "cannot import snapshot chunk without Snap-Chunk-Sha256 header")
+
+	req, err := http.NewRequest("GET", "/v2/snapshots/uploads/unknown", nil)
+	c.Assert(err, check.IsNil)
+	rspe = s.errorReq(c, req, nil, actionIsExpected)
+	c.Check(rspe.Status, check.Equals, 404)
+}
@@ -20,6 +20,7 @@
 package daemon
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -297,19 +298,19 @@ func postSystemVolumesActionJSON(c *Command, r *http.Request) Response {
 	case "check-recovery-key":
 		return postSystemVolumesActionCheckRecoveryKey(c, &req)
 	case "add-recovery-key":
-		return postSystemVolumesActionAddRecoveryKey(c, &req)
+		return postSystemVolumesActionAddRecoveryKey(r.Context(), c, &req)
 	case "replace-recovery-key":
-		return postSystemVolumesActionReplaceRecoveryKey(c, &req)
+		return postSystemVolumesActionReplaceRecoveryKey(r.Context(), c, &req)
 	case "replace-platform-key":
-		return postSystemVolumesActionReplacePlatformKey(c, &req)
+		return postSystemVolumesActionReplacePlatformKey(r.Context(), c, &req)
 	case "check-passphrase-quality", "check-passphrase": // "check-passphrase" is deprecated
 		return postSystemVolumesCheckPassphraseQuality(&req)
 	case "check-pin-quality", "check-pin": // "check-pin" is deprecated
 		return postSystemVolumesCheckPINQuality(&req)
 	case "change-passphrase":
-		return postSystemVolumesActionChangePassphrase(c, &req)
+		return postSystemVolumesActionChangePassphrase(r.Context(), c, &req)
 	case "change-pin":
-		return postSystemVolumesActionChangePIN(c, &req)
+		return postSystemVolumesActionChangePIN(r.Context(), c, &req)
 	default:
 		return BadRequest("unsupported system volumes action %q", req.Action)
 	}
@@ -355,7 +356,7 @@ func postSystemVolumesActionCheckRecoveryKey(c *Command, req *systemVolumesActio
 	return SyncResponse(nil)
 }
 
-func postSystemVolumesActionAddRecoveryKey(c *Command, req *systemVolumesActionRequest) Response {
+func postSystemVolumesActionAddRecoveryKey(ctx context.Context, c *Command, req *systemVolumesActionRequest) Response {
 	if req.KeyID == "" {
 		return BadRequest("system volume action requires key-id to be provided")
 	}
@@ -372,14 +373,14 @@ func postSystemVolumesActionAddRecoveryKey(c *Command, req *systemVolumesActionR
 		return errToResponse(err, nil, BadRequest, "cannot add recovery key: %v")
 	}
 
-	chg := newChange(st, fdeAddRecoveryKeyChangeKind, "Add recovery key", []*state.TaskSet{ts}, nil)
+	chg := newChange(ctx, st, fdeAddRecoveryKeyChangeKind, "Add recovery key", []*state.TaskSet{ts}, nil)
 
 	st.EnsureBefore(0)
 
 	return AsyncResponse(nil, chg.ID())
 }
 
-func postSystemVolumesActionReplaceRecoveryKey(c *Command, req *systemVolumesActionRequest) Response {
+func postSystemVolumesActionReplaceRecoveryKey(ctx context.Context, c *Command, req *systemVolumesActionRequest) Response {
 	if req.KeyID == "" {
 		return BadRequest("system volume action requires key-id to be provided")
 	}
@@ -393,14 +394,14 @@ func postSystemVolumesActionReplaceRecoveryKey(c *Command, req *systemVolumesAct
 		return errToResponse(err, nil, BadRequest, "cannot replace recovery key: %v")
 	}
 
-	chg := newChange(st, fdeReplaceRecoveryKeyChangeKind, "Replace recovery key", []*state.TaskSet{ts}, nil)
+	chg := newChange(ctx, st, fdeReplaceRecoveryKeyChangeKind, "Replace recovery key", []*state.TaskSet{ts}, nil)
 
 	st.EnsureBefore(0)
 
 	return AsyncResponse(nil, chg.ID())
 }
 
-func postSystemVolumesActionReplacePlatformKey(c *Command, req *systemVolumesActionRequest) Response {
+func postSystemVolumesActionReplacePlatformKey(ctx context.Context, c *Command, req *systemVolumesActionRequest) Response {
 	if req.AuthMode == "" {
 		return BadRequest("system volume action requires auth-mode to be provided")
 	}
@@ -428,7 +429,7 @@ func postSystemVolumesActionReplacePlatformKey(c *Command, req *systemVolumesAct
 		return errToResponse(err, nil, BadRequest, "cannot replace platform key: %v")
 	}
 
-	chg := newChange(st, fdeReplacePlatformKeyChangeKind, "Replace platform key", []*state.TaskSet{ts}, nil)
+	chg := newChange(ctx, st, fdeReplacePlatformKeyChangeKind, "Replace platform key", []*state.TaskSet{ts}, nil)
 
 	st.EnsureBefore(0)
 
@@ -451,7 +452,7 @@ func postSystemVolumesCheckPINQuality(req *systemVolumesActionRequest) Response
 	return postCheckAuthQuality(device.AuthModePIN, req.PIN)
 }
 
-func postSystemVolumesActionChangePassphrase(c *Command, req *systemVolumesActionRequest) Response {
+func postSystemVolumesActionChangePassphrase(ctx context.Context, c *Command, req *systemVolumesActionRequest) Response {
 	if req.OldPassphrase == "" {
 		return BadRequest("system volume action requires old-passphrase to be provided")
 	}
@@ -468,14 +469,14 @@ func postSystemVolumesActionChangePassphrase(c *Command, req *systemVolumesActio
 		return errToResponse(err, nil, BadRequest, "cannot change passphrase: %v")
 	}
 
-	chg := newChange(st, fdeChangePassphraseChangeKind, "Change passphrase", []*state.TaskSet{ts}, nil)
+	chg := newChange(ctx, st, fdeChangePassphraseChangeKind, "Change passphrase", []*state.TaskSet{ts}, nil)
 
 	st.EnsureBefore(0)
 
 	return AsyncResponse(nil, chg.ID())
 }
 
-func postSystemVolumesActionChangePIN(c *Command, req *systemVolumesActionRequest) Response {
+func postSystemVolumesActionChangePIN(ctx context.Context, c *Command, req *systemVolumesActionRequest) Response {
 	if req.OldPIN == "" {
 		return BadRequest("system volume action requires old-pin to be provided")
 	}
@@ -499,7 +500,7 @@ func postSystemVolumesActionChangePIN(c *Command, req *systemVolumesActionReques
 		return errToResponse(err, nil, BadRequest, "cannot change pin: %v")
 	}
 
-	chg := newChange(st, fdeChangePINChangeKind, "Change pin", []*state.TaskSet{ts}, nil)
+	chg := newChange(ctx, st, fdeChangePINChangeKind, "Change pin", []*state.TaskSet{ts}, nil)
 
 	st.EnsureBefore(0)
 
@@ -55,7 +55,7 @@ var systemsCmd = &Command{
 	// forward to that one
 	POST:        postSystemsAction,
 	Actions:     []string{"reboot", "create", "install", "reprovision", "fix-encryption-support", "generate-recovery-key"},
-	WriteAccess: rootAccess{},
+	WriteAccess: rootAccess{Polkit: polkitActionManageSystem},
 }
 
 var systemsActionCmd = &Command{
@@ -71,7 +71,7 @@ var systemsActionCmd = &Command{
 		// deprecated
 		"check-passphrase", "check-pin",
 	},
-	WriteAccess: rootAccess{},
+	WriteAccess: rootAccess{Polkit: polkitActionManageSystem},
 }
 
 type systemsResponse struct {
@@ -224,6 +224,7 @@ var (
 	devicestateCreateRecoverySystem           = devicestate.CreateRecoverySystem
 	devicestateRemoveRecoverySystem           = devicestate.RemoveRecoverySystem
 	devicestateGeneratePreInstallRecoveryKey  = devicestate.GeneratePreInstallRecoveryKey
+	devicestatePreviewInstallSealing          = devicestate.PreviewInstallSealing
 	devicestateGenerateReprovisionRecoveryKey = devicestate.GenerateReprovisionRecoveryKey
 	devicestateReprovision                    = devicestate.Reprovision
 )
@@ -489,6 +490,12 @@ func postSystemActionInstall(c *Command, systemLabel string, req *systemActionRe
 		return SyncResponse(map[string]string{
 			"recovery-key": rkey.String(),
 		})
+	case client.InstallStepPreviewSealing:
+		preview, err := devicestatePreviewInstallSealing(st, systemLabel)
+		if err != nil {
+			return InternalError("cannot preview sealing for %q: %v", systemLabel, err)
+		}
+		return SyncResponse(preview)
 	case client.InstallStepFinish:
 		var optional *devicestate.OptionalContainers
 		if req.OptionalInstall != nil {
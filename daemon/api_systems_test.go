@@ -75,7 +75,7 @@ type systemsSuite struct {
 func (s *systemsSuite) SetUpTest(c *check.C) {
 	s.apiBaseSuite.SetUpTest(c)
 
-	s.expectRootAccess()
+	s.expectManageSystemAccess()
 }
 
 var pcGadgetUCYaml = `
@@ -896,7 +896,7 @@ func (s *systemsSuite) TestSystemsGetSystemDetailsForLabel(c *check.C) {
 	s.mockSystemSeeds(c)
 
 	s.daemon(c)
-	s.expectRootAccess()
+	s.expectManageSystemAccess()
 
 	mockGadgetInfo := &gadget.Info{
 		Volumes: map[string]*gadget.Volume{
@@ -1093,7 +1093,7 @@ func (s *systemsSuite) TestSystemsGetSystemDetailsForLabel(c *check.C) {
 
 func (s *systemsSuite) TestSystemsGetSpecificLabelError(c *check.C) {
 	s.daemon(c)
-	s.expectRootAccess()
+	s.expectManageSystemAccess()
 
 	r := daemon.MockDeviceManagerSystemAndGadgetAndEncryptionInfo(func(
 		mgr *devicestate.DeviceManager,
@@ -1114,7 +1114,7 @@ func (s *systemsSuite) TestSystemsGetSpecificLabelError(c *check.C) {
 
 func (s *systemsSuite) TestSystemsGetSpecificLabelNotFoundIntegration(c *check.C) {
 	s.daemon(c)
-	s.expectRootAccess()
+	s.expectManageSystemAccess()
 
 	req, err := http.NewRequest("GET", "/v2/systems/does-not-exist", nil)
 	c.Assert(err, check.IsNil)
@@ -1125,7 +1125,7 @@ func (s *systemsSuite) TestSystemsGetSpecificLabelNotFoundIntegration(c *check.C
 
 func (s *systemsSuite) TestSystemsGetSpecificLabelIntegration(c *check.C) {
 	d := s.daemon(c)
-	s.expectRootAccess()
+	s.expectManageSystemAccess()
 	deviceMgr := d.Overlord().DeviceManager()
 
 	restore := s.mockSystemSeeds(c)
@@ -1743,6 +1743,60 @@ func (s *systemsSuite) TestSystemInstallActionGenerateRecoveryKeyError(c *check.
 	c.Check(rsp.Message, check.Equals, `cannot generate recovery key for "20250529": boom!`)
 }
 
+func (s *systemsSuite) TestSystemInstallActionPreviewSealing(c *check.C) {
+	s.daemon(c)
+
+	preview := &client.InstallSealingPreview{
+		SealingMethod: device.SealingMethodTPM,
+		RecoveryKey:   true,
+		PCRProfile: &secboot.PCRProfilePreview{
+			PCRAlg: "sha256",
+			PCRs:   []int{7, 12},
+		},
+	}
+	defer daemon.MockDevicestatePreviewInstallSealing(func(st *state.State, label string) (*client.InstallSealingPreview, error) {
+		c.Check(label, check.Equals, "20250529")
+		return preview, nil
+	})()
+
+	body := map[string]any{
+		"action": "install",
+		"step":   "preview-sealing",
+	}
+	b, err := json.Marshal(body)
+	c.Assert(err, check.IsNil)
+	buf := bytes.NewBuffer(b)
+	req, err := http.NewRequest("POST", "/v2/systems/20250529", buf)
+	c.Assert(err, check.IsNil)
+
+	rsp := s.syncReq(c, req, nil, actionIsExpected)
+	c.Assert(rsp.Status, check.Equals, 200)
+	c.Check(rsp.Result, check.Equals, preview)
+}
+
+func (s *systemsSuite) TestSystemInstallActionPreviewSealingError(c *check.C) {
+	s.daemon(c)
+
+	defer daemon.MockDevicestatePreviewInstallSealing(func(st *state.State, label string) (*client.InstallSealingPreview, error) {
+		c.Check(label, check.Equals, "20250529")
+		return nil, errors.New("cannot seal keys with the TPM: TPM device is not enabled")
+	})()
+
+	body := map[string]any{
+		"action": "install",
+		"step":   "preview-sealing",
+	}
+	b, err := json.Marshal(body)
+	c.Assert(err, check.IsNil)
+	buf := bytes.NewBuffer(b)
+	req, err := http.NewRequest("POST", "/v2/systems/20250529", buf)
+	c.Assert(err, check.IsNil)
+
+	rsp := s.errorReq(c, req, nil, actionIsExpected)
+	c.Check(rsp.Status, check.Equals, 500)
+	c.Check(rsp.Message, check.Equals, `cannot preview sealing for "20250529": cannot seal keys with the TPM: TPM device is not enabled`)
+}
+
 func (s *systemsSuite) TestSystemInstallActionGeneratesTasks(c *check.C) {
 	d := s.daemon(c)
 	st := d.Overlord().State()
@@ -2184,7 +2238,7 @@ func (s *systemsSuite) TestSystemActionCheckPINError(c *check.C) {
 func (s *systemsSuite) testSystemActionFixEncryptionSupport(c *check.C, runningSystem bool) {
 	s.mockSystemSeeds(c)
 	s.daemon(c)
-	s.expectRootAccess()
+	s.expectManageSystemAccess()
 
 	mockGadgetInfo := &gadget.Info{
 		Volumes: map[string]*gadget.Volume{
@@ -2447,7 +2501,7 @@ func (s *systemsSuite) TestSystemActionFixEncryptionSupportErrors(c *check.C) {
 
 func (s *systemsSuite) TestSystemActionFixEncryptionSupportIntegrationErrors(c *check.C) {
 	d := s.daemon(c)
-	s.expectRootAccess()
+	s.expectManageSystemAccess()
 	restore := s.mockSystemSeeds(c)
 	defer restore()
 
@@ -2489,7 +2543,7 @@ func (s *systemsSuite) TestSystemActionFixEncryptionSupportIntegrationErrors(c *
 
 func (s *systemsSuite) TestSystemActionFixEncryptionSupportIntegration(c *check.C) {
 	d := s.daemon(c)
-	s.expectRootAccess()
+	s.expectManageSystemAccess()
 	deviceMgr := d.Overlord().DeviceManager()
 
 	restore := s.mockSystemSeeds(c)
@@ -2741,7 +2795,7 @@ func (s *systemsCreateSuite) SetUpTest(c *check.C) {
 	s.apiBaseSuite.SetUpTest(c)
 	d := s.daemon(c)
 
-	s.expectRootAccess()
+	s.expectManageSystemAccess()
 
 	restore := asserts.MockMaxSupportedFormat(asserts.ValidationSetType, 1)
 	s.AddCleanup(restore)
@@ -3597,7 +3651,7 @@ func (s *systemsSuite) TestSystemGenerateRecoveryKeyOnSystem(c *check.C) {
 func (s *systemsSuite) TestSystemActionReprovision(c *check.C) {
 	s.mockSystemSeeds(c)
 	s.daemon(c)
-	s.expectRootAccess()
+	s.expectManageSystemAccess()
 
 	defer daemon.MockDevicestateReprovision(func(st *state.State) (*state.Change, error) {
 		return st.NewChange("reprovision", "..."), nil
@@ -3627,7 +3681,7 @@ func (s *systemsSuite) TestSystemActionReprovision(c *check.C) {
 func (s *systemsSuite) TestSystemActionReprovisionError(c *check.C) {
 	s.mockSystemSeeds(c)
 	s.daemon(c)
-	s.expectRootAccess()
+	s.expectManageSystemAccess()
 
 	defer daemon.MockDevicestateReprovision(func(st *state.State) (*state.Change, error) {
 		return nil, fmt.Errorf("foo")
@@ -3657,7 +3711,7 @@ func (s *systemsSuite) TestSystemActionReprovisionError(c *check.C) {
 func (s *systemsSuite) TestSystemActionReprovisionConflictError(c *check.C) {
 	s.mockSystemSeeds(c)
 	s.daemon(c)
-	s.expectRootAccess()
+	s.expectManageSystemAccess()
 
 	defer daemon.MockDevicestateReprovision(func(st *state.State) (*state.Change, error) {
 		return nil, &snapstate.ChangeConflictError{
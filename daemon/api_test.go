@@ -20,8 +20,11 @@
 package daemon_test
 
 import (
+	"encoding/xml"
 	"fmt"
 	"net/http"
+	"os"
+	"sort"
 
 	"gopkg.in/check.v1"
 
@@ -49,6 +52,80 @@ func (s *apiSuite) TestListIncludesAll(c *check.C) {
 		check.Commentf(`At a glance it looks like you've not added all the Commands defined in api to the api list.`))
 }
 
+func polkitActionsOf(ac daemon.AccessChecker) []string {
+	var action string
+	switch ac := ac.(type) {
+	case daemon.AuthenticatedAccess:
+		action = ac.Polkit
+	case daemon.RootAccess:
+		action = ac.Polkit
+	case daemon.InterfaceAuthenticatedAccess:
+		action = ac.Polkit
+	case daemon.InterfaceRootAccess:
+		action = ac.Polkit
+	case daemon.ByActionAccess:
+		actions := polkitActionsOf(ac.Default)
+		for _, byAction := range ac.ByAction {
+			actions = append(actions, polkitActionsOf(byAction)...)
+		}
+		return actions
+	}
+	if action == "" {
+		return nil
+	}
+	return []string{action}
+}
+
+func (s *apiSuite) TestPolkitActionsDeclared(c *check.C) {
+	// Every polkit action used by the API must be declared in the
+	// policy shipped with snapd, so that distributions can tune the
+	// policy of each group of endpoints.
+	data, err := os.ReadFile("../data/polkit/io.snapcraft.snapd.policy")
+	c.Assert(err, check.IsNil)
+	var policy struct {
+		Actions []struct {
+			ID       string `xml:"id,attr"`
+			Defaults struct {
+				AllowAny      string `xml:"allow_any"`
+				AllowInactive string `xml:"allow_inactive"`
+				AllowActive   string `xml:"allow_active"`
+			} `xml:"defaults"`
+		} `xml:"action"`
+	}
+	c.Assert(xml.Unmarshal(data, &policy), check.IsNil)
+	declared := make(map[string]bool, len(policy.Actions))
+	for _, action := range policy.Actions {
+		declared[action.ID] = true
+		// actions must require admin authentication by default
+		for _, def := range []string{action.Defaults.AllowAny, action.Defaults.AllowInactive, action.Defaults.AllowActive} {
+			c.Check(def == "auth_admin" || def == "auth_admin_keep", check.Equals, true, check.Commentf("action %s has default %q", action.ID, def))
+		}
+	}
+
+	used := make(map[string]bool)
+	for _, cmd := range daemon.APICommands() {
+		for _, ac := range []daemon.AccessChecker{cmd.ReadAccess, cmd.WriteAccess} {
+			for _, action := range polkitActionsOf(ac) {
+				c.Check(declared[action], check.Equals, true, check.Commentf("%s uses undeclared polkit action %s", cmd.Path, action))
+				used[action] = true
+			}
+		}
+	}
+	usedList := make([]string, 0, len(used))
+	for action := range used {
+		usedList = append(usedList, action)
+	}
+	sort.Strings(usedList)
+	c.Check(usedList, check.DeepEquals, []string{
+		"io.snapcraft.snapd.login",
+		"io.snapcraft.snapd.manage",
+		"io.snapcraft.snapd.manage-configuration",
+		"io.snapcraft.snapd.manage-fde",
+		"io.snapcraft.snapd.manage-interfaces",
+		"io.snapcraft.snapd.manage-system",
+	})
+}
+
 func (s *apiSuite) TestserFromRequestNoHeader(c *check.C) {
 	req, _ := http.NewRequest("GET", "http://example.com", nil)
 
@@ -262,7 +262,7 @@ func installThemes(c *Command, r *http.Request, user *auth.UserState) Response {
 		chg = st.NewChange(installThemesChangeKind, summary)
 		chg.SetStatus(state.DoneStatus)
 	} else {
-		chg = newChange(st, installThemesChangeKind, summary, tasksets, names)
+		chg = newChange(r.Context(), st, installThemesChangeKind, summary, tasksets, names)
 		ensureStateSoon(st)
 	}
 	chg.Set("api-data", map[string]any{"snap-names": names})
@@ -35,6 +35,8 @@ import (
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/release"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/strutil"
 )
 
 var (
@@ -44,6 +46,12 @@ var (
 		ReadAccess: authenticatedAccess{},
 	}
 
+	validationSetsStatusCmd = &Command{
+		Path:       "/v2/validation-sets/status",
+		GET:        getValidationSetsStatus,
+		ReadAccess: authenticatedAccess{},
+	}
+
 	validationSetsCmd = &Command{
 		Path:        "/v2/validation-sets/{account}/{name}",
 		GET:         getValidationSet,
@@ -141,6 +149,139 @@ var checkInstalledSnaps = func(vsets *snapasserts.ValidationSets, snaps []*snapa
 	return vsets.CheckInstalledSnaps(snaps, ignoreValidation)
 }
 
+type validationSetsSnapStatus struct {
+	Name string `json:"name"`
+	// Problem is one of "missing", "invalid" or "wrong-revision".
+	Problem          string        `json:"problem"`
+	Revision         snap.Revision `json:"revision,omitempty"`
+	RequiredRevision snap.Revision `json:"required-revision,omitempty"`
+	ValidationSets   []string      `json:"validation-sets"`
+	// Fix is one of "install", "refresh" or "remove", it is unset when
+	// the validation sets are in conflict.
+	Fix string `json:"fix,omitempty"`
+}
+
+type validationSetsStatus struct {
+	ValidationSets []validationSetResult      `json:"validation-sets"`
+	Conflict       string                     `json:"conflict,omitempty"`
+	Snaps          []validationSetsSnapStatus `json:"snaps,omitempty"`
+}
+
+// getValidationSetsStatus checks the installed snaps against all the
+// tracked validation sets together, so that the reported fixes satisfy all
+// of them at once.
+func getValidationSetsStatus(c *Command, r *http.Request, _ *auth.UserState) Response {
+	st := c.d.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	validationSets, err := assertstate.ValidationSets(st)
+	if err != nil {
+		return InternalError("accessing validation sets failed: %v", err)
+	}
+	names := make([]string, 0, len(validationSets))
+	for k := range validationSets {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	snaps, _, err := snapstate.InstalledSnaps(st)
+	if err != nil {
+		return InternalError(err.Error())
+	}
+
+	status := validationSetsStatus{
+		ValidationSets: make([]validationSetResult, 0, len(names)),
+	}
+	all := snapasserts.NewValidationSets()
+	for _, vs := range names {
+		tr := validationSets[vs]
+		res, err := validationSetResultFromTracking(st, tr)
+		if err != nil {
+			return InternalError("cannot get assertion for validation set tracking %s/%s/%d: %v", tr.AccountID, tr.Name, tr.Sequence(), err)
+		}
+		status.ValidationSets = append(status.ValidationSets, *res)
+		as, err := validationSetAssertFromDb(st, tr.AccountID, tr.Name, tr.Sequence())
+		if err != nil {
+			return InternalError("cannot get assertion for validation set tracking %s/%s/%d: %v", tr.AccountID, tr.Name, tr.Sequence(), err)
+		}
+		if err := all.Add(as); err != nil {
+			return InternalError(err.Error())
+		}
+	}
+
+	// revisions satisfying all the validation sets, if they are not in
+	// conflict
+	revisions, err := all.Revisions()
+	if err != nil {
+		status.Conflict = err.Error()
+	}
+
+	validErr := checkInstalledSnaps(all, snaps, nil)
+	if validErr == nil {
+		return SyncResponse(status)
+	}
+	var verr *snapasserts.ValidationSetsValidationError
+	if !errors.As(validErr, &verr) {
+		return InternalError(validErr.Error())
+	}
+
+	installed := make(map[string]snap.Revision, len(snaps))
+	for _, sn := range snaps {
+		installed[sn.SnapName()] = sn.Revision
+	}
+	fix := func(fix string) string {
+		if status.Conflict != "" {
+			return ""
+		}
+		return fix
+	}
+	setsOf := func(bySet map[snap.Revision][]string) []string {
+		var sets []string
+		for _, s := range bySet {
+			sets = append(sets, s...)
+		}
+		sort.Strings(sets)
+		return strutil.Deduplicate(sets)
+	}
+
+	for name, bySet := range verr.MissingSnaps {
+		status.Snaps = append(status.Snaps, validationSetsSnapStatus{
+			Name:             name,
+			Problem:          "missing",
+			RequiredRevision: revisions[name],
+			ValidationSets:   setsOf(bySet),
+			Fix:              fix("install"),
+		})
+	}
+	for name, sets := range verr.InvalidSnaps {
+		sets = append([]string(nil), sets...)
+		sort.Strings(sets)
+		status.Snaps = append(status.Snaps, validationSetsSnapStatus{
+			Name:           name,
+			Problem:        "invalid",
+			Revision:       installed[name],
+			ValidationSets: sets,
+			Fix:            fix("remove"),
+		})
+	}
+	for name, bySet := range verr.WrongRevisionSnaps {
+		status.Snaps = append(status.Snaps, validationSetsSnapStatus{
+			Name:             name,
+			Problem:          "wrong-revision",
+			Revision:         installed[name],
+			RequiredRevision: revisions[name],
+			ValidationSets:   setsOf(bySet),
+			Fix:              fix("refresh"),
+		})
+	}
+	sort.Slice(status.Snaps, func(i, j int) bool {
+		return status.Snaps[i].Name < status.Snaps[j].Name
+	})
+
+	return SyncResponse(status)
+}
+
 func validationSetResultFromTracking(st *state.State, tr *assertstate.ValidationSetTracking) (*validationSetResult, error) {
 	modeStr, err := modeString(tr.Mode)
 	if err != nil {
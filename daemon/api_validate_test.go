@@ -255,6 +255,66 @@ func (s *apiValidationSetsSuite) TestListValidationSets(c *check.C) {
 	})
 }
 
+func (s *apiValidationSetsSuite) TestValidationSetsStatus(c *check.C) {
+	st := s.d.Overlord().State()
+	st.Lock()
+	s.mockValidationSetsTracking(st)
+	assertstatetest.AddMany(st, s.dev1acct, s.acct1Key)
+	as := s.mockAssert(c, "foo", "9")
+	err := assertstate.Add(st, as)
+	c.Check(err, check.IsNil)
+	as = s.mockAssert(c, "baz", "2")
+	err = assertstate.Add(st, as)
+	st.Unlock()
+	c.Assert(err, check.IsNil)
+
+	req, err := http.NewRequest("GET", "/v2/validation-sets/status", nil)
+	c.Assert(err, check.IsNil)
+	rsp := s.syncReq(c, req, nil, actionIsExpected)
+	c.Assert(rsp.Status, check.Equals, 200)
+	res := rsp.Result.(daemon.ValidationSetsStatus)
+	c.Check(res, check.DeepEquals, daemon.ValidationSetsStatus{
+		ValidationSets: []daemon.ValidationSetResult{
+			{
+				AccountID: s.dev1acct.AccountID(),
+				Name:      "baz",
+				Mode:      "monitor",
+				Sequence:  2,
+				Valid:     false,
+			},
+			{
+				AccountID: s.dev1acct.AccountID(),
+				Name:      "foo",
+				PinnedAt:  9,
+				Mode:      "enforce",
+				Sequence:  9,
+				Valid:     false,
+			},
+		},
+		Snaps: []daemon.ValidationSetsSnapStatus{{
+			Name:             "snap-b",
+			Problem:          "missing",
+			RequiredRevision: snap.R(1),
+			ValidationSets: []string{
+				fmt.Sprintf("%s/baz", s.dev1acct.AccountID()),
+				fmt.Sprintf("%s/foo", s.dev1acct.AccountID()),
+			},
+			Fix: "install",
+		}},
+	})
+}
+
+func (s *apiValidationSetsSuite) TestValidationSetsStatusValid(c *check.C) {
+	req, err := http.NewRequest("GET", "/v2/validation-sets/status", nil)
+	c.Assert(err, check.IsNil)
+	rsp := s.syncReq(c, req, nil, actionIsExpected)
+	c.Assert(rsp.Status, check.Equals, 200)
+	res := rsp.Result.(daemon.ValidationSetsStatus)
+	c.Check(res.ValidationSets, check.HasLen, 0)
+	c.Check(res.Conflict, check.Equals, "")
+	c.Check(res.Snaps, check.HasLen, 0)
+}
+
 func (s *apiValidationSetsSuite) TestGetValidationSetOne(c *check.C) {
 	s.mockSeqFormingAssertionFn = func(assertType *asserts.AssertionType, sequenceKey []string, sequence int, user *auth.UserState) (asserts.Assertion, error) {
 		return nil, &asserts.NotFoundError{
@@ -30,6 +30,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"regexp"
 	"strings"
 	"sync"
 	"time"
@@ -48,6 +49,7 @@ import (
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/standby"
 	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/randutil"
 	"github.com/snapcore/snapd/snapdenv"
 	"github.com/snapcore/snapd/store"
 	"github.com/snapcore/snapd/systemd"
@@ -80,6 +82,10 @@ type Daemon struct {
 	router          *mux.Router
 	standbyOpinions *standby.StandbyOpinions
 
+	// experimental read-only status page, see web_console.go
+	webConsoleListener net.Listener
+	webConsole         *http.Server
+
 	// set to what kind of restart was requested (if any)
 	requestedRestart restart.RestartType
 	// reboot info needed to handle reboots
@@ -179,6 +185,10 @@ func (c *Command) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			rjson.addWarningCount(count, stamp)
 		}
 
+		if rjson.Change != "" {
+			recordRequestID(st, rjson.Change, requestID(r))
+		}
+
 		// serve the updated serialisation
 		rsp = rjson
 	}
@@ -236,15 +246,68 @@ func (w *wrappedWriter) Flush() {
 	}
 }
 
+// requestIDHeader is the header carrying the ID of an API request, which
+// correlates the log messages about the request and the change it created,
+// if any.
+const requestIDHeader = "X-Request-ID"
+
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+type requestIDKey struct{}
+
+// withRequestID returns the request with its ID in its context: the one
+// provided by the client in the X-Request-ID header if valid, otherwise a
+// new one.
+func withRequestID(r *http.Request) *http.Request {
+	id := r.Header.Get(requestIDHeader)
+	if !validRequestID.MatchString(id) {
+		id = randutil.RandomString(16)
+	}
+	return r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id))
+}
+
+// requestID returns the ID of the request, if set by withRequestID.
+func requestID(r *http.Request) string {
+	id, _ := r.Context().Value(requestIDKey{}).(string)
+	return id
+}
+
+// setRequestID records the ID of the request, from its context, in the
+// change it creates, for the log messages about the change and its tasks to
+// carry it. It must be called along with creating the change, before its
+// tasks can run.
+func setRequestID(ctx context.Context, chg *state.Change) {
+	if id, _ := ctx.Value(requestIDKey{}).(string); id != "" {
+		chg.Set("request-id", id)
+	}
+}
+
+// recordRequestID records the ID of the request which created the given
+// change in it, if not done yet with setRequestID. This covers changes
+// created by managers on behalf of the request, whose first tasks may have
+// run already.
+func recordRequestID(st *state.State, changeID, reqID string) {
+	if reqID == "" {
+		return
+	}
+	st.Lock()
+	defer st.Unlock()
+	if chg := st.Change(changeID); chg != nil && !chg.Has("request-id") {
+		chg.Set("request-id", reqID)
+	}
+}
+
 func logit(handler http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = withRequestID(r)
+		w.Header().Set(requestIDHeader, requestID(r))
 		ww := &wrappedWriter{w: w}
 		t0 := time.Now()
 		handler.ServeHTTP(ww, r)
 		t := time.Since(t0)
 		url := r.URL.String()
 		if !strings.Contains(url, "/changes/") {
-			logger.Debugf("%s %s %s %s %d", r.RemoteAddr, r.Method, r.URL, t, ww.s)
+			logger.DebugAttrs(fmt.Sprintf("%s %s %s %s %d", r.RemoteAddr, r.Method, r.URL, t, ww.s), "request-id", requestID(r))
 		}
 	})
 }
@@ -422,6 +485,11 @@ func (d *Daemon) Start(ctx context.Context) (err error) {
 		return nil
 	})
 
+	if err := d.startWebConsole(); err != nil {
+		// the web console is not essential, keep going without it
+		logger.Noticef("cannot start web console: %v", err)
+	}
+
 	// notify systemd that we are ready
 	systemdSdNotify("READY=1")
 	return nil
@@ -567,6 +635,7 @@ func (d *Daemon) Stop(sigCh chan<- os.Signal) error {
 	if d.snapListener != nil {
 		d.snapListener.Close()
 	}
+	d.stopWebConsole()
 	timeSpent := time.Since(ts)
 
 	// When shutting down the snapd listener wait until the rebootNoticeWait
@@ -40,6 +40,7 @@ import (
 	"github.com/snapcore/snapd/boot"
 	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/overlord"
 	"github.com/snapcore/snapd/overlord/auth"
@@ -226,6 +227,104 @@ func (s *daemonSuite) TestCommandMethodDispatchRoot(c *check.C) {
 	c.Check(rec.Code, check.Equals, 405)
 }
 
+func (s *daemonSuite) TestLogitRequestID(c *check.C) {
+	logbuf, restore := logger.MockDebugLogger()
+	defer restore()
+
+	var seen []string
+	h := logit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, requestID(r))
+	}))
+
+	req, err := http.NewRequest("GET", "/v2/snaps", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("X-Request-ID", "client-req.1")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	c.Check(rec.Header().Get("X-Request-ID"), check.Equals, "client-req.1")
+	c.Check(logbuf.String(), testutil.Contains, "GET /v2/snaps")
+	c.Check(logbuf.String(), testutil.Contains, " request-id=client-req.1\n")
+
+	// invalid IDs are replaced
+	req.Header.Set("X-Request-ID", "not valid")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	generated := rec.Header().Get("X-Request-ID")
+	c.Check(generated, check.Matches, "[a-zA-Z0-9]{16}")
+
+	// as are missing ones
+	req.Header.Del("X-Request-ID")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	c.Check(rec.Header().Get("X-Request-ID"), check.Matches, "[a-zA-Z0-9]{16}")
+	c.Check(rec.Header().Get("X-Request-ID"), check.Not(check.Equals), generated)
+
+	c.Check(seen, check.HasLen, 3)
+	c.Check(seen[0], check.Equals, "client-req.1")
+	c.Check(seen[1], check.Equals, generated)
+}
+
+func (s *daemonSuite) TestNewChangeSetsRequestID(c *check.C) {
+	d := s.newTestDaemon(c)
+	st := d.Overlord().State()
+
+	var chgID string
+	cmd := &Command{d: d}
+	cmd.POST = func(_ *Command, r *http.Request, _ *auth.UserState) Response {
+		st.Lock()
+		defer st.Unlock()
+		chg := newChange(r.Context(), st, "foo", "...", nil, nil)
+		chgID = chg.ID()
+		// set before the change can start running
+		var reqID string
+		c.Check(chg.Get("request-id", &reqID), check.IsNil)
+		c.Check(reqID, check.Equals, "req-1")
+		return AsyncResponse(nil, chgID)
+	}
+	cmd.WriteAccess = rootAccess{}
+
+	req, err := http.NewRequest("POST", "", nil)
+	c.Assert(err, check.IsNil)
+	req.RemoteAddr = fmt.Sprintf("pid=100;uid=0;socket=%s;", dirs.SnapdSocket)
+	req.Header.Set("X-Request-ID", "req-1")
+
+	rec := httptest.NewRecorder()
+	logit(cmd).ServeHTTP(rec, req)
+	c.Check(rec.Code, check.Equals, 202)
+	c.Check(chgID, check.Not(check.Equals), "")
+}
+
+func (s *daemonSuite) TestCommandRecordsRequestIDInChange(c *check.C) {
+	d := s.newTestDaemon(c)
+	st := d.Overlord().State()
+
+	var chgID string
+	cmd := &Command{d: d}
+	cmd.POST = func(*Command, *http.Request, *auth.UserState) Response {
+		st.Lock()
+		defer st.Unlock()
+		chg := st.NewChange("foo", "...")
+		chgID = chg.ID()
+		return AsyncResponse(nil, chgID)
+	}
+	cmd.WriteAccess = rootAccess{}
+
+	req, err := http.NewRequest("POST", "", nil)
+	c.Assert(err, check.IsNil)
+	req.RemoteAddr = fmt.Sprintf("pid=100;uid=0;socket=%s;", dirs.SnapdSocket)
+	req.Header.Set("X-Request-ID", "req-1")
+
+	rec := httptest.NewRecorder()
+	logit(cmd).ServeHTTP(rec, req)
+	c.Check(rec.Code, check.Equals, 202)
+
+	st.Lock()
+	defer st.Unlock()
+	var reqID string
+	c.Assert(st.Change(chgID).Get("request-id", &reqID), check.IsNil)
+	c.Check(reqID, check.Equals, "req-1")
+}
+
 func (s *daemonSuite) TestCommandRestartingState(c *check.C) {
 	d := s.newTestDaemon(c)
 
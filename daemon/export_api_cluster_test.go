@@ -0,0 +1,46 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon
+
+import (
+	"github.com/snapcore/snapd/overlord/clusterstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/testutil"
+)
+
+func MockClusterstateAssemble(f func(*state.State, clusterstate.AssembleOptions) (*state.TaskSet, error)) (restore func()) {
+	return testutil.Mock(&clusterstateAssemble, f)
+}
+
+func MockClusterstateLeave(f func(*state.State) error) (restore func()) {
+	return testutil.Mock(&clusterstateLeave, f)
+}
+
+func MockClusterstateClusterStatus(f func(*state.State) (*clusterstate.Status, error)) (restore func()) {
+	return testutil.Mock(&clusterstateClusterStatus, f)
+}
+
+func MockClusterstateHoldRefreshes(f func(*clusterstate.ClusterManager, []string) error) (restore func()) {
+	return testutil.Mock(&clusterstateHoldRefreshes, f)
+}
+
+func MockClusterstateResumeRefreshes(f func(*clusterstate.ClusterManager, []string) error) (restore func()) {
+	return testutil.Mock(&clusterstateResumeRefreshes, f)
+}
@@ -19,7 +19,14 @@
 
 package daemon
 
-import "github.com/snapcore/snapd/testutil"
+import (
+	"io"
+	"time"
+
+	"github.com/snapcore/snapd/overlord/fdestate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/testutil"
+)
 
 type (
 	ConnectivityStatus = connectivityStatus
@@ -29,12 +36,27 @@ type (
 	RefreshCandidateInfo = refreshCandidateInfo
 	RefreshCandidate     = refreshCandidate
 	FeatureResponse      = featureResponse
+
+	RecoveryInfo          = recoveryInfo
+	RecoveryPartitionInfo = recoveryPartitionInfo
+
+	RequestTrace      = requestTrace
+	RequestTraceEntry = requestTraceEntry
 )
 
 var (
-	MinLane = minLane
+	MinLane                = minLane
+	SecurityBackendTimings = securityBackendTimings
 )
 
+func MockSnapdJournal(f func(since, until time.Time) (io.ReadCloser, error)) (restore func()) {
+	return testutil.Mock(&snapdJournal, f)
+}
+
 func MockCgroupPidsOfSnap(f func(instanceName string) (map[string][]int, error)) (restore func()) {
 	return testutil.Mock(&cgroupPidsOfSnap, f)
 }
+
+func MockFdestateGetBootStatus(f func(st *state.State) (*fdestate.BootStatus, error)) (restore func()) {
+	return testutil.Mock(&fdestateGetBootStatus, f)
+}
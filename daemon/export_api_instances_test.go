@@ -0,0 +1,44 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon
+
+import (
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+type (
+	InstanceAction = instanceAction
+)
+
+func MockSnapstateRenameInstance(f func(st *state.State, from, to string) ([]*state.TaskSet, error)) func() {
+	old := snapstateRenameInstance
+	snapstateRenameInstance = f
+	return func() {
+		snapstateRenameInstance = old
+	}
+}
+
+func MockSnapstateCopyInstanceData(f func(st *state.State, from, to string) (*state.TaskSet, error)) func() {
+	old := snapstateCopyInstanceData
+	snapstateCopyInstanceData = f
+	return func() {
+		snapstateCopyInstanceData = old
+	}
+}
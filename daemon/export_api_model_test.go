@@ -33,6 +33,14 @@ func MockDevicestateRemodel(mock func(*state.State, *asserts.Model, devicestate.
 	}
 }
 
+func MockDevicestateRemodelPreview(mock func(*state.State, *asserts.Model, devicestate.RemodelOptions) (*devicestate.RemodelPlan, error)) (restore func()) {
+	oldDevicestateRemodelPreview := devicestateRemodelPreview
+	devicestateRemodelPreview = mock
+	return func() {
+		devicestateRemodelPreview = oldDevicestateRemodelPreview
+	}
+}
+
 func MockDevicestateDeviceManagerUnregister(mock func(*devicestate.DeviceManager, *devicestate.UnregisterOptions) error) (restore func()) {
 	oldDevicestateDeviceManagerUnregister := devicestateDeviceManagerUnregister
 	devicestateDeviceManagerUnregister = mock
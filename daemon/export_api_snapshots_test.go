@@ -48,6 +48,14 @@ func MockSnapshotList(newList func(context.Context, *state.State, uint64, []stri
 	}
 }
 
+func MockSnapstateKeptConfigs(newKeptConfigs func(*state.State, []string) ([]client.KeptConfig, error)) (restore func()) {
+	oldKeptConfigs := snapstateKeptConfigs
+	snapstateKeptConfigs = newKeptConfigs
+	return func() {
+		snapstateKeptConfigs = oldKeptConfigs
+	}
+}
+
 func MockSnapshotExport(newExport func(context.Context, *state.State, uint64) (*snapshotstate.SnapshotExport, error)) (restore func()) {
 	oldExport := snapshotExport
 	snapshotExport = newExport
@@ -56,6 +64,14 @@ func MockSnapshotExport(newExport func(context.Context, *state.State, uint64) (*
 	}
 }
 
+func MockSnapshotDeepCheck(newDeepCheck func(*state.State, uint64, []string, []string) ([]string, *state.TaskSet, error)) (restore func()) {
+	oldDeepCheck := snapshotDeepCheck
+	snapshotDeepCheck = newDeepCheck
+	return func() {
+		snapshotDeepCheck = oldDeepCheck
+	}
+}
+
 func MockSnapshotCheck(newCheck func(*state.State, uint64, []string, []string) ([]string, *state.TaskSet, error)) (restore func()) {
 	oldCheck := snapshotCheck
 	snapshotCheck = newCheck
@@ -88,6 +104,38 @@ func MockSnapshotImport(newImport func(context.Context, *state.State, io.Reader)
 	}
 }
 
+func MockSnapshotStartImportUpload(newStart func(*state.State, int64) (string, error)) (restore func()) {
+	oldStart := snapshotStartImportUpload
+	snapshotStartImportUpload = newStart
+	return func() {
+		snapshotStartImportUpload = oldStart
+	}
+}
+
+func MockSnapshotImportUploadStatus(newStatus func(*state.State, string) (*snapshotstate.ImportUploadStatus, error)) (restore func()) {
+	oldStatus := snapshotImportUploadStatus
+	snapshotImportUploadStatus = newStatus
+	return func() {
+		snapshotImportUploadStatus = oldStatus
+	}
+}
+
+func MockSnapshotAppendImportUploadChunk(newAppend func(*state.State, string, int64, io.Reader, string) (*snapshotstate.ImportUploadStatus, error)) (restore func()) {
+	oldAppend := snapshotAppendImportUploadChunk
+	snapshotAppendImportUploadChunk = newAppend
+	return func() {
+		snapshotAppendImportUploadChunk = oldAppend
+	}
+}
+
+func MockSnapshotImportUploaded(newImportUploaded func(context.Context, *state.State, string) (uint64, []string, error)) (restore func()) {
+	oldImportUploaded := snapshotImportUploaded
+	snapshotImportUploaded = newImportUploaded
+	return func() {
+		snapshotImportUploaded = oldImportUploaded
+	}
+}
+
 func MustUnmarshalSnapInstruction(c *check.C, jinst string) *snapInstruction {
 	var inst snapInstruction
 	if err := json.Unmarshal([]byte(jinst), &inst); err != nil {
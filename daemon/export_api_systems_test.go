@@ -92,6 +92,10 @@ func MockDevicestateGeneratePreInstallRecoveryKey(f func(st *state.State, label
 	return testutil.Mock(&devicestateGeneratePreInstallRecoveryKey, f)
 }
 
+func MockDevicestatePreviewInstallSealing(f func(st *state.State, label string) (*client.InstallSealingPreview, error)) (restore func()) {
+	return testutil.Mock(&devicestatePreviewInstallSealing, f)
+}
+
 func MockDeviceCheckAuthQuality(f func(mode device.AuthMode, authVal string) (device.AuthQuality, error)) (restore func()) {
 	return testutil.Mock(&deviceCheckAuthQuality, f)
 }
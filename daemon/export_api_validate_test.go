@@ -26,7 +26,9 @@ import (
 )
 
 type (
-	ValidationSetResult = validationSetResult
+	ValidationSetResult      = validationSetResult
+	ValidationSetsStatus     = validationSetsStatus
+	ValidationSetsSnapStatus = validationSetsSnapStatus
 )
 
 func MockCheckInstalledSnaps(f func(vsets *snapasserts.ValidationSets, snaps []*snapasserts.InstalledSnap, ignoreValidation map[string]bool) error) func() {
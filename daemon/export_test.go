@@ -84,9 +84,9 @@ type Ucrednet = ucrednet
 
 func BeforeNewChange(beforeNewChange func(st *state.State, kind, summary string, tsets []*state.TaskSet, snapNames []string)) (restore func()) {
 	oldNewChange := newChange
-	newChange = func(st *state.State, kind, summary string, tsets []*state.TaskSet, snapNames []string) *state.Change {
+	newChange = func(ctx context.Context, st *state.State, kind, summary string, tsets []*state.TaskSet, snapNames []string) *state.Change {
 		beforeNewChange(st, kind, summary, tsets, snapNames)
-		return newChangeImpl(st, kind, summary, tsets, snapNames)
+		return newChangeImpl(ctx, st, kind, summary, tsets, snapNames)
 	}
 	return func() {
 		newChange = oldNewChange
@@ -307,6 +307,10 @@ func MockSnapstateHoldRefreshesBySystem(f func(st *state.State, level snapstate.
 	}
 }
 
+func MockSnapstateHoldRefreshesBySystemInWindows(f func(st *state.State, level snapstate.HoldLevel, time, windows string, snaps []string) error) (restore func()) {
+	return testutil.Mock(&snapstateHoldRefreshesBySystemInWindows, f)
+}
+
 func MockSnapstateRemoveComponents(mock func(st *state.State, snapName string, compName []string, opts snapstate.RemoveComponentsOpts) ([]*state.TaskSet, error)) (restore func()) {
 	oldSnapstateRemoveComponents := snapstateRemoveComponents
 	snapstateRemoveComponents = mock
@@ -469,3 +473,15 @@ func ResetBuildIDDetection() {
 func MockDevicestateReprovision(f func(st *state.State) (*state.Change, error)) (restore func()) {
 	return testutil.Mock(&devicestateReprovision, f)
 }
+
+func MockSnapFileSHA3_384(f func(snapPath string) (string, uint64, error)) (restore func()) {
+	return testutil.Mock(&snapFileSHA3_384, f)
+}
+
+func MockSBOMTimeNow(f func() time.Time) (restore func()) {
+	return testutil.Mock(&sbomTimeNow, f)
+}
+
+func MockSnapstateSimulateDiskSpace(mock func(ctx context.Context, st *state.State, op string, names []string, userID int) (*snapstate.DiskSpaceEstimate, error)) (restore func()) {
+	return testutil.Mock(&snapstateSimulateDiskSpace, mock)
+}
@@ -0,0 +1,56 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/snapcore/snapd/testutil"
+)
+
+var LoopbackPeerPid = loopbackPeerPidImpl
+
+func MockWebConsoleAddress(addr string) (restore func()) {
+	return testutil.Mock(&webConsoleAddress, addr)
+}
+
+func MockLoopbackPeerPid(f func(local, remote string) (int, error)) (restore func()) {
+	return testutil.Mock(&loopbackPeerPid, f)
+}
+
+func (d *Daemon) StartWebConsole() error {
+	return d.startWebConsole()
+}
+
+func (d *Daemon) StopWebConsole() {
+	d.stopWebConsole()
+}
+
+func (d *Daemon) WebConsoleAddr() net.Addr {
+	if d.webConsoleListener == nil {
+		return nil
+	}
+	return d.webConsoleListener.Addr()
+}
+
+func (d *Daemon) WebConsoleHandler() http.Handler {
+	return &webConsole{d: d}
+}
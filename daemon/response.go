@@ -21,6 +21,7 @@ package daemon
 
 import (
 	"bufio"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -245,14 +246,34 @@ type snapshotExportResponse struct {
 	*snapshotstate.SnapshotExport
 	setID uint64
 	st    *state.State
+
+	// range of the export to serve, if length is set
+	offset int64
+	length int64
 }
 
 // ServeHTTP from the Response interface
 func (s snapshotExportResponse) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	w.Header().Add("Content-Length", strconv.FormatInt(s.Size(), 10))
 	w.Header().Add("Content-Type", client.SnapshotExportMediaType)
-	if err := s.StreamTo(w); err != nil {
-		logger.Debugf("cannot export snapshot: %v", err)
+	w.Header().Add("Accept-Ranges", "bytes")
+	w.Header().Add("ETag", strconv.Quote(s.ETag()))
+	if s.length == 0 {
+		w.Header().Add("Content-Length", strconv.FormatInt(s.Size(), 10))
+		if err := s.StreamTo(w); err != nil {
+			logger.Debugf("cannot export snapshot: %v", err)
+		}
+	} else {
+		// the hash of the range is known only once it was streamed so
+		// it is sent as a trailer, which rules out a Content-Length
+		w.Header().Add("Content-Range", fmt.Sprintf("bytes %d-%d/%d", s.offset, s.offset+s.length-1, s.Size()))
+		w.Header().Add("Trailer", client.SnapshotChunkHashHeader)
+		w.WriteHeader(http.StatusPartialContent)
+		h := sha256.New()
+		if err := s.StreamRangeTo(io.MultiWriter(w, h), s.offset, s.length); err != nil {
+			logger.Debugf("cannot export snapshot: %v", err)
+		} else {
+			w.Header().Set(client.SnapshotChunkHashHeader, fmt.Sprintf("%x", h.Sum(nil)))
+		}
 	}
 	s.Close()
 	s.st.Lock()
@@ -0,0 +1,405 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/snapcore/snapd/arch"
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/client/clientutil"
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/features"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/hookstate/ctlcmd"
+	"github.com/snapcore/snapd/overlord/servicestate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/progress"
+)
+
+// The web console is a minimal read-only status page served over plain
+// HTTP on the loopback interface only. It is only started when the
+// experimental.web-console flag is set when snapd starts. It renders the
+// same data as the REST API, but does not authenticate the requests, so it
+// must never expose anything that the unauthenticated REST API would not.
+// The process on the other end of the connection is identified instead:
+// requests from processes of snaps are refused, as those see the REST API
+// through the restricted snap socket.
+
+var (
+	webConsoleAddress = "127.0.0.1:7181"
+
+	netListen       = net.Listen
+	loopbackPeerPid = loopbackPeerPidImpl
+)
+
+// webConsoleEnabled returns whether the experimental web-console feature is
+// enabled.
+func webConsoleEnabled(st *state.State) bool {
+	st.Lock()
+	defer st.Unlock()
+
+	tr := config.NewTransaction(st)
+	enabled, err := features.Flag(tr, features.WebConsole)
+	if err != nil && !config.IsNoOption(err) {
+		logger.Noticef("cannot check web-console feature flag: %v", err)
+		return false
+	}
+	return enabled
+}
+
+// startWebConsole starts serving the web console if the feature is enabled.
+// It must be called with d.tomb alive.
+func (d *Daemon) startWebConsole() error {
+	if !webConsoleEnabled(d.state) {
+		return nil
+	}
+
+	listener, err := netListen("tcp4", webConsoleAddress)
+	if err != nil {
+		return fmt.Errorf("cannot listen on %s: %v", webConsoleAddress, err)
+	}
+	d.webConsoleListener = listener
+	d.webConsole = &http.Server{
+		Handler:           logit(&webConsole{d: d}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	d.tomb.Go(func() error {
+		if err := d.webConsole.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+			logger.Noticef("web console stopped: %v", err)
+		}
+		return nil
+	})
+	logger.Noticef("serving the web console on http://%s/", listener.Addr())
+	return nil
+}
+
+// stopWebConsole closes the web console server and its listener, if any.
+func (d *Daemon) stopWebConsole() {
+	if d.webConsole == nil {
+		return
+	}
+	d.webConsole.Close()
+}
+
+type webConsole struct {
+	d *Daemon
+}
+
+// isLoopbackHost returns whether hostport names the loopback interface.
+func isLoopbackHost(hostport string) bool {
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host = hostport
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// checkPeer checks that the request comes from a process that may read the
+// web console, which is any local process that does not belong to a snap.
+func (wc *webConsole) checkPeer(r *http.Request) error {
+	// the Host header is checked to protect against DNS rebinding, as web
+	// pages from other origins must not be able to read the console
+	if !isLoopbackHost(r.Host) {
+		return fmt.Errorf("unexpected host %q", r.Host)
+	}
+	if !isLoopbackHost(r.RemoteAddr) {
+		return fmt.Errorf("request from %s", r.RemoteAddr)
+	}
+	local, ok := r.Context().Value(http.LocalAddrContextKey).(net.Addr)
+	if !ok {
+		return fmt.Errorf("cannot determine the local address of the request")
+	}
+	pid, err := loopbackPeerPid(local.String(), r.RemoteAddr)
+	if err != nil {
+		return fmt.Errorf("cannot identify peer %s: %v", r.RemoteAddr, err)
+	}
+	if snapName, err := cgroupSnapNameFromPid(pid); err == nil {
+		return fmt.Errorf("request from snap %q", snapName)
+	}
+	return nil
+}
+
+// procNetTCPAddr returns the IPv4 address in the format used by
+// /proc/net/tcp, where the address is printed as a native endian number.
+func procNetTCPAddr(hostport string) (string, error) {
+	host, portStr, err := net.SplitHostPort(hostport)
+	if err != nil {
+		return "", err
+	}
+	ip := net.ParseIP(host).To4()
+	if ip == nil {
+		return "", fmt.Errorf("not an IPv4 address: %q", host)
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return "", fmt.Errorf("invalid port %q", portStr)
+	}
+	return fmt.Sprintf("%08X:%04X", arch.Endian().Uint32(ip), port), nil
+}
+
+// loopbackPeerPidImpl returns the id of the process owning the client end of
+// the IPv4 loopback connection between remote and local, as seen by the
+// server. Both ends of connections on the loopback interface are listed in
+// /proc/net/tcp along with the inode of their socket, which is then looked up
+// among the file descriptors of the processes.
+func loopbackPeerPidImpl(local, remote string) (int, error) {
+	// the client end is local to the client, connected to the server
+	clientAddr, err := procNetTCPAddr(remote)
+	if err != nil {
+		return 0, err
+	}
+	serverAddr, err := procNetTCPAddr(local)
+	if err != nil {
+		return 0, err
+	}
+
+	procDir := filepath.Join(dirs.GlobalRootDir, "/proc")
+	f, err := os.Open(filepath.Join(procDir, "net/tcp"))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var inode string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when
+		// retrnsmt uid timeout inode ...
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[1] != clientAddr || fields[2] != serverAddr {
+			continue
+		}
+		inode = fields[9]
+		break
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	if inode == "" || inode == "0" {
+		return 0, fmt.Errorf("connection not found")
+	}
+
+	target := fmt.Sprintf("socket:[%s]", inode)
+	procs, err := os.ReadDir(procDir)
+	if err != nil {
+		return 0, err
+	}
+	for _, proc := range procs {
+		pid, err := strconv.Atoi(proc.Name())
+		if err != nil {
+			continue
+		}
+		fdDir := filepath.Join(procDir, proc.Name(), "fd")
+		// the process may be gone already
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			if link, err := os.Readlink(filepath.Join(fdDir, fd.Name())); err == nil && link == target {
+				return pid, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("no process owns the connection")
+}
+
+func (wc *webConsole) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := wc.checkPeer(r); err != nil {
+		logger.Noticef("refusing web console request: %v", err)
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	// the flag may have been unset since snapd started
+	if !webConsoleEnabled(wc.d.state) {
+		http.NotFound(w, r)
+		return
+	}
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != "GET" && r.Method != "HEAD" {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	data, err := wc.collect()
+	if err != nil {
+		logger.Noticef("cannot collect web console data: %v", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	hdr := w.Header()
+	hdr.Set("Content-Type", "text/html; charset=utf-8")
+	hdr.Set("Cache-Control", "no-store")
+	hdr.Set("X-Content-Type-Options", "nosniff")
+	hdr.Set("X-Frame-Options", "DENY")
+	hdr.Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+	if err := webConsoleTemplate.Execute(w, data); err != nil {
+		logger.Noticef("cannot render web console: %v", err)
+	}
+}
+
+type webConsoleData struct {
+	Snaps    []*client.Snap
+	Services []client.AppInfo
+	Changes  []*ctlcmd.ChangeInfo
+	Warnings []*client.Warning
+}
+
+// collect gathers the data shown by the web console, using the same helpers
+// as the corresponding REST API endpoints.
+func (wc *webConsole) collect() (*webConsoleData, error) {
+	st := wc.d.state
+	sd := servicestate.NewStatusDecorator(progress.Null)
+
+	// as in GET /v2/snaps
+	found, err := allLocalSnapInfos(st, snapSelectNone, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list local snaps: %v", err)
+	}
+	snaps := make([]*client.Snap, 0, len(found))
+	for _, x := range found {
+		snaps = append(snaps, mapLocal(x, sd))
+	}
+	sort.Slice(snaps, func(i, j int) bool { return snaps[i].Name < snaps[j].Name })
+
+	// as in GET /v2/apps?select=service
+	appInfos, rspe := appInfosFor(st, nil, appInfoOptions{service: true})
+	if rspe != nil {
+		return nil, fmt.Errorf("cannot list services: %v", rspe)
+	}
+	services, err := clientutil.ClientAppInfosFromSnapAppInfos(appInfos, sd)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list services: %v", err)
+	}
+
+	// as in GET /v2/changes?select=all
+	st.Lock()
+	chgs := st.Changes()
+	changes := make([]*ctlcmd.ChangeInfo, 0, len(chgs))
+	for _, chg := range chgs {
+		changes = append(changes, ctlcmd.StateChangeToChangeInfo(chg))
+	}
+	st.Unlock()
+	sort.Slice(changes, func(i, j int) bool { return changes[i].SpawnTime.After(changes[j].SpawnTime) })
+
+	// as in GET /v2/warnings?select=all, going through the JSON
+	// representation as the state does not export the warning details
+	ws := stateAllWarnings(st)
+	warnings := make([]*client.Warning, 0, len(ws))
+	for _, sw := range ws {
+		data, err := json.Marshal(sw)
+		if err != nil {
+			return nil, fmt.Errorf("cannot serialize warning: %v", err)
+		}
+		// the durations are serialized as strings, as done by the client
+		var jw struct {
+			client.Warning
+			ExpireAfter string `json:"expire-after,omitempty"`
+			RepeatAfter string `json:"repeat-after,omitempty"`
+		}
+		if err := json.Unmarshal(data, &jw); err != nil {
+			return nil, fmt.Errorf("cannot decode warning: %v", err)
+		}
+		cw := jw.Warning
+		cw.ExpireAfter, _ = time.ParseDuration(jw.ExpireAfter)
+		cw.RepeatAfter, _ = time.ParseDuration(jw.RepeatAfter)
+		warnings = append(warnings, &cw)
+	}
+
+	return &webConsoleData{
+		Snaps:    snaps,
+		Services: services,
+		Changes:  changes,
+		Warnings: warnings,
+	}, nil
+}
+
+var webConsoleTemplate = template.Must(template.New("web-console").Funcs(template.FuncMap{
+	"timestamp": func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+		return t.Local().Format(time.RFC3339)
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>snapd</title>
+<style>
+body { font-family: sans-serif; margin: 1em 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { text-align: left; padding: 0.2em 1em 0.2em 0; }
+th { border-bottom: 1px solid #888; }
+</style>
+</head>
+<body>
+<h1>snapd</h1>
+
+<h2>Snaps</h2>
+{{if .Snaps}}<table>
+<tr><th>Name</th><th>Version</th><th>Rev</th><th>Tracking</th><th>Publisher</th><th>Status</th></tr>
+{{range .Snaps}}<tr><td>{{.Name}}</td><td>{{.Version}}</td><td>{{.Revision}}</td><td>{{or .TrackingChannel "-"}}</td><td>{{with .Publisher}}{{.Username}}{{else}}-{{end}}</td><td>{{.Status}}</td></tr>
+{{end}}</table>{{else}}<p>No snaps are installed.</p>{{end}}
+
+<h2>Services</h2>
+{{if .Services}}<table>
+<tr><th>Service</th><th>Startup</th><th>Current</th></tr>
+{{range .Services}}<tr><td>{{.Snap}}.{{.Name}}</td><td>{{if .Enabled}}enabled{{else}}disabled{{end}}</td><td>{{if .Active}}active{{else}}inactive{{end}}</td></tr>
+{{end}}</table>{{else}}<p>There are no services.</p>{{end}}
+
+<h2>Changes</h2>
+{{if .Changes}}<table>
+<tr><th>ID</th><th>Status</th><th>Spawn</th><th>Ready</th><th>Summary</th></tr>
+{{range .Changes}}<tr><td>{{.ID}}</td><td>{{.Status}}</td><td>{{timestamp .SpawnTime}}</td><td>{{with .ReadyTime}}{{timestamp .}}{{else}}-{{end}}</td><td>{{.Summary}}</td></tr>
+{{end}}</table>{{else}}<p>There are no changes.</p>{{end}}
+
+<h2>Warnings</h2>
+{{if .Warnings}}<table>
+<tr><th>Last occurrence</th><th>Warning</th></tr>
+{{range .Warnings}}<tr><td>{{timestamp .LastAdded}}</td><td>{{.Message}}</td></tr>
+{{end}}</table>{{else}}<p>There are no warnings.</p>{{end}}
+</body>
+</html>
+`))
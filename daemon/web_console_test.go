@@ -0,0 +1,267 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon_test
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/arch"
+	"github.com/snapcore/snapd/daemon"
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/snap"
+)
+
+var _ = Suite(&webConsoleSuite{})
+
+type webConsoleSuite struct {
+	apiBaseSuite
+
+	snapPids map[int]string
+}
+
+func (s *webConsoleSuite) SetUpTest(c *C) {
+	s.apiBaseSuite.SetUpTest(c)
+
+	s.AddCleanup(daemon.MockWebConsoleAddress("127.0.0.1:0"))
+
+	s.snapPids = make(map[int]string)
+	s.AddCleanup(daemon.MockLoopbackPeerPid(func(local, remote string) (int, error) {
+		c.Check(local, Equals, "127.0.0.1:7181")
+		if remote == "127.0.0.1:4242" {
+			return 100, nil
+		}
+		return 0, errors.New("connection not found")
+	}))
+	s.AddCleanup(daemon.MockCgroupSnapNameFromPid(func(pid int) (string, error) {
+		if name, ok := s.snapPids[pid]; ok {
+			return name, nil
+		}
+		return "", errors.New("not a snap")
+	}))
+}
+
+func (s *webConsoleSuite) enable(c *C, d *daemon.Daemon) {
+	st := d.Overlord().State()
+	st.Lock()
+	defer st.Unlock()
+	tr := config.NewTransaction(st)
+	c.Assert(tr.Set("core", "experimental.web-console", true), IsNil)
+	tr.Commit()
+}
+
+func (s *webConsoleSuite) request(c *C, d *daemon.Daemon, method, host, path, remoteAddr string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest(method, "http://"+host+path, nil)
+	c.Assert(err, IsNil)
+	req.RemoteAddr = remoteAddr
+	local := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 7181}
+	req = req.WithContext(context.WithValue(req.Context(), http.LocalAddrContextKey, local))
+	rec := httptest.NewRecorder()
+	d.WebConsoleHandler().ServeHTTP(rec, req)
+	return rec
+}
+
+func (s *webConsoleSuite) get(c *C, d *daemon.Daemon, method string) *httptest.ResponseRecorder {
+	return s.request(c, d, method, "127.0.0.1:7181", "/", "127.0.0.1:4242")
+}
+
+func (s *webConsoleSuite) TestRendersStatus(c *C) {
+	d := s.daemon(c)
+	s.enable(c, d)
+	s.mkInstalledInState(c, d, "foo", "bar", "v1", snap.R(10), true, "")
+
+	st := d.Overlord().State()
+	st.Lock()
+	st.NewChange("install", "Install <foo>")
+	st.Warnf("something <bad> happened")
+	st.Unlock()
+
+	rec := s.get(c, d, "GET")
+	c.Assert(rec.Code, Equals, 200)
+	c.Check(rec.Header().Get("Content-Type"), Equals, "text/html; charset=utf-8")
+	c.Check(rec.Header().Get("X-Frame-Options"), Equals, "DENY")
+	c.Check(rec.Header().Get("Access-Control-Allow-Origin"), Equals, "")
+
+	body := rec.Body.String()
+	c.Check(body, Matches, `(?s).*<td>foo</td><td>v1</td><td>10</td>.*`)
+	// content is escaped
+	c.Check(body, Matches, `(?s).*<td>Install &lt;foo&gt;</td>.*`)
+	c.Check(body, Matches, `(?s).*<td>something &lt;bad&gt; happened</td>.*`)
+	c.Check(body, Matches, `(?s).*There are no services\..*`)
+}
+
+func (s *webConsoleSuite) TestDisabled(c *C) {
+	d := s.daemon(c)
+
+	rec := s.get(c, d, "GET")
+	c.Check(rec.Code, Equals, 404)
+}
+
+func (s *webConsoleSuite) TestReadOnly(c *C) {
+	d := s.daemon(c)
+	s.enable(c, d)
+
+	for _, method := range []string{"POST", "PUT", "DELETE"} {
+		rec := s.get(c, d, method)
+		c.Check(rec.Code, Equals, 405, Commentf(method))
+		c.Check(rec.Header().Get("Allow"), Equals, "GET, HEAD")
+	}
+}
+
+func (s *webConsoleSuite) TestRefusesSnaps(c *C) {
+	d := s.daemon(c)
+	s.enable(c, d)
+
+	s.snapPids[100] = "foo"
+	rec := s.get(c, d, "GET")
+	c.Check(rec.Code, Equals, 403)
+}
+
+func (s *webConsoleSuite) TestRefusesUnknownPeers(c *C) {
+	d := s.daemon(c)
+	s.enable(c, d)
+
+	for _, remoteAddr := range []string{
+		// not the loopback interface
+		"10.0.0.1:4242",
+		"",
+		// no process owns the connection
+		"127.0.0.1:4343",
+	} {
+		rec := s.request(c, d, "GET", "127.0.0.1:7181", "/", remoteAddr)
+		c.Check(rec.Code, Equals, 403, Commentf(remoteAddr))
+	}
+}
+
+func (s *webConsoleSuite) TestNonLoopbackHost(c *C) {
+	d := s.daemon(c)
+	s.enable(c, d)
+
+	for _, host := range []string{"example.com", "example.com:7181", "192.168.1.1:7181"} {
+		rec := s.request(c, d, "GET", host, "/", "127.0.0.1:4242")
+		c.Check(rec.Code, Equals, 403, Commentf(host))
+	}
+
+	for _, host := range []string{"localhost", "localhost:7181", "127.0.0.1:7181", "[::1]:7181"} {
+		rec := s.request(c, d, "GET", host, "/", "127.0.0.1:4242")
+		c.Check(rec.Code, Equals, 200, Commentf(host))
+	}
+}
+
+func (s *webConsoleSuite) TestUnknownPath(c *C) {
+	d := s.daemon(c)
+	s.enable(c, d)
+
+	rec := s.request(c, d, "GET", "localhost", "/v2/snaps", "127.0.0.1:4242")
+	c.Check(rec.Code, Equals, 404)
+}
+
+func (s *webConsoleSuite) TestStartNotEnabled(c *C) {
+	d := s.daemon(c)
+
+	c.Assert(d.StartWebConsole(), IsNil)
+	c.Check(d.WebConsoleAddr(), IsNil)
+	// no-op
+	d.StopWebConsole()
+}
+
+func (s *webConsoleSuite) TestStartServes(c *C) {
+	d := s.daemon(c)
+	s.enable(c, d)
+
+	c.Assert(d.StartWebConsole(), IsNil)
+	defer d.StopWebConsole()
+	addr := d.WebConsoleAddr()
+	c.Assert(addr, NotNil)
+
+	var peerLookups int
+	restore := daemon.MockLoopbackPeerPid(func(local, remote string) (int, error) {
+		peerLookups++
+		c.Check(local, Equals, addr.String())
+		c.Check(remote, Matches, `127\.0\.0\.1:[0-9]+`)
+		return 100, nil
+	})
+	defer restore()
+
+	rsp, err := http.Get(fmt.Sprintf("http://%s/", addr))
+	c.Assert(err, IsNil)
+	defer rsp.Body.Close()
+	c.Check(rsp.StatusCode, Equals, 200)
+	body, err := io.ReadAll(rsp.Body)
+	c.Assert(err, IsNil)
+	c.Check(string(body), Matches, `(?s).*<h2>Snaps</h2>.*`)
+	c.Check(peerLookups, Equals, 1)
+}
+
+func (s *webConsoleSuite) TestLoopbackPeerPid(c *C) {
+	dirs.SetRootDir(c.MkDir())
+	defer dirs.SetRootDir("")
+
+	// the server end, the client end and an unrelated connection
+	procNetTCP := `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 0100007F:1C0D 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1111 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:1C0D 0100007F:1092 01 00000000:00000000 00:00000000 00000000     0        0 2222 1 0000000000000000 20 4 30 10 -1
+   2: 0100007F:1092 0100007F:1C0D 01 00000000:00000000 00:00000000 00000000  1000        0 3333 1 0000000000000000 20 4 30 10 -1
+   3: 0100007F:1093 0100007F:0050 01 00000000:00000000 00:00000000 00000000  1000        0 4444 1 0000000000000000 20 4 30 10 -1
+`
+	if arch.Endian() == binary.BigEndian {
+		procNetTCP = strings.ReplaceAll(procNetTCP, "0100007F", "7F000001")
+	}
+	procDir := filepath.Join(dirs.GlobalRootDir, "/proc")
+	c.Assert(os.MkdirAll(filepath.Join(procDir, "net"), 0755), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(procDir, "net/tcp"), []byte(procNetTCP), 0644), IsNil)
+	for pid, sockets := range map[string][]string{
+		"1":    {"socket:[1111]", "socket:[2222]"},
+		"4242": {"/dev/null", "socket:[3333]"},
+		"4343": {"socket:[4444]"},
+	} {
+		fdDir := filepath.Join(procDir, pid, "fd")
+		c.Assert(os.MkdirAll(fdDir, 0755), IsNil)
+		for i, target := range sockets {
+			c.Assert(os.Symlink(target, filepath.Join(fdDir, fmt.Sprint(i))), IsNil)
+		}
+	}
+
+	// 0x1C0D is 7181 and 0x1092 is 4242
+	pid, err := daemon.LoopbackPeerPid("127.0.0.1:7181", "127.0.0.1:4242")
+	c.Assert(err, IsNil)
+	c.Check(pid, Equals, 4242)
+
+	_, err = daemon.LoopbackPeerPid("127.0.0.1:7181", "127.0.0.1:4243")
+	c.Check(err, ErrorMatches, "connection not found")
+	_, err = daemon.LoopbackPeerPid("127.0.0.1:7181", "[::1]:4242")
+	c.Check(err, ErrorMatches, `not an IPv4 address: "::1"`)
+
+	c.Assert(os.Remove(filepath.Join(procDir, "4242/fd/1")), IsNil)
+	_, err = daemon.LoopbackPeerPid("127.0.0.1:7181", "127.0.0.1:4242")
+	c.Check(err, ErrorMatches, "no process owns the connection")
+}
@@ -87,9 +87,12 @@ var (
 	SnapSeqDir            string
 
 	SnapStateFile     string
+	SnapStateLogFile  string
 	SnapStateLockFile string
 	SnapSystemKeyFile string
 
+	SnapReexecTargetsFile string
+
 	SnapRepairConfigFile string
 	SnapRepairDir        string
 	SnapRepairStateFile  string
@@ -624,8 +627,10 @@ func SetRootDir(rootdir string) {
 	SnapSeqDir = filepath.Join(rootdir, snappyDir, "sequence")
 
 	SnapStateFile = SnapStateFileUnder(rootdir)
+	SnapStateLogFile = filepath.Join(rootdir, snappyDir, "state.log")
 	SnapStateLockFile = SnapStateLockFileUnder(rootdir)
 	SnapSystemKeyFile = filepath.Join(rootdir, snappyDir, "system-key")
+	SnapReexecTargetsFile = filepath.Join(rootdir, snappyDir, "reexec-targets.json")
 
 	SnapCacheDir = filepath.Join(rootdir, "/var/cache/snapd")
 	SnapNamesFile = filepath.Join(SnapCacheDir, "names")
@@ -76,6 +76,18 @@ const (
 	SeedRefresh
 	// SnapDeltaFormat enables deltas that use the "snap delta" format
 	SnapDeltaFormat
+	// ZstdDeltaFormat enables deltas generated with zstd on the compressed snaps
+	ZstdDeltaFormat
+	// WebConsole enables the read-only status page served on localhost.
+	WebConsole
+	// Metrics enables the metrics endpoint in the Prometheus format.
+	Metrics
+	// StateLog enables persisting the state incrementally through an
+	// append-only log of changes instead of rewriting it in full.
+	StateLog
+	// SealedState enables sealing the sensitive fields of the state, like
+	// store credentials, with a key derived from the disk encryption keys.
+	SealedState
 	// lastFeature is the final known feature, it is only used for testing.
 	lastFeature
 )
@@ -126,6 +138,13 @@ var featureNames = map[SnapdFeature]string{
 	SeedRefresh: "seed-refresh",
 
 	SnapDeltaFormat: "snap-delta-format",
+	ZstdDeltaFormat: "zstd-delta-format",
+
+	WebConsole: "web-console",
+	Metrics:    "metrics",
+
+	StateLog:    "state-log",
+	SealedState: "sealed-state",
 }
 
 // featuresEnabledWhenUnset contains a set of features that are enabled when not explicitly configured.
@@ -143,6 +162,9 @@ var featuresExported = map[SnapdFeature]bool{
 	RefreshAppAwarenessUX: true,
 	Confdb:                true,
 	AppArmorPrompting:     true,
+
+	StateLog:    true,
+	SealedState: true,
 }
 
 // featuresGraduated contains features that used to be guarded by an
@@ -64,6 +64,11 @@ func (*featureSuite) TestName(c *C) {
 	check(features.RemoteDeviceManagement, "remote-device-management")
 	check(features.SeedRefresh, "seed-refresh")
 	check(features.SnapDeltaFormat, "snap-delta-format")
+	check(features.ZstdDeltaFormat, "zstd-delta-format")
+	check(features.WebConsole, "web-console")
+	check(features.Metrics, "metrics")
+	check(features.StateLog, "state-log")
+	check(features.SealedState, "sealed-state")
 
 	c.Check(tested, Equals, features.NumberOfFeatures())
 	c.Check(func() { _ = features.SnapdFeature(1000).String() }, PanicMatches, "unknown feature flag code 1000")
@@ -104,6 +109,11 @@ func (*featureSuite) TestIsExported(c *C) {
 	check(features.RemoteDeviceManagement, false)
 	check(features.SeedRefresh, false)
 	check(features.SnapDeltaFormat, false)
+	check(features.ZstdDeltaFormat, false)
+	check(features.WebConsole, false)
+	check(features.Metrics, false)
+	check(features.StateLog, true)
+	check(features.SealedState, true)
 
 	c.Check(tested, Equals, features.NumberOfFeatures())
 }
@@ -239,6 +249,11 @@ func (*featureSuite) TestIsEnabledWhenUnset(c *C) {
 	check(features.RemoteDeviceManagement, false)
 	check(features.SeedRefresh, false)
 	check(features.SnapDeltaFormat, false)
+	check(features.ZstdDeltaFormat, false)
+	check(features.WebConsole, false)
+	check(features.Metrics, false)
+	check(features.StateLog, false)
+	check(features.SealedState, false)
 
 	c.Check(tested, Equals, features.NumberOfFeatures())
 }
@@ -250,6 +265,8 @@ func (*featureSuite) TestControlFile(c *C) {
 	c.Check(features.RefreshAppAwarenessUX.ControlFile(), Equals, "/var/lib/snapd/features/refresh-app-awareness-ux")
 	c.Check(features.Confdb.ControlFile(), Equals, "/var/lib/snapd/features/confdb")
 	c.Check(features.AppArmorPrompting.ControlFile(), Equals, "/var/lib/snapd/features/apparmor-prompting")
+	c.Check(features.StateLog.ControlFile(), Equals, "/var/lib/snapd/features/state-log")
+	c.Check(features.SealedState.ControlFile(), Equals, "/var/lib/snapd/features/sealed-state")
 	// Features that are not exported don't have a control file.
 	c.Check(features.Hotplug.ControlFile, PanicMatches, `cannot compute the control file of feature "hotplug" because that feature is not exported`)
 }
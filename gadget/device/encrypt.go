@@ -71,6 +71,12 @@ func DataSealedKeyUnder(deviceFDEDir string) string {
 	return filepath.Join(deviceFDEDir, "ubuntu-data.sealed-key")
 }
 
+// DataNetworkKeysUnder returns the path of what is needed to recover the
+// keys of ubuntu-data bound to key servers.
+func DataNetworkKeysUnder(deviceFDEDir string) string {
+	return filepath.Join(deviceFDEDir, "ubuntu-data.network-keys")
+}
+
 // SaveKeyUnder returns the path of a plain encryption key for ubuntu-save.
 func SaveKeyUnder(deviceFDEDir string) string {
 	return filepath.Join(deviceFDEDir, "ubuntu-save.key")
@@ -96,6 +96,8 @@ func (s *deviceSuite) TestReadEncryptionMarkers(c *C) {
 func (s *deviceSuite) TestLocations(c *C) {
 	c.Check(device.DataSealedKeyUnder(boot.InitramfsBootEncryptionKeyDir), Equals,
 		"/run/mnt/ubuntu-boot/device/fde/ubuntu-data.sealed-key")
+	c.Check(device.DataNetworkKeysUnder(boot.InitramfsBootEncryptionKeyDir), Equals,
+		"/run/mnt/ubuntu-boot/device/fde/ubuntu-data.network-keys")
 	c.Check(device.SaveKeyUnder(dirs.SnapFDEDir), Equals,
 		"/var/lib/snapd/device/fde/ubuntu-save.key")
 	c.Check(device.FallbackDataSealedKeyUnder(boot.InitramfsSeedEncryptionKeyDir), Equals,
@@ -0,0 +1,241 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package gadget
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+const (
+	// DeviceMappingLinear concatenates the partitions of one or more
+	// structures into a single device-mapper device.
+	DeviceMappingLinear = "linear"
+	// DeviceMappingVerity exposes the partition of a structure as a
+	// read-only device-mapper device that is verified against a
+	// dm-verity hash tree stored in the partition of another structure.
+	DeviceMappingVerity = "verity"
+)
+
+// DeviceMapping describes a device-mapper device that is assembled out of
+// raw structures by snap-bootstrap in the initramfs, and that is available as
+// /dev/mapper/<name> after boot.
+type DeviceMapping struct {
+	// Type is the device-mapper target, either "linear" or "verity".
+	Type string `yaml:"type" json:"type"`
+	// Name is the name of the mapped device, it defaults to the name of
+	// the structure.
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+	// Segments are, for linear mappings, the structures whose partitions
+	// are appended, in order, after the one of the structure.
+	Segments []string `yaml:"segments,omitempty" json:"segments,omitempty"`
+	// HashStructure is, for verity mappings, the structure which
+	// partition carries the hash tree of the data in the structure.
+	HashStructure string `yaml:"hash-structure,omitempty" json:"hash-structure,omitempty"`
+	// RootHash is, for verity mappings, the hex encoded root hash of the
+	// hash tree. It is the trust anchor of the mapping: gadget.yaml is
+	// covered by the snap-revision assertion of the gadget snap, so the
+	// root hash is only as trusted as the gadget.
+	RootHash string `yaml:"root-hash,omitempty" json:"root-hash,omitempty"`
+}
+
+// MappedDeviceName returns the name of the device-mapper device described by
+// the structure, or an empty string if the structure has no device mapping.
+func (vs *VolumeStructure) MappedDeviceName() string {
+	if vs.DeviceMapping == nil {
+		return ""
+	}
+	if vs.DeviceMapping.Name != "" {
+		return vs.DeviceMapping.Name
+	}
+	return vs.Name
+}
+
+var (
+	validDeviceMappingName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]{0,126}$`)
+	// only sha256, the default of veritysetup, is supported
+	validVerityRootHash = regexp.MustCompile(`^[0-9a-f]{64}$`)
+)
+
+// validateDeviceMapping checks the device mapping of a single structure,
+// references to other structures are checked by validateDeviceMappings.
+func validateDeviceMapping(vs *VolumeStructure) error {
+	dm := vs.DeviceMapping
+	if vs.Name == "" {
+		return errors.New("structures with a device mapping must be named")
+	}
+	if !vs.IsPartition() {
+		return errors.New("device mappings are only supported for partitions")
+	}
+	if vs.Role != "" {
+		return fmt.Errorf("device mappings are not supported for structures with role %q", vs.Role)
+	}
+	if vs.HasFilesystem() {
+		return errors.New("structures with a device mapping cannot have a filesystem")
+	}
+	if !validDeviceMappingName.MatchString(vs.MappedDeviceName()) {
+		return fmt.Errorf("invalid device mapping name %q", vs.MappedDeviceName())
+	}
+
+	switch dm.Type {
+	case DeviceMappingLinear:
+		if dm.HashStructure != "" {
+			return errors.New("hash-structure is only supported for verity device mappings")
+		}
+		if dm.RootHash != "" {
+			return errors.New("root-hash is only supported for verity device mappings")
+		}
+		for _, name := range dm.Segments {
+			if name == vs.Name {
+				return fmt.Errorf("structure %q cannot be a segment of its own device mapping", name)
+			}
+		}
+	case DeviceMappingVerity:
+		if len(dm.Segments) != 0 {
+			return errors.New("segments are only supported for linear device mappings")
+		}
+		if dm.HashStructure == "" {
+			return errors.New("verity device mapping requires a hash-structure")
+		}
+		if dm.HashStructure == vs.Name {
+			return errors.New("verity device mapping cannot use its own structure for the hash tree")
+		}
+		if !validVerityRootHash.MatchString(dm.RootHash) {
+			return fmt.Errorf("invalid verity root hash %q", dm.RootHash)
+		}
+	default:
+		return fmt.Errorf("invalid device mapping type %q", dm.Type)
+	}
+	return nil
+}
+
+// deviceMappingReferences returns the structures referenced by a device
+// mapping besides the structure that defines it.
+func deviceMappingReferences(dm *DeviceMapping) []string {
+	if dm.Type == DeviceMappingVerity {
+		return []string{dm.HashStructure}
+	}
+	return dm.Segments
+}
+
+// validateDeviceMappings checks the structures that device mappings
+// reference. Mappings can only reference structures of their own volume, and
+// that volume must be the boot volume, which is the only one snap-bootstrap
+// looks at. Mapped device names must be unique across volumes as they share
+// /dev/mapper.
+func validateDeviceMappings(vols map[string]*Volume) error {
+	mappedNames := make(map[string]bool)
+	for _, vol := range vols {
+		isBootVolume := false
+		byName := make(map[string]*VolumeStructure, len(vol.Structure))
+		for i := range vol.Structure {
+			vs := &vol.Structure[i]
+			if vs.Role == SystemBoot {
+				isBootVolume = true
+			}
+			if vs.Name != "" {
+				byName[vs.Name] = vs
+			}
+		}
+
+		referenced := make(map[string]bool)
+		for _, vs := range vol.Structure {
+			if vs.DeviceMapping == nil {
+				continue
+			}
+			if !isBootVolume {
+				return fmt.Errorf("invalid volume %q: device mappings are only supported in the volume with the system-boot role", vol.Name)
+			}
+			name := vs.MappedDeviceName()
+			if mappedNames[name] {
+				return fmt.Errorf("device mapping name %q is not unique", name)
+			}
+			mappedNames[name] = true
+
+			for _, refName := range deviceMappingReferences(vs.DeviceMapping) {
+				ref := byName[refName]
+				switch {
+				case ref == nil:
+					return fmt.Errorf("invalid volume %q: device mapping of structure %q refers to missing structure %q", vol.Name, vs.Name, refName)
+				case ref.DeviceMapping != nil:
+					return fmt.Errorf("invalid volume %q: device mapping of structure %q refers to structure %q which has a device mapping itself", vol.Name, vs.Name, refName)
+				}
+				if referenced[refName] {
+					return fmt.Errorf("invalid volume %q: structure %q is used more than once by device mappings", vol.Name, refName)
+				}
+				referenced[refName] = true
+				if !ref.IsPartition() || ref.Role != "" || ref.HasFilesystem() {
+					return fmt.Errorf("invalid volume %q: structure %q used by device mapping of structure %q must be a partition with no role or filesystem", vol.Name, refName, vs.Name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func sameDeviceMapping(from, to *VolumeStructure) bool {
+	fromDm, toDm := from.DeviceMapping, to.DeviceMapping
+	if fromDm == nil || toDm == nil {
+		return fromDm == toDm
+	}
+	if fromDm.Type != toDm.Type || from.MappedDeviceName() != to.MappedDeviceName() ||
+		fromDm.HashStructure != toDm.HashStructure || len(fromDm.Segments) != len(toDm.Segments) {
+		return false
+	}
+	for i := range fromDm.Segments {
+		if fromDm.Segments[i] != toDm.Segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// checkDeviceMappingUpdates checks that the device mappings of a volume are
+// kept by an update, except for the root hashes of verity mappings. As the
+// data and the hash tree of a verity mapping must match its root hash when
+// the mapping is set up on the next boot, their structures must be updated
+// together, and a new root hash can only come with such an update.
+func checkDeviceMappingUpdates(oldVol *PartiallyLaidOutVolume, newVol *LaidOutVolume, updates []updatePair) error {
+	updated := make(map[string]bool, len(updates))
+	for _, u := range updates {
+		updated[u.to.Name()] = true
+	}
+	for i := range newVol.LaidOutStructure {
+		from := oldVol.LaidOutStructure[i].VolumeStructure
+		to := newVol.LaidOutStructure[i].VolumeStructure
+		if !sameDeviceMapping(from, to) {
+			return fmt.Errorf("cannot change the device mapping of structure %q", to.Name)
+		}
+		if to.DeviceMapping == nil || to.DeviceMapping.Type != DeviceMappingVerity {
+			continue
+		}
+		hashName := to.DeviceMapping.HashStructure
+		if updated[to.Name] != updated[hashName] {
+			return fmt.Errorf("cannot update only one of structures %q and %q of verity device mapping %q",
+				to.Name, hashName, to.MappedDeviceName())
+		}
+		if from.DeviceMapping.RootHash != to.DeviceMapping.RootHash && !updated[to.Name] {
+			return fmt.Errorf("cannot change the root hash of verity device mapping %q without updating its structures",
+				to.MappedDeviceName())
+		}
+	}
+	return nil
+}
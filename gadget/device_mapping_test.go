@@ -0,0 +1,227 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package gadget_test
+
+import (
+	"strings"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/gadget"
+)
+
+const mockVerityRootHash = "cf9a379613c0dc10301fe3eba4665c38b849b7aad311471faa4d2392ee4ede49"
+
+var deviceMappingStructures = `
+      - name: payload
+        type: 0FC63DAF-8483-4772-8E79-3D69D8477DE4
+        size: 64M
+        content:
+          - image: payload.img
+        device-mapping:
+          type: verity
+          hash-structure: payload-hash
+          root-hash: ` + mockVerityRootHash + `
+      - name: payload-hash
+        type: 0FC63DAF-8483-4772-8E79-3D69D8477DE4
+        size: 1M
+        content:
+          - image: payload.hash
+      - name: extra-a
+        type: 0FC63DAF-8483-4772-8E79-3D69D8477DE4
+        size: 8M
+        device-mapping:
+          type: linear
+          name: extra
+          segments: [extra-b]
+      - name: extra-b
+        type: 0FC63DAF-8483-4772-8E79-3D69D8477DE4
+        size: 8M
+`
+
+func gadgetYamlWithDeviceMappings(structures string) []byte {
+	return []byte(strings.TrimRight(string(gadgetYamlUC20PC), "\n") + structures)
+}
+
+func (s *gadgetYamlTestSuite) TestReadGadgetYamlDeviceMappings(c *C) {
+	info, err := gadget.InfoFromGadgetYaml(gadgetYamlWithDeviceMappings(deviceMappingStructures), uc20Mod)
+	c.Assert(err, IsNil)
+
+	vol := info.Volumes["pc"]
+	var mapped []*gadget.VolumeStructure
+	for i := range vol.Structure {
+		if vol.Structure[i].DeviceMapping != nil {
+			mapped = append(mapped, &vol.Structure[i])
+		}
+	}
+	c.Assert(mapped, HasLen, 2)
+
+	c.Check(mapped[0].Name, Equals, "payload")
+	c.Check(mapped[0].DeviceMapping, DeepEquals, &gadget.DeviceMapping{
+		Type:          gadget.DeviceMappingVerity,
+		HashStructure: "payload-hash",
+		RootHash:      mockVerityRootHash,
+	})
+	c.Check(mapped[0].MappedDeviceName(), Equals, "payload")
+
+	c.Check(mapped[1].Name, Equals, "extra-a")
+	c.Check(mapped[1].DeviceMapping, DeepEquals, &gadget.DeviceMapping{
+		Type:     gadget.DeviceMappingLinear,
+		Name:     "extra",
+		Segments: []string{"extra-b"},
+	})
+	c.Check(mapped[1].MappedDeviceName(), Equals, "extra")
+
+	for _, vs := range vol.Structure {
+		if vs.Name == "ubuntu-data" {
+			c.Check(vs.DeviceMapping, IsNil)
+			c.Check(vs.MappedDeviceName(), Equals, "")
+		}
+	}
+}
+
+func (s *gadgetYamlTestSuite) TestReadGadgetYamlDeviceMappingsInvalid(c *C) {
+	for _, t := range []struct {
+		old, new string
+		err      string
+	}{
+		{"type: verity", "type: crypt", `invalid volume "pc": invalid structure #6 \("payload"\): invalid device-mapping: invalid device mapping type "crypt"`},
+		{"root-hash: " + mockVerityRootHash, "root-hash: abcd", `invalid volume "pc": invalid structure #6 \("payload"\): invalid device-mapping: invalid verity root hash "abcd"`},
+		{"root-hash: " + mockVerityRootHash, "", `invalid volume "pc": invalid structure #6 \("payload"\): invalid device-mapping: invalid verity root hash ""`},
+		{"hash-structure: payload-hash", "", `invalid volume "pc": invalid structure #6 \("payload"\): invalid device-mapping: verity device mapping requires a hash-structure`},
+		{"hash-structure: payload-hash", "hash-structure: payload", `invalid volume "pc": invalid structure #6 \("payload"\): invalid device-mapping: verity device mapping cannot use its own structure for the hash tree`},
+		{"hash-structure: payload-hash", "hash-structure: payload-hash\n          segments: [extra-b]", `invalid volume "pc": invalid structure #6 \("payload"\): invalid device-mapping: segments are only supported for linear device mappings`},
+		{"name: extra\n", "name: extra\n          root-hash: " + mockVerityRootHash + "\n", `invalid volume "pc": invalid structure #8 \("extra-a"\): invalid device-mapping: root-hash is only supported for verity device mappings`},
+		{"name: extra\n", "name: extra\n          hash-structure: extra-b\n", `invalid volume "pc": invalid structure #8 \("extra-a"\): invalid device-mapping: hash-structure is only supported for verity device mappings`},
+		{"name: extra\n", "name: extra/bad\n", `invalid volume "pc": invalid structure #8 \("extra-a"\): invalid device-mapping: invalid device mapping name "extra/bad"`},
+		{"segments: [extra-b]", "segments: [extra-a]", `invalid volume "pc": invalid structure #8 \("extra-a"\): invalid device-mapping: structure "extra-a" cannot be a segment of its own device mapping`},
+		{"        size: 8M\n        device-mapping", "        size: 8M\n        filesystem: ext4\n        device-mapping", `invalid volume "pc": invalid structure #8 \("extra-a"\): invalid device-mapping: structures with a device mapping cannot have a filesystem`},
+		{"hash-structure: payload-hash", "hash-structure: missing", `invalid volume "pc": device mapping of structure "payload" refers to missing structure "missing"`},
+		{"hash-structure: payload-hash", "hash-structure: extra-a", `invalid volume "pc": device mapping of structure "payload" refers to structure "extra-a" which has a device mapping itself`},
+		{"hash-structure: payload-hash", "hash-structure: ubuntu-data", `invalid volume "pc": structure "ubuntu-data" used by device mapping of structure "payload" must be a partition with no role or filesystem`},
+		{"segments: [extra-b]", "segments: [extra-b, extra-b]", `invalid volume "pc": structure "extra-b" is used more than once by device mappings`},
+		{"segments: [extra-b]", "segments: [payload-hash]", `invalid volume "pc": structure "payload-hash" is used more than once by device mappings`},
+		{"name: extra\n", "name: payload\n", `device mapping name "payload" is not unique`},
+	} {
+		structures := strings.Replace(deviceMappingStructures, t.old, t.new, 1)
+		c.Assert(structures, Not(Equals), deviceMappingStructures, Commentf(t.old))
+
+		_, err := gadget.InfoFromGadgetYaml(gadgetYamlWithDeviceMappings(structures), uc20Mod)
+		c.Check(err, ErrorMatches, t.err, Commentf(t.new))
+	}
+}
+
+func (s *gadgetYamlTestSuite) TestReadGadgetYamlDeviceMappingOnRoleStructure(c *C) {
+	gadgetYaml := strings.Replace(string(gadgetYamlUC20PC), `        size: 16M
+`, `        size: 16M
+        device-mapping:
+          type: linear
+`, 1)
+
+	_, err := gadget.InfoFromGadgetYaml([]byte(gadgetYaml), uc20Mod)
+	c.Check(err, ErrorMatches, `invalid volume "pc": invalid structure #4 \("ubuntu-save"\): invalid device-mapping: device mappings are not supported for structures with role "system-save"`)
+}
+
+func (s *gadgetYamlTestSuite) TestReadGadgetYamlDeviceMappingNotBootVolume(c *C) {
+	gadgetYaml := string(gadgetYamlUC20PC) + `
+  other:
+    structure:
+      - name: other-a
+        type: 0FC63DAF-8483-4772-8E79-3D69D8477DE4
+        size: 8M
+        device-mapping:
+          type: linear
+`
+
+	_, err := gadget.InfoFromGadgetYaml([]byte(gadgetYaml), uc20Mod)
+	c.Check(err, ErrorMatches, `invalid volume "other": device mappings are only supported in the volume with the system-boot role`)
+}
+
+func (s *gadgetYamlTestSuite) TestVolumeStructureCopyDeviceMapping(c *C) {
+	vs := &gadget.VolumeStructure{
+		Name: "extra-a",
+		DeviceMapping: &gadget.DeviceMapping{
+			Type:     gadget.DeviceMappingLinear,
+			Segments: []string{"extra-b"},
+		},
+	}
+	newVs := vs.Copy()
+	c.Check(newVs, DeepEquals, vs)
+
+	newVs.DeviceMapping.Segments[0] = "extra-c"
+	newVs.DeviceMapping.Type = gadget.DeviceMappingVerity
+	c.Check(vs.DeviceMapping.Segments, DeepEquals, []string{"extra-b"})
+	c.Check(vs.DeviceMapping.Type, Equals, gadget.DeviceMappingLinear)
+}
+
+func laidOutDeviceMappingVolumes(c *C, oldStructures, newStructures string) (*gadget.PartiallyLaidOutVolume, *gadget.LaidOutVolume) {
+	oldInfo, err := gadget.InfoFromGadgetYaml(gadgetYamlWithDeviceMappings(oldStructures), uc20Mod)
+	c.Assert(err, IsNil)
+	newInfo, err := gadget.InfoFromGadgetYaml(gadgetYamlWithDeviceMappings(newStructures), uc20Mod)
+	c.Assert(err, IsNil)
+
+	laidOut := func(vol *gadget.Volume) []gadget.LaidOutStructure {
+		var los []gadget.LaidOutStructure
+		for i := range vol.Structure {
+			los = append(los, gadget.LaidOutStructure{VolumeStructure: &vol.Structure[i]})
+		}
+		return los
+	}
+	oldVol := oldInfo.Volumes["pc"]
+	newVol := newInfo.Volumes["pc"]
+	return &gadget.PartiallyLaidOutVolume{Volume: oldVol, LaidOutStructure: laidOut(oldVol)},
+		&gadget.LaidOutVolume{Volume: newVol, LaidOutStructure: laidOut(newVol)}
+}
+
+func (s *gadgetYamlTestSuite) TestCheckDeviceMappingUpdates(c *C) {
+	newRootHash := strings.Repeat("ab", 32)
+	newStructures := strings.Replace(deviceMappingStructures, mockVerityRootHash, newRootHash, 1)
+
+	for _, t := range []struct {
+		newStructures string
+		updated       []string
+		err           string
+	}{
+		// nothing updated
+		{deviceMappingStructures, nil, ""},
+		// structures updated in place without changes to the mapping
+		{deviceMappingStructures, []string{"payload", "payload-hash", "extra-a"}, ""},
+		// new verity data and root hash
+		{newStructures, []string{"payload", "payload-hash"}, ""},
+		{newStructures, []string{"payload"}, `cannot update only one of structures "payload" and "payload-hash" of verity device mapping "payload"`},
+		{newStructures, []string{"payload-hash"}, `cannot update only one of structures "payload" and "payload-hash" of verity device mapping "payload"`},
+		{newStructures, nil, `cannot change the root hash of verity device mapping "payload" without updating its structures`},
+		{strings.Replace(deviceMappingStructures, "name: extra\n", "name: extra2\n", 1), nil, `cannot change the device mapping of structure "extra-a"`},
+		{strings.Replace(deviceMappingStructures, "segments: [extra-b]", "segments: []", 1), nil, `cannot change the device mapping of structure "extra-a"`},
+		{strings.Replace(deviceMappingStructures, `        device-mapping:
+          type: linear
+          name: extra
+          segments: [extra-b]
+`, "", 1), []string{"extra-a"}, `cannot change the device mapping of structure "extra-a"`},
+	} {
+		oldVol, newVol := laidOutDeviceMappingVolumes(c, deviceMappingStructures, t.newStructures)
+		err := gadget.CheckDeviceMappingUpdates(oldVol, newVol, t.updated)
+		if t.err == "" {
+			c.Check(err, IsNil, Commentf("%v", t.updated))
+		} else {
+			c.Check(err, ErrorMatches, t.err, Commentf("%v", t.updated))
+		}
+	}
+}
@@ -21,6 +21,7 @@ package gadget
 
 import (
 	"github.com/snapcore/snapd/gadget/quantity"
+	"github.com/snapcore/snapd/strutil"
 	"github.com/snapcore/snapd/testutil"
 )
 
@@ -112,3 +113,19 @@ func MockSetEMMCPartitionReadWrite(mock func(device string, rw bool) error) (res
 	setEMMCPartitionReadWrite = mock
 	return r
 }
+
+// CheckDeviceMappingUpdates checks the device mappings of an update of the
+// structures with the given names.
+func CheckDeviceMappingUpdates(oldVol *PartiallyLaidOutVolume, newVol *LaidOutVolume, updated []string) error {
+	var updates []updatePair
+	for i := range newVol.LaidOutStructure {
+		if strutil.ListContains(updated, newVol.LaidOutStructure[i].Name()) {
+			updates = append(updates, updatePair{
+				from:   &oldVol.LaidOutStructure[i],
+				to:     &newVol.LaidOutStructure[i],
+				volume: newVol.Volume,
+			})
+		}
+	}
+	return checkDeviceMappingUpdates(oldVol, newVol, updates)
+}
@@ -113,6 +113,8 @@ const (
 	BootLoaderLk      = "lk"
 	BootLoaderUboot   = "u-boot"
 	BootLoaderPiboot  = "piboot"
+
+	BootLoaderSystemdBoot = "systemd-boot"
 )
 
 var (
@@ -145,6 +147,10 @@ type Info struct {
 	Connections []Connection `yaml:"connections"`
 
 	KernelCmdline KernelCmdline `yaml:"kernel-cmdline"`
+
+	// NetworkUnlock enables unlocking the encrypted data partition with
+	// keys bound to key servers.
+	NetworkUnlock *NetworkUnlock `yaml:"network-unlock,omitempty"`
 }
 
 // HasRole returns true if any of the volume structures in this Info has the
@@ -199,8 +205,9 @@ type Volume struct {
 	Name string `json:"-"`
 	// AssignedDevice is set during runtime to assign a specific gadget
 	// volume to a device, eg. /dev/disk/by-path/pci-42:0. This is only
-	// set optionally if matched against the volume-assignments.
-	AssignedDevice string `json:"-"`
+	// set optionally if matched against the volume-assignments, or by
+	// an installer selecting the target disk of the volume.
+	AssignedDevice string `yaml:"-" json:"assigned-device,omitempty"`
 }
 
 // VolumesHaveRole checks if any of the volumes has a structure with the given
@@ -297,6 +304,14 @@ func (vs *VolumeStructure) Copy() *VolumeStructure {
 			}
 		}
 	}
+	if vs.DeviceMapping != nil {
+		dm := *vs.DeviceMapping
+		if vs.DeviceMapping.Segments != nil {
+			dm.Segments = make([]string, len(vs.DeviceMapping.Segments))
+			copy(dm.Segments, vs.DeviceMapping.Segments)
+		}
+		newVs.DeviceMapping = &dm
+	}
 	return &newVs
 }
 
@@ -368,6 +383,9 @@ type VolumeStructure struct {
 	// Content of the structure
 	Content []VolumeContent `yaml:"content" json:"content"`
 	Update  VolumeUpdate    `yaml:"update" json:"update"`
+	// DeviceMapping describes the device-mapper device that is set up
+	// at boot on top of the partition of the structure (optional).
+	DeviceMapping *DeviceMapping `yaml:"device-mapping,omitempty" json:"device-mapping,omitempty"`
 
 	// Note that the Device field will never be part of the yaml
 	// and just used as part of the POST /systems/<label> API that
@@ -1219,6 +1237,12 @@ func InfoFromGadgetYaml(gadgetYaml []byte, model Model) (*Info, error) {
 		}
 	}
 
+	if gi.NetworkUnlock != nil {
+		if err := validateNetworkUnlock(gi.NetworkUnlock); err != nil {
+			return nil, fmt.Errorf("invalid network-unlock: %v", err)
+		}
+	}
+
 	if len(gi.Volumes) == 0 && classicOrUndetermined(model) {
 		// volumes can be left out on classic
 		// can still specify defaults though
@@ -1265,8 +1289,13 @@ func InfoFromGadgetYaml(gadgetYaml []byte, model Model) (*Info, error) {
 				return nil, errors.New("piboot bootloader valid only for UC20 onwards")
 			}
 			bootloadersFound += 1
+		case BootLoaderSystemdBoot:
+			if !compatWithPibootOrIndeterminate(model) {
+				return nil, errors.New("systemd-boot bootloader valid only for UC20 onwards")
+			}
+			bootloadersFound += 1
 		default:
-			return nil, errors.New("bootloader must be one of grub, u-boot, android-boot, piboot or lk")
+			return nil, errors.New("bootloader must be one of grub, u-boot, android-boot, piboot, systemd-boot or lk")
 		}
 	}
 	switch {
@@ -1276,6 +1305,10 @@ func InfoFromGadgetYaml(gadgetYaml []byte, model Model) (*Info, error) {
 		return nil, fmt.Errorf("too many (%d) bootloaders declared", bootloadersFound)
 	}
 
+	if err := validateDeviceMappings(gi.Volumes); err != nil {
+		return nil, err
+	}
+
 	if err := validateVolumeAssignments(gi.VolumeAssignments, gi.Volumes); err != nil {
 		return nil, err
 	}
@@ -1709,6 +1742,9 @@ func validateEMMCVolumeStructure(vs *VolumeStructure) error {
 	if vs.Role != "" {
 		return fielderr("role")
 	}
+	if vs.DeviceMapping != nil {
+		return fielderr("device-mapping")
+	}
 
 	for i, c := range vs.Content {
 		if err := validateEMMCContent(&c); err != nil {
@@ -1758,6 +1794,12 @@ func validateVolumeStructure(vs *VolumeStructure, vol *Volume) error {
 		return err
 	}
 
+	if vs.DeviceMapping != nil {
+		if err := validateDeviceMapping(vs); err != nil {
+			return fmt.Errorf("invalid device-mapping: %v", err)
+		}
+	}
+
 	// TODO: validate structure size against sector-size; ubuntu-image uses
 	// a tmp file to find out the default sector size of the device the tmp
 	// file is created on
@@ -953,7 +953,25 @@ volumes:
 	c.Assert(err, IsNil)
 
 	_, err = gadget.ReadInfo(s.dir, nil)
-	c.Assert(err, ErrorMatches, "bootloader must be one of grub, u-boot, android-boot, piboot or lk")
+	c.Assert(err, ErrorMatches, "bootloader must be one of grub, u-boot, android-boot, piboot, systemd-boot or lk")
+}
+
+func (s *gadgetYamlTestSuite) TestReadGadgetYamlSystemdBoot(c *C) {
+	mockGadgetYaml := []byte(`
+volumes:
+ name:
+  bootloader: systemd-boot
+`)
+
+	err := os.WriteFile(s.gadgetYamlPath, mockGadgetYaml, 0644)
+	c.Assert(err, IsNil)
+
+	ginfo, err := gadget.ReadInfo(s.dir, &gadgettest.ModelCharacteristics{HasModes: true})
+	c.Assert(err, IsNil)
+	c.Check(ginfo.Volumes["name"].Bootloader, Equals, "systemd-boot")
+
+	_, err = gadget.ReadInfo(s.dir, &gadgettest.ModelCharacteristics{})
+	c.Assert(err, ErrorMatches, "systemd-boot bootloader valid only for UC20 onwards")
 }
 
 func (s *gadgetYamlTestSuite) TestReadGadgetYamlEmptyBootloader(c *C) {
@@ -4348,7 +4366,8 @@ func (s *gadgetYamlTestSuite) TestGadgetInfoHasSameYamlAndJsonTags(c *C) {
 		}
 	}
 
-	tagsValid(c, &gadget.Volume{}, nil)
+	// gadget.Volume.AssignedDevice is never part of Yaml either
+	tagsValid(c, &gadget.Volume{}, []string{"AssignedDevice"})
 	// gadget.VolumeStructure.Device is never part of
 	// Yaml so the test checks that the yaml tag is "-"
 	noYaml := []string{"Device"}
@@ -4356,6 +4375,7 @@ func (s *gadgetYamlTestSuite) TestGadgetInfoHasSameYamlAndJsonTags(c *C) {
 	tagsValid(c, &gadget.VolumeContent{}, nil)
 	tagsValid(c, &gadget.RelativeOffset{}, nil)
 	tagsValid(c, &gadget.VolumeUpdate{}, nil)
+	tagsValid(c, &gadget.DeviceMapping{}, nil)
 }
 
 func (s *gadgetYamlTestSuite) TestGadgetInfoVolumeInternalFieldsNoJSON(c *C) {
@@ -0,0 +1,70 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package install
+
+import (
+	"fmt"
+
+	"github.com/snapcore/snapd/gadget"
+	"github.com/snapcore/snapd/snap/integrity/dmverity"
+)
+
+var dmverityVerify = dmverity.Verify
+
+// verifyDeviceMappings checks that the partitions backing the device mappings
+// of a volume are present on disk and, for verity mappings, that their data
+// and hash tree match the root hash from the gadget. Those partitions are
+// raw structures written when the image is built, checking them during
+// install makes sure that snap-bootstrap will be able to set up the mappings
+// on boot.
+func verifyDeviceMappings(vol *gadget.Volume, gadgetStructToDisk map[int]*gadget.OnDiskStructure) error {
+	nodes := make(map[string]string, len(vol.Structure))
+	for _, vs := range vol.Structure {
+		if ds := gadgetStructToDisk[vs.YamlIndex]; ds != nil && vs.Name != "" {
+			nodes[vs.Name] = ds.Node
+		}
+	}
+
+	for _, vs := range vol.Structure {
+		dm := vs.DeviceMapping
+		if dm == nil {
+			continue
+		}
+		structNames := []string{vs.Name}
+		if dm.Type == gadget.DeviceMappingVerity {
+			structNames = append(structNames, dm.HashStructure)
+		} else {
+			structNames = append(structNames, dm.Segments...)
+		}
+		for _, name := range structNames {
+			if nodes[name] == "" {
+				return fmt.Errorf("cannot find partition of structure %q used by device mapping %q", name, vs.MappedDeviceName())
+			}
+		}
+
+		if dm.Type != gadget.DeviceMappingVerity {
+			continue
+		}
+		if err := dmverityVerify(nodes[vs.Name], nodes[dm.HashStructure], dm.RootHash); err != nil {
+			return fmt.Errorf("cannot verify device mapping %q: %v", vs.MappedDeviceName(), err)
+		}
+	}
+	return nil
+}
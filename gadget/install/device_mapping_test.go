@@ -0,0 +1,133 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package install_test
+
+import (
+	"errors"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/gadget"
+	"github.com/snapcore/snapd/gadget/install"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type deviceMappingSuite struct {
+	testutil.BaseTest
+}
+
+var _ = Suite(&deviceMappingSuite{})
+
+const mockRootHash = "cf9a379613c0dc10301fe3eba4665c38b849b7aad311471faa4d2392ee4ede49"
+
+func mockDeviceMappingVolume() (*gadget.Volume, map[int]*gadget.OnDiskStructure) {
+	vol := &gadget.Volume{
+		Name:   "pc",
+		Schema: "gpt",
+		Structure: []gadget.VolumeStructure{{
+			Name:      "ubuntu-boot",
+			Role:      gadget.SystemBoot,
+			YamlIndex: 0,
+		}, {
+			Name:      "payload",
+			YamlIndex: 1,
+			DeviceMapping: &gadget.DeviceMapping{
+				Type:          gadget.DeviceMappingVerity,
+				HashStructure: "payload-hash",
+				RootHash:      mockRootHash,
+			},
+		}, {
+			Name:      "payload-hash",
+			YamlIndex: 2,
+		}, {
+			Name:      "extra-a",
+			YamlIndex: 3,
+			DeviceMapping: &gadget.DeviceMapping{
+				Type:     gadget.DeviceMappingLinear,
+				Name:     "extra",
+				Segments: []string{"extra-b"},
+			},
+		}, {
+			Name:      "extra-b",
+			YamlIndex: 4,
+		}},
+	}
+	onDisk := map[int]*gadget.OnDiskStructure{
+		0: {Name: "ubuntu-boot", Node: "/dev/vda1"},
+		1: {Name: "payload", Node: "/dev/vda2"},
+		2: {Name: "payload-hash", Node: "/dev/vda3"},
+		3: {Name: "extra-a", Node: "/dev/vda4"},
+		4: {Name: "extra-b", Node: "/dev/vda5"},
+	}
+	return vol, onDisk
+}
+
+func (s *deviceMappingSuite) TestVerifyDeviceMappings(c *C) {
+	var calls [][]string
+	s.AddCleanup(install.MockDmverityVerify(func(dataDevice, hashDevice, rootHash string) error {
+		calls = append(calls, []string{dataDevice, hashDevice, rootHash})
+		return nil
+	}))
+
+	vol, onDisk := mockDeviceMappingVolume()
+	err := install.VerifyDeviceMappings(vol, onDisk)
+	c.Assert(err, IsNil)
+	c.Check(calls, DeepEquals, [][]string{{"/dev/vda2", "/dev/vda3", mockRootHash}})
+}
+
+func (s *deviceMappingSuite) TestVerifyDeviceMappingsNone(c *C) {
+	s.AddCleanup(install.MockDmverityVerify(func(dataDevice, hashDevice, rootHash string) error {
+		c.Fatalf("unexpected call")
+		return nil
+	}))
+
+	vol := &gadget.Volume{
+		Name:      "pc",
+		Structure: []gadget.VolumeStructure{{Name: "ubuntu-boot", Role: gadget.SystemBoot}},
+	}
+	err := install.VerifyDeviceMappings(vol, map[int]*gadget.OnDiskStructure{0: {Node: "/dev/vda1"}})
+	c.Assert(err, IsNil)
+}
+
+func (s *deviceMappingSuite) TestVerifyDeviceMappingsVerifyError(c *C) {
+	s.AddCleanup(install.MockDmverityVerify(func(dataDevice, hashDevice, rootHash string) error {
+		return errors.New("Verification of root hash failed.")
+	}))
+
+	vol, onDisk := mockDeviceMappingVolume()
+	err := install.VerifyDeviceMappings(vol, onDisk)
+	c.Assert(err, ErrorMatches, `cannot verify device mapping "payload": Verification of root hash failed.`)
+}
+
+func (s *deviceMappingSuite) TestVerifyDeviceMappingsMissingPartition(c *C) {
+	s.AddCleanup(install.MockDmverityVerify(func(dataDevice, hashDevice, rootHash string) error {
+		return nil
+	}))
+
+	vol, onDisk := mockDeviceMappingVolume()
+	delete(onDisk, 4)
+	err := install.VerifyDeviceMappings(vol, onDisk)
+	c.Assert(err, ErrorMatches, `cannot find partition of structure "extra-b" used by device mapping "extra"`)
+
+	vol, onDisk = mockDeviceMappingVolume()
+	delete(onDisk, 2)
+	err = install.VerifyDeviceMappings(vol, onDisk)
+	c.Assert(err, ErrorMatches, `cannot find partition of structure "payload-hash" used by device mapping "payload"`)
+}
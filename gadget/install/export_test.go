@@ -42,8 +42,18 @@ var (
 
 	IndexIfCreatedDuringInstall = indexIfCreatedDuringInstall
 	TestCreateMissingPartitions = createMissingPartitions
+
+	VerifyDeviceMappings = verifyDeviceMappings
 )
 
+func MockDmverityVerify(f func(dataDevice, hashDevice, rootHash string) error) (restore func()) {
+	old := dmverityVerify
+	dmverityVerify = f
+	return func() {
+		dmverityVerify = old
+	}
+}
+
 func MockSysMount(f func(source, target, fstype string, flags uintptr, data string) error) (restore func()) {
 	old := sysMount
 	sysMount = f
@@ -293,10 +293,17 @@ func createPartitions(volumes map[string]*gadget.Volume, gadgetRoot, bootDevice
 
 	// check if the current partition table is compatible with the gadget,
 	// ignoring partitions added by the installer (will be removed later)
-	if _, err := gadget.EnsureVolumeCompatibility(bootVol, diskVolume, nil); err != nil {
+	gadgetStructToDisk, err := gadget.EnsureVolumeCompatibility(bootVol, diskVolume, nil)
+	if err != nil {
 		return "", nil, 0, fmt.Errorf("gadget and system-boot device %v partition table not compatible: %v", bootDevice, err)
 	}
 
+	// the partitions behind device mappings come with the image, check
+	// them before going any further
+	if err := verifyDeviceMappings(bootVol, gadgetStructToDisk); err != nil {
+		return "", nil, 0, err
+	}
+
 	// remove partitions added during a previous install attempt
 	// TODO we probably do not need to do this, as we are re-creating the
 	// partitions with the same sizes as the ones removed. We even check
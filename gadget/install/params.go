@@ -78,6 +78,9 @@ type EncryptionSetupData struct {
 	//
 	// TODO: use typed fragment IDs instead of string keys.
 	extraSnapdKernelCommandLineFragments map[string]string
+	// sealingMethod is the method that will be used to seal the
+	// keys of the encrypted volumes
+	sealingMethod device.SealingMethod
 }
 
 // EncryptedDevices returns a map partition role -> LUKS mapper device.
@@ -112,6 +115,16 @@ func (esd *EncryptionSetupData) PreinstallCheckContext() *secboot.PreinstallChec
 	return esd.preinstallCheckContext
 }
 
+func (esd *EncryptionSetupData) SetSealingMethod(method device.SealingMethod) {
+	esd.sealingMethod = method
+}
+
+// SealingMethod returns the method that will be used to seal the keys of
+// the encrypted volumes.
+func (esd *EncryptionSetupData) SealingMethod() device.SealingMethod {
+	return esd.sealingMethod
+}
+
 // MockEncryptedDeviceAndRole is meant to be used for unit tests from other
 // packages.
 type MockEncryptedDeviceAndRole struct {
@@ -56,6 +56,17 @@ func reloadPartitionTable(gadgetRoot string, device string) error {
 	}
 }
 
+const (
+	// maxMBRPrimaryPartitions is the number of partitions that fit in the
+	// partition table of a dos disk. Logical partitions are not created as
+	// their extended boot records would need space between the gadget
+	// structures.
+	maxMBRPrimaryPartitions = 4
+	// maxMBRSectors is the number of sectors addressable by the 32-bit
+	// LBAs of the partition entries of a dos disk.
+	maxMBRSectors = uint64(1) << 32
+)
+
 type CreateOptions struct {
 	// The gadget root dir
 	GadgetRootDir string
@@ -77,6 +88,41 @@ func CreateMissingPartitions(dv *gadget.OnDiskVolume, gv *gadget.Volume, opts *C
 	return dgpairs, nil
 }
 
+// CreateMissingPartitionsForVolumes creates, for each of the gadget volumes
+// in gvs, the missing partitions on the disk found for the same volume name in
+// dvs. Each volume must be mapped to a different disk. It returns the created
+// structures for each volume. As CreateMissingPartitions, it is meant to be
+// used externally by installers supporting gadgets with multiple volumes.
+func CreateMissingPartitionsForVolumes(dvs map[string]*gadget.OnDiskVolume, gvs map[string]*gadget.Volume, opts *CreateOptions) (map[string][]*gadget.OnDiskAndGadgetStructurePair, error) {
+	volNames := make([]string, 0, len(gvs))
+	for volName := range gvs {
+		volNames = append(volNames, volName)
+	}
+	sort.Strings(volNames)
+
+	volForDevice := make(map[string]string, len(gvs))
+	for _, volName := range volNames {
+		dv := dvs[volName]
+		if dv == nil {
+			return nil, fmt.Errorf("cannot create partitions for volume %q: no disk provided", volName)
+		}
+		if other, ok := volForDevice[dv.Device]; ok {
+			return nil, fmt.Errorf("cannot create partitions for volumes %q and %q on the same disk %s", other, volName, dv.Device)
+		}
+		volForDevice[dv.Device] = volName
+	}
+
+	createdPerVol := make(map[string][]*gadget.OnDiskAndGadgetStructurePair, len(gvs))
+	for _, volName := range volNames {
+		created, err := createMissingPartitions(dvs[volName], gvs[volName], opts, nil)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create partitions for volume %q: %v", volName, err)
+		}
+		createdPerVol[volName] = created
+	}
+	return createdPerVol, nil
+}
+
 // createMissingPartitions creates the partitions listed in the gadget volume
 // gv that are missing from the disk dv taking into account options opts. The
 // map of gadget indexes to deleted partitions is needed because if they were
@@ -248,13 +294,24 @@ func buildPartitionList(dl *gadget.OnDiskVolume, vol *gadget.Volume, opts *Creat
 			return nil, nil, fmt.Errorf("cannot create partition #%d (%q)", vs.YamlIndex, vs.Name)
 		}
 
+		usableSectorsEnd := dl.UsableSectorsEnd
+		if dl.Schema == "dos" {
+			if err := checkMBRPartition(pIndex, offset, size, sectorSize); err != nil {
+				return nil, nil, fmt.Errorf("cannot create partition #%d (%q): %v", vs.YamlIndex, vs.Name, err)
+			}
+			// do not expand past what the partition table can address
+			if usableSectorsEnd > maxMBRSectors {
+				usableSectorsEnd = maxMBRSectors
+			}
+		}
+
 		// Check if the data partition should be expanded
 		startInSectors := uint64(offset) / sectorSize
 		newSizeInSectors := uint64(size) / sectorSize
-		if vs.Role == gadget.SystemData && canExpandData && startInSectors+newSizeInSectors < dl.UsableSectorsEnd {
+		if vs.Role == gadget.SystemData && canExpandData && startInSectors+newSizeInSectors < usableSectorsEnd {
 			// note that if startInSectors + newSizeInSectors == dl.UsableSectorEnd
 			// then we won't hit this branch, but it would be redundant anyways
-			newSizeInSectors = dl.UsableSectorsEnd - startInSectors
+			newSizeInSectors = usableSectorsEnd - startInSectors
 		}
 
 		ptype := partitionType(dl.Schema, vs.Type)
@@ -262,9 +319,14 @@ func buildPartitionList(dl *gadget.OnDiskVolume, vol *gadget.Volume, opts *Creat
 		// synthesize the node name and on disk structure
 		node := deviceName(dl.Device, pIndex)
 
-		// format sfdisk input for creating this partition
-		fmt.Fprintf(buf, "%s : start=%12d, size=%12d, type=%s, name=%q\n", node,
-			startInSectors, newSizeInSectors, ptype, vs.Name)
+		// format sfdisk input for creating this partition, dos partitions
+		// have no name
+		fmt.Fprintf(buf, "%s : start=%12d, size=%12d, type=%s", node,
+			startInSectors, newSizeInSectors, ptype)
+		if dl.Schema != "dos" {
+			fmt.Fprintf(buf, ", name=%q", vs.Name)
+		}
+		fmt.Fprintln(buf)
 
 		diskSt := &gadget.OnDiskStructure{
 			Name:             vs.Name,
@@ -286,6 +348,24 @@ func buildPartitionList(dl *gadget.OnDiskVolume, vol *gadget.Volume, opts *Creat
 	return buf, toBeCreated, nil
 }
 
+// checkMBRPartition checks that a partition with the given disk index, offset
+// and size can be created as a primary partition of a dos partition table.
+func checkMBRPartition(diskIndex int, offset quantity.Offset, size quantity.Size, sectorSize uint64) error {
+	if diskIndex > maxMBRPrimaryPartitions {
+		return fmt.Errorf("dos partition tables support at most %d primary partitions", maxMBRPrimaryPartitions)
+	}
+	if uint64(offset)%sectorSize != 0 {
+		return fmt.Errorf("offset %d is not aligned to the sector size %d", offset, sectorSize)
+	}
+	if uint64(size)%sectorSize != 0 {
+		return fmt.Errorf("size %d is not aligned to the sector size %d", size, sectorSize)
+	}
+	if uint64(offset)/sectorSize+uint64(size)/sectorSize > maxMBRSectors {
+		return fmt.Errorf("dos partition tables cannot address beyond sector %d", maxMBRSectors)
+	}
+	return nil
+}
+
 func partitionType(label, ptype string) string {
 	t := strings.Split(ptype, ",")
 	if len(t) < 1 {
@@ -334,7 +414,7 @@ func removeCreatedPartitions(gadgetRoot string, gv *gadget.Volume, dl *gadget.On
 		if gadgetIndexes.YamlIdx >= 0 {
 			startFromIdx = gadgetIndexes.OrderIdx + 1
 			logger.Noticef("partition %s was created during previous install", s.Node)
-			sfdiskIndexes = append(sfdiskIndexes, strconv.Itoa(i+1))
+			sfdiskIndexes = append(sfdiskIndexes, strconv.Itoa(s.DiskIndex))
 			deletedOffsetSize[gadgetIndexes.YamlIdx] = StructOffsetSize{
 				StartOffset: s.StartOffset,
 				Size:        s.Size,
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -336,6 +337,114 @@ func (s *partitionTestSuite) TestBuildPartitionListExistingPartsInSizeRange(c *C
 	})
 }
 
+func makeMockDosDiskMapping(sizeInSectors uint64) *disks.MockDiskMapping {
+	return &disks.MockDiskMapping{
+		DevNum:              "42:0",
+		DiskSizeInBytes:     sizeInSectors * 512,
+		DiskUsableSectorEnd: sizeInSectors,
+		DiskSchema:          "dos",
+		ID:                  "0x1234567",
+		SectorSizeBytes:     512,
+		Structure:           []disks.Partition{},
+		DevNode:             "/dev/node",
+	}
+}
+
+func (s *partitionTestSuite) TestBuildPartitionListDos(c *C) {
+	m := map[string]*disks.MockDiskMapping{
+		// 8GiB disk
+		"/dev/node": makeMockDosDiskMapping(16777216),
+	}
+
+	restore := disks.MockDeviceNameToDiskMapping(m)
+	defer restore()
+
+	err := gadgettest.MakeMockGadget(s.gadgetRoot, gadgettest.RaspiSimplifiedYaml)
+	c.Assert(err, IsNil)
+	pv, err := gadgettest.MustLayOutSingleVolumeFromGadget(s.gadgetRoot, "", uc20Mod)
+	c.Assert(err, IsNil)
+
+	dl, err := gadget.OnDiskVolumeFromDevice("/dev/node")
+	c.Assert(err, IsNil)
+
+	// dos partitions have no name and the data partition is expanded to the
+	// end of the disk
+	sfdiskInput, create, err := install.BuildPartitionList(dl, pv.Volume,
+		&install.CreateOptions{CreateAllMissingPartitions: true}, nil)
+	c.Assert(err, IsNil)
+	c.Assert(sfdiskInput.String(), Equals,
+		`/dev/node1 : start=        2048, size=     2457600, type=0C
+/dev/node2 : start=     2459648, size=     1536000, type=0C
+/dev/node3 : start=     3995648, size=       32768, type=83
+/dev/node4 : start=     4028416, size=    12748800, type=83
+`)
+	c.Assert(create, HasLen, 4)
+	for i, pair := range create {
+		c.Check(pair.DiskStructure.DiskIndex, Equals, i+1)
+		c.Check(pair.GadgetStructure, DeepEquals, &pv.Volume.Structure[i])
+	}
+	c.Check(create[3].DiskStructure.Size, Equals, quantity.Size(12748800*512))
+}
+
+func (s *partitionTestSuite) TestBuildPartitionListDosExpandUpTo2TiB(c *C) {
+	m := map[string]*disks.MockDiskMapping{
+		// 4TiB disk
+		"/dev/node": makeMockDosDiskMapping(8589934592),
+	}
+
+	restore := disks.MockDeviceNameToDiskMapping(m)
+	defer restore()
+
+	err := gadgettest.MakeMockGadget(s.gadgetRoot, gadgettest.RaspiSimplifiedYaml)
+	c.Assert(err, IsNil)
+	pv, err := gadgettest.MustLayOutSingleVolumeFromGadget(s.gadgetRoot, "", uc20Mod)
+	c.Assert(err, IsNil)
+
+	dl, err := gadget.OnDiskVolumeFromDevice("/dev/node")
+	c.Assert(err, IsNil)
+
+	// the data partition ends at the last sector addressable by the
+	// partition table
+	sfdiskInput, _, err := install.BuildPartitionList(dl, pv.Volume,
+		&install.CreateOptions{CreateAllMissingPartitions: true}, nil)
+	c.Assert(err, IsNil)
+	c.Assert(sfdiskInput.String(), Equals,
+		`/dev/node1 : start=        2048, size=     2457600, type=0C
+/dev/node2 : start=     2459648, size=     1536000, type=0C
+/dev/node3 : start=     3995648, size=       32768, type=83
+/dev/node4 : start=     4028416, size=  4290938880, type=83
+`)
+}
+
+func (s *partitionTestSuite) TestBuildPartitionListDosTooManyPartitions(c *C) {
+	m := map[string]*disks.MockDiskMapping{
+		"/dev/node": makeMockDosDiskMapping(16777216),
+	}
+
+	restore := disks.MockDeviceNameToDiskMapping(m)
+	defer restore()
+
+	gadgetYaml := strings.Replace(gadgettest.RaspiSimplifiedYaml, `
+    - filesystem: ext4
+      name: ubuntu-data`, `
+    - name: other
+      size: 1M
+      type: 83
+    - filesystem: ext4
+      name: ubuntu-data`, 1)
+	err := gadgettest.MakeMockGadget(s.gadgetRoot, gadgetYaml)
+	c.Assert(err, IsNil)
+	pv, err := gadgettest.MustLayOutSingleVolumeFromGadget(s.gadgetRoot, "", uc20Mod)
+	c.Assert(err, IsNil)
+
+	dl, err := gadget.OnDiskVolumeFromDevice("/dev/node")
+	c.Assert(err, IsNil)
+
+	_, _, err = install.BuildPartitionList(dl, pv.Volume,
+		&install.CreateOptions{CreateAllMissingPartitions: true}, nil)
+	c.Assert(err, ErrorMatches, `cannot create partition #4 \("ubuntu-data"\): dos partition tables support at most 4 primary partitions`)
+}
+
 func (s *partitionTestSuite) TestBuildPartitionListEMMCIsEmptyButNoError(c *C) {
 	sfdiskInput, create, err := install.BuildPartitionList(&gadget.OnDiskVolume{
 		SectorSize: 512,
@@ -406,6 +515,80 @@ func (s *partitionTestSuite) TestCreatePartitions(c *C) {
 	})
 }
 
+func (s *partitionTestSuite) TestCreatePartitionsForVolumes(c *C) {
+	cmdSfdisk := testutil.MockCommand(c, "sfdisk", "")
+	defer cmdSfdisk.Restore()
+
+	m := map[string]*disks.MockDiskMapping{
+		"/dev/node": makeMockDiskMappingIncludingPartitions(scriptPartitionsBiosSeed),
+	}
+	restore := disks.MockDeviceNameToDiskMapping(m)
+	defer restore()
+
+	cmdUdevadm := testutil.MockCommand(c, "udevadm", "")
+	defer cmdUdevadm.Restore()
+
+	restore = install.MockEnsureNodesExist(func(nodes []string, timeout time.Duration) error {
+		c.Assert(nodes, DeepEquals, []string{"/dev/node3"})
+		return nil
+	})
+	defer restore()
+
+	err := gadgettest.MakeMockGadget(s.gadgetRoot, gadgetContent)
+	c.Assert(err, IsNil)
+	pv, err := gadgettest.MustLayOutSingleVolumeFromGadget(s.gadgetRoot, "", uc20Mod)
+	c.Assert(err, IsNil)
+
+	dl, err := gadget.OnDiskVolumeFromDevice("/dev/node")
+	c.Assert(err, IsNil)
+	// a second volume on another disk, with nothing to create
+	emmcDl := &gadget.OnDiskVolume{Device: "/dev/mmcblk0", SectorSize: 512}
+	emmcVol := &gadget.Volume{Name: "emmc", Schema: "emmc"}
+
+	opts := &install.CreateOptions{
+		GadgetRootDir: s.gadgetRoot,
+	}
+	created, err := install.CreateMissingPartitionsForVolumes(
+		map[string]*gadget.OnDiskVolume{"pc": dl, "emmc": emmcDl},
+		map[string]*gadget.Volume{"pc": pv.Volume, "emmc": emmcVol}, opts)
+	c.Assert(err, IsNil)
+	c.Assert(created, DeepEquals, map[string][]*gadget.OnDiskAndGadgetStructurePair{
+		"pc": {
+			{
+				DiskStructure:   &mockOnDiskStructureWritable,
+				GadgetStructure: &pv.Volume.Structure[3],
+			},
+		},
+		"emmc": nil,
+	})
+	c.Assert(cmdSfdisk.Calls(), DeepEquals, [][]string{
+		{"sfdisk", "--append", "--no-reread", "/dev/node"},
+	})
+}
+
+func (s *partitionTestSuite) TestCreatePartitionsForVolumesErrors(c *C) {
+	cmdSfdisk := testutil.MockCommand(c, "sfdisk", "")
+	defer cmdSfdisk.Restore()
+
+	vols := map[string]*gadget.Volume{
+		"pc":   {Name: "pc", Schema: "emmc"},
+		"emmc": {Name: "emmc", Schema: "emmc"},
+	}
+
+	_, err := install.CreateMissingPartitionsForVolumes(map[string]*gadget.OnDiskVolume{
+		"pc": {Device: "/dev/node"},
+	}, vols, nil)
+	c.Check(err, ErrorMatches, `cannot create partitions for volume "emmc": no disk provided`)
+
+	_, err = install.CreateMissingPartitionsForVolumes(map[string]*gadget.OnDiskVolume{
+		"pc":   {Device: "/dev/node"},
+		"emmc": {Device: "/dev/node"},
+	}, vols, nil)
+	c.Check(err, ErrorMatches, `cannot create partitions for volumes "emmc" and "pc" on the same disk /dev/node`)
+
+	c.Check(cmdSfdisk.Calls(), HasLen, 0)
+}
+
 func (s *partitionTestSuite) TestCreatePartitionsNonRolePartitions(c *C) {
 	cmdSfdisk := testutil.MockCommand(c, "sfdisk", "")
 	defer cmdSfdisk.Restore()
@@ -0,0 +1,84 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package gadget
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// NetworkUnlock describes the key servers that network-bound disk
+// encryption uses to unlock the encrypted data partition during boot.
+type NetworkUnlock struct {
+	// Servers are the key servers, a key is bound to each of them.
+	Servers []NetworkUnlockServer `yaml:"servers"`
+	// Timeout is how long to wait for the answer of a server, as a
+	// duration like "10s".
+	Timeout string `yaml:"timeout,omitempty"`
+	// Attempts is how many times a server is asked for its key before
+	// falling back to other unlock methods.
+	Attempts int `yaml:"attempts,omitempty"`
+}
+
+// NetworkUnlockServer is a Tang style key server.
+type NetworkUnlockServer struct {
+	// URL is the base URL of the server.
+	URL string `yaml:"url"`
+	// Thumbprint is the SHA-256 JWK thumbprint of the exchange key of
+	// the server, or of a key signing its advertisement.
+	Thumbprint string `yaml:"thumbprint"`
+}
+
+// TimeoutDuration returns the timeout of the answers of servers, or zero
+// when the default timeout should be used.
+func (n *NetworkUnlock) TimeoutDuration() time.Duration {
+	// checked when reading gadget.yaml
+	d, _ := time.ParseDuration(n.Timeout)
+	return d
+}
+
+func validateNetworkUnlock(n *NetworkUnlock) error {
+	if len(n.Servers) == 0 {
+		return errors.New("no servers")
+	}
+	for _, server := range n.Servers {
+		u, err := url.Parse(server.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid server URL %q", server.URL)
+		}
+		thumbprint, err := base64.RawURLEncoding.DecodeString(server.Thumbprint)
+		if err != nil || len(thumbprint) != 32 {
+			return fmt.Errorf("invalid thumbprint %q of server %q", server.Thumbprint, server.URL)
+		}
+	}
+	if n.Timeout != "" {
+		d, err := time.ParseDuration(n.Timeout)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid timeout %q", n.Timeout)
+		}
+	}
+	if n.Attempts < 0 {
+		return fmt.Errorf("invalid number of attempts %d", n.Attempts)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package gadget_test
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/gadget"
+)
+
+func (s *gadgetYamlTestSuite) TestReadGadgetYamlNetworkUnlock(c *C) {
+	gadgetYaml := string(gadgetYamlPC) + `
+network-unlock:
+  servers:
+    - url: http://tang.example.com
+      thumbprint: oKIywvGUpTVTyxMQ3bwIIeQUudfr_CkLMjCE19ECD-U
+    - url: https://tang2.example.com:8443/
+      thumbprint: _1LOZJjnrTj4Bw3KMRKJEnwsrUfVxxXZ6r5J-2HTY2I
+  timeout: 30s
+  attempts: 5
+`
+	c.Assert(os.WriteFile(s.gadgetYamlPath, []byte(gadgetYaml), 0644), IsNil)
+
+	info, err := gadget.ReadInfo(s.dir, uc20Mod)
+	c.Assert(err, IsNil)
+	c.Check(info.NetworkUnlock, DeepEquals, &gadget.NetworkUnlock{
+		Servers: []gadget.NetworkUnlockServer{{
+			URL:        "http://tang.example.com",
+			Thumbprint: "oKIywvGUpTVTyxMQ3bwIIeQUudfr_CkLMjCE19ECD-U",
+		}, {
+			URL:        "https://tang2.example.com:8443/",
+			Thumbprint: "_1LOZJjnrTj4Bw3KMRKJEnwsrUfVxxXZ6r5J-2HTY2I",
+		}},
+		Timeout:  "30s",
+		Attempts: 5,
+	})
+	c.Check(info.NetworkUnlock.TimeoutDuration(), Equals, 30*time.Second)
+}
+
+func (s *gadgetYamlTestSuite) TestReadGadgetYamlNoNetworkUnlock(c *C) {
+	c.Assert(os.WriteFile(s.gadgetYamlPath, gadgetYamlPC, 0644), IsNil)
+
+	info, err := gadget.ReadInfo(s.dir, uc20Mod)
+	c.Assert(err, IsNil)
+	c.Check(info.NetworkUnlock, IsNil)
+}
+
+func (s *gadgetYamlTestSuite) TestReadGadgetYamlNetworkUnlockInvalid(c *C) {
+	for _, t := range []struct {
+		networkUnlock string
+		err           string
+	}{
+		{`{}`, `invalid network-unlock: no servers`},
+		{`
+  servers:
+    - url: tang.example.com
+      thumbprint: oKIywvGUpTVTyxMQ3bwIIeQUudfr_CkLMjCE19ECD-U`, `invalid network-unlock: invalid server URL "tang.example.com"`},
+		{`
+  servers:
+    - url: ftp://tang.example.com
+      thumbprint: oKIywvGUpTVTyxMQ3bwIIeQUudfr_CkLMjCE19ECD-U`, `invalid network-unlock: invalid server URL "ftp://tang.example.com"`},
+		{`
+  servers:
+    - url: http://tang.example.com`, `invalid network-unlock: invalid thumbprint "" of server "http://tang.example.com"`},
+		{`
+  servers:
+    - url: http://tang.example.com
+      thumbprint: oKIywvGUpTVTyxMQ3bwIIeQUudfr`, `invalid network-unlock: invalid thumbprint "oKIywvGUpTVTyxMQ3bwIIeQUudfr" of server "http://tang.example.com"`},
+		{`
+  servers:
+    - url: http://tang.example.com
+      thumbprint: oKIywvGUpTVTyxMQ3bwIIeQUudfr_CkLMjCE19ECD-U
+  timeout: 10`, `invalid network-unlock: invalid timeout "10"`},
+		{`
+  servers:
+    - url: http://tang.example.com
+      thumbprint: oKIywvGUpTVTyxMQ3bwIIeQUudfr_CkLMjCE19ECD-U
+  timeout: -1s`, `invalid network-unlock: invalid timeout "-1s"`},
+		{`
+  servers:
+    - url: http://tang.example.com
+      thumbprint: oKIywvGUpTVTyxMQ3bwIIeQUudfr_CkLMjCE19ECD-U
+  attempts: -1`, `invalid network-unlock: invalid number of attempts -1`},
+	} {
+		gadgetYaml := string(gadgetYamlPC) + "\nnetwork-unlock: " + strings.TrimPrefix(t.networkUnlock, " ") + "\n"
+		c.Assert(os.WriteFile(s.gadgetYamlPath, []byte(gadgetYaml), 0644), IsNil)
+
+		_, err := gadget.ReadInfo(s.dir, uc20Mod)
+		c.Check(err, ErrorMatches, t.err, Commentf(t.networkUnlock))
+	}
+}
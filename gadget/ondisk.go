@@ -23,6 +23,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/snapcore/snapd/gadget/quantity"
 	"github.com/snapcore/snapd/logger"
@@ -117,28 +119,34 @@ func OnDiskVolumeFromDisk(disk disks.Disk) (*OnDiskVolume, error) {
 		return nil, err
 	}
 
-	ds := make([]OnDiskStructure, len(parts))
+	ds := make([]OnDiskStructure, 0, len(parts))
 
 	for _, p := range parts {
+		if disk.Schema() == "dos" && isMBRExtendedPartition(p.PartitionType) {
+			// the extended partition is only a container for logical
+			// partitions, which are listed on their own
+			continue
+		}
 		s, err := OnDiskStructureFromPartition(p)
 		if err != nil {
 			return nil, err
 		}
-
-		// Use the index of the structure on the disk rather than the order in
-		// which we iterate over the list of partitions, since the order of the
-		// partitions is returned "last seen first" which matches the behavior
-		// of udev when picking partitions with the same filesystem label and
-		// populating /dev/disk/by-label/ and friends.
-		// All that is to say the order that the list of partitions from
-		// Partitions() is in is _not_ the same as the order that the structures
-		// actually appear in on disk, but this is why the DiskIndex
-		// property exists. Also note that DiskIndex starts at 1, as
-		// opposed to gadget.LaidOutVolume.Structure's Index which starts at 0.
-		i := p.DiskIndex - 1
-		ds[i] = s
+		ds = append(ds, s)
 	}
 
+	// Use the index of the structure on the disk rather than the order in
+	// which we iterate over the list of partitions, since the order of the
+	// partitions is returned "last seen first" which matches the behavior
+	// of udev when picking partitions with the same filesystem label and
+	// populating /dev/disk/by-label/ and friends.
+	// All that is to say the order that the list of partitions from
+	// Partitions() is in is _not_ the same as the order that the structures
+	// actually appear in on disk, but this is why the DiskIndex
+	// property exists. Also note that DiskIndex starts at 1, as opposed to
+	// gadget.LaidOutVolume.Structure's Index which starts at 0, and that on
+	// dos disks it need not be contiguous, as logical partitions start at 5.
+	sort.Slice(ds, func(i, j int) bool { return ds[i].DiskIndex < ds[j].DiskIndex })
+
 	diskSz, err := disk.SizeInBytes()
 	if err != nil {
 		return nil, err
@@ -167,6 +175,16 @@ func OnDiskVolumeFromDisk(disk disks.Disk) (*OnDiskVolume, error) {
 	return dl, nil
 }
 
+// isMBRExtendedPartition returns whether the given dos partition type is one
+// of an extended partition.
+func isMBRExtendedPartition(ptype string) bool {
+	switch strings.ToUpper(ptype) {
+	case "05", "0F", "85":
+		return true
+	}
+	return false
+}
+
 func OnDiskStructureFromPartition(p disks.Partition) (OnDiskStructure, error) {
 	// the PartitionLabel and FilesystemLabel are encoded, so they must be
 	// decoded before they can be used in other gadget functions
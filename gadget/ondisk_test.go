@@ -391,6 +391,83 @@ func (s *ondiskTestSuite) TestDeviceInfoMBR(c *C) {
 	})
 }
 
+func (s *ondiskTestSuite) TestDeviceInfoMBRLogicalPartitions(c *C) {
+	m := map[string]*disks.MockDiskMapping{
+		"/dev/node": {
+			DevNum:          "42:0",
+			DevNode:         "/dev/node",
+			DiskSizeInBytes: 12345670 * 512,
+			DiskSchema:      "dos",
+			ID:              "0x1234567",
+			SectorSizeBytes: 512,
+			// partitions are returned "last seen first"
+			Structure: []disks.Partition{
+				{
+					KernelDeviceNode: "/dev/node5",
+					StartInBytes:     (4096 + 2457600 + 2048) * 512,
+					SizeInBytes:      1048576 * 512,
+					PartitionType:    "83",
+					Major:            42,
+					Minor:            5,
+					DiskIndex:        5,
+					FilesystemType:   "ext4",
+					FilesystemUUID:   "8123-433a",
+					FilesystemLabel:  "ubuntu-data",
+				},
+				{
+					KernelDeviceNode: "/dev/node2",
+					StartInBytes:     (4096 + 2457600) * 512,
+					SizeInBytes:      (1048576 + 2048) * 512,
+					PartitionType:    "0F",
+					Major:            42,
+					Minor:            2,
+					DiskIndex:        2,
+				},
+				{
+					KernelDeviceNode: "/dev/node1",
+					StartInBytes:     4096 * 512,
+					SizeInBytes:      2457600 * 512,
+					PartitionType:    "0C",
+					Major:            42,
+					Minor:            1,
+					DiskIndex:        1,
+					FilesystemType:   "vfat",
+					FilesystemUUID:   "FF44-B807",
+					FilesystemLabel:  "ubuntu-seed",
+				},
+			},
+		},
+	}
+
+	restore := disks.MockDeviceNameToDiskMapping(m)
+	defer restore()
+
+	dl, err := gadget.OnDiskVolumeFromDevice("/dev/node")
+	c.Assert(err, IsNil)
+
+	// the extended partition is skipped
+	c.Assert(dl.Structure, DeepEquals, []gadget.OnDiskStructure{
+		{
+			DiskIndex:        1,
+			Size:             2457600 * 512,
+			Node:             "/dev/node1",
+			PartitionFSLabel: "ubuntu-seed",
+			Type:             "0C",
+			PartitionFSType:  "vfat",
+			StartOffset:      4096 * 512,
+		},
+		{
+			DiskIndex:        5,
+			Size:             1048576 * 512,
+			Node:             "/dev/node5",
+			PartitionFSLabel: "ubuntu-data",
+			Type:             "83",
+			PartitionFSType:  "ext4",
+			StartOffset:      (4096 + 2457600 + 2048) * 512,
+		},
+	})
+}
+
 func (s *ondiskTestSuite) TestOnDiskStructureFromPartition(c *C) {
 
 	p := disks.Partition{
@@ -19,7 +19,10 @@
 
 package gadget
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+)
 
 // ApplyInstallerVolumesToGadget takes the volume information returned
 // by the installer and applies it to the gadget volumes for the
@@ -37,7 +40,14 @@ func ApplyInstallerVolumesToGadget(installerVols map[string]*Volume, gadgetVols
 			return nil, fmt.Errorf("installer did not provide information for volume %q", volName)
 		}
 
-		// First, retrieve device specified by installer
+		// First, retrieve devices specified by installer
+		if insVol.AssignedDevice != "" {
+			if newV.AssignedDevice != "" && newV.AssignedDevice != insVol.AssignedDevice {
+				return nil, fmt.Errorf("installer assigned device %q to volume %q, which is assigned to %q by the gadget",
+					insVol.AssignedDevice, volName, newV.AssignedDevice)
+			}
+			newV.AssignedDevice = insVol.AssignedDevice
+		}
 		for i := range newV.Structure {
 			insStr, err := structureByName(insVol.Structure, newV.Structure[i].Name)
 			if err != nil {
@@ -85,9 +95,36 @@ func ApplyInstallerVolumesToGadget(installerVols map[string]*Volume, gadgetVols
 		}
 	}
 
+	if err := checkAssignedDevicesUnique(newVols); err != nil {
+		return nil, err
+	}
+
 	return newVols, nil
 }
 
+// checkAssignedDevicesUnique checks that no device has been assigned to more
+// than one volume.
+func checkAssignedDevicesUnique(vols map[string]*Volume) error {
+	volNames := make([]string, 0, len(vols))
+	for volName := range vols {
+		volNames = append(volNames, volName)
+	}
+	sort.Strings(volNames)
+
+	volForDevice := make(map[string]string, len(vols))
+	for _, volName := range volNames {
+		device := vols[volName].AssignedDevice
+		if device == "" {
+			continue
+		}
+		if other, ok := volForDevice[device]; ok {
+			return fmt.Errorf("cannot assign device %q to both volumes %q and %q", device, other, volName)
+		}
+		volForDevice[device] = volName
+	}
+	return nil
+}
+
 func applyPartialFilesystem(insVol *Volume, gadgetVol *Volume, volName string) error {
 	for sidx := range gadgetVol.Structure {
 		vs := &gadgetVol.Structure[sidx]
@@ -311,3 +311,91 @@ volumes:
 	c.Assert(err.Error(), Equals, `cannot find structure "ubuntu-seed"`)
 	c.Assert(mergedVols, IsNil)
 }
+
+func (s *gadgetYamlTestSuite) TestApplyInstallerVolumesToGadgetAssignedDevices(c *C) {
+	var yaml = []byte(`
+volumes:
+  vol0:
+    bootloader: u-boot
+    schema: gpt
+    structure:
+      - name: ubuntu-seed
+        filesystem: vfat
+        size: 500M
+        type: 0FC63DAF-8483-4772-8E79-3D69D8477DE4
+        role: system-seed
+      - name: ubuntu-boot
+        filesystem: ext4
+        size: 500M
+        type: 0FC63DAF-8483-4772-8E79-3D69D8477DE4
+        role: system-boot
+      - name: ubuntu-save
+        filesystem: ext4
+        size: 1M
+        type: 0FC63DAF-8483-4772-8E79-3D69D8477DE4
+        role: system-save
+      - name: ubuntu-data
+        filesystem: ext4
+        size: 1000M
+        type: 0FC63DAF-8483-4772-8E79-3D69D8477DE4
+        role: system-data
+  vol1:
+    schema: gpt
+    structure:
+      - name: other-data
+        filesystem: ext4
+        size: 1000M
+        type: 0FC63DAF-8483-4772-8E79-3D69D8477DE4
+`)
+	err := os.WriteFile(s.gadgetYamlPath, yaml, 0644)
+	c.Assert(err, IsNil)
+
+	installerVols := map[string]*gadget.Volume{
+		"vol0": {
+			Name:           "vol0",
+			AssignedDevice: "/dev/vda",
+			Structure: []gadget.VolumeStructure{
+				{Name: "ubuntu-seed", Device: "/dev/vda1"},
+				{Name: "ubuntu-boot", Device: "/dev/vda2"},
+				{Name: "ubuntu-save", Device: "/dev/vda3"},
+				{Name: "ubuntu-data", Device: "/dev/vda4"},
+			},
+		},
+		"vol1": {
+			Name:           "vol1",
+			AssignedDevice: "/dev/vdb",
+			Structure: []gadget.VolumeStructure{
+				{Name: "other-data", Device: "/dev/vdb1"},
+			},
+		},
+	}
+
+	// Each volume gets its own device
+	gVols := s.readGadgetVols(c)
+	mergedVols, err := gadget.ApplyInstallerVolumesToGadget(installerVols, gVols)
+	c.Assert(err, IsNil)
+	c.Check(mergedVols["vol0"].AssignedDevice, Equals, "/dev/vda")
+	c.Check(mergedVols["vol1"].AssignedDevice, Equals, "/dev/vdb")
+	c.Check(mergedVols["vol1"].Structure[0].Device, Equals, "/dev/vdb1")
+	// the gadget volumes are not modified
+	c.Check(gVols["vol0"].AssignedDevice, Equals, "")
+
+	// A device cannot be used for two volumes
+	installerVols["vol1"].AssignedDevice = "/dev/vda"
+	mergedVols, err = gadget.ApplyInstallerVolumesToGadget(installerVols, gVols)
+	c.Assert(err, ErrorMatches, `cannot assign device "/dev/vda" to both volumes "vol0" and "vol1"`)
+	c.Assert(mergedVols, IsNil)
+
+	// Devices assigned by the gadget cannot be changed
+	installerVols["vol1"].AssignedDevice = "/dev/vdb"
+	gVols["vol1"].AssignedDevice = "/dev/vdc"
+	mergedVols, err = gadget.ApplyInstallerVolumesToGadget(installerVols, gVols)
+	c.Assert(err, ErrorMatches, `installer assigned device "/dev/vdb" to volume "vol1", which is assigned to "/dev/vdc" by the gadget`)
+	c.Assert(mergedVols, IsNil)
+
+	// The installer can omit the assignment done by the gadget
+	installerVols["vol1"].AssignedDevice = ""
+	mergedVols, err = gadget.ApplyInstallerVolumesToGadget(installerVols, gVols)
+	c.Assert(err, IsNil)
+	c.Check(mergedVols["vol1"].AssignedDevice, Equals, "/dev/vdc")
+}
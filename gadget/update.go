@@ -1469,6 +1469,9 @@ func Update(model Model, old, new GadgetData, rollbackDirPath string, updatePoli
 				return fmt.Errorf("cannot update volume structure %v for volume %s: %v", update.to, volName, err)
 			}
 		}
+		if err := checkDeviceMappingUpdates(pOld, pNew, updates); err != nil {
+			return fmt.Errorf("cannot apply update to volume %s: %v", volName, err)
+		}
 
 		// collect updates per volume into a single set of updates to perform
 		// at once
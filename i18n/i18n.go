@@ -26,6 +26,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	gettext "github.com/chai2010/gettext-go"
 
@@ -37,12 +38,27 @@ import (
 // for more information.
 var (
 	TEXTDOMAIN = "snappy"
-	locale     LocaleCatalog
 
 	translationDomain string
 	translationDir    string
 )
 
+// catalog state, protected by catalogLock
+var (
+	catalogLock sync.Mutex
+	locale      LocaleCatalog
+	// loadedLocale is the locale the catalog was loaded for
+	loadedLocale string
+	// pinnedLocale is the locale set explicitly with SetLocale, if unset
+	// the locale follows the environment
+	pinnedLocale string
+	// localeMocked is set while the catalog is replaced by MockLocale
+	localeMocked bool
+	// overrideDirs are directories with catalogs taking precedence over
+	// the system ones, most recently added first
+	overrideDirs []string
+)
+
 // LocaleCatalog provides singular and plural translation lookups.
 type LocaleCatalog interface {
 	Gettext(msgid string) string
@@ -85,17 +101,89 @@ func langpackResolver(baseRoot string, locale string, domain string) string {
 	return ""
 }
 
+// overrideResolver returns the catalog for the locale in a directory of
+// catalog overrides.
+func overrideResolver(baseRoot string, locale string, domain string) string {
+	locales := []string{locale, strings.SplitN(locale, "_", 2)[0]}
+	for _, locale := range locales {
+		mo := filepath.Join(baseRoot, locale, "LC_MESSAGES", fmt.Sprintf("%s.mo", domain))
+		if osutil.FileExists(mo) {
+			return mo
+		}
+	}
+	return ""
+}
+
 func bindTextDomain(domain, dir string) {
 	translationDomain = domain
 	translationDir = dir
 }
 
 func setLocale(loc string) {
-	if loc == "" {
-		loc = localeFromEnv()
+	catalogLock.Lock()
+	defer catalogLock.Unlock()
+
+	pinnedLocale = simplifyLocale(loc)
+	loadCatalogLocked(wantedLocaleLocked())
+}
+
+// SetLocale switches the locale used for translations, an empty locale
+// means following LC_MESSAGES and LANG from the environment, which is the
+// default.
+func SetLocale(loc string) {
+	setLocale(loc)
+}
+
+// LoadCatalogOverrides makes the catalogs found in dir, laid out as
+// <locale>/LC_MESSAGES/<domain>.mo, take precedence over the system ones.
+// Messages missing from the overrides are still looked up in the system
+// catalogs.
+func LoadCatalogOverrides(dir string) error {
+	if !osutil.IsDirectory(dir) {
+		return fmt.Errorf("cannot load message catalog overrides: %q is not a directory", dir)
+	}
+
+	catalogLock.Lock()
+	defer catalogLock.Unlock()
+
+	overrideDirs = append([]string{dir}, overrideDirs...)
+	loadCatalogLocked(wantedLocaleLocked())
+	return nil
+}
+
+func wantedLocaleLocked() string {
+	if pinnedLocale != "" {
+		return pinnedLocale
 	}
+	return simplifyLocale(localeFromEnv())
+}
 
-	locale = newGettextCatalog(translationDir, translationDomain, simplifyLocale(loc), langpackResolver)
+func loadCatalogLocked(loc string) {
+	base := newGettextCatalog(translationDir, translationDomain, loc, langpackResolver)
+	if len(overrideDirs) == 0 {
+		locale = base
+	} else {
+		layers := make(layeredCatalog, 0, len(overrideDirs)+1)
+		for _, dir := range overrideDirs {
+			layers = append(layers, newGettextCatalog(dir, translationDomain, loc, overrideResolver))
+		}
+		locale = append(layers, base)
+	}
+	loadedLocale = loc
+}
+
+// currentCatalog returns the catalog for the wanted locale, reloading it
+// if the locale changed since it was loaded.
+func currentCatalog() LocaleCatalog {
+	catalogLock.Lock()
+	defer catalogLock.Unlock()
+
+	if !localeMocked {
+		if loc := wantedLocaleLocked(); loc != loadedLocale {
+			loadCatalogLocked(loc)
+		}
+	}
+	return locale
 }
 
 func simplifyLocale(loc string) string {
@@ -126,23 +214,52 @@ func CurrentLocale() string {
 
 // G is the shorthand for Gettext
 func G(msgid string) string {
-	return locale.Gettext(msgid)
+	return currentCatalog().Gettext(msgid)
 }
 
 // NG is the shorthand for NGettext
 func NG(msgid string, msgidPlural string, n int) string {
-	return locale.NGettext(msgid, msgidPlural, n)
+	return currentCatalog().NGettext(msgid, msgidPlural, n)
 }
 
 func MockLocale(l LocaleCatalog) (restore func()) {
 	osutil.MustBeTestBinary("cannot mock locale in a non-test binary")
-	old := locale
-	locale = l
+	catalogLock.Lock()
+	defer catalogLock.Unlock()
+
+	old, oldMocked := locale, localeMocked
+	locale, localeMocked = l, true
 	return func() {
-		locale = old
+		catalogLock.Lock()
+		defer catalogLock.Unlock()
+		locale, localeMocked = old, oldMocked
 	}
 }
 
+// layeredCatalog looks up translations in each of its catalogs in turn,
+// returning the first actual translation.
+type layeredCatalog []LocaleCatalog
+
+func (l layeredCatalog) Gettext(msgid string) string {
+	for _, c := range l {
+		if translated := c.Gettext(msgid); translated != msgid {
+			return translated
+		}
+	}
+	return msgid
+}
+
+func (l layeredCatalog) NGettext(msgid string, msgidPlural string, n int) string {
+	var translated string
+	for _, c := range l {
+		translated = c.NGettext(msgid, msgidPlural, n)
+		if translated != msgid && translated != msgidPlural {
+			return translated
+		}
+	}
+	return translated
+}
+
 type chaiCatalog struct {
 	gettexter gettext.Gettexter
 }
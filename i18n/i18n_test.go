@@ -23,6 +23,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	. "gopkg.in/check.v1"
@@ -54,14 +55,32 @@ msgid "singular"
 msgstr "translated singular"
 `)
 
+var mockOverridePo = []byte(`
+msgid ""
+msgstr ""
+"Project-Id-Version: snappy-test\n"
+"Language: en_DK\n"
+"MIME-Version: 1.0\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+"Content-Transfer-Encoding: 8bit\n"
+"Plural-Forms: nplurals=2; plural=n != 1;>\n"
+
+msgid "singular"
+msgstr "overridden singular"
+`)
+
 func makeMockTranslations(c *C, localeDir string) {
+	makeMockTranslationsFromPo(c, localeDir, mockLocalePo)
+}
+
+func makeMockTranslationsFromPo(c *C, localeDir string, content []byte) {
 	fullLocaleDir := filepath.Join(localeDir, "en_DK", "LC_MESSAGES")
 	err := os.MkdirAll(fullLocaleDir, 0755)
 	c.Assert(err, IsNil)
 
 	po := filepath.Join(fullLocaleDir, "snappy-test.po")
 	mo := filepath.Join(fullLocaleDir, "snappy-test.mo")
-	err = os.WriteFile(po, mockLocalePo, 0644)
+	err = os.WriteFile(po, content, 0644)
 	c.Assert(err, IsNil)
 
 	cmd := exec.Command("msgfmt", po, "--output-file", mo)
@@ -98,6 +117,7 @@ func (s *i18nTestSuite) SetUpTest(c *C) {
 func (s *i18nTestSuite) TearDownTest(c *C) {
 	os.Setenv("LANG", s.origLang)
 	os.Setenv("LC_MESSAGES", s.origLcMessages)
+	overrideDirs = nil
 }
 
 func (s *i18nTestSuite) TestTranslatedSingular(c *C) {
@@ -159,3 +179,89 @@ func (s *i18nTestSuite) TestLangpackResolverFromCore(c *C) {
 	var Gtest = G
 	c.Assert(Gtest("singular"), Equals, "translated singular", Commentf("test with %q failed", d))
 }
+
+func (s *i18nTestSuite) TestLocaleFollowsEnvironment(c *C) {
+	// no G() to avoid adding the test string to snappy-pot
+	var Gtest = G
+	c.Assert(Gtest("singular"), Equals, "translated singular")
+
+	// LC_MESSAGES takes precedence over LANG
+	os.Setenv("LC_MESSAGES", "C")
+	c.Check(Gtest("singular"), Equals, "singular")
+
+	os.Setenv("LC_MESSAGES", "en_DK.UTF-8")
+	c.Check(Gtest("singular"), Equals, "translated singular")
+}
+
+func (s *i18nTestSuite) TestSetLocale(c *C) {
+	// no G() to avoid adding the test string to snappy-pot
+	var Gtest = G
+
+	SetLocale("C")
+	c.Check(Gtest("singular"), Equals, "singular")
+
+	// the environment is ignored while the locale is set explicitly
+	os.Setenv("LC_MESSAGES", "en_DK")
+	c.Check(Gtest("singular"), Equals, "singular")
+
+	SetLocale("en_DK.UTF-8@euro")
+	c.Check(Gtest("singular"), Equals, "translated singular")
+
+	// back to following the environment
+	os.Setenv("LC_MESSAGES", "C")
+	SetLocale("")
+	c.Check(Gtest("singular"), Equals, "singular")
+}
+
+func (s *i18nTestSuite) TestLoadCatalogOverrides(c *C) {
+	overrideDir := c.MkDir()
+	makeMockTranslationsFromPo(c, overrideDir, mockOverridePo)
+
+	err := LoadCatalogOverrides(overrideDir)
+	c.Assert(err, IsNil)
+
+	// no G() to avoid adding the test string to snappy-pot
+	var Gtest = G
+	var NGtest = NG
+	c.Check(Gtest("singular"), Equals, "overridden singular")
+	// messages missing from the overrides come from the system catalog
+	c.Check(NGtest("plural_1", "plural_2", 2), Equals, "translated plural_2")
+	c.Check(Gtest("untranslated"), Equals, "untranslated")
+
+	// overrides follow locale changes too
+	os.Setenv("LC_MESSAGES", "C")
+	c.Check(Gtest("singular"), Equals, "singular")
+	c.Check(NGtest("plural_1", "plural_2", 2), Equals, "plural_2")
+}
+
+func (s *i18nTestSuite) TestLoadCatalogOverridesNotADirectory(c *C) {
+	err := LoadCatalogOverrides("/random/not/existing/dir")
+	c.Check(err, ErrorMatches, `cannot load message catalog overrides: "/random/not/existing/dir" is not a directory`)
+	c.Check(overrideDirs, HasLen, 0)
+}
+
+type upperCatalog struct{}
+
+func (upperCatalog) Gettext(msgid string) string {
+	return strings.ToUpper(msgid)
+}
+
+func (upperCatalog) NGettext(msgid string, msgidPlural string, n int) string {
+	if n == 1 {
+		return strings.ToUpper(msgid)
+	}
+	return strings.ToUpper(msgidPlural)
+}
+
+func (s *i18nTestSuite) TestMockLocaleIsNotReloaded(c *C) {
+	restore := MockLocale(upperCatalog{})
+	defer restore()
+
+	// no G() to avoid adding the test string to snappy-pot
+	var Gtest = G
+	os.Setenv("LC_MESSAGES", "C")
+	c.Check(Gtest("singular"), Equals, "SINGULAR")
+
+	restore()
+	c.Check(Gtest("singular"), Equals, "singular")
+}
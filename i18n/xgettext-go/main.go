@@ -10,7 +10,9 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -155,6 +157,64 @@ func inspectNodeForTranslations(fset *token.FileSet, f *ast.File, n ast.Node) bo
 	return true
 }
 
+// structTagsWanted returns whether descriptions in the struct tags of the
+// given file should be extracted.
+func structTagsWanted(f *ast.File) bool {
+	if !opts.StructTags {
+		return false
+	}
+	if len(opts.StructTagsPackages) == 0 {
+		return true
+	}
+	for _, pkg := range opts.StructTagsPackages {
+		if f.Name.Name == pkg {
+			return true
+		}
+	}
+	return false
+}
+
+// inspectNodeForStructTags extracts the descriptions given to go-flags via
+// the description:"..." struct tag.
+func inspectNodeForStructTags(fset *token.FileSet, f *ast.File, n ast.Node) bool {
+	x, ok := n.(*ast.StructType)
+	if !ok || x.Fields == nil {
+		return true
+	}
+
+	for _, field := range x.Fields.List {
+		if field.Tag == nil {
+			continue
+		}
+		tag, err := strconv.Unquote(field.Tag.Value)
+		if err != nil {
+			continue
+		}
+		desc, ok := reflect.StructTag(tag).Lookup("description")
+		if !ok || desc == "" {
+			continue
+		}
+
+		formatHint := ""
+		if strings.Contains(desc, "%") {
+			formatHint = "c-format"
+		}
+		// escape the description like a string literal, stripping the
+		// surrounding quotes
+		quoted := strconv.Quote(desc)
+		msgidStr := quoted[1 : len(quoted)-1]
+		posTag := fset.Position(field.Tag.Pos())
+		msgIDs[msgidStr] = append(msgIDs[msgidStr], msgID{
+			formatHint: formatHint,
+			fname:      posTag.Filename,
+			line:       posTag.Line,
+			comment:    findCommentsForTranslation(fset, f, fset.Position(field.Pos())),
+		})
+	}
+
+	return true
+}
+
 func processFiles(args []string) error {
 	// go over the input files
 	msgIDs = make(map[string][]msgID)
@@ -203,6 +263,12 @@ func processSingleGoSource(fset *token.FileSet, fname string) error {
 		return inspectNodeForTranslations(fset, f, n)
 	})
 
+	if structTagsWanted(f) {
+		ast.Inspect(f, func(n ast.Node) bool {
+			return inspectNodeForStructTags(fset, f, n)
+		})
+	}
+
 	return nil
 }
 
@@ -312,6 +378,9 @@ var opts struct {
 
 	Keyword       string `short:"k" long:"keyword" default:"gettext.Gettext" description:"look for WORD as the keyword for singular strings"`
 	KeywordPlural string `long:"keyword-plural" default:"gettext.NGettext" description:"look for WORD as the keyword for plural strings"`
+
+	StructTags         bool     `long:"struct-tags" description:"also extract descriptions from go-flags struct tags"`
+	StructTagsPackages []string `long:"struct-tags-package" description:"only extract struct tag descriptions in PACKAGE"`
 }
 
 func main() {
@@ -57,6 +57,8 @@ func (s *xgettextTestSuite) SetUpTest(c *C) {
 	opts.SortOutput = true
 	opts.PackageName = "snappy"
 	opts.MsgIDBugsAddress = "snappy-devel@lists.ubuntu.com"
+	opts.StructTags = false
+	opts.StructTagsPackages = nil
 
 	// mock time
 	formatTime = func() string {
@@ -535,3 +537,88 @@ msgstr  ""
 `, header, fname)
 	c.Check(out.String(), Equals, expected)
 }
+
+const structTagsSource = `package main
+
+type cmdFoo struct {
+	// TRANSLATORS: the verbose option
+	Verbose bool ` + "`" + `long:"verbose" description:"Show \"more\" output"` + "`" + `
+	Count   int  ` + "`" + `long:"count" description:"Repeat %d times"` + "`" + `
+	Hidden  bool ` + "`" + `long:"hidden" hidden:"yes"` + "`" + `
+	Empty   bool ` + "`" + `long:"empty" description:""` + "`" + `
+	Plain   string
+}
+
+func main() {
+	i18n.G("foo")
+}
+`
+
+func (s *xgettextTestSuite) TestProcessFilesStructTagsDisabled(c *C) {
+	fname := makeGoSourceFile(c, []byte(structTagsSource))
+	err := processFiles([]string{fname})
+	c.Assert(err, IsNil)
+
+	c.Assert(msgIDs, DeepEquals, map[string][]msgID{
+		"foo": {
+			{
+				fname: fname,
+				line:  13,
+			},
+		},
+	})
+}
+
+func (s *xgettextTestSuite) TestProcessFilesStructTags(c *C) {
+	opts.StructTags = true
+
+	fname := makeGoSourceFile(c, []byte(structTagsSource))
+	err := processFiles([]string{fname})
+	c.Assert(err, IsNil)
+
+	c.Assert(msgIDs, DeepEquals, map[string][]msgID{
+		"foo": {
+			{
+				fname: fname,
+				line:  13,
+			},
+		},
+		`Show \"more\" output`: {
+			{
+				comment: "#. TRANSLATORS: the verbose option\n",
+				fname:   fname,
+				line:    5,
+			},
+		},
+		"Repeat %d times": {
+			{
+				formatHint: "c-format",
+				fname:      fname,
+				line:       6,
+			},
+		},
+	})
+
+	out := bytes.NewBuffer([]byte(""))
+	writePotFile(out)
+	c.Check(out.String(), testutil.Contains, fmt.Sprintf(`#. TRANSLATORS: the verbose option
+#: %s:5
+msgid   "Show \"more\" output"
+msgstr  ""
+`, fname))
+}
+
+func (s *xgettextTestSuite) TestProcessFilesStructTagsPackages(c *C) {
+	opts.StructTags = true
+	opts.StructTagsPackages = []string{"cli"}
+
+	fname := makeGoSourceFile(c, []byte(structTagsSource))
+	err := processFiles([]string{fname})
+	c.Assert(err, IsNil)
+	c.Check(msgIDs, HasLen, 1)
+
+	opts.StructTagsPackages = []string{"cli", "main"}
+	err = processFiles([]string{fname})
+	c.Assert(err, IsNil)
+	c.Check(msgIDs, HasLen, 3)
+}
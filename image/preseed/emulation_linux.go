@@ -0,0 +1,214 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package preseed
+
+import (
+	"bufio"
+	"debug/elf"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/snapcore/snapd/arch"
+	"github.com/snapcore/snapd/osutil"
+)
+
+var (
+	binfmtMiscDir        = "/proc/sys/fs/binfmt_misc"
+	archDpkgArchitecture = arch.DpkgArchitecture
+
+	detectEmulation = detectEmulationImpl
+)
+
+// qemuArchitectures maps dpkg architectures to the names used by qemu-user
+// and by the binfmt_misc handlers registered for it.
+var qemuArchitectures = map[string]string{
+	"amd64":   "x86_64",
+	"i386":    "i386",
+	"arm64":   "aarch64",
+	"armhf":   "arm",
+	"ppc64":   "ppc64",
+	"ppc64el": "ppc64le",
+	"riscv64": "riscv64",
+	"s390x":   "s390x",
+}
+
+// nativeCompatArchitectures lists the architectures that hosts of a given
+// architecture run natively.
+var nativeCompatArchitectures = map[string][]string{
+	"amd64": {"i386"},
+}
+
+// elfDpkgArchitecture returns the dpkg architecture of the given ELF binary.
+func elfDpkgArchitecture(path string) (string, error) {
+	f, err := elf.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	switch f.Machine {
+	case elf.EM_X86_64:
+		return "amd64", nil
+	case elf.EM_386:
+		return "i386", nil
+	case elf.EM_AARCH64:
+		return "arm64", nil
+	case elf.EM_ARM:
+		return "armhf", nil
+	case elf.EM_PPC64:
+		if f.ByteOrder == binary.LittleEndian {
+			return "ppc64el", nil
+		}
+		return "ppc64", nil
+	case elf.EM_RISCV:
+		if f.Class == elf.ELFCLASS64 {
+			return "riscv64", nil
+		}
+	case elf.EM_S390:
+		if f.Class == elf.ELFCLASS64 {
+			return "s390x", nil
+		}
+	}
+	return "", fmt.Errorf("unsupported %s binary for %s", f.Class, f.Machine)
+}
+
+// emulation describes how snapd of a target system of another architecture
+// than the host is run.
+type emulation struct {
+	hostArch   string
+	targetArch string
+	// interpreter is the qemu-user binary registered with binfmt_misc
+	interpreter string
+}
+
+// env returns the environment telling snapd, and the helpers it runs, that
+// it preseeds under emulation.
+func (e *emulation) env() []string {
+	return []string{
+		"SNAPD_PRESEED_EMULATED=1",
+		"SNAPD_PRESEED_TARGET_ARCH=" + e.targetArch,
+	}
+}
+
+// writeReport reports what could and could not be preseeded under
+// emulation.
+func (e *emulation) writeReport(w io.Writer) {
+	fmt.Fprintf(w, "preseeded %s system on %s host using %s:\n", e.targetArch, e.hostArch, e.interpreter)
+	for _, step := range []struct{ what, status string }{
+		{"seeding of snaps", "preseeded"},
+		{"interface connections", "preseeded"},
+		{"systemd units and udev rules", "preseeded"},
+		{"seccomp filters", fmt.Sprintf("preseeded (for %s)", e.targetArch)},
+		{"AppArmor profiles", "preseeded"},
+		{"AppArmor policy cache", "not preseeded, compiled on first boot"},
+	} {
+		fmt.Fprintf(w, " - %s: %s\n", step.what, step.status)
+	}
+}
+
+type binfmtHandler struct {
+	enabled     bool
+	interpreter string
+	flags       string
+}
+
+func readBinfmtHandler(path string) (*binfmtHandler, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// the handler is described like:
+	// enabled
+	// interpreter /usr/libexec/qemu-binfmt/aarch64-binfmt-P
+	// flags: POCF
+	// offset 0
+	// magic 7f454c460201010000000000000000000200b700
+	// mask ffffffffffffff00fffffffffffffffffeffffff
+	var h binfmtHandler
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, _ := strings.Cut(scanner.Text(), " ")
+		switch key {
+		case "enabled":
+			h.enabled = true
+		case "interpreter":
+			h.interpreter = value
+		case "flags:":
+			h.flags = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+// detectEmulationImpl checks whether the snapd binary at the given path,
+// meant to run in the chroot at chrootDir, is built for another
+// architecture than the host and, if so, that the host is set up to run it
+// under qemu-user. It returns nil if snapd runs natively.
+func detectEmulationImpl(chrootDir, snapdPath string) (*emulation, error) {
+	targetArch, err := elfDpkgArchitecture(snapdPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot determine the architecture of %s: %v", snapdPath, err)
+	}
+	hostArch := archDpkgArchitecture()
+	if targetArch == hostArch {
+		return nil, nil
+	}
+	for _, compat := range nativeCompatArchitectures[hostArch] {
+		if targetArch == compat {
+			return nil, nil
+		}
+	}
+
+	qemuArch, ok := qemuArchitectures[targetArch]
+	if !ok {
+		return nil, fmt.Errorf("cannot preseed %s system on %s host: no qemu-user emulation is known", targetArch, hostArch)
+	}
+	handlerName := "qemu-" + qemuArch
+	h, err := readBinfmtHandler(filepath.Join(binfmtMiscDir, handlerName))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf(`cannot preseed %s system on %s host: binfmt_misc handler %s is not registered, please install the "qemu-user-static" package`, targetArch, hostArch, handlerName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read binfmt_misc handler %s: %v", handlerName, err)
+	}
+	if !h.enabled {
+		return nil, fmt.Errorf("cannot preseed %s system on %s host: binfmt_misc handler %s is disabled", targetArch, hostArch, handlerName)
+	}
+	// without the fix-binary flag the interpreter is looked up when
+	// snapd is executed, that is inside the chroot
+	if !strings.Contains(h.flags, "F") && !osutil.FileExists(filepath.Join(chrootDir, h.interpreter)) {
+		return nil, fmt.Errorf("cannot preseed %s system on %s host: binfmt_misc handler %s is not registered with the fix-binary (F) flag and its interpreter %s is missing from the chroot", targetArch, hostArch, handlerName, h.interpreter)
+	}
+
+	return &emulation{
+		hostArch:    hostArch,
+		targetArch:  targetArch,
+		interpreter: h.interpreter,
+	}, nil
+}
@@ -0,0 +1,194 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package preseed_test
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/image/preseed"
+)
+
+type emulationSuite struct {
+	chrootDir string
+	binfmtDir string
+}
+
+var _ = Suite(&emulationSuite{})
+
+func (s *emulationSuite) SetUpTest(c *C) {
+	s.chrootDir = c.MkDir()
+	s.binfmtDir = c.MkDir()
+}
+
+// mockELF writes the header of an ELF binary for the given machine.
+func mockELF(c *C, path string, class elf.Class, order binary.ByteOrder, machine elf.Machine) {
+	var data elf.Data = elf.ELFDATA2LSB
+	if order == binary.BigEndian {
+		data = elf.ELFDATA2MSB
+	}
+	ident := [elf.EI_NIDENT]byte{0x7f, 'E', 'L', 'F', byte(class), byte(data), byte(elf.EV_CURRENT)}
+
+	var buf bytes.Buffer
+	if class == elf.ELFCLASS64 {
+		c.Assert(binary.Write(&buf, order, elf.Header64{
+			Ident:   ident,
+			Type:    uint16(elf.ET_EXEC),
+			Machine: uint16(machine),
+			Version: uint32(elf.EV_CURRENT),
+			Ehsize:  64,
+		}), IsNil)
+	} else {
+		c.Assert(binary.Write(&buf, order, elf.Header32{
+			Ident:   ident,
+			Type:    uint16(elf.ET_EXEC),
+			Machine: uint16(machine),
+			Version: uint32(elf.EV_CURRENT),
+			Ehsize:  52,
+		}), IsNil)
+	}
+	c.Assert(os.MkdirAll(filepath.Dir(path), 0755), IsNil)
+	c.Assert(os.WriteFile(path, buf.Bytes(), 0755), IsNil)
+}
+
+func (s *emulationSuite) mockBinfmtHandler(c *C, name, content string) {
+	c.Assert(os.WriteFile(filepath.Join(s.binfmtDir, name), []byte(content), 0644), IsNil)
+}
+
+func (s *emulationSuite) TestDetectEmulationNative(c *C) {
+	defer preseed.MockBinfmtMiscDir(s.binfmtDir)()
+	defer preseed.MockArchDpkgArchitecture(func() string { return "amd64" })()
+
+	snapd := filepath.Join(s.chrootDir, "usr/lib/snapd/snapd")
+	mockELF(c, snapd, elf.ELFCLASS64, binary.LittleEndian, elf.EM_X86_64)
+	emu, err := preseed.DetectEmulationImpl(s.chrootDir, snapd)
+	c.Assert(err, IsNil)
+	c.Check(emu, IsNil)
+
+	// i386 runs natively on amd64
+	mockELF(c, snapd, elf.ELFCLASS32, binary.LittleEndian, elf.EM_386)
+	emu, err = preseed.DetectEmulationImpl(s.chrootDir, snapd)
+	c.Assert(err, IsNil)
+	c.Check(emu, IsNil)
+}
+
+func (s *emulationSuite) TestDetectEmulationHappy(c *C) {
+	defer preseed.MockBinfmtMiscDir(s.binfmtDir)()
+	defer preseed.MockArchDpkgArchitecture(func() string { return "amd64" })()
+
+	s.mockBinfmtHandler(c, "qemu-aarch64", `enabled
+interpreter /usr/libexec/qemu-binfmt/aarch64-binfmt-P
+flags: POCF
+offset 0
+magic 7f454c460201010000000000000000000200b700
+mask ffffffffffffff00fffffffffffffffffeffffff
+`)
+	snapd := filepath.Join(s.chrootDir, "usr/lib/snapd/snapd")
+	mockELF(c, snapd, elf.ELFCLASS64, binary.LittleEndian, elf.EM_AARCH64)
+
+	emu, err := preseed.DetectEmulationImpl(s.chrootDir, snapd)
+	c.Assert(err, IsNil)
+	c.Assert(emu, NotNil)
+	hostArch, targetArch, interpreter := emu.Fields()
+	c.Check(hostArch, Equals, "amd64")
+	c.Check(targetArch, Equals, "arm64")
+	c.Check(interpreter, Equals, "/usr/libexec/qemu-binfmt/aarch64-binfmt-P")
+	c.Check(emu.Env(), DeepEquals, []string{
+		"SNAPD_PRESEED_EMULATED=1",
+		"SNAPD_PRESEED_TARGET_ARCH=arm64",
+	})
+}
+
+func (s *emulationSuite) TestDetectEmulationTargetArchitectures(c *C) {
+	defer preseed.MockBinfmtMiscDir(s.binfmtDir)()
+	defer preseed.MockArchDpkgArchitecture(func() string { return "amd64" })()
+
+	snapd := filepath.Join(s.chrootDir, "usr/lib/snapd/snapd")
+	for _, t := range []struct {
+		class    elf.Class
+		order    binary.ByteOrder
+		machine  elf.Machine
+		arch     string
+		qemuArch string
+	}{
+		{elf.ELFCLASS32, binary.LittleEndian, elf.EM_ARM, "armhf", "arm"},
+		{elf.ELFCLASS64, binary.LittleEndian, elf.EM_PPC64, "ppc64el", "ppc64le"},
+		{elf.ELFCLASS64, binary.BigEndian, elf.EM_PPC64, "ppc64", "ppc64"},
+		{elf.ELFCLASS64, binary.LittleEndian, elf.EM_RISCV, "riscv64", "riscv64"},
+		{elf.ELFCLASS64, binary.BigEndian, elf.EM_S390, "s390x", "s390x"},
+	} {
+		s.mockBinfmtHandler(c, "qemu-"+t.qemuArch, "enabled\ninterpreter /usr/bin/qemu-"+t.qemuArch+"-static\nflags: F\n")
+		mockELF(c, snapd, t.class, t.order, t.machine)
+
+		emu, err := preseed.DetectEmulationImpl(s.chrootDir, snapd)
+		c.Assert(err, IsNil, Commentf("%s", t.arch))
+		c.Assert(emu, NotNil)
+		_, targetArch, _ := emu.Fields()
+		c.Check(targetArch, Equals, t.arch)
+	}
+}
+
+func (s *emulationSuite) TestDetectEmulationInterpreterInChroot(c *C) {
+	defer preseed.MockBinfmtMiscDir(s.binfmtDir)()
+	defer preseed.MockArchDpkgArchitecture(func() string { return "amd64" })()
+
+	s.mockBinfmtHandler(c, "qemu-aarch64", "enabled\ninterpreter /usr/bin/qemu-aarch64-static\nflags: OC\n")
+	snapd := filepath.Join(s.chrootDir, "usr/lib/snapd/snapd")
+	mockELF(c, snapd, elf.ELFCLASS64, binary.LittleEndian, elf.EM_AARCH64)
+
+	_, err := preseed.DetectEmulationImpl(s.chrootDir, snapd)
+	c.Check(err, ErrorMatches, `cannot preseed arm64 system on amd64 host: binfmt_misc handler qemu-aarch64 is not registered with the fix-binary \(F\) flag and its interpreter /usr/bin/qemu-aarch64-static is missing from the chroot`)
+
+	// it works with the interpreter copied into the chroot
+	interpreter := filepath.Join(s.chrootDir, "usr/bin/qemu-aarch64-static")
+	c.Assert(os.MkdirAll(filepath.Dir(interpreter), 0755), IsNil)
+	c.Assert(os.WriteFile(interpreter, nil, 0755), IsNil)
+	emu, err := preseed.DetectEmulationImpl(s.chrootDir, snapd)
+	c.Assert(err, IsNil)
+	c.Check(emu, NotNil)
+}
+
+func (s *emulationSuite) TestDetectEmulationErrors(c *C) {
+	defer preseed.MockBinfmtMiscDir(s.binfmtDir)()
+	defer preseed.MockArchDpkgArchitecture(func() string { return "amd64" })()
+
+	snapd := filepath.Join(s.chrootDir, "usr/lib/snapd/snapd")
+	c.Assert(os.MkdirAll(filepath.Dir(snapd), 0755), IsNil)
+	c.Assert(os.WriteFile(snapd, []byte("#!/bin/sh\nexit 0\n"), 0755), IsNil)
+	_, err := preseed.DetectEmulationImpl(s.chrootDir, snapd)
+	c.Check(err, ErrorMatches, `cannot determine the architecture of .*/usr/lib/snapd/snapd: bad magic number .*`)
+
+	mockELF(c, snapd, elf.ELFCLASS64, binary.LittleEndian, elf.EM_MIPS)
+	_, err = preseed.DetectEmulationImpl(s.chrootDir, snapd)
+	c.Check(err, ErrorMatches, `cannot determine the architecture of .*/usr/lib/snapd/snapd: unsupported ELFCLASS64 binary for EM_MIPS`)
+
+	mockELF(c, snapd, elf.ELFCLASS64, binary.LittleEndian, elf.EM_AARCH64)
+	_, err = preseed.DetectEmulationImpl(s.chrootDir, snapd)
+	c.Check(err, ErrorMatches, `cannot preseed arm64 system on amd64 host: binfmt_misc handler qemu-aarch64 is not registered, please install the "qemu-user-static" package`)
+
+	s.mockBinfmtHandler(c, "qemu-aarch64", "disabled\ninterpreter /usr/bin/qemu-aarch64-static\nflags: F\n")
+	_, err = preseed.DetectEmulationImpl(s.chrootDir, snapd)
+	c.Check(err, ErrorMatches, `cannot preseed arm64 system on amd64 host: binfmt_misc handler qemu-aarch64 is disabled`)
+}
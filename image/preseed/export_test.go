@@ -67,3 +67,37 @@ func MockResetPreseededChroot(f func(preseedChroot string) error) (restore func(
 	ResetPreseededChroot = f
 	return r
 }
+
+type Emulation = emulation
+
+var DetectEmulationImpl = detectEmulationImpl
+
+func NewEmulation(hostArch, targetArch, interpreter string) *Emulation {
+	return &emulation{hostArch: hostArch, targetArch: targetArch, interpreter: interpreter}
+}
+
+func (e *Emulation) Fields() (hostArch, targetArch, interpreter string) {
+	return e.hostArch, e.targetArch, e.interpreter
+}
+
+func (e *Emulation) Env() []string {
+	return e.env()
+}
+
+func MockDetectEmulation(f func(chrootDir, snapdPath string) (*Emulation, error)) (restore func()) {
+	r := testutil.Backup(&detectEmulation)
+	detectEmulation = f
+	return r
+}
+
+func MockBinfmtMiscDir(dir string) (restore func()) {
+	r := testutil.Backup(&binfmtMiscDir)
+	binfmtMiscDir = dir
+	return r
+}
+
+func MockArchDpkgArchitecture(f func() string) (restore func()) {
+	r := testutil.Backup(&archDpkgArchitecture)
+	archDpkgArchitecture = f
+	return r
+}
@@ -20,6 +20,7 @@
 package preseed_test
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -175,6 +176,86 @@ func (s *preseedSuite) TestRunPreseedHappy(c *C) {
 	c.Check(preseed.Classic(relativeChroot), IsNil)
 }
 
+func (s *preseedSuite) TestRunPreseedEmulatedHappy(c *C) {
+	tmpDir := c.MkDir()
+	dirs.SetRootDir(tmpDir)
+	defer mockChrootDirs(c, tmpDir, true)()
+
+	restoreSyscallChroot := preseed.MockSyscallChroot(func(path string) error { return nil })
+	defer restoreSyscallChroot()
+
+	mockMountCmd := testutil.MockCommand(c, "mount", "")
+	defer mockMountCmd.Restore()
+
+	mockUmountCmd := testutil.MockCommand(c, "umount", "")
+	defer mockUmountCmd.Restore()
+
+	targetSnapdRoot := filepath.Join(tmpDir, "target-core-mounted-here")
+	restoreMountPath := preseed.MockSnapdMountPath(targetSnapdRoot)
+	defer restoreMountPath()
+
+	restoreSystemSnapFromSeed := preseed.MockSystemSnapFromSeed(func(string, string) (string, string, error) { return "/a/core.snap", "", nil })
+	defer restoreSystemSnapFromSeed()
+
+	restoreDetectEmulation := preseed.MockDetectEmulation(func(chrootDir, snapdPath string) (*preseed.Emulation, error) {
+		c.Check(chrootDir, Equals, tmpDir)
+		c.Check(snapdPath, Equals, filepath.Join(tmpDir, "usr/lib/snapd/snapd"))
+		return preseed.NewEmulation("amd64", "arm64", "/usr/bin/qemu-aarch64-static"), nil
+	})
+	defer restoreDetectEmulation()
+
+	var stdout bytes.Buffer
+	restoreStdout := testutil.Backup(&preseed.Stdout)
+	defer restoreStdout()
+	preseed.Stdout = &stdout
+
+	mockTargetSnapd := testutil.MockCommand(c, filepath.Join(targetSnapdRoot, "usr/lib/snapd/snapd"), `#!/bin/sh
+	set -eu
+	[ "${SNAPD_PRESEED}" = "1" ]
+	[ "${SNAPD_PRESEED_EMULATED}" = "1" ]
+	[ "${SNAPD_PRESEED_TARGET_ARCH}" = "arm64" ]
+`)
+	defer mockTargetSnapd.Restore()
+
+	mockSnapdFromDeb := testutil.MockCommand(c, filepath.Join(tmpDir, "usr/lib/snapd/snapd"), `#!/bin/sh
+	exit 1
+`)
+	defer mockSnapdFromDeb.Restore()
+
+	// snapd from the snap is newer than deb
+	mockVersionFiles(c, targetSnapdRoot, "2.44.0", tmpDir, "2.41.0")
+
+	c.Assert(preseed.Classic(tmpDir), IsNil)
+	c.Check(mockTargetSnapd.Calls(), HasLen, 1)
+	c.Check(stdout.String(), testutil.Contains, `preseeded arm64 system on amd64 host using /usr/bin/qemu-aarch64-static:
+ - seeding of snaps: preseeded
+ - interface connections: preseeded
+ - systemd units and udev rules: preseeded
+ - seccomp filters: preseeded (for arm64)
+ - AppArmor profiles: preseeded
+ - AppArmor policy cache: not preseeded, compiled on first boot
+`)
+}
+
+func (s *preseedSuite) TestRunPreseedEmulationUnavailable(c *C) {
+	tmpDir := c.MkDir()
+	dirs.SetRootDir(tmpDir)
+	defer mockChrootDirs(c, tmpDir, true)()
+
+	restoreDetectEmulation := preseed.MockDetectEmulation(func(chrootDir, snapdPath string) (*preseed.Emulation, error) {
+		return nil, fmt.Errorf("cannot preseed arm64 system on amd64 host: boom")
+	})
+	defer restoreDetectEmulation()
+
+	restoreSyscallChroot := preseed.MockSyscallChroot(func(path string) error {
+		c.Fatalf("unexpected chroot")
+		return nil
+	})
+	defer restoreSyscallChroot()
+
+	c.Check(preseed.Classic(tmpDir), ErrorMatches, "cannot preseed arm64 system on amd64 host: boom")
+}
+
 func (s *preseedSuite) TestRunPreseedHybridHappy(c *C) {
 	tmpDir := c.MkDir()
 	dirs.SetRootDir(tmpDir)
@@ -696,7 +696,8 @@ func createPreseedArtifact(opts *preseedCoreOptions) (digest []byte, err error)
 
 // runPreseedMode runs snapd in a preseed mode. It assumes running in a chroot.
 // The chroot is expected to be set-up and ready to use (critical system directories mounted).
-func runPreseedMode(preseedChroot string, targetSnapd *targetSnapdInfo, hybrid bool) error {
+// When emu is set snapd is run under qemu-user emulation.
+func runPreseedMode(preseedChroot string, targetSnapd *targetSnapdInfo, hybrid bool, emu *emulation) error {
 	// run snapd in preseed mode
 	cmd := exec.Command(targetSnapd.path)
 	cmd.Env = os.Environ()
@@ -704,6 +705,9 @@ func runPreseedMode(preseedChroot string, targetSnapd *targetSnapdInfo, hybrid b
 	if hybrid {
 		cmd.Env = append(cmd.Env, "SNAPD_PRESEED_HYBRID=1")
 	}
+	if emu != nil {
+		cmd.Env = append(cmd.Env, emu.env()...)
+	}
 	cmd.Stderr = Stderr
 	cmd.Stdout = Stdout
 
@@ -714,6 +718,9 @@ func runPreseedMode(preseedChroot string, targetSnapd *targetSnapdInfo, hybrid b
 		return fmt.Errorf("error running snapd in preseed mode: %v\n", err)
 	}
 
+	if emu != nil {
+		emu.writeReport(Stdout)
+	}
 	return nil
 }
 
@@ -731,9 +738,17 @@ func reexecReset(preseedChroot string, targetSnapd *targetSnapdInfo) error {
 }
 
 func runUC20PreseedMode(opts *preseedCoreOptions) error {
+	emu, err := detectEmulation(opts.PreseedChrootDir, filepath.Join(opts.PreseedChrootDir, "/usr/lib/snapd/snapd"))
+	if err != nil {
+		return err
+	}
+
 	cmd := exec.Command("chroot", opts.PreseedChrootDir, "/usr/lib/snapd/snapd")
 	cmd.Env = os.Environ()
 	cmd.Env = append(cmd.Env, "SNAPD_PRESEED=1")
+	if emu != nil {
+		cmd.Env = append(cmd.Env, emu.env()...)
+	}
 	cmd.Stderr = Stderr
 	cmd.Stdout = Stdout
 	fmt.Fprintf(Stdout, "starting to preseed UC20+ system: %s\n", opts.PreseedChrootDir)
@@ -756,6 +771,9 @@ func runUC20PreseedMode(opts *preseedCoreOptions) error {
 		return fmt.Errorf("cannot create preseed assertion: %v", err)
 	}
 
+	if emu != nil {
+		emu.writeReport(Stdout)
+	}
 	return nil
 }
 
@@ -788,6 +806,13 @@ func classicLikePreseed(chrootDir, label string) error {
 		return fmt.Errorf("chroot verification failed: %w", err)
 	}
 
+	// the architecture is checked before entering the chroot, where
+	// binfmt_misc is not available
+	emu, err := detectEmulation(chrootDir, filepath.Join(chrootDir, dirs.CoreLibExecDir, "snapd"))
+	if err != nil {
+		return err
+	}
+
 	// XXX: if prepareClassicChroot & runPreseedMode were refactored to
 	// use "chroot" inside runPreseedMode (and not syscall.Chroot at the
 	// beginning of prepareClassicChroot), then we could have a single
@@ -802,7 +827,7 @@ func classicLikePreseed(chrootDir, label string) error {
 
 	// executing inside the chroot
 	hybrid := label != ""
-	return runPreseedMode(chrootDir, targetSnapd, hybrid)
+	return runPreseedMode(chrootDir, targetSnapd, hybrid, emu)
 }
 
 // Classic runs preseeding of a classic ubuntu system pointed by chrootDir.
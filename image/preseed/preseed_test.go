@@ -52,6 +52,10 @@ func (s *preseedSuite) SetUpTest(c *C) {
 	s.BaseTest.SetUpTest(c)
 	restore := squashfs.MockNeedsFuse(false)
 	s.BaseTest.AddCleanup(restore)
+	// the mocked snapd binaries are scripts, run natively
+	s.BaseTest.AddCleanup(preseed.MockDetectEmulation(func(chrootDir, snapdPath string) (*preseed.Emulation, error) {
+		return nil, nil
+	}))
 }
 
 func (s *preseedSuite) TearDownTest(c *C) {
@@ -521,3 +521,28 @@ func (s *preseedSuite) TestRunPreseedUC20ExecFormatError(c *C) {
 	err = preseed.RunUC20PreseedMode(popts)
 	c.Check(err, ErrorMatches, `error running snapd, please try installing the "qemu-user-static" package: fork/exec .* exec format error`)
 }
+
+func (s *preseedSuite) TestRunPreseedUC20EmulationUnavailable(c *C) {
+	tmpdir := c.MkDir()
+
+	mockChrootCmd := testutil.MockCommand(c, "chroot", "")
+	defer mockChrootCmd.Restore()
+
+	restoreDetectEmulation := preseed.MockDetectEmulation(func(chrootDir, snapdPath string) (*preseed.Emulation, error) {
+		c.Check(chrootDir, Equals, tmpdir)
+		c.Check(snapdPath, Equals, filepath.Join(tmpdir, "usr/lib/snapd/snapd"))
+		return nil, fmt.Errorf(`cannot preseed arm64 system on amd64 host: binfmt_misc handler qemu-aarch64 is not registered, please install the "qemu-user-static" package`)
+	})
+	defer restoreDetectEmulation()
+
+	popts := &preseed.PreseedCoreOptions{
+		CoreOptions: preseed.CoreOptions{
+			PrepareImageDir: tmpdir,
+		},
+		PreseedChrootDir: tmpdir,
+	}
+
+	err := preseed.RunUC20PreseedMode(popts)
+	c.Check(err, ErrorMatches, `cannot preseed arm64 system on amd64 host: binfmt_misc handler qemu-aarch64 is not registered, please install the "qemu-user-static" package`)
+	c.Check(mockChrootCmd.Calls(), HasLen, 0)
+}
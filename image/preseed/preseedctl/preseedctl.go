@@ -0,0 +1,237 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package preseedctl implements the configuration commands of snapctl
+// directly on top of the state of a system being preseeded, for the phases
+// of preseeding where hooks run without a live snapd to talk to.
+package preseedctl
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/client/clientutil"
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+// ErrSnapdRunning is returned when the state is locked by a running snapd,
+// in which case snapctl must go through its socket instead.
+var ErrSnapdRunning = errors.New("cannot access the state directly: snapd is running")
+
+type getCommand struct {
+	Document   bool `short:"d"`
+	Typed      bool `short:"t"`
+	Positional struct {
+		Keys []string `positional-arg-name:"<keys>"`
+	} `positional-args:"yes"`
+}
+
+type setCommand struct {
+	String     bool `short:"s"`
+	Typed      bool `short:"t"`
+	Positional struct {
+		ConfValues []string `positional-arg-name:"<key=value>"`
+	} `positional-args:"yes"`
+}
+
+type unsetCommand struct {
+	Positional struct {
+		ConfKeys []string `positional-arg-name:"<keys>"`
+	} `positional-args:"yes"`
+}
+
+// the commands only parse their arguments, they are run by Run
+func (*getCommand) Execute([]string) error   { return nil }
+func (*setCommand) Execute([]string) error   { return nil }
+func (*unsetCommand) Execute([]string) error { return nil }
+
+// Run runs the given snapctl command line on behalf of the snap the given
+// context ID (the snap cookie) belongs to, reading and writing the snapd
+// state file directly. Only the get, set and unset commands of
+// configuration options are supported.
+func Run(contextID string, args []string) (stdout []byte, err error) {
+	var get getCommand
+	var set setCommand
+	var unset unsetCommand
+	parser := flags.NewNamedParser("snapctl", flags.PassDoubleDash)
+	parser.AddCommand("get", "", "", &get)
+	parser.AddCommand("set", "", "", &set)
+	parser.AddCommand("unset", "", "", &unset)
+	if len(args) == 0 {
+		return nil, fmt.Errorf("internal error: snapctl cannot run without args")
+	}
+	switch args[0] {
+	case "get", "set", "unset":
+	default:
+		return nil, fmt.Errorf("cannot run %q without snapd while preseeding", "snapctl "+args[0])
+	}
+	if _, err := parser.ParseArgs(args); err != nil {
+		return nil, err
+	}
+
+	flock, err := osutil.NewFileLockWithMode(dirs.SnapStateLockFile, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open state lock file: %v", err)
+	}
+	defer flock.Close()
+	if err := flock.TryLock(); err != nil {
+		if err == osutil.ErrAlreadyLocked {
+			return nil, ErrSnapdRunning
+		}
+		return nil, fmt.Errorf("cannot lock state: %v", err)
+	}
+	defer flock.Unlock()
+
+	st, err := readState()
+	if err != nil {
+		return nil, err
+	}
+	st.Lock()
+	defer st.Unlock()
+
+	instanceName, err := snapForContext(st, contextID)
+	if err != nil {
+		return nil, err
+	}
+	tr := config.NewTransaction(st)
+
+	var buf bytes.Buffer
+	switch args[0] {
+	case "get":
+		if len(get.Positional.Keys) == 0 {
+			return nil, errors.New("get which option?")
+		}
+		if err := printValues(&buf, tr, instanceName, &get); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case "set":
+		if len(set.Positional.ConfValues) == 0 {
+			return nil, errors.New("set which option?")
+		}
+		if set.Typed && set.String {
+			return nil, fmt.Errorf("cannot use -t and -s together")
+		}
+		opts := &clientutil.ParseConfigOptions{String: set.String, Typed: set.Typed}
+		confValues, confKeys, err := clientutil.ParseConfigValues(set.Positional.ConfValues, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range confKeys {
+			if err := tr.Set(instanceName, key, confValues[key]); err != nil {
+				return nil, err
+			}
+		}
+	case "unset":
+		if len(unset.Positional.ConfKeys) == 0 {
+			return nil, errors.New("unset which option?")
+		}
+		for _, key := range unset.Positional.ConfKeys {
+			if err := tr.Set(instanceName, key, nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	tr.Commit()
+	if err := writeState(st); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func readState() (*state.State, error) {
+	f, err := os.Open(dirs.SnapStateFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open state: %v", err)
+	}
+	defer f.Close()
+	// changes are written back explicitly, see writeState
+	return state.ReadState(nil, f)
+}
+
+func writeState(st *state.State) error {
+	data, err := json.Marshal(st)
+	if err != nil {
+		return fmt.Errorf("cannot marshal state: %v", err)
+	}
+	if err := osutil.AtomicWriteFile(dirs.SnapStateFile, data, 0600, 0); err != nil {
+		return fmt.Errorf("cannot write state: %v", err)
+	}
+	return nil
+}
+
+func snapForContext(st *state.State, contextID string) (string, error) {
+	var cookies map[string]string
+	if err := st.Get("snap-cookies", &cookies); err != nil && !errors.Is(err, state.ErrNoState) {
+		return "", fmt.Errorf("cannot get snap cookies: %v", err)
+	}
+	instanceName, ok := cookies[contextID]
+	if !ok {
+		return "", fmt.Errorf("invalid snap cookie requested")
+	}
+	return instanceName, nil
+}
+
+// printValues prints the values of the requested options like "snapctl
+// get" does.
+func printValues(buf *bytes.Buffer, tr *config.Transaction, instanceName string, get *getCommand) error {
+	patch := make(map[string]any, len(get.Positional.Keys))
+	for _, key := range get.Positional.Keys {
+		var value any
+		err := tr.Get(instanceName, key, &value)
+		if config.IsNoOption(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		patch[key] = value
+	}
+
+	var confToPrint any = patch
+	if !get.Document && len(get.Positional.Keys) == 1 {
+		confToPrint = patch[get.Positional.Keys[0]]
+		if confToPrint == nil && !get.Typed {
+			confToPrint = ""
+		}
+	}
+	if get.Typed && confToPrint == nil {
+		buf.WriteString("null\n")
+		return nil
+	}
+	if s, ok := confToPrint.(string); ok && !get.Typed {
+		fmt.Fprintf(buf, "%s\n", s)
+		return nil
+	}
+	data, err := json.MarshalIndent(confToPrint, "", "\t")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(buf, "%s\n", data)
+	return nil
+}
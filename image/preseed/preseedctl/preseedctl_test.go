@@ -0,0 +1,168 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package preseedctl_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/image/preseed/preseedctl"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/testutil"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type preseedctlSuite struct {
+	testutil.BaseTest
+}
+
+var _ = Suite(&preseedctlSuite{})
+
+func (s *preseedctlSuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
+	dirs.SetRootDir(c.MkDir())
+	s.AddCleanup(func() { dirs.SetRootDir("/") })
+
+	st := state.New(nil)
+	st.Lock()
+	st.Set("snap-cookies", map[string]string{"cookie": "foo"})
+	tr := config.NewTransaction(st)
+	c.Assert(tr.Set("foo", "name", "frank"), IsNil)
+	c.Assert(tr.Set("foo", "port", 8080), IsNil)
+	c.Assert(tr.Set("bar", "name", "other"), IsNil)
+	tr.Commit()
+	st.Unlock()
+	s.writeState(c, st)
+}
+
+func (s *preseedctlSuite) writeState(c *C, st *state.State) {
+	st.Lock()
+	data, err := st.MarshalJSON()
+	st.Unlock()
+	c.Assert(err, IsNil)
+	c.Assert(os.MkdirAll(filepath.Dir(dirs.SnapStateFile), 0755), IsNil)
+	c.Assert(os.WriteFile(dirs.SnapStateFile, data, 0600), IsNil)
+}
+
+func (s *preseedctlSuite) readConfig(c *C, snapName, key string) any {
+	f, err := os.Open(dirs.SnapStateFile)
+	c.Assert(err, IsNil)
+	defer f.Close()
+	st, err := state.ReadState(nil, f)
+	c.Assert(err, IsNil)
+	st.Lock()
+	defer st.Unlock()
+	var value any
+	err = config.NewTransaction(st).Get(snapName, key, &value)
+	if config.IsNoOption(err) {
+		return nil
+	}
+	c.Assert(err, IsNil)
+	return value
+}
+
+func (s *preseedctlSuite) TestGet(c *C) {
+	for _, t := range []struct {
+		args   []string
+		stdout string
+	}{
+		{[]string{"get", "name"}, "frank\n"},
+		{[]string{"get", "-t", "name"}, "\"frank\"\n"},
+		{[]string{"get", "port"}, "8080\n"},
+		{[]string{"get", "missing"}, "\n"},
+		{[]string{"get", "-t", "missing"}, "null\n"},
+		{[]string{"get", "name", "port"}, "{\n\t\"name\": \"frank\",\n\t\"port\": 8080\n}\n"},
+		{[]string{"get", "-d", "name"}, "{\n\t\"name\": \"frank\"\n}\n"},
+	} {
+		stdout, err := preseedctl.Run("cookie", t.args)
+		c.Assert(err, IsNil, Commentf("%v", t.args))
+		c.Check(string(stdout), Equals, t.stdout, Commentf("%v", t.args))
+	}
+}
+
+func (s *preseedctlSuite) TestSetAndUnset(c *C) {
+	stdout, err := preseedctl.Run("cookie", []string{"set", "name=joe", "nested.key=[1,2]", "port!"})
+	c.Assert(err, IsNil)
+	c.Check(stdout, IsNil)
+	c.Check(s.readConfig(c, "foo", "name"), Equals, "joe")
+	c.Check(s.readConfig(c, "foo", "nested.key"), HasLen, 2)
+	c.Check(s.readConfig(c, "foo", "port"), IsNil)
+	// other snaps are untouched
+	c.Check(s.readConfig(c, "bar", "name"), Equals, "other")
+
+	_, err = preseedctl.Run("cookie", []string{"set", "-s", "port=8081"})
+	c.Assert(err, IsNil)
+	c.Check(s.readConfig(c, "foo", "port"), Equals, "8081")
+
+	_, err = preseedctl.Run("cookie", []string{"unset", "name", "nested"})
+	c.Assert(err, IsNil)
+	c.Check(s.readConfig(c, "foo", "name"), IsNil)
+	c.Check(s.readConfig(c, "foo", "nested"), IsNil)
+
+	st, err := os.Stat(dirs.SnapStateFile)
+	c.Assert(err, IsNil)
+	c.Check(st.Mode().Perm(), Equals, os.FileMode(0600))
+}
+
+func (s *preseedctlSuite) TestErrors(c *C) {
+	for _, t := range []struct {
+		args []string
+		err  string
+	}{
+		{nil, "internal error: snapctl cannot run without args"},
+		{[]string{"services"}, `cannot run "snapctl services" without snapd while preseeding`},
+		{[]string{"get"}, "get which option\\?"},
+		{[]string{"set"}, "set which option\\?"},
+		{[]string{"unset"}, "unset which option\\?"},
+		{[]string{"set", "-s", "-t", "a=1"}, "cannot use -t and -s together"},
+		{[]string{"set", "a"}, `invalid configuration: "a" \(want key=value\)`},
+		{[]string{"get", "--foo", "a"}, "unknown flag `foo'"},
+	} {
+		_, err := preseedctl.Run("cookie", t.args)
+		c.Check(err, ErrorMatches, t.err, Commentf("%v", t.args))
+	}
+
+	_, err := preseedctl.Run("unknown-cookie", []string{"get", "name"})
+	c.Check(err, ErrorMatches, "invalid snap cookie requested")
+}
+
+func (s *preseedctlSuite) TestSnapdRunning(c *C) {
+	flock, err := osutil.NewFileLockWithMode(dirs.SnapStateLockFile, 0644)
+	c.Assert(err, IsNil)
+	defer flock.Close()
+	c.Assert(flock.Lock(), IsNil)
+
+	_, err = preseedctl.Run("cookie", []string{"get", "name"})
+	c.Check(err, Equals, preseedctl.ErrSnapdRunning)
+}
+
+func (s *preseedctlSuite) TestNoState(c *C) {
+	c.Assert(os.Remove(dirs.SnapStateFile), IsNil)
+
+	_, err := preseedctl.Run("cookie", []string{"get", "name"})
+	c.Check(err, ErrorMatches, "cannot open state: .*")
+}
@@ -76,6 +76,8 @@ var (
 // Backend is responsible for maintaining apparmor profiles for snaps and parts of snapd.
 type Backend struct {
 	preseed bool
+	// preseedEmulated is set when preseeding under emulation
+	preseedEmulated bool
 
 	coreSnap  *snap.Info
 	snapdSnap *snap.Info
@@ -90,6 +92,7 @@ func (b *Backend) Name() interfaces.SecuritySystem {
 func (b *Backend) Initialize(opts *interfaces.SecurityBackendOptions) error {
 	if opts != nil && opts.Preseed {
 		b.preseed = true
+		b.preseedEmulated = opts.PreseedEmulated
 	}
 
 	if opts != nil {
@@ -163,7 +166,7 @@ func (b *Backend) initializeSnapConfineProfiles(preseed, reinitializing bool) er
 		aaFlags |= apparmor_sandbox.SkipKernelLoad
 	}
 
-	if err := loadProfiles([]string{profilePath}, apparmor_sandbox.SystemCacheDir, aaFlags); err != nil {
+	if err := b.compileAndLoad([]string{profilePath}, apparmor_sandbox.SystemCacheDir, aaFlags); err != nil {
 		// When we cannot reload the profile then let's remove the generated
 		// policy. Maybe we have caused the problem so it's better to let other
 		// things work.
@@ -173,6 +176,18 @@ func (b *Backend) initializeSnapConfineProfiles(preseed, reinitializing bool) er
 	return nil
 }
 
+// compileAndLoad compiles the given profiles into the cache and, unless
+// preseeding, loads them into the kernel. Nothing is compiled when
+// preseeding under emulation, as apparmor_parser runs very slowly emulated
+// and the profiles are compiled on the first boot of the target system.
+func (b *Backend) compileAndLoad(fnames []string, cacheDir string, flags apparmor_sandbox.AaParserFlags) error {
+	if b.preseedEmulated {
+		logger.Debugf("preseeding under emulation, not compiling %d apparmor profiles", len(fnames))
+		return nil
+	}
+	return loadProfiles(fnames, cacheDir, flags)
+}
+
 // Reinitialize performs a limited reinitialization of the backend. Implements
 // interfaces.ReinitializableSecurityBackend.
 func (b *Backend) Reinitialize() error {
@@ -318,7 +333,7 @@ func (b *Backend) setupSnapConfineReexec(info *snap.Info) error {
 	if b.preseed {
 		aaFlags = apparmor_sandbox.SkipKernelLoad
 	}
-	errReload := loadProfiles(pathnames, cache, aaFlags)
+	errReload := b.compileAndLoad(pathnames, cache, aaFlags)
 	errRemoveCached := removeCachedProfiles(removed, cache)
 	if errEnsure != nil {
 		return fmt.Errorf("cannot synchronize snap-confine apparmor profile: %s", errEnsure)
@@ -511,7 +526,7 @@ func (b *Backend) Setup(appSet *interfaces.SnapAppSet, opts interfaces.Confineme
 		aaFlags |= apparmor_sandbox.SkipKernelLoad
 	}
 	timings.Run(tm, "load-profiles[changed]", fmt.Sprintf("load changed security profiles of snap %q", snapInfo.InstanceName()), func(nesttm timings.Measurer) {
-		errReloadChanged = loadProfiles(prof.changed, apparmor_sandbox.CacheDir, aaFlags)
+		errReloadChanged = b.compileAndLoad(prof.changed, apparmor_sandbox.CacheDir, aaFlags)
 	})
 
 	// Load all unchanged profiles anyway. This ensures those are correct in
@@ -523,7 +538,7 @@ func (b *Backend) Setup(appSet *interfaces.SnapAppSet, opts interfaces.Confineme
 		aaFlags |= apparmor_sandbox.SkipKernelLoad
 	}
 	timings.Run(tm, "load-profiles[unchanged]", fmt.Sprintf("load unchanged security profiles of snap %q", snapInfo.InstanceName()), func(nesttm timings.Measurer) {
-		errReloadOther = loadProfiles(prof.unchanged, apparmor_sandbox.CacheDir, aaFlags)
+		errReloadOther = b.compileAndLoad(prof.unchanged, apparmor_sandbox.CacheDir, aaFlags)
 	})
 	errRemoveCached := removeCachedProfiles(prof.removed, apparmor_sandbox.CacheDir)
 	if errReloadChanged != nil {
@@ -564,7 +579,7 @@ func (b *Backend) SetupMany(appSets []*interfaces.SnapAppSet, confinement func(s
 		}
 		var errReloadChanged error
 		timings.Run(tm, "load-profiles[changed-many]", fmt.Sprintf("load changed security profiles of %d snaps", len(appSets)), func(nesttm timings.Measurer) {
-			errReloadChanged = loadProfiles(allChangedPaths, apparmor_sandbox.CacheDir, aaFlags)
+			errReloadChanged = b.compileAndLoad(allChangedPaths, apparmor_sandbox.CacheDir, aaFlags)
 		})
 
 		aaFlags = apparmor_sandbox.ConserveCPU
@@ -573,7 +588,7 @@ func (b *Backend) SetupMany(appSets []*interfaces.SnapAppSet, confinement func(s
 		}
 		var errReloadOther error
 		timings.Run(tm, "load-profiles[unchanged-many]", fmt.Sprintf("load unchanged security profiles %d snaps", len(appSets)), func(nesttm timings.Measurer) {
-			errReloadOther = loadProfiles(allUnchangedPaths, apparmor_sandbox.CacheDir, aaFlags)
+			errReloadOther = b.compileAndLoad(allUnchangedPaths, apparmor_sandbox.CacheDir, aaFlags)
 		})
 
 		errRemoveCached := removeCachedProfiles(allRemovedPaths, apparmor_sandbox.CacheDir)
@@ -3098,6 +3098,27 @@ func (s *backendSuite) TestInstallingSnapInPreseedMode(c *C) {
 	})
 }
 
+func (s *backendSuite) TestInstallingSnapInEmulatedPreseedMode(c *C) {
+	aa, ok := s.Backend.(*apparmor.Backend)
+	c.Assert(ok, Equals, true)
+
+	opts := interfaces.SecurityBackendOptions{
+		Preseed:         true,
+		PreseedEmulated: true,
+		CoreSnapInfo:    ifacetest.DefaultInitializeOpts.CoreSnapInfo,
+	}
+	c.Assert(aa.Initialize(&opts), IsNil)
+	s.loadProfilesCalls = nil
+
+	s.InstallSnap(c, interfaces.ConfinementOptions{}, "", ifacetest.SambaYamlV1, 1)
+
+	// the profile is written
+	profile := filepath.Join(dirs.SnapAppArmorDir, "snap.samba.smbd")
+	c.Check(profile, testutil.FilePresent)
+	// but not compiled, that is left to the first boot
+	c.Check(s.loadProfilesCalls, HasLen, 0)
+}
+
 func (s *backendSuite) TestSetupManyInPreseedMode(c *C) {
 	aa, ok := s.Backend.(*apparmor.Backend)
 	c.Assert(ok, Equals, true)
@@ -506,6 +506,7 @@ func (spec *Specification) emitLayout(si *snap.Info, layout *snap.Layout) {
 
 	emit("  # Layout %s\n", layout.String())
 	path := si.ExpandSnapVariables(layout.Path)
+	overlayMimic := layout.Mimic == "overlay"
 	switch {
 	case layout.Bind != "":
 		bind := si.ExpandSnapVariables(layout.Bind)
@@ -514,8 +515,8 @@ func (spec *Specification) emitLayout(si *snap.Info, layout *snap.Layout) {
 		emit("  mount options=(rprivate) -> \"%s/\",\n", path)
 		emit("  umount \"%s/\",\n", path)
 		// Allow constructing writable mimic in both bind-mount source and mount point.
-		GenWritableProfile(emit, path, 2) // At least / and /some-top-level-directory
-		GenWritableProfile(emit, bind, 4) // At least /, /snap/, /snap/$SNAP_NAME and /snap/$SNAP_NAME/$SNAP_REVISION
+		genWritableProfile(emit, path, 2, overlayMimic) // At least / and /some-top-level-directory
+		genWritableProfile(emit, bind, 4, overlayMimic) // At least /, /snap/, /snap/$SNAP_NAME and /snap/$SNAP_NAME/$SNAP_REVISION
 	case layout.BindFile != "":
 		bindFile := si.ExpandSnapVariables(layout.BindFile)
 		// Allow bind mounting the layout element.
@@ -523,18 +524,18 @@ func (spec *Specification) emitLayout(si *snap.Info, layout *snap.Layout) {
 		emit("  mount options=(rprivate) -> \"%s\",\n", path)
 		emit("  umount \"%s\",\n", path)
 		// Allow constructing writable mimic in both bind-mount source and mount point.
-		GenWritableFileProfile(emit, path, 2)     // At least / and /some-top-level-directory
-		GenWritableFileProfile(emit, bindFile, 4) // At least /, /snap/, /snap/$SNAP_NAME and /snap/$SNAP_NAME/$SNAP_REVISION
+		genWritableFileProfile(emit, path, 2, overlayMimic)     // At least / and /some-top-level-directory
+		genWritableFileProfile(emit, bindFile, 4, overlayMimic) // At least /, /snap/, /snap/$SNAP_NAME and /snap/$SNAP_NAME/$SNAP_REVISION
 	case layout.Type == "tmpfs":
 		emit("  mount fstype=tmpfs tmpfs -> \"%s/\",\n", path)
 		emit("  mount options=(rprivate) -> \"%s/\",\n", path)
 		emit("  umount \"%s/\",\n", path)
 		// Allow constructing writable mimic to mount point.
-		GenWritableProfile(emit, path, 2) // At least / and /some-top-level-directory
+		genWritableProfile(emit, path, 2, overlayMimic) // At least / and /some-top-level-directory
 	case layout.Symlink != "":
 		// Allow constructing writable mimic to symlink parent directory.
 		emit("  \"%s\" rw,\n", path)
-		GenWritableProfile(emit, path, 2) // At least / and /some-top-level-directory
+		genWritableProfile(emit, path, 2, overlayMimic) // At least / and /some-top-level-directory
 	}
 }
 
@@ -644,6 +645,13 @@ func isProbablyPresent(path string) bool {
 
 // GenWritableMimicProfile generates apparmor rules for a writable mimic at the given path.
 func GenWritableMimicProfile(emit func(f string, args ...any), path string, assumedPrefixDepth int) {
+	genWritableMimicProfile(emit, path, assumedPrefixDepth, false)
+}
+
+// genWritableMimicProfile generates apparmor rules for a writable mimic at
+// the given path, optionally allowing the mimic to be constructed with
+// overlayfs, for mount entries using x-snapd.mimic=overlay.
+func genWritableMimicProfile(emit func(f string, args ...any), path string, assumedPrefixDepth int, overlay bool) {
 	emit("  # Writable mimic %s\n", path)
 
 	iter, err := strutil.NewPathIterator(path)
@@ -680,6 +688,12 @@ func GenWritableMimicProfile(emit func(f string, args ...any), path string, assu
 		emit("  mount options=(rbind, rw) \"%s\" -> \"%s\",\n", mimicPath, mimicAuxPath)
 		emit("  # Allow mounting tmpfs over the read-only directory.\n")
 		emit("  mount fstype=tmpfs options=(rw) tmpfs -> \"%s\",\n", mimicPath)
+		if overlay {
+			emit("  # Or allow mounting an overlay over it, with the upper and work\n" +
+				"  # directories in a tmpfs mounted at the auxiliary directory.\n")
+			emit("  mount fstype=tmpfs options=(rw) tmpfs -> \"%s\",\n", mimicAuxPath)
+			emit("  mount fstype=overlay options=(rw) overlay -> \"%s\",\n", mimicPath)
+		}
 		emit("  # Allow creating empty files and directories for bind mounting things\n" +
 			"  # to reconstruct the now-writable parent directory.\n")
 		emit("  \"%s*/\" rw,\n", mimicAuxPath)
@@ -706,6 +720,10 @@ func GenWritableMimicProfile(emit func(f string, args ...any), path string, assu
 
 // GenWritableFileProfile writes a profile for snap-update-ns for making given file writable.
 func GenWritableFileProfile(emit func(f string, args ...any), path string, assumedPrefixDepth int) {
+	genWritableFileProfile(emit, path, assumedPrefixDepth, false)
+}
+
+func genWritableFileProfile(emit func(f string, args ...any), path string, assumedPrefixDepth int, overlayMimic bool) {
 	if path == "/" {
 		return
 	}
@@ -717,12 +735,16 @@ func GenWritableFileProfile(emit func(f string, args ...any), path string, assum
 		}
 	} else {
 		parentPath := parent(path)
-		GenWritableMimicProfile(emit, parentPath, assumedPrefixDepth)
+		genWritableMimicProfile(emit, parentPath, assumedPrefixDepth, overlayMimic)
 	}
 }
 
 // GenWritableProfile generates a profile for snap-update-ns for making given directory writable.
 func GenWritableProfile(emit func(f string, args ...any), path string, assumedPrefixDepth int) {
+	genWritableProfile(emit, path, assumedPrefixDepth, false)
+}
+
+func genWritableProfile(emit func(f string, args ...any), path string, assumedPrefixDepth int, overlayMimic bool) {
 	if path == "/" {
 		return
 	}
@@ -733,7 +755,7 @@ func GenWritableProfile(emit func(f string, args ...any), path string, assumedPr
 		}
 	} else {
 		parentPath := parent(path)
-		GenWritableMimicProfile(emit, parentPath, assumedPrefixDepth)
+		genWritableMimicProfile(emit, parentPath, assumedPrefixDepth, overlayMimic)
 	}
 }
 
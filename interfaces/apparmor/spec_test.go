@@ -587,6 +587,57 @@ func (s *specSuite) TestApparmorSnippetsFromLayout(c *C) {
 	c.Assert(strings.Join(updateNS, ""), DeepEquals, strings.Join([]string{profile0, profile1, profile2, profile3}, ""))
 }
 
+const snapWithOverlayMimicLayout = `
+name: vanguard
+version: 0
+apps:
+  vanguard:
+    command: vanguard
+layout:
+  /usr/foo:
+    bind: $SNAP/usr/foo
+    mimic: overlay
+`
+
+func (s *specSuite) TestApparmorSnippetsFromLayoutWithOverlayMimic(c *C) {
+	snapInfo := snaptest.MockInfo(c, snapWithOverlayMimicLayout, &snap.SideInfo{Revision: snap.R(42)})
+	restore := apparmor.SetSpecScope(s.spec, []string{"snap.vanguard.vanguard"})
+	defer restore()
+
+	appSet, err := interfaces.NewSnapAppSet(snapInfo, nil)
+	c.Assert(err, IsNil)
+
+	s.spec.AddLayout(appSet)
+
+	updateNS := strings.Join(s.spec.UpdateNS(), "")
+	// overlays may be mounted over the mimics of both the mount point and
+	// the bind mount source
+	c.Check(updateNS, testutil.Contains, `  # Or allow mounting an overlay over it, with the upper and work
+  # directories in a tmpfs mounted at the auxiliary directory.
+  mount fstype=tmpfs options=(rw) tmpfs -> "/tmp/.snap/usr/",
+  mount fstype=overlay options=(rw) overlay -> "/usr/",
+`)
+	c.Check(updateNS, testutil.Contains, `  mount fstype=tmpfs options=(rw) tmpfs -> "/tmp/.snap/snap/vanguard/42/usr/",
+  mount fstype=overlay options=(rw) overlay -> "/snap/vanguard/42/usr/",
+`)
+	// the tmpfs based mimic remains possible
+	c.Check(updateNS, testutil.Contains, `  mount fstype=tmpfs options=(rw) tmpfs -> "/usr/",
+`)
+}
+
+func (s *specSuite) TestApparmorSnippetsFromLayoutWithoutOverlayMimic(c *C) {
+	snapInfo := snaptest.MockInfo(c, snapWithLayout, &snap.SideInfo{Revision: snap.R(42)})
+	restore := apparmor.SetSpecScope(s.spec, []string{"snap.vanguard.vanguard"})
+	defer restore()
+
+	appSet, err := interfaces.NewSnapAppSet(snapInfo, nil)
+	c.Assert(err, IsNil)
+
+	s.spec.AddLayout(appSet)
+
+	c.Check(strings.Join(s.spec.UpdateNS(), ""), Not(testutil.Contains), "fstype=overlay")
+}
+
 const snapTrivial = `
 name: some-snap
 version: 0
@@ -84,6 +84,9 @@ type ConfinementOptions struct {
 type SecurityBackendOptions struct {
 	// Preseed flag is set when snapd runs in preseed mode.
 	Preseed bool
+	// PreseedEmulated is set when preseeding a system of a different
+	// architecture than the host, under emulation.
+	PreseedEmulated bool
 	// CoreSnapInfo is the current revision of the core snap (if it is
 	// installed)
 	CoreSnapInfo *snap.Info
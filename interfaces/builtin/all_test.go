@@ -494,3 +494,63 @@ func (s *AllSuite) TestDefinedConflictingConnectedInterfaces(c *C) {
 
 	c.Assert(found, DeepEquals, found)
 }
+
+// validRequiredAttrs gives valid values of the attributes that the builtin
+// interfaces document as required.
+var validRequiredAttrs = map[string]struct {
+	plug, slot map[string]any
+}{
+	"content": {plug: map[string]any{"target": "$SNAP/content"}},
+	"dbus": {
+		plug: map[string]any{"bus": "session", "name": "org.example.Foo"},
+		slot: map[string]any{"bus": "session", "name": "org.example.Foo"},
+	},
+	"gpio": {slot: map[string]any{"number": int64(5)}},
+	"spi":  {slot: map[string]any{"path": "/dev/spidev0.0"}},
+}
+
+func copyAttrsWithout(attrs map[string]any, without string) map[string]any {
+	m := make(map[string]any, len(attrs))
+	for k, v := range attrs {
+		if k != without {
+			m[k] = v
+		}
+	}
+	return m
+}
+
+func (s *AllSuite) TestRequiredAttrs(c *C) {
+	const plugSnapYaml = `name: consumer
+version: 0
+apps:
+  app:
+`
+	const slotSnapYaml = `name: producer
+version: 0
+type: gadget
+`
+	for _, iface := range builtin.Interfaces() {
+		si := interfaces.StaticInfoOf(iface)
+		if len(si.RequiredPlugAttrs) == 0 && len(si.RequiredSlotAttrs) == 0 {
+			continue
+		}
+		valid, ok := validRequiredAttrs[iface.Name()]
+		c.Assert(ok, Equals, true, Commentf("missing valid attributes of %s", iface.Name()))
+
+		plugInfo := snaptest.MockInfo(c, plugSnapYaml, nil)
+		for _, attr := range si.RequiredPlugAttrs {
+			plug := &snap.PlugInfo{Snap: plugInfo, Name: iface.Name(), Interface: iface.Name(), Attrs: copyAttrsWithout(valid.plug, "")}
+			c.Check(interfaces.BeforePreparePlug(iface, plug), IsNil, Commentf("%s plug", iface.Name()))
+			plug.Attrs = copyAttrsWithout(valid.plug, attr)
+			c.Check(interfaces.BeforePreparePlug(iface, plug), NotNil, Commentf("%s plug without %s", iface.Name(), attr))
+		}
+
+		slotInfo := snaptest.MockInfo(c, slotSnapYaml, nil)
+		for _, attr := range si.RequiredSlotAttrs {
+			slot := &snap.SlotInfo{Snap: slotInfo, Name: iface.Name(), Interface: iface.Name(), Attrs: copyAttrsWithout(valid.slot, "")}
+			c.Check(interfaces.BeforePrepareSlot(iface, slot), IsNil, Commentf("%s slot", iface.Name()))
+			slot.Attrs = copyAttrsWithout(valid.slot, attr)
+			c.Check(interfaces.BeforePrepareSlot(iface, slot), NotNil, Commentf("%s slot without %s", iface.Name(), attr))
+		}
+	}
+}
@@ -77,6 +77,7 @@ func init() {
 	registerIface(&commonInterface{
 		name:                  "camera",
 		summary:               cameraSummary,
+		consumers:             "video conferencing and photo applications",
 		implicitOnCore:        true,
 		implicitOnClassic:     true,
 		baseDeclarationSlots:  cameraBaseDeclarationSlots,
@@ -45,6 +45,9 @@ type commonInterface struct {
 	name    string
 	summary string
 	docURL  string
+	// consumers describes the kind of snaps that typically plug the
+	// interface
+	consumers string
 
 	implicitOnCore    bool
 	implicitOnClassic bool
@@ -117,6 +120,7 @@ func (iface *commonInterface) StaticInfo() interfaces.StaticInfo {
 		AffectsPlugOnRefresh:    iface.affectsPlugOnRefresh,
 		AppArmorUnconfinedPlugs: iface.appArmorUnconfinedPlugs,
 		AppArmorUnconfinedSlots: iface.appArmorUnconfinedSlots,
+		Consumers:               iface.consumers,
 	}
 }
 
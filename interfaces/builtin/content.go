@@ -34,6 +34,7 @@ import (
 	"github.com/snapcore/snapd/osutil"
 	apparmor_sandbox "github.com/snapcore/snapd/sandbox/apparmor"
 	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/channel"
 )
 
 const contentSummary = `allows sharing code and data with other snaps`
@@ -72,6 +73,8 @@ func (iface *contentInterface) StaticInfo() interfaces.StaticInfo {
 	return interfaces.StaticInfo{
 		Summary:              contentSummary,
 		BaseDeclarationSlots: contentBaseDeclarationSlots,
+		RequiredPlugAttrs:    []string{"target"},
+		Consumers:            "snaps sharing libraries, themes or data with other snaps",
 
 		AffectsPlugOnRefresh: true,
 	}
@@ -111,6 +114,21 @@ func checkLabelAttributes(attrs map[string]any, nameDef string) error {
 	if hasCompat && hasContent {
 		return errors.New("cannot have both content and compatibility labels")
 	}
+	if _, ok := attrs["version"]; ok {
+		if hasCompat {
+			return errors.New("cannot have both content version and compatibility label")
+		}
+		// YAML reads unquoted versions such as 2.3 as numbers, which
+		// would lose their meaning: 3.0 would become 3, meaning any 3.x,
+		// and 2.10 would become 2.1, so they must be quoted
+		version, ok := attrs["version"].(string)
+		if !ok {
+			return fmt.Errorf(`content version must be a quoted string, e.g. "2.x" or "2.3", not %v`, attrs["version"])
+		}
+		if _, err := compatibility.ParseVersionRange(version); err != nil {
+			return fmt.Errorf("invalid content version: %v", err)
+		}
+	}
 	if hasCompat {
 		return compatibility.IsValidExpression(compat, nil)
 	}
@@ -174,6 +192,31 @@ func (iface *contentInterface) BeforePreparePlug(plug *snap.PlugInfo) error {
 		return err
 	}
 
+	if err := validateDefaultProviderTrack(plug.Attrs); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateDefaultProviderTrack checks the optional track of the default
+// provider which should be installed if the provider is missing.
+func validateDefaultProviderTrack(attrs map[string]any) error {
+	trackAttr, ok := attrs["default-provider-track"]
+	if !ok {
+		return nil
+	}
+	track, ok := trackAttr.(string)
+	if !ok || track == "" {
+		return fmt.Errorf("content default-provider-track must be a non-empty string")
+	}
+	if provider, _ := attrs["default-provider"].(string); provider == "" {
+		return fmt.Errorf("content default-provider-track requires a default-provider")
+	}
+	ch, err := channel.ParseVerbatim(track, "-")
+	if err != nil || !ch.VerbatimTrackOnly() {
+		return fmt.Errorf("invalid content default-provider-track %q: must be a track name", track)
+	}
 	return nil
 }
 
@@ -347,9 +390,32 @@ func (iface *contentInterface) AppArmorConnectedSlot(spec *apparmor.Specificatio
 	return nil
 }
 
+// contentVersionsOverlap checks that the content versions accepted by the
+// plug and the ones provided by the slot overlap. Versions are optional, a
+// side without a version is taken to accept or provide any version.
+func contentVersionsOverlap(plug, slot interfaces.Attrer) error {
+	var plugVersion, slotVersion string
+	// the versions have been validated in BeforePreparePlug/Slot
+	_ = plug.Attr("version", &plugVersion)
+	_ = slot.Attr("version", &slotVersion)
+	overlap, err := compatibility.VersionRangesOverlap(plugVersion, slotVersion)
+	if err != nil {
+		return err
+	}
+	if !overlap {
+		return fmt.Errorf("content versions do not overlap: plug accepts %q, slot provides %q", plugVersion, slotVersion)
+	}
+	return nil
+}
+
+func (iface *contentInterface) BeforeConnect(plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	return contentVersionsOverlap(plug, slot)
+}
+
 func (iface *contentInterface) AutoConnect(plug *snap.PlugInfo, slot *snap.SlotInfo) bool {
-	// allow what declarations allowed
-	return true
+	// allow what declarations allowed, as long as the content versions
+	// can be negotiated
+	return contentVersionsOverlap(plug, slot) == nil
 }
 
 // Interactions with the mount backend.
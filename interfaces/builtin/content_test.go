@@ -1156,6 +1156,150 @@ slots:
 	c.Assert(apparmorSpec.SnippetForTag("snap.app.app"), Equals, expected)
 }
 
+func (s *ContentSuite) TestSanitizePlugContentVersion(c *C) {
+	const mockSnapYaml = `name: content-plug-snap
+version: 1.0
+plugs:
+ content-plug:
+  interface: content
+  content: mycont
+  version: "2.1-2.x"
+  target: import
+`
+	info := snaptest.MockInfo(c, mockSnapYaml, nil)
+	plug := info.Plugs["content-plug"]
+	c.Assert(interfaces.BeforePreparePlug(s.iface, plug), IsNil)
+}
+
+func (s *ContentSuite) TestSanitizeContentVersionErrors(c *C) {
+	for _, tc := range []struct {
+		attrs string
+		err   string
+	}{
+		{"content: mycont\n  version: 2", `content version must be a quoted string, e.g. "2.x" or "2.3", not 2`},
+		{"content: mycont\n  version: 2.3", `content version must be a quoted string, e.g. "2.x" or "2.3", not 2.3`},
+		{"content: mycont\n  version: 3.0", `content version must be a quoted string, e.g. "2.x" or "2.3", not 3`},
+		{"content: mycont\n  version: foo", `invalid content version: version range "foo": invalid version number "foo"`},
+		{"content: mycont\n  version: 3-2", `invalid content version: version range "3-2": lower bound is greater than upper bound`},
+		{"compatibility: foo-1\n  version: 2.x", `cannot have both content version and compatibility label`},
+	} {
+		plugYaml := fmt.Sprintf(`name: content-plug-snap
+version: 1.0
+plugs:
+ content-plug:
+  interface: content
+  %s
+  target: import
+`, tc.attrs)
+		info := snaptest.MockInfo(c, plugYaml, nil)
+		c.Check(interfaces.BeforePreparePlug(s.iface, info.Plugs["content-plug"]), ErrorMatches, tc.err, Commentf("%s", tc.attrs))
+
+		slotYaml := fmt.Sprintf(`name: content-slot-snap
+version: 1.0
+slots:
+ content-slot:
+  interface: content
+  %s
+  read:
+   - shared/read
+`, tc.attrs)
+		info = snaptest.MockInfo(c, slotYaml, nil)
+		c.Check(interfaces.BeforePrepareSlot(s.iface, info.Slots["content-slot"]), ErrorMatches, tc.err, Commentf("%s", tc.attrs))
+	}
+}
+
+func (s *ContentSuite) TestSanitizePlugDefaultProviderTrack(c *C) {
+	const mockSnapYaml = `name: content-plug-snap
+version: 1.0
+plugs:
+ content-plug:
+  interface: content
+  content: mycont
+  default-provider: provider
+  default-provider-track: "2"
+  target: import
+`
+	info := snaptest.MockInfo(c, mockSnapYaml, nil)
+	plug := info.Plugs["content-plug"]
+	c.Assert(interfaces.BeforePreparePlug(s.iface, plug), IsNil)
+}
+
+func (s *ContentSuite) TestSanitizePlugDefaultProviderTrackErrors(c *C) {
+	for _, tc := range []struct {
+		attrs string
+		err   string
+	}{
+		{"default-provider: provider\n  default-provider-track: 2", `content default-provider-track must be a non-empty string`},
+		{"default-provider: provider\n  default-provider-track: \"\"", `content default-provider-track must be a non-empty string`},
+		{"default-provider-track: \"2\"", `content default-provider-track requires a default-provider`},
+		{"default-provider: provider\n  default-provider-track: 2/stable", `invalid content default-provider-track "2/stable": must be a track name`},
+		{"default-provider: provider\n  default-provider-track: edge", `invalid content default-provider-track "edge": must be a track name`},
+	} {
+		plugYaml := fmt.Sprintf(`name: content-plug-snap
+version: 1.0
+plugs:
+ content-plug:
+  interface: content
+  content: mycont
+  %s
+  target: import
+`, tc.attrs)
+		info := snaptest.MockInfo(c, plugYaml, nil)
+		c.Check(interfaces.BeforePreparePlug(s.iface, info.Plugs["content-plug"]), ErrorMatches, tc.err, Commentf("%s", tc.attrs))
+	}
+}
+
+func (s *ContentSuite) TestContentVersionNegotiation(c *C) {
+	const consumerYaml = `name: consumer
+version: 0
+plugs:
+ content:
+  interface: content
+  content: mycont
+  %s
+  target: $SNAP/import
+`
+	const producerYaml = `name: producer
+version: 0
+slots:
+ content:
+  interface: content
+  content: mycont
+  %s
+  read:
+   - $SNAP/export
+`
+	iface := s.iface.(interface {
+		BeforeConnect(*interfaces.ConnectedPlug, *interfaces.ConnectedSlot) error
+	})
+	for _, tc := range []struct {
+		plugVersion, slotVersion string
+		err                      string
+	}{
+		{"", "", ""},
+		{`version: "2.x"`, "", ""},
+		{"", `version: "2.3"`, ""},
+		{`version: "2.x"`, `version: "2.3"`, ""},
+		{`version: "2.1-3.x"`, `version: "3.0"`, ""},
+		{`version: "2.x"`, `version: "3.0"`, `content versions do not overlap: plug accepts "2.x", slot provides "3.0"`},
+		{`version: "2.4-2.x"`, `version: "2.3"`, `content versions do not overlap: plug accepts "2.4-2.x", slot provides "2.3"`},
+	} {
+		comment := Commentf("%q %q", tc.plugVersion, tc.slotVersion)
+		plug, plugInfo := MockConnectedPlug(c, fmt.Sprintf(consumerYaml, tc.plugVersion), nil, "content")
+		slot, slotInfo := MockConnectedSlot(c, fmt.Sprintf(producerYaml, tc.slotVersion), nil, "content")
+		c.Assert(interfaces.BeforePreparePlug(s.iface, plugInfo), IsNil, comment)
+		c.Assert(interfaces.BeforePrepareSlot(s.iface, slotInfo), IsNil, comment)
+
+		err := iface.BeforeConnect(plug, slot)
+		if tc.err == "" {
+			c.Check(err, IsNil, comment)
+		} else {
+			c.Check(err, ErrorMatches, tc.err, comment)
+		}
+		c.Check(s.iface.AutoConnect(plugInfo, slotInfo), Equals, tc.err == "", comment)
+	}
+}
+
 func (s *ContentSuite) TestContentInterfaceCompatibilityLabelPlugins(c *C) {
 	// Define one app snap and two snaps plugin snaps.
 	const consumerYaml = `name: app
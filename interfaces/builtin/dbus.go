@@ -228,6 +228,9 @@ func (iface *dbusInterface) StaticInfo() interfaces.StaticInfo {
 	return interfaces.StaticInfo{
 		Summary:              dbusSummary,
 		BaseDeclarationSlots: dbusBaseDeclarationSlots,
+		RequiredPlugAttrs:    []string{"bus", "name"},
+		RequiredSlotAttrs:    []string{"bus", "name"},
+		Consumers:            "snaps providing or talking to a well-known D-Bus name",
 	}
 }
 
@@ -387,13 +390,32 @@ func (iface *dbusInterface) AppArmorPermanentSlot(spec *apparmor.Specification,
 	snippet = strings.Replace(snippet, old, new, -1)
 	spec.AddSnippet(snippet)
 
-	if release.OnClassic {
-		// classic-only policy
+	// On classic, respond to unconfined clients. The same is done for
+	// session bus names activated by user daemons, as the session bus is
+	// private to the user and its unconfined peers are that user's
+	// processes.
+	if release.OnClassic || (bus == "session" && activatedByUserDaemon(slot)) {
 		spec.AddSnippet(getAppArmorSnippet(dbusPermanentSlotAppArmorClassic, bus, name))
 	}
 	return nil
 }
 
+// activatedByUserDaemon returns true if the slot is listed in the
+// activates-on of a user daemon.
+func activatedByUserDaemon(slot *snap.SlotInfo) bool {
+	for _, app := range slot.Snap.Apps {
+		if app.DaemonScope != snap.UserDaemon {
+			continue
+		}
+		for _, activatesOn := range app.ActivatesOn {
+			if activatesOn == slot {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (iface *dbusInterface) SecCompPermanentSlot(spec *seccomp.Specification, slot *snap.SlotInfo) error {
 	spec.AddSnippet(dbusPermanentSlotSecComp)
 	return nil
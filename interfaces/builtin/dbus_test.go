@@ -341,6 +341,66 @@ func (s *DbusInterfaceSuite) TestPermanentSlotAppArmorSessionClassic(c *C) {
 	c.Check(apparmorSpec.SnippetForTag("snap.test-dbus.test-session-provider"), testutil.Contains, "# allow us to respond to unconfined clients via \"org.test-session-slot{,.*}\"\n")
 }
 
+func (s *DbusInterfaceSuite) TestPermanentSlotAppArmorSessionUserDaemonNative(c *C) {
+	restore := release.MockOnClassic(false)
+	defer restore()
+
+	info := snaptest.MockInfo(c, `
+name: test-dbus
+version: 0
+slots:
+  dbus-slot:
+    interface: dbus
+    bus: session
+    name: org.test-session-slot
+apps:
+  user-daemon:
+    daemon: simple
+    daemon-scope: user
+    activates-on: [dbus-slot]
+`, nil)
+	slot := info.Slots["dbus-slot"]
+	appSet, err := interfaces.NewSnapAppSet(info, nil)
+	c.Assert(err, IsNil)
+
+	apparmorSpec := apparmor.NewSpecification(appSet)
+	err = apparmorSpec.AddPermanentSlot(s.iface, slot)
+	c.Assert(err, IsNil)
+	c.Assert(apparmorSpec.SecurityTags(), DeepEquals, []string{"snap.test-dbus.user-daemon"})
+
+	// verify unconfined clients rule present for user daemons
+	c.Check(apparmorSpec.SnippetForTag("snap.test-dbus.user-daemon"), testutil.Contains, "# allow us to respond to unconfined clients via \"org.test-session-slot{,.*}\"\n")
+}
+
+func (s *DbusInterfaceSuite) TestPermanentSlotAppArmorSystemDaemonNative(c *C) {
+	restore := release.MockOnClassic(false)
+	defer restore()
+
+	info := snaptest.MockInfo(c, `
+name: test-dbus
+version: 0
+slots:
+  dbus-slot:
+    interface: dbus
+    bus: system
+    name: org.test-system-slot
+apps:
+  daemon:
+    daemon: simple
+    activates-on: [dbus-slot]
+`, nil)
+	slot := info.Slots["dbus-slot"]
+	appSet, err := interfaces.NewSnapAppSet(info, nil)
+	c.Assert(err, IsNil)
+
+	apparmorSpec := apparmor.NewSpecification(appSet)
+	err = apparmorSpec.AddPermanentSlot(s.iface, slot)
+	c.Assert(err, IsNil)
+
+	// verify unconfined clients rule not present for system daemons
+	c.Check(apparmorSpec.SnippetForTag("snap.test-dbus.daemon"), Not(testutil.Contains), "# allow us to respond to unconfined clients")
+}
+
 func (s *DbusInterfaceSuite) TestPermanentSlotAppArmorSystem(c *C) {
 	apparmorSpec := apparmor.NewSpecification(s.systemSlot.AppSet())
 	err := apparmorSpec.AddPermanentSlot(s.iface, s.systemSlotInfo)
@@ -158,6 +158,10 @@ func MockDesktopFilesFromInstalledSnap(fn func(s *snap.Info) ([]string, error))
 	return testutil.Mock(&desktopFilesFromInstalledSnap, fn)
 }
 
+func MockSystemBackupTargetDir(fn func() (string, error)) (restore func()) {
+	return testutil.Mock(&systemBackupTargetDir, fn)
+}
+
 func MockGpioCheckConfigfsSupport(fn func() error) (restore func()) {
 	return testutil.Mock(&gpioCheckConfigfsSupport, fn)
 }
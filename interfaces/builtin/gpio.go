@@ -60,6 +60,8 @@ func (iface *gpioInterface) StaticInfo() interfaces.StaticInfo {
 	return interfaces.StaticInfo{
 		Summary:              gpioSummary,
 		BaseDeclarationSlots: gpioBaseDeclarationSlots,
+		RequiredSlotAttrs:    []string{"number"},
+		Consumers:            "applications driving hardware through GPIO lines",
 	}
 }
 
@@ -0,0 +1,84 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin
+
+const gpuControlSummary = `allows managing GPU frequency and power profiles`
+
+// Forcing GPU clocks and power limits affects the whole system and, with
+// overclocking, the hardware itself, so treat as super-privileged
+const gpuControlBaseDeclarationPlugs = `
+  gpu-control:
+    allow-installation: false
+    deny-auto-connection: true
+`
+
+const gpuControlBaseDeclarationSlots = `
+  gpu-control:
+    allow-installation:
+      slot-snap-type:
+        - core
+    deny-auto-connection: true
+`
+
+// The /sys/class/drm/card*/device symlinks point to the PCI device of the
+// GPU, apparmor needs the symlink targets.
+const gpuControlConnectedPlugAppArmor = `
+# Description: Allow managing GPU frequency and power profiles. This affects
+# the performance and the power usage of the whole system.
+
+/sys/class/drm/ r,
+/sys/devices/pci[0-9a-f]*/**/drm/card[0-9]*/{,**} r,
+
+# amdgpu, see https://docs.kernel.org/gpu/amdgpu/thermal.html
+/sys/devices/pci[0-9a-f]*/**/power_dpm_force_performance_level rw,
+/sys/devices/pci[0-9a-f]*/**/power_dpm_state rw,
+/sys/devices/pci[0-9a-f]*/**/pp_power_profile_mode rw,
+/sys/devices/pci[0-9a-f]*/**/pp_od_clk_voltage rw,
+/sys/devices/pci[0-9a-f]*/**/pp_dpm_{sclk,mclk,socclk,fclk,dcefclk,pcie} rw,
+/sys/devices/pci[0-9a-f]*/**/hwmon/hwmon[0-9]*/power[0-9]*_cap rw,
+
+# i915, see https://docs.kernel.org/gpu/i915.html
+/sys/devices/pci[0-9a-f]*/**/drm/card[0-9]*/gt_{min,max,boost}_freq_mhz rw,
+/sys/devices/pci[0-9a-f]*/**/drm/card[0-9]*/gt/gt[0-9]*/rps_{min,max,boost}_freq_mhz rw,
+
+# nvidia, as used by nvidia-smi
+/dev/nvidiactl rw,
+/dev/nvidia[0-9]* rw,
+@{PROC}/driver/nvidia/{,**} r,
+`
+
+var gpuControlConnectedPlugUDev = []string{
+	`KERNEL=="nvidiactl"`,
+	`KERNEL=="nvidia[0-9]*"`,
+}
+
+func init() {
+	registerIface(&commonInterface{
+		name:                  "gpu-control",
+		summary:               gpuControlSummary,
+		consumers:             "device management snaps tuning the performance of GPUs",
+		implicitOnCore:        true,
+		implicitOnClassic:     true,
+		baseDeclarationPlugs:  gpuControlBaseDeclarationPlugs,
+		baseDeclarationSlots:  gpuControlBaseDeclarationSlots,
+		connectedPlugAppArmor: gpuControlConnectedPlugAppArmor,
+		connectedPlugUDev:     gpuControlConnectedPlugUDev,
+	})
+}
@@ -0,0 +1,119 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin_test
+
+import (
+	"fmt"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/udev"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type GpuControlInterfaceSuite struct {
+	iface    interfaces.Interface
+	slotInfo *snap.SlotInfo
+	slot     *interfaces.ConnectedSlot
+	plugInfo *snap.PlugInfo
+	plug     *interfaces.ConnectedPlug
+}
+
+var _ = Suite(&GpuControlInterfaceSuite{
+	iface: builtin.MustInterface("gpu-control"),
+})
+
+const gpuControlConsumerYaml = `name: consumer
+version: 0
+apps:
+ app:
+  plugs: [gpu-control]
+`
+
+const gpuControlCoreYaml = `name: core
+version: 0
+type: os
+slots:
+  gpu-control:
+`
+
+func (s *GpuControlInterfaceSuite) SetUpTest(c *C) {
+	s.plug, s.plugInfo = MockConnectedPlug(c, gpuControlConsumerYaml, nil, "gpu-control")
+	s.slot, s.slotInfo = MockConnectedSlot(c, gpuControlCoreYaml, nil, "gpu-control")
+}
+
+func (s *GpuControlInterfaceSuite) TestName(c *C) {
+	c.Assert(s.iface.Name(), Equals, "gpu-control")
+}
+
+func (s *GpuControlInterfaceSuite) TestSanitizeSlot(c *C) {
+	c.Assert(interfaces.BeforePrepareSlot(s.iface, s.slotInfo), IsNil)
+}
+
+func (s *GpuControlInterfaceSuite) TestSanitizePlug(c *C) {
+	c.Assert(interfaces.BeforePreparePlug(s.iface, s.plugInfo), IsNil)
+}
+
+func (s *GpuControlInterfaceSuite) TestAppArmorSpec(c *C) {
+	spec := apparmor.NewSpecification(s.plug.AppSet())
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, s.slot), IsNil)
+	c.Assert(spec.SecurityTags(), DeepEquals, []string{"snap.consumer.app"})
+	snippet := spec.SnippetForTag("snap.consumer.app")
+	// amdgpu
+	c.Check(snippet, testutil.Contains, "/sys/devices/pci[0-9a-f]*/**/power_dpm_force_performance_level rw,\n")
+	// i915
+	c.Check(snippet, testutil.Contains, "/sys/devices/pci[0-9a-f]*/**/drm/card[0-9]*/gt_{min,max,boost}_freq_mhz rw,\n")
+	// nvidia
+	c.Check(snippet, testutil.Contains, "/dev/nvidiactl rw,\n")
+	c.Check(snippet, testutil.Contains, "/dev/nvidia[0-9]* rw,\n")
+}
+
+func (s *GpuControlInterfaceSuite) TestUDevSpec(c *C) {
+	spec := udev.NewSpecification(s.plug.AppSet())
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, s.slot), IsNil)
+	c.Assert(spec.Snippets(), HasLen, 3)
+	c.Check(spec.Snippets(), testutil.Contains, `# gpu-control
+KERNEL=="nvidiactl", TAG+="snap_consumer_app"`)
+	c.Check(spec.Snippets(), testutil.Contains, `# gpu-control
+KERNEL=="nvidia[0-9]*", TAG+="snap_consumer_app"`)
+	c.Check(spec.Snippets(), testutil.Contains, fmt.Sprintf(`TAG=="snap_consumer_app", SUBSYSTEM!="module", SUBSYSTEM!="subsystem", RUN+="%v/snap-device-helper $env{ACTION} snap_consumer_app $devpath $major:$minor"`, dirs.DistroLibExecDir))
+}
+
+func (s *GpuControlInterfaceSuite) TestStaticInfo(c *C) {
+	si := interfaces.StaticInfoOf(s.iface)
+	c.Assert(si.ImplicitOnCore, Equals, true)
+	c.Assert(si.ImplicitOnClassic, Equals, true)
+	c.Assert(si.Summary, Equals, `allows managing GPU frequency and power profiles`)
+	c.Assert(si.BaseDeclarationPlugs, testutil.Contains, "gpu-control")
+	c.Assert(si.BaseDeclarationSlots, testutil.Contains, "gpu-control")
+}
+
+func (s *GpuControlInterfaceSuite) TestAutoConnect(c *C) {
+	c.Assert(s.iface.AutoConnect(s.plugInfo, s.slotInfo), Equals, true)
+}
+
+func (s *GpuControlInterfaceSuite) TestInterfaces(c *C) {
+	c.Check(builtin.Interfaces(), testutil.DeepContains, s.iface)
+}
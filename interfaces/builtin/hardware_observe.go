@@ -19,6 +19,15 @@
 
 package builtin
 
+import (
+	"fmt"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/udev"
+	"github.com/snapcore/snapd/snap"
+)
+
 const hardwareObserveSummary = `allows reading information about system hardware`
 
 const hardwareObserveBaseDeclarationSlots = `
@@ -155,8 +164,117 @@ socket AF_NETLINK - NETLINK_KOBJECT_UEVENT
 bind
 `
 
+const hardwareObserveDMITablesConnectedPlugAppArmor = `
+# Added due to "dmi-tables" scope in hardware-observe plug.
+# Raw SMBIOS/DMI tables, these contain serial numbers and asset tags of the
+# system and its components.
+/sys/firmware/dmi/tables/{,*} r,
+/sys/firmware/efi/systab r,
+/{,usr/}sbin/dmidecode ixr,
+`
+
+const hardwareObserveHwmonConnectedPlugAppArmor = `
+# Added due to "hwmon" scope in hardware-observe plug.
+# All the attributes of hardware monitoring chips, including the status
+# registers that PMBus chips only expose in debugfs.
+/sys/class/hwmon/{,**} r,
+/sys/devices/**/hwmon/{,**} r,
+/sys/kernel/debug/pmbus/{,**} r,
+/{,usr/}bin/sensors ixr,
+`
+
+const hardwareObserveNVMeSMARTConnectedPlugAppArmor = `
+# Added due to "nvme-smart" scope in hardware-observe plug.
+# NVMe controller character devices, to get the SMART/health information log
+# with the admin command ioctl (which needs CAP_SYS_ADMIN, granted above).
+# Namespace block devices are not included, these are granted by the
+# block-devices interface.
+/dev/nvme{[0-9],[1-9][0-9]} r,                 # NVMe (up to 100 devices)
+/sys/class/nvme/{,**} r,
+/{,usr/}sbin/nvme ixr,
+/{,usr/}sbin/smartctl ixr,
+`
+
+type hardwareObserveScope struct {
+	AppArmor string
+	UDev     []string
+}
+
+var hardwareObserveScopes = map[string]hardwareObserveScope{
+	"dmi-tables": {
+		AppArmor: hardwareObserveDMITablesConnectedPlugAppArmor,
+	},
+	"hwmon": {
+		AppArmor: hardwareObserveHwmonConnectedPlugAppArmor,
+	},
+	"nvme-smart": {
+		AppArmor: hardwareObserveNVMeSMARTConnectedPlugAppArmor,
+		UDev: []string{
+			`SUBSYSTEM=="nvme", KERNEL=="nvme[0-9]*"`,
+		},
+	},
+}
+
+// hardwareObserveInterface grants, beyond the default rules, the extra
+// access selected with the optional "scopes" plug attribute.
+type hardwareObserveInterface struct {
+	commonInterface
+}
+
+func (iface *hardwareObserveInterface) BeforePreparePlug(plug *snap.PlugInfo) error {
+	attrVal, exists := plug.Lookup("scopes")
+	if !exists {
+		return nil
+	}
+
+	scopes, ok := attrVal.([]any)
+	if !ok {
+		return fmt.Errorf(`hardware-observe "scopes" attribute must be a list of strings`)
+	}
+	for _, entry := range scopes {
+		name, ok := entry.(string)
+		if !ok {
+			return fmt.Errorf(`hardware-observe "scopes" attribute must be a list of strings`)
+		}
+		if _, valid := hardwareObserveScopes[name]; !valid {
+			return fmt.Errorf(`hardware-observe plug has invalid scope %q in "scopes" attribute`, name)
+		}
+	}
+	return nil
+}
+
+func (iface *hardwareObserveInterface) AppArmorConnectedPlug(spec *apparmor.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	if err := iface.commonInterface.AppArmorConnectedPlug(spec, plug, slot); err != nil {
+		return err
+	}
+
+	var scopes []string
+	// validated in BeforePreparePlug
+	_ = plug.Attr("scopes", &scopes)
+	for _, name := range scopes {
+		spec.AddSnippet(hardwareObserveScopes[name].AppArmor)
+	}
+	return nil
+}
+
+func (iface *hardwareObserveInterface) UDevConnectedPlug(spec *udev.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	if err := iface.commonInterface.UDevConnectedPlug(spec, plug, slot); err != nil {
+		return err
+	}
+
+	var scopes []string
+	// validated in BeforePreparePlug
+	_ = plug.Attr("scopes", &scopes)
+	for _, name := range scopes {
+		for _, rule := range hardwareObserveScopes[name].UDev {
+			spec.TagDevice(rule)
+		}
+	}
+	return nil
+}
+
 func init() {
-	registerIface(&commonInterface{
+	registerIface(&hardwareObserveInterface{commonInterface{
 		name:                  "hardware-observe",
 		summary:               hardwareObserveSummary,
 		implicitOnCore:        true,
@@ -164,5 +282,5 @@ func init() {
 		baseDeclarationSlots:  hardwareObserveBaseDeclarationSlots,
 		connectedPlugAppArmor: hardwareObserveConnectedPlugAppArmor,
 		connectedPlugSecComp:  hardwareObserveConnectedPlugSecComp,
-	})
+	}})
 }
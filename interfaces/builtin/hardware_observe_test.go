@@ -20,12 +20,16 @@
 package builtin_test
 
 import (
+	"fmt"
+
 	. "gopkg.in/check.v1"
 
+	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/apparmor"
 	"github.com/snapcore/snapd/interfaces/builtin"
 	"github.com/snapcore/snapd/interfaces/seccomp"
+	"github.com/snapcore/snapd/interfaces/udev"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/testutil"
 )
@@ -45,6 +49,17 @@ apps:
   command: foo
   plugs: [hardware-observe]
 `
+const hwobserveWithScopesMockPlugSnapInfoYaml = `name: other
+version: 1.0
+apps:
+ app2:
+  command: foo
+  plugs: [hardware-observe]
+plugs:
+ hardware-observe:
+  scopes: [dmi-tables, hwmon, nvme-smart]
+`
+
 const hwobserveMockSlotSnapInfoYaml = `name: core
 version: 1.0
 type: os
@@ -74,6 +89,34 @@ func (s *HardwareObserveInterfaceSuite) TestSanitizePlug(c *C) {
 	c.Assert(interfaces.BeforePreparePlug(s.iface, s.plugInfo), IsNil)
 }
 
+func (s *HardwareObserveInterfaceSuite) TestSanitizePlugWithScopes(c *C) {
+	_, plugInfo := MockConnectedPlug(c, hwobserveWithScopesMockPlugSnapInfoYaml, nil, "hardware-observe")
+	c.Assert(interfaces.BeforePreparePlug(s.iface, plugInfo), IsNil)
+}
+
+func (s *HardwareObserveInterfaceSuite) TestSanitizePlugWithInvalidScopes(c *C) {
+	const mockSnapYamlTemplate = `name: other
+version: 1.0
+apps:
+ app2:
+  plugs: [hardware-observe]
+plugs:
+ hardware-observe:
+  scopes: %s
+`
+	for _, t := range []struct {
+		scopes string
+		err    string
+	}{
+		{`dmi-tables`, `hardware-observe "scopes" attribute must be a list of strings`},
+		{`[1, 2]`, `hardware-observe "scopes" attribute must be a list of strings`},
+		{`[hwmon, kernel-memory]`, `hardware-observe plug has invalid scope "kernel-memory" in "scopes" attribute`},
+	} {
+		_, plugInfo := MockConnectedPlug(c, fmt.Sprintf(mockSnapYamlTemplate, t.scopes), nil, "hardware-observe")
+		c.Check(interfaces.BeforePreparePlug(s.iface, plugInfo), ErrorMatches, t.err, Commentf("scopes: %s", t.scopes))
+	}
+}
+
 func (s *HardwareObserveInterfaceSuite) TestUsedSecuritySystems(c *C) {
 	// connected plugs have a non-nil security snippet for apparmor
 	apparmorSpec := apparmor.NewSpecification(s.plug.AppSet())
@@ -95,3 +138,41 @@ func (s *HardwareObserveInterfaceSuite) TestUsedSecuritySystems(c *C) {
 func (s *HardwareObserveInterfaceSuite) TestInterfaces(c *C) {
 	c.Check(builtin.Interfaces(), testutil.DeepContains, s.iface)
 }
+
+func (s *HardwareObserveInterfaceSuite) TestAppArmorConnectedPlugDefaultScopes(c *C) {
+	apparmorSpec := apparmor.NewSpecification(s.plug.AppSet())
+	c.Assert(apparmorSpec.AddConnectedPlug(s.iface, s.plug, s.slot), IsNil)
+	snippet := apparmorSpec.SnippetForTag("snap.other.app2")
+	c.Check(snippet, Not(testutil.Contains), "/sys/firmware/dmi/tables/{,*} r,\n")
+	c.Check(snippet, Not(testutil.Contains), "/sys/kernel/debug/pmbus/{,**} r,\n")
+	c.Check(snippet, Not(testutil.Contains), "/dev/nvme")
+}
+
+func (s *HardwareObserveInterfaceSuite) TestAppArmorConnectedPlugWithScopes(c *C) {
+	plug, _ := MockConnectedPlug(c, hwobserveWithScopesMockPlugSnapInfoYaml, nil, "hardware-observe")
+	apparmorSpec := apparmor.NewSpecification(plug.AppSet())
+	c.Assert(apparmorSpec.AddConnectedPlug(s.iface, plug, s.slot), IsNil)
+	snippet := apparmorSpec.SnippetForTag("snap.other.app2")
+	c.Check(snippet, testutil.Contains, "capability sys_rawio,\n")
+	c.Check(snippet, testutil.Contains, "/sys/firmware/dmi/tables/{,*} r,\n")
+	c.Check(snippet, testutil.Contains, "/{,usr/}sbin/dmidecode ixr,\n")
+	c.Check(snippet, testutil.Contains, "/sys/kernel/debug/pmbus/{,**} r,\n")
+	c.Check(snippet, testutil.Contains, "/dev/nvme{[0-9],[1-9][0-9]} r,")
+	c.Check(snippet, Not(testutil.Contains), "/dev/nvme{[0-9],[1-9][0-9]}n")
+}
+
+func (s *HardwareObserveInterfaceSuite) TestUDevConnectedPlug(c *C) {
+	udevSpec := udev.NewSpecification(s.plug.AppSet())
+	c.Assert(udevSpec.AddConnectedPlug(s.iface, s.plug, s.slot), IsNil)
+	c.Check(udevSpec.Snippets(), HasLen, 0)
+
+	plug, _ := MockConnectedPlug(c, hwobserveWithScopesMockPlugSnapInfoYaml, nil, "hardware-observe")
+	udevSpec = udev.NewSpecification(plug.AppSet())
+	c.Assert(udevSpec.AddConnectedPlug(s.iface, plug, s.slot), IsNil)
+	c.Check(udevSpec.Snippets(), HasLen, 2)
+	c.Check(udevSpec.Snippets(), testutil.Contains, `# hardware-observe
+SUBSYSTEM=="nvme", KERNEL=="nvme[0-9]*", TAG+="snap_other_app2"`)
+	c.Check(udevSpec.Snippets(), testutil.Contains, fmt.Sprintf(
+		`TAG=="snap_other_app2", SUBSYSTEM!="module", SUBSYSTEM!="subsystem", RUN+="%v/snap-device-helper $env{ACTION} snap_other_app2 $devpath $major:$minor"`, dirs.DistroLibExecDir))
+
+}
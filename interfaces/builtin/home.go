@@ -20,10 +20,14 @@
 package builtin
 
 import (
+	"bytes"
 	"fmt"
+	"path/filepath"
+	"strings"
 
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/apparmor"
+	apparmor_sandbox "github.com/snapcore/snapd/sandbox/apparmor"
 	"github.com/snapcore/snapd/snap"
 )
 
@@ -60,14 +64,14 @@ const homeConnectedPlugAppArmor = `
 # TODO: use GenerateAAREExclusionPatterns for this - though the first
 # rule here complicates using it slightly from the inclusion of the "." to 
 # prevent reading dotfiles
-###PROMPT### owner @{HOME}/[^s.]**             rwkl###HOME_IX###,
-###PROMPT### owner @{HOME}/s[^n]**             rwkl###HOME_IX###,
-###PROMPT### owner @{HOME}/sn[^a]**            rwkl###HOME_IX###,
-###PROMPT### owner @{HOME}/sna[^p]**           rwkl###HOME_IX###,
-###PROMPT### owner @{HOME}/snap[^/]**          rwkl###HOME_IX###,
+###PROMPT### owner @{HOME}/[^s.]**             ###HOME_ACCESS######HOME_IX###,
+###PROMPT### owner @{HOME}/s[^n]**             ###HOME_ACCESS######HOME_IX###,
+###PROMPT### owner @{HOME}/sn[^a]**            ###HOME_ACCESS######HOME_IX###,
+###PROMPT### owner @{HOME}/sna[^p]**           ###HOME_ACCESS######HOME_IX###,
+###PROMPT### owner @{HOME}/snap[^/]**          ###HOME_ACCESS######HOME_IX###,
 
 # Allow creating a few files not caught above
-###PROMPT### owner @{HOME}/{s,sn,sna}{,/} rwkl###HOME_IX###,
+###PROMPT### owner @{HOME}/{s,sn,sna}{,/} ###HOME_ACCESS######HOME_IX###,
 
 # Allow access to @{HOME}/snap/ to allow directory traversals from
 # @{HOME}/snap/@{SNAP_INSTANCE_NAME} through @{HOME}/snap to @{HOME}.
@@ -78,14 +82,32 @@ const homeConnectedPlugAppArmor = `
 # Allow access to gvfs mounts for files owned by the user (including hidden
 # files; only allow writes to files, not the mount point).
 ###PROMPT### owner /run/user/[0-9]*/gvfs/{,**} r,
-###PROMPT### owner /run/user/[0-9]*/gvfs/*/**  w,
+`
+
+const homeConnectedPlugAppArmorGvfsWrite = `###PROMPT### owner /run/user/[0-9]*/gvfs/*/**  w,
+`
+
+const homeConnectedPlugAppArmorDirectories = `
+# Description: Can access the directories of the user's $HOME declared by
+# the plug. This is restricted because it gives file access to arbitrary
+# locations in the user's $HOME.
+
+# Note, @{HOME} is the user's $HOME, not the snap's $HOME
+
+`
 
+const homeConnectedPlugAppArmorDenyBin = `
 # Disallow writes to the well-known directory included in
 # the user's PATH on several distributions
 audit deny @{HOME}/bin/{,**} wl,
 audit deny @{HOME}/bin wl,
 `
 
+const homeConnectedPlugAppArmorExcludeHidden = `
+# Disallow access to hidden files and directories at any depth in @{HOME}
+deny @{HOME}/**/.*{,/**} rwklx,
+`
+
 const homeConnectedPlugAppArmorWithAllRead = `
 # Allow non-owner read to non-hidden and non-snap files and directories
 capability dac_read_search,
@@ -105,12 +127,66 @@ type homeInterface struct {
 	commonInterface
 }
 
+// validateHomeDirectory checks a path of the "directories" attribute, it
+// must be a non-hidden directory in $HOME outside of $HOME/snap.
+func validateHomeDirectory(p string) error {
+	if err := validateSinglePathHome(p); err != nil {
+		return err
+	}
+	if p != filepath.Clean(p) {
+		return fmt.Errorf("cannot use %q: try %q", p, filepath.Clean(p))
+	}
+	if strings.ContainsAny(p, "~@\n") {
+		return fmt.Errorf(`%q contains a reserved character`, p)
+	}
+	if err := apparmor_sandbox.ValidateNoAppArmorRegexp(p); err != nil {
+		return err
+	}
+	elems := strings.Split(strings.TrimPrefix(p, "$HOME/"), "/")
+	if elems[0] == "snap" {
+		return fmt.Errorf(`%q cannot be in "$HOME/snap"`, p)
+	}
+	for _, elem := range elems {
+		if strings.HasPrefix(elem, ".") {
+			return fmt.Errorf(`%q cannot be a hidden directory or be in one`, p)
+		}
+	}
+	return nil
+}
+
 func (iface *homeInterface) BeforePreparePlug(plug *snap.PlugInfo) error {
 	// It's fine if 'read' isn't specified, but if it is, it needs to be
 	// 'all'
-	if r, ok := plug.Attrs["read"]; ok && r != "all" {
+	r, hasRead := plug.Attrs["read"]
+	if hasRead && r != "all" {
 		return fmt.Errorf(`home plug requires "read" be 'all'`)
 	}
+	for _, attr := range []string{"read-only", "exclude-hidden"} {
+		if v, ok := plug.Attrs[attr]; ok {
+			if _, ok := v.(bool); !ok {
+				return fmt.Errorf(`home plug requires %q be a boolean`, attr)
+			}
+		}
+	}
+	if _, ok := plug.Attrs["directories"]; ok {
+		dirs, err := stringListAttribute(plug, "directories")
+		if err != nil {
+			return fmt.Errorf("home plug %v", err)
+		}
+		if len(dirs) == 0 {
+			return fmt.Errorf(`home plug requires "directories" be a non-empty list`)
+		}
+		// limiting access to some directories makes no sense if all of
+		// $HOME can be read
+		if hasRead {
+			return fmt.Errorf(`home plug cannot have both "read" and "directories"`)
+		}
+		for _, dir := range dirs {
+			if err := validateHomeDirectory(dir); err != nil {
+				return fmt.Errorf("home plug has invalid directory: %v", err)
+			}
+		}
+	}
 
 	return nil
 }
@@ -118,8 +194,43 @@ func (iface *homeInterface) BeforePreparePlug(plug *snap.PlugInfo) error {
 func (iface *homeInterface) AppArmorConnectedPlug(spec *apparmor.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
 	var read string
 	_ = plug.Attr("read", &read)
-	// 'owner' is the standard policy
-	spec.AddSnippet(homeConnectedPlugAppArmor)
+	var readOnly, excludeHidden bool
+	_ = plug.Attr("read-only", &readOnly)
+	_ = plug.Attr("exclude-hidden", &excludeHidden)
+	// BeforePreparePlug should prevent errors
+	dirs, err := stringListAttribute(plug, "directories")
+	if err != nil {
+		return fmt.Errorf("cannot connect plug %s: %v", plug.Name(), err)
+	}
+
+	access := filesWrite
+	if readOnly {
+		access = filesRead
+	}
+
+	var buf bytes.Buffer
+	if len(dirs) > 0 {
+		// only the declared directories of $HOME are accessible
+		buf.WriteString(homeConnectedPlugAppArmorDirectories)
+		for _, dir := range dirs {
+			p, err := formatPath(dir)
+			if err != nil {
+				return fmt.Errorf("cannot connect plug %s: %v", plug.Name(), err)
+			}
+			fmt.Fprintf(&buf, "###PROMPT### %s %s###HOME_IX###,\n", p, access)
+		}
+	} else {
+		// 'owner' is the standard policy
+		buf.WriteString(strings.Replace(homeConnectedPlugAppArmor, "###HOME_ACCESS###", access.String(), -1))
+		if !readOnly {
+			buf.WriteString(homeConnectedPlugAppArmorGvfsWrite)
+		}
+	}
+	buf.WriteString(homeConnectedPlugAppArmorDenyBin)
+	if excludeHidden {
+		buf.WriteString(homeConnectedPlugAppArmorExcludeHidden)
+	}
+	spec.AddSnippet(buf.String())
 
 	// 'all' grants standard policy plus read access to home without owner
 	// match
@@ -133,6 +244,7 @@ func init() {
 	registerIface(&homeInterface{commonInterface{
 		name:                 "home",
 		summary:              homeSummary,
+		consumers:            "editors, file managers and other applications working on documents of the user",
 		implicitOnCore:       true,
 		implicitOnClassic:    true,
 		baseDeclarationSlots: homeBaseDeclarationSlots,
@@ -20,6 +20,8 @@
 package builtin_test
 
 import (
+	"fmt"
+
 	. "gopkg.in/check.v1"
 
 	"github.com/snapcore/snapd/interfaces"
@@ -139,6 +141,53 @@ plugs:
 		`home plug requires "read" be 'all'`)
 }
 
+func (s *HomeInterfaceSuite) TestSanitizePlugWithFineGrainedAttribs(c *C) {
+	const mockSnapYaml = `name: home-plug-snap
+version: 1.0
+plugs:
+ home:
+  read-only: true
+  exclude-hidden: true
+  directories:
+   - $HOME/Games/home-plug-snap
+   - $HOME/Documents
+`
+	info := snaptest.MockInfo(c, mockSnapYaml, nil)
+	plug := info.Plugs["home"]
+	c.Assert(interfaces.BeforePreparePlug(s.iface, plug), IsNil)
+}
+
+func (s *HomeInterfaceSuite) TestSanitizePlugWithBadFineGrainedAttribs(c *C) {
+	const mockSnapYaml = `name: home-plug-snap
+version: 1.0
+plugs:
+ home:
+%s
+`
+	for _, t := range []struct {
+		attrs string
+		err   string
+	}{
+		{"  read-only: yes-please", `home plug requires "read-only" be a boolean`},
+		{"  exclude-hidden: 1", `home plug requires "exclude-hidden" be a boolean`},
+		{"  directories: $HOME/Games", `home plug "directories" attribute must be a list of strings, not "\$HOME/Games"`},
+		{"  directories: []", `home plug requires "directories" be a non-empty list`},
+		{"  read: all\n  directories: [$HOME/Games]", `home plug cannot have both "read" and "directories"`},
+		{"  directories: [Games]", `home plug has invalid directory: "Games" must start with "\$HOME/"`},
+		{"  directories: [$HOME/Games/]", `home plug has invalid directory: cannot use "\$HOME/Games/": try "\$HOME/Games"`},
+		{"  directories: [$HOME/../etc]", `home plug has invalid directory: cannot use "\$HOME/../etc": try "etc"`},
+		{"  directories: [$HOME/snap/foo]", `home plug has invalid directory: "\$HOME/snap/foo" cannot be in "\$HOME/snap"`},
+		{"  directories: [$HOME/.config]", `home plug has invalid directory: "\$HOME/.config" cannot be a hidden directory or be in one`},
+		{"  directories: [$HOME/Games/.saves]", `home plug has invalid directory: "\$HOME/Games/.saves" cannot be a hidden directory or be in one`},
+		{"  directories: [$HOME/Games/*]", `home plug has invalid directory: "\$HOME/Games/\*" contains a reserved apparmor char from .*`},
+		{"  directories: [\"$HOME/Games/@{foo}\"]", `home plug has invalid directory: "\$HOME/Games/@{foo}" contains a reserved character`},
+	} {
+		info := snaptest.MockInfo(c, fmt.Sprintf(mockSnapYaml, t.attrs), nil)
+		plug := info.Plugs["home"]
+		c.Check(interfaces.BeforePreparePlug(s.iface, plug), ErrorMatches, t.err, Commentf("%s", t.attrs))
+	}
+}
+
 func (s *HomeInterfaceSuite) TestConnectedPlugAppArmorWithoutAttrib(c *C) {
 	apparmorSpec := apparmor.NewSpecification(s.plug.AppSet())
 	err := apparmorSpec.AddConnectedPlug(s.iface, s.plug, s.slot)
@@ -172,6 +221,95 @@ apps:
 	c.Check(apparmorSpec.SnippetForTag("snap.home-plug-snap.app2"), testutil.Contains, `# Allow non-owner read`)
 }
 
+func (s *HomeInterfaceSuite) TestConnectedPlugAppArmorReadOnly(c *C) {
+	const mockSnapYaml = `name: home-plug-snap
+version: 1.0
+plugs:
+ home:
+  read-only: true
+apps:
+ app2:
+  command: foo
+`
+	plug, _ := MockConnectedPlug(c, mockSnapYaml, nil, "home")
+
+	apparmorSpec := apparmor.NewSpecification(plug.AppSet())
+	err := apparmorSpec.AddConnectedPlug(s.iface, plug, s.slot)
+	c.Assert(err, IsNil)
+	snippet := apparmorSpec.SnippetForTag("snap.home-plug-snap.app2")
+	c.Check(snippet, testutil.Contains, `###PROMPT### owner @{HOME}/ r,`)
+	c.Check(snippet, testutil.Contains, `###PROMPT### owner @{HOME}/[^s.]**             rk###HOME_IX###,`)
+	c.Check(snippet, Not(testutil.Contains), `rwkl`)
+	c.Check(snippet, Not(testutil.Contains), `gvfs/*/**  w,`)
+	c.Check(snippet, testutil.Contains, `audit deny @{HOME}/bin wl,`)
+	c.Check(snippet, Not(testutil.Contains), `deny @{HOME}/**/.*{,/**} rwklx,`)
+}
+
+func (s *HomeInterfaceSuite) TestConnectedPlugAppArmorWithoutAttribReadWrite(c *C) {
+	apparmorSpec := apparmor.NewSpecification(s.plug.AppSet())
+	err := apparmorSpec.AddConnectedPlug(s.iface, s.plug, s.slot)
+	c.Assert(err, IsNil)
+	snippet := apparmorSpec.SnippetForTag("snap.other.app")
+	c.Check(snippet, testutil.Contains, `###PROMPT### owner @{HOME}/[^s.]**             rwkl###HOME_IX###,`)
+	c.Check(snippet, testutil.Contains, `###PROMPT### owner /run/user/[0-9]*/gvfs/*/**  w,`)
+	c.Check(snippet, Not(testutil.Contains), `###HOME_ACCESS###`)
+}
+
+func (s *HomeInterfaceSuite) TestConnectedPlugAppArmorExcludeHidden(c *C) {
+	const mockSnapYaml = `name: home-plug-snap
+version: 1.0
+plugs:
+ home:
+  exclude-hidden: true
+apps:
+ app2:
+  command: foo
+`
+	plug, _ := MockConnectedPlug(c, mockSnapYaml, nil, "home")
+
+	apparmorSpec := apparmor.NewSpecification(plug.AppSet())
+	err := apparmorSpec.AddConnectedPlug(s.iface, plug, s.slot)
+	c.Assert(err, IsNil)
+	snippet := apparmorSpec.SnippetForTag("snap.home-plug-snap.app2")
+	c.Check(snippet, testutil.Contains, `###PROMPT### owner @{HOME}/[^s.]**             rwkl###HOME_IX###,`)
+	c.Check(snippet, testutil.Contains, `deny @{HOME}/**/.*{,/**} rwklx,`)
+}
+
+func (s *HomeInterfaceSuite) TestConnectedPlugAppArmorDirectories(c *C) {
+	const mockSnapYaml = `name: home-plug-snap
+version: 1.0
+plugs:
+ home:
+  read-only: true
+  directories:
+   - $HOME/Games/home-plug-snap
+   - $HOME/Music
+apps:
+ app2:
+  command: foo
+`
+	plug, _ := MockConnectedPlug(c, mockSnapYaml, nil, "home")
+
+	apparmorSpec := apparmor.NewSpecification(plug.AppSet())
+	err := apparmorSpec.AddConnectedPlug(s.iface, plug, s.slot)
+	c.Assert(err, IsNil)
+	c.Check(apparmorSpec.SnippetForTag("snap.home-plug-snap.app2"), Equals, `
+# Description: Can access the directories of the user's $HOME declared by
+# the plug. This is restricted because it gives file access to arbitrary
+# locations in the user's $HOME.
+
+# Note, @{HOME} is the user's $HOME, not the snap's $HOME
+
+###PROMPT### owner "@{HOME}/Games/home-plug-snap{,/,/**}" rk###HOME_IX###,
+###PROMPT### owner "@{HOME}/Music{,/,/**}" rk###HOME_IX###,
+
+# Disallow writes to the well-known directory included in
+# the user's PATH on several distributions
+audit deny @{HOME}/bin/{,**} wl,
+audit deny @{HOME}/bin wl,
+`)
+}
+
 func (s *HomeInterfaceSuite) TestInterfaces(c *C) {
 	c.Check(builtin.Interfaces(), testutil.DeepContains, s.iface)
 }
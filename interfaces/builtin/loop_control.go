@@ -0,0 +1,117 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/udev"
+)
+
+/*
+ * loop-control: allow snaps which work with disk images to allocate loop
+ * devices through /dev/loop-control and to attach and detach backing files.
+ * Access is limited to free loop devices and to loop devices backed by files
+ * of the snap, other block devices remain covered by block-devices.
+ *
+ * The loop devices a snap may use are not known upfront, so they cannot be
+ * expressed as AppArmor rules. Instead the device cgroup of the snap is
+ * updated from udev as loop devices are attached and detached.
+ */
+
+const loopControlSummary = `allows allocating and managing loop devices`
+
+const loopControlBaseDeclarationPlugs = `
+  loop-control:
+    allow-installation: false
+    deny-auto-connection: true
+`
+
+const loopControlBaseDeclarationSlots = `
+  loop-control:
+    allow-installation:
+      slot-snap-type:
+        - core
+    deny-auto-connection: true
+`
+
+const loopControlConnectedPlugAppArmor = `
+# Description: Allow allocating loop devices and attaching and detaching
+# backing files. See 'man 4 loop' for details.
+
+# Allocate, free and find unused loop devices
+/dev/loop-control rw,
+
+# Loop devices and their partitions (up to 1000 devices). Which of them can
+# actually be opened is decided by the device cgroup, that only grants access
+# to free loop devices and to loop devices backed by files of the snap.
+/dev/loop[0-9]{,[0-9],[0-9][0-9]} rwk,
+/dev/loop[0-9]{,[0-9],[0-9][0-9]}p[1-9]{,[0-9]} rwk,
+
+# Inspect the state of loop devices, eg. their backing file
+/sys/devices/virtual/block/loop[0-9]*/{,**} r,
+/sys/module/loop/parameters/* r,
+
+# Loop devices use block major 7
+/run/udev/data/b7:[0-9]* r,
+`
+
+type loopControlInterface struct {
+	commonInterface
+}
+
+// loopControlBackingFiles returns a udev pattern matching the files of the
+// given snap instance which may back loop devices.
+func loopControlBackingFiles(instanceName string) string {
+	return strings.Join([]string{
+		fmt.Sprintf("/var/snap/%s/*", instanceName),
+		fmt.Sprintf("/root/snap/%s/*", instanceName),
+		fmt.Sprintf("/home/*/snap/%s/*", instanceName),
+	}, "|")
+}
+
+func (iface *loopControlInterface) UDevConnectedPlug(spec *udev.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	backingFiles := loopControlBackingFiles(plug.Snap().InstanceName())
+
+	spec.TagDevice(`KERNEL=="loop-control"`)
+	// free loop devices, so that backing files can be attached to them
+	spec.TagDevice(`SUBSYSTEM=="block", KERNEL=="loop[0-9]*", ENV{DEVTYPE}=="disk", TEST!="loop/backing_file"`)
+	// loop devices, and their partitions, backed by files of the snap
+	spec.TagDevice(fmt.Sprintf(`SUBSYSTEM=="block", KERNEL=="loop[0-9]*", ATTRS{loop/backing_file}=="%s"`, backingFiles))
+	// loop devices which were free when the snap was granted access, but
+	// got a backing file attached by someone else since
+	spec.UntagDevice(fmt.Sprintf(`ACTION=="change", SUBSYSTEM=="block", KERNEL=="loop[0-9]*", ENV{DEVTYPE}=="disk", TEST=="loop/backing_file", ATTR{loop/backing_file}!="%s"`, backingFiles))
+	return nil
+}
+
+func init() {
+	registerIface(&loopControlInterface{commonInterface{
+		name:                  "loop-control",
+		summary:               loopControlSummary,
+		consumers:             "tools building or inspecting disk images",
+		implicitOnCore:        true,
+		implicitOnClassic:     true,
+		baseDeclarationPlugs:  loopControlBaseDeclarationPlugs,
+		baseDeclarationSlots:  loopControlBaseDeclarationSlots,
+		connectedPlugAppArmor: loopControlConnectedPlugAppArmor,
+	}})
+}
@@ -0,0 +1,149 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin_test
+
+import (
+	"fmt"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/seccomp"
+	"github.com/snapcore/snapd/interfaces/udev"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type LoopControlInterfaceSuite struct {
+	iface    interfaces.Interface
+	slotInfo *snap.SlotInfo
+	slot     *interfaces.ConnectedSlot
+	plugInfo *snap.PlugInfo
+	plug     *interfaces.ConnectedPlug
+}
+
+const loopControlConsumerYaml = `name: consumer
+version: 0
+apps:
+ app:
+  plugs: [loop-control]
+`
+
+const loopControlCoreYaml = `name: core
+version: 0
+type: os
+slots:
+  loop-control:
+`
+
+var _ = Suite(&LoopControlInterfaceSuite{
+	iface: builtin.MustInterface("loop-control"),
+})
+
+func (s *LoopControlInterfaceSuite) SetUpTest(c *C) {
+	s.plug, s.plugInfo = MockConnectedPlug(c, loopControlConsumerYaml, nil, "loop-control")
+	s.slot, s.slotInfo = MockConnectedSlot(c, loopControlCoreYaml, nil, "loop-control")
+}
+
+func (s *LoopControlInterfaceSuite) TestName(c *C) {
+	c.Assert(s.iface.Name(), Equals, "loop-control")
+}
+
+func (s *LoopControlInterfaceSuite) TestSanitizeSlot(c *C) {
+	c.Assert(interfaces.BeforePrepareSlot(s.iface, s.slotInfo), IsNil)
+}
+
+func (s *LoopControlInterfaceSuite) TestSanitizePlug(c *C) {
+	c.Assert(interfaces.BeforePreparePlug(s.iface, s.plugInfo), IsNil)
+}
+
+func (s *LoopControlInterfaceSuite) TestAppArmorSpec(c *C) {
+	appSet, err := interfaces.NewSnapAppSet(s.plug.Snap(), nil)
+	c.Assert(err, IsNil)
+	spec := apparmor.NewSpecification(appSet)
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, s.slot), IsNil)
+	c.Assert(spec.SecurityTags(), DeepEquals, []string{"snap.consumer.app"})
+	snippet := spec.SnippetForTag("snap.consumer.app")
+	c.Check(snippet, testutil.Contains, "/dev/loop-control rw,\n")
+	c.Check(snippet, testutil.Contains, "/dev/loop[0-9]{,[0-9],[0-9][0-9]} rwk,\n")
+	// no access to other block devices
+	c.Check(snippet, Not(testutil.Contains), "/dev/sd")
+	c.Check(snippet, Not(testutil.Contains), "capability sys_admin")
+}
+
+func (s *LoopControlInterfaceSuite) TestSecCompSpec(c *C) {
+	appSet, err := interfaces.NewSnapAppSet(s.plug.Snap(), nil)
+	c.Assert(err, IsNil)
+	spec := seccomp.NewSpecification(appSet)
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, s.slot), IsNil)
+	// the loop ioctls are allowed by the default template
+	c.Assert(spec.SecurityTags(), HasLen, 0)
+}
+
+func (s *LoopControlInterfaceSuite) TestUDevSpec(c *C) {
+	appSet, err := interfaces.NewSnapAppSet(s.plug.Snap(), nil)
+	c.Assert(err, IsNil)
+	spec := udev.NewSpecification(appSet)
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, s.slot), IsNil)
+	c.Assert(spec.Snippets(), DeepEquals, []string{
+		fmt.Sprintf(`# loop-control
+ACTION=="change", SUBSYSTEM=="block", KERNEL=="loop[0-9]*", ENV{DEVTYPE}=="disk", TEST=="loop/backing_file", ATTR{loop/backing_file}!="/var/snap/consumer/*|/root/snap/consumer/*|/home/*/snap/consumer/*", RUN+="%v/snap-device-helper remove snap_consumer_app $devpath $major:$minor"`, dirs.DistroLibExecDir),
+		`# loop-control
+KERNEL=="loop-control", TAG+="snap_consumer_app"`,
+		`# loop-control
+SUBSYSTEM=="block", KERNEL=="loop[0-9]*", ATTRS{loop/backing_file}=="/var/snap/consumer/*|/root/snap/consumer/*|/home/*/snap/consumer/*", TAG+="snap_consumer_app"`,
+		`# loop-control
+SUBSYSTEM=="block", KERNEL=="loop[0-9]*", ENV{DEVTYPE}=="disk", TEST!="loop/backing_file", TAG+="snap_consumer_app"`,
+		fmt.Sprintf(`TAG=="snap_consumer_app", SUBSYSTEM!="module", SUBSYSTEM!="subsystem", RUN+="%v/snap-device-helper $env{ACTION} snap_consumer_app $devpath $major:$minor"`, dirs.DistroLibExecDir),
+	})
+}
+
+func (s *LoopControlInterfaceSuite) TestUDevSpecInstance(c *C) {
+	plug, _ := MockConnectedPlug(c, loopControlConsumerYaml, nil, "loop-control")
+	plug.Snap().InstanceKey = "foo"
+	appSet, err := interfaces.NewSnapAppSet(plug.Snap(), nil)
+	c.Assert(err, IsNil)
+	spec := udev.NewSpecification(appSet)
+	c.Assert(spec.AddConnectedPlug(s.iface, plug, s.slot), IsNil)
+	c.Assert(spec.Snippets(), testutil.Contains, `# loop-control
+SUBSYSTEM=="block", KERNEL=="loop[0-9]*", ATTRS{loop/backing_file}=="/var/snap/consumer_foo/*|/root/snap/consumer_foo/*|/home/*/snap/consumer_foo/*", TAG+="snap_consumer_foo_app"`)
+}
+
+func (s *LoopControlInterfaceSuite) TestStaticInfo(c *C) {
+	si := interfaces.StaticInfoOf(s.iface)
+	c.Assert(si.ImplicitOnCore, Equals, true)
+	c.Assert(si.ImplicitOnClassic, Equals, true)
+	c.Assert(si.Summary, Equals, `allows allocating and managing loop devices`)
+	c.Assert(si.Consumers, Equals, `tools building or inspecting disk images`)
+	c.Assert(si.BaseDeclarationPlugs, testutil.Contains, "allow-installation: false")
+	c.Assert(si.BaseDeclarationSlots, testutil.Contains, "loop-control")
+	c.Assert(si.BaseDeclarationSlots, testutil.Contains, "deny-auto-connection: true")
+}
+
+func (s *LoopControlInterfaceSuite) TestAutoConnect(c *C) {
+	c.Assert(s.iface.AutoConnect(s.plugInfo, s.slotInfo), Equals, true)
+}
+
+func (s *LoopControlInterfaceSuite) TestInterfaces(c *C) {
+	c.Check(builtin.Interfaces(), testutil.DeepContains, s.iface)
+}
@@ -94,6 +94,7 @@ func init() {
 	registerIface(&commonInterface{
 		name:                  "network",
 		summary:               networkSummary,
+		consumers:             "applications accessing the network as clients",
 		implicitOnCore:        true,
 		implicitOnClassic:     true,
 		baseDeclarationSlots:  networkBaseDeclarationSlots,
@@ -337,6 +337,7 @@ func init() {
 		commonInterface: commonInterface{
 			name:                 "opengl",
 			summary:              openglSummary,
+			consumers:            "games and applications rendering with the GPU",
 			implicitOnCore:       true,
 			implicitOnClassic:    true,
 			baseDeclarationSlots: openglBaseDeclarationSlots,
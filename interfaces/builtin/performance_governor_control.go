@@ -0,0 +1,104 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin
+
+import (
+	"fmt"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/snap"
+)
+
+/*
+ * performance-governor-control: allow tuning snaps to select the cpufreq
+ * scaling governor and the energy performance preference (EPP) of CPUs,
+ * without the other CPU tunables granted by cpu-control. The plug may set
+ * "read-only: true" to only observe the current settings.
+ */
+
+const performanceGovernorControlSummary = `allows setting the CPU frequency governor and energy performance preference`
+
+const performanceGovernorControlBaseDeclarationSlots = `
+  performance-governor-control:
+    allow-installation:
+      slot-snap-type:
+        - core
+    deny-auto-connection: true
+`
+
+const performanceGovernorControlConnectedPlugAppArmor = `
+# Description: Can read the CPU frequency scaling governor and the energy
+# performance preference. See
+# https://www.kernel.org/doc/html/latest/admin-guide/pm/cpufreq.html#policy-interface-in-sysfs
+# The per-CPU /sys/devices/system/cpu/cpu*/cpufreq directories are symlinks
+# to the policy directories, hence the rules below only name the latter.
+
+/sys/devices/system/cpu/ r,
+/sys/devices/system/cpu/cpu[0-9]*/ r,
+/sys/devices/system/cpu/cpufreq/ r,
+/sys/devices/system/cpu/cpufreq/policy[0-9]*/ r,
+/sys/devices/system/cpu/cpufreq/policy[0-9]*/scaling_driver r,
+/sys/devices/system/cpu/cpufreq/policy[0-9]*/scaling_available_governors r,
+/sys/devices/system/cpu/cpufreq/policy[0-9]*/scaling_governor r,
+/sys/devices/system/cpu/cpufreq/policy[0-9]*/energy_performance_available_preferences r,
+/sys/devices/system/cpu/cpufreq/policy[0-9]*/energy_performance_preference r,
+`
+
+const performanceGovernorControlConnectedPlugAppArmorWrite = `
+# Description: Can set the CPU frequency scaling governor and the energy
+# performance preference.
+/sys/devices/system/cpu/cpufreq/policy[0-9]*/scaling_governor w,
+/sys/devices/system/cpu/cpufreq/policy[0-9]*/energy_performance_preference w,
+`
+
+type performanceGovernorControlInterface struct {
+	commonInterface
+}
+
+func (iface *performanceGovernorControlInterface) BeforePreparePlug(plug *snap.PlugInfo) error {
+	if v, ok := plug.Attrs["read-only"]; ok {
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf(`performance-governor-control plug requires "read-only" be a boolean`)
+		}
+	}
+	return nil
+}
+
+func (iface *performanceGovernorControlInterface) AppArmorConnectedPlug(spec *apparmor.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	var readOnly bool
+	_ = plug.Attr("read-only", &readOnly)
+
+	spec.AddSnippet(performanceGovernorControlConnectedPlugAppArmor)
+	if !readOnly {
+		spec.AddSnippet(performanceGovernorControlConnectedPlugAppArmorWrite)
+	}
+	return nil
+}
+
+func init() {
+	registerIface(&performanceGovernorControlInterface{commonInterface{
+		name:                 "performance-governor-control",
+		summary:              performanceGovernorControlSummary,
+		implicitOnCore:       true,
+		implicitOnClassic:    true,
+		baseDeclarationSlots: performanceGovernorControlBaseDeclarationSlots,
+	}})
+}
@@ -0,0 +1,146 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type PerformanceGovernorControlInterfaceSuite struct {
+	iface    interfaces.Interface
+	slotInfo *snap.SlotInfo
+	slot     *interfaces.ConnectedSlot
+	plugInfo *snap.PlugInfo
+	plug     *interfaces.ConnectedPlug
+}
+
+const performanceGovernorControlConsumerYaml = `name: consumer
+version: 0
+apps:
+ app:
+  plugs: [performance-governor-control]
+`
+
+const performanceGovernorControlReadOnlyConsumerYaml = `name: consumer
+version: 0
+plugs:
+  performance-governor-control:
+    read-only: true
+apps:
+  app:
+    plugs: [performance-governor-control]
+`
+
+const performanceGovernorControlCoreYaml = `name: core
+version: 0
+type: os
+slots:
+  performance-governor-control:
+`
+
+var _ = Suite(&PerformanceGovernorControlInterfaceSuite{
+	iface: builtin.MustInterface("performance-governor-control"),
+})
+
+func (s *PerformanceGovernorControlInterfaceSuite) SetUpTest(c *C) {
+	s.plug, s.plugInfo = MockConnectedPlug(c, performanceGovernorControlConsumerYaml, nil, "performance-governor-control")
+	s.slot, s.slotInfo = MockConnectedSlot(c, performanceGovernorControlCoreYaml, nil, "performance-governor-control")
+}
+
+func (s *PerformanceGovernorControlInterfaceSuite) TestName(c *C) {
+	c.Assert(s.iface.Name(), Equals, "performance-governor-control")
+}
+
+func (s *PerformanceGovernorControlInterfaceSuite) TestSanitizeSlot(c *C) {
+	c.Assert(interfaces.BeforePrepareSlot(s.iface, s.slotInfo), IsNil)
+}
+
+func (s *PerformanceGovernorControlInterfaceSuite) TestSanitizePlug(c *C) {
+	c.Assert(interfaces.BeforePreparePlug(s.iface, s.plugInfo), IsNil)
+
+	info := snaptest.MockInfo(c, performanceGovernorControlReadOnlyConsumerYaml, nil)
+	c.Check(interfaces.BeforePreparePlug(s.iface, info.Plugs["performance-governor-control"]), IsNil)
+}
+
+func (s *PerformanceGovernorControlInterfaceSuite) TestSanitizePlugBadReadOnly(c *C) {
+	const badYaml = `name: consumer
+version: 0
+plugs:
+  performance-governor-control:
+    read-only: yes-please
+apps:
+  app:
+    plugs: [performance-governor-control]
+`
+	info := snaptest.MockInfo(c, badYaml, nil)
+	c.Check(interfaces.BeforePreparePlug(s.iface, info.Plugs["performance-governor-control"]), ErrorMatches,
+		`performance-governor-control plug requires "read-only" be a boolean`)
+}
+
+func (s *PerformanceGovernorControlInterfaceSuite) TestAppArmorSpec(c *C) {
+	appSet, err := interfaces.NewSnapAppSet(s.plug.Snap(), nil)
+	c.Assert(err, IsNil)
+	spec := apparmor.NewSpecification(appSet)
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, s.slot), IsNil)
+	c.Assert(spec.SecurityTags(), DeepEquals, []string{"snap.consumer.app"})
+	snippet := spec.SnippetForTag("snap.consumer.app")
+	c.Check(snippet, testutil.Contains, "/sys/devices/system/cpu/cpufreq/policy[0-9]*/scaling_governor r,\n")
+	c.Check(snippet, testutil.Contains, "/sys/devices/system/cpu/cpufreq/policy[0-9]*/scaling_governor w,\n")
+	c.Check(snippet, testutil.Contains, "/sys/devices/system/cpu/cpufreq/policy[0-9]*/energy_performance_preference r,\n")
+	c.Check(snippet, testutil.Contains, "/sys/devices/system/cpu/cpufreq/policy[0-9]*/energy_performance_preference w,\n")
+	// none of the other cpufreq tunables
+	c.Check(snippet, Not(testutil.Contains), "scaling_max_freq")
+}
+
+func (s *PerformanceGovernorControlInterfaceSuite) TestAppArmorSpecReadOnly(c *C) {
+	plug, _ := MockConnectedPlug(c, performanceGovernorControlReadOnlyConsumerYaml, nil, "performance-governor-control")
+
+	appSet, err := interfaces.NewSnapAppSet(plug.Snap(), nil)
+	c.Assert(err, IsNil)
+	spec := apparmor.NewSpecification(appSet)
+	c.Assert(spec.AddConnectedPlug(s.iface, plug, s.slot), IsNil)
+	snippet := spec.SnippetForTag("snap.consumer.app")
+	c.Check(snippet, testutil.Contains, "/sys/devices/system/cpu/cpufreq/policy[0-9]*/scaling_governor r,\n")
+	c.Check(snippet, testutil.Contains, "/sys/devices/system/cpu/cpufreq/policy[0-9]*/energy_performance_preference r,\n")
+	c.Check(snippet, Not(testutil.Contains), " w,\n")
+}
+
+func (s *PerformanceGovernorControlInterfaceSuite) TestStaticInfo(c *C) {
+	si := interfaces.StaticInfoOf(s.iface)
+	c.Assert(si.ImplicitOnCore, Equals, true)
+	c.Assert(si.ImplicitOnClassic, Equals, true)
+	c.Assert(si.Summary, Equals, `allows setting the CPU frequency governor and energy performance preference`)
+	c.Assert(si.BaseDeclarationSlots, testutil.Contains, "performance-governor-control")
+}
+
+func (s *PerformanceGovernorControlInterfaceSuite) TestAutoConnect(c *C) {
+	c.Assert(s.iface.AutoConnect(s.plugInfo, s.slotInfo), Equals, true)
+}
+
+func (s *PerformanceGovernorControlInterfaceSuite) TestInterfaces(c *C) {
+	c.Check(builtin.Interfaces(), testutil.DeepContains, s.iface)
+}
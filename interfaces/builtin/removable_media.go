@@ -53,6 +53,7 @@ func init() {
 	registerIface(&commonInterface{
 		name:                  "removable-media",
 		summary:               removableMediaSummary,
+		consumers:             "file managers and media players reading external drives",
 		implicitOnCore:        true,
 		implicitOnClassic:     true,
 		baseDeclarationSlots:  removableMediaBaseDeclarationSlots,
@@ -0,0 +1,93 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin
+
+const rtcControlSummary = `allows setting the RTC and programming its wake alarms`
+
+// Unlike time-control, this does not allow changing the system clock.
+const rtcControlBaseDeclarationSlots = `
+  rtc-control:
+    allow-installation:
+      slot-snap-type:
+        - core
+    deny-auto-connection: true
+`
+
+const rtcControlConnectedPlugAppArmor = `
+# Description: Can read and set the real-time clock and program its wake
+# alarms, without changing the system clock. See 'man 4 rtc' for details.
+
+/dev/rtc[0-9]* rw,
+
+# Access to the sysfs nodes is needed by rtcwake for example to program
+# scheduled wakeups in the future.
+/sys/class/rtc/ r,
+/sys/class/rtc/*/ r,
+/sys/class/rtc/*/** r,
+/sys/class/rtc/*/wakealarm w,
+
+# Nodes in /sys/class/rtc could be symlinks under /sys/devices
+/sys/devices/**/rtc/*/** r,
+/sys/devices/**/rtc/*/wakealarm w,
+
+# As the core snap ships the hwclock utility we can also allow
+# clients to use it now that they have access to the relevant
+# device nodes. Note: hwclock --hctosys sets the system clock,
+# which requires time-control. Some invocations of hwclock will
+# try to write to the audit subsystem. We omit 'capability
+# audit_write' and 'capability net_admin' here. Applications
+# requiring audit logging should plug 'netlink-audit'.
+/{,usr/}sbin/hwclock ixr,
+/{,usr/}sbin/rtcwake ixr,
+
+# Setting the RTC with the RTC_SET_TIME ioctl requires CAP_SYS_TIME, the
+# system calls changing the system clock are still denied by seccomp.
+capability sys_time,
+`
+
+const rtcControlConnectedPlugSecComp = `
+# Description: Can read and set the real-time clock and program its wake
+# alarms. The RTC is set with ioctls on /dev/rtc*, no system call setting
+# the system clock is allowed.
+
+# util-linux built with libaudit tries to write to the audit subsystem. We
+# allow the socket call here to avoid seccomp kill, but omit the AppArmor
+# capability rules.
+bind
+socket AF_NETLINK - NETLINK_AUDIT
+`
+
+var rtcControlConnectedPlugUDev = []string{
+	`SUBSYSTEM=="rtc"`,
+}
+
+func init() {
+	registerIface(&commonInterface{
+		name:                  "rtc-control",
+		summary:               rtcControlSummary,
+		consumers:             "appliances setting the hardware clock or waking up on alarms",
+		implicitOnCore:        true,
+		implicitOnClassic:     true,
+		baseDeclarationSlots:  rtcControlBaseDeclarationSlots,
+		connectedPlugAppArmor: rtcControlConnectedPlugAppArmor,
+		connectedPlugSecComp:  rtcControlConnectedPlugSecComp,
+		connectedPlugUDev:     rtcControlConnectedPlugUDev,
+	})
+}
@@ -52,6 +52,8 @@ func (iface *spiInterface) StaticInfo() interfaces.StaticInfo {
 	return interfaces.StaticInfo{
 		Summary:              spiSummary,
 		BaseDeclarationSlots: spiBaseDeclarationSlots,
+		RequiredSlotAttrs:    []string{"path"},
+		Consumers:            "applications talking to devices on a SPI bus",
 	}
 }
 
@@ -19,6 +19,20 @@
 
 package builtin
 
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/mount"
+	"github.com/snapcore/snapd/osutil"
+	apparmor_sandbox "github.com/snapcore/snapd/sandbox/apparmor"
+	"github.com/snapcore/snapd/snap/sysparams"
+)
+
 const systemBackupSummary = `allows read-only access to the entire system for backups`
 
 const systemBackupBaseDeclarationSlots = `
@@ -32,31 +46,118 @@ const systemBackupBaseDeclarationSlots = `
 const systemBackupConnectedPlugAppArmor = `
 # Description: Allow read-only access to the entire system
 capability dac_read_search,
-
-# read access to everything except items under /dev, /sys and /proc
-# TODO: use GenerateAAREExclusionPatterns for this
-/{,var/lib/snapd/hostfs/}[^dsp]** r,
-/{,var/lib/snapd/hostfs/}{d[^e],s[^y],p[^r]}** r,
-/{,var/lib/snapd/hostfs/}{de[^v],sy[^s],pr[^o]}** r,
-/{,var/lib/snapd/hostfs/}{dev[^/],sys[^/],pro[^c]}** r,
-/{,var/lib/snapd/hostfs/}proc[^/]** r,
-
-# Allow a few not caught in the above
-/{,var/lib/snapd/hostfs/}{d,de,p,pr,pro,s,sy}/** r,
-/{,var/lib/snapd/hostfs/}{d,de,dev,p,pr,pro,proc,s,sy,sys}{,/} r,
 `
 
+// systemBackupExcludedPaths are the paths that cannot be read, either
+// directly or through /var/lib/snapd/hostfs. The pseudo file systems are
+// not backed up and the secrets must not end up in backups.
+var systemBackupExcludedPaths = []string{
+	"/dev/",
+	"/proc/",
+	"/sys/",
+	// the host file system is read directly under its own prefix
+	"/var/lib/snapd/hostfs/",
+	"/etc/shadow",
+	"/etc/gshadow",
+	"/etc/ssh/ssh_host_",
+	"/var/lib/snapd/device/private-keys-v1",
+}
+
+const systemBackupPathsPrefix = "{,/var/lib/snapd/hostfs}"
+
+// systemBackupTargetDir returns the directory that the administrator
+// designated for backups with the backup.target-dir system option, if any.
+var systemBackupTargetDir = func() (string, error) {
+	ssp, err := sysparams.Open("")
+	if err != nil {
+		return "", err
+	}
+	return ssp.BackupTargetDir, nil
+}
+
+// systemBackupReadRules returns the rules allowing to read everything
+// except the excluded paths.
+func systemBackupReadRules() (string, error) {
+	var b bytes.Buffer
+	b.WriteString("# read access to everything except the excluded paths\n")
+	rules, err := apparmorGenerateAAREExclusionPatterns(systemBackupExcludedPaths, &apparmor_sandbox.AAREExclusionPatternsOptions{
+		Prefix: systemBackupPathsPrefix,
+		Suffix: " r,",
+	})
+	if err != nil {
+		return "", err
+	}
+	b.WriteString(rules)
+
+	// the rules above only match paths which differ from the excluded
+	// ones, allow the parent directories of the excluded paths as well
+	seen := make(map[string]bool)
+	var parents []string
+	for _, path := range systemBackupExcludedPaths {
+		for i := 2; i < len(path); i++ {
+			parent := strings.TrimSuffix(path[:i], "/")
+			if !seen[parent] {
+				seen[parent] = true
+				parents = append(parents, parent)
+			}
+		}
+	}
+	sort.Strings(parents)
+	fmt.Fprintf(&b, "%s{%s}{,/} r,\n", systemBackupPathsPrefix, strings.Join(parents, ","))
+	return b.String(), nil
+}
+
 type systemBackupInterface struct {
 	commonInterface
 }
 
+func (iface *systemBackupInterface) AppArmorConnectedPlug(spec *apparmor.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	readRules, err := systemBackupReadRules()
+	if err != nil {
+		return err
+	}
+	spec.AddSnippet(systemBackupConnectedPlugAppArmor + readRules)
+
+	target, err := systemBackupTargetDir()
+	if err != nil {
+		return err
+	}
+	if target == "" {
+		return nil
+	}
+	spec.AddSnippet(fmt.Sprintf("\n# Allow writing backups to the backup target directory\n%s/{,**} rwk,\n", target))
+
+	emit := spec.AddUpdateNSf
+	emit("  # Mount the backup target directory\n")
+	emit("  mount options=(bind) /var/lib/snapd/hostfs%s/ -> %s/,\n", target, target)
+	emit("  umount %s/,\n", target)
+	// the target does not necessarily exist in the base snap, in which
+	// case a writable mimic is needed
+	apparmor.GenWritableProfile(emit, target, 1)
+	return nil
+}
+
+func (iface *systemBackupInterface) MountConnectedPlug(spec *mount.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	target, err := systemBackupTargetDir()
+	if err != nil {
+		return err
+	}
+	if target == "" {
+		return nil
+	}
+	return spec.AddMountEntry(osutil.MountEntry{
+		Name:    "/var/lib/snapd/hostfs" + target,
+		Dir:     target,
+		Options: []string{"bind", "rw"},
+	})
+}
+
 func init() {
 	registerIface(&systemBackupInterface{commonInterface{
-		name:                  "system-backup",
-		summary:               systemBackupSummary,
-		implicitOnCore:        true,
-		implicitOnClassic:     true,
-		baseDeclarationSlots:  systemBackupBaseDeclarationSlots,
-		connectedPlugAppArmor: systemBackupConnectedPlugAppArmor,
+		name:                 "system-backup",
+		summary:              systemBackupSummary,
+		implicitOnCore:       true,
+		implicitOnClassic:    true,
+		baseDeclarationSlots: systemBackupBaseDeclarationSlots,
 	}})
 }
@@ -20,12 +20,21 @@
 package builtin_test
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
 	. "gopkg.in/check.v1"
 
+	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/apparmor"
 	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/mount"
+	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/release"
+	apparmor_sandbox "github.com/snapcore/snapd/sandbox/apparmor"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/testutil"
 )
@@ -78,6 +87,8 @@ func (s *systemBackupInterfaceSuite) TestSanitizePlug(c *C) {
 func (s *systemBackupInterfaceSuite) TestAppArmorSpec(c *C) {
 	restore := release.MockOnClassic(false)
 	defer restore()
+	restore = builtin.MockSystemBackupTargetDir(func() (string, error) { return "", nil })
+	defer restore()
 
 	appSet, err := interfaces.NewSnapAppSet(s.plug.Snap(), nil)
 	c.Assert(err, IsNil)
@@ -86,6 +97,88 @@ func (s *systemBackupInterfaceSuite) TestAppArmorSpec(c *C) {
 	c.Assert(spec.SecurityTags(), DeepEquals, []string{"snap.consumer.app"})
 	c.Assert(spec.SnippetForTag("snap.consumer.app"), testutil.Contains, `capability dac_read_search,`)
 	c.Assert(spec.SnippetForTag("snap.consumer.app"), testutil.Contains, `# Description: Allow read-only access to the entire system`)
+	// the pseudo file systems and the secrets are excluded
+	c.Check(spec.SnippetForTag("snap.consumer.app"), testutil.Contains, "{,/var/lib/snapd/hostfs}/[^dpsve]** r,\n")
+	c.Check(spec.SnippetForTag("snap.consumer.app"), testutil.Contains, "{,/var/lib/snapd/hostfs}/{etc/gshado[^w],etc/ssh/ss[^h],var/lib/sn[^a]}** r,\n")
+	c.Check(spec.SnippetForTag("snap.consumer.app"), testutil.Contains, "{,/var/lib/snapd/hostfs}/var/lib/snapd/device/private-keys-v[^1]** r,\n")
+	// while their parent directories can be read
+	c.Check(spec.SnippetForTag("snap.consumer.app"), testutil.Contains, "{,/var/lib/snapd/hostfs}{/d,/de,/dev,/e,/et,/etc,")
+	c.Check(spec.SnippetForTag("snap.consumer.app"), testutil.Contains, ",/etc/ssh/ssh_host,/p,")
+	c.Check(spec.SnippetForTag("snap.consumer.app"), Not(testutil.Contains), "/etc/shadow,")
+	// there is no backup target
+	c.Check(spec.SnippetForTag("snap.consumer.app"), Not(testutil.Contains), "rwk,")
+	c.Check(spec.UpdateNS(), HasLen, 0)
+}
+
+func (s *systemBackupInterfaceSuite) TestAppArmorSpecExclusionError(c *C) {
+	restore := builtin.MockApparmorGenerateAAREExclusionPatterns(func(excludePatterns []string, opts *apparmor_sandbox.AAREExclusionPatternsOptions) (string, error) {
+		return "", errors.New("boom")
+	})
+	defer restore()
+
+	appSet, err := interfaces.NewSnapAppSet(s.plug.Snap(), nil)
+	c.Assert(err, IsNil)
+	spec := apparmor.NewSpecification(appSet)
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, s.slot), ErrorMatches, "boom")
+}
+
+func (s *systemBackupInterfaceSuite) TestAppArmorSpecBackupTarget(c *C) {
+	restore := builtin.MockSystemBackupTargetDir(func() (string, error) { return "/srv/backup", nil })
+	defer restore()
+
+	appSet, err := interfaces.NewSnapAppSet(s.plug.Snap(), nil)
+	c.Assert(err, IsNil)
+	spec := apparmor.NewSpecification(appSet)
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, s.slot), IsNil)
+	c.Check(spec.SnippetForTag("snap.consumer.app"), testutil.Contains, "/srv/backup/{,**} rwk,\n")
+	updateNS := strings.Join(spec.UpdateNS(), "")
+	c.Check(updateNS, testutil.Contains, "  mount options=(bind) /var/lib/snapd/hostfs/srv/backup/ -> /srv/backup/,\n")
+	c.Check(updateNS, testutil.Contains, "  umount /srv/backup/,\n")
+	c.Check(updateNS, testutil.Contains, "  # Writable mimic /srv\n")
+}
+
+func (s *systemBackupInterfaceSuite) TestAppArmorSpecBackupTargetError(c *C) {
+	restore := builtin.MockSystemBackupTargetDir(func() (string, error) { return "", errors.New("cannot read system-params") })
+	defer restore()
+
+	appSet, err := interfaces.NewSnapAppSet(s.plug.Snap(), nil)
+	c.Assert(err, IsNil)
+	spec := apparmor.NewSpecification(appSet)
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, s.slot), ErrorMatches, "cannot read system-params")
+}
+
+func (s *systemBackupInterfaceSuite) TestMountSpec(c *C) {
+	restore := builtin.MockSystemBackupTargetDir(func() (string, error) { return "", nil })
+	defer restore()
+
+	spec := &mount.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, s.slot), IsNil)
+	c.Check(spec.MountEntries(), HasLen, 0)
+
+	restore = builtin.MockSystemBackupTargetDir(func() (string, error) { return "/srv/backup", nil })
+	defer restore()
+
+	spec = &mount.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, s.slot), IsNil)
+	c.Check(spec.MountEntries(), DeepEquals, []osutil.MountEntry{{
+		Name:    "/var/lib/snapd/hostfs/srv/backup",
+		Dir:     "/srv/backup",
+		Options: []string{"bind", "rw"},
+	}})
+}
+
+func (s *systemBackupInterfaceSuite) TestBackupTargetFromSystemParams(c *C) {
+	dirs.SetRootDir(c.MkDir())
+	defer dirs.SetRootDir("")
+
+	sspPath := dirs.SnapSystemParamsUnder(dirs.GlobalRootDir)
+	c.Assert(os.MkdirAll(filepath.Dir(sspPath), 0755), IsNil)
+	c.Assert(os.WriteFile(sspPath, []byte("homedirs=\nbackup-target-dir=/srv/backup\n"), 0644), IsNil)
+
+	spec := &mount.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, s.slot), IsNil)
+	c.Check(spec.MountEntries(), HasLen, 1)
+	c.Check(spec.MountEntries()[0].Dir, Equals, "/srv/backup")
 }
 
 func (s *systemBackupInterfaceSuite) TestStaticInfo(c *C) {
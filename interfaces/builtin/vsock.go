@@ -0,0 +1,72 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin
+
+// https://man7.org/linux/man-pages/man7/vsock.7.html
+const vsockSummary = `allows access to vsock sockets for host/guest communication`
+
+// Unlike vsock-guest, this also grants /dev/vhost-vsock which is used on
+// the host side to provide vsock to guests.
+const vsockBaseDeclarationSlots = `
+  vsock:
+    allow-installation:
+      slot-snap-type:
+        - core
+    deny-auto-connection: true
+`
+
+const vsockConnectedPlugAppArmor = `
+# Description: Allow access to vsock sockets for communication between VM
+# guests and their host or hypervisor (AF_VSOCK).
+network vsock,
+
+# guest side
+/dev/vsock rw,
+# host side, see drivers/vhost/vsock.c
+/dev/vhost-vsock rw,
+`
+
+const vsockConnectedPlugSecComp = `
+# Description: Allow access to vsock sockets for host/guest communication.
+# socket AF_VSOCK is already permitted by the default seccomp template.
+bind
+listen
+accept
+accept4
+`
+
+var vsockConnectedPlugUDev = []string{
+	`KERNEL=="vsock"`,
+	`SUBSYSTEM=="misc", KERNEL=="vhost-vsock"`,
+}
+
+func init() {
+	registerIface(&commonInterface{
+		name:                  "vsock",
+		summary:               vsockSummary,
+		consumers:             "VM agents and hypervisors talking over vsock",
+		implicitOnCore:        true,
+		implicitOnClassic:     true,
+		baseDeclarationSlots:  vsockBaseDeclarationSlots,
+		connectedPlugAppArmor: vsockConnectedPlugAppArmor,
+		connectedPlugSecComp:  vsockConnectedPlugSecComp,
+		connectedPlugUDev:     vsockConnectedPlugUDev,
+	})
+}
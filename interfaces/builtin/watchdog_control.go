@@ -0,0 +1,142 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/udev"
+	"github.com/snapcore/snapd/snap"
+)
+
+// https://www.kernel.org/doc/html/latest/watchdog/watchdog-api.html
+const watchdogControlSummary = `allows feeding and configuring hardware watchdogs`
+
+const watchdogControlBaseDeclarationSlots = `
+  watchdog-control:
+    allow-installation:
+      slot-snap-type:
+        - core
+        - gadget
+    deny-auto-connection: true
+`
+
+// Without a path attribute the slot grants access to all the watchdogs.
+const watchdogControlConnectedPlugAppArmor = `
+# Description: allow feeding and configuring all the hardware watchdogs, the
+# ioctls of the watchdog API are covered by the write access.
+/dev/watchdog[0-9]* rw,
+`
+
+const watchdogControlConnectedPlugAppArmorCommon = `
+# Allow reading the identity, status and timeouts of the watchdogs
+/sys/class/watchdog/ r,
+/sys/devices/**/watchdog/watchdog[0-9]*/{,*} r,
+`
+
+type watchdogControlInterface struct{}
+
+func (iface *watchdogControlInterface) Name() string {
+	return "watchdog-control"
+}
+
+func (iface *watchdogControlInterface) StaticInfo() interfaces.StaticInfo {
+	return interfaces.StaticInfo{
+		Summary:              watchdogControlSummary,
+		ImplicitOnCore:       true,
+		ImplicitOnClassic:    true,
+		BaseDeclarationSlots: watchdogControlBaseDeclarationSlots,
+		Consumers:            "daemons feeding hardware watchdogs",
+	}
+}
+
+var watchdogControlPattern = regexp.MustCompile(`^/dev/watchdog[0-9]*$`)
+
+// path returns the watchdog device selected by the slot, or the empty
+// string if the slot grants access to all the watchdogs.
+func (iface *watchdogControlInterface) path(slotRef *interfaces.SlotRef, attrs interfaces.Attrer) (string, error) {
+	var path string
+	if err := attrs.Attr("path", &path); err != nil {
+		if errors.Is(err, snap.AttributeNotFoundError{}) {
+			return "", nil
+		}
+		return "", fmt.Errorf("slot %q path attribute must be a string", slotRef)
+	}
+	if cleanPath := filepath.Clean(path); cleanPath != path {
+		return "", fmt.Errorf(`cannot use slot %q path %q: try %q`, slotRef, path, cleanPath)
+	}
+	if !watchdogControlPattern.MatchString(path) {
+		return "", fmt.Errorf("slot %q path attribute must be a valid watchdog device node", slotRef)
+	}
+	return path, nil
+}
+
+func (iface *watchdogControlInterface) BeforePrepareSlot(slot *snap.SlotInfo) error {
+	_, err := iface.path(&interfaces.SlotRef{Snap: slot.Snap.InstanceName(), Name: slot.Name}, slot)
+	return err
+}
+
+func (iface *watchdogControlInterface) AppArmorConnectedPlug(spec *apparmor.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	path, err := iface.path(slot.Ref(), slot)
+	if err != nil {
+		return nil
+	}
+	if path == "" {
+		spec.AddSnippet(watchdogControlConnectedPlugAppArmor)
+	} else {
+		spec.AddSnippet(fmt.Sprintf("%s rw,", path))
+	}
+	spec.AddDeduplicatedSnippet(watchdogControlConnectedPlugAppArmorCommon)
+	return nil
+}
+
+func (iface *watchdogControlInterface) UDevConnectedPlug(spec *udev.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	path, err := iface.path(slot.Ref(), slot)
+	if err != nil {
+		return nil
+	}
+	// The legacy /dev/watchdog node of the first watchdog is a misc device
+	// while /dev/watchdog[0-9]+ nodes belong to the watchdog subsystem.
+	switch {
+	case path == "":
+		spec.TagDevice(`SUBSYSTEM=="misc", KERNEL=="watchdog"`)
+		spec.TagDevice(`SUBSYSTEM=="watchdog"`)
+	case path == "/dev/watchdog":
+		spec.TagDevice(`SUBSYSTEM=="misc", KERNEL=="watchdog"`)
+	default:
+		spec.TagDevice(fmt.Sprintf(`SUBSYSTEM=="watchdog", KERNEL=="%s"`, strings.TrimPrefix(path, "/dev/")))
+	}
+	return nil
+}
+
+func (iface *watchdogControlInterface) AutoConnect(*snap.PlugInfo, *snap.SlotInfo) bool {
+	// Allow what is allowed in the declarations
+	return true
+}
+
+func init() {
+	registerIface(&watchdogControlInterface{})
+}
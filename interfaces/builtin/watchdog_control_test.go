@@ -0,0 +1,188 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin_test
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/udev"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type watchdogControlInterfaceSuite struct {
+	testutil.BaseTest
+	iface          interfaces.Interface
+	coreSlotInfo   *snap.SlotInfo
+	coreSlot       *interfaces.ConnectedSlot
+	gadgetSlotInfo *snap.SlotInfo
+	gadgetSlot     *interfaces.ConnectedSlot
+	legacySlotInfo *snap.SlotInfo
+	legacySlot     *interfaces.ConnectedSlot
+	plugInfo       *snap.PlugInfo
+	plug           *interfaces.ConnectedPlug
+}
+
+var _ = Suite(&watchdogControlInterfaceSuite{
+	iface: builtin.MustInterface("watchdog-control"),
+})
+
+const watchdogControlConsumerYaml = `name: consumer
+version: 0
+apps:
+  app:
+    plugs: [watchdog-control]
+`
+
+const watchdogControlCoreYaml = `name: core
+version: 0
+type: os
+slots:
+  watchdog-control:
+`
+
+const watchdogControlGadgetYaml = `name: gadget
+version: 0
+type: gadget
+slots:
+  watchdog-1:
+    interface: watchdog-control
+    path: /dev/watchdog1
+  watchdog-legacy:
+    interface: watchdog-control
+    path: /dev/watchdog
+`
+
+func (s *watchdogControlInterfaceSuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
+	s.plug, s.plugInfo = MockConnectedPlug(c, watchdogControlConsumerYaml, nil, "watchdog-control")
+	s.coreSlot, s.coreSlotInfo = MockConnectedSlot(c, watchdogControlCoreYaml, nil, "watchdog-control")
+	s.gadgetSlot, s.gadgetSlotInfo = MockConnectedSlot(c, watchdogControlGadgetYaml, nil, "watchdog-1")
+	s.legacySlot, s.legacySlotInfo = MockConnectedSlot(c, watchdogControlGadgetYaml, nil, "watchdog-legacy")
+}
+
+func (s *watchdogControlInterfaceSuite) TestName(c *C) {
+	c.Assert(s.iface.Name(), Equals, "watchdog-control")
+}
+
+func (s *watchdogControlInterfaceSuite) TestSanitizeSlot(c *C) {
+	c.Assert(interfaces.BeforePrepareSlot(s.iface, s.coreSlotInfo), IsNil)
+	c.Assert(interfaces.BeforePrepareSlot(s.iface, s.gadgetSlotInfo), IsNil)
+	c.Assert(interfaces.BeforePrepareSlot(s.iface, s.legacySlotInfo), IsNil)
+
+	for _, t := range []struct {
+		path string
+		err  string
+	}{
+		{`/dev/foo`, `slot "broken-gadget:watchdog-control" path attribute must be a valid watchdog device node`},
+		{`/dev/watchdog1/`, `cannot use slot "broken-gadget:watchdog-control" path "/dev/watchdog1/": try "/dev/watchdog1"`},
+		{`/dev/../dev/watchdog1`, `cannot use slot "broken-gadget:watchdog-control" path "/dev/../dev/watchdog1": try "/dev/watchdog1"`},
+		{`/dev/watchdogs`, `slot "broken-gadget:watchdog-control" path attribute must be a valid watchdog device node`},
+		{`[1]`, `slot "broken-gadget:watchdog-control" path attribute must be a string`},
+	} {
+		brokenSlot := snaptest.MockInfo(c, fmt.Sprintf(`
+name: broken-gadget
+version: 1
+type: gadget
+slots:
+  watchdog-control:
+    path: %s
+`, t.path), nil).Slots["watchdog-control"]
+		c.Check(interfaces.BeforePrepareSlot(s.iface, brokenSlot), ErrorMatches, regexp.QuoteMeta(t.err), Commentf("path %s", t.path))
+	}
+}
+
+func (s *watchdogControlInterfaceSuite) TestSanitizePlug(c *C) {
+	c.Assert(interfaces.BeforePreparePlug(s.iface, s.plugInfo), IsNil)
+}
+
+func (s *watchdogControlInterfaceSuite) TestAppArmorSpecAllWatchdogs(c *C) {
+	spec := apparmor.NewSpecification(s.plug.AppSet())
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, s.coreSlot), IsNil)
+	c.Assert(spec.SecurityTags(), DeepEquals, []string{"snap.consumer.app"})
+	c.Check(spec.SnippetForTag("snap.consumer.app"), testutil.Contains, "/dev/watchdog[0-9]* rw,\n")
+	c.Check(spec.SnippetForTag("snap.consumer.app"), testutil.Contains, "/sys/devices/**/watchdog/watchdog[0-9]*/{,*} r,\n")
+}
+
+func (s *watchdogControlInterfaceSuite) TestAppArmorSpecSpecificWatchdogs(c *C) {
+	spec := apparmor.NewSpecification(s.plug.AppSet())
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, s.gadgetSlot), IsNil)
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, s.legacySlot), IsNil)
+	c.Assert(spec.SecurityTags(), DeepEquals, []string{"snap.consumer.app"})
+	snippet := spec.SnippetForTag("snap.consumer.app")
+	c.Check(snippet, testutil.Contains, "/dev/watchdog1 rw,\n")
+	c.Check(snippet, testutil.Contains, "/dev/watchdog rw,\n")
+	c.Check(snippet, Not(testutil.Contains), "/dev/watchdog[0-9]* rw,")
+	// the common rules are not repeated
+	c.Check(snippet, testutil.Contains, "/sys/class/watchdog/ r,\n")
+	c.Check(strings.Count(snippet, "/sys/class/watchdog/ r,"), Equals, 1)
+}
+
+func (s *watchdogControlInterfaceSuite) TestUDevSpec(c *C) {
+	helper := fmt.Sprintf(`TAG=="snap_consumer_app", SUBSYSTEM!="module", SUBSYSTEM!="subsystem", RUN+="%v/snap-device-helper $env{ACTION} snap_consumer_app $devpath $major:$minor"`, dirs.DistroLibExecDir)
+
+	spec := udev.NewSpecification(s.plug.AppSet())
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, s.coreSlot), IsNil)
+	c.Assert(spec.Snippets(), HasLen, 3)
+	c.Check(spec.Snippets(), testutil.Contains, `# watchdog-control
+SUBSYSTEM=="misc", KERNEL=="watchdog", TAG+="snap_consumer_app"`)
+	c.Check(spec.Snippets(), testutil.Contains, `# watchdog-control
+SUBSYSTEM=="watchdog", TAG+="snap_consumer_app"`)
+	c.Check(spec.Snippets(), testutil.Contains, helper)
+
+	spec = udev.NewSpecification(s.plug.AppSet())
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, s.gadgetSlot), IsNil)
+	c.Assert(spec.Snippets(), HasLen, 2)
+	c.Check(spec.Snippets(), testutil.Contains, `# watchdog-control
+SUBSYSTEM=="watchdog", KERNEL=="watchdog1", TAG+="snap_consumer_app"`)
+	c.Check(spec.Snippets(), testutil.Contains, helper)
+
+	spec = udev.NewSpecification(s.plug.AppSet())
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, s.legacySlot), IsNil)
+	c.Assert(spec.Snippets(), HasLen, 2)
+	c.Check(spec.Snippets(), testutil.Contains, `# watchdog-control
+SUBSYSTEM=="misc", KERNEL=="watchdog", TAG+="snap_consumer_app"`)
+	c.Check(spec.Snippets(), testutil.Contains, helper)
+}
+
+func (s *watchdogControlInterfaceSuite) TestStaticInfo(c *C) {
+	si := interfaces.StaticInfoOf(s.iface)
+	c.Assert(si.ImplicitOnCore, Equals, true)
+	c.Assert(si.ImplicitOnClassic, Equals, true)
+	c.Assert(si.Summary, Equals, `allows feeding and configuring hardware watchdogs`)
+	c.Assert(si.BaseDeclarationSlots, testutil.Contains, "watchdog-control")
+}
+
+func (s *watchdogControlInterfaceSuite) TestAutoConnect(c *C) {
+	c.Check(s.iface.AutoConnect(nil, nil), Equals, true)
+}
+
+func (s *watchdogControlInterfaceSuite) TestInterfaces(c *C) {
+	c.Check(builtin.Interfaces(), testutil.DeepContains, s.iface)
+}
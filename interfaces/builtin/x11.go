@@ -269,6 +269,7 @@ func init() {
 	registerIface(&x11Interface{commonInterface{
 		name:                  "x11",
 		summary:               x11Summary,
+		consumers:             "graphical applications not supporting Wayland",
 		implicitOnClassic:     true,
 		baseDeclarationSlots:  x11BaseDeclarationSlots,
 		connectedPlugAppArmor: x11ConnectedPlugAppArmor,
@@ -0,0 +1,75 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin
+
+/*
+ * zram-control: allow snaps which manage compressed memory to configure
+ * zram devices. Access is limited to the zram block devices, their sysfs
+ * attributes, the zram-control class used to add and remove devices and
+ * the zram module parameters.
+ */
+
+const zramControlSummary = `allows configuring zram devices`
+
+const zramControlBaseDeclarationSlots = `
+  zram-control:
+    allow-installation:
+      slot-snap-type:
+        - core
+    deny-auto-connection: true
+`
+
+const zramControlConnectedPlugAppArmor = `
+# Description: Allow configuring zram devices. See
+# https://docs.kernel.org/admin-guide/blockdev/zram.html for details.
+
+# zram devices (up to 100 devices)
+/dev/zram[0-9]{,[0-9]} rw,
+
+# Device attributes, eg. disksize, comp_algorithm, mem_limit, reset
+/sys/block/ r,
+/sys/devices/virtual/block/zram[0-9]*/ r,
+/sys/devices/virtual/block/zram[0-9]*/** rw,
+
+# Allocate and free zram devices
+/sys/class/zram-control/ r,
+/sys/class/zram-control/hot_add r,
+/sys/class/zram-control/hot_remove w,
+
+# Module parameters
+/sys/module/zram/parameters/ r,
+/sys/module/zram/parameters/* r,
+`
+
+var zramControlConnectedPlugUDev = []string{
+	`SUBSYSTEM=="block", KERNEL=="zram[0-9]*"`,
+}
+
+func init() {
+	registerIface(&commonInterface{
+		name:                  "zram-control",
+		summary:               zramControlSummary,
+		implicitOnCore:        true,
+		implicitOnClassic:     true,
+		baseDeclarationSlots:  zramControlBaseDeclarationSlots,
+		connectedPlugAppArmor: zramControlConnectedPlugAppArmor,
+		connectedPlugUDev:     zramControlConnectedPlugUDev,
+	})
+}
@@ -0,0 +1,151 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package compatibility
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// anyMinor is the minor version of the upper bound of ranges which accept
+// any minor version of their last major version.
+const anyMinor = math.MaxUint32
+
+// Version is a MAJOR.MINOR version.
+type Version struct {
+	Major uint
+	Minor uint
+}
+
+func (v Version) less(other Version) bool {
+	if v.Major != other.Major {
+		return v.Major < other.Major
+	}
+	return v.Minor < other.Minor
+}
+
+// VersionRange is an inclusive range of MAJOR.MINOR versions, with Min <= Max.
+type VersionRange struct {
+	Min Version
+	Max Version
+}
+
+// ParseVersionRange parses a version range. A range is either a single bound
+// or two bounds separated by a dash, like "2.1-3.x". Each bound is either
+// MAJOR.MINOR, which is a single version, or MAJOR.x (or just MAJOR), which
+// is any version with that major version.
+func ParseVersionRange(s string) (VersionRange, error) {
+	versionError := func(msg string) error { return fmt.Errorf("version range %q: %s", s, msg) }
+
+	low, high, isRange := strings.Cut(s, "-")
+	if !isRange {
+		high = low
+	}
+	min, _, err := parseVersionBound(low)
+	if err != nil {
+		return VersionRange{}, versionError(err.Error())
+	}
+	_, max, err := parseVersionBound(high)
+	if err != nil {
+		return VersionRange{}, versionError(err.Error())
+	}
+	if max.less(min) {
+		return VersionRange{}, versionError("lower bound is greater than upper bound")
+	}
+	return VersionRange{Min: min, Max: max}, nil
+}
+
+// parseVersionBound returns the lowest and the highest versions matched by
+// the given bound.
+func parseVersionBound(s string) (low, high Version, err error) {
+	majorStr, minorStr, hasMinor := strings.Cut(s, ".")
+	major, err := parseVersionNumber(majorStr)
+	if err != nil {
+		return Version{}, Version{}, err
+	}
+	if !hasMinor || minorStr == "x" {
+		return Version{Major: major}, Version{Major: major, Minor: anyMinor}, nil
+	}
+	minor, err := parseVersionNumber(minorStr)
+	if err != nil {
+		return Version{}, Version{}, err
+	}
+	v := Version{Major: major, Minor: minor}
+	return v, v, nil
+}
+
+func parseVersionNumber(s string) (uint, error) {
+	if s == "" {
+		return 0, fmt.Errorf("missing version number")
+	}
+	// reject signs and other forms accepted by strconv
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return 0, fmt.Errorf("invalid version number %q", s)
+		}
+	}
+	n, err := strconv.ParseUint(s, 10, 32)
+	if err != nil || n == anyMinor {
+		return 0, fmt.Errorf("version number %q is too large", s)
+	}
+	return uint(n), nil
+}
+
+func (v Version) String() string {
+	if v.Minor == anyMinor {
+		return fmt.Sprintf("%d.x", v.Major)
+	}
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+func (r VersionRange) String() string {
+	if r.Min == (Version{Major: r.Max.Major}) && r.Max.Minor == anyMinor {
+		return r.Max.String()
+	}
+	if r.Min == r.Max {
+		return r.Min.String()
+	}
+	return r.Min.String() + "-" + r.Max.String()
+}
+
+// Overlaps returns whether there is a version which is in both ranges.
+func (r VersionRange) Overlaps(other VersionRange) bool {
+	return !r.Max.less(other.Min) && !other.Max.less(r.Min)
+}
+
+// VersionRangesOverlap returns whether the version ranges described by the
+// given strings overlap. An empty string is taken to mean any version. An
+// error is returned if either range is invalid.
+func VersionRangesOverlap(range1, range2 string) (bool, error) {
+	if range1 == "" || range2 == "" {
+		return true, nil
+	}
+	r1, err := ParseVersionRange(range1)
+	if err != nil {
+		return false, err
+	}
+	r2, err := ParseVersionRange(range2)
+	if err != nil {
+		return false, err
+	}
+	return r1.Overlaps(r2), nil
+}
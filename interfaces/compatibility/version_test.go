@@ -0,0 +1,94 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package compatibility_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces/compatibility"
+)
+
+func (s *CompatSuite) TestParseVersionRange(c *C) {
+	for _, tc := range []struct {
+		in       string
+		min, max compatibility.Version
+		str      string
+	}{
+		{"2", compatibility.Version{Major: 2}, compatibility.Version{Major: 2, Minor: 4294967295}, "2.x"},
+		{"2.x", compatibility.Version{Major: 2}, compatibility.Version{Major: 2, Minor: 4294967295}, "2.x"},
+		{"2.3", compatibility.Version{Major: 2, Minor: 3}, compatibility.Version{Major: 2, Minor: 3}, "2.3"},
+		{"2.0", compatibility.Version{Major: 2}, compatibility.Version{Major: 2}, "2.0"},
+		{"2.1-3.x", compatibility.Version{Major: 2, Minor: 1}, compatibility.Version{Major: 3, Minor: 4294967295}, "2.1-3.x"},
+		{"2-3", compatibility.Version{Major: 2}, compatibility.Version{Major: 3, Minor: 4294967295}, "2.0-3.x"},
+		{"1.2-1.10", compatibility.Version{Major: 1, Minor: 2}, compatibility.Version{Major: 1, Minor: 10}, "1.2-1.10"},
+	} {
+		comment := Commentf("%q", tc.in)
+		r, err := compatibility.ParseVersionRange(tc.in)
+		c.Assert(err, IsNil, comment)
+		c.Check(r, Equals, compatibility.VersionRange{Min: tc.min, Max: tc.max}, comment)
+		c.Check(r.String(), Equals, tc.str, comment)
+	}
+}
+
+func (s *CompatSuite) TestParseVersionRangeErrors(c *C) {
+	for _, tc := range []struct {
+		in  string
+		err string
+	}{
+		{"", `version range "": missing version number`},
+		{"x", `version range "x": invalid version number "x"`},
+		{"2.", `version range "2.": missing version number`},
+		{"2.3.4", `version range "2.3.4": invalid version number "3.4"`},
+		{"+2", `version range "\+2": invalid version number "\+2"`},
+		{"2-", `version range "2-": missing version number`},
+		{"2-3-4", `version range "2-3-4": invalid version number "3-4"`},
+		{"3-2.x", `version range "3-2.x": lower bound is greater than upper bound`},
+		{"2.5-2.4", `version range "2.5-2.4": lower bound is greater than upper bound`},
+		{"99999999999", `version range "99999999999": version number "99999999999" is too large`},
+	} {
+		_, err := compatibility.ParseVersionRange(tc.in)
+		c.Check(err, ErrorMatches, tc.err, Commentf("%q", tc.in))
+	}
+}
+
+func (s *CompatSuite) TestVersionRangesOverlap(c *C) {
+	for _, tc := range []struct {
+		r1, r2  string
+		overlap bool
+	}{
+		{"2.x", "2.3", true},
+		{"2.3", "2.x", true},
+		{"2.x", "3.x", false},
+		{"2.4-2.x", "2.3", false},
+		{"2.3-3.1", "3.0", true},
+		{"2.3-3.1", "3.2-4.x", false},
+		{"1-2", "2.9", true},
+		{"", "2.x", true},
+		{"2.x", "", true},
+		{"", "", true},
+	} {
+		overlap, err := compatibility.VersionRangesOverlap(tc.r1, tc.r2)
+		c.Assert(err, IsNil)
+		c.Check(overlap, Equals, tc.overlap, Commentf("%q %q", tc.r1, tc.r2))
+	}
+
+	_, err := compatibility.VersionRangesOverlap("2.x", "foo")
+	c.Check(err, ErrorMatches, `version range "foo": invalid version number "foo"`)
+}
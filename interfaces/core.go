@@ -124,6 +124,12 @@ type Info struct {
 	DocURL  string
 	Plugs   []*snap.PlugInfo
 	Slots   []*snap.SlotInfo
+
+	// The following are only set when documentation is requested.
+	RequiredPlugAttrs []string
+	RequiredSlotAttrs []string
+	Consumers         string
+	SecurityNotes     []string
 }
 
 // ConnRef holds information about plug and slot reference that form a particular connection.
@@ -297,6 +303,17 @@ type StaticInfo struct {
 	// Similarly, AppArmorUnconfinedSlots results in the snap that slots this interface
 	// being granted the AppArmor unconfined profile mode
 	AppArmorUnconfinedSlots bool
+
+	// RequiredPlugAttrs lists the attributes that a plug of this interface
+	// must have. The builtin interface tests check that BeforePreparePlug
+	// rejects plugs that miss any of them.
+	RequiredPlugAttrs []string
+	// RequiredSlotAttrs lists the attributes that a slot of this interface
+	// must have, checked like RequiredPlugAttrs.
+	RequiredSlotAttrs []string
+	// Consumers describes the kind of snaps that typically plug this
+	// interface.
+	Consumers string
 }
 
 // PlugServicesSnippetSection is the target systemd unit section for
@@ -0,0 +1,84 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package denials
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// aareToRegexp converts an AppArmor path pattern to an anchored regular
+// expression. Variables are replaced by their value in vars, which are
+// regular expressions, or match a single path component if not known.
+func aareToRegexp(pattern string, vars map[string]string) (*regexp.Regexp, error) {
+	var re strings.Builder
+	re.WriteString("^")
+	depth := 0
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			re.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		case c == '*' && strings.HasPrefix(pattern[i:], "**"):
+			i++
+			re.WriteString(".*")
+		case c == '*':
+			re.WriteString("[^/]*")
+		case c == '?':
+			re.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(pattern[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated character class in %q", pattern)
+			}
+			re.WriteString(pattern[i : i+end+1])
+			i += end
+		case c == '@' && strings.HasPrefix(pattern[i:], "@{"):
+			end := strings.IndexByte(pattern[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated variable in %q", pattern)
+			}
+			name := pattern[i+2 : i+end]
+			if value, ok := vars[name]; ok {
+				re.WriteString(value)
+			} else {
+				re.WriteString("[^/]*")
+			}
+			i += end
+		case c == '{':
+			depth++
+			re.WriteString("(?:")
+		case c == ',' && depth > 0:
+			re.WriteString("|")
+		case c == '}' && depth > 0:
+			depth--
+			re.WriteString(")")
+		default:
+			re.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced alternation in %q", pattern)
+	}
+	re.WriteString("$")
+	return regexp.Compile(re.String())
+}
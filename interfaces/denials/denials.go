@@ -0,0 +1,243 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package denials parses the AppArmor and seccomp denials logged by the
+// kernel and finds the interfaces whose connection would grant the denied
+// access.
+package denials
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Kind is the kind of access that was denied.
+type Kind string
+
+const (
+	File       Kind = "file"
+	Capability Kind = "capability"
+	Network    Kind = "network"
+	DBus       Kind = "dbus"
+	Syscall    Kind = "syscall"
+)
+
+// Denial describes an access denied to an application of a snap.
+type Denial struct {
+	Kind Kind
+	// Label is the security tag of the application, e.g. snap.foo.app,
+	// when it is known.
+	Label string
+	// Operation is the operation that was denied, e.g. open.
+	Operation string
+
+	// Path is the file denied, or the path of the D-Bus object.
+	Path string
+	// Mask holds the denied permissions, for files and D-Bus.
+	Mask string
+	// Owner is set when the file is owned by the user accessing it.
+	Owner bool
+
+	// Capability is the name of the denied capability.
+	Capability string
+
+	// Family and SockType describe the denied socket.
+	Family   string
+	SockType string
+
+	// Bus, Interface and Member describe the denied D-Bus message.
+	Bus       string
+	Interface string
+	Member    string
+
+	// Arch and SyscallNumber identify the denied system call, as found in
+	// the audit record, SyscallName is set once it is resolved.
+	Arch          string
+	SyscallNumber string
+	SyscallName   string
+
+	// Count is how many times the same access was denied.
+	Count int
+}
+
+func (d *Denial) String() string {
+	switch d.Kind {
+	case File:
+		return fmt.Sprintf("file %s (%s)", d.Path, d.Mask)
+	case Capability:
+		return fmt.Sprintf("capability %s", d.Capability)
+	case Network:
+		if d.SockType == "" {
+			return fmt.Sprintf("network %s", d.Family)
+		}
+		return fmt.Sprintf("network %s %s", d.Family, d.SockType)
+	case DBus:
+		return fmt.Sprintf("dbus %s bus=%s path=%s interface=%s member=%s", d.Mask, d.Bus, d.Path, d.Interface, d.Member)
+	case Syscall:
+		if d.SyscallName != "" {
+			return fmt.Sprintf("syscall %s", d.SyscallName)
+		}
+		return fmt.Sprintf("syscall %s (arch %s)", d.SyscallNumber, d.Arch)
+	}
+	return string(d.Kind)
+}
+
+// key identifies the denials considered the same.
+func (d *Denial) key() string {
+	return d.Label + "\x00" + d.String()
+}
+
+// fields of audit records, also when nested in the msg='...' field of records
+// logged by user space, values are either quoted or not, in which case
+// strings that could contain spaces or quotes are hex encoded
+var fieldRE = regexp.MustCompile(`(?:^|[\s'])([a-z_]+)=("[^"]*"|[^\s"']+)`)
+
+func parseFields(line string) map[string]string {
+	fields := make(map[string]string)
+	for _, m := range fieldRE.FindAllStringSubmatch(line, -1) {
+		fields[m[1]] = m[2]
+	}
+	return fields
+}
+
+// stringField returns the value of a field holding a string, which the
+// kernel quotes or hex encodes.
+func stringField(fields map[string]string, name string) string {
+	v, ok := fields[name]
+	if !ok {
+		return ""
+	}
+	if strings.HasPrefix(v, `"`) {
+		return strings.Trim(v, `"`)
+	}
+	if decoded, err := hex.DecodeString(v); err == nil {
+		return string(decoded)
+	}
+	return v
+}
+
+// belongsTo returns whether the security tag is the one of an application
+// or hook of the given snap.
+func belongsTo(label, snapName string) bool {
+	label = strings.TrimSuffix(label, " (enforce)")
+	label = strings.TrimSuffix(label, " (complain)")
+	return strings.HasPrefix(label, "snap."+snapName+".")
+}
+
+func parseAppArmorDenial(fields map[string]string, snapName string) *Denial {
+	if v := stringField(fields, "apparmor"); v != "DENIED" && v != "ALLOWED" {
+		return nil
+	}
+	label := stringField(fields, "profile")
+	if label == "" || strings.HasPrefix(stringField(fields, "operation"), "dbus_") {
+		// D-Bus denials are logged by the bus
+		label = stringField(fields, "label")
+	}
+	if !belongsTo(label, snapName) {
+		return nil
+	}
+
+	d := &Denial{
+		Label:     label,
+		Operation: stringField(fields, "operation"),
+	}
+	switch {
+	case strings.HasPrefix(d.Operation, "dbus_"):
+		d.Kind = DBus
+		d.Bus = stringField(fields, "bus")
+		d.Path = stringField(fields, "path")
+		d.Interface = stringField(fields, "interface")
+		d.Member = stringField(fields, "member")
+		d.Mask = stringField(fields, "mask")
+	case d.Operation == "capable":
+		d.Kind = Capability
+		d.Capability = stringField(fields, "capname")
+	case stringField(fields, "family") != "":
+		d.Kind = Network
+		d.Family = stringField(fields, "family")
+		d.SockType = stringField(fields, "sock_type")
+	case stringField(fields, "name") != "":
+		d.Kind = File
+		d.Path = stringField(fields, "name")
+		d.Mask = strings.Trim(stringField(fields, "denied_mask"), ":")
+		d.Owner = fields["fsuid"] != "" && fields["fsuid"] == fields["ouid"]
+	default:
+		return nil
+	}
+	return d
+}
+
+func parseSeccompDenial(fields map[string]string, snapName string) *Denial {
+	// depending on the kernel the label of the application is logged, the
+	// executable is always logged
+	label := stringField(fields, "subj")
+	if !belongsTo(label, snapName) {
+		label = ""
+		if !strings.HasPrefix(stringField(fields, "exe"), "/snap/"+snapName+"/") {
+			return nil
+		}
+	}
+	if fields["syscall"] == "" || fields["arch"] == "" {
+		return nil
+	}
+	return &Denial{
+		Kind:          Syscall,
+		Label:         label,
+		Arch:          fields["arch"],
+		SyscallNumber: fields["syscall"],
+	}
+}
+
+// Parse reads kernel or audit log lines and returns the AppArmor and seccomp
+// denials of the applications and hooks of the given snap, in the order
+// they were first seen. Repeated denials are counted once.
+func Parse(r io.Reader, snapName string) ([]*Denial, error) {
+	var denials []*Denial
+	seen := make(map[string]*Denial)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		var d *Denial
+		switch {
+		case strings.Contains(line, "apparmor="):
+			d = parseAppArmorDenial(parseFields(line), snapName)
+		case strings.Contains(line, "type=1326") || strings.Contains(line, "SECCOMP"):
+			d = parseSeccompDenial(parseFields(line), snapName)
+		}
+		if d == nil {
+			continue
+		}
+		if prev := seen[d.key()]; prev != nil {
+			prev.Count++
+			continue
+		}
+		d.Count = 1
+		seen[d.key()] = d
+		denials = append(denials, d)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return denials, nil
+}
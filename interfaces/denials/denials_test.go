@@ -0,0 +1,191 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package denials_test
+
+import (
+	"strings"
+	"testing"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces/denials"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type denialsSuite struct{}
+
+var _ = Suite(&denialsSuite{})
+
+const kernelLog = `Oct 16 12:00:00 host kernel: audit: type=1400 audit(1760616000.123:100): apparmor="DENIED" operation="open" class="file" profile="snap.foo.app" name="/etc/shadow" pid=1234 comm="app" requested_mask="r" denied_mask="r" fsuid=1000 ouid=0
+Oct 16 12:00:01 host kernel: audit: type=1400 audit(1760616001.123:101): apparmor="DENIED" operation="open" class="file" profile="snap.foo.app" name="/etc/shadow" pid=1234 comm="app" requested_mask="r" denied_mask="r" fsuid=1000 ouid=0
+Oct 16 12:00:01 host kernel: audit: type=1400 audit(1760616001.124:102): apparmor="DENIED" operation="open" class="file" profile="snap.foobar.app" name="/etc/shadow" pid=1235 comm="app" requested_mask="r" denied_mask="r" fsuid=1000 ouid=0
+Oct 16 12:00:02 host kernel: audit: type=1400 audit(1760616002.123:103): apparmor="DENIED" operation="mknod" class="file" profile="snap.foo.app" name=2F746D702F666F6F20626172 pid=1234 comm="app" requested_mask="c" denied_mask="c" fsuid=1000 ouid=1000
+Oct 16 12:00:03 host kernel: audit: type=1400 audit(1760616003.123:104): apparmor="DENIED" operation="capable" class="cap" profile="snap.foo.app" pid=1234 comm="app" capability=21 capname="sys_admin"
+Oct 16 12:00:04 host kernel: audit: type=1400 audit(1760616004.123:105): apparmor="DENIED" operation="create" class="net" profile="snap.foo.hook.configure" pid=1236 comm="configure" family="netlink" sock_type="raw" protocol=15 requested_mask="create" denied_mask="create"
+Oct 16 12:00:05 host audit[987]: USER_AVC pid=987 uid=103 auid=4294967295 ses=4294967295 subj=unconfined msg='apparmor="DENIED" operation="dbus_method_call"  bus="system" path="/org/freedesktop/hostname1" interface="org.freedesktop.DBus.Properties" member="GetAll" mask="send" name="org.freedesktop.hostname1" pid=1234 label="snap.foo.app" peer_pid=99 peer_label="unconfined" exe="/usr/bin/dbus-daemon" sauid=103 hostname=? addr=? terminal=?'
+Oct 16 12:00:06 host kernel: audit: type=1326 audit(1760616006.123:106): auid=1000 uid=1000 gid=1000 ses=2 subj=snap.foo.app pid=1234 comm="app" exe="/snap/foo/x1/bin/app" sig=0 arch=c000003e syscall=165 compat=0 ip=0x7f0000000000 code=0x50000
+Oct 16 12:00:07 host kernel: audit: type=1326 audit(1760616007.123:107): auid=1000 uid=1000 gid=1000 ses=2 subj=? pid=1237 comm="helper" exe="/snap/foo/x1/bin/helper" sig=0 arch=c000003e syscall=169 compat=0 ip=0x7f0000000000 code=0x50000
+Oct 16 12:00:08 host kernel: audit: type=1326 audit(1760616008.123:108): auid=1000 uid=1000 gid=1000 ses=2 subj=? pid=1238 comm="other" exe="/snap/bar/x1/bin/other" sig=0 arch=c000003e syscall=169 compat=0 ip=0x7f0000000000 code=0x50000
+Oct 16 12:00:09 host kernel: something unrelated
+`
+
+func (s *denialsSuite) TestParse(c *C) {
+	ds, err := denials.Parse(strings.NewReader(kernelLog), "foo")
+	c.Assert(err, IsNil)
+	c.Check(ds, DeepEquals, []*denials.Denial{{
+		Kind:      denials.File,
+		Label:     "snap.foo.app",
+		Operation: "open",
+		Path:      "/etc/shadow",
+		Mask:      "r",
+		Count:     2,
+	}, {
+		Kind:      denials.File,
+		Label:     "snap.foo.app",
+		Operation: "mknod",
+		Path:      "/tmp/foo bar",
+		Mask:      "c",
+		Owner:     true,
+		Count:     1,
+	}, {
+		Kind:       denials.Capability,
+		Label:      "snap.foo.app",
+		Operation:  "capable",
+		Capability: "sys_admin",
+		Count:      1,
+	}, {
+		Kind:      denials.Network,
+		Label:     "snap.foo.hook.configure",
+		Operation: "create",
+		Family:    "netlink",
+		SockType:  "raw",
+		Count:     1,
+	}, {
+		Kind:      denials.DBus,
+		Label:     "snap.foo.app",
+		Operation: "dbus_method_call",
+		Path:      "/org/freedesktop/hostname1",
+		Mask:      "send",
+		Bus:       "system",
+		Interface: "org.freedesktop.DBus.Properties",
+		Member:    "GetAll",
+		Count:     1,
+	}, {
+		Kind:          denials.Syscall,
+		Label:         "snap.foo.app",
+		Arch:          "c000003e",
+		SyscallNumber: "165",
+		Count:         1,
+	}, {
+		Kind:          denials.Syscall,
+		Arch:          "c000003e",
+		SyscallNumber: "169",
+		Count:         1,
+	}})
+}
+
+func (s *denialsSuite) TestParseNothing(c *C) {
+	ds, err := denials.Parse(strings.NewReader(kernelLog), "baz")
+	c.Assert(err, IsNil)
+	c.Check(ds, HasLen, 0)
+}
+
+func (s *denialsSuite) TestDenialString(c *C) {
+	for _, t := range []struct {
+		d *denials.Denial
+		s string
+	}{
+		{&denials.Denial{Kind: denials.File, Path: "/etc/shadow", Mask: "r"}, "file /etc/shadow (r)"},
+		{&denials.Denial{Kind: denials.Capability, Capability: "sys_admin"}, "capability sys_admin"},
+		{&denials.Denial{Kind: denials.Network, Family: "netlink", SockType: "raw"}, "network netlink raw"},
+		{&denials.Denial{Kind: denials.Network, Family: "inet"}, "network inet"},
+		{&denials.Denial{Kind: denials.DBus, Mask: "send", Bus: "system", Path: "/a", Interface: "b.c", Member: "D"}, "dbus send bus=system path=/a interface=b.c member=D"},
+		{&denials.Denial{Kind: denials.Syscall, Arch: "c000003e", SyscallNumber: "165"}, "syscall 165 (arch c000003e)"},
+		{&denials.Denial{Kind: denials.Syscall, Arch: "c000003e", SyscallNumber: "165", SyscallName: "mount"}, "syscall mount"},
+	} {
+		c.Check(t.d.String(), Equals, t.s)
+	}
+}
+
+func (s *denialsSuite) TestAareToRegexp(c *C) {
+	vars := denials.ProfileVars("foo")
+	for _, t := range []struct {
+		pattern string
+		matches []string
+		others  []string
+	}{
+		{"/etc/shadow", []string{"/etc/shadow"}, []string{"/etc/shadowx", "/etc/shado"}},
+		{"/dev/tty*", []string{"/dev/tty", "/dev/tty1"}, []string{"/dev/tty1/x"}},
+		{"/sys/**", []string{"/sys/a", "/sys/a/b/c"}, []string{"/sysx/a"}},
+		{"/dev/rtc[0-9]*", []string{"/dev/rtc0", "/dev/rtc12"}, []string{"/dev/rtc"}},
+		{"/dev/hidraw?", []string{"/dev/hidraw1"}, []string{"/dev/hidraw12"}},
+		{"/etc/{passwd,group{,-}}", []string{"/etc/passwd", "/etc/group", "/etc/group-"}, []string{"/etc/shadow"}},
+		{"@{HOME}/.config/foo/**", []string{"/home/user/.config/foo/a", "/root/.config/foo/a"}, []string{"/home/.config/foo/a"}},
+		{"@{PROC}/@{pid}/status", []string{"/proc/12/status"}, []string{"/proc/self/status"}},
+		{"/var/snap/@{SNAP_NAME}/@{SNAP_REVISION}/**", []string{"/var/snap/foo/x1/a"}, []string{"/var/snap/bar/x1/a"}},
+		{"/run/@{UNKNOWN}/x", []string{"/run/a/x"}, []string{"/run/a/b/x"}},
+		{`/a\{b`, []string{"/a{b"}, []string{"/ab"}},
+	} {
+		re, err := denials.AareToRegexp(t.pattern, vars)
+		c.Assert(err, IsNil, Commentf("%s", t.pattern))
+		for _, m := range t.matches {
+			c.Check(re.MatchString(m), Equals, true, Commentf("%s should match %s", t.pattern, m))
+		}
+		for _, o := range t.others {
+			c.Check(re.MatchString(o), Equals, false, Commentf("%s should not match %s", t.pattern, o))
+		}
+	}
+
+	for _, t := range []struct {
+		pattern string
+		err     string
+	}{
+		{"/dev/rtc[0-9", `unterminated character class in "/dev/rtc\[0-9"`},
+		{"/proc/@{pid", `unterminated variable in "/proc/@{pid"`},
+		{"/etc/{a,b", `unbalanced alternation in "/etc/{a,b"`},
+	} {
+		_, err := denials.AareToRegexp(t.pattern, vars)
+		c.Check(err, ErrorMatches, t.err)
+	}
+}
+
+func (s *denialsSuite) TestStatements(c *C) {
+	snippet := `
+# Description: comment
+/etc/shadow r,  # trailing comment
+owner @{HOME}/foo rw,
+dbus (send)
+    bus=system
+    path=/org/freedesktop/hostname1
+    member=Get{,All},
+
+profile nested (attach_disconnected) {
+  /nested/** rw,
+}
+capability sys_admin,
+`
+	c.Check(denials.Statements(snippet), DeepEquals, []string{
+		"/etc/shadow r",
+		"owner @{HOME}/foo rw",
+		"dbus (send) bus=system path=/org/freedesktop/hostname1 member=Get{,All}",
+		"capability sys_admin",
+	})
+}
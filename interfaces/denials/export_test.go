@@ -0,0 +1,46 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package denials
+
+var (
+	AareToRegexp = aareToRegexp
+	Statements   = statements
+	ProfileVars  = profileVars
+)
+
+type IfaceRules = ifaceRules
+
+// NewIfaceRules returns the rules of the given AppArmor and seccomp snippets
+// of an interface plug of the given snap.
+func NewIfaceRules(snapName, apparmorSnippet, seccompSnippet string) (*IfaceRules, error) {
+	r := &ifaceRules{
+		capabilities: make(map[string]bool),
+		syscalls:     make(map[string]bool),
+	}
+	if err := r.addAppArmorRules(apparmorSnippet, profileVars(snapName)); err != nil {
+		return nil, err
+	}
+	r.addSeccompRules(seccompSnippet)
+	return r, nil
+}
+
+func (r *IfaceRules) Grants(d *Denial) bool {
+	return r.grants(d)
+}
@@ -0,0 +1,406 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package denials
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/seccomp"
+	"github.com/snapcore/snapd/snap"
+)
+
+// Suggestion lists the interfaces whose connection would grant an access
+// that was denied. The list is empty when no interface grants it.
+type Suggestion struct {
+	Denial     *Denial
+	Interfaces []string
+}
+
+type fileRule struct {
+	path  *regexp.Regexp
+	perms string
+	owner bool
+}
+
+type networkRule struct {
+	// empty fields match anything
+	family   string
+	sockType string
+}
+
+type dbusRule struct {
+	// empty fields match anything
+	perms  []string
+	bus    *regexp.Regexp
+	path   *regexp.Regexp
+	iface  *regexp.Regexp
+	member *regexp.Regexp
+}
+
+// ifaceRules are the rules added to the profiles of a snap when a plug of
+// an interface is connected.
+type ifaceRules struct {
+	name            string
+	files           []fileRule
+	capabilities    map[string]bool
+	allCapabilities bool
+	network         []networkRule
+	dbus            []dbusRule
+	syscalls        map[string]bool
+}
+
+// profileVars returns the values, as regular expressions, of the variables
+// used in AppArmor rules.
+func profileVars(snapName string) map[string]string {
+	name := regexp.QuoteMeta(snapName)
+	return map[string]string{
+		"HOME":                  `(?:/home/[^/]+|/root)`,
+		"PROC":                  `/proc`,
+		"SNAP_NAME":             name,
+		"SNAP_INSTANCE_NAME":    name,
+		"SNAP_INSTANCE_DESKTOP": name,
+		"SNAP_REVISION":         `[^/]+`,
+		"INSTALL_DIR":           `(?:/snap|/var/lib/snapd/snap)`,
+		"multiarch":             `[^/]+-linux-gnu[^/]*`,
+		"pid":                   `[0-9]+`,
+		"pids":                  `[0-9]+`,
+		"tid":                   `[0-9]+`,
+	}
+}
+
+// statements returns the rules of an AppArmor snippet, without comments,
+// joined over multiple lines and without their final comma. Rules of
+// nested profiles and hats are skipped.
+func statements(snippet string) []string {
+	var stmts []string
+	var current []string
+	depth := 0
+	for _, line := range strings.Split(snippet, "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			continue
+		case strings.HasSuffix(line, "{"):
+			depth++
+			current = nil
+			continue
+		case line == "}":
+			depth--
+			current = nil
+			continue
+		case depth > 0:
+			continue
+		}
+		current = append(current, line)
+		if strings.HasSuffix(line, ",") {
+			stmt := strings.TrimSuffix(strings.Join(current, " "), ",")
+			stmts = append(stmts, stmt)
+			current = nil
+		}
+	}
+	return stmts
+}
+
+var dbusFieldRE = regexp.MustCompile(`\b(bus|path|interface|member)=("[^"]*"|\S+)`)
+
+func (r *ifaceRules) addDBusRule(stmt string, vars map[string]string) error {
+	var rule dbusRule
+	rest := strings.TrimSpace(strings.TrimPrefix(stmt, "dbus"))
+	switch {
+	case strings.HasPrefix(rest, "("):
+		end := strings.Index(rest, ")")
+		if end < 0 {
+			return fmt.Errorf("cannot parse %q", stmt)
+		}
+		rule.perms = strings.FieldsFunc(rest[1:end], func(r rune) bool { return r == ' ' || r == ',' })
+	default:
+		for _, perm := range []string{"send", "receive", "bind", "eavesdrop"} {
+			if strings.HasPrefix(rest, perm+" ") || rest == perm {
+				rule.perms = []string{perm}
+			}
+		}
+	}
+	for _, m := range dbusFieldRE.FindAllStringSubmatch(stmt, -1) {
+		re, err := aareToRegexp(strings.Trim(m[2], `"`), vars)
+		if err != nil {
+			return err
+		}
+		switch m[1] {
+		case "bus":
+			rule.bus = re
+		case "path":
+			rule.path = re
+		case "interface":
+			rule.iface = re
+		case "member":
+			rule.member = re
+		}
+	}
+	r.dbus = append(r.dbus, rule)
+	return nil
+}
+
+func (r *ifaceRules) addAppArmorRules(snippet string, vars map[string]string) error {
+	for _, stmt := range statements(snippet) {
+		fields := strings.Fields(stmt)
+		owner := false
+	qualifiers:
+		for len(fields) > 0 {
+			switch fields[0] {
+			case "audit", "allow":
+				fields = fields[1:]
+			case "owner":
+				owner = true
+				fields = fields[1:]
+			default:
+				break qualifiers
+			}
+		}
+		if len(fields) == 0 || fields[0] == "deny" {
+			continue
+		}
+
+		switch {
+		case fields[0] == "capability":
+			if len(fields) == 1 {
+				r.allCapabilities = true
+			}
+			for _, name := range fields[1:] {
+				r.capabilities[name] = true
+			}
+		case fields[0] == "network":
+			rule := networkRule{}
+			if len(fields) > 1 {
+				rule.family = fields[1]
+			}
+			if len(fields) > 2 {
+				rule.sockType = fields[2]
+			}
+			r.network = append(r.network, rule)
+		case fields[0] == "dbus":
+			if err := r.addDBusRule(stmt, vars); err != nil {
+				return err
+			}
+		case fields[0] == "file" && len(fields) == 1:
+			r.files = append(r.files, fileRule{path: regexp.MustCompile(".*"), perms: "rwalkmx", owner: owner})
+		case strings.HasPrefix(fields[0], "/") || strings.HasPrefix(fields[0], "@{") || strings.HasPrefix(fields[0], `"`):
+			if len(fields) < 2 {
+				continue
+			}
+			re, err := aareToRegexp(strings.Trim(fields[0], `"`), vars)
+			if err != nil {
+				return err
+			}
+			r.files = append(r.files, fileRule{path: re, perms: fields[1], owner: owner})
+		}
+	}
+	return nil
+}
+
+func (r *ifaceRules) addSeccompRules(snippet string) {
+	for _, line := range strings.Split(snippet, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], "~") || strings.HasPrefix(fields[0], "@") {
+			continue
+		}
+		// rules with argument filters only allow some calls, but
+		// connecting the interface may still help
+		r.syscalls[fields[0]] = true
+	}
+}
+
+// plugRules returns the rules added to the profile of an application of
+// the given snap when a plug of the interface, without attributes, is
+// connected to the slot of the system.
+func plugRules(iface interfaces.Interface, snapName string) (*ifaceRules, error) {
+	name := iface.Name()
+	plugSnap, err := snap.InfoFromSnapYaml([]byte(fmt.Sprintf(`name: %s
+version: 1
+apps:
+  app:
+    command: bin/app
+    plugs: [%s]
+`, snapName, name)))
+	if err != nil {
+		return nil, err
+	}
+	slotSnap, err := snap.InfoFromSnapYaml([]byte(fmt.Sprintf(`name: core
+version: 1
+type: os
+slots:
+  %s:
+`, name)))
+	if err != nil {
+		return nil, err
+	}
+	for _, info := range []*snap.Info{plugSnap, slotSnap} {
+		builtin.SanitizePlugsSlots(info)
+		if reason, ok := info.BadInterfaces[name]; ok {
+			return nil, fmt.Errorf("%s", reason)
+		}
+	}
+
+	plugAppSet, err := interfaces.NewSnapAppSet(plugSnap, nil)
+	if err != nil {
+		return nil, err
+	}
+	slotAppSet, err := interfaces.NewSnapAppSet(slotSnap, nil)
+	if err != nil {
+		return nil, err
+	}
+	plugInfo := plugSnap.Plugs[name]
+	plug := interfaces.NewConnectedPlug(plugInfo, plugAppSet, nil, nil)
+	slot := interfaces.NewConnectedSlot(slotSnap.Slots[name], slotAppSet, nil, nil)
+	add := func(spec interfaces.Specification) error {
+		if err := spec.AddPermanentPlug(iface, plugInfo); err != nil {
+			return err
+		}
+		return spec.AddConnectedPlug(iface, plug, slot)
+	}
+	tag := fmt.Sprintf("snap.%s.app", snapName)
+
+	r := &ifaceRules{
+		name:         name,
+		capabilities: make(map[string]bool),
+		syscalls:     make(map[string]bool),
+	}
+	aaSpec := apparmor.NewSpecification(plugAppSet)
+	if err := add(aaSpec); err != nil {
+		return nil, err
+	}
+	if err := r.addAppArmorRules(aaSpec.SnippetForTag(tag), profileVars(snapName)); err != nil {
+		return nil, err
+	}
+	seccompSpec := seccomp.NewSpecification(plugAppSet)
+	if err := add(seccompSpec); err != nil {
+		return nil, err
+	}
+	r.addSeccompRules(seccompSpec.SnippetForTag(tag))
+	return r, nil
+}
+
+// filePermsNeeded maps the permissions in AppArmor denials to the
+// permissions of rules granting them.
+var filePermsNeeded = map[rune]string{
+	'r': "r",
+	'w': "w",
+	'c': "w",
+	'd': "w",
+	'a': "aw",
+	'l': "l",
+	'k': "k",
+	'm': "m",
+	'x': "x",
+}
+
+func (r *ifaceRules) grants(d *Denial) bool {
+	switch d.Kind {
+	case File:
+		var perms string
+		for _, rule := range r.files {
+			if rule.owner && !d.Owner {
+				continue
+			}
+			if rule.path.MatchString(d.Path) {
+				perms += rule.perms
+			}
+		}
+		if perms == "" {
+			return false
+		}
+		for _, p := range d.Mask {
+			if needed, ok := filePermsNeeded[p]; ok && !strings.ContainsAny(perms, needed) {
+				return false
+			}
+		}
+		return true
+	case Capability:
+		return r.allCapabilities || r.capabilities[d.Capability]
+	case Network:
+		for _, rule := range r.network {
+			if (rule.family == "" || rule.family == d.Family) && (rule.sockType == "" || rule.sockType == d.SockType) {
+				return true
+			}
+		}
+		return false
+	case DBus:
+		for _, rule := range r.dbus {
+			if len(rule.perms) > 0 && !containsPerm(rule.perms, d.Mask) {
+				continue
+			}
+			if (rule.bus == nil || rule.bus.MatchString(d.Bus)) &&
+				(rule.path == nil || rule.path.MatchString(d.Path)) &&
+				(rule.iface == nil || rule.iface.MatchString(d.Interface)) &&
+				(rule.member == nil || rule.member.MatchString(d.Member)) {
+				return true
+			}
+		}
+		return false
+	case Syscall:
+		return d.SyscallName != "" && r.syscalls[d.SyscallName]
+	}
+	return false
+}
+
+func containsPerm(perms []string, perm string) bool {
+	for _, p := range perms {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// Suggest returns, for each of the denials of an application of the given
+// snap, the builtin interfaces whose connection would grant the denied
+// access. Interfaces that cannot be connected without attributes are not
+// considered.
+func Suggest(snapName string, denials []*Denial) []*Suggestion {
+	var rules []*ifaceRules
+	for _, iface := range builtin.Interfaces() {
+		r, err := plugRules(iface, snapName)
+		if err != nil {
+			continue
+		}
+		rules = append(rules, r)
+	}
+
+	suggestions := make([]*Suggestion, 0, len(denials))
+	for _, d := range denials {
+		s := &Suggestion{Denial: d}
+		for _, r := range rules {
+			if r.grants(d) {
+				s.Interfaces = append(s.Interfaces, r.name)
+			}
+		}
+		sort.Strings(s.Interfaces)
+		suggestions = append(suggestions, s)
+	}
+	return suggestions
+}
@@ -0,0 +1,139 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package denials_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces/denials"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type suggestSuite struct{}
+
+var _ = Suite(&suggestSuite{})
+
+const testAppArmorSnippet = `
+# Description: test rules
+/etc/foo.conf r,
+owner @{HOME}/.config/foo/** rw,
+/var/snap/@{SNAP_NAME}/common/*.lock k,
+/dev/bar[0-9]* rw,
+deny /etc/shadow r,
+capability sys_time,
+network netlink raw,
+network inet,
+dbus (send)
+    bus={session,system}
+    path=/org/freedesktop/hostname1
+    interface=org.freedesktop.DBus.Properties
+    member=Get{,All},
+
+profile helper {
+  /etc/secret r,
+}
+`
+
+const testSeccompSnippet = `
+# Description: test rules
+settimeofday
+~mount
+socket AF_NETLINK - NETLINK_AUDIT
+`
+
+func (s *suggestSuite) TestGrants(c *C) {
+	r, err := denials.NewIfaceRules("foo", testAppArmorSnippet, testSeccompSnippet)
+	c.Assert(err, IsNil)
+
+	for _, t := range []struct {
+		d      *denials.Denial
+		grants bool
+	}{
+		// files
+		{&denials.Denial{Kind: denials.File, Path: "/etc/foo.conf", Mask: "r"}, true},
+		{&denials.Denial{Kind: denials.File, Path: "/etc/foo.conf", Mask: "w"}, false},
+		{&denials.Denial{Kind: denials.File, Path: "/etc/foo.conf", Mask: "a"}, false},
+		{&denials.Denial{Kind: denials.File, Path: "/etc/shadow", Mask: "r"}, false},
+		{&denials.Denial{Kind: denials.File, Path: "/etc/secret", Mask: "r"}, false},
+		{&denials.Denial{Kind: denials.File, Path: "/home/user/.config/foo/a/b", Mask: "wc", Owner: true}, true},
+		{&denials.Denial{Kind: denials.File, Path: "/home/user/.config/foo/a/b", Mask: "a", Owner: true}, true},
+		{&denials.Denial{Kind: denials.File, Path: "/home/user/.config/foo/a/b", Mask: "r"}, false},
+		{&denials.Denial{Kind: denials.File, Path: "/var/snap/foo/common/x.lock", Mask: "k"}, true},
+		{&denials.Denial{Kind: denials.File, Path: "/var/snap/bar/common/x.lock", Mask: "k"}, false},
+		{&denials.Denial{Kind: denials.File, Path: "/dev/bar1", Mask: "rw"}, true},
+		{&denials.Denial{Kind: denials.File, Path: "/dev/bar1", Mask: "m"}, false},
+		// capabilities
+		{&denials.Denial{Kind: denials.Capability, Capability: "sys_time"}, true},
+		{&denials.Denial{Kind: denials.Capability, Capability: "sys_admin"}, false},
+		// network
+		{&denials.Denial{Kind: denials.Network, Family: "netlink", SockType: "raw"}, true},
+		{&denials.Denial{Kind: denials.Network, Family: "netlink", SockType: "dgram"}, false},
+		{&denials.Denial{Kind: denials.Network, Family: "inet", SockType: "stream"}, true},
+		{&denials.Denial{Kind: denials.Network, Family: "inet6", SockType: "stream"}, false},
+		// D-Bus
+		{&denials.Denial{Kind: denials.DBus, Mask: "send", Bus: "system", Path: "/org/freedesktop/hostname1", Interface: "org.freedesktop.DBus.Properties", Member: "GetAll"}, true},
+		{&denials.Denial{Kind: denials.DBus, Mask: "send", Bus: "session", Path: "/org/freedesktop/hostname1", Interface: "org.freedesktop.DBus.Properties", Member: "Get"}, true},
+		{&denials.Denial{Kind: denials.DBus, Mask: "send", Bus: "system", Path: "/org/freedesktop/hostname1", Interface: "org.freedesktop.DBus.Properties", Member: "Set"}, false},
+		{&denials.Denial{Kind: denials.DBus, Mask: "receive", Bus: "system", Path: "/org/freedesktop/hostname1", Interface: "org.freedesktop.DBus.Properties", Member: "Get"}, false},
+		// system calls
+		{&denials.Denial{Kind: denials.Syscall, SyscallNumber: "164", SyscallName: "settimeofday"}, true},
+		{&denials.Denial{Kind: denials.Syscall, SyscallNumber: "41", SyscallName: "socket"}, true},
+		{&denials.Denial{Kind: denials.Syscall, SyscallNumber: "165", SyscallName: "mount"}, false},
+		{&denials.Denial{Kind: denials.Syscall, SyscallNumber: "164"}, false},
+	} {
+		c.Check(r.Grants(t.d), Equals, t.grants, Commentf("%s", t.d))
+	}
+}
+
+func (s *suggestSuite) TestGrantsAllCapabilities(c *C) {
+	r, err := denials.NewIfaceRules("foo", "capability,\nfile,\n", "")
+	c.Assert(err, IsNil)
+	c.Check(r.Grants(&denials.Denial{Kind: denials.Capability, Capability: "sys_admin"}), Equals, true)
+	c.Check(r.Grants(&denials.Denial{Kind: denials.File, Path: "/etc/shadow", Mask: "rw"}), Equals, true)
+}
+
+func (s *suggestSuite) TestBadRules(c *C) {
+	_, err := denials.NewIfaceRules("foo", "/dev/foo[0-9 rw,\n", "")
+	c.Check(err, ErrorMatches, `unterminated character class in "/dev/foo\[0-9"`)
+}
+
+func (s *suggestSuite) TestSuggest(c *C) {
+	ds := []*denials.Denial{
+		{Kind: denials.Capability, Capability: "sys_time"},
+		{Kind: denials.File, Path: "/dev/rtc0", Mask: "rw"},
+		{Kind: denials.Network, Family: "netlink", SockType: "raw"},
+		{Kind: denials.DBus, Mask: "send", Bus: "system", Path: "/org/freedesktop/hostname1", Interface: "org.freedesktop.DBus.Properties", Member: "GetAll"},
+		{Kind: denials.Syscall, SyscallNumber: "164", SyscallName: "settimeofday"},
+		{Kind: denials.Syscall, SyscallNumber: "9999"},
+	}
+	suggestions := denials.Suggest("foo", ds)
+	c.Assert(suggestions, HasLen, len(ds))
+	for i, s := range suggestions {
+		c.Check(s.Denial, Equals, ds[i])
+	}
+	c.Check(suggestions[0].Interfaces, testutil.Contains, "time-control")
+	c.Check(suggestions[1].Interfaces, testutil.Contains, "rtc-control")
+	c.Check(suggestions[2].Interfaces, testutil.Contains, "network-control")
+	c.Check(suggestions[3].Interfaces, testutil.Contains, "system-observe")
+	c.Check(suggestions[4].Interfaces, testutil.Contains, "time-control")
+	c.Check(suggestions[5].Interfaces, HasLen, 0)
+	// interfaces that need attributes are not considered
+	c.Check(suggestions[1].Interfaces, Not(testutil.Contains), "custom-device")
+}
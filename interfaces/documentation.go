@@ -0,0 +1,106 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package interfaces
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// baseDeclarationRules returns the rules of the given side of the base
+// declaration of an interface, or nil if there are none.
+func baseDeclarationRules(ifaceName, baseDeclaration string) map[string]any {
+	if baseDeclaration == "" {
+		return nil
+	}
+	var decl map[string]map[string]any
+	if err := yaml.Unmarshal([]byte(baseDeclaration), &decl); err != nil {
+		// the base declaration is checked when snapd starts
+		return nil
+	}
+	return decl[ifaceName]
+}
+
+// ruleIs returns whether the rule under the given allow- or deny- name is
+// unconditional, and whether it is present at all.
+func ruleIs(rules map[string]any, name string, value bool) (unconditional, present bool) {
+	v, ok := rules[name]
+	if !ok {
+		return false, false
+	}
+	b, ok := v.(bool)
+	return ok && b == value, true
+}
+
+func sideSecurityNotes(side string, rules map[string]any) []string {
+	var notes []string
+
+	if uncond, _ := ruleIs(rules, "allow-installation", false); uncond {
+		notes = append(notes, fmt.Sprintf("installing a snap with a %s requires a snap declaration", side))
+	} else if uncond, _ := ruleIs(rules, "deny-installation", true); uncond {
+		notes = append(notes, fmt.Sprintf("installing a snap with a %s requires a snap declaration", side))
+	} else if allow, ok := rules["allow-installation"].(map[any]any); ok {
+		if types, ok := allow[side+"-snap-type"].([]any); ok {
+			names := make([]string, 0, len(types))
+			for _, t := range types {
+				names = append(names, fmt.Sprint(t))
+			}
+			notes = append(notes, fmt.Sprintf("%ss can only be provided by snaps of type %s", side, strings.Join(names, ", ")))
+		}
+	}
+
+	allowUncond, allowPresent := ruleIs(rules, "allow-connection", false)
+	denyUncond, denyPresent := ruleIs(rules, "deny-connection", true)
+	switch {
+	case allowUncond || denyUncond:
+		notes = append(notes, fmt.Sprintf("connecting a %s requires a snap declaration", side))
+	case allowPresent || denyPresent:
+		notes = append(notes, fmt.Sprintf("connecting a %s requires a snap declaration depending on its attributes", side))
+	}
+
+	allowUncond, allowPresent = ruleIs(rules, "allow-auto-connection", false)
+	denyUncond, denyPresent = ruleIs(rules, "deny-auto-connection", true)
+	switch {
+	case allowUncond || denyUncond:
+		notes = append(notes, fmt.Sprintf("%s connections are not established automatically", side))
+	case allowPresent || denyPresent:
+		notes = append(notes, fmt.Sprintf("%s connections are only established automatically in some cases", side))
+	}
+
+	return notes
+}
+
+// securityNotes derives notes about the security implications of an
+// interface from its static info, so that they cannot drift from what
+// snapd enforces.
+func securityNotes(ifaceName string, si StaticInfo) []string {
+	var notes []string
+	if si.AppArmorUnconfinedPlugs {
+		notes = append(notes, "snaps with a plug run with an unconfined AppArmor profile")
+	}
+	if si.AppArmorUnconfinedSlots {
+		notes = append(notes, "snaps with a slot run with an unconfined AppArmor profile")
+	}
+	notes = append(notes, sideSecurityNotes("plug", baseDeclarationRules(ifaceName, si.BaseDeclarationPlugs))...)
+	notes = append(notes, sideSecurityNotes("slot", baseDeclarationRules(ifaceName, si.BaseDeclarationSlots))...)
+	return notes
+}
@@ -51,6 +51,8 @@ type TestInterface struct {
 
 	BeforeConnectPlugCallback func(plug *interfaces.ConnectedPlug) error
 	BeforeConnectSlotCallback func(slot *interfaces.ConnectedSlot) error
+	// BeforeConnectCallback is the callback invoked inside BeforeConnect()
+	BeforeConnectCallback func(plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error
 
 	// Support for interacting with the test backend.
 
@@ -221,6 +223,13 @@ func (t *TestInterface) BeforeConnectSlot(slot *interfaces.ConnectedSlot) error
 	return nil
 }
 
+func (t *TestInterface) BeforeConnect(plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	if t.BeforeConnectCallback != nil {
+		return t.BeforeConnectCallback(plug, slot)
+	}
+	return nil
+}
+
 // AutoConnect returns whether plug and slot should be implicitly
 // auto-connected assuming they will be an unambiguous connection
 // candidate.
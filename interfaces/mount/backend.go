@@ -143,7 +143,7 @@ func (b *Backend) updateOrDiscard(snapName string, snapInfo *snap.Info) error {
 	if err := UpdateSnapNamespace(snapName); err != nil {
 		// try to discard the mount namespace but only if there aren't enduring daemons in the snap
 		for _, app := range snapInfo.Apps {
-			if app.Daemon != "" && app.RefreshMode == "endure" {
+			if app.Daemon != "" && (app.RefreshMode == "endure" || app.RefreshMode == "ignore-running") {
 				return fmt.Errorf("cannot update mount namespace of snap %q, and cannot discard it because it contains an enduring daemon: %s", snapName, err)
 			}
 		}
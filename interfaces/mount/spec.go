@@ -94,6 +94,10 @@ func mountEntryFromLayout(layout *snap.Layout) osutil.MountEntry {
 		entry.Options = []string{osutil.XSnapdKindSymlink(), osutil.XSnapdSymlink(oldname)}
 	}
 
+	if layout.Mimic == "overlay" {
+		entry.Options = append(entry.Options, osutil.XSnapdMimicOverlay())
+	}
+
 	var uid uint32
 	// Only root is allowed here until we support custom users. Root is default.
 	switch layout.User {
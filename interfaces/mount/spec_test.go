@@ -187,6 +187,22 @@ func (s *specSuite) TestMountEntryFromLayout(c *C) {
 	})
 }
 
+func (s *specSuite) TestMountEntryFromLayoutWithOverlayMimic(c *C) {
+	const snapWithOverlayMimicLayout = `
+name: vanguard
+version: 0
+layout:
+  /usr/foo:
+    bind: $SNAP/usr/foo
+    mimic: overlay
+`
+	snapInfo := snaptest.MockInfo(c, snapWithOverlayMimicLayout, &snap.SideInfo{Revision: snap.R(42)})
+	s.spec.AddLayout(snapInfo)
+	c.Assert(s.spec.MountEntries(), DeepEquals, []osutil.MountEntry{
+		{Dir: "/usr/foo", Name: "/snap/vanguard/42/usr/foo", Options: []string{"rbind", "rw", "x-snapd.mimic=overlay", "x-snapd.origin=layout"}},
+	})
+}
+
 func (s *specSuite) TestMountEntryFromExtraLayouts(c *C) {
 	const minimalSnap = `
 name: vanguard
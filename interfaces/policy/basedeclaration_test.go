@@ -829,6 +829,7 @@ var (
 		"fwupd":                     {"app", "core"},
 		"gpio":                      {"core", "gadget"},
 		"gpio-control":              {"core"},
+		"gpu-control":               {"core"},
 		"greengrass-support":        {"core"},
 		"hidraw":                    {"core", "gadget"},
 		"i2c":                       {"core", "gadget"},
@@ -880,6 +881,7 @@ var (
 		"upower-observe":            {"app", "core"},
 		"usb-gadget":                {"core"},
 		"userns":                    {"core"},
+		"watchdog-control":          {"core", "gadget"},
 		"wayland":                   {"app", "core"},
 		"x11":                       {"app", "core"},
 		// snowflakes
@@ -1090,6 +1092,7 @@ func (s *baseDeclSuite) TestPlugInstallation(c *C) {
 		"vulkan-driver-libs":               true,
 		"greengrass-support":               true,
 		"gpio-control":                     true,
+		"gpu-control":                      true,
 		"ion-memory-control":               true,
 		"iscsi-initiator":                  true,
 		"kernel-firmware-control":          true,
@@ -1097,6 +1100,7 @@ func (s *baseDeclSuite) TestPlugInstallation(c *C) {
 		"kernel-module-load":               true,
 		"kernel-sched-ext-control":         true,
 		"kubernetes-support":               true,
+		"loop-control":                     true,
 		"lxd-support":                      true,
 		"microceph-support":                true,
 		"microstack-support":               true,
@@ -1412,6 +1416,7 @@ func (s *baseDeclSuite) TestValidity(c *C) {
 		"gbm-driver-libs":                  true,
 		"greengrass-support":               true,
 		"gpio-control":                     true,
+		"gpu-control":                      true,
 		"ion-memory-control":               true,
 		"iscsi-initiator":                  true,
 		"kernel-firmware-control":          true,
@@ -1419,6 +1424,7 @@ func (s *baseDeclSuite) TestValidity(c *C) {
 		"kernel-module-load":               true,
 		"kernel-sched-ext-control":         true,
 		"kubernetes-support":               true,
+		"loop-control":                     true,
 		"lxd-support":                      true,
 		"microceph-support":                true,
 		"microstack-support":               true,
@@ -133,6 +133,12 @@ type ConnectCandidate struct {
 	Slot                *interfaces.ConnectedSlot
 	SlotSnapDeclaration *asserts.SnapDeclaration
 
+	// PlugAutoConnectionControl and SlotAutoConnectionControl are
+	// the auto-connection-control assertions for the plug and slot
+	// snaps that apply to the device, if any.
+	PlugAutoConnectionControl *asserts.AutoConnectionControl
+	SlotAutoConnectionControl *asserts.AutoConnectionControl
+
 	BaseDeclaration *asserts.BaseDeclaration
 
 	Model *asserts.Model
@@ -271,11 +277,41 @@ func (connc *ConnectCandidate) Check() error {
 	return err
 }
 
+// checkAutoConnectionControl returns whether the auto-connection is granted
+// on the device by the auto-connection-control assertions.
+func (connc *ConnectCandidate) checkAutoConnectionControl() (interfaces.SideArity, bool) {
+	iface := connc.Plug.Interface()
+	if ctrl := connc.PlugAutoConnectionControl; ctrl != nil && ctrl.SnapID() == connc.plugSnapID() {
+		if rule := ctrl.PlugRule(iface); rule != nil {
+			if arity, err := connc.checkPlugRule("auto-connection", rule, false); err == nil {
+				return arity, true
+			}
+		}
+	}
+	if ctrl := connc.SlotAutoConnectionControl; ctrl != nil && ctrl.SnapID() == connc.slotSnapID() {
+		if rule := ctrl.SlotRule(iface); rule != nil {
+			if arity, err := connc.checkSlotRule("auto-connection", rule, false); err == nil {
+				return arity, true
+			}
+		}
+	}
+	return nil, false
+}
+
 // CheckAutoConnect checks whether the connection is allowed to auto-connect.
 func (connc *ConnectCandidate) CheckAutoConnect() (interfaces.SideArity, error) {
 	arity, err := connc.check("auto-connection")
 	if err != nil {
-		return nil, err
+		// the brand can grant the auto-connection on the device, as
+		// long as the connection itself is allowed
+		ctrlArity, granted := connc.checkAutoConnectionControl()
+		if !granted {
+			return nil, err
+		}
+		if _, cerr := connc.check("connection"); cerr != nil {
+			return nil, err
+		}
+		arity = ctrlArity
 	}
 	if arity == nil {
 		// shouldn't happen but be safe, the callers should be able
@@ -1174,6 +1174,96 @@ func (s *policySuite) TestSnapDeclAllowDenyAutoConnection(c *C) {
 	}
 }
 
+func (s *policySuite) TestAutoConnectionControl(c *C) {
+	a, err := asserts.Decode([]byte(`type: auto-connection-control
+authority-id: my-brand
+brand-id: my-brand
+model: my-model
+snap-id: plugsnapidididididididididididid
+plugs:
+  auto-snap-plug-not-allow:
+    allow-auto-connection: true
+  auto-base-plug-not-allow:
+    allow-auto-connection:
+      slot-snap-id:
+        - slotsnapidididididididididididid
+  auto-snap-plug-deny:
+    allow-auto-connection: true
+  snap-plug-deny:
+    allow-auto-connection: true
+  auto-base-plug-not-allow-slots:
+    deny-auto-connection: true
+timestamp: 2026-01-01T00:00:00Z
+sign-key-sha3-384: Jv8_JiHiIzJVcO9M55pPdqSDWUvuhfDIBJUS-3VW7F_idjix7Ffn5qMxB21ZQuij
+
+AXNpZw==`))
+	c.Assert(err, IsNil)
+	plugCtrl := a.(*asserts.AutoConnectionControl)
+
+	a, err = asserts.Decode([]byte(`type: auto-connection-control
+authority-id: my-brand
+brand-id: my-brand
+model: my-model
+snap-id: slotsnapidididididididididididid
+slots:
+  auto-snap-slot-not-allow:
+    allow-auto-connection: true
+timestamp: 2026-01-01T00:00:00Z
+sign-key-sha3-384: Jv8_JiHiIzJVcO9M55pPdqSDWUvuhfDIBJUS-3VW7F_idjix7Ffn5qMxB21ZQuij
+
+AXNpZw==`))
+	c.Assert(err, IsNil)
+	slotCtrl := a.(*asserts.AutoConnectionControl)
+
+	tests := []struct {
+		iface    string
+		expected string // "" => no error
+	}{
+		// granted on the device
+		{"auto-snap-plug-not-allow", ""},
+		{"auto-base-plug-not-allow", ""},
+		{"auto-snap-slot-not-allow", ""},
+		// the connection itself is not allowed
+		{"auto-snap-plug-deny", `auto-connection denied by plug rule of interface "auto-snap-plug-deny" for "plug-snap" snap`},
+		{"snap-plug-deny", `auto-connection denied by plug rule of interface "snap-plug-deny" for "plug-snap" snap`},
+		// not granted
+		{"auto-base-plug-not-allow-slots", `auto-connection not allowed by plug rule of interface "auto-base-plug-not-allow-slots"`},
+		{"auto-snap-slot-deny", `auto-connection denied by slot rule of interface "auto-snap-slot-deny" for "slot-snap" snap`},
+	}
+
+	for _, t := range tests {
+		cand := policy.ConnectCandidate{
+			Plug:                      interfaces.NewConnectedPlug(s.plugSnap.Plugs[t.iface], s.plugAppSet, nil, nil),
+			Slot:                      interfaces.NewConnectedSlot(s.slotSnap.Slots[t.iface], s.slotAppSet, nil, nil),
+			PlugSnapDeclaration:       s.plugDecl,
+			SlotSnapDeclaration:       s.slotDecl,
+			PlugAutoConnectionControl: plugCtrl,
+			SlotAutoConnectionControl: slotCtrl,
+			BaseDeclaration:           s.baseDecl,
+		}
+
+		arity, err := cand.CheckAutoConnect()
+		if t.expected == "" {
+			c.Check(err, IsNil, Commentf(t.iface))
+			c.Check(arity.SlotsPerPlugAny(), Equals, false)
+		} else {
+			c.Check(err, ErrorMatches, t.expected, Commentf(t.iface))
+		}
+	}
+
+	// the assertions apply only to the snaps they are about
+	cand := policy.ConnectCandidate{
+		Plug:                      interfaces.NewConnectedPlug(s.plugSnap.Plugs["auto-snap-plug-not-allow"], s.plugAppSet, nil, nil),
+		Slot:                      interfaces.NewConnectedSlot(s.slotSnap.Slots["auto-snap-plug-not-allow"], s.slotAppSet, nil, nil),
+		PlugSnapDeclaration:       s.plugDecl,
+		SlotSnapDeclaration:       s.slotDecl,
+		PlugAutoConnectionControl: slotCtrl,
+		BaseDeclaration:           s.baseDecl,
+	}
+	_, err = cand.CheckAutoConnect()
+	c.Check(err, ErrorMatches, `auto-connection not allowed by plug rule of interface "auto-snap-plug-not-allow" for "plug-snap" snap`)
+}
+
 func (s *policySuite) TestSnapTypeCheckConnection(c *C) {
 	gadgetAppSet := ifacetest.MockInfoAndAppSet(c, `
 name: gadget
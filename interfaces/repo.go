@@ -193,6 +193,10 @@ func (r *Repository) interfaceInfo(iface Interface, opts *InfoOptions) *Info {
 		} else {
 			ii.DocURL = fmt.Sprintf(defaultIfaceDocURLTemplate, ifaceName)
 		}
+		ii.RequiredPlugAttrs = si.RequiredPlugAttrs
+		ii.RequiredSlotAttrs = si.RequiredSlotAttrs
+		ii.Consumers = si.Consumers
+		ii.SecurityNotes = securityNotes(ifaceName, si)
 	}
 	if opts != nil && opts.Plugs {
 		// Collect all plugs of this interface type.
@@ -554,6 +558,13 @@ type plugValidator interface {
 	BeforeConnectPlug(plug *ConnectedPlug) error
 }
 
+// connValidator can be implemented by Interfaces that need to validate the
+// plug and the slot together before the connection is established, for
+// instance to negotiate a connection parameter.
+type connValidator interface {
+	BeforeConnect(plug *ConnectedPlug, slot *ConnectedSlot) error
+}
+
 type PolicyFunc func(*ConnectedPlug, *ConnectedSlot) (bool, error)
 
 // Connect establishes a connection between a plug and a slot.
@@ -627,6 +638,12 @@ func (r *Repository) Connect(ref *ConnRef, plugStaticAttrs, plugDynamicAttrs, sl
 				return nil, fmt.Errorf("cannot connect slot %q of snap %q: %s", slot.Name, slot.Snap.InstanceName(), err)
 			}
 		}
+		if i, ok := iface.(connValidator); ok {
+			if err := i.BeforeConnect(cplug, cslot); err != nil {
+				return nil, fmt.Errorf("cannot connect plug %q of snap %q to slot %q of snap %q: %s",
+					plug.Name, plug.Snap.InstanceName(), slot.Name, slot.Snap.InstanceName(), err)
+			}
+		}
 
 		// autoconnect policy checker returns false to indicate disallowed auto-connection, but it's not an error.
 		ok, err := policyCheck(cplug, cslot)
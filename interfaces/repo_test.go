@@ -2180,6 +2180,74 @@ apps:
 	})
 }
 
+func (s *RepositorySuite) TestInfoDocumentation(c *C) {
+	r := NewRepository()
+	i1 := &ifacetest.TestInterface{InterfaceName: "i1", InterfaceStaticInfo: StaticInfo{
+		Summary:                 "i1 summary",
+		RequiredPlugAttrs:       []string{"path"},
+		RequiredSlotAttrs:       []string{"name", "bus"},
+		Consumers:               "tools",
+		AppArmorUnconfinedPlugs: true,
+		BaseDeclarationPlugs: `
+  i1:
+    allow-installation: false
+    deny-auto-connection: true
+`,
+		BaseDeclarationSlots: `
+  i1:
+    allow-installation:
+      slot-snap-type:
+        - core
+        - gadget
+    deny-connection:
+      plug-attributes:
+        read: all
+    deny-auto-connection:
+      on-classic: false
+`,
+	}}
+	i2 := &ifacetest.TestInterface{InterfaceName: "i2", InterfaceStaticInfo: StaticInfo{
+		Summary: "i2 summary",
+		BaseDeclarationSlots: `
+  i2:
+    deny-installation: true
+    allow-connection: false
+`,
+	}}
+	c.Assert(r.AddInterface(i1), IsNil)
+	c.Assert(r.AddInterface(i2), IsNil)
+
+	infos := r.Info(&InfoOptions{Doc: true})
+	c.Assert(infos, DeepEquals, []*Info{{
+		Name:              "i1",
+		Summary:           "i1 summary",
+		DocURL:            "https://snapcraft.io/docs/i1-interface",
+		RequiredPlugAttrs: []string{"path"},
+		RequiredSlotAttrs: []string{"name", "bus"},
+		Consumers:         "tools",
+		SecurityNotes: []string{
+			"snaps with a plug run with an unconfined AppArmor profile",
+			"installing a snap with a plug requires a snap declaration",
+			"plug connections are not established automatically",
+			"slots can only be provided by snaps of type core, gadget",
+			"connecting a slot requires a snap declaration depending on its attributes",
+			"slot connections are only established automatically in some cases",
+		},
+	}, {
+		Name:    "i2",
+		Summary: "i2 summary",
+		DocURL:  "https://snapcraft.io/docs/i2-interface",
+		SecurityNotes: []string{
+			"installing a snap with a slot requires a snap declaration",
+			"connecting a slot requires a snap declaration",
+		},
+	}})
+
+	// the documentation is only returned when asked for
+	infos = r.Info(&InfoOptions{Names: []string{"i1"}})
+	c.Assert(infos, DeepEquals, []*Info{{Name: "i1", Summary: "i1 summary"}})
+}
+
 const ifacehooksSnap1 = `
 name: s1
 version: 0
@@ -2294,6 +2362,38 @@ func (s *RepositorySuite) TestBeforeConnectValidationPolicyCheckFailure(c *C) {
 	c.Assert(conn, IsNil)
 }
 
+func (s *RepositorySuite) TestBeforeConnectPlugAndSlotValidationFailure(c *C) {
+	err := s.emptyRepo.AddInterface(&ifacetest.TestInterface{
+		InterfaceName: "iface2",
+		BeforeConnectCallback: func(plug *ConnectedPlug, slot *ConnectedSlot) error {
+			var plugVal, slotVal string
+			c.Check(plug.Attr("attr0", &plugVal), IsNil)
+			c.Check(slot.Attr("attr0", &slotVal), IsNil)
+			return fmt.Errorf("%s and %s do not match", plugVal, slotVal)
+		},
+	})
+	c.Assert(err, IsNil)
+
+	s1 := ifacetest.MockInfoAndAppSet(c, ifacehooksSnap1, nil, nil)
+	c.Assert(s.emptyRepo.AddAppSet(s1), IsNil)
+	s2 := ifacetest.MockInfoAndAppSet(c, ifacehooksSnap2, nil, nil)
+	c.Assert(s.emptyRepo.AddAppSet(s2), IsNil)
+
+	policyCheck := func(plug *ConnectedPlug, slot *ConnectedSlot) (bool, error) {
+		c.Fatalf("unexpected policy check")
+		return false, nil
+	}
+	connRef := &ConnRef{PlugRef: PlugRef{Snap: "s1", Name: "consumer"}, SlotRef: SlotRef{Snap: "s2", Name: "producer"}}
+	conn, err := s.emptyRepo.Connect(connRef, nil, nil, nil, nil, policyCheck)
+	c.Assert(err, ErrorMatches, `cannot connect plug "consumer" of snap "s1" to slot "producer" of snap "s2": val0 and val0 do not match`)
+	c.Assert(conn, IsNil)
+
+	// connections are not validated again when they are reloaded
+	conn, err = s.emptyRepo.Connect(connRef, nil, nil, nil, nil, nil)
+	c.Assert(err, IsNil)
+	c.Assert(conn, NotNil)
+}
+
 func (s *RepositorySuite) TestConnection(c *C) {
 	c.Assert(s.testRepo.AddAppSet(s.consumer), IsNil)
 	c.Assert(s.testRepo.AddAppSet(s.producer), IsNil)
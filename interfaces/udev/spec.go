@@ -123,6 +123,18 @@ func (spec *Specification) TagDevice(snippet string) {
 	}
 }
 
+// UntagDevice adds an app/hook specific RUN rule revoking access to devices
+// described by the snippet. It is meant for devices which were tagged with
+// TagDevice before but changed in a way that no longer matches the tagging
+// rules.
+func (spec *Specification) UntagDevice(snippet string) {
+	for _, securityTag := range spec.securityTags {
+		tag := udevTag(securityTag)
+		spec.addEntry(fmt.Sprintf("# %s\n%s, RUN+=\"%s/snap-device-helper remove %s $devpath $major:$minor\"",
+			spec.iface, snippet, dirs.StripRootDir(dirs.DistroLibExecDir), tag), tag)
+	}
+}
+
 type byTagAndSnippet []entry
 
 func (c byTagAndSnippet) Len() int      { return len(c) }
@@ -159,6 +159,26 @@ func (s *specSuite) TestTagDeviceAltLibexecdir(c *C) {
 	s.testTagDevice(c, "/usr/libexec/snapd")
 }
 
+func (s *specSuite) TestUntagDevice(c *C) {
+	iface := &ifacetest.TestInterface{
+		InterfaceName: "iface-1",
+		UDevConnectedPlugCallback: func(spec *udev.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+			spec.UntagDevice(`kernel="voodoo"`)
+			return nil
+		},
+	}
+	c.Assert(s.spec.AddConnectedPlug(iface, s.plug, s.slot), IsNil)
+
+	c.Assert(s.spec.Snippets(), DeepEquals, []string{
+		`# iface-1
+kernel="voodoo", RUN+="/usr/lib/snapd/snap-device-helper remove snap_snap1__comp_hook_install $devpath $major:$minor"`,
+		`# iface-1
+kernel="voodoo", RUN+="/usr/lib/snapd/snap-device-helper remove snap_snap1_foo $devpath $major:$minor"`,
+		`# iface-1
+kernel="voodoo", RUN+="/usr/lib/snapd/snap-device-helper remove snap_snap1_hook_configure $devpath $major:$minor"`,
+	})
+}
+
 // The spec.Specification can be used through the interfaces.Specification interface
 func (s *specSuite) TestSpecificationIface(c *C) {
 	appSet, err := interfaces.NewSnapAppSet(s.plugInfo.Snap, nil)
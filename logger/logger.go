@@ -35,6 +35,7 @@ import (
 	"io"
 	"log"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
@@ -67,6 +68,11 @@ func (nullLogger) Debug(string)         {}
 func (nullLogger) NoGuardDebug(string)  {}
 func (nullLogger) Trace(string, ...any) {}
 
+func (nullLogger) NoticeAttrs(string, ...any) {}
+func (nullLogger) DebugAttrs(string, ...any)  {}
+
+func (nullLogger) debugEnabled() bool { return false }
+
 // NullLogger is a logger that does nothing
 var NullLogger = nullLogger{}
 
@@ -128,6 +134,84 @@ func Trace(msg string, attrs ...any) {
 	logger.Trace(msg, attrs...)
 }
 
+// An attrLogger is a Logger which can record attributes, i.e. key/value
+// pairs identifying e.g. the change, task or snap a message is about,
+// alongside its messages.
+type attrLogger interface {
+	NoticeAttrs(msg string, attrs ...any)
+	DebugAttrs(msg string, attrs ...any)
+}
+
+// NoticeAttrs notifies the user of something, recording the given
+// attributes, alternating keys and values, alongside the message. They are
+// separate fields with the structured logger in JSON mode and are
+// otherwise appended to the message as key=value.
+func NoticeAttrs(msg string, attrs ...any) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	if al, ok := logger.(attrLogger); ok {
+		al.NoticeAttrs(msg, attrs...)
+		return
+	}
+	logger.Notice(msg + formatAttrs(attrs...))
+}
+
+// DebugAttrs records something in the debug log, recording the given
+// attributes alongside the message like NoticeAttrs.
+func DebugAttrs(msg string, attrs ...any) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	if al, ok := logger.(attrLogger); ok {
+		al.DebugAttrs(msg, attrs...)
+		return
+	}
+	logger.Debug(msg + formatAttrs(attrs...))
+}
+
+// A debugLogger is a Logger which can tell whether it records debug
+// messages.
+type debugLogger interface {
+	debugEnabled() bool
+}
+
+// DebugEnabled returns true if debug messages are recorded, for callers to
+// skip building costly debug messages or attributes otherwise.
+func DebugEnabled() bool {
+	lock.Lock()
+	defer lock.Unlock()
+
+	if dl, ok := logger.(debugLogger); ok {
+		return dl.debugEnabled()
+	}
+	return true
+}
+
+// formatAttrs formats the given attributes, alternating keys and values, as
+// a sequence of " key=value", quoting the values when needed. A value
+// without a key is recorded under the !BADKEY key, as done by log/slog.
+func formatAttrs(attrs ...any) string {
+	var b strings.Builder
+	for len(attrs) > 0 {
+		var key string
+		var value any
+		if k, ok := attrs[0].(string); ok && len(attrs) > 1 {
+			key, value = k, attrs[1]
+			attrs = attrs[2:]
+		} else {
+			key, value = "!BADKEY", attrs[0]
+			attrs = attrs[1:]
+		}
+		v := fmt.Sprint(value)
+		if v == "" || strings.ContainsAny(v, " =\"\t\n") {
+			v = fmt.Sprintf("%q", v)
+		}
+		fmt.Fprintf(&b, " %s=%s", key, v)
+	}
+	return b.String()
+}
+
 // NoGuardDebugf records something in the debug log
 func NoGuardDebugf(format string, v ...any) {
 	msg := fmt.Sprintf(format, v...)
@@ -207,6 +291,25 @@ func (l *Log) Notice(msg string) {
 	}
 }
 
+// DebugAttrs only prints if SNAPD_DEBUG is set, with the attributes
+// appended to the message
+func (l *Log) DebugAttrs(msg string, attrs ...any) {
+	if l.debugEnabled() {
+		// this frame + single package level API func() + actual caller
+		calldepth := 1 + 1 + 1
+		l.log.Output(calldepth, "DEBUG: "+msg+formatAttrs(attrs...))
+	}
+}
+
+// NoticeAttrs is like Notice, with the attributes appended to the message
+func (l *Log) NoticeAttrs(msg string, attrs ...any) {
+	if !l.quiet || l.debugEnabled() {
+		// this frame + single package level API func() + actual caller
+		calldepth := 1 + 1 + 1
+		l.log.Output(calldepth, msg+formatAttrs(attrs...))
+	}
+}
+
 // Trace only prints if SNAPD_TRACE is set and the structured logger is used
 func (l *Log) Trace(string, ...any) {}
 
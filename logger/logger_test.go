@@ -160,6 +160,60 @@ func (s *LogSuite) TestPanicf(c *C) {
 	c.Check(s.logbuf.String(), Matches, `(?m).*logger_test\.go:\d+: PANIC xyzzy`)
 }
 
+func (s *LogSuite) TestNoticeAttrs(c *C) {
+	logger.NoticeAttrs("xyzzy", "change", "42", "snap", "foo", "reason", "two words", "empty", "", "count", 3, "dangling")
+	c.Check(s.logbuf.String(), Matches, `(?m).*logger_test\.go:\d+: xyzzy change=42 snap=foo reason="two words" empty="" count=3 !BADKEY=dangling`)
+}
+
+func (s *LogSuite) TestDebugAttrs(c *C) {
+	logger.DebugAttrs("xyzzy", "task", "7")
+	c.Check(s.logbuf.String(), Equals, "")
+
+	os.Setenv("SNAPD_DEBUG", "1")
+	defer os.Unsetenv("SNAPD_DEBUG")
+
+	logger.DebugAttrs("xyzzy", "task", "7", "value", `with "quotes"`)
+	c.Check(s.logbuf.String(), Matches, `(?m).*logger_test\.go:\d+: DEBUG: xyzzy task=7 value="with \\"quotes\\""`)
+}
+
+func (s *LogSuite) TestDebugEnabled(c *C) {
+	c.Check(logger.DebugEnabled(), Equals, false)
+
+	os.Setenv("SNAPD_DEBUG", "1")
+	defer os.Unsetenv("SNAPD_DEBUG")
+	c.Check(logger.DebugEnabled(), Equals, true)
+	os.Unsetenv("SNAPD_DEBUG")
+
+	_, restore := logger.MockDebugLogger()
+	defer restore()
+	c.Check(logger.DebugEnabled(), Equals, true)
+
+	logger.SetLogger(logger.NullLogger)
+	c.Check(logger.DebugEnabled(), Equals, false)
+
+	// loggers which cannot tell are assumed to record debug messages
+	logger.SetLogger(&noticeOnlyLogger{})
+	c.Check(logger.DebugEnabled(), Equals, true)
+}
+
+type noticeOnlyLogger struct {
+	msgs []string
+}
+
+func (l *noticeOnlyLogger) Notice(msg string)       { l.msgs = append(l.msgs, msg) }
+func (l *noticeOnlyLogger) Debug(msg string)        { l.msgs = append(l.msgs, "DEBUG: "+msg) }
+func (l *noticeOnlyLogger) NoGuardDebug(msg string) {}
+func (l *noticeOnlyLogger) Trace(string, ...any)    {}
+
+func (s *LogSuite) TestAttrsWithPlainLogger(c *C) {
+	l := &noticeOnlyLogger{}
+	logger.SetLogger(l)
+
+	logger.NoticeAttrs("xyzzy", "change", "42")
+	logger.DebugAttrs("plugh", "task", "7")
+	c.Check(l.msgs, DeepEquals, []string{"xyzzy change=42", "DEBUG: plugh task=7"})
+}
+
 func (s *LogSuite) TestWithLoggerLock(c *C) {
 	logger.Noticef("xyzzy")
 
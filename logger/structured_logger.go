@@ -74,6 +74,28 @@ func (l *StructuredLog) Notice(msg string) {
 	}
 }
 
+// DebugAttrs is like Debug, with the attributes as separate fields
+func (l *StructuredLog) DebugAttrs(msg string, attrs ...any) {
+	if l.debugEnabled() {
+		var pcs [1]uintptr
+		runtime.Callers(3, pcs[:])
+		r := slog.NewRecord(time.Now(), slog.LevelDebug, msg, pcs[0])
+		r.Add(attrs...)
+		l.log.Handler().Handle(context.Background(), r)
+	}
+}
+
+// NoticeAttrs is like Notice, with the attributes as separate fields
+func (l *StructuredLog) NoticeAttrs(msg string, attrs ...any) {
+	if !l.quiet {
+		var pcs [1]uintptr
+		runtime.Callers(3, pcs[:])
+		r := slog.NewRecord(time.Now(), levelNotice, msg, pcs[0])
+		r.Add(attrs...)
+		l.log.Handler().Handle(context.Background(), r)
+	}
+}
+
 // NoGuardDebug always prints the message, w/o gating it based on environment
 // variables or other configurations.
 func (l *StructuredLog) NoGuardDebug(msg string) {
@@ -131,6 +131,34 @@ func (s *LogStructuredSuite) TestNoticeStructured(c *C) {
 	c.Check(data.Source.File, Equals, "structured_logger_test.go")
 }
 
+func (s *LogStructuredSuite) TestNoticeAttrsStructured(c *C) {
+	logger.NoticeAttrs("xyzzy", "attr", "val")
+	data := TestLogEntry{}
+	err := json.Unmarshal(s.logbuf.Bytes(), &data)
+	c.Check(err, IsNil)
+	c.Check(data.Msg, Equals, "xyzzy")
+	c.Check(data.Level, Equals, "NOTICE")
+	c.Check(data.Attr, Equals, "val")
+	c.Check(data.Source.File, Equals, "structured_logger_test.go")
+}
+
+func (s *LogStructuredSuite) TestDebugAttrsStructured(c *C) {
+	logger.DebugAttrs("xyzzy", "attr", "val")
+	c.Check(s.logbuf.String(), Equals, "")
+
+	os.Setenv("SNAPD_DEBUG", "1")
+	defer os.Unsetenv("SNAPD_DEBUG")
+
+	logger.DebugAttrs("xyzzy", "attr", "val")
+	data := TestLogEntry{}
+	err := json.Unmarshal(s.logbuf.Bytes(), &data)
+	c.Check(err, IsNil)
+	c.Check(data.Msg, Equals, "xyzzy")
+	c.Check(data.Level, Equals, "DEBUG")
+	c.Check(data.Attr, Equals, "val")
+	c.Check(data.Source.File, Equals, "structured_logger_test.go")
+}
+
 func (s *LogStructuredSuite) TestNoTimestamp(c *C) {
 	os.Setenv("SNAPD_JSON_LOGGING", "1")
 	defer os.Unsetenv("SNAPD_JSON_LOGGING")
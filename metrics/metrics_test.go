@@ -0,0 +1,100 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package metrics_test
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/metrics"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type metricsSuite struct{}
+
+var _ = Suite(&metricsSuite{})
+
+func (s *metricsSuite) TestSamples(c *C) {
+	var buf bytes.Buffer
+	w := metrics.NewWriter(&buf)
+	w.Family("snapd_things", metrics.TypeGauge, "Number of things.\nReally.")
+	w.Sample("snapd_things", 3, metrics.Label{Name: "kind", Value: `a "quoted\" kind`})
+	w.Sample("snapd_things", 0.5)
+	w.Sample("snapd_things", math.Inf(1), metrics.Label{Name: "a", Value: "1"}, metrics.Label{Name: "b", Value: "2\n"})
+	c.Assert(w.Flush(), IsNil)
+	c.Check(buf.String(), Equals, `# HELP snapd_things Number of things.\nReally.
+# TYPE snapd_things gauge
+snapd_things{kind="a \"quoted\\\" kind"} 3
+snapd_things 0.5
+snapd_things{a="1",b="2\n"} +Inf
+`)
+}
+
+func (s *metricsSuite) TestHistogram(c *C) {
+	h := metrics.NewHistogram(10, 1, 5)
+	for _, v := range []float64{0.5, 1, 3, 7, 100} {
+		h.Observe(v)
+	}
+	c.Check(h.Count(), Equals, uint64(5))
+
+	var buf bytes.Buffer
+	w := metrics.NewWriter(&buf)
+	w.Family("snapd_duration_seconds", metrics.TypeHistogram, "Durations.")
+	w.Histogram("snapd_duration_seconds", h, metrics.Label{Name: "kind", Value: "foo"})
+	c.Assert(w.Flush(), IsNil)
+	c.Check(buf.String(), Equals, `# HELP snapd_duration_seconds Durations.
+# TYPE snapd_duration_seconds histogram
+snapd_duration_seconds_bucket{kind="foo",le="1"} 2
+snapd_duration_seconds_bucket{kind="foo",le="5"} 3
+snapd_duration_seconds_bucket{kind="foo",le="10"} 4
+snapd_duration_seconds_bucket{kind="foo",le="+Inf"} 5
+snapd_duration_seconds_sum{kind="foo"} 111.5
+snapd_duration_seconds_count{kind="foo"} 5
+`)
+}
+
+func (s *metricsSuite) TestEmptyHistogram(c *C) {
+	var buf bytes.Buffer
+	w := metrics.NewWriter(&buf)
+	w.Histogram("h", metrics.NewHistogram())
+	c.Assert(w.Flush(), IsNil)
+	c.Check(buf.String(), Equals, `h_bucket{le="+Inf"} 0
+h_sum 0
+h_count 0
+`)
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("boom")
+}
+
+func (s *metricsSuite) TestWriteError(c *C) {
+	w := metrics.NewWriter(failingWriter{})
+	w.Family("snapd_things", metrics.TypeCounter, "Things.")
+	w.Sample("snapd_things", 1)
+	c.Check(w.Flush(), ErrorMatches, "boom")
+}
@@ -39,11 +39,16 @@ const (
 	CopyFlagOverwrite
 	// CopyFlagPreserveAll preserves mode,owner,time attributes
 	CopyFlagPreserveAll
+	// CopyFlagReflink makes the copy share the data of the source until
+	// either is modified (a reflink), failing if the filesystem does not
+	// support it
+	CopyFlagReflink
 )
 
 var (
-	openfile = doOpenFile
-	copyfile = doCopyFile
+	openfile  = doOpenFile
+	copyfile  = doCopyFile
+	clonefile = doCloneFile
 )
 
 type fileish interface {
@@ -65,7 +70,11 @@ func CopyFile(src, dst string, flags CopyFlag) (err error) {
 		// Our native copy code does not preserve all attributes
 		// (yet). If the user needs this functionality we just
 		// fallback to use the system's "cp" binary to do the copy.
-		if err := runCpPreserveAll(src, dst, "copy all"); err != nil {
+		var extraArgs []string
+		if flags&CopyFlagReflink != 0 {
+			extraArgs = append(extraArgs, "--reflink=always")
+		}
+		if err := runCpPreserveAll(src, dst, "copy all", extraArgs...); err != nil {
 			return err
 		}
 		if flags&CopyFlagSync != 0 {
@@ -104,7 +113,11 @@ func CopyFile(src, dst string, flags CopyFlag) (err error) {
 		}
 	}()
 
-	if err := copyfile(fin, fout, fi); err != nil {
+	if flags&CopyFlagReflink != 0 {
+		if err := clonefile(fin, fout); err != nil {
+			return fmt.Errorf("unable to clone %s to %s: %w", src, dst, err)
+		}
+	} else if err := copyfile(fin, fout, fi); err != nil {
 		return fmt.Errorf("unable to copy %s to %s: %w", src, dst, err)
 	}
 
@@ -183,8 +196,10 @@ func runSync(args ...string) error {
 	return runCmd(exec.Command("sync", args...), "sync")
 }
 
-func runCpPreserveAll(path, dest, errdesc string) error {
-	return runCmd(exec.Command("cp", "-av", path, dest), errdesc)
+func runCpPreserveAll(path, dest, errdesc string, extraArgs ...string) error {
+	args := append([]string{"-av"}, extraArgs...)
+	args = append(args, path, dest)
+	return runCmd(exec.Command("cp", args...), errdesc)
 }
 
 // CopySpecialFile is used to copy all the things that are not files
@@ -22,6 +22,8 @@ package osutil
 import (
 	"os"
 	"syscall"
+
+	"golang.org/x/sys/unix"
 )
 
 const maxint = int64(^uint(0) >> 1)
@@ -46,3 +48,33 @@ func doCopyFile(fin, fout fileish, fi os.FileInfo) error {
 
 	return nil
 }
+
+func doCloneFile(fin, fout fileish) error {
+	return unix.IoctlFileClone(int(fout.Fd()), int(fin.Fd()))
+}
+
+// SupportsReflink returns whether files in the given directory can be
+// copied with reflinks, that is sharing their data until modified, as
+// supported by e.g. btrfs, xfs or zfs.
+func SupportsReflink(dir string) bool {
+	src, err := os.CreateTemp(dir, ".reflink-probe-")
+	if err != nil {
+		return false
+	}
+	defer func() {
+		src.Close()
+		os.Remove(src.Name())
+	}()
+	if _, err := src.Write([]byte{0}); err != nil {
+		return false
+	}
+	dst, err := os.CreateTemp(dir, ".reflink-probe-")
+	if err != nil {
+		return false
+	}
+	defer func() {
+		dst.Close()
+		os.Remove(dst.Name())
+	}()
+	return clonefile(src, dst) == nil
+}
@@ -21,6 +21,7 @@
 package osutil
 
 import (
+	"errors"
 	"io"
 	"os"
 )
@@ -29,3 +30,13 @@ func doCopyFile(fin, fout fileish, fi os.FileInfo) error {
 	_, err := io.Copy(fout, fin)
 	return err
 }
+
+func doCloneFile(fin, fout fileish) error {
+	return errors.New("reflinks are not supported")
+}
+
+// SupportsReflink returns whether files in the given directory can be
+// copied with reflinks, which is never the case on this platform.
+func SupportsReflink(dir string) bool {
+	return false
+}
@@ -144,6 +144,54 @@ func (s *cpSuite) TestCpCantCopy(c *C) {
 	c.Check(osutil.CopyFile(s.f1, s.f2, osutil.CopyFlagSync), ErrorMatches, `unable to copy \S+/f1 to \S+/f2: xyzzy`)
 }
 
+func (s *cpSuite) TestCpReflink(c *C) {
+	s.mock()
+	var cloned int
+	s.AddCleanup(osutil.MockCloneFile(func(fin, fout osutil.Fileish) error {
+		cloned++
+		return nil
+	}))
+
+	c.Check(osutil.CopyFile(s.f1, s.f2, osutil.CopyFlagReflink), IsNil)
+	c.Check(cloned, Equals, 1)
+	// the data is cloned, not copied
+	c.Check(s.log, Not(testutil.Contains), "copyfile")
+}
+
+func (s *cpSuite) TestCpReflinkCantClone(c *C) {
+	s.AddCleanup(osutil.MockCloneFile(func(fin, fout osutil.Fileish) error {
+		return syscall.EOPNOTSUPP
+	}))
+
+	c.Check(osutil.CopyFile(s.f1, s.f2, osutil.CopyFlagReflink), ErrorMatches, `unable to clone \S+/f1 to \S+/f2: operation not supported`)
+}
+
+func (s *cpSuite) TestSupportsReflink(c *C) {
+	var srcs, dsts []string
+	s.AddCleanup(osutil.MockCloneFile(func(fin, fout osutil.Fileish) error {
+		srcs = append(srcs, fin.(*os.File).Name())
+		dsts = append(dsts, fout.(*os.File).Name())
+		return nil
+	}))
+	c.Check(osutil.SupportsReflink(s.dir), Equals, true)
+
+	s.AddCleanup(osutil.MockCloneFile(func(fin, fout osutil.Fileish) error {
+		return syscall.EOPNOTSUPP
+	}))
+	c.Check(osutil.SupportsReflink(s.dir), Equals, false)
+
+	c.Assert(srcs, HasLen, 1)
+	c.Check(filepath.Dir(srcs[0]), Equals, s.dir)
+	c.Check(filepath.Dir(dsts[0]), Equals, s.dir)
+	// the probe files are removed
+	entries, err := os.ReadDir(s.dir)
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 1)
+	c.Check(entries[0].Name(), Equals, "f1")
+
+	c.Check(osutil.SupportsReflink(filepath.Join(s.dir, "missing")), Equals, false)
+}
+
 func (s *cpSuite) TestCpCantSync(c *C) {
 	s.mock()
 	s.errs = []error{nil, nil, nil, nil, errors.New("xyzzy"), nil}
@@ -259,6 +307,26 @@ func (s *cpSuite) TestCopyPreserveAllSync(c *C) {
 	})
 }
 
+func (s *cpSuite) TestCopyPreserveAllReflink(c *C) {
+	dir := c.MkDir()
+	mocked := testutil.MockCommand(c, "cp", "").Also("sync", "")
+	defer mocked.Restore()
+
+	src := filepath.Join(dir, "meep")
+	dst := filepath.Join(dir, "copied-meep")
+
+	err := os.WriteFile(src, []byte(nil), 0644)
+	c.Assert(err, IsNil)
+
+	err = osutil.CopyFile(src, dst, osutil.CopyFlagPreserveAll|osutil.CopyFlagReflink|osutil.CopyFlagSync)
+	c.Assert(err, IsNil)
+
+	c.Check(mocked.Calls(), DeepEquals, [][]string{
+		{"cp", "-av", "--reflink=always", src, dst},
+		{"sync"},
+	})
+}
+
 func (s *cpSuite) TestCopyPreserveAllSyncCpFailure(c *C) {
 	dir := c.MkDir()
 	mocked := testutil.MockCommand(c, "cp", "echo OUCH: cp failed.;exit 42").Also("sync", "")
@@ -47,6 +47,12 @@ func diskFree(path string) (uint64, error) {
 	return st.Bavail * uint64(st.Bsize), nil
 }
 
+// FreeDiskSpace returns the disk space available to unprivileged users on the
+// filesystem of the given path.
+func FreeDiskSpace(path string) (uint64, error) {
+	return diskFree(path)
+}
+
 // CheckFreeSpace checks if there is enough disk space for the given path
 func CheckFreeSpace(path string, minSize uint64) error {
 	free, err := diskFree(path)
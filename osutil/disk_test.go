@@ -68,3 +68,22 @@ func (s *diskSuite) TestCheckFreeSpacePathError(c *C) {
 	err := osutil.CheckFreeSpace("/does/not/exist/path", 8193)
 	c.Assert(os.IsNotExist(err), Equals, true)
 }
+
+func (s *diskSuite) TestFreeDiskSpace(c *C) {
+	restore := osutil.MockSyscallStatfs(func(path string, st *syscall.Statfs_t) error {
+		c.Assert(path, Equals, "/path")
+		st.Bsize = 4096
+		st.Bavail = 2
+		return nil
+	})
+	defer restore()
+
+	free, err := osutil.FreeDiskSpace("/path")
+	c.Assert(err, IsNil)
+	c.Check(free, Equals, uint64(8192))
+}
+
+func (s *diskSuite) TestFreeDiskSpacePathError(c *C) {
+	_, err := osutil.FreeDiskSpace("/does/not/exist/path")
+	c.Assert(os.IsNotExist(err), Equals, true)
+}
@@ -0,0 +1,508 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package disks
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/snapcore/snapd/randutil"
+)
+
+const (
+	// SchemaGPT is the GUID partition table schema, as known by blkid and
+	// sfdisk.
+	SchemaGPT = "gpt"
+	// SchemaDOS is the MBR partition table schema, as known by blkid and
+	// sfdisk.
+	SchemaDOS = "dos"
+)
+
+const (
+	mbrSize          = 512
+	mbrBootCodeSize  = 440
+	mbrEntriesOffset = 446
+	mbrMaxEntries    = 4
+	mbrEntrySize     = 16
+	mbrTypeGPT       = 0xee
+
+	gptMaxEntries = 128
+	gptEntrySize  = 128
+	gptNameLen    = 36
+)
+
+// PartitionTableEntry describes a partition to be written by
+// WritePartitionTable.
+type PartitionTableEntry struct {
+	// PartitionLabel is the partition name, only supported on GPT disks.
+	PartitionLabel string
+	// PartitionUUID is the unique partition GUID, only supported on GPT
+	// disks. A random GUID is used if empty.
+	PartitionUUID string
+	// PartitionType is the type of the partition, a GUID for GPT disks or
+	// a two digit hexadecimal type such as 0C for DOS disks.
+	PartitionType string
+	// Bootable marks the partition as active, only supported on DOS disks.
+	Bootable bool
+	// StartInBytes is the beginning of the partition in bytes, it must be
+	// aligned to the sector size.
+	StartInBytes uint64
+	// SizeInBytes is the size of the partition in bytes, it must be aligned
+	// to the sector size.
+	SizeInBytes uint64
+}
+
+// PartitionTable describes a partition table to be written by
+// WritePartitionTable.
+type PartitionTable struct {
+	// Schema is either SchemaGPT or SchemaDOS.
+	Schema string
+	// Partitions are the partitions in the table, in disk index order.
+	Partitions []PartitionTableEntry
+}
+
+// ProbePartitionTable returns the schema of the partition table found on the
+// given device or disk image, either SchemaGPT or SchemaDOS, or an empty
+// string if there is no partition table.
+func ProbePartitionTable(device string) (string, error) {
+	f, err := os.Open(device)
+	if err != nil {
+		return "", fmt.Errorf("cannot probe partition table: %v", err)
+	}
+	defer f.Close()
+
+	size, sectorSize, _, err := deviceGeometry(f)
+	if err != nil {
+		return "", fmt.Errorf("cannot probe partition table of %s: %v", device, err)
+	}
+	schema, err := probePartitionTable(f, size, sectorSize)
+	if err != nil {
+		return "", fmt.Errorf("cannot probe partition table of %s: %v", device, err)
+	}
+	return schema, nil
+}
+
+// DeviceGeometry returns the size in bytes and the sector size of the given
+// block device, as reported by the kernel, or disk image.
+func DeviceGeometry(device string) (sizeInBytes, sectorSize uint64, err error) {
+	f, err := os.Open(device)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	sizeInBytes, sectorSize, _, err = deviceGeometry(f)
+	if err != nil {
+		return 0, 0, fmt.Errorf("cannot get geometry of %s: %v", device, err)
+	}
+	return sizeInBytes, sectorSize, nil
+}
+
+// WritePartitionTable writes a new partition table with the given partitions
+// to the device or disk image, replacing any existing one. When writing to a
+// block device, the kernel is asked to re-read the partition table and udev
+// events are waited for, so that the partition device nodes are available
+// once this function returns.
+func WritePartitionTable(device string, table *PartitionTable) error {
+	f, err := os.OpenFile(device, os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("cannot write partition table: %v", err)
+	}
+	defer f.Close()
+
+	size, sectorSize, isBlockDevice, err := deviceGeometry(f)
+	if err != nil {
+		return fmt.Errorf("cannot write partition table to %s: %v", device, err)
+	}
+
+	mbr := make([]byte, mbrSize)
+	if _, err := f.ReadAt(mbr, 0); err != nil {
+		return fmt.Errorf("cannot write partition table to %s: %v", device, err)
+	}
+
+	var writes []diskWrite
+	switch table.Schema {
+	case SchemaGPT:
+		writes, err = buildGPT(table.Partitions, mbr[:mbrBootCodeSize], size, sectorSize)
+	case SchemaDOS:
+		writes, err = buildDOS(f, table.Partitions, mbr[:mbrBootCodeSize], size, sectorSize)
+	default:
+		err = fmt.Errorf("unknown schema %q", table.Schema)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot write partition table to %s: %v", device, err)
+	}
+
+	for _, w := range writes {
+		if _, err := f.WriteAt(w.data, w.offset); err != nil {
+			return fmt.Errorf("cannot write partition table to %s: %v", device, err)
+		}
+	}
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("cannot write partition table to %s: %v", device, err)
+	}
+
+	if !isBlockDevice {
+		// nothing else to do for disk images
+		return nil
+	}
+	if err := rereadPartitionTable(f); err != nil {
+		return fmt.Errorf("cannot re-read partition table of %s: %v", device, err)
+	}
+	// ensure udev is aware of the new partitions
+	return udevSettle()
+}
+
+type diskWrite struct {
+	offset int64
+	data   []byte
+}
+
+func probePartitionTable(r io.ReaderAt, size, sectorSize uint64) (string, error) {
+	if size < 2*sectorSize || sectorSize < mbrSize {
+		// too small to hold a partition table
+		return "", nil
+	}
+
+	for _, lba := range []uint64{1, size/sectorSize - 1} {
+		ok, err := hasGPTHeaderAt(r, lba, sectorSize)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return SchemaGPT, nil
+		}
+	}
+
+	mbr := make([]byte, mbrSize)
+	if _, err := r.ReadAt(mbr, 0); err != nil {
+		return "", err
+	}
+	if isDOSPartitionTable(mbr) {
+		return SchemaDOS, nil
+	}
+	return "", nil
+}
+
+func hasGPTHeaderAt(r io.ReaderAt, lba, sectorSize uint64) (bool, error) {
+	raw := make([]byte, sectorSize)
+	if _, err := r.ReadAt(raw, int64(lba*sectorSize)); err != nil {
+		return false, err
+	}
+	var header GPTHeader
+	if err := binary.Read(bytes.NewReader(raw), binary.LittleEndian, &header); err != nil {
+		return false, err
+	}
+	if err := verifyHeader(raw, header); err != nil {
+		return false, nil
+	}
+	return uint64(header.CurrentLBA) == lba, nil
+}
+
+func isDOSPartitionTable(mbr []byte) bool {
+	if mbr[510] != 0x55 || mbr[511] != 0xaa {
+		return false
+	}
+	// FAT and NTFS boot sectors carry the same signature
+	if bytes.Equal(mbr[3:11], []byte("NTFS    ")) ||
+		bytes.Equal(mbr[54:59], []byte("FAT12")) ||
+		bytes.Equal(mbr[54:59], []byte("FAT16")) ||
+		bytes.Equal(mbr[82:87], []byte("FAT32")) {
+		return false
+	}
+	for i := 0; i < mbrMaxEntries; i++ {
+		status := mbr[mbrEntriesOffset+i*mbrEntrySize]
+		if status != 0 && status != 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// validatePartitions checks that the partitions are aligned to the sector
+// size, are within the usable sectors [first, last] and do not overlap.
+func validatePartitions(parts []PartitionTableEntry, sectorSize, first, last uint64) error {
+	for i, p := range parts {
+		if p.StartInBytes%sectorSize != 0 || p.SizeInBytes%sectorSize != 0 {
+			return fmt.Errorf("partition %d is not aligned to the sector size %d", i+1, sectorSize)
+		}
+		if p.SizeInBytes == 0 {
+			return fmt.Errorf("partition %d has zero size", i+1)
+		}
+		start := p.StartInBytes / sectorSize
+		end := start + p.SizeInBytes/sectorSize - 1
+		if start < first || end > last {
+			return fmt.Errorf("partition %d is outside of the usable sectors %d-%d", i+1, first, last)
+		}
+		for j, o := range parts[:i] {
+			if p.StartInBytes < o.StartInBytes+o.SizeInBytes && o.StartInBytes < p.StartInBytes+p.SizeInBytes {
+				return fmt.Errorf("partition %d overlaps with partition %d", i+1, j+1)
+			}
+		}
+	}
+	return nil
+}
+
+// parseGUID converts a textual GUID into its on disk mixed-endian
+// representation.
+func parseGUID(s string) (GPTGUID, error) {
+	var guid GPTGUID
+	raw, err := hex.DecodeString(strings.Replace(s, "-", "", -1))
+	if err != nil || len(raw) != len(guid) || len(s) != 36 {
+		return guid, fmt.Errorf("invalid GUID %q", s)
+	}
+	// the first three fields are stored in little endian
+	binary.LittleEndian.PutUint32(guid[0:4], binary.BigEndian.Uint32(raw[0:4]))
+	binary.LittleEndian.PutUint16(guid[4:6], binary.BigEndian.Uint16(raw[4:6]))
+	binary.LittleEndian.PutUint16(guid[6:8], binary.BigEndian.Uint16(raw[6:8]))
+	copy(guid[8:], raw[8:])
+	return guid, nil
+}
+
+func randomGUID() (GPTGUID, error) {
+	var guid GPTGUID
+	b, err := randutil.CryptoTokenBytes(len(guid))
+	if err != nil {
+		return guid, err
+	}
+	copy(guid[:], b)
+	// version 4, variant 1, the version lives in the little endian third
+	// field
+	guid[7] = (guid[7] & 0x0f) | 0x40
+	guid[8] = (guid[8] & 0x3f) | 0x80
+	return guid, nil
+}
+
+type gptEntry struct {
+	TypeGUID   GPTGUID
+	UniqueGUID GPTGUID
+	FirstLBA   GPTLBA
+	LastLBA    GPTLBA
+	Attributes uint64
+	Name       [gptNameLen]uint16
+}
+
+func buildGPT(parts []PartitionTableEntry, bootCode []byte, size, sectorSize uint64) ([]diskWrite, error) {
+	if len(parts) > gptMaxEntries {
+		return nil, fmt.Errorf("too many partitions for a GPT disk: %d", len(parts))
+	}
+	sectors := size / sectorSize
+	entriesSectors := (gptMaxEntries*gptEntrySize + sectorSize - 1) / sectorSize
+	// MBR, primary header and entries, then backup entries and header
+	if sectors < 2*(entriesSectors+1)+2 {
+		return nil, fmt.Errorf("disk is too small for a GPT")
+	}
+	firstUsable := 2 + entriesSectors
+	lastUsable := sectors - entriesSectors - 2
+	if err := validatePartitions(parts, sectorSize, firstUsable, lastUsable); err != nil {
+		return nil, err
+	}
+
+	entries := new(bytes.Buffer)
+	for _, p := range parts {
+		var e gptEntry
+		var err error
+		if e.TypeGUID, err = parseGUID(p.PartitionType); err != nil {
+			return nil, fmt.Errorf("invalid partition type: %v", err)
+		}
+		if p.PartitionUUID != "" {
+			e.UniqueGUID, err = parseGUID(p.PartitionUUID)
+		} else {
+			e.UniqueGUID, err = randomGUID()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid partition UUID: %v", err)
+		}
+		e.FirstLBA = GPTLBA(p.StartInBytes / sectorSize)
+		e.LastLBA = e.FirstLBA + GPTLBA(p.SizeInBytes/sectorSize) - 1
+		name := utf16.Encode([]rune(p.PartitionLabel))
+		if len(name) > gptNameLen {
+			return nil, fmt.Errorf("partition label %q is too long", p.PartitionLabel)
+		}
+		copy(e.Name[:], name)
+		binary.Write(entries, binary.LittleEndian, &e)
+	}
+	rawEntries := make([]byte, entriesSectors*sectorSize)
+	copy(rawEntries, entries.Bytes())
+	entriesCRC := crc32.ChecksumIEEE(rawEntries[:gptMaxEntries*gptEntrySize])
+
+	diskGUID, err := randomGUID()
+	if err != nil {
+		return nil, err
+	}
+	header := GPTHeader{
+		Revision:       1 << 16,
+		CurrentLBA:     1,
+		AlternateLBA:   GPTLBA(sectors - 1),
+		FirstUsableLBA: GPTLBA(firstUsable),
+		LastUsableLBA:  GPTLBA(lastUsable),
+		DiskGUID:       diskGUID,
+		EntriesLBA:     2,
+		NEntries:       gptMaxEntries,
+		EntrySize:      gptEntrySize,
+		EntriesCRC:     entriesCRC,
+	}
+	copy(header.Signature[:], "EFI PART")
+	header.HeaderSize = uint32(binary.Size(header))
+
+	backup := header
+	backup.CurrentLBA, backup.AlternateLBA = header.AlternateLBA, header.CurrentLBA
+	backup.EntriesLBA = GPTLBA(sectors - 1 - entriesSectors)
+
+	// the protective MBR covers the whole disk, or as much as fits
+	mbrSectors := sectors - 1
+	if mbrSectors > 0xffffffff {
+		mbrSectors = 0xffffffff
+	}
+	mbr := newMBR(bootCode, 0)
+	putMBREntry(mbr, 0, mbrEntry{
+		startCHS: [3]byte{0x00, 0x02, 0x00},
+		endCHS:   [3]byte{0xff, 0xff, 0xff},
+		typ:      mbrTypeGPT,
+		firstLBA: 1,
+		sectors:  uint32(mbrSectors),
+	})
+
+	return []diskWrite{
+		{offset: 0, data: mbr},
+		{offset: int64(sectorSize), data: encodeGPTHeader(header, sectorSize)},
+		{offset: int64(2 * sectorSize), data: rawEntries},
+		{offset: int64(uint64(backup.EntriesLBA) * sectorSize), data: rawEntries},
+		{offset: int64((sectors - 1) * sectorSize), data: encodeGPTHeader(backup, sectorSize)},
+	}, nil
+}
+
+func encodeGPTHeader(header GPTHeader, sectorSize uint64) []byte {
+	header.HeaderCRC = 0
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.LittleEndian, &header)
+	header.HeaderCRC = crc32.ChecksumIEEE(buf.Bytes())
+
+	raw := make([]byte, sectorSize)
+	buf = bytes.NewBuffer(raw[:0])
+	binary.Write(buf, binary.LittleEndian, &header)
+	return raw
+}
+
+type mbrEntry struct {
+	status   byte
+	startCHS [3]byte
+	typ      byte
+	endCHS   [3]byte
+	firstLBA uint32
+	sectors  uint32
+}
+
+func newMBR(bootCode []byte, diskSignature uint32) []byte {
+	mbr := make([]byte, mbrSize)
+	copy(mbr, bootCode)
+	binary.LittleEndian.PutUint32(mbr[mbrBootCodeSize:], diskSignature)
+	mbr[510] = 0x55
+	mbr[511] = 0xaa
+	return mbr
+}
+
+func putMBREntry(mbr []byte, idx int, e mbrEntry) {
+	raw := mbr[mbrEntriesOffset+idx*mbrEntrySize:]
+	raw[0] = e.status
+	copy(raw[1:4], e.startCHS[:])
+	raw[4] = e.typ
+	copy(raw[5:8], e.endCHS[:])
+	binary.LittleEndian.PutUint32(raw[8:12], e.firstLBA)
+	binary.LittleEndian.PutUint32(raw[12:16], e.sectors)
+}
+
+// lbaToCHS converts an LBA to the legacy CHS addressing assuming 255 heads
+// and 63 sectors per track, saturating at the maximum addressable value.
+func lbaToCHS(lba uint64) [3]byte {
+	const heads, sectorsPerTrack = 255, 63
+	cylinder := lba / (heads * sectorsPerTrack)
+	if cylinder > 1023 {
+		return [3]byte{0xfe, 0xff, 0xff}
+	}
+	head := (lba / sectorsPerTrack) % heads
+	sector := lba%sectorsPerTrack + 1
+	return [3]byte{byte(head), byte(sector) | byte((cylinder>>2)&0xc0), byte(cylinder)}
+}
+
+func buildDOS(r io.ReaderAt, parts []PartitionTableEntry, bootCode []byte, size, sectorSize uint64) ([]diskWrite, error) {
+	if len(parts) > mbrMaxEntries {
+		return nil, fmt.Errorf("too many partitions for a DOS disk: %d", len(parts))
+	}
+	sectors := size / sectorSize
+	if sectors < 2 {
+		return nil, fmt.Errorf("disk is too small for a DOS partition table")
+	}
+	lastUsable := sectors - 1
+	if lastUsable > 0xffffffff {
+		lastUsable = 0xffffffff
+	}
+	if err := validatePartitions(parts, sectorSize, 1, lastUsable); err != nil {
+		return nil, err
+	}
+
+	sig, err := randutil.CryptoTokenBytes(4)
+	if err != nil {
+		return nil, err
+	}
+	mbr := newMBR(bootCode, binary.LittleEndian.Uint32(sig))
+	for i, p := range parts {
+		if p.PartitionLabel != "" || p.PartitionUUID != "" {
+			return nil, fmt.Errorf("partition %d: labels and UUIDs are not supported on DOS disks", i+1)
+		}
+		typ, err := strconv.ParseUint(p.PartitionType, 16, 8)
+		if err != nil || typ == 0 {
+			return nil, fmt.Errorf("invalid partition type %q", p.PartitionType)
+		}
+		e := mbrEntry{
+			typ:      byte(typ),
+			firstLBA: uint32(p.StartInBytes / sectorSize),
+			sectors:  uint32(p.SizeInBytes / sectorSize),
+		}
+		if p.Bootable {
+			e.status = 0x80
+		}
+		e.startCHS = lbaToCHS(uint64(e.firstLBA))
+		e.endCHS = lbaToCHS(uint64(e.firstLBA) + uint64(e.sectors) - 1)
+		putMBREntry(mbr, i, e)
+	}
+
+	writes := []diskWrite{{offset: 0, data: mbr}}
+	// wipe the headers of a previous GPT, otherwise the disk would be
+	// still detected as such
+	for _, lba := range []uint64{1, sectors - 1} {
+		ok, err := hasGPTHeaderAt(r, lba, sectorSize)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			writes = append(writes, diskWrite{offset: int64(lba * sectorSize), data: make([]byte, sectorSize)})
+		}
+	}
+	return writes, nil
+}
@@ -0,0 +1,38 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package disks
+
+import (
+	"os"
+
+	"github.com/snapcore/snapd/osutil"
+)
+
+func deviceGeometry(f *os.File) (size, sectorSize uint64, isBlockDevice bool, err error) {
+	return 0, 0, false, osutil.ErrDarwin
+}
+
+var rereadPartitionTable = func(f *os.File) error {
+	return osutil.ErrDarwin
+}
+
+var udevSettle = func() error {
+	return osutil.ErrDarwin
+}
@@ -0,0 +1,75 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package disks
+
+import (
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/snapcore/snapd/osutil"
+)
+
+// imageSectorSize is the sector size assumed for disk images.
+const imageSectorSize = 512
+
+// deviceGeometry returns the size and sector size of the block device or
+// disk image opened as f, and whether f is a block device.
+func deviceGeometry(f *os.File) (size, sectorSize uint64, isBlockDevice bool, err error) {
+	st, err := f.Stat()
+	if err != nil {
+		return 0, 0, false, err
+	}
+	if st.Mode()&os.ModeDevice == 0 || st.Mode()&os.ModeCharDevice != 0 {
+		return uint64(st.Size()), imageSectorSize, false, nil
+	}
+
+	ss, err := unix.IoctlGetInt(int(f.Fd()), unix.BLKSSZGET)
+	if err != nil {
+		return 0, 0, false, os.NewSyscallError("BLKSSZGET", err)
+	}
+	if ss <= 0 {
+		return 0, 0, false, os.NewSyscallError("BLKSSZGET", unix.EINVAL)
+	}
+	_, _, errno := unix.Syscall(unix.SYS_IOCTL, f.Fd(), unix.BLKGETSIZE64, uintptr(unsafe.Pointer(&size)))
+	if errno != 0 {
+		return 0, 0, false, os.NewSyscallError("BLKGETSIZE64", errno)
+	}
+	return size, uint64(ss), true, nil
+}
+
+// rereadPartitionTable asks the kernel to re-read the partition table of the
+// block device opened as f.
+var rereadPartitionTable = func(f *os.File) error {
+	_, _, errno := unix.Syscall(unix.SYS_IOCTL, f.Fd(), unix.BLKRRPART, 0)
+	if errno != 0 {
+		return os.NewSyscallError("BLKRRPART", errno)
+	}
+	return nil
+}
+
+// udevSettle waits for the udev event queue to be processed.
+var udevSettle = func() error {
+	if output, stderr, err := osutil.RunSplitOutput("udevadm", "settle", "--timeout=180"); err != nil {
+		return osutil.OutputErrCombine(output, stderr, err)
+	}
+	return nil
+}
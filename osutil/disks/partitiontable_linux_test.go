@@ -0,0 +1,365 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package disks_test
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/osutil/disks"
+)
+
+type partitionTableSuite struct {
+	image string
+}
+
+var _ = Suite(&partitionTableSuite{})
+
+const (
+	oneMiB    = 1024 * 1024
+	imageSize = 64 * oneMiB
+)
+
+func (s *partitionTableSuite) SetUpTest(c *C) {
+	s.image = filepath.Join(c.MkDir(), "image.img")
+	c.Assert(os.WriteFile(s.image, nil, 0644), IsNil)
+	c.Assert(os.Truncate(s.image, imageSize), IsNil)
+}
+
+func (s *partitionTableSuite) readAt(c *C, offset int64, size int) []byte {
+	f, err := os.Open(s.image)
+	c.Assert(err, IsNil)
+	defer f.Close()
+	buf := make([]byte, size)
+	_, err = f.ReadAt(buf, offset)
+	c.Assert(err, IsNil)
+	return buf
+}
+
+func (s *partitionTableSuite) writeAt(c *C, offset int64, data []byte) {
+	f, err := os.OpenFile(s.image, os.O_RDWR, 0)
+	c.Assert(err, IsNil)
+	defer f.Close()
+	_, err = f.WriteAt(data, offset)
+	c.Assert(err, IsNil)
+}
+
+var gptTable = &disks.PartitionTable{
+	Schema: disks.SchemaGPT,
+	Partitions: []disks.PartitionTableEntry{
+		{
+			PartitionLabel: "ubuntu-seed",
+			PartitionType:  "C12A7328-F81F-11D2-BA4B-00A0C93EC93B",
+			PartitionUUID:  "11111111-2222-4333-8444-555555555555",
+			StartInBytes:   oneMiB,
+			SizeInBytes:    8 * oneMiB,
+		}, {
+			PartitionLabel: "ubuntu-data",
+			PartitionType:  "0FC63DAF-8483-4772-8E79-3D69D8477DE4",
+			StartInBytes:   9 * oneMiB,
+			SizeInBytes:    20 * oneMiB,
+		},
+	},
+}
+
+var dosTable = &disks.PartitionTable{
+	Schema: disks.SchemaDOS,
+	Partitions: []disks.PartitionTableEntry{
+		{
+			PartitionType: "0C",
+			Bootable:      true,
+			StartInBytes:  oneMiB,
+			SizeInBytes:   8 * oneMiB,
+		}, {
+			PartitionType: "83",
+			StartInBytes:  9 * oneMiB,
+			SizeInBytes:   20 * oneMiB,
+		},
+	},
+}
+
+func (s *partitionTableSuite) TestProbeNoPartitionTable(c *C) {
+	schema, err := disks.ProbePartitionTable(s.image)
+	c.Assert(err, IsNil)
+	c.Check(schema, Equals, "")
+}
+
+func (s *partitionTableSuite) TestProbeNotFound(c *C) {
+	_, err := disks.ProbePartitionTable(filepath.Join(c.MkDir(), "missing"))
+	c.Assert(err, ErrorMatches, "cannot probe partition table: open .*/missing: no such file or directory")
+}
+
+func (s *partitionTableSuite) TestProbeFATBootSectorIsNotDOS(c *C) {
+	bootSector := make([]byte, 512)
+	copy(bootSector[82:], "FAT32   ")
+	bootSector[510], bootSector[511] = 0x55, 0xaa
+	s.writeAt(c, 0, bootSector)
+
+	schema, err := disks.ProbePartitionTable(s.image)
+	c.Assert(err, IsNil)
+	c.Check(schema, Equals, "")
+}
+
+func (s *partitionTableSuite) TestWriteGPT(c *C) {
+	err := disks.WritePartitionTable(s.image, gptTable)
+	c.Assert(err, IsNil)
+
+	schema, err := disks.ProbePartitionTable(s.image)
+	c.Assert(err, IsNil)
+	c.Check(schema, Equals, disks.SchemaGPT)
+
+	// protective MBR
+	mbr := s.readAt(c, 0, 512)
+	c.Check(mbr[446+4], Equals, byte(0xee))
+	c.Check(binary.LittleEndian.Uint32(mbr[446+8:]), Equals, uint32(1))
+	c.Check(binary.LittleEndian.Uint32(mbr[446+12:]), Equals, uint32(imageSize/512-1))
+	c.Check(mbr[510:], DeepEquals, []byte{0x55, 0xaa})
+
+	header, err := disks.ReadGPTHeader(s.image, 512)
+	c.Assert(err, IsNil)
+	c.Check(header.CurrentLBA, Equals, disks.GPTLBA(1))
+	c.Check(header.AlternateLBA, Equals, disks.GPTLBA(imageSize/512-1))
+	c.Check(header.FirstUsableLBA, Equals, disks.GPTLBA(34))
+	c.Check(header.LastUsableLBA, Equals, disks.GPTLBA(imageSize/512-34))
+	c.Check(header.EntriesLBA, Equals, disks.GPTLBA(2))
+	c.Check(header.NEntries, Equals, uint32(128))
+	c.Check(header.EntrySize, Equals, uint32(128))
+
+	lastUsable, err := disks.CalculateLastUsableLBA(s.image, imageSize, 512)
+	c.Assert(err, IsNil)
+	c.Check(lastUsable, Equals, uint64(header.LastUsableLBA))
+
+	// first entry
+	entry := s.readAt(c, 2*512, 128)
+	// type GUID is stored mixed-endian
+	c.Check(entry[:16], DeepEquals, []byte{
+		0x28, 0x73, 0x2a, 0xc1, 0x1f, 0xf8, 0xd2, 0x11,
+		0xba, 0x4b, 0x00, 0xa0, 0xc9, 0x3e, 0xc9, 0x3b,
+	})
+	c.Check(entry[16:32], DeepEquals, []byte{
+		0x11, 0x11, 0x11, 0x11, 0x22, 0x22, 0x33, 0x43,
+		0x84, 0x44, 0x55, 0x55, 0x55, 0x55, 0x55, 0x55,
+	})
+	c.Check(binary.LittleEndian.Uint64(entry[32:]), Equals, uint64(2048))
+	c.Check(binary.LittleEndian.Uint64(entry[40:]), Equals, uint64(18431))
+	c.Check(entry[56:56+22], DeepEquals, []byte("u\x00b\x00u\x00n\x00t\x00u\x00-\x00s\x00e\x00e\x00d\x00"))
+
+	// second entry got a random unique GUID
+	entry = s.readAt(c, 2*512+128, 128)
+	c.Check(entry[16:32], Not(DeepEquals), make([]byte, 16))
+	c.Check(binary.LittleEndian.Uint64(entry[32:]), Equals, uint64(18432))
+	c.Check(binary.LittleEndian.Uint64(entry[40:]), Equals, uint64(59391))
+
+	// the backup table matches the primary one
+	c.Check(s.readAt(c, imageSize-33*512, 128*128), DeepEquals, s.readAt(c, 2*512, 128*128))
+}
+
+func (s *partitionTableSuite) TestWriteGPTBackupHeader(c *C) {
+	err := disks.WritePartitionTable(s.image, gptTable)
+	c.Assert(err, IsNil)
+
+	// break the primary header
+	s.writeAt(c, 512, []byte("NOTGPT"))
+
+	header, err := disks.ReadGPTHeader(s.image, 512)
+	c.Assert(err, IsNil)
+	c.Check(header.CurrentLBA, Equals, disks.GPTLBA(imageSize/512-1))
+	c.Check(header.AlternateLBA, Equals, disks.GPTLBA(1))
+	c.Check(header.EntriesLBA, Equals, disks.GPTLBA(imageSize/512-33))
+
+	schema, err := disks.ProbePartitionTable(s.image)
+	c.Assert(err, IsNil)
+	c.Check(schema, Equals, disks.SchemaGPT)
+}
+
+func (s *partitionTableSuite) TestWriteEmptyGPT(c *C) {
+	err := disks.WritePartitionTable(s.image, &disks.PartitionTable{Schema: disks.SchemaGPT})
+	c.Assert(err, IsNil)
+
+	schema, err := disks.ProbePartitionTable(s.image)
+	c.Assert(err, IsNil)
+	c.Check(schema, Equals, disks.SchemaGPT)
+	c.Check(s.readAt(c, 2*512, 128*128), DeepEquals, make([]byte, 128*128))
+}
+
+func (s *partitionTableSuite) TestWriteKeepsBootCode(c *C) {
+	bootCode := make([]byte, 440)
+	for i := range bootCode {
+		bootCode[i] = byte(i)
+	}
+	s.writeAt(c, 0, bootCode)
+
+	err := disks.WritePartitionTable(s.image, gptTable)
+	c.Assert(err, IsNil)
+	c.Check(s.readAt(c, 0, 440), DeepEquals, bootCode)
+
+	err = disks.WritePartitionTable(s.image, dosTable)
+	c.Assert(err, IsNil)
+	c.Check(s.readAt(c, 0, 440), DeepEquals, bootCode)
+}
+
+func (s *partitionTableSuite) TestWriteDOS(c *C) {
+	err := disks.WritePartitionTable(s.image, dosTable)
+	c.Assert(err, IsNil)
+
+	schema, err := disks.ProbePartitionTable(s.image)
+	c.Assert(err, IsNil)
+	c.Check(schema, Equals, disks.SchemaDOS)
+
+	mbr := s.readAt(c, 0, 512)
+	c.Check(mbr[510:], DeepEquals, []byte{0x55, 0xaa})
+	// disk signature
+	c.Check(mbr[440:444], Not(DeepEquals), make([]byte, 4))
+
+	first := mbr[446 : 446+16]
+	c.Check(first[0], Equals, byte(0x80))
+	c.Check(first[4], Equals, byte(0x0c))
+	c.Check(binary.LittleEndian.Uint32(first[8:]), Equals, uint32(2048))
+	c.Check(binary.LittleEndian.Uint32(first[12:]), Equals, uint32(16384))
+	// CHS of LBA 2048 is 0/32/33
+	c.Check(first[1:4], DeepEquals, []byte{32, 33, 0})
+
+	second := mbr[446+16 : 446+32]
+	c.Check(second[0], Equals, byte(0))
+	c.Check(second[4], Equals, byte(0x83))
+	c.Check(binary.LittleEndian.Uint32(second[8:]), Equals, uint32(18432))
+	c.Check(binary.LittleEndian.Uint32(second[12:]), Equals, uint32(40960))
+
+	c.Check(mbr[446+32:510], DeepEquals, make([]byte, 32))
+}
+
+func (s *partitionTableSuite) TestWriteDOSOverGPT(c *C) {
+	err := disks.WritePartitionTable(s.image, gptTable)
+	c.Assert(err, IsNil)
+
+	err = disks.WritePartitionTable(s.image, dosTable)
+	c.Assert(err, IsNil)
+
+	schema, err := disks.ProbePartitionTable(s.image)
+	c.Assert(err, IsNil)
+	c.Check(schema, Equals, disks.SchemaDOS)
+
+	// both GPT headers are gone
+	c.Check(s.readAt(c, 512, 512), DeepEquals, make([]byte, 512))
+	c.Check(s.readAt(c, imageSize-512, 512), DeepEquals, make([]byte, 512))
+}
+
+func (s *partitionTableSuite) TestWriteGPTOverDOS(c *C) {
+	err := disks.WritePartitionTable(s.image, dosTable)
+	c.Assert(err, IsNil)
+
+	err = disks.WritePartitionTable(s.image, gptTable)
+	c.Assert(err, IsNil)
+
+	schema, err := disks.ProbePartitionTable(s.image)
+	c.Assert(err, IsNil)
+	c.Check(schema, Equals, disks.SchemaGPT)
+}
+
+func (s *partitionTableSuite) TestWriteErrors(c *C) {
+	for _, tc := range []struct {
+		table *disks.PartitionTable
+		err   string
+	}{{
+		table: &disks.PartitionTable{Schema: "mbr"},
+		err:   `unknown schema "mbr"`,
+	}, {
+		table: &disks.PartitionTable{
+			Schema:     disks.SchemaGPT,
+			Partitions: []disks.PartitionTableEntry{{PartitionType: "0FC63DAF-8483-4772-8E79-3D69D8477DE4", StartInBytes: oneMiB + 1, SizeInBytes: oneMiB}},
+		},
+		err: `partition 1 is not aligned to the sector size 512`,
+	}, {
+		table: &disks.PartitionTable{
+			Schema:     disks.SchemaGPT,
+			Partitions: []disks.PartitionTableEntry{{PartitionType: "0FC63DAF-8483-4772-8E79-3D69D8477DE4", StartInBytes: oneMiB}},
+		},
+		err: `partition 1 has zero size`,
+	}, {
+		table: &disks.PartitionTable{
+			Schema:     disks.SchemaGPT,
+			Partitions: []disks.PartitionTableEntry{{PartitionType: "0FC63DAF-8483-4772-8E79-3D69D8477DE4", StartInBytes: 512, SizeInBytes: oneMiB}},
+		},
+		err: `partition 1 is outside of the usable sectors 34-131038`,
+	}, {
+		table: &disks.PartitionTable{
+			Schema:     disks.SchemaGPT,
+			Partitions: []disks.PartitionTableEntry{{PartitionType: "0FC63DAF-8483-4772-8E79-3D69D8477DE4", StartInBytes: oneMiB, SizeInBytes: imageSize}},
+		},
+		err: `partition 1 is outside of the usable sectors 34-131038`,
+	}, {
+		table: &disks.PartitionTable{
+			Schema: disks.SchemaGPT,
+			Partitions: []disks.PartitionTableEntry{
+				{PartitionType: "0FC63DAF-8483-4772-8E79-3D69D8477DE4", StartInBytes: oneMiB, SizeInBytes: 2 * oneMiB},
+				{PartitionType: "0FC63DAF-8483-4772-8E79-3D69D8477DE4", StartInBytes: 2 * oneMiB, SizeInBytes: oneMiB},
+			},
+		},
+		err: `partition 2 overlaps with partition 1`,
+	}, {
+		table: &disks.PartitionTable{
+			Schema:     disks.SchemaGPT,
+			Partitions: []disks.PartitionTableEntry{{PartitionType: "83", StartInBytes: oneMiB, SizeInBytes: oneMiB}},
+		},
+		err: `invalid partition type: invalid GUID "83"`,
+	}, {
+		table: &disks.PartitionTable{
+			Schema:     disks.SchemaGPT,
+			Partitions: []disks.PartitionTableEntry{{PartitionType: "0FC63DAF-8483-4772-8E79-3D69D8477DE4", PartitionLabel: "a-label-that-is-way-too-long-for-a-gpt", StartInBytes: oneMiB, SizeInBytes: oneMiB}},
+		},
+		err: `partition label "a-label-that-is-way-too-long-for-a-gpt" is too long`,
+	}, {
+		table: &disks.PartitionTable{
+			Schema:     disks.SchemaDOS,
+			Partitions: make([]disks.PartitionTableEntry, 5),
+		},
+		err: `too many partitions for a DOS disk: 5`,
+	}, {
+		table: &disks.PartitionTable{
+			Schema:     disks.SchemaDOS,
+			Partitions: []disks.PartitionTableEntry{{PartitionType: "83", PartitionLabel: "data", StartInBytes: oneMiB, SizeInBytes: oneMiB}},
+		},
+		err: `partition 1: labels and UUIDs are not supported on DOS disks`,
+	}, {
+		table: &disks.PartitionTable{
+			Schema:     disks.SchemaDOS,
+			Partitions: []disks.PartitionTableEntry{{PartitionType: "0FC63DAF-8483-4772-8E79-3D69D8477DE4", StartInBytes: oneMiB, SizeInBytes: oneMiB}},
+		},
+		err: `invalid partition type "0FC63DAF-8483-4772-8E79-3D69D8477DE4"`,
+	}} {
+		err := disks.WritePartitionTable(s.image, tc.table)
+		c.Check(err, ErrorMatches, "cannot write partition table to .*/image.img: "+tc.err)
+	}
+
+	// nothing was written
+	schema, err := disks.ProbePartitionTable(s.image)
+	c.Assert(err, IsNil)
+	c.Check(schema, Equals, "")
+}
+
+func (s *partitionTableSuite) TestDeviceGeometryImage(c *C) {
+	size, sectorSize, err := disks.DeviceGeometry(s.image)
+	c.Assert(err, IsNil)
+	c.Check(size, Equals, uint64(imageSize))
+	c.Check(sectorSize, Equals, uint64(512))
+}
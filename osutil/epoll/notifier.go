@@ -0,0 +1,243 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package epoll
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/snapcore/snapd/logger"
+)
+
+var (
+	// ErrNotifierClosed indicates that the notifier has been closed.
+	ErrNotifierClosed = errors.New("the notifier has been closed")
+
+	// ErrOverflow indicates that the kernel dropped notifications, so the
+	// state they describe must be resynchronized.
+	ErrOverflow = errors.New("kernel notification queue overflowed")
+)
+
+// DefaultNotifierBufferSize is the size of the buffer that notifications are
+// read into when NotifierOptions.BufferSize is not set.
+const DefaultNotifierBufferSize = 4096
+
+// NotifierOptions describe how notifications are received from a kernel
+// notification file and handled.
+type NotifierOptions struct {
+	// BufferSize is the size of the receive buffer, by default
+	// DefaultNotifierBufferSize.
+	BufferSize int
+	// Receive reads the available notifications from the file descriptor,
+	// it is given a buffer of BufferSize bytes which can be used for that.
+	// By default they are read with read(2) into that buffer. The buffer is
+	// reused, so Handle must not retain it.
+	Receive func(fd uintptr, buf []byte) ([]byte, error)
+	// Handle handles the received notifications.
+	Handle func(data []byte) error
+	// Resync, if set, is called when Receive or Handle return ErrOverflow,
+	// to resynchronize with the kernel state. Otherwise the overflow is an
+	// error.
+	Resync func() error
+}
+
+// Notifier waits for notifications on a kernel notification file, such as
+// the apparmor notification socket or a fanotify file descriptor, and hands
+// them over for handling. Closing the notifier closes the file and makes a
+// concurrent Run return.
+type Notifier struct {
+	opts NotifierOptions
+	buf  []byte
+
+	// mu guards the file against being closed while in use
+	mu   sync.Mutex
+	file *os.File
+	// fd is the file descriptor of file, kept to recognise its events
+	// after file has been closed
+	fd        int
+	poll      *Epoll
+	closeChan chan struct{}
+}
+
+// NewNotifier returns a notifier for the given kernel notification file,
+// which it takes ownership of.
+func NewNotifier(file *os.File, opts NotifierOptions) (*Notifier, error) {
+	if opts.Handle == nil {
+		return nil, fmt.Errorf("internal error: notifier without Handle")
+	}
+	if opts.BufferSize == 0 {
+		opts.BufferSize = DefaultNotifierBufferSize
+	}
+	poll, err := Open()
+	if err != nil {
+		return nil, err
+	}
+	fd := int(file.Fd())
+	if err := poll.Register(fd, Readable); err != nil {
+		poll.Close()
+		return nil, err
+	}
+	return &Notifier{
+		opts:      opts,
+		buf:       make([]byte, opts.BufferSize),
+		file:      file,
+		fd:        fd,
+		poll:      poll,
+		closeChan: make(chan struct{}),
+	}, nil
+}
+
+// Fd returns the file descriptor of the notification file.
+func (n *Notifier) Fd() int {
+	return n.fd
+}
+
+// Closed returns a channel which is closed once the notifier is closed.
+func (n *Notifier) Closed() <-chan struct{} {
+	return n.closeChan
+}
+
+// IsClosed returns whether the notifier has been closed.
+func (n *Notifier) IsClosed() bool {
+	select {
+	case <-n.closeChan:
+		return true
+	default:
+		return false
+	}
+}
+
+// Close closes the notification file, which tells the kernel that no one
+// is listening anymore, and then the epoll instance, so that a concurrent
+// Wait returns.
+func (n *Notifier) Close() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.IsClosed() {
+		return ErrNotifierClosed
+	}
+	err1 := n.file.Close()
+	close(n.closeChan)
+	err2 := n.poll.Close()
+	if err1 != nil {
+		return err1
+	}
+	return err2
+}
+
+// Do calls f with the file descriptor of the notification file, unless the
+// notifier is closed, in which case it returns ErrNotifierClosed. The
+// notifier cannot be closed while f runs.
+func (n *Notifier) Do(f func(fd uintptr) error) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.IsClosed() {
+		return ErrNotifierClosed
+	}
+	return f(n.file.Fd())
+}
+
+func (n *Notifier) receive() (data []byte, err error) {
+	err = n.Do(func(fd uintptr) error {
+		if n.opts.Receive != nil {
+			data, err = n.opts.Receive(fd, n.buf)
+			return err
+		}
+		count, err := unix.Read(int(fd), n.buf)
+		if err != nil {
+			return err
+		}
+		data = n.buf[:count]
+		return nil
+	})
+	return data, err
+}
+
+// Wait waits for events on the notification file. If the notifier is
+// closed meanwhile it returns ErrNotifierClosed.
+func (n *Notifier) Wait() ([]Event, error) {
+	events, err := n.poll.Wait()
+	if err != nil && n.IsClosed() {
+		return nil, ErrNotifierClosed
+	}
+	return events, err
+}
+
+func (n *Notifier) resync() error {
+	if n.opts.Resync == nil {
+		return ErrOverflow
+	}
+	logger.Debugf("resynchronizing after kernel notification queue overflow")
+	return n.opts.Resync()
+}
+
+// Dispatch receives and handles the notifications signalled by the given
+// events.
+func (n *Notifier) Dispatch(events []Event) error {
+	for _, event := range events {
+		if event.Fd != n.fd {
+			logger.Debugf("unexpected event from fd %v (%v)", event.Fd, event.Readiness)
+			continue
+		}
+		if event.Readiness&Readable == 0 {
+			continue
+		}
+		data, err := n.receive()
+		if errors.Is(err, ErrOverflow) {
+			if err := n.resync(); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := n.opts.Handle(data); err != nil {
+			if !errors.Is(err, ErrOverflow) {
+				return err
+			}
+			if err := n.resync(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Run waits for and handles notifications until the notifier is closed,
+// in which case it returns nil, or an error occurs.
+func (n *Notifier) Run() error {
+	for {
+		events, err := n.Wait()
+		if err == nil {
+			err = n.Dispatch(events)
+		}
+		if errors.Is(err, ErrNotifierClosed) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,226 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package epoll_test
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/osutil/epoll"
+)
+
+type notifierSuite struct{}
+
+var _ = Suite(&notifierSuite{})
+
+func (*notifierSuite) pipe(c *C) (r, w *os.File) {
+	r, w, err := os.Pipe()
+	c.Assert(err, IsNil)
+	return r, w
+}
+
+func (s *notifierSuite) TestRunHandleClose(c *C) {
+	r, w := s.pipe(c)
+	defer w.Close()
+
+	received := make(chan string)
+	n, err := epoll.NewNotifier(r, epoll.NotifierOptions{
+		BufferSize: 8,
+		Handle: func(data []byte) error {
+			received <- string(data)
+			return nil
+		},
+	})
+	c.Assert(err, IsNil)
+	c.Check(n.Fd(), Equals, int(r.Fd()))
+
+	runErr := make(chan error)
+	go func() {
+		runErr <- n.Run()
+	}()
+
+	_, err = w.Write([]byte("hello"))
+	c.Assert(err, IsNil)
+	select {
+	case data := <-received:
+		c.Check(data, Equals, "hello")
+	case <-time.After(defaultDuration * 10):
+		c.Fatal("timed out waiting for notification")
+	}
+
+	// notifications longer than the buffer are received in pieces
+	_, err = w.Write([]byte("0123456789"))
+	c.Assert(err, IsNil)
+	var data string
+	for len(data) < 10 {
+		select {
+		case piece := <-received:
+			c.Check(len(piece) <= 8, Equals, true)
+			data += piece
+		case <-time.After(defaultDuration * 10):
+			c.Fatal("timed out waiting for notification")
+		}
+	}
+	c.Check(data, Equals, "0123456789")
+
+	c.Check(n.IsClosed(), Equals, false)
+	c.Assert(n.Close(), IsNil)
+	c.Check(n.IsClosed(), Equals, true)
+	select {
+	case <-n.Closed():
+	default:
+		c.Error("closed channel not closed")
+	}
+	select {
+	case err := <-runErr:
+		c.Check(err, IsNil)
+	case <-time.After(defaultDuration * 10):
+		c.Fatal("timed out waiting for Run to return")
+	}
+
+	c.Check(n.Close(), Equals, epoll.ErrNotifierClosed)
+	c.Check(n.Do(func(fd uintptr) error {
+		c.Error("unexpected call")
+		return nil
+	}), Equals, epoll.ErrNotifierClosed)
+	_, err = n.Wait()
+	c.Check(err, Equals, epoll.ErrNotifierClosed)
+}
+
+func (s *notifierSuite) TestHandleError(c *C) {
+	r, w := s.pipe(c)
+	defer w.Close()
+
+	n, err := epoll.NewNotifier(r, epoll.NotifierOptions{
+		Handle: func(data []byte) error {
+			return errors.New("boom")
+		},
+	})
+	c.Assert(err, IsNil)
+	defer n.Close()
+
+	_, err = w.Write([]byte("hello"))
+	c.Assert(err, IsNil)
+	c.Check(n.Run(), ErrorMatches, "boom")
+}
+
+func (s *notifierSuite) TestReceiveOverflowResync(c *C) {
+	r, w := s.pipe(c)
+	defer w.Close()
+
+	var handled []string
+	resyncs := 0
+	n, err := epoll.NewNotifier(r, epoll.NotifierOptions{
+		BufferSize: 16,
+		Receive: func(fd uintptr, buf []byte) ([]byte, error) {
+			c.Check(buf, HasLen, 16)
+			count, err := r.Read(buf)
+			if err != nil {
+				return nil, err
+			}
+			if string(buf[:count]) == "overflow" {
+				return nil, epoll.ErrOverflow
+			}
+			return buf[:count], nil
+		},
+		Handle: func(data []byte) error {
+			if string(data) == "lost" {
+				return epoll.ErrOverflow
+			}
+			handled = append(handled, string(data))
+			return nil
+		},
+		Resync: func() error {
+			resyncs++
+			return nil
+		},
+	})
+	c.Assert(err, IsNil)
+	defer n.Close()
+
+	for _, t := range []struct {
+		msg     string
+		resyncs int
+	}{
+		{"overflow", 1},
+		{"lost", 2},
+		{"hello", 2},
+	} {
+		_, err = w.Write([]byte(t.msg))
+		c.Assert(err, IsNil)
+		events, err := n.Wait()
+		c.Assert(err, IsNil)
+		c.Assert(n.Dispatch(events), IsNil)
+		c.Check(resyncs, Equals, t.resyncs)
+	}
+	c.Check(handled, DeepEquals, []string{"hello"})
+}
+
+func (s *notifierSuite) TestOverflowWithoutResync(c *C) {
+	r, w := s.pipe(c)
+	defer w.Close()
+
+	n, err := epoll.NewNotifier(r, epoll.NotifierOptions{
+		Handle: func(data []byte) error {
+			return epoll.ErrOverflow
+		},
+	})
+	c.Assert(err, IsNil)
+	defer n.Close()
+
+	_, err = w.Write([]byte("hello"))
+	c.Assert(err, IsNil)
+	c.Check(n.Run(), Equals, epoll.ErrOverflow)
+}
+
+func (s *notifierSuite) TestDispatchIgnoresOtherEvents(c *C) {
+	r, w := s.pipe(c)
+	defer w.Close()
+
+	n, err := epoll.NewNotifier(r, epoll.NotifierOptions{
+		Handle: func(data []byte) error {
+			c.Error("unexpected call")
+			return nil
+		},
+	})
+	c.Assert(err, IsNil)
+	defer n.Close()
+
+	c.Check(n.Dispatch([]epoll.Event{
+		{Fd: n.Fd() + 100, Readiness: epoll.Readable},
+		{Fd: n.Fd(), Readiness: epoll.Writable},
+	}), IsNil)
+}
+
+func (s *notifierSuite) TestNewNotifierErrors(c *C) {
+	_, err := epoll.NewNotifier(os.NewFile(^uintptr(0), "bad"), epoll.NotifierOptions{
+		Handle: func(data []byte) error { return nil },
+	})
+	c.Check(err, ErrorMatches, "bad file descriptor")
+
+	r, w := s.pipe(c)
+	defer r.Close()
+	defer w.Close()
+	_, err = epoll.NewNotifier(r, epoll.NotifierOptions{})
+	c.Check(err, ErrorMatches, "internal error: notifier without Handle")
+}
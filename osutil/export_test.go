@@ -58,6 +58,14 @@ func MockCopyFile(new func(fileish, fileish, os.FileInfo) error) (restore func()
 	}
 }
 
+func MockCloneFile(new func(fileish, fileish) error) (restore func()) {
+	old := clonefile
+	clonefile = new
+	return func() {
+		clonefile = old
+	}
+}
+
 func MockOpenFile(new func(string, int, os.FileMode) (fileish, error)) (restore func()) {
 	old := openfile
 	openfile = new
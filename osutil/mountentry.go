@@ -313,6 +313,18 @@ func (e *MountEntry) XSnapdMustExistDir() string {
 	return val
 }
 
+// XSnapdMimic returns the strategy for constructing writable mimics needed by
+// a given mount entry.
+//
+// Writable mimics are constructed with a tmpfs, replicating the contents of
+// the original directory, unless the "x-snapd.mimic" mount option selects
+// "overlay", in which case an overlayfs is mounted over the original
+// directory when supported.
+func (e *MountEntry) XSnapdMimic() string {
+	val, _ := e.OptStr("x-snapd.mimic")
+	return val
+}
+
 // XSnapdNeededBy returns the string "x-snapd.needed-by=..." with the given path appended.
 func XSnapdNeededBy(path string) string {
 	return fmt.Sprintf("x-snapd.needed-by=%s", path)
@@ -378,6 +390,11 @@ func XSnapdMustExistDir(path string) string {
 	return fmt.Sprintf("x-snapd.must-exist-dir=%s", path)
 }
 
+// XSnapdMimicOverlay returns the string "x-snapd.mimic=overlay".
+func XSnapdMimicOverlay() string {
+	return "x-snapd.mimic=overlay"
+}
+
 // XSnapdIgnoreMissing returns the string "x-snapd.ignore-missing".
 func XSnapdIgnoreMissing() string {
 	return "x-snapd.ignore-missing"
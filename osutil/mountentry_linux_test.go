@@ -534,3 +534,16 @@ func (s *entrySuite) TestXSnapdMustExistDir(c *C) {
 	e = &osutil.MountEntry{Options: []string{osutil.XSnapdMustExistDir("$HOME")}}
 	c.Assert(e.XSnapdMustExistDir(), Equals, "$HOME")
 }
+
+func (s *entrySuite) TestXSnapdMimic(c *C) {
+	// By default entries use tmpfs based writable mimics
+	e := &osutil.MountEntry{}
+	c.Assert(e.XSnapdMimic(), Equals, "")
+
+	// A mount entry can ask for overlayfs based writable mimics
+	e = &osutil.MountEntry{Options: []string{osutil.XSnapdMimicOverlay()}}
+	c.Assert(e.XSnapdMimic(), Equals, "overlay")
+
+	// There's a helper function that returns this option string.
+	c.Assert(osutil.XSnapdMimicOverlay(), Equals, "x-snapd.mimic=overlay")
+}
@@ -0,0 +1,230 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package strace
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SyscallCount counts the calls of a system call.
+type SyscallCount struct {
+	Calls  int `json:"calls"`
+	Errors int `json:"errors,omitempty"`
+}
+
+// SyscallSummary summarises the system calls done by a traced
+// application, to help debugging its confinement.
+type SyscallSummary struct {
+	Syscalls map[string]*SyscallCount `json:"syscalls"`
+	// SeccompDenied are the system calls that failed with EPERM and
+	// that the seccomp profile does not allow unconditionally.
+	SeccompDenied []string `json:"seccomp-denied"`
+	// AppArmorDenied are the paths that system calls failed to access
+	// with EACCES, which is how AppArmor reports denials.
+	AppArmorDenied []string `json:"apparmor-denied"`
+}
+
+// SyscallSummaryOptions control how a strace log is summarised.
+type SyscallSummaryOptions struct {
+	// NotifyTraceAttached is called when strace attached to the
+	// traced process.
+	NotifyTraceAttached func()
+	// StartAt, if set, is given the lines of the log until it returns
+	// true, the system calls are summarised from that line on. It is
+	// used to skip what happens before the application runs.
+	StartAt func(line string) bool
+	// SeccompProfile is the source of the seccomp profile of the
+	// traced application. Without it the system calls denied by
+	// seccomp are not identified.
+	SeccompProfile []byte
+}
+
+// lines look like, with or without the PID and the timestamp:
+// PID   SYSCALL
+// 17363 openat(AT_FDCWD, "/etc/shadow", O_RDONLY|O_CLOEXEC) = -1 EACCES (Permission denied)
+// 17363 read(3,  <unfinished ...>
+// 17363 <... read resumed>"", 4096) = 0
+var (
+	syscallRE = regexp.MustCompile(`^(?:\[pid\s+([0-9]+)\]\s+|([0-9]+)\s+)?(?:[0-9.:]+\s+)?([a-z0-9_]+)\((.*)$`)
+	resumedRE = regexp.MustCompile(`^(?:\[pid\s+([0-9]+)\]\s+|([0-9]+)\s+)?(?:[0-9.:]+\s+)?<\.\.\. ([a-z0-9_]+) resumed>(.*)$`)
+	errnoRE   = regexp.MustCompile(`\)\s+=\s+-1\s+(E[A-Z0-9]+)\b`)
+	pathRE    = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"`)
+)
+
+type pendingSyscall struct {
+	name string
+	path string
+}
+
+type syscallSummarizer struct {
+	summary        *SyscallSummary
+	seccompDenies  func(syscall string) bool
+	seccompDenied  map[string]bool
+	apparmorDenied map[string]bool
+	// unfinished system calls by PID
+	pending map[string]pendingSyscall
+}
+
+func (ss *syscallSummarizer) finish(name, path, rest string) {
+	m := errnoRE.FindStringSubmatch(rest)
+	if m == nil {
+		return
+	}
+	ss.summary.Syscalls[name].Errors++
+	switch m[1] {
+	case "EPERM":
+		if ss.seccompDenies != nil && ss.seccompDenies(name) {
+			ss.seccompDenied[name] = true
+		}
+	case "EACCES":
+		if path != "" {
+			ss.apparmorDenied[path] = true
+		}
+	}
+}
+
+func (ss *syscallSummarizer) add(line string) {
+	if m := resumedRE.FindStringSubmatch(line); m != nil {
+		pid := m[1] + m[2]
+		call, ok := ss.pending[pid]
+		if !ok || call.name != m[3] {
+			return
+		}
+		delete(ss.pending, pid)
+		ss.finish(call.name, call.path, m[4])
+		return
+	}
+
+	m := syscallRE.FindStringSubmatch(line)
+	if m == nil {
+		// signals, exits and strace messages
+		return
+	}
+	pid, name, rest := m[1]+m[2], m[3], m[4]
+	count := ss.summary.Syscalls[name]
+	if count == nil {
+		count = &SyscallCount{}
+		ss.summary.Syscalls[name] = count
+	}
+	count.Calls++
+
+	var path string
+	if p := pathRE.FindStringSubmatch(rest); p != nil && strings.HasPrefix(p[1], "/") {
+		path = p[1]
+	}
+	if strings.HasSuffix(rest, "<unfinished ...>") {
+		ss.pending[pid] = pendingSyscall{name: name, path: path}
+		return
+	}
+	ss.finish(name, path, rest)
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// seccompDenies returns whether the seccomp profile with the given source
+// may deny the system call.
+func seccompDenies(profile []byte) func(syscall string) bool {
+	allowed := make(map[string]bool)
+	denied := make(map[string]bool)
+	for _, line := range strings.Split(string(profile), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		switch {
+		case fields[0] == "@unrestricted" || fields[0] == "@complain":
+			// nothing is denied
+			return func(string) bool { return false }
+		case strings.HasPrefix(fields[0], "~"):
+			denied[strings.TrimPrefix(fields[0], "~")] = true
+		case len(fields) == 1:
+			allowed[fields[0]] = true
+		}
+		// a rule with argument filters only allows some calls
+	}
+	return func(syscall string) bool {
+		return denied[syscall] || !allowed[syscall]
+	}
+}
+
+// SummarizeSyscalls reads the strace log at the given path and summarises
+// the system calls it shows.
+func SummarizeSyscalls(straceLog string, opts *SyscallSummaryOptions) (*SyscallSummary, error) {
+	slog, err := os.Open(straceLog)
+	if err != nil {
+		return nil, err
+	}
+	defer slog.Close()
+
+	if opts == nil {
+		opts = &SyscallSummaryOptions{}
+	}
+	ss := &syscallSummarizer{
+		summary:        &SyscallSummary{Syscalls: make(map[string]*SyscallCount)},
+		seccompDenied:  make(map[string]bool),
+		apparmorDenied: make(map[string]bool),
+		pending:        make(map[string]pendingSyscall),
+	}
+	if opts.SeccompProfile != nil {
+		ss.seccompDenies = seccompDenies(opts.SeccompProfile)
+	}
+
+	scanner := bufio.NewScanner(slog)
+	// part 1, wait for strace to attach
+	for scanner.Scan() {
+		if StraceAttachedStart(scanner.Text()) {
+			if opts.NotifyTraceAttached != nil {
+				opts.NotifyTraceAttached()
+			}
+			break
+		}
+	}
+	// part 2, skip until what is interesting starts
+	if opts.StartAt != nil {
+		for scanner.Scan() {
+			if line := scanner.Text(); opts.StartAt(line) {
+				ss.add(line)
+				break
+			}
+		}
+	}
+	// part 3, summarise
+	for scanner.Scan() {
+		ss.add(scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	ss.summary.SeccompDenied = sortedKeys(ss.seccompDenied)
+	ss.summary.AppArmorDenied = sortedKeys(ss.apparmorDenied)
+	return ss.summary, nil
+}
@@ -0,0 +1,130 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package strace_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/osutil/strace"
+)
+
+type summarySuite struct{}
+
+var _ = Suite(&summarySuite{})
+
+var sampleStraceSyscalls = []byte(`strace: Process 1234 attached
+1234  --- stopped by SIGSTOP ---
+1234  --- SIGCONT {si_signo=SIGCONT, si_code=SI_USER, si_pid=1200, si_uid=1000} ---
+1234  execve("/usr/lib/snapd/snap-confine", ["snap-confine", "snap.foo.app"], 0x7ffd /* 30 vars */) = 0
+1234  openat(AT_FDCWD, "/etc/ld.so.cache", O_RDONLY|O_CLOEXEC) = 3
+1234  execve("/snap/foo/x1/bin/app", ["app"], 0x7ffd /* 30 vars */) = 0
+1234  openat(AT_FDCWD, "/etc/ld.so.cache", O_RDONLY|O_CLOEXEC) = 3
+1234  openat(AT_FDCWD, "/etc/shadow", O_RDONLY) = -1 EACCES (Permission denied)
+1234  clone(child_stack=NULL, flags=CLONE_CHILD_CLEARTID|SIGCHLD) = 1235
+1235  read(3,  <unfinished ...>
+1234  mount("tmpfs", "/mnt", "tmpfs", 0, NULL) = -1 EPERM (Operation not permitted)
+1235  <... read resumed>"", 4096)       = 0
+1235  kill(1, SIGTERM)                  = -1 EPERM (Operation not permitted)
+1235  openat(AT_FDCWD, "/home/user/.ssh/id_rsa",  <unfinished ...>
+1234  wait4(-1,  <unfinished ...>
+1235  <... openat resumed>O_RDONLY)     = -1 EACCES (Permission denied)
+1235  +++ exited with 0 +++
+1234  <... wait4 resumed>NULL, 0, NULL) = 1235
+1234  --- SIGCHLD {si_signo=SIGCHLD, si_code=CLD_EXITED, si_pid=1235, si_uid=1000, si_status=0, si_utime=0, si_stime=0} ---
+1234  +++ exited with 0 +++
+`)
+
+const sampleSeccompProfile = `# Description: some snap
+clone
+kill
+mount - - - |MS_BIND
+openat
+read
+~ioctl - TIOCSTI
+wait4
+`
+
+func (s *summarySuite) writeLog(c *C) string {
+	straceLog := filepath.Join(c.MkDir(), "strace.log")
+	c.Assert(os.WriteFile(straceLog, sampleStraceSyscalls, 0644), IsNil)
+	return straceLog
+}
+
+func (s *summarySuite) TestSummarizeSyscalls(c *C) {
+	attachedCalled := 0
+	summary, err := strace.SummarizeSyscalls(s.writeLog(c), &strace.SyscallSummaryOptions{
+		NotifyTraceAttached: func() { attachedCalled++ },
+		StartAt: func(line string) bool {
+			return strings.Contains(line, `execve("/snap/`)
+		},
+		SeccompProfile: []byte(sampleSeccompProfile),
+	})
+	c.Assert(err, IsNil)
+	c.Check(attachedCalled, Equals, 1)
+	c.Check(summary, DeepEquals, &strace.SyscallSummary{
+		Syscalls: map[string]*strace.SyscallCount{
+			"execve": {Calls: 1},
+			"openat": {Calls: 3, Errors: 2},
+			"clone":  {Calls: 1},
+			"read":   {Calls: 1},
+			"mount":  {Calls: 1, Errors: 1},
+			"kill":   {Calls: 1, Errors: 1},
+			"wait4":  {Calls: 1},
+		},
+		// kill is allowed without conditions, it failed for other reasons
+		SeccompDenied:  []string{"mount"},
+		AppArmorDenied: []string{"/etc/shadow", "/home/user/.ssh/id_rsa"},
+	})
+}
+
+func (s *summarySuite) TestSummarizeSyscallsNoOptions(c *C) {
+	summary, err := strace.SummarizeSyscalls(s.writeLog(c), nil)
+	c.Assert(err, IsNil)
+	c.Check(summary.Syscalls["execve"], DeepEquals, &strace.SyscallCount{Calls: 2})
+	c.Check(summary.Syscalls["openat"], DeepEquals, &strace.SyscallCount{Calls: 4, Errors: 2})
+	// without a profile denials by seccomp are not identified
+	c.Check(summary.SeccompDenied, HasLen, 0)
+	c.Check(summary.AppArmorDenied, DeepEquals, []string{"/etc/shadow", "/home/user/.ssh/id_rsa"})
+}
+
+func (s *summarySuite) TestSummarizeSyscallsUnrestricted(c *C) {
+	summary, err := strace.SummarizeSyscalls(s.writeLog(c), &strace.SyscallSummaryOptions{
+		SeccompProfile: []byte("# Description: unrestricted\n@unrestricted\n"),
+	})
+	c.Assert(err, IsNil)
+	c.Check(summary.SeccompDenied, HasLen, 0)
+}
+
+func (s *summarySuite) TestSummarizeSyscallsExplicitDeny(c *C) {
+	summary, err := strace.SummarizeSyscalls(s.writeLog(c), &strace.SyscallSummaryOptions{
+		SeccompProfile: []byte("~kill\nmount\n"),
+	})
+	c.Assert(err, IsNil)
+	c.Check(summary.SeccompDenied, DeepEquals, []string{"kill"})
+}
+
+func (s *summarySuite) TestSummarizeSyscallsNoLog(c *C) {
+	_, err := strace.SummarizeSyscalls(filepath.Join(c.MkDir(), "missing"), nil)
+	c.Check(err, ErrorMatches, `open .*/missing: no such file or directory`)
+}
@@ -24,7 +24,9 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 )
 
@@ -35,12 +37,35 @@ type ExeRuntime struct {
 	TotalSec float64
 }
 
+// ExeStage is the runtime of one of the snapd helpers involved in starting a
+// snap application, like snap-confine, snap-update-ns or snap-exec.
+type ExeStage struct {
+	Exe string
+	// StartSec is the time the helper was executed at, relative to the
+	// start of the profile.
+	StartSec float64
+	TotalSec float64
+}
+
+// startupHelpers are the snapd helpers tracked as startup stages.
+var startupHelpers = map[string]bool{
+	"snap-confine":   true,
+	"snap-update-ns": true,
+	"snap-exec":      true,
+}
+
 // ExecveTiming measures the execve calls timings under strace. This is
 // useful for performance analysis. It keeps the N slowest samples.
 type ExecveTiming struct {
 	TotalTime   float64
 	exeRuntimes []ExeRuntime
 
+	// StartTime is the start of the profile in seconds since the epoch.
+	StartTime float64
+	// Stages holds the runtimes of the snapd helpers involved in
+	// starting the application, ordered by their start time.
+	Stages []ExeStage
+
 	nSlowestSamples int
 }
 
@@ -50,6 +75,18 @@ func NewExecveTiming(nSlowestSamples int) *ExecveTiming {
 	return &ExecveTiming{nSlowestSamples: nSlowestSamples}
 }
 
+// addExeRun records the run of the given executable between start and end.
+func (stt *ExecveTiming) addExeRun(exe string, start, end float64) {
+	stt.addExeRuntime(exe, end-start)
+	if startupHelpers[filepath.Base(exe)] {
+		stt.Stages = append(stt.Stages, ExeStage{
+			Exe:      exe,
+			StartSec: start - stt.StartTime,
+			TotalSec: end - start,
+		})
+	}
+}
+
 func (stt *ExecveTiming) addExeRuntime(exe string, totalSec float64) {
 	stt.exeRuntimes = append(stt.exeRuntimes, ExeRuntime{
 		Exe:      exe,
@@ -143,7 +180,7 @@ func handleExecMatch(trace *ExecveTiming, pt *pidTracker, match []string) error
 	exe := match[3]
 	// deal with subsequent execve()
 	if start, exe := pt.Get(pid); exe != "" {
-		trace.addExeRuntime(exe, execStart-start)
+		trace.addExeRun(exe, start, execStart)
 	}
 	pt.Add(pid, execStart, exe)
 	return nil
@@ -159,7 +196,7 @@ func handleSignalMatch(trace *ExecveTiming, pt *pidTracker, match []string) erro
 	}
 	sigPid := match[3]
 	if start, exe := pt.Get(sigPid); exe != "" {
-		trace.addExeRuntime(exe, sigTime-start)
+		trace.addExeRun(exe, start, sigTime)
 		pt.Del(sigPid)
 	}
 	return nil
@@ -200,6 +237,7 @@ func TraceExecveTimings(straceLog string, nSlowest int, notifyTraceAttached func
 			if _, err := fmt.Sscanf(line, "%f %f ", &tmp, &start); err != nil {
 				return nil, fmt.Errorf("cannot parse start of exec profile: %s", err)
 			}
+			trace.StartTime = start
 		}
 
 		// handleExecMatch looks for execve{,at}() calls and
@@ -235,6 +273,9 @@ func TraceExecveTimings(straceLog string, nSlowest int, notifyTraceAttached func
 		return nil, fmt.Errorf("cannot parse end of exec profile: %s", err)
 	}
 	trace.TotalTime = end - start
+	sort.SliceStable(trace.Stages, func(i, j int) bool {
+		return trace.Stages[i].StartSec < trace.Stages[j].StartSec
+	})
 
 	if r.Err() != nil {
 		return nil, r.Err()
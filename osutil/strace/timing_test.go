@@ -21,6 +21,7 @@ package strace_test
 
 import (
 	"bytes"
+	"math"
 	"os"
 
 	. "gopkg.in/check.v1"
@@ -139,5 +140,16 @@ func (s *timingSuite) TestTraceExecveTimings(c *C) {
 		{Exe: "/snap/core/5976/usr/lib/snapd/snap-confine", TotalSec: 0.15650391578674316},
 		{Exe: "/usr/lib/snapd/snap-exec", TotalSec: 0.006349086761474609},
 	})
+	c.Check(st.StartTime, Equals, 1542882400.198907)
+	c.Assert(st.Stages, HasLen, 3)
+	for i, exp := range []strace.ExeStage{
+		{Exe: "/snap/core/5976/usr/lib/snapd/snap-confine", StartSec: 0.021938, TotalSec: 0.156504},
+		{Exe: "snap-update-ns", StartSec: 0.157718, TotalSec: 0.004244},
+		{Exe: "/usr/lib/snapd/snap-exec", StartSec: 0.178442, TotalSec: 0.006349},
+	} {
+		c.Check(st.Stages[i].Exe, Equals, exp.Exe)
+		c.Check(math.Abs(st.Stages[i].StartSec-exp.StartSec) < 1e-6, Equals, true, Commentf("%v", st.Stages[i]))
+		c.Check(math.Abs(st.Stages[i].TotalSec-exp.TotalSec) < 1e-6, Equals, true, Commentf("%v", st.Stages[i]))
+	}
 	c.Check(attachedCalled, Equals, 1)
 }
@@ -25,6 +25,7 @@ package assertstate
 import (
 	"errors"
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
 
@@ -52,6 +53,12 @@ func AddBatch(s *state.State, batch *asserts.Batch, opts *asserts.CommitOptions)
 	return batch.CommitTo(cachedDB(s), opts)
 }
 
+// AddStream adds the assertions read from r to the system assertion
+// database in batches of bounded size, see asserts.Database.AddStream.
+func AddStream(s *state.State, r io.Reader, opts *asserts.StreamOptions) error {
+	return cachedDB(s).AddStream(r, opts)
+}
+
 func findError(format string, ref *asserts.Ref, err error) error {
 	if errors.Is(err, &asserts.NotFoundError{}) {
 		return fmt.Errorf(format, ref)
@@ -345,6 +352,22 @@ func Store(s *state.State, store string) (*asserts.Store, error) {
 	return a.(*asserts.Store), nil
 }
 
+// AutoConnectionControl returns the auto-connection-control assertion of
+// the given brand and model about the snap with the given snap-id, if it is
+// present in the system assertion database.
+func AutoConnectionControl(s *state.State, brandID, model, snapID string) (*asserts.AutoConnectionControl, error) {
+	db := DB(s)
+	a, err := db.Find(asserts.AutoConnectionControlType, map[string]string{
+		"brand-id": brandID,
+		"model":    model,
+		"snap-id":  snapID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a.(*asserts.AutoConnectionControl), nil
+}
+
 // AutoAliases returns the explicit automatic aliases alias=>app mapping for the given installed snap.
 func AutoAliases(s *state.State, info *snap.Info) (map[string]string, error) {
 	if info.SnapID == "" {
@@ -403,6 +426,8 @@ func delayedCrossMgrInit() {
 	snapstate.EnforceLocalValidationSets = ApplyLocalEnforcedValidationSets
 	// hook helper for getting validated integrity data
 	snapstate.ValidatedIntegrityData = ValidatedIntegrityData
+	// hook helper for getting the snap file digest of a snap revision
+	snapstate.SnapRevisionDigest = SnapRevisionDigest
 	// wire confdbstate helpers that look up confdb-schema assertions
 	confdbstate.AssertstateFetchConfdbSchemaAssertion = FetchConfdbSchemaAssertion
 	confdbstate.AssertstateConfdbSchema = ConfdbSchema
@@ -1485,3 +1510,15 @@ func ValidatedIntegrityData(st *state.State, snapID string, rev snap.Revision) (
 
 	return integrity.NewIntegrityDataParamsFromRevision(revAssertion)
 }
+
+// SnapRevisionDigest returns the snap file digest listed in the snap-revision
+// assertion found in the assertion database for a specific snap revision.
+//
+// ErrNoRevisionFound is returned if no matching revision assertion is found.
+func SnapRevisionDigest(st *state.State, snapID string, rev snap.Revision) (string, error) {
+	revAssertion, err := snapRevisionFromSnapIdAndRevision(DB(st), snapID, rev)
+	if err != nil {
+		return "", err
+	}
+	return revAssertion.SnapSHA3_384(), nil
+}
@@ -375,6 +375,29 @@ func (s *assertMgrSuite) TestAddBatch(c *C) {
 	c.Check(devAcct.(*asserts.Account).Username(), Equals, "developer1")
 }
 
+func (s *assertMgrSuite) TestAddStream(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	b := &bytes.Buffer{}
+	enc := asserts.NewEncoder(b)
+	// wrong order is ok
+	err := enc.Encode(s.dev1Acct)
+	c.Assert(err, IsNil)
+	err = enc.Encode(s.storeSigning.StoreAccountKey(""))
+	c.Assert(err, IsNil)
+
+	err = assertstate.AddStream(s.state, b, &asserts.StreamOptions{BatchSize: 1})
+	c.Assert(err, IsNil)
+
+	db := assertstate.DB(s.state)
+	devAcct, err := db.Find(asserts.AccountType, map[string]string{
+		"account-id": s.dev1Acct.AccountID(),
+	})
+	c.Assert(err, IsNil)
+	c.Check(devAcct.(*asserts.Account).Username(), Equals, "developer1")
+}
+
 func (s *assertMgrSuite) TestAddBatchPartial(c *C) {
 	// Commit does add any successful assertion until the first error
 	s.state.Lock()
@@ -2647,6 +2670,41 @@ func (s *assertMgrSuite) TestStore(c *C) {
 	c.Check(store.Store(), Equals, "foo")
 }
 
+func (s *assertMgrSuite) TestAutoConnectionControl(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	err := assertstate.Add(s.state, s.storeSigning.StoreAccountKey(""))
+	c.Assert(err, IsNil)
+	err = assertstate.Add(s.state, s.dev1Acct)
+	c.Assert(err, IsNil)
+	err = assertstate.Add(s.state, s.dev1AcctKey)
+	c.Assert(err, IsNil)
+	brandID := s.dev1Acct.AccountID()
+	ctrl, err := s.dev1Signing.Sign(asserts.AutoConnectionControlType, map[string]any{
+		"brand-id": brandID,
+		"model":    "my-model",
+		"snap-id":  "snapidsnapidsnapidsnapidsnapidsn",
+		"plugs": map[string]any{
+			"hardware-observe": map[string]any{
+				"allow-auto-connection": "true",
+			},
+		},
+		"timestamp": time.Now().Format(time.RFC3339),
+	}, nil, "")
+	c.Assert(err, IsNil)
+	err = assertstate.Add(s.state, ctrl)
+	c.Assert(err, IsNil)
+
+	_, err = assertstate.AutoConnectionControl(s.state, brandID, "other-model", "snapidsnapidsnapidsnapidsnapidsn")
+	c.Check(errors.Is(err, &asserts.NotFoundError{}), Equals, true)
+
+	found, err := assertstate.AutoConnectionControl(s.state, brandID, "my-model", "snapidsnapidsnapidsnapidsnapidsn")
+	c.Assert(err, IsNil)
+	c.Check(found.SnapID(), Equals, "snapidsnapidsnapidsnapidsnapidsn")
+	c.Check(found.PlugRule("hardware-observe"), NotNil)
+}
+
 // validation-sets related tests
 
 func (s *assertMgrSuite) TestRefreshValidationSetAssertionsNop(c *C) {
@@ -6315,3 +6373,29 @@ func (s *assertMgrSuite) TestValidatedIntegrityDataErrorNoRevisionsFound(c *C) {
 		expErr:         regexp.QuoteMeta("no snap-revision assertion found that matches (snap-id=snap-id-1, snap-revision=10)."),
 	})
 }
+
+func (s *assertMgrSuite) TestSnapRevisionDigest(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	_, digests := s.prereqSnapAssertions(c, nil, "", false, 10)
+	snapDecl, err := s.storeSigning.Find(asserts.SnapDeclarationType, map[string]string{
+		"series":  "16",
+		"snap-id": "snap-id-1",
+	})
+	c.Assert(err, IsNil)
+	snapRev, err := s.storeSigning.Find(asserts.SnapRevisionType, map[string]string{
+		"snap-sha3-384": digests[10],
+	})
+	c.Assert(err, IsNil)
+	for _, a := range []asserts.Assertion{s.storeSigning.StoreAccountKey(""), s.dev1Acct, s.dev1AcctKey, snapDecl, snapRev} {
+		c.Assert(assertstate.Add(s.state, a), IsNil)
+	}
+
+	digest, err := assertstate.SnapRevisionDigest(s.state, "snap-id-1", snap.R(10))
+	c.Assert(err, IsNil)
+	c.Check(digest, Equals, digests[10])
+
+	_, err = assertstate.SnapRevisionDigest(s.state, "snap-id-1", snap.R(11))
+	c.Check(err, testutil.ErrorIs, assertstate.ErrNoRevisionFound)
+}
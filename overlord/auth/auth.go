@@ -35,6 +35,19 @@ import (
 	"github.com/snapcore/snapd/seclog"
 )
 
+func init() {
+	// the credentials are sealed when the state is persisted, if a sealing
+	// key is in use, and redacted when the state is inspected
+	state.RegisterSensitive("auth",
+		"macaroon-key",
+		"device.session-macaroon",
+		"users.*.macaroon",
+		"users.*.discharges.*",
+		"users.*.store-macaroon",
+		"users.*.store-discharges.*",
+	)
+}
+
 // AuthState represents current authenticated users as tracked in state
 type AuthState struct {
 	LastID      int          `json:"last-id"`
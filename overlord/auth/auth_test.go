@@ -22,6 +22,7 @@ package auth_test
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"fmt"
 	"strings"
 	"testing"
@@ -98,6 +99,39 @@ func (s *authSuite) TestMacaroonDeserializeInvalidData(c *C) {
 	c.Check(err, NotNil)
 }
 
+func (as *authSuite) TestCredentialsAreSensitive(c *C) {
+	as.state.Lock()
+	defer as.state.Unlock()
+
+	_, err := auth.NewUser(as.state, auth.NewUserParams{
+		Username:   "username",
+		Email:      "email@test.com",
+		Macaroon:   "store-macaroon-value",
+		Discharges: []string{"store-discharge-value"},
+	})
+	c.Assert(err, IsNil)
+
+	var authStateData auth.AuthState
+	c.Assert(as.state.Get("auth", &authStateData), IsNil)
+	authStateData.Device = &auth.DeviceState{
+		Brand:           "canonical",
+		SessionMacaroon: "session-macaroon-value",
+	}
+	as.state.Set("auth", authStateData)
+	user := authStateData.Users[0]
+
+	redacted, err := as.state.RedactedJSON()
+	c.Assert(err, IsNil)
+	for _, secret := range []string{
+		"store-macaroon-value", "store-discharge-value", "session-macaroon-value", user.Macaroon,
+		base64.StdEncoding.EncodeToString(authStateData.MacaroonKey),
+	} {
+		c.Check(strings.Contains(string(redacted), secret), Equals, false, Commentf("%q found in %s", secret, redacted))
+	}
+	c.Check(string(redacted), testutil.Contains, `"email":"email@test.com"`)
+	c.Check(string(redacted), testutil.Contains, `"brand":"canonical"`)
+}
+
 func (as *authSuite) TestNewUser(c *C) {
 	as.state.Lock()
 	user, err := auth.NewUser(as.state, auth.NewUserParams{
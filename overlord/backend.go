@@ -20,20 +20,92 @@
 package overlord
 
 import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"path/filepath"
 	"time"
 
+	"golang.org/x/crypto/hkdf"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/features"
+	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/secboot"
 )
 
+var secbootGetPrimaryKey = secboot.GetPrimaryKey
+
+// stateSealingKeyLabel is the HKDF info used to derive the state sealing
+// key from the primary key. It must never change, otherwise the sensitive
+// fields sealed on existing devices could not be unsealed anymore.
+const stateSealingKeyLabel = "snapd state sealing key"
+
 type overlordStateBackend struct {
 	path         string
+	log          *state.StateLog
 	ensureBefore func(d time.Duration)
 }
 
 func (osb *overlordStateBackend) Checkpoint(data []byte) error {
-	return osutil.AtomicWriteFile(osb.path, data, 0600, 0)
+	if err := osutil.AtomicWriteFile(osb.path, data, 0600, 0); err != nil {
+		return err
+	}
+	// the log of changes, if any, is now part of the state file
+	return osb.log.Remove()
 }
 
 func (osb *overlordStateBackend) EnsureBefore(d time.Duration) {
 	osb.ensureBefore(d)
 }
+
+// overlordStateLogBackend is used instead of overlordStateBackend when the
+// state-log feature is enabled.
+type overlordStateLogBackend struct {
+	*overlordStateBackend
+}
+
+func (osb overlordStateLogBackend) AppendLog(generation int, record []byte) error {
+	return osb.log.Append(generation, record)
+}
+
+// stateSealingKey returns the key sealing the sensitive fields of the state,
+// derived from the primary key protecting the encrypted disks of the system.
+// No key is returned if the system is not encrypted.
+func stateSealingKey() ([]byte, error) {
+	saveFDEDir := dirs.SnapFDEDirUnderSave(dirs.SnapSaveDir)
+	primaryKey, err := secbootGetPrimaryKey(nil, []string{
+		filepath.Join(saveFDEDir, "aux-key"),
+		filepath.Join(saveFDEDir, "tpm-policy-auth-key"),
+	})
+	if err != nil {
+		logger.Debugf("no primary key to derive the state sealing key from: %v", err)
+		return nil, nil
+	}
+	r := hkdf.New(sha256.New, primaryKey, nil, []byte(stateSealingKeyLabel))
+	key := make([]byte, state.SealingKeySize)
+	if _, err := io.ReadFull(r, key); err != nil {
+		return nil, fmt.Errorf("cannot derive state sealing key: %v", err)
+	}
+	return key, nil
+}
+
+// useStateSealingKey unseals the sensitive fields of the state which were
+// sealed and, if the sealed-state feature is enabled, seals them from now on.
+func useStateSealingKey(s *state.State) error {
+	key, err := stateSealingKey()
+	if err != nil {
+		return err
+	}
+	seal := features.SealedState.IsEnabled()
+	if seal && key == nil {
+		logger.Noticef("cannot seal the state: no key is available on systems without disk encryption")
+		seal = false
+	}
+
+	// the state is not shared yet, taking the lock would only checkpoint
+	// it right away
+	return s.UseSealingKey(key, seal)
+}
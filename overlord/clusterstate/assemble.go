@@ -0,0 +1,580 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package clusterstate
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/tomb.v2"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/cluster/assemblestate"
+	"github.com/snapcore/snapd/overlord/assertstate"
+	"github.com/snapcore/snapd/overlord/devicestate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/randutil"
+)
+
+// assembleStateKey is the key under which the state of the assembly of a
+// cluster is kept.
+const assembleStateKey = "cluster-assemble"
+
+func init() {
+	// the shared secret and the private key of the device's certificate are
+	// sealed when the state is persisted, if a sealing key is in use
+	state.RegisterSensitive(assembleStateKey, "secret", "tls-key")
+}
+
+var (
+	devicestateSerial = devicestate.Serial
+	netListen         = net.Listen
+	timeNow           = time.Now
+
+	// assemblePublishPeriod is how often routes are published to the peers
+	// during an assembly
+	assemblePublishPeriod = 5 * time.Second
+	// assembleDiscoveryPeriod is how often the peers that were given
+	// explicitly are contacted again, until they are reached
+	assembleDiscoveryPeriod = 10 * time.Second
+)
+
+// ErrNotInCluster indicates that the device is neither part of a cluster nor
+// assembling one.
+var ErrNotInCluster = errors.New("device is not part of a cluster")
+
+// assembleState contains the state of the assembly of a cluster that this
+// device takes part in.
+type assembleState struct {
+	// Secret is the secret shared by the devices assembling the cluster.
+	Secret string `json:"secret"`
+	// RDT is the random device token identifying this device during the
+	// assembly.
+	RDT assemblestate.DeviceToken `json:"rdt"`
+	// TLSCert and TLSKey are the PEM-encoded certificate, and its private
+	// key, that this device uses to communicate with its peers.
+	TLSCert []byte `json:"tls-cert"`
+	TLSKey  []byte `json:"tls-key"`
+	// Address is the address this device listens on for its peers.
+	Address string `json:"address"`
+	// Peers are the addresses of the peers to reach out to.
+	Peers []string `json:"peers,omitempty"`
+	// ExpectedSize is the expected number of devices in the cluster, if
+	// known.
+	ExpectedSize int `json:"expected-size,omitempty"`
+	// Session is the progress of the assembly, as last committed.
+	Session assemblestate.AssembleSession `json:"session"`
+	// Done is set once the assembly is complete.
+	Done bool `json:"done,omitempty"`
+}
+
+func currentAssembly(st *state.State) (*assembleState, error) {
+	var as assembleState
+	if err := st.Get(assembleStateKey, &as); err != nil {
+		return nil, err
+	}
+	return &as, nil
+}
+
+// updateAssembly applies update to the state of the assembly identified by
+// rdt, unless the assembly was forgotten or replaced in the meantime.
+func updateAssembly(st *state.State, rdt assemblestate.DeviceToken, update func(as *assembleState)) {
+	as, err := currentAssembly(st)
+	if err != nil || as.RDT != rdt {
+		return
+	}
+	update(as)
+	st.Set(assembleStateKey, as)
+}
+
+// forgetAssembly forgets the assembly identified by rdt.
+func forgetAssembly(st *state.State, rdt assemblestate.DeviceToken) {
+	as, err := currentAssembly(st)
+	if err != nil || as.RDT != rdt {
+		return
+	}
+	st.Set(assembleStateKey, nil)
+}
+
+// AssembleOptions contains the options for assembling a cluster.
+type AssembleOptions struct {
+	// Secret is the secret shared by all the devices assembling the cluster.
+	Secret string
+	// Address is the address, as host:port, that this device listens on for
+	// the other devices.
+	Address string
+	// Peers are the addresses of other devices to reach out to.
+	Peers []string
+	// ExpectedSize is the number of devices expected in the cluster. If
+	// unset, the assembly goes on until its session expires.
+	ExpectedSize int
+}
+
+// Assemble returns a task set which makes the device take part in the
+// assembly of a cluster with the devices sharing the same secret. Callers
+// must hold the state lock.
+func Assemble(st *state.State, opts AssembleOptions) (*state.TaskSet, error) {
+	if opts.Secret == "" {
+		return nil, errors.New("cannot assemble cluster: secret is required")
+	}
+	if _, _, err := net.SplitHostPort(opts.Address); err != nil {
+		return nil, fmt.Errorf("cannot assemble cluster: invalid address %q: %v", opts.Address, err)
+	}
+	for _, peer := range opts.Peers {
+		if _, _, err := net.SplitHostPort(peer); err != nil {
+			return nil, fmt.Errorf("cannot assemble cluster: invalid peer address %q: %v", peer, err)
+		}
+	}
+	if opts.ExpectedSize < 0 {
+		return nil, fmt.Errorf("cannot assemble cluster: invalid expected size %d", opts.ExpectedSize)
+	}
+
+	existing, err := currentAssembly(st)
+	if err != nil && !errors.Is(err, state.ErrNoState) {
+		return nil, err
+	}
+	if existing != nil {
+		if existing.Done {
+			return nil, errors.New("cannot assemble cluster: device is already part of an assembled cluster")
+		}
+		// an assembly whose change is gone, e.g. aborted before it
+		// started, is replaced
+		if len(assembleChanges(st)) > 0 {
+			return nil, errors.New("cannot assemble cluster: an assembly is already in progress")
+		}
+	}
+
+	var cs clusterState
+	if err := st.Get("cluster", &cs); err != nil && !errors.Is(err, state.ErrNoState) {
+		return nil, err
+	}
+	if cs.Current.ClusterID != "" {
+		return nil, fmt.Errorf("cannot assemble cluster: device is already part of cluster %q", cs.Current.ClusterID)
+	}
+
+	token, err := randutil.CryptoToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("cannot assemble cluster: cannot generate device token: %v", err)
+	}
+	rdt := assemblestate.DeviceToken(token)
+
+	now := timeNow()
+	cert, key, err := generateCertificate(rdt, now)
+	if err != nil {
+		return nil, fmt.Errorf("cannot assemble cluster: cannot generate certificate: %v", err)
+	}
+
+	st.Set(assembleStateKey, &assembleState{
+		Secret:       opts.Secret,
+		RDT:          rdt,
+		TLSCert:      cert,
+		TLSKey:       key,
+		Address:      opts.Address,
+		Peers:        opts.Peers,
+		ExpectedSize: opts.ExpectedSize,
+		Session: assemblestate.AssembleSession{
+			Initiated: now,
+		},
+	})
+
+	t := st.NewTask("assemble-cluster", "Assemble cluster")
+	t.Set("rdt", rdt)
+
+	return state.NewTaskSet(t), nil
+}
+
+// assembleChanges returns the changes assembling a cluster which are not
+// ready yet.
+func assembleChanges(st *state.State) []*state.Change {
+	var changes []*state.Change
+	for _, chg := range st.Changes() {
+		if chg.Status().Ready() {
+			continue
+		}
+		for _, t := range chg.Tasks() {
+			if t.Kind() == "assemble-cluster" {
+				changes = append(changes, chg)
+				break
+			}
+		}
+	}
+	return changes
+}
+
+// generateCertificate returns a new PEM-encoded self-signed certificate and
+// its private key. Peers identify the certificate by its fingerprint, the
+// certificate itself is never verified against a chain of trust.
+func generateCertificate(rdt assemblestate.DeviceToken, now time.Time) (certPEM, keyPEM []byte, err error) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	template := x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: string(rdt)},
+		NotBefore:    now,
+		NotAfter:     now.AddDate(10, 0, 0),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &priv.PublicKey, priv)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+
+	return certPEM, keyPEM, nil
+}
+
+func (m *ClusterManager) doAssembleCluster(t *state.Task, tomb *tomb.Tomb) error {
+	st := t.State()
+	st.Lock()
+	defer st.Unlock()
+
+	var rdt assemblestate.DeviceToken
+	if err := t.Get("rdt", &rdt); err != nil {
+		return err
+	}
+
+	as, err := currentAssembly(st)
+	if err != nil && !errors.Is(err, state.ErrNoState) {
+		return err
+	}
+	if as == nil || as.RDT != rdt {
+		return errors.New("cannot assemble cluster: assembly is not in progress anymore")
+	}
+	if as.Done {
+		return nil
+	}
+
+	ids, routes, err := m.runAssembly(st, as, tomb)
+	if err == nil {
+		select {
+		case <-tomb.Dying():
+			// snapd is stopping, in which case the assembly resumes
+			// later on, or the change was aborted and the assembly
+			// is forgotten when the task is undone
+			return &state.Retry{}
+		default:
+		}
+
+		if as.ExpectedSize > 0 && len(ids) < as.ExpectedSize {
+			err = fmt.Errorf("cannot assemble cluster: found %d of the %d expected devices before the session expired", len(ids), as.ExpectedSize)
+		}
+	}
+	if err != nil {
+		forgetAssembly(st, rdt)
+		return err
+	}
+
+	updateAssembly(st, rdt, func(as *assembleState) {
+		as.Session.Devices.IDs = ids
+		as.Session.Routes = routes
+		as.Done = true
+	})
+
+	return nil
+}
+
+// runAssembly runs the assembly described by as until it completes, its
+// session expires or the tomb is killed. It must be called with the state
+// locked, but releases the lock while the assembly runs.
+func (m *ClusterManager) runAssembly(st *state.State, as *assembleState, tomb *tomb.Tomb) ([]assemblestate.Identity, assemblestate.Routes, error) {
+	serial, err := devicestateSerial(st)
+	if err != nil {
+		return nil, assemblestate.Routes{}, fmt.Errorf("cannot assemble cluster without a serial assertion: %v", err)
+	}
+	db := assertstate.DB(st)
+
+	st.Unlock()
+	defer st.Lock()
+
+	signer := func(data []byte) ([]byte, error) {
+		st.Lock()
+		defer st.Unlock()
+		return m.signer.SignWithDeviceKey(data)
+	}
+
+	commit := func(session assemblestate.AssembleSession) {
+		st.Lock()
+		defer st.Unlock()
+		updateAssembly(st, as.RDT, func(as *assembleState) {
+			as.Session = session
+		})
+	}
+
+	selector := func(self assemblestate.DeviceToken, identified func(assemblestate.DeviceToken) bool) (assemblestate.RouteSelector, error) {
+		return assemblestate.NewPrioritySelector(self, nil, identified), nil
+	}
+
+	assembler, err := assemblestate.NewAssembleState(assemblestate.AssembleConfig{
+		Secret:       as.Secret,
+		RDT:          as.RDT,
+		TLSCert:      as.TLSCert,
+		TLSKey:       as.TLSKey,
+		Clock:        timeNow,
+		ExpectedSize: as.ExpectedSize,
+		Serial:       serial,
+		Signer:       signer,
+	}, as.Session, selector, commit, db)
+	if err != nil {
+		return nil, assemblestate.Routes{}, fmt.Errorf("cannot assemble cluster: %v", err)
+	}
+
+	ln, err := netListen("tcp", as.Address)
+	if err != nil {
+		return nil, assemblestate.Routes{}, fmt.Errorf("cannot assemble cluster: cannot listen on %s: %v", as.Address, err)
+	}
+	defer ln.Close()
+
+	deadline := as.Session.Initiated.Add(assemblestate.AssembleSessionLength)
+	ctx, cancel := context.WithDeadline(tomb.Context(nil), deadline)
+	defer cancel()
+
+	discoveries := make(chan []string)
+	if len(as.Peers) > 0 {
+		go func() {
+			for {
+				select {
+				case discoveries <- as.Peers:
+				case <-ctx.Done():
+					return
+				}
+				select {
+				case <-time.After(assembleDiscoveryPeriod):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	ids, routes, err := assembler.Run(ctx, ln, assemblestate.NewHTTPSTransport(), discoveries, assemblestate.RunOptions{
+		Period: assemblePublishPeriod,
+	})
+	if err != nil {
+		return nil, assemblestate.Routes{}, fmt.Errorf("cannot assemble cluster: %v", err)
+	}
+	return ids, routes, nil
+}
+
+func (m *ClusterManager) undoAssembleCluster(t *state.Task, _ *tomb.Tomb) error {
+	st := t.State()
+	st.Lock()
+	defer st.Unlock()
+
+	var rdt assemblestate.DeviceToken
+	if err := t.Get("rdt", &rdt); err != nil {
+		return err
+	}
+	forgetAssembly(st, rdt)
+
+	return nil
+}
+
+// Leave makes the device leave the cluster it is part of: any assembly in
+// progress is aborted and the assembly, the cluster assertion being tracked
+// and the refreshes coordinated with the other members are forgotten. The
+// snaps installed on behalf of the cluster are left alone. Callers must hold
+// the state lock.
+func Leave(st *state.State) error {
+	as, err := currentAssembly(st)
+	if err != nil && !errors.Is(err, state.ErrNoState) {
+		return err
+	}
+
+	var cs clusterState
+	if err := st.Get("cluster", &cs); err != nil && !errors.Is(err, state.ErrNoState) {
+		return err
+	}
+
+	if as == nil && cs.Current.ClusterID == "" {
+		return ErrNotInCluster
+	}
+
+	for _, chg := range assembleChanges(st) {
+		chg.Abort()
+	}
+
+	st.Set(assembleStateKey, nil)
+	st.Set("cluster", nil)
+	st.Set(refreshStateKey, nil)
+
+	st.EnsureBefore(0)
+
+	return nil
+}
+
+// Status describes the clustering state of the device.
+type Status struct {
+	// ClusterID is the ID of the cluster assertion being tracked, if any.
+	ClusterID string
+	// Assembly describes the assembly of a cluster this device takes part
+	// in, if any.
+	Assembly *AssemblyStatus
+	// Refreshes describes the coordination of the refreshes of clustered
+	// snaps, once the assembly is done.
+	Refreshes *RefreshStatus
+}
+
+// AssemblyStatus describes the progress of the assembly of a cluster.
+type AssemblyStatus struct {
+	// RDT is the random device token of this device.
+	RDT string
+	// Address is the address this device listens on for its peers.
+	Address string
+	// Fingerprint is the fingerprint of the certificate of this device.
+	Fingerprint string
+	// Initiated is when the assembly started.
+	Initiated time.Time
+	// ExpectedSize is the expected number of devices, if known.
+	ExpectedSize int
+	// Done is set once the assembly is complete.
+	Done bool
+	// Members are the devices identified so far, including this one.
+	Members []Member
+	// Trusted is the number of peers which proved knowledge of the secret.
+	Trusted int
+	// Routes is the number of verified routes between the devices.
+	Routes int
+}
+
+// Member describes a device identified during the assembly of a cluster.
+type Member struct {
+	RDT         string
+	Serial      string
+	Address     string
+	Fingerprint string
+}
+
+// ClusterStatus returns the clustering state of the device. Callers must hold
+// the state lock.
+func ClusterStatus(st *state.State) (*Status, error) {
+	var cs clusterState
+	if err := st.Get("cluster", &cs); err != nil && !errors.Is(err, state.ErrNoState) {
+		return nil, err
+	}
+
+	status := &Status{
+		ClusterID: cs.Current.ClusterID,
+	}
+
+	as, err := currentAssembly(st)
+	if err != nil {
+		if errors.Is(err, state.ErrNoState) {
+			return status, nil
+		}
+		return nil, err
+	}
+
+	fp, err := certificateFingerprint(as.TLSCert)
+	if err != nil {
+		return nil, err
+	}
+
+	session := as.Session
+	status.Assembly = &AssemblyStatus{
+		RDT:          string(as.RDT),
+		Address:      as.Address,
+		Fingerprint:  fp,
+		Initiated:    session.Initiated,
+		ExpectedSize: as.ExpectedSize,
+		Done:         as.Done,
+		Trusted:      len(session.Trusted),
+		Routes:       len(session.Routes.Routes) / 3,
+	}
+
+	for _, id := range session.Devices.IDs {
+		member := Member{
+			RDT:         string(id.RDT),
+			Serial:      bundleSerial(id.SerialBundle),
+			Fingerprint: base64.StdEncoding.EncodeToString(id.FP[:]),
+		}
+		if id.RDT == as.RDT {
+			member.Address = as.Address
+		} else {
+			member.Address = session.Addresses[member.Fingerprint]
+		}
+		status.Assembly.Members = append(status.Assembly.Members, member)
+	}
+	sort.Slice(status.Assembly.Members, func(i, j int) bool {
+		return status.Assembly.Members[i].RDT < status.Assembly.Members[j].RDT
+	})
+
+	if as.Done {
+		status.Refreshes, err = refreshStatus(st)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return status, nil
+}
+
+func certificateFingerprint(certPEM []byte) (string, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return "", errors.New("internal error: cannot decode cluster assembly certificate")
+	}
+	fp := assemblestate.CalculateFP(block.Bytes)
+	return base64.StdEncoding.EncodeToString(fp[:]), nil
+}
+
+// bundleSerial returns the serial number of the device from the serial
+// assertion in the given bundle, or an empty string if there is none.
+func bundleSerial(bundle string) string {
+	dec := asserts.NewDecoder(strings.NewReader(bundle))
+	for {
+		a, err := dec.Decode()
+		if err != nil {
+			return ""
+		}
+		if serial, ok := a.(*asserts.Serial); ok {
+			return serial.Serial()
+		}
+	}
+}
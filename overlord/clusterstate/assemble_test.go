@@ -0,0 +1,355 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package clusterstate_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"strings"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/cluster/assemblestate"
+	"github.com/snapcore/snapd/overlord/clusterstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type assembleSuite struct{}
+
+var _ = check.Suite(&assembleSuite{})
+
+type keySigner struct {
+	key asserts.PrivateKey
+}
+
+func (s keySigner) SignWithDeviceKey(data []byte) ([]byte, error) {
+	return asserts.RawSignWithKey(data, s.key)
+}
+
+func runChange(c *check.C, st *state.State, runner *state.TaskRunner, chg *state.Change) {
+	st.Unlock()
+	defer st.Lock()
+
+	for i := 0; i < 50; i++ {
+		runner.Ensure()
+		runner.Wait()
+
+		st.Lock()
+		ready := chg.IsReady()
+		st.Unlock()
+		if ready {
+			return
+		}
+	}
+	c.Fatalf("change %s did not become ready", chg.ID())
+}
+
+func (s *assembleSuite) TestAssembleInvalidOptions(c *check.C) {
+	st := state.New(nil)
+	st.Lock()
+	defer st.Unlock()
+
+	for _, tc := range []struct {
+		opts clusterstate.AssembleOptions
+		err  string
+	}{{
+		opts: clusterstate.AssembleOptions{Address: "10.0.0.1:7070"},
+		err:  "cannot assemble cluster: secret is required",
+	}, {
+		opts: clusterstate.AssembleOptions{Secret: "secret", Address: "10.0.0.1"},
+		err:  `cannot assemble cluster: invalid address "10.0.0.1": .*`,
+	}, {
+		opts: clusterstate.AssembleOptions{Secret: "secret", Address: "10.0.0.1:7070", Peers: []string{"10.0.0.2:7070", "nope"}},
+		err:  `cannot assemble cluster: invalid peer address "nope": .*`,
+	}, {
+		opts: clusterstate.AssembleOptions{Secret: "secret", Address: "10.0.0.1:7070", ExpectedSize: -1},
+		err:  "cannot assemble cluster: invalid expected size -1",
+	}} {
+		_, err := clusterstate.Assemble(st, tc.opts)
+		c.Check(err, check.ErrorMatches, tc.err)
+	}
+
+	var v any
+	c.Check(st.Get("cluster-assemble", &v), testutil.ErrorIs, state.ErrNoState)
+}
+
+func (s *assembleSuite) TestAssemble(c *check.C) {
+	st := state.New(nil)
+	st.Lock()
+	defer st.Unlock()
+
+	ts, err := clusterstate.Assemble(st, clusterstate.AssembleOptions{
+		Secret:       "top-secret",
+		Address:      "10.0.0.1:7070",
+		Peers:        []string{"10.0.0.2:7070"},
+		ExpectedSize: 2,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(ts.Tasks(), check.HasLen, 1)
+	c.Check(ts.Tasks()[0].Kind(), check.Equals, "assemble-cluster")
+
+	status, err := clusterstate.ClusterStatus(st)
+	c.Assert(err, check.IsNil)
+	c.Check(status.ClusterID, check.Equals, "")
+	c.Assert(status.Assembly, check.NotNil)
+	c.Check(status.Assembly.RDT, check.Not(check.Equals), "")
+	c.Check(status.Assembly.Address, check.Equals, "10.0.0.1:7070")
+	c.Check(status.Assembly.Fingerprint, check.Not(check.Equals), "")
+	c.Check(status.Assembly.ExpectedSize, check.Equals, 2)
+	c.Check(status.Assembly.Initiated.IsZero(), check.Equals, false)
+	c.Check(status.Assembly.Done, check.Equals, false)
+	c.Check(status.Assembly.Members, check.HasLen, 0)
+
+	var rdt string
+	c.Assert(ts.Tasks()[0].Get("rdt", &rdt), check.IsNil)
+	c.Check(rdt, check.Equals, status.Assembly.RDT)
+
+	// the secret and the private key are sensitive
+	redacted, err := st.RedactedJSON()
+	c.Assert(err, check.IsNil)
+	c.Check(strings.Contains(string(redacted), "top-secret"), check.Equals, false)
+	c.Check(strings.Contains(string(redacted), "PRIVATE KEY"), check.Equals, false)
+	c.Check(strings.Contains(string(redacted), "10.0.0.1:7070"), check.Equals, true)
+}
+
+func (s *assembleSuite) TestAssembleAlreadyInProgress(c *check.C) {
+	st := state.New(nil)
+	st.Lock()
+	defer st.Unlock()
+
+	opts := clusterstate.AssembleOptions{Secret: "secret", Address: "10.0.0.1:7070"}
+	ts, err := clusterstate.Assemble(st, opts)
+	c.Assert(err, check.IsNil)
+	chg := st.NewChange("assemble-cluster", "...")
+	chg.AddAll(ts)
+
+	_, err = clusterstate.Assemble(st, opts)
+	c.Check(err, check.ErrorMatches, "cannot assemble cluster: an assembly is already in progress")
+
+	// an assembly without a change is replaced
+	chg.SetStatus(state.HoldStatus)
+	_, err = clusterstate.Assemble(st, opts)
+	c.Check(err, check.IsNil)
+}
+
+func (s *assembleSuite) TestAssembleAlreadyInCluster(c *check.C) {
+	st := state.New(nil)
+	st.Lock()
+	defer st.Unlock()
+
+	st.Set("cluster", map[string]any{
+		"current": map[string]any{"cluster-id": "cluster-id", "sequence": 1},
+	})
+
+	_, err := clusterstate.Assemble(st, clusterstate.AssembleOptions{Secret: "secret", Address: "10.0.0.1:7070"})
+	c.Check(err, check.ErrorMatches, `cannot assemble cluster: device is already part of cluster "cluster-id"`)
+}
+
+func (s *assembleSuite) TestDoAssembleClusterSingleDevice(c *check.C) {
+	st, stack := newStateWithStoreStack(c)
+	serial, key := makeSerialAssertionAndKey(c, stack, "serial-1")
+
+	st.Lock()
+	defer st.Unlock()
+
+	addSerialToState(c, st, serial)
+
+	runner := state.NewTaskRunner(st)
+	clusterstate.Manager(st, runner, keySigner{key: key})
+
+	ts, err := clusterstate.Assemble(st, clusterstate.AssembleOptions{
+		Secret:       "secret",
+		Address:      "127.0.0.1:0",
+		ExpectedSize: 1,
+	})
+	c.Assert(err, check.IsNil)
+	chg := st.NewChange("assemble-cluster", "...")
+	chg.AddAll(ts)
+
+	runChange(c, st, runner, chg)
+	c.Assert(chg.Err(), check.IsNil)
+	c.Check(chg.Status(), check.Equals, state.DoneStatus)
+
+	status, err := clusterstate.ClusterStatus(st)
+	c.Assert(err, check.IsNil)
+	c.Assert(status.Assembly, check.NotNil)
+	c.Check(status.Assembly.Done, check.Equals, true)
+	c.Assert(status.Assembly.Members, check.HasLen, 1)
+	c.Check(status.Assembly.Members[0], check.DeepEquals, clusterstate.Member{
+		RDT:         status.Assembly.RDT,
+		Serial:      "serial-1",
+		Address:     "127.0.0.1:0",
+		Fingerprint: status.Assembly.Fingerprint,
+	})
+
+	// another assembly is refused
+	_, err = clusterstate.Assemble(st, clusterstate.AssembleOptions{Secret: "secret", Address: "127.0.0.1:0"})
+	c.Check(err, check.ErrorMatches, "cannot assemble cluster: device is already part of an assembled cluster")
+}
+
+func (s *assembleSuite) TestDoAssembleClusterNoSerial(c *check.C) {
+	st, _ := newStateWithStoreStack(c)
+
+	st.Lock()
+	defer st.Unlock()
+
+	runner := state.NewTaskRunner(st)
+	clusterstate.Manager(st, runner, nil)
+
+	ts, err := clusterstate.Assemble(st, clusterstate.AssembleOptions{Secret: "secret", Address: "127.0.0.1:0"})
+	c.Assert(err, check.IsNil)
+	chg := st.NewChange("assemble-cluster", "...")
+	chg.AddAll(ts)
+
+	runChange(c, st, runner, chg)
+	c.Check(chg.Err(), check.ErrorMatches, `(?s).*cannot assemble cluster without a serial assertion: .*`)
+
+	// the failed assembly is forgotten
+	status, err := clusterstate.ClusterStatus(st)
+	c.Assert(err, check.IsNil)
+	c.Check(status.Assembly, check.IsNil)
+}
+
+func (s *assembleSuite) TestDoAssembleClusterCannotListen(c *check.C) {
+	st, stack := newStateWithStoreStack(c)
+	serial, key := makeSerialAssertionAndKey(c, stack, "serial-1")
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, check.IsNil)
+	defer ln.Close()
+
+	st.Lock()
+	defer st.Unlock()
+
+	addSerialToState(c, st, serial)
+
+	runner := state.NewTaskRunner(st)
+	clusterstate.Manager(st, runner, keySigner{key: key})
+
+	ts, err := clusterstate.Assemble(st, clusterstate.AssembleOptions{Secret: "secret", Address: ln.Addr().String()})
+	c.Assert(err, check.IsNil)
+	chg := st.NewChange("assemble-cluster", "...")
+	chg.AddAll(ts)
+
+	runChange(c, st, runner, chg)
+	c.Check(chg.Err(), check.ErrorMatches, `(?s).*cannot assemble cluster: cannot listen on .*`)
+}
+
+func (s *assembleSuite) TestLeave(c *check.C) {
+	st := state.New(nil)
+	st.Lock()
+	defer st.Unlock()
+
+	ts, err := clusterstate.Assemble(st, clusterstate.AssembleOptions{Secret: "secret", Address: "10.0.0.1:7070"})
+	c.Assert(err, check.IsNil)
+	chg := st.NewChange("assemble-cluster", "...")
+	chg.AddAll(ts)
+	st.Set("cluster", map[string]any{
+		"current": map[string]any{"cluster-id": "cluster-id", "sequence": 1},
+	})
+
+	err = clusterstate.Leave(st)
+	c.Assert(err, check.IsNil)
+
+	c.Check(chg.Status(), check.Equals, state.HoldStatus)
+
+	status, err := clusterstate.ClusterStatus(st)
+	c.Assert(err, check.IsNil)
+	c.Check(status, check.DeepEquals, &clusterstate.Status{})
+
+	err = clusterstate.Leave(st)
+	c.Check(err, check.Equals, clusterstate.ErrNotInCluster)
+}
+
+func (s *assembleSuite) TestClusterStatusNotInCluster(c *check.C) {
+	st := state.New(nil)
+	st.Lock()
+	defer st.Unlock()
+
+	status, err := clusterstate.ClusterStatus(st)
+	c.Assert(err, check.IsNil)
+	c.Check(status, check.DeepEquals, &clusterstate.Status{})
+
+	st.Set("cluster", map[string]any{
+		"current": map[string]any{"cluster-id": "cluster-id", "sequence": 1},
+	})
+	status, err = clusterstate.ClusterStatus(st)
+	c.Assert(err, check.IsNil)
+	c.Check(status, check.DeepEquals, &clusterstate.Status{ClusterID: "cluster-id"})
+}
+
+func (s *assembleSuite) TestClusterStatusMembers(c *check.C) {
+	st := state.New(nil)
+	st.Lock()
+	defer st.Unlock()
+
+	_, err := clusterstate.Assemble(st, clusterstate.AssembleOptions{Secret: "secret", Address: "10.0.0.1:7070"})
+	c.Assert(err, check.IsNil)
+
+	var as map[string]any
+	c.Assert(st.Get("cluster-assemble", &as), check.IsNil)
+	rdt := as["rdt"].(string)
+
+	// build the session the way it is committed to the state, fingerprints
+	// are stored as base64 strings in map keys and as byte arrays otherwise
+	selfFP := assemblestate.CalculateFP([]byte("self"))
+	peerFP := assemblestate.CalculateFP([]byte("peer"))
+	fp1 := base64.StdEncoding.EncodeToString(selfFP[:])
+	fp2 := base64.StdEncoding.EncodeToString(peerFP[:])
+	session := assemblestate.AssembleSession{
+		Trusted:   map[string]assemblestate.DeviceToken{fp2: "peer"},
+		Addresses: map[string]string{fp2: "10.0.0.2:7070"},
+		Routes: assemblestate.Routes{
+			Devices:   []assemblestate.DeviceToken{assemblestate.DeviceToken(rdt), "peer"},
+			Addresses: []string{"10.0.0.2:7070"},
+			Routes:    []int{0, 1, 0},
+		},
+		Devices: assemblestate.DeviceQueryTrackerData{
+			IDs: []assemblestate.Identity{
+				{RDT: "peer", FP: peerFP},
+				{RDT: assemblestate.DeviceToken(rdt), FP: selfFP},
+			},
+		},
+	}
+	b, err := json.Marshal(session)
+	c.Assert(err, check.IsNil)
+	var sessionData map[string]any
+	c.Assert(json.Unmarshal(b, &sessionData), check.IsNil)
+	sessionData["initiated"] = as["session"].(map[string]any)["initiated"]
+	as["session"] = sessionData
+	st.Set("cluster-assemble", as)
+
+	status, err := clusterstate.ClusterStatus(st)
+	c.Assert(err, check.IsNil)
+	c.Assert(status.Assembly, check.NotNil)
+	c.Check(status.Assembly.Trusted, check.Equals, 1)
+	c.Check(status.Assembly.Routes, check.Equals, 1)
+
+	self := clusterstate.Member{RDT: rdt, Address: "10.0.0.1:7070", Fingerprint: fp1}
+	peer := clusterstate.Member{RDT: "peer", Address: "10.0.0.2:7070", Fingerprint: fp2}
+	expected := []clusterstate.Member{self, peer}
+	if peer.RDT < rdt {
+		expected = []clusterstate.Member{peer, self}
+	}
+	c.Check(status.Assembly.Members, check.DeepEquals, expected)
+}
@@ -24,38 +24,67 @@ import (
 	"fmt"
 
 	"github.com/snapcore/snapd/features"
+	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/overlord/swfeats"
 )
 
 var applyClusterSubclusterChangeKind = swfeats.RegisterChangeKind("apply-cluster-subcluster")
 
+// deviceKeySigner can sign data with the device's key.
+type deviceKeySigner interface {
+	SignWithDeviceKey(data []byte) ([]byte, error)
+}
+
 type ClusterManager struct {
-	state *state.State
+	state  *state.State
+	signer deviceKeySigner
+
+	// coordination is the coordination of refreshes with the other members
+	// of the cluster, if the device is part of an assembled cluster. It is
+	// protected by the state lock.
+	coordination *refreshCoordination
 }
 
 // Manager returns a new ClusterManager.
-func Manager(st *state.State) *ClusterManager {
-	return &ClusterManager{
-		state: st,
+func Manager(st *state.State, runner *state.TaskRunner, signer deviceKeySigner) *ClusterManager {
+	m := &ClusterManager{
+		state:  st,
+		signer: signer,
 	}
+
+	runner.AddHandler("assemble-cluster", m.doAssembleCluster, m.undoAssembleCluster)
+
+	snapstate.CoordinateRefreshes = coordinateRefreshes
+
+	return m
 }
 
 // Ensure ensures that the device state matches the expectations defined by the
 // cluster assertion.
 func (m *ClusterManager) Ensure() error {
+	m.state.Lock()
+	defer m.state.Unlock()
+
 	enabled, err := clusteringEnabled(m.state)
 	if err != nil {
 		return err
 	}
 
+	// the coordination of refreshes stops if clustering is disabled
+	if err := m.ensureRefreshCoordination(enabled); err != nil {
+		logger.Noticef("%v", err)
+	}
+
 	if !enabled {
 		return nil
 	}
 
-	m.state.Lock()
-	defer m.state.Unlock()
+	if err := m.ensureGrantedRefreshes(); err != nil {
+		return err
+	}
 
 	cluster, err := CurrentCluster(m.state)
 	if err != nil {
@@ -118,8 +147,6 @@ func inProgressClusterChanges(st *state.State) map[clusterChangeRef]bool {
 }
 
 func clusteringEnabled(st *state.State) (bool, error) {
-	st.Lock()
-	defer st.Unlock()
 	tr := config.NewTransaction(st)
 	return features.Flag(tr, features.Clustering)
 }
@@ -378,7 +378,7 @@ func (s *managerSuite) TestEnsureIdempotent(c *check.C) {
 
 	err := clusterstate.InitializeNewCluster(st, bytes.NewReader(bundle))
 	c.Assert(err, check.IsNil)
-	mgr := clusterstate.Manager(st)
+	mgr := clusterstate.Manager(st, state.NewTaskRunner(st), nil)
 
 	st.Unlock()
 	defer st.Lock()
@@ -406,14 +406,14 @@ func (s *managerSuite) TestEnsureClusteringDisabled(c *check.C) {
 	st.Unlock()
 	defer st.Lock()
 
-	mgr := clusterstate.Manager(st)
+	mgr := clusterstate.Manager(st, state.NewTaskRunner(st), nil)
 
 	err = mgr.Ensure()
 	c.Assert(err, check.IsNil)
 }
 
 func (s *managerSuite) TestEnsureLoopHasLogging(c *check.C) {
-	testutil.CheckEnsureLoopLogging("clustermgr.go", c, false)
+	testutil.CheckEnsureLoopLogging("clustermgr.go", c, true)
 }
 
 func (s *managerSuite) TestApplyClusterStateNoActions(c *check.C) {
@@ -457,7 +457,7 @@ func (s *managerSuite) TestApplyClusterStateNoActions(c *check.C) {
 
 	err := clusterstate.InitializeNewCluster(st, bytes.NewReader(bundle))
 	c.Assert(err, check.IsNil)
-	mgr := clusterstate.Manager(st)
+	mgr := clusterstate.Manager(st, state.NewTaskRunner(st), nil)
 
 	st.Unlock()
 	defer st.Lock()
@@ -524,7 +524,7 @@ func (s *managerSuite) TestApplyClusterStateDeviceNotInAnySubcluster(c *check.C)
 	err := clusterstate.InitializeNewCluster(st, bytes.NewReader(bundle))
 	c.Assert(err, check.IsNil)
 
-	mgr := clusterstate.Manager(st)
+	mgr := clusterstate.Manager(st, state.NewTaskRunner(st), nil)
 
 	st.Unlock()
 	defer st.Lock()
@@ -651,7 +651,7 @@ func (s *managerSuite) TestApplyClusterStateInstallRemoveAndUpdate(c *check.C) {
 
 	err := clusterstate.InitializeNewCluster(st, bytes.NewReader(bundle))
 	c.Assert(err, check.IsNil)
-	mgr := clusterstate.Manager(st)
+	mgr := clusterstate.Manager(st, state.NewTaskRunner(st), nil)
 
 	st.Unlock()
 	defer st.Lock()
@@ -761,7 +761,7 @@ func (s *managerSuite) TestApplyClusterStateMultipleSubclusters(c *check.C) {
 
 	err := clusterstate.InitializeNewCluster(st, bytes.NewReader(bundle))
 	c.Assert(err, check.IsNil)
-	mgr := clusterstate.Manager(st)
+	mgr := clusterstate.Manager(st, state.NewTaskRunner(st), nil)
 
 	st.Unlock()
 	defer st.Lock()
@@ -816,7 +816,7 @@ func (s *managerSuite) TestApplyClusterStateDeviceMissing(c *check.C) {
 
 	err := clusterstate.InitializeNewCluster(st, bytes.NewReader(bundle))
 	c.Assert(err, check.IsNil)
-	mgr := clusterstate.Manager(st)
+	mgr := clusterstate.Manager(st, state.NewTaskRunner(st), nil)
 
 	st.Unlock()
 	defer st.Lock()
@@ -831,7 +831,7 @@ func (s *managerSuite) TestApplyClusterStateDeviceMissing(c *check.C) {
 func (s *managerSuite) TestApplyClusterStateNoClusterData(c *check.C) {
 	st, _ := newStateWithStoreStack(c)
 
-	mgr := clusterstate.Manager(st)
+	mgr := clusterstate.Manager(st, state.NewTaskRunner(st), nil)
 
 	c.Assert(mgr.Ensure(), check.IsNil)
 
@@ -927,7 +927,7 @@ func (s *managerSuite) TestApplyClusterStateSkipsExistingChange(c *check.C) {
 
 	err := clusterstate.InitializeNewCluster(st, bytes.NewReader(bundle))
 	c.Assert(err, check.IsNil)
-	mgr := clusterstate.Manager(st)
+	mgr := clusterstate.Manager(st, state.NewTaskRunner(st), nil)
 
 	st.Unlock()
 	defer st.Lock()
@@ -1004,6 +1004,11 @@ func makeClusterBundleWithSigning(
 }
 
 func makeSerialAssertion(c *check.C, stack *assertstest.StoreStack, serial string) *asserts.Serial {
+	a, _ := makeSerialAssertionAndKey(c, stack, serial)
+	return a
+}
+
+func makeSerialAssertionAndKey(c *check.C, stack *assertstest.StoreStack, serial string) (*asserts.Serial, asserts.PrivateKey) {
 	deviceKey, _ := assertstest.GenerateKey(752)
 	encodedKey, err := asserts.EncodePublicKey(deviceKey.PublicKey())
 	c.Assert(err, check.IsNil)
@@ -1021,7 +1026,7 @@ func makeSerialAssertion(c *check.C, stack *assertstest.StoreStack, serial strin
 	a, err := stack.Sign(asserts.SerialType, headers, nil, "")
 	c.Assert(err, check.IsNil)
 
-	return a.(*asserts.Serial)
+	return a.(*asserts.Serial), deviceKey
 }
 
 func addSerialToState(c *check.C, st *state.State, serial *asserts.Serial) {
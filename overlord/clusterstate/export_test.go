@@ -21,7 +21,13 @@ package clusterstate
 
 import (
 	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"net"
+	"time"
 
+	"github.com/snapcore/snapd/cluster/assemblestate"
+	"github.com/snapcore/snapd/cluster/refreshcoord"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/snap"
@@ -57,3 +63,79 @@ func MockStoreInstallGoal(f func(...snapstate.StoreSnap) snapstate.InstallGoal)
 	storeInstallGoal = f
 	return restore
 }
+
+type RefreshClient = refreshClient
+
+func MockRefreshcoordServe(f func(context.Context, net.Listener, tls.Certificate, []refreshcoord.Peer, *refreshcoord.Coordinator) error) func() {
+	return testutil.Mock(&refreshcoordServe, f)
+}
+
+func MockNewRefreshClient(f func(tls.Certificate) RefreshClient) func() {
+	return testutil.Mock(&newRefreshClient, f)
+}
+
+func MockRefreshCoordinationPeriod(d time.Duration) func() {
+	return testutil.Mock(&refreshCoordinationPeriod, d)
+}
+
+func MockTimeNow(f func() time.Time) func() {
+	return testutil.Mock(&timeNow, f)
+}
+
+func MockNetListen(f func(network, address string) (net.Listener, error)) func() {
+	return testutil.Mock(&netListen, f)
+}
+
+// CoordinateRefreshesOnce runs a single step of the coordination of
+// refreshes, if it is running.
+func (m *ClusterManager) CoordinateRefreshesOnce() {
+	m.state.Lock()
+	rc := m.coordination
+	m.state.Unlock()
+
+	if rc != nil {
+		m.coordinateRefreshesOnce(context.Background(), rc)
+	}
+}
+
+// MockAssembled records a completed assembly of a cluster with the given
+// members, by device token and address, this device being self.
+func MockAssembled(st *state.State, self assemblestate.DeviceToken, members map[assemblestate.DeviceToken]string) error {
+	certPEM, keyPEM, err := generateCertificate(self, timeNow())
+	if err != nil {
+		return err
+	}
+
+	as := &assembleState{
+		Secret:  "secret",
+		RDT:     self,
+		TLSCert: certPEM,
+		TLSKey:  keyPEM,
+		Address: members[self],
+		Session: assemblestate.AssembleSession{
+			Addresses: make(map[string]string),
+		},
+		Done: true,
+	}
+	for rdt, address := range members {
+		fp := MemberFP(rdt)
+		if rdt == self {
+			cert, err := tls.X509KeyPair(certPEM, keyPEM)
+			if err != nil {
+				return err
+			}
+			fp = assemblestate.CalculateFP(cert.Certificate[0])
+		} else {
+			as.Session.Addresses[base64.StdEncoding.EncodeToString(fp[:])] = address
+		}
+		as.Session.Devices.IDs = append(as.Session.Devices.IDs, assemblestate.Identity{RDT: rdt, FP: fp})
+	}
+	st.Set(assembleStateKey, as)
+	return nil
+}
+
+// MemberFP returns the fingerprint of the certificate of a member recorded
+// with MockAssembled, other than this device.
+func MemberFP(rdt assemblestate.DeviceToken) assemblestate.Fingerprint {
+	return assemblestate.CalculateFP([]byte(rdt))
+}
@@ -0,0 +1,708 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package clusterstate
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/cluster/assemblestate"
+	"github.com/snapcore/snapd/cluster/refreshcoord"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/healthstate"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/overlord/swfeats"
+	"github.com/snapcore/snapd/snap"
+)
+
+// refreshStateKey is the key under which the state of the coordination of the
+// refreshes of clustered snaps is kept.
+const refreshStateKey = "cluster-refresh"
+
+var refreshClusterSnapChangeKind = swfeats.RegisterChangeKind("refresh-cluster-snap")
+
+var (
+	refreshcoordServe = refreshcoord.Serve
+	newRefreshClient  = func(cert tls.Certificate) refreshClient {
+		return refreshcoord.NewClient(cert)
+	}
+
+	// refreshCoordinationPeriod is how often heartbeats are sent to the
+	// other members, and the leader is asked for refreshes and reported to
+	refreshCoordinationPeriod = 30 * time.Second
+	// refreshHeartbeatTimeout is how long a member can go without sending a
+	// heartbeat before it is considered gone
+	refreshHeartbeatTimeout = 2 * time.Minute
+	// refreshHealthTimeout is how long a member granted a refresh has to
+	// report on the health of the refreshed snap
+	refreshHealthTimeout = time.Hour
+)
+
+// ErrNotAssembled indicates that the device is not part of an assembled
+// cluster.
+var ErrNotAssembled = errors.New("device is not part of an assembled cluster")
+
+// refreshClient sends refresh coordination messages to the other members.
+type refreshClient interface {
+	Heartbeat(ctx context.Context, peer refreshcoord.Peer, hb refreshcoord.Heartbeat) error
+	RequestRefresh(ctx context.Context, peer refreshcoord.Peer, req refreshcoord.RefreshRequest) (refreshcoord.Decision, error)
+	ReportRefresh(ctx context.Context, peer refreshcoord.Peer, report refreshcoord.RefreshReport) error
+}
+
+// refreshState contains the state of the coordination of the refreshes of
+// clustered snaps with the other members of the cluster.
+type refreshState struct {
+	// Coordinator is the state of the refresh coordinator, as last committed.
+	Coordinator refreshcoord.CoordinatorState `json:"coordinator"`
+	// Leader is the member last seen as the leader.
+	Leader assemblestate.DeviceToken `json:"leader,omitempty"`
+	// Refreshes are the refreshes of clustered snaps that this device waits
+	// to perform or performs, by snap instance name.
+	Refreshes map[string]*clusterRefresh `json:"refreshes,omitempty"`
+}
+
+// clusterRefresh tracks the refresh of a clustered snap on this device.
+type clusterRefresh struct {
+	Revision snap.Revision `json:"revision"`
+	// Waiting is why the leader did not grant the refresh yet.
+	Waiting string `json:"waiting,omitempty"`
+	// Granted is when the leader granted the refresh.
+	Granted time.Time `json:"granted,omitempty"`
+	// ChangeID is the ID of the change refreshing the snap.
+	ChangeID string `json:"change-id,omitempty"`
+	// Failed is why the refresh could not be started.
+	Failed string `json:"failed,omitempty"`
+}
+
+func currentRefreshState(st *state.State) (*refreshState, error) {
+	var rs refreshState
+	if err := st.Get(refreshStateKey, &rs); err != nil && !errors.Is(err, state.ErrNoState) {
+		return nil, err
+	}
+	if rs.Refreshes == nil {
+		rs.Refreshes = make(map[string]*clusterRefresh)
+	}
+	return &rs, nil
+}
+
+// coordinateRefreshes is hooked into snapstate as
+// snapstate.CoordinateRefreshes. The refreshes of clustered snaps are left out
+// of refreshes of all snaps once the device is part of an assembled cluster.
+// Instead the leader is asked for them, and they happen one member at a time.
+func coordinateRefreshes(st *state.State, refreshes []*snap.Info) (map[string]bool, error) {
+	enabled, err := clusteringEnabled(st)
+	if err != nil || !enabled {
+		return nil, err
+	}
+
+	as, err := currentAssembly(st)
+	if err != nil {
+		if errors.Is(err, state.ErrNoState) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !as.Done {
+		return nil, nil
+	}
+
+	clustered, err := clusteredSnaps(st)
+	if err != nil {
+		return nil, err
+	}
+	if len(clustered) == 0 {
+		return nil, nil
+	}
+
+	rs, err := currentRefreshState(st)
+	if err != nil {
+		return nil, err
+	}
+
+	coordinated := make(map[string]bool)
+	for _, info := range refreshes {
+		name := info.InstanceName()
+		if !clustered[name] {
+			continue
+		}
+		coordinated[name] = true
+
+		if r := rs.Refreshes[name]; r != nil && (!r.Granted.IsZero() || r.Revision == info.Revision) {
+			// granted refreshes are seen through before asking for
+			// another one
+			continue
+		}
+		rs.Refreshes[name] = &clusterRefresh{Revision: info.Revision}
+	}
+	st.Set(refreshStateKey, rs)
+
+	return coordinated, nil
+}
+
+// clusteredSnaps returns the snaps which are clustered on this device
+// according to the cluster assertion.
+func clusteredSnaps(st *state.State) (map[string]bool, error) {
+	cluster, err := CurrentCluster(st)
+	if err != nil {
+		if errors.Is(err, ErrNoClusterAssertion) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	serial, err := devicestateSerial(st)
+	if err != nil {
+		if errors.Is(err, state.ErrNoState) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	deviceID, ok := clusterDeviceIDBySerial(cluster, serial.Serial())
+	if !ok {
+		return nil, nil
+	}
+
+	clustered := make(map[string]bool)
+	for _, subcluster := range cluster.Subclusters() {
+		if !deviceInSubcluster(subcluster, deviceID) {
+			continue
+		}
+		for _, sn := range subcluster.Snaps {
+			if sn.State == asserts.ClusterSnapStateClustered {
+				clustered[sn.Instance] = true
+			}
+		}
+	}
+	return clustered, nil
+}
+
+// refreshCoordination is the coordination of refreshes with the other
+// members of the cluster this device was assembled with.
+type refreshCoordination struct {
+	rdt     assemblestate.DeviceToken
+	address string
+	cert    tls.Certificate
+	coord   *refreshcoord.Coordinator
+	client  refreshClient
+	peers   map[assemblestate.DeviceToken]refreshcoord.Peer
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// newRefreshCoordination sets up the coordination of refreshes with the
+// other members of the cluster, as assembled.
+func newRefreshCoordination(st *state.State, as *assembleState) (*refreshCoordination, error) {
+	cert, err := tls.X509KeyPair(as.TLSCert, as.TLSKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot coordinate refreshes: invalid certificate: %v", err)
+	}
+
+	rc := &refreshCoordination{
+		rdt:     as.RDT,
+		address: as.Address,
+		cert:    cert,
+		client:  newRefreshClient(cert),
+		peers:   make(map[assemblestate.DeviceToken]refreshcoord.Peer),
+	}
+
+	members := make([]assemblestate.DeviceToken, 0, len(as.Session.Devices.IDs))
+	for _, id := range as.Session.Devices.IDs {
+		members = append(members, id.RDT)
+		if id.RDT == as.RDT {
+			continue
+		}
+		rc.peers[id.RDT] = refreshcoord.Peer{
+			RDT:     id.RDT,
+			Address: as.Session.Addresses[base64.StdEncoding.EncodeToString(id.FP[:])],
+			FP:      id.FP,
+		}
+	}
+
+	rs, err := currentRefreshState(st)
+	if err != nil {
+		return nil, err
+	}
+
+	rc.coord, err = refreshcoord.New(refreshcoord.Config{
+		RDT:              as.RDT,
+		Members:          members,
+		HeartbeatTimeout: refreshHeartbeatTimeout,
+		HealthTimeout:    refreshHealthTimeout,
+		Clock:            timeNow,
+	}, rs.Coordinator)
+	if err != nil {
+		return nil, fmt.Errorf("cannot coordinate refreshes: %v", err)
+	}
+
+	return rc, nil
+}
+
+func (rc *refreshCoordination) sortedPeers() []refreshcoord.Peer {
+	peers := make([]refreshcoord.Peer, 0, len(rc.peers))
+	for _, p := range rc.peers {
+		peers = append(peers, p)
+	}
+	sort.Slice(peers, func(i, j int) bool {
+		return peers[i].RDT < peers[j].RDT
+	})
+	return peers
+}
+
+func (rc *refreshCoordination) requestRefresh(ctx context.Context, leader assemblestate.DeviceToken, req refreshcoord.RefreshRequest) (refreshcoord.Decision, error) {
+	if leader == rc.rdt {
+		return rc.coord.RequestRefresh(req)
+	}
+	return rc.client.RequestRefresh(ctx, rc.peers[leader], req)
+}
+
+func (rc *refreshCoordination) reportRefresh(ctx context.Context, leader assemblestate.DeviceToken, report refreshcoord.RefreshReport) error {
+	if leader == rc.rdt {
+		return rc.coord.ReportRefresh(report)
+	}
+	return rc.client.ReportRefresh(ctx, rc.peers[leader], report)
+}
+
+// ensureRefreshCoordination starts the coordination of refreshes once the
+// device is part of an assembled cluster, and stops it once it is not
+// anymore or clustering is disabled. The state must be locked, but is
+// released while the coordination stops.
+func (m *ClusterManager) ensureRefreshCoordination(enabled bool) error {
+	logger.Trace("ensure", "manager", "ClusterManager", "func", "ensureRefreshCoordination")
+
+	as, err := currentAssembly(m.state)
+	if err != nil && !errors.Is(err, state.ErrNoState) {
+		return err
+	}
+	done := enabled && as != nil && as.Done
+
+	if rc := m.coordination; rc != nil {
+		if done && as.RDT == rc.rdt {
+			return nil
+		}
+		m.coordination = nil
+		// the coordination takes the state lock
+		m.state.Unlock()
+		rc.stop()
+		m.state.Lock()
+	}
+
+	if !done {
+		return nil
+	}
+
+	rc, err := newRefreshCoordination(m.state, as)
+	if err != nil {
+		return err
+	}
+
+	ln, err := netListen("tcp", rc.address)
+	if err != nil {
+		return fmt.Errorf("cannot coordinate refreshes: cannot listen on %s: %v", rc.address, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rc.cancel = cancel
+	rc.done = make(chan struct{})
+
+	go func() {
+		defer close(rc.done)
+
+		served := make(chan struct{})
+		go func() {
+			defer close(served)
+			_ = refreshcoordServe(ctx, ln, rc.cert, rc.sortedPeers(), rc.coord)
+		}()
+
+		m.runRefreshCoordination(ctx, rc)
+		<-served
+	}()
+
+	m.coordination = rc
+	return nil
+}
+
+// stop stops the coordination and waits for it to be over.
+func (rc *refreshCoordination) stop() {
+	rc.cancel()
+	<-rc.done
+}
+
+// Stop stops the coordination of refreshes with the other members of the
+// cluster, if it is running.
+func (m *ClusterManager) Stop() {
+	m.state.Lock()
+	rc := m.coordination
+	m.coordination = nil
+	m.state.Unlock()
+
+	if rc != nil {
+		rc.stop()
+	}
+}
+
+func (m *ClusterManager) runRefreshCoordination(ctx context.Context, rc *refreshCoordination) {
+	ticker := time.NewTicker(refreshCoordinationPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		m.coordinateRefreshesOnce(ctx, rc)
+	}
+}
+
+// coordinateRefreshesOnce sends a heartbeat to the other members, asks the
+// leader for the refreshes that this device waits to perform and reports on
+// the ones that are over. The state must not be locked.
+func (m *ClusterManager) coordinateRefreshesOnce(ctx context.Context, rc *refreshCoordination) {
+	st := m.state
+
+	st.Lock()
+	rs, err := currentRefreshState(st)
+	if err != nil {
+		st.Unlock()
+		logger.Noticef("cannot coordinate refreshes: %v", err)
+		return
+	}
+
+	var refreshing []refreshcoord.Refresh
+	var requests []refreshcoord.RefreshRequest
+	var reports []refreshcoord.RefreshReport
+	for _, name := range sortedRefreshes(rs) {
+		r := rs.Refreshes[name]
+		ref := refreshcoord.Refresh{Snap: name, Revision: r.Revision}
+		if r.Granted.IsZero() {
+			requests = append(requests, refreshcoord.RefreshRequest{RDT: rc.rdt, Refresh: ref})
+			continue
+		}
+
+		refreshing = append(refreshing, ref)
+		if over, healthy, msg := refreshOutcome(st, name, r); over {
+			reports = append(reports, refreshcoord.RefreshReport{
+				RDT:     rc.rdt,
+				Refresh: ref,
+				Healthy: healthy,
+				Message: msg,
+			})
+		}
+	}
+	st.Unlock()
+
+	hb := rc.coord.Heartbeat(refreshing)
+	for _, peer := range rc.sortedPeers() {
+		if err := rc.client.Heartbeat(ctx, peer, hb); err != nil {
+			logger.Debugf("cannot send heartbeat to cluster member %q: %v", peer.RDT, err)
+		}
+	}
+
+	leader := rc.coord.Leader()
+
+	decisions := make(map[refreshcoord.Refresh]refreshcoord.Decision, len(requests))
+	for _, req := range requests {
+		d, err := rc.requestRefresh(ctx, leader, req)
+		if err != nil {
+			logger.Debugf("cannot ask cluster leader %q for the refresh of %q: %v", leader, req.Snap, err)
+			continue
+		}
+		decisions[req.Refresh] = d
+	}
+
+	var reported []refreshcoord.Refresh
+	for _, report := range reports {
+		if err := rc.reportRefresh(ctx, leader, report); err != nil {
+			logger.Debugf("cannot report the refresh of %q to cluster leader %q: %v", report.Snap, leader, err)
+			continue
+		}
+		reported = append(reported, report.Refresh)
+	}
+
+	st.Lock()
+	defer st.Unlock()
+
+	// the refreshes might have changed in the meantime
+	rs, err = currentRefreshState(st)
+	if err != nil {
+		logger.Noticef("cannot coordinate refreshes: %v", err)
+		return
+	}
+
+	granted := false
+	for ref, d := range decisions {
+		r := rs.Refreshes[ref.Snap]
+		if r == nil || r.Revision != ref.Revision || !r.Granted.IsZero() {
+			continue
+		}
+		if !d.Granted {
+			r.Waiting = d.Reason
+			continue
+		}
+		r.Waiting = ""
+		r.Granted = timeNow()
+		granted = true
+	}
+	for _, ref := range reported {
+		if r := rs.Refreshes[ref.Snap]; r != nil && r.Revision == ref.Revision {
+			delete(rs.Refreshes, ref.Snap)
+		}
+	}
+	rs.Coordinator = rc.coord.State()
+	rs.Leader = leader
+	st.Set(refreshStateKey, rs)
+
+	if granted {
+		st.EnsureBefore(0)
+	}
+}
+
+func sortedRefreshes(rs *refreshState) []string {
+	names := make([]string, 0, len(rs.Refreshes))
+	for name := range rs.Refreshes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// refreshOutcome checks on a granted refresh of a clustered snap. It returns
+// whether the refresh is over and, if so, whether the refreshed snap is
+// healthy. Snaps with a check-health hook must report being healthy, with
+// snapctl set-health, for their refresh to be considered over.
+func refreshOutcome(st *state.State, name string, r *clusterRefresh) (over, healthy bool, msg string) {
+	if r.Failed != "" {
+		return true, false, r.Failed
+	}
+
+	if r.ChangeID != "" {
+		if chg := st.Change(r.ChangeID); chg != nil {
+			if !chg.Status().Ready() {
+				return false, false, ""
+			}
+			if chg.Status() != state.DoneStatus {
+				return true, false, fmt.Sprintf("cannot refresh snap: %v", chg.Err())
+			}
+		}
+	}
+
+	var snapst snapstate.SnapState
+	if err := snapstate.Get(st, name, &snapst); err != nil {
+		if errors.Is(err, state.ErrNoState) {
+			return true, false, "snap is not installed"
+		}
+		return false, false, ""
+	}
+	if snapst.Current != r.Revision {
+		if r.ChangeID == "" {
+			// the refresh did not start yet
+			return false, false, ""
+		}
+		return true, false, fmt.Sprintf("snap is at revision %s instead of %s", snapst.Current, r.Revision)
+	}
+
+	info, err := snapst.CurrentInfo()
+	if err != nil {
+		return false, false, ""
+	}
+	if info.Hooks["check-health"] == nil {
+		return true, true, ""
+	}
+
+	health, err := healthstate.Get(st, name)
+	if err != nil || health == nil || health.Revision != r.Revision {
+		return false, false, ""
+	}
+	switch health.Status {
+	case healthstate.OkayStatus:
+		return true, true, ""
+	case healthstate.BlockedStatus, healthstate.ErrorStatus:
+		msg := health.Message
+		if msg == "" {
+			msg = fmt.Sprintf("snap health is %q", health.Status)
+		}
+		return true, false, msg
+	}
+	return false, false, ""
+}
+
+// ensureGrantedRefreshes starts the refreshes of clustered snaps that the
+// leader granted. The state must be locked.
+func (m *ClusterManager) ensureGrantedRefreshes() error {
+	logger.Trace("ensure", "manager", "ClusterManager", "func", "ensureGrantedRefreshes")
+
+	st := m.state
+	rs, err := currentRefreshState(st)
+	if err != nil {
+		return err
+	}
+
+	changed := false
+	for _, name := range sortedRefreshes(rs) {
+		r := rs.Refreshes[name]
+		if r.Granted.IsZero() || r.ChangeID != "" || r.Failed != "" {
+			continue
+		}
+
+		var snapst snapstate.SnapState
+		if err := snapstate.Get(st, name, &snapst); err != nil && !errors.Is(err, state.ErrNoState) {
+			return err
+		}
+		if !snapst.IsInstalled() {
+			r.Failed = "snap is not installed"
+			changed = true
+			continue
+		}
+		if snapst.Current == r.Revision {
+			// refreshed in the meantime
+			continue
+		}
+
+		goal := storeUpdateGoal(snapstate.StoreUpdate{
+			InstanceName: name,
+			RevOpts: snapstate.RevisionOptions{
+				Revision: r.Revision,
+			},
+		})
+		_, uts, err := updateWithGoal(context.Background(), st, goal, nil, snapstate.Options{})
+		if err != nil {
+			var conflict *snapstate.ChangeConflictError
+			if errors.As(err, &conflict) {
+				logger.Debugf("cannot refresh clustered snap %q yet: %v", name, err)
+				continue
+			}
+			r.Failed = fmt.Sprintf("cannot refresh snap: %v", err)
+			changed = true
+			continue
+		}
+
+		chg := st.NewChange(refreshClusterSnapChangeKind, fmt.Sprintf("Refresh clustered snap %q to revision %s", name, r.Revision))
+		for _, ts := range uts.Refresh {
+			chg.AddAll(ts)
+		}
+		r.ChangeID = chg.ID()
+		changed = true
+	}
+
+	if changed {
+		st.Set(refreshStateKey, rs)
+	}
+	return nil
+}
+
+// HoldRefreshes holds the refreshes of the given clustered snaps, or of all
+// of them if none are given, on all the members of the cluster. Refreshes
+// that were already granted are allowed to finish. Callers must hold the
+// state lock.
+func (m *ClusterManager) HoldRefreshes(snaps []string) error {
+	return m.updateHolds(func(coord *refreshcoord.Coordinator) {
+		coord.Hold(snaps...)
+	})
+}
+
+// ResumeRefreshes removes the cluster-wide holds on the refreshes of the
+// given clustered snaps, or all of them if none are given. Rollouts of the
+// given snaps which were halted, e.g. because a member reported a refreshed
+// snap as unhealthy, carry on. Callers must hold the state lock.
+func (m *ClusterManager) ResumeRefreshes(snaps []string) error {
+	return m.updateHolds(func(coord *refreshcoord.Coordinator) {
+		coord.Resume(snaps...)
+	})
+}
+
+func (m *ClusterManager) updateHolds(update func(coord *refreshcoord.Coordinator)) error {
+	rc := m.coordination
+	if rc == nil {
+		return ErrNotAssembled
+	}
+
+	update(rc.coord)
+
+	rs, err := currentRefreshState(m.state)
+	if err != nil {
+		return err
+	}
+	rs.Coordinator = rc.coord.State()
+	m.state.Set(refreshStateKey, rs)
+
+	return nil
+}
+
+// RefreshStatus describes the coordination of the refreshes of clustered
+// snaps with the other members of the cluster.
+type RefreshStatus struct {
+	// Leader is the random device token of the member last seen as the
+	// leader.
+	Leader string
+	// HeldAll is set if the refreshes of all clustered snaps are held.
+	HeldAll bool
+	// Held are the clustered snaps whose refreshes are held.
+	Held []string
+	// Refreshes are the refreshes of clustered snaps that this device waits
+	// to perform or performs.
+	Refreshes []ClusterRefresh
+}
+
+// ClusterRefresh describes the refresh of a clustered snap on this device.
+type ClusterRefresh struct {
+	Snap     string
+	Revision snap.Revision
+	// Status is either "waiting" or "refreshing".
+	Status string
+	// Reason is why the refresh waits, if it does.
+	Reason string
+}
+
+func refreshStatus(st *state.State) (*RefreshStatus, error) {
+	rs, err := currentRefreshState(st)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &RefreshStatus{
+		Leader:  string(rs.Leader),
+		HeldAll: rs.Coordinator.Holds.All,
+		Held:    rs.Coordinator.Holds.Snaps,
+	}
+	for _, name := range sortedRefreshes(rs) {
+		r := rs.Refreshes[name]
+		refresh := ClusterRefresh{
+			Snap:     name,
+			Revision: r.Revision,
+			Status:   "waiting",
+			Reason:   r.Waiting,
+		}
+		if !r.Granted.IsZero() {
+			refresh.Status = "refreshing"
+			refresh.Reason = ""
+		}
+		status.Refreshes = append(status.Refreshes, refresh)
+	}
+	return status, nil
+}
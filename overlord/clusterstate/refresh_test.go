@@ -0,0 +1,645 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package clusterstate_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/cluster/assemblestate"
+	"github.com/snapcore/snapd/cluster/refreshcoord"
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/overlord/clusterstate"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/healthstate"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/snapstate/sequence"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type fakeRefreshClient struct {
+	heartbeats map[assemblestate.DeviceToken][]refreshcoord.Heartbeat
+	requests   []refreshcoord.RefreshRequest
+	reports    []refreshcoord.RefreshReport
+
+	decision  refreshcoord.Decision
+	reportErr error
+}
+
+func (f *fakeRefreshClient) Heartbeat(ctx context.Context, peer refreshcoord.Peer, hb refreshcoord.Heartbeat) error {
+	f.heartbeats[peer.RDT] = append(f.heartbeats[peer.RDT], hb)
+	return nil
+}
+
+func (f *fakeRefreshClient) RequestRefresh(ctx context.Context, peer refreshcoord.Peer, req refreshcoord.RefreshRequest) (refreshcoord.Decision, error) {
+	if peer.RDT != "a" {
+		return refreshcoord.Decision{}, refreshcoord.ErrNotLeader
+	}
+	f.requests = append(f.requests, req)
+	return f.decision, nil
+}
+
+func (f *fakeRefreshClient) ReportRefresh(ctx context.Context, peer refreshcoord.Peer, report refreshcoord.RefreshReport) error {
+	if peer.RDT != "a" {
+		return refreshcoord.ErrNotLeader
+	}
+	if f.reportErr != nil {
+		return f.reportErr
+	}
+	f.reports = append(f.reports, report)
+	return nil
+}
+
+type refreshSuite struct {
+	testutil.BaseTest
+
+	st     *state.State
+	mgr    *clusterstate.ClusterManager
+	client *fakeRefreshClient
+
+	// served contains the peers given to each coordination started
+	served [][]refreshcoord.Peer
+	// stopped is the number of coordinations stopped
+	stopped int
+
+	goals []snapstate.StoreUpdate
+}
+
+var _ = check.Suite(&refreshSuite{})
+
+const (
+	healthHookYaml = `name: microk8s
+version: 1
+hooks:
+  check-health:
+`
+	noHealthHookYaml = `name: microk8s
+version: 1
+`
+)
+
+func (s *refreshSuite) SetUpTest(c *check.C) {
+	s.BaseTest.SetUpTest(c)
+
+	dirs.SetRootDir(c.MkDir())
+	s.AddCleanup(func() { dirs.SetRootDir("") })
+	s.AddCleanup(func() { snapstate.CoordinateRefreshes = nil })
+
+	s.served = nil
+	s.stopped = 0
+	s.goals = nil
+
+	now := time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)
+	s.AddCleanup(clusterstate.MockTimeNow(func() time.Time { return now }))
+
+	s.client = &fakeRefreshClient{
+		heartbeats: make(map[assemblestate.DeviceToken][]refreshcoord.Heartbeat),
+	}
+	s.AddCleanup(clusterstate.MockNewRefreshClient(func(cert tls.Certificate) clusterstate.RefreshClient {
+		return s.client
+	}))
+	s.AddCleanup(clusterstate.MockNetListen(func(network, address string) (net.Listener, error) {
+		return net.Listen("tcp", "127.0.0.1:0")
+	}))
+	s.AddCleanup(clusterstate.MockRefreshcoordServe(func(ctx context.Context, ln net.Listener, cert tls.Certificate, peers []refreshcoord.Peer, coord *refreshcoord.Coordinator) error {
+		ln.Close()
+		<-ctx.Done()
+		s.stopped++
+		return nil
+	}))
+
+	s.AddCleanup(clusterstate.MockStoreUpdateGoal(func(upds ...snapstate.StoreUpdate) snapstate.UpdateGoal {
+		s.goals = append(s.goals, upds...)
+		return snapstate.StoreUpdateGoal(upds...)
+	}))
+	s.AddCleanup(clusterstate.MockSnapstateUpdateWithGoal(func(ctx context.Context, st *state.State, goal snapstate.UpdateGoal, filter func(*snap.Info, *snapstate.SnapState) bool, opts snapstate.Options) ([]string, *snapstate.UpdateTaskSets, error) {
+		return []string{"microk8s"}, &snapstate.UpdateTaskSets{
+			Refresh: []*state.TaskSet{state.NewTaskSet(st.NewTask("refresh", "refresh snap"))},
+		}, nil
+	}))
+
+	st, stack := newStateWithStoreStack(c)
+	s.st = st
+
+	bundle, _ := makeClusterBundle(c, stack, []map[string]any{
+		{
+			"id":        "1",
+			"device":    "serial-1.ubuntu-core-24-amd64.canonical",
+			"addresses": []any{"10.0.0.2"},
+		},
+	}, []map[string]any{{
+		"name":    "default",
+		"devices": []any{"1"},
+		"snaps": []any{
+			map[string]any{
+				"state":    "clustered",
+				"instance": "microk8s",
+				"channel":  "latest/stable",
+			},
+		},
+	}})
+
+	st.Lock()
+	defer st.Unlock()
+
+	serial := makeSerialAssertion(c, stack, "serial-1")
+	addSerialToState(c, st, serial)
+
+	err := clusterstate.InitializeNewCluster(st, bytes.NewReader(bundle))
+	c.Assert(err, check.IsNil)
+
+	s.installMicrok8s(c, noHealthHookYaml, 1)
+
+	// this device is "b", "a" is the leader
+	err = clusterstate.MockAssembled(st, "b", map[assemblestate.DeviceToken]string{
+		"a": "10.0.0.1:7070",
+		"b": "10.0.0.2:7070",
+		"c": "10.0.0.3:7070",
+	})
+	c.Assert(err, check.IsNil)
+
+	s.mgr = clusterstate.Manager(st, state.NewTaskRunner(st), nil)
+	s.AddCleanup(s.mgr.Stop)
+}
+
+func (s *refreshSuite) installMicrok8s(c *check.C, yaml string, revs ...int) {
+	var seq []*sequence.RevisionSideState
+	for _, rev := range revs {
+		si := &snap.SideInfo{RealName: "microk8s", Revision: snap.R(rev)}
+		snaptest.MockSnap(c, yaml, si)
+		seq = append(seq, sequence.NewRevisionSideState(si, nil))
+	}
+	snapstate.Set(s.st, "microk8s", &snapstate.SnapState{
+		Active:          true,
+		Current:         snap.R(revs[len(revs)-1]),
+		TrackingChannel: "latest/stable",
+		Sequence:        sequence.SnapSequence{Revisions: seq},
+	})
+}
+
+func (s *refreshSuite) ensure(c *check.C) {
+	s.st.Unlock()
+	defer s.st.Lock()
+	c.Assert(s.mgr.Ensure(), check.IsNil)
+}
+
+func (s *refreshSuite) coordinateOnce() {
+	s.st.Unlock()
+	defer s.st.Lock()
+	s.mgr.CoordinateRefreshesOnce()
+}
+
+func (s *refreshSuite) coordinateRefreshes(c *check.C, revs map[string]int) map[string]bool {
+	var infos []*snap.Info
+	for name, rev := range revs {
+		infos = append(infos, &snap.Info{SideInfo: snap.SideInfo{RealName: name, Revision: snap.R(rev)}})
+	}
+	c.Assert(snapstate.CoordinateRefreshes, check.NotNil)
+	coordinated, err := snapstate.CoordinateRefreshes(s.st, infos)
+	c.Assert(err, check.IsNil)
+	return coordinated
+}
+
+func (s *refreshSuite) refreshStatus(c *check.C) *clusterstate.RefreshStatus {
+	status, err := clusterstate.ClusterStatus(s.st)
+	c.Assert(err, check.IsNil)
+	return status.Refreshes
+}
+
+func (s *refreshSuite) TestCoordinateRefreshes(c *check.C) {
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	coordinated := s.coordinateRefreshes(c, map[string]int{"microk8s": 2, "other": 3})
+	c.Check(coordinated, check.DeepEquals, map[string]bool{"microk8s": true})
+
+	c.Check(s.refreshStatus(c), check.DeepEquals, &clusterstate.RefreshStatus{
+		Refreshes: []clusterstate.ClusterRefresh{
+			{Snap: "microk8s", Revision: snap.R(2), Status: "waiting"},
+		},
+	})
+
+	// a newer revision replaces the one waiting to be granted
+	coordinated = s.coordinateRefreshes(c, map[string]int{"microk8s": 3})
+	c.Check(coordinated, check.DeepEquals, map[string]bool{"microk8s": true})
+	c.Check(s.refreshStatus(c).Refreshes, check.DeepEquals, []clusterstate.ClusterRefresh{
+		{Snap: "microk8s", Revision: snap.R(3), Status: "waiting"},
+	})
+}
+
+func (s *refreshSuite) TestCoordinateRefreshesKeepsGrantedRefresh(c *check.C) {
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	s.coordinateRefreshes(c, map[string]int{"microk8s": 2})
+	s.ensure(c)
+
+	s.client.decision = refreshcoord.Decision{Granted: true}
+	s.coordinateOnce()
+
+	// the granted refresh is seen through first
+	coordinated := s.coordinateRefreshes(c, map[string]int{"microk8s": 3})
+	c.Check(coordinated, check.DeepEquals, map[string]bool{"microk8s": true})
+	c.Check(s.refreshStatus(c).Refreshes, check.DeepEquals, []clusterstate.ClusterRefresh{
+		{Snap: "microk8s", Revision: snap.R(2), Status: "refreshing"},
+	})
+}
+
+func (s *refreshSuite) TestCoordinateRefreshesNotCoordinated(c *check.C) {
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	// the assembly is not complete
+	st := s.st
+	var as map[string]any
+	c.Assert(st.Get("cluster-assemble", &as), check.IsNil)
+	as["done"] = false
+	st.Set("cluster-assemble", as)
+	c.Check(s.coordinateRefreshes(c, map[string]int{"microk8s": 2}), check.IsNil)
+
+	// clustering is disabled
+	as["done"] = true
+	st.Set("cluster-assemble", as)
+	tr := config.NewTransaction(st)
+	c.Assert(tr.Set("core", "experimental.clustering", false), check.IsNil)
+	tr.Commit()
+	c.Check(s.coordinateRefreshes(c, map[string]int{"microk8s": 2}), check.IsNil)
+
+	var v any
+	c.Check(st.Get("cluster-refresh", &v), testutil.ErrorIs, state.ErrNoState)
+}
+
+func (s *refreshSuite) TestEnsureStartsAndStopsCoordination(c *check.C) {
+	var served []refreshcoord.Peer
+	s.AddCleanup(clusterstate.MockRefreshcoordServe(func(ctx context.Context, ln net.Listener, cert tls.Certificate, peers []refreshcoord.Peer, coord *refreshcoord.Coordinator) error {
+		ln.Close()
+		served = peers
+		<-ctx.Done()
+		s.stopped++
+		return nil
+	}))
+
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	c.Check(s.mgr.HoldRefreshes(nil), check.Equals, clusterstate.ErrNotAssembled)
+
+	s.ensure(c)
+	// ensuring again keeps the coordination running
+	s.ensure(c)
+
+	c.Assert(s.mgr.HoldRefreshes(nil), check.IsNil)
+	c.Check(s.refreshStatus(c).HeldAll, check.Equals, true)
+
+	c.Assert(clusterstate.Leave(s.st), check.IsNil)
+	s.ensure(c)
+	c.Check(s.stopped, check.Equals, 1)
+	c.Check(served, check.DeepEquals, []refreshcoord.Peer{
+		{RDT: "a", Address: "10.0.0.1:7070", FP: clusterstate.MemberFP("a")},
+		{RDT: "c", Address: "10.0.0.3:7070", FP: clusterstate.MemberFP("c")},
+	})
+
+	c.Check(s.mgr.ResumeRefreshes(nil), check.Equals, clusterstate.ErrNotAssembled)
+
+	// the refreshes coordinated with the other members are forgotten
+	var v any
+	c.Check(s.st.Get("cluster-refresh", &v), testutil.ErrorIs, state.ErrNoState)
+}
+
+func (s *refreshSuite) TestEnsureStopsCoordinationClusteringDisabled(c *check.C) {
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	s.ensure(c)
+	c.Assert(s.mgr.HoldRefreshes([]string{"microk8s"}), check.IsNil)
+
+	tr := config.NewTransaction(s.st)
+	c.Assert(tr.Set("core", "experimental.clustering", false), check.IsNil)
+	tr.Commit()
+
+	s.ensure(c)
+	c.Check(s.stopped, check.Equals, 1)
+	c.Check(s.mgr.HoldRefreshes(nil), check.Equals, clusterstate.ErrNotAssembled)
+
+	// the holds are kept until the coordination starts again
+	tr = config.NewTransaction(s.st)
+	c.Assert(tr.Set("core", "experimental.clustering", true), check.IsNil)
+	tr.Commit()
+
+	s.ensure(c)
+	c.Check(s.refreshStatus(c).Held, check.DeepEquals, []string{"microk8s"})
+
+	s.coordinateOnce()
+	c.Assert(s.client.heartbeats["a"], check.HasLen, 1)
+	c.Check(s.client.heartbeats["a"][0].Holds, check.DeepEquals, refreshcoord.Holds{
+		Version: 1,
+		Snaps:   []string{"microk8s"},
+	})
+}
+
+func (s *refreshSuite) TestEnsureCannotListen(c *check.C) {
+	s.AddCleanup(clusterstate.MockNetListen(func(network, address string) (net.Listener, error) {
+		return nil, errors.New("boom")
+	}))
+
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	// the rest of the cluster state is still applied
+	s.ensure(c)
+	c.Check(s.mgr.HoldRefreshes(nil), check.Equals, clusterstate.ErrNotAssembled)
+}
+
+func (s *refreshSuite) TestRefreshGrantedAndReported(c *check.C) {
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	s.coordinateRefreshes(c, map[string]int{"microk8s": 2})
+	s.ensure(c)
+
+	s.client.decision = refreshcoord.Decision{Reason: `waiting for member "c" to finish refreshing "microk8s"`}
+	s.coordinateOnce()
+
+	request := refreshcoord.RefreshRequest{
+		RDT:     "b",
+		Refresh: refreshcoord.Refresh{Snap: "microk8s", Revision: snap.R(2)},
+	}
+	c.Check(s.client.requests, check.DeepEquals, []refreshcoord.RefreshRequest{request})
+	for _, peer := range []assemblestate.DeviceToken{"a", "c"} {
+		c.Check(s.client.heartbeats[peer], check.DeepEquals, []refreshcoord.Heartbeat{{RDT: "b"}})
+	}
+	c.Check(s.refreshStatus(c), check.DeepEquals, &clusterstate.RefreshStatus{
+		Leader: "a",
+		Refreshes: []clusterstate.ClusterRefresh{{
+			Snap:     "microk8s",
+			Revision: snap.R(2),
+			Status:   "waiting",
+			Reason:   `waiting for member "c" to finish refreshing "microk8s"`,
+		}},
+	})
+
+	// nothing is refreshed until the leader grants the refresh
+	s.ensure(c)
+	c.Check(s.st.Changes(), check.HasLen, 0)
+
+	s.client.decision = refreshcoord.Decision{Granted: true}
+	s.coordinateOnce()
+	c.Check(s.refreshStatus(c).Refreshes, check.DeepEquals, []clusterstate.ClusterRefresh{
+		{Snap: "microk8s", Revision: snap.R(2), Status: "refreshing"},
+	})
+
+	s.ensure(c)
+	c.Check(s.goals, check.DeepEquals, []snapstate.StoreUpdate{{
+		InstanceName: "microk8s",
+		RevOpts:      snapstate.RevisionOptions{Revision: snap.R(2)},
+	}})
+	c.Assert(s.st.Changes(), check.HasLen, 1)
+	chg := s.st.Changes()[0]
+	c.Check(chg.Kind(), check.Equals, "refresh-cluster-snap")
+	c.Check(chg.Summary(), check.Equals, `Refresh clustered snap "microk8s" to revision 2`)
+
+	// the change is seen through before reporting
+	s.ensure(c)
+	c.Check(s.st.Changes(), check.HasLen, 1)
+	s.coordinateOnce()
+	c.Check(s.client.reports, check.HasLen, 0)
+	c.Check(s.client.heartbeats["a"][2].Refreshing, check.DeepEquals, []refreshcoord.Refresh{request.Refresh})
+
+	chg.Tasks()[0].SetStatus(state.DoneStatus)
+	s.installMicrok8s(c, noHealthHookYaml, 1, 2)
+
+	s.coordinateOnce()
+	c.Check(s.client.reports, check.DeepEquals, []refreshcoord.RefreshReport{{
+		RDT:     "b",
+		Refresh: request.Refresh,
+		Healthy: true,
+	}})
+	c.Check(s.refreshStatus(c).Refreshes, check.HasLen, 0)
+}
+
+func (s *refreshSuite) TestRefreshReportedOnHealth(c *check.C) {
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	s.coordinateRefreshes(c, map[string]int{"microk8s": 2})
+	s.ensure(c)
+
+	s.client.decision = refreshcoord.Decision{Granted: true}
+	s.coordinateOnce()
+	s.ensure(c)
+	c.Assert(s.st.Changes(), check.HasLen, 1)
+	s.st.Changes()[0].Tasks()[0].SetStatus(state.DoneStatus)
+	s.installMicrok8s(c, healthHookYaml, 1, 2)
+
+	// the snap did not report on its health yet, or only for the previous
+	// revision
+	s.st.Set("health", map[string]*healthstate.HealthState{
+		"microk8s": {Revision: snap.R(1), Status: healthstate.OkayStatus},
+	})
+	s.coordinateOnce()
+	c.Check(s.client.reports, check.HasLen, 0)
+
+	s.st.Set("health", map[string]*healthstate.HealthState{
+		"microk8s": {Revision: snap.R(2), Status: healthstate.WaitingStatus},
+	})
+	s.coordinateOnce()
+	c.Check(s.client.reports, check.HasLen, 0)
+
+	// the leader cannot be reached, the report is sent again later
+	s.client.reportErr = errors.New("boom")
+	s.st.Set("health", map[string]*healthstate.HealthState{
+		"microk8s": {Revision: snap.R(2), Status: healthstate.ErrorStatus, Message: "cannot start"},
+	})
+	s.coordinateOnce()
+	c.Check(s.client.reports, check.HasLen, 0)
+	c.Check(s.refreshStatus(c).Refreshes, check.HasLen, 1)
+
+	s.client.reportErr = nil
+	s.coordinateOnce()
+	c.Check(s.client.reports, check.DeepEquals, []refreshcoord.RefreshReport{{
+		RDT:     "b",
+		Refresh: refreshcoord.Refresh{Snap: "microk8s", Revision: snap.R(2)},
+		Message: "cannot start",
+	}})
+	c.Check(s.refreshStatus(c).Refreshes, check.HasLen, 0)
+}
+
+func (s *refreshSuite) TestRefreshFailed(c *check.C) {
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	s.coordinateRefreshes(c, map[string]int{"microk8s": 2})
+	s.ensure(c)
+
+	s.client.decision = refreshcoord.Decision{Granted: true}
+	s.coordinateOnce()
+	s.ensure(c)
+	c.Assert(s.st.Changes(), check.HasLen, 1)
+	chg := s.st.Changes()[0]
+	chg.Tasks()[0].Errorf("boom")
+	chg.Tasks()[0].SetStatus(state.ErrorStatus)
+
+	s.coordinateOnce()
+	c.Assert(s.client.reports, check.HasLen, 1)
+	c.Check(s.client.reports[0].Healthy, check.Equals, false)
+	c.Check(s.client.reports[0].Message, check.Matches, `(?s)cannot refresh snap: .*boom.*`)
+}
+
+func (s *refreshSuite) TestEnsureGrantedRefreshesErrors(c *check.C) {
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	s.coordinateRefreshes(c, map[string]int{"microk8s": 2})
+	s.ensure(c)
+
+	s.client.decision = refreshcoord.Decision{Granted: true}
+	s.coordinateOnce()
+
+	// conflicting changes are waited for
+	s.AddCleanup(clusterstate.MockSnapstateUpdateWithGoal(func(ctx context.Context, st *state.State, goal snapstate.UpdateGoal, filter func(*snap.Info, *snapstate.SnapState) bool, opts snapstate.Options) ([]string, *snapstate.UpdateTaskSets, error) {
+		return nil, nil, &snapstate.ChangeConflictError{Snap: "microk8s", ChangeKind: "install"}
+	}))
+	s.ensure(c)
+	c.Check(s.st.Changes(), check.HasLen, 0)
+	s.coordinateOnce()
+	c.Check(s.client.reports, check.HasLen, 0)
+
+	s.AddCleanup(clusterstate.MockSnapstateUpdateWithGoal(func(ctx context.Context, st *state.State, goal snapstate.UpdateGoal, filter func(*snap.Info, *snapstate.SnapState) bool, opts snapstate.Options) ([]string, *snapstate.UpdateTaskSets, error) {
+		return nil, nil, errors.New("boom")
+	}))
+	s.ensure(c)
+	c.Check(s.st.Changes(), check.HasLen, 0)
+
+	s.coordinateOnce()
+	c.Check(s.client.reports, check.DeepEquals, []refreshcoord.RefreshReport{{
+		RDT:     "b",
+		Refresh: refreshcoord.Refresh{Snap: "microk8s", Revision: snap.R(2)},
+		Message: "cannot refresh snap: boom",
+	}})
+}
+
+func (s *refreshSuite) TestLeaderHoldResume(c *check.C) {
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	// this device becomes "a", the leader
+	err := clusterstate.MockAssembled(s.st, "a", map[assemblestate.DeviceToken]string{
+		"a": "10.0.0.1:7070",
+		"b": "10.0.0.2:7070",
+	})
+	c.Assert(err, check.IsNil)
+
+	s.coordinateRefreshes(c, map[string]int{"microk8s": 2})
+	s.ensure(c)
+
+	c.Assert(s.mgr.HoldRefreshes([]string{"microk8s"}), check.IsNil)
+
+	s.coordinateOnce()
+	// the leader handles its own requests
+	c.Check(s.client.requests, check.HasLen, 0)
+	c.Check(s.client.heartbeats["b"], check.DeepEquals, []refreshcoord.Heartbeat{{
+		RDT:   "a",
+		Holds: refreshcoord.Holds{Version: 1, Snaps: []string{"microk8s"}},
+	}})
+	c.Check(s.refreshStatus(c), check.DeepEquals, &clusterstate.RefreshStatus{
+		Leader: "a",
+		Held:   []string{"microk8s"},
+		Refreshes: []clusterstate.ClusterRefresh{{
+			Snap:     "microk8s",
+			Revision: snap.R(2),
+			Status:   "waiting",
+			Reason:   `refreshes of "microk8s" are held cluster-wide`,
+		}},
+	})
+
+	c.Assert(s.mgr.ResumeRefreshes(nil), check.IsNil)
+	s.coordinateOnce()
+	c.Check(s.refreshStatus(c).Refreshes, check.DeepEquals, []clusterstate.ClusterRefresh{
+		{Snap: "microk8s", Revision: snap.R(2), Status: "refreshing"},
+	})
+
+	s.ensure(c)
+	c.Assert(s.st.Changes(), check.HasLen, 1)
+	s.st.Changes()[0].Tasks()[0].SetStatus(state.DoneStatus)
+	s.installMicrok8s(c, noHealthHookYaml, 1, 2)
+
+	s.coordinateOnce()
+	c.Check(s.client.reports, check.HasLen, 0)
+	c.Check(s.refreshStatus(c).Refreshes, check.HasLen, 0)
+
+	// the rollout carries on with "b"
+	var rs struct {
+		Coordinator refreshcoord.CoordinatorState `json:"coordinator"`
+	}
+	c.Assert(s.st.Get("cluster-refresh", &rs), check.IsNil)
+	c.Check(rs.Coordinator.Rollouts["microk8s"].Done, check.DeepEquals, []assemblestate.DeviceToken{"a"})
+}
+
+func (s *refreshSuite) TestLeaderHaltsRolloutOnUnhealthyRefresh(c *check.C) {
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	err := clusterstate.MockAssembled(s.st, "a", map[assemblestate.DeviceToken]string{
+		"a": "10.0.0.1:7070",
+		"b": "10.0.0.2:7070",
+	})
+	c.Assert(err, check.IsNil)
+
+	s.coordinateRefreshes(c, map[string]int{"microk8s": 2})
+	s.ensure(c)
+	s.coordinateOnce()
+
+	s.ensure(c)
+	c.Assert(s.st.Changes(), check.HasLen, 1)
+	s.st.Changes()[0].Tasks()[0].SetStatus(state.DoneStatus)
+	s.installMicrok8s(c, healthHookYaml, 1, 2)
+	s.st.Set("health", map[string]*healthstate.HealthState{
+		"microk8s": {Revision: snap.R(2), Status: healthstate.BlockedStatus},
+	})
+
+	s.coordinateOnce()
+	c.Check(s.refreshStatus(c).Refreshes, check.HasLen, 0)
+
+	// the next refresh of the snap waits for the rollout to be resumed
+	s.coordinateRefreshes(c, map[string]int{"microk8s": 3})
+	s.coordinateOnce()
+	c.Check(s.refreshStatus(c).Refreshes, check.DeepEquals, []clusterstate.ClusterRefresh{{
+		Snap:     "microk8s",
+		Revision: snap.R(3),
+		Status:   "waiting",
+		Reason:   fmt.Sprintf(`refreshes of "microk8s" are halted: member "a" reported refresh as unhealthy: snap health is %q`, healthstate.BlockedStatus),
+	}})
+
+	c.Assert(s.mgr.ResumeRefreshes([]string{"microk8s"}), check.IsNil)
+	s.coordinateOnce()
+	c.Check(s.refreshStatus(c).Refreshes, check.DeepEquals, []clusterstate.ClusterRefresh{
+		{Snap: "microk8s", Revision: snap.R(3), Status: "refreshing"},
+	})
+}
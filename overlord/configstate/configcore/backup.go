@@ -0,0 +1,109 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package configcore
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/sandbox/apparmor"
+	"github.com/snapcore/snapd/snap/sysparams"
+	"github.com/snapcore/snapd/sysconfig"
+)
+
+func init() {
+	// add supported configuration of this module
+	supportedConfigurations["core.backup.target-dir"] = true
+}
+
+func validateBackupTargetDir(tr ConfGetter) error {
+	dir, err := coreCfg(tr, "backup.target-dir")
+	if err != nil {
+		return err
+	}
+	if dir == "" {
+		return nil
+	}
+
+	if !filepath.IsAbs(dir) {
+		return fmt.Errorf("backup.target-dir %q is not absolute", dir)
+	}
+	if dir != filepath.Clean(dir) || dir == "/" {
+		return fmt.Errorf("backup.target-dir %q is not a clean path to a directory", dir)
+	}
+	// the directory is used in the AppArmor profiles of the backup snaps
+	if err := apparmor.ValidateNoAppArmorRegexp(dir); err != nil {
+		return fmt.Errorf("backup.target-dir invalid: %v", err)
+	}
+	for _, prefix := range invalidPrefixes {
+		if strings.HasPrefix(dir+"/", prefix) {
+			return fmt.Errorf("backup.target-dir %q uses reserved root directory %q", dir, prefix)
+		}
+	}
+
+	exists, isDir, err := osutilDirExists(dir)
+	if err != nil {
+		return fmt.Errorf("cannot get directory info for %q: %v", dir, err)
+	}
+	if !exists {
+		return fmt.Errorf("backup.target-dir %q does not exist", dir)
+	}
+	if !isDir {
+		return fmt.Errorf("backup.target-dir %q is not a directory", dir)
+	}
+	return nil
+}
+
+// handleBackupTargetDir records the backup target directory in the system
+// parameters, where the system-backup interface picks it up the next time
+// the security profiles of the connected snaps are generated.
+func handleBackupTargetDir(_ sysconfig.Device, tr ConfGetter, opts *fsOnlyContext) error {
+	dir, err := coreCfg(tr, "backup.target-dir")
+	if err != nil {
+		return err
+	}
+	var prevDir string
+	if err := tr.GetPristine("core", "backup.target-dir", &prevDir); err != nil && !config.IsNoOption(err) {
+		return err
+	}
+	if dir == prevDir {
+		return nil
+	}
+
+	// if opts is not nil this is image build time
+	rootDir := dirs.GlobalRootDir
+	if opts != nil {
+		rootDir = opts.RootDir
+	}
+	if err := os.MkdirAll(path.Dir(dirs.SnapSystemParamsUnder(rootDir)), 0755); err != nil {
+		return err
+	}
+	ssp, err := sysparams.Open(rootDir)
+	if err != nil {
+		return err
+	}
+	ssp.BackupTargetDir = dir
+	return ssp.Write()
+}
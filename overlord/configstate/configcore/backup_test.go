@@ -0,0 +1,136 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package configcore_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/overlord/configstate/configcore"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type backupSuite struct {
+	configcoreSuite
+}
+
+var _ = Suite(&backupSuite{})
+
+func (s *backupSuite) SetUpTest(c *C) {
+	s.configcoreSuite.SetUpTest(c)
+
+	restore := configcore.MockDirExists(func(path string) (exists bool, isDir bool, err error) {
+		switch path {
+		case "/srv/backup":
+			return true, true, nil
+		case "/srv/file":
+			return true, false, nil
+		case "/srv/missing":
+			return false, false, nil
+		default:
+			return false, false, errors.New("stat failed")
+		}
+	})
+	s.AddCleanup(restore)
+}
+
+func (s *backupSuite) TestValidationUnhappy(c *C) {
+	for _, tc := range []struct {
+		dir string
+		err string
+	}{
+		{"srv/backup", `backup.target-dir "srv/backup" is not absolute`},
+		{"/srv/backup/", `backup.target-dir "/srv/backup/" is not a clean path to a directory`},
+		{"/", `backup.target-dir "/" is not a clean path to a directory`},
+		{"/srv/backup*", `backup.target-dir invalid: "/srv/backup\*" contains a reserved apparmor char.*`},
+		{"/etc/backup", `backup.target-dir "/etc/backup" uses reserved root directory "/etc/"`},
+		{"/etc", `backup.target-dir "/etc" uses reserved root directory "/etc/"`},
+		{"/srv/error", `cannot get directory info for "/srv/error": stat failed`},
+		{"/srv/missing", `backup.target-dir "/srv/missing" does not exist`},
+		{"/srv/file", `backup.target-dir "/srv/file" is not a directory`},
+	} {
+		err := configcore.FilesystemOnlyRun(coreDev, &mockConf{
+			state: s.state,
+			changes: map[string]any{
+				"backup.target-dir": tc.dir,
+			},
+		})
+		c.Check(err, ErrorMatches, tc.err, Commentf("%v", tc.dir))
+	}
+}
+
+func (s *backupSuite) TestConfigureHappy(c *C) {
+	for _, dev := range []mockDev{classicDev, coreDev} {
+		err := configcore.FilesystemOnlyRun(dev, &mockConf{
+			state: s.state,
+			changes: map[string]any{
+				"backup.target-dir": "/srv/backup",
+			},
+		})
+		c.Assert(err, IsNil)
+		c.Check(dirs.SnapSystemParamsUnder(dirs.GlobalRootDir), testutil.FileEquals, "homedirs=\nbackup-target-dir=/srv/backup\n")
+		c.Assert(os.Remove(dirs.SnapSystemParamsUnder(dirs.GlobalRootDir)), IsNil)
+	}
+}
+
+func (s *backupSuite) TestConfigureKeepsHomedirs(c *C) {
+	sspPath := dirs.SnapSystemParamsUnder(dirs.GlobalRootDir)
+	c.Assert(os.MkdirAll(filepath.Dir(sspPath), 0755), IsNil)
+	c.Assert(os.WriteFile(sspPath, []byte("homedirs=/home/foo\nbackup-target-dir=/srv/old\n"), 0644), IsNil)
+
+	err := configcore.FilesystemOnlyRun(classicDev, &mockConf{
+		state: s.state,
+		conf: map[string]any{
+			"backup.target-dir": "/srv/old",
+		},
+		changes: map[string]any{
+			"backup.target-dir": "",
+		},
+	})
+	c.Assert(err, IsNil)
+	c.Check(sspPath, testutil.FileEquals, "homedirs=/home/foo\n")
+}
+
+func (s *backupSuite) TestConfigureUnchanged(c *C) {
+	err := configcore.FilesystemOnlyRun(classicDev, &mockConf{
+		state: s.state,
+		conf: map[string]any{
+			"backup.target-dir": "/srv/backup",
+		},
+		changes: map[string]any{
+			"backup.target-dir": "/srv/backup",
+		},
+	})
+	c.Assert(err, IsNil)
+	c.Check(dirs.SnapSystemParamsUnder(dirs.GlobalRootDir), testutil.FileAbsent)
+}
+
+func (s *backupSuite) TestFilesystemOnlyApply(c *C) {
+	conf := configcore.PlainCoreConfig(map[string]any{
+		"backup.target-dir": "/srv/backup",
+	})
+	tmpDir := c.MkDir()
+	c.Assert(configcore.FilesystemOnlyApply(coreDev, tmpDir, conf), IsNil)
+	c.Check(dirs.SnapSystemParamsUnder(tmpDir), testutil.FileEquals, "homedirs=\nbackup-target-dir=/srv/backup\n")
+}
@@ -109,6 +109,9 @@ func init() {
 	// home directory configuration
 	addFSOnlyHandler(validateHomedirsConfiguration, handleHomedirsConfiguration, nil)
 
+	// backup.target-dir
+	addFSOnlyHandler(validateBackupTargetDir, handleBackupTargetDir, nil)
+
 	// tmpfs.size
 	addFSOnlyHandler(validateTmpfsSettings, handleTmpfsConfiguration, coreOnly)
 
@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/snapcore/snapd/overlord/devicestate"
+	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/strutil"
 	"github.com/snapcore/snapd/timeutil"
 )
@@ -43,6 +44,8 @@ func init() {
 	supportedConfigurations["core.refresh.retain"] = true
 	supportedConfigurations["core.refresh.rate-limit"] = true
 	supportedConfigurations["core.refresh.max-inhibition-days"] = true
+	supportedConfigurations["core.refresh.stagger"] = true
+	supportedConfigurations["core.refresh.migrate-eol-tracks"] = true
 }
 
 func reportOrIgnoreInvalidManageRefreshes(tr RunTransaction, optName string) error {
@@ -163,3 +166,15 @@ func validateRefreshRateLimit(tr RunTransaction) error {
 	}
 	return nil
 }
+
+func validateRefreshStagger(tr RunTransaction) error {
+	refreshStagger, err := coreCfg(tr, "refresh.stagger")
+	if err != nil {
+		return err
+	}
+	return snapstate.ValidateRefreshStagger(refreshStagger)
+}
+
+func validateRefreshMigrateEOLTracks(tr RunTransaction) error {
+	return validateBoolFlag(tr, "refresh.migrate-eol-tracks")
+}
@@ -252,3 +252,58 @@ func (s *refreshSuite) TestConfigureRefreshMaxInhibitionDays(c *C) {
 		}
 	}
 }
+
+func (s *refreshSuite) TestConfigureRefreshStagger(c *C) {
+	for _, tc := range []struct {
+		val string
+		err string
+	}{
+		{val: "zzz", err: `refresh.stagger must be "random", "device" or a percentage between 0% and 100%, not "zzz"`},
+		{val: "50", err: `refresh.stagger must be "random", "device" or a percentage between 0% and 100%, not "50"`},
+		{val: "101%", err: `refresh.stagger must be "random", "device" or a percentage between 0% and 100%, not "101%"`},
+		{val: "-1%", err: `refresh.stagger must be "random", "device" or a percentage between 0% and 100%, not "-1%"`},
+		// happy cases
+		{val: ""},
+		{val: "random"},
+		{val: "device"},
+		{val: "0%"},
+		{val: "100%"},
+	} {
+		err := configcore.Run(classicDev, &mockConf{
+			state: s.state,
+			conf: map[string]any{
+				"refresh.stagger": tc.val,
+			},
+		})
+		if tc.err != "" {
+			c.Check(err, ErrorMatches, tc.err)
+		} else {
+			c.Check(err, IsNil)
+		}
+	}
+}
+
+func (s *refreshSuite) TestConfigureRefreshMigrateEOLTracks(c *C) {
+	for _, tc := range []struct {
+		val string
+		err string
+	}{
+		{val: "foo", err: `refresh.migrate-eol-tracks can only be set to 'true' or 'false'`},
+		// happy cases
+		{val: ""},
+		{val: "true"},
+		{val: "false"},
+	} {
+		err := configcore.Run(classicDev, &mockConf{
+			state: s.state,
+			conf: map[string]any{
+				"refresh.migrate-eol-tracks": tc.val,
+			},
+		})
+		if tc.err != "" {
+			c.Check(err, ErrorMatches, tc.err)
+		} else {
+			c.Check(err, IsNil)
+		}
+	}
+}
@@ -59,7 +59,11 @@ func init() {
 	validateOnly := &flags{validatedOnlyStateConfig: true}
 	addWithStateHandler(validateRefreshSchedule, nil, validateOnly)
 	addWithStateHandler(validateRefreshRateLimit, nil, validateOnly)
+	addWithStateHandler(validateRefreshStagger, nil, validateOnly)
+	addWithStateHandler(validateRefreshMigrateEOLTracks, nil, validateOnly)
+	addWithStateHandler(validateStoreDownload, nil, validateOnly)
 	addWithStateHandler(validateAutomaticSnapshotsExpiration, nil, validateOnly)
+	addWithStateHandler(validateIncrementalSnapshots, nil, validateOnly)
 
 	// netplan.*
 	addWithStateHandler(validateNetplanSettings, handleNetplanConfiguration, coreOnly)
@@ -173,6 +177,8 @@ func applyHandlers(dev sysconfig.Device, cfg RunTransaction, handlers []configHa
 			}
 		case strings.HasPrefix(k, "core."+customCertPrefix+"."):
 			// validated by validateCustomCertificateRequest
+		case isSysctlChange(k):
+			// validated by validateSysctlOptions
 		case isNetplanChange(k):
 			if release.OnClassic {
 				return fmt.Errorf("cannot set netplan configuration on classic")
@@ -28,6 +28,7 @@ import (
 func init() {
 	// add supported configuration of this module
 	supportedConfigurations["core.snapshots.automatic.retention"] = true
+	supportedConfigurations["core.snapshots.incremental"] = true
 }
 
 func validateAutomaticSnapshotsExpiration(tr RunTransaction) error {
@@ -46,3 +47,7 @@ func validateAutomaticSnapshotsExpiration(tr RunTransaction) error {
 	}
 	return nil
 }
+
+func validateIncrementalSnapshots(tr RunTransaction) error {
+	return validateBoolFlag(tr, "snapshots.incremental")
+}
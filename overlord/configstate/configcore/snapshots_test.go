@@ -71,3 +71,25 @@ func (s *refreshSuite) TestConfigureAutomaticSnapshotsExpirationInvalid(c *C) {
 	})
 	c.Assert(err, ErrorMatches, `snapshots.automatic.retention cannot be parsed:.*`)
 }
+
+func (s *snapshotsSuite) TestConfigureIncrementalSnapshots(c *C) {
+	for _, value := range []any{true, false, "true", "false"} {
+		err := configcore.Run(classicDev, &mockConf{
+			state: s.state,
+			conf: map[string]any{
+				"snapshots.incremental": value,
+			},
+		})
+		c.Assert(err, IsNil)
+	}
+}
+
+func (s *snapshotsSuite) TestConfigureIncrementalSnapshotsInvalid(c *C) {
+	err := configcore.Run(classicDev, &mockConf{
+		state: s.state,
+		conf: map[string]any{
+			"snapshots.incremental": "sometimes",
+		},
+	})
+	c.Assert(err, ErrorMatches, `snapshots.incremental can only be set to 'true' or 'false'`)
+}
@@ -22,18 +22,28 @@ package configcore
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/strutil"
 	"github.com/snapcore/snapd/sysconfig"
+	"github.com/snapcore/snapd/timeutil"
 )
 
 func init() {
 	supportedConfigurations["core.store.access"] = true
+	supportedConfigurations["core.store.download-rate-limit"] = true
+	supportedConfigurations["core.store.download-window"] = true
+	supportedConfigurations["core.store.download-window-min-size"] = true
+	supportedConfigurations["core.store.max-parallel-downloads"] = true
 }
 
+const maxParallelDownloads = 32
+
 func validateStoreAccess(cfg ConfGetter) error {
 	storeAccess, err := coreCfg(cfg, "store.access")
 	if err != nil {
@@ -48,6 +58,42 @@ func validateStoreAccess(cfg ConfGetter) error {
 	}
 }
 
+func validateStoreDownload(tr RunTransaction) error {
+	for _, option := range []string{"store.download-rate-limit", "store.download-window-min-size"} {
+		value, err := coreCfg(tr, option)
+		if err != nil {
+			return err
+		}
+		// reset is fine
+		if value == "" {
+			continue
+		}
+		if _, err := strutil.ParseByteSize(value); err != nil {
+			return err
+		}
+	}
+
+	parallelStr, err := coreCfg(tr, "store.max-parallel-downloads")
+	if err != nil {
+		return err
+	}
+	if parallelStr != "" {
+		if n, err := strconv.ParseUint(parallelStr, 10, 8); err != nil || n < 1 || n > maxParallelDownloads {
+			return fmt.Errorf("max-parallel-downloads must be a number between 1 and %d, not %q", maxParallelDownloads, parallelStr)
+		}
+	}
+
+	window, err := coreCfg(tr, "store.download-window")
+	if err != nil {
+		return err
+	}
+	if window == "" {
+		return nil
+	}
+	_, err = timeutil.ParseSchedule(window)
+	return err
+}
+
 // repairConfig is a set of configuration data that is consumed by the
 // snap-repair command. This struct is duplicated in cmd/snap-repair.
 type repairConfig struct {
@@ -95,3 +95,38 @@ func (s *storeSuite) TestFilesystemOnlyApply(c *C) {
 
 	c.Check(repairConfig.StoreOffline, Equals, true)
 }
+
+func (s *storeSuite) TestStoreDownloadHappy(c *C) {
+	err := configcore.Run(classicDev, &mockConf{
+		state: s.state,
+		conf: map[string]any{
+			"store.download-rate-limit":      "2MB",
+			"store.download-window":          "22:00-06:00",
+			"store.download-window-min-size": "100MB",
+			"store.max-parallel-downloads":   "4",
+		},
+	})
+	c.Assert(err, IsNil)
+}
+
+func (s *storeSuite) TestStoreDownloadUnhappy(c *C) {
+	for _, tc := range []struct {
+		option, value, err string
+	}{
+		{"store.download-rate-limit", "fast", `cannot parse "fast": no numerical prefix`},
+		{"store.download-rate-limit", "-1MB", `cannot parse "-1MB": size cannot be negative`},
+		{"store.download-window-min-size", "100", `cannot parse "100": need a number with a unit as input`},
+		{"store.download-window", "night", `cannot parse "night": "night" is not a valid weekday`},
+		{"store.max-parallel-downloads", "0", `max-parallel-downloads must be a number between 1 and 32, not "0"`},
+		{"store.max-parallel-downloads", "33", `max-parallel-downloads must be a number between 1 and 32, not "33"`},
+		{"store.max-parallel-downloads", "many", `max-parallel-downloads must be a number between 1 and 32, not "many"`},
+	} {
+		err := configcore.Run(classicDev, &mockConf{
+			state: s.state,
+			conf: map[string]any{
+				tc.option: tc.value,
+			},
+		})
+		c.Check(err, ErrorMatches, tc.err, Commentf("%s=%s", tc.option, tc.value))
+	}
+}
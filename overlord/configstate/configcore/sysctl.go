@@ -20,14 +20,22 @@
 package configcore
 
 import (
+	"bufio"
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/strutil"
 	"github.com/snapcore/snapd/sysconfig"
 	"github.com/snapcore/snapd/systemd"
 )
@@ -39,6 +47,7 @@ import (
 func init() {
 	// add supported configuration of this module
 	supportedConfigurations["core.system.kernel.printk.console-loglevel"] = true
+	supportedConfigurations["core."+sysctlOption] = true
 }
 
 const (
@@ -46,10 +55,184 @@ const (
 	snapdSysctlConf = "99-snapd.conf"
 )
 
+// sysctlOption carries a set of kernel parameters managed by snapd, usually
+// declared by the gadget defaults, e.g.:
+//
+//	system.kernel.sysctl:
+//	  vm.swappiness: 10
+//	  net.core.somaxconn: 1024
+const sysctlOption = "system.kernel.sysctl"
+
 // these are the sysctl parameters prefixes we handle
 var sysctlPrefixes = []string{"kernel.printk"}
 
+var (
+	sysctlKeyRegexp = regexp.MustCompile(`^[a-z0-9_-]+(\.[a-zA-Z0-9_-]+)+$`)
+
+	// only parameters of these hierarchies can be managed
+	sysctlAllowedRoots = []string{"fs", "kernel", "net", "vm"}
+	// parameters handled by other options
+	sysctlReservedPrefixes = map[string]string{
+		"kernel.printk":                  "system.kernel.printk.console-loglevel",
+		"net.ipv6.conf.all.disable_ipv6": "network.disable-ipv6",
+	}
+)
+
+func isSysctlChange(chg string) bool {
+	return chg == "core."+sysctlOption || strings.HasPrefix(chg, "core."+sysctlOption+".")
+}
+
+// flattenSysctl flattens the nested maps that "snap set" creates for
+// dotted sysctl names.
+func flattenSysctl(prefix string, v any, out map[string]string) error {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, sub := range v {
+			if prefix != "" {
+				k = prefix + "." + k
+			}
+			if err := flattenSysctl(k, sub, out); err != nil {
+				return err
+			}
+		}
+	case map[any]any:
+		// from gadget defaults
+		for k, sub := range v {
+			name := fmt.Sprintf("%v", k)
+			if prefix != "" {
+				name = prefix + "." + name
+			}
+			if err := flattenSysctl(name, sub, out); err != nil {
+				return err
+			}
+		}
+	case nil:
+		// unset
+	case string, bool, float64, int, int64, json.Number:
+		out[prefix] = fmt.Sprintf("%v", v)
+	default:
+		return fmt.Errorf("cannot set sysctl %q: unsupported value %v", prefix, v)
+	}
+	return nil
+}
+
+// managedSysctls returns the kernel parameters set with the
+// system.kernel.sysctl option.
+func managedSysctls(tr ConfGetter) (map[string]string, error) {
+	var v any
+	if err := tr.Get("core", sysctlOption, &v); err != nil && !config.IsNoOption(err) {
+		return nil, err
+	}
+	if v == nil {
+		return nil, nil
+	}
+	switch v.(type) {
+	case map[string]any, map[any]any:
+	default:
+		return nil, fmt.Errorf("cannot set %s: value must be a map of kernel parameters", sysctlOption)
+	}
+	sysctls := make(map[string]string)
+	if err := flattenSysctl("", v, sysctls); err != nil {
+		return nil, err
+	}
+	return sysctls, nil
+}
+
+func validateSysctlKey(key string) error {
+	if !sysctlKeyRegexp.MatchString(key) {
+		return fmt.Errorf("cannot set sysctl %q: invalid name", key)
+	}
+	if !strutil.ListContains(sysctlAllowedRoots, strings.SplitN(key, ".", 2)[0]) {
+		return fmt.Errorf("cannot set sysctl %q: only parameters under %s can be set", key, strutil.Quoted(sysctlAllowedRoots))
+	}
+	for prefix, opt := range sysctlReservedPrefixes {
+		if key == prefix || strings.HasPrefix(key, prefix+".") {
+			return fmt.Errorf("cannot set sysctl %q: use the %s option instead", key, opt)
+		}
+	}
+	return nil
+}
+
+func validateManagedSysctls(tr ConfGetter) error {
+	sysctls, err := managedSysctls(tr)
+	if err != nil {
+		return err
+	}
+	for key, value := range sysctls {
+		if err := validateSysctlKey(key); err != nil {
+			return err
+		}
+		if value == "" || strings.ContainsAny(value, "\n\r") {
+			return fmt.Errorf("cannot set sysctl %q: invalid value %q", key, value)
+		}
+	}
+	return nil
+}
+
+// procSysPath returns the path of the given kernel parameter under
+// /proc/sys.
+func procSysPath(key string) string {
+	return filepath.Join(dirs.GlobalRootDir, "/proc/sys", strings.Replace(key, ".", "/", -1))
+}
+
+// normalizeSysctlValue normalizes the whitespace between the fields of a
+// value, which the kernel separates with tabs.
+func normalizeSysctlValue(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// appliedSysctls returns the kernel parameters from the configuration file
+// written by snapd, along with its raw content.
+func appliedSysctls(path string) (sysctls map[string]string, content []byte, err error) {
+	content, err = os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	sysctls = make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		sysctls[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return sysctls, content, scanner.Err()
+}
+
+// warnSysctlDrift warns about the kernel parameters managed by snapd that
+// were changed since they were applied, and returns whether there were any.
+func warnSysctlDrift(applied map[string]string) (drifted bool) {
+	keys := make([]string, 0, len(applied))
+	for key := range applied {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if key == "kernel.printk" {
+			// the other fields are not managed by snapd
+			continue
+		}
+		current, err := os.ReadFile(procSysPath(key))
+		if err != nil {
+			continue
+		}
+		if normalizeSysctlValue(string(current)) != normalizeSysctlValue(applied[key]) {
+			logger.Noticef("WARNING: sysctl %s was changed externally to %q, resetting it to %q", key, normalizeSysctlValue(string(current)), applied[key])
+			drifted = true
+		}
+	}
+	return drifted
+}
+
 func validateSysctlOptions(tr ConfGetter) error {
+	if err := validateManagedSysctls(tr); err != nil {
+		return err
+	}
+
 	consoleLoglevelStr, err := coreCfg(tr, "system.kernel.printk.console-loglevel")
 	if err != nil {
 		return err
@@ -72,6 +255,24 @@ func handleSysctlConfiguration(_ sysconfig.Device, tr ConfGetter, opts *fsOnlyCo
 	if err != nil {
 		return nil
 	}
+	sysctls, err := managedSysctls(tr)
+	if err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(sysctls))
+	for key := range sysctls {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if opts == nil {
+		// parameters missing from the running kernel would fail
+		// to apply
+		for _, key := range keys {
+			if !osutil.FileExists(procSysPath(key)) {
+				return fmt.Errorf("cannot set sysctl %q: no such kernel parameter", key)
+			}
+		}
+	}
 
 	content := bytes.NewBuffer(nil)
 	if consoleLoglevelStr != "" {
@@ -84,6 +285,9 @@ func handleSysctlConfiguration(_ sysconfig.Device, tr ConfGetter, opts *fsOnlyCo
 		// TODO: this logic will need more non-obvious work to support
 		// kernel parameters that don't have already on-disk defaults.
 	}
+	for _, key := range keys {
+		content.WriteString(fmt.Sprintf("%s = %s\n", key, sysctls[key]))
+	}
 	dirContent := map[string]osutil.FileState{}
 	if content.Len() > 0 {
 		dirContent[snapdSysctlConf] = &osutil.MemoryFileState{
@@ -99,6 +303,15 @@ func handleSysctlConfiguration(_ sysconfig.Device, tr ConfGetter, opts *fsOnlyCo
 		}
 	}
 
+	applied, oldContent, err := appliedSysctls(filepath.Join(dir, snapdSysctlConf))
+	if err != nil {
+		return err
+	}
+	drifted := false
+	if opts == nil {
+		drifted = warnSysctlDrift(applied)
+	}
+
 	// write the new config
 	glob := snapdSysctlConf
 	changed, removed, err := osutil.EnsureDirState(dir, glob, dirContent)
@@ -107,12 +320,48 @@ func handleSysctlConfiguration(_ sysconfig.Device, tr ConfGetter, opts *fsOnlyCo
 	}
 
 	if opts == nil {
-		if len(changed) > 0 || len(removed) > 0 {
+		// parameters that are no longer managed get their defaults
+		// back, and the applied ones that drifted are reset
+		var managed []string
+		for key := range applied {
+			if !strutil.ListContains(sysctlPrefixes, key) {
+				managed = append(managed, key)
+			}
+		}
+		for _, key := range keys {
+			if _, ok := applied[key]; !ok {
+				managed = append(managed, key)
+			}
+		}
+		sort.Strings(managed)
+		prefixes := append(append([]string{}, sysctlPrefixes...), managed...)
+		if len(changed) > 0 || len(removed) > 0 || drifted {
 			// apply our configuration or default configuration
 			// via systemd-sysctl for the relevant prefixes
-			return systemd.Sysctl(sysctlPrefixes)
+			if err := systemd.Sysctl(prefixes); err != nil {
+				if rerr := restoreSysctlConfiguration(dir, oldContent, prefixes); rerr != nil {
+					logger.Noticef("cannot restore previous sysctl configuration: %v", rerr)
+				}
+				return fmt.Errorf("cannot apply sysctl configuration: %v", err)
+			}
 		}
 	}
 
 	return nil
 }
+
+// restoreSysctlConfiguration restores the previous configuration file
+// written by snapd and applies it again.
+func restoreSysctlConfiguration(dir string, oldContent []byte, prefixes []string) error {
+	dirContent := map[string]osutil.FileState{}
+	if oldContent != nil {
+		dirContent[snapdSysctlConf] = &osutil.MemoryFileState{
+			Content: oldContent,
+			Mode:    0644,
+		}
+	}
+	if _, _, err := osutil.EnsureDirState(dir, snapdSysctlConf, dirContent); err != nil {
+		return err
+	}
+	return systemd.Sysctl(prefixes)
+}
@@ -20,14 +20,19 @@
 package configcore_test
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	. "gopkg.in/check.v1"
 
 	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/overlord/configstate/configcore"
+	"github.com/snapcore/snapd/systemd"
 	"github.com/snapcore/snapd/testutil"
 )
 
@@ -136,3 +141,197 @@ func (s *sysctlSuite) TestFilesystemOnlyApply(c *C) {
 	// systemd-sysctl was not executed
 	c.Check(s.systemdSysctlArgs, HasLen, 0)
 }
+
+func (s *sysctlSuite) mockProcSys(c *C, values map[string]string) {
+	for key, value := range values {
+		path := filepath.Join(dirs.GlobalRootDir, "/proc/sys", strings.Replace(key, ".", "/", -1))
+		c.Assert(os.MkdirAll(filepath.Dir(path), 0755), IsNil)
+		c.Assert(os.WriteFile(path, []byte(value+"\n"), 0644), IsNil)
+	}
+}
+
+func (s *sysctlSuite) TestConfigureManagedSysctls(c *C) {
+	s.mockProcSys(c, map[string]string{
+		"vm.swappiness":      "60",
+		"net.core.somaxconn": "4096",
+	})
+
+	err := configcore.FilesystemOnlyRun(coreDev, &mockConf{
+		state: s.state,
+		conf: map[string]any{
+			"system.kernel.printk.console-loglevel": "2",
+			"system.kernel.sysctl": map[string]any{
+				// as set with "snap set"
+				"vm": map[string]any{"swappiness": json.Number("10")},
+				// as set by the gadget defaults
+				"net.core.somaxconn": "1024",
+			},
+		},
+	})
+	c.Assert(err, IsNil)
+	c.Check(s.mockSysctlConfPath, testutil.FileEquals, "kernel.printk = 2 4 1 7\n"+
+		"net.core.somaxconn = 1024\n"+
+		"vm.swappiness = 10\n")
+	c.Check(s.systemdSysctlArgs, DeepEquals, [][]string{
+		{"--prefix", "kernel.printk", "--prefix", "net.core.somaxconn", "--prefix", "vm.swappiness"},
+	})
+	s.systemdSysctlArgs = nil
+
+	// pretend systemd-sysctl applied them
+	s.mockProcSys(c, map[string]string{
+		"vm.swappiness":      "10",
+		"net.core.somaxconn": "1024",
+	})
+
+	// parameters that are no longer managed get their defaults back
+	err = configcore.FilesystemOnlyRun(coreDev, &mockConf{
+		state: s.state,
+		conf: map[string]any{
+			"system.kernel.sysctl": map[string]any{
+				"vm": map[string]any{"swappiness": json.Number("10")},
+			},
+		},
+	})
+	c.Assert(err, IsNil)
+	c.Check(s.mockSysctlConfPath, testutil.FileEquals, "vm.swappiness = 10\n")
+	c.Check(s.systemdSysctlArgs, DeepEquals, [][]string{
+		{"--prefix", "kernel.printk", "--prefix", "net.core.somaxconn", "--prefix", "vm.swappiness"},
+	})
+	s.systemdSysctlArgs = nil
+
+	// nothing changed, nothing to apply
+	err = configcore.FilesystemOnlyRun(coreDev, &mockConf{
+		state: s.state,
+		conf: map[string]any{
+			"system.kernel.sysctl": map[string]any{
+				"vm": map[string]any{"swappiness": json.Number("10")},
+			},
+		},
+	})
+	c.Assert(err, IsNil)
+	c.Check(s.systemdSysctlArgs, HasLen, 0)
+}
+
+func (s *sysctlSuite) TestConfigureManagedSysctlsInvalid(c *C) {
+	s.mockProcSys(c, map[string]string{"vm.swappiness": "60"})
+
+	for _, tc := range []struct {
+		value any
+		err   string
+	}{
+		{"vm.swappiness=10", `cannot set system.kernel.sysctl: value must be a map of kernel parameters`},
+		{map[string]any{"vm..swappiness": "10"}, `cannot set sysctl "vm..swappiness": invalid name`},
+		{map[string]any{"swappiness": "10"}, `cannot set sysctl "swappiness": invalid name`},
+		{map[string]any{"dev.tty.ldisc_autoload": "0"}, `cannot set sysctl "dev.tty.ldisc_autoload": only parameters under "fs", "kernel", "net", "vm" can be set`},
+		{map[string]any{"kernel.printk": "4 4 1 7"}, `cannot set sysctl "kernel.printk": use the system.kernel.printk.console-loglevel option instead`},
+		{map[string]any{"net.ipv6.conf.all.disable_ipv6": "1"}, `cannot set sysctl "net.ipv6.conf.all.disable_ipv6": use the network.disable-ipv6 option instead`},
+		{map[string]any{"vm.swappiness": "10\nkernel.printk = 8"}, `cannot set sysctl "vm.swappiness": invalid value "10\\nkernel.printk = 8"`},
+		{map[string]any{"vm.swappiness": ""}, `cannot set sysctl "vm.swappiness": invalid value ""`},
+		{map[string]any{"vm.swappiness": []any{"10"}}, `cannot set sysctl "vm.swappiness": unsupported value \[10\]`},
+		{map[string]any{"vm.dirty_ratio": "10"}, `cannot set sysctl "vm.dirty_ratio": no such kernel parameter`},
+	} {
+		err := configcore.FilesystemOnlyRun(coreDev, &mockConf{
+			state: s.state,
+			conf: map[string]any{
+				"system.kernel.sysctl": tc.value,
+			},
+		})
+		c.Check(err, ErrorMatches, tc.err, Commentf("%v", tc.value))
+		c.Check(osutil.FileExists(s.mockSysctlConfPath), Equals, false)
+		c.Check(s.systemdSysctlArgs, HasLen, 0)
+	}
+}
+
+func (s *sysctlSuite) TestConfigureManagedSysctlsRollback(c *C) {
+	s.mockProcSys(c, map[string]string{
+		"vm.swappiness":        "10",
+		"kernel.panic_on_oops": "0",
+	})
+	c.Assert(os.WriteFile(s.mockSysctlConfPath, []byte("vm.swappiness = 10\n"), 0644), IsNil)
+
+	var calls [][]string
+	restore := systemd.MockSystemdSysctl(func(args ...string) error {
+		calls = append(calls, args)
+		if len(calls) == 1 {
+			return fmt.Errorf("boom")
+		}
+		return nil
+	})
+	defer restore()
+
+	err := configcore.FilesystemOnlyRun(coreDev, &mockConf{
+		state: s.state,
+		conf: map[string]any{
+			"system.kernel.sysctl": map[string]any{
+				"kernel.panic_on_oops": "1",
+			},
+		},
+	})
+	c.Assert(err, ErrorMatches, "cannot apply sysctl configuration: boom")
+	// the previous configuration was restored and applied again
+	c.Check(s.mockSysctlConfPath, testutil.FileEquals, "vm.swappiness = 10\n")
+	prefixes := []string{"--prefix", "kernel.printk", "--prefix", "kernel.panic_on_oops", "--prefix", "vm.swappiness"}
+	c.Check(calls, DeepEquals, [][]string{prefixes, prefixes})
+}
+
+func (s *sysctlSuite) TestConfigureManagedSysctlsDrift(c *C) {
+	logbuf, restore := logger.MockLogger()
+	defer restore()
+
+	s.mockProcSys(c, map[string]string{"net.ipv4.ip_local_port_range": "32768\t60999"})
+	c.Assert(os.WriteFile(s.mockSysctlConfPath, []byte("net.ipv4.ip_local_port_range = 1024 65000\n"), 0644), IsNil)
+
+	conf := map[string]any{
+		"system.kernel.sysctl": map[string]any{
+			"net.ipv4.ip_local_port_range": "1024 65000",
+		},
+	}
+	err := configcore.FilesystemOnlyRun(coreDev, &mockConf{state: s.state, conf: conf})
+	c.Assert(err, IsNil)
+	c.Check(logbuf.String(), testutil.Contains, `WARNING: sysctl net.ipv4.ip_local_port_range was changed externally to "32768 60999", resetting it to "1024 65000"`)
+	// the managed value is applied again
+	c.Check(s.systemdSysctlArgs, DeepEquals, [][]string{
+		{"--prefix", "kernel.printk", "--prefix", "net.ipv4.ip_local_port_range"},
+	})
+	s.systemdSysctlArgs = nil
+	logbuf.Reset()
+
+	// no drift when the kernel has the managed value
+	s.mockProcSys(c, map[string]string{"net.ipv4.ip_local_port_range": "1024\t65000"})
+	err = configcore.FilesystemOnlyRun(coreDev, &mockConf{state: s.state, conf: conf})
+	c.Assert(err, IsNil)
+	c.Check(logbuf.String(), Equals, "")
+	c.Check(s.systemdSysctlArgs, HasLen, 0)
+}
+
+func (s *sysctlSuite) TestFilesystemOnlyApplyManagedSysctls(c *C) {
+	// gadget defaults, the running kernel is not checked
+	conf := configcore.PlainCoreConfig(map[string]any{
+		"system.kernel.sysctl": map[any]any{
+			"vm.swappiness": 10,
+			"net": map[any]any{
+				"core.somaxconn": 1024,
+			},
+		},
+	})
+
+	tmpDir := c.MkDir()
+	c.Assert(configcore.FilesystemOnlyApply(coreDev, tmpDir, conf), IsNil)
+
+	c.Check(filepath.Join(tmpDir, "/etc/sysctl.d/99-snapd.conf"), testutil.FileEquals, "net.core.somaxconn = 1024\n"+
+		"vm.swappiness = 10\n")
+	c.Check(s.systemdSysctlArgs, HasLen, 0)
+}
+
+func (s *sysctlSuite) TestFilesystemOnlyApplyManagedSysctlsInvalid(c *C) {
+	conf := configcore.PlainCoreConfig(map[string]any{
+		"system.kernel.sysctl": map[any]any{
+			"kernel.printk": "4 4 1 7",
+		},
+	})
+
+	tmpDir := c.MkDir()
+	err := configcore.FilesystemOnlyApply(coreDev, tmpDir, conf)
+	c.Assert(err, ErrorMatches, `cannot set sysctl "kernel.printk": use the system.kernel.printk.console-loglevel option instead`)
+	c.Check(filepath.Join(tmpDir, "/etc/sysctl.d/99-snapd.conf"), testutil.FileAbsent)
+}
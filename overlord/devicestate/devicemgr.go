@@ -2400,6 +2400,17 @@ func (m *DeviceManager) SignResponseMessage(accountID, messageID string, status
 	return a.(*asserts.ResponseMessage), nil
 }
 
+// SignWithDeviceKey signs the given data with the device's key. The signature
+// can be verified using the device key carried by the serial assertion.
+func (m *DeviceManager) SignWithDeviceKey(data []byte) ([]byte, error) {
+	privKey, err := m.keyPair()
+	if err != nil {
+		return nil, fmt.Errorf("cannot sign without device key")
+	}
+
+	return asserts.RawSignWithKey(data, privKey)
+}
+
 // Registered returns a channel that is closed when the device is known to have been registered.
 func (m *DeviceManager) Registered() <-chan struct{} {
 	return m.reg
@@ -297,6 +297,7 @@ func delayedCrossMgrInit() {
 	snapstate.CanAutoRefresh = canAutoRefresh
 	snapstate.IsOnMeteredConnection = netutil.IsOnMeteredConnection
 	snapstate.DeviceCtx = DeviceCtx
+	snapstate.DeviceSerial = Serial
 	snapstate.RemodelingChange = RemodelingChange
 	snapstate.SeedRefreshTasks = SeedRefreshTasks
 	snapstate.UpdateSeedRefreshChange = UpdateSeedRefreshChange
@@ -1441,83 +1442,75 @@ type RemodelOptions struct {
 	LocalComponents []snapstate.PathComponent
 }
 
-// Remodel takes a new model assertion and generates a change that
-// takes the device from the old to the new model or an error if the
-// transition is not possible.
-//
-// TODO:
-//   - Check estimated disk size delta
-//   - Check all relevant snaps exist in new store
-//     (need to check that even unchanged snaps are accessible)
-//   - Make sure this works with Core 20 as well, in the Core 20 case
-//     we must enforce the default-channels from the model as well
-func Remodel(st *state.State, new *asserts.Model, opts RemodelOptions) (*state.Change, error) {
+// checkRemodel checks that the device can be remodeled to the new model
+// and returns the current model and the kind of the remodel.
+func checkRemodel(st *state.State, new *asserts.Model, opts RemodelOptions) (current *asserts.Model, remodelKind RemodelKind, err error) {
 	var seeded bool
-	err := st.Get("seeded", &seeded)
+	err = st.Get("seeded", &seeded)
 	if err != nil && !errors.Is(err, state.ErrNoState) {
-		return nil, err
+		return nil, 0, err
 	}
 	if !seeded {
-		return nil, fmt.Errorf("cannot remodel until fully seeded")
+		return nil, 0, fmt.Errorf("cannot remodel until fully seeded")
 	}
 
 	if !opts.Offline && (len(opts.LocalSnaps) > 0 || len(opts.LocalComponents) > 0) {
-		return nil, errors.New("cannot do an online remodel with provided local snaps or components")
+		return nil, 0, errors.New("cannot do an online remodel with provided local snaps or components")
 	}
 
 	for _, ls := range opts.LocalSnaps {
 		if ls.Components != nil || ls.InstanceName != "" || ls.RevOpts != (snapstate.RevisionOptions{}) {
-			return nil, errors.New("internal error: locally provided snaps must only provide path and side info")
+			return nil, 0, errors.New("internal error: locally provided snaps must only provide path and side info")
 		}
 	}
 
-	current, err := findModel(st)
+	current, err = findModel(st)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	prevRev, err := findKnownRevisionOfModel(st, new)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	if new.Revision() < prevRev {
-		return nil, fmt.Errorf("cannot remodel to older revision %d of model %s/%s than last revision %d known to the device", new.Revision(), new.BrandID(), new.Model(), prevRev)
+		return nil, 0, fmt.Errorf("cannot remodel to older revision %d of model %s/%s than last revision %d known to the device", new.Revision(), new.BrandID(), new.Model(), prevRev)
 	}
 
 	// TODO: we need dedicated assertion language to permit for
 	// model transitions before we allow cross vault
 	// transitions.
 
-	remodelKind := ClassifyRemodel(current, new)
+	remodelKind = ClassifyRemodel(current, new)
 
 	if _, err := findSerial(st, nil); err != nil {
 		if !errors.Is(err, state.ErrNoState) {
-			return nil, err
+			return nil, 0, err
 		}
 
 		if opts.Offline && remodelKind == UpdateRemodel {
 			// it is allowed to remodel without serial for
 			// offline remodels that are update only
 		} else {
-			return nil, fmt.Errorf("cannot remodel without a serial")
+			return nil, 0, fmt.Errorf("cannot remodel without a serial")
 		}
 	}
 
 	if current.Series() != new.Series() {
-		return nil, fmt.Errorf("cannot remodel to different series yet")
+		return nil, 0, fmt.Errorf("cannot remodel to different series yet")
 	}
 
 	devCtx, err := DeviceCtx(st, nil, nil)
 	if err != nil {
-		return nil, fmt.Errorf("cannot get device context: %v", err)
+		return nil, 0, fmt.Errorf("cannot get device context: %v", err)
 	}
 
 	if devCtx.IsClassicBoot() {
-		return nil, fmt.Errorf("cannot remodel from classic (non-hybrid) model")
+		return nil, 0, fmt.Errorf("cannot remodel from classic (non-hybrid) model")
 	}
 
 	if current.Classic() != new.Classic() {
-		return nil, fmt.Errorf("cannot remodel across classic and non-classic models")
+		return nil, 0, fmt.Errorf("cannot remodel across classic and non-classic models")
 	}
 
 	// TODO:UC20: ensure we never remodel to a lower
@@ -1527,37 +1520,43 @@ func Remodel(st *state.State, new *asserts.Model, opts RemodelOptions) (*state.C
 	if current.Grade() != new.Grade() {
 		if current.Grade() == asserts.ModelGradeUnset && new.Grade() != asserts.ModelGradeUnset {
 			// a case of pre-UC20 -> UC20 remodel
-			return nil, fmt.Errorf("cannot remodel from pre-UC20 to UC20+ models")
+			return nil, 0, fmt.Errorf("cannot remodel from pre-UC20 to UC20+ models")
 		}
-		return nil, fmt.Errorf("cannot remodel from grade %v to grade %v", current.Grade(), new.Grade())
+		return nil, 0, fmt.Errorf("cannot remodel from grade %v to grade %v", current.Grade(), new.Grade())
 	}
 
 	if new.Base() == "" && current.Base() != "" {
-		return nil, errors.New("cannot remodel from UC18+ (using snapd snap) system back to UC16 system (using core snap)")
+		return nil, 0, errors.New("cannot remodel from UC18+ (using snapd snap) system back to UC16 system (using core snap)")
 	}
 
 	// TODO: should we restrict remodel from one arch to another?
 	// There are valid use-cases here though, i.e. amd64 machine that
 	// remodels itself to/from i386 (if the HW can do both 32/64 bit)
 	if current.Architecture() != new.Architecture() {
-		return nil, fmt.Errorf("cannot remodel to different architectures yet")
+		return nil, 0, fmt.Errorf("cannot remodel to different architectures yet")
 	}
 
 	// calculate snap differences between the two models
 	// FIXME: this needs work to switch from core->bases
 	if current.Base() == "" && new.Base() != "" {
-		return nil, fmt.Errorf("cannot remodel from core to bases yet")
+		return nil, 0, fmt.Errorf("cannot remodel from core to bases yet")
 	}
 
 	// Do we do this only for the more complicated cases (anything
 	// more than adding required-snaps really)?
 	if err := snapstate.CheckChangeConflictRunExclusively(st, "remodel"); err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
+	return current, remodelKind, nil
+}
+
+// remodelTaskSets returns the context of a remodel and the task sets that
+// carry it out, they are not added to any change yet.
+func remodelTaskSets(st *state.State, current, new *asserts.Model, remodelKind RemodelKind, opts RemodelOptions) (remodelContext, []*state.TaskSet, error) {
 	remodCtx, err := remodelCtx(st, current, new)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	var tss []*state.TaskSet
@@ -1568,7 +1567,7 @@ func Remodel(st *state.State, new *asserts.Model, opts RemodelOptions) (*state.C
 			// assertion has been provided by a file. To support
 			// this case, we will pass the snaps/paths by setting
 			// local-{snaps,paths} in the task.
-			return nil, fmt.Errorf("cannot remodel offline to different brand ID / model yet")
+			return nil, nil, fmt.Errorf("cannot remodel offline to different brand ID / model yet")
 		}
 		requestSerial := st.NewTask("request-serial", i18n.G("Request new device serial"))
 
@@ -1579,14 +1578,14 @@ func Remodel(st *state.State, new *asserts.Model, opts RemodelOptions) (*state.C
 	case StoreSwitchRemodel:
 		sto := remodCtx.Store()
 		if sto == nil {
-			return nil, fmt.Errorf("internal error: a store switch remodeling should have built a store")
+			return nil, nil, fmt.Errorf("internal error: a store switch remodeling should have built a store")
 		}
 		// ensure a new session accounting for the new brand store
 		st.Unlock()
 		err := sto.EnsureDeviceSession()
 		st.Lock()
 		if err != nil {
-			return nil, fmt.Errorf("cannot get a store session based on the new model assertion: %v", err)
+			return nil, nil, fmt.Errorf("cannot get a store session based on the new model assertion: %v", err)
 		}
 		fallthrough
 	case UpdateRemodel:
@@ -1597,10 +1596,34 @@ func Remodel(st *state.State, new *asserts.Model, opts RemodelOptions) (*state.C
 		var err error
 		tss, err = remodelTasks(context.TODO(), st, current, new, remodCtx, "", opts)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
+	return remodCtx, tss, nil
+}
+
+// Remodel takes a new model assertion and generates a change that
+// takes the device from the old to the new model or an error if the
+// transition is not possible.
+//
+// TODO:
+//   - Check estimated disk size delta
+//   - Check all relevant snaps exist in new store
+//     (need to check that even unchanged snaps are accessible)
+//   - Make sure this works with Core 20 as well, in the Core 20 case
+//     we must enforce the default-channels from the model as well
+func Remodel(st *state.State, new *asserts.Model, opts RemodelOptions) (*state.Change, error) {
+	current, remodelKind, err := checkRemodel(st, new, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	remodCtx, tss, err := remodelTaskSets(st, current, new, remodelKind, opts)
+	if err != nil {
+		return nil, err
+	}
+
 	// we potentially released the lock a couple of times here:
 	// make sure the current model is essentially the same as when
 	// we started
@@ -1641,6 +1664,94 @@ func Remodel(st *state.State, new *asserts.Model, opts RemodelOptions) (*state.C
 	return chg, nil
 }
 
+// RemodelSnapAction designates what a remodel does to a snap.
+type RemodelSnapAction string
+
+const (
+	RemodelSnapInstall       RemodelSnapAction = "install"
+	RemodelSnapRefresh       RemodelSnapAction = "refresh"
+	RemodelSnapSwitchChannel RemodelSnapAction = "switch-channel"
+)
+
+// RemodelPlannedSnap describes what a remodel would do to a snap.
+type RemodelPlannedSnap struct {
+	Name     string
+	Action   RemodelSnapAction
+	Channel  string
+	Revision snap.Revision
+}
+
+// RemodelPlan describes what a remodel would do to the device.
+type RemodelPlan struct {
+	Kind         RemodelKind
+	CurrentModel *asserts.Model
+	NewModel     *asserts.Model
+	// Snaps are the snaps that would be installed, refreshed or switched
+	// to another channel. For re-registration remodels they are only known
+	// once the device has a new serial, so none are listed.
+	Snaps []RemodelPlannedSnap
+}
+
+// RemodelPreview performs the same checks as Remodel and returns what a
+// remodel to the new model would do, without starting it.
+//
+// The tasks computed to build the plan are not part of any change, they
+// never run and get pruned like any other unlinked task.
+func RemodelPreview(st *state.State, new *asserts.Model, opts RemodelOptions) (*RemodelPlan, error) {
+	current, remodelKind, err := checkRemodel(st, new, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	_, tss, err := remodelTaskSets(st, current, new, remodelKind, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &RemodelPlan{
+		Kind:         remodelKind,
+		CurrentModel: current,
+		NewModel:     new,
+	}
+	seen := make(map[string]bool)
+	for _, ts := range tss {
+		for _, t := range ts.Tasks() {
+			if !t.Has("snap-setup") {
+				continue
+			}
+			snapsup, err := snapstate.TaskSnapSetup(t)
+			if err != nil {
+				return nil, err
+			}
+			name := snapsup.InstanceName()
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+
+			planned := RemodelPlannedSnap{
+				Name:     name,
+				Action:   RemodelSnapInstall,
+				Channel:  snapsup.Channel,
+				Revision: snapsup.Revision(),
+			}
+			var snapst snapstate.SnapState
+			err = snapstate.Get(st, name, &snapst)
+			if err != nil && !errors.Is(err, state.ErrNoState) {
+				return nil, err
+			}
+			if snapst.IsInstalled() {
+				planned.Action = RemodelSnapRefresh
+				if snapst.Current == planned.Revision {
+					planned.Action = RemodelSnapSwitchChannel
+				}
+			}
+			plan.Snaps = append(plan.Snaps, planned)
+		}
+	}
+	return plan, nil
+}
+
 // RemodelingChange returns a remodeling change in progress, if there is one
 func RemodelingChange(st *state.State) *state.Change {
 	for _, chg := range st.Changes() {
@@ -1029,7 +1029,9 @@ func (s *deviceMgrInstallAPISuite) testInstallSetupStorageEncryption(c *C, isSup
 	_, ok := apiData["encrypted-devices"]
 	c.Check(ok, Equals, true)
 	// Check that state has been stored in the cache
-	c.Check(devicestate.GetEncryptionSetupDataFromCache(s.state, label), NotNil)
+	encSetupData := devicestate.GetEncryptionSetupDataFromCache(s.state, label)
+	c.Assert(encSetupData, NotNil)
+	c.Check(encSetupData.SealingMethod(), Equals, device.SealingMethodTPM)
 	// Cached auth options are cleaned
 	c.Check(s.state.Cached(devicestate.VolumesAuthOptionsKeyByLabel(label)), IsNil)
 }
@@ -3554,6 +3554,34 @@ func (s *installStepSuite) testDeviceManagerInstallFinishTasksAndChange(c *C, op
 	}
 }
 
+func (s *installStepSuite) TestDeviceManagerInstallFinishMultipleVolumes(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	onVolumes := map[string]*gadget.Volume{
+		"pc": {
+			Bootloader:     "grub",
+			AssignedDevice: "/dev/vda",
+			Structure:      []gadget.VolumeStructure{{Name: "ubuntu-data", Device: "/dev/vda4"}},
+		},
+		"extra": {
+			AssignedDevice: "/dev/vdb",
+			Structure:      []gadget.VolumeStructure{{Name: "extra-data", Device: "/dev/vdb1"}},
+		},
+	}
+	chg, err := devicestate.InstallFinish(s.state, "1234", onVolumes, nil)
+	c.Assert(err, IsNil)
+	c.Assert(chg.Tasks(), HasLen, 1)
+
+	// the target device of each volume is kept
+	var onVols map[string]*gadget.Volume
+	c.Assert(chg.Tasks()[0].Get("on-volumes", &onVols), IsNil)
+	c.Assert(onVols, HasLen, 2)
+	c.Check(onVols["pc"].AssignedDevice, Equals, "/dev/vda")
+	c.Check(onVols["extra"].AssignedDevice, Equals, "/dev/vdb")
+	c.Check(onVols["extra"].Structure[0].Device, Equals, "/dev/vdb1")
+}
+
 func (s *installStepSuite) TestDeviceManagerInstallFinishRunthrough(c *C) {
 	st := s.state
 	st.Lock()
@@ -3740,3 +3768,152 @@ func (s *installStepSuite) TestGeneratePreInstallRecoveryKeySetupNotCalledError(
 	c.Assert(err, ErrorMatches, "storage encryption setup step was not called")
 	c.Check(rkey, DeepEquals, keys.RecoveryKey{})
 }
+
+func (s *installStepSuite) TestPreviewInstallSealingTPM(c *C) {
+	volumesAuth := &device.VolumesAuthOptions{Mode: device.AuthModePassphrase, Passphrase: "1234"}
+	rkey := &keys.RecoveryKey{'r', 'e', 'c', 'o', 'v', 'e', 'r', 'y'}
+	defer devicestate.MockEncryptionSetupDataInCache(s.state, "20250528", rkey, volumesAuth, preinstallCheckContext, nil)()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	devicestate.GetEncryptionSetupDataFromCache(s.state, "20250528").SetSealingMethod(device.SealingMethodTPM)
+
+	tpmCheckCalls := 0
+	defer devicestate.MockSecbootCheckTPMKeySealingSupported(func(mode secboot.TPMProvisionMode) error {
+		tpmCheckCalls++
+		c.Check(mode, Equals, secboot.TPMProvisionFull)
+		return nil
+	})()
+	checkResult := &secboot.PreinstallCheckResult{}
+	defer devicestate.MockSecbootPreinstallCheckResult(func(cc *secboot.PreinstallCheckContext) (*secboot.PreinstallCheckResult, error) {
+		c.Check(cc, Equals, preinstallCheckContext)
+		return checkResult, nil
+	})()
+	pcrProfile := &secboot.PCRProfilePreview{
+		PCRAlg: "sha256",
+		PCRs:   []int{7, 12},
+	}
+	defer devicestate.MockSecbootPreviewPCRProfile(func(cr *secboot.PreinstallCheckResult) (*secboot.PCRProfilePreview, error) {
+		c.Check(cr, Equals, checkResult)
+		return pcrProfile, nil
+	})()
+
+	preview, err := devicestate.PreviewInstallSealing(s.state, "20250528")
+	c.Assert(err, IsNil)
+	c.Check(preview, DeepEquals, &client.InstallSealingPreview{
+		SealingMethod:   device.SealingMethodTPM,
+		VolumesAuthMode: device.AuthModePassphrase,
+		RecoveryKey:     true,
+		PCRProfile:      pcrProfile,
+	})
+	c.Check(tpmCheckCalls, Equals, 1)
+}
+
+func (s *installStepSuite) TestPreviewInstallSealingTPMNoCheckContext(c *C) {
+	defer devicestate.MockEncryptionSetupDataInCache(s.state, "20250528", nil, nil, nil, nil)()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	devicestate.GetEncryptionSetupDataFromCache(s.state, "20250528").SetSealingMethod(device.SealingMethodTPM)
+
+	defer devicestate.MockSecbootCheckTPMKeySealingSupported(func(mode secboot.TPMProvisionMode) error {
+		return nil
+	})()
+	defer devicestate.MockSecbootPreinstallCheckResult(func(cc *secboot.PreinstallCheckContext) (*secboot.PreinstallCheckResult, error) {
+		c.Fatalf("unexpected call")
+		return nil, nil
+	})()
+	pcrProfile := &secboot.PCRProfilePreview{
+		PCRAlg: "sha256",
+		PCRs:   []int{4, 7, 12},
+	}
+	defer devicestate.MockSecbootPreviewPCRProfile(func(cr *secboot.PreinstallCheckResult) (*secboot.PCRProfilePreview, error) {
+		c.Check(cr, IsNil)
+		return pcrProfile, nil
+	})()
+
+	preview, err := devicestate.PreviewInstallSealing(s.state, "20250528")
+	c.Assert(err, IsNil)
+	c.Check(preview, DeepEquals, &client.InstallSealingPreview{
+		SealingMethod: device.SealingMethodTPM,
+		PCRProfile:    pcrProfile,
+	})
+}
+
+func (s *installStepSuite) TestPreviewInstallSealingFDESetupHook(c *C) {
+	defer devicestate.MockEncryptionSetupDataInCache(s.state, "20250528", nil, nil, nil, nil)()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	devicestate.GetEncryptionSetupDataFromCache(s.state, "20250528").SetSealingMethod(device.SealingMethodFDESetupHook)
+
+	defer devicestate.MockSecbootCheckTPMKeySealingSupported(func(mode secboot.TPMProvisionMode) error {
+		c.Fatalf("unexpected call")
+		return nil
+	})()
+	defer devicestate.MockSecbootPreviewPCRProfile(func(cr *secboot.PreinstallCheckResult) (*secboot.PCRProfilePreview, error) {
+		c.Fatalf("unexpected call")
+		return nil, nil
+	})()
+
+	preview, err := devicestate.PreviewInstallSealing(s.state, "20250528")
+	c.Assert(err, IsNil)
+	c.Check(preview, DeepEquals, &client.InstallSealingPreview{
+		SealingMethod: device.SealingMethodFDESetupHook,
+	})
+}
+
+func (s *installStepSuite) TestPreviewInstallSealingTPMUnsuitable(c *C) {
+	defer devicestate.MockEncryptionSetupDataInCache(s.state, "20250528", nil, nil, nil, nil)()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	devicestate.GetEncryptionSetupDataFromCache(s.state, "20250528").SetSealingMethod(device.SealingMethodTPM)
+
+	defer devicestate.MockSecbootCheckTPMKeySealingSupported(func(mode secboot.TPMProvisionMode) error {
+		return errors.New("TPM device is not enabled")
+	})()
+	defer devicestate.MockSecbootPreviewPCRProfile(func(cr *secboot.PreinstallCheckResult) (*secboot.PCRProfilePreview, error) {
+		c.Fatalf("unexpected call")
+		return nil, nil
+	})()
+
+	preview, err := devicestate.PreviewInstallSealing(s.state, "20250528")
+	c.Assert(err, ErrorMatches, "cannot seal keys with the TPM: TPM device is not enabled")
+	c.Check(preview, IsNil)
+}
+
+func (s *installStepSuite) TestPreviewInstallSealingPCRProfileError(c *C) {
+	defer devicestate.MockEncryptionSetupDataInCache(s.state, "20250528", nil, nil, preinstallCheckContext, nil)()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	devicestate.GetEncryptionSetupDataFromCache(s.state, "20250528").SetSealingMethod(device.SealingMethodTPM)
+
+	defer devicestate.MockSecbootCheckTPMKeySealingSupported(func(mode secboot.TPMProvisionMode) error {
+		return nil
+	})()
+	defer devicestate.MockSecbootPreinstallCheckResult(func(cc *secboot.PreinstallCheckContext) (*secboot.PreinstallCheckResult, error) {
+		return &secboot.PreinstallCheckResult{}, nil
+	})()
+	defer devicestate.MockSecbootPreviewPCRProfile(func(cr *secboot.PreinstallCheckResult) (*secboot.PCRProfilePreview, error) {
+		return nil, errors.New("PCR 7 is required, but is unsupported")
+	})()
+
+	preview, err := devicestate.PreviewInstallSealing(s.state, "20250528")
+	c.Assert(err, ErrorMatches, "cannot preview PCR profile: PCR 7 is required, but is unsupported")
+	c.Check(preview, IsNil)
+}
+
+func (s *installStepSuite) TestPreviewInstallSealingSetupNotCalledError(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+	preview, err := devicestate.PreviewInstallSealing(s.state, "20250528")
+	c.Assert(err, ErrorMatches, "storage encryption setup step was not called")
+	c.Check(preview, IsNil)
+}
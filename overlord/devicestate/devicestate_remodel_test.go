@@ -994,6 +994,133 @@ func (s *deviceMgrRemodelSuite) TestRemodelLessRequiredSnaps(c *C) {
 	c.Assert(tSetModel.Summary(), Equals, "Set new model assertion")
 }
 
+func (s *deviceMgrRemodelSuite) TestRemodelPreview(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+	s.state.Set("seeded", true)
+	s.state.Set("refresh-privacy-key", "some-privacy-key")
+
+	snapstatetest.InstallEssentialSnaps(c, s.state, "core18", nil, nil)
+
+	restore := devicestate.MockSnapstateUpdateOne(func(ctx context.Context, st *state.State, goal snapstate.UpdateGoal, filter func(*snap.Info, *snapstate.SnapState) bool, opts snapstate.Options) (*state.TaskSet, error) {
+		g := goal.(*storeUpdateGoalRecorder)
+		name := g.snaps[0].InstanceName
+
+		download := s.state.NewTask("fake-download", fmt.Sprintf("Download %s", name))
+		download.Set("snap-setup", &snapstate.SnapSetup{
+			SideInfo: &snap.SideInfo{
+				RealName: name,
+				Revision: snap.R(3),
+			},
+			Channel: "latest/stable",
+		})
+		validate := s.state.NewTask("validate-snap", fmt.Sprintf("Validate %s", name))
+		validate.WaitFor(download)
+		validate.Set("snap-setup-task", download.ID())
+		install := s.state.NewTask("fake-install", fmt.Sprintf("Install %s", name))
+		install.WaitFor(validate)
+		install.Set("snap-setup-task", download.ID())
+		ts := state.NewTaskSet(download, validate, install)
+		ts.MarkEdge(validate, snapstate.LastBeforeLocalModificationsEdge)
+		return ts, nil
+	})
+	defer restore()
+
+	s.makeModelAssertionInState(c, "canonical", "pc-model", map[string]any{
+		"architecture": "amd64",
+		"kernel":       "pc-kernel",
+		"gadget":       "pc",
+		"base":         "core18",
+	})
+	s.makeSerialAssertionInState(c, "canonical", "pc-model", "1234")
+	devicestatetest.SetDevice(s.state, &auth.DeviceState{
+		Brand:  "canonical",
+		Model:  "pc-model",
+		Serial: "1234",
+	})
+
+	new := s.brands.Model("canonical", "pc-model", map[string]any{
+		"architecture":   "amd64",
+		"kernel":         "pc-kernel",
+		"gadget":         "pc",
+		"base":           "core18",
+		"required-snaps": []any{"new-required-snap-1", "new-required-snap-2"},
+		"revision":       "1",
+	})
+	plan, err := devicestate.RemodelPreview(s.state, new, devicestate.RemodelOptions{})
+	c.Assert(err, IsNil)
+	c.Check(plan.Kind, Equals, devicestate.UpdateRemodel)
+	c.Check(plan.CurrentModel.Revision(), Equals, 0)
+	c.Check(plan.NewModel, DeepEquals, new)
+	c.Check(plan.Snaps, DeepEquals, []devicestate.RemodelPlannedSnap{{
+		Name:     "new-required-snap-1",
+		Action:   devicestate.RemodelSnapInstall,
+		Channel:  "latest/stable",
+		Revision: snap.R(3),
+	}, {
+		Name:     "new-required-snap-2",
+		Action:   devicestate.RemodelSnapInstall,
+		Channel:  "latest/stable",
+		Revision: snap.R(3),
+	}})
+
+	// nothing was started
+	c.Check(s.state.Changes(), HasLen, 0)
+	c.Check(devicestate.RemodelingChange(s.state), IsNil)
+}
+
+func (s *deviceMgrRemodelSuite) TestRemodelPreviewRereg(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+	s.state.Set("seeded", true)
+
+	s.makeModelAssertionInState(c, "canonical", "pc-model", map[string]any{
+		"architecture": "amd64",
+		"kernel":       "pc-kernel",
+		"gadget":       "pc",
+		"base":         "core18",
+	})
+	s.makeSerialAssertionInState(c, "canonical", "pc-model", "orig-serial")
+	devicestatetest.SetDevice(s.state, &auth.DeviceState{
+		Brand:           "canonical",
+		Model:           "pc-model",
+		Serial:          "orig-serial",
+		SessionMacaroon: "old-session",
+	})
+
+	new := s.brands.Model("canonical", "rereg-model", map[string]any{
+		"architecture":   "amd64",
+		"kernel":         "pc-kernel",
+		"gadget":         "pc",
+		"base":           "core18",
+		"required-snaps": []any{"new-required-snap-1"},
+	})
+	s.newFakeStore = func(devBE storecontext.DeviceBackend) snapstate.StoreService {
+		return nil
+	}
+
+	plan, err := devicestate.RemodelPreview(s.state, new, devicestate.RemodelOptions{})
+	c.Assert(err, IsNil)
+	c.Check(plan.Kind, Equals, devicestate.ReregRemodel)
+	// snaps are only known after re-registration
+	c.Check(plan.Snaps, HasLen, 0)
+	c.Check(s.state.Changes(), HasLen, 0)
+}
+
+func (s *deviceMgrRemodelSuite) TestRemodelPreviewUnhappyNotSeeded(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+	s.state.Set("seeded", false)
+
+	newModel := s.brands.Model("canonical", "pc", map[string]any{
+		"architecture": "amd64",
+		"kernel":       "pc-kernel",
+		"gadget":       "pc",
+	})
+	_, err := devicestate.RemodelPreview(s.state, newModel, devicestate.RemodelOptions{})
+	c.Assert(err, ErrorMatches, "cannot remodel until fully seeded")
+}
+
 type freshSessionStore struct {
 	storetest.Store
 
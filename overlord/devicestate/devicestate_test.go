@@ -3970,6 +3970,34 @@ func (s *deviceMgrSuite) TestSignResponseMessageInvalid(c *C) {
 	)
 }
 
+func (s *deviceMgrSuite) TestSignWithDeviceKeyNoKey(c *C) {
+	s.setPCModelInState(c)
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.makeSerialAssertionInState(c, "canonical", "pc", "serialserialserial")
+
+	_, err := s.mgr.SignWithDeviceKey([]byte("data"))
+	c.Assert(err, ErrorMatches, "cannot sign without device key")
+}
+
+func (s *deviceMgrSuite) TestSignWithDeviceKeyOK(c *C) {
+	s.setPCModelInState(c)
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	serial := s.makeSerialAssertionInState(c, "canonical", "pc", "serialserialserial")
+	s.addKeyToManagerInState(c)
+
+	sig, err := s.mgr.SignWithDeviceKey([]byte("data"))
+	c.Assert(err, IsNil)
+
+	err = asserts.RawVerifyWithKey([]byte("data"), sig, serial.DeviceKey())
+	c.Assert(err, IsNil)
+	err = asserts.RawVerifyWithKey([]byte("other"), sig, serial.DeviceKey())
+	c.Assert(err, NotNil)
+}
+
 type myStateDeviceInitialized struct {
 	called int
 }
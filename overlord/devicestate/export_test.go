@@ -738,6 +738,18 @@ func MockFdestateGetRecoveryKey(f func(st *state.State, keyID string) (rkey keys
 	return testutil.Mock(&fdestateGetRecoveryKey, f)
 }
 
+func MockSecbootCheckTPMKeySealingSupported(f func(mode secboot.TPMProvisionMode) error) (restore func()) {
+	return testutil.Mock(&secbootCheckTPMKeySealingSupported, f)
+}
+
+func MockSecbootPreinstallCheckResult(f func(cc *secboot.PreinstallCheckContext) (*secboot.PreinstallCheckResult, error)) (restore func()) {
+	return testutil.Mock(&secbootPreinstallCheckResult, f)
+}
+
+func MockSecbootPreviewPCRProfile(f func(checkResult *secboot.PreinstallCheckResult) (*secboot.PCRProfilePreview, error)) (restore func()) {
+	return testutil.Mock(&secbootPreviewPCRProfile, f)
+}
+
 func MockFdestateGetKeyslots(f func(st *state.State, keyslotRefs []fdestate.KeyslotRef) (keyslots []fdestate.Keyslot, missingRefs []fdestate.KeyslotRef, err error)) (restore func()) {
 	return testutil.Mock(&fdestateGetKeyslots, f)
 }
@@ -844,8 +856,8 @@ func MockSnapstateKernelInfo(f func(st *state.State, deviceCtx snapstate.DeviceC
 	return testutil.Mock(&snapstateKernelInfo, f)
 }
 
-func MockKeysNewProtectorKey(f func() (keys.ProtectorKey, error)) (restore func()) {
-	return testutil.Mock(&keysNewProtectorKey, f)
+func MockKeysNewPrimaryKey(f func() (keys.PrimaryKey, error)) (restore func()) {
+	return testutil.Mock(&keysNewPrimaryKey, f)
 }
 
 func MockKeysCreateProtectedKey(f func(k keys.ProtectorKey, primaryKey []byte) (*keys.PlainKey, []byte, []byte, error)) (restore func()) {
@@ -86,6 +86,9 @@ var (
 	secbootTemporaryNameOldKeys          = secboot.TemporaryNameOldKeys
 	fdestateGetRecoveryKey               = fdestate.GetRecoveryKey
 	fdestateGenerateRecoveryKey          = fdestate.GenerateRecoveryKey
+	secbootCheckTPMKeySealingSupported   = secboot.CheckTPMKeySealingSupported
+	secbootPreinstallCheckResult         = (*secboot.PreinstallCheckContext).CheckResult
+	secbootPreviewPCRProfile             = secboot.PreviewPCRProfile
 
 	installLogicPrepareRunSystemData = installLogic.PrepareRunSystemData
 	copyInstallModeHostname          = copyInstallModeHostnameImpl
@@ -1509,6 +1512,7 @@ func (m *DeviceManager) doInstallSetupStorageEncryption(t *state.Task, _ *tomb.T
 	if err != nil {
 		return err
 	}
+	encryptionSetupData.SetSealingMethod(installSealingMethod(systemAndSeeds.InfosByType[snap.TypeKernel]))
 
 	// Store created devices in the change so they can be accessed from the installer
 	apiData := map[string]any{
@@ -1657,6 +1661,18 @@ func rotateSaveKeyAndDeleteOldKeys(saveMntPnt string) error {
 	return nil
 }
 
+// installSealingMethod returns the method that will be used to seal the
+// keys of the encrypted volumes when installing with the given kernel.
+func installSealingMethod(kernelInfo *snap.Info) device.SealingMethod {
+	if _, ok := kernelInfo.Hooks["fde-setup"]; ok {
+		return device.SealingMethodFDESetupHook
+	}
+	if secboot.FDEOpteeTAPresent() {
+		return device.SealingMethodFDESetupHook
+	}
+	return device.SealingMethodTPM
+}
+
 // GeneratePreInstallRecoveryKey generates a recovery key and embeds
 // its corresponding id in the storage encryption setup data.
 //
@@ -1694,3 +1710,55 @@ func GeneratePreInstallRecoveryKey(st *state.State, label string) (rkey keys.Rec
 
 	return rkey, err
 }
+
+// PreviewInstallSealing reports how the keys of the encrypted volumes will
+// be sealed in the install finish step. When sealing with the TPM, it
+// checks that the TPM is suitable for sealing and reports the PCR profile
+// the keys will be bound to.
+//
+// Note: InstallSetupStorageEncryption must be called before calling
+// this helper.
+func PreviewInstallSealing(st *state.State, label string) (*client.InstallSealingPreview, error) {
+	cached := st.Cached(encryptionSetupDataKey{label})
+	if cached == nil {
+		return nil, fmt.Errorf("storage encryption setup step was not called")
+	}
+
+	encryptSetupData, ok := cached.(*install.EncryptionSetupData)
+	if !ok {
+		return nil, fmt.Errorf("internal error: wrong data type under encryptionSetupDataKey")
+	}
+
+	preview := &client.InstallSealingPreview{
+		SealingMethod: encryptSetupData.SealingMethod(),
+		RecoveryKey:   encryptSetupData.RecoveryKey() != nil,
+	}
+	if volumesAuth := encryptSetupData.VolumesAuth(); volumesAuth != nil {
+		preview.VolumesAuthMode = volumesAuth.Mode
+	}
+	if preview.SealingMethod != device.SealingMethodTPM {
+		return preview, nil
+	}
+
+	if err := secbootCheckTPMKeySealingSupported(secboot.TPMProvisionFull); err != nil {
+		return nil, fmt.Errorf("cannot seal keys with the TPM: %v", err)
+	}
+
+	var checkResult *secboot.PreinstallCheckResult
+	if checkContext := encryptSetupData.PreinstallCheckContext(); checkContext != nil {
+		var err error
+		checkResult, err = secbootPreinstallCheckResult(checkContext)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get preinstall check result: %v", err)
+		}
+	}
+	pcrProfile, err := secbootPreviewPCRProfile(checkResult)
+	if err != nil {
+		return nil, fmt.Errorf("cannot preview PCR profile: %v", err)
+	}
+	preview.PCRProfile = pcrProfile
+
+	logger.Noticef("keys for system %q will be sealed with the TPM to %s PCRs %v", label, pcrProfile.PCRAlg, pcrProfile.PCRs)
+
+	return preview, nil
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/snapcore/snapd/asserts"
 	"github.com/snapcore/snapd/boot"
 	"github.com/snapcore/snapd/gadget"
+	"github.com/snapcore/snapd/i18n"
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/overlord/assertstate"
 	"github.com/snapcore/snapd/overlord/snapstate"
@@ -310,6 +311,10 @@ func (m *DeviceManager) doPrepareRemodeling(t *state.Task, tmb *tomb.Tomb) error
 	if sto == nil {
 		return fmt.Errorf("internal error: re-registration remodeling should have built a store")
 	}
+	// the state is unlocked while talking to the store, report what is
+	// going on meanwhile
+	const prepareRemodelingSteps = 2
+	t.SetProgress(i18n.G("Getting a store session for the new model"), 0, prepareRemodelingSteps)
 	// ensure a new session accounting for the new brand/model
 	st.Unlock()
 	err = sto.EnsureDeviceSession()
@@ -320,6 +325,7 @@ func (m *DeviceManager) doPrepareRemodeling(t *state.Task, tmb *tomb.Tomb) error
 
 	chgID := t.Change().ID()
 
+	t.SetProgress(i18n.G("Computing the snaps to install or refresh"), 1, prepareRemodelingSteps)
 	tss, err := remodelTasks(tmb.Context(nil), st, current, remodCtx.Model(), remodCtx, chgID, RemodelOptions{})
 	if err != nil {
 		return err
@@ -330,6 +336,7 @@ func (m *DeviceManager) doPrepareRemodeling(t *state.Task, tmb *tomb.Tomb) error
 		allTs.AddAll(ts)
 	}
 	snapstate.InjectTasks(t, allTs)
+	t.SetProgress(i18n.G("Prepared remodeling"), prepareRemodelingSteps, prepareRemodelingSteps)
 
 	st.EnsureBefore(0)
 	t.SetStatus(state.DoneStatus)
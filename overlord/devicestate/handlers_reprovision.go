@@ -29,6 +29,7 @@ import (
 
 	"github.com/snapcore/snapd/boot"
 	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/gadget"
 	"github.com/snapcore/snapd/gadget/device"
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/overlord/fdestate"
@@ -53,7 +54,7 @@ var (
 	secbootSaveCheckResult               = (*secboot.PreinstallCheckContext).SaveCheckResult
 	secbootCheckResult                   = (*secboot.PreinstallCheckContext).CheckResult
 
-	keysNewProtectorKey    = keys.NewProtectorKey
+	keysNewPrimaryKey      = keys.NewPrimaryKey
 	keysCreateProtectedKey = (keys.ProtectorKey).CreateProtectedKey
 	keysPlainKeyWrite      = (*keys.PlainKey).Write
 	keysSaveProtectorKey   = func(key keys.ProtectorKey, path string) error { return key.SaveToFile(path) }
@@ -344,15 +345,21 @@ func (m *DeviceManager) doReprovision(t *state.Task, _ *tomb.Tomb) error {
 		return err
 	}
 
-	protectorKey, err := keysNewProtectorKey()
+	// Steps:
+	//  2. Generate primary key
+	//  3. Create plainkey protector key (and save to keyslot)
+	newPrimaryKey, err := keysNewPrimaryKey()
+	if err != nil {
+		return err
+	}
+	// the protector key is derived from the primary key so that
+	// ubuntu-save can still be unlocked if the key file gets lost
+	protectorKey, err := newPrimaryKey.DeriveProtectorKey(gadget.SystemSave)
 	if err != nil {
 		return err
 	}
 
-	// Steps:
-	//  2. Generate primary key
-	//  3. Create plainkey protector key (and save to keyslot)
-	plainKey, primaryKey, unlockPlainKey, err := keysCreateProtectedKey(protectorKey, nil)
+	plainKey, primaryKey, unlockPlainKey, err := keysCreateProtectedKey(protectorKey, newPrimaryKey)
 	if err != nil {
 		return err
 	}
@@ -601,6 +601,10 @@ func (s *deviceMgrSuite) TestDoPrepareRemodeling(c *C) {
 	c.Check(tl[1].Kind(), Equals, "fake-download")
 	c.Check(tl[1+2*3].Kind(), Equals, "set-model")
 
+	label, done, total := t.Progress()
+	c.Check(label, Equals, "Prepared remodeling")
+	c.Check(done, Equals, total)
+
 	// cleanup
 	// fake completion
 	for _, t := range tl[1:] {
@@ -1007,15 +1007,18 @@ version: 1.0
 		return snaptest.MockSnap(c, yml, sideInfo), nil
 	})()
 
-	defer devicestate.MockKeysNewProtectorKey(func() (keys.ProtectorKey, error) {
-		return keys.ProtectorKey([]byte("new-protector")), nil
+	newPrimaryKey := keys.PrimaryKey(bytes.Repeat([]byte{0x42}, keys.PrimaryKeySize))
+	defer devicestate.MockKeysNewPrimaryKey(func() (keys.PrimaryKey, error) {
+		return newPrimaryKey, nil
 	})()
+	newProtectorKey, err := newPrimaryKey.DeriveProtectorKey("system-save")
+	c.Assert(err, IsNil)
 
 	plainKey := &keys.PlainKey{}
 	defer devicestate.MockKeysCreateProtectedKey(func(k keys.ProtectorKey, primaryKey []byte) (*keys.PlainKey, []byte, []byte, error) {
-		c.Check(primaryKey, IsNil)
-		c.Check(k, DeepEquals, keys.ProtectorKey([]byte("new-protector")))
-		return plainKey, []byte("new-primary-key"), []byte("new-save-default"), nil
+		c.Check(primaryKey, DeepEquals, []byte(newPrimaryKey))
+		c.Check(k, DeepEquals, newProtectorKey)
+		return plainKey, primaryKey, []byte("new-save-default"), nil
 	})()
 
 	defer devicestate.MockKeysPlainKeyWrite(func(key *keys.PlainKey, writer keys.KeyDataWriter) error {
@@ -1035,7 +1038,7 @@ version: 1.0
 	defer devicestate.MockBootMakeRunnableReprovision(func(model *asserts.Model, protector secboot.KeyProtectorFactory, encryption *boot.EncryptionSetup) error {
 		bootMakeRunnableReprovisionCalls++
 
-		c.Check(encryption.PrimaryKey(), DeepEquals, []byte("new-primary-key"))
+		c.Check(encryption.PrimaryKey(), DeepEquals, []byte(newPrimaryKey))
 
 		c.Check(protector, IsNil)
 
@@ -1068,7 +1071,7 @@ version: 1.0
 
 	st.Set("fde", "not-modified")
 
-	err := func() error {
+	err = func() error {
 		st.Unlock()
 		defer st.Lock()
 		return devicestate.DoReprovision(s.mgr, t)
@@ -1114,7 +1117,7 @@ version: 1.0
 
 	newSaveKey, err := os.ReadFile(filepath.Join(dirs.SnapFDEDir, "ubuntu-save.key"))
 	c.Assert(err, IsNil)
-	c.Assert(newSaveKey, DeepEquals, []byte("new-protector"))
+	c.Assert(newSaveKey, DeepEquals, []byte(newProtectorKey))
 
 	var newState any
 	err = st.Get("fde", &newState)
@@ -1290,15 +1293,18 @@ version: 1.0
 		return snaptest.MockSnap(c, yml, sideInfo), nil
 	})()
 
-	defer devicestate.MockKeysNewProtectorKey(func() (keys.ProtectorKey, error) {
-		return keys.ProtectorKey([]byte("new-protector")), nil
+	newPrimaryKey := keys.PrimaryKey(bytes.Repeat([]byte{0x42}, keys.PrimaryKeySize))
+	defer devicestate.MockKeysNewPrimaryKey(func() (keys.PrimaryKey, error) {
+		return newPrimaryKey, nil
 	})()
+	newProtectorKey, err := newPrimaryKey.DeriveProtectorKey("system-save")
+	c.Assert(err, IsNil)
 
 	plainKey := &keys.PlainKey{}
 	defer devicestate.MockKeysCreateProtectedKey(func(k keys.ProtectorKey, primaryKey []byte) (*keys.PlainKey, []byte, []byte, error) {
-		c.Check(primaryKey, IsNil)
-		c.Check(k, DeepEquals, keys.ProtectorKey([]byte("new-protector")))
-		return plainKey, []byte("new-primary-key"), []byte("new-save-default"), nil
+		c.Check(primaryKey, DeepEquals, []byte(newPrimaryKey))
+		c.Check(k, DeepEquals, newProtectorKey)
+		return plainKey, primaryKey, []byte("new-save-default"), nil
 	})()
 
 	defer devicestate.MockKeysPlainKeyWrite(func(key *keys.PlainKey, writer keys.KeyDataWriter) error {
@@ -1318,7 +1324,7 @@ version: 1.0
 	defer devicestate.MockBootMakeRunnableReprovision(func(model *asserts.Model, protector secboot.KeyProtectorFactory, encryption *boot.EncryptionSetup) error {
 		bootMakeRunnableReprovisionCalls++
 
-		c.Check(encryption.PrimaryKey(), DeepEquals, []byte("new-primary-key"))
+		c.Check(encryption.PrimaryKey(), DeepEquals, []byte(newPrimaryKey))
 
 		c.Check(protector, IsNil)
 
@@ -1356,7 +1362,7 @@ version: 1.0
 
 	st.Set("fde", "not-modified")
 
-	err := func() error {
+	err = func() error {
 		st.Unlock()
 		defer st.Lock()
 		return devicestate.DoReprovision(s.mgr, t)
@@ -1494,7 +1500,7 @@ func (s *handlersReprovisionSuite) TestDoReprovisionUnsetRecoveryKey(c *C) {
 	c.Assert(err, ErrorMatches, "missing recovery key")
 }
 
-func (s *handlersReprovisionSuite) TestDoReprovisionNewProtectorKeyError(c *C) {
+func (s *handlersReprovisionSuite) TestDoReprovisionNewPrimaryKeyError(c *C) {
 	st := s.state
 	st.Lock()
 	defer st.Unlock()
@@ -1525,8 +1531,8 @@ func (s *handlersReprovisionSuite) TestDoReprovisionNewProtectorKeyError(c *C) {
 		return false, fmt.Errorf("unexpected")
 	})()
 
-	defer devicestate.MockKeysNewProtectorKey(func() (keys.ProtectorKey, error) {
-		return nil, fmt.Errorf("protector key failed")
+	defer devicestate.MockKeysNewPrimaryKey(func() (keys.PrimaryKey, error) {
+		return nil, fmt.Errorf("primary key failed")
 	})()
 
 	err := func() error {
@@ -1534,7 +1540,7 @@ func (s *handlersReprovisionSuite) TestDoReprovisionNewProtectorKeyError(c *C) {
 		defer st.Lock()
 		return devicestate.DoReprovision(s.mgr, t)
 	}()
-	c.Assert(err, ErrorMatches, "protector key failed")
+	c.Assert(err, ErrorMatches, "primary key failed")
 	s.verifyRollback(c)
 }
 
@@ -1569,10 +1575,6 @@ func (s *handlersReprovisionSuite) TestDoReprovisionCreateProtectedKeyError(c *C
 		return false, fmt.Errorf("unexpected")
 	})()
 
-	defer devicestate.MockKeysNewProtectorKey(func() (keys.ProtectorKey, error) {
-		return keys.ProtectorKey([]byte("new-protector")), nil
-	})()
-
 	defer devicestate.MockKeysCreateProtectedKey(func(k keys.ProtectorKey, primaryKey []byte) (*keys.PlainKey, []byte, []byte, error) {
 		return nil, nil, nil, fmt.Errorf("protected key creation failed")
 	})()
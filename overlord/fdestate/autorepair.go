@@ -271,7 +271,7 @@ func autoRepair(st *state.State, runPostInstallChecks bool) (AutoRepairResult, e
 			BootChains: bc,
 			Options:    boot.ResealKeyToModeenvOptions{Force: true},
 		}
-		return backendResealKeyForBootChains(wrapped, method, dirs.GlobalRootDir, &params)
+		return wrapped.resealKeyForBootChains(method, dirs.GlobalRootDir, &params)
 	}, method)
 
 	if err != nil {
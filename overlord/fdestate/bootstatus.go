@@ -0,0 +1,152 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package fdestate
+
+import (
+	"errors"
+	"time"
+
+	"github.com/snapcore/snapd/boot"
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/gadget/device"
+	"github.com/snapcore/snapd/overlord/fdestate/backend"
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+const lastResealStateKey = "fde-last-reseal"
+
+// ResealStatus is the outcome of the last attempt to reseal the disk
+// encryption keys to the boot chains.
+type ResealStatus struct {
+	Time time.Time `json:"time"`
+	// Error is set if resealing failed
+	Error string `json:"error,omitempty"`
+}
+
+func recordResealStatus(st *state.State, err error) {
+	status := ResealStatus{Time: timeNow()}
+	if err != nil {
+		status.Error = err.Error()
+	}
+	st.Set(lastResealStateKey, status)
+}
+
+// resealKeyForBootChains reseals the keys to the given boot chains and
+// records the outcome, the state must be locked.
+func (m *unlockedStateManager) resealKeyForBootChains(method device.SealingMethod, rootdir string, params *boot.ResealKeyForBootChainsParams) error {
+	err := backendResealKeyForBootChains(m, method, rootdir, params)
+	if !params.Options.DryRun {
+		recordResealStatus(m.state, err)
+	}
+	return err
+}
+
+// SealedBootChains are boot chains that keys were sealed to.
+type SealedBootChains struct {
+	BootChains  boot.PredictableBootChains `json:"boot-chains"`
+	ResealCount int                        `json:"reseal-count"`
+}
+
+// BootStatus describes the boot chains that the disk encryption keys are
+// sealed to and the state of resealing them.
+type BootStatus struct {
+	SealingMethod device.SealingMethod `json:"sealing-method"`
+	// RunBootChains are the boot chains of the run+recover keys, they
+	// include the chains of kernels and recovery systems being tried.
+	RunBootChains SealedBootChains `json:"run-boot-chains"`
+	// RecoveryBootChains are the boot chains of the recover keys.
+	RecoveryBootChains SealedBootChains `json:"recovery-boot-chains"`
+	LastReseal         *ResealStatus    `json:"last-reseal,omitempty"`
+	// PendingResealReasons lists why the keys would need to be resealed,
+	// it is empty if they are up to date.
+	PendingResealReasons []string `json:"pending-reseal-reasons,omitempty"`
+}
+
+// GetBootStatus returns the boot chains that the disk encryption keys are
+// sealed to, the outcome of the last reseal and whether resealing is pending
+// because the sealed boot chains differ from the ones that are currently
+// expected. It returns device.ErrNoSealedKeys if the system has no sealed
+// keys.
+//
+// The state must be locked, it is unlocked while computing the expected boot
+// chains as resealing holds the modeenv lock and may need to relock the state.
+func GetBootStatus(st *state.State) (*BootStatus, error) {
+	method, err := device.SealedKeysMethod(dirs.GlobalRootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &BootStatus{SealingMethod: method}
+
+	var lastReseal ResealStatus
+	if err := st.Get(lastResealStateKey, &lastReseal); err == nil {
+		status.LastReseal = &lastReseal
+		if lastReseal.Error != "" {
+			status.PendingResealReasons = append(status.PendingResealReasons, "last reseal failed")
+		}
+	} else if !errors.Is(err, state.ErrNoState) {
+		return nil, err
+	}
+
+	runChainsFile := backend.BootChainsFileUnder(dirs.GlobalRootDir)
+	recoveryChainsFile := backend.RecoveryBootChainsFileUnder(dirs.GlobalRootDir)
+	status.RunBootChains.BootChains, status.RunBootChains.ResealCount, err = boot.ReadBootChains(runChainsFile)
+	if err != nil {
+		return nil, err
+	}
+	status.RecoveryBootChains.BootChains, status.RecoveryBootChains.ResealCount, err = boot.ReadBootChains(recoveryChainsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if method == device.SealingMethodFDESetupHook {
+		// keys sealed with the fde-setup hook do not track boot chains
+		return status, nil
+	}
+
+	var runNeeded, recoveryNeeded bool
+	st.Unlock()
+	err = boot.WithBootChains(func(bc boot.BootChains) error {
+		var err error
+		pbc := boot.ToPredictableBootChains(append(bc.RunModeBootChains, bc.RecoveryBootChainsForRunKey...))
+		runNeeded, _, err = boot.IsResealNeeded(pbc, runChainsFile, false)
+		if err != nil {
+			return err
+		}
+		rpbc := boot.ToPredictableBootChains(bc.RecoveryBootChains)
+		recoveryNeeded, _, err = boot.IsResealNeeded(rpbc, recoveryChainsFile, false)
+		return err
+	}, method)
+	st.Lock()
+	if err != nil {
+		// the sealed boot chains are still useful without knowing
+		// whether they are up to date
+		status.PendingResealReasons = append(status.PendingResealReasons, "cannot compute expected boot chains: "+err.Error())
+		return status, nil
+	}
+	if runNeeded {
+		status.PendingResealReasons = append(status.PendingResealReasons, "run boot chains differ from the expected ones")
+	}
+	if recoveryNeeded {
+		status.PendingResealReasons = append(status.PendingResealReasons, "recovery boot chains differ from the expected ones")
+	}
+
+	return status, nil
+}
@@ -0,0 +1,151 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//go:build !nosecboot
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package fdestate_test
+
+import (
+	"errors"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/boot"
+	"github.com/snapcore/snapd/bootloader"
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/gadget/device"
+	"github.com/snapcore/snapd/overlord/fdestate"
+	"github.com/snapcore/snapd/overlord/fdestate/backend"
+	"github.com/snapcore/snapd/testutil"
+)
+
+var mockSealedBootChains = boot.PredictableBootChains{
+	{
+		BrandID:        "my-brand",
+		Model:          "my-model",
+		Grade:          "signed",
+		ModelSignKeyID: "my-key-id",
+		AssetChain: []boot.BootAsset{
+			{Role: bootloader.RoleRecovery, Name: "shim", Hashes: []string{"x"}},
+			{Role: bootloader.RoleRunMode, Name: "grubx64.efi", Hashes: []string{"y", "z"}},
+		},
+		Kernel:         "pc-kernel",
+		KernelRevision: "1",
+		KernelCmdlines: []string{"snapd_recovery_mode=run"},
+	},
+}
+
+func (s *fdeMgrSuite) TestResealRecordsLastReseal(c *C) {
+	st := s.st
+	const onClassic = true
+	s.startedManager(c, onClassic)
+
+	now := time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)
+	s.AddCleanup(fdestate.MockTimeNow(func() time.Time { return now }))
+
+	var resealErr error
+	s.AddCleanup(fdestate.MockBackendResealKeyForBootChains(func(manager backend.FDEStateManager, method device.SealingMethod, rootdir string, params *boot.ResealKeyForBootChainsParams) error {
+		return resealErr
+	}))
+
+	st.Lock()
+	defer st.Unlock()
+
+	unlocker := &instrumentedUnlocker{state: st}
+	params := &boot.ResealKeyForBootChainsParams{}
+	err := boot.ResealKeyForBootChains(unlocker.Unlock, device.SealingMethodTPM, dirs.GlobalRootDir, params)
+	c.Assert(err, IsNil)
+
+	var lastReseal fdestate.ResealStatus
+	c.Assert(st.Get("fde-last-reseal", &lastReseal), IsNil)
+	c.Check(lastReseal, DeepEquals, fdestate.ResealStatus{Time: now})
+
+	now = now.Add(time.Hour)
+	resealErr = errors.New("boom")
+	err = boot.ResealKeyForBootChains(unlocker.Unlock, device.SealingMethodTPM, dirs.GlobalRootDir, params)
+	c.Assert(err, ErrorMatches, "boom")
+
+	c.Assert(st.Get("fde-last-reseal", &lastReseal), IsNil)
+	c.Check(lastReseal, DeepEquals, fdestate.ResealStatus{Time: now, Error: "boom"})
+
+	// dry runs are not recorded
+	now = now.Add(time.Hour)
+	resealErr = nil
+	params.Options.DryRun = true
+	err = boot.ResealKeyForBootChains(unlocker.Unlock, device.SealingMethodTPM, dirs.GlobalRootDir, params)
+	c.Assert(err, IsNil)
+
+	c.Assert(st.Get("fde-last-reseal", &lastReseal), IsNil)
+	c.Check(lastReseal.Error, Equals, "boom")
+}
+
+func (s *fdeMgrSuite) TestGetBootStatusNoSealedKeys(c *C) {
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	_, err := fdestate.GetBootStatus(s.st)
+	c.Check(err, testutil.ErrorIs, device.ErrNoSealedKeys)
+}
+
+func (s *fdeMgrSuite) TestGetBootStatusFDESetupHook(c *C) {
+	c.Assert(device.StampSealedKeys(dirs.GlobalRootDir, device.SealingMethodFDESetupHook), IsNil)
+
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	status, err := fdestate.GetBootStatus(s.st)
+	c.Assert(err, IsNil)
+	c.Check(status, DeepEquals, &fdestate.BootStatus{
+		SealingMethod: device.SealingMethodFDESetupHook,
+	})
+}
+
+func (s *fdeMgrSuite) TestGetBootStatusTPM(c *C) {
+	c.Assert(device.StampSealedKeys(dirs.GlobalRootDir, device.SealingMethodTPM), IsNil)
+	c.Assert(boot.WriteBootChains(mockSealedBootChains, backend.BootChainsFileUnder(dirs.GlobalRootDir), 3), IsNil)
+	c.Assert(boot.WriteBootChains(mockSealedBootChains, backend.RecoveryBootChainsFileUnder(dirs.GlobalRootDir), 1), IsNil)
+
+	now := time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)
+
+	s.st.Lock()
+	defer s.st.Unlock()
+	s.st.Set("fde-last-reseal", fdestate.ResealStatus{Time: now, Error: "boom"})
+
+	status, err := fdestate.GetBootStatus(s.st)
+	c.Assert(err, IsNil)
+	c.Check(status.SealingMethod, Equals, device.SealingMethodTPM)
+	c.Check(status.RunBootChains, DeepEquals, fdestate.SealedBootChains{
+		BootChains:  mockSealedBootChains,
+		ResealCount: 3,
+	})
+	c.Check(status.RecoveryBootChains, DeepEquals, fdestate.SealedBootChains{
+		BootChains:  mockSealedBootChains,
+		ResealCount: 1,
+	})
+	c.Check(status.LastReseal, DeepEquals, &fdestate.ResealStatus{Time: now, Error: "boom"})
+	// there is no bootloader in the test environment to compute the
+	// expected boot chains with
+	c.Assert(status.PendingResealReasons, HasLen, 2)
+	c.Check(status.PendingResealReasons[0], Equals, "last reseal failed")
+	c.Check(status.PendingResealReasons[1], Matches, "cannot compute expected boot chains: .*")
+
+	// the state is locked again
+	var lastReseal fdestate.ResealStatus
+	c.Check(s.st.Get("fde-last-reseal", &lastReseal), IsNil)
+}
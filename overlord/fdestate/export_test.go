@@ -58,6 +58,8 @@ var (
 
 	SetRepairAttemptResult = setRepairAttemptResult
 	GetRepairAttemptResult = getRepairAttemptResult
+
+	MigrateSaveProtectorKey = migrateSaveProtectorKey
 )
 
 type ExternalOperation = externalOperation
@@ -198,6 +200,18 @@ func MockSecbootGetPrimaryKey(f func(devices []string, fallbackKeyFiles []string
 	return testutil.Mock(&secbootGetPrimaryKey, f)
 }
 
+func MockSecbootTestProtectorKey(f func(ctx context.Context, devicePath, slotName string, protectorKey []byte) (bool, error)) (restore func()) {
+	return testutil.Mock(&secbootTestProtectorKey, f)
+}
+
+func MockSecbootAddBootstrapKeyOnExistingDisk(f func(node string, newKey keys.EncryptionKey) error) (restore func()) {
+	return testutil.Mock(&secbootAddBootstrapKeyOnExistingDisk, f)
+}
+
+func MockSecbootCreateBootstrappedContainer(f func(key secboot.DiskUnlockKey, devicePath string) secboot.BootstrappedContainer) (restore func()) {
+	return testutil.Mock(&secbootCreateBootstrappedContainer, f)
+}
+
 func MockBootloaderFind(f func(rootdir string, opts *bootloader.Options) (bootloader.Bootloader, error)) (restore func()) {
 	return testutil.Mock(&bootloaderFind, f)
 }
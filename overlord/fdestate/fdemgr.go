@@ -189,6 +189,12 @@ func (m *FDEManager) DeviceInitialized() {
 			if err := initializeState(m.state); err != nil {
 				return fmt.Errorf("cannot initialize FDE state: %v", err)
 			}
+			// devices installed by older snapd versions use a
+			// random protector key for ubuntu-save, this is
+			// not fatal as the key file is still there
+			if err := migrateSaveProtectorKey(m.state); err != nil {
+				logger.Noticef("cannot migrate ubuntu-save protector key: %v", err)
+			}
 		}
 		return nil
 	}()
@@ -599,7 +605,7 @@ func (m *FDEManager) resealKeyForBootChains(unlocker boot.Unlocker, method devic
 		FDEManager: m,
 		unlocker:   unlocker,
 	}
-	return backendResealKeyForBootChains(wrapped, method, rootdir, params)
+	return wrapped.resealKeyForBootChains(method, rootdir, params)
 }
 
 func fdeMgr(st *state.State) *FDEManager {
@@ -673,7 +679,7 @@ func (m *FDEManager) ensureParametersLoadedWithMaybeReseal(role, containerRole s
 			BootChains: bc,
 			Options:    boot.ResealKeyToModeenvOptions{Force: true},
 		}
-		return backendResealKeyForBootChains(wrapped, method, dirs.GlobalRootDir, &params)
+		return wrapped.resealKeyForBootChains(method, dirs.GlobalRootDir, &params)
 	}, method)
 }
 
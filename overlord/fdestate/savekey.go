@@ -0,0 +1,190 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package fdestate
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/gadget/device"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/fdestate/backend"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/secboot"
+	"github.com/snapcore/snapd/secboot/keys"
+	"github.com/snapcore/snapd/strutil"
+)
+
+var (
+	secbootTestProtectorKey              = secboot.TestProtectorKey
+	secbootAddBootstrapKeyOnExistingDisk = secboot.AddBootstrapKeyOnExistingDisk
+	secbootCreateBootstrappedContainer   = secboot.CreateBootstrappedContainer
+)
+
+// derivedSaveKeyslot is the name of the temporary key slot holding the
+// plainkey protected by the derived key until it replaces "default".
+const derivedSaveKeyslot = "snapd-derived-default"
+
+// migrateSaveProtectorKey moves the "default" key slot of ubuntu-save to
+// a plainkey protected by a key derived from the primary key, so that
+// ubuntu-save can still be unlocked if ubuntu-save.key gets lost. Devices
+// installed before the protector key was derived use a random one.
+//
+// Every step can be interrupted: the derived key is only made the
+// "default" one after a working key slot using it was added, and the
+// initramfs tries all plainkey slots with the key from the file.
+func migrateSaveProtectorKey(st *state.State) error {
+	containers, err := GetEncryptedContainers(st)
+	if err != nil {
+		return fmt.Errorf("cannot get encrypted disks: %w", err)
+	}
+
+	var save backend.EncryptedContainer
+	var devices []string
+	for _, container := range containers {
+		if len(container.LegacyKeys()) != 0 {
+			// ubuntu-save is not protected by a plainkey
+			// without tokens
+			return nil
+		}
+		if container.ContainerRole() == "system-save" {
+			save = container
+		}
+		devices = append(devices, container.DevPath())
+	}
+	if save == nil {
+		return nil
+	}
+	saveDevice := save.DevPath()
+
+	primaryKey, err := secbootGetPrimaryKey(devices, nil)
+	if err != nil {
+		return fmt.Errorf("cannot get primary key: %w", err)
+	}
+	protectorKey, err := keys.PrimaryKey(primaryKey).DeriveProtectorKey(save.ContainerRole())
+	if err != nil {
+		return err
+	}
+
+	slots, err := secbootListContainerUnlockKeyNames(saveDevice)
+	if err != nil {
+		return err
+	}
+	hasDefault := strutil.ListContains(slots, "default")
+	hasDerived := strutil.ListContains(slots, derivedSaveKeyslot)
+	if !hasDefault && !hasDerived {
+		return nil
+	}
+
+	if hasDefault {
+		migrated, err := secbootTestProtectorKey(context.Background(), saveDevice, "default", protectorKey)
+		if err != nil {
+			return fmt.Errorf("cannot test the default key of %s: %w", saveDevice, err)
+		}
+		if migrated {
+			if hasDerived {
+				// left over from an interrupted migration
+				if err := secbootDeleteContainerKey(saveDevice, derivedSaveKeyslot); err != nil {
+					return err
+				}
+			}
+			return writeSaveProtectorKey(protectorKey)
+		}
+	}
+
+	if hasDerived {
+		valid, err := secbootTestProtectorKey(context.Background(), saveDevice, derivedSaveKeyslot, protectorKey)
+		if err != nil {
+			return fmt.Errorf("cannot test the %s key of %s: %w", derivedSaveKeyslot, saveDevice, err)
+		}
+		if !valid {
+			if err := secbootDeleteContainerKey(saveDevice, derivedSaveKeyslot); err != nil {
+				return err
+			}
+			hasDerived = false
+		}
+	}
+
+	if !hasDerived {
+		if err := addDerivedSaveKeyslot(saveDevice, protectorKey, primaryKey); err != nil {
+			return err
+		}
+	}
+
+	if err := writeSaveProtectorKey(protectorKey); err != nil {
+		return err
+	}
+	if hasDefault {
+		if err := secbootDeleteContainerKey(saveDevice, "default"); err != nil {
+			return err
+		}
+	}
+	if err := secbootRenameContainerKey(saveDevice, derivedSaveKeyslot, "default"); err != nil {
+		return err
+	}
+
+	logger.Noticef("ubuntu-save is now protected by a key derived from the primary key")
+	return nil
+}
+
+func addDerivedSaveKeyslot(saveDevice string, protectorKey keys.ProtectorKey, primaryKey []byte) error {
+	bootstrapKey, err := keys.NewEncryptionKey()
+	if err != nil {
+		return fmt.Errorf("cannot create encryption key: %v", err)
+	}
+	if err := secbootDeleteContainerKey(saveDevice, "bootstrap-key"); err != nil {
+		logger.Debugf("cannot delete bootstrap-key on %s", saveDevice)
+	}
+	if err := secbootAddBootstrapKeyOnExistingDisk(saveDevice, bootstrapKey); err != nil {
+		return err
+	}
+	container := secbootCreateBootstrappedContainer(secboot.DiskUnlockKey(bootstrapKey), saveDevice)
+
+	plainKey, _, unlockKey, err := protectorKey.CreateProtectedKey(primaryKey)
+	if err != nil {
+		return err
+	}
+	if err := container.AddKey(derivedSaveKeyslot, unlockKey); err != nil {
+		return err
+	}
+	tokenWriter, err := container.GetTokenWriter(derivedSaveKeyslot)
+	if err != nil {
+		return err
+	}
+	if err := plainKey.Write(tokenWriter); err != nil {
+		return err
+	}
+	return container.RemoveBootstrapKey()
+}
+
+func writeSaveProtectorKey(protectorKey keys.ProtectorKey) error {
+	saveKeyPath := device.SaveKeyUnder(dirs.SnapFDEDir)
+	current, err := os.ReadFile(saveKeyPath)
+	if err == nil && bytes.Equal(current, protectorKey) {
+		return nil
+	}
+	if err := protectorKey.SaveToFile(saveKeyPath); err != nil {
+		return fmt.Errorf("cannot save the system-save key: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//go:build !nosecboot
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package fdestate_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/boot"
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/gadget/device"
+	"github.com/snapcore/snapd/overlord/fdestate"
+	"github.com/snapcore/snapd/secboot"
+	"github.com/snapcore/snapd/secboot/keys"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type saveKeyMigration struct {
+	slots      []string
+	validSlots map[string]bool
+
+	container *secboot.MockBootstrappedContainer
+	calls     []string
+}
+
+func (s *fdeMgrSuite) mockSaveKeyMigration(c *C, m *saveKeyMigration) (derivedKey keys.ProtectorKey) {
+	s.mockDeviceInState(&asserts.Model{}, "run")
+
+	s.AddCleanup(fdestate.MockDisksDMCryptUUIDFromMountPoint(func(mountpoint string) (string, error) {
+		if mountpoint == dirs.SnapSaveDir {
+			return "bbb", nil
+		}
+		return "aaa", nil
+	}))
+
+	primaryKey := bytes.Repeat([]byte{0x42}, keys.PrimaryKeySize)
+	derivedKey, err := keys.PrimaryKey(primaryKey).DeriveProtectorKey("system-save")
+	c.Assert(err, IsNil)
+
+	s.AddCleanup(fdestate.MockSecbootGetPrimaryKey(func(devices []string, fallbackKeyFiles []string) ([]byte, error) {
+		c.Check(devices, DeepEquals, []string{"/dev/disk/by-uuid/aaa", "/dev/disk/by-uuid/bbb"})
+		c.Check(fallbackKeyFiles, IsNil)
+		return primaryKey, nil
+	}))
+	s.AddCleanup(fdestate.MockSecbootListContainerUnlockKeyNames(func(devicePath string) ([]string, error) {
+		c.Check(devicePath, Equals, "/dev/disk/by-uuid/bbb")
+		return m.slots, nil
+	}))
+	s.AddCleanup(fdestate.MockSecbootTestProtectorKey(func(ctx context.Context, devicePath, slotName string, protectorKey []byte) (bool, error) {
+		c.Check(devicePath, Equals, "/dev/disk/by-uuid/bbb")
+		c.Check(protectorKey, DeepEquals, []byte(derivedKey))
+		m.calls = append(m.calls, "test:"+slotName)
+		return m.validSlots[slotName], nil
+	}))
+	s.AddCleanup(fdestate.MockSecbootAddBootstrapKeyOnExistingDisk(func(node string, newKey keys.EncryptionKey) error {
+		c.Check(node, Equals, "/dev/disk/by-uuid/bbb")
+		m.calls = append(m.calls, "add-bootstrap-key")
+		return nil
+	}))
+	s.AddCleanup(fdestate.MockSecbootCreateBootstrappedContainer(func(key secboot.DiskUnlockKey, devicePath string) secboot.BootstrappedContainer {
+		c.Check(devicePath, Equals, "/dev/disk/by-uuid/bbb")
+		m.container = secboot.CreateMockBootstrappedContainer()
+		return m.container
+	}))
+	saveKeyPath := device.SaveKeyUnder(dirs.SnapFDEDir)
+	s.AddCleanup(fdestate.MockSecbootDeleteContainerKey(func(devicePath string, slotName string) error {
+		c.Check(devicePath, Equals, "/dev/disk/by-uuid/bbb")
+		if slotName == "default" {
+			// the derived key must be in place before the old
+			// slot goes away
+			c.Check(saveKeyPath, testutil.FileEquals, []byte(derivedKey))
+		}
+		m.calls = append(m.calls, "delete:"+slotName)
+		return nil
+	}))
+	s.AddCleanup(fdestate.MockSecbootRenameContainerKey(func(devicePath string, oldName string, newName string) error {
+		c.Check(devicePath, Equals, "/dev/disk/by-uuid/bbb")
+		m.calls = append(m.calls, fmt.Sprintf("rename:%s:%s", oldName, newName))
+		return nil
+	}))
+
+	c.Assert(os.MkdirAll(filepath.Dir(saveKeyPath), 0755), IsNil)
+	c.Assert(os.WriteFile(saveKeyPath, []byte("old-protector-key"), 0600), IsNil)
+
+	return derivedKey
+}
+
+func (s *fdeMgrSuite) TestMigrateSaveProtectorKey(c *C) {
+	m := &saveKeyMigration{
+		slots: []string{"default", "default-fallback"},
+	}
+	derivedKey := s.mockSaveKeyMigration(c, m)
+
+	s.st.Lock()
+	defer s.st.Unlock()
+	err := fdestate.MigrateSaveProtectorKey(s.st)
+	c.Assert(err, IsNil)
+
+	c.Check(m.calls, DeepEquals, []string{
+		"test:default",
+		"delete:bootstrap-key",
+		"add-bootstrap-key",
+		"delete:default",
+		"rename:snapd-derived-default:default",
+	})
+	c.Assert(m.container, NotNil)
+	c.Check(m.container.Slots, HasLen, 1)
+	c.Check(m.container.Slots["snapd-derived-default"], NotNil)
+	_, hasToken := m.container.Tokens["snapd-derived-default"]
+	c.Check(hasToken, Equals, true)
+	c.Check(m.container.BootstrapKeyRemoved, Equals, true)
+	c.Check(device.SaveKeyUnder(dirs.SnapFDEDir), testutil.FileEquals, []byte(derivedKey))
+	c.Check(s.logbuf.String(), testutil.Contains, "ubuntu-save is now protected by a key derived from the primary key")
+}
+
+func (s *fdeMgrSuite) TestMigrateSaveProtectorKeyAlreadyMigrated(c *C) {
+	m := &saveKeyMigration{
+		slots:      []string{"default", "default-fallback"},
+		validSlots: map[string]bool{"default": true},
+	}
+	derivedKey := s.mockSaveKeyMigration(c, m)
+
+	s.st.Lock()
+	defer s.st.Unlock()
+	err := fdestate.MigrateSaveProtectorKey(s.st)
+	c.Assert(err, IsNil)
+
+	c.Check(m.calls, DeepEquals, []string{"test:default"})
+	c.Check(m.container, IsNil)
+	// the key file is fixed up if needed
+	c.Check(device.SaveKeyUnder(dirs.SnapFDEDir), testutil.FileEquals, []byte(derivedKey))
+}
+
+func (s *fdeMgrSuite) TestMigrateSaveProtectorKeyResumeAfterDelete(c *C) {
+	// interrupted after the old default slot was deleted
+	m := &saveKeyMigration{
+		slots:      []string{"default-fallback", "snapd-derived-default"},
+		validSlots: map[string]bool{"snapd-derived-default": true},
+	}
+	derivedKey := s.mockSaveKeyMigration(c, m)
+
+	s.st.Lock()
+	defer s.st.Unlock()
+	err := fdestate.MigrateSaveProtectorKey(s.st)
+	c.Assert(err, IsNil)
+
+	c.Check(m.calls, DeepEquals, []string{
+		"test:snapd-derived-default",
+		"rename:snapd-derived-default:default",
+	})
+	c.Check(m.container, IsNil)
+	c.Check(device.SaveKeyUnder(dirs.SnapFDEDir), testutil.FileEquals, []byte(derivedKey))
+}
+
+func (s *fdeMgrSuite) TestMigrateSaveProtectorKeyInvalidLeftoverSlot(c *C) {
+	m := &saveKeyMigration{
+		slots: []string{"default", "default-fallback", "snapd-derived-default"},
+	}
+	derivedKey := s.mockSaveKeyMigration(c, m)
+
+	s.st.Lock()
+	defer s.st.Unlock()
+	err := fdestate.MigrateSaveProtectorKey(s.st)
+	c.Assert(err, IsNil)
+
+	c.Check(m.calls, DeepEquals, []string{
+		"test:default",
+		"test:snapd-derived-default",
+		"delete:snapd-derived-default",
+		"delete:bootstrap-key",
+		"add-bootstrap-key",
+		"delete:default",
+		"rename:snapd-derived-default:default",
+	})
+	c.Check(device.SaveKeyUnder(dirs.SnapFDEDir), testutil.FileEquals, []byte(derivedKey))
+}
+
+func (s *fdeMgrSuite) TestMigrateSaveProtectorKeyLegacyKeys(c *C) {
+	m := &saveKeyMigration{
+		slots: []string{"default", "default-fallback"},
+	}
+	s.mockSaveKeyMigration(c, m)
+	s.AddCleanup(fdestate.MockSecbootGetPrimaryKey(func(devices []string, fallbackKeyFiles []string) ([]byte, error) {
+		c.Errorf("unexpected call")
+		return nil, fmt.Errorf("unexpected call")
+	}))
+
+	// without tokens, the save key is stored in a sealed key file
+	fallbackSaveKey := device.FallbackSaveSealedKeyUnder(boot.InitramfsSeedEncryptionKeyDir)
+	c.Assert(os.MkdirAll(filepath.Dir(fallbackSaveKey), 0755), IsNil)
+	c.Assert(os.WriteFile(fallbackSaveKey, nil, 0644), IsNil)
+
+	s.st.Lock()
+	defer s.st.Unlock()
+	err := fdestate.MigrateSaveProtectorKey(s.st)
+	c.Assert(err, IsNil)
+
+	c.Check(m.calls, HasLen, 0)
+	c.Check(device.SaveKeyUnder(dirs.SnapFDEDir), testutil.FileEquals, "old-protector-key")
+}
+
+func (s *fdeMgrSuite) TestMigrateSaveProtectorKeyNoPrimaryKey(c *C) {
+	m := &saveKeyMigration{
+		slots: []string{"default", "default-fallback"},
+	}
+	s.mockSaveKeyMigration(c, m)
+	s.AddCleanup(fdestate.MockSecbootGetPrimaryKey(func(devices []string, fallbackKeyFiles []string) ([]byte, error) {
+		return nil, secboot.ErrKernelKeyNotFound
+	}))
+
+	s.st.Lock()
+	defer s.st.Unlock()
+	err := fdestate.MigrateSaveProtectorKey(s.st)
+	c.Assert(err, ErrorMatches, "cannot get primary key: .*")
+
+	c.Check(m.calls, HasLen, 0)
+	c.Check(device.SaveKeyUnder(dirs.SnapFDEDir), testutil.FileEquals, "old-protector-key")
+}
@@ -36,6 +36,7 @@ import (
 	"github.com/snapcore/snapd/overlord/servicestate"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/sandbox/cgroup"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/testutil"
 )
@@ -222,3 +223,7 @@ func MockTimeAfter(f func(time.Duration) <-chan time.Time) (restore func()) {
 	timeAfter = f
 	return func() { timeAfter = old }
 }
+
+func MockCgroupCreateTransientScopeForHelper(f func(securityTag string, pid int, opts *cgroup.HelperTrackingOptions) error) (restore func()) {
+	return testutil.Mock(&cgroupCreateTransientScopeForHelper, f)
+}
@@ -39,7 +39,8 @@ var (
 	longMountHelp  = i18n.G(`
 The mount command mounts the given source onto the given destination path,
 provided that the snap has a plug for the mount-control interface which allows
-this operation.`)
+this operation. The mount is removed when the mount-control plug allowing it is
+disconnected.`)
 )
 
 func init() {
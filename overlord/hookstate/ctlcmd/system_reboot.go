@@ -0,0 +1,121 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ctlcmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/overlord/restart"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/snap"
+)
+
+var (
+	shortSystemRebootHelp = i18n.G("Schedule a reboot of the system")
+	longSystemRebootHelp  = i18n.G(`
+The system-reboot command can be used from the hooks of gadget and kernel snaps
+to schedule a reboot of the system.
+
+The reboot does not happen immediately: it happens once the delay given with
+--delay has elapsed and the change running the hook is finished. Reboots
+scheduled by several snaps are coalesced into a single one. The reason given
+with --reason is recorded in the change and logged when rebooting.
+
+With --cancel, the reboot previously scheduled by the snap is cancelled.
+`)
+)
+
+func init() {
+	addCommand("system-reboot", shortSystemRebootHelp, longSystemRebootHelp, func() command { return &systemRebootCommand{} })
+}
+
+type systemRebootCommand struct {
+	baseCommand
+
+	Delay  string `long:"delay" value-name:"<duration>" description:"how long to wait before rebooting, e.g. 10m"`
+	Reason string `long:"reason" value-name:"<reason>" description:"why the system needs to reboot"`
+	Cancel bool   `long:"cancel" description:"cancel the reboot previously scheduled by the snap"`
+}
+
+func (c *systemRebootCommand) Execute([]string) error {
+	ctx, err := c.ensureContext()
+	if err != nil {
+		return err
+	}
+	if ctx.IsEphemeral() {
+		return fmt.Errorf("cannot use system-reboot outside of a hook")
+	}
+	task, ok := ctx.Task()
+	if !ok {
+		return fmt.Errorf("internal error: inside a hook but no task")
+	}
+	if c.Cancel && (c.Delay != "" || c.Reason != "") {
+		return fmt.Errorf("cannot use --cancel with --delay or --reason")
+	}
+	var delay time.Duration
+	if c.Delay != "" {
+		delay, err = time.ParseDuration(c.Delay)
+		if err != nil {
+			return fmt.Errorf("cannot parse --delay: %v", err)
+		}
+		if delay < 0 {
+			return fmt.Errorf("cannot use a negative --delay")
+		}
+	}
+
+	ctx.Lock()
+	defer ctx.Unlock()
+	st := ctx.State()
+
+	snapName := ctx.InstanceName()
+	info, err := snapstate.CurrentInfo(st, snapName)
+	if err != nil {
+		return err
+	}
+	switch info.Type() {
+	case snap.TypeGadget, snap.TypeKernel:
+	default:
+		return fmt.Errorf("cannot use system-reboot from snap %q: must be a gadget or a kernel snap", snapName)
+	}
+
+	if c.Cancel {
+		cancelled, err := restart.CancelScheduledReboot(st, snapName)
+		if err != nil {
+			return err
+		}
+		if !cancelled {
+			return fmt.Errorf("cannot cancel reboot: no reboot scheduled by snap %q", snapName)
+		}
+		task.Logf("Reboot scheduled by %q cancelled", snapName)
+		return nil
+	}
+
+	if err := restart.ScheduleReboot(st, snapName, delay, c.Reason, task.Change()); err != nil {
+		return err
+	}
+	if c.Reason != "" {
+		task.Logf("Reboot scheduled by %q in %v: %s", snapName, delay, c.Reason)
+	} else {
+		task.Logf("Reboot scheduled by %q in %v", snapName, delay)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ctlcmd_test
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/overlord/hookstate"
+	"github.com/snapcore/snapd/overlord/hookstate/ctlcmd"
+	"github.com/snapcore/snapd/overlord/hookstate/hooktest"
+	"github.com/snapcore/snapd/overlord/restart"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type systemRebootSuite struct {
+	testutil.BaseTest
+	state       *state.State
+	mockHandler *hooktest.MockHandler
+	chg         *state.Change
+}
+
+var _ = Suite(&systemRebootSuite{})
+
+func (s *systemRebootSuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
+	dirs.SetRootDir(c.MkDir())
+	s.AddCleanup(func() { dirs.SetRootDir("/") })
+
+	s.mockHandler = hooktest.NewMockHandler()
+	s.state = state.New(nil)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	_, err := restart.Manager(s.state, "boot-id-1", nil)
+	c.Assert(err, IsNil)
+	mockInstalledSnap(c, s.state, "name: pc\nversion: 1\ntype: gadget\n", "")
+	mockInstalledSnap(c, s.state, "name: some-app\nversion: 1\n", "")
+	s.chg = s.state.NewChange("refresh", "...")
+}
+
+func (s *systemRebootSuite) hookContext(c *C, snapName string) (*hookstate.Context, *state.Task) {
+	s.state.Lock()
+	defer s.state.Unlock()
+	task := s.state.NewTask("run-hook", "...")
+	s.chg.AddTask(task)
+	setup := &hookstate.HookSetup{Snap: snapName, Revision: snap.R(1), Hook: "post-refresh"}
+	ctx, err := hookstate.NewContext(task, s.state, setup, s.mockHandler, "")
+	c.Assert(err, IsNil)
+	return ctx, task
+}
+
+func (s *systemRebootSuite) TestScheduleReboot(c *C) {
+	ctx, task := s.hookContext(c, "pc")
+	_, _, _, err := ctlcmd.Run(ctx, []string{"system-reboot", "--delay=10m", "--reason=firmware update"}, 0, nil)
+	c.Assert(err, IsNil)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	scheduled, err := restart.ScheduledReboots(s.state)
+	c.Assert(err, IsNil)
+	c.Assert(scheduled, HasLen, 1)
+	c.Check(scheduled["pc"].Reason, Equals, "firmware update")
+	c.Check(scheduled["pc"].ChangeID, Equals, s.chg.ID())
+	c.Check(scheduled["pc"].At.After(time.Now().Add(9*time.Minute)), Equals, true)
+	c.Assert(task.Log(), HasLen, 1)
+	c.Check(task.Log()[0], Matches, `.* Reboot scheduled by "pc" in 10m0s: firmware update`)
+	// no reboot is requested right away
+	c.Check(restart.Pending(s.state), Equals, restart.RestartUnset)
+}
+
+func (s *systemRebootSuite) TestCancelScheduledReboot(c *C) {
+	ctx, task := s.hookContext(c, "pc")
+	_, _, _, err := ctlcmd.Run(ctx, []string{"system-reboot"}, 0, nil)
+	c.Assert(err, IsNil)
+	_, _, _, err = ctlcmd.Run(ctx, []string{"system-reboot", "--cancel"}, 0, nil)
+	c.Assert(err, IsNil)
+
+	s.state.Lock()
+	scheduled, err := restart.ScheduledReboots(s.state)
+	c.Assert(err, IsNil)
+	c.Check(scheduled, HasLen, 0)
+	c.Check(task.Log(), HasLen, 2)
+	c.Check(task.Log()[1], Matches, `.* Reboot scheduled by "pc" cancelled`)
+	s.state.Unlock()
+
+	_, _, _, err = ctlcmd.Run(ctx, []string{"system-reboot", "--cancel"}, 0, nil)
+	c.Assert(err, ErrorMatches, `cannot cancel reboot: no reboot scheduled by snap "pc"`)
+}
+
+func (s *systemRebootSuite) TestErrors(c *C) {
+	ctx, _ := s.hookContext(c, "pc")
+	for _, t := range []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"system-reboot", "--delay=soon"}, `cannot parse --delay: .*`},
+		{[]string{"system-reboot", "--delay=-1m"}, `cannot use a negative --delay`},
+		{[]string{"system-reboot", "--cancel", "--reason=foo"}, `cannot use --cancel with --delay or --reason`},
+	} {
+		_, _, _, err := ctlcmd.Run(ctx, t.args, 0, nil)
+		c.Check(err, ErrorMatches, t.err, Commentf("%v", t.args))
+	}
+
+	appCtx, _ := s.hookContext(c, "some-app")
+	_, _, _, err := ctlcmd.Run(appCtx, []string{"system-reboot"}, 0, nil)
+	c.Check(err, ErrorMatches, `cannot use system-reboot from snap "some-app": must be a gadget or a kernel snap`)
+
+	ephemeralCtx, err := hookstate.NewContext(nil, s.state, &hookstate.HookSetup{Snap: "pc", Revision: snap.R(1)}, nil, "")
+	c.Assert(err, IsNil)
+	_, _, _, err = ctlcmd.Run(ephemeralCtx, []string{"system-reboot"}, 0, nil)
+	c.Check(err, ErrorMatches, `cannot use system-reboot outside of a hook`)
+}
@@ -0,0 +1,130 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ctlcmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/overlord/servicestate"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/sandbox/cgroup"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/strutil"
+)
+
+var (
+	shortTrackHelperHelp = i18n.G("Track a helper process with the snap")
+	longTrackHelperHelp  = i18n.G(`
+The track-helper command moves the given process into a new tracking scope of
+the snap, so that it is accounted for with the snap, including the limits of
+the quota group the snap is in.
+
+This is meant for helper processes spawned on the host on behalf of the snap,
+for instance by a companion daemon, or started outside of the tracking of the
+snap, for instance through D-Bus activation. The process must run as the same
+user as the caller and be either unconfined or confined by the snap. When
+invoked from a hook, the process is tracked as part of the hook unless --app
+is given.
+`)
+
+	cgroupCreateTransientScopeForHelper = cgroup.CreateTransientScopeForHelper
+)
+
+func init() {
+	addCommand("track-helper", shortTrackHelperHelp, longTrackHelperHelp, func() command { return &trackHelperCommand{} })
+}
+
+type trackHelperCommand struct {
+	baseCommand
+
+	App        string `long:"app" description:"track the process as part of the given app of the snap"`
+	Positional struct {
+		Pid string `positional-arg-name:"<pid>" required:"yes" description:"id of the process to track"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+func (c *trackHelperCommand) Execute([]string) error {
+	ctx, err := c.ensureContext()
+	if err != nil {
+		return err
+	}
+
+	pid, err := strconv.Atoi(c.Positional.Pid)
+	if err != nil {
+		return fmt.Errorf("cannot track process: invalid pid %q", c.Positional.Pid)
+	}
+
+	st := ctx.State()
+	st.Lock()
+	securityTag, slice, err := helperTrackingTarget(ctx.InstanceName(), ctx.HookName(), c.App, st)
+	st.Unlock()
+	if err != nil {
+		return err
+	}
+
+	uid, err := strconv.Atoi(c.uid)
+	if err != nil {
+		return fmt.Errorf("internal error: invalid caller uid %q", c.uid)
+	}
+
+	opts := &cgroup.HelperTrackingOptions{Slice: slice, Uid: uid}
+	if err := cgroupCreateTransientScopeForHelper(securityTag, pid, opts); err != nil {
+		if err == cgroup.ErrCannotTrackProcess {
+			return fmt.Errorf("cannot track process %d: systemd could not move it to a tracking scope", pid)
+		}
+		return err
+	}
+	return nil
+}
+
+// helperTrackingTarget returns the security tag under which a helper process
+// is tracked, along with the slice of the quota group of the snap, if any.
+func helperTrackingTarget(snapName, hookName, appName string, st *state.State) (securityTag, slice string, err error) {
+	switch {
+	case appName != "":
+		info, err := snapstate.CurrentInfo(st, snapName)
+		if err != nil {
+			return "", "", err
+		}
+		if _, ok := info.Apps[appName]; !ok {
+			return "", "", fmt.Errorf("cannot track process: snap %q has no app %q", snapName, appName)
+		}
+		securityTag = snap.AppSecurityTag(snapName, appName)
+	case hookName != "":
+		securityTag = snap.HookSecurityTag(snapName, hookName)
+	default:
+		return "", "", fmt.Errorf("cannot track process: --app is required outside of hooks")
+	}
+
+	grps, err := servicestate.AllQuotas(st)
+	if err != nil {
+		return "", "", err
+	}
+	for _, grp := range grps {
+		if strutil.ListContains(grp.Snaps, snapName) {
+			slice = grp.SliceFileName()
+			break
+		}
+	}
+	return securityTag, slice, nil
+}
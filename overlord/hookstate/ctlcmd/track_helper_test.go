@@ -0,0 +1,156 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ctlcmd_test
+
+import (
+	"errors"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/gadget/quantity"
+	"github.com/snapcore/snapd/overlord/hookstate"
+	"github.com/snapcore/snapd/overlord/hookstate/ctlcmd"
+	"github.com/snapcore/snapd/overlord/hookstate/hooktest"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/sandbox/cgroup"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/quota"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type trackHelperSuite struct {
+	testutil.BaseTest
+	state       *state.State
+	mockContext *hookstate.Context
+
+	trackedTag  string
+	trackedPid  int
+	trackedOpts *cgroup.HelperTrackingOptions
+	trackErr    error
+}
+
+var _ = Suite(&trackHelperSuite{})
+
+const trackHelperSnapYaml = `name: snap1
+version: 1
+apps:
+  daemon:
+    command: bin/daemon
+    daemon: simple
+`
+
+func (s *trackHelperSuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
+	dirs.SetRootDir(c.MkDir())
+	s.AddCleanup(func() { dirs.SetRootDir("/") })
+
+	s.state = state.New(nil)
+	s.state.Lock()
+	defer s.state.Unlock()
+	mockInstalledSnap(c, s.state, trackHelperSnapYaml, "")
+
+	task := s.state.NewTask("test-task", "my test task")
+	setup := &hookstate.HookSetup{Snap: "snap1", Revision: snap.R(1), Hook: "configure"}
+	ctx, err := hookstate.NewContext(task, s.state, setup, hooktest.NewMockHandler(), "")
+	c.Assert(err, IsNil)
+	s.mockContext = ctx
+
+	s.trackedTag, s.trackedPid, s.trackedOpts, s.trackErr = "", 0, nil, nil
+	s.AddCleanup(ctlcmd.MockCgroupCreateTransientScopeForHelper(func(securityTag string, pid int, opts *cgroup.HelperTrackingOptions) error {
+		s.trackedTag, s.trackedPid, s.trackedOpts = securityTag, pid, opts
+		return s.trackErr
+	}))
+}
+
+func (s *trackHelperSuite) TestMissingContext(c *C) {
+	_, _, _, err := ctlcmd.Run(nil, []string{"track-helper", "1234"}, 0, nil)
+	c.Check(err, ErrorMatches, `cannot invoke snapctl operation commands \(here "track-helper"\) from outside of a snap`)
+}
+
+func (s *trackHelperSuite) TestRootOnly(c *C) {
+	_, _, _, err := ctlcmd.Run(s.mockContext, []string{"track-helper", "1234"}, 1000, nil)
+	c.Check(err, ErrorMatches, `cannot use "track-helper" with uid 1000, try with sudo`)
+	c.Check(s.trackedPid, Equals, 0)
+}
+
+func (s *trackHelperSuite) TestTrackInHook(c *C) {
+	stdout, stderr, _, err := ctlcmd.Run(s.mockContext, []string{"track-helper", "1234"}, 0, nil)
+	c.Assert(err, IsNil)
+	c.Check(string(stdout), Equals, "")
+	c.Check(string(stderr), Equals, "")
+	c.Check(s.trackedTag, Equals, "snap.snap1.hook.configure")
+	c.Check(s.trackedPid, Equals, 1234)
+	c.Check(s.trackedOpts, DeepEquals, &cgroup.HelperTrackingOptions{})
+}
+
+func (s *trackHelperSuite) TestTrackAppInQuotaGroup(c *C) {
+	grp, err := quota.NewGroup("grp", quota.NewResourcesBuilder().WithMemoryLimit(quantity.SizeGiB).Build())
+	c.Assert(err, IsNil)
+	grp.Snaps = []string{"snap1"}
+	s.state.Lock()
+	s.state.Set("quotas", map[string]*quota.Group{"grp": grp})
+	s.state.Unlock()
+
+	_, _, _, err = ctlcmd.Run(s.mockContext, []string{"track-helper", "--app", "daemon", "1234"}, 0, nil)
+	c.Assert(err, IsNil)
+	c.Check(s.trackedTag, Equals, "snap.snap1.daemon")
+	c.Check(s.trackedPid, Equals, 1234)
+	c.Check(s.trackedOpts, DeepEquals, &cgroup.HelperTrackingOptions{Slice: "snap.grp.slice"})
+}
+
+func (s *trackHelperSuite) TestTrackUnknownApp(c *C) {
+	_, _, _, err := ctlcmd.Run(s.mockContext, []string{"track-helper", "--app", "nope", "1234"}, 0, nil)
+	c.Check(err, ErrorMatches, `cannot track process: snap "snap1" has no app "nope"`)
+	c.Check(s.trackedPid, Equals, 0)
+}
+
+func (s *trackHelperSuite) TestTrackOutsideHookNeedsApp(c *C) {
+	s.state.Lock()
+	ctx, err := hookstate.NewContext(nil, s.state, &hookstate.HookSetup{Snap: "snap1", Revision: snap.R(1)}, nil, "")
+	s.state.Unlock()
+	c.Assert(err, IsNil)
+
+	_, _, _, err = ctlcmd.Run(ctx, []string{"track-helper", "1234"}, 0, nil)
+	c.Check(err, ErrorMatches, `cannot track process: --app is required outside of hooks`)
+	c.Check(s.trackedPid, Equals, 0)
+}
+
+func (s *trackHelperSuite) TestTrackInvalidPid(c *C) {
+	_, _, _, err := ctlcmd.Run(s.mockContext, []string{"track-helper", "self"}, 0, nil)
+	c.Check(err, ErrorMatches, `cannot track process: invalid pid "self"`)
+	c.Check(s.trackedPid, Equals, 0)
+}
+
+func (s *trackHelperSuite) TestTrackErrors(c *C) {
+	s.trackErr = cgroup.ErrCannotTrackProcess
+	_, _, _, err := ctlcmd.Run(s.mockContext, []string{"track-helper", "1234"}, 0, nil)
+	c.Check(err, ErrorMatches, `cannot track process 1234: systemd could not move it to a tracking scope`)
+
+	s.trackErr = errors.New(`cannot track process 1234: process belongs to snap "other"`)
+	_, _, _, err = ctlcmd.Run(s.mockContext, []string{"track-helper", "1234"}, 0, nil)
+	c.Check(err, ErrorMatches, `cannot track process 1234: process belongs to snap "other"`)
+
+	s.trackErr = errors.New(`cannot track process 1234: security label "/usr/sbin/helperd" does not belong to a snap`)
+	_, _, _, err = ctlcmd.Run(s.mockContext, []string{"track-helper", "1234"}, 0, nil)
+	c.Check(err, ErrorMatches, `cannot track process 1234: security label "/usr/sbin/helperd" does not belong to a snap`)
+	// the caller is root, so must be the tracked process
+	c.Check(s.trackedOpts.Uid, Equals, 0)
+}
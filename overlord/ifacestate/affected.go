@@ -0,0 +1,88 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ifacestate
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/mount"
+	"github.com/snapcore/snapd/snap"
+)
+
+// servicesAffectedBy returns the services of the snaps whose mount namespace
+// is changed by the given connection. Running services need a restart to
+// reliably see such changes.
+func servicesAffectedBy(iface interfaces.Interface, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) ([]*snap.AppInfo, error) {
+	var plugSpec, slotSpec mount.Specification
+	if err := plugSpec.AddConnectedPlug(iface, plug, slot); err != nil {
+		return nil, err
+	}
+	if err := slotSpec.AddConnectedSlot(iface, plug, slot); err != nil {
+		return nil, err
+	}
+
+	var services []*snap.AppInfo
+	seen := make(map[string]bool)
+	addServices := func(info *snap.Info, spec *mount.Specification) {
+		if seen[info.InstanceName()] || len(spec.MountEntries())+len(spec.UserMountEntries()) == 0 {
+			return
+		}
+		seen[info.InstanceName()] = true
+		svcs := info.Services()
+		sort.Slice(svcs, func(i, j int) bool { return svcs[i].Name < svcs[j].Name })
+		services = append(services, svcs...)
+	}
+	addServices(plug.Snap(), &plugSpec)
+	addServices(slot.Snap(), &slotSpec)
+	return services, nil
+}
+
+// AffectedServices returns the services which need a restart to see the
+// given connection being made, or removed if it exists. For connections not
+// made yet only the static attributes of the plug and slot are considered.
+// The state must be locked by the caller.
+func (m *InterfaceManager) AffectedServices(connRef *interfaces.ConnRef) ([]*snap.AppInfo, error) {
+	conn, err := m.repo.Connection(connRef)
+	if err == nil {
+		return servicesAffectedBy(m.repo.Interface(conn.Interface()), conn.Plug, conn.Slot)
+	}
+	if _, ok := err.(*interfaces.NotConnectedError); !ok {
+		return nil, err
+	}
+
+	plugInfo := m.repo.Plug(connRef.PlugRef.Snap, connRef.PlugRef.Name)
+	slotInfo := m.repo.Slot(connRef.SlotRef.Snap, connRef.SlotRef.Name)
+	if plugInfo == nil || slotInfo == nil {
+		return nil, fmt.Errorf("internal error: cannot find plug or slot of connection %q", connRef.ID())
+	}
+	plugAppSet, err := appSetForSnapRevision(m.state, plugInfo.Snap)
+	if err != nil {
+		return nil, err
+	}
+	slotAppSet, err := appSetForSnapRevision(m.state, slotInfo.Snap)
+	if err != nil {
+		return nil, err
+	}
+	plug := interfaces.NewConnectedPlug(plugInfo, plugAppSet, nil, nil)
+	slot := interfaces.NewConnectedSlot(slotInfo, slotAppSet, nil, nil)
+	return servicesAffectedBy(m.repo.Interface(plugInfo.Interface), plug, slot)
+}
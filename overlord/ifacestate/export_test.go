@@ -33,36 +33,37 @@ import (
 )
 
 var (
-	AddImplicitInterfaces        = addImplicitInterfaces
-	SnapsWithSecurityProfiles    = snapsWithSecurityProfiles
-	CheckAutoconnectConflicts    = checkAutoconnectConflicts
-	FindSymmetricAutoconnectTask = findSymmetricAutoconnectTask
-	ConnectPriv                  = connect
-	DisconnectPriv               = disconnectTasks
-	GetConns                     = getConns
-	SetConns                     = setConns
-	DefaultDeviceKey             = defaultDeviceKey
-	RemoveDevice                 = removeDevice
-	MakeSlotName                 = makeSlotName
-	EnsureUniqueName             = ensureUniqueName
-	SuggestedSlotName            = suggestedSlotName
-	HotplugSlotName              = hotplugSlotName
-	InSameChangeWaitChain        = inSameChangeWaitChain
-	GetHotplugAttrs              = getHotplugAttrs
-	SetHotplugAttrs              = setHotplugAttrs
-	GetHotplugSlots              = getHotplugSlots
-	SetHotplugSlots              = setHotplugSlots
-	UpdateDevice                 = updateDevice
-	FindConnsForHotplugKey       = findConnsForHotplugKey
-	CheckSystemSnapIsPresent     = checkSystemSnapIsPresent
-	SystemSnapInfo               = systemSnapInfo
-	IsHotplugChange              = isHotplugChange
-	GetHotplugChangeAttrs        = getHotplugChangeAttrs
-	SetHotplugChangeAttrs        = setHotplugChangeAttrs
-	AllocHotplugSeq              = allocHotplugSeq
-	AddHotplugSeqWaitTask        = addHotplugSeqWaitTask
-	AddHotplugSlot               = addHotplugSlot
-	HasActiveConnection          = hasActiveConnection
+	AddImplicitInterfaces         = addImplicitInterfaces
+	SnapsWithSecurityProfiles     = snapsWithSecurityProfiles
+	CheckAutoconnectConflicts     = checkAutoconnectConflicts
+	FindSymmetricAutoconnectTask  = findSymmetricAutoconnectTask
+	ConnectPriv                   = connect
+	DisconnectPriv                = disconnectTasks
+	GetConns                      = getConns
+	SetConns                      = setConns
+	RemoveStaleMountControlMounts = removeStaleMountControlMounts
+	DefaultDeviceKey              = defaultDeviceKey
+	RemoveDevice                  = removeDevice
+	MakeSlotName                  = makeSlotName
+	EnsureUniqueName              = ensureUniqueName
+	SuggestedSlotName             = suggestedSlotName
+	HotplugSlotName               = hotplugSlotName
+	InSameChangeWaitChain         = inSameChangeWaitChain
+	GetHotplugAttrs               = getHotplugAttrs
+	SetHotplugAttrs               = setHotplugAttrs
+	GetHotplugSlots               = getHotplugSlots
+	SetHotplugSlots               = setHotplugSlots
+	UpdateDevice                  = updateDevice
+	FindConnsForHotplugKey        = findConnsForHotplugKey
+	CheckSystemSnapIsPresent      = checkSystemSnapIsPresent
+	SystemSnapInfo                = systemSnapInfo
+	IsHotplugChange               = isHotplugChange
+	GetHotplugChangeAttrs         = getHotplugChangeAttrs
+	SetHotplugChangeAttrs         = setHotplugChangeAttrs
+	AllocHotplugSeq               = allocHotplugSeq
+	AddHotplugSeqWaitTask         = addHotplugSeqWaitTask
+	AddHotplugSlot                = addHotplugSlot
+	HasActiveConnection           = hasActiveConnection
 
 	BatchConnectTasks                = batchConnectTasks
 	FirstTaskAfterBootWhenPreseeding = firstTaskAfterBootWhenPreseeding
@@ -232,3 +233,12 @@ func (m *InterfaceManager) SetupSecurityByBackend(task *state.Task, appSets []*i
 func MockIsSnapVerified(new func(st *state.State, snapID string) bool) (restore func()) {
 	return testutil.Mock(&isSnapVerified, new)
 }
+
+func MockMountControlMounts(list func(instanceName string) ([]string, error), remove func(where string) error) (restore func()) {
+	restoreList := testutil.Mock(&mountControlMountPoints, list)
+	restoreRemove := testutil.Mock(&removeMountControlMount, remove)
+	return func() {
+		restoreList()
+		restoreRemove()
+	}
+}
@@ -1045,6 +1045,18 @@ func (m *InterfaceManager) doConnect(task *state.Task, _ *tomb.Tomb) (err error)
 	}
 	setConns(st, conns)
 
+	// auto-connections happen while seeding, installing or refreshing
+	// snaps, whose services are started afterwards
+	if !autoConnect {
+		services, err := servicesAffectedBy(m.repo.Interface(conn.Interface()), conn.Plug, conn.Slot)
+		if err != nil {
+			return err
+		}
+		if err := servicestate.MarkNeedingRestart(st, services); err != nil {
+			return err
+		}
+	}
+
 	// the dynamic attributes might have been updated by the interface's BeforeConnectPlug/Slot code,
 	// so we need to update the task for connect-plug- and connect-slot- hooks to see new values.
 	setDynamicHookAttributes(task, conn.Plug.DynamicAttrs(), conn.Slot.DynamicAttrs())
@@ -1096,9 +1108,29 @@ func (m *InterfaceManager) doDisconnect(task *state.Task, _ *tomb.Tomb) error {
 		return fmt.Errorf("internal error: connection %q not found in state", cref.ID())
 	}
 
+	// mounts done with "snapctl mount" must not outlive the mount-control
+	// connection that allowed them
+	if conn.Interface == "mount-control" && len(snapStates) == 2 {
+		plugSnapInfo, err := snapStates[0].CurrentInfo()
+		if err != nil {
+			return err
+		}
+		if err := removeStaleMountControlMounts(task, plugSnapInfo, cref.ID(), conns); err != nil {
+			return err
+		}
+	}
+
 	// store old connection for undo
 	task.Set("old-conn", conn)
 
+	var affectedServices []*snap.AppInfo
+	if repoConn, err := m.repo.Connection(&cref); err == nil {
+		affectedServices, err = servicesAffectedBy(m.repo.Interface(repoConn.Interface()), repoConn.Plug, repoConn.Slot)
+		if err != nil {
+			return err
+		}
+	}
+
 	err = m.repo.Disconnect(plugRef.Snap, plugRef.Name, slotRef.Snap, slotRef.Name)
 	if err != nil {
 		_, notConnected := err.(*interfaces.NotConnectedError)
@@ -1164,6 +1196,11 @@ func (m *InterfaceManager) doDisconnect(task *state.Task, _ *tomb.Tomb) error {
 	}
 	setConns(st, conns)
 
+	// services of a snap being removed are forgotten with it
+	if err := servicestate.MarkNeedingRestart(st, affectedServices); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -1896,11 +1933,31 @@ func (m *InterfaceManager) doAutoConnect(task *state.Task, _ *tomb.Tomb) error {
 			return err
 		}
 	}
+	// Restore manual connections of a previous installation of the snap
+	restored, removedConns, err := m.addRestoredConnections(task, snapsup, deviceCtx, newconns, conns, conflictError)
+	if err != nil {
+		return err
+	}
+	if len(restored) > 0 {
+		if connOpts == nil {
+			connOpts = make(map[string]*connectOpts, len(restored))
+		}
+		for _, id := range restored {
+			connOpts[id] = &connectOpts{}
+		}
+	}
+
 	autots, hasInterfaceHooks, err := batchConnectTasks(st, snapsup, newconns, connOpts)
 	if err != nil {
 		return err
 	}
 
+	if removedConns != nil {
+		if err := consumeRemovedConns(task, snapName, removedConns, restored); err != nil {
+			return err
+		}
+	}
+
 	// If interface hooks are not present then connects can be executed during
 	// preseeding.
 	// Otherwise we will run all connects, their hooks and setup-profiles after
@@ -1980,6 +2037,12 @@ func (m *InterfaceManager) doAutoDisconnect(task *state.Task, _ *tomb.Tomb) erro
 		}
 	}
 
+	// remember the manual connections, so that they can be restored if the
+	// snap is installed again
+	if err := rememberRemovedConns(st, snapsup); err != nil {
+		return err
+	}
+
 	hookTasks := state.NewTaskSet()
 	for _, connRef := range connections {
 		conn, err := m.repo.Connection(connRef)
@@ -2010,6 +2073,223 @@ func (m *InterfaceManager) doAutoDisconnect(task *state.Task, _ *tomb.Tomb) erro
 func (m *InterfaceManager) undoAutoConnect(task *state.Task, _ *tomb.Tomb) error {
 	// TODO Introduce disconnection hooks, and run them here as well to give a chance
 	// for the snap to undo whatever it did when the connection was established.
+	st := task.State()
+	st.Lock()
+	defer st.Unlock()
+
+	// remember again the connections of the removed snap that were
+	// consumed by the task
+	var removedConns *removedSnapConns
+	err := task.Get("removed-conns", &removedConns)
+	if errors.Is(err, state.ErrNoState) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	snapsup, err := snapstate.TaskSnapSetup(task)
+	if err != nil {
+		return err
+	}
+	removed, err := getRemovedConns(st)
+	if err != nil {
+		return err
+	}
+	removed[snapsup.InstanceName()] = removedConns
+	setRemovedConns(st, removed)
+	task.Set("removed-conns", nil)
+	return nil
+}
+
+// addRestoredConnections adds to newconns the manual connections of a
+// previous installation of the snap that can be restored. It returns the IDs
+// of the added connections and the connections of the removed snap, if any,
+// that are consumed by the installation.
+func (m *InterfaceManager) addRestoredConnections(task *state.Task, snapsup *snapstate.SnapSetup, deviceCtx snapstate.DeviceContext, newconns map[string]*interfaces.ConnRef, conns map[string]*schema.ConnState, conflictError func(*state.Retry, error) error) (restored []string, removedConns *removedSnapConns, err error) {
+	st := task.State()
+	instanceName := snapsup.InstanceName()
+
+	removed, err := getRemovedConns(st)
+	if err != nil {
+		return nil, nil, err
+	}
+	removedConns = removed[instanceName]
+	if removedConns == nil {
+		// forget the expired connections, if any
+		setRemovedConns(st, removed)
+		return nil, nil, nil
+	}
+
+	switch {
+	case snapsup.Flags.NoRestoreConnections:
+		task.Logf("Not restoring connections of the previous installation of snap %q as requested", instanceName)
+		return nil, removedConns, nil
+	case removedConns.SnapID != snapsup.SideInfo.SnapID:
+		task.Logf("Not restoring connections of the previous installation of snap %q: snap ID mismatch", instanceName)
+		return nil, removedConns, nil
+	case !removedConns.Revision.Local() && !snapsup.Revision().Local() && snapsup.Revision().N < removedConns.Revision.N:
+		task.Logf("Not restoring connections of the previous installation of snap %q: revision %s is older than the removed revision %s",
+			instanceName, snapsup.Revision(), removedConns.Revision)
+		return nil, removedConns, nil
+	}
+
+	connectChecker, err := newConnectChecker(st, deviceCtx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ids := make([]string, 0, len(removedConns.Conns))
+	for id := range removedConns.Conns {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		connRef, err := interfaces.ParseConnRef(id)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, ok := newconns[id]; ok {
+			// auto-connected again
+			continue
+		}
+		if connState, ok := conns[id]; ok && !connState.Undesired && !connState.HotplugGone {
+			continue
+		}
+		plug := m.repo.Plug(connRef.PlugRef.Snap, connRef.PlugRef.Name)
+		slot := m.repo.Slot(connRef.SlotRef.Snap, connRef.SlotRef.Name)
+		iface := removedConns.Conns[id]
+		if plug == nil || slot == nil || plug.Interface != iface || slot.Interface != iface {
+			task.Logf("Cannot restore connection %s: plug or slot not found", id)
+			continue
+		}
+		plugAppSet, err := appSetForSnapRevision(st, plug.Snap)
+		if err != nil {
+			return nil, nil, err
+		}
+		slotAppSet, err := appSetForSnapRevision(st, slot.Snap)
+		if err != nil {
+			return nil, nil, err
+		}
+		ok, err := connectChecker.check(interfaces.NewConnectedPlug(plug, plugAppSet, nil, nil), interfaces.NewConnectedSlot(slot, slotAppSet, nil, nil))
+		if err != nil || !ok {
+			task.Logf("Cannot restore connection %s: not allowed by policy", id)
+			continue
+		}
+		if err := checkAutoconnectConflicts(st, task, connRef.PlugRef.Snap, connRef.SlotRef.Snap); err != nil {
+			if retry, ok := err.(*state.Retry); ok {
+				return nil, nil, conflictError(retry, nil)
+			}
+			return nil, nil, conflictError(nil, err)
+		}
+		newconns[id] = connRef
+		restored = append(restored, id)
+	}
+	return restored, removedConns, nil
+}
+
+// consumeRemovedConns forgets the connections of the removed snap once a new
+// installation of it took care of them, keeping them in the task for undo,
+// and records a notice about the restored ones.
+func consumeRemovedConns(task *state.Task, instanceName string, removedConns *removedSnapConns, restored []string) error {
+	st := task.State()
+	removed, err := getRemovedConns(st)
+	if err != nil {
+		return err
+	}
+	delete(removed, instanceName)
+	setRemovedConns(st, removed)
+	task.Set("removed-conns", removedConns)
+
+	if len(restored) == 0 {
+		return nil
+	}
+	task.Logf("Restoring connections of the previous installation of snap %q: %s", instanceName, strings.Join(restored, ", "))
+	opts := &state.AddNoticeOptions{
+		Data: map[string]string{"connections": strings.Join(restored, ",")},
+	}
+	_, err = st.AddNotice(nil, state.InterfacesConnectionsRestoredNotice, instanceName, opts)
+	return err
+}
+
+// doCopyInstanceConnections connects the plugs and slots of the snap instance
+// of the task like the ones of another instance of the same snap, given by
+// the "from-instance" of the task, are connected.
+func (m *InterfaceManager) doCopyInstanceConnections(task *state.Task, _ *tomb.Tomb) error {
+	st := task.State()
+	st.Lock()
+	defer st.Unlock()
+
+	snapsup, err := snapstate.TaskSnapSetup(task)
+	if err != nil {
+		return err
+	}
+	var fromInstance string
+	if err := task.Get("from-instance", &fromInstance); err != nil {
+		return err
+	}
+	toInstance := snapsup.InstanceName()
+
+	conns, err := getConns(st)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(conns))
+	for id := range conns {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	newconns := make(map[string]*interfaces.ConnRef)
+	connOpts := make(map[string]*connectOpts)
+	for _, id := range ids {
+		connState := conns[id]
+		if connState.Undesired || connState.HotplugGone {
+			continue
+		}
+		connRef, err := interfaces.ParseConnRef(id)
+		if err != nil {
+			return err
+		}
+		if connRef.PlugRef.Snap != fromInstance && connRef.SlotRef.Snap != fromInstance {
+			continue
+		}
+		if connRef.PlugRef.Snap == fromInstance {
+			connRef.PlugRef.Snap = toInstance
+		}
+		if connRef.SlotRef.Snap == fromInstance {
+			connRef.SlotRef.Snap = toInstance
+		}
+		if cstate, ok := conns[connRef.ID()]; ok && !cstate.Undesired && !cstate.HotplugGone {
+			// already connected, e.g. automatically
+			continue
+		}
+		if m.repo.Plug(connRef.PlugRef.Snap, connRef.PlugRef.Name) == nil || m.repo.Slot(connRef.SlotRef.Snap, connRef.SlotRef.Name) == nil {
+			task.Logf("cannot copy connection %s: plug or slot not found", id)
+			continue
+		}
+		if err := checkAutoconnectConflicts(st, task, connRef.PlugRef.Snap, connRef.SlotRef.Snap); err != nil {
+			if retry, ok := err.(*state.Retry); ok {
+				task.Logf("Waiting for conflicting change in progress: %s", retry.Reason)
+				return retry
+			}
+			return fmt.Errorf("cannot copy connections of snap %q: %v", fromInstance, err)
+		}
+		newconns[connRef.ID()] = connRef
+		connOpts[connRef.ID()] = &connectOpts{AutoConnect: connState.Auto, ByGadget: connState.ByGadget}
+	}
+
+	if len(newconns) > 0 {
+		ts, _, err := batchConnectTasks(st, snapsup, newconns, connOpts)
+		if err != nil {
+			return err
+		}
+		snapstate.InjectTasks(task, ts)
+		st.EnsureBefore(0)
+	}
+
+	task.SetStatus(state.DoneStatus)
 	return nil
 }
 
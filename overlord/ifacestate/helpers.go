@@ -27,6 +27,7 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/snapcore/snapd/asserts"
 	"github.com/snapcore/snapd/dirs"
@@ -43,6 +44,7 @@ import (
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snapdenv"
 	"github.com/snapcore/snapd/systemd"
 	"github.com/snapcore/snapd/timings"
 )
@@ -54,6 +56,9 @@ func init() {
 var (
 	snapdAppArmorServiceIsDisabled = snapdAppArmorServiceIsDisabledImpl
 
+	mountControlMountPoints = mountControlMountPointsImpl
+	removeMountControlMount = removeMountControlMountImpl
+
 	writeSystemKey = interfaces.WriteSystemKey
 )
 
@@ -110,9 +115,10 @@ func (m *InterfaceManager) securityBackendOpts() (*interfaces.SecurityBackendOpt
 	}
 
 	opts := interfaces.SecurityBackendOptions{
-		Preseed:       m.preseed,
-		CoreSnapInfo:  coreSnapInfo,
-		SnapdSnapInfo: snapdSnapInfo,
+		Preseed:         m.preseed,
+		PreseedEmulated: m.preseed && snapdenv.PreseedingEmulated(),
+		CoreSnapInfo:    coreSnapInfo,
+		SnapdSnapInfo:   snapdSnapInfo,
 	}
 
 	return &opts, nil
@@ -212,6 +218,85 @@ func snapdAppArmorServiceIsDisabledImpl() bool {
 	return err == nil && !isEnabled
 }
 
+// mountControlMountPointsImpl returns the mount points of the mount units
+// created for the given snap with "snapctl mount".
+func mountControlMountPointsImpl(instanceName string) ([]string, error) {
+	sysd := systemd.New(systemd.SystemMode, nil)
+	return sysd.ListMountUnits(instanceName, "mount-control")
+}
+
+func removeMountControlMountImpl(where string) error {
+	sysd := systemd.New(systemd.SystemMode, nil)
+	return sysd.RemoveMountUnitFile(where)
+}
+
+// mountControlPath returns the given path relative to the root directory,
+// as the mount points of the mount units are, if it is under it. Snap
+// variables such as $SNAP_COMMON expand to paths including the root
+// directory.
+func mountControlPath(path string) string {
+	if dirs.GlobalRootDir != "/" && strings.HasPrefix(path, dirs.GlobalRootDir+"/") {
+		return dirs.StripRootDir(path)
+	}
+	return path
+}
+
+// removeStaleMountControlMounts removes the mounts that the given snap
+// created with "snapctl mount" and that are not allowed anymore by any of
+// its active mount-control connections other than the one with the given
+// ID, which is being disconnected.
+func removeStaleMountControlMounts(task *state.Task, snapInfo *snap.Info, disconnectedID string, conns map[string]*schema.ConnState) error {
+	var allowed []*utils.PathPattern
+	for id, connState := range conns {
+		if id == disconnectedID || connState.Interface != "mount-control" || connState.Undesired || connState.HotplugGone {
+			continue
+		}
+		connRef, err := interfaces.ParseConnRef(id)
+		if err != nil {
+			return err
+		}
+		if connRef.PlugRef.Snap != snapInfo.InstanceName() {
+			continue
+		}
+		mounts, _ := connState.StaticPlugAttrs["mount"].([]any)
+		for _, mount := range mounts {
+			attrs, _ := mount.(map[string]any)
+			where, ok := attrs["where"].(string)
+			if !ok {
+				continue
+			}
+			const allowCommas = true
+			pp, err := utils.NewPathPattern(mountControlPath(snapInfo.ExpandSnapVariables(where)), allowCommas)
+			if err != nil {
+				continue
+			}
+			allowed = append(allowed, pp)
+		}
+	}
+
+	mountPoints, err := mountControlMountPoints(snapInfo.InstanceName())
+	if err != nil {
+		return fmt.Errorf("cannot list mount-control mounts of snap %q: %v", snapInfo.InstanceName(), err)
+	}
+	for _, where := range mountPoints {
+		stillAllowed := false
+		for _, pp := range allowed {
+			if pp.Matches(mountControlPath(where)) {
+				stillAllowed = true
+				break
+			}
+		}
+		if stillAllowed {
+			continue
+		}
+		if err := removeMountControlMount(where); err != nil {
+			return fmt.Errorf("cannot remove mount-control mount %q of snap %q: %v", where, snapInfo.InstanceName(), err)
+		}
+		task.Logf("Removed mount of %q created by snap %q", where, snapInfo.InstanceName())
+	}
+	return nil
+}
+
 // regenerateAllSecurityProfiles will regenerate all security profiles. This
 // function is expected to be called with the state locked, though in some
 // scenarios one may want to temporarily unlock the state for the duration of
@@ -954,6 +1039,8 @@ type autoConnectChecker struct {
 
 	deviceCtx            snapstate.DeviceContext
 	cache                map[string]*asserts.SnapDeclaration
+	ctrlCache            map[string]*asserts.AutoConnectionControl
+	serial               *asserts.Serial
 	baseDecl             *asserts.BaseDeclaration
 	contentCompatEnabled bool
 }
@@ -963,11 +1050,18 @@ func newAutoConnectChecker(s *state.State, repo *interfaces.Repository, deviceCt
 	if err != nil {
 		return nil, fmt.Errorf("internal error: cannot find base declaration: %v", err)
 	}
+	var serial *asserts.Serial
+	if snapstate.DeviceSerial != nil {
+		// without a serial no auto-connection-control applies
+		serial, _ = snapstate.DeviceSerial(s)
+	}
 	return &autoConnectChecker{
 		st:                   s,
 		repo:                 repo,
 		deviceCtx:            deviceCtx,
 		cache:                make(map[string]*asserts.SnapDeclaration),
+		ctrlCache:            make(map[string]*asserts.AutoConnectionControl),
+		serial:               serial,
 		baseDecl:             baseDecl,
 		contentCompatEnabled: isContentCompatLabelEnabled(s),
 	}, nil
@@ -986,6 +1080,27 @@ func (c *autoConnectChecker) snapDeclaration(snapID string) (*asserts.SnapDeclar
 	return snapDecl, nil
 }
 
+// autoConnectionControl returns the auto-connection-control assertion about
+// the snap with the given snap-id that applies to the device, if any.
+func (c *autoConnectChecker) autoConnectionControl(snapID string) (*asserts.AutoConnectionControl, error) {
+	if snapID == "" || c.serial == nil {
+		return nil, nil
+	}
+	if ctrl, ok := c.ctrlCache[snapID]; ok {
+		return ctrl, nil
+	}
+	modelAs := c.deviceCtx.Model()
+	ctrl, err := assertstate.AutoConnectionControl(c.st, modelAs.BrandID(), modelAs.Model(), snapID)
+	if err != nil && !errors.Is(err, &asserts.NotFoundError{}) {
+		return nil, err
+	}
+	if ctrl != nil && !ctrl.AppliesTo(c.serial) {
+		ctrl = nil
+	}
+	c.ctrlCache[snapID] = ctrl
+	return ctrl, nil
+}
+
 func (c *autoConnectChecker) check(plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) (bool, interfaces.SideArity, error) {
 	modelAs := c.deviceCtx.Model()
 
@@ -1018,16 +1133,27 @@ func (c *autoConnectChecker) check(plug *interfaces.ConnectedPlug, slot *interfa
 		}
 	}
 
+	plugCtrl, err := c.autoConnectionControl(plug.Snap().SnapID)
+	if err != nil {
+		return false, nil, err
+	}
+	slotCtrl, err := c.autoConnectionControl(slot.Snap().SnapID)
+	if err != nil {
+		return false, nil, err
+	}
+
 	// check the connection against the declarations' rules
 	ic := policy.ConnectCandidate{
-		Plug:                plug,
-		PlugSnapDeclaration: plugDecl,
-		Slot:                slot,
-		SlotSnapDeclaration: slotDecl,
-		BaseDeclaration:     c.baseDecl,
-		Model:               modelAs,
-		Store:               storeAs,
-		CompatEnabled:       allowCompatLabel(c.contentCompatEnabled, plug.Interface()),
+		Plug:                      plug,
+		PlugSnapDeclaration:       plugDecl,
+		Slot:                      slot,
+		SlotSnapDeclaration:       slotDecl,
+		PlugAutoConnectionControl: plugCtrl,
+		SlotAutoConnectionControl: slotCtrl,
+		BaseDeclaration:           c.baseDecl,
+		Model:                     modelAs,
+		Store:                     storeAs,
+		CompatEnabled:             allowCompatLabel(c.contentCompatEnabled, plug.Interface()),
 	}
 
 	arity, err := ic.CheckAutoConnect()
@@ -1295,6 +1421,92 @@ func setConns(st *state.State, conns map[string]*schema.ConnState) {
 	st.Set("conns", remapped)
 }
 
+// removedConnsTTL is for how long the manual connections of a removed snap
+// are remembered, so that they can be restored if the snap is installed
+// again.
+var removedConnsTTL = 30 * 24 * time.Hour
+
+// removedSnapConns keeps track of the manual connections of a removed snap.
+type removedSnapConns struct {
+	SnapID    string        `json:"snap-id,omitempty"`
+	Revision  snap.Revision `json:"revision"`
+	RemovedAt time.Time     `json:"removed-at"`
+	// Kept is set when the snap was removed with the KeepConnections
+	// flag, the connections then do not expire.
+	Kept bool `json:"kept,omitempty"`
+	// Conns maps the IDs of the connections to their interface.
+	Conns map[string]string `json:"conns"`
+}
+
+// getRemovedConns returns the manual connections of removed snaps, keyed by
+// snap instance name, dropping the ones that expired unless kept.
+func getRemovedConns(st *state.State) (map[string]*removedSnapConns, error) {
+	var removed map[string]*removedSnapConns
+	if err := st.Get("removed-conns", &removed); err != nil && !errors.Is(err, state.ErrNoState) {
+		return nil, fmt.Errorf("cannot obtain data about connections of removed snaps: %v", err)
+	}
+	if removed == nil {
+		removed = make(map[string]*removedSnapConns)
+	}
+	now := time.Now()
+	for instanceName, r := range removed {
+		if !r.Kept && now.Sub(r.RemovedAt) > removedConnsTTL {
+			delete(removed, instanceName)
+		}
+	}
+	return removed, nil
+}
+
+func setRemovedConns(st *state.State, removed map[string]*removedSnapConns) {
+	if len(removed) == 0 {
+		st.Set("removed-conns", nil)
+		return
+	}
+	st.Set("removed-conns", removed)
+}
+
+// rememberRemovedConns records the manual connections of the snap being
+// removed, so that they can be restored if the snap is installed again.
+func rememberRemovedConns(st *state.State, snapsup *snapstate.SnapSetup) error {
+	instanceName := snapsup.InstanceName()
+	conns, err := getConns(st)
+	if err != nil {
+		return err
+	}
+	removed, err := getRemovedConns(st)
+	if err != nil {
+		return err
+	}
+
+	snapConns := make(map[string]string)
+	for id, connState := range conns {
+		if connState.Auto || connState.Undesired || connState.HotplugGone {
+			continue
+		}
+		connRef, err := interfaces.ParseConnRef(id)
+		if err != nil {
+			return err
+		}
+		if connRef.PlugRef.Snap == instanceName || connRef.SlotRef.Snap == instanceName {
+			snapConns[id] = connState.Interface
+		}
+	}
+
+	if len(snapConns) == 0 {
+		delete(removed, instanceName)
+	} else {
+		removed[instanceName] = &removedSnapConns{
+			SnapID:    snapsup.SideInfo.SnapID,
+			Revision:  snapsup.Revision(),
+			RemovedAt: time.Now(),
+			Kept:      snapsup.KeepConnections,
+			Conns:     snapConns,
+		}
+	}
+	setRemovedConns(st, removed)
+	return nil
+}
+
 // snapsWithSecurityProfiles returns all snaps that have active
 // security profiles: these are either snaps that are active,
 // inactive snaps that are being operated on, whose profile state
@@ -40,6 +40,7 @@ import (
 	"github.com/snapcore/snapd/overlord"
 	"github.com/snapcore/snapd/overlord/assertstate"
 	"github.com/snapcore/snapd/overlord/ifacestate"
+	"github.com/snapcore/snapd/overlord/ifacestate/schema"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/snapstate/snapstatetest"
 	"github.com/snapcore/snapd/overlord/state"
@@ -871,3 +872,66 @@ func (s *helpersSuite) TestHasActiveConnection(c *C) {
 	c.Assert(err, IsNil)
 	c.Check(active, Equals, true)
 }
+
+func (s *helpersSuite) TestRemoveStaleMountControlMounts(c *C) {
+	var removed []string
+	restore := ifacestate.MockMountControlMounts(func(instanceName string) ([]string, error) {
+		c.Check(instanceName, Equals, "consumer")
+		return []string{"/media/disk", "/var/snap/consumer/common/data", "/mnt/stray"}, nil
+	}, func(where string) error {
+		removed = append(removed, where)
+		return nil
+	})
+	defer restore()
+
+	info := snaptest.MockInfo(c, "name: consumer\nversion: 1\n", &snap.SideInfo{Revision: snap.R(1)})
+	conns := map[string]*schema.ConnState{
+		"consumer:mnt core:mount-control": {
+			Interface:       "mount-control",
+			StaticPlugAttrs: map[string]any{"mount": []any{map[string]any{"where": "/media/**"}}},
+		},
+		"consumer:other-mnt core:mount-control": {
+			Interface:       "mount-control",
+			StaticPlugAttrs: map[string]any{"mount": []any{map[string]any{"where": "$SNAP_COMMON/**"}}},
+		},
+		// undesired connections do not allow mounts
+		"consumer:undesired-mnt core:mount-control": {
+			Interface:       "mount-control",
+			Undesired:       true,
+			StaticPlugAttrs: map[string]any{"mount": []any{map[string]any{"where": "/mnt/**"}}},
+		},
+		// neither do connections of other snaps
+		"other:mnt core:mount-control": {
+			Interface:       "mount-control",
+			StaticPlugAttrs: map[string]any{"mount": []any{map[string]any{"where": "/mnt/**"}}},
+		},
+	}
+
+	s.st.Lock()
+	defer s.st.Unlock()
+	task := s.st.NewTask("disconnect", "")
+
+	err := ifacestate.RemoveStaleMountControlMounts(task, info, "consumer:mnt core:mount-control", conns)
+	c.Assert(err, IsNil)
+	c.Check(removed, DeepEquals, []string{"/media/disk", "/mnt/stray"})
+	c.Assert(task.Log(), HasLen, 2)
+	c.Check(task.Log()[0], Matches, `.* Removed mount of "/media/disk" created by snap "consumer"`)
+}
+
+func (s *helpersSuite) TestRemoveStaleMountControlMountsError(c *C) {
+	restore := ifacestate.MockMountControlMounts(func(instanceName string) ([]string, error) {
+		return []string{"/media/disk"}, nil
+	}, func(where string) error {
+		return errors.New("boom")
+	})
+	defer restore()
+
+	info := snaptest.MockInfo(c, "name: consumer\nversion: 1\n", &snap.SideInfo{Revision: snap.R(1)})
+
+	s.st.Lock()
+	defer s.st.Unlock()
+	task := s.st.NewTask("disconnect", "")
+
+	err := ifacestate.RemoveStaleMountControlMounts(task, info, "consumer:mnt core:mount-control", nil)
+	c.Assert(err, ErrorMatches, `cannot remove mount-control mount "/media/disk" of snap "consumer": boom`)
+}
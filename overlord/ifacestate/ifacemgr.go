@@ -119,12 +119,14 @@ func Manager(s *state.State, hookManager *hookstate.HookManager, noticeManager *
 	addHandler("discard-conns", m.doDiscardConns, m.undoDiscardConns)
 	addHandler("auto-connect", m.doAutoConnect, m.undoAutoConnect)
 	addHandler("auto-disconnect", m.doAutoDisconnect, nil)
+	addHandler("copy-instance-connections", m.doCopyInstanceConnections, nil)
 	addHandler("hotplug-add-slot", m.doHotplugAddSlot, nil)
 	addHandler("hotplug-connect", m.doHotplugConnect, nil)
 	addHandler("hotplug-update-slot", m.doHotplugUpdateSlot, nil)
 	addHandler("hotplug-remove-slot", m.doHotplugRemoveSlot, nil)
 	addHandler("hotplug-disconnect", m.doHotplugDisconnect, nil)
 	addHandler("regenerate-security-profiles", m.doRegenerateAllSecurityProfiles, nil)
+	addHandler("reevaluate-interface-policy", m.doReevaluateInterfacePolicy, nil)
 	addHandler("process-delayed-security-backend-effects", m.doProcessDelayedSecurityBackendEffects, nil)
 	addHandler("apply-delayed-snap-security-backend-effects", m.doApplyDelayedSnapSecurityBackendEffects, nil)
 	// Explicitly add "mark-preseeded" as a task which cannot run in parallel
@@ -297,11 +299,16 @@ Run "systemctl enable --now snapd.apparmor" to correct this.`)
 
 // Ensure implements StateManager.Ensure.
 func (m *InterfaceManager) Ensure() error {
-	// do not worry about udev monitor in preseeding mode
+	// do not worry about udev monitor or policy changes in preseeding mode
 	if m.preseed {
 		return nil
 	}
 
+	// apply refreshed base and snap declarations to the installed snaps
+	if err := m.ensurePolicyReevaluation(); err != nil {
+		logger.Noticef("cannot re-evaluate interface policy: %v", err)
+	}
+
 	if m.udevMonitorDisabled {
 		return nil
 	}
@@ -355,6 +362,18 @@ func (m *InterfaceManager) stopUDevMon() {
 	m.udevMon = nil
 }
 
+// UDevMonitorHealth returns the health of the udev monitor used for hotplug,
+// ok is false if the monitor is not running.
+func (m *InterfaceManager) UDevMonitorHealth() (health udevmonitor.Health, ok bool) {
+	m.udevMonMu.Lock()
+	udevMon := m.udevMon
+	m.udevMonMu.Unlock()
+	if udevMon == nil {
+		return udevmonitor.Health{}, false
+	}
+	return udevMon.Health(), true
+}
+
 // interfacesRequestsManagerStop calls stop on the given manager. The state lock
 // must not be held while this function is called, as the manager may need to
 // record notices while it is stopping.
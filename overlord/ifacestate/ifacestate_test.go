@@ -41,6 +41,7 @@ import (
 	"github.com/snapcore/snapd/interfaces/builtin"
 	"github.com/snapcore/snapd/interfaces/hotplug"
 	"github.com/snapcore/snapd/interfaces/ifacetest"
+	"github.com/snapcore/snapd/interfaces/mount"
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/overlord"
@@ -55,6 +56,7 @@ import (
 	"github.com/snapcore/snapd/overlord/ifacestate/udevmonitor"
 	"github.com/snapcore/snapd/overlord/notices"
 	"github.com/snapcore/snapd/overlord/restart"
+	"github.com/snapcore/snapd/overlord/servicestate"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/snapstate/sequence"
 	"github.com/snapcore/snapd/overlord/snapstate/snapstatetest"
@@ -5978,6 +5980,140 @@ func (s *interfaceManagerSuite) TestDisconnectSetsUpSecurity(c *C) {
 	c.Check(s.secBackend.SetupCalls[1].Options, DeepEquals, interfaces.ConfinementOptions{KernelSnap: "krnl"})
 }
 
+const consumerServicesYaml = `
+name: consumer
+version: 1
+apps:
+ app:
+ svc2:
+  daemon: simple
+ svc1:
+  daemon: simple
+plugs:
+ plug:
+  interface: test
+`
+
+const producerServicesYaml = `
+name: producer
+version: 1
+apps:
+ svc:
+  daemon: simple
+slots:
+ slot:
+  interface: test
+`
+
+func (s *interfaceManagerSuite) mockMountingIface() {
+	s.mockIfaces(&ifacetest.TestInterface{
+		InterfaceName: "test",
+		MountConnectedPlugCallback: func(spec *mount.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+			return spec.AddMountEntry(osutil.MountEntry{Name: "/snap/producer/1/dir", Dir: "/snap/consumer/1/dir", Type: "none", Options: []string{"bind", "ro"}})
+		},
+	})
+}
+
+func servicesNeedingRestart(c *C, st *state.State) []string {
+	apps, err := servicestate.ServicesNeedingRestart(st)
+	c.Assert(err, IsNil)
+	var names []string
+	for _, app := range apps {
+		names = append(names, app.String())
+	}
+	return names
+}
+
+func (s *interfaceManagerSuite) TestAffectedServices(c *C) {
+	s.mockMountingIface()
+	s.mockSnap(c, consumerServicesYaml)
+	s.mockSnap(c, producerServicesYaml)
+	mgr := s.manager(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	connRef := &interfaces.ConnRef{
+		PlugRef: interfaces.PlugRef{Snap: "consumer", Name: "plug"},
+		SlotRef: interfaces.SlotRef{Snap: "producer", Name: "slot"},
+	}
+	apps, err := mgr.AffectedServices(connRef)
+	c.Assert(err, IsNil)
+	c.Assert(apps, HasLen, 2)
+	c.Check(apps[0].String(), Equals, "consumer.svc1")
+	c.Check(apps[1].String(), Equals, "consumer.svc2")
+}
+
+func (s *interfaceManagerSuite) TestAffectedServicesNoMounts(c *C) {
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"})
+	s.mockSnap(c, consumerServicesYaml)
+	s.mockSnap(c, producerServicesYaml)
+	mgr := s.manager(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	apps, err := mgr.AffectedServices(&interfaces.ConnRef{
+		PlugRef: interfaces.PlugRef{Snap: "consumer", Name: "plug"},
+		SlotRef: interfaces.SlotRef{Snap: "producer", Name: "slot"},
+	})
+	c.Assert(err, IsNil)
+	c.Check(apps, HasLen, 0)
+}
+
+func (s *interfaceManagerSuite) TestConnectMarksServicesNeedingRestart(c *C) {
+	s.MockModel(c, nil)
+	s.mockMountingIface()
+	s.mockSnap(c, consumerServicesYaml)
+	s.mockSnap(c, producerServicesYaml)
+	_ = s.manager(c)
+
+	s.state.Lock()
+	ts, err := ifacestate.Connect(s.state, "consumer", "plug", "producer", "slot")
+	c.Assert(err, IsNil)
+	change := s.state.NewChange("connect", "")
+	change.AddAll(ts)
+	s.state.Unlock()
+
+	s.settle(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	c.Assert(change.Err(), IsNil)
+	c.Check(servicesNeedingRestart(c, s.state), DeepEquals, []string{"consumer.svc1", "consumer.svc2"})
+}
+
+func (s *interfaceManagerSuite) TestDisconnectMarksServicesNeedingRestart(c *C) {
+	s.mockMountingIface()
+	s.mockSnap(c, consumerServicesYaml)
+	s.mockSnap(c, producerServicesYaml)
+
+	s.state.Lock()
+	s.state.Set("conns", map[string]any{
+		"consumer:plug producer:slot": map[string]any{"interface": "test"},
+	})
+	s.state.Unlock()
+
+	s.manager(c)
+	conn := s.getConnection(c, "consumer", "plug", "producer", "slot")
+
+	s.state.Lock()
+	ts, err := ifacestate.Disconnect(s.state, conn)
+	c.Assert(err, IsNil)
+	change := s.state.NewChange("disconnect", "")
+	change.AddAll(ts)
+	s.state.Unlock()
+
+	s.settle(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	c.Assert(change.Err(), IsNil)
+	c.Check(servicesNeedingRestart(c, s.state), DeepEquals, []string{"consumer.svc1", "consumer.svc2"})
+}
+
 func (s *interfaceManagerSuite) TestDisconnectTracksConnectionsInState(c *C) {
 	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"}, &ifacetest.TestInterface{InterfaceName: "test2"})
 	s.mockSnap(c, consumerYaml)
@@ -8636,6 +8772,326 @@ func (s *interfaceManagerSuite) TestDisconnectInterfaces(c *C) {
 	}
 }
 
+func (s *interfaceManagerSuite) TestCopyInstanceConnections(c *C) {
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"}, &ifacetest.TestInterface{InterfaceName: "test2"})
+	mgr := s.manager(c)
+
+	consumerInfo := s.mockSnap(c, consumerYaml)
+	consumerInstanceInfo := s.mockSnapInstance(c, "consumer_foo", consumerYaml)
+	producerInfo := s.mockSnap(c, producerYaml)
+
+	repo := mgr.Repository()
+	for _, info := range []*snap.Info{consumerInfo, consumerInstanceInfo, producerInfo} {
+		appSet, err := interfaces.NewSnapAppSet(info, nil)
+		c.Assert(err, IsNil)
+		c.Assert(repo.AddAppSet(appSet), IsNil)
+	}
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.state.Set("conns", map[string]any{
+		"consumer:plug producer:slot":           map[string]any{"interface": "test", "auto": true},
+		"consumer:otherplug producer:otherslot": map[string]any{"interface": "test2", "undesired": true},
+	})
+
+	chg := s.state.NewChange("rename-instance", "")
+	t := s.state.NewTask("copy-instance-connections", "")
+	t.Set("snap-setup", &snapstate.SnapSetup{
+		SideInfo:    &snap.SideInfo{RealName: "consumer", Revision: snap.R(1)},
+		InstanceKey: "foo",
+	})
+	t.Set("from-instance", "consumer")
+	chg.AddTask(t)
+
+	s.state.Unlock()
+	s.se.Ensure()
+	s.se.Wait()
+	s.state.Lock()
+
+	c.Assert(t.Status(), Equals, state.DoneStatus)
+
+	var connects []*state.Task
+	for _, ct := range chg.Tasks() {
+		if ct.Kind() == "connect" {
+			connects = append(connects, ct)
+		}
+	}
+	// the undesired connection is not copied
+	c.Assert(connects, HasLen, 1)
+	var plug interfaces.PlugRef
+	var slot interfaces.SlotRef
+	var auto bool
+	c.Assert(connects[0].Get("plug", &plug), IsNil)
+	c.Assert(connects[0].Get("slot", &slot), IsNil)
+	c.Assert(connects[0].Get("auto", &auto), IsNil)
+	c.Check(plug, Equals, interfaces.PlugRef{Snap: "consumer_foo", Name: "plug"})
+	c.Check(slot, Equals, interfaces.SlotRef{Snap: "producer", Name: "slot"})
+	c.Check(auto, Equals, true)
+}
+
+func (s *interfaceManagerSuite) TestAutoDisconnectRemembersManualConnections(c *C) {
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"}, &ifacetest.TestInterface{InterfaceName: "test2"})
+	mgr := s.manager(c)
+
+	consumerInfo := s.mockSnap(c, consumerYaml)
+	producerInfo := s.mockSnap(c, producerYaml)
+
+	repo := mgr.Repository()
+	for _, info := range []*snap.Info{consumerInfo, producerInfo} {
+		appSet, err := interfaces.NewSnapAppSet(info, nil)
+		c.Assert(err, IsNil)
+		c.Assert(repo.AddAppSet(appSet), IsNil)
+	}
+	_, err := repo.Connect(&interfaces.ConnRef{
+		PlugRef: interfaces.PlugRef{Snap: "consumer", Name: "plug"},
+		SlotRef: interfaces.SlotRef{Snap: "producer", Name: "slot"},
+	}, nil, nil, nil, nil, nil)
+	c.Assert(err, IsNil)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.state.Set("conns", map[string]any{
+		"consumer:plug producer:slot":           map[string]any{"interface": "test"},
+		"consumer:otherplug producer:otherslot": map[string]any{"interface": "test2", "auto": true, "undesired": true},
+	})
+
+	chg := s.state.NewChange("remove", "")
+	t := s.state.NewTask("auto-disconnect", "")
+	t.Set("snap-setup", &snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{RealName: "consumer", SnapID: "consumer-id", Revision: snap.R(3)},
+		Flags:    snapstate.Flags{KeepConnections: true},
+	})
+	chg.AddTask(t)
+
+	before := time.Now()
+	s.state.Unlock()
+	s.se.Ensure()
+	s.se.Wait()
+	s.state.Lock()
+
+	c.Assert(t.Status(), Equals, state.DoneStatus)
+
+	var removed map[string]map[string]any
+	c.Assert(s.state.Get("removed-conns", &removed), IsNil)
+	c.Assert(removed, HasLen, 1)
+	removedAt, err := time.Parse(time.RFC3339Nano, removed["consumer"]["removed-at"].(string))
+	c.Assert(err, IsNil)
+	c.Check(removedAt.Before(before), Equals, false)
+	delete(removed["consumer"], "removed-at")
+	// only the manual connection is remembered
+	c.Check(removed["consumer"], DeepEquals, map[string]any{
+		"snap-id":  "consumer-id",
+		"revision": "3",
+		"kept":     true,
+		"conns": map[string]any{
+			"consumer:plug producer:slot": "test",
+		},
+	})
+}
+
+// setupRestoreConnections mocks a consumer snap that is being installed
+// again and the manual connections of its previous installation.
+func (s *interfaceManagerSuite) setupRestoreConnections(c *C, removedAt time.Time, revision snap.Revision) (*ifacestate.InterfaceManager, *snapstate.SnapSetup) {
+	// prevent the connections from being made automatically
+	noAutoConnect := func(*snap.PlugInfo, *snap.SlotInfo) bool { return false }
+	s.mockIfaces(
+		&ifacetest.TestInterface{InterfaceName: "test", AutoConnectCallback: noAutoConnect},
+		&ifacetest.TestInterface{InterfaceName: "test2", AutoConnectCallback: noAutoConnect},
+	)
+	s.MockModel(c, nil)
+
+	consumerInfo := s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+	mgr := s.manager(c)
+
+	s.state.Lock()
+	s.state.Set("removed-conns", map[string]any{
+		"consumer": map[string]any{
+			"revision":   revision.String(),
+			"removed-at": removedAt,
+			"conns": map[string]any{
+				"consumer:plug producer:slot": "test",
+				// the producer has no such slot anymore
+				"consumer:otherplug producer:otherslot": "test2",
+			},
+		},
+	})
+	s.state.Unlock()
+
+	return mgr, &snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{
+			RealName: consumerInfo.SnapName(),
+			Revision: consumerInfo.Revision,
+		},
+	}
+}
+
+func (s *interfaceManagerSuite) TestAutoConnectRestoresRemovedConnections(c *C) {
+	mgr, snapsup := s.setupRestoreConnections(c, time.Now().Add(-time.Hour), snap.R(1))
+
+	change := s.addSetupSnapSecurityChange(c, snapsup)
+	s.settle(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	c.Assert(change.Err(), IsNil)
+	c.Assert(change.Status(), Equals, state.DoneStatus)
+
+	// the connection is restored as a manual one
+	var conns map[string]any
+	c.Assert(s.state.Get("conns", &conns), IsNil)
+	c.Check(conns, DeepEquals, map[string]any{
+		"consumer:plug producer:slot": map[string]any{
+			"interface":   "test",
+			"plug-static": map[string]any{"attr1": "value1"},
+			"slot-static": map[string]any{"attr2": "value2"},
+		},
+	})
+	c.Check(mgr.Repository().Interfaces().Connections, DeepEquals, []*interfaces.ConnRef{{
+		PlugRef: interfaces.PlugRef{Snap: "consumer", Name: "plug"},
+		SlotRef: interfaces.SlotRef{Snap: "producer", Name: "slot"}}})
+
+	// and forgotten
+	var removed map[string]any
+	c.Check(s.state.Get("removed-conns", &removed), testutil.ErrorIs, state.ErrNoState)
+
+	var autoConnect *state.Task
+	for _, t := range change.Tasks() {
+		if t.Kind() == "auto-connect" {
+			autoConnect = t
+		}
+	}
+	c.Assert(autoConnect, NotNil)
+	log := strings.Join(autoConnect.Log(), "\n")
+	c.Check(log, testutil.Contains, `Cannot restore connection consumer:otherplug producer:otherslot: plug or slot not found`)
+	c.Check(log, testutil.Contains, `Restoring connections of the previous installation of snap "consumer": consumer:plug producer:slot`)
+
+	notices := s.state.Notices(&state.NoticeFilter{Types: []state.NoticeType{state.InterfacesConnectionsRestoredNotice}})
+	c.Assert(notices, HasLen, 1)
+	c.Check(notices[0].Key(), Equals, "consumer")
+	c.Check(notices[0].LastData(), DeepEquals, map[string]string{"connections": "consumer:plug producer:slot"})
+}
+
+func (s *interfaceManagerSuite) TestAutoConnectRestoresKeptRemovedConnections(c *C) {
+	mgr, snapsup := s.setupRestoreConnections(c, time.Now().Add(-90*24*time.Hour), snap.R(1))
+
+	// connections kept on removal do not expire
+	s.state.Lock()
+	var removed map[string]map[string]any
+	c.Assert(s.state.Get("removed-conns", &removed), IsNil)
+	removed["consumer"]["kept"] = true
+	s.state.Set("removed-conns", removed)
+	s.state.Unlock()
+
+	change := s.addSetupSnapSecurityChange(c, snapsup)
+	s.settle(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	c.Assert(change.Err(), IsNil)
+	c.Check(mgr.Repository().Interfaces().Connections, DeepEquals, []*interfaces.ConnRef{{
+		PlugRef: interfaces.PlugRef{Snap: "consumer", Name: "plug"},
+		SlotRef: interfaces.SlotRef{Snap: "producer", Name: "slot"}}})
+	c.Check(s.state.Get("removed-conns", &removed), testutil.ErrorIs, state.ErrNoState)
+}
+
+func (s *interfaceManagerSuite) TestAutoConnectRestoresRemovedConnectionsUndo(c *C) {
+	_, snapsup := s.setupRestoreConnections(c, time.Now().Add(-time.Hour), snap.R(1))
+
+	change := s.addSetupSnapSecurityChange(c, snapsup)
+	s.state.Lock()
+	terr := s.state.NewTask("error-trigger", "provoking total undo")
+	terr.WaitAll(state.NewTaskSet(change.Tasks()...))
+	// the tasks of the change run in their own lane
+	for _, lane := range change.Tasks()[0].Lanes() {
+		terr.JoinLane(lane)
+	}
+	change.AddTask(terr)
+	s.state.Unlock()
+
+	s.settle(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	c.Assert(change.Status(), Equals, state.ErrorStatus)
+
+	// the connections of the removed snap are remembered again
+	var removed map[string]map[string]any
+	c.Assert(s.state.Get("removed-conns", &removed), IsNil)
+	c.Check(removed["consumer"]["conns"], DeepEquals, map[string]any{
+		"consumer:plug producer:slot":           "test",
+		"consumer:otherplug producer:otherslot": "test2",
+	})
+}
+
+func (s *interfaceManagerSuite) testAutoConnectNotRestoringRemovedConnections(c *C, removedAt time.Time, revision snap.Revision, mutate func(*snapstate.SnapSetup), expectedLog string) {
+	mgr, snapsup := s.setupRestoreConnections(c, removedAt, revision)
+	if mutate != nil {
+		mutate(snapsup)
+	}
+
+	change := s.addSetupSnapSecurityChange(c, snapsup)
+	s.settle(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	c.Assert(change.Err(), IsNil)
+	c.Assert(change.Status(), Equals, state.DoneStatus)
+
+	var conns map[string]any
+	err := s.state.Get("conns", &conns)
+	if !errors.Is(err, state.ErrNoState) {
+		c.Assert(err, IsNil)
+	}
+	c.Check(conns, HasLen, 0)
+	c.Check(mgr.Repository().Interfaces().Connections, HasLen, 0)
+
+	var removed map[string]any
+	c.Check(s.state.Get("removed-conns", &removed), testutil.ErrorIs, state.ErrNoState)
+
+	for _, t := range change.Tasks() {
+		if t.Kind() != "auto-connect" {
+			continue
+		}
+		if expectedLog == "" {
+			c.Check(t.Log(), HasLen, 0)
+		} else {
+			c.Assert(t.Log(), HasLen, 1)
+			c.Check(t.Log()[0], Matches, ".* "+expectedLog)
+		}
+	}
+
+	notices := s.state.Notices(&state.NoticeFilter{Types: []state.NoticeType{state.InterfacesConnectionsRestoredNotice}})
+	c.Check(notices, HasLen, 0)
+}
+
+func (s *interfaceManagerSuite) TestAutoConnectNotRestoringRemovedConnectionsExpired(c *C) {
+	s.testAutoConnectNotRestoringRemovedConnections(c, time.Now().Add(-31*24*time.Hour), snap.R(1), nil, "")
+}
+
+func (s *interfaceManagerSuite) TestAutoConnectNotRestoringRemovedConnectionsOptOut(c *C) {
+	s.testAutoConnectNotRestoringRemovedConnections(c, time.Now(), snap.R(1), func(snapsup *snapstate.SnapSetup) {
+		snapsup.Flags.NoRestoreConnections = true
+	}, `Not restoring connections of the previous installation of snap "consumer" as requested`)
+}
+
+func (s *interfaceManagerSuite) TestAutoConnectNotRestoringRemovedConnectionsOlderRevision(c *C) {
+	s.testAutoConnectNotRestoringRemovedConnections(c, time.Now(), snap.R(2), nil,
+		`Not restoring connections of the previous installation of snap "consumer": revision 1 is older than the removed revision 2`)
+}
+
+func (s *interfaceManagerSuite) TestAutoConnectNotRestoringRemovedConnectionsSnapIDMismatch(c *C) {
+	s.testAutoConnectNotRestoringRemovedConnections(c, time.Now(), snap.R(1), func(snapsup *snapstate.SnapSetup) {
+		snapsup.SideInfo.SnapID = "other-snap-id"
+	}, `Not restoring connections of the previous installation of snap "consumer": snap ID mismatch`)
+}
+
 func (s *interfaceManagerSuite) testDisconnectInterfacesRetry(c *C, conflictingKind string) {
 	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"})
 	_ = s.manager(c)
@@ -9205,6 +9661,7 @@ type udevMonitorMock struct {
 	AddDevice                         udevmonitor.DeviceAddedFunc
 	RemoveDevice                      udevmonitor.DeviceRemovedFunc
 	EnumerationDone                   udevmonitor.EnumerationDoneFunc
+	MockHealth                        udevmonitor.Health
 }
 
 func (u *udevMonitorMock) Connect() error {
@@ -9222,6 +9679,10 @@ func (u *udevMonitorMock) Stop() error {
 	return nil
 }
 
+func (u *udevMonitorMock) Health() udevmonitor.Health {
+	return u.MockHealth
+}
+
 func (s *interfaceManagerSuite) TestUDevMonitorInit(c *C) {
 	u := udevMonitorMock{}
 	st := s.state
@@ -9311,6 +9772,50 @@ func (s *interfaceManagerSuite) TestUDevMonitorInitErrors(c *C) {
 	c.Assert(u.StopCalls, Equals, 1)
 }
 
+func (s *interfaceManagerSuite) TestUDevMonitorHealth(c *C) {
+	u := udevMonitorMock{
+		MockHealth: udevmonitor.Health{Running: true, EventsReceived: 42},
+	}
+	st := s.state
+	st.Lock()
+	snapstate.Set(s.state, "core", &snapstate.SnapState{
+		Active: true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+			{RealName: "core", Revision: snap.R(1)},
+		}),
+		Current:  snap.R(1),
+		SnapType: "os",
+	})
+	st.Unlock()
+	s.mockSnap(c, coreSnapYaml)
+
+	restoreTimeout := ifacestate.MockUDevInitRetryTimeout(0 * time.Second)
+	defer restoreTimeout()
+
+	restoreCreate := ifacestate.MockCreateUDevMonitor(func(udevmonitor.DeviceAddedFunc, udevmonitor.DeviceRemovedFunc, udevmonitor.EnumerationDoneFunc) udevmonitor.Interface {
+		return &u
+	})
+	defer restoreCreate()
+
+	mgr, err := ifacestate.Manager(s.state, nil, nil, s.o.TaskRunner(), nil, nil)
+	c.Assert(err, IsNil)
+	s.o.AddManager(mgr)
+	c.Assert(s.o.StartUp(), IsNil)
+
+	// not running yet
+	_, ok := mgr.UDevMonitorHealth()
+	c.Check(ok, Equals, false)
+
+	c.Assert(s.se.Ensure(), IsNil)
+	health, ok := mgr.UDevMonitorHealth()
+	c.Check(ok, Equals, true)
+	c.Check(health, DeepEquals, u.MockHealth)
+
+	s.se.Stop()
+	_, ok = mgr.UDevMonitorHealth()
+	c.Check(ok, Equals, false)
+}
+
 func (s *interfaceManagerSuite) TestUDevMonitorInitWaitsForCore(c *C) {
 	restoreTimeout := ifacestate.MockUDevInitRetryTimeout(0 * time.Second)
 	defer restoreTimeout()
@@ -12762,7 +13267,7 @@ func (s *interfaceManagerSuite) TestSystemKeyMismatchCompat(c *C) {
 }
 
 func (s *interfaceManagerSuite) TestEnsureLoopLogging(c *C) {
-	testutil.CheckEnsureLoopLogging("ifacemgr.go", c, false)
+	testutil.CheckEnsureLoopLogging("ifacemgr.go", c, true)
 }
 
 func (s *interfaceManagerSuite) setCompatEnabledFeature(c *C) {
@@ -0,0 +1,271 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ifacestate
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/tomb.v2"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/assertstate"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/overlord/swfeats"
+	"github.com/snapcore/snapd/snap"
+)
+
+var reevaluateInterfacePolicyChangeKind = swfeats.RegisterChangeKind("reevaluate-interface-policy")
+
+// policyRevisions records the revisions of the assertions making up the
+// interface policy when it was last evaluated for the installed snaps.
+type policyRevisions struct {
+	BaseDeclaration int `json:"base-declaration"`
+	// SnapDeclarations maps snap IDs to the revisions of their
+	// snap-declarations.
+	SnapDeclarations map[string]int `json:"snap-declarations,omitempty"`
+}
+
+func (r *policyRevisions) equal(other *policyRevisions) bool {
+	if r.BaseDeclaration != other.BaseDeclaration || len(r.SnapDeclarations) != len(other.SnapDeclarations) {
+		return false
+	}
+	for snapID, rev := range r.SnapDeclarations {
+		if otherRev, ok := other.SnapDeclarations[snapID]; !ok || otherRev != rev {
+			return false
+		}
+	}
+	return true
+}
+
+func currentPolicyRevisions(st *state.State, snapStates map[string]*snapstate.SnapState) (*policyRevisions, error) {
+	baseDecl, err := assertstate.BaseDeclaration(st)
+	if err != nil {
+		return nil, fmt.Errorf("internal error: cannot find base declaration: %v", err)
+	}
+	revs := &policyRevisions{
+		BaseDeclaration:  baseDecl.Revision(),
+		SnapDeclarations: make(map[string]int, len(snapStates)),
+	}
+	for _, snapst := range snapStates {
+		snapID := snapst.CurrentSideInfo().SnapID
+		if snapID == "" {
+			continue
+		}
+		snapDecl, err := assertstate.SnapDeclaration(st, snapID)
+		if errors.Is(err, &asserts.NotFoundError{}) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		revs.SnapDeclarations[snapID] = snapDecl.Revision()
+	}
+	return revs, nil
+}
+
+// ensurePolicyReevaluation compares the revisions of the base-declaration
+// and of the snap-declarations of the installed snaps with those recorded
+// when the interface policy was last evaluated. When some were refreshed,
+// it creates a change re-evaluating the auto-connections of the affected
+// snaps, so that the new policy takes effect without restarting snapd.
+func (m *InterfaceManager) ensurePolicyReevaluation() error {
+	logger.Trace("ensure", "manager", "InterfaceManager", "func", "ensurePolicyReevaluation")
+	st := m.state
+	st.Lock()
+	defer st.Unlock()
+
+	var seeded bool
+	err := st.Get("seeded", &seeded)
+	if err != nil && !errors.Is(err, state.ErrNoState) {
+		return err
+	}
+	if !seeded {
+		return nil
+	}
+
+	for _, chg := range st.Changes() {
+		if chg.Kind() == reevaluateInterfacePolicyChangeKind && !chg.IsReady() {
+			// wait for it, the revisions are compared again afterwards
+			return nil
+		}
+	}
+
+	snapStates, err := snapstate.All(st)
+	if err != nil {
+		return err
+	}
+	current, err := currentPolicyRevisions(st, snapStates)
+	if err != nil {
+		return err
+	}
+
+	var recorded policyRevisions
+	err = st.Get("interfaces-policy-revisions", &recorded)
+	if errors.Is(err, state.ErrNoState) {
+		// nothing to compare with yet
+		st.Set("interfaces-policy-revisions", current)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if current.equal(&recorded) {
+		return nil
+	}
+	st.Set("interfaces-policy-revisions", current)
+
+	var affected []string
+	for instanceName, snapst := range snapStates {
+		snapID := snapst.CurrentSideInfo().SnapID
+		if current.BaseDeclaration != recorded.BaseDeclaration ||
+			(snapID != "" && current.SnapDeclarations[snapID] != recorded.SnapDeclarations[snapID]) {
+			affected = append(affected, instanceName)
+		}
+	}
+	if len(affected) == 0 {
+		return nil
+	}
+	sort.Strings(affected)
+
+	chg := st.NewChange(reevaluateInterfacePolicyChangeKind, "Re-evaluate interface policy")
+	t := st.NewTask(reevaluateInterfacePolicyChangeKind, fmt.Sprintf("Re-evaluate auto-connections of snaps %s", strings.Join(affected, ", ")))
+	t.Set("snaps", affected)
+	chg.AddTask(t)
+	st.EnsureBefore(0)
+
+	return nil
+}
+
+// doReevaluateInterfacePolicy creates tasks auto-connecting the given snaps
+// to the candidates that the current interface policy allows. The security
+// profiles of the snaps are regenerated by the connect tasks.
+func (m *InterfaceManager) doReevaluateInterfacePolicy(task *state.Task, _ *tomb.Tomb) error {
+	st := task.State()
+	st.Lock()
+	defer st.Unlock()
+
+	var snapNames []string
+	if err := task.Get("snaps", &snapNames); err != nil {
+		return fmt.Errorf("internal error: cannot get snaps to re-evaluate: %v", err)
+	}
+
+	deviceCtx, err := snapstate.DeviceCtx(st, task, nil)
+	if err != nil {
+		return err
+	}
+
+	conns, err := getConns(st)
+	if err != nil {
+		return err
+	}
+
+	autochecker, err := newAutoConnectChecker(st, m.repo, deviceCtx)
+	if err != nil {
+		return err
+	}
+
+	conflictError := func(retry *state.Retry, err error) error {
+		if retry != nil {
+			task.Logf("Waiting for conflicting change in progress: %s", retry.Reason)
+			return retry // will retry
+		}
+		return fmt.Errorf("auto-connect conflict check failed: %v", err)
+	}
+
+	newconns := make(map[string]*interfaces.ConnRef)
+	for _, snapName := range snapNames {
+		var snapst snapstate.SnapState
+		err := snapstate.Get(st, snapName, &snapst)
+		if errors.Is(err, state.ErrNoState) {
+			// removed in the meantime
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !snapst.Active {
+			continue
+		}
+
+		snapID := snapst.CurrentSideInfo().SnapID
+		checkAutoConnectAllowed := func(css []*snap.SlotInfo) []*snap.SlotInfo {
+			filtered, err := filterAllowedAutoConnectionSlots(st, snapID, css)
+			if err != nil {
+				task.Logf("failed to filter auto-connection slots: %v", err)
+			}
+			return filtered
+		}
+		cannotAutoConnectLog := func(plug *snap.PlugInfo, candRefs []string) string {
+			return fmt.Sprintf("cannot auto-connect plug %s, candidates found: %s", plug, strings.Join(candRefs, ", "))
+		}
+		if err := autochecker.addAutoConnections(task, newconns, m.repo.Plugs(snapName), checkAutoConnectAllowed, conns, cannotAutoConnectLog, conflictError); err != nil {
+			return err
+		}
+
+		for _, slot := range m.repo.Slots(snapName) {
+			candidates := m.repo.AutoConnectCandidatePlugs(snapName, slot.Name, autochecker.check)
+			if len(candidates) == 0 {
+				continue
+			}
+			cannotAutoConnectLog := func(plug *snap.PlugInfo, candRefs []string) string {
+				return fmt.Sprintf("cannot auto-connect slot %s to plug %s, candidates found: %s", slot, plug, strings.Join(candRefs, ", "))
+			}
+			if err := autochecker.addAutoConnections(task, newconns, candidates, filterForSlot(slot), conns, cannotAutoConnectLog, conflictError); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(newconns) == 0 {
+		task.Logf("No new auto-connections")
+		return nil
+	}
+
+	connIDs := make([]string, 0, len(newconns))
+	for id := range newconns {
+		connIDs = append(connIDs, id)
+	}
+	sort.Strings(connIDs)
+
+	connectTs := state.NewTaskSet()
+	for _, id := range connIDs {
+		conn := newconns[id]
+		ts, err := connect(st, conn.PlugRef.Snap, conn.PlugRef.Name, conn.SlotRef.Snap, conn.SlotRef.Name, connectOpts{AutoConnect: true})
+		if err != nil {
+			return fmt.Errorf("internal error: auto-connect of %q failed: %s", conn, err)
+		}
+		connectTs.AddAll(ts)
+	}
+	task.Logf("Auto-connecting %s", strings.Join(connIDs, ", "))
+
+	snapstate.InjectTasks(task, connectTs)
+	st.EnsureBefore(0)
+
+	// add the tasks and mark this task done in the same atomic write
+	task.SetStatus(state.DoneStatus)
+
+	return nil
+}
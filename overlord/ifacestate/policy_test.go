@@ -0,0 +1,191 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ifacestate_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/testutil"
+)
+
+func (s *interfaceManagerSuite) mockPolicyReevaluation(c *C) {
+	restore := s.mockBaseDeclaration(c, s.state, []byte(`
+type: base-declaration
+account-id: system
+authority-id: canonical
+series: 16
+slots:
+  test:
+    allow-auto-connection: false
+`))
+	s.AddCleanup(restore)
+
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"}, &ifacetest.TestInterface{InterfaceName: "test2"})
+	s.MockSnapDecl(c, "producer", "one-publisher", nil)
+	s.mockSnap(c, producerYaml)
+	s.MockSnapDecl(c, "consumer", "one-publisher", nil)
+	s.mockSnap(c, consumerYaml)
+}
+
+func (s *interfaceManagerSuite) refreshConsumerSnapDecl(c *C) {
+	// the refreshed snap-declaration allows auto-connecting the plug
+	s.MockSnapDecl(c, "consumer", "one-publisher", map[string]any{
+		"format":   "1",
+		"revision": "1",
+		"plugs": map[string]any{
+			"test": map[string]any{
+				"allow-auto-connection": "true",
+			},
+		},
+	})
+}
+
+func (s *interfaceManagerSuite) TestReevaluateInterfacePolicyOnSnapDeclarationRefresh(c *C) {
+	s.mockPolicyReevaluation(c)
+	s.state.Lock()
+	s.state.Set("seeded", true)
+	s.state.Unlock()
+
+	mgr := s.manager(c)
+
+	// the first run only records the revisions of the declarations
+	s.settle(c)
+	s.state.Lock()
+	c.Check(s.state.Changes(), HasLen, 0)
+	var revs map[string]any
+	c.Assert(s.state.Get("interfaces-policy-revisions", &revs), IsNil)
+	c.Check(revs, DeepEquals, map[string]any{
+		"base-declaration": 0.,
+		"snap-declarations": map[string]any{
+			"consumeridididididididididididid": 0.,
+			"produceridididididididididididid": 0.,
+		},
+	})
+	s.state.Unlock()
+
+	s.refreshConsumerSnapDecl(c)
+	s.settle(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	chgs := s.state.Changes()
+	c.Assert(chgs, HasLen, 1)
+	chg := chgs[0]
+	c.Check(chg.Kind(), Equals, "reevaluate-interface-policy")
+	c.Check(chg.Status(), Equals, state.DoneStatus)
+	t := chg.Tasks()[0]
+	c.Check(t.Kind(), Equals, "reevaluate-interface-policy")
+	c.Check(t.Summary(), Equals, "Re-evaluate auto-connections of snaps consumer")
+	// connect tasks and their interface hooks were injected
+	c.Check(len(chg.Tasks()) > 1, Equals, true)
+
+	var conns map[string]any
+	c.Assert(s.state.Get("conns", &conns), IsNil)
+	c.Check(conns, DeepEquals, map[string]any{
+		"consumer:plug producer:slot": map[string]any{
+			"interface": "test", "auto": true,
+			"plug-static": map[string]any{"attr1": "value1"},
+			"slot-static": map[string]any{"attr2": "value2"},
+		},
+	})
+	c.Check(mgr.Repository().Interfaces().Connections, HasLen, 1)
+
+	revs = nil
+	c.Assert(s.state.Get("interfaces-policy-revisions", &revs), IsNil)
+	c.Check(revs["snap-declarations"], DeepEquals, map[string]any{
+		"consumeridididididididididididid": 1.,
+		"produceridididididididididididid": 0.,
+	})
+}
+
+func (s *interfaceManagerSuite) TestReevaluateInterfacePolicyNothingToConnect(c *C) {
+	s.mockPolicyReevaluation(c)
+	s.state.Lock()
+	s.state.Set("seeded", true)
+	s.state.Unlock()
+
+	mgr := s.manager(c)
+	s.settle(c)
+
+	// the refreshed snap-declaration of the producer does not allow
+	// anything more
+	s.MockSnapDecl(c, "producer", "one-publisher", map[string]any{
+		"revision": "1",
+	})
+	s.settle(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	chgs := s.state.Changes()
+	c.Assert(chgs, HasLen, 1)
+	c.Check(chgs[0].Status(), Equals, state.DoneStatus)
+	c.Check(chgs[0].Tasks(), HasLen, 1)
+	c.Check(chgs[0].Tasks()[0].Summary(), Equals, "Re-evaluate auto-connections of snaps producer")
+	c.Check(mgr.Repository().Interfaces().Connections, HasLen, 0)
+}
+
+func (s *interfaceManagerSuite) TestReevaluateInterfacePolicyNotSeeded(c *C) {
+	s.mockPolicyReevaluation(c)
+
+	s.manager(c)
+	s.settle(c)
+	s.refreshConsumerSnapDecl(c)
+	s.settle(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	c.Check(s.state.Changes(), HasLen, 0)
+	var revs map[string]any
+	c.Check(s.state.Get("interfaces-policy-revisions", &revs), testutil.ErrorIs, state.ErrNoState)
+}
+
+func (s *interfaceManagerSuite) TestReevaluateInterfacePolicyWaitsForPendingChange(c *C) {
+	s.mockPolicyReevaluation(c)
+	s.state.Lock()
+	s.state.Set("seeded", true)
+	s.state.Unlock()
+
+	mgr := s.manager(c)
+	s.settle(c)
+
+	s.state.Lock()
+	chg := s.state.NewChange("reevaluate-interface-policy", "...")
+	chg.AddTask(s.state.NewTask("other", "..."))
+	s.state.Unlock()
+
+	s.refreshConsumerSnapDecl(c)
+	c.Assert(mgr.Ensure(), IsNil)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	c.Check(s.state.Changes(), HasLen, 1)
+	var revs map[string]any
+	c.Assert(s.state.Get("interfaces-policy-revisions", &revs), IsNil)
+	c.Check(revs["snap-declarations"], DeepEquals, map[string]any{
+		"consumeridididididididididididid": 0.,
+		"produceridididididididididididid": 0.,
+	})
+}
@@ -0,0 +1,48 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package udevmonitor
+
+import (
+	"time"
+
+	"github.com/snapcore/snapd/interfaces/hotplug"
+	"github.com/snapcore/snapd/osutil/udev/netlink"
+	"github.com/snapcore/snapd/testutil"
+)
+
+func MockDebounceDelay(d time.Duration) (restore func()) {
+	return testutil.Mock(&debounceDelay, d)
+}
+
+func MockMaxPendingDevices(n int) (restore func()) {
+	return testutil.Mock(&maxPendingDevices, n)
+}
+
+func MockTimeNow(f func() time.Time) (restore func()) {
+	return testutil.Mock(&timeNow, f)
+}
+
+func (m *Monitor) QueueEvent(action netlink.KObjAction, dev *hotplug.HotplugDeviceInfo, now time.Time) bool {
+	return m.queueEvent(action, dev, now)
+}
+
+func (m *Monitor) DeliverDue(now time.Time) (next time.Duration, ok bool) {
+	return m.deliverDue(now)
+}
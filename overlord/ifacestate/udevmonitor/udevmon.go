@@ -21,6 +21,7 @@ package udevmonitor
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"gopkg.in/tomb.v2"
@@ -34,12 +35,101 @@ type Interface interface {
 	Connect() error
 	Run() error
 	Stop() error
+	Health() Health
 }
 
 type DeviceAddedFunc func(device *hotplug.HotplugDeviceInfo)
 type DeviceRemovedFunc func(device *hotplug.HotplugDeviceInfo)
 type EnumerationDoneFunc func()
 
+var (
+	// debounceDelay is how long events for a device are held back
+	// waiting for more events for the same device, so that bursts of
+	// events (e.g. a hub with many devices being plugged and unplugged)
+	// are coalesced before being delivered.
+	debounceDelay = 250 * time.Millisecond
+	// maxPendingDevices bounds the number of devices with events that
+	// were not delivered yet, events for other devices are dropped when
+	// the queue is full.
+	maxPendingDevices = 512
+
+	timeNow = time.Now
+)
+
+// Health reports the state of the udev monitor and the rate of the events
+// it observes.
+type Health struct {
+	Running         bool `json:"running"`
+	EnumerationDone bool `json:"enumeration-done"`
+	// EventsReceived counts the add and remove events received from udev.
+	EventsReceived uint64 `json:"events-received"`
+	// EventsCoalesced counts the events that were superseded by a later
+	// event for the same device before being delivered.
+	EventsCoalesced uint64 `json:"events-coalesced"`
+	// EventsDropped counts the events dropped because the queue was full.
+	EventsDropped uint64 `json:"events-dropped"`
+	// Errors counts the errors reported by the netlink connection.
+	Errors        uint64    `json:"errors"`
+	QueueLength   int       `json:"queue-length"`
+	QueueCapacity int       `json:"queue-capacity"`
+	LastEvent     time.Time `json:"last-event,omitzero"`
+	// EventRate10s and EventRate1m are the average number of events per
+	// second over the last 10 seconds and the last minute.
+	EventRate10s float64 `json:"event-rate-10s"`
+	EventRate1m  float64 `json:"event-rate-1m"`
+}
+
+type pendingEvent struct {
+	action netlink.KObjAction
+	dev    *hotplug.HotplugDeviceInfo
+}
+
+// pendingDevice holds the coalesced events of a device that were not
+// delivered yet, which are one of: add, remove, or remove followed by add.
+type pendingDevice struct {
+	devPath string
+	due     time.Time
+	events  []pendingEvent
+}
+
+// eventRate counts events in one second buckets over the last minute.
+type eventRate struct {
+	buckets [60]uint64
+	// last is the unix time of the most recent bucket
+	last int64
+}
+
+func (r *eventRate) advance(now time.Time) {
+	sec := now.Unix()
+	if sec <= r.last {
+		return
+	}
+	for i := r.last + 1; i <= sec && i <= r.last+int64(len(r.buckets)); i++ {
+		r.buckets[i%int64(len(r.buckets))] = 0
+	}
+	r.last = sec
+}
+
+func (r *eventRate) add(t time.Time) {
+	r.advance(t)
+	sec := t.Unix()
+	if r.last-sec >= int64(len(r.buckets)) {
+		return
+	}
+	r.buckets[sec%int64(len(r.buckets))]++
+}
+
+// rate returns the average events per second over the last given seconds,
+// including the current one.
+func (r *eventRate) rate(now time.Time, seconds int) float64 {
+	r.advance(now)
+	var total uint64
+	for i := 0; i < seconds; i++ {
+		total += r.buckets[(r.last-int64(i))%int64(len(r.buckets))]
+	}
+	return float64(total) / float64(seconds)
+}
+
 // Monitor monitors kernel uevents making it possible to find hotpluggable devices.
 type Monitor struct {
 	tomb            tomb.Tomb
@@ -60,6 +150,20 @@ type Monitor struct {
 	// removed.  the lookup is not persisted and gets populated
 	// and updated in response to enumeration and hotplug events.
 	seen map[string]bool
+
+	// wakeup is signalled when new events are queued
+	wakeup chan struct{}
+
+	// mu protects the fields below, which are shared between the
+	// goroutine receiving events and the one delivering them
+	mu sync.Mutex
+	// pending maps device paths to their queued events, queue keeps
+	// them in the order they are due
+	pending         map[string]*pendingDevice
+	queue           []*pendingDevice
+	health          Health
+	rate            eventRate
+	reportedDropped uint64
 }
 
 func New(added DeviceAddedFunc, removed DeviceRemovedFunc, enumerationDone EnumerationDoneFunc) Interface {
@@ -69,6 +173,8 @@ func New(added DeviceAddedFunc, removed DeviceRemovedFunc, enumerationDone Enume
 		enumerationDone: enumerationDone,
 		netlinkConn:     &netlink.UEventConn{},
 		seen:            make(map[string]bool),
+		wakeup:          make(chan struct{}, 1),
+		pending:         make(map[string]*pendingDevice),
 	}
 
 	m.netlinkEvents = make(chan netlink.UEvent)
@@ -114,9 +220,9 @@ func (m *Monitor) disconnect() error {
 	return m.netlinkConn.Close()
 }
 
-// Run enumerates existing USB devices and starts a new goroutine that
-// handles hotplug events (devices added or removed). It returns immediately.
-// The goroutine must be stopped by calling Stop() method.
+// Run enumerates existing USB devices and starts new goroutines that
+// handle hotplug events (devices added or removed). It returns immediately.
+// The goroutines must be stopped by calling Stop() method.
 func (m *Monitor) Run() error {
 	// Gather devices from udevadm info output (enumeration on startup).
 	devices, parseErrors, err := hotplug.EnumerateExistingDevices()
@@ -124,6 +230,29 @@ func (m *Monitor) Run() error {
 		m.disconnect()
 		return fmt.Errorf("cannot enumerate existing devices: %s", err)
 	}
+
+	m.mu.Lock()
+	m.health.Running = true
+	m.mu.Unlock()
+
+	// Receive hotplug events reported by udev monitor, these are only
+	// queued so that slow consumers do not stall the netlink socket.
+	m.tomb.Go(func() error {
+		for {
+			select {
+			case err := <-m.netlinkErrors:
+				logger.Noticef("udev event error: %s", err)
+				m.mu.Lock()
+				m.health.Errors++
+				m.mu.Unlock()
+			case ev := <-m.netlinkEvents:
+				m.udevEvent(&ev)
+			case <-m.tomb.Dying():
+				return m.disconnect()
+			}
+		}
+	})
+
 	m.tomb.Go(func() error {
 		for _, perr := range parseErrors {
 			logger.Noticef("udev enumeration error: %s", perr)
@@ -141,16 +270,23 @@ func (m *Monitor) Run() error {
 		if m.enumerationDone != nil {
 			m.enumerationDone()
 		}
+		m.mu.Lock()
+		m.health.EnumerationDone = true
+		m.mu.Unlock()
 
-		// Process hotplug events reported by udev monitor.
+		// Deliver the queued events once they are due.
+		var timer <-chan time.Time
 		for {
+			if next, ok := m.deliverDue(timeNow()); ok {
+				timer = time.After(next)
+			} else {
+				timer = nil
+			}
 			select {
-			case err := <-m.netlinkErrors:
-				logger.Noticef("udev event error: %s", err)
-			case ev := <-m.netlinkEvents:
-				m.udevEvent(&ev)
+			case <-m.wakeup:
+			case <-timer:
 			case <-m.tomb.Dying():
-				return m.disconnect()
+				return nil
 			}
 		}
 	})
@@ -162,24 +298,130 @@ func (m *Monitor) Stop() error {
 	m.tomb.Kill(nil)
 	err := m.tomb.Wait()
 	m.netlinkConn = nil
+	m.mu.Lock()
+	m.health.Running = false
+	m.mu.Unlock()
 	return err
 }
 
+// Health returns the current state of the monitor.
+func (m *Monitor) Health() Health {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := timeNow()
+	h := m.health
+	h.QueueLength = len(m.queue)
+	h.QueueCapacity = maxPendingDevices
+	h.EventRate10s = m.rate.rate(now, 10)
+	h.EventRate1m = m.rate.rate(now, 60)
+	return h
+}
+
 func (m *Monitor) udevEvent(ev *netlink.UEvent) {
 	switch ev.Action {
-	case netlink.ADD:
-		m.addDevice(ev.KObj, ev.Env)
-	case netlink.REMOVE:
-		m.removeDevice(ev.KObj, ev.Env)
+	case netlink.ADD, netlink.REMOVE:
 	default:
+		return
 	}
-}
-
-func (m *Monitor) addDevice(kobj string, env map[string]string) {
-	dev, err := hotplug.NewHotplugDeviceInfo(env)
+	dev, err := hotplug.NewHotplugDeviceInfo(ev.Env)
 	if err != nil {
 		return
 	}
+	if m.queueEvent(ev.Action, dev, timeNow()) {
+		select {
+		case m.wakeup <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// queueEvent queues the event for delivery after the debounce delay,
+// coalescing it with the events of the same device that are still pending.
+// It returns false if the event was dropped.
+func (m *Monitor) queueEvent(action netlink.KObjAction, dev *hotplug.HotplugDeviceInfo, now time.Time) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.health.EventsReceived++
+	if now.After(m.health.LastEvent) {
+		m.health.LastEvent = now
+	}
+	m.rate.add(now)
+
+	devPath := dev.DevicePath()
+	pd := m.pending[devPath]
+	if pd == nil {
+		if len(m.queue) >= maxPendingDevices {
+			m.health.EventsDropped++
+			logger.Debugf("udev monitor queue full, dropping %s event for %s", action, devPath)
+			return false
+		}
+		pd = &pendingDevice{devPath: devPath}
+		m.pending[devPath] = pd
+	} else {
+		// move it to the back of the queue as it is due later now
+		for i, queued := range m.queue {
+			if queued == pd {
+				m.queue = append(m.queue[:i], m.queue[i+1:]...)
+				break
+			}
+		}
+	}
+	m.queue = append(m.queue, pd)
+	pd.due = now.Add(debounceDelay)
+
+	before := len(pd.events)
+	ev := pendingEvent{action: action, dev: dev}
+	switch {
+	case action == netlink.REMOVE:
+		// supersedes anything pending, if the device was never
+		// reported the removal is ignored on delivery
+		pd.events = []pendingEvent{ev}
+	case before > 0 && pd.events[before-1].action == netlink.ADD:
+		pd.events[before-1] = ev
+	default:
+		pd.events = append(pd.events, ev)
+	}
+	m.health.EventsCoalesced += uint64(before + 1 - len(pd.events))
+	return true
+}
+
+// deliverDue delivers the events that are due at the given time. It returns
+// the time until the next queued events are due, if any.
+func (m *Monitor) deliverDue(now time.Time) (next time.Duration, ok bool) {
+	m.mu.Lock()
+	var due []*pendingDevice
+	for len(m.queue) > 0 && !m.queue[0].due.After(now) {
+		pd := m.queue[0]
+		m.queue = m.queue[1:]
+		delete(m.pending, pd.devPath)
+		due = append(due, pd)
+	}
+	if len(m.queue) > 0 {
+		next, ok = m.queue[0].due.Sub(now), true
+	}
+	dropped := m.health.EventsDropped - m.reportedDropped
+	m.reportedDropped = m.health.EventsDropped
+	m.mu.Unlock()
+
+	if dropped > 0 {
+		logger.Noticef("udev monitor dropped %d events as too many devices had pending events", dropped)
+	}
+	for _, pd := range due {
+		for _, ev := range pd.events {
+			switch ev.action {
+			case netlink.ADD:
+				m.addDevice(ev.dev)
+			case netlink.REMOVE:
+				m.removeDevice(ev.dev)
+			}
+		}
+	}
+	return next, ok
+}
+
+func (m *Monitor) addDevice(dev *hotplug.HotplugDeviceInfo) {
 	devPath := dev.DevicePath()
 	if m.seen[devPath] {
 		return
@@ -190,11 +432,7 @@ func (m *Monitor) addDevice(kobj string, env map[string]string) {
 	}
 }
 
-func (m *Monitor) removeDevice(kobj string, env map[string]string) {
-	dev, err := hotplug.NewHotplugDeviceInfo(env)
-	if err != nil {
-		return
-	}
+func (m *Monitor) removeDevice(dev *hotplug.HotplugDeviceInfo) {
 	devPath := dev.DevicePath()
 	if !m.seen[devPath] {
 		logger.Debugf("udev monitor observed remove event for unknown device %s", dev)
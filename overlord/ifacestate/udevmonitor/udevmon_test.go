@@ -206,3 +206,157 @@ Loop:
 	c.Assert(remInfo.Major(), Equals, "0")
 	c.Assert(remInfo.Minor(), Equals, "3")
 }
+
+func mockDevice(c *C, devPath string) *hotplug.HotplugDeviceInfo {
+	dev, err := hotplug.NewHotplugDeviceInfo(map[string]string{
+		"DEVPATH":   devPath,
+		"SUBSYSTEM": "usb",
+	})
+	c.Assert(err, IsNil)
+	return dev
+}
+
+type recordedEvent struct {
+	action  string
+	devPath string
+}
+
+func (s *udevMonitorSuite) newRecordingMonitor(c *C) (*udevmonitor.Monitor, *[]recordedEvent) {
+	var events []recordedEvent
+	added := func(inf *hotplug.HotplugDeviceInfo) {
+		events = append(events, recordedEvent{"add", inf.DevicePath()})
+	}
+	removed := func(inf *hotplug.HotplugDeviceInfo) {
+		events = append(events, recordedEvent{"remove", inf.DevicePath()})
+	}
+	return udevmonitor.New(added, removed, nil).(*udevmonitor.Monitor), &events
+}
+
+func (s *udevMonitorSuite) TestCoalesceEvents(c *C) {
+	s.AddCleanup(udevmonitor.MockDebounceDelay(time.Second))
+	udevmon, events := s.newRecordingMonitor(c)
+
+	t0 := time.Now()
+	// e is known from before
+	c.Assert(udevmon.QueueEvent(netlink.ADD, mockDevice(c, "e"), t0), Equals, true)
+	_, ok := udevmon.DeliverDue(t0.Add(time.Second))
+	c.Assert(ok, Equals, false)
+	c.Check(*events, DeepEquals, []recordedEvent{{"add", "/sys/e"}})
+	*events = nil
+
+	for _, ev := range []struct {
+		action  netlink.KObjAction
+		devPath string
+	}{
+		// repeated add
+		{netlink.ADD, "a"},
+		{netlink.ADD, "a"},
+		{netlink.ADD, "b"},
+		// removal of a device that was never reported
+		{netlink.REMOVE, "c"},
+		// device that is gone before being reported
+		{netlink.ADD, "d"},
+		{netlink.REMOVE, "d"},
+		// known device replaced
+		{netlink.REMOVE, "e"},
+		{netlink.ADD, "e"},
+		{netlink.ADD, "e"},
+	} {
+		c.Assert(udevmon.QueueEvent(ev.action, mockDevice(c, ev.devPath), t0.Add(2*time.Second)), Equals, true)
+	}
+
+	// nothing is delivered before the debounce delay
+	next, ok := udevmon.DeliverDue(t0.Add(2500 * time.Millisecond))
+	c.Assert(ok, Equals, true)
+	c.Check(next, Equals, 500*time.Millisecond)
+	c.Check(*events, HasLen, 0)
+
+	_, ok = udevmon.DeliverDue(t0.Add(3 * time.Second))
+	c.Assert(ok, Equals, false)
+	c.Check(*events, DeepEquals, []recordedEvent{
+		{"add", "/sys/a"},
+		{"add", "/sys/b"},
+		{"remove", "/sys/e"},
+		{"add", "/sys/e"},
+	})
+
+	health := udevmon.Health()
+	c.Check(health.EventsReceived, Equals, uint64(10))
+	c.Check(health.EventsCoalesced, Equals, uint64(3))
+	c.Check(health.EventsDropped, Equals, uint64(0))
+	c.Check(health.QueueLength, Equals, 0)
+}
+
+func (s *udevMonitorSuite) TestDebouncePerDevice(c *C) {
+	s.AddCleanup(udevmonitor.MockDebounceDelay(time.Second))
+	udevmon, events := s.newRecordingMonitor(c)
+
+	t0 := time.Now()
+	udevmon.QueueEvent(netlink.ADD, mockDevice(c, "a"), t0)
+	udevmon.QueueEvent(netlink.ADD, mockDevice(c, "b"), t0.Add(500*time.Millisecond))
+	// a new event for a delays its delivery
+	udevmon.QueueEvent(netlink.ADD, mockDevice(c, "a"), t0.Add(800*time.Millisecond))
+
+	next, ok := udevmon.DeliverDue(t0.Add(1500 * time.Millisecond))
+	c.Assert(ok, Equals, true)
+	c.Check(next, Equals, 300*time.Millisecond)
+	c.Check(*events, DeepEquals, []recordedEvent{{"add", "/sys/b"}})
+
+	_, ok = udevmon.DeliverDue(t0.Add(1800 * time.Millisecond))
+	c.Assert(ok, Equals, false)
+	c.Check(*events, DeepEquals, []recordedEvent{{"add", "/sys/b"}, {"add", "/sys/a"}})
+}
+
+func (s *udevMonitorSuite) TestQueueBounded(c *C) {
+	s.AddCleanup(udevmonitor.MockMaxPendingDevices(2))
+	udevmon, events := s.newRecordingMonitor(c)
+
+	t0 := time.Now()
+	c.Check(udevmon.QueueEvent(netlink.ADD, mockDevice(c, "a"), t0), Equals, true)
+	c.Check(udevmon.QueueEvent(netlink.ADD, mockDevice(c, "b"), t0), Equals, true)
+	c.Check(udevmon.QueueEvent(netlink.ADD, mockDevice(c, "c"), t0), Equals, false)
+	// events for devices already queued are still coalesced
+	c.Check(udevmon.QueueEvent(netlink.ADD, mockDevice(c, "a"), t0), Equals, true)
+
+	health := udevmon.Health()
+	c.Check(health.EventsReceived, Equals, uint64(4))
+	c.Check(health.EventsDropped, Equals, uint64(1))
+	c.Check(health.EventsCoalesced, Equals, uint64(1))
+	c.Check(health.QueueLength, Equals, 2)
+	c.Check(health.QueueCapacity, Equals, 2)
+
+	udevmon.DeliverDue(t0.Add(time.Minute))
+	c.Check(*events, DeepEquals, []recordedEvent{{"add", "/sys/b"}, {"add", "/sys/a"}})
+	c.Check(udevmon.Health().QueueLength, Equals, 0)
+}
+
+func (s *udevMonitorSuite) TestHealthEventRates(c *C) {
+	now := time.Unix(1700000000, 0)
+	s.AddCleanup(udevmonitor.MockTimeNow(func() time.Time { return now }))
+	udevmon, _ := s.newRecordingMonitor(c)
+
+	health := udevmon.Health()
+	c.Check(health.Running, Equals, false)
+	c.Check(health.EventRate10s, Equals, 0.0)
+	c.Check(health.EventRate1m, Equals, 0.0)
+
+	// 60 events half a minute ago and 20 events in the last seconds
+	for i := 0; i < 60; i++ {
+		udevmon.QueueEvent(netlink.ADD, mockDevice(c, "a"), now.Add(-30*time.Second))
+	}
+	for i := 0; i < 20; i++ {
+		udevmon.QueueEvent(netlink.ADD, mockDevice(c, "b"), now.Add(-time.Duration(4-i%5)*time.Second))
+	}
+
+	health = udevmon.Health()
+	c.Check(health.EventsReceived, Equals, uint64(80))
+	c.Check(health.LastEvent.Equal(now), Equals, true)
+	c.Check(health.EventRate10s, Equals, 2.0)
+	c.Check(health.EventRate1m, Equals, 80.0/60)
+
+	// old events are forgotten
+	now = now.Add(2 * time.Minute)
+	health = udevmon.Health()
+	c.Check(health.EventRate10s, Equals, 0.0)
+	c.Check(health.EventRate1m, Equals, 0.0)
+}
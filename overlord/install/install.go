@@ -677,12 +677,19 @@ func PrepareEncryptedSystemData(
 
 	if saveBootstrappedContainer != nil {
 		if bootUseTokens(model) {
-			protectorKey, err := keys.NewProtectorKey()
+			newPrimaryKey, err := keys.NewPrimaryKey()
+			if err != nil {
+				return err
+			}
+			// the protector key is derived from the primary key so
+			// that ubuntu-save can still be unlocked if the key file
+			// gets lost
+			protectorKey, err := newPrimaryKey.DeriveProtectorKey(gadget.SystemSave)
 			if err != nil {
 				return err
 			}
 
-			plainKey, generatedPK, diskKey, err := protectorKey.CreateProtectedKey(nil)
+			plainKey, generatedPK, diskKey, err := protectorKey.CreateProtectedKey(newPrimaryKey)
 			if err != nil {
 				return err
 			}
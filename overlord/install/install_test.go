@@ -50,6 +50,7 @@ import (
 	"github.com/snapcore/snapd/overlord/install"
 	"github.com/snapcore/snapd/release"
 	"github.com/snapcore/snapd/secboot"
+	"github.com/snapcore/snapd/secboot/keys"
 	"github.com/snapcore/snapd/seed"
 	"github.com/snapcore/snapd/seed/seedtest"
 	"github.com/snapcore/snapd/seed/seedwriter"
@@ -2095,6 +2096,8 @@ func (s *installSuite) testPrepareEncryptedSystemData(c *C, useTokens, hasCheckR
 	if useTokens {
 		_, hasToken := saveDisk.Tokens["default"]
 		c.Assert(hasToken, Equals, true)
+		// the protector key is derived from the primary key
+		c.Check(saveKey, HasLen, keys.PrimaryKeySize)
 	} else {
 		slotKey, hasSlot := saveDisk.Slots["default"]
 		c.Assert(hasSlot, Equals, true)
@@ -24,6 +24,7 @@ package overlord
 import (
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"os"
@@ -35,6 +36,7 @@ import (
 	"gopkg.in/tomb.v2"
 
 	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/features"
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/overlord/assertstate"
@@ -144,10 +146,14 @@ func New(restartHandler restart.Handler) (*Overlord, error) {
 	// create the loop goroutine
 	o.loopTomb.Go(o.loop)
 
-	backend := &overlordStateBackend{
+	var backend state.Backend = &overlordStateBackend{
 		path:         dirs.SnapStateFile,
+		log:          state.NewStateLog(dirs.SnapStateLogFile),
 		ensureBefore: o.ensureBefore,
 	}
+	if features.StateLog.IsEnabled() {
+		backend = overlordStateLogBackend{backend.(*overlordStateBackend)}
+	}
 	s, restartMgr, err := o.loadState(backend, restartHandler)
 	if err != nil {
 		return nil, err
@@ -206,7 +212,7 @@ func New(restartHandler restart.Handler) (*Overlord, error) {
 	deviceMgr.AddOnInit(fdeMgr)
 	o.addManager(deviceMgr)
 
-	o.addManager(clusterstate.Manager(s))
+	o.addManager(clusterstate.Manager(s, o.runner, deviceMgr))
 
 	o.addManager(cmdstate.Manager(s, o.runner))
 	o.addManager(snapshotstate.Manager(s, o.runner))
@@ -335,6 +341,9 @@ func (o *Overlord) loadState(backend state.Backend, restartHandler restart.Handl
 			return nil, nil, fmt.Errorf("fatal: directory %q must be present", stateDir)
 		}
 		s := state.New(backend)
+		if err := useStateSealingKey(s); err != nil {
+			return nil, nil, err
+		}
 		restartMgr, err := initRestart(s, curBootID, restartHandler)
 		if err != nil {
 			return nil, nil, err
@@ -349,13 +358,28 @@ func (o *Overlord) loadState(backend state.Backend, restartHandler restart.Handl
 	}
 	defer r.Close()
 
+	// the log of the changes made since the state file was last written
+	// in full is replayed even if the state-log feature got disabled
+	var log io.Reader
+	logFile, err := os.Open(dirs.SnapStateLogFile)
+	switch {
+	case err == nil:
+		defer logFile.Close()
+		log = logFile
+	case !os.IsNotExist(err):
+		return nil, nil, fmt.Errorf("cannot read the state log: %s", err)
+	}
+
 	var s *state.State
 	timings.Run(perfTimings, "read-state", "read snapd state from disk", func(tm timings.Measurer) {
-		s, err = state.ReadState(backend, r)
+		s, err = state.ReadStateWithLog(backend, r, log)
 	})
 	if err != nil {
 		return nil, nil, err
 	}
+	if err := useStateSealingKey(s); err != nil {
+		return nil, nil, err
+	}
 	s.Lock()
 	perfTimings.Save(s)
 	s.Unlock()
@@ -571,6 +595,12 @@ func (o *Overlord) Stop() error {
 	o.loopTomb.Kill(nil)
 	err := o.loopTomb.Wait()
 	o.stateEng.Stop()
+	// leave a state file which is complete on its own, e.g. for a snapd
+	// revert, if the state-log feature is in use
+	st := o.State()
+	st.Lock()
+	st.CompactLog()
+	st.Unlock()
 	if o.stateFLock != nil {
 		// This will also unlock the file
 		o.stateFLock.Close()
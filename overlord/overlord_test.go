@@ -20,6 +20,7 @@
 package overlord_test
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -39,8 +40,10 @@ import (
 	"github.com/snapcore/snapd/boot"
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/dirs/dirstest"
+	"github.com/snapcore/snapd/features"
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/overlord"
+	"github.com/snapcore/snapd/overlord/auth"
 	"github.com/snapcore/snapd/overlord/devicestate/devicestatetest"
 	"github.com/snapcore/snapd/overlord/hookstate"
 	"github.com/snapcore/snapd/overlord/ifacestate"
@@ -248,6 +251,112 @@ func (ovs *overlordSuite) TestNewWithGoodState(c *C) {
 	c.Check(got, DeepEquals, expected)
 }
 
+func (ovs *overlordSuite) TestNewWithStateLog(c *C) {
+	c.Assert(os.MkdirAll(dirs.FeaturesDir, 0755), IsNil)
+	c.Assert(os.WriteFile(features.StateLog.ControlFile(), nil, 0644), IsNil)
+
+	fakeState := []byte(fmt.Sprintf(`{
+		"data": {"patch-level": %d, "patch-sublevel": %d, "patch-sublevel-last-version": %q, "some": "data", "refresh-privacy-key": "0123456789ABCDEF"},
+		"changes": null,
+		"tasks": null,
+		"last-change-id": 0,
+		"last-task-id": 0,
+		"last-lane-id": 0,
+		"last-notice-id": 0,
+		"log-generation": 1
+	}`, patch.Level, patch.Sublevel, snapdtool.Version))
+	c.Assert(os.WriteFile(dirs.SnapStateFile, fakeState, 0600), IsNil)
+	stateLog := state.NewStateLog(dirs.SnapStateLogFile)
+	c.Assert(stateLog.Append(1, []byte(`{"data":{"some":"other"}}`)), IsNil)
+
+	o, err := overlord.New(nil)
+	c.Assert(err, IsNil)
+
+	st := o.State()
+	st.Lock()
+	// the log was replayed
+	var some string
+	c.Assert(st.Get("some", &some), IsNil)
+	c.Check(some, Equals, "other")
+	// the first checkpoint writes the state in full
+	st.Set("more", "data")
+	st.Unlock()
+	c.Check(dirs.SnapStateFile, testutil.FileContains, `"some":"other"`)
+	c.Check(dirs.SnapStateLogFile, testutil.FileAbsent)
+
+	st.Lock()
+	st.Set("more", "changes")
+	st.Unlock()
+	c.Check(dirs.SnapStateLogFile, testutil.FileContains, `"more":"changes"`)
+	c.Check(dirs.SnapStateFile, Not(testutil.FileContains), `"more":"changes"`)
+
+	// the log is compacted when stopping
+	c.Assert(o.Stop(), IsNil)
+	c.Check(dirs.SnapStateFile, testutil.FileContains, `"more":"changes"`)
+	c.Check(dirs.SnapStateLogFile, testutil.FileAbsent)
+}
+
+func (ovs *overlordSuite) TestNewWithSealedState(c *C) {
+	c.Assert(os.MkdirAll(dirs.FeaturesDir, 0755), IsNil)
+	c.Assert(os.WriteFile(features.SealedState.ControlFile(), nil, 0644), IsNil)
+
+	primaryKey := bytes.Repeat([]byte{1}, 32)
+	ovs.AddCleanup(overlord.MockSecbootGetPrimaryKey(func(devices []string, fallbackKeyFiles []string) ([]byte, error) {
+		c.Check(devices, HasLen, 0)
+		c.Check(fallbackKeyFiles, DeepEquals, []string{
+			filepath.Join(dirs.SnapSaveDir, "device/fde/aux-key"),
+			filepath.Join(dirs.SnapSaveDir, "device/fde/tpm-policy-auth-key"),
+		})
+		return primaryKey, nil
+	}))
+
+	o, err := overlord.New(nil)
+	c.Assert(err, IsNil)
+
+	st := o.State()
+	st.Lock()
+	_, err = auth.NewUser(st, auth.NewUserParams{
+		Username:   "username",
+		Email:      "email@test.com",
+		Macaroon:   "store-macaroon-value",
+		Discharges: []string{"store-discharge-value"},
+	})
+	st.Unlock()
+	c.Assert(err, IsNil)
+
+	c.Check(dirs.SnapStateFile, testutil.FileContains, `"email":"email@test.com"`)
+	c.Check(dirs.SnapStateFile, Not(testutil.FileContains), "store-macaroon-value")
+	c.Check(dirs.SnapStateFile, Not(testutil.FileContains), "store-discharge-value")
+	c.Assert(o.Stop(), IsNil)
+
+	// the credentials are unsealed when loading the state again, even with
+	// the feature disabled
+	c.Assert(os.Remove(features.SealedState.ControlFile()), IsNil)
+	o, err = overlord.New(nil)
+	c.Assert(err, IsNil)
+
+	st = o.State()
+	st.Lock()
+	user, err := auth.User(st, 1)
+	c.Assert(err, IsNil)
+	c.Check(user.StoreMacaroon, Equals, "store-macaroon-value")
+	c.Check(user.StoreDischarges, DeepEquals, []string{"store-discharge-value"})
+	// and no longer sealed once persisted again
+	st.Set("more", "data")
+	st.Unlock()
+	c.Check(dirs.SnapStateFile, testutil.FileContains, "store-macaroon-value")
+
+	// a sealed state cannot be loaded without the key
+	st.Lock()
+	c.Assert(st.UseSealingKey(bytes.Repeat([]byte{2}, 32), true), IsNil)
+	st.Set("more", "changes")
+	st.Unlock()
+	c.Assert(o.Stop(), IsNil)
+
+	_, err = overlord.New(nil)
+	c.Assert(err, ErrorMatches, `cannot unseal state entry "auth": cipher: message authentication failed`)
+}
+
 func (ovs *overlordSuite) TestNewWithStateSnapmgrUpdate(c *C) {
 	fakeState := []byte(fmt.Sprintf(`{"data":{"patch-level":%d,"some":"data"},"changes":null,"tasks":null,"last-change-id":0,"last-task-id":0,"last-lane-id":0}`, patch.Level))
 	err := os.WriteFile(dirs.SnapStateFile, fakeState, 0600)
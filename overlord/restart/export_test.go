@@ -20,7 +20,10 @@
 package restart
 
 import (
+	"time"
+
 	"github.com/snapcore/snapd/boot"
+	"github.com/snapcore/snapd/testutil"
 )
 
 var (
@@ -32,3 +35,7 @@ var (
 func RestartParametersInit(rt *RestartParameters, snapName string, restartType RestartType, rebootInfo *boot.RebootInfo) {
 	rt.init(snapName, restartType, rebootInfo)
 }
+
+func MockTimeNow(f func() time.Time) (restore func()) {
+	return testutil.Mock(&timeNow, f)
+}
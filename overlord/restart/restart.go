@@ -149,6 +149,9 @@ type RestartManager struct {
 	h                Handler
 	bootID           string
 	changeCallbackID int
+	// scheduleCallbackID is the change status handler checking the
+	// scheduled reboots
+	scheduleCallbackID int
 }
 
 // Manager returns a new restart manager and initializes the support
@@ -174,6 +177,7 @@ func Manager(st *state.State, curBootID string, h Handler) (*RestartManager, err
 
 	st.RegisterPendingChangeByAttr("wait-for-system-restart", rm.pendingForSystemRestart)
 	rm.changeCallbackID = st.AddChangeStatusChangedHandler(processRestartForChange)
+	rm.scheduleCallbackID = st.AddChangeStatusChangedHandler(rm.scheduledRebootChangeStatusChanged)
 
 	return rm, nil
 }
@@ -197,10 +201,13 @@ func ClearReboot(st *state.State) {
 	st.Set("system-restart-from-boot-id", nil)
 }
 
-// Ensure implements StateManager.Ensure. Required by StateEngine, we
-// actually do nothing here.
+// Ensure implements StateManager.Ensure. It requests the reboots
+// scheduled by snaps once they are due.
 func (m *RestartManager) Ensure() error {
-	return nil
+	m.state.Lock()
+	defer m.state.Unlock()
+
+	return m.ensureScheduledReboot()
 }
 
 // StartUp implements StateStarterUp.Startup.
@@ -257,6 +264,7 @@ func (rm *RestartManager) Stop() {
 	defer st.Unlock()
 
 	st.RemoveChangeStatusChangedHandler(rm.changeCallbackID)
+	st.RemoveChangeStatusChangedHandler(rm.scheduleCallbackID)
 }
 
 func (rm *RestartManager) handleRestart(t RestartType, rebootInfo *boot.RebootInfo) {
@@ -1278,7 +1278,7 @@ func (s *notifyRebootRequiredSuite) TestFinishTaskWithRestartNotifiesRebootRequi
 }
 
 func (s *restartSuite) TestEnsureLoopLogging(c *C) {
-	testutil.CheckEnsureLoopLogging("restart.go", c, false)
+	testutil.CheckEnsureLoopLogging("restart.go", c, true)
 }
 
 func (*restartSuite) TestStringfiedTypes(c *C) {
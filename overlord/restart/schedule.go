@@ -0,0 +1,187 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package restart
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/snapcore/snapd/boot"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+var timeNow = time.Now
+
+// ScheduledReboot describes a reboot of the system scheduled by a snap.
+type ScheduledReboot struct {
+	// At is when the system should reboot at the earliest.
+	At time.Time `json:"at"`
+	// Reason is the reason given by the snap for the reboot.
+	Reason string `json:"reason,omitempty"`
+	// ChangeID is the change that scheduled the reboot, the reboot
+	// waits for it to be ready.
+	ChangeID string `json:"change-id,omitempty"`
+}
+
+func scheduledReboots(st *state.State) (map[string]*ScheduledReboot, error) {
+	var scheduled map[string]*ScheduledReboot
+	if err := st.Get("scheduled-system-reboots", &scheduled); err != nil && !errors.Is(err, state.ErrNoState) {
+		return nil, err
+	}
+	if scheduled == nil {
+		scheduled = make(map[string]*ScheduledReboot)
+	}
+	return scheduled, nil
+}
+
+func setScheduledReboots(st *state.State, scheduled map[string]*ScheduledReboot) {
+	if len(scheduled) == 0 {
+		st.Set("scheduled-system-reboots", nil)
+		return
+	}
+	st.Set("scheduled-system-reboots", scheduled)
+}
+
+// ScheduleReboot schedules a reboot of the system on behalf of the given
+// snap, once the delay has elapsed and the change that scheduled it, if
+// any, is ready. Scheduling again on behalf of the same snap replaces the
+// previous schedule. The reboots scheduled by different snaps are
+// coalesced into one, happening as soon as all of them are due.
+// The state needs to be locked.
+func ScheduleReboot(st *state.State, snapName string, delay time.Duration, reason string, chg *state.Change) error {
+	restartManager(st, "internal error: cannot schedule a reboot before RestartManager initialization")
+	if delay < 0 {
+		return fmt.Errorf("cannot schedule a reboot with a negative delay")
+	}
+	scheduled, err := scheduledReboots(st)
+	if err != nil {
+		return err
+	}
+	sr := &ScheduledReboot{
+		At:     timeNow().Add(delay),
+		Reason: reason,
+	}
+	if chg != nil {
+		sr.ChangeID = chg.ID()
+	}
+	scheduled[snapName] = sr
+	setScheduledReboots(st, scheduled)
+	st.EnsureBefore(delay)
+	return nil
+}
+
+// CancelScheduledReboot cancels the reboot scheduled on behalf of the
+// given snap. It returns whether there was one. The state needs to be
+// locked.
+func CancelScheduledReboot(st *state.State, snapName string) (bool, error) {
+	scheduled, err := scheduledReboots(st)
+	if err != nil {
+		return false, err
+	}
+	if _, ok := scheduled[snapName]; !ok {
+		return false, nil
+	}
+	delete(scheduled, snapName)
+	setScheduledReboots(st, scheduled)
+	// the reboots scheduled by other snaps may be due now
+	st.EnsureBefore(0)
+	return true, nil
+}
+
+// ScheduledReboots returns the reboots scheduled by snaps, by snap name.
+// The state needs to be locked.
+func ScheduledReboots(st *state.State) (map[string]*ScheduledReboot, error) {
+	return scheduledReboots(st)
+}
+
+// ensureScheduledReboot requests a reboot of the system once all the
+// scheduled reboots are due.
+func (rm *RestartManager) ensureScheduledReboot() error {
+	logger.Trace("ensure", "manager", "RestartManager", "func", "ensureScheduledReboot")
+	st := rm.state
+	scheduled, err := scheduledReboots(st)
+	if err != nil {
+		return err
+	}
+	if len(scheduled) == 0 || rm.Pending() != RestartUnset {
+		return nil
+	}
+
+	now := timeNow()
+	var wait time.Duration
+	for _, sr := range scheduled {
+		if d := sr.At.Sub(now); d > wait {
+			wait = d
+		}
+		if sr.ChangeID == "" {
+			continue
+		}
+		if chg := st.Change(sr.ChangeID); chg != nil && !chg.IsReady() {
+			// checked again when the change is ready
+			return nil
+		}
+	}
+	if wait > 0 {
+		st.EnsureBefore(wait)
+		return nil
+	}
+
+	snapNames := make([]string, 0, len(scheduled))
+	for snapName := range scheduled {
+		snapNames = append(snapNames, snapName)
+	}
+	sort.Strings(snapNames)
+	reasons := make([]string, 0, len(snapNames))
+	for _, snapName := range snapNames {
+		if reason := scheduled[snapName].Reason; reason != "" {
+			reasons = append(reasons, fmt.Sprintf("%s (%s)", snapName, reason))
+		} else {
+			reasons = append(reasons, snapName)
+		}
+	}
+	logger.Noticef("rebooting as scheduled by %s", strings.Join(reasons, ", "))
+
+	setScheduledReboots(st, nil)
+	Request(st, RestartSystemNow, &boot.RebootInfo{RebootRequired: true})
+	return nil
+}
+
+// scheduledRebootChangeStatusChanged checks again the scheduled reboots
+// when a change that scheduled one is ready.
+func (rm *RestartManager) scheduledRebootChangeStatusChanged(chg *state.Change, old, new state.Status) {
+	if !new.Ready() {
+		return
+	}
+	scheduled, err := scheduledReboots(rm.state)
+	if err != nil {
+		logger.Noticef("internal error: cannot retrieve scheduled reboots: %v", err)
+		return
+	}
+	for _, sr := range scheduled {
+		if sr.ChangeID == chg.ID() {
+			rm.state.EnsureBefore(0)
+			return
+		}
+	}
+}
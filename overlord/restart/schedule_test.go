@@ -0,0 +1,179 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package restart_test
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/boot"
+	"github.com/snapcore/snapd/overlord/restart"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type scheduleBackend struct {
+	ensureBefore time.Duration
+}
+
+func (b *scheduleBackend) Checkpoint(data []byte) error { return nil }
+
+func (b *scheduleBackend) EnsureBefore(d time.Duration) {
+	b.ensureBefore = d
+}
+
+type scheduleSuite struct {
+	testutil.BaseTest
+
+	backend *scheduleBackend
+	st      *state.State
+	h       *testHandler
+	mgr     *restart.RestartManager
+	now     time.Time
+}
+
+var _ = Suite(&scheduleSuite{})
+
+func (s *scheduleSuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
+
+	s.backend = &scheduleBackend{ensureBefore: -1}
+	s.st = state.New(s.backend)
+	s.h = &testHandler{}
+	s.now = time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	s.AddCleanup(restart.MockTimeNow(func() time.Time { return s.now }))
+
+	s.st.Lock()
+	defer s.st.Unlock()
+	mgr, err := restart.Manager(s.st, "boot-id-1", s.h)
+	c.Assert(err, IsNil)
+	s.mgr = mgr
+}
+
+func (s *scheduleSuite) TestScheduleReboot(c *C) {
+	s.st.Lock()
+	err := restart.ScheduleReboot(s.st, "pc", 10*time.Minute, "firmware update", nil)
+	c.Assert(err, IsNil)
+	c.Check(s.backend.ensureBefore, Equals, 10*time.Minute)
+	scheduled, err := restart.ScheduledReboots(s.st)
+	c.Assert(err, IsNil)
+	c.Check(scheduled, DeepEquals, map[string]*restart.ScheduledReboot{
+		"pc": {At: s.now.Add(10 * time.Minute), Reason: "firmware update"},
+	})
+	s.st.Unlock()
+
+	// not due yet
+	s.now = s.now.Add(4 * time.Minute)
+	c.Assert(s.mgr.Ensure(), IsNil)
+	c.Check(s.h.restartRequested, Equals, false)
+	c.Check(s.backend.ensureBefore, Equals, 6*time.Minute)
+
+	s.now = s.now.Add(6 * time.Minute)
+	c.Assert(s.mgr.Ensure(), IsNil)
+	c.Check(s.h.restartRequested, Equals, true)
+	c.Check(s.h.restartType, Equals, restart.RestartSystemNow)
+	c.Check(s.h.rebootInfo, DeepEquals, &boot.RebootInfo{RebootRequired: true})
+
+	s.st.Lock()
+	defer s.st.Unlock()
+	scheduled, err = restart.ScheduledReboots(s.st)
+	c.Assert(err, IsNil)
+	c.Check(scheduled, HasLen, 0)
+}
+
+func (s *scheduleSuite) TestScheduleRebootWaitsForChange(c *C) {
+	s.st.Lock()
+	chg := s.st.NewChange("install", "...")
+	t := s.st.NewTask("run-hook", "...")
+	chg.AddTask(t)
+	err := restart.ScheduleReboot(s.st, "pc", 0, "", chg)
+	c.Assert(err, IsNil)
+	s.st.Unlock()
+
+	c.Assert(s.mgr.Ensure(), IsNil)
+	c.Check(s.h.restartRequested, Equals, false)
+
+	s.st.Lock()
+	s.backend.ensureBefore = -1
+	t.SetStatus(state.DoneStatus)
+	// the scheduled reboots are checked again
+	c.Check(s.backend.ensureBefore, Equals, time.Duration(0))
+	s.st.Unlock()
+
+	c.Assert(s.mgr.Ensure(), IsNil)
+	c.Check(s.h.restartRequested, Equals, true)
+}
+
+func (s *scheduleSuite) TestScheduleRebootCoalesced(c *C) {
+	s.st.Lock()
+	c.Assert(restart.ScheduleReboot(s.st, "pc", time.Minute, "", nil), IsNil)
+	c.Assert(restart.ScheduleReboot(s.st, "pc-kernel", 5*time.Minute, "", nil), IsNil)
+	// scheduling again replaces the previous schedule of the snap
+	c.Assert(restart.ScheduleReboot(s.st, "pc", 2*time.Minute, "", nil), IsNil)
+	s.st.Unlock()
+
+	s.now = s.now.Add(2 * time.Minute)
+	c.Assert(s.mgr.Ensure(), IsNil)
+	c.Check(s.h.restartRequested, Equals, false)
+	c.Check(s.backend.ensureBefore, Equals, 3*time.Minute)
+
+	s.now = s.now.Add(3 * time.Minute)
+	c.Assert(s.mgr.Ensure(), IsNil)
+	c.Check(s.h.restartRequested, Equals, true)
+}
+
+func (s *scheduleSuite) TestCancelScheduledReboot(c *C) {
+	s.st.Lock()
+	c.Assert(restart.ScheduleReboot(s.st, "pc", time.Minute, "", nil), IsNil)
+	c.Assert(restart.ScheduleReboot(s.st, "pc-kernel", 5*time.Minute, "", nil), IsNil)
+
+	cancelled, err := restart.CancelScheduledReboot(s.st, "pc-kernel")
+	c.Assert(err, IsNil)
+	c.Check(cancelled, Equals, true)
+	cancelled, err = restart.CancelScheduledReboot(s.st, "pc-kernel")
+	c.Assert(err, IsNil)
+	c.Check(cancelled, Equals, false)
+	s.st.Unlock()
+
+	// only the reboot of pc remains
+	s.now = s.now.Add(time.Minute)
+	c.Assert(s.mgr.Ensure(), IsNil)
+	c.Check(s.h.restartRequested, Equals, true)
+}
+
+func (s *scheduleSuite) TestCancelAllScheduledReboots(c *C) {
+	s.st.Lock()
+	c.Assert(restart.ScheduleReboot(s.st, "pc", 0, "", nil), IsNil)
+	cancelled, err := restart.CancelScheduledReboot(s.st, "pc")
+	c.Assert(err, IsNil)
+	c.Check(cancelled, Equals, true)
+	s.st.Unlock()
+
+	c.Assert(s.mgr.Ensure(), IsNil)
+	c.Check(s.h.restartRequested, Equals, false)
+}
+
+func (s *scheduleSuite) TestScheduleRebootNegativeDelay(c *C) {
+	s.st.Lock()
+	defer s.st.Unlock()
+	err := restart.ScheduleReboot(s.st, "pc", -time.Minute, "", nil)
+	c.Assert(err, ErrorMatches, "cannot schedule a reboot with a negative delay")
+}
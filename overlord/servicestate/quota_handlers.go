@@ -613,6 +613,7 @@ func ensureSnapServicesForGroup(st *state.State, t *state.Task, grp *quota.Group
 
 	grpsToStart := []*quota.Group{}
 	journalsToRestart := []string{}
+	journalForwardsToStart := []string{}
 	appsToRestartBySnap = map[*snap.Info][]*snap.AppInfo{}
 	markAppForRestart := func(info *snap.Info, app *snap.AppInfo) {
 		// make sure it is not already in the list
@@ -696,6 +697,14 @@ func ensureSnapServicesForGroup(st *state.State, t *state.Task, grp *quota.Group
 				serviceName := fmt.Sprintf("systemd-journald@%s", grp.JournalNamespaceName())
 				journalsToRestart = append(journalsToRestart, serviceName)
 			}
+
+		case "journal-forward":
+			// the service forwarding the journal namespace was written
+			// or modified, it needs (re)starting, while wrappers takes
+			// care of stopping it when forwarding is disabled
+			if new != "" {
+				journalForwardsToStart = append(journalForwardsToStart, grp.JournalForwardServiceName())
+			}
 		}
 	}
 	if err := wrappers.EnsureSnapServices(snapSvcMap, ensureOpts, collectModifiedUnits, meterLocked); err != nil {
@@ -748,6 +757,17 @@ func ensureSnapServicesForGroup(st *state.State, t *state.Task, grp *quota.Group
 		}
 	}
 
+	// and (re)start forwarding the journal namespaces, once journald
+	// services have the new configuration
+	if len(journalForwardsToStart) > 0 {
+		if err := systemSysd.EnableNoReload(journalForwardsToStart); err != nil {
+			return nil, err
+		}
+		if err := systemSysd.Restart(journalForwardsToStart); err != nil {
+			return nil, err
+		}
+	}
+
 	return appsToRestartBySnap, nil
 }
 
@@ -2380,6 +2380,62 @@ SystemMaxUse=16M
 	})
 }
 
+func (s *quotaHandlersSuite) TestUpdateJournalQuotaForward(c *C) {
+	r := s.mockSystemctlCalls(c, join(
+		[]expectedSystemctl{{expArgs: []string{"daemon-reload"}}},
+		systemctlCallsForSliceStart("foo"),
+		[]expectedSystemctl{
+			{expArgs: []string{"--no-reload", "enable", "snap-foo-journal-forward.service"}},
+			{expArgs: []string{"stop", "snap-foo-journal-forward.service"}},
+			{
+				expArgs: []string{"show", "--property=ActiveState", "snap-foo-journal-forward.service"},
+				output:  "ActiveState=inactive",
+			},
+			{expArgs: []string{"start", "snap-foo-journal-forward.service"}},
+		},
+		systemctlCallsForServiceRestart("test-snap"),
+	))
+	defer r()
+
+	st := s.state
+	st.Lock()
+	defer st.Unlock()
+
+	// setup the snap so it exists
+	snapstate.Set(s.state, "test-snap", s.testSnapState)
+	snaptest.MockSnapCurrent(c, testYaml, s.testSnapSideInfo)
+
+	// setup an existing quota group we can update it
+	err := servicestatetest.MockQuotaInState(st, "foo", "", []string{"test-snap"}, nil, quota.NewResourcesBuilder().WithJournalSize(16*quantity.SizeMiB).Build())
+	c.Assert(err, check.IsNil)
+
+	qc := servicestate.QuotaControlAction{
+		Action:         "update",
+		QuotaName:      "foo",
+		ResourceLimits: quota.NewResourcesBuilder().WithJournalForward("syslog://logs.example.com").Build(),
+	}
+	qcs := []*servicestate.QuotaControlAction{&qc}
+
+	chg := st.NewChange("quota-control-tasks", "...")
+	t := st.NewTask("quota-control", "...")
+	t.Set("quota-control-actions", &qcs)
+	chg.AddTask(t)
+
+	st.Unlock()
+	defer s.se.Stop()
+	err = s.o.Settle(5 * time.Second)
+	st.Lock()
+	c.Check(err, IsNil)
+	checkQuotaState(c, st, map[string]quotaGroupState{
+		"foo": {
+			ResourceLimits: quota.NewResourcesBuilder().WithJournalSize(16 * quantity.SizeMiB).WithJournalForward("syslog://logs.example.com").Build(),
+			Snaps:          []string{"test-snap"},
+		},
+	})
+	c.Check(filepath.Join(dirs.SnapServicesDir, "snap-foo-journal-forward.service"), testutil.FileContains,
+		"--server=logs.example.com --port=514 --udp --tag=snap-foo")
+}
+
 func (s *quotaHandlersSuite) TestRemoveJournalQuota(c *C) {
 	r := s.mockSystemctlCalls(c, join(
 		// RemoveQuota for foo
@@ -0,0 +1,153 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package servicestate
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/strutil"
+)
+
+// servicesNeedingRestartKey is the state key holding, by snap, the names of
+// the services which need a restart to see the changes of the connections
+// of their snap, or to run its current revision after they were kept
+// running across a refresh because of their "ignore-running" refresh-mode.
+const servicesNeedingRestartKey = "services-needing-restart"
+
+func getServicesNeedingRestart(st *state.State) (map[string][]string, error) {
+	var needRestart map[string][]string
+	if err := st.Get(servicesNeedingRestartKey, &needRestart); err != nil && !errors.Is(err, state.ErrNoState) {
+		return nil, err
+	}
+	if needRestart == nil {
+		needRestart = make(map[string][]string)
+	}
+	return needRestart, nil
+}
+
+func setServicesNeedingRestart(st *state.State, needRestart map[string][]string) {
+	if len(needRestart) == 0 {
+		st.Set(servicesNeedingRestartKey, nil)
+		return
+	}
+	st.Set(servicesNeedingRestartKey, needRestart)
+}
+
+// MarkNeedingRestart records that the given services need a restart to see
+// the changes of their snap, until they are restarted.
+// The state must be locked by the caller.
+func MarkNeedingRestart(st *state.State, apps []*snap.AppInfo) error {
+	if len(apps) == 0 {
+		return nil
+	}
+	needRestart, err := getServicesNeedingRestart(st)
+	if err != nil {
+		return err
+	}
+	// forget about the snaps removed in the meantime
+	for snapName := range needRestart {
+		var snapst snapstate.SnapState
+		if err := snapstate.Get(st, snapName, &snapst); errors.Is(err, state.ErrNoState) {
+			delete(needRestart, snapName)
+		}
+	}
+	for _, app := range apps {
+		if !app.IsService() {
+			continue
+		}
+		snapName := app.Snap.InstanceName()
+		if !strutil.ListContains(needRestart[snapName], app.Name) {
+			needRestart[snapName] = append(needRestart[snapName], app.Name)
+			sort.Strings(needRestart[snapName])
+		}
+	}
+	setServicesNeedingRestart(st, needRestart)
+	return nil
+}
+
+// ServicesNeedingRestart returns the services which need a restart to see
+// the changes of their snap, sorted by snap and name.
+// The state must be locked by the caller.
+func ServicesNeedingRestart(st *state.State) ([]*snap.AppInfo, error) {
+	needRestart, err := getServicesNeedingRestart(st)
+	if err != nil {
+		return nil, err
+	}
+	snapNames := make([]string, 0, len(needRestart))
+	for snapName := range needRestart {
+		snapNames = append(snapNames, snapName)
+	}
+	sort.Strings(snapNames)
+
+	var apps []*snap.AppInfo
+	for _, snapName := range snapNames {
+		var snapst snapstate.SnapState
+		if err := snapstate.Get(st, snapName, &snapst); err != nil {
+			if errors.Is(err, state.ErrNoState) {
+				continue
+			}
+			return nil, err
+		}
+		info, err := snapst.CurrentInfo()
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range needRestart[snapName] {
+			// services can be gone after a refresh
+			if app := info.Apps[name]; app != nil && app.IsService() {
+				apps = append(apps, app)
+			}
+		}
+	}
+	return apps, nil
+}
+
+// clearNeedingRestart forgets that the given services of a snap need a
+// restart, once they are restarted.
+func clearNeedingRestart(st *state.State, snapName string, apps []*snap.AppInfo) error {
+	needRestart, err := getServicesNeedingRestart(st)
+	if err != nil {
+		return err
+	}
+	if len(needRestart[snapName]) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(apps))
+	for _, app := range apps {
+		names = append(names, app.Name)
+	}
+	var left []string
+	for _, name := range needRestart[snapName] {
+		if !strutil.ListContains(names, name) {
+			left = append(left, name)
+		}
+	}
+	if len(left) == 0 {
+		delete(needRestart, snapName)
+	} else {
+		needRestart[snapName] = left
+	}
+	setServicesNeedingRestart(st, needRestart)
+	return nil
+}
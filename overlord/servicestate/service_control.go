@@ -185,7 +185,11 @@ func (m *ServiceManager) doServiceControl(t *state.Task, _ *tomb.Tomb) error {
 			ScopeOptions:         sc.ScopeOptions,
 		}, meter, perfTimings)
 		st.Lock()
-		return err
+		if err != nil {
+			return err
+		}
+		// restarted services see the current state of the connections
+		return clearNeedingRestart(st, sc.SnapName, startupOrdered)
 	case "reload-or-restart":
 		st.Unlock()
 		err := wrappers.RestartServices(startupOrdered, explicitServicesSystemdUnits, &wrappers.RestartServicesOptions{
@@ -898,6 +898,91 @@ func (s *serviceControlSuite) TestRestartServices(c *C) {
 	})
 }
 
+func (s *serviceControlSuite) TestServicesNeedingRestart(c *C) {
+	st := s.state
+	st.Lock()
+	defer st.Unlock()
+
+	info := s.mockTestSnap(c)
+
+	apps, err := servicestate.ServicesNeedingRestart(st)
+	c.Assert(err, IsNil)
+	c.Check(apps, HasLen, 0)
+
+	// only services are tracked
+	err = servicestate.MarkNeedingRestart(st, []*snap.AppInfo{info.Apps["foo"], info.Apps["someapp"], info.Apps["bar"]})
+	c.Assert(err, IsNil)
+	err = servicestate.MarkNeedingRestart(st, []*snap.AppInfo{info.Apps["foo"]})
+	c.Assert(err, IsNil)
+
+	apps, err = servicestate.ServicesNeedingRestart(st)
+	c.Assert(err, IsNil)
+	c.Assert(apps, HasLen, 2)
+	c.Check(apps[0].String(), Equals, "test-snap.bar")
+	c.Check(apps[1].String(), Equals, "test-snap.foo")
+
+	// a gone snap is forgotten
+	snapstate.Set(st, "test-snap", nil)
+	apps, err = servicestate.ServicesNeedingRestart(st)
+	c.Assert(err, IsNil)
+	c.Check(apps, HasLen, 0)
+}
+
+func (s *serviceControlSuite) TestSnapstateNeedingRestartHooks(c *C) {
+	st := s.state
+	st.Lock()
+	defer st.Unlock()
+
+	info := s.mockTestSnap(c)
+
+	// snapstate records the services kept running across refreshes
+	err := snapstate.MarkServicesNeedingRestart(st, []*snap.AppInfo{info.Apps["foo"], info.Apps["bar"]})
+	c.Assert(err, IsNil)
+	apps, err := servicestate.ServicesNeedingRestart(st)
+	c.Assert(err, IsNil)
+	c.Assert(apps, HasLen, 2)
+
+	// and forgets them when the refresh is undone
+	err = snapstate.ClearServicesNeedingRestart(st, "test-snap", []*snap.AppInfo{info.Apps["bar"]})
+	c.Assert(err, IsNil)
+	apps, err = servicestate.ServicesNeedingRestart(st)
+	c.Assert(err, IsNil)
+	c.Assert(apps, HasLen, 1)
+	c.Check(apps[0].String(), Equals, "test-snap.foo")
+}
+
+func (s *serviceControlSuite) TestRestartServicesClearsNeedingRestart(c *C) {
+	st := s.state
+	st.Lock()
+	defer st.Unlock()
+
+	info := s.mockTestSnap(c)
+	err := servicestate.MarkNeedingRestart(st, []*snap.AppInfo{info.Apps["foo"], info.Apps["bar"]})
+	c.Assert(err, IsNil)
+
+	chg := st.NewChange("service-control", "...")
+	t := st.NewTask("service-control", "...")
+	cmd := &servicestate.ServiceAction{
+		SnapName: "test-snap",
+		Action:   "restart",
+		Services: []string{"foo"},
+	}
+	t.Set("service-action", cmd)
+	chg.AddTask(t)
+
+	st.Unlock()
+	defer s.se.Stop()
+	err = s.o.Settle(5 * time.Second)
+	st.Lock()
+	c.Assert(err, IsNil)
+	c.Assert(t.Status(), Equals, state.DoneStatus)
+
+	apps, err := servicestate.ServicesNeedingRestart(st)
+	c.Assert(err, IsNil)
+	c.Check(apps, HasLen, 1)
+	c.Check(apps[0].Name, Equals, "bar")
+}
+
 func (s *serviceControlSuite) TestRestartServicesWithScope(c *C) {
 	st := s.state
 	st.Lock()
@@ -219,6 +219,8 @@ func delayedCrossMgrInit() {
 	snapstate.RegisterAffectedSnapsByAttr("service-action", serviceControlAffectedSnaps)
 	snapstate.SnapServiceOptions = SnapServiceOptions
 	snapstate.EnsureSnapAbsentFromQuotaGroup = EnsureSnapAbsentFromQuota
+	snapstate.MarkServicesNeedingRestart = MarkNeedingRestart
+	snapstate.ClearServicesNeedingRestart = clearNeedingRestart
 }
 
 func serviceControlAffectedSnaps(t *state.Task) ([]string, error) {
@@ -356,6 +356,9 @@ type StatusDecorator struct {
 	globalUserSysd systemd.Systemd
 	context        context.Context
 	uid            string
+	// aggregateSessions is set to also report, along with the global
+	// status of user services, in how many user sessions they are active
+	aggregateSessions bool
 }
 
 // NewStatusDecorator returns a new StatusDecorator.
@@ -398,6 +401,44 @@ func (sd *StatusDecorator) hasEnabledActivator(appInfo *client.AppInfo) bool {
 	return false
 }
 
+// NewAggregatingStatusDecorator returns a new StatusDecorator that reports
+// the global enablement of user-services together with the number of user
+// sessions that are currently running them.
+func NewAggregatingStatusDecorator(rep interface {
+	Notify(string)
+}, context context.Context) clientutil.StatusDecorator {
+	return &StatusDecorator{
+		sysd:              systemd.New(systemd.SystemMode, rep),
+		globalUserSysd:    systemd.New(systemd.GlobalUserMode, rep),
+		context:           context,
+		aggregateSessions: true,
+	}
+}
+
+// aggregateUserSessions queries the status of the given user service unit in
+// all the user sessions and records in how many of them it is active.
+func (sd *StatusDecorator) aggregateUserSessions(appInfo *client.AppInfo, unit string) error {
+	sts, failures, err := usc.New().ServiceStatus(sd.context, []string{unit})
+	if err != nil {
+		return err
+	}
+	sessions := make(map[int]bool, len(sts)+len(failures))
+	for uid, unitSts := range sts {
+		sessions[uid] = true
+		for _, st := range unitSts {
+			if st.Active {
+				appInfo.ActiveSessions++
+			}
+		}
+	}
+	// sessions where the status could not be retrieved are still counted
+	for uid := range failures {
+		sessions[uid] = true
+	}
+	appInfo.Sessions = len(sessions)
+	return nil
+}
+
 // queryUserServiceStatus returns a list of service-statuses for the configured users.
 func (sd *StatusDecorator) queryUserServiceStatus(units []string) ([]*systemd.UnitStatus, error) {
 	// Avoid any expensive call if there are no user daemons
@@ -496,6 +537,11 @@ func (sd *StatusDecorator) DecorateWithStatus(appInfo *client.AppInfo, snapApp *
 	if err != nil {
 		return fmt.Errorf("cannot get status of services of app %q: %v", appInfo.Name, err)
 	}
+	if snapApp.DaemonScope == snap.UserDaemon && sd.aggregateSessions {
+		if err := sd.aggregateUserSessions(appInfo, snapApp.ServiceName()); err != nil {
+			return fmt.Errorf("cannot get status of services of app %q in user sessions: %v", appInfo.Name, err)
+		}
+	}
 
 	for _, st := range sts {
 		switch filepath.Ext(st.Name) {
@@ -588,14 +634,19 @@ func SnapServiceOptions(st *state.State, snapInfo *snap.Info, quotaGroups map[st
 
 // LogReader returns an io.ReadCloser which produce logs for the provided
 // snap AppInfo's. It is a convenience wrapper around the systemd.LogReader
-// implementation.
-func LogReader(appInfos []*snap.AppInfo, n int, follow bool) (io.ReadCloser, error) {
-	serviceNames := make([]string, len(appInfos))
-	for i, appInfo := range appInfos {
+// implementation. The logs of user services are restricted to the user with
+// the given uid, or include all users if uid is empty.
+func LogReader(appInfos []*snap.AppInfo, n int, follow bool, uid string) (io.ReadCloser, error) {
+	var serviceNames, userServiceNames []string
+	for _, appInfo := range appInfos {
 		if !appInfo.IsService() {
 			return nil, fmt.Errorf("cannot read logs for app %q: not a service", appInfo.Name)
 		}
-		serviceNames[i] = appInfo.ServiceName()
+		if appInfo.DaemonScope == snap.UserDaemon {
+			userServiceNames = append(userServiceNames, appInfo.ServiceName())
+		} else {
+			serviceNames = append(serviceNames, appInfo.ServiceName())
+		}
 	}
 
 	// Include journal namespaces if supported. The --namespace option was
@@ -609,5 +660,5 @@ func LogReader(appInfos []*snap.AppInfo, n int, follow bool) (io.ReadCloser, err
 	}
 
 	sysd := systemd.New(systemd.SystemMode, progress.Null)
-	return sysd.LogReader(serviceNames, n, follow, includeNamespaces)
+	return sysd.LogReader(serviceNames, userServiceNames, uid, n, follow, includeNamespaces)
 }
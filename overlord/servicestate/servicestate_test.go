@@ -410,6 +410,107 @@ NeedDaemonReload=no
 	}
 }
 
+func (s *statusDecoratorSuite) TestAggregatingDecorateWithStatus(c *C) {
+	snp := &snap.Info{
+		SideInfo: snap.SideInfo{
+			RealName: "foo",
+			Revision: snap.R(1),
+		},
+	}
+	err := os.MkdirAll(snp.MountDir(), 0755)
+	c.Assert(err, IsNil)
+	err = os.Symlink(snp.Revision.String(), filepath.Join(filepath.Dir(snp.MountDir()), "current"))
+	c.Assert(err, IsNil)
+
+	active := true
+	var sessionQueries int
+	r := systemd.MockSystemctl(func(args ...string) (buf []byte, err error) {
+		c.Assert(args[0], Equals, "--user")
+		switch args[1] {
+		case "--global":
+			// global enablement
+			c.Check(args[2:], DeepEquals, []string{"is-enabled", "snap.foo.svc.service"})
+			return []byte("enabled\n"), nil
+		case "show":
+			// status queried by the session agent
+			sessionQueries++
+			c.Check(args[3:], DeepEquals, []string{"snap.foo.svc.service"})
+			activeState := "inactive"
+			if active {
+				activeState = "active"
+			}
+			return []byte(fmt.Sprintf(`Id=snap.foo.svc.service
+Names=snap.foo.svc.service
+Type=simple
+ActiveState=%s
+UnitFileState=enabled
+NeedDaemonReload=no
+`, activeState)), nil
+		}
+		c.Errorf("unexpected systemctl call %q", args)
+		return nil, fmt.Errorf("unexpected")
+	})
+	defer r()
+
+	sd := servicestate.NewAggregatingStatusDecorator(nil, context.Background())
+
+	for _, isActive := range []bool{true, false} {
+		active = isActive
+		app := &client.AppInfo{
+			Snap:   snp.InstanceName(),
+			Name:   "svc",
+			Daemon: "simple",
+		}
+		snapApp := &snap.AppInfo{
+			Snap:        snp,
+			Name:        "svc",
+			Daemon:      "simple",
+			DaemonScope: snap.UserDaemon,
+		}
+
+		err = sd.DecorateWithStatus(app, snapApp)
+		c.Assert(err, IsNil)
+		c.Check(app.Enabled, Equals, true)
+		c.Check(app.Active, Equals, false)
+		// the test runs a single session agent
+		c.Check(app.Sessions, Equals, 1)
+		if isActive {
+			c.Check(app.ActiveSessions, Equals, 1)
+		} else {
+			c.Check(app.ActiveSessions, Equals, 0)
+		}
+	}
+	c.Check(sessionQueries, Equals, 2)
+
+	// system services are not queried in user sessions
+	r = systemd.MockSystemctl(func(args ...string) (buf []byte, err error) {
+		c.Check(args[0], Equals, "show")
+		return []byte(`Id=snap.foo.svc.service
+Names=snap.foo.svc.service
+Type=simple
+ActiveState=active
+UnitFileState=enabled
+NeedDaemonReload=no
+`), nil
+	})
+	defer r()
+	app := &client.AppInfo{
+		Snap:   snp.InstanceName(),
+		Name:   "svc",
+		Daemon: "simple",
+	}
+	snapApp := &snap.AppInfo{
+		Snap:        snp,
+		Name:        "svc",
+		Daemon:      "simple",
+		DaemonScope: snap.SystemDaemon,
+	}
+	err = sd.DecorateWithStatus(app, snapApp)
+	c.Assert(err, IsNil)
+	c.Check(app.Active, Equals, true)
+	c.Check(app.Sessions, Equals, 0)
+}
+
 type instructionSuite struct {
 	rootUser       *user.User
 	defaultUser    *user.User
@@ -948,9 +1049,11 @@ func (s *snapServiceOptionsSuite) TestLogReader(c *C) {
 	defer restore()
 
 	var jctlCalls int
-	restore = systemd.MockJournalctl(func(svcs []string, n int, follow, namespaces bool) (rc io.ReadCloser, err error) {
+	restore = systemd.MockJournalctl(func(svcs, userSvcs []string, uid string, n int, follow, namespaces bool) (rc io.ReadCloser, err error) {
 		jctlCalls++
-		c.Check(svcs, DeepEquals, []string{"snap.foo.svc1.service", "snap.foo.svc2.service"})
+		c.Check(svcs, HasLen, 0)
+		c.Check(userSvcs, DeepEquals, []string{"snap.foo.svc1.service", "snap.foo.svc2.service"})
+		c.Check(uid, Equals, "")
 		c.Check(n, Equals, 100)
 		c.Check(follow, Equals, false)
 		c.Check(namespaces, Equals, false)
@@ -958,7 +1061,7 @@ func (s *snapServiceOptionsSuite) TestLogReader(c *C) {
 	})
 	defer restore()
 
-	_, err := servicestate.LogReader(appInfos, 100, false)
+	_, err := servicestate.LogReader(appInfos, 100, false, "")
 	c.Assert(err, IsNil)
 	c.Check(jctlCalls, Equals, 1)
 }
@@ -990,7 +1093,7 @@ func (s *snapServiceOptionsSuite) TestLogReaderFailsWithNonServices(c *C) {
 		},
 	}
 
-	_, err := servicestate.LogReader(appInfos, 100, false)
+	_, err := servicestate.LogReader(appInfos, 100, false, "")
 	c.Assert(err.Error(), Equals, `cannot read logs for app "app1": not a service`)
 }
 
@@ -1026,9 +1129,11 @@ func (s *snapServiceOptionsSuite) TestLogReaderNamespaces(c *C) {
 
 	restore := systemd.MockSystemdVersion(245, nil)
 	defer restore()
-	restore = systemd.MockJournalctl(func(svcs []string, n int, follow, namespaces bool) (rc io.ReadCloser, err error) {
+	restore = systemd.MockJournalctl(func(svcs, userSvcs []string, uid string, n int, follow, namespaces bool) (rc io.ReadCloser, err error) {
 		jctlCalls++
-		c.Check(svcs, DeepEquals, []string{"snap.foo.svc1.service", "snap.foo.svc2.service"})
+		c.Check(svcs, HasLen, 0)
+		c.Check(userSvcs, DeepEquals, []string{"snap.foo.svc1.service", "snap.foo.svc2.service"})
+		c.Check(uid, Equals, "")
 		c.Check(n, Equals, 100)
 		c.Check(follow, Equals, false)
 		c.Check(namespaces, Equals, true)
@@ -1036,7 +1141,56 @@ func (s *snapServiceOptionsSuite) TestLogReaderNamespaces(c *C) {
 	})
 	defer restore()
 
-	_, err := servicestate.LogReader(appInfos, 100, false)
+	_, err := servicestate.LogReader(appInfos, 100, false, "")
+	c.Assert(err, IsNil)
+	c.Check(jctlCalls, Equals, 1)
+}
+
+func (s *snapServiceOptionsSuite) TestLogReaderMixedScopes(c *C) {
+	st := s.state
+	st.Lock()
+	defer st.Unlock()
+
+	si := snap.SideInfo{RealName: "foo", Revision: snap.R(1)}
+	snp := &snap.Info{SideInfo: si}
+	snapstate.Set(st, "foo", &snapstate.SnapState{
+		Active:   true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{&si}),
+		Current:  snap.R(1),
+		SnapType: "app",
+	})
+	appInfos := []*snap.AppInfo{
+		{
+			Snap:        snp,
+			Name:        "svc1",
+			Daemon:      "simple",
+			DaemonScope: snap.SystemDaemon,
+		},
+		{
+			Snap:        snp,
+			Name:        "svc2",
+			Daemon:      "simple",
+			DaemonScope: snap.UserDaemon,
+		},
+	}
+
+	restore := systemd.MockSystemdVersion(245, nil)
+	defer restore()
+
+	var jctlCalls int
+	restore = systemd.MockJournalctl(func(svcs, userSvcs []string, uid string, n int, follow, namespaces bool) (rc io.ReadCloser, err error) {
+		jctlCalls++
+		c.Check(svcs, DeepEquals, []string{"snap.foo.svc1.service"})
+		c.Check(userSvcs, DeepEquals, []string{"snap.foo.svc2.service"})
+		c.Check(uid, Equals, "1000")
+		c.Check(n, Equals, 10)
+		c.Check(follow, Equals, true)
+		c.Check(namespaces, Equals, true)
+		return io.NopCloser(strings.NewReader("")), nil
+	})
+	defer restore()
+
+	_, err := servicestate.LogReader(appInfos, 10, true, "1000")
 	c.Assert(err, IsNil)
 	c.Check(jctlCalls, Equals, 1)
 }
@@ -325,6 +325,17 @@ func MapSnapDataDirToSnapVar(si *snap.Info, opts *dirs.SnapDirOptions, usernames
 
 // Save a snapshot
 func Save(ctx context.Context, id uint64, si *snap.Info, cfg map[string]any, usernames []string, dynSnapshotOpts *snap.SnapshotOptions, dirOpts *dirs.SnapDirOptions) (*client.Snapshot, error) {
+	return save(ctx, id, si, cfg, usernames, dynSnapshotOpts, dirOpts, false)
+}
+
+// SaveIncremental saves a snapshot like Save, but the data of its archives
+// is stored in the chunk store, where it is shared with the other
+// incremental snapshots.
+func SaveIncremental(ctx context.Context, id uint64, si *snap.Info, cfg map[string]any, usernames []string, dynSnapshotOpts *snap.SnapshotOptions, dirOpts *dirs.SnapDirOptions) (*client.Snapshot, error) {
+	return save(ctx, id, si, cfg, usernames, dynSnapshotOpts, dirOpts, true)
+}
+
+func save(ctx context.Context, id uint64, si *snap.Info, cfg map[string]any, usernames []string, dynSnapshotOpts *snap.SnapshotOptions, dirOpts *dirs.SnapDirOptions, incremental bool) (*client.Snapshot, error) {
 	if err := os.MkdirAll(dirs.SnapshotsDir, 0700); err != nil {
 		return nil, err
 	}
@@ -339,10 +350,11 @@ func Save(ctx context.Context, id uint64, si *snap.Info, cfg map[string]any, use
 		Time:     timeNow(),
 		// Pass only dynamic snapshot options here. Static options are tied to the snap version
 		// and should not be repeated in snapshot metadata on every save.
-		Options:  dynSnapshotOpts,
-		SHA3_384: make(map[string]string),
-		Size:     0,
-		Conf:     cfg,
+		Options:     dynSnapshotOpts,
+		SHA3_384:    make(map[string]string),
+		Size:        0,
+		Incremental: incremental,
+		Conf:        cfg,
 		// Note: Auto is no longer set in the Snapshot.
 	}
 
@@ -469,19 +481,19 @@ func addSnapDirToZip(ctx context.Context, snapshot *client.Snapshot, w *zip.Writ
 
 // addToZip adds 'paths' to the snapshot. tar will change into the paths' parent
 // directory before creating the archive so that parent dirs are not added.
+// For incremental snapshots the archive is added to the chunk store and only
+// the list of its chunks is added to the snapshot.
 func addToZip(ctx context.Context, snapshot *client.Snapshot, w *zip.Writer, username, entry string, paths []string, excludePaths []string) error {
-	archiveWriter, err := w.CreateHeader(&zip.FileHeader{Name: entry})
-	if err != nil {
-		return err
+	tarArgs := []string{"--create", "--sparse"}
+	if !snapshot.Incremental {
+		// chunks are compressed one by one instead
+		tarArgs = append(tarArgs, "--gzip")
 	}
-
-	tarArgs := []string{
-		"--create",
-		"--sparse", "--gzip",
+	tarArgs = append(tarArgs,
 		"--format", "gnu",
 		"--anchored",
 		"--no-wildcards-match-slash",
-	}
+	)
 
 	for _, path := range excludePaths {
 		tarArgs = append(tarArgs, fmt.Sprintf("--exclude=%s", path))
@@ -497,7 +509,17 @@ func addToZip(ctx context.Context, snapshot *client.Snapshot, w *zip.Writer, use
 	hasher := crypto.SHA3_384.New()
 
 	cmd := tarAsUser(ctx, username, tarArgs...)
-	cmd.Stdout = io.MultiWriter(archiveWriter, hasher, &sz)
+	var chunker *chunkWriter
+	if snapshot.Incremental {
+		chunker = &chunkWriter{w: io.MultiWriter(hasher, &sz)}
+		cmd.Stdout = chunker
+	} else {
+		archiveWriter, err := w.CreateHeader(&zip.FileHeader{Name: entry})
+		if err != nil {
+			return err
+		}
+		cmd.Stdout = io.MultiWriter(archiveWriter, hasher, &sz)
+	}
 
 	// keep (at most) the last 5 non-empty lines of what 'tar' writes to stderr
 	// (those are the most likely contain the reason for fatal errors)
@@ -527,6 +549,19 @@ func addToZip(ctx context.Context, snapshot *client.Snapshot, w *zip.Writer, use
 		return fmt.Errorf("tar failed: %v", err)
 	}
 
+	if chunker != nil {
+		if err := chunker.Flush(); err != nil {
+			return err
+		}
+		listWriter, err := w.Create(entry + chunkListSuffix)
+		if err != nil {
+			return err
+		}
+		if err := json.NewEncoder(listWriter).Encode(chunkList{Chunks: chunker.chunks}); err != nil {
+			return err
+		}
+	}
+
 	snapshot.SHA3_384[entry] = fmt.Sprintf("%x", hasher.Sum(nil))
 	snapshot.Size += sz.Size()
 
@@ -895,6 +930,13 @@ func unpackVerifySnapshotImport(ctx context.Context, r io.Reader, realSetID uint
 			continue
 		}
 
+		if strings.HasPrefix(header.Name, "chunks/") {
+			if err := importChunk(strings.TrimPrefix(header.Name, "chunks/"), tr); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
 		if header.Name == "export.json" {
 			// XXX: read into memory and validate once we
 			// hashes in export.json
@@ -942,11 +984,15 @@ type exportMetadata struct {
 	Format int       `json:"format"`
 	Date   time.Time `json:"date"`
 	Files  []string  `json:"files"`
+	Chunks []string  `json:"chunks,omitempty"`
 }
 
 type SnapshotExport struct {
 	// open snapshot files
 	snapshotFiles []*os.File
+	// open chunks of the incremental snapshots, exported along the
+	// snapshot files so that the export is complete on its own
+	chunkFiles []*os.File
 
 	// contentHash of the full snapshot
 	contentHash []byte
@@ -954,6 +1000,10 @@ type SnapshotExport struct {
 	// remember setID mostly for nicer errors
 	setID uint64
 
+	// date of the export, taken from the snapshots so that exporting
+	// the same set twice gives the same data
+	date time.Time
+
 	// cached size, needs to be calculated with CalculateSize
 	size int64
 }
@@ -962,6 +1012,7 @@ type SnapshotExport struct {
 // Close()ed after use to avoid leaking file descriptors.
 func NewSnapshotExport(ctx context.Context, setID uint64) (se *SnapshotExport, err error) {
 	var snapshotFiles []*os.File
+	var chunkFiles []*os.File
 	var snapshotSet client.SnapshotSet
 
 	defer func() {
@@ -970,9 +1021,14 @@ func NewSnapshotExport(ctx context.Context, setID uint64) (se *SnapshotExport, e
 			for _, f := range snapshotFiles {
 				f.Close()
 			}
+			for _, f := range chunkFiles {
+				f.Close()
+			}
 		}
 	}()
 
+	seenChunks := make(map[string]bool)
+
 	// Open all files first and keep the file descriptors
 	// open. The caller should have locked the state so that no
 	// delete/change snapshot operations can happen while the
@@ -995,6 +1051,22 @@ func NewSnapshotExport(ctx context.Context, setID uint64) (se *SnapshotExport, e
 				return fmt.Errorf("cannot open file from descriptor %d", fd)
 			}
 			snapshotFiles = append(snapshotFiles, f)
+
+			sums, err := reader.referencedChunks()
+			if err != nil {
+				return err
+			}
+			for _, sum := range sums {
+				if seenChunks[sum] {
+					continue
+				}
+				seenChunks[sum] = true
+				f, err := os.Open(chunkPath(sum))
+				if err != nil {
+					return fmt.Errorf("cannot open chunk of snapshot %q: %v", reader.Name(), err)
+				}
+				chunkFiles = append(chunkFiles, f)
+			}
 		}
 		return nil
 	})
@@ -1009,7 +1081,7 @@ func NewSnapshotExport(ctx context.Context, setID uint64) (se *SnapshotExport, e
 	if err != nil {
 		return nil, fmt.Errorf("cannot calculate content hash for snapshot export %v: %v", setID, err)
 	}
-	se = &SnapshotExport{snapshotFiles: snapshotFiles, setID: setID, contentHash: h}
+	se = &SnapshotExport{snapshotFiles: snapshotFiles, chunkFiles: chunkFiles, setID: setID, contentHash: h, date: snapshotSet.Time()}
 
 	// ensure we never leak FDs even if the user does not call close
 	runtime.SetFinalizer(se, (*SnapshotExport).Close)
@@ -1041,21 +1113,99 @@ func (se *SnapshotExport) Size() int64 {
 	return se.size
 }
 
+// ETag returns an identifier of the export data. Exports of the same
+// snapshot set have the same data, so ranges of it can be streamed
+// separately as long as the identifier does not change.
+func (se *SnapshotExport) ETag() string {
+	return fmt.Sprintf("%x", se.contentHash)
+}
+
 func (se *SnapshotExport) Close() {
 	for _, f := range se.snapshotFiles {
 		f.Close()
 	}
 	se.snapshotFiles = nil
+	for _, f := range se.chunkFiles {
+		f.Close()
+	}
+	se.chunkFiles = nil
 }
 
 type contentJSON struct {
 	ContentHash []byte `json:"content-hash"`
 }
 
+var errRangeWritten = errors.New("range written")
+
+// rangeWriter writes only the given range of the data written to it.
+type rangeWriter struct {
+	w      io.Writer
+	offset int64
+	length int64
+}
+
+func (rw *rangeWriter) Write(p []byte) (int, error) {
+	if rw.length == 0 {
+		// stop streaming the rest of the export
+		return 0, errRangeWritten
+	}
+	n := len(p)
+	if rw.offset >= int64(n) {
+		rw.offset -= int64(n)
+		return n, nil
+	}
+	p = p[rw.offset:]
+	rw.offset = 0
+	if int64(len(p)) > rw.length {
+		p = p[:rw.length]
+	}
+	if _, err := rw.w.Write(p); err != nil {
+		return 0, err
+	}
+	rw.length -= int64(len(p))
+	return n, nil
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
+
+// StreamTo writes the export to w.
 func (se *SnapshotExport) StreamTo(w io.Writer) error {
+	return se.streamTo(w, 0)
+}
+
+// StreamRangeTo writes length bytes of the export starting at offset to
+// w. Init must have been called before.
+func (se *SnapshotExport) StreamRangeTo(w io.Writer, offset, length int64) error {
+	if offset < 0 || length <= 0 || offset+length > se.size {
+		return fmt.Errorf("cannot export %d bytes at offset %d of snapshot %v: export size is %d", length, offset, se.setID, se.size)
+	}
+	rw := &rangeWriter{w: w, offset: offset, length: length}
+	err := se.streamTo(rw, offset)
+	if rw.length == 0 {
+		// the range was written, the error only tells that the rest
+		// of the export was not
+		return nil
+	}
+	if err == nil {
+		err = fmt.Errorf("cannot export %d bytes at offset %d of snapshot %v: export ended early", length, offset, se.setID)
+	}
+	return err
+}
+
+// streamTo writes the export to w, the data of snapshot files that end
+// before skip is not read as it will be discarded.
+func (se *SnapshotExport) streamTo(w io.Writer, skip int64) error {
 	// write out a tar
 	var files []string
-	tw := tar.NewWriter(w)
+	var sz osutil.Sizer
+	tw := tar.NewWriter(io.MultiWriter(w, &sz))
 	defer tw.Close()
 
 	// export contentHash as content.json
@@ -1068,7 +1218,7 @@ func (se *SnapshotExport) StreamTo(w io.Writer) error {
 		Name:     "content.json",
 		Size:     int64(len(h)),
 		Mode:     0640,
-		ModTime:  timeNow(),
+		ModTime:  se.date,
 	}
 	if err := tw.WriteHeader(hdr); err != nil {
 		return err
@@ -1077,9 +1227,8 @@ func (se *SnapshotExport) StreamTo(w io.Writer) error {
 		return err
 	}
 
-	// write out the individual snapshots
-	for _, snapshotFile := range se.snapshotFiles {
-		stat, err := snapshotFile.Stat()
+	writeFile := func(f *os.File, adjustHeader func(*tar.Header)) error {
+		stat, err := f.Stat()
 		if err != nil {
 			return err
 		}
@@ -1087,20 +1236,53 @@ func (se *SnapshotExport) StreamTo(w io.Writer) error {
 			// should never happen
 			return fmt.Errorf("unexported special file %q in snapshot: %s", stat.Name(), stat.Mode())
 		}
-		if _, err := snapshotFile.Seek(0, 0); err != nil {
+		if _, err := f.Seek(0, 0); err != nil {
 			return fmt.Errorf("cannot seek on %v: %v", stat.Name(), err)
 		}
 		hdr, err := tar.FileInfoHeader(stat, "")
 		if err != nil {
 			return fmt.Errorf("symlink: %v", stat.Name())
 		}
+		if adjustHeader != nil {
+			adjustHeader(hdr)
+		}
 		if err = tw.WriteHeader(hdr); err != nil {
 			return fmt.Errorf("cannot write header for %v: %v", stat.Name(), err)
 		}
-		if _, err := io.Copy(tw, snapshotFile); err != nil {
+		var data io.Reader = f
+		if sz.Size()+hdr.Size <= skip {
+			data = io.LimitReader(zeroReader{}, hdr.Size)
+		}
+		if _, err := io.Copy(tw, data); err != nil {
 			return fmt.Errorf("cannot write data for %v: %v", stat.Name(), err)
 		}
+		return nil
+	}
 
+	// write out the chunks of incremental snapshots first, so that they
+	// are in place when the snapshots are checked on import
+	var chunks []string
+	for _, chunkFile := range se.chunkFiles {
+		err := writeFile(chunkFile, func(hdr *tar.Header) {
+			hdr.Name = "chunks/" + hdr.Name
+			// chunks that are reused get their time bumped, use
+			// the date of the export so that exporting the same
+			// set twice still gives the same data
+			hdr.ModTime = se.date
+			hdr.AccessTime = time.Time{}
+			hdr.ChangeTime = time.Time{}
+		})
+		if err != nil {
+			return err
+		}
+		chunks = append(chunks, path.Base(chunkFile.Name()))
+	}
+
+	// write out the individual snapshots
+	for _, snapshotFile := range se.snapshotFiles {
+		if err := writeFile(snapshotFile, nil); err != nil {
+			return err
+		}
 		files = append(files, path.Base(snapshotFile.Name()))
 	}
 
@@ -1118,8 +1300,9 @@ func (se *SnapshotExport) StreamTo(w io.Writer) error {
 	// validate the archive is complete
 	meta := exportMetadata{
 		Format: 1,
-		Date:   timeNow(),
+		Date:   se.date,
 		Files:  files,
+		Chunks: chunks,
 	}
 	metaDataBuf, err := json.Marshal(&meta)
 	if err != nil {
@@ -1130,7 +1313,7 @@ func (se *SnapshotExport) StreamTo(w io.Writer) error {
 		Name:     "export.json",
 		Size:     int64(len(metaDataBuf)),
 		Mode:     0640,
-		ModTime:  timeNow(),
+		ModTime:  se.date,
 	}
 	if err := tw.WriteHeader(hdr); err != nil {
 		return err
@@ -23,6 +23,7 @@ import (
 	"archive/tar"
 	"archive/zip"
 	"bytes"
+	"compress/gzip"
 	"context"
 	"crypto"
 	"crypto/sha256"
@@ -1362,6 +1363,62 @@ func (s *snapshotSuite) TestExportTwice(c *check.C) {
 	c.Check(buf.Len(), check.Equals, int(expectedSize))
 }
 
+func (s *snapshotSuite) TestExportRange(c *check.C) {
+	info := &snap.Info{
+		SideInfo: snap.SideInfo{
+			RealName: "hello-snap",
+			Revision: snap.R(42),
+			SnapID:   "hello-id",
+		},
+		Version: "v1.33",
+	}
+	shID := uint64(12)
+	_, err := backend.Save(context.TODO(), shID, info, nil, []string{"snapuser"}, nil, nil)
+	c.Assert(err, check.IsNil)
+
+	ctx := context.Background()
+	se, err := backend.NewSnapshotExport(ctx, shID)
+	c.Assert(err, check.IsNil)
+	defer se.Close()
+	c.Assert(se.Init(), check.IsNil)
+	c.Check(se.ETag(), check.Matches, "[0-9a-f]{64}")
+
+	var full bytes.Buffer
+	c.Assert(se.StreamTo(&full), check.IsNil)
+	c.Assert(int64(full.Len()), check.Equals, se.Size())
+
+	// a new export of the same set has the same data
+	se2, err := backend.NewSnapshotExport(ctx, shID)
+	c.Assert(err, check.IsNil)
+	defer se2.Close()
+	c.Assert(se2.Init(), check.IsNil)
+	c.Check(se2.ETag(), check.Equals, se.ETag())
+	var again bytes.Buffer
+	c.Assert(se2.StreamTo(&again), check.IsNil)
+	c.Check(again.Bytes(), check.DeepEquals, full.Bytes())
+
+	// ranges reassemble into the whole export, including ranges that
+	// start after the data of the snapshot file
+	var chunks bytes.Buffer
+	for offset := int64(0); offset < se.Size(); offset += 700 {
+		length := int64(700)
+		if offset+length > se.Size() {
+			length = se.Size() - offset
+		}
+		var chunk bytes.Buffer
+		c.Assert(se.StreamRangeTo(&chunk, offset, length), check.IsNil)
+		c.Assert(int64(chunk.Len()), check.Equals, length)
+		chunks.Write(chunk.Bytes())
+	}
+	c.Check(chunks.Bytes(), check.DeepEquals, full.Bytes())
+
+	var buf bytes.Buffer
+	err = se.StreamRangeTo(&buf, se.Size()-10, 20)
+	c.Check(err, check.ErrorMatches, fmt.Sprintf("cannot export 20 bytes at offset %d of snapshot 12: export size is %d", se.Size()-10, se.Size()))
+	err = se.StreamRangeTo(&buf, 0, 0)
+	c.Check(err, check.ErrorMatches, "cannot export 0 bytes at offset 0 of snapshot 12: .*")
+}
+
 func (s *snapshotSuite) TestExportUnhappy(c *check.C) {
 	se, err := backend.NewSnapshotExport(context.Background(), 5)
 	c.Assert(err, check.ErrorMatches, "no snapshot data found for 5")
@@ -1780,3 +1837,67 @@ func (s *snapshotSuite) TestMapSnapDataDirToSnapVarUserLookupError(c *check.C) {
 	c.Assert(err, check.ErrorMatches, "mock usersForUsernames error")
 	c.Check(mappings, check.IsNil)
 }
+
+func (s *snapshotSuite) TestVerify(c *check.C) {
+	var tgz bytes.Buffer
+	gz := gzip.NewWriter(&tgz)
+	tw := tar.NewWriter(gz)
+	c.Assert(tw.WriteHeader(&tar.Header{Name: "foo", Mode: 0644, Size: 3}), check.IsNil)
+	_, err := tw.Write([]byte("foo"))
+	c.Assert(err, check.IsNil)
+	c.Assert(tw.Close(), check.IsNil)
+	c.Assert(gz.Close(), check.IsNil)
+
+	members := map[string][]byte{
+		"archive.tgz": tgz.Bytes(),
+		// hash is fine but the data is not a gzipped tar
+		"user/broken.tgz": []byte("not a tarball"),
+		// hash does not match
+		"user/mismatch.tgz": tgz.Bytes(),
+		// not checked by user request
+		"user/other.tgz": []byte("not a tarball"),
+	}
+	hashes := make(map[string]string, len(members))
+	for entry, data := range members {
+		hasher := crypto.SHA3_384.New()
+		hasher.Write(data)
+		hashes[entry] = fmt.Sprintf("%x", hasher.Sum(nil))
+	}
+	hashes["user/mismatch.tgz"] = strings.Repeat("0", 96)
+
+	f, err := os.Create(filepath.Join(c.MkDir(), "1_foo_1.0_1.zip"))
+	c.Assert(err, check.IsNil)
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for _, entry := range []string{"archive.tgz", "user/broken.tgz", "user/mismatch.tgz", "user/other.tgz"} {
+		w, err := zw.Create(entry)
+		c.Assert(err, check.IsNil)
+		_, err = w.Write(members[entry])
+		c.Assert(err, check.IsNil)
+	}
+	c.Assert(zw.Close(), check.IsNil)
+
+	r := &backend.Reader{
+		Snapshot: client.Snapshot{SetID: 1, Snap: "foo", SHA3_384: hashes},
+		File:     f,
+	}
+
+	corruptions, err := r.Verify(context.TODO(), []string{"broken", "mismatch"})
+	c.Assert(err, check.IsNil)
+	c.Assert(corruptions, check.HasLen, 2)
+	c.Check(corruptions[0].SetID, check.Equals, uint64(1))
+	c.Check(corruptions[0].Snap, check.Equals, "foo")
+	c.Check(corruptions[0].Entry, check.Equals, "user/broken.tgz")
+	c.Check(corruptions[0].Reason, check.Matches, `cannot read snapshot entry "user/broken.tgz": .*`)
+	c.Check(corruptions[1].Entry, check.Equals, "user/mismatch.tgz")
+	c.Check(corruptions[1].Reason, check.Matches, `snapshot entry "user/mismatch.tgz" expected hash \(0000000…\) does not match actual \(.*\)`)
+
+	// a plain check stops at the first corrupted archive
+	c.Check(r.Check(context.TODO(), []string{"broken"}), check.IsNil)
+	c.Check(r.Check(context.TODO(), []string{"mismatch"}), check.ErrorMatches, `snapshot entry "user/mismatch.tgz" expected hash .*`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = r.Verify(ctx, nil)
+	c.Check(err, check.Equals, context.Canceled)
+}
@@ -0,0 +1,430 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package backend
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/osutil"
+)
+
+// The archives of incremental snapshots are not stored in the snapshot
+// file. Their uncompressed tar stream is cut into chunks at boundaries that
+// depend on the content only, so that the data of files that did not change
+// between two snapshots ends up in the same chunks, and each chunk is stored
+// gzip compressed, once, in a chunk store shared by all snapshots. The
+// snapshot file lists the chunks of each archive. As the concatenation of
+// gzip streams is itself a valid gzip stream, the concatenation of the
+// stored chunks is a valid compressed archive, which is what the hash and
+// size of the archive in the snapshot metadata refer to.
+//
+// Chunks are never modified once written, they are removed by
+// CleanupChunks once no snapshot refers to them anymore.
+
+const (
+	chunkListSuffix = ".chunks"
+
+	// chunks are at least minChunkSize and at most maxChunkSize bytes,
+	// past minChunkSize a boundary is found when the bits of the rolling
+	// hash selected by chunkBoundaryMask are all zero, which happens every
+	// 1MiB on average
+	minChunkSize      = 256 * 1024
+	maxChunkSize      = 4 * 1024 * 1024
+	chunkBoundaryMask = uint64(1<<20-1) << 44
+)
+
+var (
+	// chunks that no snapshot refers to are only removed after
+	// chunkGracePeriod, so that the chunks written or reused by snapshots
+	// that are still being saved or imported are kept
+	chunkGracePeriod = 24 * time.Hour
+
+	validChunkSum = regexp.MustCompile("^[0-9a-f]{96}$")
+
+	// gearTable holds the values the rolling hash uses for each byte; it
+	// must never change as that would move the chunk boundaries and stop
+	// the chunks of new snapshots from matching the ones already stored
+	gearTable = func() (t [256]uint64) {
+		for i := range t {
+			sum := sha256.Sum256([]byte{byte(i)})
+			t[i] = binary.LittleEndian.Uint64(sum[:8])
+		}
+		return t
+	}()
+)
+
+// chunksDir is where the chunks of incremental snapshots are kept. It is
+// not a valid snapshot filename so Iter skips it.
+func chunksDir() string {
+	return filepath.Join(dirs.SnapshotsDir, "chunks")
+}
+
+func chunkPath(sum string) string {
+	return filepath.Join(chunksDir(), sum[:2], sum)
+}
+
+// chunkRef refers to a chunk of an archive of an incremental snapshot.
+type chunkRef struct {
+	// SHA3_384 is the hash of the uncompressed data of the chunk, it is
+	// also its name in the chunk store
+	SHA3_384 string `json:"sha3-384"`
+	// Size is the size of the chunk as stored, i.e. compressed
+	Size int64 `json:"size"`
+}
+
+type chunkList struct {
+	Chunks []chunkRef `json:"chunks"`
+}
+
+func compressChunk(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	// the header is left empty so that compressing the same data always
+	// gives the same stored chunk
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(data); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decompressChunk(stored []byte) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(stored))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+	return io.ReadAll(io.LimitReader(gz, maxChunkSize+1))
+}
+
+func chunkSum(data []byte) string {
+	h := crypto.SHA3_384.New()
+	h.Write(data)
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// storeChunk adds the chunk with the given uncompressed data to the chunk
+// store, unless it is already there, and returns the chunk as stored.
+func storeChunk(sum string, data []byte) ([]byte, error) {
+	p := chunkPath(sum)
+	stored, err := os.ReadFile(p)
+	if err == nil {
+		if uncompressed, err := decompressChunk(stored); err == nil && bytes.Equal(uncompressed, data) {
+			// bump the time of the chunk so that it is not
+			// removed before the snapshot that reuses it is
+			// complete
+			now := timeNow()
+			if err := os.Chtimes(p, now, now); err != nil {
+				return nil, err
+			}
+			return stored, nil
+		}
+		// the data is compressed the same way every time, rewriting
+		// the chunk repairs the snapshots that refer to it
+		logger.Noticef("Replacing corrupted snapshot chunk %.7s….", sum)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	stored, err = compressChunk(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+		return nil, err
+	}
+	if err := osutil.AtomicWriteFile(p, stored, 0600, 0); err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
+
+// chunkWriter cuts the data written to it into content-defined chunks that
+// it adds to the chunk store. The stored chunks are written to w, in order.
+type chunkWriter struct {
+	w      io.Writer
+	buf    []byte
+	hash   uint64
+	chunks []chunkRef
+}
+
+func (cw *chunkWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		i := 0
+		boundary := false
+		for i < len(p) {
+			cw.hash = cw.hash<<1 + gearTable[p[i]]
+			i++
+			size := len(cw.buf) + i
+			if size >= maxChunkSize || (size >= minChunkSize && cw.hash&chunkBoundaryMask == 0) {
+				boundary = true
+				break
+			}
+		}
+		cw.buf = append(cw.buf, p[:i]...)
+		p = p[i:]
+		if boundary {
+			if err := cw.Flush(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return n, nil
+}
+
+// Flush stores the data written since the last chunk boundary as a chunk.
+func (cw *chunkWriter) Flush() error {
+	if len(cw.buf) == 0 {
+		return nil
+	}
+	sum := chunkSum(cw.buf)
+	stored, err := storeChunk(sum, cw.buf)
+	if err != nil {
+		return fmt.Errorf("cannot store chunk: %v", err)
+	}
+	if _, err := cw.w.Write(stored); err != nil {
+		return err
+	}
+	cw.chunks = append(cw.chunks, chunkRef{SHA3_384: sum, Size: int64(len(stored))})
+	cw.buf = cw.buf[:0]
+	cw.hash = 0
+	return nil
+}
+
+// chunkedEntryReader reads the stored chunks of an archive one after
+// the other.
+type chunkedEntryReader struct {
+	chunks []chunkRef
+	cur    *os.File
+	left   int64
+}
+
+func (cr *chunkedEntryReader) Read(p []byte) (int, error) {
+	for cr.cur == nil {
+		if len(cr.chunks) == 0 {
+			return 0, io.EOF
+		}
+		chunk := cr.chunks[0]
+		cr.chunks = cr.chunks[1:]
+		f, err := os.Open(chunkPath(chunk.SHA3_384))
+		if err != nil {
+			if os.IsNotExist(err) {
+				return 0, fmt.Errorf("missing chunk %.7s…", chunk.SHA3_384)
+			}
+			return 0, err
+		}
+		cr.cur = f
+		cr.left = chunk.Size
+	}
+
+	if int64(len(p)) > cr.left {
+		p = p[:cr.left]
+	}
+	n, err := cr.cur.Read(p)
+	cr.left -= int64(n)
+	if err == io.EOF {
+		if cr.left > 0 {
+			err = fmt.Errorf("chunk %s is shorter than expected", filepath.Base(cr.cur.Name()))
+			return n, err
+		}
+		err = nil
+	}
+	if cr.left == 0 {
+		cr.cur.Close()
+		cr.cur = nil
+	}
+	return n, err
+}
+
+func (cr *chunkedEntryReader) Close() error {
+	if cr.cur != nil {
+		return cr.cur.Close()
+	}
+	return nil
+}
+
+// entryChunks returns the chunks of the given archive of an incremental
+// snapshot.
+func (r *Reader) entryChunks(entry string) ([]chunkRef, error) {
+	body, _, err := zipMember(r.File, entry+chunkListSuffix)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var list chunkList
+	if err := json.NewDecoder(body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("cannot decode chunks of snapshot entry %q: %v", entry, err)
+	}
+	for _, chunk := range list.Chunks {
+		if !validChunkSum.MatchString(chunk.SHA3_384) || chunk.Size <= 0 {
+			return nil, fmt.Errorf("invalid chunk in snapshot entry %q", entry)
+		}
+	}
+	return list.Chunks, nil
+}
+
+// openEntry returns the data of the given archive and its size, the data of
+// incremental snapshots is read from the chunk store.
+func (r *Reader) openEntry(entry string) (io.ReadCloser, int64, error) {
+	if !r.Incremental {
+		return zipMember(r.File, entry)
+	}
+	chunks, err := r.entryChunks(entry)
+	if err != nil {
+		return nil, -1, err
+	}
+	var size int64
+	for _, chunk := range chunks {
+		size += chunk.Size
+	}
+	return &chunkedEntryReader{chunks: chunks}, size, nil
+}
+
+// referencedChunks returns the sums of the chunks the archives of the
+// snapshot refer to.
+func (r *Reader) referencedChunks() ([]string, error) {
+	if !r.Incremental {
+		return nil, nil
+	}
+	var sums []string
+	for entry := range r.SHA3_384 {
+		chunks, err := r.entryChunks(entry)
+		if err != nil {
+			return nil, err
+		}
+		for _, chunk := range chunks {
+			sums = append(sums, chunk.SHA3_384)
+		}
+	}
+	return sums, nil
+}
+
+// importChunk adds a chunk from a snapshot import to the chunk store,
+// after checking that it matches its sum.
+func importChunk(sum string, r io.Reader) error {
+	if !validChunkSum.MatchString(sum) {
+		return fmt.Errorf("invalid chunk name %q in import file", sum)
+	}
+	// a stored chunk of maxChunkSize bytes of incompressible data is a
+	// bit larger than the data, this leaves room for the gzip overhead
+	stored, err := io.ReadAll(io.LimitReader(r, 2*maxChunkSize))
+	if err != nil {
+		return err
+	}
+	data, err := decompressChunk(stored)
+	if err != nil {
+		return fmt.Errorf("cannot decompress chunk %.7s…: %v", sum, err)
+	}
+	if len(data) > maxChunkSize || chunkSum(data) != sum {
+		return fmt.Errorf("chunk %.7s… does not match its content", sum)
+	}
+	if _, err := storeChunk(sum, data); err != nil {
+		return fmt.Errorf("cannot store chunk %.7s…: %v", sum, err)
+	}
+	return nil
+}
+
+// CleanupChunks removes the chunks of incremental snapshots that no snapshot
+// refers to anymore, which is how the space of forgotten snapshots is
+// reclaimed. Chunks written or reused in the last day are kept as they
+// might belong to a snapshot that is still being saved or imported.
+//
+// The amount of chunks removed is returned. Nothing is removed if any of
+// the snapshots is broken as the chunks it refers to cannot be determined.
+func CleanupChunks(ctx context.Context) (removed int, err error) {
+	subDirs, err := os.ReadDir(chunksDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			// no incremental snapshot was ever saved
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	referenced := make(map[string]bool)
+	err = Iter(ctx, func(r *Reader) error {
+		if r.Broken != "" {
+			return fmt.Errorf("cannot determine chunks of broken snapshot #%d of %q: %s", r.SetID, r.Snap, r.Broken)
+		}
+		sums, err := r.referencedChunks()
+		if err != nil {
+			return fmt.Errorf("cannot determine chunks of snapshot %q: %v", r.Name(), err)
+		}
+		for _, sum := range sums {
+			referenced[sum] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := timeNow().Add(-chunkGracePeriod)
+	for _, subDir := range subDirs {
+		if !subDir.IsDir() {
+			continue
+		}
+		dir := filepath.Join(chunksDir(), subDir.Name())
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return removed, err
+		}
+		for _, entry := range entries {
+			if referenced[entry.Name()] {
+				continue
+			}
+			// this also removes what is left of chunks that were not
+			// completely written
+			fi, err := entry.Info()
+			if err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return removed, err
+			}
+			if fi.ModTime().After(cutoff) {
+				continue
+			}
+			if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil && !os.IsNotExist(err) {
+				return removed, err
+			}
+			removed++
+		}
+	}
+	return removed, nil
+}
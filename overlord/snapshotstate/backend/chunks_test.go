@@ -0,0 +1,299 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package backend_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/overlord/snapshotstate/backend"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/testutil"
+)
+
+func randomData(size int, seed int64) []byte {
+	data := make([]byte, size)
+	rand.New(rand.NewSource(seed)).Read(data)
+	return data
+}
+
+func storedChunkNames(c *check.C) []string {
+	var names []string
+	err := filepath.Walk(backend.ChunksDir(), func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.Mode().IsRegular() {
+			names = append(names, fi.Name())
+		}
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return nil
+	}
+	c.Assert(err, check.IsNil)
+	sort.Strings(names)
+	return names
+}
+
+func (s *snapshotSuite) TestChunksAreContentDefined(c *check.C) {
+	data := randomData(12*1024*1024, 1)
+
+	chunks, stored, err := backend.StoreChunks(data)
+	c.Assert(err, check.IsNil)
+	c.Assert(len(chunks) > 2, check.Equals, true)
+
+	// the stored chunks make up a valid gzip stream of the data
+	gz, err := gzip.NewReader(bytes.NewReader(stored))
+	c.Assert(err, check.IsNil)
+	uncompressed, err := io.ReadAll(gz)
+	c.Assert(err, check.IsNil)
+	c.Check(bytes.Equal(uncompressed, data), check.Equals, true)
+
+	var storedSize int64
+	for _, chunk := range chunks {
+		storedSize += chunk.Size
+		c.Check(backend.ChunkPath(chunk.SHA3_384), testutil.FilePresent)
+	}
+	c.Check(storedSize, check.Equals, int64(len(stored)))
+
+	// inserting data only changes the chunks around the insertion
+	modified := append(append(append([]byte(nil), data[:6*1024*1024]...), "some new data"...), data[6*1024*1024:]...)
+	modifiedChunks, _, err := backend.StoreChunks(modified)
+	c.Assert(err, check.IsNil)
+
+	known := make(map[string]bool)
+	for _, chunk := range chunks {
+		known[chunk.SHA3_384] = true
+	}
+	var changed int
+	for _, chunk := range modifiedChunks {
+		if !known[chunk.SHA3_384] {
+			changed++
+		}
+	}
+	c.Check(changed > 0, check.Equals, true)
+	c.Check(changed <= 2, check.Equals, true, check.Commentf("%d chunks changed", changed))
+}
+
+func (s *snapshotSuite) TestChunksAreStoredOnce(c *check.C) {
+	data := randomData(3*1024*1024, 2)
+
+	chunks, stored, err := backend.StoreChunks(data)
+	c.Assert(err, check.IsNil)
+	names := storedChunkNames(c)
+	c.Check(names, check.HasLen, len(chunks))
+
+	chunksAgain, storedAgain, err := backend.StoreChunks(data)
+	c.Assert(err, check.IsNil)
+	c.Check(chunksAgain, check.DeepEquals, chunks)
+	c.Check(bytes.Equal(storedAgain, stored), check.Equals, true)
+	c.Check(storedChunkNames(c), check.DeepEquals, names)
+}
+
+func (s *snapshotSuite) TestChunksCorruptedChunkIsReplaced(c *check.C) {
+	data := randomData(1024*1024, 3)
+
+	chunks, stored, err := backend.StoreChunks(data)
+	c.Assert(err, check.IsNil)
+	c.Assert(chunks, check.HasLen, 1)
+
+	p := backend.ChunkPath(chunks[0].SHA3_384)
+	c.Assert(os.WriteFile(p, []byte("garbage"), 0600), check.IsNil)
+
+	_, _, err = backend.StoreChunks(data)
+	c.Assert(err, check.IsNil)
+	c.Check(p, testutil.FileEquals, stored)
+}
+
+func (s *snapshotSuite) mockTarAsCurrentUser() {
+	s.restore = append(s.restore, backend.MockTarAsUser(func(ctx context.Context, username string, args ...string) *exec.Cmd {
+		return exec.CommandContext(ctx, s.tarPath, args...)
+	}))
+}
+
+func (s *snapshotSuite) TestSaveIncrementalRoundtrip(c *check.C) {
+	s.mockTarAsCurrentUser()
+
+	info := &snap.Info{SideInfo: snap.SideInfo{RealName: "hello-snap", Revision: snap.R(42), SnapID: "hello-id"}, Version: "v1.33"}
+	si := snap.MinimalPlaceInfo("hello-snap", snap.R(42))
+	big := randomData(2*1024*1024, 4)
+	c.Assert(os.WriteFile(filepath.Join(si.DataDir(), "big"), big, 0644), check.IsNil)
+
+	shw, err := backend.SaveIncremental(context.TODO(), 12, info, nil, []string{"snapuser"}, nil, nil)
+	c.Assert(err, check.IsNil)
+	c.Check(shw.Incremental, check.Equals, true)
+	c.Check(hashkeys(shw), check.DeepEquals, []string{"archive.tgz", "user/snapuser.tgz"})
+
+	// the snapshot file only lists the chunks
+	zr, err := zip.OpenReader(backend.Filename(shw))
+	c.Assert(err, check.IsNil)
+	var members []string
+	for _, f := range zr.File {
+		members = append(members, f.Name)
+	}
+	zr.Close()
+	c.Check(members, check.DeepEquals, []string{"archive.tgz.chunks", "user/snapuser.tgz.chunks", "meta.json", "meta.sha3_384"})
+	chunks := storedChunkNames(c)
+	c.Check(len(chunks) > 0, check.Equals, true)
+
+	shr, err := backend.Open(backend.Filename(shw), backend.ExtractFnameSetID)
+	c.Assert(err, check.IsNil)
+	c.Check(shr.Incremental, check.Equals, true)
+	c.Check(shr.Check(context.TODO(), nil), check.IsNil)
+	corruptions, err := shr.Verify(context.TODO(), nil)
+	c.Check(err, check.IsNil)
+	c.Check(corruptions, check.HasLen, 0)
+
+	// saving the same data again does not add chunks
+	shw2, err := backend.SaveIncremental(context.TODO(), 13, info, nil, []string{"snapuser"}, nil, nil)
+	c.Assert(err, check.IsNil)
+	c.Check(shw2.SHA3_384, check.DeepEquals, shw.SHA3_384)
+	c.Check(storedChunkNames(c), check.DeepEquals, chunks)
+
+	// the data is restored from the chunks
+	c.Assert(os.RemoveAll(si.DataDir()), check.IsNil)
+	rs, err := shr.Restore(context.TODO(), snap.R(0), nil, c.Logf, nil)
+	shr.Close()
+	c.Assert(err, check.IsNil)
+	rs.Cleanup()
+	c.Check(filepath.Join(si.DataDir(), "big"), testutil.FileEquals, big)
+	c.Check(filepath.Join(si.DataDir(), "foo"), testutil.FileEquals, "versioned system canary\n")
+}
+
+func (s *snapshotSuite) TestIncrementalMissingChunk(c *check.C) {
+	s.mockTarAsCurrentUser()
+
+	info := &snap.Info{SideInfo: snap.SideInfo{RealName: "hello-snap", Revision: snap.R(42), SnapID: "hello-id"}, Version: "v1.33"}
+	shw, err := backend.SaveIncremental(context.TODO(), 12, info, nil, nil, nil, nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(os.RemoveAll(backend.ChunksDir()), check.IsNil)
+
+	shr, err := backend.Open(backend.Filename(shw), backend.ExtractFnameSetID)
+	c.Assert(err, check.IsNil)
+	defer shr.Close()
+	c.Check(shr.Check(context.TODO(), nil), check.ErrorMatches, "missing chunk .*")
+}
+
+func (s *snapshotSuite) TestIncrementalExportImportRoundtrip(c *check.C) {
+	s.mockTarAsCurrentUser()
+
+	ctx := context.TODO()
+	info := &snap.Info{SideInfo: snap.SideInfo{RealName: "hello-snap", Revision: snap.R(42), SnapID: "hello-id"}, Version: "v1.33"}
+	shw, err := backend.SaveIncremental(ctx, 12, info, nil, []string{"snapuser"}, nil, nil)
+	c.Assert(err, check.IsNil)
+
+	export, err := backend.NewSnapshotExport(ctx, shw.SetID)
+	c.Assert(err, check.IsNil)
+	c.Assert(export.Init(), check.IsNil)
+	buf := bytes.NewBuffer(nil)
+	c.Assert(export.StreamTo(buf), check.IsNil)
+	export.Close()
+	c.Check(buf.Len(), check.Equals, int(export.Size()))
+
+	// the export carries the chunks, the import puts them back
+	c.Assert(os.Remove(backend.Filename(shw)), check.IsNil)
+	c.Assert(os.RemoveAll(backend.ChunksDir()), check.IsNil)
+
+	names, err := backend.Import(ctx, 123, buf, nil)
+	c.Assert(err, check.IsNil)
+	c.Check(names, check.DeepEquals, []string{"hello-snap"})
+
+	rdr, err := backend.Open(filepath.Join(dirs.SnapshotsDir, "123_hello-snap_v1.33_42.zip"), backend.ExtractFnameSetID)
+	c.Assert(err, check.IsNil)
+	defer rdr.Close()
+	c.Check(rdr.Incremental, check.Equals, true)
+	c.Check(rdr.Check(ctx, nil), check.IsNil)
+}
+
+func (s *snapshotSuite) TestCleanupChunks(c *check.C) {
+	s.mockTarAsCurrentUser()
+
+	n, err := backend.CleanupChunks(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Check(n, check.Equals, 0)
+
+	info := &snap.Info{SideInfo: snap.SideInfo{RealName: "hello-snap", Revision: snap.R(42), SnapID: "hello-id"}, Version: "v1.33"}
+	shw, err := backend.SaveIncremental(context.TODO(), 12, info, nil, nil, nil, nil)
+	c.Assert(err, check.IsNil)
+	used := storedChunkNames(c)
+
+	unused, _, err := backend.StoreChunks(randomData(1024, 5))
+	c.Assert(err, check.IsNil)
+	recent, _, err := backend.StoreChunks(randomData(1024, 6))
+	c.Assert(err, check.IsNil)
+
+	old := time.Now().Add(-48 * time.Hour)
+	for _, sum := range append(append([]string(nil), used...), unused[0].SHA3_384) {
+		c.Assert(os.Chtimes(backend.ChunkPath(sum), old, old), check.IsNil)
+	}
+
+	// only the old chunk that no snapshot refers to is removed
+	n, err = backend.CleanupChunks(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Check(n, check.Equals, 1)
+	c.Check(backend.ChunkPath(unused[0].SHA3_384), testutil.FileAbsent)
+	c.Check(backend.ChunkPath(recent[0].SHA3_384), testutil.FilePresent)
+	for _, sum := range used {
+		c.Check(backend.ChunkPath(sum), testutil.FilePresent)
+	}
+
+	// once the snapshot is gone its chunks go too
+	c.Assert(os.Remove(backend.Filename(shw)), check.IsNil)
+	n, err = backend.CleanupChunks(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Check(n, check.Equals, len(used))
+	c.Check(storedChunkNames(c), check.DeepEquals, []string{recent[0].SHA3_384})
+}
+
+func (s *snapshotSuite) TestCleanupChunksBrokenSnapshot(c *check.C) {
+	chunks, _, err := backend.StoreChunks(randomData(1024, 7))
+	c.Assert(err, check.IsNil)
+	old := time.Now().Add(-48 * time.Hour)
+	c.Assert(os.Chtimes(backend.ChunkPath(chunks[0].SHA3_384), old, old), check.IsNil)
+
+	restore := backend.MockOpen(func(fn string, setID uint64) (*backend.Reader, error) {
+		r := &backend.Reader{}
+		r.SetID = setID
+		r.Snap = "hello-snap"
+		r.Broken = "broken"
+		return r, errors.New(r.Broken)
+	})
+	defer restore()
+	c.Assert(os.WriteFile(filepath.Join(dirs.SnapshotsDir, "1_hello-snap_v1.33_42.zip"), nil, 0600), check.IsNil)
+
+	n, err := backend.CleanupChunks(context.TODO())
+	c.Assert(err, check.ErrorMatches, `cannot determine chunks of broken snapshot #1 of "hello-snap": broken`)
+	c.Check(n, check.Equals, 0)
+	c.Check(backend.ChunkPath(chunks[0].SHA3_384), testutil.FilePresent)
+}
@@ -20,6 +20,7 @@
 package backend
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"os/exec"
@@ -158,3 +159,25 @@ func MockReadSnapshotYaml(f func(si *snap.Info) (*snap.SnapshotOptions, error))
 		snapReadSnapshotYaml = oldReadSnapshotYaml
 	}
 }
+
+type ChunkRef = chunkRef
+
+var ChunksDir = chunksDir
+
+// StoreChunks cuts data into chunks like the archives of incremental
+// snapshots are and adds them to the chunk store.
+func StoreChunks(data []byte) (chunks []ChunkRef, stored []byte, err error) {
+	var buf bytes.Buffer
+	cw := &chunkWriter{w: &buf}
+	if _, err := cw.Write(data); err != nil {
+		return nil, nil, err
+	}
+	if err := cw.Flush(); err != nil {
+		return nil, nil, err
+	}
+	return cw.chunks, buf.Bytes(), nil
+}
+
+func ChunkPath(sum string) string {
+	return chunkPath(sum)
+}
@@ -20,7 +20,9 @@
 package backend
 
 import (
+	"archive/tar"
 	"bytes"
+	"compress/gzip"
 	"context"
 	"crypto"
 	"errors"
@@ -144,7 +146,7 @@ func Open(fn string, setID uint64) (reader *Reader, e error) {
 }
 
 func (r *Reader) checkOne(ctx context.Context, entry string, hasher hash.Hash) error {
-	body, reportedSize, err := zipMember(r.File, entry)
+	body, reportedSize, err := r.openEntry(entry)
 	if err != nil {
 		return err
 	}
@@ -189,6 +191,82 @@ func (r *Reader) Check(ctx context.Context, usernames []string) error {
 	return nil
 }
 
+// readArchive reads the given entry, a gzipped tar archive, in full.
+func (r *Reader) readArchive(ctx context.Context, entry string) error {
+	body, _, err := r.openEntry(entry)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	gz, err := gzip.NewReader(body)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	for {
+		if _, err := tr.Next(); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if _, err := io.Copy(osutil.ContextWriter(ctx), tr); err != nil {
+			return err
+		}
+	}
+}
+
+// Verify checks the data contained in the snapshot like Check does, but
+// checks all the archives instead of stopping at the first one that does
+// not match its hashsum, and reads the archives that match in full to
+// find archives that were already broken when the snapshot was saved.
+// The archives that are corrupted are returned, an error is returned
+// only if the check itself failed.
+func (r *Reader) Verify(ctx context.Context, usernames []string) ([]client.SnapshotCorruption, error) {
+	sort.Strings(usernames)
+
+	entries := make([]string, 0, len(r.SHA3_384))
+	for entry := range r.SHA3_384 {
+		if len(usernames) > 0 && isUserArchive(entry) {
+			username := entryUsername(entry)
+			if !strutil.SortedListContains(usernames, username) {
+				logger.Debugf("In verifying snapshot %q, skipping entry %q by user request.", r.Name(), username)
+				continue
+			}
+		}
+		entries = append(entries, entry)
+	}
+	sort.Strings(entries)
+
+	var corruptions []client.SnapshotCorruption
+	hasher := crypto.SHA3_384.New()
+	for _, entry := range entries {
+		err := r.checkOne(ctx, entry, hasher)
+		hasher.Reset()
+		if err == nil {
+			err = r.readArchive(ctx, entry)
+			if err != nil {
+				err = fmt.Errorf("cannot read snapshot entry %q: %v", entry, err)
+			}
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		if err != nil {
+			corruptions = append(corruptions, client.SnapshotCorruption{
+				SetID:  r.SetID,
+				Snap:   r.Snap,
+				Entry:  entry,
+				Reason: err.Error(),
+			})
+		}
+	}
+
+	return corruptions, nil
+}
+
 // Logf is the type implemented by logging functions.
 type Logf func(format string, args ...any)
 
@@ -311,10 +389,11 @@ func (r *Reader) Restore(ctx context.Context, current snap.Revision, usernames [
 
 		logger.Debugf("Restoring %q from %q into %q.", entry, r.Name(), tempdir)
 
-		body, expectedSize, err := zipMember(r.File, entry)
+		body, expectedSize, err := r.openEntry(entry)
 		if err != nil {
 			return rs, err
 		}
+		defer body.Close()
 
 		expectedHash := r.SHA3_384[entry]
 
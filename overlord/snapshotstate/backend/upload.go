@@ -0,0 +1,129 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package backend
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/logger"
+)
+
+// ErrUploadChunkHashMismatch is returned when the data of an upload chunk
+// does not match its hash.
+var ErrUploadChunkHashMismatch = errors.New("chunk data does not match its sha256 hash")
+
+// UploadOffsetError is returned when an upload chunk does not start where
+// the data received so far ends.
+type UploadOffsetError struct {
+	Offset   int64
+	Received int64
+}
+
+func (e *UploadOffsetError) Error() string {
+	return fmt.Sprintf("cannot append chunk at offset %d: %d bytes received so far", e.Offset, e.Received)
+}
+
+// uploadsDir is where snapshot exports uploaded in chunks are kept until
+// they are complete. It is not a valid snapshot filename so Iter skips it.
+func uploadsDir() string {
+	return filepath.Join(dirs.SnapshotsDir, "uploads")
+}
+
+func uploadPath(id string) string {
+	return filepath.Join(uploadsDir(), id+".part")
+}
+
+// UploadReceived returns the number of bytes received so far for the
+// given upload.
+func UploadReceived(id string) (int64, error) {
+	st, err := os.Stat(uploadPath(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return st.Size(), nil
+}
+
+// AppendUploadChunk appends the chunk read from r to the data of the
+// given upload and returns the number of bytes received so far. The chunk
+// must start at offset, where the data received so far ends, and its
+// sha256 hash must match the given hex encoded one. A chunk that cannot
+// be read completely or that does not match its hash is dropped so that
+// it can be sent again.
+func AppendUploadChunk(id string, offset int64, r io.Reader, sha256Hash string) (received int64, err error) {
+	if err := os.MkdirAll(uploadsDir(), 0700); err != nil {
+		return 0, err
+	}
+	f, err := os.OpenFile(uploadPath(id), os.O_WRONLY|os.O_CREATE, 0600)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	st, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	received = st.Size()
+	if offset != received {
+		return received, &UploadOffsetError{Offset: offset, Received: received}
+	}
+	if _, err := f.Seek(received, io.SeekStart); err != nil {
+		return received, err
+	}
+
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(f, h), r)
+	if err == nil && fmt.Sprintf("%x", h.Sum(nil)) != sha256Hash {
+		err = ErrUploadChunkHashMismatch
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if err != nil {
+		if terr := f.Truncate(received); terr != nil {
+			logger.Noticef("cannot drop incomplete chunk of snapshot upload %s: %v", id, terr)
+		}
+		return received, err
+	}
+
+	return received + n, nil
+}
+
+// OpenUpload opens the data received for the given upload.
+func OpenUpload(id string) (*os.File, error) {
+	return os.Open(uploadPath(id))
+}
+
+// RemoveUpload removes the data received for the given upload.
+func RemoveUpload(id string) error {
+	if err := os.Remove(uploadPath(id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package backend_test
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/overlord/snapshotstate/backend"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type uploadSuite struct {
+	testutil.BaseTest
+}
+
+var _ = check.Suite(&uploadSuite{})
+
+func (s *uploadSuite) SetUpTest(c *check.C) {
+	s.BaseTest.SetUpTest(c)
+	dirs.SetRootDir(c.MkDir())
+	s.AddCleanup(func() { dirs.SetRootDir("") })
+}
+
+func chunkHash(data string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(data)))
+}
+
+func (s *uploadSuite) TestAppendUploadChunk(c *check.C) {
+	received, err := backend.UploadReceived("some-id")
+	c.Assert(err, check.IsNil)
+	c.Check(received, check.Equals, int64(0))
+
+	received, err = backend.AppendUploadChunk("some-id", 0, strings.NewReader("hello "), chunkHash("hello "))
+	c.Assert(err, check.IsNil)
+	c.Check(received, check.Equals, int64(6))
+
+	received, err = backend.AppendUploadChunk("some-id", 6, strings.NewReader("world"), chunkHash("world"))
+	c.Assert(err, check.IsNil)
+	c.Check(received, check.Equals, int64(11))
+
+	uploadPath := filepath.Join(dirs.SnapshotsDir, "uploads", "some-id.part")
+	c.Check(uploadPath, testutil.FileEquals, "hello world")
+	received, err = backend.UploadReceived("some-id")
+	c.Assert(err, check.IsNil)
+	c.Check(received, check.Equals, int64(11))
+
+	f, err := backend.OpenUpload("some-id")
+	c.Assert(err, check.IsNil)
+	data, err := io.ReadAll(f)
+	f.Close()
+	c.Assert(err, check.IsNil)
+	c.Check(string(data), check.Equals, "hello world")
+
+	c.Assert(backend.RemoveUpload("some-id"), check.IsNil)
+	c.Check(uploadPath, testutil.FileAbsent)
+	// removing again is fine
+	c.Assert(backend.RemoveUpload("some-id"), check.IsNil)
+}
+
+func (s *uploadSuite) TestAppendUploadChunkWrongOffset(c *check.C) {
+	_, err := backend.AppendUploadChunk("some-id", 0, strings.NewReader("hello "), chunkHash("hello "))
+	c.Assert(err, check.IsNil)
+
+	received, err := backend.AppendUploadChunk("some-id", 3, strings.NewReader("world"), chunkHash("world"))
+	c.Assert(err, check.ErrorMatches, "cannot append chunk at offset 3: 6 bytes received so far")
+	c.Check(err, check.FitsTypeOf, &backend.UploadOffsetError{})
+	c.Check(received, check.Equals, int64(6))
+}
+
+func (s *uploadSuite) TestAppendUploadChunkHashMismatch(c *check.C) {
+	_, err := backend.AppendUploadChunk("some-id", 0, strings.NewReader("hello "), chunkHash("hello "))
+	c.Assert(err, check.IsNil)
+
+	received, err := backend.AppendUploadChunk("some-id", 6, strings.NewReader("wrld"), chunkHash("world"))
+	c.Assert(err, check.Equals, backend.ErrUploadChunkHashMismatch)
+	c.Check(received, check.Equals, int64(6))
+
+	// the broken chunk was dropped and can be sent again
+	c.Check(filepath.Join(dirs.SnapshotsDir, "uploads", "some-id.part"), testutil.FileEquals, "hello ")
+	received, err = backend.AppendUploadChunk("some-id", 6, strings.NewReader("world"), chunkHash("world"))
+	c.Assert(err, check.IsNil)
+	c.Check(received, check.Equals, int64(11))
+}
+
+type failingReader struct{}
+
+func (failingReader) Read(p []byte) (int, error) {
+	copy(p, "wor")
+	return 3, fmt.Errorf("connection lost")
+}
+
+func (s *uploadSuite) TestAppendUploadChunkReadError(c *check.C) {
+	_, err := backend.AppendUploadChunk("some-id", 0, strings.NewReader("hello "), chunkHash("hello "))
+	c.Assert(err, check.IsNil)
+
+	received, err := backend.AppendUploadChunk("some-id", 6, failingReader{}, chunkHash("world"))
+	c.Assert(err, check.ErrorMatches, "connection lost")
+	c.Check(received, check.Equals, int64(6))
+	c.Check(filepath.Join(dirs.SnapshotsDir, "uploads", "some-id.part"), testutil.FileEquals, "hello ")
+}
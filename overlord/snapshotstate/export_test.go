@@ -105,6 +105,10 @@ func MockBackendCheck(f func(*backend.Reader, context.Context, []string) error)
 	return testutil.Mock(&backendCheck, f)
 }
 
+func MockBackendVerify(f func(*backend.Reader, context.Context, []string) ([]client.SnapshotCorruption, error)) (restore func()) {
+	return testutil.Mock(&backendVerify, f)
+}
+
 func MockBackendRevert(f func(*backend.RestoreState)) (restore func()) {
 	return testutil.Mock(&backendRevert, f)
 }
@@ -121,6 +125,14 @@ func MockBackendCleanupAbandonedImports(f func() (int, error)) (restore func())
 	return testutil.Mock(&backendCleanupAbandonedImports, f)
 }
 
+func MockBackendCleanupChunks(f func(context.Context) (int, error)) (restore func()) {
+	return testutil.Mock(&backendCleanupChunks, f)
+}
+
+func MockBackendSaveIncremental(f func(context.Context, uint64, *snap.Info, map[string]any, []string, *snap.SnapshotOptions, *dirs.SnapDirOptions) (*client.Snapshot, error)) (restore func()) {
+	return testutil.Mock(&backendSaveIncremental, f)
+}
+
 func MockBackendEstimateSnapshotSize(f func(*snap.Info, []string, *dirs.SnapDirOptions) (uint64, error)) (restore func()) {
 	return testutil.Mock(&backendEstimateSnapshotSize, f)
 }
@@ -142,6 +154,10 @@ func SetLastForgetExpiredSnapshotTime(mgr *SnapshotManager, t time.Time) {
 	mgr.lastForgetExpiredSnapshotTime = t
 }
 
+func SetLastChunksCleanupTime(mgr *SnapshotManager, t time.Time) {
+	mgr.lastChunksCleanupTime = t
+}
+
 func MockGetSnapDirOptions(f func(*state.State, string) (*dirs.SnapDirOptions, error)) (restore func()) {
 	return testutil.Mock(&getSnapDirOpts, f)
 }
@@ -149,3 +165,5 @@ func MockGetSnapDirOptions(f func(*state.State, string) (*dirs.SnapDirOptions, e
 func MockBackendMapSnapDataDirToSnapVar(f func(*snap.Info, *dirs.SnapDirOptions, []string) (map[string]string, error)) (restore func()) {
 	return testutil.Mock(&backendMapSnapDataDirToSnapVar, f)
 }
+
+var RemoveExpiredImportUploads = removeExpiredImportUploads
@@ -22,6 +22,7 @@ package snapshotstate
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -50,12 +51,16 @@ var (
 	backendImport        = backend.Import
 	backendRestore       = (*backend.Reader).Restore // TODO: look into using an interface instead
 	backendCheck         = (*backend.Reader).Check
+	backendVerify        = (*backend.Reader).Verify
 	backendRevert        = (*backend.RestoreState).Revert // ditto
 	backendCleanup       = (*backend.RestoreState).Cleanup
 
 	backendCleanupAbandonedImports = backend.CleanupAbandonedImports
+	backendCleanupChunks           = backend.CleanupChunks
+	backendSaveIncremental         = backend.SaveIncremental
 
 	autoExpirationInterval = time.Hour * 24 // interval between forgetExpiredSnapshots runs as part of Ensure()
+	chunksCleanupInterval  = time.Hour * 24 // interval between cleanupChunks runs as part of Ensure()
 
 	getSnapDirOpts = snapstate.GetSnapDirOpts
 
@@ -67,6 +72,7 @@ type SnapshotManager struct {
 	state *state.State
 
 	lastForgetExpiredSnapshotTime time.Time
+	lastChunksCleanupTime         time.Time
 }
 
 // Manager returns a new SnapshotManager
@@ -91,12 +97,34 @@ func Manager(st *state.State, runner *state.TaskRunner) *SnapshotManager {
 func (mgr *SnapshotManager) Ensure() error {
 	// process expired snapshots once a day.
 	if time.Now().After(mgr.lastForgetExpiredSnapshotTime.Add(autoExpirationInterval)) {
-		return mgr.forgetExpiredSnapshots()
+		if err := mgr.forgetExpiredSnapshots(); err != nil {
+			return err
+		}
+	}
+
+	// reclaim the space of forgotten incremental snapshots once a day.
+	if time.Now().After(mgr.lastChunksCleanupTime.Add(chunksCleanupInterval)) {
+		mgr.cleanupChunks()
 	}
 
 	return nil
 }
 
+// cleanupChunks removes the chunks that no incremental snapshot refers to
+// anymore. It does not need the state lock: chunks that are being written or
+// reused by snapshots that are not complete yet are left alone by the
+// backend.
+func (mgr *SnapshotManager) cleanupChunks() {
+	mgr.lastChunksCleanupTime = time.Now()
+	removed, err := backendCleanupChunks(context.TODO())
+	if err != nil {
+		logger.Noticef("cannot cleanup snapshot chunks: %v", err)
+	}
+	if removed > 0 {
+		logger.Noticef("Removed %d unused snapshot chunks.", removed)
+	}
+}
+
 func (mgr *SnapshotManager) StartUp() error {
 	if _, err := backendCleanupAbandonedImports(); err != nil {
 		logger.Noticef("cannot cleanup incomplete imports: %v", err)
@@ -108,6 +136,10 @@ func (mgr *SnapshotManager) forgetExpiredSnapshots() error {
 	mgr.state.Lock()
 	defer mgr.state.Unlock()
 
+	if err := removeExpiredImportUploads(mgr.state, time.Now()); err != nil {
+		logger.Noticef("cannot remove expired snapshot import uploads: %v", err)
+	}
+
 	sets, err := expiredSnapshotSets(mgr.state, time.Now())
 	if err != nil {
 		return fmt.Errorf("internal error: cannot determine expired snapshots: %v", err)
@@ -174,6 +206,9 @@ type snapshotSetup struct {
 	Filename string                `json:"filename,omitempty"`
 	Current  snap.Revision         `json:"current"`
 	Auto     bool                  `json:"auto,omitempty"`
+	Deep     bool                  `json:"deep,omitempty"`
+	// Incremental is set if the snapshot is saved to the chunk store
+	Incremental bool `json:"incremental,omitempty"`
 }
 
 func filename(setID uint64, si *snap.Info) string {
@@ -241,7 +276,11 @@ func doSave(task *state.Task, tomb *tomb.Tomb) error {
 	if err := snapshot.excludeMountPoints(cur, opts); err != nil {
 		logger.Noticef("cannot exclude mount points: %v", err)
 	}
-	_, err = backendSave(tomb.Context(nil), snapshot.SetID, cur, cfg, snapshot.Users, snapshot.Options, opts)
+	save := backendSave
+	if snapshot.Incremental {
+		save = backendSaveIncremental
+	}
+	_, err = save(tomb.Context(nil), snapshot.SetID, cur, cfg, snapshot.Users, snapshot.Options, opts)
 	if err != nil {
 		st.Lock()
 		defer st.Unlock()
@@ -500,7 +539,52 @@ func doCheck(task *state.Task, tomb *tomb.Tomb) error {
 	}
 	defer reader.Close()
 
-	return backendCheck(reader, tomb.Context(nil), snapshot.Users)
+	if !snapshot.Deep {
+		return backendCheck(reader, tomb.Context(nil), snapshot.Users)
+	}
+
+	corruptions, err := backendVerify(reader, tomb.Context(nil), snapshot.Users)
+	if err != nil {
+		return err
+	}
+	if len(corruptions) == 0 {
+		return nil
+	}
+
+	st.Lock()
+	defer st.Unlock()
+	if err := addCorruptionsToChange(task.Change(), corruptions); err != nil {
+		return err
+	}
+	return fmt.Errorf("snapshot of snap %q in set #%d has %d corrupted archives", snapshot.Snap, snapshot.SetID, len(corruptions))
+}
+
+const corruptionsAPIDataKey = "snapshot-corruptions"
+
+// addCorruptionsToChange appends the given corruptions to the ones
+// reported to clients in the data of the change.
+func addCorruptionsToChange(chg *state.Change, corruptions []client.SnapshotCorruption) error {
+	var apiData map[string]json.RawMessage
+	if err := chg.Get("api-data", &apiData); err != nil && !errors.Is(err, state.ErrNoState) {
+		return err
+	}
+	if apiData == nil {
+		apiData = make(map[string]json.RawMessage)
+	}
+
+	var reported []client.SnapshotCorruption
+	if raw, ok := apiData[corruptionsAPIDataKey]; ok {
+		if err := json.Unmarshal(raw, &reported); err != nil {
+			return fmt.Errorf("internal error: cannot decode reported snapshot corruptions: %v", err)
+		}
+	}
+	raw, err := json.Marshal(append(reported, corruptions...))
+	if err != nil {
+		return err
+	}
+	apiData[corruptionsAPIDataKey] = raw
+	chg.Set("api-data", apiData)
+	return nil
 }
 
 func doForget(task *state.Task, _ *tomb.Tomb) error {
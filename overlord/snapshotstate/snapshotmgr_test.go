@@ -239,6 +239,50 @@ func (snapshotSuite) testEnsureForgetSnapshotsConflict(c *check.C, snapshotOp st
 	c.Check(expirations, check.HasLen, 0)
 }
 
+func (snapshotSuite) TestEnsureCleansUpChunksRegularly(c *check.C) {
+	var cleanupCalls int
+	defer snapshotstate.MockBackendCleanupChunks(func(context.Context) (int, error) {
+		cleanupCalls++
+		return 3, nil
+	})()
+
+	st := state.New(nil)
+	runner := state.NewTaskRunner(st)
+	mgr := snapshotstate.Manager(st, runner)
+	c.Assert(mgr, check.NotNil)
+
+	// consecutive runs of Ensure clean up the chunks just once because of
+	// the chunksCleanupInterval
+	for i := 0; i < 3; i++ {
+		c.Assert(mgr.Ensure(), check.IsNil)
+		c.Check(cleanupCalls, check.Equals, 1)
+	}
+
+	// pretend we haven't run for a while
+	t, err := time.Parse(time.RFC3339, "2002-03-11T11:24:00Z")
+	c.Assert(err, check.IsNil)
+	snapshotstate.SetLastChunksCleanupTime(mgr, t)
+	c.Assert(mgr.Ensure(), check.IsNil)
+	c.Check(cleanupCalls, check.Equals, 2)
+}
+
+func (snapshotSuite) TestEnsureChunksCleanupErrorIsNotFatal(c *check.C) {
+	logbuf, restore := logger.MockLogger()
+	defer restore()
+
+	defer snapshotstate.MockBackendCleanupChunks(func(context.Context) (int, error) {
+		return 0, errors.New("boom")
+	})()
+
+	st := state.New(nil)
+	runner := state.NewTaskRunner(st)
+	mgr := snapshotstate.Manager(st, runner)
+	c.Assert(mgr, check.NotNil)
+
+	c.Assert(mgr.Ensure(), check.IsNil)
+	c.Check(logbuf.String(), testutil.Contains, "cannot cleanup snapshot chunks: boom")
+}
+
 func (s *snapshotSuite) TestEnsureForgetSnapshotsConflictWithCheckSnapshot(c *check.C) {
 	s.testEnsureForgetSnapshotsConflict(c, "check-snapshot")
 }
@@ -322,6 +366,50 @@ func (snapshotSuite) TestDoSave(c *check.C) {
 	}
 }
 
+func (snapshotSuite) TestDoSaveIncremental(c *check.C) {
+	snapInfo := snap.Info{
+		SideInfo: snap.SideInfo{
+			RealName: "a-snap",
+			Revision: snap.R(-1),
+		},
+		Version: "1.33",
+	}
+	defer snapshotstate.MockSnapstateCurrentInfo(func(*state.State, string) (*snap.Info, error) {
+		return &snapInfo, nil
+	})()
+	defer snapshotstate.MockConfigGetSnapConfig(func(*state.State, string) (*json.RawMessage, error) {
+		return nil, nil
+	})()
+	defer osutil.MockMountInfo("")()
+
+	defer snapshotstate.MockBackendSave(func(context.Context, uint64, *snap.Info, map[string]any, []string, *snap.SnapshotOptions, *dirs.SnapDirOptions) (*client.Snapshot, error) {
+		c.Fatal("full snapshot should not have been taken")
+		return nil, nil
+	})()
+	var incrementalCalls int
+	defer snapshotstate.MockBackendSaveIncremental(func(_ context.Context, id uint64, si *snap.Info, _ map[string]any, usernames []string, _ *snap.SnapshotOptions, _ *dirs.SnapDirOptions) (*client.Snapshot, error) {
+		incrementalCalls++
+		c.Check(id, check.Equals, uint64(42))
+		c.Check(si, check.DeepEquals, &snapInfo)
+		c.Check(usernames, check.DeepEquals, []string{"a-user"})
+		return nil, nil
+	})()
+
+	st := state.New(nil)
+	st.Lock()
+	task := st.NewTask("save-snapshot", "...")
+	task.Set("snapshot-setup", map[string]any{
+		"set-id":      42,
+		"snap":        "a-snap",
+		"users":       []string{"a-user"},
+		"incremental": true,
+	})
+	st.Unlock()
+
+	c.Assert(snapshotstate.DoSave(task, &tomb.Tomb{}), check.IsNil)
+	c.Check(incrementalCalls, check.Equals, 1)
+}
+
 func (snapshotSuite) TestMapMountPointsInDataDirsToExcludes(c *check.C) {
 	snapInfo := snap.Info{
 		SideInfo: snap.SideInfo{
@@ -1300,6 +1388,76 @@ func (rs *readerSuite) TestDoCheck(c *check.C) {
 	c.Check(rs.calls, check.DeepEquals, []string{"open", "check"})
 }
 
+func (rs *readerSuite) TestDoCheckDeep(c *check.C) {
+	st := rs.task.State()
+	st.Lock()
+	chg := st.NewChange("check-snapshot", "...")
+	chg.AddTask(rs.task)
+	rs.task.Set("snapshot-setup", map[string]any{
+		"set-id":   1,
+		"snap":     "a-snap",
+		"filename": "/some/1_file.zip",
+		"users":    []string{"a-user"},
+		"deep":     true,
+	})
+	// corruptions reported by other tasks are kept
+	chg.Set("api-data", map[string]any{
+		"snapshot-corruptions": []client.SnapshotCorruption{
+			{SetID: 1, Snap: "b-snap", Entry: "archive.tgz", Reason: "bzzt"},
+		},
+	})
+	st.Unlock()
+
+	defer snapshotstate.MockBackendVerify(func(r *backend.Reader, _ context.Context, users []string) ([]client.SnapshotCorruption, error) {
+		rs.calls = append(rs.calls, "verify")
+		c.Check(users, check.DeepEquals, []string{"a-user"})
+		return []client.SnapshotCorruption{
+			{SetID: 1, Snap: "a-snap", Entry: "user/a-user.tgz", Reason: "hash mismatch"},
+		}, nil
+	})()
+
+	err := snapshotstate.DoCheck(rs.task, &tomb.Tomb{})
+	c.Assert(err, check.ErrorMatches, `snapshot of snap "a-snap" in set #1 has 1 corrupted archives`)
+	c.Check(rs.calls, check.DeepEquals, []string{"open", "verify"})
+
+	st.Lock()
+	defer st.Unlock()
+	var apiData map[string][]client.SnapshotCorruption
+	c.Assert(chg.Get("api-data", &apiData), check.IsNil)
+	c.Check(apiData["snapshot-corruptions"], check.DeepEquals, []client.SnapshotCorruption{
+		{SetID: 1, Snap: "b-snap", Entry: "archive.tgz", Reason: "bzzt"},
+		{SetID: 1, Snap: "a-snap", Entry: "user/a-user.tgz", Reason: "hash mismatch"},
+	})
+}
+
+func (rs *readerSuite) TestDoCheckDeepNoCorruptions(c *check.C) {
+	st := rs.task.State()
+	st.Lock()
+	chg := st.NewChange("check-snapshot", "...")
+	chg.AddTask(rs.task)
+	rs.task.Set("snapshot-setup", map[string]any{
+		"set-id":   1,
+		"snap":     "a-snap",
+		"filename": "/some/1_file.zip",
+		"deep":     true,
+	})
+	st.Unlock()
+
+	defer snapshotstate.MockBackendVerify(func(*backend.Reader, context.Context, []string) ([]client.SnapshotCorruption, error) {
+		rs.calls = append(rs.calls, "verify")
+		return nil, nil
+	})()
+
+	err := snapshotstate.DoCheck(rs.task, &tomb.Tomb{})
+	c.Assert(err, check.IsNil)
+	c.Check(rs.calls, check.DeepEquals, []string{"open", "verify"})
+
+	st.Lock()
+	defer st.Unlock()
+	var apiData map[string]any
+	c.Check(chg.Get("api-data", &apiData), testutil.ErrorIs, state.ErrNoState)
+}
+
 func (rs *readerSuite) TestDoRemove(c *check.C) {
 	defer snapshotstate.MockOsRemove(func(filename string) error {
 		c.Check(filename, check.Equals, "/some/1_file.zip")
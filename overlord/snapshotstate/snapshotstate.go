@@ -151,6 +151,18 @@ func AutomaticSnapshotExpiration(st *state.State) (time.Duration, error) {
 	return defaultAutomaticSnapshotExpiration, nil
 }
 
+// incrementalSnapshots returns whether snapshots should be saved
+// incrementally, to the chunk store, as set with snapshots.incremental.
+func incrementalSnapshots(st *state.State) (bool, error) {
+	tr := config.NewTransaction(st)
+	var incremental bool
+	err := tr.GetMaybe("core", "snapshots.incremental", &incremental)
+	if err != nil && !errors.Is(err, state.ErrNoState) {
+		return false, err
+	}
+	return incremental, nil
+}
+
 // saveExpiration saves expiration date of the given snapshot set, in the state.
 // The state needs to be locked by the caller.
 func saveExpiration(st *state.State, setID uint64, expiryTime time.Time) error {
@@ -409,6 +421,11 @@ func Save(st *state.State, instanceNames []string, users []string, options map[s
 		return 0, nil, nil, err
 	}
 
+	incremental, err := incrementalSnapshots(st)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
 	ts = state.NewTaskSet()
 
 	for _, name := range instanceNames {
@@ -422,6 +439,8 @@ func Save(st *state.State, instanceNames []string, users []string, options map[s
 			Snap:    name,
 			Users:   users,
 			Options: options[name],
+
+			Incremental: incremental,
 		}
 
 		task.Set("snapshot-setup", &snapshot)
@@ -456,6 +475,10 @@ func AutomaticSnapshot(st *state.State, snapName string) (ts *state.TaskSet, err
 	if err != nil {
 		return nil, err
 	}
+	incremental, err := incrementalSnapshots(st)
+	if err != nil {
+		return nil, err
+	}
 
 	ts = state.NewTaskSet()
 
@@ -467,6 +490,8 @@ func AutomaticSnapshot(st *state.State, snapName string) (ts *state.TaskSet, err
 		SetID: setID,
 		Snap:  snapName,
 		Auto:  true,
+
+		Incremental: incremental,
 	}
 	task.Set("snapshot-setup", &snapshot)
 	ts.AddTask(task)
@@ -554,6 +579,19 @@ func Restore(st *state.State, setID uint64, snapNames []string, users []string)
 // Check creates a taskset for checking a snapshot's data.
 // Note that the state must be locked by the caller.
 func Check(st *state.State, setID uint64, snapNames []string, users []string) (snapsFound []string, ts *state.TaskSet, err error) {
+	return check(st, setID, snapNames, users, false)
+}
+
+// DeepCheck creates a taskset for checking a snapshot's data like Check,
+// but all the archives of each snap are checked and read in full. The
+// archives found to be corrupted are listed in the "snapshot-corruptions"
+// data of the change.
+// Note that the state must be locked by the caller.
+func DeepCheck(st *state.State, setID uint64, snapNames []string, users []string) (snapsFound []string, ts *state.TaskSet, err error) {
+	return check(st, setID, snapNames, users, true)
+}
+
+func check(st *state.State, setID uint64, snapNames []string, users []string, deep bool) (snapsFound []string, ts *state.TaskSet, err error) {
 	// check needs to conflict with forget of itself
 	if err := checkSnapshotConflict(st, setID, "forget-snapshot"); err != nil {
 		return nil, nil, err
@@ -574,8 +612,14 @@ func Check(st *state.State, setID uint64, snapNames []string, users []string) (s
 			Snap:     summary.snap,
 			Users:    users,
 			Filename: summary.filename,
+			Deep:     deep,
 		}
 		task.Set("snapshot-setup", &snapshot)
+		if deep {
+			// report the corruptions of all the snaps instead of
+			// stopping at the first snap that fails the check
+			task.JoinLane(st.NewLane())
+		}
 		ts.AddTask(task)
 	}
 
@@ -604,6 +604,38 @@ func (s snapshotSuite) TestSaveOneSnap(c *check.C) {
 	})
 }
 
+func (snapshotSuite) TestSaveOneSnapIncremental(c *check.C) {
+	st := state.New(nil)
+	st.Lock()
+	defer st.Unlock()
+
+	snapstate.Set(st, "a-snap", &snapstate.SnapState{
+		Active: true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+			{RealName: "a-snap", Revision: snap.R(1)},
+		}),
+		Current: snap.R(1),
+	})
+
+	tr := config.NewTransaction(st)
+	tr.Set("core", "snapshots.incremental", true)
+	tr.Commit()
+
+	_, saved, taskset, err := snapshotstate.Save(st, []string{"a-snap"}, nil, nil)
+	c.Assert(err, check.IsNil)
+	c.Check(saved, check.DeepEquals, []string{"a-snap"})
+	tasks := taskset.Tasks()
+	c.Assert(tasks, check.HasLen, 1)
+	var snapshot map[string]any
+	c.Check(tasks[0].Get("snapshot-setup", &snapshot), check.IsNil)
+	c.Check(snapshot, check.DeepEquals, map[string]any{
+		"set-id":      1.,
+		"snap":        "a-snap",
+		"current":     "unset",
+		"incremental": true,
+	})
+}
+
 func (snapshotSuite) TestSaveIntegration(c *check.C) {
 	if os.Geteuid() == 0 {
 		c.Skip("this test cannot run as root (runuser will fail)")
@@ -1489,6 +1521,45 @@ func (snapshotSuite) TestCheck(c *check.C) {
 	})
 }
 
+func (snapshotSuite) TestDeepCheck(c *check.C) {
+	dir := c.MkDir()
+	fakeIter := func(_ context.Context, f func(*backend.Reader) error) error {
+		for _, name := range []string{"a-snap", "b-snap"} {
+			shotfile, err := os.Create(filepath.Join(dir, name+".zip"))
+			c.Assert(err, check.IsNil)
+			defer shotfile.Close()
+			c.Assert(f(&backend.Reader{
+				Snapshot: client.Snapshot{SetID: 42, Snap: name},
+				File:     shotfile,
+			}), check.IsNil)
+		}
+		return nil
+	}
+	defer snapshotstate.MockBackendIter(fakeIter)()
+
+	st := state.New(nil)
+	st.Lock()
+	defer st.Unlock()
+
+	found, taskset, err := snapshotstate.DeepCheck(st, 42, nil, nil)
+	c.Assert(err, check.IsNil)
+	c.Check(found, check.DeepEquals, []string{"a-snap", "b-snap"})
+	tasks := taskset.Tasks()
+	c.Assert(tasks, check.HasLen, 2)
+	for i, name := range found {
+		c.Check(tasks[i].Kind(), check.Equals, "check-snapshot")
+		var snapshot map[string]any
+		c.Check(tasks[i].Get("snapshot-setup", &snapshot), check.IsNil)
+		c.Check(snapshot["snap"], check.Equals, name)
+		c.Check(snapshot["deep"], check.Equals, true)
+	}
+	// the tasks are in separate lanes so that all of them run even if
+	// one of them finds corrupted archives
+	c.Assert(tasks[0].Lanes(), check.HasLen, 1)
+	c.Assert(tasks[1].Lanes(), check.HasLen, 1)
+	c.Check(tasks[0].Lanes()[0], check.Not(check.Equals), tasks[1].Lanes()[0])
+}
+
 func (snapshotSuite) TestForgetChecksIterError(c *check.C) {
 	defer snapshotstate.MockBackendIter(func(context.Context, func(*backend.Reader) error) error {
 		return errors.New("bzzt")
@@ -0,0 +1,242 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snapshotstate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/snapshotstate/backend"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/randutil"
+)
+
+var (
+	backendUploadReceived    = backend.UploadReceived
+	backendAppendUploadChunk = backend.AppendUploadChunk
+	backendOpenUpload        = backend.OpenUpload
+	backendRemoveUpload      = backend.RemoveUpload
+
+	// uploads that do not receive any data for this long are removed
+	importUploadExpiration = time.Hour * 24
+)
+
+// ErrNoImportUpload is returned when there is no snapshot import upload
+// with the given ID.
+var ErrNoImportUpload = errors.New("no such snapshot import upload")
+
+// UploadOffsetError is returned when a chunk does not start where the
+// data received so far for its upload ends.
+type UploadOffsetError = backend.UploadOffsetError
+
+type importUploadState struct {
+	Size       int64     `json:"size"`
+	LastUpdate time.Time `json:"last-update"`
+}
+
+// ImportUploadStatus is the status of a snapshot export that is being
+// uploaded in chunks to be imported.
+type ImportUploadStatus struct {
+	ID       string `json:"upload-id"`
+	Size     int64  `json:"size"`
+	Received int64  `json:"received"`
+}
+
+// Complete returns whether all the data of the upload was received.
+func (s *ImportUploadStatus) Complete() bool {
+	return s.Received == s.Size
+}
+
+func importUploads(st *state.State) (map[string]*importUploadState, error) {
+	var uploads map[string]*importUploadState
+	if err := st.Get("snapshot-import-uploads", &uploads); err != nil && !errors.Is(err, state.ErrNoState) {
+		return nil, err
+	}
+	if uploads == nil {
+		uploads = make(map[string]*importUploadState)
+	}
+	return uploads, nil
+}
+
+// setImportUploadBusy marks the given upload as having a chunk being
+// appended or being imported, it fails if it is already marked. The state
+// must be locked by the caller.
+func setImportUploadBusy(st *state.State, id string) error {
+	busy, _ := st.Cached("snapshot-import-uploads-busy").(map[string]bool)
+	if busy[id] {
+		return fmt.Errorf("cannot use snapshot import upload %s: already in use", id)
+	}
+	if busy == nil {
+		busy = make(map[string]bool)
+	}
+	busy[id] = true
+	st.Cache("snapshot-import-uploads-busy", busy)
+	return nil
+}
+
+func unsetImportUploadBusy(st *state.State, id string) {
+	busy, _ := st.Cached("snapshot-import-uploads-busy").(map[string]bool)
+	delete(busy, id)
+}
+
+// StartImportUpload starts uploading a snapshot export of the given size
+// in chunks and returns the ID of the upload.
+// The state must be locked by the caller.
+func StartImportUpload(st *state.State, size int64) (id string, err error) {
+	if size <= 0 {
+		return "", fmt.Errorf("cannot start snapshot import upload: invalid size %d", size)
+	}
+	uploads, err := importUploads(st)
+	if err != nil {
+		return "", err
+	}
+	id, err = randutil.CryptoToken(16)
+	if err != nil {
+		return "", err
+	}
+	uploads[id] = &importUploadState{Size: size, LastUpdate: time.Now()}
+	st.Set("snapshot-import-uploads", uploads)
+	return id, nil
+}
+
+// ImportUploadStatusFor returns the status of the given upload.
+// The state must be locked by the caller.
+func ImportUploadStatusFor(st *state.State, id string) (*ImportUploadStatus, error) {
+	uploads, err := importUploads(st)
+	if err != nil {
+		return nil, err
+	}
+	upload := uploads[id]
+	if upload == nil {
+		return nil, ErrNoImportUpload
+	}
+	received, err := backendUploadReceived(id)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get status of snapshot import upload %s: %v", id, err)
+	}
+	return &ImportUploadStatus{ID: id, Size: upload.Size, Received: received}, nil
+}
+
+// AppendImportUploadChunk appends the chunk read from r, which must start
+// at offset and match the given hex encoded sha256 hash, to the given
+// upload.
+func AppendImportUploadChunk(st *state.State, id string, offset int64, r io.Reader, sha256Hash string) (*ImportUploadStatus, error) {
+	st.Lock()
+	defer st.Unlock()
+
+	uploads, err := importUploads(st)
+	if err != nil {
+		return nil, err
+	}
+	upload := uploads[id]
+	if upload == nil {
+		return nil, ErrNoImportUpload
+	}
+	if err := setImportUploadBusy(st, id); err != nil {
+		return nil, err
+	}
+	defer unsetImportUploadBusy(st, id)
+
+	st.Unlock()
+	// never receive more than the size of the upload
+	received, err := backendAppendUploadChunk(id, offset, io.LimitReader(r, upload.Size-offset), sha256Hash)
+	st.Lock()
+	if err != nil {
+		return nil, err
+	}
+
+	uploads, err = importUploads(st)
+	if err != nil {
+		return nil, err
+	}
+	if upload := uploads[id]; upload != nil {
+		upload.LastUpdate = time.Now()
+		st.Set("snapshot-import-uploads", uploads)
+	}
+
+	return &ImportUploadStatus{ID: id, Size: upload.Size, Received: received}, nil
+}
+
+// ImportUploaded imports the snapshot export of the given complete upload
+// as a new snapshot set. The upload is removed once imported, or if the
+// import fails.
+func ImportUploaded(ctx context.Context, st *state.State, id string) (setID uint64, snapNames []string, err error) {
+	st.Lock()
+	status, err := ImportUploadStatusFor(st, id)
+	if err == nil && !status.Complete() {
+		err = fmt.Errorf("cannot import snapshot upload %s: received %d of %d bytes", id, status.Received, status.Size)
+	}
+	if err == nil {
+		err = setImportUploadBusy(st, id)
+	}
+	st.Unlock()
+	if err != nil {
+		return 0, nil, err
+	}
+	defer func() {
+		st.Lock()
+		defer st.Unlock()
+		unsetImportUploadBusy(st, id)
+		if rerr := removeImportUpload(st, id); rerr != nil {
+			logger.Noticef("cannot remove snapshot import upload %s: %v", id, rerr)
+		}
+	}()
+
+	f, err := backendOpenUpload(id)
+	if err != nil {
+		return 0, nil, fmt.Errorf("cannot import snapshot upload %s: %v", id, err)
+	}
+	defer f.Close()
+
+	return Import(ctx, st, f)
+}
+
+func removeImportUpload(st *state.State, id string) error {
+	uploads, err := importUploads(st)
+	if err != nil {
+		return err
+	}
+	delete(uploads, id)
+	st.Set("snapshot-import-uploads", uploads)
+	return backendRemoveUpload(id)
+}
+
+// removeExpiredImportUploads removes the uploads that did not receive data
+// for a while. The state must be locked by the caller.
+func removeExpiredImportUploads(st *state.State, now time.Time) error {
+	uploads, err := importUploads(st)
+	if err != nil {
+		return err
+	}
+	busy, _ := st.Cached("snapshot-import-uploads-busy").(map[string]bool)
+	for id, upload := range uploads {
+		if busy[id] || now.Before(upload.LastUpdate.Add(importUploadExpiration)) {
+			continue
+		}
+		if err := removeImportUpload(st, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snapshotstate_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/overlord/snapshotstate"
+	"github.com/snapcore/snapd/overlord/snapshotstate/backend"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/testutil"
+)
+
+func uploadChunk(st *state.State, id string, offset int64, data string) (*snapshotstate.ImportUploadStatus, error) {
+	return snapshotstate.AppendImportUploadChunk(st, id, offset, strings.NewReader(data), fmt.Sprintf("%x", sha256.Sum256([]byte(data))))
+}
+
+func (snapshotSuite) TestImportUploadHappy(c *check.C) {
+	st := state.New(nil)
+
+	restore := snapshotstate.MockBackendImport(func(ctx context.Context, id uint64, r io.Reader, flags *backend.ImportFlags) ([]string, error) {
+		d, err := io.ReadAll(r)
+		c.Assert(err, check.IsNil)
+		c.Check(string(d), check.Equals, "hello world")
+		return []string{"foo"}, nil
+	})
+	defer restore()
+
+	st.Lock()
+	id, err := snapshotstate.StartImportUpload(st, 11)
+	c.Assert(err, check.IsNil)
+	status, err := snapshotstate.ImportUploadStatusFor(st, id)
+	st.Unlock()
+	c.Assert(err, check.IsNil)
+	c.Check(status, check.DeepEquals, &snapshotstate.ImportUploadStatus{ID: id, Size: 11})
+
+	status, err = uploadChunk(st, id, 0, "hello ")
+	c.Assert(err, check.IsNil)
+	c.Check(status, check.DeepEquals, &snapshotstate.ImportUploadStatus{ID: id, Size: 11, Received: 6})
+	c.Check(status.Complete(), check.Equals, false)
+
+	// importing an incomplete upload fails
+	_, _, err = snapshotstate.ImportUploaded(context.TODO(), st, id)
+	c.Assert(err, check.ErrorMatches, fmt.Sprintf("cannot import snapshot upload %s: received 6 of 11 bytes", id))
+
+	// chunks must follow the data received so far
+	_, err = uploadChunk(st, id, 3, "world")
+	c.Assert(err, check.FitsTypeOf, &snapshotstate.UploadOffsetError{})
+
+	// data past the size of the upload is ignored
+	status, err = uploadChunk(st, id, 6, "world and more")
+	c.Assert(err, check.ErrorMatches, "chunk data does not match its sha256 hash")
+	c.Check(status, check.IsNil)
+
+	status, err = uploadChunk(st, id, 6, "world")
+	c.Assert(err, check.IsNil)
+	c.Check(status.Complete(), check.Equals, true)
+
+	setID, snapNames, err := snapshotstate.ImportUploaded(context.TODO(), st, id)
+	c.Assert(err, check.IsNil)
+	c.Check(setID, check.Equals, uint64(1))
+	c.Check(snapNames, check.DeepEquals, []string{"foo"})
+
+	// the upload is gone
+	c.Check(filepath.Join(dirs.SnapshotsDir, "uploads", id+".part"), testutil.FileAbsent)
+	st.Lock()
+	defer st.Unlock()
+	_, err = snapshotstate.ImportUploadStatusFor(st, id)
+	c.Check(err, check.Equals, snapshotstate.ErrNoImportUpload)
+}
+
+func (snapshotSuite) TestImportUploadErrors(c *check.C) {
+	st := state.New(nil)
+	st.Lock()
+	_, err := snapshotstate.StartImportUpload(st, 0)
+	c.Check(err, check.ErrorMatches, "cannot start snapshot import upload: invalid size 0")
+	st.Unlock()
+
+	_, err = uploadChunk(st, "unknown", 0, "hello")
+	c.Check(err, check.Equals, snapshotstate.ErrNoImportUpload)
+	_, _, err = snapshotstate.ImportUploaded(context.TODO(), st, "unknown")
+	c.Check(err, check.Equals, snapshotstate.ErrNoImportUpload)
+}
+
+func (snapshotSuite) TestImportUploadFailedImportRemovesUpload(c *check.C) {
+	st := state.New(nil)
+
+	restore := snapshotstate.MockBackendImport(func(ctx context.Context, id uint64, r io.Reader, flags *backend.ImportFlags) ([]string, error) {
+		return nil, fmt.Errorf("boom")
+	})
+	defer restore()
+
+	st.Lock()
+	id, err := snapshotstate.StartImportUpload(st, 5)
+	st.Unlock()
+	c.Assert(err, check.IsNil)
+	_, err = uploadChunk(st, id, 0, "hello")
+	c.Assert(err, check.IsNil)
+
+	_, _, err = snapshotstate.ImportUploaded(context.TODO(), st, id)
+	c.Assert(err, check.ErrorMatches, "boom")
+
+	c.Check(filepath.Join(dirs.SnapshotsDir, "uploads", id+".part"), testutil.FileAbsent)
+	st.Lock()
+	defer st.Unlock()
+	_, err = snapshotstate.ImportUploadStatusFor(st, id)
+	c.Check(err, check.Equals, snapshotstate.ErrNoImportUpload)
+}
+
+func (snapshotSuite) TestRemoveExpiredImportUploads(c *check.C) {
+	st := state.New(nil)
+
+	st.Lock()
+	id, err := snapshotstate.StartImportUpload(st, 11)
+	st.Unlock()
+	c.Assert(err, check.IsNil)
+	_, err = uploadChunk(st, id, 0, "hello ")
+	c.Assert(err, check.IsNil)
+	uploadPath := filepath.Join(dirs.SnapshotsDir, "uploads", id+".part")
+
+	st.Lock()
+	defer st.Unlock()
+
+	c.Assert(snapshotstate.RemoveExpiredImportUploads(st, time.Now().Add(time.Hour)), check.IsNil)
+	c.Check(uploadPath, testutil.FilePresent)
+	_, err = snapshotstate.ImportUploadStatusFor(st, id)
+	c.Check(err, check.IsNil)
+
+	c.Assert(snapshotstate.RemoveExpiredImportUploads(st, time.Now().Add(25*time.Hour)), check.IsNil)
+	c.Check(uploadPath, testutil.FileAbsent)
+	_, err = snapshotstate.ImportUploadStatusFor(st, id)
+	c.Check(err, check.Equals, snapshotstate.ErrNoImportUpload)
+}
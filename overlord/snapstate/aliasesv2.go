@@ -554,6 +554,12 @@ func Alias(st *state.State, instanceName, app, alias string) (*state.TaskSet, er
 		return nil, err
 	}
 
+	return state.NewTaskSet(manualAliasTask(st, instanceName, app, alias)), nil
+}
+
+// manualAliasTask returns a task setting up a manual alias from alias to app
+// in instanceName.
+func manualAliasTask(st *state.State, instanceName, app, alias string) *state.Task {
 	snapName, instanceKey := snap.SplitInstanceName(instanceName)
 	snapsup := &SnapSetup{
 		SideInfo:    &snap.SideInfo{RealName: snapName},
@@ -564,8 +570,7 @@ func Alias(st *state.State, instanceName, app, alias string) (*state.TaskSet, er
 	manualAlias.Set("alias", alias)
 	manualAlias.Set("target", app)
 	manualAlias.Set("snap-setup", &snapsup)
-
-	return state.NewTaskSet(manualAlias), nil
+	return manualAlias
 }
 
 // manualAliases returns newAliases with a manual alias to target setup over
@@ -139,8 +139,12 @@ type autoRefresh struct {
 	state *state.State
 
 	lastRefreshSchedule string
+	lastRefreshStagger  string
 	nextRefresh         time.Time
-	lastRefreshAttempt  time.Time
+	// offset of nextRefresh from the start of its refresh window, if
+	// auto-refreshes are staggered deterministically
+	nextRefreshStagger time.Duration
+	lastRefreshAttempt time.Time
 
 	restoredMonitoring bool
 }
@@ -338,15 +342,23 @@ func (m *autoRefresh) Ensure() (err error) {
 		m.nextRefresh = time.Time{}
 		return nil
 	}
+	refreshStaggerStr, _, err := refreshStagger(m.state)
+	if err != nil {
+		return err
+	}
 	// we already have a refresh time, check if we got a new config
 	if !m.nextRefresh.IsZero() {
 		if m.lastRefreshSchedule != refreshScheduleStr {
 			// the refresh schedule has changed
 			logger.Debugf("Refresh timer changed.")
 			m.nextRefresh = time.Time{}
+		} else if m.lastRefreshStagger != refreshStaggerStr {
+			logger.Debugf("Refresh stagger changed.")
+			m.nextRefresh = time.Time{}
 		}
 	}
 	m.lastRefreshSchedule = refreshScheduleStr
+	m.lastRefreshStagger = refreshStaggerStr
 
 	// ensure nothing is in flight already
 	if autoRefreshInFlight(m.state) {
@@ -360,7 +372,10 @@ func (m *autoRefresh) Ensure() (err error) {
 	if m.nextRefresh.IsZero() {
 		// store attempts in memory so that we can backoff
 		if !lastRefresh.IsZero() {
-			delta := timeutil.Next(refreshSchedule, lastRefresh, maxPostponement)
+			delta, err := m.nextRefreshDelta(refreshSchedule, lastRefresh)
+			if err != nil {
+				return err
+			}
 			now = time.Now()
 			m.nextRefresh = now.Add(delta)
 		} else {
@@ -385,7 +400,10 @@ func (m *autoRefresh) Ensure() (err error) {
 			m.clearRefreshHold()
 			if m.nextRefresh.Before(holdTime) {
 				// next refresh is obsolete, compute the next one
-				delta := timeutil.Next(refreshSchedule, holdTime, maxPostponement)
+				delta, err := m.nextRefreshDelta(refreshSchedule, holdTime)
+				if err != nil {
+					return err
+				}
 				now = time.Now()
 				m.nextRefresh = now.Add(delta)
 			}
@@ -992,7 +1010,11 @@ func MockRefreshCandidate(snapSetup *SnapSetup) any {
 	}
 }
 
-func incrementSnapRefreshFailures(st *state.State, snapsup *SnapSetup, severity snap.RefreshFailureSeverity) error {
+// refreshFailureWarningThreshold is the number of consecutive failed
+// auto-refreshes of a snap after which a warning is added.
+const refreshFailureWarningThreshold = 2
+
+func incrementSnapRefreshFailures(st *state.State, snapsup *SnapSetup, severity snap.RefreshFailureSeverity, lastErr string) error {
 	var snapst SnapState
 	err := Get(st, snapsup.InstanceName(), &snapst)
 	if err != nil {
@@ -1011,13 +1033,49 @@ func incrementSnapRefreshFailures(st *state.State, snapsup *SnapSetup, severity
 		}
 	}
 	snapst.RefreshFailures.LastFailureSeverity = severity
+	snapst.RefreshFailures.LastError = lastErr
 	Set(st, snapsup.InstanceName(), &snapst)
 
 	delay := computeSnapRefreshRemainingDelay(snapst.RefreshFailures).Round(time.Hour)
 	logger.Noticef("snap %q auto-refresh to revision %s has failed, next auto-refresh attempt will be delayed by %v hours", snapsup.InstanceName(), snapsup.Revision(), delay.Hours())
+
+	if snapst.RefreshFailures.FailureCount >= refreshFailureWarningThreshold {
+		msg := fmt.Sprintf("snap %q failed to auto-refresh to revision %s %d times in a row", snapsup.InstanceName(), snapsup.Revision(), snapst.RefreshFailures.FailureCount)
+		if lastErr != "" {
+			msg += fmt.Sprintf(" (last error: %s)", lastErr)
+		}
+		msg += fmt.Sprintf("; automatic refreshes of the snap are paused for %v hours, run \"snap refresh %s\" to retry now", delay.Hours(), snapsup.InstanceName())
+		st.AddWarning(msg, &state.AddWarningOptions{RepeatAfter: 24 * time.Hour})
+	}
 	return nil
 }
 
+// resetSnapRefreshFailures clears the refresh failure information of the
+// given snap, e.g. after the user explicitly asked for it to be refreshed.
+func resetSnapRefreshFailures(st *state.State, snapst *SnapState) {
+	if snapst.RefreshFailures == nil {
+		return
+	}
+	snapst.RefreshFailures = nil
+	Set(st, snapst.InstanceName(), snapst)
+}
+
+// taskLastError returns the last error logged by the given task, if any.
+func taskLastError(t *state.Task) string {
+	log := t.Log()
+	for i := len(log) - 1; i >= 0; i-- {
+		// log entries are of the form "<timestamp> <kind> <message>"
+		_, rest, ok := strings.Cut(log[i], " ")
+		if !ok {
+			continue
+		}
+		if prefix := state.LogError + " "; strings.HasPrefix(rest, prefix) {
+			return rest[len(prefix):]
+		}
+	}
+	return ""
+}
+
 func computeSnapRefreshFailureSeverity(chg *state.Change, unlinkTask *state.Task, snapName string) snap.RefreshFailureSeverity {
 	// It is ok to pass nil for the DeviceContext as the situation here is auto-refresh and not remodel.
 	bootBase, err := deviceModelBootBase(chg.State(), nil)
@@ -1063,10 +1121,11 @@ func processFailedAutoRefresh(chg *state.Change, _ state.Status, new state.Statu
 
 	var failedSnapNames []string
 	for _, t := range chg.Tasks() {
-		// We only care about snaps that failed after unlink-current-snap because
-		// this indicates (with high probability) that something related to the snap
-		// itself is broken.
-		if t.Kind() != "unlink-current-snap" || t.Status() != state.UndoneStatus {
+		// We only care about snaps that failed after unlink-current-snap, or
+		// whose own hooks failed before it was reached, because this indicates
+		// (with high probability) that something related to the snap itself
+		// is broken.
+		if t.Kind() != "unlink-current-snap" {
 			continue
 		}
 
@@ -1076,8 +1135,23 @@ func processFailedAutoRefresh(chg *state.Change, _ state.Status, new state.Statu
 			continue
 		}
 
+		failedTask := snapRefreshFailedTask(chg, t, snapsup.InstanceName())
+		switch t.Status() {
+		case state.UndoneStatus:
+		case state.HoldStatus:
+			if failedTask == nil || failedTask.Kind() != "run-hook" {
+				continue
+			}
+		default:
+			continue
+		}
+
+		var lastErr string
+		if failedTask != nil {
+			lastErr = taskLastError(failedTask)
+		}
 		failureSeverity := computeSnapRefreshFailureSeverity(chg, t, snapsup.InstanceName())
-		if err := incrementSnapRefreshFailures(t.State(), snapsup, failureSeverity); err != nil {
+		if err := incrementSnapRefreshFailures(t.State(), snapsup, failureSeverity, lastErr); err != nil {
 			logger.Debugf("internal error: failed to increment failure count for snap %q: %v", snapsup.InstanceName(), err)
 			continue
 		}
@@ -1105,6 +1179,37 @@ func processFailedAutoRefresh(chg *state.Change, _ state.Status, new state.Statu
 	chg.Set("api-data", data)
 }
 
+// snapRefreshFailedTask returns the task in the lanes of the given
+// unlink-current-snap task which failed while refreshing the named snap, or
+// nil if there is no such task.
+func snapRefreshFailedTask(chg *state.Change, unlinkTask *state.Task, snapName string) *state.Task {
+	for _, t := range chg.LaneTasks(unlinkTask.Lanes()...) {
+		if t.Status() != state.ErrorStatus {
+			continue
+		}
+		var instanceName string
+		if t.Kind() == "run-hook" {
+			var hooksup struct {
+				Snap string `json:"snap"`
+			}
+			if err := t.Get("hook-setup", &hooksup); err != nil {
+				continue
+			}
+			instanceName = hooksup.Snap
+		} else {
+			snapsup, err := TaskSnapSetup(t)
+			if err != nil {
+				continue
+			}
+			instanceName = snapsup.InstanceName()
+		}
+		if instanceName == snapName {
+			return t
+		}
+	}
+	return nil
+}
+
 // snapRefreshDelay maps from failure count to time to snap refresh delay capped at 2 weeks.
 //
 // Note: Those are heuristic values listed in the SD183 spec.
@@ -1177,19 +1282,64 @@ var errKnownBadRevision = errors.New("revision is known to fail during refresh a
 // checkSnapRefreshFailures checks if a snap refresh to a target revision should be skipped or not.
 //
 // In case refresh to target revision should be skipped errKnownBadRevision error is returned.
-// Also, If snap has a new target revision not known to fail, the state is modified to reset
-// the snap's RefreshFailures.
+// Also, If snap has a new target revision not known to fail, or if the refresh was
+// explicitly requested by the user, the state is modified to reset the snap's
+// RefreshFailures.
 func checkSnapRefreshFailures(st *state.State, snapst *SnapState, targetRevision snap.Revision, opts Options) error {
 	if snapst.RefreshFailures != nil {
 		// Check if snap revision is known to fail and if the current refresh needs to be skipped.
-		if snapst.RefreshFailures.Revision != targetRevision {
-			// Snap has new target revision not known to fail, let's reset RefreshFailures
+		if !opts.Flags.IsAutoRefresh || snapst.RefreshFailures.Revision != targetRevision {
+			// The refresh was requested by the user or the snap has a new
+			// target revision not known to fail, let's reset RefreshFailures
 			// and continue refresh normally.
-			snapst.RefreshFailures = nil
-			Set(st, snapst.InstanceName(), snapst)
+			resetSnapRefreshFailures(st, snapst)
 		} else if shouldSkipSnapRefresh(snapst, targetRevision, opts) {
 			return errKnownBadRevision
 		}
 	}
 	return nil
 }
+
+// RefreshBackoff describes a snap whose auto-refreshes are currently
+// paused because of previous failed attempts.
+type RefreshBackoff struct {
+	InstanceName string
+	// Revision is the target revision that failed to refresh.
+	Revision snap.Revision
+	// FailureCount is the number of consecutive failed attempts.
+	FailureCount int
+	// LastError is the error of the last failed attempt, if known.
+	LastError string
+	// Until is the time after which auto-refreshes of the snap resume.
+	Until time.Time
+}
+
+func refreshBackoffs(st *state.State) ([]RefreshBackoff, error) {
+	snapStates, err := All(st)
+	if err != nil {
+		return nil, err
+	}
+
+	var backoffs []RefreshBackoff
+	for name, snapst := range snapStates {
+		failures := snapst.RefreshFailures
+		if failures == nil || failures.Revision == snapst.Current {
+			continue
+		}
+		delay := computeSnapRefreshRemainingDelay(failures)
+		if delay == 0 {
+			continue
+		}
+		backoffs = append(backoffs, RefreshBackoff{
+			InstanceName: name,
+			Revision:     failures.Revision,
+			FailureCount: failures.FailureCount,
+			LastError:    failures.LastError,
+			Until:        timeNow().Add(delay),
+		})
+	}
+	sort.Slice(backoffs, func(i, j int) bool {
+		return backoffs[i].InstanceName < backoffs[j].InstanceName
+	})
+	return backoffs, nil
+}
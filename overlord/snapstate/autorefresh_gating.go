@@ -36,6 +36,7 @@ import (
 	"github.com/snapcore/snapd/release"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/strutil"
+	"github.com/snapcore/snapd/timeutil"
 )
 
 var gateAutoRefreshHookName = "gate-auto-refresh"
@@ -98,6 +99,26 @@ type holdState struct {
 	HoldUntil time.Time `json:"hold-until"`
 	// Level of this hold.
 	Level HoldLevel `json:"level,omitempty"`
+	// Windows optionally restricts the hold to recurring calendar
+	// windows, expressed like refresh.timer. Outside of them the snap
+	// can be refreshed.
+	Windows string `json:"windows,omitempty"`
+}
+
+// inWindows returns whether the given time falls into the windows of the
+// hold, or true if the hold is not restricted to windows.
+func (h *holdState) inWindows(t time.Time) bool {
+	if h.Windows == "" {
+		return true
+	}
+	schedule, err := timeutil.ParseSchedule(h.Windows)
+	if err != nil {
+		// windows are validated when the hold is set, keep holding
+		// rather than refreshing unexpectedly
+		logger.Noticef("cannot parse refresh hold windows %q: %v", h.Windows, err)
+		return true
+	}
+	return timeutil.Includes(schedule, t)
 }
 
 func refreshGating(st *state.State) (map[string]map[string]*holdState, error) {
@@ -163,6 +184,20 @@ func holdDurationLeft(now time.Time, lastRefresh, firstHeld time.Time, maxDurati
 // A hold level can be specified indicating which operations are affected by the
 // hold.
 func HoldRefreshesBySystem(st *state.State, level HoldLevel, holdTime string, holdSnaps []string) error {
+	return HoldRefreshesBySystemInWindows(st, level, holdTime, "", holdSnaps)
+}
+
+// HoldRefreshesBySystemInWindows is like HoldRefreshesBySystem but the hold
+// is only effective during the recurring calendar windows described by
+// windows, using the same format as refresh.timer (e.g. "mon-fri,9:00-17:00").
+// An empty windows value holds the snaps at all times.
+func HoldRefreshesBySystemInWindows(st *state.State, level HoldLevel, holdTime, windows string, holdSnaps []string) error {
+	if windows != "" {
+		if _, err := timeutil.ParseSchedule(windows); err != nil {
+			return fmt.Errorf("cannot parse hold windows: %v", err)
+		}
+	}
+
 	snaps, err := All(st)
 	if err != nil {
 		return err
@@ -185,8 +220,19 @@ func HoldRefreshesBySystem(st *state.State, level HoldLevel, holdTime string, ho
 		holdDuration = holdTime.Sub(timeNow())
 	}
 
-	_, err = HoldRefresh(st, level, "system", holdDuration, holdSnaps...)
-	return err
+	if _, err := HoldRefresh(st, level, "system", holdDuration, holdSnaps...); err != nil {
+		return err
+	}
+
+	gating, err := refreshGating(st)
+	if err != nil {
+		return err
+	}
+	for _, holdSnap := range holdSnaps {
+		gating[holdSnap]["system"].Windows = windows
+	}
+	st.Set("snaps-hold", gating)
+	return nil
 }
 
 // HoldRefresh marks affectingSnaps as held for refresh for up to holdTime.
@@ -480,6 +526,10 @@ func HeldSnaps(st *state.State, level HoldLevel) (map[string][]string, error) {
 				continue
 			}
 
+			if !hold.inWindows(now) {
+				continue
+			}
+
 			held[heldSnap] = append(held[heldSnap], holdingSnap)
 		}
 	}
@@ -504,6 +554,43 @@ func SystemHold(st *state.State, snap string) (time.Time, error) {
 	return time.Time{}, nil
 }
 
+// SystemRefreshHold describes a refresh hold set on a snap by the sysadmin.
+type SystemRefreshHold struct {
+	InstanceName string
+	Level        HoldLevel
+	Until        time.Time
+	// Windows restricts the hold to recurring calendar windows, if set.
+	Windows string
+}
+
+// SystemRefreshHolds returns the refresh holds set by the sysadmin which
+// did not expire yet, sorted by snap name.
+func SystemRefreshHolds(st *state.State) ([]SystemRefreshHold, error) {
+	gating, err := refreshGating(st)
+	if err != nil {
+		return nil, err
+	}
+
+	now := timeNow()
+	var holds []SystemRefreshHold
+	for heldSnap, holdingSnaps := range gating {
+		hold, ok := holdingSnaps["system"]
+		if !ok || hold.HoldUntil.Before(now) {
+			continue
+		}
+		holds = append(holds, SystemRefreshHold{
+			InstanceName: heldSnap,
+			Level:        hold.Level,
+			Until:        hold.HoldUntil,
+			Windows:      hold.Windows,
+		})
+	}
+	sort.Slice(holds, func(i, j int) bool {
+		return holds[i].InstanceName < holds[j].InstanceName
+	})
+	return holds, nil
+}
+
 // LongestGatingHold returns the time until which the snap's refreshes have been held
 // by a gating snap. If no such hold exists, returns a zero time.Time value.
 func LongestGatingHold(st *state.State, snap string) (time.Time, error) {
@@ -3130,6 +3130,115 @@ func (s *autorefreshGatingSuite) TestSystemHoldEmptyTimeOnHoldNotFound(c *C) {
 	c.Assert(holdTime.IsZero(), Equals, true)
 }
 
+func (s *autorefreshGatingSuite) TestHoldRefreshesBySystemInWindows(c *C) {
+	st := s.state
+	st.Lock()
+	defer st.Unlock()
+
+	// a Monday
+	now := time.Date(2021, 5, 10, 10, 0, 0, 0, time.Local)
+	restore := snapstate.MockTimeNow(func() time.Time {
+		return now
+	})
+	defer restore()
+
+	mockInstalledSnap(c, st, snapAyaml, false)
+	mockInstalledSnap(c, st, snapByaml, false)
+
+	err := snapstate.HoldRefreshesBySystemInWindows(st, snapstate.HoldGeneral, "forever", "mon-fri,9:00-17:00", []string{"snap-a"})
+	c.Assert(err, IsNil)
+	err = snapstate.HoldRefreshesBySystem(st, snapstate.HoldAutoRefresh, "forever", []string{"snap-b"})
+	c.Assert(err, IsNil)
+
+	var gating map[string]map[string]*snapstate.HoldState
+	c.Assert(st.Get("snaps-hold", &gating), IsNil)
+	c.Check(gating["snap-a"]["system"].Windows, Equals, "mon-fri,9:00-17:00")
+	c.Check(gating["snap-b"]["system"].Windows, Equals, "")
+
+	// within the window
+	held, err := snapstate.HeldSnaps(st, snapstate.HoldAutoRefresh)
+	c.Assert(err, IsNil)
+	c.Check(held, DeepEquals, map[string][]string{"snap-a": {"system"}, "snap-b": {"system"}})
+
+	// outside of the window
+	now = time.Date(2021, 5, 10, 18, 0, 0, 0, time.Local)
+	held, err = snapstate.HeldSnaps(st, snapstate.HoldAutoRefresh)
+	c.Assert(err, IsNil)
+	c.Check(held, DeepEquals, map[string][]string{"snap-b": {"system"}})
+
+	// on the weekend
+	now = time.Date(2021, 5, 15, 10, 0, 0, 0, time.Local)
+	held, err = snapstate.HeldSnaps(st, snapstate.HoldAutoRefresh)
+	c.Assert(err, IsNil)
+	c.Check(held, DeepEquals, map[string][]string{"snap-b": {"system"}})
+
+	// holding again without windows holds at all times
+	err = snapstate.HoldRefreshesBySystem(st, snapstate.HoldGeneral, "forever", []string{"snap-a"})
+	c.Assert(err, IsNil)
+	held, err = snapstate.HeldSnaps(st, snapstate.HoldAutoRefresh)
+	c.Assert(err, IsNil)
+	c.Check(held, DeepEquals, map[string][]string{"snap-a": {"system"}, "snap-b": {"system"}})
+}
+
+func (s *autorefreshGatingSuite) TestHoldRefreshesBySystemInWindowsInvalid(c *C) {
+	st := s.state
+	st.Lock()
+	defer st.Unlock()
+
+	mockInstalledSnap(c, st, snapAyaml, false)
+
+	err := snapstate.HoldRefreshesBySystemInWindows(st, snapstate.HoldGeneral, "forever", "bogus", []string{"snap-a"})
+	c.Assert(err, ErrorMatches, `cannot parse hold windows: .*`)
+
+	var gating map[string]map[string]*snapstate.HoldState
+	c.Assert(st.Get("snaps-hold", &gating), testutil.ErrorIs, state.ErrNoState)
+}
+
+func (s *autorefreshGatingSuite) TestSystemRefreshHolds(c *C) {
+	st := s.state
+	st.Lock()
+	defer st.Unlock()
+
+	now := time.Date(2021, 5, 10, 10, 0, 0, 0, time.UTC)
+	restore := snapstate.MockTimeNow(func() time.Time {
+		return now
+	})
+	defer restore()
+
+	mockInstalledSnap(c, st, snapAyaml, false)
+	mockInstalledSnap(c, st, snapByaml, false)
+	mockInstalledSnap(c, st, snapCyaml, false)
+	mockLastRefreshed(c, st, "2021-05-09T10:00:00Z", "snap-c")
+
+	err := snapstate.HoldRefreshesBySystemInWindows(st, snapstate.HoldAutoRefresh, "2021-05-12T10:00:00Z", "sat-sun", []string{"snap-b"})
+	c.Assert(err, IsNil)
+	err = snapstate.HoldRefreshesBySystem(st, snapstate.HoldGeneral, "forever", []string{"snap-a"})
+	c.Assert(err, IsNil)
+	// holds by other snaps are not reported
+	_, err = snapstate.HoldRefresh(st, snapstate.HoldAutoRefresh, "snap-c", 0, "snap-c")
+	c.Assert(err, IsNil)
+
+	holds, err := snapstate.SystemRefreshHolds(st)
+	c.Assert(err, IsNil)
+	c.Check(holds, DeepEquals, []snapstate.SystemRefreshHold{{
+		InstanceName: "snap-a",
+		Level:        snapstate.HoldGeneral,
+		Until:        now.Add(snapstate.MaxDuration),
+	}, {
+		InstanceName: "snap-b",
+		Level:        snapstate.HoldAutoRefresh,
+		Until:        time.Date(2021, 5, 12, 10, 0, 0, 0, time.UTC),
+		Windows:      "sat-sun",
+	}})
+
+	// expired holds are not reported
+	now = time.Date(2021, 5, 13, 10, 0, 0, 0, time.UTC)
+	holds, err = snapstate.SystemRefreshHolds(st)
+	c.Assert(err, IsNil)
+	c.Check(holds, HasLen, 1)
+	c.Check(holds[0].InstanceName, Equals, "snap-a")
+}
+
 func verifyPhasedAutorefreshTasks(c *C, tasks []*state.Task, expected []string) {
 	c.Assert(len(tasks), Equals, len(expected))
 	for i, t := range tasks {
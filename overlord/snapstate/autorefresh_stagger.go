@@ -0,0 +1,136 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snapstate
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/timeutil"
+)
+
+// hook setup by devicestate
+var DeviceSerial func(st *state.State) (*asserts.Serial, error)
+
+const (
+	// auto-refreshes happen at a random time of the refresh window
+	refreshStaggerRandom = "random"
+	// auto-refreshes happen at a time of the refresh window derived
+	// from the serial of the device
+	refreshStaggerDevice = "device"
+)
+
+// parseRefreshStagger parses a refresh.stagger value, which is "random",
+// "device" or a percentage of the refresh window. It returns the fraction
+// of the window given by a percentage, or a negative one otherwise.
+func parseRefreshStagger(spec string) (fraction float64, err error) {
+	switch spec {
+	case "", refreshStaggerRandom, refreshStaggerDevice:
+		return -1, nil
+	}
+	percent, err := strconv.ParseUint(strings.TrimSuffix(spec, "%"), 10, 8)
+	if err != nil || !strings.HasSuffix(spec, "%") || percent > 100 {
+		return 0, fmt.Errorf(`refresh.stagger must be "random", "device" or a percentage between 0%% and 100%%, not %q`, spec)
+	}
+	return float64(percent) / 100, nil
+}
+
+// ValidateRefreshStagger checks that the given refresh.stagger value is
+// valid.
+func ValidateRefreshStagger(spec string) error {
+	_, err := parseRefreshStagger(spec)
+	return err
+}
+
+// deviceStaggerFraction returns a fraction between 0 and 1 derived from
+// the identity of the device, so that a fleet of devices spreads evenly
+// over the refresh window while each device keeps its place in it.
+func deviceStaggerFraction(serial *asserts.Serial) float64 {
+	h := sha256.Sum256([]byte(fmt.Sprintf("%s/%s/%s", serial.BrandID(), serial.Model(), serial.Serial())))
+	return float64(binary.BigEndian.Uint64(h[:8])) / (1 << 64)
+}
+
+// refreshStagger returns the refresh.stagger option and the fraction of
+// the refresh window at which auto-refreshes happen, which is negative if
+// they happen at a random time.
+func refreshStagger(st *state.State) (spec string, fraction float64, err error) {
+	tr := config.NewTransaction(st)
+	if err := tr.Get("core", "refresh.stagger", &spec); err != nil && !config.IsNoOption(err) {
+		return "", 0, err
+	}
+	fraction, err = parseRefreshStagger(spec)
+	if err != nil {
+		return "", 0, err
+	}
+	if spec != refreshStaggerDevice {
+		return spec, fraction, nil
+	}
+
+	var serial *asserts.Serial
+	if DeviceSerial != nil {
+		serial, err = DeviceSerial(st)
+	}
+	if serial == nil {
+		// not registered yet, refresh at a random time
+		// meanwhile
+		logger.Debugf("cannot stagger auto-refreshes by device: no serial: %v", err)
+		return spec, -1, nil
+	}
+	return spec, deviceStaggerFraction(serial), nil
+}
+
+// nextRefreshDelta returns how long to wait from now until the next
+// auto-refresh after the given time, according to the refresh schedule
+// and the refresh.stagger option.
+func (m *autoRefresh) nextRefreshDelta(refreshSchedule []*timeutil.Schedule, last time.Time) (time.Duration, error) {
+	_, fraction, err := refreshStagger(m.state)
+	if err != nil {
+		return 0, err
+	}
+	if fraction < 0 {
+		m.nextRefreshStagger = 0
+		return timeutil.Next(refreshSchedule, last, maxPostponement), nil
+	}
+	delta, offset := timeutil.NextStaggered(refreshSchedule, last, maxPostponement, fraction)
+	m.nextRefreshStagger = offset
+	return delta, nil
+}
+
+// RefreshStagger returns the refresh.stagger option and, unless
+// auto-refreshes happen at a random time, the offset of the next
+// auto-refresh from the start of its refresh window.
+func (m *autoRefresh) RefreshStagger() (spec string, offset time.Duration, err error) {
+	spec, fraction, err := refreshStagger(m.state)
+	if err != nil {
+		return "", 0, err
+	}
+	if fraction < 0 || m.nextRefresh.IsZero() {
+		return spec, 0, nil
+	}
+	return spec, m.nextRefreshStagger, nil
+}
@@ -33,6 +33,7 @@ import (
 	. "gopkg.in/check.v1"
 
 	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/asserts/assertstest"
 	"github.com/snapcore/snapd/asserts/snapasserts"
 	"github.com/snapcore/snapd/confdb"
 	"github.com/snapcore/snapd/dirs"
@@ -1667,3 +1668,279 @@ func (s *autoRefreshTestSuite) TestAutoRefreshWithConfdbs(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(snapsup.PluggedConfdbIDs, DeepEquals, []confdb.SchemaID{{Account: "my-publisher", Name: "my-reg"}})
 }
+
+func (s *autoRefreshTestSuite) mockSerial(c *C, serial string) *asserts.Serial {
+	storeSigning := assertstest.NewStoreStack("canonical", nil)
+	devKey, _ := assertstest.GenerateKey(752)
+	encDevKey, err := asserts.EncodePublicKey(devKey.PublicKey())
+	c.Assert(err, IsNil)
+	a, err := storeSigning.Sign(asserts.SerialType, map[string]any{
+		"brand-id":            "canonical",
+		"model":               "pc",
+		"serial":              serial,
+		"device-key":          string(encDevKey),
+		"device-key-sha3-384": devKey.PublicKey().ID(),
+		"timestamp":           time.Now().Format(time.RFC3339),
+	}, nil, "")
+	c.Assert(err, IsNil)
+	return a.(*asserts.Serial)
+}
+
+// setupStaggeredRefresh sets up a daily refresh window spread over the
+// whole day, the last refresh happened in today's window and the next one
+// happens in tomorrow's, and returns when that window starts.
+func (s *autoRefreshTestSuite) setupStaggeredRefresh(c *C, stagger string) time.Time {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	now := time.Now()
+	s.state.Set("last-refresh", now)
+	tr := config.NewTransaction(s.state)
+	tr.Set("core", "refresh.timer", "00:00~24:00")
+	tr.Set("core", "refresh.stagger", stagger)
+	tr.Commit()
+
+	return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.Local)
+}
+
+func (s *autoRefreshTestSuite) checkStaggeredRefresh(c *C, next, windowStart time.Time, offset time.Duration) {
+	c.Check(next.Sub(windowStart.Add(offset)) < time.Second, Equals, true, Commentf("next refresh at %v, expected %v", next, windowStart.Add(offset)))
+	c.Check(windowStart.Add(offset).Sub(next) < time.Second, Equals, true, Commentf("next refresh at %v, expected %v", next, windowStart.Add(offset)))
+}
+
+func (s *autoRefreshTestSuite) TestRefreshStaggerPercentage(c *C) {
+	windowStart := s.setupStaggeredRefresh(c, "50%")
+
+	af := snapstate.NewAutoRefresh(s.state)
+	err := af.Ensure()
+	c.Assert(err, IsNil)
+	c.Check(s.store.ops, HasLen, 0)
+
+	// half of the window, less the 5 minutes that are not spread over
+	offset := 11*time.Hour + 57*time.Minute + 30*time.Second
+	s.checkStaggeredRefresh(c, af.NextRefresh(), windowStart, offset)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	spec, stagger, err := af.RefreshStagger()
+	c.Assert(err, IsNil)
+	c.Check(spec, Equals, "50%")
+	c.Check(stagger, Equals, offset)
+}
+
+func (s *autoRefreshTestSuite) TestRefreshStaggerDevice(c *C) {
+	windowStart := s.setupStaggeredRefresh(c, "device")
+
+	serial := s.mockSerial(c, "serial-1")
+	restore := snapstate.MockDeviceSerial(func(st *state.State) (*asserts.Serial, error) {
+		return serial, nil
+	})
+	defer restore()
+	fraction := snapstate.DeviceStaggerFraction(serial)
+	c.Assert(fraction >= 0 && fraction < 1, Equals, true)
+	offset := time.Duration(float64(24*time.Hour-5*time.Minute) * fraction)
+
+	// the offset is the same every time it is computed
+	for i := 0; i < 2; i++ {
+		af := snapstate.NewAutoRefresh(s.state)
+		err := af.Ensure()
+		c.Assert(err, IsNil)
+		s.checkStaggeredRefresh(c, af.NextRefresh(), windowStart, offset)
+
+		s.state.Lock()
+		spec, stagger, err := af.RefreshStagger()
+		s.state.Unlock()
+		c.Assert(err, IsNil)
+		c.Check(spec, Equals, "device")
+		c.Check(stagger, Equals, offset)
+	}
+
+	// but differs between devices
+	c.Check(snapstate.DeviceStaggerFraction(s.mockSerial(c, "serial-2")), Not(Equals), fraction)
+}
+
+func (s *autoRefreshTestSuite) TestRefreshStaggerDeviceNotRegistered(c *C) {
+	s.setupStaggeredRefresh(c, "device")
+
+	restore := snapstate.MockDeviceSerial(func(st *state.State) (*asserts.Serial, error) {
+		return nil, state.ErrNoState
+	})
+	defer restore()
+
+	af := snapstate.NewAutoRefresh(s.state)
+	err := af.Ensure()
+	c.Assert(err, IsNil)
+	c.Check(af.NextRefresh().IsZero(), Equals, false)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	spec, stagger, err := af.RefreshStagger()
+	c.Assert(err, IsNil)
+	c.Check(spec, Equals, "device")
+	c.Check(stagger, Equals, time.Duration(0))
+}
+
+func (s *autoRefreshTestSuite) TestRefreshStaggerChangeReschedules(c *C) {
+	windowStart := s.setupStaggeredRefresh(c, "0%")
+
+	af := snapstate.NewAutoRefresh(s.state)
+	err := af.Ensure()
+	c.Assert(err, IsNil)
+	s.checkStaggeredRefresh(c, af.NextRefresh(), windowStart, 0)
+
+	s.state.Lock()
+	tr := config.NewTransaction(s.state)
+	tr.Set("core", "refresh.stagger", "100%")
+	tr.Commit()
+	s.state.Unlock()
+
+	err = af.Ensure()
+	c.Assert(err, IsNil)
+	s.checkStaggeredRefresh(c, af.NextRefresh(), windowStart, 24*time.Hour-5*time.Minute)
+}
+
+func (s *autoRefreshTestSuite) TestRefreshStaggerDefault(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	af := snapstate.NewAutoRefresh(s.state)
+	spec, stagger, err := af.RefreshStagger()
+	c.Assert(err, IsNil)
+	c.Check(spec, Equals, "")
+	c.Check(stagger, Equals, time.Duration(0))
+}
+
+func (s *autoRefreshTestSuite) TestProcessFailedAutoRefreshPreRefreshHookFailure(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	now := time.Now()
+	restore := snapstate.MockTimeNow(func() time.Time { return now })
+	defer restore()
+
+	snapsup := &snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{RealName: "some-snap", SnapID: "some-snap-id", Revision: snap.R(7)},
+	}
+
+	for i := 1; i <= 2; i++ {
+		chg := s.state.NewChange("auto-refresh", "...")
+		hookTask := s.state.NewTask("run-hook", "Run pre-refresh hook of \"some-snap\" snap if present")
+		hookTask.Set("hook-setup", map[string]any{"snap": "some-snap", "hook": "pre-refresh"})
+		hookTask.Errorf("run hook %q: boom", "pre-refresh")
+		hookTask.SetStatus(state.ErrorStatus)
+		unlinkTask := s.state.NewTask("unlink-current-snap", "...")
+		unlinkTask.Set("snap-setup", snapsup)
+		unlinkTask.WaitFor(hookTask)
+		unlinkTask.SetStatus(state.HoldStatus)
+		lane := s.state.NewLane()
+		for _, t := range []*state.Task{hookTask, unlinkTask} {
+			t.JoinLane(lane)
+			chg.AddTask(t)
+		}
+
+		snapstate.ProcessFailedAutoRefresh(chg, state.DoingStatus, state.ErrorStatus)
+
+		var snapst snapstate.SnapState
+		c.Assert(snapstate.Get(s.state, "some-snap", &snapst), IsNil)
+		c.Assert(snapst.RefreshFailures, NotNil)
+		c.Check(snapst.RefreshFailures.Revision, Equals, snap.R(7))
+		c.Check(snapst.RefreshFailures.FailureCount, Equals, i)
+		c.Check(snapst.RefreshFailures.LastError, Equals, `run hook "pre-refresh": boom`)
+
+		var apiData map[string]any
+		c.Assert(chg.Get("api-data", &apiData), IsNil)
+		c.Check(apiData["refresh-failed"], DeepEquals, []any{"some-snap"})
+	}
+
+	// a warning is added once the snap failed repeatedly
+	warnings := s.state.AllWarnings()
+	c.Assert(warnings, HasLen, 1)
+	c.Check(warnings[0].String(), Equals, `snap "some-snap" failed to auto-refresh to revision 7 2 times in a row (last error: run hook "pre-refresh": boom); automatic refreshes of the snap are paused for 12 hours, run "snap refresh some-snap" to retry now`)
+
+	backoffs, err := snapstate.RefreshBackoffs(s.state)
+	c.Assert(err, IsNil)
+	c.Check(backoffs, DeepEquals, []snapstate.RefreshBackoff{{
+		InstanceName: "some-snap",
+		Revision:     snap.R(7),
+		FailureCount: 2,
+		LastError:    `run hook "pre-refresh": boom`,
+		Until:        now.Add(12 * time.Hour),
+	}})
+}
+
+func (s *autoRefreshTestSuite) TestProcessFailedAutoRefreshIgnoresOtherFailures(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	snapsup := &snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{RealName: "some-snap", SnapID: "some-snap-id", Revision: snap.R(7)},
+	}
+
+	// download failures are not the snap's fault
+	chg := s.state.NewChange("auto-refresh", "...")
+	downloadTask := s.state.NewTask("download-snap", "...")
+	downloadTask.Set("snap-setup", snapsup)
+	downloadTask.Errorf("network is down")
+	downloadTask.SetStatus(state.ErrorStatus)
+	unlinkTask := s.state.NewTask("unlink-current-snap", "...")
+	unlinkTask.Set("snap-setup-task", downloadTask.ID())
+	unlinkTask.WaitFor(downloadTask)
+	unlinkTask.SetStatus(state.HoldStatus)
+	lane := s.state.NewLane()
+	for _, t := range []*state.Task{downloadTask, unlinkTask} {
+		t.JoinLane(lane)
+		chg.AddTask(t)
+	}
+
+	snapstate.ProcessFailedAutoRefresh(chg, state.DoingStatus, state.ErrorStatus)
+
+	var snapst snapstate.SnapState
+	c.Assert(snapstate.Get(s.state, "some-snap", &snapst), IsNil)
+	c.Check(snapst.RefreshFailures, IsNil)
+	c.Check(s.state.AllWarnings(), HasLen, 0)
+}
+
+func (s *autoRefreshTestSuite) TestRefreshBackoffs(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	now := time.Now()
+	restore := snapstate.MockTimeNow(func() time.Time { return now })
+	defer restore()
+
+	for name, failures := range map[string]*snap.RefreshFailuresInfo{
+		// backoff delay is still running
+		"snap-a": {Revision: snap.R(3), FailureCount: 1, LastFailureTime: now.Add(-time.Hour), LastError: "boom"},
+		"snap-b": {Revision: snap.R(4), FailureCount: 3, LastFailureTime: now.Add(-time.Hour)},
+		// backoff delay has passed
+		"snap-c": {Revision: snap.R(5), FailureCount: 1, LastFailureTime: now.Add(-9 * time.Hour)},
+		// failed revision was installed since
+		"snap-d": {Revision: snap.R(1), FailureCount: 1, LastFailureTime: now},
+		// no failures
+		"snap-e": nil,
+	} {
+		snapstate.Set(s.state, name, &snapstate.SnapState{
+			Active: true,
+			Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+				{RealName: name, Revision: snap.R(1)},
+			}),
+			Current:         snap.R(1),
+			RefreshFailures: failures,
+		})
+	}
+
+	backoffs, err := snapstate.RefreshBackoffs(s.state)
+	c.Assert(err, IsNil)
+	c.Check(backoffs, DeepEquals, []snapstate.RefreshBackoff{{
+		InstanceName: "snap-a",
+		Revision:     snap.R(3),
+		FailureCount: 1,
+		LastError:    "boom",
+		Until:        now.Add(7 * time.Hour),
+	}, {
+		InstanceName: "snap-b",
+		Revision:     snap.R(4),
+		FailureCount: 3,
+		Until:        now.Add(23 * time.Hour),
+	}})
+}
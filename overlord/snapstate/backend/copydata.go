@@ -24,10 +24,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"syscall"
 
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/osutil/sys"
 	"github.com/snapcore/snapd/osutil/user"
 	"github.com/snapcore/snapd/progress"
 	"github.com/snapcore/snapd/snap"
@@ -76,7 +78,7 @@ func (b Backend) CopySnapData(newSnap, oldSnap *snap.Info, opts *dirs.SnapDirOpt
 		return nil
 	}
 
-	return copySnapData(oldSnap, newSnap, opts)
+	return copySnapData(oldSnap, newSnap, opts, meter)
 }
 
 // UndoCopySnapData removes the copy that may have been done for newInfo snap of oldInfo snap data and also the data directories that may have been created for newInfo snap.
@@ -158,6 +160,16 @@ func (b Backend) ClearTrashedData(oldSnap *snap.Info) {
 
 	// this will have duplicates but the second remove will just be ignored
 	dataDirs = append(dataDirs, hiddenDirs...)
+
+	// common data is only trashed when copying data between instances
+	for _, o := range []*dirs.SnapDirOptions{nil, opts} {
+		commonDirs, err := snapCommonDataDirs(oldSnap, o)
+		if err != nil {
+			logger.Noticef("Cannot remove previous common data for %q: %v", oldSnap.InstanceName(), err)
+			continue
+		}
+		dataDirs = append(dataDirs, commonDirs...)
+	}
 	for _, d := range dataDirs {
 		if err := clearTrash(d); err != nil {
 			logger.Noticef("Cannot remove %s: %v", d, err)
@@ -165,6 +177,154 @@ func (b Backend) ClearTrashedData(oldSnap *snap.Info) {
 	}
 }
 
+// CopyInstanceData replaces the data of the snap instance newSnap with a copy
+// of the data of the snap instance oldSnap, which must be another instance of
+// the same snap. Both the data of the given revisions and the common data are
+// copied, for the system and for each user. The replaced data is moved aside,
+// to be either restored by UndoCopyInstanceData or removed by
+// ClearTrashedData.
+func (b Backend) CopyInstanceData(newSnap, oldSnap *snap.Info, opts *dirs.SnapDirOptions, meter progress.Meter) (retErr error) {
+	if newSnap.SnapName() != oldSnap.SnapName() || newSnap.InstanceName() == oldSnap.InstanceName() {
+		return fmt.Errorf("internal error: cannot copy data of %q to %q: not another instance of the same snap", oldSnap.InstanceName(), newSnap.InstanceName())
+	}
+
+	pairs, err := instanceDataDirs(newSnap, oldSnap, opts)
+	if err != nil {
+		return err
+	}
+
+	done := make([]string, 0, len(pairs))
+	defer func() {
+		if retErr == nil {
+			return
+		}
+		for _, newDir := range done {
+			if err := os.RemoveAll(newDir); err != nil {
+				logger.Noticef("while undoing copy of instance data directory %q: %v", newDir, err)
+			}
+			if err := untrash(newDir); err != nil {
+				logger.Noticef("while restoring the old version of data directory %q: %v", newDir, err)
+			}
+		}
+	}()
+
+	var stats copyStats
+	for _, p := range pairs {
+		st, err := os.Stat(p.oldDir)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !st.IsDir() {
+			return fmt.Errorf("cannot copy %q: not a directory", p.oldDir)
+		}
+		// the per-instance directory of a user may not exist yet, create it
+		// with the ownership of the one of the other instance
+		if err := mkdirLike(filepath.Dir(p.newDir), filepath.Dir(p.oldDir)); err != nil {
+			return err
+		}
+		copied, reflinked, err := copySnapDataDirectory(p.oldDir, p.newDir)
+		if err != nil {
+			return err
+		}
+		if copied {
+			stats.add(reflinked)
+		}
+		done = append(done, p.newDir)
+	}
+	stats.report(meter, fmt.Sprintf("data of snap %q to %q", oldSnap.InstanceName(), newSnap.InstanceName()))
+
+	return nil
+}
+
+// UndoCopyInstanceData removes the data copied by CopyInstanceData to the
+// snap instance newSnap and restores the data it replaced.
+func (b Backend) UndoCopyInstanceData(newSnap, oldSnap *snap.Info, opts *dirs.SnapDirOptions, meter progress.Meter) error {
+	pairs, err := instanceDataDirs(newSnap, oldSnap, opts)
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+	for _, p := range pairs {
+		if err := os.RemoveAll(p.newDir); err != nil {
+			logger.Noticef("Cannot remove copied data directory %q: %v", p.newDir, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if err := untrash(p.newDir); err != nil {
+			logger.Noticef("Cannot restore original data directory %q: %v", p.newDir, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+type instanceDataDir struct {
+	oldDir string
+	newDir string
+}
+
+// instanceDataDirs returns the data directories of oldSnap together with the
+// corresponding directories of newSnap, another instance of the same snap.
+func instanceDataDirs(newSnap, oldSnap *snap.Info, opts *dirs.SnapDirOptions) ([]instanceDataDir, error) {
+	oldDirs, err := snapDataDirs(oldSnap, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range oldSnap.CommonDataHomeDirs(opts) {
+		entryPaths, err := filepath.Glob(entry)
+		if err != nil {
+			return nil, err
+		}
+		oldDirs = append(oldDirs, entryPaths...)
+	}
+	oldDirs = append(oldDirs,
+		oldSnap.UserCommonDataDir(filepath.Join(dirs.GlobalRootDir, "/root/"), opts),
+		oldSnap.CommonDataDir())
+
+	oldRev := filepath.Base(oldSnap.DataDir())
+	newRev := filepath.Base(newSnap.DataDir())
+	pairs := make([]instanceDataDir, 0, len(oldDirs))
+	for _, oldDir := range oldDirs {
+		// all data directories are of the form
+		// .../<instance-name>/{<revision>,common}
+		base := filepath.Base(oldDir)
+		if base == oldRev {
+			base = newRev
+		}
+		parent := filepath.Dir(filepath.Dir(oldDir))
+		pairs = append(pairs, instanceDataDir{
+			oldDir: oldDir,
+			newDir: filepath.Join(parent, newSnap.InstanceName(), base),
+		})
+	}
+	return pairs, nil
+}
+
+// mkdirLike creates the directory path, if it does not exist, with the same
+// permissions and ownership as the directory ref.
+func mkdirLike(path, ref string) error {
+	if osutil.IsDirectory(path) {
+		return nil
+	}
+	fi, err := os.Stat(ref)
+	if err != nil {
+		return err
+	}
+	var uid, gid uint32
+	if st, ok := fi.Sys().(*syscall.Stat_t); ok {
+		uid, gid = st.Uid, st.Gid
+	}
+	return mkdirAllChown(path, fi.Mode().Perm(), sys.UserID(uid), sys.GroupID(gid))
+}
+
 // HideSnapData moves the snap's data directory in ~/snap into the corresponding
 // ~/.snap/data directory, for each user using the snap.
 func (b Backend) HideSnapData(snapName string) error {
@@ -37,6 +37,7 @@ import (
 	"github.com/snapcore/snapd/osutil/user"
 	"github.com/snapcore/snapd/overlord/snapstate/backend"
 	"github.com/snapcore/snapd/progress"
+	"github.com/snapcore/snapd/progress/progresstest"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/snap/snaptest"
 	"github.com/snapcore/snapd/testutil"
@@ -45,6 +46,8 @@ import (
 type copydataSuite struct {
 	be      backend.Backend
 	tempdir string
+
+	restoreSupportsReflink func()
 }
 
 var _ = Suite(&copydataSuite{})
@@ -52,9 +55,12 @@ var _ = Suite(&copydataSuite{})
 func (s *copydataSuite) SetUpTest(c *C) {
 	s.tempdir = c.MkDir()
 	dirs.SetRootDir(s.tempdir)
+	// do not depend on the filesystem of the tests
+	s.restoreSupportsReflink = backend.MockOsutilSupportsReflink(func(dir string) bool { return false })
 }
 
 func (s *copydataSuite) TearDownTest(c *C) {
+	s.restoreSupportsReflink()
 	dirs.SetRootDir("")
 }
 
@@ -557,6 +563,84 @@ func (s *copydataSuite) TestCopyDataCopyFailure(c *C) {
 	c.Assert(err, ErrorMatches, fmt.Sprintf(`cannot copy %s to %s: .*: "cp: boom" \(3\)`, q(v1.DataDir()), q(v2.DataDir())))
 }
 
+// cpNoReflinkScript makes cp copy the data without reflinks, failing if
+// they are required and fail is set
+const cpNoReflinkScript = `
+if [ "$2" = "--reflink=always" ]; then
+	[ -n "$FAIL_REFLINK" ] && { echo "cp: failed to clone: Operation not supported"; exit 1; }
+	shift 2
+	exec /bin/cp -av "$@"
+fi
+exec /bin/cp "$@"
+`
+
+func (s *copydataSuite) testCopyDataReflink(c *C, supportsReflink func(dir string) bool, failReflink bool, expectedNotice string) (cpCalls [][]string) {
+	restore := backend.MockOsutilSupportsReflink(supportsReflink)
+	defer restore()
+	if failReflink {
+		os.Setenv("FAIL_REFLINK", "1")
+		defer os.Unsetenv("FAIL_REFLINK")
+	}
+	cp := testutil.MockCommand(c, "cp", cpNoReflinkScript)
+	defer cp.Restore()
+
+	v1 := snaptest.MockSnap(c, helloYaml1, &snap.SideInfo{Revision: snap.R(10)})
+	s.populateData(c, snap.R(10))
+	homedir := s.populateHomeData(c, "user1", snap.R(10))
+	v2 := snaptest.MockSnap(c, helloYaml2, &snap.SideInfo{Revision: snap.R(20)})
+
+	meter := &progresstest.Meter{}
+	err := s.be.CopySnapData(v2, v1, nil, meter)
+	c.Assert(err, IsNil)
+
+	c.Check(s.populatedData("20"), Equals, "10\n")
+	c.Check(filepath.Join(homedir, "hello/20/canary.home"), testutil.FileEquals, "10\n")
+	c.Check(meter.Notices, DeepEquals, []string{expectedNotice})
+	return cp.Calls()
+}
+
+func (s *copydataSuite) TestCopyDataReflink(c *C) {
+	calls := s.testCopyDataReflink(c, func(string) bool { return true }, false,
+		`Copied data of snap "hello" from revision 10 using copy-on-write reflinks`)
+	c.Assert(calls, HasLen, 2)
+	for _, call := range calls {
+		c.Check(call[:3], DeepEquals, []string{"cp", "-av", "--reflink=always"})
+	}
+}
+
+func (s *copydataSuite) TestCopyDataReflinkFallback(c *C) {
+	calls := s.testCopyDataReflink(c, func(string) bool { return true }, true,
+		`Copied data of snap "hello" from revision 10 using full copies`)
+	// each copy is attempted with reflinks first
+	c.Assert(calls, HasLen, 4)
+	for i, call := range calls {
+		if i%2 == 0 {
+			c.Check(call[:3], DeepEquals, []string{"cp", "-av", "--reflink=always"})
+		} else {
+			c.Check(call[:2], DeepEquals, []string{"cp", "-av"})
+			c.Check(call, HasLen, 4)
+		}
+	}
+}
+
+func (s *copydataSuite) TestCopyDataReflinkPartial(c *C) {
+	supportsReflink := func(dir string) bool {
+		return strings.HasPrefix(dir, dirs.SnapDataDir)
+	}
+	calls := s.testCopyDataReflink(c, supportsReflink, false,
+		`Copied data of snap "hello" from revision 10 using copy-on-write reflinks where supported and full copies otherwise`)
+	c.Assert(calls, HasLen, 2)
+}
+
+func (s *copydataSuite) TestCopyDataNoReflink(c *C) {
+	calls := s.testCopyDataReflink(c, func(string) bool { return false }, false,
+		`Copied data of snap "hello" from revision 10 using full copies`)
+	c.Assert(calls, HasLen, 2)
+	for _, call := range calls {
+		c.Check(call, HasLen, 4)
+	}
+}
+
 func (s *copydataSuite) TestCopyDataPartialFailure(c *C) {
 	v1 := snaptest.MockSnap(c, helloYaml1, &snap.SideInfo{Revision: snap.R(10)})
 
@@ -1062,6 +1146,87 @@ func (s *copydataSuite) TestCleanupAfterCopyAndMigration(c *C) {
 	c.Assert(exists, Equals, false)
 }
 
+func (s *copydataSuite) mockInstanceData(c *C, instanceName string, rev snap.Revision, canary string) *snap.Info {
+	info := snaptest.MockSnapInstance(c, instanceName, helloYaml1, &snap.SideInfo{Revision: rev})
+	homedir := filepath.Join(dirs.GlobalRootDir, "home", "user1")
+	for _, d := range []string{
+		info.DataDir(),
+		info.CommonDataDir(),
+		info.UserDataDir(homedir, nil),
+		info.UserCommonDataDir(homedir, nil),
+	} {
+		c.Assert(os.MkdirAll(d, 0755), IsNil)
+		c.Assert(os.WriteFile(filepath.Join(d, "canary"), []byte(canary), 0644), IsNil)
+	}
+	return info
+}
+
+func (s *copydataSuite) TestCopyInstanceData(c *C) {
+	dirs.SetSnapHomeDirs("/home")
+	homedir := filepath.Join(dirs.GlobalRootDir, "home", "user1")
+
+	from := s.mockInstanceData(c, "hello_foo", snap.R(10), "foo")
+	to := s.mockInstanceData(c, "hello_bar", snap.R(20), "bar")
+	// the data of the user was never created for the target instance
+	c.Assert(os.RemoveAll(filepath.Join(homedir, "snap", "hello_bar")), IsNil)
+
+	err := s.be.CopyInstanceData(to, from, nil, progress.Null)
+	c.Assert(err, IsNil)
+
+	for _, d := range []string{
+		to.DataDir(),
+		to.CommonDataDir(),
+		to.UserDataDir(homedir, nil),
+		to.UserCommonDataDir(homedir, nil),
+	} {
+		c.Check(filepath.Join(d, "canary"), testutil.FileEquals, "foo")
+	}
+	// the source is untouched
+	c.Check(filepath.Join(from.DataDir(), "canary"), testutil.FileEquals, "foo")
+	// the previous data of the system was trashed
+	c.Check(filepath.Join(dirs.SnapDataDir, "hello_bar/20.old/canary"), testutil.FileEquals, "bar")
+	c.Check(filepath.Join(dirs.SnapDataDir, "hello_bar/common.old/canary"), testutil.FileEquals, "bar")
+
+	s.be.ClearTrashedData(to)
+	c.Check(filepath.Join(dirs.SnapDataDir, "hello_bar/20.old"), testutil.FileAbsent)
+	c.Check(filepath.Join(dirs.SnapDataDir, "hello_bar/common.old"), testutil.FileAbsent)
+	c.Check(filepath.Join(to.DataDir(), "canary"), testutil.FileEquals, "foo")
+}
+
+func (s *copydataSuite) TestCopyInstanceDataUndo(c *C) {
+	dirs.SetSnapHomeDirs("/home")
+	homedir := filepath.Join(dirs.GlobalRootDir, "home", "user1")
+
+	from := s.mockInstanceData(c, "hello_foo", snap.R(10), "foo")
+	to := s.mockInstanceData(c, "hello_bar", snap.R(20), "bar")
+
+	err := s.be.CopyInstanceData(to, from, nil, progress.Null)
+	c.Assert(err, IsNil)
+	c.Check(filepath.Join(to.DataDir(), "canary"), testutil.FileEquals, "foo")
+
+	err = s.be.UndoCopyInstanceData(to, from, nil, progress.Null)
+	c.Assert(err, IsNil)
+	for _, d := range []string{
+		to.DataDir(),
+		to.CommonDataDir(),
+		to.UserDataDir(homedir, nil),
+		to.UserCommonDataDir(homedir, nil),
+	} {
+		c.Check(filepath.Join(d, "canary"), testutil.FileEquals, "bar")
+		c.Check(d+".old", testutil.FileAbsent)
+	}
+}
+
+func (s *copydataSuite) TestCopyInstanceDataNotAnInstance(c *C) {
+	from := snaptest.MockSnapInstance(c, "hello_foo", helloYaml1, &snap.SideInfo{Revision: snap.R(10)})
+	other := snaptest.MockSnapInstance(c, "other_foo", "name: other\nversion: 1\n", &snap.SideInfo{Revision: snap.R(10)})
+
+	err := s.be.CopyInstanceData(from, from, nil, progress.Null)
+	c.Check(err, ErrorMatches, `internal error: cannot copy data of "hello_foo" to "hello_foo": not another instance of the same snap`)
+	err = s.be.CopyInstanceData(other, from, nil, progress.Null)
+	c.Check(err, ErrorMatches, `internal error: cannot copy data of "hello_foo" to "other_foo": not another instance of the same snap`)
+}
+
 func (s *copydataSuite) TestRemoveIfEmpty(c *C) {
 	file := filepath.Join(dirs.GlobalRootDir, "random")
 	c.Assert(os.WriteFile(file, []byte("stuff"), 0664), IsNil)
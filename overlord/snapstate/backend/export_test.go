@@ -88,3 +88,7 @@ func MockWrappersStartServices(f func(apps []*snap.AppInfo, disabledSvcs *wrappe
 func MockWrappersStopServices(f func(svcs []*snap.AppInfo, removedSvcs map[string]*snap.AppInfo, opts *wrappers.StopServicesOptions, reason snap.ServiceStopReason, inter wrappers.Interacter, tm timings.Measurer) error) func() {
 	return testutil.Mock(&wrappersStopServices, f)
 }
+
+func MockOsutilSupportsReflink(f func(dir string) bool) func() {
+	return testutil.Mock(&osutilSupportsReflink, f)
+}
@@ -30,9 +30,12 @@ import (
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/progress"
 	"github.com/snapcore/snapd/snap"
 )
 
+var osutilSupportsReflink = osutil.SupportsReflink
+
 // RemoveSnapData removes the data for the given version of the given snap.
 func (b Backend) RemoveSnapData(snap *snap.Info, opts *dirs.SnapDirOptions) error {
 	dirs, err := snapDataDirs(snap, opts)
@@ -234,7 +237,7 @@ func snapCommonDataDirs(snap *snap.Info, opts *dirs.SnapDirOptions) ([]string, e
 
 // Copy all data for oldSnap to newSnap
 // (but never overwrite)
-func copySnapData(oldSnap, newSnap *snap.Info, opts *dirs.SnapDirOptions) (retErr error) {
+func copySnapData(oldSnap, newSnap *snap.Info, opts *dirs.SnapDirOptions, meter progress.Meter) (retErr error) {
 	oldDataDirs, err := snapDataDirs(oldSnap, opts)
 	if err != nil {
 		return err
@@ -255,19 +258,56 @@ func copySnapData(oldSnap, newSnap *snap.Info, opts *dirs.SnapDirOptions) (retEr
 		}
 	}()
 
+	var stats copyStats
 	newSuffix := filepath.Base(newSnap.DataDir())
 	for _, oldDir := range oldDataDirs {
 		// replace the trailing "../$old-suffix" with the "../$new-suffix"
 		newDir := filepath.Join(filepath.Dir(oldDir), newSuffix)
-		if err := copySnapDataDirectory(oldDir, newDir); err != nil {
+		copied, reflinked, err := copySnapDataDirectory(oldDir, newDir)
+		if err != nil {
 			return err
 		}
+		if copied {
+			stats.add(reflinked)
+		}
 		done = append(done, newDir)
 	}
+	stats.report(meter, fmt.Sprintf("data of snap %q from revision %s", newSnap.InstanceName(), oldSnap.Revision))
 
 	return nil
 }
 
+// copyStats counts the data directories copied with reflinks and with
+// full copies, to report the used strategy.
+type copyStats struct {
+	reflinked int
+	copied    int
+}
+
+func (cs *copyStats) add(reflinked bool) {
+	if reflinked {
+		cs.reflinked++
+	} else {
+		cs.copied++
+	}
+}
+
+func (cs *copyStats) report(meter progress.Meter, what string) {
+	var how string
+	switch {
+	case cs.reflinked > 0 && cs.copied > 0:
+		how = "copy-on-write reflinks where supported and full copies otherwise"
+	case cs.reflinked > 0:
+		how = "copy-on-write reflinks"
+	case cs.copied > 0:
+		how = "full copies"
+	default:
+		// nothing was copied
+		return
+	}
+	meter.Notify(fmt.Sprintf("Copied %s using %s", what, how))
+}
+
 // trashPath returns the trash path for the given path. This will
 // differ only in the last element.
 func trashPath(path string) string {
@@ -327,15 +367,16 @@ func clearTrash(path string) error {
 	return nil
 }
 
-// Lowlevel copy the snap data (but never override existing data)
-func copySnapDataDirectory(oldPath, newPath string) (err error) {
+// Lowlevel copy the snap data (but never override existing data). It returns
+// whether the data was copied at all, and if so whether reflinks were used.
+func copySnapDataDirectory(oldPath, newPath string) (copied, reflinked bool, err error) {
 	if _, err := os.Stat(oldPath); err == nil {
 		if err := trash(newPath); err != nil {
-			return err
+			return false, false, err
 		}
 
 		if _, err := os.Stat(newPath); err != nil {
-			if err := osutil.CopyFile(oldPath, newPath, osutil.CopyFlagPreserveAll|osutil.CopyFlagSync); err != nil {
+			if reflinked, err = copyDataDirectory(oldPath, newPath); err != nil {
 				msg := fmt.Sprintf("cannot copy %q to %q: %v", oldPath, newPath, err)
 				// remove the directory, in case it was a partial success
 				if e := os.RemoveAll(newPath); e != nil && !os.IsNotExist(e) {
@@ -349,12 +390,33 @@ func copySnapDataDirectory(oldPath, newPath string) (err error) {
 					msg += fmt.Sprintf("; and when trying to restore the old data directory: %v", e)
 				}
 
-				return errors.New(msg)
+				return false, false, errors.New(msg)
 			}
+			copied = true
 		}
 	} else if !os.IsNotExist(err) {
-		return err
+		return false, false, err
 	}
 
-	return nil
+	return copied, reflinked, nil
+}
+
+// copyDataDirectory copies oldPath to newPath, sharing the data of the
+// files with reflinks if the filesystem supports them, which is both
+// faster and does not use additional disk space, and falling back to a
+// full copy otherwise. It returns whether reflinks were used.
+func copyDataDirectory(oldPath, newPath string) (reflinked bool, err error) {
+	flags := osutil.CopyFlagPreserveAll | osutil.CopyFlagSync
+	if osutilSupportsReflink(filepath.Dir(oldPath)) {
+		err := osutil.CopyFile(oldPath, newPath, flags|osutil.CopyFlagReflink)
+		if err == nil {
+			return true, nil
+		}
+		// reflinks may not be possible across e.g. btrfs subvolumes
+		logger.Noticef("cannot copy %q to %q with reflinks, falling back to a full copy: %v", oldPath, newPath, err)
+		if err := os.RemoveAll(newPath); err != nil {
+			return false, err
+		}
+	}
+	return false, osutil.CopyFile(oldPath, newPath, flags)
 }
@@ -224,6 +224,9 @@ type fakeStore struct {
 
 	downloadCallback     func()
 	downloadIconCallback func(targetPath string)
+	// downloadRateLimitCallback is called with the dynamic rate limit of
+	// a download, if any
+	downloadRateLimitCallback func(limit *store.DynamicRateLimit)
 
 	namesToAssertedIDs map[string]string
 	idsToNames         map[string]string
@@ -959,6 +962,16 @@ func (f *fakeStore) Download(ctx context.Context, name, targetFn string, snapInf
 	if f.downloadCallback != nil {
 		f.downloadCallback()
 	}
+	if dlOpts != nil && dlOpts.DynamicRateLimit != nil {
+		// dynamic rate limits are checked through the callback, record
+		// the options without them
+		opts := *dlOpts
+		if f.downloadRateLimitCallback != nil {
+			f.downloadRateLimitCallback(opts.DynamicRateLimit)
+		}
+		opts.DynamicRateLimit = nil
+		dlOpts = &opts
+	}
 
 	var macaroon string
 	if user != nil {
@@ -1652,6 +1665,29 @@ func (f *fakeSnappyBackend) UndoCopySnapData(newInfo *snap.Info, oldInfo *snap.I
 	return f.maybeErr(op)
 }
 
+func (f *fakeSnappyBackend) CopyInstanceData(newInfo, oldInfo *snap.Info, opts *dirs.SnapDirOptions, p progress.Meter) error {
+	op := &fakeOp{
+		op:   "copy-instance-data",
+		path: newInfo.MountDir(),
+		old:  oldInfo.MountDir(),
+	}
+	if opts != nil && opts.HiddenSnapDataDir {
+		op.dirOpts = opts
+	}
+	f.appendOp(op)
+	return f.maybeErr(op)
+}
+
+func (f *fakeSnappyBackend) UndoCopyInstanceData(newInfo, oldInfo *snap.Info, opts *dirs.SnapDirOptions, p progress.Meter) error {
+	op := &fakeOp{
+		op:   "undo-copy-instance-data",
+		path: newInfo.MountDir(),
+		old:  oldInfo.MountDir(),
+	}
+	f.appendOp(op)
+	return f.maybeErr(op)
+}
+
 func (f *fakeSnappyBackend) UndoSetupSnapSaveData(newInfo, oldInfo *snap.Info, _ snap.Device, meter progress.Meter) error {
 	old := "<no-old>"
 	if oldInfo != nil {
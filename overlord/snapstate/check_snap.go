@@ -52,6 +52,8 @@ var featureSet = map[string]bool{
 	"kernel-assets": true,
 	// Support for "refresh-mode: ignore-running" in snap.yaml
 	"app-refresh-mode": true,
+	// Support for "refresh-mode: ignore-running" for services in snap.yaml
+	"service-refresh-mode-ignore-running": true,
 	// Support for "SNAP_UID" and "SNAP_EUID" environment variables
 	"snap-uid-envvars": true,
 }
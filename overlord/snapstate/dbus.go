@@ -20,12 +20,90 @@
 package snapstate
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
 
+	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/snap"
 )
 
+var (
+	// hostDBusSessionServicesDirs and hostDBusSystemServicesDirs are the
+	// standard locations of the D-Bus service activation files installed
+	// on the host, which take precedence over the ones written by snapd.
+	hostDBusSessionServicesDirs = []string{
+		"/usr/local/share/dbus-1/services",
+		"/usr/share/dbus-1/services",
+	}
+	hostDBusSystemServicesDirs = []string{
+		"/usr/local/share/dbus-1/system-services",
+		"/usr/share/dbus-1/system-services",
+		"/lib/dbus-1/system-services",
+	}
+)
+
+var serviceNameLine = regexp.MustCompile(`(?m)^Name=(.*)$`)
+
+// hostDBusServices returns the bus names which are activatable through the
+// service files found in the given host directories, mapped to the file
+// providing them.
+func hostDBusServices(servicesDirs []string) (map[string]string, error) {
+	services := make(map[string]string)
+	for _, dir := range servicesDirs {
+		matches, err := filepath.Glob(filepath.Join(dirs.GlobalRootDir, dir, "*.service"))
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			content, err := os.ReadFile(match)
+			if err != nil {
+				return nil, err
+			}
+			m := serviceNameLine.FindSubmatch(content)
+			if m == nil {
+				continue
+			}
+			busName := string(m[1])
+			if _, ok := services[busName]; !ok {
+				services[busName] = filepath.Join(dir, filepath.Base(match))
+			}
+		}
+	}
+	return services, nil
+}
+
+// checkHostDBusServiceConflicts checks that none of the given bus names can
+// already be activated by services of the host, as those would be started
+// instead of the snap ones.
+func checkHostDBusServiceConflicts(info *snap.Info, sessionServices, systemServices map[string]bool) error {
+	for _, bus := range []struct {
+		name        string
+		services    map[string]bool
+		servicesDir []string
+	}{
+		{"session", sessionServices, hostDBusSessionServicesDirs},
+		{"system", systemServices, hostDBusSystemServicesDirs},
+	} {
+		if len(bus.services) == 0 {
+			continue
+		}
+		hostServices, err := hostDBusServices(bus.servicesDir)
+		if err != nil {
+			return fmt.Errorf("cannot check for host D-Bus services: %v", err)
+		}
+		for svc := range bus.services {
+			if file, ok := hostServices[svc]; ok {
+				return fmt.Errorf("snap %q requesting to activate on %s bus name %q conflicts with host service file %q", info.InstanceName(), bus.name, svc, file)
+			}
+		}
+	}
+	return nil
+}
+
 func getActivatableDBusServices(info *snap.Info) (session, system map[string]bool) {
 	session = make(map[string]bool)
 	system = make(map[string]bool)
@@ -48,6 +126,16 @@ func getActivatableDBusServices(info *snap.Info) (session, system map[string]boo
 	return session, system
 }
 
+func servicesNotIn(services, others map[string]bool) map[string]bool {
+	notIn := make(map[string]bool, len(services))
+	for svc := range services {
+		if !others[svc] {
+			notIn[svc] = true
+		}
+	}
+	return notIn
+}
+
 func checkDBusServiceConflicts(st *state.State, info *snap.Info) error {
 	sessionServices, systemServices := getActivatableDBusServices(info)
 
@@ -56,6 +144,27 @@ func checkDBusServiceConflicts(st *state.State, info *snap.Info) error {
 		return nil
 	}
 
+	// Bus names already activated by the installed revision are not
+	// checked against the host again, so that services installed on the
+	// host later on do not block refreshes.
+	var snapst SnapState
+	if err := Get(st, info.InstanceName(), &snapst); err != nil && !errors.Is(err, state.ErrNoState) {
+		return err
+	}
+	newSessionServices, newSystemServices := sessionServices, systemServices
+	if snapst.IsInstalled() {
+		curInfo, err := snapst.CurrentInfo()
+		if err != nil {
+			return err
+		}
+		curSessionServices, curSystemServices := getActivatableDBusServices(curInfo)
+		newSessionServices = servicesNotIn(sessionServices, curSessionServices)
+		newSystemServices = servicesNotIn(systemServices, curSystemServices)
+	}
+	if err := checkHostDBusServiceConflicts(info, newSessionServices, newSystemServices); err != nil {
+		return err
+	}
+
 	stateMap, err := All(st)
 	if err != nil {
 		return err
@@ -22,9 +22,12 @@ package snapstate_test
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 
 	. "gopkg.in/check.v1"
 
+	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/snapstate/snapstatetest"
 	"github.com/snapcore/snapd/snap"
@@ -213,6 +216,81 @@ func (s *snapmgrTestSuite) TestCheckDBusServiceConflictsNoConflictWithSelf(c *C)
 	c.Assert(err, IsNil)
 }
 
+func (s *snapmgrTestSuite) mockHostDBusService(c *C, dir, filename, busName string) {
+	servicesDir := filepath.Join(dirs.GlobalRootDir, dir)
+	c.Assert(os.MkdirAll(servicesDir, 0755), IsNil)
+	content := fmt.Sprintf("[D-BUS Service]\nName=%s\nExec=/usr/bin/foo\n", busName)
+	c.Assert(os.WriteFile(filepath.Join(servicesDir, filename), []byte(content), 0644), IsNil)
+}
+
+func (s *snapmgrTestSuite) TestCheckDBusServiceConflictsHostSession(c *C) {
+	info, err := snap.InfoFromSnapYaml([]byte(fmt.Sprintf(dbusSessionYamlTemplate, "some-snap")))
+	c.Assert(err, IsNil)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	// services on the other bus do not conflict
+	s.mockHostDBusService(c, "/usr/share/dbus-1/system-services", "org.example.Foo.service", "org.example.Foo")
+	err = snapstate.CheckDBusServiceConflicts(s.state, info)
+	c.Assert(err, IsNil)
+
+	// the name of the service file does not matter
+	s.mockHostDBusService(c, "/usr/local/share/dbus-1/services", "foo.service", "org.example.Foo")
+	err = snapstate.CheckDBusServiceConflicts(s.state, info)
+	c.Assert(err, ErrorMatches, `snap "some-snap" requesting to activate on session bus name "org.example.Foo" conflicts with host service file "/usr/local/share/dbus-1/services/foo.service"`)
+}
+
+func (s *snapmgrTestSuite) TestCheckDBusServiceConflictsHostSystem(c *C) {
+	info, err := snap.InfoFromSnapYaml([]byte(fmt.Sprintf(dbusSystemYamlTemplate, "some-snap")))
+	c.Assert(err, IsNil)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.mockHostDBusService(c, "/usr/share/dbus-1/system-services", "org.example.Bar.service", "org.example.Bar")
+	err = snapstate.CheckDBusServiceConflicts(s.state, info)
+	c.Assert(err, IsNil)
+
+	s.mockHostDBusService(c, "/usr/share/dbus-1/system-services", "org.example.Foo.service", "org.example.Foo")
+	err = snapstate.CheckDBusServiceConflicts(s.state, info)
+	c.Assert(err, ErrorMatches, `snap "some-snap" requesting to activate on system bus name "org.example.Foo" conflicts with host service file "/usr/share/dbus-1/system-services/org.example.Foo.service"`)
+}
+
+func (s *snapmgrTestSuite) TestCheckDBusServiceConflictsHostAlreadyActivated(c *C) {
+	info, err := snap.InfoFromSnapYaml([]byte(fmt.Sprintf(dbusSessionYamlTemplate, "some-snap")))
+	c.Assert(err, IsNil)
+	restore := snapstate.MockSnapReadInfo(func(name string, si *snap.SideInfo) (*snap.Info, error) {
+		switch name {
+		case "some-snap":
+			return info, nil
+		default:
+			return s.fakeBackend.ReadInfo(name, si)
+		}
+	})
+	defer restore()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	si := &snap.SideInfo{
+		RealName: "some-snap",
+		Revision: snap.R(-42),
+	}
+	snapstate.Set(s.state, "some-snap", &snapstate.SnapState{
+		Active:   true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{si}),
+		Current:  si.Revision,
+		SnapType: "app",
+	})
+
+	// the host service appeared after the snap started activating on the
+	// same name, which does not block refreshes
+	s.mockHostDBusService(c, "/usr/share/dbus-1/services", "org.example.Foo.service", "org.example.Foo")
+	err = snapstate.CheckDBusServiceConflicts(s.state, info)
+	c.Assert(err, IsNil)
+}
+
 func (s *snapmgrTestSuite) TestInstallDBusActivationConflicts(c *C) {
 	someSnap, err := snap.InfoFromSnapYaml([]byte(fmt.Sprintf(dbusSystemYamlTemplate, "some-snap")))
 	c.Assert(err, IsNil)
@@ -0,0 +1,334 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snapstate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"syscall"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/features"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+)
+
+var (
+	osutilFreeDiskSpace = osutil.FreeDiskSpace
+	diskSpaceDirSize    = dirSize
+	diskSpaceDeviceOf   = deviceOf
+)
+
+// DiskSpaceUsage describes the space a simulated operation needs on a
+// single filesystem, split by what the space is used for.
+type DiskSpaceUsage struct {
+	// Path is a representative path on the filesystem.
+	Path string `json:"path"`
+	// Snaps is the space needed to download snaps and their prerequisites.
+	Snaps uint64 `json:"snaps"`
+	// DataCopies is the space needed to copy the data of refreshed snaps.
+	DataCopies uint64 `json:"data-copies"`
+	// Snapshots is the space needed for automatic snapshots of removed snaps.
+	Snapshots uint64 `json:"snapshots"`
+	// Required is the total space needed, including a safety margin.
+	Required uint64 `json:"required"`
+	// Available is the space currently available.
+	Available uint64 `json:"available"`
+}
+
+// DiskSpaceEstimate is the result of simulating an install, refresh or
+// remove of a set of snaps.
+type DiskSpaceEstimate struct {
+	Operation   string           `json:"operation"`
+	Snaps       []string         `json:"snaps"`
+	Filesystems []DiskSpaceUsage `json:"filesystems"`
+	// CheckEnabled is true if the disk space check for the operation is
+	// enabled through its feature flag.
+	CheckEnabled bool `json:"check-enabled"`
+	// Refused is true if snapd would refuse to perform the operation
+	// because of insufficient disk space.
+	Refused bool `json:"refused"`
+	// Error is the error snapd would return when refusing the operation.
+	Error string `json:"error,omitempty"`
+}
+
+type diskSpaceCategory int
+
+const (
+	diskSpaceSnaps diskSpaceCategory = iota
+	diskSpaceDataCopies
+	diskSpaceSnapshots
+)
+
+// SimulateDiskSpace estimates the disk space needed to install, refresh or
+// remove the given snaps without making any changes to the system. For
+// refresh, no names means all snaps with available updates.
+// The caller should be holding the state lock.
+func SimulateDiskSpace(ctx context.Context, st *state.State, op string, names []string, userID int) (*DiskSpaceEstimate, error) {
+	var featFlag features.SnapdFeature
+	switch op {
+	case "install":
+		featFlag = features.CheckDiskSpaceInstall
+		if len(names) == 0 {
+			return nil, fmt.Errorf("cannot simulate install without snap names")
+		}
+	case "refresh":
+		featFlag = features.CheckDiskSpaceRefresh
+	case "remove":
+		featFlag = features.CheckDiskSpaceRemove
+		if len(names) == 0 {
+			return nil, fmt.Errorf("cannot simulate remove without snap names")
+		}
+	default:
+		return nil, fmt.Errorf("cannot simulate disk space for unknown operation %q", op)
+	}
+
+	var usage [3]uint64
+	var snaps []string
+	var err error
+	switch op {
+	case "install":
+		snaps, usage[diskSpaceSnaps], err = simulateInstallSpace(ctx, st, names, userID)
+	case "refresh":
+		snaps, usage[diskSpaceSnaps], usage[diskSpaceDataCopies], err = simulateRefreshSpace(ctx, st, names, userID)
+	case "remove":
+		snaps, usage[diskSpaceSnapshots], err = simulateRemoveSpace(st, names)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(snaps)
+	est := &DiskSpaceEstimate{
+		Operation: op,
+		Snaps:     snaps,
+	}
+	if est.Snaps == nil {
+		est.Snaps = []string{}
+	}
+
+	paths := [3]string{
+		diskSpaceSnaps:      dirs.SnapBlobDir,
+		diskSpaceDataCopies: dirs.SnapDataDir,
+		diskSpaceSnapshots:  dirs.SnapshotsDir,
+	}
+	byDevice := make(map[uint64]int)
+	for cat, path := range paths {
+		if usage[cat] == 0 {
+			continue
+		}
+		dev, err := diskSpaceDeviceOf(path)
+		if err != nil {
+			return nil, err
+		}
+		idx, ok := byDevice[dev]
+		if !ok {
+			idx = len(est.Filesystems)
+			byDevice[dev] = idx
+			est.Filesystems = append(est.Filesystems, DiskSpaceUsage{Path: path})
+		}
+		fs := &est.Filesystems[idx]
+		switch diskSpaceCategory(cat) {
+		case diskSpaceSnaps:
+			fs.Snaps += usage[cat]
+		case diskSpaceDataCopies:
+			fs.DataCopies += usage[cat]
+		case diskSpaceSnapshots:
+			fs.Snapshots += usage[cat]
+		}
+	}
+	for i := range est.Filesystems {
+		fs := &est.Filesystems[i]
+		fs.Required = safetyMarginDiskSpace(fs.Snaps + fs.DataCopies + fs.Snapshots)
+		fs.Available, err = osutilFreeDiskSpace(existingAncestor(fs.Path))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if est.Filesystems == nil {
+		est.Filesystems = []DiskSpaceUsage{}
+	}
+
+	tr := config.NewTransaction(st)
+	est.CheckEnabled, err = features.Flag(tr, featFlag)
+	if err != nil && !config.IsNoOption(err) {
+		return nil, err
+	}
+	// mirror the checks done by snapd itself, which only account for
+	// downloads on install and refresh and for snapshots on remove
+	checked := usage[diskSpaceSnaps]
+	if op == "remove" {
+		checked = usage[diskSpaceSnapshots]
+	}
+	if est.CheckEnabled && checked > 0 {
+		path := dirs.SnapdStateDir(dirs.GlobalRootDir)
+		if err := osutilCheckFreeSpace(path, safetyMarginDiskSpace(checked)); err != nil {
+			if _, ok := err.(*osutil.NotEnoughDiskSpaceError); !ok {
+				return nil, err
+			}
+			est.Refused = true
+			est.Error = (&InsufficientSpaceError{
+				Path:       path,
+				Snaps:      snaps,
+				ChangeKind: op,
+			}).Error()
+		}
+	}
+
+	return est, nil
+}
+
+func simulateInstallSpace(ctx context.Context, st *state.State, names []string, userID int) (snaps []string, download uint64, err error) {
+	opts := Options{UserID: userID}
+	if err := setDefaultSnapstateOptions(st, &opts); err != nil {
+		return nil, 0, err
+	}
+
+	storeSnaps := make([]StoreSnap, 0, len(names))
+	for _, name := range names {
+		storeSnaps = append(storeSnaps, StoreSnap{InstanceName: name})
+	}
+	targets, err := StoreInstallGoal(storeSnaps...).toInstall(ctx, st, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	infos := make([]minimalInstallInfo, 0, len(targets))
+	for _, t := range targets {
+		infos = append(infos, installSnapInfo{t.info})
+		snaps = append(snaps, t.info.InstanceName())
+	}
+	download, err = installSize(st, infos, userID, opts.PrereqTracker)
+	if err != nil {
+		return nil, 0, err
+	}
+	return snaps, download, nil
+}
+
+func simulateRefreshSpace(ctx context.Context, st *state.State, names []string, userID int) (snaps []string, download, dataCopies uint64, err error) {
+	opts := Options{UserID: userID}
+	if err := setDefaultSnapstateOptions(st, &opts); err != nil {
+		return nil, 0, 0, err
+	}
+
+	updates := make([]StoreUpdate, 0, len(names))
+	for _, name := range names {
+		updates = append(updates, StoreUpdate{InstanceName: name})
+	}
+	plan, err := StoreUpdateGoal(updates...).toUpdate(ctx, st, opts)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	infos := make([]minimalInstallInfo, 0, len(plan.targets))
+	for _, t := range plan.targets {
+		infos = append(infos, installSnapInfo{t.info})
+		snaps = append(snaps, t.info.InstanceName())
+		if !t.snapst.IsInstalled() {
+			continue
+		}
+		// the data of the current revision is copied for the new one
+		size, err := diskSpaceDirSize(snap.DataDir(t.snapst.InstanceName(), t.snapst.Current))
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		dataCopies += size
+	}
+	download, err = installSize(st, infos, userID, opts.PrereqTracker)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return snaps, download, dataCopies, nil
+}
+
+func simulateRemoveSpace(st *state.State, names []string) (snaps []string, snapshots uint64, err error) {
+	for _, name := range names {
+		var snapst SnapState
+		if err := Get(st, name, &snapst); err != nil {
+			if errors.Is(err, state.ErrNoState) {
+				return nil, 0, &snap.NotInstalledError{Snap: name}
+			}
+			return nil, 0, err
+		}
+		snaps = append(snaps, name)
+		// only app snaps get an automatic snapshot on removal
+		if tp, _ := snapst.Type(); tp != snap.TypeApp || EstimateSnapshotSize == nil {
+			continue
+		}
+		size, err := EstimateSnapshotSize(st, name, nil)
+		if err != nil {
+			return nil, 0, err
+		}
+		snapshots += size
+	}
+	return snaps, snapshots, nil
+}
+
+// dirSize returns the apparent size of the regular files under dir. A
+// missing directory has size zero.
+func dirSize(dir string) (uint64, error) {
+	var size uint64
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += uint64(info.Size())
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("cannot compute size of %q: %v", dir, err)
+	}
+	return size, nil
+}
+
+// existingAncestor returns path or its closest ancestor that exists.
+func existingAncestor(path string) string {
+	for {
+		if osutil.FileExists(path) {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
+
+// deviceOf returns the ID of the device holding the filesystem of path, or
+// of its closest existing ancestor.
+func deviceOf(path string) (uint64, error) {
+	var st syscall.Stat_t
+	if err := syscall.Stat(existingAncestor(path), &st); err != nil {
+		return 0, fmt.Errorf("cannot stat %q: %v", path, err)
+	}
+	return uint64(st.Dev), nil
+}
@@ -0,0 +1,195 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snapstate_test
+
+import (
+	"context"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/snapstate/snapstatetest"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/testutil"
+)
+
+func (s *snapmgrTestSuite) mockDiskSpace(c *C, free uint64, devices map[string]uint64) {
+	s.AddCleanup(snapstate.MockDiskSpaceHelpers(
+		func(path string) (uint64, error) {
+			return free, nil
+		},
+		func(dir string) (uint64, error) {
+			c.Check(dir, Equals, filepath.Join(dirs.SnapDataDir, "some-snap/7"))
+			return 42, nil
+		},
+		func(path string) (uint64, error) {
+			dev, ok := devices[path]
+			c.Check(ok, Equals, true, Commentf("unexpected path %q", path))
+			return dev, nil
+		},
+	))
+}
+
+func (s *snapmgrTestSuite) TestSimulateDiskSpaceInstall(c *C) {
+	s.mockDiskSpace(c, 1000, map[string]uint64{dirs.SnapBlobDir: 1})
+	s.AddCleanup(snapstate.MockInstallSize(func(st *state.State, snaps []snapstate.MinimalInstallInfo, userID int, prqt snapstate.PrereqTracker) (uint64, error) {
+		c.Assert(snaps, HasLen, 1)
+		c.Check(snaps[0].InstanceName(), Equals, "some-snap")
+		return 100, nil
+	}))
+	var checked uint64
+	s.AddCleanup(snapstate.MockOsutilCheckFreeSpace(func(path string, sz uint64) error {
+		c.Check(path, Equals, filepath.Join(dirs.GlobalRootDir, "/var/lib/snapd"))
+		checked = sz
+		return nil
+	}))
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	tr := config.NewTransaction(s.state)
+	tr.Set("core", "experimental.check-disk-space-install", true)
+	tr.Commit()
+
+	est, err := snapstate.SimulateDiskSpace(context.Background(), s.state, "install", []string{"some-snap"}, s.user.ID)
+	c.Assert(err, IsNil)
+	c.Check(est, DeepEquals, &snapstate.DiskSpaceEstimate{
+		Operation: "install",
+		Snaps:     []string{"some-snap"},
+		Filesystems: []snapstate.DiskSpaceUsage{{
+			Path:      dirs.SnapBlobDir,
+			Snaps:     100,
+			Required:  snapstate.SafetyMarginDiskSpace(100),
+			Available: 1000,
+		}},
+		CheckEnabled: true,
+	})
+	c.Check(checked, Equals, snapstate.SafetyMarginDiskSpace(100))
+	// nothing was installed
+	c.Check(s.state.Changes(), HasLen, 0)
+	var snapst snapstate.SnapState
+	c.Check(snapstate.Get(s.state, "some-snap", &snapst), testutil.ErrorIs, state.ErrNoState)
+}
+
+func (s *snapmgrTestSuite) TestSimulateDiskSpaceRefreshRefused(c *C) {
+	s.mockDiskSpace(c, 10, map[string]uint64{
+		dirs.SnapBlobDir: 1,
+		dirs.SnapDataDir: 2,
+	})
+	s.AddCleanup(snapstate.MockInstallSize(func(st *state.State, snaps []snapstate.MinimalInstallInfo, userID int, prqt snapstate.PrereqTracker) (uint64, error) {
+		return 123, nil
+	}))
+	s.AddCleanup(snapstate.MockOsutilCheckFreeSpace(func(path string, sz uint64) error {
+		c.Check(sz, Equals, snapstate.SafetyMarginDiskSpace(123))
+		return &osutil.NotEnoughDiskSpaceError{}
+	}))
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	tr := config.NewTransaction(s.state)
+	tr.Set("core", "experimental.check-disk-space-refresh", true)
+	tr.Commit()
+
+	snapstate.Set(s.state, "some-snap", &snapstate.SnapState{
+		Active: true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+			{RealName: "some-snap", SnapID: "some-snap-id", Revision: snap.R(7)},
+		}),
+		Current:  snap.R(7),
+		SnapType: "app",
+	})
+
+	est, err := snapstate.SimulateDiskSpace(context.Background(), s.state, "refresh", []string{"some-snap"}, s.user.ID)
+	c.Assert(err, IsNil)
+	c.Check(est.Snaps, DeepEquals, []string{"some-snap"})
+	c.Check(est.Filesystems, DeepEquals, []snapstate.DiskSpaceUsage{{
+		Path:      dirs.SnapBlobDir,
+		Snaps:     123,
+		Required:  snapstate.SafetyMarginDiskSpace(123),
+		Available: 10,
+	}, {
+		Path:       dirs.SnapDataDir,
+		DataCopies: 42,
+		Required:   snapstate.SafetyMarginDiskSpace(42),
+		Available:  10,
+	}})
+	c.Check(est.CheckEnabled, Equals, true)
+	c.Check(est.Refused, Equals, true)
+	c.Check(est.Error, Matches, `insufficient space in .* to perform "refresh" change for the following snaps: some-snap`)
+	c.Check(s.state.Changes(), HasLen, 0)
+}
+
+func (s *snapmgrTestSuite) TestSimulateDiskSpaceRemoveSameFilesystem(c *C) {
+	s.mockDiskSpace(c, 1000, map[string]uint64{dirs.SnapshotsDir: 1})
+	s.AddCleanup(snapstate.MockOsutilCheckFreeSpace(func(path string, sz uint64) error {
+		c.Fatalf("unexpected disk space check")
+		return nil
+	}))
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	snapstate.Set(s.state, "some-snap", &snapstate.SnapState{
+		Active:   true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{{RealName: "some-snap", Revision: snap.R(11)}}),
+		Current:  snap.R(11),
+		SnapType: "app",
+	})
+
+	// the check for remove is disabled by default
+	est, err := snapstate.SimulateDiskSpace(context.Background(), s.state, "remove", []string{"some-snap"}, 0)
+	c.Assert(err, IsNil)
+	c.Check(est, DeepEquals, &snapstate.DiskSpaceEstimate{
+		Operation: "remove",
+		Snaps:     []string{"some-snap"},
+		Filesystems: []snapstate.DiskSpaceUsage{{
+			Path:      dirs.SnapshotsDir,
+			Snapshots: 1,
+			Required:  snapstate.SafetyMarginDiskSpace(1),
+			Available: 1000,
+		}},
+	})
+}
+
+func (s *snapmgrTestSuite) TestSimulateDiskSpaceRemoveNotInstalled(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	_, err := snapstate.SimulateDiskSpace(context.Background(), s.state, "remove", []string{"some-snap"}, 0)
+	c.Check(err, ErrorMatches, `snap "some-snap" is not installed`)
+}
+
+func (s *snapmgrTestSuite) TestSimulateDiskSpaceErrors(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	_, err := snapstate.SimulateDiskSpace(context.Background(), s.state, "frobnicate", nil, 0)
+	c.Check(err, ErrorMatches, `cannot simulate disk space for unknown operation "frobnicate"`)
+	_, err = snapstate.SimulateDiskSpace(context.Background(), s.state, "install", nil, 0)
+	c.Check(err, ErrorMatches, `cannot simulate install without snap names`)
+	_, err = snapstate.SimulateDiskSpace(context.Background(), s.state, "remove", nil, 0)
+	c.Check(err, ErrorMatches, `cannot simulate remove without snap names`)
+}
@@ -0,0 +1,121 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snapstate
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/strutil"
+	"github.com/snapcore/snapd/timeutil"
+)
+
+// downloadWindow returns the schedule of the store.download-window option
+// and the minimal size of the downloads it restricts, or a nil schedule if
+// scheduled downloads can happen at any time.
+func downloadWindow(st *state.State) (schedule []*timeutil.Schedule, minSize int64, err error) {
+	tr := config.NewTransaction(st)
+
+	var window string
+	if err := tr.Get("core", "store.download-window", &window); err != nil && !config.IsNoOption(err) {
+		return nil, 0, err
+	}
+	if window == "" {
+		return nil, 0, nil
+	}
+	schedule, err = timeutil.ParseSchedule(window)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var minSizeStr string
+	if err := tr.Get("core", "store.download-window-min-size", &minSizeStr); err != nil && !config.IsNoOption(err) {
+		return nil, 0, err
+	}
+	if minSizeStr != "" {
+		minSize, err = strutil.ParseByteSize(minSizeStr)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+	return schedule, minSize, nil
+}
+
+// nextWindowStart returns when the earliest window of the schedule after
+// now starts.
+func nextWindowStart(schedule []*timeutil.Schedule, now time.Time) time.Time {
+	var start time.Time
+	for _, sched := range schedule {
+		window := sched.Next(now)
+		if start.IsZero() || window.Start.Before(start) {
+			start = window.Start
+		}
+	}
+	return start
+}
+
+// deferScheduledDownload returns a state.Retry error deferring the
+// scheduled download of the given size of the task to the next download
+// window, if it must not happen now. The caller should be holding the
+// state lock.
+func deferScheduledDownload(t *state.Task, size int64) error {
+	st := t.State()
+
+	schedule, minSize, err := downloadWindow(st)
+	if err != nil {
+		return fmt.Errorf("cannot get download window: %v", err)
+	}
+	// the size is not always known, such downloads are restricted too
+	if schedule == nil || (size > 0 && size < minSize) {
+		return nil
+	}
+	now := timeNow()
+	if timeutil.Includes(schedule, now) {
+		return nil
+	}
+
+	until := nextWindowStart(schedule, now)
+	st.Set("downloads-deferred-until", until)
+	t.Logf("Download deferred until %s: outside of the download window", until.Format(time.RFC3339))
+	return &state.Retry{After: until.Sub(now), Reason: "outside of the download window"}
+}
+
+// DownloadsDeferredUntil returns when the scheduled downloads that are
+// deferred because of the store.download-window option will start, or
+// the zero time if none are.
+// The caller should be holding the state lock.
+func (m *SnapManager) DownloadsDeferredUntil() (time.Time, error) {
+	schedule, _, err := downloadWindow(m.state)
+	if err != nil || schedule == nil {
+		return time.Time{}, err
+	}
+
+	var deferredUntil time.Time
+	if err := m.state.Get("downloads-deferred-until", &deferredUntil); err != nil && !errors.Is(err, state.ErrNoState) {
+		return time.Time{}, err
+	}
+	if deferredUntil.Before(timeNow()) {
+		return time.Time{}, nil
+	}
+	return deferredUntil, nil
+}
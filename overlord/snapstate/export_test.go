@@ -86,6 +86,12 @@ func MockMountPollInterval(intv time.Duration) (restore func()) {
 	return func() { mountPollInterval = old }
 }
 
+func MockAutoRefreshRateLimitPollInterval(intv time.Duration) (restore func()) {
+	old := autoRefreshRateLimitPollInterval
+	autoRefreshRateLimitPollInterval = intv
+	return func() { autoRefreshRateLimitPollInterval = old }
+}
+
 func MockRevisionDate(mock func(info *snap.Info) time.Time) (restore func()) {
 	old := revisionDate
 	if mock == nil {
@@ -107,6 +113,8 @@ func MockPrerequisitesRetryTimeout(d time.Duration) (restore func()) {
 	return func() { prerequisitesRetryTimeout = old }
 }
 
+var PrereqSnapsChannel = prereqSnapsChannel
+
 func MockOsutilEnsureSnapUserGroup(mock func(name string, id uint32, extraUsers bool) error) (restore func()) {
 	old := osutilEnsureSnapUserGroup
 	osutilEnsureSnapUserGroup = mock
@@ -150,8 +158,9 @@ func PreviousSideInfo(snapst *SnapState) *snap.SideInfo {
 
 // helpers
 var (
-	InstallSize          = installSize
-	ResealingTaskBlocked = resealingTaskBlocked
+	InstallSize             = installSize
+	ResealingTaskBlocked    = resealingTaskBlocked
+	TooManyDownloadsRunning = tooManyDownloadsRunning
 )
 
 func ResealingTaskKinds() []string {
@@ -199,6 +208,9 @@ var (
 
 	SoftCheckNothingRunningForRefresh     = softCheckNothingRunningForRefresh
 	HardEnsureNothingRunningDuringRefresh = hardEnsureNothingRunningDuringRefresh
+
+	ProcessFailedAutoRefresh = processFailedAutoRefresh
+	RefreshBackoffs          = refreshBackoffs
 )
 
 // cleanup
@@ -242,6 +254,12 @@ func (snapmgr *SnapManager) MockNextRefresh(when time.Time) {
 	snapmgr.autoRefresh.nextRefresh = when
 }
 
+var DeviceStaggerFraction = deviceStaggerFraction
+
+func MockDeviceSerial(f func(st *state.State) (*asserts.Serial, error)) (restore func()) {
+	return testutil.Mock(&DeviceSerial, f)
+}
+
 func MockLastRefreshSchedule(ar *autoRefresh, schedule string) {
 	ar.lastRefreshSchedule = schedule
 }
@@ -626,6 +644,10 @@ func (sts *snapInstallTaskSet) TaskSet() *state.TaskSet {
 	return sts.ts
 }
 
+func (sts *snapInstallTaskSet) PrerequisitesSync() *state.Task {
+	return sts.prerequisitesSync
+}
+
 type SnapInstallTaskSet = snapInstallTaskSet
 
 func NewSnapInstallTaskSetForTest(
@@ -658,3 +680,17 @@ func MockProcessDelayedSecurityBackendEffects(f func(st *state.State, lanes []in
 func (s *catalogRefresh) GetCatalogRefreshDelayWithDelta() time.Duration {
 	return s.catalogRefreshDelayWithDelta
 }
+
+func MockDiskSpaceHelpers(freeDiskSpace func(path string) (uint64, error), dirSize func(dir string) (uint64, error), deviceOf func(path string) (uint64, error)) (restore func()) {
+	oldFree := osutilFreeDiskSpace
+	oldDirSize := diskSpaceDirSize
+	oldDeviceOf := diskSpaceDeviceOf
+	osutilFreeDiskSpace = freeDiskSpace
+	diskSpaceDirSize = dirSize
+	diskSpaceDeviceOf = deviceOf
+	return func() {
+		osutilFreeDiskSpace = oldFree
+		diskSpaceDirSize = oldDirSize
+		diskSpaceDeviceOf = oldDeviceOf
+	}
+}
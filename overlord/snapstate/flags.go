@@ -73,6 +73,20 @@ type Flags struct {
 	// be disabled and manual aliases will be removed.
 	Prefer bool `json:"prefer,omitempty"`
 
+	// NoRestoreConnections is set to request that the manual connections
+	// of a previous installation of the snap, removed recently, are not
+	// restored when installing the snap.
+	NoRestoreConnections bool `json:"no-restore-connections,omitempty"`
+
+	// KeepConfig is set when removing a snap to request that its
+	// configuration is kept and restored on a future install of the snap.
+	KeepConfig bool `json:"keep-config,omitempty"`
+
+	// KeepConnections is set when removing a snap to request that its
+	// manual connections are kept, without expiring, to be restored on a
+	// future install of the snap.
+	KeepConnections bool `json:"keep-connections,omitempty"`
+
 	// Amend allows refreshing out of a snap unknown to the store
 	// and into one that is known.
 	Amend bool `json:"amend,omitempty"`
@@ -90,6 +90,16 @@ var EnsureSnapAbsentFromQuotaGroup = func(st *state.State, snap string) error {
 	panic("internal error: snapstate.EnsureSnapAbsentFromQuotaGroup is unset")
 }
 
+// MarkServicesNeedingRestart is a hook set by servicestate.
+var MarkServicesNeedingRestart = func(st *state.State, apps []*snap.AppInfo) error {
+	panic("internal error: snapstate.MarkServicesNeedingRestart is unset")
+}
+
+// ClearServicesNeedingRestart is a hook set by servicestate.
+var ClearServicesNeedingRestart = func(st *state.State, snapName string, apps []*snap.AppInfo) error {
+	panic("internal error: snapstate.ClearServicesNeedingRestart is unset")
+}
+
 var SecurityProfilesRemoveLate = func(snapName string, rev snap.Revision, typ snap.Type) error {
 	panic("internal error: snapstate.SecurityProfilesRemoveLate is unset")
 }
@@ -249,13 +259,13 @@ func sendOneInstallActionUnlocked(ctx context.Context, st *state.State, snaps St
 	return sendOneInstallAction(ctx, st, snaps, opts)
 }
 
-// autoRefreshRateLimited returns the rate limit of auto-refreshes or 0 if
-// there is no limit.
-func autoRefreshRateLimited(st *state.State) (rate int64) {
+// rateLimitOption returns the rate limit set by the given core option or 0
+// if there is no limit.
+func rateLimitOption(st *state.State, option string) (rate int64) {
 	tr := config.NewTransaction(st)
 
 	var rateLimit string
-	err := tr.Get("core", "refresh.rate-limit", &rateLimit)
+	err := tr.Get("core", option, &rateLimit)
 	if err != nil {
 		return 0
 	}
@@ -267,6 +277,46 @@ func autoRefreshRateLimited(st *state.State) (rate int64) {
 	return val
 }
 
+// autoRefreshRateLimited returns the rate limit of auto-refreshes or 0 if
+// there is no limit.
+func autoRefreshRateLimited(st *state.State) (rate int64) {
+	return rateLimitOption(st, "refresh.rate-limit")
+}
+
+// autoRefreshRateLimitPollInterval is how often in-flight auto-refresh
+// downloads pick up changes to refresh.rate-limit.
+var autoRefreshRateLimitPollInterval = 5 * time.Second
+
+// trackAutoRefreshRateLimit returns a rate limit for auto-refresh downloads,
+// starting at the given rate, that follows the changes to refresh.rate-limit
+// until stop is called.
+func trackAutoRefreshRateLimit(st *state.State, rate int64) (limit *store.DynamicRateLimit, stop func()) {
+	limit = store.NewDynamicRateLimit(rate)
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(autoRefreshRateLimitPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				st.Lock()
+				rate := autoRefreshRateLimited(st)
+				st.Unlock()
+				limit.Set(rate)
+			case <-done:
+				return
+			}
+		}
+	}()
+	return limit, func() { close(done) }
+}
+
+// totalDownloadRateLimited returns the rate limit of all the store
+// downloads together or 0 if there is no limit.
+func totalDownloadRateLimited(st *state.State) (rate int64) {
+	return rateLimitOption(st, "store.download-rate-limit")
+}
+
 func downloadSnapParams(st *state.State, t *state.Task) (*SnapSetup, StoreService, *auth.UserState, error) {
 	snapsup, err := TaskSnapSetup(t)
 	if err != nil {
@@ -310,7 +360,15 @@ func (m *SnapManager) doDownloadSnap(t *state.Task, tomb *tomb.Tomb) error {
 	if snapsup != nil && snapsup.IsAutoRefresh {
 		// NOTE rate is never negative
 		rate = autoRefreshRateLimited(st)
+		if err == nil {
+			var size int64
+			if snapsup.DownloadInfo != nil {
+				size = snapsup.DownloadInfo.Size
+			}
+			err = deferScheduledDownload(t, size)
+		}
 	}
+	totalRate := totalDownloadRateLimited(st)
 
 	if err == nil {
 		cloud, err = maybeCloudName(st)
@@ -333,8 +391,14 @@ func (m *SnapManager) doDownloadSnap(t *state.Task, tomb *tomb.Tomb) error {
 	dlOpts := &store.DownloadOptions{
 		Scheduled:           snapsup.IsAutoRefresh,
 		RateLimit:           rate,
+		TotalRateLimit:      totalRate,
 		LeavePartialOnError: true,
 	}
+	if snapsup.IsAutoRefresh {
+		var stop func()
+		dlOpts.DynamicRateLimit, stop = trackAutoRefreshRateLimit(st, rate)
+		defer stop()
+	}
 	if snapsup.DownloadInfo == nil {
 		vsets, err := EnforcedValidationSets(st)
 		if err != nil {
@@ -474,19 +538,32 @@ func (m *SnapManager) doPreDownloadSnap(t *state.Task, tomb *tomb.Tomb) error {
 		return err
 	}
 
+	var size int64
+	if snapsup.DownloadInfo != nil {
+		size = snapsup.DownloadInfo.Size
+	}
+	if err := deferScheduledDownload(t, size); err != nil {
+		return err
+	}
+
 	targetFn := snapsup.BlobPath()
+	rate := autoRefreshRateLimited(st)
 	dlOpts := &store.DownloadOptions{
 		// pre-downloads are only triggered in auto-refreshes
 		Scheduled:           true,
-		RateLimit:           autoRefreshRateLimited(st),
+		RateLimit:           rate,
+		TotalRateLimit:      totalDownloadRateLimited(st),
 		LeavePartialOnError: true,
 	}
 
 	perfTimings := state.TimingsForTask(t)
 	st.Unlock()
+	var stop func()
+	dlOpts.DynamicRateLimit, stop = trackAutoRefreshRateLimit(st, rate)
 	timings.Run(perfTimings, "pre-download", fmt.Sprintf("pre-download snap %q", snapsup.SnapName()), func(timings.Measurer) {
 		err = theStore.Download(tomb.Context(nil), snapsup.SnapName(), targetFn, snapsup.DownloadInfo, nil, user, dlOpts)
 	})
+	stop()
 	st.Lock()
 	if err != nil {
 		return err
@@ -1054,13 +1131,13 @@ func canDiscardMountNamespace(oldInfo *snap.Info) (yesNo bool, noReason string)
 		// the snap change its base, the mount namespace is guaranteed to be
 		// discarded. In practice it means that snaps should not rely on the
 		// content of its mount namespace to be preserved across refreshes.
-		// However services marked as 'endure' are not stopped during refresh
-		// and could be occupying the mount namespace, and newly started from
-		// new revision of the snap will join it (unless the snap changes its
-		// base).
-		if app.IsService() && app.RefreshMode == "endure" {
+		// However services marked as 'endure' or 'ignore-running' are not
+		// stopped during refresh and could be occupying the mount namespace,
+		// and newly started from new revision of the snap will join it (unless
+		// the snap changes its base).
+		if app.IsService() && (app.RefreshMode == "endure" || app.RefreshMode == "ignore-running") {
 			// TODO: we could try to check whether the service is actually running
-			return false, fmt.Sprintf("service %q uses endure refresh-mode", app.Name)
+			return false, fmt.Sprintf("service %q uses %s refresh-mode", app.Name, app.RefreshMode)
 		}
 	}
 
@@ -1669,6 +1746,154 @@ func (m *SnapManager) cleanupCopySnapData(t *state.Task, _ *tomb.Tomb) error {
 	return nil
 }
 
+// copyInstanceDataInfos returns the current infos of the snap instance the
+// task copies data to, and of the instance it copies data from, as well as
+// the data directory options to use.
+func copyInstanceDataInfos(t *state.Task) (newInfo, oldInfo *snap.Info, opts *dirs.SnapDirOptions, err error) {
+	st := t.State()
+	snapsup, snapst, err := snapSetupAndState(t)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var from string
+	if err := t.Get("from-instance", &from); err != nil {
+		return nil, nil, nil, err
+	}
+	var fromst SnapState
+	if err := Get(st, from, &fromst); err != nil {
+		return nil, nil, nil, err
+	}
+
+	newInfo, err = readInfo(snapsup.InstanceName(), snapsup.SideInfo, errorOnBroken)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	oldInfo, err = fromst.CurrentInfo()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	newOpts, err := getDirMigrationOpts(st, snapst, snapsup)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	oldOpts, err := getDirMigrationOpts(st, &fromst, nil)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	opts = oldOpts.getSnapDirOpts()
+	if *newOpts.getSnapDirOpts() != *opts {
+		return nil, nil, nil, fmt.Errorf("cannot copy data of snap %q to snap %q: instances use different data directory layouts", from, snapsup.InstanceName())
+	}
+	return newInfo, oldInfo, opts, nil
+}
+
+func (m *SnapManager) doCopyInstanceData(t *state.Task, _ *tomb.Tomb) error {
+	st := t.State()
+	st.Lock()
+	newInfo, oldInfo, opts, err := copyInstanceDataInfos(t)
+	st.Unlock()
+	if err != nil {
+		return err
+	}
+
+	pb := NewTaskProgressAdapterUnlocked(t)
+	if err := m.backend.CopyInstanceData(newInfo, oldInfo, opts, pb); err != nil {
+		return err
+	}
+
+	st.Lock()
+	defer st.Unlock()
+
+	var copyServices bool
+	if err := t.Get("copy-disabled-services", &copyServices); err != nil && !errors.Is(err, state.ErrNoState) {
+		return err
+	}
+	if !copyServices {
+		return nil
+	}
+
+	snapsup, snapst, err := snapSetupAndState(t)
+	if err != nil {
+		return err
+	}
+	var fromst SnapState
+	if err := Get(st, oldInfo.InstanceName(), &fromst); err != nil {
+		return err
+	}
+	// for undo
+	t.Set("old-last-active-disabled-services", snapst.LastActiveDisabledServices)
+	t.Set("old-last-active-disabled-user-services", snapst.LastActiveDisabledUserServices)
+
+	// the services of the other instance are stopped at this point, so the
+	// ones it had disabled are tracked as last active disabled services
+	snapst.LastActiveDisabledServices = fromst.LastActiveDisabledServices
+	snapst.LastActiveDisabledUserServices = fromst.LastActiveDisabledUserServices
+	Set(st, snapsup.InstanceName(), snapst)
+
+	return nil
+}
+
+func (m *SnapManager) undoCopyInstanceData(t *state.Task, _ *tomb.Tomb) error {
+	st := t.State()
+	st.Lock()
+	newInfo, oldInfo, opts, err := copyInstanceDataInfos(t)
+	if err != nil {
+		st.Unlock()
+		return err
+	}
+
+	if t.Has("old-last-active-disabled-services") || t.Has("old-last-active-disabled-user-services") {
+		snapsup, snapst, err := snapSetupAndState(t)
+		if err != nil {
+			st.Unlock()
+			return err
+		}
+		var oldLastActiveDisabledServices []string
+		var oldLastActiveDisabledUserServices map[int][]string
+		if err := t.Get("old-last-active-disabled-services", &oldLastActiveDisabledServices); err != nil && !errors.Is(err, state.ErrNoState) {
+			st.Unlock()
+			return err
+		}
+		if err := t.Get("old-last-active-disabled-user-services", &oldLastActiveDisabledUserServices); err != nil && !errors.Is(err, state.ErrNoState) {
+			st.Unlock()
+			return err
+		}
+		snapst.LastActiveDisabledServices = oldLastActiveDisabledServices
+		snapst.LastActiveDisabledUserServices = oldLastActiveDisabledUserServices
+		Set(st, snapsup.InstanceName(), snapst)
+	}
+	st.Unlock()
+
+	pb := NewTaskProgressAdapterUnlocked(t)
+	return m.backend.UndoCopyInstanceData(newInfo, oldInfo, opts, pb)
+}
+
+func (m *SnapManager) cleanupCopyInstanceData(t *state.Task, _ *tomb.Tomb) error {
+	st := t.State()
+	st.Lock()
+	defer st.Unlock()
+
+	if t.Status() != state.DoneStatus {
+		// it failed
+		return nil
+	}
+
+	snapsup, err := TaskSnapSetup(t)
+	if err != nil {
+		return err
+	}
+	info, err := readInfo(snapsup.InstanceName(), snapsup.SideInfo, 0)
+	if err != nil {
+		return err
+	}
+
+	// remove the data of the instance that was replaced
+	m.backend.ClearTrashedData(info)
+
+	return nil
+}
+
 // writeSeqFile writes the sequence file for failover handling
 func writeSeqFile(name string, snapst *SnapState) error {
 	p := filepath.Join(dirs.SnapSeqDir, name+".json")
@@ -2067,6 +2292,14 @@ func (m *SnapManager) doLinkSnap(t *state.Task, _ *tomb.Tomb) (retErr error) {
 		}
 	}
 
+	// Restore the configuration kept when the snap was last removed, if
+	// any, on first install.
+	if !isInstalled {
+		if err := restoreKeptConfig(t, snapsup); err != nil {
+			return err
+		}
+	}
+
 	if len(snapst.Sequence.Revisions) == 1 {
 		if err := m.createSnapCookie(st, snapsup.InstanceName()); err != nil {
 			return fmt.Errorf("cannot create snap cookie: %v", err)
@@ -2253,6 +2486,11 @@ func (m *SnapManager) finishTaskWithMaybeRestart(t *state.Task, status state.Sta
 
 	typ := restartPoss.info.Type()
 
+	// snap tools verify the snapd/core snap revision before re-exec'ing
+	// into it, record it before snapd restarts, or right away if the
+	// change does not involve a restart
+	m.maybeRecordReexecTargets(st, typ)
+
 	// If the type of the snap requesting this start is non-trivial that either
 	// means we are on Ubuntu Core and the type is a base/kernel/gadget which
 	// requires a reboot of the system, or that the type is snapd in which case
@@ -2272,6 +2510,20 @@ func (m *SnapManager) finishTaskWithMaybeRestart(t *state.Task, status state.Sta
 	return FinishTaskWithRestart(t, status, restart.RestartDaemon, nil)
 }
 
+// maybeRecordReexecTargets records the re-exec targets on classic after the
+// current revision of the snapd or core snap changed.
+func (m *SnapManager) maybeRecordReexecTargets(st *state.State, typ snap.Type) {
+	if m.preseed || !release.OnClassic {
+		return
+	}
+	if typ != snap.TypeOS && typ != snap.TypeSnapd {
+		return
+	}
+	if err := recordReexecTargets(st); err != nil {
+		logger.Noticef("cannot record re-exec targets: %v", err)
+	}
+}
+
 func daemonRestartReason(st *state.State, typ snap.Type) string {
 	if !((release.OnClassic && typ == snap.TypeOS) || typ == snap.TypeSnapd) {
 		// not interesting
@@ -2628,6 +2880,11 @@ func (m *SnapManager) undoLinkSnap(t *state.Task, _ *tomb.Tomb) error {
 		if err != nil {
 			return err
 		}
+		// and keep again the configuration restored from the previous
+		// installation, if any
+		if err := undoRestoreKeptConfig(t, snapsup); err != nil {
+			return err
+		}
 	}
 
 	pb := NewTaskProgressAdapterLocked(t)
@@ -2688,6 +2945,10 @@ func (m *SnapManager) undoLinkSnap(t *state.Task, _ *tomb.Tomb) error {
 		return m.finishTaskWithMaybeRestart(t, finalStatus, *restartPoss)
 	}
 
+	// the core snap is either back at its previous revision, which
+	// undoUnlinkCurrentSnap records, or gone if this was its first install
+	m.maybeRecordReexecTargets(st, newInfo.Type())
+
 	// If we are on classic and have no previous version of core
 	// we may have restarted from a distro package into the core
 	// snap. We need to undo that restart here. Instead of in
@@ -3094,6 +3355,21 @@ func (m *SnapManager) stopSnapServices(t *state.Task, _ *tomb.Tomb) (retErr erro
 	st.Lock()
 	defer st.Unlock()
 
+	// services with "refresh-mode: ignore-running" are not stopped and keep
+	// running the current revision across the refresh, until they are
+	// restarted
+	keptRunning := servicesKeptRunning(svcs, rmSvcs, disabledServices, stopReason)
+	if len(keptRunning) > 0 {
+		if err := MarkServicesNeedingRestart(st, keptRunning); err != nil {
+			return err
+		}
+		keptRunningNames := make([]string, 0, len(keptRunning))
+		for _, svc := range keptRunning {
+			keptRunningNames = append(keptRunningNames, svc.Name)
+		}
+		t.Set("kept-running-services", keptRunningNames)
+	}
+
 	// for undo
 	t.Set("old-last-active-disabled-services", snapst.LastActiveDisabledServices)
 	t.Set("old-last-active-disabled-user-services", snapst.LastActiveDisabledUserServices)
@@ -3128,6 +3404,28 @@ func (m *SnapManager) stopSnapServices(t *state.Task, _ *tomb.Tomb) (retErr erro
 	return nil
 }
 
+// servicesKeptRunning returns the enabled services which are not stopped
+// when refreshing because of their "ignore-running" refresh-mode.
+func servicesKeptRunning(svcs []*snap.AppInfo, rmSvcs map[string]*snap.AppInfo, disabledServices *wrappers.DisabledServices, stopReason snap.ServiceStopReason) []*snap.AppInfo {
+	if stopReason != snap.StopReasonRefresh {
+		return nil
+	}
+	var kept []*snap.AppInfo
+	for _, svc := range svcs {
+		if svc.RefreshMode != "ignore-running" {
+			continue
+		}
+		if _, removed := rmSvcs[svc.Name]; removed {
+			continue
+		}
+		if disabledServices != nil && svc.DaemonScope == snap.SystemDaemon && strutil.ListContains(disabledServices.SystemServices, svc.Name) {
+			continue
+		}
+		kept = append(kept, svc)
+	}
+	return kept
+}
+
 func (m *SnapManager) undoStopSnapServices(t *state.Task, _ *tomb.Tomb) error {
 	st := t.State()
 	st.Lock()
@@ -3167,6 +3465,23 @@ func (m *SnapManager) undoStopSnapServices(t *state.Task, _ *tomb.Tomb) error {
 		return err
 	}
 
+	// the services kept running are running the current revision again
+	var keptRunningNames []string
+	if err := t.Get("kept-running-services", &keptRunningNames); err != nil && !errors.Is(err, state.ErrNoState) {
+		return err
+	}
+	if len(keptRunningNames) > 0 {
+		keptRunning := make([]*snap.AppInfo, 0, len(keptRunningNames))
+		for _, name := range keptRunningNames {
+			if app := currentInfo.Apps[name]; app != nil {
+				keptRunning = append(keptRunning, app)
+			}
+		}
+		if err := ClearServicesNeedingRestart(st, snapsup.InstanceName(), keptRunning); err != nil {
+			return err
+		}
+	}
+
 	st.Unlock()
 	err = m.backend.StartServices(svcs, &disabledServices, progress.Null, perfTimings)
 	st.Lock()
@@ -3596,6 +3911,11 @@ func (m *SnapManager) doDiscardSnap(t *state.Task, _ *tomb.Tomb) error {
 			return err
 		}
 
+		if snapsup.KeepConfig {
+			if err := keepSnapConfig(st, snapsup); err != nil {
+				return err
+			}
+		}
 		// Remove configuration associated with this snap.
 		err = config.DeleteSnapConfig(st, snapsup.InstanceName())
 		if err != nil {
@@ -189,7 +189,11 @@ func (m *SnapManager) doDownloadComponent(t *state.Task, tomb *tomb.Tomb) error
 	var rate int64
 	if snapsup.IsAutoRefresh {
 		rate = autoRefreshRateLimited(st)
+		if err := deferScheduledDownload(t, compsup.DownloadInfo.Size); err != nil {
+			return err
+		}
 	}
+	totalRate := totalDownloadRateLimited(st)
 
 	target := compsup.BlobPath(snapsup.InstanceName())
 
@@ -203,8 +207,14 @@ func (m *SnapManager) doDownloadComponent(t *state.Task, tomb *tomb.Tomb) error
 		opts := &store.DownloadOptions{
 			Scheduled:           snapsup.IsAutoRefresh,
 			RateLimit:           rate,
+			TotalRateLimit:      totalRate,
 			LeavePartialOnError: true,
 		}
+		if snapsup.IsAutoRefresh {
+			var stop func()
+			opts.DynamicRateLimit, stop = trackAutoRefreshRateLimit(st, rate)
+			defer stop()
+		}
 
 		err = sto.Download(tomb.Context(nil), compRef, target, compsup.DownloadInfo, meter, user, opts)
 	})
@@ -21,6 +21,7 @@ package snapstate_test
 
 import (
 	"errors"
+	"fmt"
 	"path/filepath"
 	"time"
 
@@ -527,6 +528,195 @@ func (s *downloadSnapSuite) TestDoDownloadRateLimitedIntegration(c *C) {
 
 }
 
+func (s *downloadSnapSuite) TestDoDownloadRateLimitAppliedLive(c *C) {
+	s.AddCleanup(snapstate.MockAutoRefreshRateLimitPollInterval(time.Millisecond))
+
+	s.state.Lock()
+	tr := config.NewTransaction(s.state)
+	tr.Set("core", "refresh.rate-limit", "1234B")
+	tr.Commit()
+
+	t := s.state.NewTask("download-snap", "test")
+	t.Set("snap-setup", &snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{
+			RealName: "foo",
+			SnapID:   "foo-id",
+			Revision: snap.R(11),
+		},
+		DownloadInfo: &snap.DownloadInfo{
+			DownloadURL: "http://some-url.com/snap",
+		},
+		Flags: snapstate.Flags{
+			IsAutoRefresh: true,
+		},
+	})
+	s.state.NewChange("sample", "...").AddTask(t)
+
+	var rates []int64
+	s.fakeStore.downloadRateLimitCallback = func(limit *store.DynamicRateLimit) {
+		rates = append(rates, limit.Rate())
+
+		// change the limit while the download is in progress
+		s.state.Lock()
+		tr := config.NewTransaction(s.state)
+		tr.Set("core", "refresh.rate-limit", "2kB")
+		tr.Commit()
+		s.state.Unlock()
+
+		for i := 0; i < 5000 && limit.Rate() != 2000; i++ {
+			time.Sleep(time.Millisecond)
+		}
+		rates = append(rates, limit.Rate())
+	}
+
+	s.state.Unlock()
+
+	s.se.Ensure()
+	s.se.Wait()
+
+	c.Check(rates, DeepEquals, []int64{1234, 2000})
+	c.Assert(s.fakeStore.downloads, DeepEquals, []fakeDownload{
+		{
+			name:   "foo",
+			target: filepath.Join(dirs.SnapBlobDir, "foo_11.snap"),
+			opts: &store.DownloadOptions{
+				RateLimit:           1234,
+				Scheduled:           true,
+				LeavePartialOnError: true,
+			},
+		},
+	})
+}
+
+func (s *downloadSnapSuite) TestDoDownloadTotalRateLimited(c *C) {
+	s.state.Lock()
+
+	tr := config.NewTransaction(s.state)
+	tr.Set("core", "store.download-rate-limit", "2MB")
+	tr.Commit()
+
+	t := s.state.NewTask("download-snap", "test")
+	t.Set("snap-setup", &snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{
+			RealName: "foo",
+			SnapID:   "foo-id",
+			Revision: snap.R(11),
+		},
+		DownloadInfo: &snap.DownloadInfo{
+			DownloadURL: "http://some-url.com/snap",
+		},
+	})
+	s.state.NewChange("sample", "...").AddTask(t)
+
+	s.state.Unlock()
+
+	s.se.Ensure()
+	s.se.Wait()
+
+	// the limit applies to downloads that are not auto-refreshes too
+	c.Assert(s.fakeStore.downloads, DeepEquals, []fakeDownload{
+		{
+			name:   "foo",
+			target: filepath.Join(dirs.SnapBlobDir, "foo_11.snap"),
+			opts: &store.DownloadOptions{
+				TotalRateLimit:      2000000,
+				LeavePartialOnError: true,
+			},
+		},
+	})
+}
+
+// mockDownloadWindow sets a download window starting two hours from now and
+// returns now and when the window starts.
+func (s *downloadSnapSuite) mockDownloadWindow(c *C, minSize string) (now, start time.Time) {
+	now = time.Now()
+	s.AddCleanup(snapstate.MockTimeNow(func() time.Time { return now }))
+	start = now.Add(2 * time.Hour).Truncate(time.Minute)
+
+	tr := config.NewTransaction(s.state)
+	tr.Set("core", "store.download-window", fmt.Sprintf("%s-%s", start.Format("15:04"), start.Add(time.Hour).Format("15:04")))
+	if minSize != "" {
+		tr.Set("core", "store.download-window-min-size", minSize)
+	}
+	tr.Commit()
+	return now, start
+}
+
+func (s *downloadSnapSuite) addDownloadTask(c *C, isAutoRefresh bool, size int64) *state.Task {
+	t := s.state.NewTask("download-snap", "test")
+	t.Set("snap-setup", &snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{
+			RealName: "foo",
+			SnapID:   "foo-id",
+			Revision: snap.R(11),
+		},
+		DownloadInfo: &snap.DownloadInfo{
+			DownloadURL: "http://some-url.com/snap",
+			Size:        size,
+		},
+		Flags: snapstate.Flags{
+			IsAutoRefresh: isAutoRefresh,
+		},
+	})
+	s.state.NewChange("sample", "...").AddTask(t)
+	return t
+}
+
+func (s *downloadSnapSuite) TestDoDownloadDeferredToDownloadWindow(c *C) {
+	s.state.Lock()
+	_, start := s.mockDownloadWindow(c, "")
+	t := s.addDownloadTask(c, true, 1000)
+	s.state.Unlock()
+
+	s.se.Ensure()
+	s.se.Wait()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	c.Check(s.fakeStore.downloads, HasLen, 0)
+	c.Check(t.Status(), Equals, state.DoingStatus)
+	// retried when the window starts
+	c.Check(t.AtTime().Sub(start) < time.Second, Equals, true, Commentf("%v != %v", t.AtTime(), start))
+	c.Check(t.AtTime().Before(start), Equals, false)
+	c.Assert(t.Log(), HasLen, 1)
+	c.Check(t.Log()[0], Matches, `.* Download deferred until .*: outside of the download window`)
+
+	deferred, err := s.snapmgr.DownloadsDeferredUntil()
+	c.Assert(err, IsNil)
+	c.Check(deferred.Equal(start), Equals, true)
+}
+
+func (s *downloadSnapSuite) TestDoDownloadNotDeferred(c *C) {
+	for _, tc := range []struct {
+		isAutoRefresh bool
+		minSize       string
+		size          int64
+	}{
+		// the user asked for the download
+		{isAutoRefresh: false},
+		// the download is small enough
+		{isAutoRefresh: true, minSize: "100MB", size: 1000},
+	} {
+		s.fakeStore.downloads = nil
+		s.state.Lock()
+		s.mockDownloadWindow(c, tc.minSize)
+		t := s.addDownloadTask(c, tc.isAutoRefresh, tc.size)
+		s.state.Unlock()
+
+		s.se.Ensure()
+		s.se.Wait()
+
+		s.state.Lock()
+		c.Check(s.fakeStore.downloads, HasLen, 1)
+		c.Check(t.Status(), Equals, state.DoneStatus)
+		deferred, err := s.snapmgr.DownloadsDeferredUntil()
+		c.Assert(err, IsNil)
+		c.Check(deferred.IsZero(), Equals, true)
+		s.state.Unlock()
+	}
+}
+
 type testUndoDownloadSnapFileCorruptedScenario int
 
 const (
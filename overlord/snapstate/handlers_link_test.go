@@ -584,6 +584,25 @@ func (s *linkSnapSuite) TestDoUnlinkCurrentSnapWithServicesModeEndure(c *C) {
 	})
 }
 
+func (s *linkSnapSuite) TestDoUnlinkCurrentSnapWithServicesModeIgnoreRunning(c *C) {
+	// like endure, the service is kept running and the namespace kept
+	s.testDoUnlinkCurrentSnapWithAppsOrServices(c, testDoUnlinkCurrentSnapWithServicesOpts{
+		apps: []*snap.AppInfo{
+			{Name: "app"},
+			{Name: "service", Daemon: "simple", RefreshMode: "ignore-running"},
+		},
+		expectedOps: fakeOps{{
+			op:          "run-inhibit-snap-for-unlink",
+			name:        "pkg",
+			inhibitHint: "refresh",
+		}, {
+			op:          "unlink-snap",
+			path:        filepath.Join(dirs.SnapMountDir, "pkg/42"),
+			inhibitHint: "refresh",
+		}},
+	})
+}
+
 func (s *linkSnapSuite) TestDoUnlinkCurrentSnapOnlyServicesAllStopped(c *C) {
 	s.testDoUnlinkCurrentSnapWithAppsOrServices(c, testDoUnlinkCurrentSnapWithServicesOpts{
 		apps: []*snap.AppInfo{
@@ -1342,6 +1361,53 @@ func (s *linkSnapSuite) TestDoLinkSnapSuccessCoreRestarts(c *C) {
 	c.Check(t.Log()[0], Matches, `.*INFO Requested daemon restart\.`)
 }
 
+func (s *linkSnapSuite) TestDoLinkSnapSuccessSnapdRecordsReexecTargetOnClassic(c *C) {
+	restore := release.MockOnClassic(true)
+	defer restore()
+
+	restore = testutil.Mock(&snapstate.SnapRevisionDigest, func(st *state.State, snapID string, rev snap.Revision) (string, error) {
+		c.Check(snapID, Equals, "snapd-snap-id")
+		c.Check(rev, Equals, snap.R(22))
+		return "snapd-digest", nil
+	})
+	defer restore()
+
+	c.Assert(os.MkdirAll(dirs.SnapBlobDir, 0755), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(dirs.SnapBlobDir, "snapd_22.snap"), []byte("snapd"), 0644), IsNil)
+
+	s.state.Lock()
+	si := &snap.SideInfo{
+		RealName: "snapd",
+		SnapID:   "snapd-snap-id",
+		Revision: snap.R(22),
+	}
+	t := s.state.NewTask("link-snap", "test")
+	t.Set("snap-setup", &snapstate.SnapSetup{
+		SideInfo: si,
+		Type:     snap.TypeSnapd,
+	})
+	s.state.NewChange("sample", "...").AddTask(t)
+
+	s.state.Unlock()
+
+	s.se.Ensure()
+	s.se.Wait()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	c.Check(t.Status(), Equals, state.DoneStatus)
+	c.Check(s.restartRequested, DeepEquals, []restart.RestartType{restart.RestartDaemon})
+
+	// the new revision was recorded before restarting
+	targets, err := snapdtool.ReadReexecTargets()
+	c.Assert(err, IsNil)
+	c.Assert(targets["snapd"], NotNil)
+	c.Check(targets["snapd"].Revision, Equals, "22")
+	c.Check(targets["snapd"].SnapSHA3_384, Equals, "snapd-digest")
+	c.Check(targets["snapd"].Size, Equals, int64(5))
+}
+
 func (s *linkSnapSuite) TestDoLinkSnapSuccessSnapdRestartsOnCoreWithBase(c *C) {
 	restore := release.MockOnClassic(false)
 	defer restore()
@@ -1728,6 +1794,55 @@ func (s *linkSnapSuite) TestDoLinkSnapSuccessCoreAndSnapdNoCoreRestart(c *C) {
 	c.Check(t.Log(), HasLen, 0)
 }
 
+func (s *linkSnapSuite) TestDoLinkSnapSuccessCoreAndSnapdRecordsReexecTargetsOnClassic(c *C) {
+	restore := release.MockOnClassic(true)
+	defer restore()
+
+	c.Assert(os.MkdirAll(dirs.SnapBlobDir, 0755), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(dirs.SnapBlobDir, "snapd_64.snap"), []byte("snapd"), 0644), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(dirs.SnapBlobDir, "core_33.snap"), []byte("core"), 0644), IsNil)
+
+	s.state.Lock()
+	siSnapd := &snap.SideInfo{
+		RealName: "snapd",
+		Revision: snap.R(64),
+	}
+	snapstate.Set(s.state, "snapd", &snapstate.SnapState{
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{siSnapd}),
+		Current:  siSnapd.Revision,
+		Active:   true,
+		SnapType: "snapd",
+	})
+
+	si := &snap.SideInfo{
+		RealName: "core",
+		Revision: snap.R(33),
+	}
+	t := s.state.NewTask("link-snap", "test")
+	t.Set("snap-setup", &snapstate.SnapSetup{
+		SideInfo: si,
+	})
+	s.state.NewChange("sample", "...").AddTask(t)
+
+	s.state.Unlock()
+
+	s.se.Ensure()
+	s.se.Wait()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	c.Check(t.Status(), Equals, state.DoneStatus)
+	c.Check(s.restartRequested, IsNil)
+
+	// the core snap was recorded even though snapd does not restart
+	targets, err := snapdtool.ReadReexecTargets()
+	c.Assert(err, IsNil)
+	c.Assert(targets, HasLen, 2)
+	c.Check(targets["core"].Revision, Equals, "33")
+	c.Check(targets["snapd"].Revision, Equals, "64")
+}
+
 func (s *linkSnapSuite) TestDoLinkSnapdSnapCleanupOnErrorFirstInstall(c *C) {
 	s.state.Lock()
 	defer s.state.Unlock()
@@ -2340,6 +2455,105 @@ func (s *linkSnapSuite) TestDoUndoLinkSnapCoreClassic(c *C) {
 
 }
 
+func (s *linkSnapSuite) TestDoUndoLinkSnapCoreClassicForgetsReexecTarget(c *C) {
+	restore := release.MockOnClassic(true)
+	defer restore()
+
+	c.Assert(os.MkdirAll(dirs.SnapBlobDir, 0755), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(dirs.SnapBlobDir, "core_1.snap"), []byte("core"), 0644), IsNil)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	si1 := &snap.SideInfo{
+		RealName: "core",
+		Revision: snap.R(1),
+	}
+	t := s.state.NewTask("link-snap", "test")
+	t.Set("snap-setup", &snapstate.SnapSetup{
+		SideInfo: si1,
+	})
+	chg := s.state.NewChange("sample", "...")
+	chg.AddTask(t)
+
+	terr := s.state.NewTask("error-trigger", "provoking total undo")
+	terr.WaitFor(t)
+	chg.AddTask(terr)
+
+	s.state.Unlock()
+
+	for i := 0; i < 3; i++ {
+		s.se.Ensure()
+		s.se.Wait()
+	}
+
+	s.state.Lock()
+	c.Check(t.Status(), Equals, state.UndoneStatus)
+
+	// the core snap is gone and so is its re-exec target
+	targets, err := snapdtool.ReadReexecTargets()
+	c.Assert(err, IsNil)
+	c.Check(targets, HasLen, 0)
+}
+
+func (s *linkSnapSuite) TestUndoLinkSnapdNthInstallRecordsReexecTargetOnClassic(c *C) {
+	restore := release.MockOnClassic(true)
+	defer restore()
+
+	restore = testutil.Mock(&snapstate.SnapRevisionDigest, func(st *state.State, snapID string, rev snap.Revision) (string, error) {
+		return fmt.Sprintf("snapd-digest-%s", rev), nil
+	})
+	defer restore()
+
+	c.Assert(os.MkdirAll(dirs.SnapBlobDir, 0755), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(dirs.SnapBlobDir, "snapd_20.snap"), []byte("old"), 0644), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(dirs.SnapBlobDir, "snapd_22.snap"), []byte("new"), 0644), IsNil)
+
+	s.state.Lock()
+	si := &snap.SideInfo{
+		RealName: "snapd",
+		SnapID:   "snapd-snap-id",
+		Revision: snap.R(22),
+	}
+	siOld := *si
+	siOld.Revision = snap.R(20)
+	snapstate.Set(s.state, "snapd", &snapstate.SnapState{
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{&siOld}),
+		Current:  siOld.Revision,
+		Active:   true,
+		SnapType: "snapd",
+	})
+	chg := s.state.NewChange("sample", "...")
+	t := s.state.NewTask("link-snap", "test")
+	t.Set("snap-setup", &snapstate.SnapSetup{
+		SideInfo: si,
+		Type:     snap.TypeSnapd,
+	})
+	chg.AddTask(t)
+	terr := s.state.NewTask("error-trigger", "provoking total undo")
+	terr.WaitFor(t)
+	chg.AddTask(terr)
+
+	s.state.Unlock()
+
+	for i := 0; i < 6; i++ {
+		s.se.Ensure()
+		s.se.Wait()
+	}
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	c.Check(t.Status(), Equals, state.UndoneStatus)
+
+	// the previous revision is recorded again before snapd restarts into it
+	targets, err := snapdtool.ReadReexecTargets()
+	c.Assert(err, IsNil)
+	c.Assert(targets["snapd"], NotNil)
+	c.Check(targets["snapd"].Revision, Equals, "20")
+	c.Check(targets["snapd"].SnapSHA3_384, Equals, "snapd-digest-20")
+}
+
 func (s *linkSnapSuite) TestLinkSnapInjectsAutoConnectIfMissing(c *C) {
 	si1 := &snap.SideInfo{
 		RealName: "snap1",
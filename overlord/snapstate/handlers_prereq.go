@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"sort"
 	"strings"
 	"time"
 
@@ -32,6 +33,7 @@ import (
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/release"
 	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/channel"
 	"github.com/snapcore/snapd/timings"
 	"gopkg.in/tomb.v2"
 )
@@ -119,7 +121,7 @@ func (m *SnapManager) doPrerequisites(t *state.Task, _ *tomb.Tomb) error {
 		base = snapsup.Base
 	}
 
-	return installPrereqs(t, base, snapsup.PrereqContentAttrs, tm, Options{
+	return installPrereqs(t, base, snapsup.PrereqContentAttrs, snapsup.PrereqTracks, tm, Options{
 		Flags:     flags,
 		UserID:    snapsup.UserID,
 		DeviceCtx: dctx,
@@ -156,7 +158,19 @@ func defaultPrereqSnapsChannel() string {
 	return channel
 }
 
-func installPrereqs(t *state.Task, base string, prereq map[string][]string, tm timings.Measurer, opts Options) error {
+// prereqSnapsChannel returns the channel to install a default provider from,
+// honoring the track preferred by the snap needing it, if any. A track set via
+// the environment takes precedence over the preferred one.
+func prereqSnapsChannel(track string) string {
+	ch := defaultPrereqSnapsChannel()
+	resolved, err := channel.ResolvePinned(track, ch)
+	if err != nil {
+		return ch
+	}
+	return resolved
+}
+
+func installPrereqs(t *state.Task, base string, prereq map[string][]string, tracks map[string]string, tm timings.Measurer, opts Options) error {
 	st := t.State()
 
 	// We try to install all wanted snaps. If one snap cannot be installed
@@ -170,7 +184,7 @@ func installPrereqs(t *state.Task, base string, prereq map[string][]string, tm t
 			ts, err = ensurePrerequisite(t, contentAttrs, StoreSnap{
 				InstanceName: prereqName,
 				RevOpts: RevisionOptions{
-					Channel: defaultPrereqSnapsChannel(),
+					Channel: prereqSnapsChannel(tracks[prereqName]),
 				},
 			}, opts)
 		})
@@ -368,10 +382,21 @@ func ensurePrerequisite(t *state.Task, contentAttrs []string, sn StoreSnap, opts
 		return nil, &state.Retry{After: prerequisitesRetryTimeout}
 	}
 
+	installedName := sn.InstanceName
 	installed, err := isInstalled(st, sn.InstanceName)
 	if err != nil {
 		return nil, err
 	}
+	isContentProvider := !opts.Flags.RequireTypeBase && sn.InstanceName != "snapd"
+	if !installed && isContentProvider {
+		// a content provider installed as a parallel instance provides the
+		// content just as well
+		installedName, err = installedProviderInstance(st, sn.InstanceName)
+		if err != nil {
+			return nil, err
+		}
+		installed = installedName != ""
+	}
 
 	var ts *state.TaskSet
 	if !installed {
@@ -396,7 +421,7 @@ func ensurePrerequisite(t *state.Task, contentAttrs []string, sn StoreSnap, opts
 			// that case, we proceed without it.
 			return nil, nil
 		}
-		ts, err = maybeUpdateContentProvider(t, sn.InstanceName, contentAttrs, opts)
+		ts, err = maybeUpdateContentProvider(t, installedName, contentAttrs, opts)
 	}
 	if err != nil {
 		var cerr *ChangeConflictError
@@ -503,6 +528,35 @@ func isInstalled(st *state.State, snapName string) (bool, error) {
 	return snapState.IsInstalled(), nil
 }
 
+// installedProviderInstance returns the instance name of an installed
+// instance of the given default provider snap, preferring the one without an
+// instance key. An empty string is returned if no instance is installed.
+func installedProviderInstance(st *state.State, snapName string) (string, error) {
+	installed, err := isInstalled(st, snapName)
+	if err != nil {
+		return "", err
+	}
+	if installed {
+		return snapName, nil
+	}
+
+	all, err := All(st)
+	if err != nil {
+		return "", err
+	}
+	var instances []string
+	for instanceName, snapst := range all {
+		if snap.InstanceSnap(instanceName) == snapName && snapst.IsInstalled() {
+			instances = append(instances, instanceName)
+		}
+	}
+	if len(instances) == 0 {
+		return "", nil
+	}
+	sort.Strings(instances)
+	return instances[0], nil
+}
+
 func prereqError(what, snapName string, err error) error {
 	if _, ok := err.(*state.Retry); ok {
 		return err
@@ -32,6 +32,7 @@ import (
 	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/overlord/configstate/config"
 	"github.com/snapcore/snapd/overlord/ifacestate/ifacerepo"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/snapstate/sequence"
@@ -39,6 +40,7 @@ import (
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/release"
 	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
 	"github.com/snapcore/snapd/store"
 	"github.com/snapcore/snapd/store/storetest"
 	"github.com/snapcore/snapd/testutil"
@@ -1417,6 +1419,143 @@ func (s *prereqSuite) TestPreReqContentAttrsRefreshKeepsTrackedChannel(c *C) {
 	c.Check(op.action.Channel, Equals, "latest/edge")
 }
 
+func (s *prereqSuite) TestDoPrereqHonorsProviderTrack(c *C) {
+	s.state.Lock()
+
+	// install snapd so that prerequisites handler won't try to install it
+	snapstate.Set(s.state, "snapd", &snapstate.SnapState{
+		Active: true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+			{RealName: "snapd", Revision: snap.R(1)},
+		}),
+		Current:  snap.R(1),
+		SnapType: "snapd",
+	})
+
+	t := s.state.NewTask("prerequisites", "test")
+	t.Set("snap-setup", &snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{
+			RealName: "foo",
+			Revision: snap.R(33),
+		},
+		Base:               "none",
+		PrereqContentAttrs: map[string][]string{"prereq1": {"some-content"}, "prereq2": {"other-content"}},
+		PrereqTracks:       map[string]string{"prereq1": "2"},
+	})
+	chg := s.state.NewChange("sample", "...")
+	chg.AddTask(t)
+	s.state.Unlock()
+
+	s.se.Ensure()
+	s.se.Wait()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Assert(s.fakeBackend.ops, testutil.DeepUnsortedMatches, fakeOps{
+		{
+			op: "storesvc-snap-action",
+		},
+		{
+			op: "storesvc-snap-action:action",
+			action: store.SnapAction{
+				Action:       "install",
+				InstanceName: "prereq1",
+				Channel:      "2/stable",
+			},
+			revno: snap.R(11),
+		},
+		{
+			op: "storesvc-snap-action",
+		},
+		{
+			op: "storesvc-snap-action:action",
+			action: store.SnapAction{
+				Action:       "install",
+				InstanceName: "prereq2",
+				Channel:      "stable",
+			},
+			revno: snap.R(11),
+		},
+	})
+	c.Check(t.Status(), Equals, state.DoneStatus)
+}
+
+func (s *prereqSuite) TestDoPrereqProviderTrackWithChannelEnvvar(c *C) {
+	os.Setenv("SNAPD_PREREQS_CHANNEL", "candidate")
+	defer os.Unsetenv("SNAPD_PREREQS_CHANNEL")
+
+	c.Check(snapstate.PrereqSnapsChannel(""), Equals, "candidate")
+	c.Check(snapstate.PrereqSnapsChannel("2"), Equals, "2/candidate")
+
+	// a track set in the environment wins over the preferred one
+	os.Setenv("SNAPD_PREREQS_CHANNEL", "3/edge")
+	c.Check(snapstate.PrereqSnapsChannel("2"), Equals, "3/edge")
+}
+
+func (s *prereqSuite) TestPreReqContentAttrsParallelInstanceProvider(c *C) {
+	snapstate.AutoAliases = func(*state.State, *snap.Info) (map[string]string, error) {
+		return nil, nil
+	}
+	s.AddCleanup(func() { snapstate.AutoAliases = nil })
+
+	st := s.state
+	st.Lock()
+
+	tr := config.NewTransaction(st)
+	tr.Set("core", "experimental.parallel-instances", true)
+	tr.Commit()
+
+	// the default-provider is only installed as a parallel instance
+	si := &snap.SideInfo{RealName: "some-snap", SnapID: "some-snap-id", Revision: snap.R(1)}
+	snaptest.MockSnapInstance(c, "some-snap_instance", `name: some-snap`, si)
+	snapstate.Set(st, "some-snap_instance", &snapstate.SnapState{
+		Active:          true,
+		Sequence:        snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{si}),
+		Current:         si.Revision,
+		SnapType:        "app",
+		TrackingChannel: "latest/stable",
+		InstanceKey:     "instance",
+	})
+
+	snapstate.Set(st, "snapd", &snapstate.SnapState{
+		Active: true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+			{RealName: "snapd", Revision: snap.R(1)},
+		}),
+		Current:  snap.R(1),
+		SnapType: "snapd",
+	})
+
+	t := st.NewTask("prerequisites", "test")
+	t.Set("snap-setup", &snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{
+			RealName: "foo",
+			Revision: snap.R(33),
+		},
+		Base:               "none",
+		PrereqContentAttrs: map[string][]string{"some-snap": {"this-does-not-match"}},
+		PrereqTracks:       map[string]string{"some-snap": "2"},
+	})
+	chg := st.NewChange("sample", "...")
+	chg.AddTask(t)
+	st.Unlock()
+
+	s.se.Ensure()
+	s.se.Wait()
+
+	st.Lock()
+	defer st.Unlock()
+
+	// the installed instance is refreshed rather than installing the snap
+	// again, and it keeps tracking its channel
+	c.Assert(chg.Err(), IsNil)
+	c.Assert(s.fakeBackend.ops.Count("storesvc-snap-action:action"), Equals, 1)
+	op := s.fakeBackend.ops.MustFindOp(c, "storesvc-snap-action:action")
+	c.Check(op.action.InstanceName, Equals, "some-snap_instance")
+	c.Check(op.action.Action, Equals, "refresh")
+	c.Check(op.action.Channel, Equals, "latest/stable")
+}
+
 func (s *prereqSuite) TestPreReqContentAttrsNotSatisfiedSeeding(c *C) {
 	snapstate.AutoAliases = func(*state.State, *snap.Info) (map[string]string, error) {
 		return nil, nil
@@ -0,0 +1,240 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snapstate
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+)
+
+// otherInstances returns the states of the two given instances of the same
+// snap, which must both be installed unless toMayBeMissing is set.
+func otherInstances(st *state.State, from, to string, toMayBeMissing bool) (fromst, tost *SnapState, err error) {
+	for _, name := range []string{from, to} {
+		if err := snap.ValidateInstanceName(name); err != nil {
+			return nil, nil, err
+		}
+	}
+	if from == to {
+		return nil, nil, fmt.Errorf("cannot use snap %q as both source and target instance", from)
+	}
+	if snap.InstanceSnap(from) != snap.InstanceSnap(to) {
+		return nil, nil, fmt.Errorf("cannot use %q and %q: not instances of the same snap", from, to)
+	}
+
+	fromst = &SnapState{}
+	if err := Get(st, from, fromst); err != nil && !errors.Is(err, state.ErrNoState) {
+		return nil, nil, err
+	}
+	if !fromst.IsInstalled() {
+		return nil, nil, &snap.NotInstalledError{Snap: from}
+	}
+
+	tost = &SnapState{}
+	if err := Get(st, to, tost); err != nil && !errors.Is(err, state.ErrNoState) {
+		return nil, nil, err
+	}
+	if !tost.IsInstalled() && !toMayBeMissing {
+		return nil, nil, &snap.NotInstalledError{Snap: to}
+	}
+
+	return fromst, tost, nil
+}
+
+func instanceSnapSetup(snapst *SnapState) (*SnapSetup, error) {
+	info, err := snapst.CurrentInfo()
+	if err != nil {
+		return nil, err
+	}
+	return &SnapSetup{
+		SideInfo:    snapst.CurrentSideInfo(),
+		Type:        info.Type(),
+		InstanceKey: snapst.InstanceKey,
+	}, nil
+}
+
+// CopyInstanceData returns a set of tasks for replacing the data of the snap
+// instance "to" with a copy of the data of the snap instance "from", another
+// instance of the same snap. The services of both instances are stopped while
+// the data is copied.
+func CopyInstanceData(st *state.State, from, to string) (*state.TaskSet, error) {
+	fromst, tost, err := otherInstances(st, from, to, false)
+	if err != nil {
+		return nil, err
+	}
+	if !fromst.Active || !tost.Active {
+		return nil, fmt.Errorf("cannot copy data of snap %q to snap %q: both instances must be enabled", from, to)
+	}
+	if fromst.MigratedHidden != tost.MigratedHidden || fromst.MigratedToExposedHome != tost.MigratedToExposedHome {
+		return nil, fmt.Errorf("cannot copy data of snap %q to snap %q: instances use different data directory layouts", from, to)
+	}
+	if err := CheckChangeConflictMany(st, []string{from, to}, ""); err != nil {
+		return nil, err
+	}
+
+	fromsup, err := instanceSnapSetup(fromst)
+	if err != nil {
+		return nil, err
+	}
+	tosup, err := instanceSnapSetup(tost)
+	if err != nil {
+		return nil, err
+	}
+
+	stopFrom := st.NewTask("stop-snap-services", fmt.Sprintf(i18n.G("Stop snap %q services"), from))
+	stopFrom.Set("snap-setup", fromsup)
+	stopTo := st.NewTask("stop-snap-services", fmt.Sprintf(i18n.G("Stop snap %q services"), to))
+	stopTo.Set("snap-setup", tosup)
+	stopTo.WaitFor(stopFrom)
+
+	copyData := st.NewTask("copy-instance-data", fmt.Sprintf(i18n.G("Copy data of snap %q to snap %q"), from, to))
+	copyData.Set("snap-setup-task", stopTo.ID())
+	copyData.Set("from-instance", from)
+	copyData.WaitFor(stopTo)
+
+	startTo := st.NewTask("start-snap-services", fmt.Sprintf(i18n.G("Start snap %q (%s) services"), to, tost.Current))
+	startTo.Set("snap-setup-task", stopTo.ID())
+	startTo.WaitFor(copyData)
+	startFrom := st.NewTask("start-snap-services", fmt.Sprintf(i18n.G("Start snap %q (%s) services"), from, fromst.Current))
+	startFrom.Set("snap-setup-task", stopFrom.ID())
+	startFrom.WaitFor(startTo)
+
+	return state.NewTaskSet(stopFrom, stopTo, copyData, startTo, startFrom), nil
+}
+
+// RenameInstance returns a set of tasks for renaming the snap instance "from"
+// to "to", which must be a not yet installed instance of the same snap. The
+// current revision of "from" is installed as "to", its data is copied over
+// and its services state, connections and manual aliases are recreated
+// before "from" is removed. Older revisions of "from" are not carried over.
+func RenameInstance(st *state.State, from, to string) ([]*state.TaskSet, error) {
+	fromst, tost, err := otherInstances(st, from, to, true)
+	if err != nil {
+		return nil, err
+	}
+	if tost.IsInstalled() {
+		return nil, fmt.Errorf("cannot rename snap %q to %q: snap %q is already installed", from, to, to)
+	}
+	if !fromst.Active {
+		return nil, fmt.Errorf("cannot rename snap %q to %q: snap %q is disabled", from, to, from)
+	}
+	// TODO: support renaming instances with components
+	if len(fromst.Sequence.ComponentsForRevision(fromst.Current)) != 0 {
+		return nil, fmt.Errorf("cannot rename snap %q to %q: snaps with components are not supported yet", from, to)
+	}
+	if err := CheckChangeConflictMany(st, []string{from, to}, ""); err != nil {
+		return nil, err
+	}
+
+	fromsup, err := instanceSnapSetup(fromst)
+	if err != nil {
+		return nil, err
+	}
+
+	stopFrom := st.NewTask("stop-snap-services", fmt.Sprintf(i18n.G("Stop snap %q services"), from))
+	stopFrom.Set("snap-setup", fromsup)
+	stopTs := state.NewTaskSet(stopFrom)
+
+	si := *fromst.CurrentSideInfo()
+	flags := fromst.Flags
+	// the new instance takes over the aliases of the old one
+	flags.Prefer = !fromst.AutoAliasesDisabled
+	installTs, err := InstallPath(st, &si, snap.MountFile(from, si.Revision), to, fromst.TrackingChannel, flags, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := addCopyInstanceDataTask(st, installTs, from, to); err != nil {
+		return nil, err
+	}
+	installTs.WaitAll(stopTs)
+
+	copyConns := st.NewTask("copy-instance-connections", fmt.Sprintf(i18n.G("Copy connections of snap %q to snap %q"), from, to))
+	_, toKey := snap.SplitInstanceName(to)
+	copyConns.Set("snap-setup", &SnapSetup{
+		SideInfo:    &si,
+		Type:        fromsup.Type,
+		InstanceKey: toKey,
+	})
+	copyConns.Set("from-instance", from)
+	copyConns.WaitAll(installTs)
+	copyConnsTs := state.NewTaskSet(copyConns)
+
+	removeTs, err := Remove(st, from, snap.R(0), &RemoveFlags{Purge: true})
+	if err != nil {
+		return nil, err
+	}
+	removeTs.WaitAll(copyConnsTs)
+
+	tss := []*state.TaskSet{stopTs, installTs, copyConnsTs, removeTs}
+
+	manualAliases := make([]string, 0, len(fromst.Aliases))
+	for alias, target := range fromst.Aliases {
+		if target.Manual != "" {
+			manualAliases = append(manualAliases, alias)
+		}
+	}
+	sort.Strings(manualAliases)
+	prev := removeTs
+	for _, alias := range manualAliases {
+		aliasTs := state.NewTaskSet(manualAliasTask(st, to, fromst.Aliases[alias].Manual, alias))
+		aliasTs.WaitAll(prev)
+		tss = append(tss, aliasTs)
+		prev = aliasTs
+	}
+
+	return tss, nil
+}
+
+// addCopyInstanceDataTask adds to the given install task set a task copying
+// the data of the snap instance "from" right after the new instance "to" is
+// linked, before its hooks run and its services are started.
+func addCopyInstanceDataTask(st *state.State, ts *state.TaskSet, from, to string) error {
+	var linkSnap *state.Task
+	for _, t := range ts.Tasks() {
+		if t.Kind() == "link-snap" {
+			linkSnap = t
+			break
+		}
+	}
+	if linkSnap == nil {
+		return fmt.Errorf("internal error: cannot find link-snap task of snap %q", to)
+	}
+	var snapsupTask string
+	if err := linkSnap.Get("snap-setup-task", &snapsupTask); err != nil {
+		return err
+	}
+
+	copyData := st.NewTask("copy-instance-data", fmt.Sprintf(i18n.G("Copy data of snap %q to snap %q"), from, to))
+	copyData.Set("snap-setup-task", snapsupTask)
+	copyData.Set("from-instance", from)
+	// also carry over which services were disabled
+	copyData.Set("copy-disabled-services", true)
+	for _, t := range linkSnap.HaltTasks() {
+		t.WaitFor(copyData)
+	}
+	copyData.WaitFor(linkSnap)
+	ts.AddTask(copyData)
+	return nil
+}
@@ -0,0 +1,279 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snapstate_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/snapstate/snapstatetest"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/testutil"
+)
+
+func (s *snapmgrTestSuite) mockInstances(c *C) {
+	tr := config.NewTransaction(s.state)
+	tr.Set("core", "experimental.parallel-instances", true)
+	tr.Commit()
+
+	for _, inst := range []struct {
+		key string
+		rev snap.Revision
+	}{{"foo", snap.R(7)}, {"bar", snap.R(8)}} {
+		si := &snap.SideInfo{RealName: "some-snap", SnapID: "some-snap-id", Revision: inst.rev}
+		snapstate.Set(s.state, "some-snap_"+inst.key, &snapstate.SnapState{
+			Active:          true,
+			Sequence:        snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{si}),
+			Current:         si.Revision,
+			SnapType:        "app",
+			InstanceKey:     inst.key,
+			TrackingChannel: "latest/stable",
+		})
+	}
+}
+
+func (s *snapmgrTestSuite) TestCopyInstanceDataTasks(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.mockInstances(c)
+
+	ts, err := snapstate.CopyInstanceData(s.state, "some-snap_foo", "some-snap_bar")
+	c.Assert(err, IsNil)
+	chg := s.state.NewChange("copy-instance-data", "...")
+	chg.AddAll(ts)
+
+	tasks := ts.Tasks()
+	c.Assert(taskKinds(tasks), DeepEquals, []string{
+		"stop-snap-services",
+		"stop-snap-services",
+		"copy-instance-data",
+		"start-snap-services",
+		"start-snap-services",
+	})
+	for i, t := range tasks[1:] {
+		c.Check(t.WaitTasks(), DeepEquals, []*state.Task{tasks[i]})
+	}
+
+	for i, name := range []string{"some-snap_foo", "some-snap_bar", "some-snap_bar", "some-snap_bar", "some-snap_foo"} {
+		snapsup, err := snapstate.TaskSnapSetup(tasks[i])
+		c.Assert(err, IsNil)
+		c.Check(snapsup.InstanceName(), Equals, name)
+	}
+	var from string
+	c.Assert(tasks[2].Get("from-instance", &from), IsNil)
+	c.Check(from, Equals, "some-snap_foo")
+	c.Check(tasks[2].Has("copy-disabled-services"), Equals, false)
+}
+
+func (s *snapmgrTestSuite) TestCopyInstanceDataErrors(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.mockInstances(c)
+
+	for _, tc := range []struct {
+		from, to string
+		err      string
+	}{
+		{"some-snap_foo", "some-snap_foo", `cannot use snap "some-snap_foo" as both source and target instance`},
+		{"some-snap_foo", "other-snap_bar", `cannot use "some-snap_foo" and "other-snap_bar": not instances of the same snap`},
+		{"some-snap_foo", "some-snap_baz", `snap "some-snap_baz" is not installed`},
+		{"some-snap_baz", "some-snap_foo", `snap "some-snap_baz" is not installed`},
+		{"some-snap_foo", "some-snap_-invalid", `invalid instance key: "-invalid"`},
+	} {
+		_, err := snapstate.CopyInstanceData(s.state, tc.from, tc.to)
+		c.Check(err, ErrorMatches, tc.err, Commentf("%s -> %s", tc.from, tc.to))
+	}
+
+	var snapst snapstate.SnapState
+	c.Assert(snapstate.Get(s.state, "some-snap_bar", &snapst), IsNil)
+	snapst.MigratedHidden = true
+	snapstate.Set(s.state, "some-snap_bar", &snapst)
+	_, err := snapstate.CopyInstanceData(s.state, "some-snap_foo", "some-snap_bar")
+	c.Check(err, ErrorMatches, `cannot copy data of snap "some-snap_foo" to snap "some-snap_bar": instances use different data directory layouts`)
+}
+
+func (s *snapmgrTestSuite) TestCopyInstanceDataRunThrough(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.mockInstances(c)
+
+	chg := s.state.NewChange("copy-instance-data", "...")
+	ts, err := snapstate.CopyInstanceData(s.state, "some-snap_foo", "some-snap_bar")
+	c.Assert(err, IsNil)
+	chg.AddAll(ts)
+
+	s.settle(c)
+
+	c.Assert(chg.Err(), IsNil)
+	c.Check(chg.Status(), Equals, state.DoneStatus)
+	c.Check(s.fakeBackend.ops.First("copy-instance-data"), DeepEquals, &fakeOp{
+		op:   "copy-instance-data",
+		path: filepath.Join(dirs.SnapMountDir, "some-snap_bar/8"),
+		old:  filepath.Join(dirs.SnapMountDir, "some-snap_foo/7"),
+	})
+	c.Check(s.fakeBackend.ops.First("undo-copy-instance-data"), IsNil)
+}
+
+func (s *snapmgrTestSuite) TestCopyInstanceDataUndo(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.mockInstances(c)
+
+	chg := s.state.NewChange("copy-instance-data", "...")
+	ts, err := snapstate.CopyInstanceData(s.state, "some-snap_foo", "some-snap_bar")
+	c.Assert(err, IsNil)
+	chg.AddAll(ts)
+
+	terr := s.state.NewTask("error-trigger", "provoking total undo")
+	terr.WaitAll(ts)
+	chg.AddTask(terr)
+
+	s.settle(c)
+
+	c.Check(chg.Status(), Equals, state.ErrorStatus)
+	for _, t := range ts.Tasks() {
+		c.Check(t.Status(), Equals, state.UndoneStatus, Commentf("%s", t.Summary()))
+	}
+	c.Check(s.fakeBackend.ops.First("undo-copy-instance-data"), DeepEquals, &fakeOp{
+		op:   "undo-copy-instance-data",
+		path: filepath.Join(dirs.SnapMountDir, "some-snap_bar/8"),
+		old:  filepath.Join(dirs.SnapMountDir, "some-snap_foo/7"),
+	})
+}
+
+func (s *snapmgrTestSuite) TestRenameInstanceTasks(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.mockInstances(c)
+	var snapst snapstate.SnapState
+	c.Assert(snapstate.Get(s.state, "some-snap_foo", &snapst), IsNil)
+	snapst.Aliases = map[string]*snapstate.AliasTarget{
+		"alias1": {Manual: "cmd1"},
+		"alias2": {Auto: "cmd2"},
+	}
+	snapstate.Set(s.state, "some-snap_foo", &snapst)
+	mountFile := snap.MountFile("some-snap_foo", snap.R(7))
+	c.Assert(os.MkdirAll(filepath.Dir(mountFile), 0755), IsNil)
+	c.Assert(os.Rename(makeTestSnap(c, "name: some-snap\nversion: 1.0"), mountFile), IsNil)
+
+	tss, err := snapstate.RenameInstance(s.state, "some-snap_foo", "some-snap_baz")
+	c.Assert(err, IsNil)
+	c.Assert(tss, HasLen, 5)
+	chg := s.state.NewChange("rename-instance", "...")
+	for _, ts := range tss {
+		chg.AddAll(ts)
+	}
+
+	// services of the renamed instance are stopped first
+	c.Check(taskKinds(tss[0].Tasks()), DeepEquals, []string{"stop-snap-services"})
+
+	// the current revision is installed as the new instance, with the data
+	// copied right after it is linked
+	var linkSnap, copyData *state.Task
+	for _, t := range tss[1].Tasks() {
+		switch t.Kind() {
+		case "link-snap":
+			linkSnap = t
+		case "copy-instance-data":
+			copyData = t
+		}
+	}
+	c.Assert(linkSnap, NotNil)
+	c.Assert(copyData, NotNil)
+	c.Check(copyData.WaitTasks(), DeepEquals, []*state.Task{linkSnap, tss[0].Tasks()[0]})
+	for _, t := range linkSnap.HaltTasks() {
+		if t != copyData {
+			c.Check(t.WaitTasks(), testutil.Contains, copyData)
+		}
+	}
+	snapsup, err := snapstate.TaskSnapSetup(copyData)
+	c.Assert(err, IsNil)
+	c.Check(snapsup.InstanceName(), Equals, "some-snap_baz")
+	c.Check(snapsup.Revision(), Equals, snap.R(7))
+	c.Check(snapsup.Flags.Prefer, Equals, true)
+	c.Check(snapsup.SnapPath, Equals, snap.MountFile("some-snap_foo", snap.R(7)))
+	var copyServices bool
+	c.Assert(copyData.Get("copy-disabled-services", &copyServices), IsNil)
+	c.Check(copyServices, Equals, true)
+
+	// then connections are copied
+	c.Check(taskKinds(tss[2].Tasks()), DeepEquals, []string{"copy-instance-connections"})
+	var from string
+	c.Assert(tss[2].Tasks()[0].Get("from-instance", &from), IsNil)
+	c.Check(from, Equals, "some-snap_foo")
+
+	// then the old instance is removed
+	c.Check(tss[3].Tasks()[0].Kind(), Equals, "stop-snap-services")
+	snapsup, err = snapstate.TaskSnapSetup(tss[3].Tasks()[0])
+	c.Assert(err, IsNil)
+	c.Check(snapsup.InstanceName(), Equals, "some-snap_foo")
+
+	// and its manual aliases recreated
+	aliasTasks := tss[4].Tasks()
+	c.Assert(taskKinds(aliasTasks), DeepEquals, []string{"alias"})
+	var alias, target string
+	c.Assert(aliasTasks[0].Get("alias", &alias), IsNil)
+	c.Assert(aliasTasks[0].Get("target", &target), IsNil)
+	c.Check(alias, Equals, "alias1")
+	c.Check(target, Equals, "cmd1")
+	snapsup, err = snapstate.TaskSnapSetup(aliasTasks[0])
+	c.Assert(err, IsNil)
+	c.Check(snapsup.InstanceName(), Equals, "some-snap_baz")
+
+	// the task sets are chained
+	c.Check(tss[1].Tasks()[0].WaitTasks(), testutil.Contains, tss[0].Tasks()[0])
+	c.Check(tss[2].Tasks()[0].WaitTasks(), testutil.Contains, copyData)
+	c.Check(tss[3].Tasks()[0].WaitTasks(), DeepEquals, tss[2].Tasks())
+	c.Check(aliasTasks[0].WaitTasks(), testutil.Contains, tss[3].Tasks()[len(tss[3].Tasks())-1])
+}
+
+func (s *snapmgrTestSuite) TestRenameInstanceErrors(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.mockInstances(c)
+
+	_, err := snapstate.RenameInstance(s.state, "some-snap_foo", "some-snap_bar")
+	c.Check(err, ErrorMatches, `cannot rename snap "some-snap_foo" to "some-snap_bar": snap "some-snap_bar" is already installed`)
+
+	_, err = snapstate.RenameInstance(s.state, "some-snap_baz", "some-snap_qux")
+	c.Check(err, ErrorMatches, `snap "some-snap_baz" is not installed`)
+
+	_, err = snapstate.RenameInstance(s.state, "some-snap_foo", "other-snap_foo")
+	c.Check(err, ErrorMatches, `cannot use "some-snap_foo" and "other-snap_foo": not instances of the same snap`)
+
+	var snapst snapstate.SnapState
+	c.Assert(snapstate.Get(s.state, "some-snap_foo", &snapst), IsNil)
+	snapst.Active = false
+	snapstate.Set(s.state, "some-snap_foo", &snapst)
+	_, err = snapstate.RenameInstance(s.state, "some-snap_foo", "some-snap_baz")
+	c.Check(err, ErrorMatches, `cannot rename snap "some-snap_foo" to "some-snap_baz": snap "some-snap_foo" is disabled`)
+}
@@ -0,0 +1,172 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snapstate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/strutil"
+)
+
+// keptConfig is the configuration of a snap removed with the KeepConfig
+// flag, stored under "kept-configs" in the state until the snap is
+// installed again.
+type keptConfig struct {
+	SnapID   string           `json:"snap-id,omitempty"`
+	Revision snap.Revision    `json:"revision"`
+	KeptAt   time.Time        `json:"kept-at"`
+	Config   *json.RawMessage `json:"config"`
+}
+
+func getKeptConfigs(st *state.State) (map[string]*keptConfig, error) {
+	var kept map[string]*keptConfig
+	if err := st.Get("kept-configs", &kept); err != nil && !errors.Is(err, state.ErrNoState) {
+		return nil, fmt.Errorf("cannot get kept snap configurations: %v", err)
+	}
+	if kept == nil {
+		kept = make(map[string]*keptConfig)
+	}
+	return kept, nil
+}
+
+func setKeptConfigs(st *state.State, kept map[string]*keptConfig) {
+	if len(kept) == 0 {
+		st.Set("kept-configs", nil)
+		return
+	}
+	st.Set("kept-configs", kept)
+}
+
+// keepSnapConfig stores the configuration of the snap being removed so
+// that it can be restored when the snap is installed again.
+func keepSnapConfig(st *state.State, snapsup *SnapSetup) error {
+	snapcfg, err := config.GetSnapConfig(st, snapsup.InstanceName())
+	if err != nil {
+		return err
+	}
+	if snapcfg == nil {
+		// nothing to keep
+		return nil
+	}
+	kept, err := getKeptConfigs(st)
+	if err != nil {
+		return err
+	}
+	kept[snapsup.InstanceName()] = &keptConfig{
+		SnapID:   snapsup.SideInfo.SnapID,
+		Revision: snapsup.Revision(),
+		KeptAt:   timeNow(),
+		Config:   snapcfg,
+	}
+	setKeptConfigs(st, kept)
+	return nil
+}
+
+// restoreKeptConfig sets the configuration kept when the snap was last
+// removed, if any, as the configuration of the snap being installed. The
+// kept configuration is remembered in the task for undo.
+func restoreKeptConfig(t *state.Task, snapsup *SnapSetup) error {
+	st := t.State()
+	kept, err := getKeptConfigs(st)
+	if err != nil {
+		return err
+	}
+	instanceName := snapsup.InstanceName()
+	kc := kept[instanceName]
+	if kc == nil {
+		return nil
+	}
+	if kc.SnapID != snapsup.SideInfo.SnapID {
+		// keep it around for an install of the right snap
+		t.Logf("Not restoring configuration of the previous installation of snap %q: snap ID mismatch", instanceName)
+		return nil
+	}
+	if err := config.SetSnapConfig(st, instanceName, kc.Config); err != nil {
+		return err
+	}
+	delete(kept, instanceName)
+	setKeptConfigs(st, kept)
+	t.Set("kept-config", kc)
+	t.Logf("Restored configuration of the previous installation of snap %q", instanceName)
+	return nil
+}
+
+// undoRestoreKeptConfig puts back the configuration restored by
+// restoreKeptConfig into the kept configurations.
+func undoRestoreKeptConfig(t *state.Task, snapsup *SnapSetup) error {
+	var kc keptConfig
+	if err := t.Get("kept-config", &kc); err != nil {
+		if errors.Is(err, state.ErrNoState) {
+			return nil
+		}
+		return err
+	}
+	st := t.State()
+	kept, err := getKeptConfigs(st)
+	if err != nil {
+		return err
+	}
+	kept[snapsup.InstanceName()] = &kc
+	setKeptConfigs(st, kept)
+	return nil
+}
+
+// KeptConfigs returns the configurations of removed snaps that are kept
+// to be restored when the snaps are installed again, limited to the
+// given snaps if non-empty.
+func KeptConfigs(st *state.State, snapNames []string) ([]client.KeptConfig, error) {
+	kept, err := getKeptConfigs(st)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]client.KeptConfig, 0, len(kept))
+	for name, kc := range kept {
+		if len(snapNames) > 0 && !strutil.ListContains(snapNames, name) {
+			continue
+		}
+		var values map[string]*json.RawMessage
+		if kc.Config != nil {
+			if err := json.Unmarshal(*kc.Config, &values); err != nil {
+				return nil, fmt.Errorf("cannot unmarshal kept configuration of snap %q: %v", name, err)
+			}
+		}
+		keys := make([]string, 0, len(values))
+		for k := range values {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		infos = append(infos, client.KeptConfig{
+			Snap:     name,
+			Revision: kc.Revision,
+			Time:     kc.KeptAt,
+			Keys:     keys,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Snap < infos[j].Snap })
+	return infos, nil
+}
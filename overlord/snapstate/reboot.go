@@ -177,7 +177,12 @@ func waitForIfNeeded(waiter, target *state.Task) {
 //	|
 //	boot-base -> gadget -> kernel (post-reboot tasks, everything after link-snap)
 //	|
-//	non-essential bases and apps
+//	non-essential bases and apps (from the prerequisites synchronization task)
+//
+// Everything but snapd itself waits on snapd. Otherwise, the
+// before-local-modifications phase of every snap, e.g. downloading it, is not
+// ordered against the other snaps, so the downloads of a refresh run in
+// parallel.
 //
 // Seed refresh adds a phase before create-recovery-system. The seed creation
 // task waits on every snap's initial prerequisites task, because those tasks
@@ -431,9 +436,11 @@ func arrangeRebootAndUpdateSeed(
 			return head(sts.beforeLocalSystemModificationsTasks)
 		}
 
-		// in the absence of a seed-refresh, the first post-essential tasks
-		// should simply be the first task of each snap's chain of tasks.
-		return sts.prerequisites
+		// in the absence of a seed-refresh, the before-local-modifications
+		// phase of every snap, which downloads it, runs in parallel with the
+		// essential snaps and the bases. only the synchronization task, which
+		// comes right before the snap is mounted, waits on those.
+		return sts.prerequisitesSync
 	}
 
 	// make the bases just wait on the final essential snap to finish up
@@ -75,6 +75,7 @@ func (s *rebootSuite) snapInstallTaskSetForSnapSetup(snapName, base string, snap
 	prepareSnap.Set("snap-setup", snapsup)
 	prepareSnap.WaitFor(prereq)
 	prereqSync := s.state.NewTask("prerequisites", "...")
+	prereqSync.Set("prerequisites-sync", true)
 	prereqSync.WaitFor(prepareSnap)
 	mountSnap := s.state.NewTask("mount-snap", "...")
 	mountSnap.WaitFor(prereqSync)
@@ -357,13 +358,17 @@ func (s *rebootSuite) TestSetEssentialSnapsRestartBoundariesUC20(c *C) {
 	c.Check(s.hasRestartBoundaries(c, stss[4].TaskSet()), Equals, false)
 }
 
-func (s *rebootSuite) setDependsOn(c *C, ts, dep *state.TaskSet) bool {
-	firstTaskOfTS, err := ts.Edge(snapstate.BeginEdge)
+// setDependsOn checks whether the snap of sts waits on dep before it is
+// mounted. Snaps are downloaded independently from each other, so the first
+// task of sts must not wait on dep.
+func (s *rebootSuite) setDependsOn(c *C, sts snapstate.SnapInstallTaskSet, dep *state.TaskSet) bool {
+	firstTaskOfTS, err := sts.TaskSet().Edge(snapstate.BeginEdge)
 	c.Assert(err, IsNil)
 	lastTaskOfDep, err := dep.Edge(snapstate.EndEdge)
 	c.Assert(err, IsNil)
 
-	for _, wt := range firstTaskOfTS.WaitTasks() {
+	c.Check(firstTaskOfTS.WaitTasks(), Not(testutil.Contains), lastTaskOfDep)
+	for _, wt := range sts.PrerequisitesSync().WaitTasks() {
 		if wt == lastTaskOfDep {
 			return true
 		}
@@ -1226,9 +1231,9 @@ func (s *rebootSuite) TestArrangeSnapInstallTaskSetsForSnapWithBaseAndWithout(c
 
 	// snap-base-app depends on snap-base, but snap-other-app's base
 	// is not updated
-	c.Check(s.setDependsOn(c, stss[1].TaskSet(), stss[0].TaskSet()), Equals, true)
-	c.Check(s.setDependsOn(c, stss[2].TaskSet(), stss[0].TaskSet()), Equals, false)
-	c.Check(s.setDependsOn(c, stss[2].TaskSet(), stss[1].TaskSet()), Equals, false)
+	c.Check(s.setDependsOn(c, stss[1], stss[0].TaskSet()), Equals, true)
+	c.Check(s.setDependsOn(c, stss[2], stss[0].TaskSet()), Equals, false)
+	c.Check(s.setDependsOn(c, stss[2], stss[1].TaskSet()), Equals, false)
 }
 
 func (s *rebootSuite) TestArrangeSnapInstallTaskSetsForSnapWithBootBaseAndWithout(c *C) {
@@ -1264,9 +1269,9 @@ func (s *rebootSuite) TestArrangeSnapInstallTaskSetsForSnapWithBootBaseAndWithou
 	// snap-core20-app depends on core20, but snap-other-app' base is
 	// not updated. Yet snap-other-base still depends on core20. But there
 	// is no dependency between snap-core20-app and snap-other-app
-	c.Check(s.setDependsOn(c, stss[1].TaskSet(), stss[0].TaskSet()), Equals, true)  // snap-core20-app depend on core20
-	c.Check(s.setDependsOn(c, stss[2].TaskSet(), stss[0].TaskSet()), Equals, true)  // snap-other-app depend on core20
-	c.Check(s.setDependsOn(c, stss[2].TaskSet(), stss[1].TaskSet()), Equals, false) // snap-other-app does not depend on snap-core20-app
+	c.Check(s.setDependsOn(c, stss[1], stss[0].TaskSet()), Equals, true)  // snap-core20-app depend on core20
+	c.Check(s.setDependsOn(c, stss[2], stss[0].TaskSet()), Equals, true)  // snap-other-app depend on core20
+	c.Check(s.setDependsOn(c, stss[2], stss[1].TaskSet()), Equals, false) // snap-other-app does not depend on snap-core20-app
 }
 
 func (s *rebootSuite) TestArrangeSnapInstallTaskSetsAll(c *C) {
@@ -26,12 +26,14 @@ import (
 	"time"
 
 	"github.com/snapcore/snapd/features"
+	"github.com/snapcore/snapd/i18n"
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/overlord/auth"
 	"github.com/snapcore/snapd/overlord/configstate/config"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/overlord/swfeats"
 	"github.com/snapcore/snapd/release"
+	"github.com/snapcore/snapd/snap/channel"
 	"github.com/snapcore/snapd/store"
 	"github.com/snapcore/snapd/strutil"
 	"github.com/snapcore/snapd/timings"
@@ -39,6 +41,8 @@ import (
 
 var refreshHintsDelay = time.Duration(24 * time.Hour)
 
+var migrateEndOfLifeTrackChangeKind = swfeats.RegisterChangeKind("switch-snap")
+
 func init() {
 	swfeats.RegisterEnsure("SnapManager", "refreshHints.Ensure")
 }
@@ -113,9 +117,95 @@ func (r *refreshHints) refresh() error {
 	// update candidates in state dropping all entries which are not part of
 	// the new hints
 	updateRefreshCandidates(r.state, hints, nil)
+
+	return handleEndOfLifeTracks(r.state, plan)
+}
+
+func canMigrateEndOfLifeTracks(st *state.State) (bool, error) {
+	tr := config.NewTransaction(st)
+	var migrate bool
+	err := tr.GetMaybe("core", "refresh.migrate-eol-tracks", &migrate)
+	if err != nil && !errors.Is(err, state.ErrNoState) {
+		return false, err
+	}
+	return migrate, nil
+}
+
+// handleEndOfLifeTracks looks for snaps in the update plan that track a
+// track which reached its end of life. Those are switched to the successor
+// track if the refresh.migrate-eol-tracks option allows it, otherwise a
+// warning is emitted. Note that the store sends the tracks metadata only
+// along with the snaps it has updates for.
+func handleEndOfLifeTracks(st *state.State, plan updatePlan) error {
+	migrate, err := canMigrateEndOfLifeTracks(st)
+	if err != nil {
+		return err
+	}
+
+	now := timeNow()
+	for _, t := range plan.targets {
+		if len(t.info.TrackInfos) == 0 || t.snapst.TrackingChannel == "" {
+			continue
+		}
+		tracking, err := channel.ParseVerbatim(t.snapst.TrackingChannel, "-")
+		if err != nil {
+			logger.Noticef("cannot parse tracking channel of snap %q: %v", t.info.InstanceName(), err)
+			continue
+		}
+		track := tracking.Track
+		if track == "" {
+			track = "latest"
+		}
+		trackInfo := t.info.TrackInfos[track]
+		if trackInfo == nil || !trackInfo.EndOfLifeReached(now) {
+			continue
+		}
+
+		instanceName := t.info.InstanceName()
+		successor := trackInfo.Successor
+		if successor == "" || successor == track {
+			st.Warnf("snap %q is tracking %q which reached its end of life on %s", instanceName, track, trackInfo.EndOfLife.Format(time.RFC3339))
+			continue
+		}
+		risk := tracking.Risk
+		if risk == "" {
+			risk = "stable"
+		}
+		successorChannel := successor + "/" + risk
+		if !migrate {
+			st.Warnf("snap %q is tracking %q which reached its end of life on %s, consider switching to %q with: snap refresh --channel=%s %s",
+				instanceName, track, trackInfo.EndOfLife.Format(time.RFC3339), successor, successorChannel, instanceName)
+			continue
+		}
+		if err := migrateEndOfLifeTrack(st, instanceName, track, successorChannel); err != nil {
+			logger.Noticef("cannot switch snap %q away from end-of-life track %q: %v", instanceName, track, err)
+			st.Warnf("cannot switch snap %q from end-of-life track %q to %q: %v", instanceName, track, successor, err)
+		}
+	}
 	return nil
 }
 
+// migrateEndOfLifeTrack switches the given snap to the successor channel of
+// its end-of-life track and records a snap-track-migration notice about it.
+func migrateEndOfLifeTrack(st *state.State, instanceName, track, successorChannel string) error {
+	ts, err := Switch(st, instanceName, &RevisionOptions{Channel: successorChannel}, nil)
+	if err != nil {
+		return err
+	}
+	msg := fmt.Sprintf(i18n.G("Switch snap %q from end-of-life track %q to channel %q"), instanceName, track, successorChannel)
+	chg := st.NewChange(migrateEndOfLifeTrackChangeKind, msg)
+	chg.AddAll(ts)
+
+	_, err = st.AddNotice(nil, state.SnapTrackMigrationNotice, instanceName, &state.AddNoticeOptions{
+		Data: map[string]string{
+			"old-track":   track,
+			"new-channel": successorChannel,
+			"change-id":   chg.ID(),
+		},
+	})
+	return err
+}
+
 // AtSeed configures hints refresh policies at end of seeding.
 func (r *refreshHints) AtSeed() error {
 	// on classic hold hints refreshes for a full 24h
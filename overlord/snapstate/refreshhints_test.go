@@ -798,3 +798,153 @@ func (s *refreshHintsTestSuite) TestUpdateCandidatesDiscriminating(c *C) {
 	c.Check(hints["bar"].SideInfo.Revision, Equals, snap.R(1))
 	c.Check(hints["bar"].Monitored, Equals, true)
 }
+
+func (s *refreshHintsTestSuite) mockEndOfLifeTrack(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	repo := interfaces.NewRepository()
+	for _, iface := range builtin.Interfaces() {
+		err := repo.AddInterface(iface)
+		c.Assert(err, IsNil)
+	}
+	ifacerepo.Replace(s.state, repo)
+
+	si := &snap.SideInfo{RealName: "some-snap", Revision: snap.R(5), SnapID: "some-snap-id"}
+	snaptest.MockSnap(c, "name: some-snap\nversion: 1.0\n", si)
+	snapstate.Set(s.state, "some-snap", &snapstate.SnapState{
+		Active:          true,
+		Sequence:        snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{si}),
+		Current:         snap.R(5),
+		SnapType:        "app",
+		TrackingChannel: "9/candidate",
+	})
+
+	s.store.refreshedSnaps = []*snap.Info{{
+		Architectures: []string{"all"},
+		SnapType:      snap.TypeApp,
+		SideInfo: snap.SideInfo{
+			RealName: "some-snap",
+			Revision: snap.R(6),
+			SnapID:   "some-snap-id",
+		},
+		TrackInfos: map[string]*snap.TrackInfo{
+			"9": {
+				EndOfLife: time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC),
+				Successor: "10",
+			},
+			"10": {
+				EndOfLife: time.Date(2026, 4, 30, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}}
+
+	restore := snapstate.MockTimeNow(func() time.Time {
+		return time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	})
+	s.AddCleanup(restore)
+}
+
+func (s *refreshHintsTestSuite) TestRefreshHintsWarnsAboutEndOfLifeTrack(c *C) {
+	s.mockEndOfLifeTrack(c)
+
+	rh := snapstate.NewRefreshHints(s.state)
+	c.Assert(rh.Ensure(), IsNil)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	warnings := s.state.AllWarnings()
+	c.Assert(warnings, HasLen, 1)
+	c.Check(warnings[0].String(), Equals, `snap "some-snap" is tracking "9" which reached its end of life on 2024-04-30T00:00:00Z, consider switching to "10" with: snap refresh --channel=10/candidate some-snap`)
+	c.Check(s.state.Changes(), HasLen, 0)
+	c.Check(s.state.Notices(&state.NoticeFilter{Types: []state.NoticeType{state.SnapTrackMigrationNotice}}), HasLen, 0)
+}
+
+func (s *refreshHintsTestSuite) TestRefreshHintsIgnoresTrackBeforeEndOfLife(c *C) {
+	s.mockEndOfLifeTrack(c)
+	s.AddCleanup(snapstate.MockTimeNow(func() time.Time {
+		return time.Date(2024, 4, 29, 0, 0, 0, 0, time.UTC)
+	}))
+
+	s.state.Lock()
+	tr := config.NewTransaction(s.state)
+	c.Assert(tr.Set("core", "refresh.migrate-eol-tracks", true), IsNil)
+	tr.Commit()
+	s.state.Unlock()
+
+	rh := snapstate.NewRefreshHints(s.state)
+	c.Assert(rh.Ensure(), IsNil)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	c.Check(s.state.AllWarnings(), HasLen, 0)
+	c.Check(s.state.Changes(), HasLen, 0)
+}
+
+func (s *refreshHintsTestSuite) TestRefreshHintsMigratesEndOfLifeTrack(c *C) {
+	s.mockEndOfLifeTrack(c)
+
+	s.state.Lock()
+	tr := config.NewTransaction(s.state)
+	c.Assert(tr.Set("core", "refresh.migrate-eol-tracks", true), IsNil)
+	tr.Commit()
+	s.state.Unlock()
+
+	rh := snapstate.NewRefreshHints(s.state)
+	c.Assert(rh.Ensure(), IsNil)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	c.Check(s.state.AllWarnings(), HasLen, 0)
+
+	chgs := s.state.Changes()
+	c.Assert(chgs, HasLen, 1)
+	chg := chgs[0]
+	c.Check(chg.Kind(), Equals, "switch-snap")
+	c.Check(chg.Summary(), Equals, `Switch snap "some-snap" from end-of-life track "9" to channel "10/candidate"`)
+	tasks := chg.Tasks()
+	c.Assert(tasks, HasLen, 1)
+	c.Check(tasks[0].Kind(), Equals, "switch-snap")
+	snapsup, err := snapstate.TaskSnapSetup(tasks[0])
+	c.Assert(err, IsNil)
+	c.Check(snapsup.Channel, Equals, "10/candidate")
+
+	notices := s.state.Notices(&state.NoticeFilter{Types: []state.NoticeType{state.SnapTrackMigrationNotice}})
+	c.Assert(notices, HasLen, 1)
+	n := noticeToMap(c, notices[0])
+	c.Check(n["key"], Equals, "some-snap")
+	c.Check(n["last-data"], DeepEquals, map[string]any{
+		"old-track":   "9",
+		"new-channel": "10/candidate",
+		"change-id":   chg.ID(),
+	})
+}
+
+func (s *refreshHintsTestSuite) TestRefreshHintsMigrateEndOfLifeTrackConflict(c *C) {
+	s.mockEndOfLifeTrack(c)
+
+	s.state.Lock()
+	tr := config.NewTransaction(s.state)
+	c.Assert(tr.Set("core", "refresh.migrate-eol-tracks", true), IsNil)
+	tr.Commit()
+	otherChg := s.state.NewChange("other", "...")
+	t := s.state.NewTask("link-snap", "...")
+	t.Set("snap-setup", &snapstate.SnapSetup{SideInfo: &snap.SideInfo{RealName: "some-snap"}})
+	otherChg.AddTask(t)
+	s.state.Unlock()
+
+	rh := snapstate.NewRefreshHints(s.state)
+	c.Assert(rh.Ensure(), IsNil)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	c.Check(s.state.Changes(), HasLen, 1)
+	warnings := s.state.AllWarnings()
+	c.Assert(warnings, HasLen, 1)
+	c.Check(warnings[0].String(), Matches, `cannot switch snap "some-snap" from end-of-life track "9" to "10": .* has "other" change in progress`)
+	c.Check(s.state.Notices(&state.NoticeFilter{Types: []state.NoticeType{state.SnapTrackMigrationNotice}}), HasLen, 0)
+}
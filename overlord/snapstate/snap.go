@@ -581,7 +581,7 @@ func (sc *snapInstallChoreographer) addCleanupTasks(st *state.State, s *taskChai
 			// but don't discard this one; its' the thing we're switching to!
 			continue
 		}
-		ts, err := removeInactiveRevision(st, sc.snapst, sc.snapsup.InstanceName(), si.Snap.SnapID, si.Snap.Revision, sc.snapsup.Type)
+		ts, err := removeInactiveRevision(st, sc.snapst, sc.snapsup.InstanceName(), si.Snap.SnapID, si.Snap.Revision, sc.snapsup.Type, false)
 		if err != nil {
 			return err
 		}
@@ -614,7 +614,7 @@ func (sc *snapInstallChoreographer) addCleanupTasks(st *state.State, s *taskChai
 		if inUse(sc.snapsup.InstanceName(), si.Snap.Revision) {
 			continue
 		}
-		ts, err := removeInactiveRevision(st, sc.snapst, sc.snapsup.InstanceName(), si.Snap.SnapID, si.Snap.Revision, sc.snapsup.Type)
+		ts, err := removeInactiveRevision(st, sc.snapst, sc.snapsup.InstanceName(), si.Snap.SnapID, si.Snap.Revision, sc.snapsup.Type, false)
 		if err != nil {
 			return err
 		}
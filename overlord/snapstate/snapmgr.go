@@ -35,6 +35,7 @@ import (
 	"github.com/snapcore/snapd/i18n"
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/overlord/configstate/config"
 	"github.com/snapcore/snapd/overlord/snapstate/backend"
 	"github.com/snapcore/snapd/overlord/snapstate/sequence"
 	"github.com/snapcore/snapd/overlord/state"
@@ -89,6 +90,7 @@ type SnapManager struct {
 
 	ensuredMountsUpdated        bool
 	ensuredDesktopFilesUpdated  bool
+	ensuredReexecTargets        bool
 	ensuredDownloadsCleanedNext time.Time
 	ensureStoreCacheCleanNext   time.Time
 
@@ -124,6 +126,10 @@ type SnapSetup struct {
 	// PrereqContentAttrs maps default providers snap names to the content they provide.
 	PrereqContentAttrs map[string][]string `json:"prereq-content-attrs,omitempty"`
 
+	// PrereqTracks maps default providers snap names to the track they
+	// should be installed from, as preferred by the plugs of this snap.
+	PrereqTracks map[string]string `json:"prereq-tracks,omitempty"`
+
 	Flags
 
 	SnapPath string `json:"snap-path,omitempty"`
@@ -891,6 +897,8 @@ func Manager(st *state.State, runner *state.TaskRunner) (*SnapManager, error) {
 	// misc
 	runner.AddHandler("switch-snap", m.doSwitchSnap, nil)
 	runner.AddHandler("migrate-snap-home", m.doMigrateSnapHome, m.undoMigrateSnapHome)
+	runner.AddHandler("copy-instance-data", m.doCopyInstanceData, m.undoCopyInstanceData)
+	runner.AddCleanup("copy-instance-data", m.cleanupCopyInstanceData)
 	// no undo for now since it's last task in valset auto-resolution change
 	runner.AddHandler("enforce-validation-sets", m.doEnforceValidationSets, nil)
 	runner.AddHandler("pre-download-snap", m.doPreDownloadSnap, nil)
@@ -913,6 +921,7 @@ func Manager(st *state.State, runner *state.TaskRunner) (*SnapManager, error) {
 	// control serialisation
 	runner.AddBlocked(m.otherPrereqRunning)
 	runner.AddBlocked(affectsRunningHooks)
+	runner.AddBlocked(tooManyDownloadsRunning)
 
 	// block resealing tasks from running concurrently
 	runner.AddBlocked(resealingTaskBlocked)
@@ -999,6 +1008,47 @@ func (m *SnapManager) otherPrereqRunning(cand *state.Task, running []*state.Task
 	return false
 }
 
+// downloadTaskKinds are the kinds of the tasks fetching blobs from the store.
+var downloadTaskKinds = map[string]bool{
+	"download-snap":      true,
+	"download-component": true,
+	"pre-download-snap":  true,
+}
+
+// maxParallelDownloads returns the number of downloads that can run at the
+// same time, as set by the store.max-parallel-downloads option, or 0 if
+// there is no limit.
+func maxParallelDownloads(st *state.State) int {
+	var limit int
+	err := config.NewTransaction(st).Get("core", "store.max-parallel-downloads", &limit)
+	if err != nil && !config.IsNoOption(err) {
+		logger.Noticef("internal error: store.max-parallel-downloads system option is not valid: %v", err)
+		return 0
+	}
+	return limit
+}
+
+// tooManyDownloadsRunning blocks the downloads that would exceed the limit
+// of parallel downloads. The task sets of unrelated snaps are independent, and
+// snaps waiting on other snaps of the same refresh, e.g. on their base, only
+// do so after being downloaded, so their downloads run in parallel otherwise.
+func tooManyDownloadsRunning(cand *state.Task, running []*state.Task) (block bool) {
+	if !downloadTaskKinds[cand.Kind()] || cand.Status() != state.DoStatus {
+		return false
+	}
+	limit := maxParallelDownloads(cand.State())
+	if limit <= 0 {
+		return false
+	}
+	downloads := 0
+	for _, t := range running {
+		if downloadTaskKinds[t.Kind()] {
+			downloads++
+		}
+	}
+	return downloads >= limit
+}
+
 func affectsRunningHooks(cand *state.Task, running []*state.Task) (block bool) {
 	st := cand.State()
 
@@ -1097,6 +1147,28 @@ func (m *SnapManager) RefreshSchedule() (string, bool, error) {
 	return m.autoRefresh.RefreshSchedule()
 }
 
+// RefreshStagger returns the refresh.stagger option and, unless
+// auto-refreshes happen at a random time of the refresh window, the offset
+// of the next auto-refresh from the start of its window.
+// The caller should be holding the state lock.
+func (m *SnapManager) RefreshStagger() (string, time.Duration, error) {
+	return m.autoRefresh.RefreshStagger()
+}
+
+// RefreshBackoffs returns the snaps whose auto-refreshes are currently
+// paused because of previous failed attempts, sorted by name.
+// The caller should be holding the state lock.
+func (m *SnapManager) RefreshBackoffs() ([]RefreshBackoff, error) {
+	return refreshBackoffs(m.state)
+}
+
+// SystemRefreshHolds returns the refresh holds set on snaps by the
+// sysadmin which did not expire yet, sorted by snap name.
+// The caller should be holding the state lock.
+func (m *SnapManager) SystemRefreshHolds() ([]SystemRefreshHold, error) {
+	return SystemRefreshHolds(m.state)
+}
+
 // EnsureAutoRefreshesAreDelayed will delay refreshes for the specified amount
 // of time, as well as return any active auto-refresh changes that are currently
 // not ready so that the client can wait for those.
@@ -1599,6 +1671,76 @@ func (m *SnapManager) ensureDesktopFilesUpdated() error {
 	return nil
 }
 
+// recordReexecTargets records the current revisions of the snapd and core
+// snaps, which snap tools verify before re-exec'ing into them.
+func recordReexecTargets(st *state.State) error {
+	targets := make(map[string]*snapdtool.ReexecTarget)
+	for _, name := range []string{"snapd", "core"} {
+		var snapst SnapState
+		err := Get(st, name, &snapst)
+		if errors.Is(err, state.ErrNoState) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		info, err := snapst.CurrentInfo()
+		if errors.Is(err, ErrNoCurrent) {
+			// the snap is being removed or its first install undone
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		var digest string
+		if info.SnapID != "" && SnapRevisionDigest != nil {
+			digest, err = SnapRevisionDigest(st, info.SnapID, info.Revision)
+			if err != nil {
+				return err
+			}
+		}
+		target, err := snapdtool.NewReexecTarget(info.MountFile(), info.Revision.String(), digest)
+		if err != nil {
+			return err
+		}
+		targets[name] = target
+	}
+	return snapdtool.WriteReexecTargets(targets)
+}
+
+// ensureReexecTargetsRecorded records the re-exec targets once after startup,
+// this covers systems where snapd was updated from a version not recording
+// them.
+func (m *SnapManager) ensureReexecTargetsRecorded() error {
+	m.state.Lock()
+	defer m.state.Unlock()
+
+	if m.ensuredReexecTargets || !release.OnClassic {
+		return nil
+	}
+
+	// only run after we are seeded
+	var seeded bool
+	err := m.state.Get("seeded", &seeded)
+	if err != nil && !errors.Is(err, state.ErrNoState) {
+		return err
+	}
+	if !seeded {
+		return nil
+	}
+
+	logger.Trace("ensure", "manager", "SnapManager", "func", "ensureReexecTargetsRecorded")
+	// snap tools refuse to re-exec into targets which are not recorded
+	// correctly, so there is no point in retrying on errors
+	if err := recordReexecTargets(m.state); err != nil {
+		logger.Noticef("cannot record re-exec targets: %v", err)
+	}
+
+	m.ensuredReexecTargets = true
+
+	return nil
+}
+
 func (m *SnapManager) ensureDownloadsCleaned() error {
 	m.state.Lock()
 	defer m.state.Unlock()
@@ -1713,6 +1855,7 @@ func (m *SnapManager) Ensure() error {
 		m.ensureVulnerableSnapConfineVersionsRemovedOnClassic(),
 		m.ensureMountsUpdated(),
 		m.ensureDesktopFilesUpdated(),
+		m.ensureReexecTargetsRecorded(),
 		m.ensureDownloadsCleaned(),
 		m.ensureStoreDownloadsCacheCleaned(),
 	}
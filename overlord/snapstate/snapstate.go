@@ -526,6 +526,35 @@ func defaultProviderContentAttrs(st *state.State, info *snap.Info, prqt PrereqTr
 	return prqt.MissingProviderContentTags(info, repo)
 }
 
+// defaultProviderTracks takes a snap.Info and the default providers it needs,
+// as returned by defaultProviderContentAttrs, and returns a map of those
+// default providers to the track preferred by the plugs of the snap. Providers
+// without a preferred track are omitted.
+func defaultProviderTracks(info *snap.Info, providerContentAttrs map[string][]string) (map[string]string, error) {
+	if len(providerContentAttrs) == 0 {
+		return nil, nil
+	}
+	plugs := make([]*snap.PlugInfo, 0, len(info.Plugs))
+	for _, plug := range info.Plugs {
+		plugs = append(plugs, plug)
+	}
+	tracks, err := snap.DefaultContentProviderTracks(plugs)
+	if err != nil {
+		return nil, err
+	}
+	var providerTracks map[string]string
+	for provider, track := range tracks {
+		if _, ok := providerContentAttrs[provider]; !ok {
+			continue
+		}
+		if providerTracks == nil {
+			providerTracks = make(map[string]string)
+		}
+		providerTracks[provider] = track
+	}
+	return providerTracks, nil
+}
+
 // validateFeatureFlags validates the given snap only uses experimental
 // features that are enabled by the user.
 func validateFeatureFlags(st *state.State, info *snap.Info) error {
@@ -1050,6 +1079,13 @@ func RefreshCandidates(st *state.State, user *auth.UserState) ([]*snap.Info, err
 // ValidateRefreshes allows to hook validation into the handling of refresh candidates.
 var ValidateRefreshes func(st *state.State, refreshes []*snap.Info, ignoreValidation map[string]bool, userID int, deviceCtx DeviceContext) (validated []*snap.Info, err error)
 
+// CoordinateRefreshes allows to hook the coordination of refreshes with other
+// devices into the handling of refreshes of all snaps, e.g. auto-refreshes. It
+// returns the snaps whose refreshes are coordinated, those are left out and
+// refreshed by the owner of the hook once it is their turn. Refreshes of
+// specific snaps are not coordinated.
+var CoordinateRefreshes func(st *state.State, refreshes []*snap.Info) (coordinated map[string]bool, err error)
+
 // UpdateMany updates everything from the given list of names that the
 // store says is updatable. If the list is empty, update everything.
 // Note that the state must be locked by the caller.
@@ -1548,6 +1584,7 @@ func doUpdate(st *state.State, requested []string, updates []update, opts Option
 
 	var snapInstallTSS []snapInstallTaskSet
 	snapLanes := map[int]struct{}{}
+	providerTrackHints := make(map[string]providerTrackHint)
 
 	// updates is sorted by kind so this will process first core
 	// and bases and then other snaps
@@ -1575,6 +1612,14 @@ func doUpdate(st *state.State, requested []string, updates []update, opts Option
 			return nil, false, nil, err
 		}
 
+		if err := checkProviderTrackHints(st, providerTrackHints, &up.Setup); err != nil {
+			if refreshAll {
+				logger.Noticef("cannot refresh snap %q: %v", up.Setup.InstanceName(), err)
+				continue
+			}
+			return nil, false, nil, err
+		}
+
 		// keep track of any snaps that we requested to refresh actually got
 		// their revisions changed. if any did, pass that up to the caller so
 		// that they may set up a re-refresh if applicable
@@ -3094,6 +3139,12 @@ type RemoveFlags struct {
 	Purge bool
 	// Kill running snap apps and services
 	Terminate bool
+	// Keep the configuration of the snap to restore it on a future
+	// install of the snap
+	KeepConfig bool
+	// Keep the manual connections of the snap, without expiring, to
+	// restore them on a future install of the snap
+	KeepConnections bool
 }
 
 // Remove returns a set of tasks for removing snap.
@@ -3196,6 +3247,10 @@ func removeTasks(st *state.State, snapst *SnapState, removals map[string]bool, r
 		// no version info needed
 		PlugsOnly:   len(info.Slots) == 0,
 		InstanceKey: snapst.InstanceKey,
+		Flags: Flags{
+			KeepConfig:      flags.KeepConfig,
+			KeepConnections: flags.KeepConnections,
+		},
 	}
 
 	// trigger remove
@@ -3246,6 +3301,10 @@ func removeTasks(st *state.State, snapst *SnapState, removals map[string]bool, r
 		prev = disconnect
 	}
 
+	if (flags.KeepConfig || flags.KeepConnections) && !removeAll {
+		return nil, 0, fmt.Errorf("cannot keep configuration or connections unless all revisions are removed")
+	}
+
 	if flags.Terminate {
 		// This check is needed to avoid having the snap stuck in inhibition since
 		// "kill-snap-apps" inhibits the snap from running and "discard-snap" only
@@ -3316,7 +3375,7 @@ func removeTasks(st *state.State, snapst *SnapState, removals map[string]bool, r
 			if i != currentIndex {
 				si := si[i]
 				ts, err := removeInactiveRevision(st, snapst, instanceName,
-					info.SnapID, si.Revision, snapsup.Type)
+					info.SnapID, si.Revision, snapsup.Type, false)
 				if err != nil {
 					return nil, 0, err
 				}
@@ -3327,7 +3386,7 @@ func removeTasks(st *state.State, snapst *SnapState, removals map[string]bool, r
 		// this is then also when common data will be removed
 		if currentIndex >= 0 {
 			ts, err := removeInactiveRevision(st, snapst, instanceName,
-				info.SnapID, si[currentIndex].Revision, snapsup.Type)
+				info.SnapID, si[currentIndex].Revision, snapsup.Type, flags.KeepConfig)
 			if err != nil {
 				return nil, 0, err
 			}
@@ -3335,7 +3394,7 @@ func removeTasks(st *state.State, snapst *SnapState, removals map[string]bool, r
 		}
 	} else {
 		ts, err := removeInactiveRevision(st, snapst, instanceName, info.SnapID, revision,
-			snapsup.Type)
+			snapsup.Type, false)
 		if err != nil {
 			return nil, 0, err
 		}
@@ -3345,7 +3404,10 @@ func removeTasks(st *state.State, snapst *SnapState, removals map[string]bool, r
 	return removeTs, snapshotSize, nil
 }
 
-func removeInactiveRevision(st *state.State, snapst *SnapState, name, snapID string, revision snap.Revision, typ snap.Type) (*state.TaskSet, error) {
+// removeInactiveRevision returns the tasks removing the given inactive
+// revision of the snap. When it is the last revision, keepConfig requests
+// the configuration of the snap to be kept for a later install.
+func removeInactiveRevision(st *state.State, snapst *SnapState, name, snapID string, revision snap.Revision, typ snap.Type, keepConfig bool) (*state.TaskSet, error) {
 	var tasks []*state.Task
 
 	snapName, instanceKey := snap.SplitInstanceName(name)
@@ -3358,6 +3420,9 @@ func removeInactiveRevision(st *state.State, snapst *SnapState, name, snapID str
 		InstanceKey: instanceKey,
 		Type:        typ,
 		// no version info needed
+		Flags: Flags{
+			KeepConfig: keepConfig,
+		},
 	}
 
 	clearData := st.NewTask("clear-snap",
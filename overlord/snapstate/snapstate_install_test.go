@@ -1947,6 +1947,112 @@ func (s *snapmgrTestSuite) TestParallelInstanceInstallPreferRunThrough(c *C) {
 	s.testParallelInstanceInstallRunThrough(c, inputFlags, expectedFlags)
 }
 
+func (s *snapmgrTestSuite) setKeptConfig(c *C, snapID string) {
+	s.state.Set("kept-configs", map[string]any{
+		"some-snap": map[string]any{
+			"snap-id":  snapID,
+			"revision": "7",
+			"kept-at":  time.Now(),
+			"config":   map[string]any{"foo": "bar"},
+		},
+	})
+}
+
+func (s *snapmgrTestSuite) TestInstallRestoresKeptConfig(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.setKeptConfig(c, "some-snap-id")
+
+	chg := s.state.NewChange("install", "install a snap")
+	opts := &snapstate.RevisionOptions{Channel: "some-channel"}
+	ts, err := snapstate.Install(context.Background(), s.state, "some-snap", opts, s.user.ID, snapstate.Flags{})
+	c.Assert(err, IsNil)
+	chg.AddAll(ts)
+
+	s.settle(c)
+
+	c.Assert(chg.Err(), IsNil)
+
+	var res string
+	tr := config.NewTransaction(s.state)
+	c.Assert(tr.Get("some-snap", "foo", &res), IsNil)
+	c.Check(res, Equals, "bar")
+
+	kept, err := snapstate.KeptConfigs(s.state, nil)
+	c.Assert(err, IsNil)
+	c.Check(kept, HasLen, 0)
+
+	linkTask := findLastTask(chg, "link-snap")
+	c.Assert(linkTask, NotNil)
+	c.Check(strings.Join(linkTask.Log(), "\n"), testutil.Contains, `Restored configuration of the previous installation of snap "some-snap"`)
+}
+
+func (s *snapmgrTestSuite) TestInstallKeptConfigSnapIDMismatch(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.setKeptConfig(c, "other-snap-id")
+
+	chg := s.state.NewChange("install", "install a snap")
+	opts := &snapstate.RevisionOptions{Channel: "some-channel"}
+	ts, err := snapstate.Install(context.Background(), s.state, "some-snap", opts, s.user.ID, snapstate.Flags{})
+	c.Assert(err, IsNil)
+	chg.AddAll(ts)
+
+	s.settle(c)
+
+	c.Assert(chg.Err(), IsNil)
+
+	var res string
+	tr := config.NewTransaction(s.state)
+	c.Check(tr.Get("some-snap", "foo", &res), ErrorMatches, `snap "some-snap" has no "foo" configuration option`)
+
+	// the kept configuration is left alone
+	kept, err := snapstate.KeptConfigs(s.state, nil)
+	c.Assert(err, IsNil)
+	c.Check(kept, HasLen, 1)
+
+	linkTask := findLastTask(chg, "link-snap")
+	c.Assert(linkTask, NotNil)
+	c.Check(strings.Join(linkTask.Log(), "\n"), testutil.Contains, `Not restoring configuration of the previous installation of snap "some-snap": snap ID mismatch`)
+}
+
+func (s *snapmgrTestSuite) TestInstallRestoresKeptConfigUndo(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.setKeptConfig(c, "some-snap-id")
+
+	chg := s.state.NewChange("install", "install a snap")
+	opts := &snapstate.RevisionOptions{Channel: "some-channel"}
+	ts, err := snapstate.Install(context.Background(), s.state, "some-snap", opts, s.user.ID, snapstate.Flags{})
+	c.Assert(err, IsNil)
+	chg.AddAll(ts)
+
+	tasks := ts.Tasks()
+	last := tasks[len(tasks)-2]
+	terr := s.state.NewTask("error-trigger", "provoking total undo")
+	terr.WaitFor(last)
+	terr.JoinLane(last.Lanes()[0])
+	chg.AddTask(terr)
+
+	s.settle(c)
+
+	c.Assert(chg.Status(), Equals, state.ErrorStatus)
+
+	var res string
+	tr := config.NewTransaction(s.state)
+	c.Check(tr.Get("some-snap", "foo", &res), ErrorMatches, `snap "some-snap" has no "foo" configuration option`)
+
+	// the configuration is kept again
+	kept, err := snapstate.KeptConfigs(s.state, nil)
+	c.Assert(err, IsNil)
+	c.Assert(kept, HasLen, 1)
+	c.Check(kept[0].Snap, Equals, "some-snap")
+	c.Check(kept[0].Keys, DeepEquals, []string{"foo"})
+}
+
 func (s *snapmgrTestSuite) TestInstallUndoRunThroughJustOneSnap(c *C) {
 	s.state.Lock()
 	defer s.state.Unlock()
@@ -4131,6 +4237,34 @@ func (s *snapmgrTestSuite) TestInstallMany(c *C) {
 	verifyDelayedEffectsTasks(c, tts[2], []int{1, 2}, 0)
 }
 
+func (s *snapmgrTestSuite) TestInstallManyDownloadsIndependent(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	_, tts, err := snapstate.InstallMany(s.state, []string{"one", "two"}, nil, 0, nil)
+	c.Assert(err, IsNil)
+	c.Assert(tts, HasLen, 3)
+
+	// the tasks of one snap, up to the mount, never wait for the tasks of
+	// the other snaps so that they can run in parallel
+	for _, ts := range tts[:2] {
+		own := make(map[string]bool)
+		for _, t := range ts.Tasks() {
+			own[t.ID()] = true
+		}
+		for _, t := range ts.Tasks() {
+			switch t.Kind() {
+			case "prerequisites", "download-snap", "validate-snap", "mount-snap":
+			default:
+				continue
+			}
+			for _, wt := range t.WaitTasks() {
+				c.Check(own[wt.ID()], Equals, true, Commentf("%s waits for %s", t.Summary(), wt.Summary()))
+			}
+		}
+	}
+}
+
 func (s *snapmgrTestSuite) TestInstallManyNoDelayed(c *C) {
 	s.state.Lock()
 	defer s.state.Unlock()
@@ -6215,6 +6349,79 @@ type: base
 	c.Assert(op, IsNil)
 }
 
+func (s *snapmgrTestSuite) installPathManyWithProviderTracks(c *C, tracks ...string) ([]*state.TaskSet, error) {
+	var paths []string
+	var sideInfos []*snap.SideInfo
+	for i, track := range tracks {
+		name := fmt.Sprintf("consumer-%d", i+1)
+		yaml := fmt.Sprintf(`name: %s
+version: 1.0
+plugs:
+  myplug:
+    interface: content
+    content: mycontent
+    default-provider: prereq-snap
+    default-provider-track: %q
+`, name, track)
+		paths = append(paths, makeTestSnap(c, yaml))
+		sideInfos = append(sideInfos, &snap.SideInfo{
+			RealName: name,
+			Revision: snap.R("1"),
+		})
+	}
+
+	return snapstate.InstallPathMany(context.Background(), s.state, sideInfos, paths, 0, nil)
+}
+
+func (s *snapmgrTestSuite) TestInstallPathManyProviderTracks(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	tss, err := s.installPathManyWithProviderTracks(c, "2", "2")
+	c.Assert(err, IsNil)
+
+	var prereqTasks int
+	for _, ts := range tss {
+		for _, t := range ts.Tasks() {
+			if t.Kind() != "prerequisites" || t.Has("prerequisites-sync") {
+				continue
+			}
+			snapsup, err := snapstate.TaskSnapSetup(t)
+			c.Assert(err, IsNil)
+			c.Check(snapsup.PrereqContentAttrs, DeepEquals, map[string][]string{"prereq-snap": {"mycontent"}})
+			c.Check(snapsup.PrereqTracks, DeepEquals, map[string]string{"prereq-snap": "2"})
+			prereqTasks++
+		}
+	}
+	c.Check(prereqTasks, Equals, 2)
+}
+
+func (s *snapmgrTestSuite) TestInstallPathManyProviderTracksConflict(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	_, err := s.installPathManyWithProviderTracks(c, "2", "3")
+	c.Assert(err, ErrorMatches, `cannot install snaps "consumer-1" and "consumer-2" together: they prefer different tracks \("2" and "3"\) of missing default provider "prereq-snap", install it from the wanted track first`)
+}
+
+func (s *snapmgrTestSuite) TestInstallPathManyProviderTracksConflictProviderInstalled(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	snapstate.Set(s.state, "prereq-snap", &snapstate.SnapState{
+		Active: true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+			{RealName: "prereq-snap", SnapID: "prereq-snap-id", Revision: snap.R(1)},
+		}),
+		Current:  snap.R(1),
+		SnapType: "app",
+	})
+
+	// the installed provider is at most refreshed, the hints do not matter
+	_, err := s.installPathManyWithProviderTracks(c, "2", "3")
+	c.Assert(err, IsNil)
+}
+
 func (s *snapmgrTestSuite) TestInstallPathManyNoDelayed(c *C) {
 	s.state.Lock()
 	defer s.state.Unlock()
@@ -31,6 +31,7 @@ import (
 
 	"github.com/snapcore/snapd/asserts"
 	"github.com/snapcore/snapd/asserts/snapasserts"
+	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/osutil"
@@ -1475,6 +1476,97 @@ func (s *snapmgrTestSuite) TestRemoveDeletesConfigOnLastRevision(c *C) {
 	c.Assert(res, Equals, "baz")
 }
 
+func (s *snapmgrTestSuite) TestRemoveKeepConfig(c *C) {
+	si := snap.SideInfo{
+		SnapID:   "some-snap-id",
+		RealName: "some-snap",
+		Revision: snap.R(7),
+	}
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	snapstate.Set(s.state, "some-snap", &snapstate.SnapState{
+		Active:   true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{&si}),
+		Current:  si.Revision,
+		SnapType: "app",
+	})
+
+	tr := config.NewTransaction(s.state)
+	tr.Set("some-snap", "foo", "bar")
+	tr.Set("some-snap", "baz.qux", 42)
+	tr.Commit()
+
+	now := time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC)
+	defer snapstate.MockTimeNow(func() time.Time { return now })()
+
+	chg := s.state.NewChange("remove", "remove a snap")
+	ts, err := snapstate.Remove(s.state, "some-snap", snap.R(0), &snapstate.RemoveFlags{KeepConfig: true})
+	c.Assert(err, IsNil)
+	chg.AddAll(ts)
+
+	s.settle(c)
+
+	c.Assert(chg.Err(), IsNil)
+
+	// the configuration is gone
+	var res string
+	tr = config.NewTransaction(s.state)
+	err = tr.Get("some-snap", "foo", &res)
+	c.Assert(err, ErrorMatches, `snap "some-snap" has no "foo" configuration option`)
+
+	// but kept
+	kept, err := snapstate.KeptConfigs(s.state, nil)
+	c.Assert(err, IsNil)
+	c.Check(kept, DeepEquals, []client.KeptConfig{{
+		Snap:     "some-snap",
+		Revision: snap.R(7),
+		Time:     now,
+		Keys:     []string{"baz", "foo"},
+	}})
+
+	var keptConfigs map[string]struct {
+		SnapID string `json:"snap-id"`
+		Config struct {
+			Foo string `json:"foo"`
+		} `json:"config"`
+	}
+	c.Assert(s.state.Get("kept-configs", &keptConfigs), IsNil)
+	c.Check(keptConfigs["some-snap"].SnapID, Equals, "some-snap-id")
+	c.Check(keptConfigs["some-snap"].Config.Foo, Equals, "bar")
+
+	kept, err = snapstate.KeptConfigs(s.state, []string{"other-snap"})
+	c.Assert(err, IsNil)
+	c.Check(kept, HasLen, 0)
+}
+
+func (s *snapmgrTestSuite) TestRemoveKeepConfigNotLastRevision(c *C) {
+	si1 := snap.SideInfo{
+		RealName: "some-snap",
+		Revision: snap.R(7),
+	}
+	si2 := snap.SideInfo{
+		RealName: "some-snap",
+		Revision: snap.R(8),
+	}
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	snapstate.Set(s.state, "some-snap", &snapstate.SnapState{
+		Active:   true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{&si1, &si2}),
+		Current:  si2.Revision,
+		SnapType: "app",
+	})
+
+	for _, flags := range []*snapstate.RemoveFlags{{KeepConfig: true}, {KeepConnections: true}} {
+		_, err := snapstate.Remove(s.state, "some-snap", si1.Revision, flags)
+		c.Check(err, ErrorMatches, "cannot keep configuration or connections unless all revisions are removed")
+	}
+}
+
 func (s *snapmgrTestSuite) TestRemoveDoesntDeleteConfigIfNotLastRevision(c *C) {
 	si1 := snap.SideInfo{
 		RealName: "some-snap",
@@ -71,6 +71,7 @@ import (
 	"github.com/snapcore/snapd/snap/naming"
 	"github.com/snapcore/snapd/snap/snaptest"
 	"github.com/snapcore/snapd/snapdenv"
+	"github.com/snapcore/snapd/snapdtool"
 	"github.com/snapcore/snapd/store"
 	"github.com/snapcore/snapd/systemd"
 	"github.com/snapcore/snapd/testutil"
@@ -483,6 +484,7 @@ SNAPD_APPARMOR_REEXEC=1
 func (s *snapmgrBaseTest) TearDownTest(c *C) {
 	s.BaseTest.TearDownTest(c)
 	snapstate.ValidateRefreshes = nil
+	snapstate.CoordinateRefreshes = nil
 	snapstate.AutoAliases = nil
 	snapstate.CanAutoRefresh = nil
 }
@@ -3903,6 +3905,61 @@ func (s *snapmgrTestSuite) TestEnsureChecksSnapdInfoFileOnClassicOnly(c *C) {
 	c.Assert(ensureErr, IsNil)
 }
 
+func (s *snapmgrTestSuite) TestEnsureRecordsReexecTargetsOnClassic(c *C) {
+	r := release.MockOnClassic(true)
+	defer r()
+
+	s.state.Lock()
+	// only core is installed
+	snapstate.Set(s.state, "snapd", nil)
+	snapstate.Set(s.state, "core", &snapstate.SnapState{
+		Active: true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+			{RealName: "core", SnapID: "core-id", Revision: snap.R(1)},
+		}),
+		Current:  snap.R(1),
+		SnapType: "os",
+	})
+	s.state.Unlock()
+
+	r = testutil.Mock(&snapstate.SnapRevisionDigest, func(st *state.State, snapID string, rev snap.Revision) (string, error) {
+		c.Check(snapID, Equals, "core-id")
+		c.Check(rev, Equals, snap.R(1))
+		return "core-digest", nil
+	})
+	defer r()
+
+	snapFile := filepath.Join(dirs.SnapBlobDir, "core_1.snap")
+	c.Assert(os.MkdirAll(dirs.SnapBlobDir, 0755), IsNil)
+	c.Assert(os.WriteFile(snapFile, []byte("core"), 0644), IsNil)
+	fi, err := os.Stat(snapFile)
+	c.Assert(err, IsNil)
+
+	c.Assert(s.snapmgr.Ensure(), IsNil)
+
+	targets, err := snapdtool.ReadReexecTargets()
+	c.Assert(err, IsNil)
+	c.Assert(targets, HasLen, 1)
+	c.Check(targets["core"].Revision, Equals, "1")
+	c.Check(targets["core"].SnapSHA3_384, Equals, "core-digest")
+	c.Check(targets["core"].Size, Equals, int64(4))
+	c.Check(targets["core"].ModTime.Equal(fi.ModTime()), Equals, true)
+	c.Check(dirs.SnapReexecTargetsFile, testutil.FileContains, `"core":{"revision":"1"`)
+
+	// the targets are recorded only once
+	c.Assert(os.Remove(dirs.SnapReexecTargetsFile), IsNil)
+	c.Assert(s.snapmgr.Ensure(), IsNil)
+	c.Check(dirs.SnapReexecTargetsFile, testutil.FileAbsent)
+}
+
+func (s *snapmgrTestSuite) TestEnsureDoesNotRecordReexecTargetsOnCore(c *C) {
+	r := release.MockOnClassic(false)
+	defer r()
+
+	c.Assert(s.snapmgr.Ensure(), IsNil)
+	c.Check(dirs.SnapReexecTargetsFile, testutil.FileAbsent)
+}
+
 func (s *snapmgrTestSuite) TestEnsureSkipsCheckingSnapdSnapInfoFileWhenStateSet(c *C) {
 	// we default from SetUp to having the core snap installed, remove it so we
 	// only have the snapd snap available
@@ -11142,6 +11199,38 @@ func (s *snapmgrTestSuite) TestBackoffOnAutoRefreshWithNewRevision(c *C) {
 	c.Assert(snapst.RefreshFailures, IsNil)
 }
 
+func (s *snapmgrTestSuite) TestBackoffResetOnManualRefresh(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	badRevison := snap.R(12)
+	snapstate.Set(s.state, "some-snap", &snapstate.SnapState{
+		Active: true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+			{RealName: "some-snap", SnapID: "some-snap-id", Revision: snap.R(1)},
+		}),
+		Current:  snap.R(1),
+		SnapType: "app",
+		RefreshFailures: &snap.RefreshFailuresInfo{
+			Revision:        badRevison,
+			FailureCount:    3,
+			LastFailureTime: time.Now(),
+			LastError:       "boom",
+		},
+	})
+	s.fakeStore.refreshRevnos["some-snap-id"] = badRevison
+
+	// the user explicitly asked for the refresh, so it is not skipped
+	ts, err := snapstate.Update(s.state, "some-snap", nil, 0, snapstate.Flags{})
+	c.Assert(err, IsNil)
+	c.Check(ts.Tasks(), Not(HasLen), 0)
+
+	// and the failure streak starts anew
+	var snapst snapstate.SnapState
+	c.Assert(snapstate.Get(s.state, "some-snap", &snapst), IsNil)
+	c.Check(snapst.RefreshFailures, IsNil)
+}
+
 type customStore struct {
 	*fakeStore
 
@@ -12801,6 +12890,38 @@ func (s *snapmgrTestSuite) TestResealingTasksAreRegistered(c *C) {
 	c.Assert(registeredTaskKinds, DeepEquals, expectedTaskKinds)
 }
 
+func (s *snapmgrTestSuite) TestTooManyDownloadsRunning(c *C) {
+	st := s.state
+	st.Lock()
+	defer st.Unlock()
+
+	download := st.NewTask("download-snap", "...")
+	downloadComp := st.NewTask("download-component", "...")
+	preDownload := st.NewTask("pre-download-snap", "...")
+	other := st.NewTask("mount-snap", "...")
+	running := []*state.Task{st.NewTask("download-snap", "..."), st.NewTask("download-component", "...")}
+
+	// no limit by default
+	for _, t := range []*state.Task{download, downloadComp, preDownload, other} {
+		c.Check(snapstate.TooManyDownloadsRunning(t, running), Equals, false)
+	}
+
+	tr := config.NewTransaction(st)
+	c.Assert(tr.Set("core", "store.max-parallel-downloads", 2), IsNil)
+	tr.Commit()
+
+	for _, t := range []*state.Task{download, downloadComp, preDownload} {
+		c.Check(snapstate.TooManyDownloadsRunning(t, running), Equals, true)
+		c.Check(snapstate.TooManyDownloadsRunning(t, running[:1]), Equals, false)
+		c.Check(snapstate.TooManyDownloadsRunning(t, []*state.Task{other}), Equals, false)
+	}
+	c.Check(snapstate.TooManyDownloadsRunning(other, running), Equals, false)
+
+	// undoing is never blocked
+	download.SetStatus(state.UndoStatus)
+	c.Check(snapstate.TooManyDownloadsRunning(download, running), Equals, false)
+}
+
 func (s *snapmgrTestSuite) TestResealingTaskBlocked(c *C) {
 	st := s.state
 	st.Lock()
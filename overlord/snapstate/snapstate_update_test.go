@@ -174,6 +174,17 @@ func firstTaskAfterMount(c *C, ts *state.TaskSet) *state.Task {
 	return nil
 }
 
+func findPrerequisitesSync(c *C, ts *state.TaskSet) *state.Task {
+	for _, t := range ts.Tasks() {
+		if t.Kind() == "prerequisites" && t.Has("prerequisites-sync") {
+			return t
+		}
+	}
+
+	c.Fatalf("cannot find prerequisites synchronization task")
+	return nil
+}
+
 func findMountSnap(c *C, ts *state.TaskSet) *state.Task {
 	mountTask := findKindInTaskSet(ts, "mount-snap")
 	c.Assert(mountTask, NotNil)
@@ -6287,16 +6298,20 @@ func (s *snapmgrTestSuite) TestUpdateManyWaitForBasesUC16(c *C) {
 
 	// Some-snap is expected to wait for both the essential snap, but
 	// also the base of some-snap. These dependencies are set up between
-	// last tasks of core/some-base, on preprequisites
+	// last tasks of core/some-base and the prerequisites synchronization
+	// task of some-snap, so that some-snap is downloaded in parallel
 	lastTaskOfCore, err := tts[0].Edge(snapstate.EndEdge)
 	c.Assert(err, IsNil)
 	lastTaskOfBase, err := tts[1].Edge(snapstate.EndEdge)
 	c.Assert(err, IsNil)
 	firstTaskOfSnap, err := tts[2].Edge(snapstate.BeginEdge)
 	c.Assert(err, IsNil)
-	c.Check(firstTaskOfSnap.WaitTasks(), HasLen, 2)
-	c.Check(firstTaskOfSnap.WaitTasks(), testutil.Contains, lastTaskOfCore)
-	c.Check(firstTaskOfSnap.WaitTasks(), testutil.Contains, lastTaskOfBase)
+	c.Check(firstTaskOfSnap.WaitTasks(), Not(testutil.Contains), lastTaskOfCore)
+	c.Check(firstTaskOfSnap.WaitTasks(), Not(testutil.Contains), lastTaskOfBase)
+	prereqsSyncOfSnap := findPrerequisitesSync(c, tts[2])
+	c.Check(prereqsSyncOfSnap.WaitTasks(), HasLen, 3)
+	c.Check(prereqsSyncOfSnap.WaitTasks(), testutil.Contains, lastTaskOfCore)
+	c.Check(prereqsSyncOfSnap.WaitTasks(), testutil.Contains, lastTaskOfBase)
 
 	// core and the other snaps are not expected to share the same lane
 	c.Check(taskSetsShareLane(tts[0], tts[1]), Equals, false)
@@ -6367,17 +6382,21 @@ func (s *snapmgrTestSuite) TestUpdateManyWaitForBasesUC18(c *C) {
 	}
 
 	// Some-app will be waiting for the bases, which includes both some-base and
-	// core18. The first task of some-snap will be waiting for the last tasks of
-	// those two dependencies.
+	// core18. The prerequisites synchronization task of some-snap will be
+	// waiting for the last tasks of those two dependencies, its download does
+	// not.
 	lastTaskOfCore, err := tts[1].Edge(snapstate.EndEdge)
 	c.Assert(err, IsNil)
 	lastTaskOfBase, err := tts[2].Edge(snapstate.EndEdge)
 	c.Assert(err, IsNil)
 	firstTaskOfSnap, err := tts[3].Edge(snapstate.BeginEdge)
 	c.Assert(err, IsNil)
-	c.Check(firstTaskOfSnap.WaitTasks(), HasLen, 2)
-	c.Check(firstTaskOfSnap.WaitTasks(), testutil.Contains, lastTaskOfCore)
-	c.Check(firstTaskOfSnap.WaitTasks(), testutil.Contains, lastTaskOfBase)
+	c.Check(firstTaskOfSnap.WaitTasks(), Not(testutil.Contains), lastTaskOfCore)
+	c.Check(firstTaskOfSnap.WaitTasks(), Not(testutil.Contains), lastTaskOfBase)
+	prereqsSyncOfSnap := findPrerequisitesSync(c, tts[3])
+	c.Check(prereqsSyncOfSnap.WaitTasks(), HasLen, 3)
+	c.Check(prereqsSyncOfSnap.WaitTasks(), testutil.Contains, lastTaskOfCore)
+	c.Check(prereqsSyncOfSnap.WaitTasks(), testutil.Contains, lastTaskOfBase)
 
 	// Core18 and snapd are not expected to share the same lane, we only
 	// check essential snaps as those are the ones that can end up in same lane.
@@ -11302,11 +11321,16 @@ NextSnap3:
 			continue
 		}
 
-		firstTaskOfCurrent, err := currentTs.Edge(snapstate.BeginEdge)
+		// snapd has to be refreshed first, otherwise snaps only wait on
+		// the previous snap after being downloaded
+		waitingTask, err := currentTs.Edge(snapstate.BeginEdge)
 		c.Assert(err, IsNil)
+		if prevTs != tsByName["snapd"] {
+			waitingTask = findPrerequisitesSync(c, currentTs)
+		}
 		lastTaskOfPrev, err := prevTs.Edge(snapstate.EndEdge)
 		c.Assert(err, IsNil)
-		c.Check(firstTaskOfCurrent.WaitTasks(), testutil.Contains, lastTaskOfPrev)
+		c.Check(waitingTask.WaitTasks(), testutil.Contains, lastTaskOfPrev)
 		prevTs = currentTs
 	}
 
@@ -11582,6 +11606,71 @@ func (s *snapmgrTestSuite) TestSpecificRefreshRefreshesHeldSnaps(c *C) {
 	c.Check(chg.Status(), Equals, state.DoneStatus)
 }
 
+func (s *snapmgrTestSuite) TestRefreshAllLeavesCoordinatedSnaps(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	for _, name := range []string{"some-snap", "some-other-snap"} {
+		snapID := fmt.Sprintf("%s-id", name)
+		si := &snap.SideInfo{
+			RealName: name,
+			SnapID:   snapID,
+			Revision: snap.R(7),
+		}
+
+		snaptest.MockSnap(c, `name: some-snap`, si)
+		snapstate.Set(s.state, name, &snapstate.SnapState{
+			Active:   true,
+			Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{si}),
+			Current:  si.Revision,
+		})
+	}
+
+	var coordinated []string
+	snapstate.CoordinateRefreshes = func(st *state.State, refreshes []*snap.Info) (map[string]bool, error) {
+		for _, info := range refreshes {
+			coordinated = append(coordinated, info.InstanceName())
+		}
+		return map[string]bool{"some-snap": true}, nil
+	}
+
+	updates, _, err := snapstate.UpdateMany(context.Background(), s.state, nil, nil, s.user.ID, nil)
+	c.Assert(err, IsNil)
+	c.Check(updates, DeepEquals, []string{"some-other-snap"})
+	c.Check(coordinated, testutil.DeepUnsortedMatches, []string{"some-snap", "some-other-snap"})
+
+	// refreshes of specific snaps are not coordinated
+	coordinated = nil
+	updates, _, err = snapstate.UpdateMany(context.Background(), s.state, []string{"some-snap"}, nil, s.user.ID, nil)
+	c.Assert(err, IsNil)
+	c.Check(updates, DeepEquals, []string{"some-snap"})
+	c.Check(coordinated, HasLen, 0)
+}
+
+func (s *snapmgrTestSuite) TestRefreshAllCoordinateRefreshesError(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	si := &snap.SideInfo{
+		RealName: "some-snap",
+		SnapID:   "some-snap-id",
+		Revision: snap.R(7),
+	}
+	snaptest.MockSnap(c, `name: some-snap`, si)
+	snapstate.Set(s.state, "some-snap", &snapstate.SnapState{
+		Active:   true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{si}),
+		Current:  si.Revision,
+	})
+
+	snapstate.CoordinateRefreshes = func(st *state.State, refreshes []*snap.Info) (map[string]bool, error) {
+		return nil, errors.New("boom")
+	}
+
+	_, _, err := snapstate.UpdateMany(context.Background(), s.state, nil, nil, s.user.ID, nil)
+	c.Assert(err, ErrorMatches, "boom")
+}
+
 func (s *snapmgrTestSuite) TestUpdateManyTransactionalWithLane(c *C) {
 	s.state.Lock()
 	defer s.state.Unlock()
@@ -11979,6 +12068,7 @@ func (s *snapmgrTestSuite) testAutoRefreshRecordsFailures(c *C, afterReboot bool
 	c.Check(getRefreshFailures(badSnap).Revision, Equals, badSnapRevision)
 	c.Check(getRefreshFailures(badSnap).FailureCount, Equals, 1)
 	c.Check(getRefreshFailures(badSnap).LastFailureSeverity, Equals, expectedFailureSeverity)
+	c.Check(getRefreshFailures(badSnap).LastError, Equals, "auto-connect mock error")
 	c.Check(getRevision(badSnap), Equals, snap.R(1))
 	c.Check(getRefreshFailures("some-other-snap"), IsNil)
 	c.Check(getRevision("some-other-snap"), Equals, goodSnapRevision)
@@ -20105,6 +20195,106 @@ func (s *snapmgrTestSuite) TestStopSnapServicesComputesRemovedServices(c *C) {
 	})
 }
 
+func (s *snapmgrTestSuite) TestStopSnapServicesRefreshKeepsIgnoreRunningServices(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	const oldYaml = `name: test-snap
+version: 1.0
+apps:
+ kept-svc:
+  command: bin/service
+  daemon: simple
+  refresh-mode: ignore-running
+ disabled-svc:
+  command: bin/service
+  daemon: simple
+  refresh-mode: ignore-running
+ removed-svc:
+  command: bin/service
+  daemon: simple
+  refresh-mode: ignore-running
+ endure-svc:
+  command: bin/service
+  daemon: simple
+  refresh-mode: endure
+`
+	const newYaml = `name: test-snap
+version: 2.0
+apps:
+ kept-svc:
+  command: bin/service
+  daemon: simple
+  refresh-mode: ignore-running
+ disabled-svc:
+  command: bin/service
+  daemon: simple
+  refresh-mode: ignore-running
+ endure-svc:
+  command: bin/service
+  daemon: simple
+  refresh-mode: endure
+`
+	oldSi := &snap.SideInfo{RealName: "test-snap", Revision: snap.R(11)}
+	oldInfo := snaptest.MockSnap(c, oldYaml, oldSi)
+	snapstate.Set(s.state, "test-snap", &snapstate.SnapState{
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{oldSi}),
+		Current:  snap.R(11),
+		Active:   true,
+	})
+	newSi := &snap.SideInfo{RealName: "test-snap", Revision: snap.R(12)}
+	newInfo := snaptest.MockSnap(c, newYaml, newSi)
+	s.fakeBackend.infos = map[string]map[snap.Revision]*snap.Info{
+		"test-snap": {
+			oldSi.Revision: oldInfo,
+			newSi.Revision: newInfo,
+		},
+	}
+	prevCurrentlyDisabled := s.fakeBackend.servicesCurrentlyDisabled
+	s.fakeBackend.servicesCurrentlyDisabled = []string{"disabled-svc"}
+	defer func() {
+		s.fakeBackend.servicesCurrentlyDisabled = prevCurrentlyDisabled
+	}()
+
+	var marked, cleared []string
+	restore := testutil.Mock(&snapstate.MarkServicesNeedingRestart, func(st *state.State, apps []*snap.AppInfo) error {
+		for _, app := range apps {
+			marked = append(marked, app.String())
+		}
+		return nil
+	})
+	defer restore()
+	restore = testutil.Mock(&snapstate.ClearServicesNeedingRestart, func(st *state.State, snapName string, apps []*snap.AppInfo) error {
+		c.Check(snapName, Equals, "test-snap")
+		for _, app := range apps {
+			cleared = append(cleared, app.String())
+		}
+		return nil
+	})
+	defer restore()
+
+	chg := s.state.NewChange("stop-services", "stop the services")
+	task := s.state.NewTask("stop-snap-services", "Stop snap services")
+	task.Set("stop-reason", snap.StopReasonRefresh)
+	task.Set("snap-setup", &snapstate.SnapSetup{SideInfo: newSi})
+	chg.AddTask(task)
+	terr := s.state.NewTask("error-trigger", "provoking total undo")
+	terr.WaitFor(task)
+	chg.AddTask(terr)
+
+	s.settle(c)
+
+	c.Check(task.Status(), Equals, state.UndoneStatus)
+	// only the enabled service that is kept in the new revision keeps
+	// running the old one
+	c.Check(marked, DeepEquals, []string{"test-snap.kept-svc"})
+	var keptRunning []string
+	c.Assert(task.Get("kept-running-services", &keptRunning), IsNil)
+	c.Check(keptRunning, DeepEquals, []string{"kept-svc"})
+	// which is current again after the undo
+	c.Check(cleared, DeepEquals, []string{"test-snap.kept-svc"})
+}
+
 func (s *snapmgrTestSuite) TestUpdateWithGoalSeedRefresh(c *C) {
 	restore := snapstate.MockRevisionDate(nil)
 	defer restore()
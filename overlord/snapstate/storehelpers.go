@@ -54,6 +54,10 @@ var EnforceValidationSets func(*state.State, map[string]*asserts.ValidationSet,
 // have been already validated by inclusion in the assertion database. It's hooked from assertstate.
 var ValidatedIntegrityData func(*state.State, string, snap.Revision) (*integrity.IntegrityDataParams, error)
 
+// SnapRevisionDigest allows to hook looking up the snap file digest listed in
+// the snap-revision assertion of a snap revision. It's hooked from assertstate.
+var SnapRevisionDigest func(st *state.State, snapID string, rev snap.Revision) (string, error)
+
 func userIDForSnap(st *state.State, snapst *SnapState, fallbackUserID int) (int, error) {
 	userID := snapst.UserID
 	_, err := auth.User(st, userID)
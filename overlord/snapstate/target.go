@@ -290,6 +290,10 @@ func (t *target) setups(st *state.State, opts Options) (SnapSetup, []ComponentSe
 	}
 
 	providerContentAttrs := defaultProviderContentAttrs(st, t.info, opts.PrereqTracker)
+	providerTracks, err := defaultProviderTracks(t.info, providerContentAttrs)
+	if err != nil {
+		return SnapSetup{}, nil, err
+	}
 
 	snapsup := SnapSetup{
 		Channel:      t.setup.Channel,
@@ -301,6 +305,7 @@ func (t *target) setups(st *state.State, opts Options) (SnapSetup, []ComponentSe
 		Base:               t.info.Base,
 		Prereq:             keys(providerContentAttrs),
 		PrereqContentAttrs: providerContentAttrs,
+		PrereqTracks:       providerTracks,
 		UserID:             snapUserID,
 		Flags:              flags.ForSnapSetup(),
 		SideInfo:           &t.info.SideInfo,
@@ -892,6 +897,7 @@ func InstallWithGoal(ctx context.Context, st *state.State, goal InstallGoal, opt
 	tasksets := make([]*state.TaskSet, 0, len(targets))
 	infos := make([]*snap.Info, 0, len(targets))
 	snapLanes := make(map[int]bool)
+	providerTrackHints := make(map[string]providerTrackHint)
 	for _, t := range targets {
 		if t.setup.SnapPath != "" && t.setup.DownloadInfo != nil {
 			return nil, nil, errors.New("internal error: target cannot specify both a path and a download info")
@@ -908,6 +914,10 @@ func InstallWithGoal(ctx context.Context, st *state.State, goal InstallGoal, opt
 			return nil, nil, err
 		}
 
+		if err := checkProviderTrackHints(st, providerTrackHints, &snapsup); err != nil {
+			return nil, nil, err
+		}
+
 		installTS, err := doInstallOrPreDownload(st, &t.snapst, &snapsup, compsups, installContext{
 			ConflictOptions: opts.ConflictOptions,
 			DeviceCtx:       opts.DeviceCtx,
@@ -938,6 +948,45 @@ func InstallWithGoal(ctx context.Context, st *state.State, goal InstallGoal, opt
 	return infos, tasksets, nil
 }
 
+// providerTrackHint is the track of a default provider preferred by one of
+// the snaps being installed together.
+type providerTrackHint struct {
+	consumer string
+	track    string
+}
+
+// checkProviderTrackHints checks that the track hints of the default providers
+// of the given snap agree with the ones of the other snaps being installed in
+// the same operation, which are collected in hints. Only one track of a
+// missing default provider can be installed, so rather than having the first
+// snap to run its prerequisites win, ask the user to resolve the conflict.
+func checkProviderTrackHints(st *state.State, hints map[string]providerTrackHint, snapsup *SnapSetup) error {
+	providers := keys(snapsup.PrereqTracks)
+	sort.Strings(providers)
+	for _, provider := range providers {
+		track := snapsup.PrereqTracks[provider]
+		other, ok := hints[provider]
+		if !ok {
+			hints[provider] = providerTrackHint{consumer: snapsup.InstanceName(), track: track}
+			continue
+		}
+		if other.track == track {
+			continue
+		}
+		// the hints only matter when the provider needs to be installed
+		installed, err := installedProviderInstance(st, provider)
+		if err != nil {
+			return err
+		}
+		if installed != "" {
+			continue
+		}
+		return fmt.Errorf("cannot install snaps %q and %q together: they prefer different tracks (%q and %q) of missing default provider %q, install it from the wanted track first",
+			other.consumer, snapsup.InstanceName(), other.track, track, provider)
+	}
+	return nil
+}
+
 // generateLane returns the lane to use for the tasks that all operate on a
 // single snap. If the transaction is set to "all-snaps", then the lane is
 // explicitly set to the lane provided in the options. If the transaction is set
@@ -1178,6 +1227,24 @@ func (p *updatePlan) filterHeldSnaps(st *state.State, opts Options) error {
 	return nil
 }
 
+// filterCoordinatedSnaps removes any targets from the update plan whose
+// refreshes are coordinated with other devices. If the update plan is not
+// refreshing all snaps, then this function does nothing.
+func (p *updatePlan) filterCoordinatedSnaps(st *state.State) error {
+	if CoordinateRefreshes == nil || !p.refreshAll() || len(p.targets) == 0 {
+		return nil
+	}
+
+	coordinated, err := CoordinateRefreshes(st, p.targetInfos())
+	if err != nil {
+		return err
+	}
+
+	return p.filter(func(t target) (bool, error) {
+		return !coordinated[t.info.InstanceName()], nil
+	})
+}
+
 // validateAndFilterTargets validates the targets in the update plan against
 // refresh control validation assertions. Any targets that cannot be validated
 // are removed from the update plan.
@@ -1306,6 +1373,11 @@ func UpdateWithGoal(ctx context.Context, st *state.State, goal UpdateGoal, filte
 		return nil, nil, err
 	}
 
+	// filter out snaps whose refreshes are coordinated with other devices
+	if err := plan.filterCoordinatedSnaps(st); err != nil {
+		return nil, nil, err
+	}
+
 	changeKind := "refresh"
 	installInfos := make([]minimalInstallInfo, 0, len(plan.targets))
 	for _, t := range plan.targets {
@@ -151,6 +151,11 @@ type Change struct {
 	lastObservedStatus       Status
 	lastRecordedNoticeStatus Status
 
+	// updated and updateID are not serialized, they are only used during
+	// runtime for watching the change
+	updated  chan struct{}
+	updateID int
+
 	spawnTime time.Time
 	readyTime time.Time
 }
@@ -285,6 +290,24 @@ func (c *Change) Has(key string) bool {
 	return c.data.has(key)
 }
 
+// LogAttrs returns the attributes identifying the change, to be recorded
+// alongside log messages about it with logger.NoticeAttrs or
+// logger.DebugAttrs. Besides its ID and kind, they carry the ID of the API
+// request the change was created for and the names of the snaps it is
+// about, when known.
+func (c *Change) LogAttrs() []any {
+	attrs := []any{"change", c.id, "change-kind", c.kind}
+	var requestID string
+	if err := c.Get("request-id", &requestID); err == nil && requestID != "" {
+		attrs = append(attrs, "request-id", requestID)
+	}
+	var snapNames []string
+	if err := c.Get("snap-names", &snapNames); err == nil && len(snapNames) > 0 {
+		attrs = append(attrs, "snap-names", strings.Join(snapNames, ","))
+	}
+	return attrs
+}
+
 var statusOrder = []Status{
 	AbortStatus,
 	UndoingStatus,
@@ -467,6 +490,7 @@ func (c *Change) SetStatus(s Status) {
 		c.markReady()
 	}
 	c.notifyStatusChange(c.Status())
+	c.notifyUpdated()
 }
 
 func (c *Change) markReady() {
@@ -485,6 +509,35 @@ func (c *Change) Ready() <-chan struct{} {
 	return c.ready
 }
 
+// Updated returns a channel that is closed the next time the change or
+// one of its tasks is updated, that is when a status, progress or log
+// changes or when a task is added. A new channel must be obtained to wait
+// for any further update.
+func (c *Change) Updated() <-chan struct{} {
+	c.state.reading()
+	if c.updated == nil {
+		c.updated = make(chan struct{})
+	}
+	return c.updated
+}
+
+// UpdateID returns an identifier of the last update of the change or of
+// one of its tasks. It is not persisted and only meaningful while the
+// state is loaded in memory.
+func (c *Change) UpdateID() int {
+	c.state.reading()
+	return c.updateID
+}
+
+func (c *Change) notifyUpdated() {
+	c.state.lastChangeUpdateId++
+	c.updateID = c.state.lastChangeUpdateId
+	if c.updated != nil {
+		close(c.updated)
+		c.updated = nil
+	}
+}
+
 func (c *Change) detectChangeReady(excludeTask *Task) {
 	for _, tid := range c.taskIDs {
 		task := c.state.tasks[tid]
@@ -505,6 +558,7 @@ func (c *Change) detectChangeReady(excludeTask *Task) {
 // to give the opportunity for the change to close its ready channel, and
 // notify observers of Change changes.
 func (c *Change) taskStatusChanged(t *Task, old, new Status) {
+	c.notifyUpdated()
 	cs := c.Status()
 	// If the task changes from ready => unready or unready => ready,
 	// update the ready status for the change.
@@ -632,6 +686,7 @@ func (c *Change) AddTask(t *Task) {
 	}
 	t.change = c.id
 	c.taskIDs = addOnce(c.taskIDs, t.ID())
+	c.notifyUpdated()
 }
 
 // AddAll registers all tasks in the set as required for the state
@@ -116,6 +116,22 @@ func (cs *changeSuite) TestHas(c *C) {
 
 // TODO Better testing of full change roundtripping via JSON.
 
+func (cs *changeSuite) TestLogAttrs(c *C) {
+	st := state.New(nil)
+	st.Lock()
+	defer st.Unlock()
+
+	chg := st.NewChange("refresh-snap", "...")
+	c.Check(chg.LogAttrs(), DeepEquals, []any{"change", chg.ID(), "change-kind", "refresh-snap"})
+
+	chg.Set("request-id", "req-1")
+	chg.Set("snap-names", []string{"foo", "bar"})
+	c.Check(chg.LogAttrs(), DeepEquals, []any{
+		"change", chg.ID(), "change-kind", "refresh-snap",
+		"request-id", "req-1", "snap-names", "foo,bar",
+	})
+}
+
 func (cs *changeSuite) TestNewTaskAddTaskAndTasks(c *C) {
 	st := state.New(nil)
 	st.Lock()
@@ -354,6 +370,69 @@ func (cs *changeSuite) TestCloseReadyWhenTasksReady(c *C) {
 	c.Assert(chg.IsReady(), Equals, true)
 }
 
+func (cs *changeSuite) TestUpdated(c *C) {
+	st := state.New(nil)
+	st.Lock()
+	defer st.Unlock()
+
+	chg := st.NewChange("install", "...")
+	c.Check(chg.UpdateID(), Equals, 0)
+
+	checkUpdated := func(updated <-chan struct{}, lastID int) int {
+		select {
+		case <-updated:
+		default:
+			c.Fatalf("Change should have been updated")
+		}
+		c.Check(chg.UpdateID() > lastID, Equals, true)
+		return chg.UpdateID()
+	}
+
+	updated := chg.Updated()
+	// the channel is reused until the next update
+	c.Check(chg.Updated(), Equals, updated)
+	select {
+	case <-updated:
+		c.Fatalf("Change should not have been updated")
+	default:
+	}
+
+	t := st.NewTask("download", "...")
+	chg.AddTask(t)
+	lastID := checkUpdated(updated, 0)
+
+	updated = chg.Updated()
+	t.SetStatus(state.DoingStatus)
+	lastID = checkUpdated(updated, lastID)
+
+	updated = chg.Updated()
+	t.SetProgress("x", 1, 10)
+	lastID = checkUpdated(updated, lastID)
+
+	updated = chg.Updated()
+	t.Logf("some log")
+	lastID = checkUpdated(updated, lastID)
+
+	updated = chg.Updated()
+	chg.SetStatus(state.ErrorStatus)
+	checkUpdated(updated, lastID)
+
+	// tasks of other changes do not update the change
+	other := st.NewChange("other", "...")
+	t2 := st.NewTask("download", "...")
+	other.AddTask(t2)
+	lastID = chg.UpdateID()
+	updated = chg.Updated()
+	t2.SetStatus(state.DoingStatus)
+	t2.Logf("some log")
+	select {
+	case <-updated:
+		c.Fatalf("Change should not have been updated")
+	default:
+	}
+	c.Check(chg.UpdateID(), Equals, lastID)
+}
+
 func (cs *changeSuite) TestIsClean(c *C) {
 	st := state.New(nil)
 	st.Lock()
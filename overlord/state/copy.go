@@ -24,6 +24,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -113,9 +114,21 @@ func CopyState(srcStatePath, dstStatePath string, dataEntries []string) error {
 	}
 	defer f.Close()
 
+	// the log of changes of the state, if any, is kept next to it, see
+	// dirs.SnapStateLogFile
+	var log io.Reader
+	logFile, err := os.Open(filepath.Join(filepath.Dir(srcStatePath), "state.log"))
+	switch {
+	case err == nil:
+		defer logFile.Close()
+		log = logFile
+	case !os.IsNotExist(err):
+		return fmt.Errorf("cannot open state log: %s", err)
+	}
+
 	// No need to lock/unlock the state here, srcState should not be
 	// in use at all.
-	srcState, err := ReadState(nil, f)
+	srcState, err := ReadStateWithLog(nil, f, log)
 	if err != nil {
 		return err
 	}
@@ -134,6 +134,23 @@ func (ss *stateSuite) TestCopyState(c *C) {
 	c.Check(string(dstContent), Equals, `{"data":{"A":{"B":[{"C":1},{"D":2}]},"E":{"F":2,"G":3},"I":null}`+stateSuffix)
 }
 
+func (ss *stateSuite) TestCopyStateWithLog(c *C) {
+	srcDir := c.MkDir()
+	srcStateFile := filepath.Join(srcDir, "state.json")
+	err := os.WriteFile(srcStateFile, []byte(`{"data":{"A":1,"B":2},"log-generation":1}`), 0644)
+	c.Assert(err, IsNil)
+	log := state.NewStateLog(filepath.Join(srcDir, "state.log"))
+	c.Assert(log.Append(1, []byte(`{"data":{"A":3}}`)), IsNil)
+
+	dstStateFile := filepath.Join(c.MkDir(), "dst-state.json")
+	err = state.CopyState(srcStateFile, dstStateFile, []string{"A", "B"})
+	c.Assert(err, IsNil)
+
+	dstContent, err := os.ReadFile(dstStateFile)
+	c.Assert(err, IsNil)
+	c.Check(string(dstContent), Equals, `{"data":{"A":3,"B":2}`+stateSuffix)
+}
+
 func (ss *stateSuite) TestCopyStateUnmarshalNotMap(c *C) {
 	srcStateFile := filepath.Join(c.MkDir(), "src-state.json")
 	err := os.WriteFile(srcStateFile, srcStateContent1, 0644)
@@ -76,3 +76,11 @@ func (s *State) NumNotices() int {
 func (s *State) GetLastNoticeTimestamp() time.Time {
 	return s.getLastNoticeTimestamp()
 }
+
+func MockStateLogMinCompactionSize(size int) (restore func()) {
+	old := stateLogMinCompactionSize
+	stateLogMinCompactionSize = size
+	return func() {
+		stateLogMinCompactionSize = old
+	}
+}
@@ -307,11 +307,21 @@ const (
 	// expired. The key for interfaces-requests-rule-update notices is the
 	// rule ID.
 	InterfacesRequestsRuleUpdateNotice NoticeType = "interfaces-requests-rule-update"
+
+	// Recorded whenever manual connections of a previous installation of a
+	// snap are restored when the snap is installed again. The key for
+	// interfaces-connections-restored notices is the snap instance name.
+	InterfacesConnectionsRestoredNotice NoticeType = "interfaces-connections-restored"
+
+	// Recorded whenever a snap is switched away from a track that reached
+	// its end of life to its successor track. The key for
+	// snap-track-migration notices is the snap instance name.
+	SnapTrackMigrationNotice NoticeType = "snap-track-migration"
 )
 
 func (t NoticeType) Valid() bool {
 	switch t {
-	case ChangeUpdateNotice, WarningNotice, RefreshInhibitNotice, SnapRunInhibitNotice, InterfacesRequestsPromptNotice, InterfacesRequestsRuleUpdateNotice:
+	case ChangeUpdateNotice, WarningNotice, RefreshInhibitNotice, SnapRunInhibitNotice, InterfacesRequestsPromptNotice, InterfacesRequestsRuleUpdateNotice, InterfacesConnectionsRestoredNotice, SnapTrackMigrationNotice:
 		return true
 	}
 	return false
@@ -0,0 +1,309 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package state
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// sealedPrefix marks the values of sensitive fields which are sealed, the
+// rest of the value is the base64 encoding of the nonce followed by the
+// AES-GCM ciphertext
+const sealedPrefix = "sealed:v1:"
+
+// redactedValue replaces the values of sensitive fields in redacted output
+const redactedValue = "[redacted]"
+
+// SealingKeySize is the size of the key used to seal sensitive fields.
+const SealingKeySize = 32
+
+var (
+	sensitiveMu sync.RWMutex
+	// sensitive maps state data keys to the paths of the sensitive fields
+	// of their values
+	sensitive = make(map[string][][]string)
+)
+
+// RegisterSensitive marks fields of the value stored in the state under key
+// as sensitive. Each path is a dot separated list of object members leading
+// to a string field, with "*" matching any member of an object or element
+// of an array, e.g. "users.*.macaroon". The key itself must be given as an
+// empty path if its whole value is a sensitive string.
+//
+// Sensitive fields are sealed when the state is persisted, if a sealing key
+// is in use, and are redacted by RedactedJSON.
+func RegisterSensitive(key string, paths ...string) {
+	sensitiveMu.Lock()
+	defer sensitiveMu.Unlock()
+
+	for _, p := range paths {
+		var path []string
+		if p != "" {
+			path = strings.Split(p, ".")
+		}
+		sensitive[key] = append(sensitive[key], path)
+	}
+}
+
+func sensitivePaths(key string) [][]string {
+	sensitiveMu.RLock()
+	defer sensitiveMu.RUnlock()
+	return sensitive[key]
+}
+
+// sealedEntry caches the sealed form of a data entry, entries of data are
+// replaced, never modified in place, by Set so the sealed form can be reused
+// for as long as the same entry is in place
+type sealedEntry struct {
+	plain  *json.RawMessage
+	sealed *json.RawMessage
+}
+
+// UseSealingKey sets the key used to seal the sensitive fields of the state,
+// see RegisterSensitive. The sensitive fields read from disk which are sealed
+// are unsealed with it, which fails without a key. If seal is true, the
+// sensitive fields are then sealed whenever the state is persisted, otherwise
+// they are persisted in plain text again, from the next checkpoint on.
+//
+// It must be called before the state is shared, right after it is read, and
+// it does not need the state lock.
+func (s *State) UseSealingKey(key []byte, seal bool) error {
+	var aead cipher.AEAD
+	if key != nil {
+		if len(key) != SealingKeySize {
+			return fmt.Errorf("cannot use sealing key: invalid key size %d", len(key))
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return fmt.Errorf("cannot use sealing key: %v", err)
+		}
+		aead, err = cipher.NewGCM(block)
+		if err != nil {
+			return fmt.Errorf("cannot use sealing key: %v", err)
+		}
+	}
+	if seal && aead == nil {
+		return errors.New("cannot seal sensitive state fields without a key")
+	}
+
+	unsealed := make(customData, len(s.data))
+	for key, value := range s.data {
+		paths := sensitivePaths(key)
+		if len(paths) == 0 || value == nil {
+			unsealed[key] = value
+			continue
+		}
+		plain, err := transformSensitive(value, paths, func(v string) (string, error) {
+			return unsealValue(aead, key, v)
+		})
+		if err != nil {
+			return fmt.Errorf("cannot unseal state entry %q: %v", key, err)
+		}
+		unsealed[key] = plain
+	}
+
+	s.data = unsealed
+	s.sealingAEAD = nil
+	if seal {
+		s.sealingAEAD = aead
+	}
+	s.sealed = make(map[string]sealedEntry)
+	return nil
+}
+
+// persistedData returns the data of the state as it is persisted, with the
+// sensitive fields sealed if a sealing key is in use.
+func (s *State) persistedData() customData {
+	if s.sealingAEAD == nil {
+		return s.data
+	}
+	persisted := make(customData, len(s.data))
+	for key, value := range s.data {
+		persisted[key] = s.sealedEntry(key, value)
+	}
+	return persisted
+}
+
+func (s *State) sealedEntry(key string, value *json.RawMessage) *json.RawMessage {
+	paths := sensitivePaths(key)
+	if len(paths) == 0 || value == nil {
+		return value
+	}
+	if cached, ok := s.sealed[key]; ok && cached.plain == value {
+		return cached.sealed
+	}
+	sealed, err := transformSensitive(value, paths, func(v string) (string, error) {
+		return sealValue(s.sealingAEAD, key, v)
+	})
+	if err != nil {
+		// the entry was serialized by Set, this shouldn't happen
+		panic(fmt.Sprintf("internal error: cannot seal state entry %q: %v", key, err))
+	}
+	s.sealed[key] = sealedEntry{plain: value, sealed: sealed}
+	return sealed
+}
+
+func sealValue(aead cipher.AEAD, key, value string) (string, error) {
+	if strings.HasPrefix(value, sealedPrefix) {
+		return value, nil
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	// the data key is authenticated as well, so that a sealed value
+	// cannot be moved around to another entry
+	sealed := aead.Seal(nonce, nonce, []byte(value), []byte(key))
+	return sealedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func unsealValue(aead cipher.AEAD, key, value string) (string, error) {
+	if !strings.HasPrefix(value, sealedPrefix) {
+		return value, nil
+	}
+	if aead == nil {
+		return "", errors.New("value is sealed but no key is available")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(value[len(sealedPrefix):])
+	if err != nil {
+		return "", fmt.Errorf("invalid sealed value: %v", err)
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", errors.New("invalid sealed value: too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, ciphertext, []byte(key))
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+// RedactedJSON returns the state serialized like MarshalJSON does, but with
+// the values of all sensitive fields, sealed or not, replaced. The result is
+// suitable for inspection, but not for reading the state back.
+func (s *State) RedactedJSON() ([]byte, error) {
+	s.reading()
+
+	redacted := make(customData, len(s.data))
+	for key, value := range s.data {
+		paths := sensitivePaths(key)
+		if len(paths) == 0 || value == nil {
+			redacted[key] = value
+			continue
+		}
+		v, err := transformSensitive(value, paths, func(string) (string, error) {
+			return redactedValue, nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("cannot redact state entry %q: %v", key, err)
+		}
+		redacted[key] = v
+	}
+	return s.marshal(redacted)
+}
+
+// transformSensitive returns raw with f applied to the string values found
+// at the given paths. raw itself is returned if nothing was changed.
+func transformSensitive(raw *json.RawMessage, paths [][]string, f func(string) (string, error)) (*json.RawMessage, error) {
+	dec := json.NewDecoder(bytes.NewReader(*raw))
+	// keep numbers as they are
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	changed := false
+	for _, path := range paths {
+		var err error
+		v, err = transformPath(v, path, func(s string) (string, error) {
+			t, err := f(s)
+			if err != nil {
+				return "", err
+			}
+			if t != s {
+				changed = true
+			}
+			return t, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !changed {
+		return raw, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	transformed := json.RawMessage(data)
+	return &transformed, nil
+}
+
+func transformPath(v any, path []string, f func(string) (string, error)) (any, error) {
+	if len(path) == 0 {
+		s, ok := v.(string)
+		if !ok {
+			// not set, or not a string
+			return v, nil
+		}
+		return f(s)
+	}
+
+	elem, rest := path[0], path[1:]
+	switch v := v.(type) {
+	case map[string]any:
+		for member, value := range v {
+			if elem != "*" && elem != member {
+				continue
+			}
+			t, err := transformPath(value, rest, f)
+			if err != nil {
+				return nil, err
+			}
+			v[member] = t
+		}
+	case []any:
+		if elem != "*" {
+			return v, nil
+		}
+		for i, value := range v {
+			t, err := transformPath(value, rest, f)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = t
+		}
+	}
+	return v, nil
+}
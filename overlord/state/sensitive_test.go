@@ -0,0 +1,276 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package state_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type sensitiveSuite struct {
+	key []byte
+}
+
+var _ = Suite(&sensitiveSuite{})
+
+type sensitiveUser struct {
+	ID       int64    `json:"id"`
+	Name     string   `json:"name"`
+	Macaroon string   `json:"macaroon,omitempty"`
+	Tokens   []string `json:"tokens,omitempty"`
+}
+
+type sensitiveData struct {
+	Key   []byte          `json:"key,omitempty"`
+	Users []sensitiveUser `json:"users"`
+}
+
+func (s *sensitiveSuite) SetUpSuite(c *C) {
+	state.RegisterSensitive("sensitive-test", "key", "users.*.macaroon", "users.*.tokens.*")
+	state.RegisterSensitive("sensitive-string", "")
+}
+
+func (s *sensitiveSuite) SetUpTest(c *C) {
+	s.key = bytes.Repeat([]byte{1}, state.SealingKeySize)
+}
+
+var testSensitiveData = sensitiveData{
+	Key: []byte("secret-key"),
+	Users: []sensitiveUser{
+		// big IDs do not lose precision
+		{ID: 1 << 60, Name: "foo", Macaroon: "foo-macaroon", Tokens: []string{"foo-token"}},
+		{ID: 2, Name: "bar"},
+	},
+}
+
+func checkNoSecrets(c *C, data []byte) {
+	for _, secret := range []string{"foo-macaroon", "foo-token", "secret-key", "c2VjcmV0LWtleQ==", "also-secret"} {
+		c.Check(strings.Contains(string(data), secret), Equals, false, Commentf("%q found in %s", secret, data))
+	}
+}
+
+func (s *sensitiveSuite) TestNotSealedByDefault(c *C) {
+	b := new(fakeStateBackend)
+	st := state.New(b)
+	st.Lock()
+	st.Set("sensitive-test", testSensitiveData)
+	st.Unlock()
+
+	c.Assert(b.checkpoints, HasLen, 1)
+	c.Check(string(b.checkpoints[0]), testutil.Contains, `"macaroon":"foo-macaroon"`)
+}
+
+func (s *sensitiveSuite) TestSealAndUnseal(c *C) {
+	b := new(fakeStateBackend)
+	st := state.New(b)
+	st.Lock()
+	c.Assert(st.UseSealingKey(s.key, true), IsNil)
+	st.Set("sensitive-test", testSensitiveData)
+	st.Set("sensitive-string", "also-secret")
+	st.Set("other", "not-secret")
+
+	// the state in memory is not sealed
+	var data sensitiveData
+	c.Assert(st.Get("sensitive-test", &data), IsNil)
+	c.Check(data, DeepEquals, testSensitiveData)
+	st.Unlock()
+
+	c.Assert(b.checkpoints, HasLen, 1)
+	checkpoint := b.checkpoints[0]
+	checkNoSecrets(c, checkpoint)
+	c.Check(string(checkpoint), testutil.Contains, `"name":"foo"`)
+	c.Check(string(checkpoint), testutil.Contains, `"id":1152921504606846976`)
+	c.Check(string(checkpoint), testutil.Contains, `"other":"not-secret"`)
+	c.Check(strings.Count(string(checkpoint), "sealed:v1:"), Equals, 4)
+
+	// sealed values are left alone when no key is available
+	st2, err := state.ReadState(nil, bytes.NewReader(checkpoint))
+	c.Assert(err, IsNil)
+	st2.Lock()
+	defer st2.Unlock()
+	var sealed sensitiveData
+	c.Assert(st2.Get("sensitive-test", &sealed), ErrorMatches, `.*could not unmarshal state entry "sensitive-test".*`)
+	var str string
+	c.Assert(st2.Get("sensitive-string", &str), IsNil)
+	c.Check(str, Matches, "sealed:v1:.*")
+	data2, err := st2.MarshalJSON()
+	c.Assert(err, IsNil)
+	checkNoSecrets(c, data2)
+
+	// and unsealed with the key
+	c.Assert(st2.UseSealingKey(s.key, false), IsNil)
+	c.Assert(st2.Get("sensitive-test", &data), IsNil)
+	c.Check(data, DeepEquals, testSensitiveData)
+	c.Assert(st2.Get("sensitive-string", &str), IsNil)
+	c.Check(str, Equals, "also-secret")
+
+	// which are not sealed anymore when persisted without sealing
+	data2, err = st2.MarshalJSON()
+	c.Assert(err, IsNil)
+	c.Check(string(data2), testutil.Contains, `"macaroon":"foo-macaroon"`)
+	c.Check(string(data2), testutil.Contains, `"sensitive-string":"also-secret"`)
+}
+
+func (s *sensitiveSuite) TestSealedFormIsStable(c *C) {
+	st := state.New(nil)
+	st.Lock()
+	defer st.Unlock()
+	c.Assert(st.UseSealingKey(s.key, true), IsNil)
+	st.Set("sensitive-test", testSensitiveData)
+
+	data1, err := st.MarshalJSON()
+	c.Assert(err, IsNil)
+	data2, err := st.MarshalJSON()
+	c.Assert(err, IsNil)
+	c.Check(data1, DeepEquals, data2)
+
+	// until the entry is set again
+	st.Set("sensitive-test", testSensitiveData)
+	data3, err := st.MarshalJSON()
+	c.Assert(err, IsNil)
+	c.Check(data3, Not(DeepEquals), data1)
+}
+
+func (s *sensitiveSuite) TestUnsealErrors(c *C) {
+	st := state.New(nil)
+	st.Lock()
+	c.Assert(st.UseSealingKey(s.key, true), IsNil)
+	st.Set("sensitive-test", testSensitiveData)
+	checkpoint, err := st.MarshalJSON()
+	c.Assert(err, IsNil)
+	st.Unlock()
+
+	st, err = state.ReadState(nil, bytes.NewReader(checkpoint))
+	c.Assert(err, IsNil)
+	st.Lock()
+	defer st.Unlock()
+
+	err = st.UseSealingKey(nil, false)
+	c.Check(err, ErrorMatches, `cannot unseal state entry "sensitive-test": value is sealed but no key is available`)
+
+	err = st.UseSealingKey(bytes.Repeat([]byte{2}, state.SealingKeySize), false)
+	c.Check(err, ErrorMatches, `cannot unseal state entry "sensitive-test": cipher: message authentication failed`)
+
+	err = st.UseSealingKey([]byte("short"), false)
+	c.Check(err, ErrorMatches, `cannot use sealing key: invalid key size 5`)
+
+	err = st.UseSealingKey(nil, true)
+	c.Check(err, ErrorMatches, `cannot seal sensitive state fields without a key`)
+
+	// the sealed state is left untouched
+	data, err := st.MarshalJSON()
+	c.Assert(err, IsNil)
+	c.Check(data, DeepEquals, checkpoint)
+}
+
+func (s *sensitiveSuite) TestSealedValuesCannotBeMoved(c *C) {
+	st := state.New(nil)
+	st.Lock()
+	c.Assert(st.UseSealingKey(s.key, true), IsNil)
+	st.Set("sensitive-string", "also-secret")
+	checkpoint, err := st.MarshalJSON()
+	c.Assert(err, IsNil)
+	st.Unlock()
+
+	var raw map[string]any
+	c.Assert(json.Unmarshal(checkpoint, &raw), IsNil)
+	data := raw["data"].(map[string]any)
+	data["sensitive-test"] = map[string]any{"key": data["sensitive-string"]}
+	checkpoint, err = json.Marshal(raw)
+	c.Assert(err, IsNil)
+
+	st, err = state.ReadState(nil, bytes.NewReader(checkpoint))
+	c.Assert(err, IsNil)
+	st.Lock()
+	defer st.Unlock()
+	err = st.UseSealingKey(s.key, true)
+	c.Check(err, ErrorMatches, `cannot unseal state entry "sensitive-test": cipher: message authentication failed`)
+}
+
+func (s *sensitiveSuite) TestSealedLog(c *C) {
+	b := newFakeLogBackend(c)
+	st := state.New(b)
+	st.Lock()
+	c.Assert(st.UseSealingKey(s.key, true), IsNil)
+	st.Set("other", 1)
+	st.Unlock()
+
+	st.Lock()
+	st.Set("sensitive-test", testSensitiveData)
+	st.Unlock()
+
+	st.Lock()
+	st.Set("other", 2)
+	st.Unlock()
+
+	c.Assert(b.records, HasLen, 2)
+	checkNoSecrets(c, b.records[0])
+	// the unchanged sealed entry is not logged again
+	c.Check(string(b.records[1]), Not(testutil.Contains), "sensitive-test")
+
+	st2 := b.readState(c)
+	st2.Lock()
+	defer st2.Unlock()
+	c.Assert(st2.UseSealingKey(s.key, true), IsNil)
+	var data sensitiveData
+	c.Assert(st2.Get("sensitive-test", &data), IsNil)
+	c.Check(data, DeepEquals, testSensitiveData)
+}
+
+func (s *sensitiveSuite) TestRedactedJSON(c *C) {
+	for _, sealed := range []bool{false, true} {
+		st := state.New(nil)
+		st.Lock()
+		if sealed {
+			c.Assert(st.UseSealingKey(s.key, true), IsNil)
+		}
+		st.Set("sensitive-test", testSensitiveData)
+		st.Set("sensitive-string", "also-secret")
+		st.Set("other", "not-secret")
+
+		data, err := st.RedactedJSON()
+		st.Unlock()
+		c.Assert(err, IsNil)
+
+		checkNoSecrets(c, data)
+		c.Check(strings.Contains(string(data), "sealed:"), Equals, false)
+		var redacted struct {
+			Data struct {
+				Test   map[string]any `json:"sensitive-test"`
+				String string         `json:"sensitive-string"`
+				Other  string         `json:"other"`
+			} `json:"data"`
+		}
+		c.Assert(json.Unmarshal(data, &redacted), IsNil)
+		c.Check(redacted.Data.Test["key"], Equals, "[redacted]")
+		c.Check(redacted.Data.Test["users"], DeepEquals, []any{
+			map[string]any{"id": float64(1 << 60), "name": "foo", "macaroon": "[redacted]", "tokens": []any{"[redacted]"}},
+			map[string]any{"id": float64(2), "name": "bar"},
+		})
+		c.Check(redacted.Data.String, Equals, "[redacted]")
+		c.Check(redacted.Data.Other, Equals, "not-secret")
+	}
+}
@@ -21,6 +21,7 @@
 package state
 
 import (
+	"crypto/cipher"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -92,6 +93,9 @@ type State struct {
 	// lastHandlerId is not serialized, it's only used during runtime
 	// for registering runtime callbacks
 	lastHandlerId int
+	// lastChangeUpdateId is not serialized, it's only used during runtime
+	// to identify updates of changes for watching them
+	lastChangeUpdateId int
 
 	// lastNoticeTimestamp is protected by a mutex, and is unique and
 	// monotonically increasing timestamp. It is still saved to disk for
@@ -122,6 +126,24 @@ type State struct {
 
 	modified bool
 
+	// logGeneration identifies the last full checkpoint through a
+	// LogBackend, the records appended to the log since belong to it
+	logGeneration int
+	// logged holds the entries of the state as last persisted through a
+	// LogBackend, it is nil until the state is checkpointed in full
+	logged *stateEntries
+	// logSize is the size of the records appended to the log since the
+	// last full checkpoint, which is compacted past logCompactionSize
+	logSize           int
+	logCompactionSize int
+	// logPending is set when the state file lacks changes held by the log
+	logPending bool
+
+	// sealingAEAD seals the sensitive fields of the data when persisted,
+	// if set, with sealed caching the sealed form of the entries
+	sealingAEAD cipher.AEAD
+	sealed      map[string]sealedEntry
+
 	cache map[any]any
 
 	pendingChangeByAttr map[string]func(*Change) bool
@@ -143,6 +165,7 @@ func New(backend Backend) *State {
 		tasks:               make(map[string]*Task),
 		warnings:            make(map[string]*Warning),
 		notices:             make(map[noticeKey]*Notice),
+		sealed:              make(map[string]sealedEntry),
 		modified:            true,
 		cache:               make(map[any]any),
 		pendingChangeByAttr: make(map[string]func(*Change) bool),
@@ -204,13 +227,19 @@ type marshalledState struct {
 	LastNoticeId int `json:"last-notice-id"`
 
 	LastNoticeTimestamp time.Time `json:"last-notice-timestamp,omitzero"`
+
+	LogGeneration int `json:"log-generation,omitempty"`
 }
 
 // MarshalJSON makes State a json.Marshaller
 func (s *State) MarshalJSON() ([]byte, error) {
 	s.reading()
+	return s.marshal(s.persistedData())
+}
+
+func (s *State) marshal(data customData) ([]byte, error) {
 	return json.Marshal(marshalledState{
-		Data:     s.data,
+		Data:     data,
 		Changes:  s.changes,
 		Tasks:    s.tasks,
 		Warnings: s.flattenWarnings(),
@@ -222,6 +251,8 @@ func (s *State) MarshalJSON() ([]byte, error) {
 		LastNoticeId: s.lastNoticeId,
 
 		LastNoticeTimestamp: s.getLastNoticeTimestamp(),
+
+		LogGeneration: s.logGeneration,
 	})
 }
 
@@ -242,6 +273,7 @@ func (s *State) UnmarshalJSON(data []byte) error {
 	s.lastTaskId = unmarshalled.LastTaskId
 	s.lastLaneId = unmarshalled.LastLaneId
 	s.lastNoticeId = unmarshalled.LastNoticeId
+	s.logGeneration = unmarshalled.LogGeneration
 	// Update the last notice timestamp if the one saved to disk is later.
 	// The timestamp on disk is only guaranteed to reflect the most recent
 	// timestamp of notices which are stored in state, since state lock was
@@ -273,6 +305,18 @@ func (s *State) checkpointData() []byte {
 	return data
 }
 
+// checkpointer returns a function persisting the current state through
+// the backend.
+func (s *State) checkpointer() func() error {
+	if lb, ok := s.backend.(LogBackend); ok {
+		return s.logCheckpointer(lb)
+	}
+	data := s.checkpointData()
+	return func() error {
+		return s.backend.Checkpoint(data)
+	}
+}
+
 // unlock checkpoint retry parameters (5 mins of retries by default)
 var (
 	unlockCheckpointRetryMaxTime  = 5 * time.Minute
@@ -298,11 +342,11 @@ func (s *State) Unlock() {
 		return
 	}
 
-	data := s.checkpointData()
+	checkpoint := s.checkpointer()
 	var err error
 	start := time.Now()
 	for time.Since(start) <= unlockCheckpointRetryMaxTime {
-		if err = s.backend.Checkpoint(data); err == nil {
+		if err = checkpoint(); err == nil {
 			s.modified = false
 			return
 		}
@@ -445,6 +489,20 @@ func (s *State) Tasks() []*Task {
 	return res
 }
 
+// UnlinkedTasks returns all tasks currently known to the state that are not
+// linked to any change, either because they were never added to one or
+// because the change they refer to no longer exists.
+func (s *State) UnlinkedTasks() []*Task {
+	s.reading()
+	var res []*Task
+	for _, t := range s.tasks {
+		if t.Change() == nil {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
 // AllTasksForTests returns all tasks currently known to the state,
 // including tasks not linked to any change.
 //
@@ -673,6 +731,7 @@ func ReadState(backend Backend, r io.Reader) (*State, error) {
 	s.backend = backend
 	s.noticeCond = sync.NewCond(s.noticesMu.RLocker())
 	s.modified = false
+	s.sealed = make(map[string]sealedEntry)
 	s.cache = make(map[any]any)
 	s.pendingChangeByAttr = make(map[string]func(*Change) bool)
 	s.changeHandlers = make(map[int]func(chg *Change, old Status, new Status))
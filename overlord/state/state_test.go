@@ -593,6 +593,7 @@ func (ss *stateSuite) TestEmptyStateDataAndCheckpointReadAndSet(c *C) {
 		"tasks",
 		"warnings",
 		"notices",
+		"sealed",
 		"cache",
 		"pendingChangeByAttr",
 		"taskHandlers",
@@ -759,6 +760,21 @@ func (ss *stateSuite) TestNewTaskHiddenUntilLinked(c *C) {
 	c.Check(st.Task(t1.ID()), IsNil)
 }
 
+func (ss *stateSuite) TestUnlinkedTasks(c *C) {
+	st := state.New(nil)
+	st.Lock()
+	defer st.Unlock()
+
+	chg := st.NewChange("install", "...")
+	t1 := st.NewTask("check", "...")
+	chg.AddTask(t1)
+	c.Check(st.UnlinkedTasks(), HasLen, 0)
+
+	t2 := st.NewTask("check", "...")
+	c.Check(st.UnlinkedTasks(), DeepEquals, []*state.Task{t2})
+	c.Check(st.Tasks(), DeepEquals, []*state.Task{t1})
+}
+
 func (ss *stateSuite) TestMethodEntrance(c *C) {
 	st := state.New(&fakeStateBackend{})
 
@@ -790,6 +806,7 @@ func (ss *stateSuite) TestMethodEntrance(c *C) {
 		func() { st.MarshalJSON() },
 		func() { st.Prune(time.Now(), time.Hour, time.Hour, 100) },
 		func() { st.TaskCount() },
+		func() { st.UnlinkedTasks() },
 	}
 
 	for i, f := range reads {
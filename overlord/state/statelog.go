@@ -0,0 +1,577 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package state
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/snapcore/snapd/logger"
+)
+
+// A LogBackend is a Backend which can also persist the state
+// incrementally. Checkpoint is then only used to write the state in full
+// from time to time, while the changes made in between are appended to a
+// log of records through AppendLog.
+type LogBackend interface {
+	Backend
+	// AppendLog durably appends a record to the log of the changes made
+	// since the full checkpoint of the given generation.
+	AppendLog(generation int, record []byte) error
+}
+
+// the log is compacted, i.e. the state is checkpointed in full, once the
+// records appended to it are bigger than half the last full checkpoint,
+// or than stateLogMinCompactionSize for small states
+var stateLogMinCompactionSize = 512 * 1024
+
+// stateEntries holds the state as a set of individually serialized
+// entries. It is used both for the state as last persisted through a
+// LogBackend and for the log records, which carry the entries which
+// changed since, with removed entries set to nil.
+type stateEntries struct {
+	Data     map[string]*json.RawMessage `json:"data,omitempty"`
+	Changes  map[string]*json.RawMessage `json:"changes,omitempty"`
+	Tasks    map[string]*json.RawMessage `json:"tasks,omitempty"`
+	Warnings map[string]*json.RawMessage `json:"warnings,omitempty"`
+	Notices  map[string]*json.RawMessage `json:"notices,omitempty"`
+
+	LastChangeId int `json:"last-change-id"`
+	LastTaskId   int `json:"last-task-id"`
+	LastLaneId   int `json:"last-lane-id"`
+	LastNoticeId int `json:"last-notice-id"`
+
+	LastNoticeTimestamp time.Time `json:"last-notice-timestamp,omitzero"`
+}
+
+func mustMarshalEntry(kind, key string, v any) *json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		// this shouldn't happen, because the actual delicate serializing happens at various Set()s
+		logger.Panicf("internal error: could not marshal %s %q for checkpointing: %v", kind, key, err)
+	}
+	raw := json.RawMessage(data)
+	return &raw
+}
+
+func (s *State) entries() *stateEntries {
+	e := &stateEntries{
+		Data:     make(map[string]*json.RawMessage, len(s.data)),
+		Changes:  make(map[string]*json.RawMessage, len(s.changes)),
+		Tasks:    make(map[string]*json.RawMessage, len(s.tasks)),
+		Warnings: make(map[string]*json.RawMessage),
+		Notices:  make(map[string]*json.RawMessage),
+
+		LastChangeId: s.lastChangeId,
+		LastTaskId:   s.lastTaskId,
+		LastLaneId:   s.lastLaneId,
+		LastNoticeId: s.lastNoticeId,
+
+		LastNoticeTimestamp: s.getLastNoticeTimestamp(),
+	}
+	// entries of data are replaced, never modified in place, by Set
+	for key, value := range s.persistedData() {
+		e.Data[key] = value
+	}
+	for id, chg := range s.changes {
+		e.Changes[id] = mustMarshalEntry("change", id, chg)
+	}
+	for id, t := range s.tasks {
+		e.Tasks[id] = mustMarshalEntry("task", id, t)
+	}
+	for _, w := range s.flattenWarnings() {
+		e.Warnings[w.message] = mustMarshalEntry("warning", w.message, w)
+	}
+	for _, n := range s.flattenNotices() {
+		e.Notices[n.id] = mustMarshalEntry("notice", n.id, n)
+	}
+	return e
+}
+
+func changedEntries(old, new map[string]*json.RawMessage) map[string]*json.RawMessage {
+	var changed map[string]*json.RawMessage
+	set := func(key string, value *json.RawMessage) {
+		if changed == nil {
+			changed = make(map[string]*json.RawMessage)
+		}
+		changed[key] = value
+	}
+	for key, value := range new {
+		if oldValue, ok := old[key]; ok && bytes.Equal(*oldValue, *value) {
+			continue
+		}
+		set(key, value)
+	}
+	for key := range old {
+		if _, ok := new[key]; !ok {
+			set(key, nil)
+		}
+	}
+	return changed
+}
+
+// changedSince returns the log record of the changes from old to e, or nil
+// if there are none.
+func (e *stateEntries) changedSince(old *stateEntries) *stateEntries {
+	rec := &stateEntries{
+		Data:     changedEntries(old.Data, e.Data),
+		Changes:  changedEntries(old.Changes, e.Changes),
+		Tasks:    changedEntries(old.Tasks, e.Tasks),
+		Warnings: changedEntries(old.Warnings, e.Warnings),
+		Notices:  changedEntries(old.Notices, e.Notices),
+
+		LastChangeId: e.LastChangeId,
+		LastTaskId:   e.LastTaskId,
+		LastLaneId:   e.LastLaneId,
+		LastNoticeId: e.LastNoticeId,
+
+		LastNoticeTimestamp: e.LastNoticeTimestamp,
+	}
+	if rec.Data == nil && rec.Changes == nil && rec.Tasks == nil && rec.Warnings == nil && rec.Notices == nil &&
+		rec.LastChangeId == old.LastChangeId && rec.LastTaskId == old.LastTaskId &&
+		rec.LastLaneId == old.LastLaneId && rec.LastNoticeId == old.LastNoticeId &&
+		rec.LastNoticeTimestamp.Equal(old.LastNoticeTimestamp) {
+		return nil
+	}
+	return rec
+}
+
+func applyEntries(entries, changed map[string]*json.RawMessage) {
+	for key, value := range changed {
+		if value == nil {
+			delete(entries, key)
+		} else {
+			entries[key] = value
+		}
+	}
+}
+
+// apply applies the changes of the log record rec to e.
+func (e *stateEntries) apply(rec *stateEntries) {
+	applyEntries(e.Data, rec.Data)
+	applyEntries(e.Changes, rec.Changes)
+	applyEntries(e.Tasks, rec.Tasks)
+	applyEntries(e.Warnings, rec.Warnings)
+	applyEntries(e.Notices, rec.Notices)
+
+	e.LastChangeId = rec.LastChangeId
+	e.LastTaskId = rec.LastTaskId
+	e.LastLaneId = rec.LastLaneId
+	e.LastNoticeId = rec.LastNoticeId
+
+	if rec.LastNoticeTimestamp.After(e.LastNoticeTimestamp) {
+		e.LastNoticeTimestamp = rec.LastNoticeTimestamp
+	}
+}
+
+// rawState is marshalledState with its entries kept serialized.
+type rawState struct {
+	Data     map[string]*json.RawMessage `json:"data"`
+	Changes  map[string]*json.RawMessage `json:"changes"`
+	Tasks    map[string]*json.RawMessage `json:"tasks"`
+	Warnings []*json.RawMessage          `json:"warnings,omitempty"`
+	Notices  []*json.RawMessage          `json:"notices,omitempty"`
+
+	LastChangeId int `json:"last-change-id"`
+	LastTaskId   int `json:"last-task-id"`
+	LastLaneId   int `json:"last-lane-id"`
+	LastNoticeId int `json:"last-notice-id"`
+
+	LastNoticeTimestamp time.Time `json:"last-notice-timestamp,omitzero"`
+
+	LogGeneration int `json:"log-generation,omitempty"`
+}
+
+func sortedEntries(entries map[string]*json.RawMessage) []*json.RawMessage {
+	if len(entries) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	sorted := make([]*json.RawMessage, len(keys))
+	for i, key := range keys {
+		sorted[i] = entries[key]
+	}
+	return sorted
+}
+
+// marshal returns the state held by e in the format of a full checkpoint.
+func (e *stateEntries) marshal(generation int) ([]byte, error) {
+	return json.Marshal(rawState{
+		Data:     e.Data,
+		Changes:  e.Changes,
+		Tasks:    e.Tasks,
+		Warnings: sortedEntries(e.Warnings),
+		Notices:  sortedEntries(e.Notices),
+
+		LastChangeId: e.LastChangeId,
+		LastTaskId:   e.LastTaskId,
+		LastLaneId:   e.LastLaneId,
+		LastNoticeId: e.LastNoticeId,
+
+		LastNoticeTimestamp: e.LastNoticeTimestamp,
+
+		LogGeneration: generation,
+	})
+}
+
+func keyedEntries(list []*json.RawMessage, keyField string) (map[string]*json.RawMessage, error) {
+	entries := make(map[string]*json.RawMessage, len(list))
+	for _, raw := range list {
+		var fields map[string]any
+		if err := json.Unmarshal(*raw, &fields); err != nil {
+			return nil, err
+		}
+		key, ok := fields[keyField].(string)
+		if !ok {
+			return nil, fmt.Errorf("entry without %s: %s", keyField, *raw)
+		}
+		entries[key] = raw
+	}
+	return entries, nil
+}
+
+// entries returns the entries of the full checkpoint held by r.
+func (r *rawState) entries() (*stateEntries, error) {
+	warnings, err := keyedEntries(r.Warnings, "message")
+	if err != nil {
+		return nil, err
+	}
+	notices, err := keyedEntries(r.Notices, "id")
+	if err != nil {
+		return nil, err
+	}
+	e := &stateEntries{
+		Data:     r.Data,
+		Changes:  r.Changes,
+		Tasks:    r.Tasks,
+		Warnings: warnings,
+		Notices:  notices,
+
+		LastChangeId: r.LastChangeId,
+		LastTaskId:   r.LastTaskId,
+		LastLaneId:   r.LastLaneId,
+		LastNoticeId: r.LastNoticeId,
+
+		LastNoticeTimestamp: r.LastNoticeTimestamp,
+	}
+	if e.Data == nil {
+		e.Data = make(map[string]*json.RawMessage)
+	}
+	if e.Changes == nil {
+		e.Changes = make(map[string]*json.RawMessage)
+	}
+	if e.Tasks == nil {
+		e.Tasks = make(map[string]*json.RawMessage)
+	}
+	return e, nil
+}
+
+// logCheckpointer returns a function checkpointing the state through lb,
+// by appending the changes since the last checkpoint to the log unless it
+// is time to compact it.
+func (s *State) logCheckpointer(lb LogBackend) func() error {
+	entries := s.entries()
+	var record []byte
+	if s.logged != nil && s.logSize < s.logCompactionSize {
+		rec := entries.changedSince(s.logged)
+		if rec == nil {
+			// nothing to persist
+			return func() error { return nil }
+		}
+		var err error
+		record, err = json.Marshal(rec)
+		if err != nil {
+			logger.Panicf("internal error: could not marshal state log record: %v", err)
+		}
+	}
+	return func() error {
+		if record != nil {
+			err := lb.AppendLog(s.logGeneration, record)
+			if err == nil {
+				s.logged = entries
+				s.logSize += len(record)
+				s.logPending = true
+				return nil
+			}
+			// the log may not be usable anymore, the state is
+			// written in full instead, starting a new log
+			logger.Noticef("cannot append to the state log, checkpointing the whole state instead: %v", err)
+			record = nil
+		}
+		return s.compactLog(lb, entries)
+	}
+}
+
+// compactLog checkpoints the state held by entries in full through lb,
+// starting a new generation of the log.
+func (s *State) compactLog(lb LogBackend, entries *stateEntries) error {
+	data, err := entries.marshal(s.logGeneration + 1)
+	if err != nil {
+		logger.Panicf("internal error: could not marshal state for checkpointing: %v", err)
+	}
+	if err := lb.Checkpoint(data); err != nil {
+		return err
+	}
+	s.logGeneration++
+	s.logged = entries
+	s.logSize = 0
+	s.logCompactionSize = len(data) / 2
+	if s.logCompactionSize < stateLogMinCompactionSize {
+		s.logCompactionSize = stateLogMinCompactionSize
+	}
+	s.logPending = false
+	return nil
+}
+
+// CompactLog makes the next unlock checkpoint the state in full if the
+// changes of the state were last persisted by appending them to a log, so
+// that the state file on its own is up to date again, e.g. before snapd
+// exits.
+func (s *State) CompactLog() {
+	s.reading()
+	if !s.logPending {
+		return
+	}
+	s.modified = true
+	s.logged = nil
+}
+
+// ReadStateWithLog returns the state deserialized from r, with the records
+// of the log of its changes read from log, if not nil, replayed on top of
+// it.
+//
+// A log of a different generation than the state, left behind by an
+// interrupted compaction, is ignored. So are the records from the first
+// torn or corrupted one on, as left behind by a crash while appending.
+func ReadStateWithLog(backend Backend, r io.Reader, log io.Reader) (*State, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read state: %s", err)
+	}
+	replayed := false
+	if log != nil {
+		data, replayed, err = replayLog(data, log)
+		if err != nil {
+			return nil, err
+		}
+	}
+	s, err := ReadState(backend, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	s.logPending = replayed
+	return s, nil
+}
+
+func replayLog(data []byte, log io.Reader) (replayedData []byte, replayed bool, err error) {
+	var snapshot rawState
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, false, fmt.Errorf("cannot read state: %s", err)
+	}
+
+	br := bufio.NewReader(log)
+	var header stateLogHeader
+	if err := readLogLine(br, &header); err != nil {
+		logger.Noticef("Ignoring state log without a valid header: %v", err)
+		return data, false, nil
+	}
+	if header.Generation == 0 || header.Generation != snapshot.LogGeneration {
+		logger.Noticef("Ignoring state log of generation %d for state of generation %d", header.Generation, snapshot.LogGeneration)
+		return data, false, nil
+	}
+
+	entries, err := snapshot.entries()
+	if err != nil {
+		return nil, false, fmt.Errorf("cannot read state: %s", err)
+	}
+	n := 0
+	for {
+		var rec stateEntries
+		if err := readLogLine(br, &rec); err != nil {
+			if err != io.EOF {
+				logger.Noticef("Ignoring state log from record %d on: %v", n+1, err)
+			}
+			break
+		}
+		entries.apply(&rec)
+		n++
+	}
+	if n == 0 {
+		return data, false, nil
+	}
+	logger.Noticef("Replayed %d records of the state log", n)
+
+	replayedData, err = entries.marshal(snapshot.LogGeneration)
+	if err != nil {
+		return nil, false, fmt.Errorf("cannot replay state log: %v", err)
+	}
+	return replayedData, true, nil
+}
+
+type stateLogHeader struct {
+	Generation int `json:"generation"`
+}
+
+var errTornLogLine = errors.New("incomplete record")
+
+// A log line is the checksum of its payload followed by the payload.
+func logLine(payload []byte) []byte {
+	return []byte(fmt.Sprintf("%08x %s\n", crc32.ChecksumIEEE(payload), payload))
+}
+
+func readLogLine(br *bufio.Reader, v any) error {
+	line, err := br.ReadBytes('\n')
+	if err == io.EOF && len(line) > 0 {
+		return errTornLogLine
+	}
+	if err != nil {
+		return err
+	}
+	line = line[:len(line)-1]
+	if len(line) < 9 || line[8] != ' ' {
+		return errors.New("malformed record")
+	}
+	sum, err := strconv.ParseUint(string(line[:8]), 16, 32)
+	if err != nil {
+		return errors.New("malformed record checksum")
+	}
+	payload := line[9:]
+	if crc32.ChecksumIEEE(payload) != uint32(sum) {
+		return errors.New("record checksum mismatch")
+	}
+	return json.Unmarshal(payload, v)
+}
+
+// StateLog manages the file holding the log of the changes of the state
+// since its last full checkpoint, on behalf of a LogBackend.
+type StateLog struct {
+	path string
+	f    *os.File
+
+	generation int
+	// failed is set when the log is left in an unknown state by an
+	// append which failed
+	failed bool
+	// removed is set when the log is known not to exist
+	removed bool
+}
+
+// NewStateLog returns a StateLog for the log file at the given path.
+func NewStateLog(path string) *StateLog {
+	return &StateLog{path: path}
+}
+
+func (l *StateLog) close() {
+	if l.f != nil {
+		l.f.Close()
+		l.f = nil
+	}
+}
+
+func (l *StateLog) start(generation int) error {
+	l.close()
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	l.removed = false
+	header, err := json.Marshal(stateLogHeader{Generation: generation})
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Write(logLine(header)); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	// make the new file itself durable
+	dir, err := os.Open(filepath.Dir(l.path))
+	if err != nil {
+		f.Close()
+		return err
+	}
+	defer dir.Close()
+	if err := dir.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	l.f = f
+	l.generation = generation
+	l.failed = false
+	return nil
+}
+
+// Append durably appends the record to the log of the given generation,
+// starting a new log if the current one is of another generation.
+func (l *StateLog) Append(generation int, record []byte) error {
+	if l.failed && l.generation == generation {
+		return fmt.Errorf("cannot append to state log after a failed append")
+	}
+	if l.f == nil || l.generation != generation {
+		if err := l.start(generation); err != nil {
+			return fmt.Errorf("cannot start state log: %v", err)
+		}
+	}
+	if _, err := l.f.Write(logLine(record)); err != nil {
+		l.close()
+		l.failed = true
+		return fmt.Errorf("cannot append to state log: %v", err)
+	}
+	if err := l.f.Sync(); err != nil {
+		l.close()
+		l.failed = true
+		return fmt.Errorf("cannot append to state log: %v", err)
+	}
+	return nil
+}
+
+// Remove removes the log, once its records are part of a full checkpoint
+// of the state.
+func (l *StateLog) Remove() error {
+	if l.removed {
+		return nil
+	}
+	l.close()
+	if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("cannot remove state log: %v", err)
+	}
+	l.generation = 0
+	l.failed = false
+	l.removed = true
+	return nil
+}
@@ -0,0 +1,452 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package state_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type stateLogSuite struct{}
+
+var _ = Suite(&stateLogSuite{})
+
+type fakeLogBackend struct {
+	fakeStateBackend
+	log       *state.StateLog
+	logPath   string
+	records   [][]byte
+	appendErr error
+}
+
+func newFakeLogBackend(c *C) *fakeLogBackend {
+	logPath := filepath.Join(c.MkDir(), "state.log")
+	return &fakeLogBackend{
+		log:     state.NewStateLog(logPath),
+		logPath: logPath,
+	}
+}
+
+func (b *fakeLogBackend) Checkpoint(data []byte) error {
+	if err := b.fakeStateBackend.Checkpoint(data); err != nil {
+		return err
+	}
+	return b.log.Remove()
+}
+
+func (b *fakeLogBackend) AppendLog(generation int, record []byte) error {
+	if b.appendErr != nil {
+		return b.appendErr
+	}
+	b.records = append(b.records, record)
+	return b.log.Append(generation, record)
+}
+
+// readState reads the state back from the last full checkpoint and the log.
+func (b *fakeLogBackend) readState(c *C) *state.State {
+	var log io.Reader
+	f, err := os.Open(b.logPath)
+	if err == nil {
+		defer f.Close()
+		log = f
+	} else {
+		c.Assert(os.IsNotExist(err), Equals, true)
+	}
+	c.Assert(b.checkpoints, Not(HasLen), 0)
+	st, err := state.ReadStateWithLog(nil, bytes.NewReader(b.checkpoints[len(b.checkpoints)-1]), log)
+	c.Assert(err, IsNil)
+	return st
+}
+
+func (ss *stateLogSuite) TestCheckpointAppendsChanges(c *C) {
+	b := newFakeLogBackend(c)
+	st := state.New(b)
+
+	st.Lock()
+	st.Set("a", 1)
+	st.Set("b", 2)
+	st.Unlock()
+	// the first checkpoint is a full one
+	c.Check(b.checkpoints, HasLen, 1)
+	c.Check(b.records, HasLen, 0)
+	c.Check(b.logPath, testutil.FileAbsent)
+
+	st.Lock()
+	st.Set("a", 3)
+	st.Set("b", nil)
+	chg := st.NewChange("kind", "summary")
+	t := st.NewTask("kind", "summary")
+	chg.AddTask(t)
+	chgID, taskID := chg.ID(), t.ID()
+	st.Unlock()
+	c.Check(b.checkpoints, HasLen, 1)
+	c.Assert(b.records, HasLen, 1)
+	// only what changed is recorded
+	c.Check(string(b.records[0]), Matches, `.*"data":\{"a":3,"b":null\}.*`)
+	c.Check(string(b.records[0]), Matches, `.*"changes":\{"`+chgID+`":\{.*`)
+	c.Check(string(b.records[0]), Matches, `.*"tasks":\{"`+taskID+`":\{.*`)
+
+	st2 := b.readState(c)
+	st2.Lock()
+	defer st2.Unlock()
+	var a int
+	c.Assert(st2.Get("a", &a), IsNil)
+	c.Check(a, Equals, 3)
+	c.Check(st2.Has("b"), Equals, false)
+	chg2 := st2.Change(chgID)
+	c.Assert(chg2, NotNil)
+	c.Assert(chg2.Tasks(), HasLen, 1)
+	c.Check(chg2.Tasks()[0].ID(), Equals, taskID)
+	c.Check(st2.Modified(), Equals, false)
+}
+
+func (ss *stateLogSuite) TestCheckpointNothingChanged(c *C) {
+	b := newFakeLogBackend(c)
+	st := state.New(b)
+
+	st.Lock()
+	st.Set("a", 1)
+	st.Unlock()
+
+	st.Lock()
+	st.Set("a", 1)
+	c.Check(st.Modified(), Equals, true)
+	st.Unlock()
+	c.Check(b.checkpoints, HasLen, 1)
+	c.Check(b.records, HasLen, 0)
+
+	st.Lock()
+	defer st.Unlock()
+	c.Check(st.Modified(), Equals, false)
+}
+
+func (ss *stateLogSuite) TestCheckpointRemovals(c *C) {
+	b := newFakeLogBackend(c)
+	st := state.New(b)
+
+	st.Lock()
+	chg := st.NewChange("kind", "summary")
+	chg.AddTask(st.NewTask("kind", "summary"))
+	st.Warnf("hello")
+	st.Unlock()
+
+	st.Lock()
+	chg.SetStatus(state.DoneStatus)
+	for _, t := range chg.Tasks() {
+		t.SetStatus(state.DoneStatus)
+	}
+	state.MockChangeTimes(chg, chg.SpawnTime(), chg.SpawnTime())
+	st.Prune(chg.SpawnTime().Add(1), 0, 0, 0)
+	st.Unlock()
+	c.Assert(b.records, HasLen, 1)
+
+	st2 := b.readState(c)
+	st2.Lock()
+	defer st2.Unlock()
+	c.Check(st2.Changes(), HasLen, 0)
+	c.Check(st2.Tasks(), HasLen, 0)
+	c.Check(st2.AllWarnings(), HasLen, 1)
+}
+
+func (ss *stateLogSuite) TestCheckpointWarningsAndNotices(c *C) {
+	b := newFakeLogBackend(c)
+	st := state.New(b)
+
+	st.Lock()
+	st.Set("a", 1)
+	st.Unlock()
+
+	st.Lock()
+	st.Warnf("hello")
+	_, err := st.AddNotice(nil, state.WarningNotice, "hello", nil)
+	c.Assert(err, IsNil)
+	st.Unlock()
+	c.Check(b.checkpoints, HasLen, 1)
+	c.Check(b.records, HasLen, 1)
+
+	st2 := b.readState(c)
+	st2.Lock()
+	defer st2.Unlock()
+	c.Assert(st2.AllWarnings(), HasLen, 1)
+	c.Check(st2.AllWarnings()[0].String(), Equals, "hello")
+	notices := st2.Notices(nil)
+	c.Assert(notices, HasLen, 1)
+	c.Check(notices[0].String(), Matches, `Notice .* \(public:warning:hello\)`)
+}
+
+func (ss *stateLogSuite) TestCompaction(c *C) {
+	restore := state.MockStateLogMinCompactionSize(100)
+	defer restore()
+
+	b := newFakeLogBackend(c)
+	st := state.New(b)
+
+	st.Lock()
+	st.Set("a", 1)
+	st.Unlock()
+
+	st.Lock()
+	st.Set("big", string(make([]byte, 100)))
+	st.Unlock()
+	c.Check(b.checkpoints, HasLen, 1)
+	c.Check(b.records, HasLen, 1)
+
+	// the log got too big
+	st.Lock()
+	st.Set("a", 2)
+	st.Unlock()
+	c.Check(b.checkpoints, HasLen, 2)
+	c.Check(b.records, HasLen, 1)
+	c.Check(b.logPath, testutil.FileAbsent)
+
+	// a new log is started
+	st.Lock()
+	st.Set("a", 3)
+	st.Unlock()
+	c.Check(b.checkpoints, HasLen, 2)
+	c.Check(b.records, HasLen, 2)
+
+	st2 := b.readState(c)
+	st2.Lock()
+	defer st2.Unlock()
+	var a int
+	c.Assert(st2.Get("a", &a), IsNil)
+	c.Check(a, Equals, 3)
+	c.Check(st2.Has("big"), Equals, true)
+}
+
+func (ss *stateLogSuite) TestAppendErrorCheckpointsInFull(c *C) {
+	b := newFakeLogBackend(c)
+	st := state.New(b)
+
+	st.Lock()
+	st.Set("a", 1)
+	st.Unlock()
+
+	b.appendErr = errors.New("boom")
+	st.Lock()
+	st.Set("a", 2)
+	st.Unlock()
+	c.Check(b.checkpoints, HasLen, 2)
+	c.Check(b.records, HasLen, 0)
+
+	b.appendErr = nil
+	st.Lock()
+	st.Set("a", 3)
+	st.Unlock()
+	c.Check(b.checkpoints, HasLen, 2)
+	c.Check(b.records, HasLen, 1)
+
+	st2 := b.readState(c)
+	st2.Lock()
+	defer st2.Unlock()
+	var a int
+	c.Assert(st2.Get("a", &a), IsNil)
+	c.Check(a, Equals, 3)
+}
+
+func (ss *stateLogSuite) TestCompactLog(c *C) {
+	b := newFakeLogBackend(c)
+	st := state.New(b)
+
+	st.Lock()
+	st.Set("a", 1)
+	st.Unlock()
+
+	// nothing in the log
+	st.Lock()
+	st.CompactLog()
+	st.Unlock()
+	c.Check(b.checkpoints, HasLen, 1)
+
+	st.Lock()
+	st.Set("a", 2)
+	st.Unlock()
+	c.Check(b.records, HasLen, 1)
+
+	st.Lock()
+	st.CompactLog()
+	st.Unlock()
+	c.Check(b.checkpoints, HasLen, 2)
+	c.Check(b.logPath, testutil.FileAbsent)
+
+	// the state file is complete on its own
+	st2, err := state.ReadState(nil, bytes.NewReader(b.checkpoints[1]))
+	c.Assert(err, IsNil)
+	st2.Lock()
+	defer st2.Unlock()
+	var a int
+	c.Assert(st2.Get("a", &a), IsNil)
+	c.Check(a, Equals, 2)
+}
+
+func (ss *stateLogSuite) TestReadStateWithLogCompactsOnNextCheckpoint(c *C) {
+	b := newFakeLogBackend(c)
+	st := state.New(b)
+
+	st.Lock()
+	st.Set("a", 1)
+	st.Unlock()
+	st.Lock()
+	st.Set("a", 2)
+	st.Unlock()
+	c.Assert(b.records, HasLen, 1)
+
+	b2 := newFakeLogBackend(c)
+	// checkpointing through another backend, as after a restart
+	st3, err := state.ReadStateWithLog(b2, bytes.NewReader(b.checkpoints[0]), bytes.NewReader(mustReadFile(c, b.logPath)))
+	c.Assert(err, IsNil)
+	st3.Lock()
+	st3.Set("b", 1)
+	st3.Unlock()
+	// the replayed log is folded into a full checkpoint
+	c.Check(b2.checkpoints, HasLen, 1)
+	c.Check(b2.records, HasLen, 0)
+
+	st4, err := state.ReadState(nil, bytes.NewReader(b2.checkpoints[0]))
+	c.Assert(err, IsNil)
+	st4.Lock()
+	defer st4.Unlock()
+	var a int
+	c.Assert(st4.Get("a", &a), IsNil)
+	c.Check(a, Equals, 2)
+	c.Check(st4.Has("b"), Equals, true)
+}
+
+func (ss *stateLogSuite) TestReadStateWithLogIgnoresStaleLog(c *C) {
+	b := newFakeLogBackend(c)
+	st := state.New(b)
+
+	st.Lock()
+	st.Set("a", 1)
+	st.Unlock()
+	st.Lock()
+	st.Set("a", 2)
+	st.Unlock()
+	staleLog := mustReadFile(c, b.logPath)
+
+	st.Lock()
+	st.CompactLog()
+	st.Set("a", 3)
+	st.Unlock()
+	c.Check(b.checkpoints, HasLen, 2)
+
+	// the log of the previous generation was left behind
+	st2, err := state.ReadStateWithLog(nil, bytes.NewReader(b.checkpoints[1]), bytes.NewReader(staleLog))
+	c.Assert(err, IsNil)
+	st2.Lock()
+	defer st2.Unlock()
+	var a int
+	c.Assert(st2.Get("a", &a), IsNil)
+	c.Check(a, Equals, 3)
+}
+
+func (ss *stateLogSuite) TestReadStateWithLogTornRecord(c *C) {
+	b := newFakeLogBackend(c)
+	st := state.New(b)
+
+	st.Lock()
+	st.Set("a", 1)
+	st.Unlock()
+	st.Lock()
+	st.Set("a", 2)
+	st.Unlock()
+	st.Lock()
+	st.Set("a", 3)
+	st.Unlock()
+	c.Assert(b.records, HasLen, 2)
+	log := mustReadFile(c, b.logPath)
+
+	for _, tc := range []struct {
+		log      []byte
+		expected int
+	}{
+		// interrupted while appending
+		{log[:len(log)-1], 2},
+		{log[:len(log)-10], 2},
+		// corrupted
+		{bytes.Replace(log, []byte(`"a":3`), []byte(`"a":4`), 1), 2},
+		{bytes.Replace(log, []byte(`"a":2`), []byte(`"a":4`), 1), 1},
+		// no header
+		{log[:5], 1},
+		{nil, 1},
+	} {
+		st2, err := state.ReadStateWithLog(nil, bytes.NewReader(b.checkpoints[0]), bytes.NewReader(tc.log))
+		c.Assert(err, IsNil)
+		st2.Lock()
+		var a int
+		c.Assert(st2.Get("a", &a), IsNil)
+		c.Check(a, Equals, tc.expected, Commentf("log: %q", tc.log))
+		st2.Unlock()
+	}
+}
+
+func (ss *stateLogSuite) TestReadStateWithLogNoLog(c *C) {
+	b := new(fakeStateBackend)
+	st := state.New(b)
+	st.Lock()
+	st.Set("a", 1)
+	st.Unlock()
+
+	st2, err := state.ReadStateWithLog(nil, bytes.NewReader(b.checkpoints[0]), nil)
+	c.Assert(err, IsNil)
+	st2.Lock()
+	defer st2.Unlock()
+	var a int
+	c.Assert(st2.Get("a", &a), IsNil)
+	c.Check(a, Equals, 1)
+}
+
+func (ss *stateLogSuite) TestStateLogNewGeneration(c *C) {
+	logPath := filepath.Join(c.MkDir(), "state.log")
+	log := state.NewStateLog(logPath)
+
+	c.Assert(log.Append(1, []byte(`{"a":1}`)), IsNil)
+	c.Assert(log.Append(1, []byte(`{"a":2}`)), IsNil)
+	c.Check(string(mustReadFile(c, logPath)), Equals, `c877ba4b {"generation":1}
+561bacaf {"a":1}
+7d36ff6c {"a":2}
+`)
+
+	// a new generation starts afresh
+	c.Assert(log.Append(2, []byte(`{"a":3}`)), IsNil)
+	c.Check(string(mustReadFile(c, logPath)), Equals, `e35ae988 {"generation":2}
+642dce2d {"a":3}
+`)
+
+	c.Assert(log.Remove(), IsNil)
+	c.Check(logPath, testutil.FileAbsent)
+	// removing again is fine
+	c.Assert(log.Remove(), IsNil)
+}
+
+func mustReadFile(c *C, path string) []byte {
+	data, err := os.ReadFile(path)
+	c.Assert(err, IsNil)
+	return data
+}
@@ -199,6 +199,17 @@ func (t *Task) Summary() string {
 	return t.summary
 }
 
+// LogAttrs returns the attributes identifying the task and its change, to
+// be recorded alongside log messages about the task with
+// logger.NoticeAttrs or logger.DebugAttrs.
+func (t *Task) LogAttrs() []any {
+	var attrs []any
+	if chg := t.state.changes[t.change]; chg != nil {
+		attrs = chg.LogAttrs()
+	}
+	return append(attrs, "task", t.id, "task-kind", t.kind)
+}
+
 // Status returns the current task status.
 //
 // Possible state transitions:
@@ -366,6 +377,9 @@ func (t *Task) SetProgress(label string, done, total int) {
 	} else {
 		t.progress = &progress{Label: label, Done: done, Total: total}
 	}
+	if chg := t.state.changes[t.change]; chg != nil {
+		chg.notifyUpdated()
+	}
 }
 
 // SpawnTime returns the time when the change was created.
@@ -430,7 +444,13 @@ func (t *Task) addLog(kind, format string, args []any) {
 	tstr := timeNow().Format(time.RFC3339)
 	msg := tstr + " " + kind + " " + fmt.Sprintf(format, args...)
 	t.log = append(t.log, msg)
-	logger.Debug(msg)
+	// LogAttrs needs to look up the change, only do so when recorded
+	if logger.DebugEnabled() {
+		logger.DebugAttrs(msg, t.LogAttrs()...)
+	}
+	if chg := t.state.changes[t.change]; chg != nil {
+		chg.notifyUpdated()
+	}
 }
 
 // Log returns the most recent messages logged into the task.
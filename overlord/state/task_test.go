@@ -26,6 +26,7 @@ import (
 
 	. "gopkg.in/check.v1"
 
+	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/testutil"
 )
@@ -111,6 +112,39 @@ func (ts *taskSuite) TestHas(c *C) {
 	c.Check(t.Has("a"), Equals, false)
 }
 
+func (ts *taskSuite) TestLogAttrs(c *C) {
+	st := state.New(nil)
+	st.Lock()
+	defer st.Unlock()
+
+	t := st.NewTask("download", "1...")
+	c.Check(t.LogAttrs(), DeepEquals, []any{"task", t.ID(), "task-kind", "download"})
+
+	chg := st.NewChange("install", "...")
+	chg.AddTask(t)
+	chg.Set("request-id", "req-1")
+	c.Check(t.LogAttrs(), DeepEquals, []any{
+		"change", chg.ID(), "change-kind", "install", "request-id", "req-1",
+		"task", t.ID(), "task-kind", "download",
+	})
+}
+
+func (ts *taskSuite) TestLogDebugAttrs(c *C) {
+	logbuf, restore := logger.MockDebugLogger()
+	defer restore()
+
+	st := state.New(nil)
+	st.Lock()
+	defer st.Unlock()
+
+	chg := st.NewChange("install", "...")
+	t := st.NewTask("download", "1...")
+	chg.AddTask(t)
+
+	t.Logf("some %s", "info")
+	c.Check(logbuf.String(), Matches, `(?m).*DEBUG: \S+ INFO some info change=1 change-kind=install task=1 task-kind=download\n`)
+}
+
 func (ts *taskSuite) TestClear(c *C) {
 	st := state.New(nil)
 	st.Lock()
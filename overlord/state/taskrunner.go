@@ -20,6 +20,7 @@
 package state
 
 import (
+	"fmt"
 	"sync"
 	"time"
 
@@ -301,7 +302,7 @@ func (r *TaskRunner) run(t *Task) {
 			t.SetStatus(ErrorStatus)
 			t.Errorf("%s", err)
 			// ensure the error is available in the global log too
-			logger.Noticef("Change %s task (%s) failed: %v", t.Change().ID(), t.Summary(), err)
+			logger.NoticeAttrs(fmt.Sprintf("Change %s task (%s) failed: %v", t.Change().ID(), t.Summary(), err), t.LogAttrs()...)
 			if r.taskErrorCallback != nil {
 				r.taskErrorCallback(err)
 			}
@@ -473,7 +474,7 @@ ConsiderTasks:
 			}
 		}
 
-		logger.Debugf("Running task %s on %s: %s", t.ID(), t.Status(), t.Summary())
+		logger.DebugAttrs(fmt.Sprintf("Running task %s on %s: %s", t.ID(), t.Status(), t.Summary()), t.LogAttrs()...)
 		r.run(t)
 
 		running = append(running, t)
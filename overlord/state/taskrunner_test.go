@@ -1293,6 +1293,7 @@ func (ts *taskRunnerSuite) TestErrorCallbackCalledOnError(c *C) {
 
 	st.Lock()
 	chg := st.NewChange("install", "change summary")
+	chg.Set("request-id", "req-1")
 	t1 := st.NewTask("foo", "task summary")
 	chg.AddTask(t1)
 	st.Unlock()
@@ -1308,7 +1309,7 @@ func (ts *taskRunnerSuite) TestErrorCallbackCalledOnError(c *C) {
 	c.Check(strings.Join(t1.Log(), ""), Matches, `.*handler error for "foo"`)
 	c.Check(called, Equals, true)
 
-	c.Check(logbuf.String(), Matches, `(?m).*: Change 1 task \(task summary\) failed: handler error for "foo".*`)
+	c.Check(logbuf.String(), Matches, `(?m).*: Change 1 task \(task summary\) failed: handler error for "foo" change=1 change-kind=install request-id=req-1 task=1 task-kind=foo`)
 }
 
 func (ts *taskRunnerSuite) TestErrorCallbackNotCalled(c *C) {
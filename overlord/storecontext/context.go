@@ -281,6 +281,22 @@ func (sc *storeContext) WithSnapStoreDelta() bool {
 	return enabled
 }
 
+func (sc *storeContext) WithZstdStoreDelta() bool {
+	sc.state.Lock()
+	defer sc.state.Unlock()
+
+	tr := config.NewTransaction(sc.state)
+	enabled, err := features.Flag(tr, features.ZstdDeltaFormat)
+	if err != nil && !config.IsNoOption(err) {
+		logger.Noticef("cannot check zstd-delta-format feature flag: %v", err)
+
+		// If the flag cannot be checked, assume disabled.
+		return false
+	}
+
+	return enabled
+}
+
 // CloudInfo returns the cloud instance information (if available).
 func (sc *storeContext) CloudInfo() (*auth.CloudInfo, error) {
 	sc.state.Lock()
@@ -238,6 +238,24 @@ func (s *storeCtxSuite) TestWithSnapStoreDelta(c *C) {
 	c.Check(hasSnapDeltaFormat, Equals, true)
 }
 
+func (s *storeCtxSuite) TestWithZstdStoreDelta(c *C) {
+	storeCtx := storecontext.New(s.state, &testBackend{nothing: true})
+
+	hasZstdDeltaFormat := storeCtx.WithZstdStoreDelta()
+	c.Check(hasZstdDeltaFormat, Equals, false)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	tr := config.NewTransaction(s.state)
+	tr.Set("core", "experimental.zstd-delta-format", true)
+	tr.Commit()
+
+	s.state.Unlock()
+	hasZstdDeltaFormat = storeCtx.WithZstdStoreDelta()
+	s.state.Lock()
+	c.Check(hasZstdDeltaFormat, Equals, true)
+}
+
 func (s *storeCtxSuite) TestCloudInfo(c *C) {
 	storeCtx := storecontext.New(s.state, &testBackend{nothing: true})
 
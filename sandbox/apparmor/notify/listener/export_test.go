@@ -58,23 +58,14 @@ func MockOsOpenWithSocket() (restore func()) {
 }
 
 func MockEpollWaitForClose() (restore func()) {
-	closeChan := make(chan struct{})
-	restoreWait := testutil.Mock(&listenerEpollWait, func(l epollWaiter) ([]epoll.Event, error) {
-		<-closeChan
+	return testutil.Mock(&listenerEpollWait, func(l epollWaiter) ([]epoll.Event, error) {
+		<-l.closed()
 		// The listenerEpollWait() error will cause handleRequests() to check
 		// whether the listener has been closed, and if so, return ErrClosed.
 		// Thus, return an arbitrary error here to make sure that ErrClosed is
 		// returned instead.
 		return nil, fmt.Errorf("fake epoll error")
 	})
-	restoreClose := testutil.Mock(&listenerEpollClose, func(l epollWaiter) error {
-		close(closeChan)
-		return nil
-	})
-	return func() {
-		restoreWait()
-		restoreClose()
-	}
 }
 
 func MockNotifyRegisterFileDescriptor(f func(fd uintptr) (notify.ProtocolVersion, int, error)) (restore func()) {
@@ -89,7 +80,7 @@ func MockNotifyIoctl(f func(fd uintptr, req notify.IoctlRequest, buf notify.Ioct
 	return restore
 }
 
-// Mocks epoll.Wait, epoll.Close, notify.Ioctl, and notify.RegisterFileDescriptor
+// Mocks epoll.Wait, notify.Ioctl, and notify.RegisterFileDescriptor
 // calls by sending data over channels, using the given version as the protocol
 // version for the listener.
 //
@@ -101,11 +92,6 @@ func MockEpollWaitNotifyIoctl(protoVersion notify.ProtocolVersion, pendingCount
 	recvChanRW := make(chan []byte)
 	sendChanRW := make(chan []byte, 1) // need to have buffer size 1 since reply does not run in a goroutine and the test would otherwise block
 	internalRecvChan := make(chan []byte, 1)
-	closeChan := make(chan struct{})
-	epollCloseF := func(l epollWaiter) error {
-		close(closeChan)
-		return nil
-	}
 	epollWaitF := func(l epollWaiter) ([]epoll.Event, error) {
 		// In the real listener, the epoll instance has its own FD and we don't
 		// need to get the notify FD, but here, we need to get the notify FD
@@ -127,7 +113,7 @@ func MockEpollWaitNotifyIoctl(protoVersion notify.ProtocolVersion, pendingCount
 				},
 			}
 			return events, nil
-		case <-closeChan:
+		case <-l.closed():
 			return nil, epoll.ErrEpollClosed
 		}
 	}
@@ -155,13 +141,11 @@ func MockEpollWaitNotifyIoctl(protoVersion notify.ProtocolVersion, pendingCount
 	rfdF := func(fd uintptr) (notify.ProtocolVersion, int, error) {
 		return protoVersion, pendingCount, nil
 	}
-	restoreEpollClose := testutil.Mock(&listenerEpollClose, epollCloseF)
 	restoreEpollWait := testutil.Mock(&listenerEpollWait, epollWaitF)
 	restoreIoctl := testutil.Mock(&notifyIoctl, ioctlF)
 	restoreRegisterFileDescriptor := testutil.Mock(&notifyRegisterFileDescriptor, rfdF)
 
 	restore = func() {
-		restoreEpollClose()
 		restoreEpollWait()
 		restoreIoctl()
 		restoreRegisterFileDescriptor()
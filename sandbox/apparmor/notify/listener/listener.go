@@ -92,13 +92,9 @@ type Listener[R any] struct {
 	// socket.
 	protocolVersion notify.ProtocolVersion
 
-	// socketMu guards the notify file, the epoll instance, and the close chan.
-	socketMu   sync.Mutex
-	notifyFile *os.File
-	poll       *epoll.Epoll
-	// closeChan will be closed by Close to indicate to the run loop that the
-	// listener should be closed.
-	closeChan chan struct{}
+	// notifier waits for messages on the notify socket and guards the
+	// socket against being closed while in use.
+	notifier *epoll.Notifier
 }
 
 // Register opens and configures the apparmor notification interface.
@@ -123,18 +119,25 @@ func Register[R any](newReq func(msg notify.MsgNotificationGeneric, sendResponse
 		}
 	}()
 
-	poll, err := epoll.Open()
+	listener = &Listener[R]{
+		newRequest: newReq,
+
+		reqs: make(chan *R),
+
+		ready: make(chan struct{}),
+	}
+	notifier, err := epoll.NewNotifier(notifyFile, epoll.NotifierOptions{
+		Receive: listener.receive,
+		Handle:  listener.decodeAndDispatchRequest,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("cannot open epoll file descriptor: %v", err)
+		return nil, fmt.Errorf("cannot register epoll on %q: %v", path, err)
 	}
 	defer func() {
 		if err != nil {
-			poll.Close()
+			notifier.Close()
 		}
 	}()
-	if err = poll.Register(int(notifyFile.Fd()), epoll.Readable); err != nil {
-		return nil, fmt.Errorf("cannot register epoll on %q: %v", path, err)
-	}
 
 	protoVersion, pendingCount, err := notifyRegisterFileDescriptor(notifyFile.Fd())
 	if err != nil {
@@ -142,20 +145,9 @@ func Register[R any](newReq func(msg notify.MsgNotificationGeneric, sendResponse
 	}
 	logger.Debugf("registered listener with protocol version %d", protoVersion)
 
-	listener = &Listener[R]{
-		newRequest: newReq,
-
-		reqs: make(chan *R),
-
-		ready:        make(chan struct{}),
-		pendingCount: pendingCount,
-
-		protocolVersion: protoVersion,
-
-		notifyFile: notifyFile,
-		poll:       poll,
-		closeChan:  make(chan struct{}),
-	}
+	listener.pendingCount = pendingCount
+	listener.protocolVersion = protoVersion
+	listener.notifier = notifier
 	// If there are no pending requests waiting to be re-sent, ready
 	// immediately, otherwise start the ready timer when Run is called.
 	if listener.pendingCount == 0 {
@@ -172,42 +164,20 @@ func Register[R any](newReq func(msg notify.MsgNotificationGeneric, sendResponse
 // lock while checking isClosed, and continue to hold the lock until it is safe
 // for Close to be run.
 func (l *Listener[R]) isClosed() bool {
-	select {
-	case <-l.closeChan:
-		return true
-	default:
-		return false
-	}
+	return l.notifier.IsClosed()
 }
 
 // Close stops the listener and closes the kernel communication file.
+//
+// Closing the notify file signals to the kernel that the listener is
+// disconnecting, so the kernel will send back denials or pass requests on to
+// other listeners which connect.
 func (l *Listener[R]) Close() error {
-	l.socketMu.Lock()
-	defer l.socketMu.Unlock()
-	if l.isClosed() {
+	err := l.notifier.Close()
+	if errors.Is(err, epoll.ErrNotifierClosed) {
 		return ErrAlreadyClosed
 	}
-
-	// Closing the notify file signals to the kernel that the listener is
-	// disconnecting, so the kernel will send back denials or pass requests
-	// on to other listeners which connect. Do this before closing the epoll
-	// instance so that the kernel does not try to send any further messages
-	// which won't be received.
-	err1 := l.notifyFile.Close()
-
-	// Close the close channel so that the the run loop knows to stop trying
-	// to send requests over the request channel, and so that once the epoll
-	// FD is closed (causing the syscall to error), it can check the closeChan
-	// to see whether Close was called or whether a real error occurred.
-	close(l.closeChan)
-
-	// Close the epoll so that if the run loop is waiting on an event, it will
-	// return an error.
-	err2 := listenerEpollClose(l)
-	if err1 != nil {
-		return err1
-	}
-	return err2
+	return err
 }
 
 // Reqs returns a read-only channel through which requests may be received.
@@ -269,7 +239,7 @@ func (l *Listener[R]) Run() error {
 // epollWaiter allows listenerEpollWait to be mocked in tests.
 type epollWaiter interface {
 	epollWait() ([]epoll.Event, error)
-	epollClose() error
+	closed() <-chan struct{}
 	socketFD() int
 }
 
@@ -278,30 +248,18 @@ var listenerEpollWait = func(l epollWaiter) ([]epoll.Event, error) {
 }
 
 func (l *Listener[R]) epollWait() ([]epoll.Event, error) {
-	return l.poll.Wait()
+	return l.notifier.Wait()
 }
 
-var listenerEpollClose = func(l epollWaiter) error {
-	return l.epollClose()
-}
-
-func (l *Listener[R]) epollClose() error {
-	return l.poll.Close()
+func (l *Listener[R]) closed() <-chan struct{} {
+	return l.notifier.Closed()
 }
 
 func (l *Listener[R]) socketFD() int {
-	l.socketMu.Lock()
-	defer l.socketMu.Unlock()
-	return int(l.notifyFile.Fd())
+	return l.notifier.Fd()
 }
 
 func (l *Listener[R]) handleRequests() error {
-	// Get the socket FD with the lock held, so we don't break our contract.
-	// Do this before any check whether the epoll instance is closed, so we
-	// are sure to have the pre-close FD in case the instance is closed after
-	// the check.
-	socketFd := l.socketFD()
-
 	events, err := listenerEpollWait(l)
 	if err != nil {
 		// The epoll syscall returned an error, so let's see whether it was
@@ -319,39 +277,33 @@ func (l *Listener[R]) handleRequests() error {
 	// This would require some work on the kernel side, so it could be a future
 	// enhancement, but not one we can pursue at time of writing.
 
-	for _, event := range events {
-		if event.Fd != socketFd {
-			logger.Debugf("unexpected event from fd %v (%v)", event.Fd, event.Readiness)
-			continue
-		}
-		if event.Readiness&epoll.Readable == 0 {
-			continue
-		}
-		// Prepare a receive buffer for incoming request. The buffer is of the
-		// maximum allowed size and will contain one or more kernel requests
-		// upon return.
-		ioctlBuf := notify.NewIoctlRequestBuffer(l.protocolVersion)
-		buf, err := l.doIoctl(notify.APPARMOR_NOTIF_RECV, ioctlBuf)
-		if err != nil {
-			return err
-		}
-		if err := l.decodeAndDispatchRequest(buf); err != nil {
-			return err
-		}
+	err = l.notifier.Dispatch(events)
+	if errors.Is(err, epoll.ErrNotifierClosed) {
+		return ErrClosed
 	}
-	return nil
+	return err
+}
+
+// receive receives one or more kernel requests from the notify socket.
+func (l *Listener[R]) receive(fd uintptr, _ []byte) ([]byte, error) {
+	// Prepare a receive buffer for incoming request. The buffer is of the
+	// maximum allowed size and will contain one or more kernel requests
+	// upon return.
+	ioctlBuf := notify.NewIoctlRequestBuffer(l.protocolVersion)
+	return notifyIoctl(fd, notify.APPARMOR_NOTIF_RECV, ioctlBuf)
 }
 
-// doIoctl locks the mutex guarding the notify socket, checks whether the
-// listener is being closed, and if not, sends an ioctl request with the given
-// request type and buffer.
-func (l *Listener[R]) doIoctl(sendOrRecv notify.IoctlRequest, buf notify.IoctlRequestBuffer) ([]byte, error) {
-	l.socketMu.Lock()
-	defer l.socketMu.Unlock()
-	if l.isClosed() {
+// doIoctl sends an ioctl request with the given request type and buffer,
+// unless the listener is closed.
+func (l *Listener[R]) doIoctl(sendOrRecv notify.IoctlRequest, buf notify.IoctlRequestBuffer) (ret []byte, err error) {
+	err = l.notifier.Do(func(fd uintptr) error {
+		ret, err = notifyIoctl(fd, sendOrRecv, buf)
+		return err
+	})
+	if errors.Is(err, epoll.ErrNotifierClosed) {
 		return nil, ErrClosed
 	}
-	return notifyIoctl(l.notifyFile.Fd(), sendOrRecv, buf)
+	return ret, err
 }
 
 // decodeAndDispatchRequest reads all messages from the given buffer, decodes
@@ -410,7 +362,7 @@ func (l *Listener[R]) decodeAndDispatchRequest(buf []byte) error {
 		select {
 		case l.reqs <- req:
 			// request received
-		case <-l.closeChan:
+		case <-l.notifier.Closed():
 			// The listener is being closed, so stop trying to deliver the
 			// message up to the manager. It will appear to the kernel that we
 			// have received and processed the request, when in reality, we
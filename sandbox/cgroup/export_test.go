@@ -29,11 +29,12 @@ import (
 )
 
 var (
-	Cgroup2SuperMagic       = cgroup2SuperMagic
-	ProbeCgroupVersion      = probeCgroupVersion
-	ParsePid                = parsePid
-	DoCreateTransientScope  = doCreateTransientScope
-	SessionOrMaybeSystemBus = sessionOrMaybeSystemBus
+	Cgroup2SuperMagic             = cgroup2SuperMagic
+	ProbeCgroupVersion            = probeCgroupVersion
+	ParsePid                      = parsePid
+	DoCreateTransientScope        = doCreateTransientScope
+	DoCreateTransientScopeInSlice = doCreateTransientScopeInSlice
+	SessionOrMaybeSystemBus       = sessionOrMaybeSystemBus
 
 	ErrDBusUnknownMethod    = errDBusUnknownMethod
 	ErrDBusNameHasNoOwner   = errDBusNameHasNoOwner
@@ -102,6 +103,14 @@ func MockDoCreateTransientScope(fn func(conn *dbus.Conn, unitName string, pid in
 	}
 }
 
+func MockProcessUid(fn func(pid int) (int, error)) func() {
+	return testutil.Mock(&processUid, fn)
+}
+
+func MockDoCreateTransientScopeInSlice(fn func(conn *dbus.Conn, unitName string, pid int, slice string) error) func() {
+	return testutil.Mock(&doCreateTransientScopeInSlice, fn)
+}
+
 func FreezerCgroupV1Dir() string { return freezerCgroupV1Dir }
 
 func MockCreateScopeJobTimeout(d time.Duration) (restore func()) {
@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/godbus/dbus/v5"
@@ -18,12 +19,29 @@ import (
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/randutil"
+	"github.com/snapcore/snapd/sandbox/apparmor"
+	"github.com/snapcore/snapd/snap/naming"
 	"github.com/snapcore/snapd/systemd"
 )
 
 var osGetuid = os.Getuid
 var osGetpid = os.Getpid
 var cgroupProcessPathInTrackingCgroup = ProcessPathInTrackingCgroup
+var apparmorLabelFromPid = apparmor.LabelFromPid
+
+// processUid returns the effective uid of the given process, which owns its
+// directory in /proc.
+var processUid = func(pid int) (int, error) {
+	fi, err := os.Stat(filepath.Dir(ProcPidPath(pid)))
+	if err != nil {
+		return 0, err
+	}
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, fmt.Errorf("cannot determine owner of process %d", pid)
+	}
+	return int(st.Uid), nil
+}
 
 var ErrCannotTrackProcess = errors.New("cannot track application process")
 
@@ -141,6 +159,12 @@ tryAgain:
 	//
 	// Verify the effective tracking cgroup and check that our scope name is
 	// contained therein.
+	return waitForTrackingScope(pid, unitName, start)
+}
+
+// waitForTrackingScope waits for the process to show up in the tracking
+// cgroup of the given transient scope unit.
+func waitForTrackingScope(pid int, unitName string, start time.Time) error {
 	hasTracking := false
 	for tries := 0; tries < 100; tries++ {
 		path, err := cgroupProcessPathInTrackingCgroup(pid)
@@ -162,6 +186,103 @@ tryAgain:
 	return nil
 }
 
+// HelperTrackingOptions control how a helper process is moved into the
+// tracking scope of a snap.
+type HelperTrackingOptions struct {
+	// Slice is the systemd slice the transient scope is placed in, eg. the
+	// slice of the quota group of the snap, so that the quota limits apply
+	// to the helper as well. When empty, systemd picks the default slice.
+	Slice string
+	// Uid is the user the process must run as, ie. the user of the caller
+	// asking for the process to be tracked.
+	Uid int
+}
+
+// CreateTransientScopeForHelper moves the given process into a new transient
+// scope for the snap application or hook identified by the security tag, so
+// that the process is tracked and resource-accounted with the snap.
+//
+// This is meant for host-side helpers spawned on behalf of a snap, eg. by a
+// companion daemon, and for helpers of the snap which ended up outside of its
+// tracking, eg. when started through D-Bus activation. Unlike
+// CreateTransientScopeForTracking the process is not the caller, hence the
+// scope is always created on the system bus. The process must not be the init
+// process nor already belong to a different snap, it must run as the user given
+// in the options and be either unconfined or confined by the snap. Processes
+// confined by other AppArmor profiles are never moved into the scope of a snap.
+func CreateTransientScopeForHelper(securityTag string, pid int, opts *HelperTrackingOptions) error {
+	if opts == nil {
+		opts = &HelperTrackingOptions{}
+	}
+	tag, err := naming.ParseSecurityTag(securityTag)
+	if err != nil {
+		return err
+	}
+	if pid <= 1 {
+		return fmt.Errorf("cannot track process %d: invalid pid", pid)
+	}
+	if opts.Slice != "" && !strings.HasSuffix(opts.Slice, ".slice") {
+		return fmt.Errorf("cannot track process %d: invalid slice %q", pid, opts.Slice)
+	}
+	if _, err := os.Stat(ProcPidPath(pid)); err != nil {
+		return fmt.Errorf("cannot track process %d: %v", pid, err)
+	}
+	path, err := cgroupProcessPathInTrackingCgroup(pid)
+	if err != nil {
+		return err
+	}
+	if other := SecurityTagFromCgroupPath(path); other != nil && other.InstanceName() != tag.InstanceName() {
+		return fmt.Errorf("cannot track process %d: process belongs to snap %q", pid, other.InstanceName())
+	}
+	label, err := apparmorLabelFromPid(pid)
+	if err != nil {
+		return fmt.Errorf("cannot track process %d: %v", pid, err)
+	}
+	// unconfined helpers are started on the host on behalf of the snap,
+	// confined ones must belong to the snap itself
+	if label != "unconfined" {
+		snapName, _, _, err := apparmor.DecodeLabel(label)
+		if err != nil {
+			return fmt.Errorf("cannot track process %d: %v", pid, err)
+		}
+		if snapName != tag.InstanceName() {
+			return fmt.Errorf("cannot track process %d: process is confined by snap %q", pid, snapName)
+		}
+	}
+	uid, err := processUid(pid)
+	if err != nil {
+		return fmt.Errorf("cannot track process %d: %v", pid, err)
+	}
+	if uid != opts.Uid {
+		return fmt.Errorf("cannot track process %d: process runs as uid %d rather than %d", pid, uid, opts.Uid)
+	}
+
+	conn, err := dbusutil.SystemBus()
+	if err != nil {
+		return ErrCannotTrackProcess
+	}
+	uuid, err := randomUUID()
+	if err != nil {
+		return err
+	}
+	securityTagUnitName, err := systemd.SecurityTagToUnitName(securityTag)
+	if err != nil {
+		return err
+	}
+	unitName := fmt.Sprintf("%s-%s.scope", securityTagUnitName, uuid)
+
+	logger.Debugf("creating transient scope %s for process %d", unitName, pid)
+	start := time.Now()
+	if err := doCreateTransientScopeInSlice(conn, unitName, pid, opts.Slice); err != nil {
+		switch err {
+		case errDBusUnknownMethod, errDBusNameHasNoOwner, errDBusSpawnChildExited:
+			return ErrCannotTrackProcess
+		}
+		return err
+	}
+	return waitForTrackingScope(pid, unitName, start)
+}
+
 // ConfirmSystemdAppTracking checks if systemd tracks this process as a snap app.
 //
 // If the application process is not tracked then ErrCannotTrackProcess is returned.
@@ -258,7 +379,7 @@ var (
 // The scope is created by asking systemd via the specified DBus connection.
 // The unit name and the PID to attach are provided as well. The DBus method
 // call is performed outside confinement established by snap-confine.
-func startTransientScope(conn *dbus.Conn, unitName string, pid int) (job dbus.ObjectPath, err error) {
+func startTransientScope(conn *dbus.Conn, unitName string, pid int, slice string) (job dbus.ObjectPath, err error) {
 	// Documentation of StartTransientUnit is available at
 	// https://www.freedesktop.org/wiki/Software/systemd/dbus/
 	//
@@ -314,6 +435,9 @@ func startTransientScope(conn *dbus.Conn, unitName string, pid int) (job dbus.Ob
 	// Here we choose "fail" to match systemd-run.
 	mode := "fail"
 	properties := []property{{"PIDs", []uint{uint(pid)}}}
+	if slice != "" {
+		properties = append(properties, property{"Slice", slice})
+	}
 	aux := []auxUnit(nil)
 	systemd := conn.Object("org.freedesktop.systemd1", "/org/freedesktop/systemd1")
 	call := systemd.Call(
@@ -358,15 +482,15 @@ func startTransientScope(conn *dbus.Conn, unitName string, pid int) (job dbus.Ob
 // doCreateTransientScopeOpportunisticSync creates a transient scope with a
 // given unit name asking systemd to move the provided pid to that scope, does
 // not wait for the systemd job to complete
-func doCreateTransientScopeNoSync(conn *dbus.Conn, unitName string, pid int) error {
-	_, err := startTransientScope(conn, unitName, pid)
+func doCreateTransientScopeNoSync(conn *dbus.Conn, unitName string, pid int, slice string) error {
+	_, err := startTransientScope(conn, unitName, pid, slice)
 	return err
 }
 
 // doCreateTransientScopeOpportunisticSync creates a transient scope with a
 // given unit name asking systemd to move the provided pid to that scope, and
 // waits for the systemd job to finish
-func doCreateTransientScopeJobRemovedSync(conn *dbus.Conn, unitName string, pid int) error {
+func doCreateTransientScopeJobRemovedSync(conn *dbus.Conn, unitName string, pid int, slice string) error {
 	// set up a watch for JobRemoved signals, so that we'll know when our
 	// request has completed
 	jobRemoveMatch := []dbus.MatchOption{
@@ -444,7 +568,7 @@ func doCreateTransientScopeJobRemovedSync(conn *dbus.Conn, unitName string, pid
 			}
 		}
 	}()
-	job, err := startTransientScope(conn, unitName, pid)
+	job, err := startTransientScope(conn, unitName, pid, slice)
 	if err != nil {
 		return err
 	}
@@ -468,6 +592,12 @@ func doCreateTransientScopeJobRemovedSync(conn *dbus.Conn, unitName string, pid
 // The unit name and the PID to attach are provided as well. The DBus method
 // call is performed outside confinement established by snap-confine.
 var doCreateTransientScope = func(conn *dbus.Conn, unitName string, pid int) error {
+	return doCreateTransientScopeInSlice(conn, unitName, pid, "")
+}
+
+// doCreateTransientScopeInSlice is like doCreateTransientScope but places the
+// scope in the given slice, unless it is empty.
+var doCreateTransientScopeInSlice = func(conn *dbus.Conn, unitName string, pid int, slice string) error {
 	// in theory we could use a single implementation that sync with job
 	// removed signal and inspects the result, however some older
 	// distributions sport an unpatched and broken version of systemd, which
@@ -481,9 +611,9 @@ var doCreateTransientScope = func(conn *dbus.Conn, unitName string, pid int) err
 		// when using cgroup v2, we absolutely must be sure that the
 		// tracking group has been created, otherwise we risk
 		// establishing a device cgroup filtering in the wrong group
-		return doCreateTransientScopeJobRemovedSync(conn, unitName, pid)
+		return doCreateTransientScopeJobRemovedSync(conn, unitName, pid, slice)
 	}
-	return doCreateTransientScopeNoSync(conn, unitName, pid)
+	return doCreateTransientScopeNoSync(conn, unitName, pid, slice)
 }
 
 // The source of the bytes generated here is the same as that of
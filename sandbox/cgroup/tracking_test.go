@@ -23,6 +23,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -391,6 +392,166 @@ func (s *trackingSuite) TestCreateTransientScopeForRootOnSystemBus(c *C) {
 	c.Assert(err, IsNil)
 }
 
+func (s *trackingSuite) mockHelperProcess(c *C, pid int, label string) {
+	procCgroup := cgroup.ProcPidPath(pid)
+	c.Assert(os.MkdirAll(filepath.Join(filepath.Dir(procCgroup), "attr"), 0755), IsNil)
+	c.Assert(os.WriteFile(procCgroup, nil, 0644), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(filepath.Dir(procCgroup), "attr/current"), []byte(label+"\n"), 0644), IsNil)
+}
+
+func (s *trackingSuite) TestCreateTransientScopeForHelper(c *C) {
+	s.testCreateTransientScopeForHelper(c, "snap.pkg.hook.configure (enforce)")
+}
+
+func (s *trackingSuite) TestCreateTransientScopeForHelperUnconfined(c *C) {
+	// a host-side helper started on behalf of the snap
+	s.testCreateTransientScopeForHelper(c, "unconfined")
+}
+
+func (s *trackingSuite) testCreateTransientScopeForHelper(c *C, label string) {
+	systemBus, err := dbustest.StubConnection()
+	c.Assert(err, IsNil)
+	restore := dbusutil.MockConnections(func() (*dbus.Conn, error) { return systemBus, nil }, func() (*dbus.Conn, error) {
+		c.Fatal("session bus must not be used")
+		return nil, nil
+	})
+	defer restore()
+
+	s.mockHelperProcess(c, 4242, label)
+	restore = cgroup.MockProcessUid(func(pid int) (int, error) {
+		c.Check(pid, Equals, 4242)
+		return 1000, nil
+	})
+	defer restore()
+
+	uuid := "cc98cd01-6a25-46bd-b71b-82069b71b770"
+	restore = cgroup.MockRandomUUID(func() (string, error) {
+		return uuid, nil
+	})
+	defer restore()
+
+	scopeCreated := false
+	restore = cgroup.MockDoCreateTransientScopeInSlice(func(conn *dbus.Conn, unitName string, pid int, slice string) error {
+		c.Check(conn, Equals, systemBus)
+		c.Check(unitName, Equals, "snap.pkg.hook.configure-"+uuid+".scope")
+		c.Check(pid, Equals, 4242)
+		c.Check(slice, Equals, "snap.grp.slice")
+		scopeCreated = true
+		return nil
+	})
+	defer restore()
+
+	restore = cgroup.MockCgroupProcessPathInTrackingCgroup(func(pid int) (string, error) {
+		c.Check(pid, Equals, 4242)
+		if !scopeCreated {
+			// a host-side process
+			return "/system.slice/helper-daemon.service", nil
+		}
+		return "/snap.grp.slice/snap.pkg.hook.configure-" + uuid + ".scope", nil
+	})
+	defer restore()
+
+	err = cgroup.CreateTransientScopeForHelper("snap.pkg.hook.configure", 4242, &cgroup.HelperTrackingOptions{Slice: "snap.grp.slice", Uid: 1000})
+	c.Assert(err, IsNil)
+	c.Check(scopeCreated, Equals, true)
+}
+
+func (s *trackingSuite) TestCreateTransientScopeForHelperNotTracked(c *C) {
+	systemBus, err := dbustest.StubConnection()
+	c.Assert(err, IsNil)
+	restore := dbusutil.MockConnections(func() (*dbus.Conn, error) { return systemBus, nil }, dbustest.StubConnection)
+	defer restore()
+
+	s.mockHelperProcess(c, 4242, "snap.pkg.app (enforce)")
+	restore = cgroup.MockProcessUid(func(pid int) (int, error) { return 0, nil })
+	defer restore()
+
+	restore = cgroup.MockDoCreateTransientScopeInSlice(func(conn *dbus.Conn, unitName string, pid int, slice string) error {
+		c.Check(slice, Equals, "")
+		return nil
+	})
+	defer restore()
+	restore = cgroup.MockCgroupProcessPathInTrackingCgroup(func(pid int) (string, error) {
+		return "/system.slice/helper-daemon.service", nil
+	})
+	defer restore()
+
+	err = cgroup.CreateTransientScopeForHelper("snap.pkg.app", 4242, nil)
+	c.Assert(err, Equals, cgroup.ErrCannotTrackProcess)
+}
+
+func (s *trackingSuite) TestCreateTransientScopeForHelperValidation(c *C) {
+	s.mockHelperProcess(c, 4242, "snap.pkg.app (enforce)")
+
+	restore := cgroup.MockDoCreateTransientScopeInSlice(func(conn *dbus.Conn, unitName string, pid int, slice string) error {
+		c.Fatal("unexpected call")
+		return nil
+	})
+	defer restore()
+	restore = cgroup.MockCgroupProcessPathInTrackingCgroup(func(pid int) (string, error) {
+		return "/user.slice/user-1000.slice/user@1000.service/app.slice/snap.other.app-1234.scope", nil
+	})
+	defer restore()
+
+	for _, tc := range []struct {
+		tag   string
+		pid   int
+		slice string
+		err   string
+	}{
+		{"not-a-tag", 4242, "", `invalid security tag`},
+		{"snap.pkg.app", 1, "", `cannot track process 1: invalid pid`},
+		{"snap.pkg.app", -5, "", `cannot track process -5: invalid pid`},
+		{"snap.pkg.app", 4242, "snap.grp.service", `cannot track process 4242: invalid slice "snap.grp.service"`},
+		{"snap.pkg.app", 4343, "", `cannot track process 4343: stat .*/proc/4343/cgroup: no such file or directory`},
+		{"snap.pkg.app", 4242, "", `cannot track process 4242: process belongs to snap "other"`},
+	} {
+		err := cgroup.CreateTransientScopeForHelper(tc.tag, tc.pid, &cgroup.HelperTrackingOptions{Slice: tc.slice})
+		c.Check(err, ErrorMatches, tc.err, Commentf("%v", tc))
+	}
+}
+
+func (s *trackingSuite) TestCreateTransientScopeForHelperRejectsUnconfinedOtherUser(c *C) {
+	s.testCreateTransientScopeForHelperRejects(c, "unconfined", 1000,
+		`cannot track process 4242: process runs as uid 1000 rather than 0`)
+}
+
+func (s *trackingSuite) TestCreateTransientScopeForHelperRejectsHostProcess(c *C) {
+	s.testCreateTransientScopeForHelperRejects(c, "/usr/sbin/helperd (enforce)", 0,
+		`cannot track process 4242: security label "/usr/sbin/helperd" does not belong to a snap`)
+}
+
+func (s *trackingSuite) TestCreateTransientScopeForHelperRejectsOtherSnap(c *C) {
+	s.testCreateTransientScopeForHelperRejects(c, "snap.other.app (enforce)", 0,
+		`cannot track process 4242: process is confined by snap "other"`)
+}
+
+func (s *trackingSuite) TestCreateTransientScopeForHelperRejectsOtherUser(c *C) {
+	s.testCreateTransientScopeForHelperRejects(c, "snap.pkg.app (enforce)", 1000,
+		`cannot track process 4242: process runs as uid 1000 rather than 0`)
+}
+
+func (s *trackingSuite) testCreateTransientScopeForHelperRejects(c *C, label string, uid int, expectedErr string) {
+	s.mockHelperProcess(c, 4242, label)
+	restore := cgroup.MockProcessUid(func(pid int) (int, error) {
+		return uid, nil
+	})
+	defer restore()
+
+	restore = cgroup.MockDoCreateTransientScopeInSlice(func(conn *dbus.Conn, unitName string, pid int, slice string) error {
+		c.Fatal("unexpected call")
+		return nil
+	})
+	defer restore()
+	restore = cgroup.MockCgroupProcessPathInTrackingCgroup(func(pid int) (string, error) {
+		return "/system.slice/helper-daemon.service", nil
+	})
+	defer restore()
+
+	err := cgroup.CreateTransientScopeForHelper("snap.pkg.app", 4242, &cgroup.HelperTrackingOptions{Uid: 0})
+	c.Check(err, ErrorMatches, expectedErr)
+}
+
 type testTransientScopeConfirm struct {
 	uuid        string
 	securityTag string
@@ -798,6 +959,48 @@ func (s *trackingSuite) TestDoCreateTransientScopeHappyCgroupV1(c *C) {
 	c.Assert(err, IsNil)
 }
 
+func (s *trackingSuite) TestDoCreateTransientScopeInSlice(c *C) {
+	restore := cgroup.MockVersion(cgroup.V1, nil)
+	defer restore()
+
+	type Property struct {
+		Name  string
+		Value any
+	}
+	type Unit struct {
+		Name  string
+		Props []Property
+	}
+	conn, err := dbustest.Connection(func(msg *dbus.Message, n int) ([]*dbus.Message, error) {
+		switch n {
+		case 0:
+			c.Check(msg.Body, DeepEquals, []any{
+				"foo.scope",
+				"fail",
+				[][]any{
+					{"PIDs", dbus.MakeVariant([]uint32{uint32(312123)})},
+					{"Slice", dbus.MakeVariant("snap.grp.slice")},
+				},
+				[][]any{},
+			})
+			return []*dbus.Message{{
+				Type: dbus.TypeMethodReply,
+				Headers: map[dbus.HeaderField]dbus.Variant{
+					dbus.FieldReplySerial: dbus.MakeVariant(msg.Serial()),
+					dbus.FieldSender:      dbus.MakeVariant(":1"),
+					dbus.FieldSignature:   dbus.MakeVariant(dbus.SignatureOf(dbus.ObjectPath(""))),
+				},
+				Body: []any{dbus.ObjectPath("/org/freedesktop/systemd1/job/1462")},
+			}}, nil
+		}
+		return nil, fmt.Errorf("unexpected message #%d: %s", n, msg)
+	})
+	c.Assert(err, IsNil)
+	defer conn.Close()
+	err = cgroup.DoCreateTransientScopeInSlice(conn, "foo.scope", 312123, "snap.grp.slice")
+	c.Assert(err, IsNil)
+}
+
 func (s *trackingSuite) TestDoCreateTransientScopeForwardedErrors(c *C) {
 	// Certain errors are forwarded and handled in the logic calling into
 	// DoCreateTransientScope. Those are tested here.
@@ -0,0 +1,163 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package sbom
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snapdtool"
+)
+
+type cdxDocument struct {
+	BOMFormat    string          `json:"bomFormat"`
+	SpecVersion  string          `json:"specVersion"`
+	SerialNumber string          `json:"serialNumber"`
+	Version      int             `json:"version"`
+	Metadata     cdxMetadata     `json:"metadata"`
+	Components   []cdxComponent  `json:"components"`
+	Dependencies []cdxDependency `json:"dependencies,omitempty"`
+}
+
+type cdxMetadata struct {
+	Timestamp  string        `json:"timestamp"`
+	Tools      cdxTools      `json:"tools"`
+	Properties []cdxProperty `json:"properties,omitempty"`
+}
+
+type cdxTools struct {
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxComponent struct {
+	Type       string        `json:"type"`
+	BOMRef     string        `json:"bom-ref,omitempty"`
+	Name       string        `json:"name"`
+	Version    string        `json:"version,omitempty"`
+	Publisher  string        `json:"publisher,omitempty"`
+	Hashes     []cdxHash     `json:"hashes,omitempty"`
+	PURL       string        `json:"purl,omitempty"`
+	Properties []cdxProperty `json:"properties,omitempty"`
+}
+
+type cdxHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+func cdxRef(instanceName string) string {
+	return "snap:" + instanceName
+}
+
+func cdxType(typ snap.Type) string {
+	switch typ {
+	case snap.TypeOS, snap.TypeBase, snap.TypeKernel:
+		return "operating-system"
+	default:
+		return "application"
+	}
+}
+
+func generateCycloneDX(inv *Inventory) (*cdxDocument, error) {
+	uuid, err := randomUUID()
+	if err != nil {
+		return nil, fmt.Errorf("cannot generate serial number: %v", err)
+	}
+
+	doc := &cdxDocument{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + uuid,
+		Version:      1,
+		Metadata: cdxMetadata{
+			Timestamp: inv.Timestamp.Format(time.RFC3339),
+			Tools: cdxTools{
+				Components: []cdxComponent{{
+					Type:    "application",
+					Name:    "snapd",
+					Version: snapdtool.Version,
+				}},
+			},
+		},
+		Components: make([]cdxComponent, 0, len(inv.Snaps)),
+	}
+
+	installed := make(map[string]bool, len(inv.Snaps))
+	for _, sn := range inv.Snaps {
+		installed[sn.Name] = true
+	}
+
+	for i := range inv.Snaps {
+		sn := &inv.Snaps[i]
+		comp := cdxComponent{
+			Type:      cdxType(sn.Type),
+			BOMRef:    cdxRef(sn.Name),
+			Name:      sn.Name,
+			Version:   sn.Version,
+			Publisher: publisherName(sn),
+			PURL:      packageURL(sn),
+			Properties: []cdxProperty{
+				{Name: "snap:revision", Value: sn.Revision.String()},
+				{Name: "snap:type", Value: string(sn.Type)},
+			},
+		}
+		if sn.SnapID != "" {
+			comp.Properties = append(comp.Properties, cdxProperty{Name: "snap:id", Value: sn.SnapID})
+		}
+		if sn.Channel != "" {
+			comp.Properties = append(comp.Properties, cdxProperty{Name: "snap:channel", Value: sn.Channel})
+		}
+		if sn.SHA3_384 != "" {
+			digest, err := hexDigest(sn.SHA3_384)
+			if err != nil {
+				return nil, err
+			}
+			comp.Hashes = []cdxHash{{Alg: "SHA3-384", Content: digest}}
+		}
+		doc.Components = append(doc.Components, comp)
+
+		if sn.Base != "" && installed[sn.Base] {
+			doc.Dependencies = append(doc.Dependencies, cdxDependency{
+				Ref:       comp.BOMRef,
+				DependsOn: []string{cdxRef(sn.Base)},
+			})
+		}
+	}
+
+	for i := range inv.ValidationSets {
+		doc.Metadata.Properties = append(doc.Metadata.Properties, cdxProperty{
+			Name:  "snap:validation-set",
+			Value: inv.ValidationSets[i].String(),
+		})
+	}
+
+	return doc, nil
+}
@@ -0,0 +1,131 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package sbom produces software bills of materials describing the snaps
+// installed on a device.
+package sbom
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/snapcore/snapd/randutil"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snapdtool"
+)
+
+// Format is the format of a generated bill of materials.
+type Format string
+
+const (
+	// FormatSPDX is the SPDX 2.3 JSON format.
+	FormatSPDX Format = "spdx"
+	// FormatCycloneDX is the CycloneDX 1.5 JSON format.
+	FormatCycloneDX Format = "cyclonedx"
+)
+
+// Snap describes an installed snap.
+type Snap struct {
+	Name      string
+	SnapID    string
+	Version   string
+	Revision  snap.Revision
+	Type      snap.Type
+	Base      string
+	Channel   string
+	Publisher snap.StoreAccount
+	// SHA3_384 is the digest of the snap file, encoded as in assertions.
+	SHA3_384 string
+}
+
+// ValidationSet describes a validation set in force on the device.
+type ValidationSet struct {
+	AccountID string
+	Name      string
+	Sequence  int
+}
+
+func (vs *ValidationSet) String() string {
+	return fmt.Sprintf("%s/%s=%d", vs.AccountID, vs.Name, vs.Sequence)
+}
+
+// Inventory is the set of software a bill of materials is generated from.
+type Inventory struct {
+	Snaps          []Snap
+	ValidationSets []ValidationSet
+	Timestamp      time.Time
+}
+
+var randomUUID = randutil.RandomKernelUUID
+
+// Generate produces a bill of materials for the inventory in the given
+// format. The result is meant to be marshalled to JSON.
+func Generate(inv *Inventory, format Format) (interface{}, error) {
+	snaps := append([]Snap(nil), inv.Snaps...)
+	sort.Slice(snaps, func(i, j int) bool { return snaps[i].Name < snaps[j].Name })
+	inv = &Inventory{
+		Snaps:          snaps,
+		ValidationSets: inv.ValidationSets,
+		Timestamp:      inv.Timestamp.UTC(),
+	}
+
+	switch format {
+	case FormatSPDX:
+		return generateSPDX(inv)
+	case FormatCycloneDX:
+		return generateCycloneDX(inv)
+	default:
+		return nil, fmt.Errorf("cannot generate bill of materials: unknown format %q", format)
+	}
+}
+
+// hexDigest converts a digest encoded as in assertions to the hex encoding
+// expected by the bill of materials formats.
+func hexDigest(digest string) (string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(digest)
+	if err != nil {
+		return "", fmt.Errorf("cannot decode snap digest %q: %v", digest, err)
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+// packageURL returns the package URL identifying the snap revision.
+func packageURL(sn *Snap) string {
+	q := url.Values{}
+	q.Set("revision", sn.Revision.String())
+	if sn.Channel != "" {
+		q.Set("channel", sn.Channel)
+	}
+	return fmt.Sprintf("pkg:snap/%s@%s?%s", sn.Name, url.PathEscape(sn.Version), q.Encode())
+}
+
+func publisherName(sn *Snap) string {
+	if sn.Publisher.Username != "" {
+		return sn.Publisher.Username
+	}
+	return sn.Publisher.ID
+}
+
+func toolName() string {
+	return "snapd-" + snapdtool.Version
+}
@@ -0,0 +1,250 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package sbom_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/sbom"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snapdtool"
+	"github.com/snapcore/snapd/testutil"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type sbomSuite struct {
+	testutil.BaseTest
+
+	inv *sbom.Inventory
+}
+
+var _ = Suite(&sbomSuite{})
+
+func (s *sbomSuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
+	s.AddCleanup(snapdtool.MockVersion("2.70"))
+	s.AddCleanup(sbom.MockRandomUUID(func() (string, error) {
+		return "5f29e5a2-9d2b-4a55-b9e4-7b2f1f0c6a11", nil
+	}))
+
+	s.inv = &sbom.Inventory{
+		Snaps: []sbom.Snap{
+			{
+				Name:      "hello_foo",
+				Version:   "2.10",
+				Revision:  snap.R(42),
+				Type:      snap.TypeApp,
+				Base:      "core22",
+				Channel:   "latest/stable",
+				SnapID:    "hello-id",
+				Publisher: snap.StoreAccount{ID: "canonical", Username: "canonical"},
+				// sha3-384 of the empty string
+				SHA3_384: "DGOnW4ReT30BEH2FLkwkhcUaUKqqlPxhmV5xu-6YOirDcTgxJkrbR_tr0eBY1fAE",
+			},
+			{
+				Name:      "core22",
+				Version:   "20240111",
+				Revision:  snap.R(1122),
+				Type:      snap.TypeBase,
+				Channel:   "latest/stable",
+				SnapID:    "core22-id",
+				Publisher: snap.StoreAccount{ID: "canonical", Username: "canonical"},
+			},
+			{
+				Name:     "local",
+				Version:  "1.0",
+				Revision: snap.R(-1),
+				Type:     snap.TypeApp,
+				Base:     "core24",
+			},
+		},
+		ValidationSets: []sbom.ValidationSet{
+			{AccountID: "acme", Name: "base-set", Sequence: 3},
+		},
+		Timestamp: time.Date(2026, 10, 16, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60)),
+	}
+}
+
+const emptySHA3_384Hex = "0c63a75b845e4f7d01107d852e4c2485c51a50aaaa94fc61995e71bbee983a2ac3713831264adb47fb6bd1e058d5f004"
+
+func checkJSON(c *C, doc interface{}, expected string) {
+	obtained, err := json.Marshal(doc)
+	c.Assert(err, IsNil)
+	var obtainedValue, expectedValue interface{}
+	c.Assert(json.Unmarshal(obtained, &obtainedValue), IsNil)
+	c.Assert(json.Unmarshal([]byte(expected), &expectedValue), IsNil)
+	c.Check(obtainedValue, DeepEquals, expectedValue, Commentf("obtained:\n%s", obtained))
+}
+
+func (s *sbomSuite) TestGenerateSPDX(c *C) {
+	doc, err := sbom.Generate(s.inv, sbom.FormatSPDX)
+	c.Assert(err, IsNil)
+	checkJSON(c, doc, `{
+  "spdxVersion": "SPDX-2.3",
+  "dataLicense": "CC0-1.0",
+  "SPDXID": "SPDXRef-DOCUMENT",
+  "name": "snaps",
+  "documentNamespace": "https://snapcraft.io/spdxdocs/snaps-5f29e5a2-9d2b-4a55-b9e4-7b2f1f0c6a11",
+  "creationInfo": {"created": "2026-10-16T10:00:00Z", "creators": ["Tool: snapd-2.70"]},
+  "packages": [
+    {
+      "SPDXID": "SPDXRef-Snap-core22",
+      "name": "core22",
+      "versionInfo": "20240111",
+      "supplier": "Organization: canonical",
+      "downloadLocation": "NOASSERTION",
+      "filesAnalyzed": false,
+      "primaryPackagePurpose": "OPERATING-SYSTEM",
+      "externalRefs": [{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:snap/core22@20240111?channel=latest%2Fstable&revision=1122"}],
+      "comment": "revision: 1122, type: base"
+    },
+    {
+      "SPDXID": "SPDXRef-Snap-hello.foo",
+      "name": "hello_foo",
+      "versionInfo": "2.10",
+      "supplier": "Organization: canonical",
+      "downloadLocation": "NOASSERTION",
+      "filesAnalyzed": false,
+      "primaryPackagePurpose": "APPLICATION",
+      "checksums": [{"algorithm": "SHA3-384", "checksumValue": "`+emptySHA3_384Hex+`"}],
+      "externalRefs": [{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:snap/hello_foo@2.10?channel=latest%2Fstable&revision=42"}],
+      "comment": "revision: 42, type: app"
+    },
+    {
+      "SPDXID": "SPDXRef-Snap-local",
+      "name": "local",
+      "versionInfo": "1.0",
+      "supplier": "NOASSERTION",
+      "downloadLocation": "NOASSERTION",
+      "filesAnalyzed": false,
+      "primaryPackagePurpose": "APPLICATION",
+      "externalRefs": [{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:snap/local@1.0?revision=x1"}],
+      "comment": "revision: x1, type: app"
+    }
+  ],
+  "relationships": [
+    {"spdxElementId": "SPDXRef-DOCUMENT", "relationshipType": "DESCRIBES", "relatedSpdxElement": "SPDXRef-Snap-core22"},
+    {"spdxElementId": "SPDXRef-DOCUMENT", "relationshipType": "DESCRIBES", "relatedSpdxElement": "SPDXRef-Snap-hello.foo"},
+    {"spdxElementId": "SPDXRef-Snap-hello.foo", "relationshipType": "DEPENDS_ON", "relatedSpdxElement": "SPDXRef-Snap-core22"},
+    {"spdxElementId": "SPDXRef-DOCUMENT", "relationshipType": "DESCRIBES", "relatedSpdxElement": "SPDXRef-Snap-local"}
+  ],
+  "annotations": [
+    {"annotationDate": "2026-10-16T10:00:00Z", "annotationType": "OTHER", "annotator": "Tool: snapd-2.70", "comment": "validation-set: acme/base-set=3"}
+  ]
+}`)
+}
+
+func (s *sbomSuite) TestGenerateCycloneDX(c *C) {
+	doc, err := sbom.Generate(s.inv, sbom.FormatCycloneDX)
+	c.Assert(err, IsNil)
+	checkJSON(c, doc, `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "serialNumber": "urn:uuid:5f29e5a2-9d2b-4a55-b9e4-7b2f1f0c6a11",
+  "version": 1,
+  "metadata": {
+    "timestamp": "2026-10-16T10:00:00Z",
+    "tools": {"components": [{"type": "application", "name": "snapd", "version": "2.70"}]},
+    "properties": [{"name": "snap:validation-set", "value": "acme/base-set=3"}]
+  },
+  "components": [
+    {
+      "type": "operating-system",
+      "bom-ref": "snap:core22",
+      "name": "core22",
+      "version": "20240111",
+      "publisher": "canonical",
+      "purl": "pkg:snap/core22@20240111?channel=latest%2Fstable&revision=1122",
+      "properties": [
+        {"name": "snap:revision", "value": "1122"},
+        {"name": "snap:type", "value": "base"},
+        {"name": "snap:id", "value": "core22-id"},
+        {"name": "snap:channel", "value": "latest/stable"}
+      ]
+    },
+    {
+      "type": "application",
+      "bom-ref": "snap:hello_foo",
+      "name": "hello_foo",
+      "version": "2.10",
+      "publisher": "canonical",
+      "hashes": [{"alg": "SHA3-384", "content": "`+emptySHA3_384Hex+`"}],
+      "purl": "pkg:snap/hello_foo@2.10?channel=latest%2Fstable&revision=42",
+      "properties": [
+        {"name": "snap:revision", "value": "42"},
+        {"name": "snap:type", "value": "app"},
+        {"name": "snap:id", "value": "hello-id"},
+        {"name": "snap:channel", "value": "latest/stable"}
+      ]
+    },
+    {
+      "type": "application",
+      "bom-ref": "snap:local",
+      "name": "local",
+      "version": "1.0",
+      "purl": "pkg:snap/local@1.0?revision=x1",
+      "properties": [
+        {"name": "snap:revision", "value": "x1"},
+        {"name": "snap:type", "value": "app"}
+      ]
+    }
+  ],
+  "dependencies": [
+    {"ref": "snap:hello_foo", "dependsOn": ["snap:core22"]}
+  ]
+}`)
+}
+
+func (s *sbomSuite) TestGenerateDoesNotReorderInventory(c *C) {
+	_, err := sbom.Generate(s.inv, sbom.FormatSPDX)
+	c.Assert(err, IsNil)
+	c.Check(s.inv.Snaps[0].Name, Equals, "hello_foo")
+}
+
+func (s *sbomSuite) TestGenerateUnknownFormat(c *C) {
+	_, err := sbom.Generate(s.inv, "swid")
+	c.Check(err, ErrorMatches, `cannot generate bill of materials: unknown format "swid"`)
+}
+
+func (s *sbomSuite) TestGenerateBadDigest(c *C) {
+	s.inv.Snaps[0].SHA3_384 = "!!"
+	for _, format := range []sbom.Format{sbom.FormatSPDX, sbom.FormatCycloneDX} {
+		_, err := sbom.Generate(s.inv, format)
+		c.Check(err, ErrorMatches, `cannot decode snap digest "!!": .*`)
+	}
+}
+
+func (s *sbomSuite) TestGenerateUUIDError(c *C) {
+	restore := sbom.MockRandomUUID(func() (string, error) {
+		return "", errors.New("boom")
+	})
+	defer restore()
+
+	_, err := sbom.Generate(s.inv, sbom.FormatSPDX)
+	c.Check(err, ErrorMatches, "cannot generate document namespace: boom")
+	_, err = sbom.Generate(s.inv, sbom.FormatCycloneDX)
+	c.Check(err, ErrorMatches, "cannot generate serial number: boom")
+}
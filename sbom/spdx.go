@@ -0,0 +1,178 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package sbom
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/snapcore/snapd/snap"
+)
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+	Annotations       []spdxAnnotation   `json:"annotations,omitempty"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	SPDXID                string            `json:"SPDXID"`
+	Name                  string            `json:"name"`
+	VersionInfo           string            `json:"versionInfo"`
+	Supplier              string            `json:"supplier"`
+	DownloadLocation      string            `json:"downloadLocation"`
+	FilesAnalyzed         bool              `json:"filesAnalyzed"`
+	PrimaryPackagePurpose string            `json:"primaryPackagePurpose"`
+	Checksums             []spdxChecksum    `json:"checksums,omitempty"`
+	ExternalRefs          []spdxExternalRef `json:"externalRefs"`
+	Comment               string            `json:"comment,omitempty"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+type spdxAnnotation struct {
+	AnnotationDate string `json:"annotationDate"`
+	AnnotationType string `json:"annotationType"`
+	Annotator      string `json:"annotator"`
+	Comment        string `json:"comment"`
+}
+
+// spdxID returns the SPDX identifier of the snap, instance keys are
+// separated by a dot as underscores are not allowed in identifiers.
+func spdxID(instanceName string) string {
+	return "SPDXRef-Snap-" + strings.Replace(instanceName, "_", ".", 1)
+}
+
+func spdxPurpose(typ snap.Type) string {
+	switch typ {
+	case snap.TypeOS, snap.TypeBase, snap.TypeKernel:
+		return "OPERATING-SYSTEM"
+	default:
+		return "APPLICATION"
+	}
+}
+
+func generateSPDX(inv *Inventory) (*spdxDocument, error) {
+	uuid, err := randomUUID()
+	if err != nil {
+		return nil, fmt.Errorf("cannot generate document namespace: %v", err)
+	}
+	created := inv.Timestamp.Format(time.RFC3339)
+	tool := "Tool: " + toolName()
+
+	doc := &spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              "snaps",
+		DocumentNamespace: "https://snapcraft.io/spdxdocs/snaps-" + uuid,
+		CreationInfo: spdxCreationInfo{
+			Created:  created,
+			Creators: []string{tool},
+		},
+		Packages:      make([]spdxPackage, 0, len(inv.Snaps)),
+		Relationships: make([]spdxRelationship, 0, len(inv.Snaps)),
+	}
+
+	installed := make(map[string]bool, len(inv.Snaps))
+	for _, sn := range inv.Snaps {
+		installed[sn.Name] = true
+	}
+
+	for i := range inv.Snaps {
+		sn := &inv.Snaps[i]
+		pkg := spdxPackage{
+			SPDXID:                spdxID(sn.Name),
+			Name:                  sn.Name,
+			VersionInfo:           sn.Version,
+			Supplier:              "NOASSERTION",
+			DownloadLocation:      "NOASSERTION",
+			PrimaryPackagePurpose: spdxPurpose(sn.Type),
+			ExternalRefs: []spdxExternalRef{{
+				ReferenceCategory: "PACKAGE-MANAGER",
+				ReferenceType:     "purl",
+				ReferenceLocator:  packageURL(sn),
+			}},
+			Comment: fmt.Sprintf("revision: %s, type: %s", sn.Revision, sn.Type),
+		}
+		if publisher := publisherName(sn); publisher != "" {
+			pkg.Supplier = "Organization: " + publisher
+		}
+		if sn.SHA3_384 != "" {
+			digest, err := hexDigest(sn.SHA3_384)
+			if err != nil {
+				return nil, err
+			}
+			pkg.Checksums = []spdxChecksum{{Algorithm: "SHA3-384", ChecksumValue: digest}}
+		}
+		doc.Packages = append(doc.Packages, pkg)
+
+		doc.Relationships = append(doc.Relationships, spdxRelationship{
+			SPDXElementID:      doc.SPDXID,
+			RelationshipType:   "DESCRIBES",
+			RelatedSPDXElement: pkg.SPDXID,
+		})
+		if sn.Base != "" && installed[sn.Base] {
+			doc.Relationships = append(doc.Relationships, spdxRelationship{
+				SPDXElementID:      pkg.SPDXID,
+				RelationshipType:   "DEPENDS_ON",
+				RelatedSPDXElement: spdxID(sn.Base),
+			})
+		}
+	}
+
+	for i := range inv.ValidationSets {
+		doc.Annotations = append(doc.Annotations, spdxAnnotation{
+			AnnotationDate: created,
+			AnnotationType: "OTHER",
+			Annotator:      tool,
+			Comment:        "validation-set: " + inv.ValidationSets[i].String(),
+		})
+	}
+
+	return doc, nil
+}
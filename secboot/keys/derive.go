@@ -0,0 +1,99 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package keys
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// PrimaryKeySize is the size of the primary key from which per-volume
+// unlock keys are derived.
+const PrimaryKeySize = 32
+
+// volumeKeyLabel is the HKDF info prefix for per-volume unlock keys. It
+// must never change, otherwise keys derived on existing devices would no
+// longer match.
+const volumeKeyLabel = "snapd per-volume unlock key"
+
+// PrimaryKey is the key which is protected once (e.g. sealed to the TPM)
+// and from which the unlock keys of the individual encrypted volumes are
+// derived.
+type PrimaryKey []byte
+
+// NewPrimaryKey creates a new random primary key.
+func NewPrimaryKey() (PrimaryKey, error) {
+	key := make(PrimaryKey, PrimaryKeySize)
+	// rand.Read() is protected against short reads
+	_, err := randRead(key[:])
+	// On return, n == len(b) if and only if err == nil
+	return key, err
+}
+
+// DeriveVolumeKey derives the unlock key of the volume with the given role
+// (e.g. "system-data" or "system-save") from the primary key using
+// HKDF-SHA256. The same primary key and role always yield the same key,
+// while different roles yield unrelated keys.
+func (pk PrimaryKey) DeriveVolumeKey(role string) (EncryptionKey, error) {
+	if len(pk) != PrimaryKeySize {
+		return nil, fmt.Errorf("cannot derive volume key: invalid primary key size %d", len(pk))
+	}
+	if role == "" {
+		return nil, fmt.Errorf("cannot derive volume key: role is unset")
+	}
+	r := hkdf.New(sha256.New, pk, nil, []byte(volumeKeyLabel+"/"+role))
+	key := make(EncryptionKey, EncryptionKeySize)
+	if _, err := io.ReadFull(r, key); err != nil {
+		return nil, fmt.Errorf("cannot derive volume key for %q: %v", role, err)
+	}
+	return key, nil
+}
+
+// DeriveVolumeKeys derives the unlock keys for all the given roles, see
+// DeriveVolumeKey.
+func (pk PrimaryKey) DeriveVolumeKeys(roles ...string) (map[string]EncryptionKey, error) {
+	derived := make(map[string]EncryptionKey, len(roles))
+	for _, role := range roles {
+		if _, ok := derived[role]; ok {
+			return nil, fmt.Errorf("cannot derive volume keys: duplicate role %q", role)
+		}
+		key, err := pk.DeriveVolumeKey(role)
+		if err != nil {
+			return nil, err
+		}
+		derived[role] = key
+	}
+	return derived, nil
+}
+
+// DeriveProtectorKey derives the protector key of the plainkey protecting
+// the volume with the given role from the primary key. This lets the
+// volume be unlocked with only the primary key available, e.g. when the
+// protector key file is missing.
+func (pk PrimaryKey) DeriveProtectorKey(role string) (ProtectorKey, error) {
+	key, err := pk.DeriveVolumeKey(role)
+	if err != nil {
+		return nil, err
+	}
+	return ProtectorKey(key), nil
+}
@@ -0,0 +1,116 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package keys_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/secboot/keys"
+)
+
+type deriveSuite struct{}
+
+var _ = Suite(&deriveSuite{})
+
+func (s *deriveSuite) TestNewPrimaryKey(c *C) {
+	restore := keys.MockRandRead(func(p []byte) (int, error) {
+		for i := range p {
+			p[i] = byte(i)
+		}
+		return len(p), nil
+	})
+	defer restore()
+
+	pk, err := keys.NewPrimaryKey()
+	c.Assert(err, IsNil)
+	c.Assert(pk, HasLen, keys.PrimaryKeySize)
+	c.Check(pk[31], Equals, byte(31))
+}
+
+func (s *deriveSuite) TestDeriveVolumeKey(c *C) {
+	pk := keys.PrimaryKey(bytes.Repeat([]byte{0x42}, keys.PrimaryKeySize))
+
+	dataKey, err := pk.DeriveVolumeKey("system-data")
+	c.Assert(err, IsNil)
+	c.Assert(dataKey, HasLen, keys.EncryptionKeySize)
+
+	// derivation is stable
+	again, err := pk.DeriveVolumeKey("system-data")
+	c.Assert(err, IsNil)
+	c.Check(again, DeepEquals, dataKey)
+
+	// and matches plain HKDF-SHA256 with the role label
+	expected := make([]byte, keys.EncryptionKeySize)
+	_, err = io.ReadFull(hkdf.New(sha256.New, pk, nil, []byte("snapd per-volume unlock key/system-data")), expected)
+	c.Assert(err, IsNil)
+	c.Check([]byte(dataKey), DeepEquals, expected)
+
+	// different roles give different keys
+	saveKey, err := pk.DeriveVolumeKey("system-save")
+	c.Assert(err, IsNil)
+	c.Check(saveKey, Not(DeepEquals), dataKey)
+
+	// as do different primary keys
+	otherPK := keys.PrimaryKey(bytes.Repeat([]byte{0x43}, keys.PrimaryKeySize))
+	otherDataKey, err := otherPK.DeriveVolumeKey("system-data")
+	c.Assert(err, IsNil)
+	c.Check(otherDataKey, Not(DeepEquals), dataKey)
+}
+
+func (s *deriveSuite) TestDeriveVolumeKeyErrors(c *C) {
+	_, err := keys.PrimaryKey([]byte{1, 2, 3}).DeriveVolumeKey("system-data")
+	c.Check(err, ErrorMatches, "cannot derive volume key: invalid primary key size 3")
+
+	pk := keys.PrimaryKey(make([]byte, keys.PrimaryKeySize))
+	_, err = pk.DeriveVolumeKey("")
+	c.Check(err, ErrorMatches, "cannot derive volume key: role is unset")
+}
+
+func (s *deriveSuite) TestDeriveVolumeKeys(c *C) {
+	pk := keys.PrimaryKey(bytes.Repeat([]byte{0x42}, keys.PrimaryKeySize))
+
+	derived, err := pk.DeriveVolumeKeys("system-data", "system-save")
+	c.Assert(err, IsNil)
+	c.Assert(derived, HasLen, 2)
+	dataKey, err := pk.DeriveVolumeKey("system-data")
+	c.Assert(err, IsNil)
+	c.Check(derived["system-data"], DeepEquals, dataKey)
+
+	_, err = pk.DeriveVolumeKeys("system-data", "system-data")
+	c.Check(err, ErrorMatches, `cannot derive volume keys: duplicate role "system-data"`)
+}
+
+func (s *deriveSuite) TestDeriveProtectorKey(c *C) {
+	pk := keys.PrimaryKey(bytes.Repeat([]byte{0x42}, keys.PrimaryKeySize))
+
+	protectorKey, err := pk.DeriveProtectorKey("system-save")
+	c.Assert(err, IsNil)
+	saveKey, err := pk.DeriveVolumeKey("system-save")
+	c.Assert(err, IsNil)
+	c.Check([]byte(protectorKey), DeepEquals, []byte(saveKey))
+
+	_, err = keys.PrimaryKey([]byte{1, 2, 3}).DeriveProtectorKey("system-save")
+	c.Check(err, ErrorMatches, "cannot derive volume key: invalid primary key size 3")
+}
@@ -0,0 +1,454 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package netkey implements the client side of network-bound disk
+// encryption with Tang style key servers.
+//
+// Keys are bound to a server with the McCallum-Relyea exchange: when
+// provisioning, a key is derived from the public exchange key of the
+// server and from a client key pair, of which only the public half is
+// kept. Recovering the key later needs the server to take part in the
+// exchange, without the server ever learning the key.
+package netkey
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/hkdf"
+
+	"github.com/snapcore/snapd/logger"
+)
+
+// KeySize is the size of the keys derived with the help of a server.
+const KeySize = 32
+
+const (
+	algECMR      = "ECMR"
+	opDeriveKey  = "deriveKey"
+	opVerify     = "verify"
+	maxReplySize = 64 * 1024
+)
+
+// info of the key derivation, changing it changes all derived keys
+var hkdfInfo = []byte("snapd network-bound disk encryption")
+
+// JWK is an elliptic curve JSON web key, as exchanged with servers.
+type JWK struct {
+	Kty    string   `json:"kty"`
+	Crv    string   `json:"crv"`
+	X      string   `json:"x"`
+	Y      string   `json:"y"`
+	Alg    string   `json:"alg,omitempty"`
+	KeyOps []string `json:"key_ops,omitempty"`
+}
+
+func curveByName(name string) (elliptic.Curve, error) {
+	switch name {
+	case "P-256":
+		return elliptic.P256(), nil
+	case "P-384":
+		return elliptic.P384(), nil
+	case "P-521":
+		return elliptic.P521(), nil
+	}
+	return nil, fmt.Errorf("unsupported curve %q", name)
+}
+
+func coordinateSize(curve elliptic.Curve) int {
+	return (curve.Params().BitSize + 7) / 8
+}
+
+func newJWK(curve elliptic.Curve, x, y *big.Int) *JWK {
+	size := coordinateSize(curve)
+	return &JWK{
+		Kty: "EC",
+		Crv: curve.Params().Name,
+		X:   base64.RawURLEncoding.EncodeToString(x.FillBytes(make([]byte, size))),
+		Y:   base64.RawURLEncoding.EncodeToString(y.FillBytes(make([]byte, size))),
+	}
+}
+
+// point returns the curve and the point of the public key.
+func (k *JWK) point() (curve elliptic.Curve, x, y *big.Int, err error) {
+	if k.Kty != "EC" {
+		return nil, nil, nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+	curve, err = curveByName(k.Crv)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	coords := make([]*big.Int, 2)
+	for i, c := range []string{k.X, k.Y} {
+		b, err := base64.RawURLEncoding.DecodeString(c)
+		if err != nil || len(b) != coordinateSize(curve) {
+			return nil, nil, nil, fmt.Errorf("invalid coordinates")
+		}
+		coords[i] = new(big.Int).SetBytes(b)
+	}
+	if !curve.IsOnCurve(coords[0], coords[1]) {
+		return nil, nil, nil, fmt.Errorf("point is not on curve %s", k.Crv)
+	}
+	return curve, coords[0], coords[1], nil
+}
+
+func (k *JWK) hasOp(op string) bool {
+	for _, o := range k.KeyOps {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+// Thumbprint returns the SHA-256 thumbprint of the key, as defined by
+// RFC 7638, which identifies the keys of servers.
+func (k *JWK) Thumbprint() string {
+	// members in lexicographic order, without white space
+	canonical, _ := json.Marshal(struct {
+		Crv string `json:"crv"`
+		Kty string `json:"kty"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+	}{k.Crv, k.Kty, k.X, k.Y})
+	sum := sha256.Sum256(canonical)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// Server is a key server.
+type Server struct {
+	// URL is the base URL of the server.
+	URL string
+	// Thumbprint is the thumbprint of the exchange key of the server, or
+	// of a key signing its advertisement, which is how the server is
+	// trusted.
+	Thumbprint string
+}
+
+// Binding holds what is needed to recover a key with the help of a
+// server, none of it is secret.
+type Binding struct {
+	URL       string `json:"url"`
+	ServerKey *JWK   `json:"server-key"`
+	ClientKey *JWK   `json:"client-key"`
+}
+
+// advertisement is the JSON web signature of the keys of a server.
+type advertisement struct {
+	Payload    string         `json:"payload"`
+	Protected  string         `json:"protected,omitempty"`
+	Signature  string         `json:"signature,omitempty"`
+	Signatures []jwsSignature `json:"signatures,omitempty"`
+}
+
+type jwsSignature struct {
+	Protected string `json:"protected"`
+	Signature string `json:"signature"`
+}
+
+func (adv *advertisement) signatures() []jwsSignature {
+	if adv.Signature != "" {
+		return append(adv.Signatures, jwsSignature{Protected: adv.Protected, Signature: adv.Signature})
+	}
+	return adv.Signatures
+}
+
+func (adv *advertisement) keys() ([]*JWK, error) {
+	payload, err := base64.RawURLEncoding.DecodeString(adv.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode payload: %v", err)
+	}
+	var set struct {
+		Keys []*JWK `json:"keys"`
+	}
+	if err := json.Unmarshal(payload, &set); err != nil {
+		return nil, fmt.Errorf("cannot decode payload: %v", err)
+	}
+	return set.Keys, nil
+}
+
+func hashForAlg(alg string) func() hash.Hash {
+	switch alg {
+	case "ES256":
+		return sha256.New
+	case "ES384":
+		return sha512.New384
+	case "ES512":
+		return sha512.New
+	}
+	return nil
+}
+
+// signedBy returns whether the advertisement is signed with the given key.
+func (adv *advertisement) signedBy(key *JWK) bool {
+	curve, x, y, err := key.point()
+	if err != nil {
+		return false
+	}
+	pub := &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+	for _, sig := range adv.signatures() {
+		protected, err := base64.RawURLEncoding.DecodeString(sig.Protected)
+		if err != nil {
+			continue
+		}
+		var header struct {
+			Alg string `json:"alg"`
+		}
+		if err := json.Unmarshal(protected, &header); err != nil {
+			continue
+		}
+		newHash := hashForAlg(header.Alg)
+		if newHash == nil {
+			continue
+		}
+		rs, err := base64.RawURLEncoding.DecodeString(sig.Signature)
+		if err != nil || len(rs) != 2*coordinateSize(curve) {
+			continue
+		}
+		h := newHash()
+		h.Write([]byte(sig.Protected + "." + adv.Payload))
+		r := new(big.Int).SetBytes(rs[:len(rs)/2])
+		s := new(big.Int).SetBytes(rs[len(rs)/2:])
+		if ecdsa.Verify(pub, h.Sum(nil), r, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func exchangeRequest(ctx context.Context, method, url string, body []byte) ([]byte, error) {
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/jwk+json")
+	}
+	rsp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %q", rsp.Status)
+	}
+	return io.ReadAll(io.LimitReader(rsp.Body, maxReplySize))
+}
+
+// exchangeKey returns the exchange key of the server, as trusted with the
+// thumbprint of the server.
+func exchangeKey(ctx context.Context, server *Server) (*JWK, error) {
+	data, err := exchangeRequest(ctx, "GET", strings.TrimSuffix(server.URL, "/")+"/adv", nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get advertisement of %s: %v", server.URL, err)
+	}
+	var adv advertisement
+	if err := json.Unmarshal(data, &adv); err != nil {
+		return nil, fmt.Errorf("cannot decode advertisement of %s: %v", server.URL, err)
+	}
+	keys, err := adv.keys()
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode advertisement of %s: %v", server.URL, err)
+	}
+
+	trusted := false
+	for _, key := range keys {
+		if key.Thumbprint() != server.Thumbprint {
+			continue
+		}
+		if key.Alg == algECMR || key.hasOp(opDeriveKey) {
+			return key, nil
+		}
+		if key.hasOp(opVerify) && adv.signedBy(key) {
+			trusted = true
+		}
+	}
+	if !trusted {
+		return nil, fmt.Errorf("cannot find key with thumbprint %q in advertisement of %s", server.Thumbprint, server.URL)
+	}
+	for _, key := range keys {
+		if key.Alg == algECMR || key.hasOp(opDeriveKey) {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot find exchange key in advertisement of %s", server.URL)
+}
+
+func deriveKey(curve elliptic.Curve, x *big.Int) ([]byte, error) {
+	key := make([]byte, KeySize)
+	r := hkdf.New(sha256.New, x.FillBytes(make([]byte, coordinateSize(curve))), nil, hkdfInfo)
+	if _, err := io.ReadFull(r, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Provision derives a new key bound to the given server, and returns it
+// with what is needed to recover it later.
+func Provision(ctx context.Context, server *Server) (key []byte, binding *Binding, err error) {
+	serverKey, err := exchangeKey(ctx, server)
+	if err != nil {
+		return nil, nil, err
+	}
+	curve, sx, sy, err := serverKey.point()
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid exchange key of %s: %v", server.URL, err)
+	}
+
+	c, cx, cy, err := elliptic.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	kx, _ := curve.ScalarMult(sx, sy, c)
+	key, err = deriveKey(curve, kx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	serverKey = newJWK(curve, sx, sy)
+	serverKey.Alg = algECMR
+	return key, &Binding{
+		URL:       server.URL,
+		ServerKey: serverKey,
+		ClientKey: newJWK(curve, cx, cy),
+	}, nil
+}
+
+// Recover recovers the key of the binding with the help of its server.
+func Recover(ctx context.Context, binding *Binding) ([]byte, error) {
+	curve, sx, sy, err := binding.ServerKey.point()
+	if err != nil {
+		return nil, fmt.Errorf("invalid server key: %v", err)
+	}
+	clientCurve, cx, cy, err := binding.ClientKey.point()
+	if err != nil {
+		return nil, fmt.Errorf("invalid client key: %v", err)
+	}
+	if clientCurve != curve {
+		return nil, fmt.Errorf("invalid client key: unexpected curve %s", binding.ClientKey.Crv)
+	}
+
+	// the server sees the client key blinded with an ephemeral key
+	e, ex, ey, err := elliptic.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	xx, xy := curve.Add(cx, cy, ex, ey)
+	req := newJWK(curve, xx, xy)
+	req.Alg = algECMR
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/rec/%s", strings.TrimSuffix(binding.URL, "/"), binding.ServerKey.Thumbprint())
+	data, err := exchangeRequest(ctx, "POST", url, body)
+	if err != nil {
+		return nil, fmt.Errorf("cannot recover key from %s: %v", binding.URL, err)
+	}
+	var rsp JWK
+	if err := json.Unmarshal(data, &rsp); err != nil {
+		return nil, fmt.Errorf("cannot decode answer of %s: %v", binding.URL, err)
+	}
+	rspCurve, yx, yy, err := rsp.point()
+	if err != nil || rspCurve != curve {
+		return nil, fmt.Errorf("invalid answer of %s", binding.URL)
+	}
+
+	// remove the blinding
+	zx, zy := curve.ScalarMult(sx, sy, e)
+	zy.Sub(curve.Params().P, zy)
+	kx, _ := curve.Add(yx, yy, zx, zy)
+	return deriveKey(curve, kx)
+}
+
+// RecoverOptions control how keys are recovered.
+type RecoverOptions struct {
+	// Timeout is how long to wait for an answer of the server.
+	Timeout time.Duration `json:"timeout,omitempty"`
+	// Attempts is how many times the server is asked for the key.
+	Attempts int `json:"attempts,omitempty"`
+	// RetryDelay is how long to wait before asking again.
+	RetryDelay time.Duration `json:"retry-delay,omitempty"`
+}
+
+const (
+	defaultRecoverTimeout    = 10 * time.Second
+	defaultRecoverAttempts   = 3
+	defaultRecoverRetryDelay = 2 * time.Second
+)
+
+// RecoverWithRetry recovers the key of the binding like Recover, asking
+// the server again when it cannot be reached, as happens while the network
+// is being set up.
+func RecoverWithRetry(ctx context.Context, binding *Binding, opts *RecoverOptions) ([]byte, error) {
+	timeout, attempts, delay := defaultRecoverTimeout, defaultRecoverAttempts, defaultRecoverRetryDelay
+	if opts != nil {
+		if opts.Timeout > 0 {
+			timeout = opts.Timeout
+		}
+		if opts.Attempts > 0 {
+			attempts = opts.Attempts
+		}
+		if opts.RetryDelay > 0 {
+			delay = opts.RetryDelay
+		}
+	}
+
+	var err error
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			logger.Noticef("cannot recover key, retrying: %v", err)
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		var key []byte
+		attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+		key, err = Recover(attemptCtx, binding)
+		cancel()
+		if err == nil {
+			return key, nil
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, err
+}
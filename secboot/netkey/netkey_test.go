@@ -0,0 +1,314 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package netkey_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/secboot/netkey"
+)
+
+func TestNetkey(t *testing.T) { TestingT(t) }
+
+type netkeySuite struct {
+	server *httptest.Server
+
+	exchangeKey *ecdsa.PrivateKey
+	signingKey  *ecdsa.PrivateKey
+	// key signing the advertisement, the signing key by default
+	advSigner *ecdsa.PrivateKey
+
+	failures   int
+	recoveries int
+}
+
+var _ = Suite(&netkeySuite{})
+
+func jwkOf(pub *ecdsa.PublicKey, alg string, ops ...string) *netkey.JWK {
+	size := (pub.Curve.Params().BitSize + 7) / 8
+	return &netkey.JWK{
+		Kty:    "EC",
+		Crv:    pub.Curve.Params().Name,
+		X:      base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, size))),
+		Y:      base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, size))),
+		Alg:    alg,
+		KeyOps: ops,
+	}
+}
+
+func (s *netkeySuite) SetUpTest(c *C) {
+	var err error
+	s.exchangeKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, IsNil)
+	s.signingKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, IsNil)
+	s.advSigner = s.signingKey
+	s.failures = 0
+	s.recoveries = 0
+	s.server = httptest.NewServer(http.HandlerFunc(s.serve))
+}
+
+func (s *netkeySuite) TearDownTest(c *C) {
+	s.server.Close()
+}
+
+// serve acts as a Tang server
+func (s *netkeySuite) serve(w http.ResponseWriter, r *http.Request) {
+	if s.failures > 0 {
+		s.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	exchangeJWK := jwkOf(&s.exchangeKey.PublicKey, "ECMR", "deriveKey")
+	switch {
+	case r.Method == "GET" && r.URL.Path == "/adv":
+		payload, _ := json.Marshal(map[string]any{"keys": []*netkey.JWK{
+			jwkOf(&s.signingKey.PublicKey, "ES256", "verify"),
+			exchangeJWK,
+		}})
+		encPayload := base64.RawURLEncoding.EncodeToString(payload)
+		protected := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"ES256","cty":"jwk-set+json"}`))
+		digest := sha256.Sum256([]byte(protected + "." + encPayload))
+		r, sig, _ := ecdsa.Sign(rand.Reader, s.advSigner, digest[:])
+		rs := append(r.FillBytes(make([]byte, 32)), sig.FillBytes(make([]byte, 32))...)
+		json.NewEncoder(w).Encode(map[string]any{
+			"payload":   encPayload,
+			"protected": protected,
+			"signature": base64.RawURLEncoding.EncodeToString(rs),
+		})
+	case r.Method == "POST" && r.URL.Path == "/rec/"+exchangeJWK.Thumbprint():
+		if r.Header.Get("Content-Type") != "application/jwk+json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var req netkey.JWK
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		x, _ := base64.RawURLEncoding.DecodeString(req.X)
+		y, _ := base64.RawURLEncoding.DecodeString(req.Y)
+		curve := s.exchangeKey.Curve
+		yx, yy := curve.ScalarMult(new(big.Int).SetBytes(x), new(big.Int).SetBytes(y), s.exchangeKey.D.Bytes())
+		s.recoveries++
+		json.NewEncoder(w).Encode(jwkOf(&ecdsa.PublicKey{Curve: curve, X: yx, Y: yy}, "ECMR"))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (s *netkeySuite) TestThumbprint(c *C) {
+	// example key of RFC 7517
+	key := &netkey.JWK{
+		Kty: "EC",
+		Crv: "P-256",
+		X:   "f83OJ3D2xF1Bg8vub9tLe1gHMzV76e8Tus9uPHvRVEU",
+		Y:   "x_FEzRu9m36HLN_tue659LNpXW6pCyStikYjKIWI5a0",
+		Alg: "ES256",
+	}
+	c.Check(key.Thumbprint(), Equals, "oKIywvGUpTVTyxMQ3bwIIeQUudfr_CkLMjCE19ECD-U")
+}
+
+func (s *netkeySuite) testProvisionRecover(c *C, thumbprint string) {
+	key, binding, err := netkey.Provision(context.Background(), &netkey.Server{
+		URL:        s.server.URL,
+		Thumbprint: thumbprint,
+	})
+	c.Assert(err, IsNil)
+	c.Check(key, HasLen, netkey.KeySize)
+	c.Check(binding.URL, Equals, s.server.URL)
+	c.Check(binding.ServerKey.Thumbprint(), Equals, jwkOf(&s.exchangeKey.PublicKey, "").Thumbprint())
+	c.Check(s.recoveries, Equals, 0)
+
+	// the binding can be saved
+	data, err := json.Marshal(binding)
+	c.Assert(err, IsNil)
+	var loaded netkey.Binding
+	c.Assert(json.Unmarshal(data, &loaded), IsNil)
+
+	recovered, err := netkey.Recover(context.Background(), &loaded)
+	c.Assert(err, IsNil)
+	c.Check(recovered, DeepEquals, key)
+	c.Check(s.recoveries, Equals, 1)
+
+	// the client key is blinded differently each time
+	recovered, err = netkey.Recover(context.Background(), &loaded)
+	c.Assert(err, IsNil)
+	c.Check(recovered, DeepEquals, key)
+
+	// and each binding has its own key
+	otherKey, _, err := netkey.Provision(context.Background(), &netkey.Server{
+		URL:        s.server.URL,
+		Thumbprint: thumbprint,
+	})
+	c.Assert(err, IsNil)
+	c.Check(otherKey, Not(DeepEquals), key)
+}
+
+func (s *netkeySuite) TestProvisionRecoverExchangeKeyThumbprint(c *C) {
+	s.testProvisionRecover(c, jwkOf(&s.exchangeKey.PublicKey, "").Thumbprint())
+}
+
+func (s *netkeySuite) TestProvisionRecoverSigningKeyThumbprint(c *C) {
+	s.testProvisionRecover(c, jwkOf(&s.signingKey.PublicKey, "").Thumbprint())
+}
+
+func (s *netkeySuite) TestProvisionUnknownThumbprint(c *C) {
+	_, _, err := netkey.Provision(context.Background(), &netkey.Server{
+		URL:        s.server.URL,
+		Thumbprint: "oKIywvGUpTVTyxMQ3bwIIeQUudfr_CkLMjCE19ECD-U",
+	})
+	c.Assert(err, ErrorMatches, `cannot find key with thumbprint "oKIywvGUpTVTyxMQ3bwIIeQUudfr_CkLMjCE19ECD-U" in advertisement of http://.*`)
+}
+
+func (s *netkeySuite) TestProvisionBadSignature(c *C) {
+	var err error
+	s.advSigner, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, IsNil)
+
+	_, _, err = netkey.Provision(context.Background(), &netkey.Server{
+		URL:        s.server.URL,
+		Thumbprint: jwkOf(&s.signingKey.PublicKey, "").Thumbprint(),
+	})
+	c.Assert(err, ErrorMatches, `cannot find key with thumbprint ".*" in advertisement of http://.*`)
+}
+
+func (s *netkeySuite) TestProvisionServerError(c *C) {
+	s.failures = 1
+	_, _, err := netkey.Provision(context.Background(), &netkey.Server{
+		URL:        s.server.URL,
+		Thumbprint: jwkOf(&s.exchangeKey.PublicKey, "").Thumbprint(),
+	})
+	c.Assert(err, ErrorMatches, `cannot get advertisement of http://.*: unexpected status "503 Service Unavailable"`)
+}
+
+func (s *netkeySuite) provision(c *C) ([]byte, *netkey.Binding) {
+	key, binding, err := netkey.Provision(context.Background(), &netkey.Server{
+		URL:        s.server.URL,
+		Thumbprint: jwkOf(&s.exchangeKey.PublicKey, "").Thumbprint(),
+	})
+	c.Assert(err, IsNil)
+	return key, binding
+}
+
+func (s *netkeySuite) TestRecoverWrongServer(c *C) {
+	_, binding := s.provision(c)
+
+	// the server has a new exchange key
+	var err error
+	s.exchangeKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, IsNil)
+
+	_, err = netkey.Recover(context.Background(), binding)
+	c.Assert(err, ErrorMatches, `cannot recover key from http://.*: unexpected status "404 Not Found"`)
+}
+
+func (s *netkeySuite) TestRecoverInvalidBinding(c *C) {
+	_, binding := s.provision(c)
+	binding.ClientKey.X = "AAAA"
+
+	_, err := netkey.Recover(context.Background(), binding)
+	c.Assert(err, ErrorMatches, `invalid client key: invalid coordinates`)
+
+	binding.ServerKey.Crv = "P-42"
+	_, err = netkey.Recover(context.Background(), binding)
+	c.Assert(err, ErrorMatches, `invalid server key: unsupported curve "P-42"`)
+}
+
+func (s *netkeySuite) TestRecoverWithRetry(c *C) {
+	logbuf, restore := logger.MockLogger()
+	defer restore()
+
+	key, binding := s.provision(c)
+	s.failures = 2
+
+	recovered, err := netkey.RecoverWithRetry(context.Background(), binding, &netkey.RecoverOptions{
+		Attempts:   3,
+		RetryDelay: time.Millisecond,
+	})
+	c.Assert(err, IsNil)
+	c.Check(recovered, DeepEquals, key)
+	c.Check(strings.Count(logbuf.String(), "cannot recover key, retrying"), Equals, 2)
+}
+
+func (s *netkeySuite) TestRecoverWithRetryGivesUp(c *C) {
+	_, restore := logger.MockLogger()
+	defer restore()
+
+	_, binding := s.provision(c)
+	s.failures = 3
+
+	_, err := netkey.RecoverWithRetry(context.Background(), binding, &netkey.RecoverOptions{
+		Attempts:   3,
+		RetryDelay: time.Millisecond,
+	})
+	c.Assert(err, ErrorMatches, `cannot recover key from http://.*: unexpected status "503 Service Unavailable"`)
+	c.Check(s.failures, Equals, 0)
+	c.Check(s.recoveries, Equals, 0)
+}
+
+func (s *netkeySuite) TestRecoverWithRetryTimeout(c *C) {
+	_, restore := logger.MockLogger()
+	defer restore()
+
+	_, binding := s.provision(c)
+	done := make(chan struct{})
+	hanging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer hanging.Close()
+	defer close(done)
+	binding.URL = hanging.URL
+
+	_, err := netkey.RecoverWithRetry(context.Background(), binding, &netkey.RecoverOptions{
+		Timeout:    10 * time.Millisecond,
+		Attempts:   2,
+		RetryDelay: time.Millisecond,
+	})
+	c.Assert(err, ErrorMatches, fmt.Sprintf(`cannot recover key from %s: .*context deadline exceeded.*`, hanging.URL))
+}
+
+func (s *netkeySuite) TestRecoverWithRetryCancelled(c *C) {
+	_, binding := s.provision(c)
+	s.failures = 1
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := netkey.RecoverWithRetry(ctx, binding, &netkey.RecoverOptions{Attempts: 3})
+	c.Assert(err, ErrorMatches, `cannot recover key from http://.*context canceled`)
+	c.Check(s.failures, Equals, 1)
+}
@@ -0,0 +1,151 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package secboot
+
+// This file must not have a build-constraint and must not import
+// the github.com/snapcore/secboot repository.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/secboot/netkey"
+)
+
+var (
+	netkeyProvision        = netkey.Provision
+	netkeyRecoverWithRetry = netkey.RecoverWithRetry
+)
+
+// how long to wait for a key server when enrolling keys bound to it
+const defaultNetworkKeyEnrollTimeout = 30 * time.Second
+
+// NetworkKeys holds what is needed to recover the keys of an encrypted
+// volume that are bound to key servers, none of it is secret.
+type NetworkKeys struct {
+	Bindings []*netkey.Binding     `json:"bindings"`
+	Options  netkey.RecoverOptions `json:"options"`
+}
+
+// ReadNetworkKeys reads the network keys saved at the given path.
+func ReadNetworkKeys(path string) (*NetworkKeys, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var nk NetworkKeys
+	if err := json.Unmarshal(data, &nk); err != nil {
+		return nil, fmt.Errorf("cannot decode network keys: %v", err)
+	}
+	return &nk, nil
+}
+
+func networkKeySlotName(i int) string {
+	return fmt.Sprintf("network-%d", i)
+}
+
+// EnrollNetworkKeys adds to the container a key bound to each of the given
+// servers, and saves at the given path what is needed to recover them when
+// unlocking the container.
+func EnrollNetworkKeys(container BootstrappedContainer, servers []*netkey.Server, opts *netkey.RecoverOptions, path string) error {
+	timeout := defaultNetworkKeyEnrollTimeout
+	if opts.Timeout > 0 {
+		timeout = opts.Timeout
+	}
+
+	nk := &NetworkKeys{Options: *opts}
+	for i, server := range servers {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		key, binding, err := netkeyProvision(ctx, server)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("cannot bind key to %s: %v", server.URL, err)
+		}
+		if err := container.AddKey(networkKeySlotName(i), key); err != nil {
+			return fmt.Errorf("cannot add key bound to %s: %v", server.URL, err)
+		}
+		nk.Bindings = append(nk.Bindings, binding)
+	}
+
+	data, err := json.Marshal(nk)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return osutil.AtomicWriteFile(path, data, 0600, 0)
+}
+
+// KeyProvider provides keys unlocking encrypted volumes, in addition to
+// the keys saved with the volumes. Unlocking falls back to the recovery
+// key, when allowed, if none of the keys unlocks the volume.
+type KeyProvider interface {
+	// Name identifies the provided keys.
+	Name() string
+	// ProvideKeys returns the keys, which may be none.
+	ProvideKeys(ctx context.Context) ([][]byte, error)
+}
+
+type networkKeyProvider struct {
+	path string
+}
+
+// NewNetworkKeyProvider returns a key provider recovering the keys saved at
+// the given path by EnrollNetworkKeys, with the help of the key servers
+// they are bound to. The network must be set up already, while it is not
+// the servers are asked again as set when enrolling the keys.
+func NewNetworkKeyProvider(path string) KeyProvider {
+	return &networkKeyProvider{path: path}
+}
+
+func (p *networkKeyProvider) Name() string {
+	return "network"
+}
+
+func (p *networkKeyProvider) ProvideKeys(ctx context.Context) ([][]byte, error) {
+	nk, err := ReadNetworkKeys(p.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var keys [][]byte
+	for _, binding := range nk.Bindings {
+		key, err := netkeyRecoverWithRetry(ctx, binding, &nk.Options)
+		if err != nil {
+			logger.Noticef("WARNING: cannot recover key bound to %s: %v", binding.URL, err)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 && len(nk.Bindings) > 0 {
+		return nil, fmt.Errorf("cannot recover any key bound to key servers")
+	}
+	return keys, nil
+}
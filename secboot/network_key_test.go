@@ -0,0 +1,158 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package secboot_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/secboot"
+	"github.com/snapcore/snapd/secboot/netkey"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type networkKeySuite struct {
+	testutil.BaseTest
+
+	path string
+}
+
+var _ = Suite(&networkKeySuite{})
+
+func (s *networkKeySuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
+	s.path = filepath.Join(c.MkDir(), "device/fde/ubuntu-data.network-keys")
+}
+
+func bindingFor(url string) *netkey.Binding {
+	return &netkey.Binding{
+		URL:       url,
+		ServerKey: &netkey.JWK{Kty: "EC", Crv: "P-256", X: "server-x", Y: "server-y", Alg: "ECMR"},
+		ClientKey: &netkey.JWK{Kty: "EC", Crv: "P-256", X: "client-x", Y: "client-y"},
+	}
+}
+
+func (s *networkKeySuite) TestEnrollNetworkKeys(c *C) {
+	s.AddCleanup(secboot.MockNetkeyProvision(func(ctx context.Context, server *netkey.Server) ([]byte, *netkey.Binding, error) {
+		deadline, ok := ctx.Deadline()
+		c.Check(ok, Equals, true)
+		c.Check(time.Until(deadline) <= 5*time.Second, Equals, true)
+		c.Check(server.Thumbprint, Equals, "thumbprint-of-"+server.URL)
+		return []byte("key-of-" + server.URL), bindingFor(server.URL), nil
+	}))
+
+	container := secboot.CreateMockBootstrappedContainer()
+	err := secboot.EnrollNetworkKeys(container, []*netkey.Server{
+		{URL: "http://tang1", Thumbprint: "thumbprint-of-http://tang1"},
+		{URL: "http://tang2", Thumbprint: "thumbprint-of-http://tang2"},
+	}, &netkey.RecoverOptions{Timeout: 5 * time.Second, Attempts: 4}, s.path)
+	c.Assert(err, IsNil)
+	c.Check(container.Slots, DeepEquals, map[string][]byte{
+		"network-0": []byte("key-of-http://tang1"),
+		"network-1": []byte("key-of-http://tang2"),
+	})
+
+	nk, err := secboot.ReadNetworkKeys(s.path)
+	c.Assert(err, IsNil)
+	c.Check(nk, DeepEquals, &secboot.NetworkKeys{
+		Bindings: []*netkey.Binding{bindingFor("http://tang1"), bindingFor("http://tang2")},
+		Options:  netkey.RecoverOptions{Timeout: 5 * time.Second, Attempts: 4},
+	})
+	st, err := os.Stat(s.path)
+	c.Assert(err, IsNil)
+	c.Check(st.Mode().Perm(), Equals, os.FileMode(0600))
+}
+
+func (s *networkKeySuite) TestEnrollNetworkKeysError(c *C) {
+	s.AddCleanup(secboot.MockNetkeyProvision(func(ctx context.Context, server *netkey.Server) ([]byte, *netkey.Binding, error) {
+		return nil, nil, fmt.Errorf("cannot get advertisement of %s: boom", server.URL)
+	}))
+
+	container := secboot.CreateMockBootstrappedContainer()
+	err := secboot.EnrollNetworkKeys(container, []*netkey.Server{{URL: "http://tang1"}}, &netkey.RecoverOptions{}, s.path)
+	c.Assert(err, ErrorMatches, `cannot bind key to http://tang1: cannot get advertisement of http://tang1: boom`)
+	c.Check(container.Slots, HasLen, 0)
+	c.Check(s.path, testutil.FileAbsent)
+}
+
+func (s *networkKeySuite) TestNetworkKeyProvider(c *C) {
+	logbuf, restore := logger.MockLogger()
+	defer restore()
+
+	c.Assert(os.MkdirAll(filepath.Dir(s.path), 0755), IsNil)
+	c.Assert(os.WriteFile(s.path, []byte(`{"bindings":[
+{"url":"http://tang1","server-key":{"kty":"EC","crv":"P-256","x":"server-x","y":"server-y","alg":"ECMR"},"client-key":{"kty":"EC","crv":"P-256","x":"client-x","y":"client-y"}},
+{"url":"http://tang2","server-key":{"kty":"EC","crv":"P-256","x":"server-x","y":"server-y","alg":"ECMR"},"client-key":{"kty":"EC","crv":"P-256","x":"client-x","y":"client-y"}},
+{"url":"http://tang3","server-key":{"kty":"EC","crv":"P-256","x":"server-x","y":"server-y","alg":"ECMR"},"client-key":{"kty":"EC","crv":"P-256","x":"client-x","y":"client-y"}}
+],"options":{"attempts":4}}`), 0600), IsNil)
+
+	var urls []string
+	s.AddCleanup(secboot.MockNetkeyRecoverWithRetry(func(ctx context.Context, binding *netkey.Binding, opts *netkey.RecoverOptions) ([]byte, error) {
+		c.Check(binding, DeepEquals, bindingFor(binding.URL))
+		c.Check(opts, DeepEquals, &netkey.RecoverOptions{Attempts: 4})
+		urls = append(urls, binding.URL)
+		if binding.URL == "http://tang2" {
+			return nil, fmt.Errorf("cannot recover key from %s: boom", binding.URL)
+		}
+		return []byte("key-of-" + binding.URL), nil
+	}))
+
+	provider := secboot.NewNetworkKeyProvider(s.path)
+	c.Check(provider.Name(), Equals, "network")
+	keys, err := provider.ProvideKeys(context.Background())
+	c.Assert(err, IsNil)
+	c.Check(keys, DeepEquals, [][]byte{[]byte("key-of-http://tang1"), []byte("key-of-http://tang3")})
+	c.Check(urls, DeepEquals, []string{"http://tang1", "http://tang2", "http://tang3"})
+	c.Check(logbuf.String(), testutil.Contains, "WARNING: cannot recover key bound to http://tang2: cannot recover key from http://tang2: boom")
+}
+
+func (s *networkKeySuite) TestNetworkKeyProviderNoKeys(c *C) {
+	s.AddCleanup(secboot.MockNetkeyRecoverWithRetry(func(ctx context.Context, binding *netkey.Binding, opts *netkey.RecoverOptions) ([]byte, error) {
+		c.Fatalf("unexpected call")
+		return nil, nil
+	}))
+
+	keys, err := secboot.NewNetworkKeyProvider(s.path).ProvideKeys(context.Background())
+	c.Assert(err, IsNil)
+	c.Check(keys, HasLen, 0)
+}
+
+func (s *networkKeySuite) TestNetworkKeyProviderErrors(c *C) {
+	_, restore := logger.MockLogger()
+	defer restore()
+
+	c.Assert(os.MkdirAll(filepath.Dir(s.path), 0755), IsNil)
+	c.Assert(os.WriteFile(s.path, []byte(`{`), 0600), IsNil)
+	_, err := secboot.NewNetworkKeyProvider(s.path).ProvideKeys(context.Background())
+	c.Assert(err, ErrorMatches, `cannot decode network keys: unexpected end of JSON input`)
+
+	c.Assert(os.WriteFile(s.path, []byte(`{"bindings":[{"url":"http://tang1"}]}`), 0600), IsNil)
+	s.AddCleanup(secboot.MockNetkeyRecoverWithRetry(func(ctx context.Context, binding *netkey.Binding, opts *netkey.RecoverOptions) ([]byte, error) {
+		return nil, fmt.Errorf("boom")
+	}))
+	_, err = secboot.NewNetworkKeyProvider(s.path).ProvideKeys(context.Background())
+	c.Assert(err, ErrorMatches, `cannot recover any key bound to key servers`)
+}
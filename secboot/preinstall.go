@@ -38,3 +38,19 @@ type PreinstallAction struct {
 	Action string                     `json:"action"`
 	Args   map[string]json.RawMessage `json:"args,omitempty"`
 }
+
+// PCRProfilePreview describes the PCR profile that keys sealed with the
+// TPM during install are bound to.
+type PCRProfilePreview struct {
+	// PCRAlg is the name of the PCR bank the profile uses.
+	PCRAlg string `json:"pcr-alg"`
+	// PCRs lists the PCR indexes the profile is bound to, in
+	// ascending order.
+	PCRs []int `json:"pcrs"`
+	// Options lists the PCR profile options selected by the
+	// preinstall check.
+	Options []string `json:"options,omitempty"`
+	// AcceptedErrors lists the preinstall check error kinds that were
+	// accepted when selecting the profile.
+	AcceptedErrors []string `json:"accepted-errors,omitempty"`
+}
@@ -231,6 +231,8 @@ type UnlockVolumeUsingSealedKeyOptions struct {
 	WhichModel func() (*asserts.Model, error)
 	// BootMode is the current boot mode (i.e. snapd_recovery_mode kernel parameter)
 	BootMode string
+	// KeyProviders provide keys to try in addition to the sealed keys.
+	KeyProviders []KeyProvider
 }
 
 // UnlockMethod is the method that was used to unlock a volume.
@@ -114,6 +114,10 @@ func BuildPCRProtectionProfile(modelParams []*SealKeyModelParams, checkResult *P
 	return nil, errBuildWithoutSecboot
 }
 
+func PreviewPCRProfile(checkResult *PreinstallCheckResult) (*PCRProfilePreview, error) {
+	return nil, errBuildWithoutSecboot
+}
+
 func GetPrimaryKeyDigest(devicePath string, alg crypto.Hash) ([]byte, []byte, error) {
 	return nil, nil, errBuildWithoutSecboot
 }
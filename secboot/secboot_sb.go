@@ -351,6 +351,21 @@ func UnlockVolumeUsingSealedKeyIfEncrypted(activation ActivateContext, disk Disk
 		}
 	}
 
+	// keys from providers unlock the container directly
+	providedKeys := make(map[string]bool)
+	for _, provider := range opts.KeyProviders {
+		keys, err := provider.ProvideKeys(context.Background())
+		if err != nil {
+			logger.Noticef("WARNING: cannot get %s keys for device %s: %v", provider.Name(), sourceDevice, err)
+			continue
+		}
+		for i, key := range keys {
+			name := fmt.Sprintf("%s-%d", provider.Name(), i)
+			options = append(options, sbWithExternalUnlockKey(name, key, sb.ExternalUnlockKeyFromStorageContainer))
+			providedKeys["external:"+name] = true
+		}
+	}
+
 	if opts.WhichModel != nil {
 		model, err := opts.WhichModel()
 		if err != nil {
@@ -391,6 +406,10 @@ func UnlockVolumeUsingSealedKeyIfEncrypted(activation ActivateContext, disk Disk
 			logger.Noticef("successfully activated encrypted device %q using a fallback activation method", sourceDevice)
 			res.UnlockMethod = UnlockedWithRecoveryKey
 			res.Keyslot = activationState.Keyslot
+		} else if providedKeys[activationState.Keyslot] {
+			logger.Noticef("successfully activated encrypted device %q with provided key %s", sourceDevice, activationState.Keyslot)
+			res.UnlockMethod = UnlockedWithKey
+			res.Keyslot = activationState.Keyslot
 		} else {
 			logger.Noticef("successfully activated encrypted device %q with TPM", sourceDevice)
 			res.UnlockMethod = UnlockedWithSealedKey
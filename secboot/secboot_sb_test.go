@@ -2725,6 +2725,85 @@ func (s *secbootSuite) TestUnlockVolumeUsingSealedKeyWithOPTEE(c *C) {
 	c.Check(activated, Equals, 1)
 }
 
+type mockKeyProvider struct {
+	name string
+	keys [][]byte
+	err  error
+}
+
+func (p *mockKeyProvider) Name() string {
+	return p.name
+}
+
+func (p *mockKeyProvider) ProvideKeys(ctx context.Context) ([][]byte, error) {
+	return p.keys, p.err
+}
+
+func (s *secbootSuite) TestUnlockVolumeUsingSealedKeyIfEncryptedKeyProviders(c *C) {
+	logbuf, restore := logger.MockLogger()
+	defer restore()
+
+	restore = secboot.MockRandomKernelUUID(func() (string, error) {
+		return "random-uuid-for-test", nil
+	})
+	defer restore()
+
+	mockDiskWithEncDev := &MockDiskMapping{
+		Structure: []MockPartition{
+			{
+				node:            "/dev/sda3",
+				filesystemLabel: "device-name-enc",
+				filesystemUUID:  "enc-dev-uuid",
+				partitionUUID:   "enc-dev-partuuid",
+			},
+		},
+	}
+
+	var providedKeys []string
+	defer secboot.MockSbWithExternalUnlockKey(func(name string, key sb.DiskUnlockKey, src sb.ExternalUnlockKeySource) sb.ActivateOption {
+		c.Check(src, Equals, sb.ExternalUnlockKeyFromStorageContainer)
+		providedKeys = append(providedKeys, fmt.Sprintf("%s:%s", name, key))
+		return &mockActivateOption{name: name}
+	})()
+
+	storage := &mockStorageContainer{name: "storage"}
+	var activateContext *mockActivateContext
+	activateContext = newMockActivateContext(
+		func(ctx context.Context, container sb.StorageContainer, opts ...sb.ActivateOption) error {
+			c.Check(container, Equals, storage)
+			c.Check(opts, testutil.DeepContains, &mockActivateOption{name: "network-1"})
+			activateContext.state.Activations[container.CredentialName()] = &sb.ContainerActivateState{
+				Status:  sb.ActivationSucceededWithPlatformKey,
+				Keyslot: "external:network-1",
+			}
+			return nil
+		},
+	)
+	defer secboot.MockSbFindStorageContainer(func(ctx context.Context, path string) (sb.StorageContainer, error) {
+		return storage, nil
+	})()
+
+	opts := &secboot.UnlockVolumeUsingSealedKeyOptions{
+		AllowRecoveryKey: true,
+		KeyProviders: []secboot.KeyProvider{
+			&mockKeyProvider{name: "network", keys: [][]byte{[]byte("key-0"), []byte("key-1")}},
+			&mockKeyProvider{name: "other", err: fmt.Errorf("boom")},
+		},
+	}
+	res, err := secboot.UnlockVolumeUsingSealedKeyIfEncrypted(activateContext, mockDiskWithEncDev, "device-name", nil, opts)
+	c.Assert(err, IsNil)
+	c.Check(res, DeepEquals, secboot.UnlockResult{
+		UnlockMethod: secboot.UnlockedWithKey,
+		IsEncrypted:  true,
+		PartDevice:   "/dev/disk/by-partuuid/enc-dev-partuuid",
+		FsDevice:     "/dev/mapper/device-name-random-uuid-for-test",
+		Keyslot:      "external:network-1",
+	})
+	c.Check(providedKeys, DeepEquals, []string{"network-0:key-0", "network-1:key-1"})
+	c.Check(logbuf.String(), testutil.Contains, `WARNING: cannot get other keys for device /dev/disk/by-uuid/enc-dev-uuid: boom`)
+	c.Check(logbuf.String(), testutil.Contains, `successfully activated encrypted device "/dev/disk/by-uuid/enc-dev-uuid" with provided key external:network-1`)
+}
+
 func (s *secbootSuite) TestUnlockVolumeUsingSealedKeyIfEncryptedFdeRevealKeyV2(c *C) {
 	var reqs []*fde.RevealKeyRequest
 	restore := fde.MockRunFDERevealKey(func(req *fde.RevealKeyRequest) ([]byte, error) {
@@ -6177,3 +6256,68 @@ func (s *secbootSuite) TestTestProtectorKey(c *C) {
 	c.Assert(err, IsNil)
 	c.Check(works, Equals, false)
 }
+
+func (s *secbootSuite) TestPreviewPCRProfileLegacy(c *C) {
+	preview, err := secboot.PreviewPCRProfile(nil)
+	c.Assert(err, IsNil)
+	c.Check(preview, DeepEquals, &secboot.PCRProfilePreview{
+		PCRAlg: "sha256",
+		PCRs:   []int{4, 7, 12},
+	})
+}
+
+func (s *secbootSuite) TestPreviewPCRProfileWithCheckResult(c *C) {
+	checkResult := secboot.NewPreinstallCheckResult(
+		&sb_preinstall.CheckResult{
+			PCRAlg: tpm2.HashAlgorithmSHA384,
+			Flags:  sb_preinstall.NoPlatformConfigProfileSupport,
+			AcceptedErrors: map[sb_preinstall.ErrorKind]json.RawMessage{
+				sb_preinstall.ErrorKindTPMHierarchiesOwned: nil,
+			},
+		},
+		sb_preinstall.PCRProfileOptionTrustSecureBootAuthoritiesForBootCode|sb_preinstall.PCRProfileOptionLockToDriversAndApps,
+	)
+
+	preview, err := secboot.PreviewPCRProfile(checkResult)
+	c.Assert(err, IsNil)
+	c.Check(preview, DeepEquals, &secboot.PCRProfilePreview{
+		PCRAlg:         "sha384",
+		PCRs:           []int{2, 7, 12},
+		Options:        []string{"lock-drivers-and-apps", "trust-authorities-for-boot-code"},
+		AcceptedErrors: []string{string(sb_preinstall.ErrorKindTPMHierarchiesOwned)},
+	})
+}
+
+func (s *secbootSuite) TestPreviewPCRProfileDefaultOptions(c *C) {
+	checkResult := secboot.NewPreinstallCheckResult(
+		&sb_preinstall.CheckResult{
+			PCRAlg: tpm2.HashAlgorithmSHA256,
+			Flags:  sb_preinstall.NoPlatformConfigProfileSupport,
+		},
+		sb_preinstall.PCRProfileOptionsDefault,
+	)
+
+	preview, err := secboot.PreviewPCRProfile(checkResult)
+	c.Assert(err, IsNil)
+	c.Check(preview, DeepEquals, &secboot.PCRProfilePreview{
+		PCRAlg: "sha256",
+		PCRs:   []int{7, 12},
+	})
+}
+
+func (s *secbootSuite) TestPreviewPCRProfileUnsupportedPCRs(c *C) {
+	checkResult := secboot.NewPreinstallCheckResult(
+		&sb_preinstall.CheckResult{
+			PCRAlg: tpm2.HashAlgorithmSHA256,
+			Flags: sb_preinstall.NoSecureBootPolicyProfileSupport |
+				sb_preinstall.NoBootManagerCodeProfileSupport |
+				sb_preinstall.NoPlatformFirmwareProfileSupport |
+				sb_preinstall.NoDriversAndAppsProfileSupport,
+		},
+		sb_preinstall.PCRProfileOptionsDefault,
+	)
+
+	preview, err := secboot.PreviewPCRProfile(checkResult)
+	c.Assert(err, ErrorMatches, "cannot compute PCRs from preinstall check result: cannot select an appropriate set of TCG defined PCRs with the current options: PCR 0x00000007 is required, but is unsupported")
+	c.Check(preview, IsNil)
+}
@@ -29,6 +29,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	efilib "github.com/canonical/go-efilib"
@@ -1028,6 +1029,70 @@ func BuildPCRProtectionProfile(modelParams []*SealKeyModelParams, checkResult *P
 	return mu.MarshalToBytes(pcrProfile)
 }
 
+// legacyProfilePCRs are the PCRs bound by the legacy PCR profile: the boot
+// manager code, the secure boot policy and the initramfs PCRs.
+var legacyProfilePCRs = []int{4, 7, initramfsPCR}
+
+func pcrAlgName(alg tpm2.HashAlgorithmId) string {
+	switch alg {
+	case tpm2.HashAlgorithmSHA1:
+		return "sha1"
+	case tpm2.HashAlgorithmSHA256:
+		return "sha256"
+	case tpm2.HashAlgorithmSHA384:
+		return "sha384"
+	case tpm2.HashAlgorithmSHA512:
+		return "sha512"
+	default:
+		return fmt.Sprintf("%#x", uint16(alg))
+	}
+}
+
+// PreviewPCRProfile describes the PCR profile that
+// BuildPCRProtectionProfile would build for the given preinstall check
+// result, without measuring any boot assets. If checkResult is nil, the
+// legacy profile is described.
+func PreviewPCRProfile(checkResult *PreinstallCheckResult) (*PCRProfilePreview, error) {
+	if checkResult == nil || checkResult.sbCheckResult == nil {
+		return &PCRProfilePreview{
+			PCRAlg: pcrAlgName(tpm2.HashAlgorithmSHA256),
+			PCRs:   append([]int(nil), legacyProfilePCRs...),
+		}, nil
+	}
+
+	autoPCRs, err := sbWithAutoTCGPCRProfile(checkResult.sbCheckResult, checkResult.sbPCRProfileOpts).PCRs()
+	if err != nil {
+		return nil, fmt.Errorf("cannot compute PCRs from preinstall check result: %v", err)
+	}
+	kernelConfigPCRs, err := sbWithKernelConfigProfile().PCRs()
+	if err != nil {
+		return nil, fmt.Errorf("cannot compute kernel configuration PCRs: %v", err)
+	}
+
+	seen := make(map[int]bool)
+	var pcrs []int
+	for _, h := range append(autoPCRs, kernelConfigPCRs...) {
+		if seen[int(h)] {
+			continue
+		}
+		seen[int(h)] = true
+		pcrs = append(pcrs, int(h))
+	}
+	sort.Ints(pcrs)
+
+	var options []string
+	if opts := checkResult.sbPCRProfileOpts.String(); opts != "" {
+		options = strings.Split(opts, ",")
+	}
+
+	return &PCRProfilePreview{
+		PCRAlg:         pcrAlgName(checkResult.sbCheckResult.PCRAlg),
+		PCRs:           pcrs,
+		Options:        options,
+		AcceptedErrors: checkResult.AcceptedErrors(),
+	}, nil
+}
+
 func readLockoutAuth(lockoutAuthFile string) (data []byte, isValue bool, err error) {
 	logger.Debugf("using existing lockout authorization")
 	data, err = os.ReadFile(lockoutAuthFile)
@@ -21,6 +21,7 @@ package seed
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 
@@ -58,42 +59,54 @@ func newMemAssertionsDB(commitObserve func(verified asserts.Assertion)) (db *ass
 	return memDB, commitTo, nil
 }
 
-func loadAssertions(assertsDir string, loadedFunc func(*asserts.Ref) error) (*asserts.Batch, error) {
+// assertsAdder is implemented by asserts.Batch and asserts.StreamCommitter.
+type assertsAdder interface {
+	AddStream(r io.Reader) ([]*asserts.Ref, error)
+}
+
+func loadAssertions(assertsDir string, adder assertsAdder, loadedFunc func(*asserts.Ref) error) error {
 	logger.Debugf("loading assertions from %s", assertsDir)
 	dc, err := os.ReadDir(assertsDir)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil, ErrNoAssertions
+			return ErrNoAssertions
 		}
-		return nil, fmt.Errorf("cannot read assertions dir: %s", err)
+		return fmt.Errorf("cannot read assertions dir: %s", err)
 	}
 
-	batch := asserts.NewBatch(nil)
+	// the same assertion can be in more than one file and a stream
+	// committer does not remember the ones of past batches
+	loaded := make(map[string]bool)
 	for _, fi := range dc {
 		fn := filepath.Join(assertsDir, fi.Name())
-		refs, err := readAsserts(batch, fn)
+		refs, err := readAsserts(adder, fn)
 		if err != nil {
-			return nil, fmt.Errorf("cannot read assertions: %s", err)
+			return fmt.Errorf("cannot read assertions: %s", err)
 		}
-		if loadedFunc != nil {
-			for _, ref := range refs {
-				if err := loadedFunc(ref); err != nil {
-					return nil, err
-				}
+		if loadedFunc == nil {
+			continue
+		}
+		for _, ref := range refs {
+			if loaded[ref.Unique()] {
+				continue
+			}
+			loaded[ref.Unique()] = true
+			if err := loadedFunc(ref); err != nil {
+				return err
 			}
 		}
 	}
 
-	return batch, nil
+	return nil
 }
 
-func readAsserts(batch *asserts.Batch, fn string) ([]*asserts.Ref, error) {
+func readAsserts(adder assertsAdder, fn string) ([]*asserts.Ref, error) {
 	f, err := os.Open(fn)
 	if err != nil {
 		return nil, err
 	}
 	defer f.Close()
-	return batch.AddStream(f)
+	return adder.AddStream(f)
 }
 
 func readInfo(snapPath string, si *snap.SideInfo) (*snap.Info, error) {
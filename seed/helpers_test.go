@@ -82,9 +82,8 @@ version: 2.0
 func (s *helpersSuite) TestLoadAssertionsNoAssertions(c *C) {
 	os.Remove(s.assertsDir)
 
-	b, err := seed.LoadAssertions(s.assertsDir, nil)
+	err := seed.LoadAssertions(s.assertsDir, asserts.NewBatch(nil), nil)
 	c.Check(err, Equals, seed.ErrNoAssertions)
-	c.Check(b, IsNil)
 }
 
 func (s *helpersSuite) TestLoadAssertions(c *C) {
@@ -95,16 +94,20 @@ func (s *helpersSuite) TestLoadAssertions(c *C) {
 	s.writeAssertions("foo.asserts", s.devAcct, fooDecl, fooRev)
 	s.writeAssertions("bar.asserts", barDecl, barRev)
 
-	b, err := seed.LoadAssertions(s.assertsDir, nil)
-	c.Assert(err, IsNil)
-
 	db, err := asserts.OpenDatabase(&asserts.DatabaseConfig{
 		Backstore: asserts.NewMemoryBackstore(),
 		Trusted:   s.StoreSigning.Trusted,
 	})
 	c.Assert(err, IsNil)
 
-	err = b.CommitTo(db, nil)
+	commitTo := func(b *asserts.Batch) error {
+		return b.CommitTo(db, nil)
+	}
+	sc := asserts.NewStreamCommitter(db, commitTo, &asserts.StreamOptions{BatchSize: 2})
+
+	err = seed.LoadAssertions(s.assertsDir, sc, nil)
+	c.Assert(err, IsNil)
+	err = sc.Commit()
 	c.Assert(err, IsNil)
 
 	_, err = db.Find(asserts.SnapRevisionType, map[string]string{
@@ -137,7 +140,7 @@ func (s *helpersSuite) TestLoadAssertionsLoadedCallback(c *C) {
 		return nil
 	}
 
-	_, err := seed.LoadAssertions(s.assertsDir, loaded)
+	err := seed.LoadAssertions(s.assertsDir, asserts.NewBatch(nil), loaded)
 	c.Assert(err, IsNil)
 
 	c.Check(seen, DeepEquals, map[string]bool{
@@ -162,6 +165,43 @@ func (s *helpersSuite) TestLoadAssertionsLoadedCallbackError(c *C) {
 
 	}
 
-	_, err := seed.LoadAssertions(s.assertsDir, loaded)
+	err := seed.LoadAssertions(s.assertsDir, asserts.NewBatch(nil), loaded)
 	c.Assert(err, ErrorMatches, "boom")
 }
+
+func (s *helpersSuite) TestLoadAssertionsLoadedCallbackDuplicates(c *C) {
+	fooDecl, fooRev := s.MakeAssertedSnap(c, fooSnap, nil, snap.R(1), "developerid")
+
+	s.writeAssertions("ground.asserts", s.StoreSigning.StoreAccountKey(""), s.devAcct)
+	s.writeAssertions("foo.asserts", s.devAcct, fooDecl, fooRev)
+
+	db, err := asserts.OpenDatabase(&asserts.DatabaseConfig{
+		Backstore: asserts.NewMemoryBackstore(),
+		Trusted:   s.StoreSigning.Trusted,
+	})
+	c.Assert(err, IsNil)
+
+	commitTo := func(b *asserts.Batch) error {
+		return b.CommitTo(db, nil)
+	}
+	// the duplicates end up in different batches
+	sc := asserts.NewStreamCommitter(db, commitTo, &asserts.StreamOptions{BatchSize: 1})
+
+	counts := make(map[string]int)
+	loaded := func(ref *asserts.Ref) error {
+		counts[ref.Type.Name]++
+		return nil
+	}
+
+	err = seed.LoadAssertions(s.assertsDir, sc, loaded)
+	c.Assert(err, IsNil)
+	err = sc.Commit()
+	c.Assert(err, IsNil)
+
+	c.Check(counts, DeepEquals, map[string]int{
+		"account":          1,
+		"account-key":      1,
+		"snap-declaration": 1,
+		"snap-revision":    1,
+	})
+}
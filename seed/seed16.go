@@ -84,8 +84,10 @@ func (s *seed16) LoadAssertions(db asserts.RODatabase, commitTo func(*asserts.Ba
 		return nil
 	}
 
-	batch, err := loadAssertions(assertSeedDir, checkForModel)
-	if err != nil {
+	// seeds can carry a large number of assertions, commit them in
+	// batches as they are read
+	sc := asserts.NewStreamCommitter(db, commitTo, nil)
+	if err := loadAssertions(assertSeedDir, sc, checkForModel); err != nil {
 		return err
 	}
 
@@ -94,7 +96,7 @@ func (s *seed16) LoadAssertions(db asserts.RODatabase, commitTo func(*asserts.Ba
 		return fmt.Errorf("seed must have a model assertion")
 	}
 
-	if err := commitTo(batch); err != nil {
+	if err := sc.Commit(); err != nil {
 		return err
 	}
 
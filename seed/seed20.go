@@ -595,12 +595,14 @@ func (s *seed20) LoadAssertions(db asserts.RODatabase, commitTo func(*asserts.Ba
 		return nil
 	}
 
-	batch, err := loadAssertions(assertsDir, checkAssertion)
-	if err != nil {
+	// systems can carry a large number of assertions, commit them in
+	// batches as they are read
+	sc := asserts.NewStreamCommitter(db, commitTo, nil)
+	if err := loadAssertions(assertsDir, sc, checkAssertion); err != nil {
 		return err
 	}
 
-	refs, err := readAsserts(batch, filepath.Join(s.systemDir, "model"))
+	refs, err := readAsserts(sc, filepath.Join(s.systemDir, "model"))
 	if err != nil {
 		return fmt.Errorf("cannot read model assertion: %v", err)
 	}
@@ -617,7 +619,7 @@ func (s *seed20) LoadAssertions(db asserts.RODatabase, commitTo func(*asserts.Ba
 	}
 
 	// this also verifies the consistency of all of them
-	if err := commitTo(batch); err != nil {
+	if err := sc.Commit(); err != nil {
 		return err
 	}
 
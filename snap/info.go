@@ -435,6 +435,13 @@ type Info struct {
 	// The ordered list of tracks that contain channels
 	Tracks []string
 
+	// The metadata the store publishes about the tracks, by track name
+	TrackInfos map[string]*TrackInfo
+
+	// The complete channel map, including all architectures and
+	// branches, if it was requested from the store
+	ChannelMap []*ChannelSnapInfo
+
 	Layout map[string]*Layout
 
 	// The list of common-ids from all apps of the snap
@@ -475,6 +482,9 @@ type Layout struct {
 	Group    string      `json:"group,omitempty"`
 	Mode     os.FileMode `json:"mode,omitempty"`
 	Symlink  string      `json:"symlink,omitempty"`
+	// Mimic selects how writable mimics needed by the layout are
+	// constructed, either with a tmpfs, the default, or with an overlay.
+	Mimic string `json:"mimic,omitempty"`
 }
 
 // String returns a simple textual representation of a layout.
@@ -508,13 +518,29 @@ func (l *Layout) String() string {
 // ChannelSnapInfo is the minimum information that can be used to clearly
 // distinguish different revisions of the same snap.
 type ChannelSnapInfo struct {
-	Revision    Revision        `json:"revision"`
-	Confinement ConfinementType `json:"confinement"`
-	Version     string          `json:"version"`
-	Channel     string          `json:"channel"`
-	Epoch       Epoch           `json:"epoch"`
-	Size        int64           `json:"size"`
-	ReleasedAt  time.Time       `json:"released-at"`
+	Revision     Revision        `json:"revision"`
+	Confinement  ConfinementType `json:"confinement"`
+	Version      string          `json:"version"`
+	Channel      string          `json:"channel"`
+	Epoch        Epoch           `json:"epoch"`
+	Size         int64           `json:"size"`
+	ReleasedAt   time.Time       `json:"released-at"`
+	Architecture string          `json:"architecture,omitempty"`
+}
+
+// TrackInfo holds the metadata the store publishes about a track.
+type TrackInfo struct {
+	// EndOfLife is when the track stops being supported, if set.
+	EndOfLife time.Time `json:"end-of-life,omitempty"`
+	// Successor is the track that the snaps tracking this one should
+	// move to at the end of its life, if any.
+	Successor string `json:"successor,omitempty"`
+}
+
+// EndOfLifeReached returns whether the track reached its end of life at
+// the given time.
+func (ti *TrackInfo) EndOfLifeReached(now time.Time) bool {
+	return !ti.EndOfLife.IsZero() && !now.Before(ti.EndOfLife)
 }
 
 // Provenance returns the provenance of the snap, this is a label set
@@ -1271,6 +1297,39 @@ func DefaultContentProviders(plugs []*PlugInfo) (providerSnapsToContentTag map[s
 	return providerSnapsToContentTag
 }
 
+// DefaultContentProviderTracks returns the tracks of the default provider
+// snaps preferred by the given plugs, as hinted by their
+// "default-provider-track" attribute. Providers without a hint are omitted. An
+// error is returned if the plugs prefer different tracks of the same provider.
+func DefaultContentProviderTracks(plugs []*PlugInfo) (providerSnapsToTrack map[string]string, err error) {
+	// sort the plugs for stable error messages
+	plugs = append([]*PlugInfo(nil), plugs...)
+	sort.Slice(plugs, func(i, j int) bool { return plugs[i].Name < plugs[j].Name })
+
+	providerSnapsToTrack = make(map[string]string)
+	hintingPlugs := make(map[string]string)
+	for _, plug := range plugs {
+		if plug.Interface != "content" {
+			continue
+		}
+		var dprovider, track string
+		if err := plug.Attr("default-provider", &dprovider); err != nil || dprovider == "" {
+			continue
+		}
+		if err := plug.Attr("default-provider-track", &track); err != nil || track == "" {
+			continue
+		}
+		name := strings.Split(dprovider, ":")[0]
+		if other, ok := providerSnapsToTrack[name]; ok && other != track {
+			return nil, fmt.Errorf("plugs %q and %q of snap %q prefer different tracks of default provider %q: %q and %q",
+				hintingPlugs[name], plug.Name, plug.Snap.InstanceName(), name, other, track)
+		}
+		providerSnapsToTrack[name] = track
+		hintingPlugs[name] = plug.Name
+	}
+	return providerSnapsToTrack, nil
+}
+
 // SlotInfo provides information about a slot.
 type SlotInfo struct {
 	Snap *Info
@@ -1309,6 +1368,70 @@ type TimerInfo struct {
 	Timer string
 }
 
+// ServiceHardening describes the additional sandboxing a service requests
+// via the "hardening:" field of snap.yaml. The directives are applied on top
+// of the snap confinement when they are compatible with the interfaces
+// plugged by the service.
+type ServiceHardening struct {
+	// ProtectSystem follows the semantics of systemd's ProtectSystem=
+	// directive, either "true" or "full". It is applied by snap-confine.
+	ProtectSystem string `yaml:"protect-system,omitempty"`
+	// PrivateTmp requests a /tmp private to the service, in addition to the
+	// one shared by all the apps of the snap. It is applied by snap-confine.
+	PrivateTmp bool `yaml:"private-tmp,omitempty"`
+	// CapabilityBoundingSet is the list of capabilities, in addition to
+	// those snap-confine needs, that the service may retain.
+	CapabilityBoundingSet []string `yaml:"capability-bounding-set,omitempty"`
+}
+
+// protectSystemIncompatibleInterfaces are interfaces which grant write
+// access to parts of the host that protect-system makes read-only.
+var protectSystemIncompatibleInterfaces = []string{
+	"hostname-control",
+	"kernel-firmware-control",
+	"kernel-module-control",
+	"locale-control",
+	"mount-control",
+	"network-setup-control",
+	"system-files",
+	"timezone-control",
+}
+
+// capabilityBoundingSetIncompatibleInterfaces are interfaces which grant
+// capabilities that cannot be known upfront, typically to run other
+// workloads, and which thus cannot be combined with a bounding set.
+var capabilityBoundingSetIncompatibleInterfaces = []string{
+	"docker-support",
+	"greengrass-support",
+	"kubernetes-support",
+	"lxd-support",
+	"microstack-support",
+	"multipass-support",
+	"nomad-support",
+}
+
+func (app *AppInfo) plugOfInterfaces(interfaces []string) string {
+	for _, plug := range app.Plugs {
+		if strutil.ListContains(interfaces, plug.Interface) {
+			return plug.Name
+		}
+	}
+	return ""
+}
+
+// ProtectSystemConflict returns the name of a plug of the app which is
+// incompatible with the protect-system hardening, or "" if there is none.
+func (app *AppInfo) ProtectSystemConflict() string {
+	return app.plugOfInterfaces(protectSystemIncompatibleInterfaces)
+}
+
+// CapabilityBoundingSetConflict returns the name of a plug of the app which
+// is incompatible with the capability-bounding-set hardening, or "" if
+// there is none.
+func (app *AppInfo) CapabilityBoundingSetConflict() string {
+	return app.plugOfInterfaces(capabilityBoundingSetIncompatibleInterfaces)
+}
+
 // StopModeType is the type for the "stop-mode:" of a snap app
 type StopModeType string
 
@@ -1375,6 +1498,10 @@ type AppInfo struct {
 	StopMode          StopModeType
 	InstallMode       string
 
+	// Hardening holds the additional systemd sandboxing directives
+	// requested for a service.
+	Hardening *ServiceHardening
+
 	// TODO: this should go away once we have more plumbing and can change
 	// things vs refactor
 	// https://github.com/snapcore/snapd/pull/794#discussion_r58688496
@@ -2194,6 +2321,9 @@ type RefreshFailuresInfo struct {
 	// LastFailureSeverity identifies how severe the last failure was.
 	// This allows for more aggressive backoff delay for snaps that fail after a reboot.
 	LastFailureSeverity RefreshFailureSeverity `json:"last-failure-severity,omitempty"`
+	// LastError is the error reported by the task that caused the last
+	// failed refresh attempt, if known.
+	LastError string `json:"last-error,omitempty"`
 }
 
 // IntegrityDataInfo contains all the integrity metadata associated with a snap.
@@ -90,6 +90,8 @@ type appYaml struct {
 	StopMode        StopModeType    `yaml:"stop-mode,omitempty"`
 	InstallMode     string          `yaml:"install-mode,omitempty"`
 
+	Hardening *ServiceHardening `yaml:"hardening,omitempty"`
+
 	RestartCond       RestartCondition `yaml:"restart-condition,omitempty"`
 	RestartDelay      timeout.Timeout  `yaml:"restart-delay,omitempty"`
 	SuccessExitStatus []string         `yaml:"success-exit-status,omitempty"`
@@ -134,6 +136,7 @@ type layoutYaml struct {
 	Group    string `yaml:"group,omitempty"`
 	Mode     string `yaml:"mode,omitempty"`
 	Symlink  string `yaml:"symlink,omitempty"`
+	Mimic    string `yaml:"mimic,omitempty"`
 }
 
 type socketsYaml struct {
@@ -232,7 +235,7 @@ func infoFromSnapYaml(yamlData []byte, strk *scopedTracker) (*Info, error) {
 			snap.Layout[path] = &Layout{
 				Snap: snap, Path: path,
 				Bind: l.Bind, Type: l.Type, Symlink: l.Symlink, BindFile: l.BindFile,
-				User: user, Group: group, Mode: mode,
+				User: user, Group: group, Mode: mode, Mimic: l.Mimic,
 			}
 		}
 	}
@@ -455,6 +458,7 @@ func setAppsFromSnapYaml(y snapYaml, snap *Info, strk *scopedTracker) error {
 			StopMode:          yApp.StopMode,
 			RefreshMode:       yApp.RefreshMode,
 			InstallMode:       yApp.InstallMode,
+			Hardening:         yApp.Hardening,
 			Before:            yApp.Before,
 			After:             yApp.After,
 			Autostart:         yApp.Autostart,
@@ -1844,6 +1844,31 @@ apps:
 	c.Check(info.Apps["foo"].WatchdogTimeout, Equals, timeout.Timeout(12*time.Second))
 }
 
+func (s *YamlSuite) TestSnapYamlHardening(c *C) {
+	y := []byte(`
+name: foo
+version: 1.0
+apps:
+  foo:
+    daemon: simple
+    hardening:
+      protect-system: full
+      private-tmp: true
+      capability-bounding-set: [CAP_NET_BIND_SERVICE]
+  bar:
+    daemon: simple
+`)
+	info, err := snap.InfoFromSnapYaml(y)
+	c.Assert(err, IsNil)
+
+	c.Check(info.Apps["foo"].Hardening, DeepEquals, &snap.ServiceHardening{
+		ProtectSystem:         "full",
+		PrivateTmp:            true,
+		CapabilityBoundingSet: []string{"CAP_NET_BIND_SERVICE"},
+	})
+	c.Check(info.Apps["bar"].Hardening, IsNil)
+}
+
 func (s *YamlSuite) TestLayout(c *C) {
 	y := []byte(`
 name: foo
@@ -1851,6 +1876,7 @@ version: 1.0
 layout:
   /usr/share/foo:
     bind: $SNAP/usr/share/foo
+    mimic: overlay
   /usr/share/bar:
     symlink: $SNAP/usr/share/bar
   /etc/froz:
@@ -1865,6 +1891,7 @@ layout:
 		User:  "root",
 		Group: "root",
 		Mode:  0755,
+		Mimic: "overlay",
 	})
 	c.Assert(info.Layout["/usr/share/bar"], DeepEquals, &snap.Layout{
 		Snap:    info,
@@ -28,6 +28,7 @@ import (
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	. "gopkg.in/check.v1"
 	"gopkg.in/yaml.v2"
@@ -1825,6 +1826,41 @@ func (s *infoSuite) TestDefaultContentProviders(c *C) {
 	c.Check(dps, DeepEquals, map[string][]string{"gtk-common-themes": {"gtk-3-themes", "icon-themes"}})
 }
 
+func (s *infoSuite) TestDefaultContentProviderTracks(c *C) {
+	info, err := snap.InfoFromSnapYaml([]byte(`name: foo
+plugs:
+  themes:
+    interface: content
+    content: gtk-3-themes
+    default-provider: gtk-common-themes
+    default-provider-track: "2"
+  icons:
+    interface: content
+    content: icon-themes
+    default-provider: gtk-common-themes:icon-themes
+    default-provider-track: "2"
+  runtime:
+    interface: content
+    content: runtime
+    default-provider: some-runtime
+  network:
+`))
+	c.Assert(err, IsNil)
+
+	plugs := make([]*snap.PlugInfo, 0, len(info.Plugs))
+	for _, plug := range info.Plugs {
+		plugs = append(plugs, plug)
+	}
+
+	tracks, err := snap.DefaultContentProviderTracks(plugs)
+	c.Assert(err, IsNil)
+	c.Check(tracks, DeepEquals, map[string]string{"gtk-common-themes": "2"})
+
+	info.Plugs["icons"].Attrs["default-provider-track"] = "3"
+	_, err = snap.DefaultContentProviderTracks(plugs)
+	c.Check(err, ErrorMatches, `plugs "icons" and "themes" of snap "foo" prefer different tracks of default provider "gtk-common-themes": "3" and "2"`)
+}
+
 func (s *infoSuite) TestExpandSnapVariables(c *C) {
 	dirs.SetRootDir("")
 	info, err := snap.InfoFromSnapYaml([]byte(`name: foo`))
@@ -2958,3 +2994,41 @@ apps:
 	c.Check(dmverity_file, Equals, "")
 	c.Check(err, ErrorMatches, fmt.Sprintf("internal error: dm-verity data not found for file %q", info.MountFile()))
 }
+
+func (s *infoSuite) TestTrackInfoEndOfLifeReached(c *C) {
+	eol := time.Date(2026, 4, 30, 0, 0, 0, 0, time.UTC)
+	ti := &snap.TrackInfo{EndOfLife: eol}
+	c.Check(ti.EndOfLifeReached(eol.Add(-time.Second)), Equals, false)
+	c.Check(ti.EndOfLifeReached(eol), Equals, true)
+	c.Check(ti.EndOfLifeReached(eol.Add(time.Hour)), Equals, true)
+
+	// no end of life
+	ti = &snap.TrackInfo{Successor: "2.0"}
+	c.Check(ti.EndOfLifeReached(eol), Equals, false)
+}
+
+func (s *infoSuite) TestAppInfoHardeningConflicts(c *C) {
+	info, err := snap.InfoFromSnapYaml([]byte(`name: foo
+version: 1.0
+plugs:
+  etc-files:
+    interface: system-files
+    write: [/etc/foo]
+apps:
+  conflicting:
+    daemon: simple
+    plugs: [etc-files, docker-support]
+  compatible:
+    daemon: simple
+    plugs: [network, network-bind]
+`))
+	c.Assert(err, IsNil)
+
+	app := info.Apps["conflicting"]
+	c.Check(app.ProtectSystemConflict(), Equals, "etc-files")
+	c.Check(app.CapabilityBoundingSetConflict(), Equals, "docker-support")
+
+	app = info.Apps["compatible"]
+	c.Check(app.ProtectSystemConflict(), Equals, "")
+	c.Check(app.CapabilityBoundingSetConflict(), Equals, "")
+}
@@ -198,6 +198,27 @@ func Format(dataDevice string, hashDevice string, opts *DmVerityParams) (rootHas
 	return rootHash, nil
 }
 
+// Verify runs "veritysetup verify" to check the data in dataDevice against
+// the hash tree in hashDevice and the given root hash.
+func Verify(dataDevice, hashDevice, rootHash string) error {
+	output, stderr, err := osutil.RunSplitOutput("veritysetup", "verify", dataDevice, hashDevice, rootHash)
+	if err != nil {
+		return osutil.OutputErrCombine(output, stderr, err)
+	}
+	return nil
+}
+
+// Open runs "veritysetup open" to create the read-only /dev/mapper/<name>
+// device which data in dataDevice is verified, as it is read, against the
+// hash tree in hashDevice and the given root hash.
+func Open(dataDevice, name, hashDevice, rootHash string) error {
+	output, stderr, err := osutil.RunSplitOutput("veritysetup", "open", dataDevice, name, hashDevice, rootHash)
+	if err != nil {
+		return osutil.OutputErrCombine(output, stderr, err)
+	}
+	return nil
+}
+
 // VeritySuperblock represents the dm-verity superblock structure.
 //
 // It mirrors cryptsetup's verity_sb structure from
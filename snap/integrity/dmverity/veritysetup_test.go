@@ -187,6 +187,50 @@ esac
 	c.Check(err, ErrorMatches, "Cannot create hash image  for writing.")
 }
 
+func (s *VerityTestSuite) TestVerifySuccess(c *C) {
+	vscmd := testutil.MockCommand(c, "veritysetup", "")
+	defer vscmd.Restore()
+
+	err := dmverity.Verify("/dev/vda4", "/dev/vda5", "cf9a379613c0dc10301fe3eba4665c38b849b7aad311471faa4d2392ee4ede49")
+	c.Assert(err, IsNil)
+	c.Check(vscmd.Calls(), DeepEquals, [][]string{
+		{"veritysetup", "verify", "/dev/vda4", "/dev/vda5", "cf9a379613c0dc10301fe3eba4665c38b849b7aad311471faa4d2392ee4ede49"},
+	})
+}
+
+func (s *VerityTestSuite) TestVerifyFails(c *C) {
+	vscmd := testutil.MockCommand(c, "veritysetup", `
+echo "Verification of root hash failed." >&2
+exit 1
+`)
+	defer vscmd.Restore()
+
+	err := dmverity.Verify("/dev/vda4", "/dev/vda5", "cf9a379613c0dc10301fe3eba4665c38b849b7aad311471faa4d2392ee4ede49")
+	c.Check(err, ErrorMatches, `(?s).*stderr:\nVerification of root hash failed\.\n.*`)
+}
+
+func (s *VerityTestSuite) TestOpenSuccess(c *C) {
+	vscmd := testutil.MockCommand(c, "veritysetup", "")
+	defer vscmd.Restore()
+
+	err := dmverity.Open("/dev/vda4", "payload", "/dev/vda5", "cf9a379613c0dc10301fe3eba4665c38b849b7aad311471faa4d2392ee4ede49")
+	c.Assert(err, IsNil)
+	c.Check(vscmd.Calls(), DeepEquals, [][]string{
+		{"veritysetup", "open", "/dev/vda4", "payload", "/dev/vda5", "cf9a379613c0dc10301fe3eba4665c38b849b7aad311471faa4d2392ee4ede49"},
+	})
+}
+
+func (s *VerityTestSuite) TestOpenFails(c *C) {
+	vscmd := testutil.MockCommand(c, "veritysetup", `
+echo "Device payload already exists." >&2
+exit 1
+`)
+	defer vscmd.Restore()
+
+	err := dmverity.Open("/dev/vda4", "payload", "/dev/vda5", "cf9a379613c0dc10301fe3eba4665c38b849b7aad311471faa4d2392ee4ede49")
+	c.Check(err, ErrorMatches, `(?s).*stderr:\nDevice payload already exists\.\n.*`)
+}
+
 func (s *VerityTestSuite) TestVerityVersionDetect(c *C) {
 	tests := []struct {
 		ver    string
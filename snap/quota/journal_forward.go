@@ -0,0 +1,113 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package quota
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+)
+
+const (
+	// JournalForwardUpload is the method forwarding the journal of a quota
+	// group to a remote journal server, e.g. systemd-journal-remote, with
+	// systemd-journal-upload.
+	JournalForwardUpload = "upload"
+	// JournalForwardSyslog is the method forwarding the journal of a quota
+	// group to a remote syslog server.
+	JournalForwardSyslog = "syslog"
+
+	defaultSyslogPort = 514
+)
+
+// JournalForward describes where the journal namespace of a quota group is
+// forwarded to.
+type JournalForward struct {
+	// Method is either JournalForwardUpload or JournalForwardSyslog.
+	Method string
+	// URL is the URL of the remote journal server, for the upload method.
+	URL string
+	// Protocol is either "http" or "https" for the upload method, "udp"
+	// or "tcp" for the syslog method.
+	Protocol string
+	// Host and Port are the address of the remote server.
+	Host string
+	Port int
+}
+
+// hosts end up in the command lines of systemd units, only allow what is
+// needed for host names and IP addresses
+var validForwardHost = regexp.MustCompile(`^[A-Za-z0-9.:-]+$`)
+
+// ParseJournalForward parses the target the journal of a quota group is
+// forwarded to, which is one of:
+//   - http(s)://host[:port], a remote journal server
+//   - syslog://host[:port], a remote syslog server over UDP
+//   - syslog+tcp://host[:port], a remote syslog server over TCP
+//
+// The port of syslog servers defaults to 514.
+func ParseJournalForward(target string) (*JournalForward, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid journal forwarding target %q: %v", target, err)
+	}
+	var method, protocol string
+	switch u.Scheme {
+	case "http", "https":
+		method, protocol = JournalForwardUpload, u.Scheme
+	case "syslog":
+		method, protocol = JournalForwardSyslog, "udp"
+	case "syslog+tcp":
+		method, protocol = JournalForwardSyslog, "tcp"
+	default:
+		return nil, fmt.Errorf("invalid journal forwarding target %q: unsupported scheme %q, expected one of http, https, syslog or syslog+tcp", target, u.Scheme)
+	}
+	if u.User != nil || (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+		return nil, fmt.Errorf("invalid journal forwarding target %q: only a host and port can be provided", target)
+	}
+	host := u.Hostname()
+	if host == "" || !validForwardHost.MatchString(host) {
+		return nil, fmt.Errorf("invalid journal forwarding target %q: invalid host", target)
+	}
+	port := 0
+	if p := u.Port(); p != "" {
+		port, err = strconv.Atoi(p)
+		if err != nil || port < 1 || port > 65535 {
+			return nil, fmt.Errorf("invalid journal forwarding target %q: invalid port", target)
+		}
+	}
+
+	fwd := &JournalForward{
+		Method:   method,
+		Protocol: protocol,
+		Host:     host,
+		Port:     port,
+	}
+	switch method {
+	case JournalForwardUpload:
+		fwd.URL = fmt.Sprintf("%s://%s", u.Scheme, u.Host)
+	case JournalForwardSyslog:
+		if fwd.Port == 0 {
+			fwd.Port = defaultSyslogPort
+		}
+	}
+	return fwd, nil
+}
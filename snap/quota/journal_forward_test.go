@@ -0,0 +1,77 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package quota_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/snap/quota"
+)
+
+type journalForwardSuite struct{}
+
+var _ = Suite(&journalForwardSuite{})
+
+func (s *journalForwardSuite) TestParseJournalForward(c *C) {
+	tests := []struct {
+		target string
+		fwd    quota.JournalForward
+	}{
+		{"https://logs.example.com", quota.JournalForward{
+			Method: quota.JournalForwardUpload, URL: "https://logs.example.com", Protocol: "https", Host: "logs.example.com",
+		}},
+		{"http://10.0.0.1:19532/", quota.JournalForward{
+			Method: quota.JournalForwardUpload, URL: "http://10.0.0.1:19532", Protocol: "http", Host: "10.0.0.1", Port: 19532,
+		}},
+		{"syslog://logs.example.com", quota.JournalForward{
+			Method: quota.JournalForwardSyslog, Protocol: "udp", Host: "logs.example.com", Port: 514,
+		}},
+		{"syslog+tcp://[fd00::1]:6514", quota.JournalForward{
+			Method: quota.JournalForwardSyslog, Protocol: "tcp", Host: "fd00::1", Port: 6514,
+		}},
+	}
+	for _, t := range tests {
+		fwd, err := quota.ParseJournalForward(t.target)
+		c.Assert(err, IsNil, Commentf(t.target))
+		c.Check(*fwd, DeepEquals, t.fwd, Commentf(t.target))
+	}
+}
+
+func (s *journalForwardSuite) TestParseJournalForwardErrors(c *C) {
+	tests := []struct {
+		target string
+		err    string
+	}{
+		{"logs.example.com", `invalid journal forwarding target "logs.example.com": unsupported scheme "", expected one of http, https, syslog or syslog\+tcp`},
+		{"ftp://logs.example.com", `invalid journal forwarding target "ftp://logs.example.com": unsupported scheme "ftp", expected one of http, https, syslog or syslog\+tcp`},
+		{"syslog://", `invalid journal forwarding target "syslog://": invalid host`},
+		{"syslog://logs.example.com:0", `invalid journal forwarding target "syslog://logs.example.com:0": invalid port`},
+		{"syslog://logs.example.com:65536", `invalid journal forwarding target "syslog://logs.example.com:65536": invalid port`},
+		{"https://user@logs.example.com", `invalid journal forwarding target "https://user@logs.example.com": only a host and port can be provided`},
+		{"https://logs.example.com/upload", `invalid journal forwarding target "https://logs.example.com/upload": only a host and port can be provided`},
+		{"https://logs.example.com?x=1", `invalid journal forwarding target "https://logs.example.com\?x=1": only a host and port can be provided`},
+		{"syslog://logs%25example.com", `invalid journal forwarding target .*`},
+		{"https://%zz", `invalid journal forwarding target "https://%zz": .*`},
+	}
+	for _, t := range tests {
+		_, err := quota.ParseJournalForward(t.target)
+		c.Check(err, ErrorMatches, t.err, Commentf(t.target))
+	}
+}
@@ -81,6 +81,11 @@ type GroupQuotaJournal struct {
 	// RateCount number of messages is allowed. A zero value in this field will
 	// disable the rate-limit.
 	RatePeriod time.Duration `json:"rate-period,omitempty"`
+
+	// Forward is the target the journal namespace of the group is forwarded
+	// to, as accepted by ParseJournalForward. An empty value means that the
+	// journal is not forwarded.
+	Forward string `json:"forward,omitempty"`
 }
 
 // Group is a quota group of snaps, services or sub-groups that are all subject
@@ -191,6 +196,9 @@ func (grp *Group) GetQuotaResources() Resources {
 		if grp.JournalLimit.RateEnabled {
 			resourcesBuilder.WithJournalRate(grp.JournalLimit.RateCount, grp.JournalLimit.RatePeriod)
 		}
+		if grp.JournalLimit.Forward != "" {
+			resourcesBuilder.WithJournalForward(grp.JournalLimit.Forward)
+		}
 	}
 	return resourcesBuilder.Build()
 }
@@ -315,6 +323,12 @@ func (grp *Group) JournalSocketName() string {
 	return fmt.Sprintf("systemd-journald@%s.socket", grp.JournalNamespaceName())
 }
 
+// JournalForwardServiceName returns the name of the systemd service
+// forwarding the journal namespace of the quota group to a remote server.
+func (grp *Group) JournalForwardServiceName() string {
+	return fmt.Sprintf("%s-journal-forward.service", grp.JournalNamespaceName())
+}
+
 // JournalServiceFile returns the directory specific to this quota group for
 // its journal service unit drop-in.
 func (grp *Group) JournalServiceDropInDir() string {
@@ -803,6 +817,9 @@ func (grp *Group) UpdateQuotaLimits(resourceLimits Resources) error {
 			grp.JournalLimit.RateCount = resourceLimits.Journal.Rate.Count
 			grp.JournalLimit.RatePeriod = resourceLimits.Journal.Rate.Period
 		}
+		if resourceLimits.Journal.Forward != nil {
+			grp.JournalLimit.Forward = resourceLimits.Journal.Forward.Target
+		}
 	}
 	return nil
 }
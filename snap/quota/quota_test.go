@@ -380,6 +380,12 @@ func (ts *quotaTestSuite) TestJournalConfFileName(c *C) {
 	c.Check(grp.JournalConfFileName(), Equals, "journald@snap-foo.conf")
 }
 
+func (ts *quotaTestSuite) TestJournalForwardServiceName(c *C) {
+	grp, err := quota.NewGroup("foo", quota.NewResourcesBuilder().WithMemoryLimit(quantity.SizeMiB).Build())
+	c.Assert(err, IsNil)
+	c.Check(grp.JournalForwardServiceName(), Equals, "snap-foo-journal-forward.service")
+}
+
 func (ts *quotaTestSuite) TestJournalServiceName(c *C) {
 	grp, err := quota.NewGroup("foo", quota.NewResourcesBuilder().WithMemoryLimit(quantity.SizeMiB).Build())
 	c.Assert(err, IsNil)
@@ -1575,6 +1581,25 @@ func (ts *quotaTestSuite) TestJournalQuotasUpdatesCorrectly(c *C) {
 	c.Check(grp1.JournalLimit.RatePeriod, Equals, time.Microsecond*5)
 }
 
+func (ts *quotaTestSuite) TestJournalForwardUpdatesCorrectly(c *C) {
+	grp, err := quota.NewGroup("groot", quota.NewResourcesBuilder().WithJournalSize(quantity.SizeMiB).Build())
+	c.Assert(err, IsNil)
+	c.Check(grp.JournalLimit.Forward, Equals, "")
+	c.Check(grp.GetQuotaResources().Journal.Forward, IsNil)
+
+	err = grp.UpdateQuotaLimits(quota.NewResourcesBuilder().WithJournalForward("syslog+tcp://logs.example.com:6514").Build())
+	c.Assert(err, IsNil)
+	c.Check(grp.JournalLimit.Size, Equals, quantity.SizeMiB)
+	c.Check(grp.JournalLimit.Forward, Equals, "syslog+tcp://logs.example.com:6514")
+	c.Check(grp.GetQuotaResources().Journal.Forward, DeepEquals, &quota.ResourceJournalForward{Target: "syslog+tcp://logs.example.com:6514"})
+
+	// an empty target disables forwarding
+	err = grp.UpdateQuotaLimits(quota.NewResourcesBuilder().WithJournalForward("").Build())
+	c.Assert(err, IsNil)
+	c.Check(grp.JournalLimit.Size, Equals, quantity.SizeMiB)
+	c.Check(grp.JournalLimit.Forward, Equals, "")
+}
+
 func (ts *quotaTestSuite) TestServiceMapEmptyOnEmptyGroup(c *C) {
 	rootGrp, err := quota.NewGroup("myroot", quota.NewResourcesBuilder().WithMemoryLimit(quantity.SizeGiB).Build())
 	c.Assert(err, IsNil)
@@ -71,13 +71,20 @@ type ResourceJournalRate struct {
 	Period time.Duration `json:"period"`
 }
 
+// ResourceJournalForward is the target the journal namespace is forwarded
+// to. An empty Target disables forwarding.
+type ResourceJournalForward struct {
+	Target string `json:"target"`
+}
+
 // ResourceJournal represents the available journal quotas. It's structured
 // a bit different compared to the other resources to support namespace only
 // cases, where the existence of != nil ResourceJournal with empty values
 // indicates that the namespace only is wanted.
 type ResourceJournal struct {
-	Size *ResourceJournalSize `json:"size,omitempty"`
-	Rate *ResourceJournalRate `json:"rate,omitempty"`
+	Size    *ResourceJournalSize    `json:"size,omitempty"`
+	Rate    *ResourceJournalRate    `json:"rate,omitempty"`
+	Forward *ResourceJournalForward `json:"forward,omitempty"`
 }
 
 // Resources are built up of multiple quota limits. Each quota limit is a pointer
@@ -188,6 +195,12 @@ func (qr *Resources) validateJournalQuota() error {
 			return fmt.Errorf("journal quota must have a period of at least 1 microsecond (minimum resolution)")
 		}
 	}
+
+	if qr.Journal.Forward != nil && qr.Journal.Forward.Target != "" {
+		if _, err := ParseJournalForward(qr.Journal.Forward.Target); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -382,6 +395,9 @@ func (qr *Resources) clone() Resources {
 		if qr.Journal.Rate != nil {
 			resourcesCopy.Journal.Rate = &ResourceJournalRate{Count: qr.Journal.Rate.Count, Period: qr.Journal.Rate.Period}
 		}
+		if qr.Journal.Forward != nil {
+			resourcesCopy.Journal.Forward = &ResourceJournalForward{Target: qr.Journal.Forward.Target}
+		}
 	}
 	return resourcesCopy
 }
@@ -410,6 +426,9 @@ func (qr *Resources) changeInternal(newLimits Resources) {
 		if newLimits.Journal.Rate != nil {
 			qr.Journal.Rate = newLimits.Journal.Rate
 		}
+		if newLimits.Journal.Forward != nil {
+			qr.Journal.Forward = newLimits.Journal.Forward
+		}
 	}
 }
 
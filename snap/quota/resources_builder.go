@@ -49,6 +49,9 @@ type ResourcesBuilder struct {
 	JournalRateCountLimit  int
 	JournalRatePeriodLimit time.Duration
 	JournalRateSet         bool
+
+	JournalForward    string
+	JournalForwardSet bool
 }
 
 func (rb *ResourcesBuilder) WithMemoryLimit(limit quantity.Size) *ResourcesBuilder {
@@ -99,6 +102,14 @@ func (rb *ResourcesBuilder) WithJournalRate(count int, period time.Duration) *Re
 	return rb
 }
 
+// WithJournalForward sets the target the journal namespace is forwarded to,
+// an empty target disables forwarding.
+func (rb *ResourcesBuilder) WithJournalForward(target string) *ResourcesBuilder {
+	rb.JournalForward = target
+	rb.JournalForwardSet = true
+	return rb
+}
+
 func (rb *ResourcesBuilder) Build() Resources {
 	var quotaResources Resources
 	if rb.MemoryLimitSet {
@@ -122,7 +133,7 @@ func (rb *ResourcesBuilder) Build() Resources {
 			Limit: rb.ThreadLimit,
 		}
 	}
-	if rb.JournalNamespaceSet || rb.JournalSizeLimitSet || rb.JournalRateSet {
+	if rb.JournalNamespaceSet || rb.JournalSizeLimitSet || rb.JournalRateSet || rb.JournalForwardSet {
 		quotaResources.Journal = &ResourceJournal{}
 		if rb.JournalSizeLimitSet {
 			quotaResources.Journal.Size = &ResourceJournalSize{
@@ -135,6 +146,11 @@ func (rb *ResourcesBuilder) Build() Resources {
 				Period: rb.JournalRatePeriodLimit,
 			}
 		}
+		if rb.JournalForwardSet {
+			quotaResources.Journal.Forward = &ResourceJournalForward{
+				Target: rb.JournalForward,
+			}
+		}
 	}
 	return quotaResources
 }
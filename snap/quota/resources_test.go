@@ -50,6 +50,7 @@ func (s *resourcesTestSuite) TestQuotaValidationFails(c *C) {
 		{quota.NewResourcesBuilder().WithJournalRate(0, 1).Build(), `journal quota must have a period of at least 1 microsecond \(minimum resolution\)`},
 		{quota.NewResourcesBuilder().WithJournalRate(1, time.Nanosecond).Build(), `journal quota must have a period of at least 1 microsecond \(minimum resolution\)`},
 		{quota.NewResourcesBuilder().WithJournalSize(0).Build(), `journal size quota must have a limit set`},
+		{quota.NewResourcesBuilder().WithJournalForward("ftp://host").Build(), `invalid journal forwarding target "ftp://host": unsupported scheme "ftp", expected one of http, https, syslog or syslog\+tcp`},
 	}
 
 	for _, t := range tests {
@@ -95,6 +96,8 @@ func (s *resourcesTestSuite) TestQuotaValidationPasses(c *C) {
 		{quota.NewResourcesBuilder().WithJournalSize(quantity.SizeMiB).Build()},
 		{quota.NewResourcesBuilder().WithJournalRate(1, time.Microsecond).Build()},
 		{quota.NewResourcesBuilder().WithJournalNamespace().Build()},
+		{quota.NewResourcesBuilder().WithJournalForward("syslog://logs.example.com").Build()},
+		{quota.NewResourcesBuilder().WithJournalSize(quantity.SizeMiB).WithJournalForward("").Build()},
 	}
 
 	for _, t := range tests {
@@ -37,10 +37,12 @@ import (
 	"github.com/snapcore/snapd/snapdtool"
 )
 
-// This file implements the support for snap deltas. Currently two formats are
-// supported:
+// This file implements the support for snap deltas. Currently three formats
+// are supported:
 //
 // - plain xdelta3 diff file on the compressed snaps
+// - plain zstd diff file on the compressed snaps, created with the
+//   --patch-from option of zstd
 // - xdelta3 diff on an uncompressed representation of the snap files defined
 //   by squashfs-tools called pseudo-files
 //
@@ -75,6 +77,7 @@ import (
 const (
 	// Identifiers for the store
 	xdelta3Format = "xdelta3"
+	zstdFormat    = "zstd"
 	// This follows compatibility labels conventions. First and second
 	// number represent format and tools versions respectively, and could
 	// use intervals in the future.
@@ -84,6 +87,8 @@ const (
 const (
 	// xdelta3 header, see https://datatracker.ietf.org/doc/html/rfc3284
 	xdelta3MagicNumber = uint32(0x00c4c3d6)
+	// zstd frame header, see https://datatracker.ietf.org/doc/html/rfc8878
+	zstdMagicNumber = uint32(0xfd2fb528)
 	// squashfs magic number ("hsqs")
 	squashfsMagicNumber = uint32(0x73717368)
 )
@@ -142,6 +147,13 @@ var (
 	// compression duplicates window (-P)
 	xdelta3Tuning = []string{"-7"}
 
+	// zstd on compressed files tuning.
+	// The window must cover the whole source snap for --patch-from to find
+	// the matches, --long=31 allows windows of up to 2GB, and needs to be
+	// passed when applying the delta too.
+	zstdPlainTuning      = []string{"-19", "--long=31"}
+	zstdPlainApplyTuning = []string{"--long=31"}
+
 	// unsquashfs tuning.
 	// By default unsquashfs would allocate ~2x256 for any size of squashfs image.
 	// We need to tame it down and use different tuning for:
@@ -253,6 +265,7 @@ func copyBuffer(dst io.Writer, src io.Reader) (int64, error) {
 
 type DeltaFormatOpts struct {
 	WithSnapDeltaFormat bool
+	WithZstdFormat      bool
 }
 
 // Supported delta formats. The order here is determines the preferred formats,
@@ -263,6 +276,9 @@ func SupportedDeltaFormats(opts DeltaFormatOpts) []string {
 	if opts.WithSnapDeltaFormat {
 		formats = append(formats, snapDeltaFormatXdelta3)
 	}
+	if opts.WithZstdFormat {
+		formats = append(formats, zstdFormat)
+	}
 	formats = append(formats, xdelta3Format)
 	return formats
 }
@@ -289,6 +305,9 @@ func GenerateDelta(ctx context.Context, sourceSnap, targetSnap, delta string, de
 	case xdelta3Format:
 		// Plain xdelta3 on compressed files
 		return generatePlainXdelta3Delta(ctx, sourceSnap, targetSnap, delta)
+	case zstdFormat:
+		// Plain zstd on compressed files
+		return generatePlainZstdDelta(ctx, sourceSnap, targetSnap, delta)
 	case snapDeltaFormatXdelta3:
 		return generateSnapDelta(ctx, sourceSnap, targetSnap, delta)
 	default:
@@ -351,6 +370,9 @@ func ApplyDelta(ctx context.Context, sourceSnap, delta, targetSnap string) error
 	case xdelta3MagicNumber:
 		logger.Debugf("plain xdelta3 detected")
 		return applyPlainXdelta3Delta(ctx, sourceSnap, delta, targetSnap)
+	case zstdMagicNumber:
+		logger.Debugf("plain zstd detected")
+		return applyPlainZstdDelta(ctx, sourceSnap, delta, targetSnap)
 	case deltaMagicNumber:
 		if n < deltaHeaderSize {
 			return fmt.Errorf("snap delta header too short (%d bytes read)", n)
@@ -415,6 +437,36 @@ func generatePlainXdelta3Delta(ctx context.Context, sourceSnap, targetSnap, delt
 	return cmdRun(cmd)
 }
 
+// generatePlainZstdDelta generates a zstd delta between compressed snaps
+func generatePlainZstdDelta(ctx context.Context, sourceSnap, targetSnap, delta string) error {
+	// Compression level and window, force overwrite (-f), source
+	// (--patch-from=<file>), target, delta (-o <file>)
+	opts := append([]string{}, zstdPlainTuning...)
+	opts = append(opts, "-f", "--patch-from="+sourceSnap, targetSnap, "-o", delta)
+	cmd, err := snapdtoolCommandFromSystemSnapWithContext(ctx, "/usr/bin/zstd", opts...)
+	if err != nil {
+		return err
+	}
+
+	// cmd is cancellable if ctx is a cancellable context
+	return cmdRun(cmd)
+}
+
+// applyPlainZstdDelta applies a zstd delta between compressed snaps
+func applyPlainZstdDelta(ctx context.Context, sourceSnap, delta, targetSnap string) error {
+	// Window, force overwrite (-f), decompress (-d), source
+	// (--patch-from=<file>), delta, target (-o <file>)
+	opts := append([]string{}, zstdPlainApplyTuning...)
+	opts = append(opts, "-f", "-d", "--patch-from="+sourceSnap, delta, "-o", targetSnap)
+	cmd, err := snapdtoolCommandFromSystemSnapWithContext(ctx, "/usr/bin/zstd", opts...)
+	if err != nil {
+		return err
+	}
+
+	// cmd is cancellable if ctx is a cancellable context
+	return cmdRun(cmd)
+}
+
 // applyPlainXdelta3Delta applies a delta between compressed snaps
 func applyPlainXdelta3Delta(ctx context.Context, sourceSnap, delta, targetSnap string) error {
 	// Force overwrite (-f), decompress (-d), source (-s <file>), target, delta
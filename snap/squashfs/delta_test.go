@@ -62,6 +62,14 @@ func (s *DeltaTestSuite) TestSupportedDeltaFormats(c *C) {
 	c.Assert(squashfs.SupportedDeltaFormats(
 		squashfs.DeltaFormatOpts{WithSnapDeltaFormat: true}), DeepEquals,
 		[]string{"snap-1-1-xdelta3", "xdelta3"})
+
+	c.Assert(squashfs.SupportedDeltaFormats(
+		squashfs.DeltaFormatOpts{WithZstdFormat: true}), DeepEquals,
+		[]string{"zstd", "xdelta3"})
+
+	c.Assert(squashfs.SupportedDeltaFormats(
+		squashfs.DeltaFormatOpts{WithSnapDeltaFormat: true, WithZstdFormat: true}), DeepEquals,
+		[]string{"snap-1-1-xdelta3", "zstd", "xdelta3"})
 }
 
 func (s *DeltaTestSuite) TestCompIdToMksquashfsArgs(c *C) {
@@ -372,6 +380,37 @@ func (s *DeltaTestSuite) TestGenerateDeltaPlainSuccess(c *C) {
 	c.Assert(err, IsNil)
 }
 
+func (s *DeltaTestSuite) TestGenerateDeltaPlainZstdSuccess(c *C) {
+	defer squashfs.MockCommandFromSystemSnapWithContext(
+		func(ctx context.Context, cmd string, args ...string) (*exec.Cmd, error) {
+			c.Check(cmd, Equals, "/usr/bin/zstd")
+			c.Check(args, DeepEquals,
+				[]string{"-19", "--long=31", "-f", "--patch-from=source.snap", "target.snap", "-o", "diff.zstd"})
+			return &exec.Cmd{
+				Path: cmd,
+				Args: append([]string{cmd}, args...),
+			}, nil
+		})()
+
+	defer squashfs.MockCmdRun(func(cmd *exec.Cmd) error {
+		return nil
+	})()
+
+	// Execute
+	err := squashfs.GenerateDelta(context.Background(), "source.snap", "target.snap", "diff.zstd", "zstd")
+	c.Assert(err, IsNil)
+}
+
+func (s *DeltaTestSuite) TestGenerateDeltaPlainZstdError(c *C) {
+	defer squashfs.MockCommandFromSystemSnapWithContext(
+		func(ctx context.Context, cmd string, args ...string) (*exec.Cmd, error) {
+			return nil, errors.New("cannot find zstd")
+		})()
+
+	err := squashfs.GenerateDelta(context.Background(), "source.snap", "target.snap", "diff.zstd", "zstd")
+	c.Assert(err, ErrorMatches, "cannot find zstd")
+}
+
 func (s *DeltaTestSuite) TestGenerateDeltaSnapXdelta3Success(c *C) {
 	// Setup mock snaps
 	// 4 = xz compression, 0x0040 = flagDuplicates
@@ -625,6 +664,34 @@ func (s *DeltaTestSuite) TestApplyDeltaPlainSuccess(c *C) {
 	c.Assert(err, IsNil)
 }
 
+func (s *DeltaTestSuite) TestApplyDeltaPlainZstdSuccess(c *C) {
+	// Write file with just the zstd frame magic
+	zstdDiffPath := filepath.Join(dirs.GlobalRootDir, "diff.zstd")
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.LittleEndian, uint32(0xfd2fb528))
+	err := os.WriteFile(zstdDiffPath, buf.Bytes(), 0644)
+	c.Assert(err, IsNil)
+
+	defer squashfs.MockCommandFromSystemSnapWithContext(
+		func(ctx context.Context, cmd string, args ...string) (*exec.Cmd, error) {
+			c.Check(cmd, Equals, "/usr/bin/zstd")
+			c.Check(args[0:4], DeepEquals, []string{"--long=31", "-f", "-d", "--patch-from=source.snap"})
+			c.Check(args[4], Matches, ".*/diff.zstd")
+			c.Check(args[5:], DeepEquals, []string{"-o", "target.snap"})
+			return &exec.Cmd{
+				Path: cmd,
+				Args: append([]string{cmd}, args...),
+			}, nil
+		})()
+	defer squashfs.MockCmdRun(func(cmd *exec.Cmd) error {
+		return nil
+	})()
+
+	// Execute
+	err = squashfs.ApplyDelta(context.Background(), "source.snap", zstdDiffPath, "target.snap")
+	c.Assert(err, IsNil)
+}
+
 func (s *DeltaTestSuite) TestApplyDeltaSnapXdelta3Success(c *C) {
 	// Create a mock delta: gzip (1), duplicate flags set (0x0040), timestamp 5000
 	deltaPath := s.createDeltaFile(c, "valid.delta", 5000, 1, 0x0040)
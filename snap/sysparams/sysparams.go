@@ -44,6 +44,9 @@ type SystemParams struct {
 	// Homedirs is the comma-delimited list of user specified home
 	// directories that should be mounted.
 	Homedirs string
+	// BackupTargetDir is the directory that snaps connected to the
+	// system-backup interface can write backups to.
+	BackupTargetDir string
 }
 
 func parseSystemParams(contents string) (*SystemParams, error) {
@@ -69,9 +72,12 @@ func parseSystemParams(contents string) (*SystemParams, error) {
 		}
 		seen[tokens[0]] = true
 
-		if tokens[0] == "homedirs" {
+		switch tokens[0] {
+		case "homedirs":
 			params.Homedirs = tokens[1]
-		} else {
+		case "backup-target-dir":
+			params.BackupTargetDir = tokens[1]
+		default:
 			return nil, fmt.Errorf("invalid line: %q", line)
 		}
 	}
@@ -116,6 +122,9 @@ func Open(rootdir string) (*SystemParams, error) {
 func (ssp *SystemParams) Write() error {
 	sspFile := sysparamsFile(ssp.rootdir)
 	contents := fmt.Sprintf("homedirs=%s\n", ssp.Homedirs)
+	if ssp.BackupTargetDir != "" {
+		contents += fmt.Sprintf("backup-target-dir=%s\n", ssp.BackupTargetDir)
+	}
 	if err := osutilAtomicWriteFile(sspFile, []byte(contents), 0644, 0); err != nil {
 		return fmt.Errorf("cannot write system-params: %v", err)
 	}
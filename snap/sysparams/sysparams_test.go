@@ -152,3 +152,20 @@ homedirs=foo/baz
 	c.Check(err, ErrorMatches, `cannot parse system-params: duplicate entry found: "homedirs"`)
 	c.Check(ssp, IsNil)
 }
+
+func (s *sysParamsTestSuite) TestBackupTargetDirRoundTrip(c *C) {
+	sspPath := dirs.SnapSystemParamsUnder(dirs.GlobalRootDir)
+	c.Assert(os.MkdirAll(path.Dir(sspPath), 0755), IsNil)
+
+	ssp, err := sysparams.Open("")
+	c.Assert(err, IsNil)
+	ssp.Homedirs = "/home/foo"
+	ssp.BackupTargetDir = "/srv/backup"
+	c.Assert(ssp.Write(), IsNil)
+	c.Check(sspPath, testutil.FileEquals, "homedirs=/home/foo\nbackup-target-dir=/srv/backup\n")
+
+	ssp, err = sysparams.Open("")
+	c.Assert(err, IsNil)
+	c.Check(ssp.Homedirs, Equals, "/home/foo")
+	c.Check(ssp.BackupTargetDir, Equals, "/srv/backup")
+}
@@ -831,6 +831,54 @@ func validateAppSuccessExitStatus(app *AppInfo) error {
 	return nil
 }
 
+// knownCapabilities is the list of Linux capabilities that can be named in
+// the "capability-bounding-set" of a service hardening definition.
+var knownCapabilities = []string{
+	"CAP_AUDIT_CONTROL", "CAP_AUDIT_READ", "CAP_AUDIT_WRITE",
+	"CAP_BLOCK_SUSPEND", "CAP_BPF", "CAP_CHECKPOINT_RESTORE", "CAP_CHOWN",
+	"CAP_DAC_OVERRIDE", "CAP_DAC_READ_SEARCH", "CAP_FOWNER", "CAP_FSETID",
+	"CAP_IPC_LOCK", "CAP_IPC_OWNER", "CAP_KILL", "CAP_LEASE",
+	"CAP_LINUX_IMMUTABLE", "CAP_MAC_ADMIN", "CAP_MAC_OVERRIDE", "CAP_MKNOD",
+	"CAP_NET_ADMIN", "CAP_NET_BIND_SERVICE", "CAP_NET_BROADCAST",
+	"CAP_NET_RAW", "CAP_PERFMON", "CAP_SETFCAP", "CAP_SETGID", "CAP_SETPCAP",
+	"CAP_SETUID", "CAP_SYS_ADMIN", "CAP_SYS_BOOT", "CAP_SYS_CHROOT",
+	"CAP_SYS_MODULE", "CAP_SYS_NICE", "CAP_SYS_PACCT", "CAP_SYS_PTRACE",
+	"CAP_SYS_RAWIO", "CAP_SYS_RESOURCE", "CAP_SYS_TIME",
+	"CAP_SYS_TTY_CONFIG", "CAP_SYSLOG", "CAP_WAKE_ALARM",
+}
+
+func validateAppHardening(app *AppInfo) error {
+	if app.Hardening == nil {
+		return nil
+	}
+
+	if !app.IsService() {
+		return fmt.Errorf(`"hardening" cannot be used for %q, only for services`, app.Name)
+	}
+
+	switch app.Hardening.ProtectSystem {
+	case "", "true", "full":
+		// valid
+	default:
+		// "strict" is not supported as it would make the writable
+		// snap data directories read-only
+		return fmt.Errorf(`"hardening" field "protect-system" contains invalid value %q`, app.Hardening.ProtectSystem)
+	}
+
+	seen := make(map[string]bool, len(app.Hardening.CapabilityBoundingSet))
+	for _, capability := range app.Hardening.CapabilityBoundingSet {
+		if !strutil.ListContains(knownCapabilities, capability) {
+			return fmt.Errorf(`"hardening" field "capability-bounding-set" contains unknown capability %q`, capability)
+		}
+		if seen[capability] {
+			return fmt.Errorf(`"hardening" field "capability-bounding-set" contains duplicate capability %q`, capability)
+		}
+		seen[capability] = true
+	}
+
+	return nil
+}
+
 func validateAppActivatesOn(app *AppInfo) error {
 	if len(app.ActivatesOn) == 0 {
 		return nil
@@ -964,6 +1012,10 @@ func ValidateApp(app *AppInfo) error {
 		return err
 	}
 
+	if err := validateAppHardening(app); err != nil {
+		return err
+	}
+
 	// validate stop-mode
 	if err := app.StopMode.Validate(); err != nil {
 		return err
@@ -985,12 +1037,8 @@ func ValidateApp(app *AppInfo) error {
 	if app.StopMode != "" && app.Daemon == "" {
 		return fmt.Errorf(`"stop-mode" cannot be used for %q, only for services`, app.Name)
 	}
-	if app.RefreshMode != "" {
-		if app.Daemon != "" && app.RefreshMode == "ignore-running" {
-			return errors.New(`"refresh-mode" cannot be set to "ignore-running" for services`)
-		} else if app.Daemon == "" && app.RefreshMode != "ignore-running" {
-			return fmt.Errorf(`"refresh-mode" for app %q can only have value "ignore-running"`, app.Name)
-		}
+	if app.RefreshMode != "" && app.Daemon == "" && app.RefreshMode != "ignore-running" {
+		return fmt.Errorf(`"refresh-mode" for app %q can only have value "ignore-running"`, app.Name)
 	}
 	if app.InstallMode != "" && app.Daemon == "" {
 		return fmt.Errorf(`"install-mode" cannot be used for %q, only for services`, app.Name)
@@ -1218,6 +1266,12 @@ func ValidateLayout(layout *Layout, constraints []LayoutConstraint) error {
 		return fmt.Errorf("layout %q uses invalid filesystem %q", layout.Path, layout.Type)
 	}
 
+	switch layout.Mimic {
+	case "overlay", "":
+	default:
+		return fmt.Errorf("layout %q uses invalid mimic %q", layout.Path, layout.Mimic)
+	}
+
 	if layout.Symlink != "" {
 		oldname := layout.Symlink
 		if err := ValidatePathVariables(oldname); err != nil {
@@ -561,11 +561,11 @@ func (s *ValidateSuite) TestAppRefreshMode(c *C) {
 		{"endure", "simple", ""},
 		{"restart", "simple", ""},
 		{"ignore-running", "", ""},
+		{"ignore-running", "simple", ""},
 		// bad
 		{"invalid-thing", "simple", `"refresh-mode" field contains invalid value "invalid-thing"`},
 		{"endure", "", `"refresh-mode" for app "foo" can only have value "ignore-running"`},
 		{"restart", "", `"refresh-mode" for app "foo" can only have value "ignore-running"`},
-		{"ignore-running", "simple", `"refresh-mode" cannot be set to "ignore-running" for services`},
 	} {
 		var daemonScope DaemonScope
 		if t.daemon != "" {
@@ -1044,6 +1044,9 @@ func (s *ValidateSuite) TestValidateLayout(c *C) {
 		ErrorMatches, `layout "/foo" must define a bind mount, a filesystem mount or a symlink`)
 	c.Check(ValidateLayout(&Layout{Snap: si, Path: "/foo", Type: "ext4"}, nil),
 		ErrorMatches, `layout "/foo" uses invalid filesystem "ext4"`)
+	c.Check(ValidateLayout(&Layout{Snap: si, Path: "/foo", Type: "tmpfs", Mimic: "aufs"}, nil),
+		ErrorMatches, `layout "/foo" uses invalid mimic "aufs"`)
+	c.Check(ValidateLayout(&Layout{Snap: si, Path: "/foo", Type: "tmpfs", Mimic: "overlay"}, nil), IsNil)
 	c.Check(ValidateLayout(&Layout{Snap: si, Path: "/foo/bar", Type: "tmpfs", User: "foo"}, nil),
 		ErrorMatches, `layout "/foo/bar" uses invalid user "foo"`)
 	c.Check(ValidateLayout(&Layout{Snap: si, Path: "/foo/bar", Type: "tmpfs", Group: "foo"}, nil),
@@ -2298,6 +2301,35 @@ func (s *ValidateSuite) TestAppInstallMode(c *C) {
 	c.Check(err, ErrorMatches, `"install-mode" cannot be used for "foo", only for services`)
 }
 
+func (s *ValidateSuite) TestAppHardening(c *C) {
+	for _, t := range []struct {
+		hardening *ServiceHardening
+		err       string
+	}{
+		// good
+		{&ServiceHardening{}, ""},
+		{&ServiceHardening{ProtectSystem: "true", PrivateTmp: true}, ""},
+		{&ServiceHardening{ProtectSystem: "full", CapabilityBoundingSet: []string{"CAP_NET_BIND_SERVICE", "CAP_KILL"}}, ""},
+		// bad
+		{&ServiceHardening{ProtectSystem: "strict"}, `"hardening" field "protect-system" contains invalid value "strict"`},
+		{&ServiceHardening{ProtectSystem: "yes"}, `"hardening" field "protect-system" contains invalid value "yes"`},
+		{&ServiceHardening{CapabilityBoundingSet: []string{"CAP_FOO"}}, `"hardening" field "capability-bounding-set" contains unknown capability "CAP_FOO"`},
+		{&ServiceHardening{CapabilityBoundingSet: []string{"cap_kill"}}, `"hardening" field "capability-bounding-set" contains unknown capability "cap_kill"`},
+		{&ServiceHardening{CapabilityBoundingSet: []string{"CAP_KILL", "CAP_KILL"}}, `"hardening" field "capability-bounding-set" contains duplicate capability "CAP_KILL"`},
+	} {
+		err := ValidateApp(&AppInfo{Name: "foo", Daemon: "simple", DaemonScope: SystemDaemon, Hardening: t.hardening})
+		if t.err == "" {
+			c.Check(err, IsNil)
+		} else {
+			c.Check(err, ErrorMatches, t.err)
+		}
+	}
+
+	// non-services cannot be hardened
+	err := ValidateApp(&AppInfo{Name: "foo", Daemon: "", Hardening: &ServiceHardening{PrivateTmp: true}})
+	c.Check(err, ErrorMatches, `"hardening" cannot be used for "foo", only for services`)
+}
+
 func (s *ValidateSuite) TestValidateLinks(c *C) {
 	info, err := InfoFromSnapYaml([]byte(`name: foo
 version: 1.0
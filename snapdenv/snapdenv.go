@@ -97,3 +97,9 @@ func MockPreseeding(preseeding bool) (restore func()) {
 func PreseedingHybrid() bool {
 	return Preseeding() && osutil.GetenvBool("SNAPD_PRESEED_HYBRID")
 }
+
+// PreseedingEmulated returns true if snapd is preseeding a system of a
+// different architecture than the host, running under qemu-user emulation.
+func PreseedingEmulated() bool {
+	return Preseeding() && osutil.GetenvBool("SNAPD_PRESEED_EMULATED")
+}
@@ -125,6 +125,31 @@ func (s *snapdenvSuite) TestPreseeding(c *C) {
 	c.Check(snapdenv.Preseeding(), Equals, false)
 }
 
+func (s *snapdenvSuite) TestPreseedingEmulated(c *C) {
+	oldEmulated := os.Getenv("SNAPD_PRESEED_EMULATED")
+	defer func() {
+		if oldEmulated == "" {
+			os.Unsetenv("SNAPD_PRESEED_EMULATED")
+		} else {
+			os.Setenv("SNAPD_PRESEED_EMULATED", oldEmulated)
+		}
+	}()
+
+	restore := snapdenv.MockPreseeding(true)
+	defer restore()
+
+	os.Setenv("SNAPD_PRESEED_EMULATED", "1")
+	c.Check(snapdenv.PreseedingEmulated(), Equals, true)
+
+	os.Unsetenv("SNAPD_PRESEED_EMULATED")
+	c.Check(snapdenv.PreseedingEmulated(), Equals, false)
+
+	// only meaningful when preseeding
+	os.Setenv("SNAPD_PRESEED_EMULATED", "1")
+	snapdenv.MockPreseeding(false)
+	c.Check(snapdenv.PreseedingEmulated(), Equals, false)
+}
+
 func (s *snapdenvSuite) TestMockPreseeding(c *C) {
 	oldPreseeding := os.Getenv("SNAPD_PRESEED")
 	defer func() {
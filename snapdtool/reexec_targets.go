@@ -0,0 +1,86 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snapdtool
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/osutil"
+)
+
+// ReexecTarget records a revision of the snapd or core snap which snap tools
+// may re-exec into, as last linked by snapd.
+type ReexecTarget struct {
+	Revision string `json:"revision"`
+	// SnapSHA3_384 is the digest of the snap file as listed in its
+	// snap-revision assertion, it is empty for unasserted snaps.
+	SnapSHA3_384 string `json:"snap-sha3-384,omitempty"`
+	// Size and ModTime describe the snap file at the time the record was
+	// made and allow for a cheap check that it was not modified since.
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mtime"`
+}
+
+// NewReexecTarget returns a re-exec target for the given revision of a snap
+// file, with the digest coming from its snap-revision assertion if any.
+func NewReexecTarget(snapFile, revision, sha3_384 string) (*ReexecTarget, error) {
+	fi, err := os.Stat(snapFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot record re-exec target: %v", err)
+	}
+	return &ReexecTarget{
+		Revision:     revision,
+		SnapSHA3_384: sha3_384,
+		Size:         fi.Size(),
+		ModTime:      fi.ModTime(),
+	}, nil
+}
+
+// WriteReexecTargets records the re-exec targets, keyed by snap name. The
+// record is world readable as it is checked by unprivileged snap tools.
+func WriteReexecTargets(targets map[string]*ReexecTarget) error {
+	data, err := json.Marshal(targets)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dirs.SnapReexecTargetsFile), 0755); err != nil {
+		return err
+	}
+	return osutil.AtomicWriteFile(dirs.SnapReexecTargetsFile, data, 0644, 0)
+}
+
+// ReadReexecTargets returns the re-exec targets recorded by snapd, keyed by
+// snap name.
+func ReadReexecTargets() (map[string]*ReexecTarget, error) {
+	data, err := os.ReadFile(dirs.SnapReexecTargetsFile)
+	if err != nil {
+		return nil, err
+	}
+	var targets map[string]*ReexecTarget
+	if err := json.Unmarshal(data, &targets); err != nil {
+		return nil, fmt.Errorf("cannot decode re-exec targets: %v", err)
+	}
+	return targets, nil
+}
@@ -1,7 +1,7 @@
 // -*- Mode: Go; indent-tabs-mode: t -*-
 
 /*
- * Copyright (C) 2016-2020 Canonical Ltd
+ * Copyright (C) 2016-2026 Canonical Ltd
  *
  * This program is free software: you can redistribute it and/or modify
  * it under the terms of the GNU General Public License version 3 as
@@ -20,7 +20,11 @@
 package snapdtool
 
 import (
+	"crypto"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
@@ -32,6 +36,9 @@ import (
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/release"
 	"github.com/snapcore/snapd/strutil"
+
+	// sha3 is needed to verify re-exec targets
+	_ "golang.org/x/crypto/sha3"
 )
 
 // The SNAP_REEXEC environment variable controls whether the command
@@ -40,6 +47,12 @@ import (
 // to be set to 1 (do re-exec); that is: set it to 0 to disable.
 const reExecKey = "SNAP_REEXEC"
 
+// The SNAP_REEXEC_VERIFY environment variable, when set to "full", makes the
+// verification of the re-exec target also compare the digest of its snap file
+// with the one from the snap-revision assertion, instead of only checking the
+// revision, size and modification time recorded by snapd.
+const reExecVerifyKey = "SNAP_REEXEC_VERIFY"
+
 var (
 	// snapdSnap is the place to look for the snapd snap; we will re-exec
 	// here
@@ -97,6 +110,60 @@ func candidateVersionNewer(coreOrSnapdPath string) (bool, error) {
 	return true, nil
 }
 
+// verifyReexecTarget checks that the given core/snapd snap is at the revision
+// last recorded by snapd and that its snap file was not modified since, which
+// guards against re-exec'ing into a tampered snap. Nothing is verified if snapd
+// has not recorded any re-exec targets yet.
+func verifyReexecTarget(coreOrSnapdPath string) error {
+	targets, err := ReadReexecTargets()
+	if errors.Is(err, fs.ErrNotExist) {
+		logger.Debugf("no re-exec targets recorded, not verifying %q", coreOrSnapdPath)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	snapName := filepath.Base(filepath.Dir(coreOrSnapdPath))
+	target := targets[snapName]
+	if target == nil {
+		return fmt.Errorf("no re-exec target recorded for snap %q", snapName)
+	}
+
+	resolved, err := filepath.EvalSymlinks(coreOrSnapdPath)
+	if err != nil {
+		return fmt.Errorf("cannot resolve snap %q revision: %v", snapName, err)
+	}
+	rev := filepath.Base(resolved)
+	if rev != target.Revision {
+		return fmt.Errorf("snap %q revision %s does not match recorded revision %s", snapName, rev, target.Revision)
+	}
+
+	snapFile := filepath.Join(dirs.SnapBlobDir, fmt.Sprintf("%s_%s.snap", snapName, rev))
+	fi, err := os.Stat(snapFile)
+	if err != nil {
+		return err
+	}
+	if fi.Size() != target.Size || !fi.ModTime().Equal(target.ModTime) {
+		return fmt.Errorf("snap file %q was modified since it was recorded", snapFile)
+	}
+
+	if os.Getenv(reExecVerifyKey) != "full" {
+		return nil
+	}
+	if target.SnapSHA3_384 == "" {
+		return fmt.Errorf("no snap-revision digest recorded for snap %q", snapName)
+	}
+	digest, _, err := osutil.FileDigest(snapFile, crypto.SHA3_384)
+	if err != nil {
+		return fmt.Errorf("cannot compute snap file %q digest: %v", snapFile, err)
+	}
+	if base64.RawURLEncoding.EncodeToString(digest) != target.SnapSHA3_384 {
+		return fmt.Errorf("snap file %q does not match its snap-revision digest", snapFile)
+	}
+	return nil
+}
+
 // InternalToolPath returns the path of an internal snapd tool. The tool
 // *must* be located inside the same tree as the current binary.
 //
@@ -250,6 +317,11 @@ func ExecInSnapdOrCoreSnap() {
 		}
 	}
 
+	if err := verifyReexecTarget(coreOrSnapdPath); err != nil {
+		logger.Noticef("WARNING: not restarting into %q: %v", full, err)
+		return
+	}
+
 	logger.Debugf("restarting into %q", full)
 
 	// We want to make "ps", "top" and other tools show a
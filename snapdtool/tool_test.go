@@ -1,7 +1,7 @@
 // -*- Mode: Go; indent-tabs-mode: t -*-
 
 /*
- * Copyright (C) 2017-2020 Canonical Ltd
+ * Copyright (C) 2017-2026 Canonical Ltd
  *
  * This program is free software: you can redistribute it and/or modify
  * it under the terms of the GNU General Public License version 3 as
@@ -20,11 +20,13 @@
 package snapdtool_test
 
 import (
+	"encoding/base64"
 	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"golang.org/x/crypto/sha3"
 	. "gopkg.in/check.v1"
 
 	"github.com/snapcore/snapd/dirs"
@@ -548,6 +550,89 @@ func (s *toolSuite) TestExecInSnapdOrCoreForced(c *C) {
 	c.Check(s.execCalled, Equals, 1)
 }
 
+func (s *toolSuite) recordReexecTarget(c *C, snapName, rev string, content []byte) string {
+	snapFile := filepath.Join(dirs.SnapBlobDir, fmt.Sprintf("%s_%s.snap", snapName, rev))
+	c.Assert(os.MkdirAll(dirs.SnapBlobDir, 0755), IsNil)
+	c.Assert(os.WriteFile(snapFile, content, 0644), IsNil)
+
+	digest := sha3.Sum384(content)
+	target, err := snapdtool.NewReexecTarget(snapFile, rev, base64.RawURLEncoding.EncodeToString(digest[:]))
+	c.Assert(err, IsNil)
+	c.Assert(snapdtool.WriteReexecTargets(map[string]*snapdtool.ReexecTarget{snapName: target}), IsNil)
+	return snapFile
+}
+
+func (s *toolSuite) TestExecInSnapdOrCoreSnapVerifiedTarget(c *C) {
+	defer s.mockReExecFor(c, s.snapdPath, "potato", dirs.DefaultDistroLibexecDir)()
+	s.recordReexecTarget(c, "snapd", "42", []byte("snapd-42"))
+
+	c.Check(snapdtool.ExecInSnapdOrCoreSnap, PanicMatches, `>exec of "[^"]+/potato" in tests<`)
+	c.Check(s.execCalled, Equals, 1)
+
+	os.Setenv("SNAP_REEXEC_VERIFY", "full")
+	defer os.Unsetenv("SNAP_REEXEC_VERIFY")
+
+	c.Check(snapdtool.ExecInSnapdOrCoreSnap, PanicMatches, `>exec of "[^"]+/potato" in tests<`)
+	c.Check(s.execCalled, Equals, 2)
+}
+
+func (s *toolSuite) TestExecInSnapdOrCoreSnapTargetRevisionMismatch(c *C) {
+	defer s.mockReExecFor(c, s.snapdPath, "potato", dirs.DefaultDistroLibexecDir)()
+	s.recordReexecTarget(c, "snapd", "41", []byte("snapd-41"))
+	logbuf, restore := logger.MockLogger()
+	defer restore()
+
+	snapdtool.ExecInSnapdOrCoreSnap()
+	c.Check(s.execCalled, Equals, 0)
+	c.Check(logbuf.String(), testutil.Contains, `WARNING: not restarting into "`+s.snapdPath+`/usr/lib/snapd/potato": snap "snapd" revision 42 does not match recorded revision 41`)
+}
+
+func (s *toolSuite) TestExecInSnapdOrCoreSnapTargetNotRecorded(c *C) {
+	defer s.mockReExecFor(c, s.corePath, "potato", dirs.DefaultDistroLibexecDir)()
+	s.recordReexecTarget(c, "snapd", "42", []byte("snapd-42"))
+	logbuf, restore := logger.MockLogger()
+	defer restore()
+
+	snapdtool.ExecInSnapdOrCoreSnap()
+	c.Check(s.execCalled, Equals, 0)
+	c.Check(logbuf.String(), testutil.Contains, `no re-exec target recorded for snap "core"`)
+}
+
+func (s *toolSuite) TestExecInSnapdOrCoreSnapTargetModified(c *C) {
+	defer s.mockReExecFor(c, s.snapdPath, "potato", dirs.DefaultDistroLibexecDir)()
+	snapFile := s.recordReexecTarget(c, "snapd", "42", []byte("snapd-42"))
+	c.Assert(os.WriteFile(snapFile, []byte("tampered snap"), 0644), IsNil)
+	logbuf, restore := logger.MockLogger()
+	defer restore()
+
+	snapdtool.ExecInSnapdOrCoreSnap()
+	c.Check(s.execCalled, Equals, 0)
+	c.Check(logbuf.String(), testutil.Contains, fmt.Sprintf("snap file %q was modified since it was recorded", snapFile))
+}
+
+func (s *toolSuite) TestExecInSnapdOrCoreSnapTargetDigestMismatch(c *C) {
+	defer s.mockReExecFor(c, s.snapdPath, "potato", dirs.DefaultDistroLibexecDir)()
+	snapFile := s.recordReexecTarget(c, "snapd", "42", []byte("snapd-42"))
+	// same size and modification time, different content
+	fi, err := os.Stat(snapFile)
+	c.Assert(err, IsNil)
+	c.Assert(os.WriteFile(snapFile, []byte("snapd-XX"), 0644), IsNil)
+	c.Assert(os.Chtimes(snapFile, fi.ModTime(), fi.ModTime()), IsNil)
+	logbuf, restore := logger.MockLogger()
+	defer restore()
+
+	// the cheap check does not notice
+	c.Check(snapdtool.ExecInSnapdOrCoreSnap, PanicMatches, `>exec of "[^"]+/potato" in tests<`)
+	c.Check(s.execCalled, Equals, 1)
+
+	os.Setenv("SNAP_REEXEC_VERIFY", "full")
+	defer os.Unsetenv("SNAP_REEXEC_VERIFY")
+
+	snapdtool.ExecInSnapdOrCoreSnap()
+	c.Check(s.execCalled, Equals, 1)
+	c.Check(logbuf.String(), testutil.Contains, fmt.Sprintf("snap file %q does not match its snap-revision digest", snapFile))
+}
+
 func (s *toolSuite) TestIsReexecd(c *C) {
 	mockedSelfExe := filepath.Join(s.fakeroot, "proc/self/exe")
 	restore := snapdtool.MockSelfExe(mockedSelfExe)
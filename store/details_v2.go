@@ -69,6 +69,8 @@ type storeSnap struct {
 	CommonIDs []string `json:"common-ids"`
 
 	Categories []storeSnapCategory `json:"categories"`
+
+	Tracks []storeTrack `json:"tracks"`
 }
 
 type storeDownload struct {
@@ -109,12 +111,20 @@ type storeSnapCategory struct {
 	Name     string `json:"name"`
 }
 
+// storeTrack is the metadata published about a track of a snap
+type storeTrack struct {
+	Name      string    `json:"name"`
+	EndOfLife time.Time `json:"end-of-life"`
+	Successor string    `json:"successor"`
+}
+
 // storeInfoChannel is the channel description included in info results
 type storeInfoChannel struct {
 	Architecture string    `json:"architecture"`
 	Name         string    `json:"name"`
 	Risk         string    `json:"risk"`
 	Track        string    `json:"track"`
+	Branch       string    `json:"branch"`
 	ReleasedAt   time.Time `json:"released-at"`
 }
 
@@ -191,6 +201,30 @@ func infoFromStoreInfo(si *storeInfo) (*snap.Info, error) {
 	return info, nil
 }
 
+// channelMapFromStoreInfo returns the complete channel map of the v2/info
+// result, including all the architectures and branches it lists.
+func channelMapFromStoreInfo(si *storeInfo) []*snap.ChannelSnapInfo {
+	channelMap := make([]*snap.ChannelSnapInfo, 0, len(si.ChannelMap))
+	for _, s := range si.ChannelMap {
+		ch := s.Channel
+		chName := ch.Track + "/" + ch.Risk
+		if ch.Branch != "" {
+			chName += "/" + ch.Branch
+		}
+		channelMap = append(channelMap, &snap.ChannelSnapInfo{
+			Revision:     snap.R(s.Revision),
+			Confinement:  snap.ConfinementType(s.Confinement),
+			Version:      s.Version,
+			Channel:      chName,
+			Epoch:        s.Epoch,
+			Size:         s.Download.Size,
+			ReleasedAt:   ch.ReleasedAt.UTC(),
+			Architecture: ch.Architecture,
+		})
+	}
+	return channelMap
+}
+
 func minimalFromStoreInfo(si *storeInfo) (naming.SnapRef, *channel.Channel, error) {
 	if len(si.ChannelMap) == 0 {
 		// if a snap has no released revisions, it _could_ be returned
@@ -297,6 +331,9 @@ func copyNonZeroFrom(src, dst *storeSnap) {
 	if len(src.Resources) > 0 {
 		dst.Resources = src.Resources
 	}
+	if len(src.Tracks) > 0 {
+		dst.Tracks = src.Tracks
+	}
 	if len(src.IntegrityData) > 0 {
 		dst.IntegrityData = src.IntegrityData
 	}
@@ -371,6 +408,8 @@ func infoFromStoreSnap(d *storeSnap) (*snap.Info, error) {
 
 	addCategories(info, d.Categories)
 
+	addTrackInfos(info, d.Tracks)
+
 	if err := addIntegrityData(info, d.IntegrityData); err != nil {
 		return nil, err
 	}
@@ -378,6 +417,22 @@ func infoFromStoreSnap(d *storeSnap) (*snap.Info, error) {
 	return info, nil
 }
 
+func addTrackInfos(info *snap.Info, tracks []storeTrack) {
+	for _, t := range tracks {
+		if t.EndOfLife.IsZero() && t.Successor == "" {
+			continue
+		}
+		if info.TrackInfos == nil {
+			info.TrackInfos = make(map[string]*snap.TrackInfo, len(tracks))
+		}
+		trackInfo := &snap.TrackInfo{Successor: t.Successor}
+		if !t.EndOfLife.IsZero() {
+			trackInfo.EndOfLife = t.EndOfLife.UTC()
+		}
+		info.TrackInfos[t.Name] = trackInfo
+	}
+}
+
 func componentFromStoreResource(r storeResource) (*snap.Component, error) {
 	typeString := strings.TrimPrefix(r.Type, "component/")
 
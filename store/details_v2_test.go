@@ -24,6 +24,7 @@ import (
 	"encoding/json"
 	"reflect"
 	"strings"
+	"time"
 
 	. "gopkg.in/check.v1"
 
@@ -184,6 +185,11 @@ const (
      {"type": "screenshot", "url": "https://dashboard.snapcraft.io/site_media/appmedia/2018/01/Thingy_01.png"},
      {"type": "screenshot", "url": "https://dashboard.snapcraft.io/site_media/appmedia/2018/01/Thingy_02.png", "width": 600, "height": 200}
   ],
+  "tracks": [
+     {"name": "latest"},
+     {"name": "9", "end-of-life": "2019-04-30T00:00:00+02:00", "successor": "10"},
+     {"name": "10", "end-of-life": "2021-04-30T00:00:00Z"}
+  ],
   "integrity": [
     {
       "type": "dm-verity",
@@ -377,6 +383,15 @@ func (s *detailsV2Suite) TestInfoFromStoreSnap(c *C) {
 		},
 		StoreURL:       "https://snapcraft.io/thingy",
 		SnapProvenance: "prov",
+		TrackInfos: map[string]*snap.TrackInfo{
+			"9": {
+				EndOfLife: time.Date(2019, 4, 29, 22, 0, 0, 0, time.UTC),
+				Successor: "10",
+			},
+			"10": {
+				EndOfLife: time.Date(2021, 4, 30, 0, 0, 0, 0, time.UTC),
+			},
+		},
 		// empty
 		BadInterfaces:   map[string]string{},
 		SystemUsernames: map[string]*snap.SystemUsernameInfo{},
@@ -456,8 +471,9 @@ func (s *detailsV2Suite) TestInfoFromStoreSnap(c *C) {
 		"LicenseVersion",   // XXX go away?
 		"Broken",
 		"MustBuy",
-		"Channels", // handled at a different level (see TestInfo)
-		"Tracks",   // handled at a different level (see TestInfo)
+		"Channels",   // handled at a different level (see TestInfo)
+		"Tracks",     // handled at a different level (see TestInfo)
+		"ChannelMap", // handled at a different level (see TestInfo)
 		"Layout",
 		"SideInfo.Channel",
 		"LegacyWebsite",
@@ -563,6 +579,12 @@ func fillStruct(a any, c *C) {
 				Name:     "some-component",
 				Revision: 1,
 			}}
+		case []storeTrack:
+			x = []storeTrack{{
+				Name:      "foo",
+				EndOfLife: time.Date(2021, 4, 30, 0, 0, 0, 0, time.UTC),
+				Successor: "bar",
+			}}
 		case []storeIntegrity:
 			x = []storeIntegrity{{
 				Type:          "dm-verity",
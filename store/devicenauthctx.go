@@ -51,6 +51,9 @@ type DeviceAndAuthContext interface {
 
 	// WithSnapStoreDelta returns whether snap store delta format experimental flag is set or not.
 	WithSnapStoreDelta() bool
+
+	// WithZstdStoreDelta returns whether zstd delta format experimental flag is set or not.
+	WithZstdStoreDelta() bool
 }
 
 // DeviceSessionRequestParams gathers the assertions and information to be sent to request a device session.
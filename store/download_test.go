@@ -30,6 +30,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -352,6 +353,61 @@ func (s *downloadSuite) TestActualDownloadResume(c *C) {
 	c.Check(n, Equals, 1)
 }
 
+func (s *downloadSuite) TestActualDownloadResumeContentRange(c *C) {
+	n := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n++
+		c.Check(r.Header.Get("Range"), Equals, "bytes=5-")
+		w.Header().Set("Content-Range", "bytes 5-8/9")
+		w.WriteHeader(206)
+		io.WriteString(w, "data")
+	}))
+	c.Assert(mockServer, NotNil)
+	defer mockServer.Close()
+
+	theStore := store.New(&store.Config{}, nil)
+	buf := NewSillyBufferString("some ")
+	h := crypto.SHA3_384.New()
+	h.Write([]byte("some data"))
+	sha3 := fmt.Sprintf("%x", h.Sum(nil))
+	err := store.Download(context.TODO(), "foo", sha3, mockServer.URL, nil, theStore, buf, int64(len("some ")), nil, nil)
+	c.Check(err, IsNil)
+	c.Check(buf.String(), Equals, "some data")
+	c.Check(n, Equals, 1)
+}
+
+func (s *downloadSuite) TestActualDownloadResumeUnexpectedContentRange(c *C) {
+	n := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n++
+		switch n {
+		case 1:
+			// content from an offset that is not the requested one
+			c.Check(r.Header.Get("Range"), Equals, "bytes=5-")
+			w.Header().Set("Content-Range", "bytes 2-8/9")
+			w.WriteHeader(206)
+			io.WriteString(w, "me data")
+		case 2:
+			c.Check(r.Header.Get("Range"), Equals, "")
+			io.WriteString(w, "some data")
+		default:
+			c.Fatal("only two requests expected")
+		}
+	}))
+	c.Assert(mockServer, NotNil)
+	defer mockServer.Close()
+
+	theStore := store.New(&store.Config{}, nil)
+	buf := NewSillyBufferString("some ")
+	h := crypto.SHA3_384.New()
+	h.Write([]byte("some data"))
+	sha3 := fmt.Sprintf("%x", h.Sum(nil))
+	err := store.Download(context.TODO(), "foo", sha3, mockServer.URL, nil, theStore, buf, int64(len("some ")), nil, nil)
+	c.Check(err, IsNil)
+	c.Check(buf.String(), Equals, "some data")
+	c.Check(n, Equals, 2)
+}
+
 func (s *downloadSuite) TestActualDownloadServerNoResumeHandeled(c *C) {
 	n := 0
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -498,6 +554,116 @@ func (s *downloadSuite) TestActualDownloadRateLimited(c *C) {
 	c.Check(ratelimitReaderUsed, Equals, true)
 }
 
+func (s *downloadSuite) TestActualDownloadTotalRateLimited(c *C) {
+	var buckets []*ratelimit.Bucket
+	restore := store.MockRatelimitReader(func(r io.Reader, bucket *ratelimit.Bucket) io.Reader {
+		buckets = append(buckets, bucket)
+		return r
+	})
+	defer restore()
+
+	canary := "downloaded data"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, canary)
+	}))
+	defer ts.Close()
+
+	theStore := store.New(&store.Config{}, nil)
+	for _, limit := range []int64{1000, 1000, 2000} {
+		var buf SillyBuffer
+		err := store.Download(context.TODO(), "example-name", "", ts.URL, nil, theStore, &buf, 0, nil, &store.DownloadOptions{TotalRateLimit: limit})
+		c.Assert(err, IsNil)
+		c.Check(buf.String(), Equals, canary)
+	}
+	c.Assert(buckets, HasLen, 3)
+	// downloads with the same limit share the bandwidth
+	c.Check(buckets[0], Equals, buckets[1])
+	c.Check(buckets[0].Rate(), Equals, float64(1000))
+	c.Check(buckets[2], Not(Equals), buckets[0])
+	c.Check(buckets[2].Rate(), Equals, float64(2000))
+}
+
+func (s *downloadSuite) TestActualDownloadRateLimitedBoth(c *C) {
+	var buckets []*ratelimit.Bucket
+	restore := store.MockRatelimitReader(func(r io.Reader, bucket *ratelimit.Bucket) io.Reader {
+		buckets = append(buckets, bucket)
+		return r
+	})
+	defer restore()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "downloaded data")
+	}))
+	defer ts.Close()
+
+	theStore := store.New(&store.Config{}, nil)
+	var buf SillyBuffer
+	err := store.Download(context.TODO(), "example-name", "", ts.URL, nil, theStore, &buf, 0, nil, &store.DownloadOptions{RateLimit: 500, TotalRateLimit: 1000})
+	c.Assert(err, IsNil)
+	c.Assert(buckets, HasLen, 2)
+	c.Check(buckets[0].Rate(), Equals, float64(500))
+	c.Check(buckets[1].Rate(), Equals, float64(1000))
+}
+
+func (s *downloadSuite) TestActualDownloadDynamicRateLimit(c *C) {
+	var buckets []*ratelimit.Bucket
+	restore := store.MockRatelimitReader(func(r io.Reader, bucket *ratelimit.Bucket) io.Reader {
+		buckets = append(buckets, bucket)
+		return r
+	})
+	defer restore()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "downloaded data")
+	}))
+	defer ts.Close()
+
+	theStore := store.New(&store.Config{}, nil)
+	var buf SillyBuffer
+	limit := store.NewDynamicRateLimit(1000)
+	// the dynamic limit takes precedence
+	err := store.Download(context.TODO(), "example-name", "", ts.URL, nil, theStore, &buf, 0, nil, &store.DownloadOptions{RateLimit: 500, DynamicRateLimit: limit})
+	c.Assert(err, IsNil)
+	c.Check(buf.String(), Equals, "downloaded data")
+	c.Assert(buckets, HasLen, 1)
+	c.Check(buckets[0].Rate(), Equals, float64(1000))
+}
+
+func (s *downloadSuite) TestDynamicRateLimitReader(c *C) {
+	var rates []float64
+	restore := store.MockRatelimitReader(func(r io.Reader, bucket *ratelimit.Bucket) io.Reader {
+		rates = append(rates, bucket.Rate())
+		return r
+	})
+	defer restore()
+
+	limit := store.NewDynamicRateLimit(1000)
+	c.Check(limit.Rate(), Equals, int64(1000))
+	r := store.NewDynamicRateLimitReader(strings.NewReader("0123456789"), limit)
+	p := make([]byte, 2)
+
+	read := func() string {
+		n, err := r.Read(p)
+		c.Assert(err, IsNil)
+		return string(p[:n])
+	}
+
+	c.Check(read(), Equals, "01")
+	c.Check(read(), Equals, "23")
+	c.Check(rates, DeepEquals, []float64{1000})
+
+	// lifting the limit
+	limit.Set(0)
+	c.Check(read(), Equals, "45")
+	c.Check(rates, DeepEquals, []float64{1000})
+
+	// changes apply to the next read
+	limit.Set(2000)
+	c.Check(read(), Equals, "67")
+	c.Check(read(), Equals, "89")
+	c.Check(rates, DeepEquals, []float64{1000, 2000})
+}
+
 func (s *downloadSuite) TestActualDownloadIcon(c *C) {
 	n := 0
 	const existingEtag = ""
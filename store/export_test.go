@@ -262,6 +262,10 @@ func MockRatelimitReader(f func(r io.Reader, bucket *ratelimit.Bucket) io.Reader
 	}
 }
 
+func NewDynamicRateLimitReader(r io.Reader, limit *DynamicRateLimit) io.Reader {
+	return &dynamicRateLimitReader{r: r, limit: limit}
+}
+
 func MockRequestTimeout(d time.Duration) (restore func()) {
 	old := requestTimeout
 	requestTimeout = d
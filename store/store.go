@@ -36,6 +36,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/juju/ratelimit"
 	"gopkg.in/retry.v1"
 
 	"github.com/snapcore/snapd/arch"
@@ -44,6 +45,7 @@ import (
 	"github.com/snapcore/snapd/httputil"
 	"github.com/snapcore/snapd/jsonutil"
 	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/metrics"
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/overlord/auth"
 	"github.com/snapcore/snapd/release"
@@ -164,6 +166,9 @@ type Store struct {
 
 	mu                sync.Mutex
 	suggestedCurrency string
+	// token bucket shaping the downloads with a total rate limit
+	downloadBucket     *ratelimit.Bucket
+	downloadBucketRate int64
 
 	cacher downloadCache
 
@@ -318,7 +323,7 @@ func init() {
 	defaultConfig.DetailFields = jsonutil.StructFields((*snapDetails)(nil), "snap_yaml_raw", "integrity")
 	defaultConfig.InfoFields = jsonutil.StructFields((*storeSnap)(nil), "snap-yaml", "integrity")
 	defaultConfig.FindFields = append(jsonutil.StructFields((*storeSnap)(nil),
-		"architectures", "created-at", "epoch", "name", "snap-id", "snap-yaml", "resources", "integrity"),
+		"architectures", "created-at", "epoch", "name", "snap-id", "snap-yaml", "resources", "integrity", "tracks"),
 		"channel")
 }
 
@@ -419,12 +424,17 @@ func New(cfg *Config, dauthCtx DeviceAndAuthContext) *Store {
 
 func (s *Store) supportedDeltaFormats() []string {
 	withSnapStoreDelta := false
+	withZstdStoreDelta := false
 	if s.dauthCtx != nil {
 		withSnapStoreDelta = s.dauthCtx.WithSnapStoreDelta()
+		withZstdStoreDelta = s.dauthCtx.WithZstdStoreDelta()
 	}
 
 	return squashfsSupportedDeltaFormats(
-		squashfs.DeltaFormatOpts{WithSnapDeltaFormat: withSnapStoreDelta})
+		squashfs.DeltaFormatOpts{
+			WithSnapDeltaFormat: withSnapStoreDelta,
+			WithZstdFormat:      withZstdStoreDelta,
+		})
 }
 
 // SetAssertionMaxFormats allows to change the assertion max formats to send
@@ -639,15 +649,18 @@ type alias struct {
 }
 
 type catalogItem struct {
-	Name    string   `json:"package_name"`
-	Version string   `json:"version"`
-	Summary string   `json:"summary"`
-	Aliases []alias  `json:"aliases"`
-	Apps    []string `json:"apps"`
+	Name      string   `json:"package_name"`
+	Version   string   `json:"version"`
+	Summary   string   `json:"summary"`
+	Aliases   []alias  `json:"aliases"`
+	Apps      []string `json:"apps"`
+	Libraries []string `json:"libraries"`
+	ManPages  []string `json:"manpages"`
 }
 
 type SnapAdder interface {
 	AddSnap(snapName, version, summary string, commands []string) error
+	AddSnapProvides(snapName, version string, libraries, manPages []string) error
 }
 
 func decodeCatalog(resp *http.Response, names io.Writer, db SnapAdder) error {
@@ -675,6 +688,13 @@ func decodeCatalog(resp *http.Response, names io.Writer, db SnapAdder) error {
 			continue
 		}
 		fmt.Fprintln(names, v.Name)
+
+		if len(v.Libraries) > 0 || len(v.ManPages) > 0 {
+			if err := db.AddSnapProvides(v.Name, v.Version, v.Libraries, v.ManPages); err != nil {
+				return err
+			}
+		}
+
 		if len(v.Apps) == 0 {
 			continue
 		}
@@ -721,6 +741,16 @@ func (s *Store) retryRequestDecodeJSON(ctx context.Context, reqOptions *requestO
 	}, defaultRetryStrategy)
 }
 
+// requestDurations tracks the duration in seconds of the requests made to
+// the store API.
+var requestDurations = metrics.NewHistogram(0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30)
+
+// RequestDurations returns the histogram of the durations in seconds of
+// the requests made to the store API.
+func RequestDurations() *metrics.Histogram {
+	return requestDurations
+}
+
 // doRequest does an authenticated request to the store handling a potential macaroon refresh required if needed
 func (s *Store) doRequest(ctx context.Context, client *http.Client, reqOptions *requestOptions, user *auth.UserState) (*http.Response, error) {
 	authRefreshes := 0
@@ -733,7 +763,9 @@ func (s *Store) doRequest(ctx context.Context, client *http.Client, reqOptions *
 			req = req.WithContext(ctx)
 		}
 
+		start := time.Now()
 		resp, err := client.Do(req)
+		requestDurations.Observe(time.Since(start).Seconds())
 		if err != nil {
 			return nil, err
 		}
@@ -988,21 +1020,38 @@ func mustBuy(paid bool, bought bool) bool {
 // A SnapSpec describes a single snap wanted from SnapInfo
 type SnapSpec struct {
 	Name string
+	// ChannelMap requests the complete channel map of the snap, for all
+	// architectures, to be set in the ChannelMap field of the result.
+	ChannelMap bool
 }
 
 // SnapInfo returns the snap.Info for the store-hosted snap matching the given spec, or an error.
 func (s *Store) SnapInfo(ctx context.Context, snapSpec SnapSpec, user *auth.UserState) (*snap.Info, error) {
 	fields := strings.Join(s.infoFields, ",")
 
-	si, resp, err := s.snapInfo(ctx, snapSpec.Name, fields, user)
+	si, resp, err := s.snapInfo(ctx, snapSpec.Name, fields, snapSpec.ChannelMap, user)
 	if err != nil {
 		return nil, err
 	}
 
+	var channelMap []*snap.ChannelSnapInfo
+	if snapSpec.ChannelMap {
+		channelMap = channelMapFromStoreInfo(si)
+		// the rest of the info is about this architecture only
+		thisArch := make([]*storeInfoChannelSnap, 0, len(si.ChannelMap))
+		for _, chSnap := range si.ChannelMap {
+			if chSnap.Channel.Architecture == s.architecture {
+				thisArch = append(thisArch, chSnap)
+			}
+		}
+		si.ChannelMap = thisArch
+	}
+
 	info, err := infoFromStoreInfo(si)
 	if err != nil {
 		return nil, err
 	}
+	info.ChannelMap = channelMap
 
 	err = s.decorateOrders([]*snap.Info{info}, user)
 	if err != nil {
@@ -1014,10 +1063,13 @@ func (s *Store) SnapInfo(ctx context.Context, snapSpec SnapSpec, user *auth.User
 	return info, nil
 }
 
-func (s *Store) snapInfo(ctx context.Context, snapName string, fields string, user *auth.UserState) (*storeInfo, *http.Response, error) {
+func (s *Store) snapInfo(ctx context.Context, snapName string, fields string, allArchitectures bool, user *auth.UserState) (*storeInfo, *http.Response, error) {
 	query := url.Values{}
 	query.Set("fields", fields)
-	query.Set("architecture", s.architecture)
+	// without an architecture the channel map covers all of them
+	if !allArchitectures {
+		query.Set("architecture", s.architecture)
+	}
 
 	u, err := s.endpointURL(path.Join(snapInfoEndpPath, snapName), query)
 	if err != nil {
@@ -1055,7 +1107,7 @@ func (s *Store) SnapExists(ctx context.Context, snapSpec SnapSpec, user *auth.Us
 	// request the minimal amount information
 	fields := "channel-map"
 
-	si, _, err := s.snapInfo(ctx, snapSpec.Name, fields, user)
+	si, _, err := s.snapInfo(ctx, snapSpec.Name, fields, false, user)
 	if err != nil {
 		return nil, nil, err
 	}
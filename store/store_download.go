@@ -34,6 +34,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/juju/ratelimit"
@@ -110,7 +111,13 @@ func (e HashError) Error() string {
 }
 
 type DownloadOptions struct {
-	RateLimit           int64
+	RateLimit int64
+	// DynamicRateLimit, if set, is used instead of RateLimit and changes
+	// to its rate are applied to the download while it is in progress.
+	DynamicRateLimit *DynamicRateLimit
+	// TotalRateLimit caps, in bytes per second, the bandwidth used by all
+	// the downloads of the store that set it.
+	TotalRateLimit      int64
 	Scheduled           bool
 	LeavePartialOnError bool
 }
@@ -502,6 +509,65 @@ func (w *TransferSpeedMonitoringWriter) Err() error {
 
 var ratelimitReader = ratelimit.Reader
 
+// DynamicRateLimit is a download rate limit, in bytes per second, that can be
+// changed while the downloads using it are in progress. A rate of 0 means no
+// limit.
+type DynamicRateLimit struct {
+	rate int64
+}
+
+// NewDynamicRateLimit returns a DynamicRateLimit with the given initial rate.
+func NewDynamicRateLimit(rate int64) *DynamicRateLimit {
+	return &DynamicRateLimit{rate: rate}
+}
+
+// Set changes the rate limit, it is safe to call while downloads are in
+// progress.
+func (l *DynamicRateLimit) Set(rate int64) {
+	atomic.StoreInt64(&l.rate, rate)
+}
+
+// Rate returns the current rate limit.
+func (l *DynamicRateLimit) Rate() int64 {
+	return atomic.LoadInt64(&l.rate)
+}
+
+// dynamicRateLimitReader limits the reads from the underlying reader to the
+// current rate of a DynamicRateLimit.
+type dynamicRateLimitReader struct {
+	r     io.Reader
+	limit *DynamicRateLimit
+
+	rate    int64
+	limited io.Reader
+}
+
+func (dr *dynamicRateLimitReader) Read(p []byte) (int, error) {
+	if rate := dr.limit.Rate(); dr.limited == nil || rate != dr.rate {
+		if dr.limited != nil {
+			logger.Debugf("Download rate limit changed from %d to %d B/s.", dr.rate, rate)
+		}
+		dr.rate = rate
+		dr.limited = dr.r
+		if rate > 0 {
+			dr.limited = ratelimitReader(dr.r, ratelimit.NewBucketWithRate(float64(rate), 2*rate))
+		}
+	}
+	return dr.limited.Read(p)
+}
+
+// sharedDownloadBucket returns the token bucket shared by the downloads
+// that are limited to the given total rate.
+func (s *Store) sharedDownloadBucket(rate int64) *ratelimit.Bucket {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.downloadBucket == nil || s.downloadBucketRate != rate {
+		s.downloadBucket = ratelimit.NewBucketWithRate(float64(rate), 2*rate)
+		s.downloadBucketRate = rate
+	}
+	return s.downloadBucket
+}
+
 var download = downloadImpl
 
 // download writes an http.Request showing a progress.Meter
@@ -575,12 +641,26 @@ func downloadImpl(ctx context.Context, name, sha3_384, downloadURL string, user
 		}
 		if resume > 0 && resp.StatusCode != 206 {
 			logger.Debugf("server does not support resume")
-			if _, err := w.Seek(0, io.SeekStart); err != nil {
+			if err := rewindDownload(w); err != nil {
 				return err
 			}
 			h = crypto.SHA3_384.New()
 			resume = 0
 		}
+		if resume > 0 {
+			if start, ok := contentRangeStart(resp.Header.Get("Content-Range")); ok && start != resume {
+				// the content cannot be appended to what we have,
+				// start again from scratch
+				resp.Body.Close()
+				logger.Noticef("Cannot resume download of %s at %d, server sent content from %d, restarting.", name, resume, start)
+				if err := rewindDownload(w); err != nil {
+					return err
+				}
+				resume = 0
+				finalErr = fmt.Errorf("cannot resume download of %s: unexpected content range start %d", name, start)
+				continue
+			}
+		}
 		if httputil.ShouldRetryHttpResponse(attempt, resp) {
 			resp.Body.Close()
 			continue
@@ -611,9 +691,14 @@ func downloadImpl(ctx context.Context, name, sha3_384, downloadURL string, user
 		mw := io.MultiWriter(w, h, pbar, tc)
 		var limiter io.Reader
 		limiter = resp.Body
-		if limit := dlOpts.RateLimit; limit > 0 {
+		if dlOpts.DynamicRateLimit != nil {
+			limiter = &dynamicRateLimitReader{r: limiter, limit: dlOpts.DynamicRateLimit}
+		} else if limit := dlOpts.RateLimit; limit > 0 {
 			bucket := ratelimit.NewBucketWithRate(float64(limit), 2*limit)
-			limiter = ratelimitReader(resp.Body, bucket)
+			limiter = ratelimitReader(limiter, bucket)
+		}
+		if limit := dlOpts.TotalRateLimit; limit > 0 {
+			limiter = ratelimitReader(limiter, s.sharedDownloadBucket(limit))
 		}
 
 		stopMonitorCh := tc.Monitor()
@@ -666,6 +751,36 @@ func downloadImpl(ctx context.Context, name, sha3_384, downloadURL string, user
 	return finalErr
 }
 
+// rewindDownload prepares w for downloading again from the start, truncating
+// it when possible so that no stale data is left behind.
+func rewindDownload(w io.ReadWriteSeeker) error {
+	if _, err := w.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if t, ok := w.(interface{ Truncate(size int64) error }); ok {
+		return t.Truncate(0)
+	}
+	return nil
+}
+
+// contentRangeStart returns the first byte position of a Content-Range
+// header of the form "bytes <first>-<last>/<complete-length>".
+func contentRangeStart(contentRange string) (start int64, ok bool) {
+	rng := strings.TrimPrefix(contentRange, "bytes ")
+	if rng == contentRange {
+		return 0, false
+	}
+	idx := strings.IndexByte(rng, '-')
+	if idx < 0 {
+		return 0, false
+	}
+	start, err := strconv.ParseInt(rng[:idx], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return start, true
+}
+
 type ReadWriteSeekTruncater interface {
 	io.Reader
 	io.Writer
@@ -643,6 +643,18 @@ var downloadDeltaTests = []struct {
 	supportedFormats: []string{"xdelta3", "snap-1-1-xdelta3"},
 	expectedURL:      "xdelta3-delta-url",
 	expectError:      false,
+}, {
+	// zstd is preferred over plain xdelta3
+	info: snap.DownloadInfo{
+		Sha3_384: "sha3",
+		Deltas: []snap.DeltaInfo{
+			{DownloadURL: "xdelta3-delta-url", Format: "xdelta3", FromRevision: 24, ToRevision: 26},
+			{DownloadURL: "zstd-delta-url", Format: "zstd", FromRevision: 24, ToRevision: 26},
+		},
+	},
+	supportedFormats: []string{"snap-1-1-xdelta3", "zstd", "xdelta3"},
+	expectedURL:      "zstd-delta-url",
+	expectError:      false,
 }}
 
 func (s *storeDownloadSuite) TestDownloadDelta(c *C) {
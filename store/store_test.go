@@ -325,6 +325,10 @@ func (sc *testDauthContext) WithSnapStoreDelta() bool {
 	return true
 }
 
+func (sc *testDauthContext) WithZstdStoreDelta() bool {
+	return true
+}
+
 func (dac *testDauthContext) CloudInfo() (*auth.CloudInfo, error) {
 	return dac.cloudInfo, nil
 }
@@ -483,6 +487,25 @@ func (s *storeTestSuite) TestDoRequestSetsAuth(c *C) {
 	c.Check(string(responseData), Equals, "response-data")
 }
 
+func (s *storeTestSuite) TestDoRequestRecordsDuration(c *C) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "response-data")
+	}))
+	c.Assert(mockServer, NotNil)
+	defer mockServer.Close()
+
+	sto := store.New(&store.Config{}, nil)
+
+	endpoint, _ := url.Parse(mockServer.URL)
+	reqOptions := store.NewRequestOptions("GET", endpoint)
+
+	before := store.RequestDurations().Count()
+	response, err := sto.DoRequest(s.ctx, sto.Client(), reqOptions, nil)
+	c.Assert(err, IsNil)
+	response.Body.Close()
+	c.Check(store.RequestDurations().Count(), Equals, before+1)
+}
+
 func (s *storeTestSuite) TestDoRequestDoesNotSetAuthForLocalOnlyUser(c *C) {
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		c.Check(r.UserAgent(), Equals, userAgent)
@@ -1703,6 +1726,71 @@ func (s *storeTestSuite) TestInfoMoreChannels(c *C) {
 	c.Check(result.Tracks, DeepEquals, []string{"latest", "1.11", "1.10", "1.9", "1.8", "1.7", "1.6"})
 }
 
+func (s *storeTestSuite) TestInfoChannelMapAllArchitectures(c *C) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertRequest(c, r, "GET", infoPathPattern)
+		// no architecture so that the channel map covers all of them
+		c.Check(r.URL.Query().Has("architecture"), Equals, false)
+		io.WriteString(w, `{"channel-map": [
+{"channel":{"architecture":"amd64","name":"stable",     "released-at":"2018-12-17T09:17:16.288554+00:00","risk":"stable","track":"latest"},"revision":10,"version":"1.0","download":{"size":1024}},
+{"channel":{"architecture":"arm64","name":"stable",     "released-at":"2018-12-17T09:18:16.288554+00:00","risk":"stable","track":"latest"},"revision":11,"version":"1.0","download":{"size":2048}},
+{"channel":{"architecture":"amd64","name":"edge/fix-1", "released-at":"2018-11-06T00:46:03.348730+00:00","risk":"edge",  "track":"latest","branch":"fix-1"},"revision":12,"version":"1.1"},
+{"channel":{"architecture":"arm64","name":"2.0/beta",   "released-at":"2019-01-02T03:04:05+00:00",       "risk":"beta",  "track":"2.0"},"revision":13,"version":"2.0"}
+]}`)
+	}))
+
+	c.Assert(mockServer, NotNil)
+	defer mockServer.Close()
+
+	mockServerURL, _ := url.Parse(mockServer.URL)
+	cfg := store.Config{
+		StoreBaseURL: mockServerURL,
+		Architecture: "amd64",
+	}
+	dauthCtx := &testDauthContext{c: c, device: s.device}
+	sto := store.New(&cfg, dauthCtx)
+
+	result, err := sto.SnapInfo(s.ctx, store.SnapSpec{Name: "eh", ChannelMap: true}, nil)
+	c.Assert(err, IsNil)
+	c.Check(result.ChannelMap, DeepEquals, []*snap.ChannelSnapInfo{
+		{Architecture: "amd64", Channel: "latest/stable", Revision: snap.R(10), Version: "1.0", Size: 1024, ReleasedAt: time.Date(2018, 12, 17, 9, 17, 16, 288554000, time.UTC)},
+		{Architecture: "arm64", Channel: "latest/stable", Revision: snap.R(11), Version: "1.0", Size: 2048, ReleasedAt: time.Date(2018, 12, 17, 9, 18, 16, 288554000, time.UTC)},
+		{Architecture: "amd64", Channel: "latest/edge/fix-1", Revision: snap.R(12), Version: "1.1", ReleasedAt: time.Date(2018, 11, 6, 0, 46, 3, 348730000, time.UTC)},
+		{Architecture: "arm64", Channel: "2.0/beta", Revision: snap.R(13), Version: "2.0", ReleasedAt: time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)},
+	})
+	// the rest is about the store architecture only
+	c.Check(result.Revision, Equals, snap.R(10))
+	c.Check(result.Tracks, DeepEquals, []string{"latest"})
+	c.Check(result.Channels, HasLen, 2)
+	c.Check(result.Channels["latest/stable"].Revision, Equals, snap.R(10))
+	c.Check(result.Channels["latest/edge"].Revision, Equals, snap.R(12))
+}
+
+func (s *storeTestSuite) TestInfoNoChannelMapByDefault(c *C) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertRequest(c, r, "GET", infoPathPattern)
+		c.Check(r.URL.Query().Get("architecture"), Equals, "amd64")
+		io.WriteString(w, `{"channel-map": [
+{"channel":{"architecture":"amd64","name":"stable","released-at":"2018-12-17T09:17:16.288554+00:00","risk":"stable","track":"latest"},"revision":10}
+]}`)
+	}))
+
+	c.Assert(mockServer, NotNil)
+	defer mockServer.Close()
+
+	mockServerURL, _ := url.Parse(mockServer.URL)
+	cfg := store.Config{
+		StoreBaseURL: mockServerURL,
+		Architecture: "amd64",
+	}
+	dauthCtx := &testDauthContext{c: c, device: s.device}
+	sto := store.New(&cfg, dauthCtx)
+
+	result, err := sto.SnapInfo(s.ctx, store.SnapSpec{Name: "eh"}, nil)
+	c.Assert(err, IsNil)
+	c.Check(result.ChannelMap, IsNil)
+}
+
 func (s *storeTestSuite) TestInfoNonDefaults(c *C) {
 	restore := release.MockOnClassic(true)
 	defer restore()
@@ -2491,6 +2579,8 @@ const mockNamesJSON = `
       {
         "aliases": [{"name": "meh", "target": "foo"}],
         "apps": ["foo"],
+        "libraries": ["libfoo.so.3"],
+        "manpages": ["foo.1"],
         "package_name": "foo",
         "version": "1.0"
       }
@@ -2562,6 +2652,13 @@ func (s *storeTestSuite) testSnapCommands(c *C, onClassic bool) {
 		"potato":  `[{"snap":"bar","version":"2.0"}]`,
 		"meh":     `[{"snap":"bar","version":"2.0"},{"snap":"foo","version":"1.0"}]`,
 	})
+
+	libs, err := advisor.FindLibrary("libfoo.so.3")
+	c.Assert(err, IsNil)
+	c.Check(libs, DeepEquals, []advisor.Package{{Snap: "foo", Version: "1.0"}})
+	manPages, err := advisor.FindManPage("foo.1")
+	c.Assert(err, IsNil)
+	c.Check(manPages, DeepEquals, []advisor.Package{{Snap: "foo", Version: "1.0"}})
 	c.Check(n, Equals, 1)
 }
 
@@ -20,10 +20,14 @@
 package systemd
 
 import (
+	"bytes"
+	"encoding/binary"
 	"fmt"
 	"log/syslog"
 	"net"
 	"os"
+	"sort"
+	"strings"
 
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/gadget/quantity"
@@ -96,3 +100,45 @@ func NewJournalStreamFile(params JournalStreamFileParams) (*os.File, error) {
 	}
 	return conn.File()
 }
+
+// SendJournalFields sends a single structured entry with the given fields to
+// the journal using its native protocol. The semantics is that of
+// sd_journal_send(3), field names must be valid journal field names, i.e.
+// consist of upper case letters, digits and underscores.
+func SendJournalFields(fields map[string]string) error {
+	if len(fields) == 0 {
+		return fmt.Errorf("internal error: cannot send a journal entry without fields")
+	}
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	for _, name := range names {
+		value := fields[name]
+		if !strings.Contains(value, "\n") {
+			fmt.Fprintf(&buf, "%s=%s\n", name, value)
+			continue
+		}
+		// values with newlines are sent as the field name followed by
+		// the little endian 64bit size of the value and the value itself
+		buf.WriteString(name)
+		buf.WriteByte('\n')
+		binary.Write(&buf, binary.LittleEndian, uint64(len(value)))
+		buf.WriteString(value)
+		buf.WriteByte('\n')
+	}
+
+	journalPath := fmt.Sprintf("%s/journal/socket", dirs.SnapSystemdRunDir)
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: journalPath, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Write(buf.Bytes())
+	return err
+}
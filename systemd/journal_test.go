@@ -120,3 +120,32 @@ func (j *journalTestSuite) TestStreamFileHeader(c *C) {
 func (j *journalTestSuite) TestNamespaceStream(c *C) {
 	j.testStreamFileHeader(c, j.journalNamespaceDir, "test")
 }
+
+func (j *journalTestSuite) TestSendJournalFields(c *C) {
+	socketPath := path.Join(j.journalDir, "socket")
+	listener, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socketPath, Net: "unixgram"})
+	c.Assert(err, IsNil)
+	defer listener.Close()
+
+	err = SendJournalFields(map[string]string{
+		"MESSAGE":           "hello",
+		"SYSLOG_IDENTIFIER": "snap-exec",
+		"MULTI_LINE":        "foo\nbar",
+	})
+	c.Assert(err, IsNil)
+
+	buf := make([]byte, 1024)
+	n, err := listener.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "MESSAGE=hello\n"+
+		"MULTI_LINE\n\x07\x00\x00\x00\x00\x00\x00\x00foo\nbar\n"+
+		"SYSLOG_IDENTIFIER=snap-exec\n")
+}
+
+func (j *journalTestSuite) TestSendJournalFieldsErrors(c *C) {
+	err := SendJournalFields(nil)
+	c.Assert(err, ErrorMatches, "internal error: cannot send a journal entry without fields")
+
+	err = SendJournalFields(map[string]string{"MESSAGE": "hello"})
+	c.Assert(err, ErrorMatches, ".*/journal/socket: connect: no such file or directory")
+}
@@ -313,11 +313,11 @@ func EnsureAtLeast(requiredVersion int) error {
 var osutilStreamCommand = osutil.StreamCommand
 
 // jctl calls journalctl to get the JSON logs of the given services.
-var jctl = func(svcs []string, n int, follow, namespaces bool) (io.ReadCloser, error) {
+var jctl = func(svcs, userSvcs []string, uid string, n int, follow, namespaces bool) (io.ReadCloser, error) {
 	// args will need two entries per service, plus a fixed number (give or take
 	// one) for the initial options.
-	args := make([]string, 0, 2*len(svcs)+7)        // We have at most 7 extra arguments
-	args = append(args, "-o", "json", "--no-pager") //   3...
+	args := make([]string, 0, 2*(len(svcs)+len(userSvcs))+7) // We have at most 7 extra arguments
+	args = append(args, "-o", "json", "--no-pager")          //   3...
 	if n < 0 {
 		args = append(args, "--no-tail") // < 2
 	} else {
@@ -330,14 +330,33 @@ var jctl = func(svcs []string, n int, follow, namespaces bool) (io.ReadCloser, e
 		args = append(args, "--namespace=*") // ... + 1 == 7
 	}
 
-	for i := range svcs {
-		args = append(args, "-u", svcs[i]) // this is why 2×
+	if len(userSvcs) == 0 {
+		for i := range svcs {
+			args = append(args, "-u", svcs[i]) // this is why 2×
+		}
+		return osutilStreamCommand("journalctl", args...)
+	}
+
+	// journalctl --user-unit only matches the units of the user running
+	// journalctl, and -u cannot be combined with field matches, so
+	// express all the units as a disjunction of field matches instead
+	for _, svc := range svcs {
+		args = append(args, "_SYSTEMD_UNIT="+svc, "+")
+	}
+	for _, svc := range userSvcs {
+		args = append(args, "_SYSTEMD_USER_UNIT="+svc)
+		if uid != "" {
+			args = append(args, "_UID="+uid)
+		}
+		args = append(args, "+")
 	}
+	// drop the trailing disjunction
+	args = args[:len(args)-1]
 
 	return osutilStreamCommand("journalctl", args...)
 }
 
-func MockJournalctl(f func(svcs []string, n int, follow, namespaces bool) (io.ReadCloser, error)) func() {
+func MockJournalctl(f func(svcs, userSvcs []string, uid string, n int, follow, namespaces bool) (io.ReadCloser, error)) func() {
 	oldJctl := jctl
 	jctl = f
 	return func() {
@@ -446,11 +465,13 @@ type Systemd interface {
 	// IsActive checks whether the given service is Active
 	IsActive(service string) (bool, error)
 	// LogReader returns a reader for the given services' log.
+	// The userServices are services of the per-user service managers, their
+	// logs are restricted to the user with the given uid unless it is empty.
 	// If follow is set to true, the reader returned will follow the log
 	// as it grows.
 	// If namespaces is set to true, the log reader will include journal namespace
 	// logs, and is required to get logs for services which are in journal namespaces.
-	LogReader(services []string, n int, follow, namespaces bool) (io.ReadCloser, error)
+	LogReader(services, userServices []string, uid string, n int, follow, namespaces bool) (io.ReadCloser, error)
 	// ConfigureMountUnitOptions configures several options of the mount unit in-place.
 	ConfigureMountUnitOptions(o *MountUnitOptions, fstype string, startBeforeDrivers bool) error
 	// EnsureMountUnitFile adds/enables/starts a mount unit with options.
@@ -726,8 +747,8 @@ func (s *systemd) StartNoBlock(serviceNames []string) error {
 	return err
 }
 
-func (*systemd) LogReader(serviceNames []string, n int, follow, namespaces bool) (io.ReadCloser, error) {
-	return jctl(serviceNames, n, follow, namespaces)
+func (*systemd) LogReader(serviceNames, userServiceNames []string, uid string, n int, follow, namespaces bool) (io.ReadCloser, error) {
+	return jctl(serviceNames, userServiceNames, uid, n, follow, namespaces)
 }
 
 var statusregex = regexp.MustCompile(`(?m)^(?:(.+?)=(.*)|(.*))?$`)
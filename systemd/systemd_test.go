@@ -76,6 +76,8 @@ type SystemdTestSuite struct {
 	j           int
 	jns         []string
 	jsvcs       [][]string
+	juserSvcs   [][]string
+	juids       []string
 	jouts       [][]byte
 	jerrs       []error
 	jfollows    []bool
@@ -117,6 +119,8 @@ func (s *SystemdTestSuite) SetUpTest(c *C) {
 	s.j = 0
 	s.jns = nil
 	s.jsvcs = nil
+	s.juserSvcs = nil
+	s.juids = nil
 	s.jouts = nil
 	s.jerrs = nil
 	s.jfollows = nil
@@ -164,12 +168,14 @@ func (s *SystemdTestSuite) myRun(args ...string) (out []byte, delay time.Duratio
 	return out, delayReq, err
 }
 
-func (s *SystemdTestSuite) myJctl(svcs []string, n int, follow, namespaces bool) (io.ReadCloser, error) {
+func (s *SystemdTestSuite) myJctl(svcs, userSvcs []string, uid string, n int, follow, namespaces bool) (io.ReadCloser, error) {
 	var err error
 	var out []byte
 
 	s.jns = append(s.jns, strconv.Itoa(n))
 	s.jsvcs = append(s.jsvcs, svcs)
+	s.juserSvcs = append(s.juserSvcs, userSvcs)
+	s.juids = append(s.juids, uid)
 	s.jfollows = append(s.jfollows, follow)
 	s.jnamespaces = append(s.jnamespaces, namespaces)
 
@@ -938,7 +944,7 @@ func (s *SystemdTestSuite) TestKill(c *C) {
 func (s *SystemdTestSuite) TestLogErrJctl(c *C) {
 	s.jerrs = []error{errors.New("mock journalctl error")}
 
-	reader, err := New(SystemMode, s.rep).LogReader([]string{"foo"}, 24, false, false)
+	reader, err := New(SystemMode, s.rep).LogReader([]string{"foo"}, nil, "", 24, false, false)
 	c.Check(err, NotNil)
 	c.Check(reader, IsNil)
 	c.Check(s.jns, DeepEquals, []string{"24"})
@@ -954,7 +960,7 @@ func (s *SystemdTestSuite) TestLogs(c *C) {
 `
 	s.jouts = [][]byte{[]byte(expected)}
 
-	reader, err := New(SystemMode, s.rep).LogReader([]string{"foo"}, 24, false, false)
+	reader, err := New(SystemMode, s.rep).LogReader([]string{"foo"}, nil, "", 24, false, false)
 	c.Check(err, IsNil)
 	logs, err := io.ReadAll(reader)
 	c.Assert(err, IsNil)
@@ -1971,20 +1977,55 @@ func (s *SystemdTestSuite) TestJctl(c *C) {
 		return nil, nil
 	})
 
-	_, err = Jctl([]string{"foo", "bar"}, 10, false, false)
+	_, err = Jctl([]string{"foo", "bar"}, nil, "", 10, false, false)
 	c.Assert(err, IsNil)
 	c.Check(args, DeepEquals, []string{"-o", "json", "--no-pager", "-n", "10", "-u", "foo", "-u", "bar"})
-	_, err = Jctl([]string{"foo", "bar", "baz"}, 99, true, false)
+	_, err = Jctl([]string{"foo", "bar", "baz"}, nil, "", 99, true, false)
 	c.Assert(err, IsNil)
 	c.Check(args, DeepEquals, []string{"-o", "json", "--no-pager", "-n", "99", "-f", "-u", "foo", "-u", "bar", "-u", "baz"})
-	_, err = Jctl([]string{"foo", "bar"}, -1, false, false)
+	_, err = Jctl([]string{"foo", "bar"}, nil, "", -1, false, false)
 	c.Assert(err, IsNil)
 	c.Check(args, DeepEquals, []string{"-o", "json", "--no-pager", "--no-tail", "-u", "foo", "-u", "bar"})
-	_, err = Jctl([]string{"foo", "bar"}, -1, false, true)
+	_, err = Jctl([]string{"foo", "bar"}, nil, "", -1, false, true)
 	c.Assert(err, IsNil)
 	c.Check(args, DeepEquals, []string{"-o", "json", "--no-pager", "--no-tail", "--namespace=*", "-u", "foo", "-u", "bar"})
 }
 
+func (s *SystemdTestSuite) TestJctlUserServices(c *C) {
+	var args []string
+	MockOsutilStreamCommand(func(name string, myargs ...string) (io.ReadCloser, error) {
+		args = myargs
+		return nil, nil
+	})
+
+	_, err := Jctl(nil, []string{"foo"}, "", 10, false, false)
+	c.Assert(err, IsNil)
+	c.Check(args, DeepEquals, []string{"-o", "json", "--no-pager", "-n", "10", "_SYSTEMD_USER_UNIT=foo"})
+
+	_, err = Jctl([]string{"foo", "bar"}, []string{"baz", "quux"}, "", 10, true, true)
+	c.Assert(err, IsNil)
+	c.Check(args, DeepEquals, []string{"-o", "json", "--no-pager", "-n", "10", "-f", "--namespace=*",
+		"_SYSTEMD_UNIT=foo", "+", "_SYSTEMD_UNIT=bar", "+",
+		"_SYSTEMD_USER_UNIT=baz", "+", "_SYSTEMD_USER_UNIT=quux"})
+
+	_, err = Jctl([]string{"foo"}, []string{"baz"}, "1000", -1, false, false)
+	c.Assert(err, IsNil)
+	c.Check(args, DeepEquals, []string{"-o", "json", "--no-pager", "--no-tail",
+		"_SYSTEMD_UNIT=foo", "+", "_SYSTEMD_USER_UNIT=baz", "_UID=1000"})
+}
+
+func (s *SystemdTestSuite) TestUserLogs(c *C) {
+	s.jouts = [][]byte{[]byte(`{"a": 1}` + "\n")}
+
+	reader, err := New(SystemMode, s.rep).LogReader([]string{"foo"}, []string{"bar"}, "1000", 24, true, false)
+	c.Assert(err, IsNil)
+	reader.Close()
+	c.Check(s.jsvcs, DeepEquals, [][]string{{"foo"}})
+	c.Check(s.juserSvcs, DeepEquals, [][]string{{"bar"}})
+	c.Check(s.juids, DeepEquals, []string{"1000"})
+	c.Check(s.jfollows, DeepEquals, []bool{true})
+}
+
 func (s *SystemdTestSuite) TestIsActiveUnderRoot(c *C) {
 	sysErr := &Error{}
 	// manpage states that systemctl returns exit code 3 for inactive
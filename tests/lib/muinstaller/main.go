@@ -21,7 +21,7 @@
 package main
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"flag"
@@ -30,7 +30,6 @@ import (
 	"os/exec"
 	"path/filepath"
 	"sort"
-	"strconv"
 	"strings"
 	"time"
 
@@ -110,77 +109,112 @@ devicesLoop:
 	return devices, nil
 }
 
-func firstVol(volumes map[string]*gadget.Volume) *gadget.Volume {
-	for _, vol := range volumes {
-		return vol
+func sortedVolNames(volumes map[string]*gadget.Volume) []string {
+	volNames := make([]string, 0, len(volumes))
+	for volName := range volumes {
+		volNames = append(volNames, volName)
 	}
-	return nil
+	sort.Strings(volNames)
+	return volNames
+}
+
+// targetDevices maps the gadget volumes to the target devices given on the
+// command line, either as a single device for gadgets with one volume or as
+// a comma separated list of <volume>=<device> pairs.
+func targetDevices(deviceArg string, volumes map[string]*gadget.Volume) (map[string]string, error) {
+	if !strings.Contains(deviceArg, "=") {
+		if len(volumes) != 1 {
+			return nil, fmt.Errorf("gadget defines %v volumes, a device must be given for each of them as <volume>=<device>,...", len(volumes))
+		}
+		if deviceArg == "auto" {
+			deviceArg = waitForDevice()
+		}
+		return map[string]string{sortedVolNames(volumes)[0]: deviceArg}, nil
+	}
+
+	devices := make(map[string]string, len(volumes))
+	seen := make(map[string]bool, len(volumes))
+	for _, pair := range strings.Split(deviceArg, ",") {
+		volName, device, _ := strings.Cut(pair, "=")
+		if volumes[volName] == nil {
+			return nil, fmt.Errorf("gadget has no volume %q", volName)
+		}
+		if device == "" || device == "auto" {
+			return nil, fmt.Errorf("invalid device for volume %q: %q", volName, device)
+		}
+		if seen[device] {
+			return nil, fmt.Errorf("device %q used for more than one volume", device)
+		}
+		seen[device] = true
+		devices[volName] = device
+	}
+	for volName := range volumes {
+		if devices[volName] == "" {
+			return nil, fmt.Errorf("no device given for volume %q", volName)
+		}
+	}
+	return devices, nil
 }
 
 func maybeCreatePartitionTable(bootDevice, schema string) error {
+	// the partition table label, as known by blkid and sfdisk
+	var label string
 	switch schema {
-	case "dos":
-		return fmt.Errorf("cannot use partition schema %v yet", schema)
+	case "mbr", "dos":
+		label = disks.SchemaDOS
 	case "gpt":
-		// ok
+		label = disks.SchemaGPT
 	default:
 		return fmt.Errorf("cannot use unknown partition schema %v", schema)
 	}
 
-	// check if there is a GPT partition table already
-	output, stderr, err := osutil.RunSplitOutput("blkid", "--probe", "--match-types", "gpt", bootDevice)
-	exitCode, err := osutil.ExitCode(err)
+	// check if there is a partition table of the right type already
+	existing, err := disks.ProbePartitionTable(bootDevice)
 	if err != nil {
 		return err
 	}
-	switch exitCode {
-	case 0:
+	if existing == label {
 		// partition table already exists, nothing to do
-	case 2:
-		// no match found, create partition table
-		cmd := exec.Command("sfdisk", bootDevice)
-		cmd.Stdin = bytes.NewBufferString("label: gpt\n")
-		if output, stderr, err := osutil.RunCmd(cmd); err != nil {
-			return osutil.OutputErrCombine(output, stderr, err)
-		}
-		// ensure udev is aware of the new attributes
-		if output, stderr, err := osutil.RunSplitOutput("udevadm", "settle"); err != nil {
-			return osutil.OutputErrCombine(output, stderr, err)
-		}
-	default:
-		// unknown error
-		return fmt.Errorf("unexpected exit code from blkid: %v", osutil.OutputErrCombine(output, stderr, err))
+		return nil
 	}
-
-	return nil
+	// this also waits for udev to be aware of the new attributes
+	return disks.WritePartitionTable(bootDevice, &disks.PartitionTable{Schema: label})
 }
 
-func createPartitions(bootDevice string, volumes map[string]*gadget.Volume) ([]*gadget.OnDiskAndGadgetStructurePair, error) {
-	vol := firstVol(volumes)
-	// snapd does not create partition tables so we have to do it here
-	// or gadget.OnDiskVolumeFromDevice() will fail
-	if err := maybeCreatePartitionTable(bootDevice, vol.Schema); err != nil {
-		return nil, err
-	}
+func createPartitions(devices map[string]string, volumes map[string]*gadget.Volume) (map[string][]*gadget.OnDiskAndGadgetStructurePair, error) {
+	diskLayouts := make(map[string]*gadget.OnDiskVolume, len(volumes))
+	for _, volName := range sortedVolNames(volumes) {
+		vol := volumes[volName]
+		device := devices[volName]
+		// snapd does not create partition tables so we have to do it here
+		// or gadget.OnDiskVolumeFromDevice() will fail
+		if err := maybeCreatePartitionTable(device, vol.Schema); err != nil {
+			return nil, err
+		}
 
-	diskLayout, err := gadget.OnDiskVolumeFromDevice(bootDevice)
-	if err != nil {
-		return nil, fmt.Errorf("cannot read %v partitions: %v", bootDevice, err)
-	}
-	if len(diskLayout.Structure) > 0 && !vol.HasPartial(gadget.PartialStructure) {
-		return nil, fmt.Errorf("cannot yet install on a disk that has partitions")
+		diskLayout, err := gadget.OnDiskVolumeFromDevice(device)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read %v partitions: %v", device, err)
+		}
+		if len(diskLayout.Structure) > 0 && !vol.HasPartial(gadget.PartialStructure) {
+			return nil, fmt.Errorf("cannot yet install on a disk that has partitions")
+		}
+		diskLayouts[volName] = diskLayout
+
+		// Fill index, as it is not passed around to muinstaller
+		for i := range vol.Structure {
+			vol.Structure[i].YamlIndex = i
+		}
 	}
 
 	opts := &install.CreateOptions{CreateAllMissingPartitions: true}
-	// Fill index, as it is not passed around to muinstaller
-	for i := range vol.Structure {
-		vol.Structure[i].YamlIndex = i
-	}
-	created, err := install.CreateMissingPartitions(diskLayout, vol, opts)
+	created, err := install.CreateMissingPartitionsForVolumes(diskLayouts, volumes, opts)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create partitions: %v", err)
 	}
-	logger.Noticef("created %d partitions", len(created))
+	for volName, volCreated := range created {
+		logger.Noticef("created %d partitions on %s for volume %q", len(volCreated), devices[volName], volName)
+	}
 
 	return created, nil
 }
@@ -218,13 +252,14 @@ func parseKeyboardConfig(s string) *client.KeyboardConfig {
 }
 
 func postSystemsInstallSetupStorageEncryption(cli *client.Client,
-	details *client.SystemDetails, bootDevice string,
-	dgpairs []*gadget.OnDiskAndGadgetStructurePair,
+	details *client.SystemDetails, devices map[string]string,
+	dgpairs map[string][]*gadget.OnDiskAndGadgetStructurePair,
 	volumesAuth volumeAuthOptions,
 	keyboardConfig *client.KeyboardConfig) (map[string]string, error) {
 
 	// We are modifiying the details struct here
-	for _, gadgetVol := range details.Volumes {
+	for volName, gadgetVol := range details.Volumes {
+		gadgetVol.AssignedDevice = devices[volName]
 		for i := range gadgetVol.Structure {
 			switch gadgetVol.Structure[i].Role {
 			case "system-save", "system-data":
@@ -232,7 +267,7 @@ func postSystemsInstallSetupStorageEncryption(cli *client.Client,
 			default:
 				continue
 			}
-			gadgetVol.Structure[i].Device = nodeForPartLabel(dgpairs, gadgetVol.Structure[i].Name)
+			gadgetVol.Structure[i].Device = nodeForPartLabel(dgpairs[volName], gadgetVol.Structure[i].Name)
 		}
 	}
 
@@ -282,8 +317,10 @@ func postSystemsInstallSetupStorageEncryption(cli *client.Client,
 // XXX: reuse/extract cmd/snap/wait.go:waitMixin()
 func waitChange(chgId string) error {
 	cli := client.New(nil)
+	watcher := cli.WatchChange(context.Background(), chgId)
+	watcher.PollTime = 1 * time.Second
 	for {
-		chg, err := cli.Change(chgId)
+		chg, err := watcher.Next()
 		if err != nil {
 			return err
 		}
@@ -294,7 +331,6 @@ func waitChange(chgId string) error {
 		if chg.Ready {
 			return nil
 		}
-		time.Sleep(1 * time.Second)
 	}
 }
 
@@ -312,18 +348,19 @@ func nodeForPartLabel(dgpairs []*gadget.OnDiskAndGadgetStructurePair, name strin
 // TODO laidoutStructs is used to get the devices, when encryption is
 // happening maybe we need to find the information differently.
 func postSystemsInstallFinish(cli *client.Client,
-	details *client.SystemDetails, bootDevice string, optionalInstallPath string,
-	dgpairs []*gadget.OnDiskAndGadgetStructurePair) error {
+	details *client.SystemDetails, devices map[string]string, optionalInstallPath string,
+	dgpairs map[string][]*gadget.OnDiskAndGadgetStructurePair) error {
 
 	vols := make(map[string]*gadget.Volume)
 	for volName, gadgetVol := range details.Volumes {
+		gadgetVol.AssignedDevice = devices[volName]
 		for i := range gadgetVol.Structure {
 			// TODO mbr is special, what is the device for that?
 			if gadgetVol.Structure[i].Role == "mbr" {
-				gadgetVol.Structure[i].Device = bootDevice
+				gadgetVol.Structure[i].Device = devices[volName]
 				continue
 			}
-			gadgetVol.Structure[i].Device = nodeForPartLabel(dgpairs, gadgetVol.Structure[i].Name)
+			gadgetVol.Structure[i].Device = nodeForPartLabel(dgpairs[volName], gadgetVol.Structure[i].Name)
 			logger.Debugf("partition to install: %q", gadgetVol.Structure[i].Device)
 		}
 		vols[volName] = gadgetVol
@@ -426,19 +463,26 @@ func maybeGetOptionalInstall(path string) (*client.OptionalInstallRequest, error
 
 // createAndMountFilesystems creates and mounts filesystems. It returns
 // an slice with the paths where the filesystems have been mounted to.
-func createAndMountFilesystems(bootDevice string, volumes map[string]*gadget.Volume, encryptedDevices map[string]string) ([]string, error) {
-	// only support a single volume for now
-	if len(volumes) != 1 {
-		return nil, fmt.Errorf("got unexpected number of volumes %v", len(volumes))
+func createAndMountFilesystems(devices map[string]string, volumes map[string]*gadget.Volume, encryptedDevices map[string]string) ([]string, error) {
+	var mountPoints []string
+	for _, volName := range sortedVolNames(volumes) {
+		mntPts, err := createAndMountVolumeFilesystems(devices[volName], volumes[volName], encryptedDevices)
+		mountPoints = append(mountPoints, mntPts...)
+		if err != nil {
+			return mountPoints, err
+		}
 	}
+	return mountPoints, nil
+}
+
+func createAndMountVolumeFilesystems(device string, vol *gadget.Volume, encryptedDevices map[string]string) ([]string, error) {
 	// XXX: make this more elegant
 	shouldEncrypt := len(encryptedDevices) > 0
 
-	disk, err := disks.DiskFromDeviceName(bootDevice)
+	disk, err := disks.DiskFromDeviceName(device)
 	if err != nil {
 		return nil, err
 	}
-	vol := firstVol(volumes)
 
 	var mountPoints []string
 	for _, volStruct := range vol.Structure {
@@ -610,21 +654,9 @@ func fillPartiallyDefinedVolume(vol *gadget.Volume, bootDevice string) error {
 
 	// Fill sizes: for the moment, to avoid complicating unnecessarily the
 	// code, we do size=min-size except for the last partition.
-	output, stderr, err := osutil.RunSplitOutput("lsblk", "--bytes", "--noheadings", "--output", "SIZE", bootDevice)
-	exitCode, err := osutil.ExitCode(err)
+	diskSize, _, err := disks.DeviceGeometry(bootDevice)
 	if err != nil {
-		return err
-	}
-	if exitCode != 0 {
-		return fmt.Errorf("cannot find size of %q: %q (stderr: %s)", bootDevice, string(output), string(stderr))
-	}
-	lines := strings.Split(string(output), "\n")
-	if len(lines) == 0 {
-		return fmt.Errorf("error splitting %q (stderr: %s)", string(output), string(stderr))
-	}
-	diskSize, err := strconv.Atoi(lines[0])
-	if err != nil {
-		return fmt.Errorf("while converting %s to a size: %v (stderr: %s)", string(output), err, string(stderr))
+		return fmt.Errorf("cannot find size of %q: %v", bootDevice, err)
 	}
 	partStart := quantity.Offset(0)
 	if vol.HasPartial(gadget.PartialSize) {
@@ -656,9 +688,7 @@ func fillPartiallyDefinedVolume(vol *gadget.Volume, bootDevice string) error {
 	return nil
 }
 
-func run(seedLabel, bootDevice, rootfsCreator, optionalInstallPath, recoveryKeyOut string, preseedRootfs bool, volumesAuth volumeAuthOptions, keyboardConfig *client.KeyboardConfig) error {
-	logger.Noticef("installing on %q", bootDevice)
-
+func run(seedLabel, deviceArg, rootfsCreator, optionalInstallPath, recoveryKeyOut string, preseedRootfs bool, volumesAuth volumeAuthOptions, keyboardConfig *client.KeyboardConfig) error {
 	cli := client.New(nil)
 	details, err := cli.SystemDetails(seedLabel)
 	if err != nil {
@@ -668,24 +698,26 @@ func run(seedLabel, bootDevice, rootfsCreator, optionalInstallPath, recoveryKeyO
 	if err != nil {
 		return err
 	}
-	// TODO: support multiple volumes, see gadget/install/install.go
-	if len(details.Volumes) != 1 {
-		return fmt.Errorf("gadget defines %v volumes, while we support only one at the moment", len(details.Volumes))
-	}
-
-	// If partial gadget, fill missing information based on the installation target
-	if err := fillPartiallyDefinedVolume(firstVol(details.Volumes), bootDevice); err != nil {
+	devices, err := targetDevices(deviceArg, details.Volumes)
+	if err != nil {
 		return err
 	}
+	for _, volName := range sortedVolNames(details.Volumes) {
+		logger.Noticef("installing volume %q on %q", volName, devices[volName])
+		// If partial gadget, fill missing information based on the installation target
+		if err := fillPartiallyDefinedVolume(details.Volumes[volName], devices[volName]); err != nil {
+			return err
+		}
+	}
 
 	// TODO: grow the data-partition based on disk size
-	dgpairs, err := createPartitions(bootDevice, details.Volumes)
+	dgpairs, err := createPartitions(devices, details.Volumes)
 	if err != nil {
 		return fmt.Errorf("cannot setup partitions: %v", err)
 	}
 	var encryptedDevices = make(map[string]string)
 	if shouldEncrypt {
-		encryptedDevices, err = postSystemsInstallSetupStorageEncryption(cli, details, bootDevice, dgpairs, volumesAuth, keyboardConfig)
+		encryptedDevices, err = postSystemsInstallSetupStorageEncryption(cli, details, devices, dgpairs, volumesAuth, keyboardConfig)
 		if err != nil {
 			return fmt.Errorf("cannot setup storage encryption: %v", err)
 		}
@@ -700,10 +732,23 @@ func run(seedLabel, bootDevice, rootfsCreator, optionalInstallPath, recoveryKeyO
 				return fmt.Errorf("cannot write generated recovery key at %q: %v", recoveryKeyOut, err)
 			}
 		}
+
+		// fail before writing anything to disk if the keys cannot
+		// be sealed
+		preview, err := cli.PreviewInstallSealing(seedLabel)
+		if err != nil {
+			return fmt.Errorf("cannot preview sealing: %v", err)
+		}
+		logger.Noticef("keys will be sealed with method %q", preview.SealingMethod)
+		if preview.PCRProfile != nil {
+			logger.Noticef("keys will be bound to %s PCRs %v (options: %v, accepted errors: %v)",
+				preview.PCRProfile.PCRAlg, preview.PCRProfile.PCRs,
+				preview.PCRProfile.Options, preview.PCRProfile.AcceptedErrors)
+		}
 	}
 	logger.Noticef("creating and mounting filesystems")
 
-	mntPts, err := createAndMountFilesystems(bootDevice, details.Volumes, encryptedDevices)
+	mntPts, err := createAndMountFilesystems(devices, details.Volumes, encryptedDevices)
 	if err != nil {
 		return fmt.Errorf("cannot create filesystems: %v", err)
 	}
@@ -736,7 +781,7 @@ func run(seedLabel, bootDevice, rootfsCreator, optionalInstallPath, recoveryKeyO
 		return fmt.Errorf("cannot unmount filesystems: %v", err)
 	}
 
-	if err := postSystemsInstallFinish(cli, details, bootDevice, optionalInstallPath, dgpairs); err != nil {
+	if err := postSystemsInstallFinish(cli, details, devices, optionalInstallPath, dgpairs); err != nil {
 		return fmt.Errorf("cannot finalize install: %v", err)
 	}
 
@@ -758,7 +803,7 @@ func checkForRole(details *client.SystemDetails, role string) bool {
 
 func main() {
 	seedLabel := flag.String("label", "", "seed label (required)")
-	bootDevice := flag.String("device", "", "target device (required)")
+	bootDevice := flag.String("device", "", "target device (required). Either a single device, \"auto\" or, for gadgets with multiple volumes,\na comma separated list of <volume>=<device> pairs")
 	rootfsCreator := flag.String("rootfs-creator", "", "rootfs creator (optional). If specified, classic Ubuntu with core boot will be installed.\nOtherwise, Ubuntu Core will be installed")
 	optionalInstallPath := flag.String("optional", "", "path to optional snaps and components JSON file (optional)")
 	passphrase := flag.String("passphrase", "", "encryption passphrase (optional). If specified and encryption is suppported, passphrase authentication will be enabled")
@@ -788,10 +833,6 @@ func main() {
 
 	logger.SimpleSetup(nil)
 
-	if *bootDevice == "auto" {
-		*bootDevice = waitForDevice()
-	}
-
 	volumesAuth := volumeAuthOptions{
 		pin:        *pin,
 		passphrase: *passphrase,
@@ -514,13 +514,19 @@ func (sched *Schedule) Next(last time.Time) ScheduleWindow {
 
 }
 
-func randDur(a, b time.Time) time.Duration {
+// spreadDur returns the duration over which events are spread in the
+// window from a to b.
+func spreadDur(a, b time.Time) time.Duration {
 	dur := b.Sub(a)
 	if dur > 5*time.Minute {
 		// doing it this way we still spread really small windows about
 		dur -= 5 * time.Minute
 	}
+	return dur
+}
 
+func randDur(a, b time.Time) time.Duration {
+	dur := spreadDur(a, b)
 	if dur <= 0 {
 		// avoid panic'ing (even if things are probably messed up)
 		return 0
@@ -536,6 +542,35 @@ var (
 // Next returns the earliest event after last according to the provided
 // schedule but no later than maxDuration since last.
 func Next(schedule []*Schedule, last time.Time, maxDuration time.Duration) time.Duration {
+	when, _ := next(schedule, last, maxDuration, randDur)
+	return when
+}
+
+// NextStaggered is like Next, but rather than at a random time, the event
+// is placed at the given fraction, between 0 and 1, of a window that
+// allows spreading. It also returns the offset from the start of the
+// window that the fraction corresponds to. This lets a fleet of devices
+// spread their events over the window deterministically.
+func NextStaggered(schedule []*Schedule, last time.Time, maxDuration time.Duration, fraction float64) (when, offset time.Duration) {
+	return next(schedule, last, maxDuration, func(a, b time.Time) time.Duration {
+		return StaggerOffset(a, b, fraction)
+	})
+}
+
+// StaggerOffset returns the offset at the given fraction, between 0 and 1,
+// of the window from a to b in which events are spread.
+func StaggerOffset(a, b time.Time, fraction float64) time.Duration {
+	dur := spreadDur(a, b)
+	if dur <= 0 || fraction <= 0 {
+		return 0
+	}
+	if fraction >= 1 {
+		return dur
+	}
+	return time.Duration(float64(dur) * fraction)
+}
+
+func next(schedule []*Schedule, last time.Time, maxDuration time.Duration, spread func(a, b time.Time) time.Duration) (when, offset time.Duration) {
 	now := timeNow()
 
 	window := ScheduleWindow{
@@ -550,16 +585,16 @@ func Next(schedule []*Schedule, last time.Time, maxDuration time.Duration) time.
 		}
 	}
 	if window.Start.Before(now) {
-		return 0
+		return 0, 0
 	}
 
-	when := window.Start.Sub(now)
+	when = window.Start.Sub(now)
 	if window.Spread {
-		when += randDur(window.Start, window.End)
+		offset = spread(window.Start, window.End)
+		when += offset
 	}
 
-	return when
-
+	return when, offset
 }
 
 var weekdayMap = map[string]time.Weekday{
@@ -841,6 +841,54 @@ func (ts *timeutilSuite) TestScheduleNext(c *C) {
 	}
 }
 
+func (ts *timeutilSuite) TestScheduleNextStaggered(c *C) {
+	const shortForm = "2006-01-02 15:04"
+
+	restore := testutil.Backup(&time.Local)
+	defer restore()
+	local, err := time.LoadLocation("UTC")
+	c.Assert(err, IsNil)
+	time.Local = local
+
+	last, err := time.ParseInLocation(shortForm, "2017-02-05 22:00", time.Local)
+	c.Assert(err, IsNil)
+	fakeNow, err := time.ParseInLocation(shortForm, "2017-02-06 08:00", time.Local)
+	c.Assert(err, IsNil)
+	restorer := timeutil.MockTimeNow(func() time.Time {
+		return fakeNow
+	})
+	defer restorer()
+
+	// a 4h window, of which 3h55m are used for spreading
+	sched, err := timeutil.ParseSchedule("9:00~13:00")
+	c.Assert(err, IsNil)
+
+	for _, t := range []struct {
+		fraction float64
+		offset   time.Duration
+	}{
+		{0, 0},
+		{0.5, 117*time.Minute + 30*time.Second},
+		{1, 235 * time.Minute},
+		{-1, 0},
+		{2, 235 * time.Minute},
+	} {
+		when, offset := timeutil.NextStaggered(sched, last, maxDuration, t.fraction)
+		c.Check(offset, Equals, t.offset, Commentf("fraction %v", t.fraction))
+		c.Check(when, Equals, time.Hour+t.offset, Commentf("fraction %v", t.fraction))
+		// the result is deterministic
+		again, _ := timeutil.NextStaggered(sched, last, maxDuration, t.fraction)
+		c.Check(again, Equals, when)
+	}
+
+	// no spreading in windows which are not spread
+	sched, err = timeutil.ParseSchedule("9:00-13:00")
+	c.Assert(err, IsNil)
+	when, offset := timeutil.NextStaggered(sched, last, maxDuration, 0.5)
+	c.Check(offset, Equals, time.Duration(0))
+	c.Check(when, Equals, time.Hour)
+}
+
 func (ts *timeutilSuite) TestScheduleIncludes(c *C) {
 	const shortForm = "2006-01-02 15:04:05"
 
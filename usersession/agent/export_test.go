@@ -22,7 +22,9 @@ package agent
 import (
 	"syscall"
 
+	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/testutil"
 )
 
 var (
@@ -33,6 +35,7 @@ var (
 	FinishRefreshNotificationCmd       = finishRefreshNotificationCmd
 	GuessAppData                       = guessAppData
 	GetLocalizedAppNameFromDesktopFile = getLocalizedAppNameFromDesktopFile
+	ProcessInfoCmd                     = processInfoCmd
 )
 
 func MockUcred(ucred *syscall.Ucred, err error) (restore func()) {
@@ -62,3 +65,15 @@ func MockCurrentLocale(newLocale string) (restore func()) {
 		currentLocale = i18n.CurrentLocale
 	}
 }
+
+func MockCgroupProcessPathInTrackingCgroup(f func(pid int) (string, error)) (restore func()) {
+	return testutil.Mock(&cgroupProcessPathInTrackingCgroup, f)
+}
+
+func MockOsutilBootID(f func() (string, error)) (restore func()) {
+	return testutil.Mock(&osutilBootID, f)
+}
+
+func MockSnapdConnections(f func(instanceName string) (client.Connections, error)) (restore func()) {
+	return testutil.Mock(&snapdConnections, f)
+}
@@ -20,22 +20,33 @@
 package agent
 
 import (
+	"bytes"
+	"crypto/rand"
 	"encoding/json"
 	"fmt"
 	"mime"
 	"net/http"
+	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/mvo5/goconfigparser"
 
+	snapdclient "github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/desktop/notification"
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/i18n"
 	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/osutil/sys"
+	"github.com/snapcore/snapd/sandbox/cgroup"
 	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/naming"
 	"github.com/snapcore/snapd/strutil"
 	"github.com/snapcore/snapd/systemd"
 	"github.com/snapcore/snapd/usersession/client"
@@ -48,6 +59,7 @@ var restApi = []*Command{
 	serviceStatusCmd,
 	pendingRefreshNotificationCmd,
 	finishRefreshNotificationCmd,
+	processInfoCmd,
 }
 
 var (
@@ -80,6 +92,11 @@ var (
 		Path: "/v1/notifications/finish-refresh",
 		POST: postRefreshFinishedNotification,
 	}
+
+	processInfoCmd = &Command{
+		Path: "/v1/process-info",
+		GET:  processInfo,
+	}
 )
 
 func sessionInfo(c *Command, r *http.Request) Response {
@@ -572,3 +589,213 @@ func postRefreshFinishedNotification(c *Command, r *http.Request) Response {
 	}
 	return SyncResponse(nil)
 }
+
+var (
+	cgroupProcessPathInTrackingCgroup = cgroup.ProcessPathInTrackingCgroup
+	osutilBootID                      = osutil.BootID
+
+	snapdConnections = func(instanceName string) (snapdclient.Connections, error) {
+		cli := snapdclient.New(nil)
+		return cli.Connections(&snapdclient.ConnectionOptions{Snap: instanceName})
+	}
+)
+
+// processStartTime returns the start time of the given process in clock
+// ticks since boot, as found in /proc/<pid>/stat.
+func processStartTime(pid int) (uint64, error) {
+	statPath := filepath.Join(dirs.GlobalRootDir, fmt.Sprintf("proc/%d/stat", pid))
+	data, err := os.ReadFile(statPath)
+	if err != nil {
+		return 0, err
+	}
+	// the command name is enclosed in parentheses and can contain
+	// spaces, so only look at the fields past the last closing one
+	idx := bytes.LastIndexByte(data, ')')
+	if idx < 0 {
+		return 0, fmt.Errorf("cannot parse %s: missing command name", statPath)
+	}
+	fields := strings.Fields(string(data[idx+1:]))
+	// starttime is the 22nd field, the remaining fields start at the 3rd
+	const startTimeIdx = 22 - 3
+	if len(fields) <= startTimeIdx {
+		return 0, fmt.Errorf("cannot parse %s: too few fields", statPath)
+	}
+	startTime, err := strconv.ParseUint(fields[startTimeIdx], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("cannot parse %s: invalid start time: %v", statPath, err)
+	}
+	return startTime, nil
+}
+
+// processSnapRevision returns the revision of the snap run by the given
+// process. snap run records it in the environment of the process, it is
+// only trusted if the revision is also mounted in the mount namespace of
+// the process, as the process may have been started before a refresh.
+func processSnapRevision(pid int, instanceName string) (snap.Revision, error) {
+	procDir := filepath.Join(dirs.GlobalRootDir, fmt.Sprintf("proc/%d", pid))
+	environ, err := os.ReadFile(filepath.Join(procDir, "environ"))
+	if err != nil {
+		return snap.Revision{}, err
+	}
+	var revStr string
+	for _, kv := range bytes.Split(environ, []byte{0}) {
+		if strings.HasPrefix(string(kv), "SNAP_REVISION=") {
+			revStr = strings.TrimPrefix(string(kv), "SNAP_REVISION=")
+		}
+	}
+	rev, err := snap.ParseRevision(revStr)
+	if err != nil {
+		return snap.Revision{}, fmt.Errorf("cannot find snap revision of process: %v", err)
+	}
+
+	f, err := os.Open(filepath.Join(procDir, "mountinfo"))
+	if err != nil {
+		return snap.Revision{}, err
+	}
+	defer f.Close()
+	entries, err := osutil.ReadMountInfo(f)
+	if err != nil {
+		return snap.Revision{}, err
+	}
+	// parallel instances of snaps are mounted under the name of the snap
+	// in their mount namespace
+	snapName, _ := snap.SplitInstanceName(instanceName)
+	mountDir := filepath.Join("/snap", snapName, rev.String())
+	for _, entry := range entries {
+		if entry.MountDir == mountDir {
+			return rev, nil
+		}
+	}
+	return snap.Revision{}, fmt.Errorf("snap revision %s is not mounted in the mount namespace of the process", rev)
+}
+
+// connectedInterfaces returns the interfaces of the given plugs of the
+// snap that are connected.
+func connectedInterfaces(instanceName string, plugs map[string]*snap.PlugInfo) ([]string, error) {
+	conns, err := snapdConnections(instanceName)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get connections of snap %q: %v", instanceName, err)
+	}
+	var ifaces []string
+	for _, conn := range conns.Established {
+		if conn.Plug.Snap != instanceName || plugs[conn.Plug.Name] == nil {
+			continue
+		}
+		if !strutil.ListContains(ifaces, conn.Interface) {
+			ifaces = append(ifaces, conn.Interface)
+		}
+	}
+	sort.Strings(ifaces)
+	return ifaces, nil
+}
+
+// processInfoKey returns the key signing the process information, creating
+// it on first use. The key is only readable by the user owning the session,
+// which makes the signed information trustworthy for processes able to read
+// it, even when the information was relayed by a snap.
+func processInfoKey() ([]byte, error) {
+	keyPath := client.ProcessInfoKeyPath(int(sys.Geteuid()))
+	key, err := os.ReadFile(keyPath)
+	if err == nil && len(key) == client.ProcessInfoKeySize {
+		return key, nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	key = make([]byte, client.ProcessInfoKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := osutil.AtomicWriteFile(keyPath, key, 0600, 0); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func processInfo(c *Command, r *http.Request) Response {
+	pidStr := r.URL.Query().Get("pid")
+	pid, err := strconv.Atoi(pidStr)
+	if err != nil || pid <= 0 {
+		return BadRequest("cannot identify process: invalid pid %q", pidStr)
+	}
+
+	// only processes of the user owning this session can be identified
+	fi, err := os.Stat(filepath.Join(dirs.GlobalRootDir, fmt.Sprintf("proc/%d", pid)))
+	if err != nil {
+		return NotFound("cannot find process %d", pid)
+	}
+	if st, ok := fi.Sys().(*syscall.Stat_t); !ok || st.Uid != uint32(sys.Geteuid()) {
+		return Forbidden("cannot identify process %d: process belongs to another user", pid)
+	}
+
+	startTime, err := processStartTime(pid)
+	if err != nil {
+		return InternalError("cannot identify process %d: %v", pid, err)
+	}
+	cgroupPath, err := cgroupProcessPathInTrackingCgroup(pid)
+	if err != nil {
+		return InternalError("cannot identify process %d: %v", pid, err)
+	}
+	tag := cgroup.SecurityTagFromCgroupPath(cgroupPath)
+	if tag == nil {
+		return NotFound("process %d does not belong to a snap", pid)
+	}
+	rev, err := processSnapRevision(pid, tag.InstanceName())
+	if err != nil {
+		return InternalError("cannot identify process %d: %v", pid, err)
+	}
+	si, err := snap.ReadInfo(tag.InstanceName(), &snap.SideInfo{Revision: rev})
+	if err != nil {
+		return InternalError("cannot read information about snap %q: %v", tag.InstanceName(), err)
+	}
+
+	info := &client.ProcessSnapInfo{
+		Pid:          pid,
+		StartTime:    startTime,
+		SecurityTag:  tag.String(),
+		InstanceName: tag.InstanceName(),
+		Revision:     rev.String(),
+	}
+	var plugs map[string]*snap.PlugInfo
+	switch tag := tag.(type) {
+	case naming.AppSecurityTag:
+		info.App = tag.AppName()
+		if app := si.Apps[tag.AppName()]; app != nil {
+			plugs = app.Plugs
+			if desktopFile := app.DesktopFile(); osutil.FileExists(desktopFile) {
+				info.DesktopFile = filepath.Base(desktopFile)
+			}
+		}
+	case naming.HookSecurityTag:
+		info.Hook = tag.HookName()
+		if hook := si.Hooks[tag.HookName()]; hook != nil {
+			plugs = hook.Plugs
+		}
+	}
+	if len(plugs) != 0 {
+		info.Interfaces, err = connectedInterfaces(tag.InstanceName(), plugs)
+		if err != nil {
+			return InternalError("cannot identify process %d: %v", pid, err)
+		}
+	}
+
+	info.BootID, err = osutilBootID()
+	if err != nil {
+		return InternalError("cannot identify process %d: %v", pid, err)
+	}
+	// the process may have exited, and its pid been reused, while it
+	// was being identified
+	if again, err := processStartTime(pid); err != nil || again != startTime {
+		return Conflict("cannot identify process %d: process changed during identification", pid)
+	}
+
+	key, err := processInfoKey()
+	if err != nil {
+		return InternalError("cannot sign process information: %v", err)
+	}
+	signed, err := client.SignProcessSnapInfo(info, key)
+	if err != nil {
+		return InternalError("cannot sign process information: %v", err)
+	}
+	return SyncResponse(signed)
+}
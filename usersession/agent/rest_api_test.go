@@ -34,6 +34,7 @@ import (
 	"github.com/mvo5/goconfigparser"
 	. "gopkg.in/check.v1"
 
+	snapdclient "github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/desktop/notification"
 	"github.com/snapcore/snapd/desktop/notification/notificationtest"
 	"github.com/snapcore/snapd/dirs"
@@ -1399,3 +1400,321 @@ func (s *restSuite) TestPostPendingRefreshNotificationTestInstanceKeyWithDesktop
 		"desktop-entry": dbus.MakeVariant("io.snapcraft.SessionAgent"),
 	})
 }
+
+func mockProcStat(c *C, pid int, startTime uint64) {
+	procDir := filepath.Join(dirs.GlobalRootDir, fmt.Sprintf("proc/%d", pid))
+	c.Assert(os.MkdirAll(procDir, 0755), IsNil)
+	// the command name may contain spaces and parentheses
+	stat := fmt.Sprintf("%d (my (app) x) S 1 2 3 4 5 6 7 8 9 10 11 12 13 14 15 16 17 18 %d 0 0\n", pid, startTime)
+	c.Assert(os.WriteFile(filepath.Join(procDir, "stat"), []byte(stat), 0644), IsNil)
+}
+
+func mockProcSnapRevision(c *C, pid int, revision string, mountedSnaps ...string) {
+	procDir := filepath.Join(dirs.GlobalRootDir, fmt.Sprintf("proc/%d", pid))
+	c.Assert(os.MkdirAll(procDir, 0755), IsNil)
+	environ := "HOME=/home/user\x00SNAP_REVISION=" + revision + "\x00SNAP_NAME=foo\x00"
+	c.Assert(os.WriteFile(filepath.Join(procDir, "environ"), []byte(environ), 0644), IsNil)
+	mountinfo := "1 0 7:1 / / rw - ext4 /dev/sda1 rw\n"
+	for i, mounted := range mountedSnaps {
+		mountinfo += fmt.Sprintf("%d 1 7:%d / /snap/%s ro,nodev - squashfs /dev/loop%d ro\n", i+2, i+2, mounted, i+2)
+	}
+	c.Assert(os.WriteFile(filepath.Join(procDir, "mountinfo"), []byte(mountinfo), 0644), IsNil)
+}
+
+func (s *restSuite) processInfo(c *C, query string) (int, *resp) {
+	req := httptest.NewRequest("GET", "/v1/process-info?"+query, nil)
+	rec := httptest.NewRecorder()
+	agent.ProcessInfoCmd.GET(agent.ProcessInfoCmd, req).ServeHTTP(rec, req)
+	c.Check(rec.Header().Get("Content-Type"), Equals, "application/json")
+
+	var rsp resp
+	c.Assert(json.Unmarshal(rec.Body.Bytes(), &rsp), IsNil)
+	return rec.Code, &rsp
+}
+
+// verifiedProcessInfo checks the signature of a process info result and
+// returns the decoded document.
+func verifiedProcessInfo(c *C, result any) map[string]any {
+	m, ok := result.(map[string]any)
+	c.Assert(ok, Equals, true)
+	signed := client.SignedProcessSnapInfo{
+		Document:  m["document"].(string),
+		Signature: m["signature"].(string),
+	}
+	key, err := os.ReadFile(client.ProcessInfoKeyPath(os.Geteuid()))
+	c.Assert(err, IsNil)
+	_, err = signed.Verify(key)
+	c.Assert(err, IsNil)
+
+	var document map[string]any
+	c.Assert(json.Unmarshal([]byte(signed.Document), &document), IsNil)
+	return document
+}
+
+func (s *restSuite) TestProcessInfo(c *C) {
+	s.AddCleanup(snap.MockSanitizePlugsSlots(func(snapInfo *snap.Info) {}))
+	c.Check(agent.ProcessInfoCmd.Path, Equals, "/v1/process-info")
+	c.Check(agent.ProcessInfoCmd.POST, IsNil)
+	c.Assert(agent.ProcessInfoCmd.GET, NotNil)
+
+	snaptest.MockSnapCurrent(c, `name: foo
+version: 1
+apps:
+  app:
+    command: bin/app
+    plugs: [x11, home, wayland, x11-again]
+  other:
+    command: bin/other
+plugs:
+  x11-again:
+    interface: x11
+hooks:
+  configure:
+    plugs: [network]
+`, &snap.SideInfo{Revision: snap.R(42)})
+	c.Assert(os.MkdirAll(dirs.SnapDesktopFilesDir, 0755), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(dirs.SnapDesktopFilesDir, "foo_app.desktop"), nil, 0644), IsNil)
+
+	mockProcStat(c, 1234, 5678)
+	mockProcSnapRevision(c, 1234, "42", "core22/100", "foo/41", "foo/42")
+	var cgroupPath string
+	restore := agent.MockCgroupProcessPathInTrackingCgroup(func(pid int) (string, error) {
+		c.Check(pid, Equals, 1234)
+		return cgroupPath, nil
+	})
+	defer restore()
+	restore = agent.MockOsutilBootID(func() (string, error) {
+		return "my-boot-id", nil
+	})
+	defer restore()
+	restore = agent.MockSnapdConnections(func(instanceName string) (snapdclient.Connections, error) {
+		c.Check(instanceName, Equals, "foo")
+		return snapdclient.Connections{
+			Established: []snapdclient.Connection{
+				{Plug: snapdclient.PlugRef{Snap: "foo", Name: "x11"}, Slot: snapdclient.SlotRef{Snap: "core", Name: "x11"}, Interface: "x11"},
+				{Plug: snapdclient.PlugRef{Snap: "foo", Name: "home"}, Slot: snapdclient.SlotRef{Snap: "core", Name: "home"}, Interface: "home"},
+				{Plug: snapdclient.PlugRef{Snap: "foo", Name: "network"}, Slot: snapdclient.SlotRef{Snap: "core", Name: "network"}, Interface: "network"},
+				// slot of the snap
+				{Plug: snapdclient.PlugRef{Snap: "bar", Name: "wayland"}, Slot: snapdclient.SlotRef{Snap: "foo", Name: "wayland"}, Interface: "wayland"},
+			},
+		}, nil
+	})
+	defer restore()
+
+	cgroupPath = "/user.slice/user-1000.slice/user@1000.service/app.slice/snap.foo.app-fa2c04f5-fb07-4c63-8aa8-fd36d3ca4fac.scope"
+	code, rsp := s.processInfo(c, "pid=1234")
+	c.Check(code, Equals, 200)
+	c.Check(rsp.Type, Equals, agent.ResponseTypeSync)
+	c.Check(verifiedProcessInfo(c, rsp.Result), DeepEquals, map[string]any{
+		"pid":           1234.,
+		"start-time":    5678.,
+		"boot-id":       "my-boot-id",
+		"security-tag":  "snap.foo.app",
+		"instance-name": "foo",
+		"revision":      "42",
+		"app":           "app",
+		// the wayland plug is not connected
+		"interfaces":   []any{"home", "x11"},
+		"desktop-file": "foo_app.desktop",
+	})
+
+	cgroupPath = "/user.slice/user-1000.slice/user@1000.service/app.slice/snap.foo.other-fa2c04f5-fb07-4c63-8aa8-fd36d3ca4fac.scope"
+	code, rsp = s.processInfo(c, "pid=1234")
+	c.Check(code, Equals, 200)
+	c.Check(verifiedProcessInfo(c, rsp.Result), DeepEquals, map[string]any{
+		"pid":           1234.,
+		"start-time":    5678.,
+		"boot-id":       "my-boot-id",
+		"security-tag":  "snap.foo.other",
+		"instance-name": "foo",
+		"revision":      "42",
+		"app":           "other",
+	})
+
+	cgroupPath = "/system.slice/snap.foo.hook.configure-fa2c04f5-fb07-4c63-8aa8-fd36d3ca4fac.scope"
+	code, rsp = s.processInfo(c, "pid=1234")
+	c.Check(code, Equals, 200)
+	c.Check(verifiedProcessInfo(c, rsp.Result), DeepEquals, map[string]any{
+		"pid":           1234.,
+		"start-time":    5678.,
+		"boot-id":       "my-boot-id",
+		"security-tag":  "snap.foo.hook.configure",
+		"instance-name": "foo",
+		"revision":      "42",
+		"hook":          "configure",
+		"interfaces":    []any{"network"},
+	})
+}
+
+func (s *restSuite) TestProcessInfoRevisionOfProcess(c *C) {
+	s.AddCleanup(snap.MockSanitizePlugsSlots(func(snapInfo *snap.Info) {}))
+	// the process was started before the snap was refreshed
+	snaptest.MockSnap(c, `name: foo
+version: 1
+apps:
+  app:
+    command: bin/app
+    plugs: [home]
+`, &snap.SideInfo{Revision: snap.R(41)})
+	snaptest.MockSnapCurrent(c, `name: foo
+version: 2
+apps:
+  app:
+    command: bin/app
+    plugs: [home, x11]
+`, &snap.SideInfo{Revision: snap.R(42)})
+
+	mockProcStat(c, 1234, 5678)
+	mockProcSnapRevision(c, 1234, "41", "foo/41", "foo/42")
+	restore := agent.MockCgroupProcessPathInTrackingCgroup(func(pid int) (string, error) {
+		return "/user.slice/user-1000.slice/user@1000.service/app.slice/snap.foo.app-fa2c04f5-fb07-4c63-8aa8-fd36d3ca4fac.scope", nil
+	})
+	defer restore()
+	restore = agent.MockOsutilBootID(func() (string, error) {
+		return "my-boot-id", nil
+	})
+	defer restore()
+	restore = agent.MockSnapdConnections(func(instanceName string) (snapdclient.Connections, error) {
+		return snapdclient.Connections{
+			Established: []snapdclient.Connection{
+				{Plug: snapdclient.PlugRef{Snap: "foo", Name: "x11"}, Slot: snapdclient.SlotRef{Snap: "core", Name: "x11"}, Interface: "x11"},
+				{Plug: snapdclient.PlugRef{Snap: "foo", Name: "home"}, Slot: snapdclient.SlotRef{Snap: "core", Name: "home"}, Interface: "home"},
+			},
+		}, nil
+	})
+	defer restore()
+
+	code, rsp := s.processInfo(c, "pid=1234")
+	c.Check(code, Equals, 200)
+	document := verifiedProcessInfo(c, rsp.Result)
+	c.Check(document["revision"], Equals, "41")
+	// the x11 plug is not part of the revision run by the process
+	c.Check(document["interfaces"], DeepEquals, []any{"home"})
+}
+
+func (s *restSuite) TestProcessInfoErrors(c *C) {
+	s.AddCleanup(snap.MockSanitizePlugsSlots(func(snapInfo *snap.Info) {}))
+	mockProcStat(c, 1234, 5678)
+	cgroupPath := "/user.slice/user-1000.slice/session-1.scope"
+	var cgroupErr error
+	restore := agent.MockCgroupProcessPathInTrackingCgroup(func(pid int) (string, error) {
+		return cgroupPath, cgroupErr
+	})
+	defer restore()
+
+	for _, t := range []struct {
+		query string
+		code  int
+		msg   string
+	}{
+		{"", 400, `cannot identify process: invalid pid ""`},
+		{"pid=-1", 400, `cannot identify process: invalid pid "-1"`},
+		{"pid=foo", 400, `cannot identify process: invalid pid "foo"`},
+		{"pid=999", 404, `cannot find process 999`},
+		{"pid=1234", 404, `process 1234 does not belong to a snap`},
+	} {
+		code, rsp := s.processInfo(c, t.query)
+		c.Check(code, Equals, t.code, Commentf("%q", t.query))
+		c.Check(rsp.Type, Equals, agent.ResponseTypeError)
+		c.Check(rsp.Result, DeepEquals, map[string]any{"message": t.msg}, Commentf("%q", t.query))
+	}
+
+	cgroupErr = errors.New("boom")
+	code, rsp := s.processInfo(c, "pid=1234")
+	c.Check(code, Equals, 500)
+	c.Check(rsp.Result, DeepEquals, map[string]any{"message": "cannot identify process 1234: boom"})
+
+	cgroupErr = nil
+	cgroupPath = "/system.slice/snap.foo.app-fa2c04f5-fb07-4c63-8aa8-fd36d3ca4fac.scope"
+
+	// the revision of the snap is unknown
+	mockProcSnapRevision(c, 1234, "", "foo/1")
+	code, rsp = s.processInfo(c, "pid=1234")
+	c.Check(code, Equals, 500)
+	c.Check(rsp.Result, DeepEquals, map[string]any{"message": `cannot identify process 1234: cannot find snap revision of process: invalid snap revision: ""`})
+
+	// the revision of the snap is not mounted in the namespace of the process
+	mockProcSnapRevision(c, 1234, "2", "foo/1")
+	code, rsp = s.processInfo(c, "pid=1234")
+	c.Check(code, Equals, 500)
+	c.Check(rsp.Result, DeepEquals, map[string]any{"message": "cannot identify process 1234: snap revision 2 is not mounted in the mount namespace of the process"})
+
+	// snap is not installed
+	mockProcSnapRevision(c, 1234, "1", "foo/1")
+	code, rsp = s.processInfo(c, "pid=1234")
+	c.Check(code, Equals, 500)
+	c.Check(rsp.Result.(map[string]any)["message"], Matches, `cannot read information about snap "foo": cannot find installed snap "foo" at revision 1: .*`)
+}
+
+func (s *restSuite) TestProcessInfoConnectionsError(c *C) {
+	s.AddCleanup(snap.MockSanitizePlugsSlots(func(snapInfo *snap.Info) {}))
+	snaptest.MockSnapCurrent(c, "name: foo\nversion: 1\napps:\n  app:\n    command: bin/app\n    plugs: [home]\n", &snap.SideInfo{Revision: snap.R(1)})
+	mockProcStat(c, 1234, 5678)
+	mockProcSnapRevision(c, 1234, "1", "foo/1")
+	restore := agent.MockCgroupProcessPathInTrackingCgroup(func(pid int) (string, error) {
+		return "/system.slice/snap.foo.app-fa2c04f5-fb07-4c63-8aa8-fd36d3ca4fac.scope", nil
+	})
+	defer restore()
+	restore = agent.MockSnapdConnections(func(instanceName string) (snapdclient.Connections, error) {
+		return snapdclient.Connections{}, errors.New("snapd is down")
+	})
+	defer restore()
+
+	code, rsp := s.processInfo(c, "pid=1234")
+	c.Check(code, Equals, 500)
+	c.Check(rsp.Result, DeepEquals, map[string]any{"message": `cannot identify process 1234: cannot get connections of snap "foo": snapd is down`})
+}
+
+func (s *restSuite) TestProcessInfoPidReused(c *C) {
+	s.AddCleanup(snap.MockSanitizePlugsSlots(func(snapInfo *snap.Info) {}))
+	snaptest.MockSnapCurrent(c, "name: foo\nversion: 1\napps:\n  app:\n    command: bin/app\n", &snap.SideInfo{Revision: snap.R(1)})
+	mockProcStat(c, 1234, 5678)
+	mockProcSnapRevision(c, 1234, "1", "foo/1")
+	restore := agent.MockCgroupProcessPathInTrackingCgroup(func(pid int) (string, error) {
+		// the process exits and the pid is reused while it is being identified
+		mockProcStat(c, 1234, 9999)
+		return "/system.slice/snap.foo.app-fa2c04f5-fb07-4c63-8aa8-fd36d3ca4fac.scope", nil
+	})
+	defer restore()
+	restore = agent.MockOsutilBootID(func() (string, error) {
+		return "my-boot-id", nil
+	})
+	defer restore()
+
+	code, rsp := s.processInfo(c, "pid=1234")
+	c.Check(code, Equals, 409)
+	c.Check(rsp.Result, DeepEquals, map[string]any{"message": "cannot identify process 1234: process changed during identification"})
+}
+
+func (s *restSuite) TestProcessInfoKeyReused(c *C) {
+	s.AddCleanup(snap.MockSanitizePlugsSlots(func(snapInfo *snap.Info) {}))
+	snaptest.MockSnapCurrent(c, "name: foo\nversion: 1\napps:\n  app:\n    command: bin/app\n", &snap.SideInfo{Revision: snap.R(1)})
+	mockProcStat(c, 1234, 5678)
+	mockProcSnapRevision(c, 1234, "1", "foo/1")
+	restore := agent.MockCgroupProcessPathInTrackingCgroup(func(pid int) (string, error) {
+		return "/system.slice/snap.foo.app-fa2c04f5-fb07-4c63-8aa8-fd36d3ca4fac.scope", nil
+	})
+	defer restore()
+	restore = agent.MockOsutilBootID(func() (string, error) {
+		return "my-boot-id", nil
+	})
+	defer restore()
+
+	keyPath := client.ProcessInfoKeyPath(os.Geteuid())
+	c.Check(keyPath, testutil.FileAbsent)
+	code, _ := s.processInfo(c, "pid=1234")
+	c.Check(code, Equals, 200)
+
+	// the key is private to the user
+	fi, err := os.Stat(keyPath)
+	c.Assert(err, IsNil)
+	c.Check(fi.Mode().Perm(), Equals, os.FileMode(0600))
+	key, err := os.ReadFile(keyPath)
+	c.Assert(err, IsNil)
+	c.Check(key, HasLen, client.ProcessInfoKeySize)
+
+	// and reused
+	code, _ = s.processInfo(c, "pid=1234")
+	c.Check(code, Equals, 200)
+	c.Check(keyPath, testutil.FileEquals, key)
+}
@@ -22,13 +22,18 @@ package client
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"net/url"
+	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -539,3 +544,101 @@ func (client *Client) FinishRefreshNotification(ctx context.Context, closeInfo *
 	_, err = client.doMany(ctx, "POST", "/v1/notifications/finish-refresh", nil, headers, reqBody)
 	return err
 }
+
+// ProcessSnapInfo describes the snap a process belongs to, as identified by
+// the session agent from the tracking cgroup of the process.
+type ProcessSnapInfo struct {
+	Pid int `json:"pid"`
+	// StartTime (in clock ticks since boot) and BootID identify the
+	// process instance the information refers to, allowing to detect
+	// a pid that was reused since.
+	StartTime uint64 `json:"start-time"`
+	BootID    string `json:"boot-id"`
+
+	SecurityTag  string `json:"security-tag"`
+	InstanceName string `json:"instance-name"`
+	// Revision is the revision of the snap run by the process, which
+	// is not necessarily the current one.
+	Revision string `json:"revision"`
+	App      string `json:"app,omitempty"`
+	Hook     string `json:"hook,omitempty"`
+	// Interfaces are the interfaces of the connected plugs bound to the
+	// app or hook.
+	Interfaces  []string `json:"interfaces,omitempty"`
+	DesktopFile string   `json:"desktop-file,omitempty"`
+}
+
+// SignedProcessSnapInfo is the process information as returned by the
+// session agent. Document holds the JSON encoding of a ProcessSnapInfo and
+// Signature the base64 encoded HMAC-SHA256 of Document, keyed with the key
+// found at ProcessInfoKeyPath. The key is only readable by the user owning
+// the session, so the document can be trusted by the processes able to
+// read it, even when it was relayed by a snap.
+type SignedProcessSnapInfo struct {
+	Document  string `json:"document"`
+	Signature string `json:"signature"`
+}
+
+// ProcessInfoKeySize is the size of the key signing process information.
+const ProcessInfoKeySize = 32
+
+// ProcessInfoKeyPath returns the path of the key with which the session
+// agent of the given user signs process information.
+func ProcessInfoKeyPath(uid int) string {
+	return filepath.Join(dirs.XdgRuntimeDirBase, strconv.Itoa(uid), "snapd-session-agent.key")
+}
+
+func processInfoSignature(document string, key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(document))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// SignProcessSnapInfo encodes and signs the given process information with
+// the given key.
+func SignProcessSnapInfo(info *ProcessSnapInfo, key []byte) (*SignedProcessSnapInfo, error) {
+	document, err := json.Marshal(info)
+	if err != nil {
+		return nil, err
+	}
+	return &SignedProcessSnapInfo{
+		Document:  string(document),
+		Signature: processInfoSignature(string(document), key),
+	}, nil
+}
+
+// Verify checks the signature of the process information with the given
+// key and returns the decoded information.
+func (signed *SignedProcessSnapInfo) Verify(key []byte) (*ProcessSnapInfo, error) {
+	expected := processInfoSignature(signed.Document, key)
+	if !hmac.Equal([]byte(expected), []byte(signed.Signature)) {
+		return nil, fmt.Errorf("cannot verify process info: invalid signature")
+	}
+	var info ProcessSnapInfo
+	if err := json.Unmarshal([]byte(signed.Document), &info); err != nil {
+		return nil, fmt.Errorf("cannot decode process info: %v", err)
+	}
+	return &info, nil
+}
+
+// ProcessSnapInfo asks the session agent of the given user which snap the
+// process with the given pid belongs to. The signature of the answer is
+// verified with the key of the session agent, which the caller must be
+// able to read.
+func (client *Client) ProcessSnapInfo(ctx context.Context, uid, pid int) (*ProcessSnapInfo, error) {
+	q := make(url.Values)
+	q.Set("pid", strconv.Itoa(pid))
+	resp := client.sendRequest(ctx, uid, "GET", "/v1/process-info", q, nil, nil)
+	if resp.err != nil {
+		return nil, resp.err
+	}
+	var signed SignedProcessSnapInfo
+	if err := json.Unmarshal(resp.Result, &signed); err != nil {
+		return nil, fmt.Errorf("cannot decode process info: %v", err)
+	}
+	key, err := os.ReadFile(ProcessInfoKeyPath(uid))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read process info key: %v", err)
+	}
+	return signed.Verify(key)
+}
@@ -20,6 +20,7 @@
 package client_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -28,6 +29,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -744,3 +746,96 @@ func (s *clientSuite) TestPendingRefreshNotificationOneClient(c *C) {
 	c.Assert(err, IsNil)
 	c.Check(atomic.LoadInt32(&n), Equals, int32(1))
 }
+
+func (s *clientSuite) serveSignedProcessSnapInfo(c *C, info *client.ProcessSnapInfo, key []byte) {
+	signed, err := client.SignProcessSnapInfo(info, key)
+	c.Assert(err, IsNil)
+	result, err := json.Marshal(signed)
+	c.Assert(err, IsNil)
+	s.handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v1/process-info")
+		c.Check(r.URL.Query().Get("pid"), Equals, "1234")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(200)
+		fmt.Fprintf(w, `{"type": "sync", "result": %s}`, result)
+	})
+}
+
+func (s *clientSuite) TestProcessSnapInfo(c *C) {
+	key := bytes.Repeat([]byte{'k'}, client.ProcessInfoKeySize)
+	c.Assert(os.WriteFile(client.ProcessInfoKeyPath(1000), key, 0600), IsNil)
+
+	expected := &client.ProcessSnapInfo{
+		Pid:          1234,
+		StartTime:    5678,
+		BootID:       "boot-id",
+		SecurityTag:  "snap.foo.app",
+		InstanceName: "foo",
+		Revision:     "42",
+		App:          "app",
+		Interfaces:   []string{"home", "x11"},
+		DesktopFile:  "foo_app.desktop",
+	}
+	s.serveSignedProcessSnapInfo(c, expected, key)
+
+	info, err := s.cli.ProcessSnapInfo(context.Background(), 1000, 1234)
+	c.Assert(err, IsNil)
+	c.Check(info, DeepEquals, expected)
+}
+
+func (s *clientSuite) TestProcessSnapInfoInvalidSignature(c *C) {
+	key := bytes.Repeat([]byte{'k'}, client.ProcessInfoKeySize)
+	c.Assert(os.WriteFile(client.ProcessInfoKeyPath(1000), key, 0600), IsNil)
+
+	// signed with another key
+	otherKey := bytes.Repeat([]byte{'o'}, client.ProcessInfoKeySize)
+	s.serveSignedProcessSnapInfo(c, &client.ProcessSnapInfo{Pid: 1234, InstanceName: "foo"}, otherKey)
+
+	info, err := s.cli.ProcessSnapInfo(context.Background(), 1000, 1234)
+	c.Assert(err, ErrorMatches, "cannot verify process info: invalid signature")
+	c.Check(info, IsNil)
+}
+
+func (s *clientSuite) TestProcessSnapInfoNoKey(c *C) {
+	key := bytes.Repeat([]byte{'k'}, client.ProcessInfoKeySize)
+	s.serveSignedProcessSnapInfo(c, &client.ProcessSnapInfo{Pid: 1234, InstanceName: "foo"}, key)
+
+	info, err := s.cli.ProcessSnapInfo(context.Background(), 1000, 1234)
+	c.Assert(err, ErrorMatches, "cannot read process info key: open .*/run/user/1000/snapd-session-agent.key: no such file or directory")
+	c.Check(info, IsNil)
+}
+
+func (s *clientSuite) TestSignedProcessSnapInfoVerify(c *C) {
+	key := bytes.Repeat([]byte{'k'}, client.ProcessInfoKeySize)
+	info := &client.ProcessSnapInfo{Pid: 1234, InstanceName: "foo", Revision: "1"}
+	signed, err := client.SignProcessSnapInfo(info, key)
+	c.Assert(err, IsNil)
+
+	verified, err := signed.Verify(key)
+	c.Assert(err, IsNil)
+	c.Check(verified, DeepEquals, info)
+
+	// the document cannot be altered
+	tampered := *signed
+	tampered.Document = strings.Replace(signed.Document, `"revision":"1"`, `"revision":"2"`, 1)
+	c.Assert(tampered.Document, Not(Equals), signed.Document)
+	_, err = tampered.Verify(key)
+	c.Check(err, ErrorMatches, "cannot verify process info: invalid signature")
+}
+
+func (s *clientSuite) TestProcessSnapInfoError(c *C) {
+	s.handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(404)
+		w.Write([]byte(`{
+  "type": "error",
+  "result": {
+    "message": "process 1234 does not belong to a snap"
+  }
+}`))
+	})
+	info, err := s.cli.ProcessSnapInfo(context.Background(), 1000, 1234)
+	c.Assert(err, ErrorMatches, "process 1234 does not belong to a snap")
+	c.Check(info, IsNil)
+}
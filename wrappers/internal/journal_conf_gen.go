@@ -75,3 +75,53 @@ LogsDirectory=
 	fmt.Fprint(&buf, template)
 	return buf.Bytes()
 }
+
+const journalUploadPath = "/lib/systemd/systemd-journal-upload"
+
+// GenerateQuotaJournalForwardUnitFile generates the systemd service unit
+// forwarding the journal namespace of the quota group to the remote server
+// it is configured with. It returns nil if the journal of the group is not
+// forwarded.
+func GenerateQuotaJournalForwardUnitFile(grp *quota.Group) ([]byte, error) {
+	if grp.JournalLimit == nil || grp.JournalLimit.Forward == "" {
+		return nil, nil
+	}
+	fwd, err := quota.ParseJournalForward(grp.JournalLimit.Forward)
+	if err != nil {
+		return nil, err
+	}
+
+	namespace := grp.JournalNamespaceName()
+	var serviceOptions string
+	switch fwd.Method {
+	case quota.JournalForwardUpload:
+		// the upload state, i.e. the cursor of the last uploaded entry,
+		// is kept per namespace
+		serviceOptions = fmt.Sprintf(`ExecStart=%[1]s --namespace=%[2]s --save-state=${STATE_DIRECTORY}/%[2]s.state --url=%[3]s
+StateDirectory=snapd/journal-forward
+`, journalUploadPath, namespace, fwd.URL)
+	case quota.JournalForwardSyslog:
+		serviceOptions = fmt.Sprintf(`ExecStart=/bin/sh -c 'journalctl --namespace=%[1]s --follow --lines=0 --quiet --output=short-iso | logger --server=%[2]s --port=%[3]d --%[4]s --tag=%[1]s'
+`, namespace, fwd.Host, fwd.Port, fwd.Protocol)
+	default:
+		return nil, fmt.Errorf("internal error: unknown journal forwarding method %q", fwd.Method)
+	}
+
+	template := `[Unit]
+Description=Journal forwarding for snap quota group %[1]s
+Requires=%[2]s
+Wants=network-online.target
+After=%[2]s network-online.target
+X-Snappy=yes
+
+[Service]
+%[3]sRestart=always
+RestartSec=10
+
+[Install]
+WantedBy=multi-user.target
+`
+	buf := bytes.Buffer{}
+	fmt.Fprintf(&buf, template, grp.Name, grp.JournalSocketName(), serviceOptions)
+	return buf.Bytes(), nil
+}
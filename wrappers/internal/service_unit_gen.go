@@ -82,6 +82,46 @@ func ensureMinSystemdDuration(app *snap.AppInfo, in timeout.Timeout, field strin
 	return inDur
 }
 
+// snapConfineCapabilities are the capabilities snap-confine and
+// snap-update-ns need to set up the sandbox of the service, they are always
+// part of a generated CapabilityBoundingSet= directive.
+var snapConfineCapabilities = []string{
+	"CAP_CHOWN",
+	"CAP_DAC_OVERRIDE",
+	"CAP_DAC_READ_SEARCH",
+	"CAP_FOWNER",
+	"CAP_SETGID",
+	"CAP_SETUID",
+	"CAP_SYS_ADMIN",
+	"CAP_SYS_CHROOT",
+	"CAP_SYS_PTRACE",
+}
+
+// hardeningDirectives returns the systemd directives for the hardening
+// requested by the service, leaving out those that conflict with the
+// interfaces the service plugs. Only the capability bounding set is applied
+// by systemd, the hardening of the mount namespace is applied by
+// snap-confine so that it stays private to the service and does not leak
+// into the mount namespace shared by all the apps of the snap.
+func hardeningDirectives(appInfo *snap.AppInfo) []string {
+	hardening := appInfo.Hardening
+	if hardening == nil || len(hardening.CapabilityBoundingSet) == 0 {
+		return nil
+	}
+
+	if plug := appInfo.CapabilityBoundingSetConflict(); plug != "" {
+		logger.Noticef("Warning: ignoring capability-bounding-set hardening of service %s from snap %s as it is incompatible with plug %q", appInfo.Name, appInfo.Snap.InstanceName(), plug)
+		return nil
+	}
+	capabilities := append([]string(nil), snapConfineCapabilities...)
+	for _, capability := range hardening.CapabilityBoundingSet {
+		if !strutil.ListContains(capabilities, capability) {
+			capabilities = append(capabilities, capability)
+		}
+	}
+	return []string{"CapabilityBoundingSet=" + strings.Join(capabilities, " ")}
+}
+
 func GenerateSnapServiceUnitFile(appInfo *snap.AppInfo, opts *SnapServicesUnitOptions) ([]byte, error) {
 	if opts == nil {
 		opts = &SnapServicesUnitOptions{}
@@ -205,6 +245,9 @@ KillSignal={{.KillSignal}}
 {{- if .OOMAdjustScore }}
 OOMScoreAdjust={{.OOMAdjustScore}}
 {{- end}}
+{{- range .HardeningDirectives}}
+{{.}}
+{{- end}}
 {{- if .InterfaceServiceSnippets}}
 {{.InterfaceServiceSnippets}}
 {{- end}}
@@ -285,6 +328,7 @@ WantedBy={{.ServicesTarget}}
 		Before                   []string
 		After                    []string
 		Requires                 []string
+		HardeningDirectives      []string
 		InterfaceServiceSnippets string
 		InterfaceUnitSnippets    string
 		SliceUnit                string
@@ -299,6 +343,7 @@ WantedBy={{.ServicesTarget}}
 
 		InterfaceServiceSnippets: ifaceSpecifiedServiceSnippet,
 		InterfaceUnitSnippets:    ifaceSpecifiedUnitSnippet,
+		HardeningDirectives:      hardeningDirectives(appInfo),
 		Restart:                  restartCond,
 
 		// When converting a Duration to a string, Golang produces units "ns",
@@ -33,6 +33,7 @@ import (
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/builtin"
 	_ "github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/release"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/snap/quota"
@@ -1040,3 +1041,110 @@ func (s *serviceUnitGenSuite) TestSuccessExitStatusNotPresent(c *C) {
 
 	c.Check(string(generatedWrapper), Not(Matches), `(?s).*SuccessExitStatus.*`)
 }
+
+func (s *serviceUnitGenSuite) TestHardeningSocketActivated(c *C) {
+	yamlText := `
+name: foo
+version: 1.0
+apps:
+    app:
+        command: bin/start
+        daemon: simple
+        plugs: [network-bind]
+        sockets:
+            sock:
+                listen-stream: $SNAP_DATA/sock
+        hardening:
+            protect-system: full
+            private-tmp: true
+            capability-bounding-set: [CAP_NET_BIND_SERVICE, CAP_SETUID]
+`
+	info, err := snap.InfoFromSnapYaml([]byte(yamlText))
+	c.Assert(err, IsNil)
+	info.Revision = snap.R(44)
+	app := info.Apps["app"]
+
+	generatedWrapper, err := internal.GenerateSnapServiceUnitFile(app, nil)
+	c.Assert(err, IsNil)
+	c.Check(string(generatedWrapper), Equals, fmt.Sprintf(`[Unit]
+# Auto-generated, DO NOT EDIT
+Description=Service for snap application foo.app
+Requires=%[1]s-foo-44.mount
+Wants=network.target
+After=%[1]s-foo-44.mount network.target snapd.apparmor.service
+X-Snappy=yes
+
+[Service]
+EnvironmentFile=-/etc/environment
+ExecStart=/usr/bin/snap run foo.app
+SyslogIdentifier=foo.app
+Restart=on-failure
+WorkingDirectory=/var/snap/foo/44
+TimeoutStopSec=30s
+Type=simple
+CapabilityBoundingSet=CAP_CHOWN CAP_DAC_OVERRIDE CAP_DAC_READ_SEARCH CAP_FOWNER CAP_SETGID CAP_SETUID CAP_SYS_ADMIN CAP_SYS_CHROOT CAP_SYS_PTRACE CAP_NET_BIND_SERVICE
+`, mountUnitPrefix))
+}
+
+func (s *serviceUnitGenSuite) TestHardeningIncompatibleInterfaces(c *C) {
+	logBuf, restore := logger.MockLogger()
+	defer restore()
+
+	yamlText := `
+name: foo
+version: 1.0
+plugs:
+    etc-files:
+        interface: system-files
+        write: [/etc/foo]
+apps:
+    app:
+        command: bin/start
+        daemon: simple
+        plugs: [etc-files, docker-support]
+        hardening:
+            protect-system: "true"
+            private-tmp: true
+            capability-bounding-set: [CAP_NET_ADMIN]
+`
+	info, err := snap.InfoFromSnapYaml([]byte(yamlText))
+	c.Assert(err, IsNil)
+	info.Revision = snap.R(44)
+	app := info.Apps["app"]
+
+	generatedWrapper, err := internal.GenerateSnapServiceUnitFile(app, nil)
+	c.Assert(err, IsNil)
+	c.Check(string(generatedWrapper), Not(testutil.Contains), "CapabilityBoundingSet=")
+	c.Check(logBuf.String(), testutil.Contains, `ignoring capability-bounding-set hardening of service app from snap foo as it is incompatible with plug "docker-support"`)
+}
+
+func (s *serviceUnitGenSuite) TestHardeningMountNamespaceNotInUnit(c *C) {
+	yamlText := `
+name: foo
+version: 1.0
+apps:
+    app:
+        command: bin/start
+        daemon: simple
+        plugs: [network-bind]
+        sockets:
+            sock:
+                listen-stream: $SNAP_DATA/sock
+        hardening:
+            protect-system: full
+            private-tmp: true
+`
+	info, err := snap.InfoFromSnapYaml([]byte(yamlText))
+	c.Assert(err, IsNil)
+	info.Revision = snap.R(44)
+	app := info.Apps["app"]
+
+	// the mount namespace hardening is applied by snap-confine, systemd
+	// must not set up a mount namespace of its own as snap-confine would
+	// preserve it for all the apps of the snap
+	generatedWrapper, err := internal.GenerateSnapServiceUnitFile(app, nil)
+	c.Assert(err, IsNil)
+	c.Check(string(generatedWrapper), Not(testutil.Contains), "ProtectSystem=")
+	c.Check(string(generatedWrapper), Not(testutil.Contains), "PrivateTmp=")
+	c.Check(string(generatedWrapper), Not(testutil.Contains), "CapabilityBoundingSet=")
+}
@@ -841,6 +841,60 @@ func (es *ensureSnapServicesContext) ensureJournalQuotaServiceUnits(quotaGroups
 	return nil
 }
 
+// ensureJournalForwardUnits takes care of writing, or removing when
+// forwarding was disabled, the service units forwarding journal namespaces
+// to remote servers.
+func (es *ensureSnapServicesContext) ensureJournalForwardUnits(quotaGroups *quota.QuotaGroupSet) error {
+	for _, grp := range quotaGroups.AllQuotaGroups() {
+		if len(grp.Services) > 0 {
+			// service sub-groups use the journal namespace of their
+			// parent group
+			continue
+		}
+
+		content, err := internal.GenerateQuotaJournalForwardUnitFile(grp)
+		if err != nil {
+			return err
+		}
+
+		path := filepath.Join(dirs.SnapServicesDir, grp.JournalForwardServiceName())
+		var old *osutil.MemoryFileState
+		if content != nil {
+			var modified bool
+			old, modified, err = tryFileUpdate(path, content)
+			if err != nil {
+				return err
+			}
+			if !modified {
+				continue
+			}
+		} else {
+			b, err := os.ReadFile(path)
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			old = &osutil.MemoryFileState{Content: b, Mode: 0644}
+			if err := removeJournalForwardUnit(es.sysd, grp, es.opts.Preseeding); err != nil {
+				return err
+			}
+		}
+
+		if es.observeChange != nil {
+			var oldContent []byte
+			if old != nil {
+				oldContent = old.Content
+			}
+			es.observeChange(nil, grp, "journal-forward", grp.Name, string(oldContent), string(content))
+		}
+		es.modifiedUnits[path] = old
+		es.systemDaemonReloadNeeded = true
+	}
+	return nil
+}
+
 // EnsureSnapServices will ensure that the specified snap services' file states
 // are up to date with the specified options and infos. It will add new services
 // if those units don't already exist, but it does not delete existing service
@@ -896,6 +950,10 @@ func EnsureSnapServices(snaps map[*snap.Info]*SnapServiceOptions, opts *EnsureSn
 		return err
 	}
 
+	if err := context.ensureJournalForwardUnits(quotaGroups); err != nil {
+		return err
+	}
+
 	return context.reloadModified()
 }
 
@@ -932,7 +990,8 @@ func serviceUnitsFromApps(apps []*snap.AppInfo, includeActivatedServices bool) [
 //  1. They must be services
 //  2. They must have a service unit
 //  3. If the reason for the stop is a refresh and the service is not being removed,
-//     it must not be marked as "endure" (i.e., it persists through refreshes)
+//     it must not be marked as "endure" or "ignore-running" (i.e., it persists
+//     through refreshes)
 //  4. The services must match the provided scope in flags.Scope
 //     (i. e) whether we are restarting user or system services (or both)
 //
@@ -950,7 +1009,7 @@ func filterAppsForStop(apps []*snap.AppInfo, removedSvcs map[string]*snap.AppInf
 		if reason == snap.StopReasonRefresh {
 			logger.Debugf(" %s refresh-mode: %v", app.Name, app.RefreshMode)
 			switch app.RefreshMode {
-			case "endure":
+			case "endure", "ignore-running":
 				if _, removed := removedSvcs[app.Name]; !removed {
 					// skip this service if it's not being removed
 					continue
@@ -984,7 +1043,7 @@ type StopServicesOptions struct {
 
 // StopServices stops and optionally disables service units for the applications
 // from the snap which are services eligible for stopping (i.e,. services marked
-// as "endure" that are not being removed should persist).
+// as "endure" or "ignore-running" that are not being removed should persist).
 func StopServices(svcs []*snap.AppInfo, removedSvcs map[string]*snap.AppInfo, opts *StopServicesOptions, reason snap.ServiceStopReason, inter Interacter, tm timings.Measurer) error {
 	if opts == nil {
 		opts = &StopServicesOptions{}
@@ -1080,6 +1139,24 @@ func StopServices(svcs []*snap.AppInfo, removedSvcs map[string]*snap.AppInfo, op
 	return nil
 }
 
+// removeJournalForwardUnit stops and disables the service forwarding the
+// journal namespace of the quota group, then removes its unit file.
+func removeJournalForwardUnit(sysd systemd.Systemd, grp *quota.Group, preseeding bool) error {
+	unit := grp.JournalForwardServiceName()
+	if !preseeding {
+		if err := sysd.Stop([]string{unit}); err != nil {
+			logger.Noticef("cannot stop journal forwarding of quota group %q: %v", grp.Name, err)
+		}
+		if err := sysd.DisableNoReload([]string{unit}); err != nil {
+			return err
+		}
+	}
+	if err := os.Remove(filepath.Join(dirs.SnapServicesDir, unit)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
 // RemoveQuotaGroup ensures that the slice file for a quota group is removed. It
 // assumes that the slice corresponding to the group is not in use anymore by
 // any services or sub-groups of the group when it is invoked. To remove a group
@@ -1094,13 +1171,21 @@ func RemoveQuotaGroup(grp *quota.Group, inter Interacter) error {
 
 	systemSysd := systemd.New(systemd.SystemMode, inter)
 
+	// stop forwarding the journal of the group, if it was
+	removedForwardUnit := osutil.FileExists(filepath.Join(dirs.SnapServicesDir, grp.JournalForwardServiceName()))
+	if removedForwardUnit {
+		if err := removeJournalForwardUnit(systemSysd, grp, false); err != nil {
+			return err
+		}
+	}
+
 	// remove the slice file
 	err := os.Remove(filepath.Join(dirs.SnapServicesDir, grp.SliceFileName()))
 	if err != nil && !os.IsNotExist(err) {
 		return err
 	}
 
-	if err == nil {
+	if err == nil || removedForwardUnit {
 		// we deleted the slice unit, so we need to daemon-reload
 		if err := systemSysd.DaemonReload(); err != nil {
 			return err
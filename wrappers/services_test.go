@@ -880,6 +880,148 @@ apps:
    daemon: simple
 `
 
+func (s *servicesTestSuite) TestEnsureSnapServicesWithJournalForward(c *C) {
+	info := snaptest.MockSnap(c, packageHello, &snap.SideInfo{Revision: snap.R(12)})
+	fwdFile := filepath.Join(dirs.GlobalRootDir, "/etc/systemd/system/snap-foogroup-journal-forward.service")
+
+	tests := []struct {
+		target  string
+		content string
+	}{
+		{"syslog+tcp://logs.example.com:6514", `[Unit]
+Description=Journal forwarding for snap quota group foogroup
+Requires=systemd-journald@snap-foogroup.socket
+Wants=network-online.target
+After=systemd-journald@snap-foogroup.socket network-online.target
+X-Snappy=yes
+
+[Service]
+ExecStart=/bin/sh -c 'journalctl --namespace=snap-foogroup --follow --lines=0 --quiet --output=short-iso | logger --server=logs.example.com --port=6514 --tcp --tag=snap-foogroup'
+Restart=always
+RestartSec=10
+
+[Install]
+WantedBy=multi-user.target
+`},
+		{"https://logs.example.com:19532", `[Unit]
+Description=Journal forwarding for snap quota group foogroup
+Requires=systemd-journald@snap-foogroup.socket
+Wants=network-online.target
+After=systemd-journald@snap-foogroup.socket network-online.target
+X-Snappy=yes
+
+[Service]
+ExecStart=/lib/systemd/systemd-journal-upload --namespace=snap-foogroup --save-state=${STATE_DIRECTORY}/snap-foogroup.state --url=https://logs.example.com:19532
+StateDirectory=snapd/journal-forward
+Restart=always
+RestartSec=10
+
+[Install]
+WantedBy=multi-user.target
+`},
+	}
+
+	for _, t := range tests {
+		grp, err := quota.NewGroup("foogroup", quota.NewResourcesBuilder().
+			WithJournalNamespace().
+			WithJournalForward(t.target).
+			Build())
+		c.Assert(err, IsNil)
+		m := map[*snap.Info]*wrappers.SnapServiceOptions{
+			info: {QuotaGroup: grp},
+		}
+
+		var observed []string
+		observe := func(app *snap.AppInfo, grp *quota.Group, unitType, name, old, new string) {
+			if unitType == "journal-forward" {
+				c.Check(name, Equals, "foogroup")
+				c.Check(new, Equals, t.content)
+				observed = append(observed, old)
+			}
+		}
+
+		s.sysdLog = nil
+		err = wrappers.EnsureSnapServices(m, nil, observe, progress.Null)
+		c.Assert(err, IsNil)
+		c.Check(observed, HasLen, 1)
+		c.Check(s.sysdLog, DeepEquals, [][]string{
+			{"daemon-reload"},
+		})
+		c.Check(fwdFile, testutil.FileEquals, t.content)
+	}
+}
+
+func (s *servicesTestSuite) TestEnsureSnapServicesWithJournalForwardDisabled(c *C) {
+	info := snaptest.MockSnap(c, packageHello, &snap.SideInfo{Revision: snap.R(12)})
+	fwdFile := filepath.Join(dirs.GlobalRootDir, "/etc/systemd/system/snap-foogroup-journal-forward.service")
+
+	grp, err := quota.NewGroup("foogroup", quota.NewResourcesBuilder().
+		WithJournalNamespace().
+		WithJournalForward("syslog://logs.example.com").
+		Build())
+	c.Assert(err, IsNil)
+	m := map[*snap.Info]*wrappers.SnapServiceOptions{
+		info: {QuotaGroup: grp},
+	}
+	err = wrappers.EnsureSnapServices(m, nil, nil, progress.Null)
+	c.Assert(err, IsNil)
+	c.Assert(fwdFile, testutil.FilePresent)
+
+	// forwarding is then disabled
+	err = grp.UpdateQuotaLimits(quota.NewResourcesBuilder().WithJournalForward("").Build())
+	c.Assert(err, IsNil)
+
+	var observed int
+	observe := func(app *snap.AppInfo, grp *quota.Group, unitType, name, old, new string) {
+		if unitType == "journal-forward" {
+			c.Check(old, Matches, `(?s).*--server=logs.example.com --port=514 --udp.*`)
+			c.Check(new, Equals, "")
+			observed++
+		}
+	}
+
+	s.sysdLog = nil
+	err = wrappers.EnsureSnapServices(m, nil, observe, progress.Null)
+	c.Assert(err, IsNil)
+	c.Check(observed, Equals, 1)
+	c.Check(fwdFile, testutil.FileAbsent)
+	c.Check(s.sysdLog, DeepEquals, [][]string{
+		{"stop", "snap-foogroup-journal-forward.service"},
+		{"show", "--property=ActiveState", "snap-foogroup-journal-forward.service"},
+		{"--no-reload", "disable", "snap-foogroup-journal-forward.service"},
+		{"daemon-reload"},
+	})
+
+	// nothing more to do the next time
+	s.sysdLog = nil
+	err = wrappers.EnsureSnapServices(m, nil, observe, progress.Null)
+	c.Assert(err, IsNil)
+	c.Check(observed, Equals, 1)
+	c.Check(s.sysdLog, HasLen, 0)
+}
+
+func (s *servicesTestSuite) TestRemoveQuotaGroupWithJournalForward(c *C) {
+	grp, err := quota.NewGroup("foogroup", quota.NewResourcesBuilder().
+		WithJournalNamespace().
+		WithJournalForward("syslog://logs.example.com").
+		Build())
+	c.Assert(err, IsNil)
+
+	fwdFile := filepath.Join(dirs.GlobalRootDir, "/etc/systemd/system/snap-foogroup-journal-forward.service")
+	c.Assert(os.MkdirAll(filepath.Dir(fwdFile), 0755), IsNil)
+	c.Assert(os.WriteFile(fwdFile, nil, 0644), IsNil)
+
+	err = wrappers.RemoveQuotaGroup(grp, progress.Null)
+	c.Assert(err, IsNil)
+	c.Check(fwdFile, testutil.FileAbsent)
+	c.Check(s.sysdLog, DeepEquals, [][]string{
+		{"stop", "snap-foogroup-journal-forward.service"},
+		{"show", "--property=ActiveState", "snap-foogroup-journal-forward.service"},
+		{"--no-reload", "disable", "snap-foogroup-journal-forward.service"},
+		{"daemon-reload"},
+	})
+}
+
 func (s *servicesTestSuite) TestEnsureSnapServicesWithSnapServices(c *C) {
 	// Test ensures that if a snap has services in a sub-group, the sub-group
 	// slice is correctly applied to the service unit for the snap service. We should
@@ -4401,14 +4543,22 @@ func (s *servicesTestSuite) TestServiceWatchdog(c *C) {
 }
 
 func (s *servicesTestSuite) TestStopServiceEndure(c *C) {
-	const surviveYaml = `name: survive-snap
+	s.testStopServiceKeptOnRefresh(c, "endure")
+}
+
+func (s *servicesTestSuite) TestStopServiceIgnoreRunning(c *C) {
+	s.testStopServiceKeptOnRefresh(c, "ignore-running")
+}
+
+func (s *servicesTestSuite) testStopServiceKeptOnRefresh(c *C, refreshMode string) {
+	surviveYaml := fmt.Sprintf(`name: survive-snap
 version: 1.0
 apps:
  survivor:
   command: bin/survivor
-  refresh-mode: endure
+  refresh-mode: %s
   daemon: simple
-`
+`, refreshMode)
 	info := snaptest.MockSnap(c, surviveYaml, &snap.SideInfo{Revision: snap.R(1)})
 	survivorFile := filepath.Join(dirs.GlobalRootDir, "/etc/systemd/system/snap.survive-snap.survivor.service")
 
@@ -4443,13 +4593,13 @@ apps:
 			reason: snap.StopReasonRefresh,
 		},
 		{
-			// stops endure service if not present in new snap revision
+			// stops the service if not present in new snap revision
 			reason:      snap.StopReasonRefresh,
 			removedSvcs: apps[0].Snap.Apps,
 			sysdLog:     stoppedSvc,
 		},
 		{
-			// stops endure service if removing the snap
+			// stops the service if removing the snap
 			reason:  snap.StopReasonRemove,
 			sysdLog: stoppedSvc,
 		},